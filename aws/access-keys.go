@@ -124,6 +124,8 @@ func (m *AccessKeysModule) PrintAccessKeys(filter string, outputDirectory string
 		})
 		o.PrefixIdentifier = m.AWSProfile
 		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
 		o.WriteFullOutput(o.Table.TableFiles, nil)
 		m.writeLoot(o.Table.DirectoryName, verbosity)
 		//m.writeLoot(m.output.FilePath, verbosity)
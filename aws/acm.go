@@ -0,0 +1,412 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	acmTypes "github.com/aws/aws-sdk-go-v2/service/acm/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type ACMModule struct {
+	// General configuration data
+	ACMClient sdk.ACMClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+	ExpiryDays int
+
+	// Main module data
+	Certificates   []Certificate
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type Certificate struct {
+	Region      string
+	Arn         string
+	DomainName  string
+	SANs        []string
+	Expiry      time.Time
+	DaysLeft    int
+	Issuer      string
+	Type        string
+	AutoRenew   string
+	InUse       string
+	PublicCA    string
+	Status      string
+	Note        string
+	ExpiryState string
+}
+
+// Issuers of publicly trusted certificates that show up on imported certificates. A publicly trusted certificate
+// whose private key lives outside of ACM can be used to MITM the domains it covers if that key leaks.
+var publicCertificateIssuers = []string{
+	"amazon",
+	"buypass",
+	"comodo",
+	"digicert",
+	"entrust",
+	"geotrust",
+	"globalsign",
+	"go daddy",
+	"godaddy",
+	"google trust services",
+	"identrust",
+	"let's encrypt",
+	"rapidssl",
+	"sectigo",
+	"starfield",
+	"thawte",
+	"zerossl",
+}
+
+func (m *ACMModule) PrintCertificates(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "acm"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+	if m.ExpiryDays <= 0 {
+		m.ExpiryDays = 30
+	}
+
+	fmt.Printf("[%s][%s] Enumerating ACM certificates for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+	fmt.Printf("[%s][%s] Flagging certificates that expire within %d days.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.ExpiryDays)
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan Certificate)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	// Show the certificates that expire first at the top
+	sort.Slice(m.Certificates, func(i, j int) bool {
+		return m.Certificates[i].Expiry.Before(m.Certificates[j].Expiry)
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Domain",
+		"SANs",
+		"Expiry",
+		"Days Left",
+		"State",
+		"Issuer",
+		"Type",
+		"Auto-renew?",
+		"In Use?",
+		"Public CA?",
+		"Note",
+		"Arn",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Domain",
+			"SANs",
+			"Expiry",
+			"Days Left",
+			"State",
+			"Issuer",
+			"Type",
+			"Auto-renew?",
+			"In Use?",
+			"Public CA?",
+			"Note",
+			"Arn",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Domain",
+			"Expiry",
+			"State",
+			"Issuer",
+			"Auto-renew?",
+			"Public CA?",
+			"Note",
+		}
+	}
+
+	// Table rows
+	for i := range m.Certificates {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				m.Certificates[i].Region,
+				m.Certificates[i].DomainName,
+				strings.Join(m.Certificates[i].SANs, "\n"),
+				m.Certificates[i].Expiry.Format("2006-01-02"),
+				strconv.Itoa(m.Certificates[i].DaysLeft),
+				m.Certificates[i].ExpiryState,
+				m.Certificates[i].Issuer,
+				m.Certificates[i].Type,
+				m.Certificates[i].AutoRenew,
+				m.Certificates[i].InUse,
+				m.Certificates[i].PublicCA,
+				m.Certificates[i].Note,
+				m.Certificates[i].Arn,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+
+		loot := m.writeLoot()
+		if loot != "" {
+			o.Loot.DirectoryName = o.Table.DirectoryName
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     "acm-certificates",
+				Contents: loot,
+			})
+		}
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		expired, expiring := m.countExpiring()
+		fmt.Printf("[%s][%s] %d certificates found (%d expired, %d expiring within %d days).\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), expired, expiring, m.ExpiryDays)
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No certificates found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *ACMModule) countExpiring() (int, int) {
+	var expired, expiring int
+	for _, certificate := range m.Certificates {
+		switch certificate.ExpiryState {
+		case "Expired":
+			expired++
+		case "Expiring":
+			expiring++
+		}
+	}
+	return expired, expiring
+}
+
+// writeLoot lists the describe commands for expired or expiring certificates and the export commands for private
+// CA certificates, which are the only ones ACM lets you export along with the private key.
+func (m *ACMModule) writeLoot() string {
+	var out string
+	for _, certificate := range m.Certificates {
+		if certificate.ExpiryState != "Valid" {
+			out += fmt.Sprintf("# %s - %s (%d days left)\n", certificate.DomainName, certificate.ExpiryState, certificate.DaysLeft)
+			out += fmt.Sprintf("aws --profile $profile --region %s acm describe-certificate --certificate-arn %s\n\n", certificate.Region, certificate.Arn)
+		}
+		if certificate.Type == string(acmTypes.CertificateTypePrivate) {
+			out += fmt.Sprintf("# %s - private CA certificate\n", certificate.DomainName)
+			out += fmt.Sprintf("aws --profile $profile --region %s acm export-certificate --certificate-arn %s --passphrase $(echo -n 'cloudfox' | base64)\n\n", certificate.Region, certificate.Arn)
+		}
+	}
+	return out
+}
+
+func (m *ACMModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Certificate) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("acm", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getCertificatesPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *ACMModule) Receiver(receiver chan Certificate, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Certificates = append(m.Certificates, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *ACMModule) getCertificatesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Certificate) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	certificates, err := sdk.CachedACMListCertificates(m.ACMClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	now := time.Now()
+	for _, summary := range certificates {
+		detail, err := sdk.CachedACMDescribeCertificate(m.ACMClient, aws.ToString(m.Caller.Account), r, aws.ToString(summary.CertificateArn))
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		dataReceiver <- m.newCertificate(r, detail, now)
+	}
+}
+
+func (m *ACMModule) newCertificate(r string, detail acmTypes.CertificateDetail, now time.Time) Certificate {
+	certificate := Certificate{
+		Region:     r,
+		Arn:        aws.ToString(detail.CertificateArn),
+		DomainName: aws.ToString(detail.DomainName),
+		SANs:       detail.SubjectAlternativeNames,
+		Issuer:     aws.ToString(detail.Issuer),
+		Type:       string(detail.Type),
+		Status:     string(detail.Status),
+		AutoRenew:  "No",
+		InUse:      "No",
+		PublicCA:   "No",
+	}
+
+	if detail.NotAfter != nil {
+		certificate.Expiry = aws.ToTime(detail.NotAfter)
+		certificate.DaysLeft = int(certificate.Expiry.Sub(now).Hours() / 24)
+	}
+	switch {
+	case detail.NotAfter == nil:
+		// Certificates that are still pending validation don't have an expiry date yet
+		certificate.ExpiryState = certificate.Status
+	case certificate.Expiry.Before(now):
+		certificate.ExpiryState = "Expired"
+	case certificate.DaysLeft <= m.ExpiryDays:
+		certificate.ExpiryState = "Expiring"
+	default:
+		certificate.ExpiryState = "Valid"
+	}
+
+	// Imported certificates are never renewed by ACM
+	if detail.RenewalEligibility == acmTypes.RenewalEligibilityEligible && detail.Type != acmTypes.CertificateTypeImported {
+		certificate.AutoRenew = "Yes"
+	}
+	if len(detail.InUseBy) > 0 {
+		certificate.InUse = "Yes"
+	}
+
+	var notes []string
+	if detail.RenewalSummary != nil && detail.RenewalSummary.RenewalStatus == acmTypes.RenewalStatusFailed {
+		notes = append(notes, "Auto-renewal failed")
+	}
+	switch detail.Type {
+	case acmTypes.CertificateTypeAmazonIssued:
+		certificate.PublicCA = "Yes"
+	case acmTypes.CertificateTypeImported:
+		if isPublicCertificateIssuer(certificate.Issuer) {
+			certificate.PublicCA = "Yes"
+			notes = append(notes, "Publicly trusted and the private key exists outside of ACM (MITM risk if it leaks)")
+		}
+	case acmTypes.CertificateTypePrivate:
+		notes = append(notes, "Private CA certificate, the private key can be exported")
+	}
+	certificate.Note = strings.Join(notes, "; ")
+
+	return certificate
+}
+
+func isPublicCertificateIssuer(issuer string) bool {
+	issuer = strings.ToLower(issuer)
+	for _, publicIssuer := range publicCertificateIssuers {
+		if strings.Contains(issuer, publicIssuer) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package aws
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestACMCertificates(t *testing.T) {
+
+	m := ACMModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines: 3,
+		WrapTable:  false,
+		ExpiryDays: 30,
+		ACMClient:  &sdk.MockedACMClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+	tmpDir := "."
+
+	m.PrintCertificates(tmpDir, 2)
+
+	expectedStates := map[string]string{
+		"www.example.com":           "Expiring",
+		"legacy.example.com":        "Expired",
+		"internal.corp.example.com": "Valid",
+	}
+	for _, certificate := range m.Certificates {
+		if certificate.ExpiryState != expectedStates[certificate.DomainName] {
+			t.Errorf("Expected %s to be %s, got %s", certificate.DomainName, expectedStates[certificate.DomainName], certificate.ExpiryState)
+		}
+		switch certificate.DomainName {
+		case "www.example.com":
+			if certificate.AutoRenew != "Yes" || !strings.Contains(certificate.Note, "Auto-renewal failed") {
+				t.Errorf("Expected www.example.com to be auto-renewing with a failed renewal")
+			}
+		case "legacy.example.com":
+			if certificate.PublicCA != "Yes" || certificate.AutoRenew != "No" {
+				t.Errorf("Expected legacy.example.com to be an imported public CA certificate that doesn't auto-renew")
+			}
+		case "internal.corp.example.com":
+			if certificate.PublicCA != "No" {
+				t.Errorf("Expected internal.corp.example.com not to be from a public CA")
+			}
+		}
+	}
+
+	// Certificates are sorted by expiry, so the expired one comes first
+	if len(m.Certificates) != 3 || m.Certificates[0].DomainName != "legacy.example.com" {
+		t.Errorf("Expected the expired certificate to be listed first")
+	}
+
+	lootFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/loot/acm-certificates.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	if !strings.Contains(string(lootFile), "acm export-certificate --certificate-arn arn:aws:acm:us-east-1:123456789012:certificate/33333333-3333-3333-3333-333333333333") {
+		t.Errorf("Expected an export command for the private certificate in the loot file")
+	}
+}
+
+func TestIsPublicCertificateIssuer(t *testing.T) {
+	subtests := map[string]bool{
+		"DigiCert Inc":     true,
+		"Let's Encrypt":    true,
+		"Amazon":           true,
+		"Corp Internal CA": false,
+		"":                 false,
+	}
+	for issuer, expected := range subtests {
+		if got := isPublicCertificateIssuer(issuer); got != expected {
+			t.Errorf("isPublicCertificateIssuer(%s) = %t, expected %t", issuer, got, expected)
+		}
+	}
+}
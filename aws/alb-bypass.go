@@ -0,0 +1,654 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	elbV2Types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type ALBBypassModule struct {
+	// General configuration data
+	ELBv2Client sdk.ELBv2ClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Rules          []ALBListenerRule
+	Bypasses       []ALBBypass
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type ALBListenerRule struct {
+	Region       string
+	LoadBalancer string
+	DNSName      string
+	Scheme       string
+	Port         int32
+	Protocol     string
+	// Priority is the rule priority as a number, the default rule gets albDefaultRulePriority so it sorts last
+	Priority      int
+	Conditions    []elbV2Types.RuleCondition
+	Actions       []elbV2Types.Action
+	Authenticated bool
+	Findings      []string
+}
+
+// ALBBypass is an unauthenticated forward rule that takes requests an authenticated rule with a lower priority was
+// meant to get
+type ALBBypass struct {
+	Region       string
+	LoadBalancer string
+	DNSName      string
+	Scheme       string
+	Port         int32
+	Protocol     string
+	// ProtectedPriority is the priority of the authenticated rule, BypassPriority the one of the forward rule before it
+	ProtectedPriority int
+	BypassPriority    int
+	// Path and Host are an example request that both rules match
+	Path string
+	Host string
+	// OtherConditions are the fields of the forward rule's conditions besides the path and host, the request has to
+	// meet those as well
+	OtherConditions []string
+}
+
+const albDefaultRulePriority = 50001
+
+func (m *ALBBypassModule) PrintALBBypass(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "alb-bypass"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating ALB listener rules for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan []ALBListenerRule)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.Rules, func(i, j int) bool {
+		a, b := m.Rules[i], m.Rules[j]
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.LoadBalancer != b.LoadBalancer {
+			return a.LoadBalancer < b.LoadBalancer
+		}
+		if a.Port != b.Port {
+			return a.Port < b.Port
+		}
+		return a.Priority < b.Priority
+	})
+	m.Bypasses = findALBBypasses(m.Rules)
+	for _, bypass := range m.Bypasses {
+		for i := range m.Rules {
+			rule := &m.Rules[i]
+			if rule.Region != bypass.Region || rule.LoadBalancer != bypass.LoadBalancer || rule.Port != bypass.Port {
+				continue
+			}
+			switch rule.Priority {
+			case bypass.ProtectedPriority:
+				rule.Findings = append(rule.Findings, fmt.Sprintf("Bypassed by unauthenticated rule %s", albPriorityString(bypass.BypassPriority)))
+			case bypass.BypassPriority:
+				rule.Findings = append(rule.Findings, fmt.Sprintf("Skips authentication of rule %s", albPriorityString(bypass.ProtectedPriority)))
+			}
+		}
+	}
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Load Balancer",
+		"Scheme",
+		"Listener",
+		"Priority",
+		"Conditions",
+		"Actions",
+		"Findings",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Load Balancer",
+			"Scheme",
+			"Listener",
+			"Priority",
+			"Conditions",
+			"Actions",
+			"Findings",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Load Balancer",
+			"Listener",
+			"Priority",
+			"Conditions",
+			"Actions",
+			"Findings",
+		}
+	}
+
+	// Table rows
+	for _, rule := range m.Rules {
+		var findings []string
+		for _, finding := range rule.Findings {
+			findings = append(findings, magenta(finding))
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				rule.Region,
+				rule.LoadBalancer,
+				rule.Scheme,
+				fmt.Sprintf("%s:%d", rule.Protocol, rule.Port),
+				albPriorityString(rule.Priority),
+				strings.Join(albConditionStrings(rule.Conditions), "\n"),
+				strings.Join(albActionStrings(rule.Actions), "\n"),
+				strings.Join(findings, "\n"),
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		if len(m.Bypasses) > 0 {
+			o.Loot.DirectoryName = o.Table.DirectoryName
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     m.output.CallingModule,
+				Contents: m.writeLoot(),
+			})
+		}
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d listener rules found, %d authenticated rules can be bypassed.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), len(m.Bypasses))
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No ALB listener rules found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+// writeLoot has a curl command for each bypass that requests a path the authenticated rule was meant to protect
+func (m *ALBBypassModule) writeLoot() string {
+	var out string
+	out += "#############################################\n"
+	out += "# Request paths that authenticated listener rules were meant to protect. A higher priority rule forwards\n"
+	out += "# them to its target group without authentication. Without a login redirect, the bypass works.\n"
+	out += "#############################################\n"
+
+	for _, bypass := range m.Bypasses {
+		out += fmt.Sprintf("\n# %s in %s, rule %s skips the authentication of rule %s", bypass.LoadBalancer, bypass.Region, albPriorityString(bypass.BypassPriority), albPriorityString(bypass.ProtectedPriority))
+		if bypass.Scheme != string(elbV2Types.LoadBalancerSchemeEnumInternetFacing) {
+			out += ", only reachable from inside the VPC"
+		}
+		out += "\n"
+		if len(bypass.OtherConditions) > 0 {
+			out += fmt.Sprintf("# The request also has to match the %s conditions of rule %s\n", strings.Join(bypass.OtherConditions, ", "), albPriorityString(bypass.BypassPriority))
+		}
+		var hostHeader string
+		if bypass.Host != "" {
+			hostHeader = fmt.Sprintf("-H 'Host: %s' ", bypass.Host)
+		}
+		out += fmt.Sprintf("curl -sk -o /dev/null -w '%%{http_code} %%{redirect_url}\\n' %s'%s://%s:%d%s'\n", hostHeader, strings.ToLower(bypass.Protocol), bypass.DNSName, bypass.Port, bypass.Path)
+	}
+	return out
+}
+
+func (m *ALBBypassModule) Receiver(receiver chan []ALBListenerRule, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Rules = append(m.Rules, data...)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *ALBBypassModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan []ALBListenerRule) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("elb", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getListenerRulesPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *ALBBypassModule) getListenerRulesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan []ALBListenerRule) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	loadBalancers, err := sdk.CachedELBv2DescribeLoadBalancers(m.ELBv2Client, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, lb := range loadBalancers {
+		// Only application load balancers have listener rules
+		if lb.Type != elbV2Types.LoadBalancerTypeEnumApplication {
+			continue
+		}
+		listeners, err := sdk.CachedELBv2DescribeListeners(m.ELBv2Client, aws.ToString(m.Caller.Account), r, aws.ToString(lb.LoadBalancerArn))
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		for _, listener := range listeners {
+			rules, err := sdk.CachedELBv2DescribeRules(m.ELBv2Client, aws.ToString(m.Caller.Account), r, aws.ToString(listener.ListenerArn))
+			if err != nil {
+				m.modLog.Error(err.Error())
+				m.CommandCounter.Error++
+				continue
+			}
+			var listenerRules []ALBListenerRule
+			for _, rule := range rules {
+				listenerRules = append(listenerRules, ALBListenerRule{
+					Region:        r,
+					LoadBalancer:  aws.ToString(lb.LoadBalancerName),
+					DNSName:       aws.ToString(lb.DNSName),
+					Scheme:        string(lb.Scheme),
+					Port:          aws.ToInt32(listener.Port),
+					Protocol:      string(listener.Protocol),
+					Priority:      albRulePriority(rule),
+					Conditions:    rule.Conditions,
+					Actions:       rule.Actions,
+					Authenticated: albRuleAuthenticates(rule.Actions),
+				})
+			}
+			dataReceiver <- listenerRules
+		}
+	}
+}
+
+func albRulePriority(rule elbV2Types.Rule) int {
+	priority, err := strconv.Atoi(aws.ToString(rule.Priority))
+	if err != nil || aws.ToBool(rule.IsDefault) {
+		return albDefaultRulePriority
+	}
+	return priority
+}
+
+func albPriorityString(priority int) string {
+	if priority == albDefaultRulePriority {
+		return "default"
+	}
+	return strconv.Itoa(priority)
+}
+
+func albRuleAuthenticates(actions []elbV2Types.Action) bool {
+	for _, action := range actions {
+		if action.Type == elbV2Types.ActionTypeEnumAuthenticateOidc || action.Type == elbV2Types.ActionTypeEnumAuthenticateCognito {
+			return true
+		}
+	}
+	return false
+}
+
+func albRuleForwards(actions []elbV2Types.Action) bool {
+	for _, action := range actions {
+		if action.Type == elbV2Types.ActionTypeEnumForward {
+			return true
+		}
+	}
+	return false
+}
+
+// findALBBypasses compares every authenticated rule with the unauthenticated forward rules that the listener evaluates
+// before it. rules has to be sorted by listener and priority.
+func findALBBypasses(rules []ALBListenerRule) []ALBBypass {
+	var bypasses []ALBBypass
+	for i, protected := range rules {
+		if !protected.Authenticated {
+			continue
+		}
+		for _, rule := range rules[:i] {
+			if rule.Region != protected.Region || rule.LoadBalancer != protected.LoadBalancer || rule.Port != protected.Port {
+				continue
+			}
+			if rule.Authenticated || !albRuleForwards(rule.Actions) {
+				continue
+			}
+			path, ok := albPatternsOverlap(albConditionValues(rule.Conditions, "path-pattern"), albConditionValues(protected.Conditions, "path-pattern"), false)
+			if !ok {
+				continue
+			}
+			host, ok := albPatternsOverlap(albConditionValues(rule.Conditions, "host-header"), albConditionValues(protected.Conditions, "host-header"), true)
+			if !ok {
+				continue
+			}
+			if !strings.HasPrefix(path, "/") {
+				path = "/" + path
+			}
+			var otherConditions []string
+			for _, condition := range rule.Conditions {
+				field := aws.ToString(condition.Field)
+				if field != "path-pattern" && field != "host-header" && !internal.Contains(field, otherConditions) {
+					otherConditions = append(otherConditions, field)
+				}
+			}
+			bypasses = append(bypasses, ALBBypass{
+				Region:            protected.Region,
+				LoadBalancer:      protected.LoadBalancer,
+				DNSName:           protected.DNSName,
+				Scheme:            protected.Scheme,
+				Port:              protected.Port,
+				Protocol:          protected.Protocol,
+				ProtectedPriority: protected.Priority,
+				BypassPriority:    rule.Priority,
+				Path:              path,
+				Host:              host,
+				OtherConditions:   otherConditions,
+			})
+			// The first rule is the one the listener picks
+			break
+		}
+	}
+	return bypasses
+}
+
+// albConditionValues returns the values of the rule's conditions on field. Both the field's config and the older
+// Values list are read.
+func albConditionValues(conditions []elbV2Types.RuleCondition, field string) []string {
+	var values []string
+	for _, condition := range conditions {
+		if aws.ToString(condition.Field) != field {
+			continue
+		}
+		values = append(values, condition.Values...)
+		switch {
+		case field == "path-pattern" && condition.PathPatternConfig != nil:
+			values = append(values, condition.PathPatternConfig.Values...)
+		case field == "host-header" && condition.HostHeaderConfig != nil:
+			values = append(values, condition.HostHeaderConfig.Values...)
+		}
+	}
+	return values
+}
+
+// albPatternsOverlap tells if a value matches one of a and one of b and returns an example of such a value. A rule
+// without a condition on the field matches every value, which is what an empty list stands for. The example is empty
+// when neither list has patterns.
+func albPatternsOverlap(a []string, b []string, caseInsensitive bool) (string, bool) {
+	if len(a) == 0 && len(b) == 0 {
+		return "", true
+	}
+	if len(a) == 0 {
+		a = []string{"*"}
+	}
+	if len(b) == 0 {
+		b = []string{"*"}
+	}
+	for _, patternA := range a {
+		for _, patternB := range b {
+			if caseInsensitive {
+				patternA, patternB = strings.ToLower(patternA), strings.ToLower(patternB)
+			}
+			if example, ok := albWildcardIntersection(patternA, patternB); ok {
+				return example, true
+			}
+		}
+	}
+	return "", false
+}
+
+// albWildcardIntersection looks for a value that both patterns match, where * matches any number of characters and ?
+// exactly one, like the path and host conditions of listener rules do
+func albWildcardIntersection(a string, b string) (string, bool) {
+	failed := make(map[[2]int]bool)
+	var intersect func(i, j int) (string, bool)
+	intersect = func(i, j int) (string, bool) {
+		if failed[[2]int{i, j}] {
+			return "", false
+		}
+		example, ok := func() (string, bool) {
+			if i == len(a) && j == len(b) {
+				return "", true
+			}
+			if i < len(a) && a[i] == '*' {
+				if rest, ok := intersect(i+1, j); ok {
+					return rest, true
+				}
+				if j < len(b) && b[j] != '*' {
+					if rest, ok := intersect(i, j+1); ok {
+						return albExampleChar(b[j], b[j]) + rest, true
+					}
+				}
+			}
+			if j < len(b) && b[j] == '*' {
+				if rest, ok := intersect(i, j+1); ok {
+					return rest, true
+				}
+				if i < len(a) && a[i] != '*' {
+					if rest, ok := intersect(i+1, j); ok {
+						return albExampleChar(a[i], a[i]) + rest, true
+					}
+				}
+			}
+			if i == len(a) || j == len(b) || a[i] == '*' || b[j] == '*' {
+				return "", false
+			}
+			if a[i] == b[j] || a[i] == '?' || b[j] == '?' {
+				if rest, ok := intersect(i+1, j+1); ok {
+					return albExampleChar(a[i], b[j]) + rest, true
+				}
+			}
+			return "", false
+		}()
+		if !ok {
+			failed[[2]int{i, j}] = true
+		}
+		return example, ok
+	}
+	return intersect(0, 0)
+}
+
+// albExampleChar is the character of the example value where the patterns have a and b, which can be ? wildcards
+func albExampleChar(a byte, b byte) string {
+	switch {
+	case a != '?':
+		return string(a)
+	case b != '?':
+		return string(b)
+	}
+	return "x"
+}
+
+func albConditionStrings(conditions []elbV2Types.RuleCondition) []string {
+	var out []string
+	for _, condition := range conditions {
+		field := aws.ToString(condition.Field)
+		var values []string
+		switch field {
+		case "path-pattern", "host-header":
+			values = albConditionValues([]elbV2Types.RuleCondition{condition}, field)
+		case "http-header":
+			if condition.HttpHeaderConfig != nil {
+				values = append(values, fmt.Sprintf("%s: %s", aws.ToString(condition.HttpHeaderConfig.HttpHeaderName), strings.Join(condition.HttpHeaderConfig.Values, ", ")))
+			}
+		case "http-request-method":
+			if condition.HttpRequestMethodConfig != nil {
+				values = condition.HttpRequestMethodConfig.Values
+			}
+		case "query-string":
+			if condition.QueryStringConfig != nil {
+				for _, pair := range condition.QueryStringConfig.Values {
+					values = append(values, fmt.Sprintf("%s=%s", aws.ToString(pair.Key), aws.ToString(pair.Value)))
+				}
+			}
+		case "source-ip":
+			if condition.SourceIpConfig != nil {
+				values = condition.SourceIpConfig.Values
+			}
+		}
+		if len(values) == 0 {
+			values = condition.Values
+		}
+		out = append(out, fmt.Sprintf("%s: %s", field, strings.Join(values, ", ")))
+	}
+	return out
+}
+
+func albActionStrings(actions []elbV2Types.Action) []string {
+	sorted := append([]elbV2Types.Action{}, actions...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return aws.ToInt32(sorted[i].Order) < aws.ToInt32(sorted[j].Order)
+	})
+	var out []string
+	for _, action := range sorted {
+		switch action.Type {
+		case elbV2Types.ActionTypeEnumForward:
+			var targetGroups []string
+			if action.TargetGroupArn != nil {
+				targetGroups = append(targetGroups, albTargetGroupName(aws.ToString(action.TargetGroupArn)))
+			} else if action.ForwardConfig != nil {
+				for _, targetGroup := range action.ForwardConfig.TargetGroups {
+					targetGroups = append(targetGroups, albTargetGroupName(aws.ToString(targetGroup.TargetGroupArn)))
+				}
+			}
+			out = append(out, fmt.Sprintf("forward: %s", strings.Join(targetGroups, ", ")))
+		case elbV2Types.ActionTypeEnumAuthenticateOidc:
+			if action.AuthenticateOidcConfig != nil {
+				out = append(out, fmt.Sprintf("authenticate-oidc: %s", aws.ToString(action.AuthenticateOidcConfig.Issuer)))
+			} else {
+				out = append(out, string(action.Type))
+			}
+		case elbV2Types.ActionTypeEnumAuthenticateCognito:
+			if action.AuthenticateCognitoConfig != nil {
+				out = append(out, fmt.Sprintf("authenticate-cognito: %s", aws.ToString(action.AuthenticateCognitoConfig.UserPoolDomain)))
+			} else {
+				out = append(out, string(action.Type))
+			}
+		case elbV2Types.ActionTypeEnumRedirect:
+			if action.RedirectConfig != nil {
+				out = append(out, fmt.Sprintf("redirect: %s://%s:%s%s", aws.ToString(action.RedirectConfig.Protocol), aws.ToString(action.RedirectConfig.Host), aws.ToString(action.RedirectConfig.Port), aws.ToString(action.RedirectConfig.Path)))
+			} else {
+				out = append(out, string(action.Type))
+			}
+		case elbV2Types.ActionTypeEnumFixedResponse:
+			if action.FixedResponseConfig != nil {
+				out = append(out, fmt.Sprintf("fixed-response: %s", aws.ToString(action.FixedResponseConfig.StatusCode)))
+			} else {
+				out = append(out, string(action.Type))
+			}
+		default:
+			out = append(out, string(action.Type))
+		}
+	}
+	return out
+}
+
+// albTargetGroupName takes the name out of a target group ARN, arn:...:targetgroup/<name>/<id>
+func albTargetGroupName(arn string) string {
+	parts := strings.Split(arn, "/")
+	if len(parts) >= 3 {
+		return parts[len(parts)-2]
+	}
+	return arn
+}
@@ -0,0 +1,91 @@
+package aws
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestALBBypass(t *testing.T) {
+	m := ALBBypassModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:  3,
+		ELBv2Client: &sdk.MockedElbv2Client{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintALBBypass(".", 2)
+
+	var priorities []int
+	for _, rule := range m.Rules {
+		priorities = append(priorities, rule.Priority)
+	}
+	if expected := []int{5, 10, 20, 30, 40, albDefaultRulePriority}; !reflect.DeepEqual(priorities, expected) {
+		t.Fatalf("Expected the rules sorted by priority %v, got %v", expected, priorities)
+	}
+
+	// The /console/* rules have different host headers, so only the /api/* rule gets in the way
+	expectedBypasses := []ALBBypass{
+		{
+			Region:            "us-east-1",
+			LoadBalancer:      "my-load-balancer",
+			DNSName:           "my-load-balancer-424835706.us-east-1.elb.amazonaws.com",
+			Scheme:            "internet-facing",
+			Port:              443,
+			Protocol:          "HTTPS",
+			ProtectedPriority: 10,
+			BypassPriority:    5,
+			Path:              "/api/admin/",
+		},
+	}
+	if !reflect.DeepEqual(m.Bypasses, expectedBypasses) {
+		t.Errorf("Expected bypasses %+v, got %+v", expectedBypasses, m.Bypasses)
+	}
+	if findings := m.Rules[1].Findings; !reflect.DeepEqual(findings, []string{"Bypassed by unauthenticated rule 5"}) {
+		t.Errorf("Unexpected findings of rule 10: %v", findings)
+	}
+
+	lootFilePath := filepath.Join(".", "cloudfox-output/aws/unittesting-123456789012/loot/alb-bypass.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	if !strings.Contains(string(lootFile), "'https://my-load-balancer-424835706.us-east-1.elb.amazonaws.com:443/api/admin/'") {
+		t.Errorf("Expected a curl command for /api/admin/ in the loot file")
+	}
+}
+
+func TestALBWildcardIntersection(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		example string
+		ok      bool
+	}{
+		{"/api/*", "/api/admin/*", "/api/admin/", true},
+		{"*", "/login", "/login", true},
+		{"/img/*.png", "/img/logo.?ng", "/img/logo.png", true},
+		{"/static/*", "/api/*", "", false},
+		{"/api", "/api/*", "", false},
+		{"/a?c", "/ab*", "/abc", true},
+	}
+	for _, test := range tests {
+		example, ok := albWildcardIntersection(test.a, test.b)
+		if example != test.example || ok != test.ok {
+			t.Errorf("%s and %s: expected %q %v, got %q %v", test.a, test.b, test.example, test.ok, example, ok)
+		}
+	}
+}
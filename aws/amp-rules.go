@@ -0,0 +1,495 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+type AMPRulesModule struct {
+	// General configuration data
+	AMPClient             sdk.AMPClientInterface
+	PrometheusRulesClient sdk.PrometheusRulesClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Workspaces     []AMPWorkspace
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type AMPWorkspace struct {
+	Region         string
+	ID             string
+	Alias          string
+	RuleGroups     int
+	AlertingRules  int
+	RecordingRules int
+	SecurityRules  []AMPSecurityRule
+	// Security categories without an alerting rule
+	MissingCoverage []string
+	AlertManager    string
+	Receivers       []string
+	// Set when the rules couldn't be read, in which case nothing is known about coverage
+	RulesError string
+	Findings   []string
+}
+
+type AMPSecurityRule struct {
+	Group    string
+	Name     string
+	Type     string
+	Category string
+	Query    string
+}
+
+type ampSecurityRuleCategory struct {
+	name     string
+	keywords []string
+}
+
+// What a security team would be expected to alert on. A rule belongs to a category when one of the keywords shows
+// up in its name, query or annotations.
+var ampSecurityRuleCategories = []ampSecurityRuleCategory{
+	{"Unauthorized access", []string{"unauthorized", "unauthorised", "access_denied", "accessdenied", "forbidden", "permission_denied"}},
+	{"Failed logins", []string{"failed_login", "failedlogin", "login_fail", "loginfail", "authentication_fail", "auth_fail", "brute"}},
+	{"Suspicious network", []string{"suspicious_network", "suspiciousnetwork", "port_scan", "portscan", "intrusion", "network_anomaly"}},
+}
+
+const (
+	ampAlertManagerNotConfigured = "Not configured"
+	ampFindingAlertsNotDelivered = "Alerts are not delivered anywhere"
+)
+
+func (m *AMPRulesModule) PrintAMPRules(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "amp-rules"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating Managed Prometheus rules and alert managers for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan AMPWorkspace)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.Workspaces, func(i, j int) bool {
+		if m.Workspaces[i].Region != m.Workspaces[j].Region {
+			return m.Workspaces[i].Region < m.Workspaces[j].Region
+		}
+		return m.Workspaces[i].Alias < m.Workspaces[j].Alias
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Workspace",
+		"Workspace ID",
+		"Rule Groups",
+		"Alerting Rules",
+		"Recording Rules",
+		"Security Rules",
+		"Alert Manager",
+		"Missing Coverage",
+		"Finding",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Workspace",
+			"Workspace ID",
+			"Rule Groups",
+			"Alerting Rules",
+			"Recording Rules",
+			"Security Rules",
+			"Alert Manager",
+			"Missing Coverage",
+			"Finding",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Workspace",
+			"Alerting Rules",
+			"Security Rules",
+			"Alert Manager",
+			"Missing Coverage",
+			"Finding",
+		}
+	}
+
+	// Table rows
+	for _, workspace := range m.Workspaces {
+		var securityRules []string
+		for _, rule := range workspace.SecurityRules {
+			securityRules = append(securityRules, rule.Name)
+		}
+		ruleGroups, alertingRules, recordingRules := strconv.Itoa(workspace.RuleGroups), strconv.Itoa(workspace.AlertingRules), strconv.Itoa(workspace.RecordingRules)
+		if workspace.RulesError != "" {
+			ruleGroups, alertingRules, recordingRules = "Unknown", "Unknown", "Unknown"
+		}
+		finding := strings.Join(workspace.Findings, "; ")
+		if finding != "" {
+			finding = magenta(finding)
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				workspace.Region,
+				workspace.Alias,
+				workspace.ID,
+				ruleGroups,
+				alertingRules,
+				recordingRules,
+				strings.Join(securityRules, ", "),
+				workspace.AlertManager,
+				strings.Join(workspace.MissingCoverage, ", "),
+				finding,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		if securityRulesBody := m.securityRulesTableBody(); len(securityRulesBody) > 0 {
+			o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+				Header: []string{
+					"Region",
+					"Workspace",
+					"Rule Group",
+					"Rule",
+					"Type",
+					"Category",
+					"Query",
+				},
+				Body: securityRulesBody,
+				Name: "amp-security-rules",
+			})
+		}
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s Managed Prometheus workspaces found (%d with detection gaps).\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)), m.countWorkspacesWithGaps())
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No Managed Prometheus workspaces found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *AMPRulesModule) securityRulesTableBody() [][]string {
+	var body [][]string
+	for _, workspace := range m.Workspaces {
+		for _, rule := range workspace.SecurityRules {
+			body = append(body, []string{
+				workspace.Region,
+				workspace.Alias,
+				rule.Group,
+				rule.Name,
+				rule.Type,
+				rule.Category,
+				rule.Query,
+			})
+		}
+	}
+	return body
+}
+
+func (m *AMPRulesModule) countWorkspacesWithGaps() int {
+	var count int
+	for _, workspace := range m.Workspaces {
+		if len(workspace.MissingCoverage) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func (m *AMPRulesModule) Receiver(receiver chan AMPWorkspace, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Workspaces = append(m.Workspaces, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *AMPRulesModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan AMPWorkspace) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("aps", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getWorkspacesPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *AMPRulesModule) getWorkspacesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan AMPWorkspace) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	workspaces, err := sdk.CachedAMPListWorkspaces(m.AMPClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, summary := range workspaces {
+		workspace := AMPWorkspace{
+			Region: r,
+			ID:     aws.ToString(summary.WorkspaceId),
+			Alias:  aws.ToString(summary.Alias),
+		}
+		if workspace.Alias == "" {
+			workspace.Alias = workspace.ID
+		}
+
+		var ruleGroups []sdk.PrometheusRuleGroup
+		description, rulesErr := sdk.CachedAMPDescribeWorkspace(m.AMPClient, aws.ToString(m.Caller.Account), r, workspace.ID)
+		if rulesErr == nil {
+			ruleGroups, rulesErr = sdk.CachedPrometheusListRules(m.PrometheusRulesClient, aws.ToString(m.Caller.Account), r, workspace.ID, aws.ToString(description.PrometheusEndpoint))
+		}
+		if rulesErr != nil {
+			m.modLog.Error(rulesErr.Error())
+			m.CommandCounter.Error++
+			workspace.RulesError = rulesErr.Error()
+		} else {
+			analyzeAMPRuleGroups(&workspace, ruleGroups)
+		}
+
+		definition, err := sdk.CachedAMPDescribeAlertManagerDefinition(m.AMPClient, aws.ToString(m.Caller.Account), r, workspace.ID)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			workspace.AlertManager = "Unknown"
+		} else {
+			var deliverable bool
+			workspace.Receivers, deliverable = parseAlertManagerReceivers(definition)
+			if definition == "" {
+				workspace.AlertManager = ampAlertManagerNotConfigured
+			} else {
+				workspace.AlertManager = strings.Join(workspace.Receivers, ", ")
+			}
+			if workspace.AlertingRules > 0 && !deliverable {
+				workspace.Findings = append(workspace.Findings, ampFindingAlertsNotDelivered)
+			}
+		}
+
+		if rulesErr != nil {
+			var apiErr *sdk.PrometheusAPIError
+			if errors.As(rulesErr, &apiErr) {
+				workspace.Findings = append(workspace.Findings, fmt.Sprintf("Rules not readable (HTTP %d)", apiErr.StatusCode))
+			} else {
+				workspace.Findings = append(workspace.Findings, "Rules not readable")
+			}
+		} else if len(workspace.MissingCoverage) > 0 {
+			workspace.Findings = append([]string{"Detection gap"}, workspace.Findings...)
+		}
+		dataReceiver <- workspace
+	}
+}
+
+// analyzeAMPRuleGroups counts a workspace's rules, picks out the security related ones and works out which security
+// categories have no alerting rule
+func analyzeAMPRuleGroups(workspace *AMPWorkspace, ruleGroups []sdk.PrometheusRuleGroup) {
+	covered := make(map[string]bool)
+	workspace.RuleGroups = len(ruleGroups)
+	for _, group := range ruleGroups {
+		for _, rule := range group.Rules {
+			if rule.Type == "alerting" {
+				workspace.AlertingRules++
+			} else {
+				workspace.RecordingRules++
+			}
+			category := ampRuleSecurityCategory(rule)
+			if category == "" {
+				continue
+			}
+			workspace.SecurityRules = append(workspace.SecurityRules, AMPSecurityRule{
+				Group:    group.Name,
+				Name:     rule.Name,
+				Type:     rule.Type,
+				Category: category,
+				Query:    rule.Query,
+			})
+			// A recording rule only computes a series. Nobody hears about it unless an alert uses it.
+			if rule.Type == "alerting" {
+				covered[category] = true
+			}
+		}
+	}
+
+	for _, category := range ampSecurityRuleCategories {
+		if !covered[category.name] {
+			workspace.MissingCoverage = append(workspace.MissingCoverage, category.name)
+		}
+	}
+}
+
+// ampRuleSecurityCategory returns the first security category a rule falls into, or "" if it isn't security related
+func ampRuleSecurityCategory(rule sdk.PrometheusRule) string {
+	text := []string{rule.Name, rule.Query}
+	for _, annotation := range rule.Annotations {
+		text = append(text, annotation)
+	}
+	haystack := strings.ToLower(strings.Join(text, " "))
+	for _, category := range ampSecurityRuleCategories {
+		for _, keyword := range category.keywords {
+			if strings.Contains(haystack, keyword) {
+				return category.name
+			}
+		}
+	}
+	return ""
+}
+
+type ampAlertManagerDefinition struct {
+	AlertmanagerConfig string `yaml:"alertmanager_config"`
+}
+
+type alertmanagerConfig struct {
+	Receivers []map[string]interface{} `yaml:"receivers"`
+}
+
+// parseAlertManagerReceivers returns the receivers of an AMP alert manager definition with the integrations they use,
+// and whether any of them actually sends alerts somewhere. A receiver without integrations drops everything routed
+// to it.
+func parseAlertManagerReceivers(definition string) ([]string, bool) {
+	var receivers []string
+	var deliverable bool
+	if definition == "" {
+		return receivers, deliverable
+	}
+
+	var wrapper ampAlertManagerDefinition
+	if err := yaml.Unmarshal([]byte(definition), &wrapper); err != nil {
+		return receivers, deliverable
+	}
+	var config alertmanagerConfig
+	if err := yaml.Unmarshal([]byte(wrapper.AlertmanagerConfig), &config); err != nil {
+		return receivers, deliverable
+	}
+
+	for _, receiver := range config.Receivers {
+		name, _ := receiver["name"].(string)
+		var integrations []string
+		for key, value := range receiver {
+			configs, ok := value.([]interface{})
+			if ok && strings.HasSuffix(key, "_configs") && len(configs) > 0 {
+				integrations = append(integrations, strings.TrimSuffix(key, "_configs"))
+			}
+		}
+		if len(integrations) == 0 {
+			receivers = append(receivers, fmt.Sprintf("%s (no integrations)", name))
+			continue
+		}
+		deliverable = true
+		sort.Strings(integrations)
+		receivers = append(receivers, fmt.Sprintf("%s (%s)", name, strings.Join(integrations, ", ")))
+	}
+	return receivers, deliverable
+}
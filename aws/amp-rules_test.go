@@ -0,0 +1,71 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+func TestAMPRules(t *testing.T) {
+	m := AMPRulesModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:            3,
+		AMPClient:             &sdk.MockedAMPClient{},
+		PrometheusRulesClient: &sdk.MockedPrometheusRulesClient{},
+	}
+
+	internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintAMPRules(".", 2)
+
+	type summary struct {
+		alias           string
+		alertingRules   int
+		securityRules   int
+		missingCoverage []string
+		alertManager    string
+		findings        []string
+	}
+	expected := []summary{
+		{"infra", 1, 0, []string{"Unauthorized access", "Failed logins", "Suspicious network"}, ampAlertManagerNotConfigured, []string{"Detection gap", ampFindingAlertsNotDelivered}},
+		{"payments", 0, 0, nil, ampAlertManagerNotConfigured, []string{"Rules not readable (HTTP 403)"}},
+		{"security-monitoring", 2, 2, []string{"Suspicious network"}, "security-team (sns), blackhole (no integrations)", []string{"Detection gap"}},
+	}
+	var got []summary
+	for _, workspace := range m.Workspaces {
+		got = append(got, summary{workspace.Alias, workspace.AlertingRules, len(workspace.SecurityRules), workspace.MissingCoverage, workspace.AlertManager, workspace.Findings})
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestAMPRuleSecurityCategory(t *testing.T) {
+	subtests := map[string]struct {
+		rule     sdk.PrometheusRule
+		expected string
+	}{
+		"name":       {sdk.PrometheusRule{Name: "SSHBruteForce", Query: "sum(rate(sshd_auth_total[5m])) > 50"}, "Failed logins"},
+		"query":      {sdk.PrometheusRule{Name: "ApiErrors", Query: `sum(rate(http_requests_total{code="403"}[5m])) / sum(rate(http_requests_total[5m])) > 0.1 or forbidden_total > 0`}, "Unauthorized access"},
+		"annotation": {sdk.PrometheusRule{Name: "VPCFlowAlert", Query: "flow_rejects > 100", Annotations: map[string]string{"summary": "Possible intrusion from a single source"}}, "Suspicious network"},
+		"portscan":   {sdk.PrometheusRule{Name: "PortScanDetected", Query: "flow_rejects > 100"}, "Suspicious network"},
+		"unrelated":  {sdk.PrometheusRule{Name: "HighMemory", Query: "node_memory_utilisation > 0.9"}, ""},
+	}
+	for name, subtest := range subtests {
+		t.Run(name, func(t *testing.T) {
+			if category := ampRuleSecurityCategory(subtest.rule); category != subtest.expected {
+				t.Errorf("Expected %q, got %q", subtest.expected, category)
+			}
+		})
+	}
+}
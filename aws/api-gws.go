@@ -129,6 +129,8 @@ func (m *ApiGwModule) PrintApiGws(outputDirectory string, verbosity int) {
 		o := internal.OutputClient{
 			Verbosity:     verbosity,
 			CallingModule: m.output.CallingModule,
+			AccountID:     aws.ToString(m.Caller.Account),
+			BaseDirectory: outputDirectory,
 			Table: internal.TableClient{
 				Wrap:          m.WrapTable,
 				DirectoryName: filepath,
@@ -243,13 +245,7 @@ func (m *ApiGwModule) writeLoot(outputDirectory string, verbosity int) string {
 	// 	panic(err.Error())
 	// }
 
-	if verbosity > 2 {
-		fmt.Println()
-		fmt.Printf("[%s][%s] %s \n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("Send these requests through your favorite interception proxy"))
-		fmt.Print(out)
-		fmt.Printf("[%s][%s] %s \n\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("End of loot file."))
-	}
-
+	// WriteFullOutput prints the loot to the terminal, see internal.ShowLoot
 	fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), f)
 
 	return out
@@ -0,0 +1,419 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	rdsTypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type AuroraGlobalModule struct {
+	// General configuration data
+	RDSClient        sdk.RDSClientInterface
+	CloudWatchClient sdk.CloudWatchClientInterface
+	IAMClient        sdk.AWSIAMClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	GlobalClusters []AuroraGlobalCluster
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type AuroraGlobalCluster struct {
+	Identifier     string
+	Arn            string
+	Engine         string
+	Status         string
+	Encrypted      string
+	PrimaryCluster string
+	PrimaryRegion  string
+	PrimaryAccount string
+	Secondaries    []AuroraGlobalMember
+	PromotionRoles []string
+	Finding        string
+}
+
+// AuroraGlobalMember is a secondary (read-only) cluster of a global database
+type AuroraGlobalMember struct {
+	Cluster string
+	Region  string
+	Account string
+	// Replication lag in milliseconds, or -1 if there are no datapoints
+	ReplicationLag float64
+}
+
+const auroraGlobalCrossAccountSecondary = "Cross-account secondary cluster"
+
+// Actions that let a principal turn a secondary cluster into a writable primary
+var auroraGlobalPromotionActions = []string{
+	"rds:FailoverGlobalCluster",
+	"rds:SwitchoverGlobalCluster",
+	"rds:RemoveFromGlobalCluster",
+}
+
+// Look at the last 15 minutes of the replication lag metric
+const auroraGlobalReplicationLagPeriod = 15 * time.Minute
+
+func (m *AuroraGlobalModule) PrintAuroraGlobalClusters(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "aurora-global"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating Aurora global databases for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan rdsTypes.GlobalCluster)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	// Global clusters are returned by every region, so the lag and promotion checks only run once the duplicates
+	// have been dropped
+	for i := range m.GlobalClusters {
+		m.addReplicationLag(&m.GlobalClusters[i])
+		m.GlobalClusters[i].PromotionRoles = m.getPromotionRoles(m.GlobalClusters[i].Arn)
+	}
+
+	sort.Slice(m.GlobalClusters, func(i, j int) bool {
+		return m.GlobalClusters[i].Identifier < m.GlobalClusters[j].Identifier
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Global Cluster",
+		"Engine",
+		"Status",
+		"Encrypted",
+		"Primary Cluster",
+		"Primary Region",
+		"Secondary Regions",
+		"Secondary Clusters",
+		"Replication Lag",
+		"Cross-Account Secondaries",
+		"Promotion Roles",
+		"Finding",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Global Cluster",
+			"Engine",
+			"Status",
+			"Encrypted",
+			"Primary Cluster",
+			"Primary Region",
+			"Secondary Regions",
+			"Secondary Clusters",
+			"Replication Lag",
+			"Cross-Account Secondaries",
+			"Promotion Roles",
+			"Finding",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Global Cluster",
+			"Primary Region",
+			"Secondary Regions",
+			"Replication Lag",
+			"Cross-Account Secondaries",
+			"Promotion Roles",
+			"Finding",
+		}
+	}
+
+	// Table rows
+	for _, globalCluster := range m.GlobalClusters {
+		var secondaryRegions, secondaryClusters, replicationLag, crossAccount []string
+		for _, secondary := range globalCluster.Secondaries {
+			if !internal.Contains(secondary.Region, secondaryRegions) {
+				secondaryRegions = append(secondaryRegions, secondary.Region)
+			}
+			secondaryClusters = append(secondaryClusters, secondary.Cluster)
+			if secondary.ReplicationLag >= 0 {
+				replicationLag = append(replicationLag, fmt.Sprintf("%s: %s ms", secondary.Cluster, strconv.FormatFloat(secondary.ReplicationLag, 'f', -1, 64)))
+			}
+			if secondary.Account != globalCluster.PrimaryAccount {
+				crossAccount = append(crossAccount, fmt.Sprintf("%s (%s)", secondary.Cluster, secondary.Account))
+			}
+		}
+		finding := globalCluster.Finding
+		if finding != "" {
+			finding = magenta(finding)
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				globalCluster.Identifier,
+				globalCluster.Engine,
+				globalCluster.Status,
+				globalCluster.Encrypted,
+				globalCluster.PrimaryCluster,
+				globalCluster.PrimaryRegion,
+				strings.Join(secondaryRegions, ", "),
+				strings.Join(secondaryClusters, ", "),
+				strings.Join(replicationLag, ", "),
+				strings.Join(crossAccount, ", "),
+				strings.Join(globalCluster.PromotionRoles, ", "),
+				finding,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s Aurora global databases found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No Aurora global databases found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *AuroraGlobalModule) Receiver(receiver chan rdsTypes.GlobalCluster, receiverDone chan bool) {
+	defer close(receiverDone)
+	seen := make(map[string]bool)
+	for {
+		select {
+		case data := <-receiver:
+			if seen[aws.ToString(data.GlobalClusterArn)] {
+				continue
+			}
+			seen[aws.ToString(data.GlobalClusterArn)] = true
+			m.GlobalClusters = append(m.GlobalClusters, auroraGlobalClusterFromAPI(data, aws.ToString(m.Caller.Account)))
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *AuroraGlobalModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan rdsTypes.GlobalCluster) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("rds", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getGlobalClustersPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *AuroraGlobalModule) getGlobalClustersPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan rdsTypes.GlobalCluster) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	globalClusters, err := sdk.CachedRDSDescribeGlobalClusters(m.RDSClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, globalCluster := range globalClusters {
+		dataReceiver <- globalCluster
+	}
+}
+
+// auroraGlobalClusterFromAPI splits the members of a global cluster into the writer and its secondaries
+func auroraGlobalClusterFromAPI(globalCluster rdsTypes.GlobalCluster, callerAccount string) AuroraGlobalCluster {
+	g := AuroraGlobalCluster{
+		Identifier: aws.ToString(globalCluster.GlobalClusterIdentifier),
+		Arn:        aws.ToString(globalCluster.GlobalClusterArn),
+		Engine:     aws.ToString(globalCluster.Engine),
+		Status:     aws.ToString(globalCluster.Status),
+		Encrypted:  "False",
+	}
+	if aws.ToBool(globalCluster.StorageEncrypted) {
+		g.Encrypted = "True"
+	}
+
+	for _, member := range globalCluster.GlobalClusterMembers {
+		cluster, region, account := auroraClusterLocation(aws.ToString(member.DBClusterArn))
+		if aws.ToBool(member.IsWriter) {
+			g.PrimaryCluster, g.PrimaryRegion, g.PrimaryAccount = cluster, region, account
+			continue
+		}
+		g.Secondaries = append(g.Secondaries, AuroraGlobalMember{
+			Cluster:        cluster,
+			Region:         region,
+			Account:        account,
+			ReplicationLag: -1,
+		})
+	}
+
+	// A global cluster that is being failed over has no writer for a moment. Fall back to the caller's account so
+	// secondaries aren't all reported as cross-account.
+	owner := g.PrimaryAccount
+	if owner == "" {
+		owner = callerAccount
+	}
+	for _, secondary := range g.Secondaries {
+		if secondary.Account != owner {
+			g.Finding = auroraGlobalCrossAccountSecondary
+			break
+		}
+	}
+	return g
+}
+
+// auroraClusterLocation returns the name, region and account of an Aurora cluster ARN
+func auroraClusterLocation(clusterArn string) (string, string, string) {
+	parsedArn, err := arn.Parse(clusterArn)
+	if err != nil {
+		return clusterArn, "Unknown", "Unknown"
+	}
+	// cluster:<name>
+	name := strings.TrimPrefix(parsedArn.Resource, "cluster:")
+	return name, parsedArn.Region, parsedArn.AccountID
+}
+
+// addReplicationLag looks up the highest recent replication lag of each secondary. CloudWatch metrics of clusters in
+// other accounts aren't visible to the caller, so those are skipped.
+func (m *AuroraGlobalModule) addReplicationLag(globalCluster *AuroraGlobalCluster) {
+	for i, secondary := range globalCluster.Secondaries {
+		if secondary.Account != aws.ToString(m.Caller.Account) {
+			continue
+		}
+		datapoints, err := sdk.CachedCloudWatchGetMetricMaximum(m.CloudWatchClient, aws.ToString(m.Caller.Account), secondary.Region, "AWS/RDS", "AuroraGlobalDBReplicationLag", "DBClusterIdentifier", secondary.Cluster, auroraGlobalReplicationLagPeriod)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		for _, datapoint := range datapoints {
+			if aws.ToFloat64(datapoint.Maximum) > globalCluster.Secondaries[i].ReplicationLag {
+				globalCluster.Secondaries[i].ReplicationLag = aws.ToFloat64(datapoint.Maximum)
+			}
+		}
+	}
+}
+
+// getPromotionRoles returns the roles in the account that are allowed to promote a secondary cluster of the global
+// database, by failing over, switching over or detaching it
+func (m *AuroraGlobalModule) getPromotionRoles(globalClusterArn string) []string {
+	var promotionRoles []string
+	roles, err := sdk.CachedIamListRoles(m.IAMClient, aws.ToString(m.Caller.Account))
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return promotionRoles
+	}
+
+	for _, role := range roles {
+		evaluationResults, err := sdk.CachedIamSimulatePrincipalPolicy(m.IAMClient, aws.ToString(m.Caller.Account), role.Arn, auroraGlobalPromotionActions, []string{globalClusterArn})
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		for _, result := range evaluationResults {
+			if result.EvalDecision == iamTypes.PolicyEvaluationDecisionTypeAllowed {
+				promotionRoles = append(promotionRoles, aws.ToString(role.RoleName))
+				break
+			}
+		}
+	}
+	sort.Strings(promotionRoles)
+	return promotionRoles
+}
@@ -0,0 +1,83 @@
+package aws
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// mockedAuroraGlobalIAMClient only lets role2 fail over the orders global database
+type mockedAuroraGlobalIAMClient struct {
+	sdk.MockedIAMClient
+}
+
+func (c *mockedAuroraGlobalIAMClient) SimulatePrincipalPolicy(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error) {
+	var results []iamTypes.EvaluationResult
+	for _, action := range params.ActionNames {
+		decision := iamTypes.PolicyEvaluationDecisionTypeImplicitDeny
+		if strings.HasSuffix(aws.ToString(params.PolicySourceArn), "role/role2") && action == "rds:FailoverGlobalCluster" && strings.HasSuffix(params.ResourceArns[0], "orders-global") {
+			decision = iamTypes.PolicyEvaluationDecisionTypeAllowed
+		}
+		results = append(results, iamTypes.EvaluationResult{
+			EvalActionName: aws.String(action),
+			EvalDecision:   decision,
+		})
+	}
+	return &iam.SimulatePrincipalPolicyOutput{EvaluationResults: results}, nil
+}
+
+func TestAuroraGlobalClusters(t *testing.T) {
+	m := AuroraGlobalModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1", "us-west-2"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:       3,
+		RDSClient:        &sdk.MockedRDSClient{},
+		CloudWatchClient: &sdk.MockedCloudWatchClient{},
+		IAMClient:        &mockedAuroraGlobalIAMClient{},
+	}
+
+	internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintAuroraGlobalClusters(".", 2)
+
+	// Both regions return the same global clusters
+	if len(m.GlobalClusters) != 2 {
+		t.Fatalf("Expected 2 global clusters, got %d", len(m.GlobalClusters))
+	}
+
+	inventory := m.GlobalClusters[0]
+	if inventory.Identifier != "inventory-global" || len(inventory.Secondaries) != 0 || inventory.Finding != "" || len(inventory.PromotionRoles) != 0 {
+		t.Errorf("Unexpected inventory global cluster %+v", inventory)
+	}
+
+	orders := m.GlobalClusters[1]
+	if orders.PrimaryCluster != "orders-primary" || orders.PrimaryRegion != "us-east-1" || orders.PrimaryAccount != "123456789012" {
+		t.Errorf("Unexpected primary %s in %s (%s)", orders.PrimaryCluster, orders.PrimaryRegion, orders.PrimaryAccount)
+	}
+	expectedSecondaries := []AuroraGlobalMember{
+		{Cluster: "orders-secondary", Region: "us-west-2", Account: "123456789012", ReplicationLag: 1250},
+		{Cluster: "orders-partner", Region: "eu-west-1", Account: "210987654321", ReplicationLag: -1},
+	}
+	if !reflect.DeepEqual(orders.Secondaries, expectedSecondaries) {
+		t.Errorf("Expected secondaries %+v, got %+v", expectedSecondaries, orders.Secondaries)
+	}
+	if orders.Finding != auroraGlobalCrossAccountSecondary {
+		t.Errorf("Expected finding %q, got %q", auroraGlobalCrossAccountSecondary, orders.Finding)
+	}
+	if !reflect.DeepEqual(orders.PromotionRoles, []string{"role2"}) {
+		t.Errorf("Expected role2 to be able to promote a secondary, got %v", orders.PromotionRoles)
+	}
+}
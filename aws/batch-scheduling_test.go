@@ -0,0 +1,92 @@
+package aws
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestBatchScheduling(t *testing.T) {
+
+	m := BatchSchedulingModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:  3,
+		WrapTable:   false,
+		BatchClient: &sdk.MockedBatchClient{},
+		IAMClient:   &sdk.MockedIAMClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+	tmpDir := "."
+
+	m.PrintBatchScheduling(tmpDir, 2)
+
+	if len(m.Entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(m.Entries))
+	}
+
+	for _, entry := range m.Entries {
+		switch entry.JobQueue {
+		case "queue1":
+			if entry.SchedulingPolicy != "fairshare1" || len(entry.Shares) != 2 {
+				t.Errorf("Expected queue1 to use the fairshare1 policy with 2 shares, got %s %v", entry.SchedulingPolicy, entry.Shares)
+			}
+			if entry.OverPrivileged != "Yes" {
+				t.Errorf("Expected the service role of ce1 to be flagged as over-privileged")
+			}
+		case "queue2":
+			if entry.SchedulingPolicy != "None (FIFO)" {
+				t.Errorf("Expected queue2 to have no scheduling policy, got %s", entry.SchedulingPolicy)
+			}
+			if entry.OverPrivileged != "No" {
+				t.Errorf("Expected the service-linked role of ce2 not to be flagged")
+			}
+			if entry.Platform != "FARGATE" {
+				t.Errorf("Expected ce2 to run on FARGATE, got %s", entry.Platform)
+			}
+		case "":
+			if entry.SchedulingPolicy != "unused" {
+				t.Errorf("Expected the unattached scheduling policy to be listed, got %s", entry.SchedulingPolicy)
+			}
+		}
+	}
+
+	lootFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/loot/batch-service-roles.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	if !strings.Contains(string(lootFile), "iam list-attached-role-policies --role-name BatchServiceRole") {
+		t.Errorf("Expected BatchServiceRole to be in the loot file")
+	}
+	if strings.Contains(string(lootFile), "AWSServiceRoleForBatch") {
+		t.Errorf("Did not expect the service-linked role to be in the loot file")
+	}
+
+	fargateLootFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/loot/batch-fargate-jobs.txt")
+	fargateLootFile, err := afero.ReadFile(fs, fargateLootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", fargateLootFilePath, err)
+	}
+	if !strings.Contains(string(fargateLootFile), "batch register-job-definition --job-definition-name cloudfox-fargate-job --type container --platform-capabilities FARGATE") {
+		t.Errorf("Expected a Fargate job definition in the loot file")
+	}
+	if !strings.Contains(string(fargateLootFile), "batch submit-job --job-name cloudfox-fargate-job --job-queue queue2 ") {
+		t.Errorf("Expected a job submission to the Fargate queue2 in the loot file")
+	}
+	if strings.Contains(string(fargateLootFile), "queue1") {
+		t.Errorf("Did not expect queue1, which runs on EC2, in the Fargate loot file")
+	}
+}
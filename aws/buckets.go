@@ -175,6 +175,8 @@ func (m *BucketsModule) PrintBuckets(outputDirectory string, verbosity int) {
 		})
 		o.PrefixIdentifier = m.AWSProfile
 		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
 		o.WriteFullOutput(o.Table.TableFiles, nil)
 		m.writeLoot(o.Table.DirectoryName, verbosity, m.AWSProfile)
 
@@ -243,7 +245,7 @@ func (m *BucketsModule) writeLoot(outputDirectory string, verbosity int, profile
 		m.modLog.Error(err.Error())
 	}
 
-	if verbosity > 2 {
+	if internal.ShowLoot(verbosity) {
 		fmt.Println()
 		fmt.Printf("[%s][%s] %s \n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("Use the commands below to manually inspect certain buckets of interest."))
 		fmt.Print(out)
@@ -321,8 +323,8 @@ func (m *BucketsModule) createBucketsRows(verbosity int, wg *sync.WaitGroup, sem
 	}
 }
 
-func (m *BucketsModule) isPublicAccessBlocked(bucketName string, r string) bool {
-	publicAccessBlock, err := sdk.CachedGetPublicAccessBlock(m.S3Client, aws.ToString(m.Caller.Account), r, bucketName)
+func isBucketPublicAccessBlocked(S3Client sdk.AWSS3ClientInterface, accountID string, r string, bucketName string) bool {
+	publicAccessBlock, err := sdk.CachedGetPublicAccessBlock(S3Client, accountID, r, bucketName)
 	if err != nil {
 		return false
 	}
@@ -330,10 +332,37 @@ func (m *BucketsModule) isPublicAccessBlocked(bucketName string, r string) bool
 
 }
 
+// isBucketPolicyPublic is the check behind the Public? column: the policy lets everyone in without a condition and the
+// public access block doesn't stop it
+func isBucketPolicyPublic(S3Client sdk.AWSS3ClientInterface, accountID string, r string, bucketName string, bucketPolicy policy.Policy) bool {
+	return bucketPolicy.IsPublic() && !bucketPolicy.IsConditionallyPublic() && !isBucketPublicAccessBlocked(S3Client, accountID, r, bucketName)
+}
+
+// isBucketPublic looks up where a bucket lives and whether its policy makes it public. A bucket without a policy is not
+// public.
+func isBucketPublic(S3Client sdk.AWSS3ClientInterface, accountID string, bucketName string) (bool, error) {
+	region, err := sdk.CachedGetBucketLocation(S3Client, accountID, bucketName)
+	if err != nil {
+		return false, err
+	}
+	policyJSON, err := sdk.CachedGetBucketPolicy(S3Client, accountID, region, bucketName)
+	if err != nil {
+		if isNoResourcePolicyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	bucketPolicy, err := policy.ParseJSONPolicy([]byte(policyJSON))
+	if err != nil {
+		return false, fmt.Errorf("parsing bucket access policy (%s) as JSON: %s", bucketName, err)
+	}
+	return isBucketPolicyPublic(S3Client, accountID, region, bucketName, bucketPolicy), nil
+}
+
 func (m *BucketsModule) analyseBucketPolicy(bucket *BucketRow, dataReceiver chan BucketRow) {
 	m.storeAccessPolicy(bucket)
 
-	if bucket.Policy.IsPublic() && !bucket.Policy.IsConditionallyPublic() && !m.isPublicAccessBlocked(bucket.Name, bucket.Region) {
+	if isBucketPolicyPublic(m.S3Client, aws.ToString(m.Caller.Account), bucket.Region, bucket.Name, bucket.Policy) {
 		bucket.IsPublic = "YES"
 	}
 
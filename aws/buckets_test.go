@@ -72,6 +72,30 @@ func (m *MockedS3Client) GetPublicAccessBlock(ctx context.Context, params *s3.Ge
 	return output, nil
 }
 
+func (m *MockedS3Client) GetBucketWebsite(ctx context.Context, params *s3.GetBucketWebsiteInput, optFns ...func(*s3.Options)) (*s3.GetBucketWebsiteOutput, error) {
+	output := &s3.GetBucketWebsiteOutput{
+		IndexDocument: &types.IndexDocument{
+			Suffix: aws.String("index.html"),
+		},
+	}
+	return output, nil
+}
+
+func (m *MockedS3Client) GetBucketEncryption(ctx context.Context, params *s3.GetBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error) {
+	output := &s3.GetBucketEncryptionOutput{
+		ServerSideEncryptionConfiguration: &types.ServerSideEncryptionConfiguration{
+			Rules: []types.ServerSideEncryptionRule{
+				{
+					ApplyServerSideEncryptionByDefault: &types.ServerSideEncryptionByDefault{
+						SSEAlgorithm: types.ServerSideEncryptionAes256,
+					},
+				},
+			},
+		},
+	}
+	return output, nil
+}
+
 func TestListBuckets(t *testing.T) {
 
 	m := BucketsModule{
@@ -77,6 +77,8 @@ func (m *CapeCommand) RunCapeCommand() {
 
 	o.PrefixIdentifier = m.AWSProfile
 	o.Table.DirectoryName = filepath.Join(m.AWSOutputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+	o.AccountID = aws.ToString(m.Caller.Account)
+	o.BaseDirectory = m.AWSOutputDirectory
 
 	// Table #1: Inbound Privilege Escalation Paths
 	fmt.Printf("[%s][%s] Printing inbound privesc paths for account: %s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
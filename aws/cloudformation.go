@@ -181,6 +181,8 @@ func (m *CloudformationModule) PrintCloudformationStacks(outputDirectory string,
 		})
 		o.PrefixIdentifier = m.AWSProfile
 		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
 		o.WriteFullOutput(o.Table.TableFiles, nil)
 		m.writeLoot(o.Table.DirectoryName, verbosity)
 		fmt.Printf("[%s][%s] %s cloudformation stacks found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
@@ -269,7 +271,7 @@ func (m *CloudformationModule) writeLoot(outputDirectory string, verbosity int)
 		m.CommandCounter.Error++
 	}
 
-	if verbosity > 2 {
+	if internal.ShowLoot(verbosity) {
 		fmt.Println()
 		fmt.Printf("[%s][%s] %s \n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("Look for secrets. Use something like trufflehog"))
 		fmt.Print(out)
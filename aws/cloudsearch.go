@@ -0,0 +1,422 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/aws/policy"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type CloudSearchModule struct {
+	// General configuration data
+	CloudSearchClient sdk.CloudSearchClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Domains        []CloudSearchDomain
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type CloudSearchDomain struct {
+	Region         string
+	Name           string
+	Arn            string
+	DocEndpoint    string
+	SearchEndpoint string
+	EnforceHTTPS   bool
+	// PublicActions are granted to everyone without a condition, ConditionalActions to everyone under some condition,
+	// like a source IP range
+	PublicActions      []string
+	ConditionalActions []string
+	Findings           []string
+}
+
+const (
+	cloudSearchHTTPDocEndpoint    = "Document endpoint accepts HTTP"
+	cloudSearchAnonymousSearch    = "Anonymous search"
+	cloudSearchAnonymousSuggest   = "Anonymous suggestions"
+	cloudSearchAnonymousDocuments = "Anonymous document uploads"
+	cloudSearchConditionalAccess  = "Public access restricted by a condition"
+)
+
+// cloudSearchActions are the domain actions the access policy can grant, in the order they are reported
+var cloudSearchActions = []string{"cloudsearch:search", "cloudsearch:suggest", "cloudsearch:document"}
+
+func (m *CloudSearchModule) PrintCloudSearch(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "cloudsearch"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating CloudSearch domains for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan CloudSearchDomain)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.Domains, func(i, j int) bool {
+		if m.Domains[i].Region != m.Domains[j].Region {
+			return m.Domains[i].Region < m.Domains[j].Region
+		}
+		return m.Domains[i].Name < m.Domains[j].Name
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Domain",
+		"Search Endpoint",
+		"Document Endpoint",
+		"HTTPS Enforced",
+		"Public Actions",
+		"Conditional Actions",
+		"Findings",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Domain",
+			"Search Endpoint",
+			"Document Endpoint",
+			"HTTPS Enforced",
+			"Public Actions",
+			"Conditional Actions",
+			"Findings",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Domain",
+			"Search Endpoint",
+			"HTTPS Enforced",
+			"Public Actions",
+			"Findings",
+		}
+	}
+
+	// Table rows
+	for _, domain := range m.Domains {
+		var findings []string
+		for _, finding := range domain.Findings {
+			findings = append(findings, magenta(finding))
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				domain.Region,
+				domain.Name,
+				domain.SearchEndpoint,
+				domain.DocEndpoint,
+				fmt.Sprint(domain.EnforceHTTPS),
+				strings.Join(domain.PublicActions, ", "),
+				strings.Join(domain.ConditionalActions, ", "),
+				strings.Join(findings, "\n"),
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     m.output.CallingModule,
+			Contents: m.writeLoot(),
+		})
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d CloudSearch domains found, %d open to anonymous requests.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), m.countPublic())
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No CloudSearch domains found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *CloudSearchModule) countPublic() int {
+	var count int
+	for _, domain := range m.Domains {
+		if len(domain.PublicActions) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// writeLoot has curl commands for the domains that take anonymous requests and signed aws cli commands for the rest.
+// The document probe posts an empty batch, which adds nothing but tells apart an accepted upload from a denied one.
+func (m *CloudSearchModule) writeLoot() string {
+	var out string
+	out += "#############################################\n"
+	out += "# Query the CloudSearch domains. Domains open to everyone answer plain curl requests, the others need\n"
+	out += "# credentials that the access policy allows.\n"
+	out += "#############################################\n"
+
+	for _, domain := range m.Domains {
+		if domain.SearchEndpoint == "" {
+			continue
+		}
+		out += fmt.Sprintf("\n# %s in %s\n", domain.Name, domain.Region)
+		out += fmt.Sprintf("curl -s 'https://%s/2013-01-01/search?q=matchall&q.parser=structured&size=10'\n", domain.SearchEndpoint)
+		if internal.Contains("cloudsearch:suggest", domain.PublicActions) {
+			out += fmt.Sprintf("curl -s 'https://%s/2013-01-01/suggest?q=a&suggester=<suggester>'\n", domain.SearchEndpoint)
+		}
+		if internal.Contains("cloudsearch:document", domain.PublicActions) && domain.DocEndpoint != "" {
+			out += fmt.Sprintf("curl -s -X POST 'https://%s/2013-01-01/documents/batch' -H 'Content-Type: application/json' -d '[]'\n", domain.DocEndpoint)
+		}
+		out += fmt.Sprintf("aws --profile $profile --region %s cloudsearchdomain search --endpoint-url https://%s --search-query matchall --query-parser structured --size 10\n", domain.Region, domain.SearchEndpoint)
+	}
+	return out
+}
+
+func (m *CloudSearchModule) Receiver(receiver chan CloudSearchDomain, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Domains = append(m.Domains, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *CloudSearchModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan CloudSearchDomain) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("cloudsearch", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getDomainsPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *CloudSearchModule) getDomainsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan CloudSearchDomain) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	domains, err := sdk.CachedCloudSearchDescribeDomains(m.CloudSearchClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, status := range domains {
+		if aws.ToBool(status.Deleted) {
+			continue
+		}
+		domain := CloudSearchDomain{
+			Region: r,
+			Name:   aws.ToString(status.DomainName),
+			Arn:    aws.ToString(status.ARN),
+		}
+		if status.DocService != nil {
+			domain.DocEndpoint = aws.ToString(status.DocService.Endpoint)
+		}
+		if status.SearchService != nil {
+			domain.SearchEndpoint = aws.ToString(status.SearchService.Endpoint)
+		}
+
+		// A domain without endpoint options takes HTTP, so only failing to read them leaves it unknown
+		endpointOptions, endpointErr := sdk.CachedCloudSearchDescribeDomainEndpointOptions(m.CloudSearchClient, aws.ToString(m.Caller.Account), r, domain.Name)
+		if endpointErr != nil {
+			m.modLog.Error(endpointErr.Error())
+			m.CommandCounter.Error++
+		} else {
+			domain.EnforceHTTPS = aws.ToBool(endpointOptions.EnforceHTTPS)
+		}
+
+		accessPolicy, err := sdk.CachedCloudSearchDescribeServiceAccessPolicies(m.CloudSearchClient, aws.ToString(m.Caller.Account), r, domain.Name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		} else if accessPolicy != "" {
+			parsedPolicy, err := policy.ParseJSONPolicy([]byte(accessPolicy))
+			if err != nil {
+				m.modLog.Error(fmt.Sprintf("parsing the access policy of %s: %s", domain.Name, err))
+			} else {
+				domain.PublicActions, domain.ConditionalActions = cloudSearchPublicActions(parsedPolicy)
+			}
+		}
+
+		domain.Findings = cloudSearchDomainFindings(domain, endpointErr == nil)
+		dataReceiver <- domain
+	}
+}
+
+// cloudSearchPublicActions returns the domain actions that Allow statements grant to everyone, split into the ones
+// without a condition and the ones only granted under a condition
+func cloudSearchPublicActions(accessPolicy policy.Policy) ([]string, []string) {
+	var public, conditional []string
+	for _, statement := range accessPolicy.Statement {
+		if !statement.IsAllow() || !statement.Principal.IsPublic() {
+			continue
+		}
+		for _, action := range cloudSearchActions {
+			if !cloudSearchStatementGrants(statement, action) {
+				continue
+			}
+			if statement.Condition.IsEmpty() {
+				if !internal.Contains(action, public) {
+					public = append(public, action)
+				}
+			} else if !internal.Contains(action, conditional) {
+				conditional = append(conditional, action)
+			}
+		}
+	}
+	// An action granted without a condition by one statement is public, whatever the others say
+	var onlyConditional []string
+	for _, action := range conditional {
+		if !internal.Contains(action, public) {
+			onlyConditional = append(onlyConditional, action)
+		}
+	}
+	return sortCloudSearchActions(public), sortCloudSearchActions(onlyConditional)
+}
+
+func cloudSearchStatementGrants(statement policy.PolicyStatement, action string) bool {
+	for _, pattern := range statement.Action {
+		if policy.MatchesAfterExpansion(action, pattern) {
+			return true
+		}
+	}
+	if len(statement.NotAction) == 0 {
+		return false
+	}
+	for _, pattern := range statement.NotAction {
+		if policy.MatchesAfterExpansion(action, pattern) {
+			return false
+		}
+	}
+	return true
+}
+
+func sortCloudSearchActions(actions []string) []string {
+	var sorted []string
+	for _, action := range cloudSearchActions {
+		if internal.Contains(action, actions) {
+			sorted = append(sorted, action)
+		}
+	}
+	return sorted
+}
+
+func cloudSearchDomainFindings(domain CloudSearchDomain, endpointOptionsKnown bool) []string {
+	var findings []string
+	if endpointOptionsKnown && !domain.EnforceHTTPS && domain.DocEndpoint != "" {
+		findings = append(findings, cloudSearchHTTPDocEndpoint)
+	}
+	if internal.Contains("cloudsearch:search", domain.PublicActions) {
+		findings = append(findings, cloudSearchAnonymousSearch)
+	}
+	if internal.Contains("cloudsearch:suggest", domain.PublicActions) {
+		findings = append(findings, cloudSearchAnonymousSuggest)
+	}
+	if internal.Contains("cloudsearch:document", domain.PublicActions) {
+		findings = append(findings, cloudSearchAnonymousDocuments)
+	}
+	if len(domain.ConditionalActions) > 0 {
+		findings = append(findings, cloudSearchConditionalAccess)
+	}
+	return findings
+}
@@ -0,0 +1,68 @@
+package aws
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestCloudSearch(t *testing.T) {
+	m := CloudSearchModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:        3,
+		CloudSearchClient: &sdk.MockedCloudSearchClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintCloudSearch(".", 2)
+
+	expectedFindings := map[string][]string{
+		"products": {cloudSearchHTTPDocEndpoint, cloudSearchAnonymousSearch, cloudSearchAnonymousSuggest, cloudSearchAnonymousDocuments},
+		"logs":     {cloudSearchConditionalAccess},
+		"internal": nil,
+	}
+	if len(m.Domains) != len(expectedFindings) {
+		t.Fatalf("Expected %d domains, got %d", len(expectedFindings), len(m.Domains))
+	}
+	for _, domain := range m.Domains {
+		if !reflect.DeepEqual(domain.Findings, expectedFindings[domain.Name]) {
+			t.Errorf("%s: expected findings %v, got %v", domain.Name, expectedFindings[domain.Name], domain.Findings)
+		}
+		if domain.Name == "logs" && !reflect.DeepEqual(domain.ConditionalActions, cloudSearchActions) {
+			t.Errorf("Expected cloudsearch:* to grant every action to logs under its condition, got %v", domain.ConditionalActions)
+		}
+	}
+
+	lootFilePath := filepath.Join(".", "cloudfox-output/aws/unittesting-123456789012/loot/cloudsearch.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	expectedCommands := []string{
+		"curl -s 'https://search-products-abcdefghijklmnop.us-east-1.cloudsearch.amazonaws.com/2013-01-01/search?q=matchall&q.parser=structured&size=10'",
+		"curl -s -X POST 'https://doc-products-abcdefghijklmnop.us-east-1.cloudsearch.amazonaws.com/2013-01-01/documents/batch'",
+	}
+	for _, expected := range expectedCommands {
+		if !strings.Contains(string(lootFile), expected) {
+			t.Errorf("Expected %s to be in the loot file", expected)
+		}
+	}
+	// Only products takes anonymous uploads
+	if strings.Contains(string(lootFile), "doc-logs-") {
+		t.Errorf("Did not expect a document upload for logs")
+	}
+}
@@ -0,0 +1,521 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	cloudtrailTypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type CloudTrailGapsModule struct {
+	// General configuration data
+	CloudTrailClient sdk.CloudTrailClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+	// Days is how far back we look for StopLogging events
+	Days int
+	// SecurityToolRoles are the roles of security tooling that is expected to stop trails, e.g. for remediation or
+	// migrations. Entries are role ARNs or role names, assumed role sessions match the ARN of their role.
+	SecurityToolRoles []string
+
+	// Main module data
+	Trails            []CloudTrailDelivery
+	StopLoggingEvents []StopLoggingEvent
+	CommandCounter    internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+// CloudTrailDelivery is the logging and log delivery status of a trail
+type CloudTrailDelivery struct {
+	Region                string
+	Name                  string
+	Arn                   string
+	IsLogging             bool
+	StopLoggingTime       *time.Time
+	LatestDeliveryAttempt string
+	LatestDeliverySuccess string
+	LatestDeliveryError   string
+	Findings              []string
+}
+
+// StopLoggingEvent is a StopLogging call CloudTrail recorded
+type StopLoggingEvent struct {
+	EventID       string
+	Region        string
+	EventTime     time.Time
+	Trail         string
+	Principal     string
+	PrincipalType string
+	SourceIP      string
+	Result        string
+	Finding       string
+}
+
+// stopLoggingEvent holds the trail of a StopLogging CloudTrail event, the rest is parsed as an iamChangeEvent
+type stopLoggingEvent struct {
+	RequestParameters struct {
+		Name string `json:"name"`
+	} `json:"requestParameters"`
+}
+
+const (
+	cloudTrailGapsDefaultDays = 30
+	// potentialEvidenceDestruction flags trails stopped by principals that are not known security tooling
+	potentialEvidenceDestruction = "Potential evidence destruction"
+)
+
+func (m *CloudTrailGapsModule) PrintCloudTrailGaps(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "cloudtrail-gaps"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+	if m.Days <= 0 {
+		m.Days = cloudTrailGapsDefaultDays
+	}
+
+	fmt.Printf("[%s][%s] Looking for CloudTrail delivery failures and StopLogging events in the last %d days for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.Days, aws.ToString(m.Caller.Account))
+	if len(m.SecurityToolRoles) == 0 {
+		fmt.Printf("[%s][%s] Add --security-tool-roles to not flag trails stopped by your security tooling.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create channels to receive the objects
+	trailReceiver := make(chan CloudTrailDelivery)
+	eventReceiver := make(chan StopLoggingEvent)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(trailReceiver, eventReceiver, receiverDone)
+
+	endTime := time.Now()
+	startTime := endTime.AddDate(0, 0, -m.Days)
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, startTime, endTime, wg, semaphore, trailReceiver, eventReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.Trails, func(i, j int) bool {
+		if m.Trails[i].Region != m.Trails[j].Region {
+			return m.Trails[i].Region < m.Trails[j].Region
+		}
+		return m.Trails[i].Name < m.Trails[j].Name
+	})
+	sort.Slice(m.StopLoggingEvents, func(i, j int) bool {
+		return m.StopLoggingEvents[i].EventTime.After(m.StopLoggingEvents[j].EventTime)
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Trail",
+		"Arn",
+		"Logging",
+		"Stopped At",
+		"Latest Delivery Attempt",
+		"Latest Successful Delivery",
+		"Delivery Error",
+		"Finding",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Trail",
+			"Arn",
+			"Logging",
+			"Stopped At",
+			"Latest Delivery Attempt",
+			"Latest Successful Delivery",
+			"Delivery Error",
+			"Finding",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Trail",
+			"Logging",
+			"Latest Delivery Attempt",
+			"Latest Successful Delivery",
+			"Finding",
+		}
+	}
+
+	// Table rows
+	var flaggedTrails int
+	for _, trail := range m.Trails {
+		finding := strings.Join(trail.Findings, ", ")
+		if finding != "" {
+			flaggedTrails++
+			finding = magenta(finding)
+		}
+		var stoppedAt string
+		if trail.StopLoggingTime != nil && !trail.IsLogging {
+			stoppedAt = trail.StopLoggingTime.UTC().Format(time.RFC3339)
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				trail.Region,
+				trail.Name,
+				trail.Arn,
+				fmt.Sprintf("%t", trail.IsLogging),
+				stoppedAt,
+				trail.LatestDeliveryAttempt,
+				trail.LatestDeliverySuccess,
+				trail.LatestDeliveryError,
+				finding,
+			},
+		)
+	}
+
+	eventHeaders := []string{
+		"Account",
+		"Region",
+		"Time",
+		"Trail",
+		"Principal",
+		"Principal Type",
+		"Source IP",
+		"Result",
+		"Finding",
+	}
+	var eventBody [][]string
+	var flaggedEvents int
+	for _, event := range m.StopLoggingEvents {
+		finding := event.Finding
+		if finding != "" {
+			flaggedEvents++
+			finding = magenta(finding)
+		}
+		eventBody = append(
+			eventBody,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				event.Region,
+				event.EventTime.UTC().Format(time.RFC3339),
+				event.Trail,
+				event.Principal,
+				event.PrincipalType,
+				event.SourceIP,
+				event.Result,
+				finding,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 || len(eventBody) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		if len(m.output.Body) > 0 {
+			o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+				Header:    m.output.Headers,
+				Body:      m.output.Body,
+				TableCols: tableCols,
+				Name:      m.output.CallingModule,
+			})
+		}
+		if len(eventBody) > 0 {
+			o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+				Header: eventHeaders,
+				Body:   eventBody,
+				Name:   fmt.Sprintf("%s-stoplogging", m.output.CallingModule),
+			})
+		}
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		if flaggedEvents > 0 {
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     m.output.CallingModule,
+				Contents: m.writeLoot(),
+			})
+		}
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d trails found, %d of them not logging or failing to deliver logs.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), flaggedTrails)
+		fmt.Printf("[%s][%s] %d StopLogging events found, %d of them flagged.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(eventBody), flaggedEvents)
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No trails or StopLogging events found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *CloudTrailGapsModule) executeChecks(r string, startTime time.Time, endTime time.Time, wg *sync.WaitGroup, semaphore chan struct{}, trailReceiver chan CloudTrailDelivery, eventReceiver chan StopLoggingEvent) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("cloudtrail", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getCloudTrailGapsPerRegion(r, startTime, endTime, wg, semaphore, trailReceiver, eventReceiver)
+	}
+}
+
+func (m *CloudTrailGapsModule) Receiver(trailReceiver chan CloudTrailDelivery, eventReceiver chan StopLoggingEvent, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-trailReceiver:
+			m.Trails = append(m.Trails, data)
+		case data := <-eventReceiver:
+			m.StopLoggingEvents = append(m.StopLoggingEvents, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *CloudTrailGapsModule) getCloudTrailGapsPerRegion(r string, startTime time.Time, endTime time.Time, wg *sync.WaitGroup, semaphore chan struct{}, trailReceiver chan CloudTrailDelivery, eventReceiver chan StopLoggingEvent) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	trails, err := sdk.CachedCloudTrailDescribeTrails(m.CloudTrailClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	for _, trail := range trails {
+		// Multi-region trails show up in every region, they are checked in their home region only
+		if aws.ToString(trail.HomeRegion) != r {
+			continue
+		}
+		status, err := sdk.CachedCloudTrailGetTrailStatus(m.CloudTrailClient, aws.ToString(m.Caller.Account), r, aws.ToString(trail.TrailARN))
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		delivery := CloudTrailDelivery{
+			Region:                r,
+			Name:                  aws.ToString(trail.Name),
+			Arn:                   aws.ToString(trail.TrailARN),
+			IsLogging:             status.IsLogging,
+			StopLoggingTime:       status.StopLoggingTime,
+			LatestDeliveryAttempt: status.LatestDeliveryAttemptTime,
+			LatestDeliverySuccess: status.LatestDeliveryAttemptSucceeded,
+			LatestDeliveryError:   status.LatestDeliveryError,
+		}
+		delivery.Findings = cloudTrailDeliveryFindings(delivery)
+		trailReceiver <- delivery
+	}
+
+	for _, event := range m.getStopLoggingEvents(r, startTime, endTime) {
+		eventReceiver <- event
+	}
+}
+
+// cloudTrailDeliveryFindings flags trails that don't log and trails whose latest delivery attempts failed. When the
+// latest attempt succeeded, both attempt times are the same.
+func cloudTrailDeliveryFindings(trail CloudTrailDelivery) []string {
+	var findings []string
+	if !trail.IsLogging {
+		findings = append(findings, "Logging stopped")
+	}
+	switch {
+	case trail.LatestDeliveryAttempt != "" && trail.LatestDeliverySuccess == "":
+		findings = append(findings, "Log delivery never succeeded")
+	case trail.LatestDeliveryAttempt != "" && trail.LatestDeliveryAttempt != trail.LatestDeliverySuccess:
+		findings = append(findings, fmt.Sprintf("Log delivery failing since %s", trail.LatestDeliverySuccess))
+	}
+	if trail.LatestDeliveryError != "" {
+		findings = append(findings, fmt.Sprintf("Delivery error: %s", trail.LatestDeliveryError))
+	}
+	return findings
+}
+
+// getStopLoggingEvents looks up the StopLogging events of a region. The events are not cached, CloudTrail is the
+// source of truth for them.
+func (m *CloudTrailGapsModule) getStopLoggingEvents(r string, startTime time.Time, endTime time.Time) []StopLoggingEvent {
+	var events []StopLoggingEvent
+	var PaginationControl *string
+	for {
+		LookupEvents, err := m.CloudTrailClient.LookupEvents(
+			context.TODO(),
+			&cloudtrail.LookupEventsInput{
+				StartTime: aws.Time(startTime),
+				EndTime:   aws.Time(endTime),
+				LookupAttributes: []cloudtrailTypes.LookupAttribute{
+					{
+						AttributeKey:   cloudtrailTypes.LookupAttributeKeyEventName,
+						AttributeValue: aws.String("StopLogging"),
+					},
+				},
+				NextToken: PaginationControl,
+			},
+			func(o *cloudtrail.Options) {
+				o.Region = r
+				o.Retryer = lookupEventsRetryer()
+			},
+		)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			break
+		}
+
+		for _, event := range LookupEvents.Events {
+			var parsed iamChangeEvent
+			var trail stopLoggingEvent
+			if err := json.Unmarshal([]byte(aws.ToString(event.CloudTrailEvent)), &parsed); err != nil {
+				m.modLog.Error(err.Error())
+				continue
+			}
+			if err := json.Unmarshal([]byte(aws.ToString(event.CloudTrailEvent)), &trail); err != nil {
+				m.modLog.Error(err.Error())
+				continue
+			}
+			stop := StopLoggingEvent{
+				EventID:       aws.ToString(event.EventId),
+				Region:        r,
+				EventTime:     parsed.EventTime,
+				Trail:         trail.RequestParameters.Name,
+				Principal:     iamChangePrincipal(parsed),
+				PrincipalType: parsed.UserIdentity.Type,
+				SourceIP:      parsed.SourceIPAddress,
+				Result:        "Success",
+			}
+			if stop.EventTime.IsZero() {
+				stop.EventTime = aws.ToTime(event.EventTime)
+			}
+			if parsed.ErrorCode != "" {
+				stop.Result = parsed.ErrorCode
+			}
+			stop.Finding = m.flagStopLogging(stop)
+			events = append(events, stop)
+		}
+
+		// The "NextToken" value is nil when there's no more data to return.
+		if LookupEvents.NextToken == nil {
+			break
+		}
+		PaginationControl = LookupEvents.NextToken
+	}
+	return events
+}
+
+// flagStopLogging flags StopLogging calls by anyone but the security tooling. Failed calls did not stop the trail,
+// but someone tried.
+func (m *CloudTrailGapsModule) flagStopLogging(event StopLoggingEvent) string {
+	if m.isSecurityToolRole(event.Principal) {
+		return ""
+	}
+	if event.Result != "Success" {
+		return "Failed attempt to stop logging"
+	}
+	return potentialEvidenceDestruction
+}
+
+func (m *CloudTrailGapsModule) isSecurityToolRole(principal string) bool {
+	for _, role := range m.SecurityToolRoles {
+		role = strings.TrimSpace(role)
+		if role == "" {
+			continue
+		}
+		if principal == role {
+			return true
+		}
+		if !strings.HasPrefix(role, "arn:") && strings.Contains(principal, ":role/") && strings.HasSuffix(principal, "/"+role) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *CloudTrailGapsModule) writeLoot() string {
+	var out string
+	out += fmt.Sprintln("#############################################")
+	out += fmt.Sprintln("# Pull the full CloudTrail events of the flagged StopLogging calls.")
+	out += fmt.Sprintln("# Set the $profile environment variable to the profile you are going to use, e.g. export profile=dev-prod.")
+	out += fmt.Sprintln("#############################################")
+	out += fmt.Sprintln("")
+	for _, event := range m.StopLoggingEvents {
+		if event.Finding == "" {
+			continue
+		}
+		out += fmt.Sprintf("# %s called StopLogging on %s from %s: %s\n", event.Principal, event.Trail, event.SourceIP, event.Finding)
+		out += fmt.Sprintf("aws --profile $profile --region %s cloudtrail lookup-events --lookup-attributes AttributeKey=EventId,AttributeValue=%s\n\n", event.Region, event.EventID)
+	}
+	return out
+}
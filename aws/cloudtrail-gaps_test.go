@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestCloudTrailGaps(t *testing.T) {
+	m := CloudTrailGapsModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1", "us-west-2"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:        3,
+		CloudTrailClient:  &sdk.MockedCloudTrailClient{},
+		SecurityToolRoles: []string{"security-automation"},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintCloudTrailGaps(".", 2)
+
+	if len(m.Trails) != 2 {
+		t.Fatalf("Expected 2 trails, got %d", len(m.Trails))
+	}
+	// management-events is still logging but has not delivered for three days, stopped-trail delivered its last logs
+	// before it was stopped
+	if findings := m.Trails[0].Findings; len(findings) != 2 || !strings.HasPrefix(findings[0], "Log delivery failing since ") || findings[1] != "Delivery error: AccessDenied" {
+		t.Errorf("Unexpected findings for management-events: %v", findings)
+	}
+	if findings := m.Trails[1].Findings; !reflect.DeepEqual(findings, []string{"Logging stopped"}) {
+		t.Errorf("Unexpected findings for stopped-trail: %v", findings)
+	}
+
+	// The 45 days old event is outside of the time window
+	expected := map[string]string{
+		"stop-1": "",
+		"stop-2": "Failed attempt to stop logging",
+		"stop-3": potentialEvidenceDestruction,
+	}
+	if len(m.StopLoggingEvents) != len(expected) {
+		t.Fatalf("Expected %d StopLogging events, got %d", len(expected), len(m.StopLoggingEvents))
+	}
+	for _, event := range m.StopLoggingEvents {
+		if want, ok := expected[event.EventID]; !ok || event.Finding != want {
+			t.Errorf("%s by %s: expected finding %q, got %q", event.EventID, event.Principal, want, event.Finding)
+		}
+	}
+
+	for _, name := range []string{"cloudtrail-gaps", "cloudtrail-gaps-stoplogging"} {
+		resultsFilePath := filepath.Join(".", "cloudfox-output/aws/unittesting-123456789012/table", name+".txt")
+		if _, err := afero.ReadFile(fs, resultsFilePath); err != nil {
+			t.Errorf("Cannot read output file at %s: %s", resultsFilePath, err)
+		}
+	}
+	lootFilePath := filepath.Join(".", "cloudfox-output/aws/unittesting-123456789012/loot/cloudtrail-gaps.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	if !strings.Contains(string(lootFile), "AttributeKey=EventId,AttributeValue=stop-3") || strings.Contains(string(lootFile), "AttributeValue=stop-1") {
+		t.Errorf("Expected only the flagged StopLogging events in the loot file")
+	}
+}
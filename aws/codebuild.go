@@ -2,13 +2,16 @@ package aws
 
 import (
 	"fmt"
+	"net/url"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 
 	"github.com/BishopFox/cloudfox/aws/sdk"
 	"github.com/BishopFox/cloudfox/internal"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	codeBuildTypes "github.com/aws/aws-sdk-go-v2/service/codebuild/types"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/bishopfox/awsservicemap"
 	"github.com/sirupsen/logrus"
@@ -42,14 +45,22 @@ type CodeBuildModule struct {
 }
 
 type Project struct {
-	Region     string
-	Name       string
-	Arn        string
-	Role       string
-	Admin      string
-	CanPrivEsc string
+	Region           string
+	Name             string
+	Arn              string
+	Role             string
+	Admin            string
+	CanPrivEsc       string
+	Source           string
+	SourceAuth       string
+	PlaintextSecrets []string
+	SecretReferences []string
+	BuildspecSecrets []string
 }
 
+// Matches "NAME: value" and "NAME=value" lines in an inline buildspec
+var reBuildspecAssignment = regexp.MustCompile(`^\s*-?\s*(?:export\s+)?["']?([A-Za-z_][A-Za-z0-9_.-]*)["']?\s*[:=]\s*(.+)$`)
+
 func (m *CodeBuildModule) PrintCodeBuildProjects(outputDirectory string, verbosity int) {
 	// These struct values are used by the output module
 	m.output.Verbosity = verbosity
@@ -118,6 +129,11 @@ func (m *CodeBuildModule) PrintCodeBuildProjects(outputDirectory string, verbosi
 		"Role",
 		"IsAdminRole?",
 		"CanPrivEscToAdmin?",
+		"Source",
+		"Source Auth",
+		"Plaintext Env Secrets",
+		"Secret References",
+		"Buildspec Secrets",
 	}
 
 	// If the user specified table columns, use those.
@@ -139,6 +155,11 @@ func (m *CodeBuildModule) PrintCodeBuildProjects(outputDirectory string, verbosi
 			"Role",
 			"IsAdminRole?",
 			"CanPrivEscToAdmin?",
+			"Source",
+			"Source Auth",
+			"Plaintext Env Secrets",
+			"Secret References",
+			"Buildspec Secrets",
 		}
 		// Otherwise, use the default columns.
 	} else {
@@ -148,6 +169,8 @@ func (m *CodeBuildModule) PrintCodeBuildProjects(outputDirectory string, verbosi
 			"Role",
 			"IsAdminRole?",
 			"CanPrivEscToAdmin?",
+			"Plaintext Env Secrets",
+			"Buildspec Secrets",
 		}
 	}
 
@@ -169,6 +192,11 @@ func (m *CodeBuildModule) PrintCodeBuildProjects(outputDirectory string, verbosi
 				m.Projects[i].Role,
 				m.Projects[i].Admin,
 				m.Projects[i].CanPrivEsc,
+				m.Projects[i].Source,
+				m.Projects[i].SourceAuth,
+				strings.Join(m.Projects[i].PlaintextSecrets, ", "),
+				strings.Join(m.Projects[i].SecretReferences, ", "),
+				strings.Join(m.Projects[i].BuildspecSecrets, ", "),
 			},
 		)
 	}
@@ -197,9 +225,15 @@ func (m *CodeBuildModule) PrintCodeBuildProjects(outputDirectory string, verbosi
 		})
 		o.PrefixIdentifier = m.AWSProfile
 		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
-		o.WriteFullOutput(o.Table.TableFiles, nil)
-		//m.writeLoot(o.Table.DirectoryName, verbosity)
-		fmt.Printf("[%s][%s] %d projects found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     "codebuild-commands",
+			Contents: m.writeLoot(),
+		})
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d projects found (%d with plaintext secrets).\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), m.countProjectsWithSecrets())
 		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
 
 	} else {
@@ -208,6 +242,44 @@ func (m *CodeBuildModule) PrintCodeBuildProjects(outputDirectory string, verbosi
 
 }
 
+func (m *CodeBuildModule) countProjectsWithSecrets() int {
+	var count int
+	for _, project := range m.Projects {
+		if project.hasPlaintextSecrets() {
+			count++
+		}
+	}
+	return count
+}
+
+func (p Project) hasPlaintextSecrets() bool {
+	return len(p.PlaintextSecrets) > 0 || len(p.BuildspecSecrets) > 0 || strings.HasPrefix(p.SourceAuth, "Credentials in URL")
+}
+
+// writeLoot lists describe commands for the projects with plaintext secrets and start-build templates for all of
+// them. A build runs with the project's service role, so anyone who can start one (and override the buildspec) can
+// act as that role.
+func (m *CodeBuildModule) writeLoot() string {
+	var out string
+	out += "#############################################\n"
+	out += "# Set the $profile environment variable to the profile you are going to use, e.g. export profile=dev-prod.\n"
+	out += "#############################################\n\n"
+
+	out += "# Projects with plaintext secrets. The values are in the environment and source sections of the output.\n"
+	for _, project := range m.Projects {
+		if project.hasPlaintextSecrets() {
+			out += fmt.Sprintf("aws --profile $profile --region %s codebuild batch-get-projects --names %s\n", project.Region, project.Name)
+		}
+	}
+
+	out += "\n# Start a build with your own buildspec. Commands in it run with the credentials of the project's service role.\n"
+	for _, project := range m.Projects {
+		out += fmt.Sprintf("# %s runs as %s\n", project.Name, project.Role)
+		out += fmt.Sprintf("aws --profile $profile --region %s codebuild start-build --project-name %s --buildspec-override file://buildspec.yml\n\n", project.Region, project.Name)
+	}
+	return out
+}
+
 func (m *CodeBuildModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Project) {
 	defer wg.Done()
 
@@ -253,16 +325,103 @@ func (m *CodeBuildModule) getcodeBuildProjectsPerRegion(r string, wg *sync.WaitG
 		details, err := sdk.CachedCodeBuildBatchGetProjects(m.CodeBuildClient, aws.ToString(m.Caller.Account), r, project)
 		if err != nil {
 			sharedLogger.Error(err.Error())
+			continue
+		}
+
+		codeBuildProject := analyzeCodeBuildProject(details)
+		codeBuildProject.Region = r
+		dataReceiver <- codeBuildProject
+
+	}
+
+}
+
+// analyzeCodeBuildProject sorts the environment variables of a project into plaintext values with credential-looking
+// names and references to Parameter Store or Secrets Manager, and checks the source and inline buildspec for secrets.
+func analyzeCodeBuildProject(details codeBuildTypes.Project) Project {
+	project := Project{
+		Name: aws.ToString(details.Name),
+		Arn:  aws.ToString(details.Arn),
+		Role: aws.ToString(details.ServiceRole),
+	}
+
+	if details.Environment != nil {
+		for _, envVar := range details.Environment.EnvironmentVariables {
+			name := aws.ToString(envVar.Name)
+			switch envVar.Type {
+			case codeBuildTypes.EnvironmentVariableTypeParameterStore, codeBuildTypes.EnvironmentVariableTypeSecretsManager:
+				project.SecretReferences = append(project.SecretReferences, fmt.Sprintf("%s (%s: %s)", name, envVar.Type, aws.ToString(envVar.Value)))
+			default:
+				if isSecretLookingName(name) && aws.ToString(envVar.Value) != "" {
+					project.PlaintextSecrets = append(project.PlaintextSecrets, name)
+				}
+			}
 		}
+	}
 
-		dataReceiver <- Project{
-			Name:       aws.ToString(details.Name),
-			Region:     r,
-			Role:       aws.ToString(details.ServiceRole),
-			Admin:      "",
-			CanPrivEsc: "",
+	if details.Source != nil {
+		location := aws.ToString(details.Source.Location)
+		project.Source = string(details.Source.Type)
+		if location != "" {
+			project.Source = fmt.Sprintf("%s: %s", details.Source.Type, location)
+		}
+		if sourceURL, err := url.Parse(location); err == nil && sourceURL.User != nil {
+			if _, hasPassword := sourceURL.User.Password(); hasPassword {
+				project.SourceAuth = "Credentials in URL"
+				project.Source = fmt.Sprintf("%s: %s", details.Source.Type, sourceURL.Redacted())
+			}
+		}
+		if details.Source.Auth != nil && project.SourceAuth == "" {
+			project.SourceAuth = string(details.Source.Auth.Type)
+			if details.Source.Auth.Resource != nil {
+				project.SourceAuth = fmt.Sprintf("%s: %s", details.Source.Auth.Type, aws.ToString(details.Source.Auth.Resource))
+			}
 		}
 
+		// A buildspec is either a path in the source, an S3 object, or the buildspec itself
+		buildspec := aws.ToString(details.Source.Buildspec)
+		if strings.Contains(buildspec, "\n") {
+			project.BuildspecSecrets = scanBuildspecForSecrets(buildspec)
+		}
 	}
 
+	return project
+}
+
+// scanBuildspecForSecrets returns the names of the assignments in a buildspec that look like credentials. Entries
+// under parameter-store and secrets-manager are references, not values, so they are skipped.
+func scanBuildspecForSecrets(buildspec string) []string {
+	var secrets []string
+	referenceIndent := -1
+	for _, line := range strings.Split(buildspec, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if referenceIndent >= 0 && indent <= referenceIndent {
+			referenceIndent = -1
+		}
+		if trimmed == "parameter-store:" || trimmed == "secrets-manager:" {
+			referenceIndent = indent
+			continue
+		}
+		if referenceIndent >= 0 {
+			continue
+		}
+
+		match := reBuildspecAssignment.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		name, value := match[1], strings.Trim(strings.TrimSpace(match[2]), `"'`)
+		// Skip section headers, block scalars and values that come from somewhere else
+		if value == "" || value == "|" || value == ">" || strings.HasPrefix(value, "$") {
+			continue
+		}
+		if isSecretLookingName(name) && !internal.Contains(name, secrets) {
+			secrets = append(secrets, name)
+		}
+	}
+	return secrets
 }
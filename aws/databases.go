@@ -183,6 +183,8 @@ func (m *DatabasesModule) PrintDatabases(outputDirectory string, verbosity int)
 		o := internal.OutputClient{
 			Verbosity:     verbosity,
 			CallingModule: m.output.CallingModule,
+			AccountID:     aws.ToString(m.Caller.Account),
+			BaseDirectory: outputDirectory,
 			Table: internal.TableClient{
 				Wrap:          m.WrapTable,
 				DirectoryName: filepath,
@@ -323,13 +325,7 @@ func (m *DatabasesModule) writeLoot(outputDirectory string, verbosity int) strin
 	// 	panic(err.Error())
 	// }
 
-	if verbosity > 2 {
-		fmt.Println()
-		fmt.Printf("[%s][%s] %s \n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("Feed this databases into nmap and something like gowitness/aquatone for screenshots."))
-		fmt.Print(out)
-		fmt.Printf("[%s][%s] %s \n\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("End of loot file."))
-	}
-
+	// WriteFullOutput prints the loot to the terminal, see internal.ShowLoot
 	fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), f)
 
 	return out
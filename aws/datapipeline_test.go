@@ -0,0 +1,70 @@
+package aws
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestDataPipelines(t *testing.T) {
+
+	m := DataPipelineModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:         3,
+		WrapTable:          false,
+		DataPipelineClient: &sdk.MockedDataPipelineClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+	tmpDir := "."
+
+	m.PrintDataPipelines(tmpDir, 2)
+
+	expectedPasswords := map[string]string{
+		"SourceDatabase":    "Sup3rS3cret!",
+		"ReportingDatabase": "hunter2",
+		"Warehouse":         "r3dsh1ft",
+		"OutputLocation":    "",
+	}
+	if len(m.PipelineObjects) != len(expectedPasswords) {
+		t.Errorf("Expected %d pipeline objects, got %d", len(expectedPasswords), len(m.PipelineObjects))
+	}
+	for _, object := range m.PipelineObjects {
+		expected, ok := expectedPasswords[object.ObjectName]
+		if !ok {
+			t.Errorf("Unexpected pipeline object %s", object.ObjectName)
+			continue
+		}
+		if object.Password != expected {
+			t.Errorf("Expected password for %s to be %s, got %s", object.ObjectName, expected, object.Password)
+		}
+		if object.ObjectName == "ReportingDatabase" && object.Username != "" {
+			t.Errorf("Did not expect a username field for %s", object.ObjectName)
+		}
+	}
+
+	lootFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/loot/data-pipeline-definitions.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	expectedCommand := "aws --profile $profile --region us-east-1 datapipeline get-pipeline-definition --pipeline-id pipeline1"
+	if !strings.Contains(string(lootFile), expectedCommand) {
+		t.Errorf("Expected %s to be in the loot file", expectedCommand)
+	}
+	if strings.Contains(string(lootFile), "pipeline2") {
+		t.Errorf("Did not expect pipeline2 to be in the loot file")
+	}
+}
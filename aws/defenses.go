@@ -0,0 +1,417 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	accessAnalyzerTypes "github.com/aws/aws-sdk-go-v2/service/accessanalyzer/types"
+	guardDutyTypes "github.com/aws/aws-sdk-go-v2/service/guardduty/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/sirupsen/logrus"
+)
+
+type DefensesModule struct {
+	// General configuration data
+	GuardDutyClient      sdk.GuardDutyClientInterface
+	CloudTrailClient     sdk.CloudTrailClientInterface
+	ConfigClient         sdk.AWSConfigServiceClientInterface
+	SecurityHubClient    sdk.SecurityHubClientInterface
+	AccessAnalyzerClient sdk.AccessAnalyzerClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Controls       []DefenseControl
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type DefenseControl struct {
+	Region  string
+	Control string
+	Status  string
+	Details string
+}
+
+const (
+	defenseStatusEnabled       = "Enabled"
+	defenseStatusDisabled      = "Disabled"
+	defenseStatusNotConfigured = "Not configured"
+	defenseStatusAccessDenied  = "Access denied"
+	defenseStatusError         = "Error"
+)
+
+func (m *DefensesModule) PrintDefenses(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "defenses"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating detective controls for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+	fmt.Printf("[%s][%s] Supported Services: GuardDuty, CloudTrail, Config, Security Hub, IAM Access Analyzer\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "tasks")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan DefenseControl)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.Controls, func(i, j int) bool {
+		if m.Controls[i].Region != m.Controls[j].Region {
+			return m.Controls[i].Region < m.Controls[j].Region
+		}
+		return m.Controls[i].Control < m.Controls[j].Control
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Control",
+		"Status",
+		"Details",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Control",
+			"Status",
+			"Details",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Control",
+			"Status",
+			"Details",
+		}
+	}
+
+	// Table rows
+	for i := range m.Controls {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				m.Controls[i].Region,
+				m.Controls[i].Control,
+				m.Controls[i].Status,
+				m.Controls[i].Details,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %d controls checked in %d regions.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), len(m.AWSRegions))
+
+		uncovered, unknown := m.regionsWithoutCloudTrail()
+		if len(uncovered) > 0 {
+			fmt.Printf("[%s][%s] %s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), red(fmt.Sprintf("No CloudTrail coverage in %d regions: %s", len(uncovered), strings.Join(uncovered, ", "))))
+		}
+		if len(unknown) > 0 {
+			fmt.Printf("[%s][%s] Could not determine CloudTrail coverage in %d regions: %s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(unknown), strings.Join(unknown, ", "))
+		}
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No controls checked, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+// regionsWithoutCloudTrail returns the regions without a logging trail, and the regions where that couldn't be checked
+func (m *DefensesModule) regionsWithoutCloudTrail() ([]string, []string) {
+	var uncovered, unknown []string
+	for _, control := range m.Controls {
+		if control.Control != "CloudTrail" {
+			continue
+		}
+		switch control.Status {
+		case defenseStatusEnabled:
+		case defenseStatusAccessDenied, defenseStatusError:
+			unknown = append(unknown, control.Region)
+		default:
+			uncovered = append(uncovered, control.Region)
+		}
+	}
+	return uncovered, unknown
+}
+
+func (m *DefensesModule) Receiver(receiver chan DefenseControl, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Controls = append(m.Controls, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+// executeChecks runs every control check in a region. All of these services are available in every region,
+// so there is no service map lookup.
+func (m *DefensesModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan DefenseControl) {
+	defer wg.Done()
+
+	checks := []func(string) DefenseControl{
+		m.getGuardDutyStatus,
+		m.getCloudTrailStatus,
+		m.getConfigStatus,
+		m.getSecurityHubStatus,
+		m.getAccessAnalyzerStatus,
+	}
+	for _, check := range checks {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.runCheck(r, check, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *DefensesModule) runCheck(r string, check func(string) DefenseControl, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan DefenseControl) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	control := check(r)
+	control.Region = r
+	dataReceiver <- control
+}
+
+// errorControl turns an API error into a control row. Read access to security tooling is often restricted,
+// so AccessDenied is expected and not counted as an error.
+func (m *DefensesModule) errorControl(control string, err error) DefenseControl {
+	if isAccessDeniedError(err) {
+		return DefenseControl{
+			Control: control,
+			Status:  defenseStatusAccessDenied,
+		}
+	}
+	m.modLog.Error(err.Error())
+	m.CommandCounter.Error++
+	return DefenseControl{
+		Control: control,
+		Status:  defenseStatusError,
+	}
+}
+
+func (m *DefensesModule) getGuardDutyStatus(r string) DefenseControl {
+	control := "GuardDuty"
+	detectors, err := sdk.CachedGuardDutyListDetectors(m.GuardDutyClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		return m.errorControl(control, err)
+	}
+	if len(detectors) == 0 {
+		return DefenseControl{Control: control, Status: defenseStatusNotConfigured}
+	}
+
+	status := defenseStatusDisabled
+	var details []string
+	for _, detectorID := range detectors {
+		detector, err := sdk.CachedGuardDutyGetDetector(m.GuardDutyClient, aws.ToString(m.Caller.Account), r, detectorID)
+		if err != nil {
+			return m.errorControl(control, err)
+		}
+		if detector.Status == string(guardDutyTypes.DetectorStatusEnabled) {
+			status = defenseStatusEnabled
+		}
+		details = append(details, fmt.Sprintf("Detector %s, findings published every %s", detectorID, detector.FindingPublishingFrequency))
+	}
+	return DefenseControl{Control: control, Status: status, Details: strings.Join(details, "\n")}
+}
+
+func (m *DefensesModule) getCloudTrailStatus(r string) DefenseControl {
+	control := "CloudTrail"
+	trails, err := sdk.CachedCloudTrailDescribeTrails(m.CloudTrailClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		return m.errorControl(control, err)
+	}
+	if len(trails) == 0 {
+		return DefenseControl{Control: control, Status: defenseStatusNotConfigured}
+	}
+
+	status := defenseStatusDisabled
+	var details []string
+	for _, trail := range trails {
+		logging := "unknown"
+		isLogging, err := sdk.CachedCloudTrailGetTrailIsLogging(m.CloudTrailClient, aws.ToString(m.Caller.Account), aws.ToString(trail.HomeRegion), aws.ToString(trail.TrailARN))
+		if err != nil {
+			if !isAccessDeniedError(err) {
+				m.modLog.Error(err.Error())
+				m.CommandCounter.Error++
+			}
+		} else if isLogging {
+			logging = "logging"
+			status = defenseStatusEnabled
+		} else {
+			logging = "not logging"
+		}
+
+		var scope []string
+		if aws.ToBool(trail.IsMultiRegionTrail) {
+			scope = append(scope, "multi-region")
+		}
+		if aws.ToBool(trail.IsOrganizationTrail) {
+			scope = append(scope, "organization")
+		}
+		if len(scope) > 0 {
+			logging = logging + ", " + strings.Join(scope, ", ")
+		}
+
+		kmsKey := aws.ToString(trail.KmsKeyId)
+		if kmsKey == "" {
+			kmsKey = "none"
+		}
+		details = append(details, fmt.Sprintf("%s (%s) s3://%s KMS: %s", aws.ToString(trail.Name), logging, aws.ToString(trail.S3BucketName), kmsKey))
+	}
+	return DefenseControl{Control: control, Status: status, Details: strings.Join(details, "\n")}
+}
+
+func (m *DefensesModule) getConfigStatus(r string) DefenseControl {
+	control := "Config"
+	recorders, err := sdk.CachedConfigServiceDescribeConfigurationRecorderStatus(m.ConfigClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		return m.errorControl(control, err)
+	}
+	if len(recorders) == 0 {
+		return DefenseControl{Control: control, Status: defenseStatusNotConfigured}
+	}
+
+	status := defenseStatusDisabled
+	var details []string
+	for _, recorder := range recorders {
+		recording := "stopped"
+		if recorder.Recording {
+			recording = "recording"
+			status = defenseStatusEnabled
+		}
+		details = append(details, fmt.Sprintf("Recorder %s (%s, last status %s)", aws.ToString(recorder.Name), recording, recorder.LastStatus))
+	}
+	return DefenseControl{Control: control, Status: status, Details: strings.Join(details, "\n")}
+}
+
+func (m *DefensesModule) getSecurityHubStatus(r string) DefenseControl {
+	control := "Security Hub"
+	hub, err := sdk.CachedSecurityHubDescribeHub(m.SecurityHubClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		// DescribeHub returns one of these when the account isn't subscribed to Security Hub in the region
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && (apiErr.ErrorCode() == "InvalidAccessException" || apiErr.ErrorCode() == "ResourceNotFoundException") {
+			return DefenseControl{Control: control, Status: defenseStatusDisabled}
+		}
+		return m.errorControl(control, err)
+	}
+
+	details := hub.HubArn
+	if hub.SubscribedAt != "" {
+		details = fmt.Sprintf("%s (enabled %s)", hub.HubArn, hub.SubscribedAt)
+	}
+	return DefenseControl{Control: control, Status: defenseStatusEnabled, Details: details}
+}
+
+func (m *DefensesModule) getAccessAnalyzerStatus(r string) DefenseControl {
+	control := "Access Analyzer"
+	analyzers, err := sdk.CachedAccessAnalyzerListAnalyzers(m.AccessAnalyzerClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		return m.errorControl(control, err)
+	}
+	if len(analyzers) == 0 {
+		return DefenseControl{Control: control, Status: defenseStatusNotConfigured}
+	}
+
+	status := defenseStatusDisabled
+	var details []string
+	for _, analyzer := range analyzers {
+		if analyzer.Status == accessAnalyzerTypes.AnalyzerStatusActive {
+			status = defenseStatusEnabled
+		}
+		details = append(details, fmt.Sprintf("%s (%s, %s)", aws.ToString(analyzer.Name), analyzer.Type, analyzer.Status))
+	}
+	return DefenseControl{Control: control, Status: status, Details: strings.Join(details, "\n")}
+}
@@ -0,0 +1,103 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/guardduty"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+)
+
+type MockedDeniedGuardDutyClient struct {
+}
+
+func (m *MockedDeniedGuardDutyClient) ListDetectors(ctx context.Context, input *guardduty.ListDetectorsInput, options ...func(*guardduty.Options)) (*guardduty.ListDetectorsOutput, error) {
+	return nil, &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized to perform: guardduty:ListDetectors"}
+}
+
+func (m *MockedDeniedGuardDutyClient) GetDetector(ctx context.Context, input *guardduty.GetDetectorInput, options ...func(*guardduty.Options)) (*guardduty.GetDetectorOutput, error) {
+	return nil, &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized to perform: guardduty:GetDetector"}
+}
+
+func TestDefenses(t *testing.T) {
+	internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m := DefensesModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1", "us-west-1", "us-west-2"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:           3,
+		GuardDutyClient:      &sdk.MockedGuardDutyClient{},
+		CloudTrailClient:     &sdk.MockedCloudTrailClient{},
+		ConfigClient:         &sdk.MockedConfigServiceClient{},
+		SecurityHubClient:    &sdk.MockedSecurityHubClient{},
+		AccessAnalyzerClient: &sdk.MockedAccessAnalyzerClient{},
+	}
+	m.PrintDefenses(".", 2)
+
+	if len(m.Controls) != 15 {
+		t.Fatalf("Expected 15 controls (5 per region), got %d", len(m.Controls))
+	}
+
+	expectedStatuses := map[string]string{
+		"us-east-1 CloudTrail":   defenseStatusEnabled,
+		"us-west-1 CloudTrail":   defenseStatusNotConfigured,
+		"us-west-2 CloudTrail":   defenseStatusDisabled,
+		"us-east-1 Security Hub": defenseStatusEnabled,
+		"us-west-1 Security Hub": defenseStatusDisabled,
+		"us-east-1 GuardDuty":    defenseStatusEnabled,
+		"us-east-1 Config":       defenseStatusEnabled,
+	}
+	for _, control := range m.Controls {
+		expected, ok := expectedStatuses[control.Region+" "+control.Control]
+		if ok && control.Status != expected {
+			t.Errorf("Expected %s %s to be %s, got %s", control.Region, control.Control, expected, control.Status)
+		}
+	}
+
+	uncovered, unknown := m.regionsWithoutCloudTrail()
+	if len(uncovered) != 2 || uncovered[0] != "us-west-1" || uncovered[1] != "us-west-2" {
+		t.Errorf("Expected us-west-1 and us-west-2 to have no CloudTrail coverage, got %v", uncovered)
+	}
+	if len(unknown) != 0 {
+		t.Errorf("Did not expect regions with unknown CloudTrail coverage, got %v", unknown)
+	}
+}
+
+func TestDefensesAccessDenied(t *testing.T) {
+	internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m := DefensesModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"eu-west-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:           3,
+		GuardDutyClient:      &MockedDeniedGuardDutyClient{},
+		CloudTrailClient:     &sdk.MockedCloudTrailClient{},
+		ConfigClient:         &sdk.MockedConfigServiceClient{},
+		SecurityHubClient:    &sdk.MockedSecurityHubClient{},
+		AccessAnalyzerClient: &sdk.MockedAccessAnalyzerClient{},
+	}
+	m.PrintDefenses(".", 2)
+
+	for _, control := range m.Controls {
+		if control.Control == "GuardDuty" && control.Status != defenseStatusAccessDenied {
+			t.Errorf("Expected GuardDuty to be reported as access denied, got %s", control.Status)
+		}
+	}
+	if m.CommandCounter.Error != 0 {
+		t.Errorf("Did not expect AccessDenied to be counted as an error")
+	}
+}
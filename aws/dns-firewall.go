@@ -0,0 +1,467 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	route53resolverTypes "github.com/aws/aws-sdk-go-v2/service/route53resolver/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type DNSFirewallModule struct {
+	// General configuration data
+	Route53ResolverClient sdk.Route53ResolverClientInterface
+	EC2Client             sdk.AWSEC2RoutingClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	VPCProtections []DNSFirewallVPC
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+// DNSFirewallVPC is a VPC together with one of the rule groups associated with it. VPCs without a rule group get a
+// single entry with an empty RuleGroup.
+type DNSFirewallVPC struct {
+	Region            string
+	VpcID             string
+	VpcName           string
+	RuleGroup         string
+	RuleGroupPriority int32
+	DomainLists       []string
+	ManagedLists      bool
+	PermissiveAllows  []string
+	Finding           string
+}
+
+// dnsFirewallRuleGroup is what we learn about a rule group once, so every VPC it is associated with can reuse it
+type dnsFirewallRuleGroup struct {
+	Name             string
+	DomainLists      []string
+	ManagedLists     bool
+	PermissiveAllows []string
+	RulesError       bool
+}
+
+const (
+	dnsFirewallFindingUnprotected = "No DNS firewall, can resolve malware C2 domains"
+	dnsFirewallFindingNoManaged   = "No AWS managed domain lists"
+	dnsFirewallFindingUnknown     = "Rules could not be listed"
+)
+
+func (m *DNSFirewallModule) PrintDNSFirewall(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "dns-firewall"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating Route53 Resolver DNS firewall rule groups and VPC associations for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan DNSFirewallVPC)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.VPCProtections, func(i, j int) bool {
+		if m.VPCProtections[i].Region != m.VPCProtections[j].Region {
+			return m.VPCProtections[i].Region < m.VPCProtections[j].Region
+		}
+		if m.VPCProtections[i].VpcID != m.VPCProtections[j].VpcID {
+			return m.VPCProtections[i].VpcID < m.VPCProtections[j].VpcID
+		}
+		return m.VPCProtections[i].RuleGroupPriority < m.VPCProtections[j].RuleGroupPriority
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"VPC",
+		"VPC Name",
+		"Rule Group",
+		"Priority",
+		"Domain Lists",
+		"Managed Lists",
+		"Permissive Allows",
+		"Finding",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"VPC",
+			"VPC Name",
+			"Rule Group",
+			"Priority",
+			"Domain Lists",
+			"Managed Lists",
+			"Permissive Allows",
+			"Finding",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"VPC",
+			"Rule Group",
+			"Domain Lists",
+			"Managed Lists",
+			"Finding",
+		}
+	}
+
+	var unprotected int
+	// Table rows
+	for i := range m.VPCProtections {
+		ruleGroup, priority := "-", "-"
+		if m.VPCProtections[i].RuleGroup == "" {
+			unprotected++
+		} else {
+			ruleGroup = m.VPCProtections[i].RuleGroup
+			priority = strconv.Itoa(int(m.VPCProtections[i].RuleGroupPriority))
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				m.VPCProtections[i].Region,
+				m.VPCProtections[i].VpcID,
+				m.VPCProtections[i].VpcName,
+				ruleGroup,
+				priority,
+				dnsFirewallListColumn(m.VPCProtections[i].DomainLists),
+				dnsFirewallManagedListsColumn(m.VPCProtections[i]),
+				dnsFirewallListColumn(m.VPCProtections[i].PermissiveAllows),
+				m.VPCProtections[i].Finding,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s VPC and rule group combinations found, %s VPCs without DNS firewall protection.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)), strconv.Itoa(unprotected))
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No VPCs found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *DNSFirewallModule) Receiver(receiver chan DNSFirewallVPC, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.VPCProtections = append(m.VPCProtections, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *DNSFirewallModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan DNSFirewallVPC) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("route53resolver", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getDNSFirewallPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *DNSFirewallModule) getDNSFirewallPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan DNSFirewallVPC) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	vpcs, err := sdk.CachedEC2DescribeVpcs(m.EC2Client, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	if len(vpcs) == 0 {
+		return
+	}
+
+	associations, err := sdk.CachedRoute53ResolverListFirewallRuleGroupAssociations(m.Route53ResolverClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	ruleGroups := m.getRuleGroups(r)
+
+	associationsPerVPC := make(map[string][]route53resolverTypes.FirewallRuleGroupAssociation)
+	for _, association := range associations {
+		// A rule group that is being removed no longer protects the VPC
+		if association.Status == route53resolverTypes.FirewallRuleGroupAssociationStatusDeleting {
+			continue
+		}
+		vpcID := aws.ToString(association.VpcId)
+		associationsPerVPC[vpcID] = append(associationsPerVPC[vpcID], association)
+	}
+
+	for _, vpc := range vpcs {
+		vpcID := aws.ToString(vpc.VpcId)
+		vpcName := ""
+		for _, tag := range vpc.Tags {
+			if aws.ToString(tag.Key) == "Name" {
+				vpcName = aws.ToString(tag.Value)
+			}
+		}
+
+		if len(associationsPerVPC[vpcID]) == 0 {
+			dataReceiver <- DNSFirewallVPC{
+				Region:  r,
+				VpcID:   vpcID,
+				VpcName: vpcName,
+				Finding: dnsFirewallFindingUnprotected,
+			}
+			continue
+		}
+
+		for _, association := range associationsPerVPC[vpcID] {
+			ruleGroupID := aws.ToString(association.FirewallRuleGroupId)
+			ruleGroup, ok := ruleGroups[ruleGroupID]
+			if !ok {
+				// Rule groups shared with this account through RAM are not always listed
+				ruleGroup = dnsFirewallRuleGroup{Name: ruleGroupID, RulesError: true}
+			}
+			dataReceiver <- DNSFirewallVPC{
+				Region:            r,
+				VpcID:             vpcID,
+				VpcName:           vpcName,
+				RuleGroup:         ruleGroup.Name,
+				RuleGroupPriority: aws.ToInt32(association.Priority),
+				DomainLists:       ruleGroup.DomainLists,
+				ManagedLists:      ruleGroup.ManagedLists,
+				PermissiveAllows:  ruleGroup.PermissiveAllows,
+				Finding:           dnsFirewallRuleGroupFinding(ruleGroup),
+			}
+		}
+	}
+}
+
+// getRuleGroups reads the rules of every rule group in the region and resolves the domain lists they reference
+func (m *DNSFirewallModule) getRuleGroups(r string) map[string]dnsFirewallRuleGroup {
+	ruleGroups := make(map[string]dnsFirewallRuleGroup)
+
+	ruleGroupMetadata, err := sdk.CachedRoute53ResolverListFirewallRuleGroups(m.Route53ResolverClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return ruleGroups
+	}
+
+	domainLists, err := sdk.CachedRoute53ResolverListFirewallDomainLists(m.Route53ResolverClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	domainListsByID := make(map[string]route53resolverTypes.FirewallDomainListMetadata)
+	for _, domainList := range domainLists {
+		domainListsByID[aws.ToString(domainList.Id)] = domainList
+	}
+
+	for _, metadata := range ruleGroupMetadata {
+		ruleGroupID := aws.ToString(metadata.Id)
+		rules, err := sdk.CachedRoute53ResolverListFirewallRules(m.Route53ResolverClient, aws.ToString(m.Caller.Account), r, ruleGroupID)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			ruleGroups[ruleGroupID] = dnsFirewallRuleGroup{Name: aws.ToString(metadata.Name), RulesError: true}
+			continue
+		}
+
+		domainsByList := make(map[string][]string)
+		for _, rule := range rules {
+			domainListID := aws.ToString(rule.FirewallDomainListId)
+			if rule.Action != route53resolverTypes.ActionAllow || aws.ToString(domainListsByID[domainListID].ManagedOwnerName) != "" {
+				continue
+			}
+			if _, ok := domainsByList[domainListID]; ok {
+				continue
+			}
+			domains, err := sdk.CachedRoute53ResolverListFirewallDomains(m.Route53ResolverClient, aws.ToString(m.Caller.Account), r, domainListID)
+			if err != nil {
+				m.modLog.Error(err.Error())
+			}
+			domainsByList[domainListID] = domains
+		}
+
+		ruleGroup := analyzeDNSFirewallRules(rules, domainListsByID, domainsByList)
+		ruleGroup.Name = aws.ToString(metadata.Name)
+		ruleGroups[ruleGroupID] = ruleGroup
+	}
+	return ruleGroups
+}
+
+// analyzeDNSFirewallRules summarizes which domain lists a rule group applies, whether any of them are the AWS managed
+// threat lists, and which ALLOW rules match so broadly that queries for C2 domains are let through. Rules are
+// evaluated by priority and the first match wins, so an ALLOW for "*" in front of the BLOCK rules disables them.
+func analyzeDNSFirewallRules(rules []route53resolverTypes.FirewallRule, domainLists map[string]route53resolverTypes.FirewallDomainListMetadata, domainsByList map[string][]string) dnsFirewallRuleGroup {
+	sort.Slice(rules, func(i, j int) bool {
+		return aws.ToInt32(rules[i].Priority) < aws.ToInt32(rules[j].Priority)
+	})
+
+	var ruleGroup dnsFirewallRuleGroup
+	for _, rule := range rules {
+		domainListID := aws.ToString(rule.FirewallDomainListId)
+		domainList, ok := domainLists[domainListID]
+		name := domainListID
+		if ok {
+			name = aws.ToString(domainList.Name)
+		}
+		ruleGroup.DomainLists = append(ruleGroup.DomainLists, fmt.Sprintf("%s (%s)", name, rule.Action))
+
+		if aws.ToString(domainList.ManagedOwnerName) != "" && rule.Action != route53resolverTypes.ActionAllow {
+			ruleGroup.ManagedLists = true
+		}
+
+		if rule.Action != route53resolverTypes.ActionAllow {
+			continue
+		}
+		for _, domain := range domainsByList[domainListID] {
+			if isPermissiveDNSFirewallDomain(domain) {
+				ruleGroup.PermissiveAllows = append(ruleGroup.PermissiveAllows, fmt.Sprintf("%s at priority %d", domain, aws.ToInt32(rule.Priority)))
+			}
+		}
+	}
+	return ruleGroup
+}
+
+// isPermissiveDNSFirewallDomain reports whether a domain list entry matches every domain or a whole top level domain,
+// e.g. "*" or "*.com"
+func isPermissiveDNSFirewallDomain(domain string) bool {
+	domain = strings.TrimSuffix(domain, ".")
+	if domain == "*" {
+		return true
+	}
+	return strings.HasPrefix(domain, "*.") && !strings.Contains(strings.TrimPrefix(domain, "*."), ".")
+}
+
+func dnsFirewallRuleGroupFinding(ruleGroup dnsFirewallRuleGroup) string {
+	if ruleGroup.RulesError {
+		return dnsFirewallFindingUnknown
+	}
+	var findings []string
+	if !ruleGroup.ManagedLists {
+		findings = append(findings, dnsFirewallFindingNoManaged)
+	}
+	if len(ruleGroup.PermissiveAllows) > 0 {
+		findings = append(findings, "Permissive ALLOW rule")
+	}
+	return strings.Join(findings, ", ")
+}
+
+func dnsFirewallManagedListsColumn(vpc DNSFirewallVPC) string {
+	switch {
+	case vpc.RuleGroup == "" || vpc.Finding == dnsFirewallFindingUnknown:
+		return "-"
+	case vpc.ManagedLists:
+		return "Yes"
+	}
+	return "No"
+}
+
+func dnsFirewallListColumn(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	return strings.Join(values, ", ")
+}
@@ -0,0 +1,97 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+func TestDNSFirewall(t *testing.T) {
+	m := DNSFirewallModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:            3,
+		WrapTable:             false,
+		Route53ResolverClient: &sdk.MockedRoute53ResolverClient{},
+		EC2Client:             &sdk.MockedEC2RoutingClient{},
+	}
+
+	internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintDNSFirewall(".", 2)
+
+	expected := map[string]struct {
+		ruleGroup        string
+		domainLists      string
+		managedLists     bool
+		permissiveAllows string
+		finding          string
+	}{
+		"vpc-11111111": {
+			ruleGroup:    "baseline-protection",
+			domainLists:  "corp-domains (ALLOW), AWSManagedDomainsMalwareDomainList (BLOCK), AWSManagedDomainsBotnetCommandandControl (BLOCK)",
+			managedLists: true,
+		},
+		"vpc-22222222": {
+			ruleGroup:        "dev-allow-all",
+			domainLists:      "allow-everything (ALLOW), corp-domains (ALERT)",
+			permissiveAllows: "* at priority 10",
+			finding:          "No AWS managed domain lists, Permissive ALLOW rule",
+		},
+		// The only association of this VPC is being deleted
+		"vpc-33333333": {
+			finding: dnsFirewallFindingUnprotected,
+		},
+	}
+	if len(m.VPCProtections) != len(expected) {
+		t.Fatalf("Expected %d VPC entries, got %d", len(expected), len(m.VPCProtections))
+	}
+	for _, vpc := range m.VPCProtections {
+		want, ok := expected[vpc.VpcID]
+		if !ok {
+			t.Errorf("Unexpected VPC %s", vpc.VpcID)
+			continue
+		}
+		if vpc.RuleGroup != want.ruleGroup {
+			t.Errorf("VPC %s: expected rule group %q, got %q", vpc.VpcID, want.ruleGroup, vpc.RuleGroup)
+		}
+		if domainLists := strings.Join(vpc.DomainLists, ", "); domainLists != want.domainLists {
+			t.Errorf("VPC %s: expected domain lists %q, got %q", vpc.VpcID, want.domainLists, domainLists)
+		}
+		if vpc.ManagedLists != want.managedLists {
+			t.Errorf("VPC %s: expected managed lists to be %t", vpc.VpcID, want.managedLists)
+		}
+		if permissiveAllows := strings.Join(vpc.PermissiveAllows, ", "); permissiveAllows != want.permissiveAllows {
+			t.Errorf("VPC %s: expected permissive allows %q, got %q", vpc.VpcID, want.permissiveAllows, permissiveAllows)
+		}
+		if vpc.Finding != want.finding {
+			t.Errorf("VPC %s: expected finding %q, got %q", vpc.VpcID, want.finding, vpc.Finding)
+		}
+	}
+}
+
+func TestIsPermissiveDNSFirewallDomain(t *testing.T) {
+	subtests := map[string]bool{
+		"*":                  true,
+		"*.":                 true,
+		"*.com":              true,
+		"*.com.":             true,
+		"*.example.com":      false,
+		"*.corp.example.com": false,
+		"example.com":        false,
+	}
+	for domain, expected := range subtests {
+		if permissive := isPermissiveDNSFirewallDomain(domain); permissive != expected {
+			t.Errorf("Expected %q to be permissive: %t, got %t", domain, expected, permissive)
+		}
+	}
+}
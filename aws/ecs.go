@@ -0,0 +1,524 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ecsTypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type ECSModule struct {
+	// General configuration data
+	ECSClient sdk.AWSECSClientInterface
+	IAMClient sdk.AWSIAMClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	ECSServices    []ECSService
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+// ECSService is an ECS service, or a group of standalone tasks that were started from the same task definition family
+type ECSService struct {
+	Region             string
+	Cluster            string
+	Name               string
+	LaunchType         string
+	RunningTasks       int
+	TaskDefinition     string
+	TaskRole           string
+	ExecutionRole      string
+	ServiceExecEnabled bool
+	ExecTasks          []ECSExecTask
+	BroadPolicies      []string
+}
+
+// ECSExecTask is a running task that has ECS Exec enabled, so every container in it can be reached with execute-command
+type ECSExecTask struct {
+	ID         string
+	Containers []string
+}
+
+// Managed policies that give a task role far more than a container should need
+var broadManagedPolicyNames = []string{
+	"AdministratorAccess",
+	"PowerUserAccess",
+	"IAMFullAccess",
+}
+
+func (m *ECSModule) PrintECS(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "ecs"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating ECS clusters, services and tasks for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan ECSService)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	m.flagBroadTaskRoles()
+
+	sort.Slice(m.ECSServices, func(i, j int) bool {
+		if m.ECSServices[i].Region != m.ECSServices[j].Region {
+			return m.ECSServices[i].Region < m.ECSServices[j].Region
+		}
+		if m.ECSServices[i].Cluster != m.ECSServices[j].Cluster {
+			return m.ECSServices[i].Cluster < m.ECSServices[j].Cluster
+		}
+		return m.ECSServices[i].Name < m.ECSServices[j].Name
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Cluster",
+		"Service",
+		"Launch Type",
+		"Running Tasks",
+		"Task Definition",
+		"Task Role",
+		"Execution Role",
+		"Exec Enabled",
+		"Broad Task Role",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Cluster",
+			"Service",
+			"Launch Type",
+			"Running Tasks",
+			"Task Definition",
+			"Task Role",
+			"Execution Role",
+			"Exec Enabled",
+			"Broad Task Role",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Cluster",
+			"Service",
+			"Launch Type",
+			"Running Tasks",
+			"Task Role",
+			"Exec Enabled",
+			"Broad Task Role",
+		}
+	}
+
+	// Table rows
+	for i := range m.ECSServices {
+		broadPolicies := strings.Join(m.ECSServices[i].BroadPolicies, ", ")
+		if broadPolicies != "" {
+			broadPolicies = magenta(broadPolicies)
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				m.ECSServices[i].Region,
+				m.ECSServices[i].Cluster,
+				m.ECSServices[i].Name,
+				m.ECSServices[i].LaunchType,
+				strconv.Itoa(m.ECSServices[i].RunningTasks),
+				m.ECSServices[i].TaskDefinition,
+				m.ECSServices[i].TaskRole,
+				m.ECSServices[i].ExecutionRole,
+				m.ECSServices[i].execEnabled(),
+				broadPolicies,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     "ecs-commands",
+			Contents: m.writeLoot(),
+		})
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %s ECS services and task groups found, %d have ECS Exec enabled.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)), m.countExecEnabled())
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No ECS clusters found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *ECSModule) Receiver(receiver chan ECSService, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.ECSServices = append(m.ECSServices, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *ECSModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan ECSService) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("ecs", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getECSServicesPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *ECSModule) getECSServicesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan ECSService) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	clusterArns, err := sdk.CachedECSListClusters(m.ECSClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, clusterArn := range clusterArns {
+		for _, ecsService := range m.getClusterServices(clusterArn, r) {
+			ecsService.Region = r
+			ecsService.Cluster = getNameFromARN(clusterArn)
+			dataReceiver <- ecsService
+		}
+	}
+}
+
+// getClusterServices returns every service in the cluster along with the running tasks that belong to it. Tasks that
+// weren't started by a service are grouped by their task group, which is the task definition family by default.
+func (m *ECSModule) getClusterServices(clusterArn string, r string) []ECSService {
+	serviceArns, err := sdk.CachedECSListServices(m.ECSClient, aws.ToString(m.Caller.Account), r, clusterArn)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	var services []ecsTypes.Service
+	if len(serviceArns) > 0 {
+		services, err = sdk.CachedECSDescribeServices(m.ECSClient, aws.ToString(m.Caller.Account), r, clusterArn, serviceArns)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+	}
+
+	tasks := m.describeClusterTasks(clusterArn, r)
+
+	var ecsServices []ECSService
+	tasksByGroup := make(map[string][]ecsTypes.Task)
+	var groups []string
+	for _, task := range tasks {
+		group := aws.ToString(task.Group)
+		if _, ok := tasksByGroup[group]; !ok {
+			groups = append(groups, group)
+		}
+		tasksByGroup[group] = append(tasksByGroup[group], task)
+	}
+
+	for _, service := range services {
+		group := "service:" + aws.ToString(service.ServiceName)
+		ecsService := ECSService{
+			Name:               aws.ToString(service.ServiceName),
+			LaunchType:         string(service.LaunchType),
+			RunningTasks:       int(service.RunningCount),
+			TaskDefinition:     aws.ToString(service.TaskDefinition),
+			ServiceExecEnabled: service.EnableExecuteCommand,
+			ExecTasks:          getECSExecTasks(tasksByGroup[group]),
+		}
+		if ecsService.LaunchType == "" && len(service.CapacityProviderStrategy) > 0 {
+			ecsService.LaunchType = "CAPACITY_PROVIDER"
+		}
+		m.addTaskDefinitionRoles(&ecsService, r)
+		ecsServices = append(ecsServices, ecsService)
+		delete(tasksByGroup, group)
+	}
+
+	for _, group := range groups {
+		groupTasks, ok := tasksByGroup[group]
+		if !ok {
+			continue
+		}
+		ecsService := ECSService{
+			Name:           group,
+			LaunchType:     string(groupTasks[0].LaunchType),
+			RunningTasks:   len(groupTasks),
+			TaskDefinition: aws.ToString(groupTasks[0].TaskDefinitionArn),
+			ExecTasks:      getECSExecTasks(groupTasks),
+		}
+		m.addTaskDefinitionRoles(&ecsService, r)
+		ecsServices = append(ecsServices, ecsService)
+	}
+
+	// Show empty clusters too, they still tell us where workloads are deployed
+	if len(ecsServices) == 0 {
+		ecsServices = append(ecsServices, ECSService{})
+	}
+	return ecsServices
+}
+
+// describeClusterTasks lists every running task in the cluster and describes them in batches of 100, the most
+// DescribeTasks accepts in one call
+func (m *ECSModule) describeClusterTasks(clusterArn string, r string) []ecsTypes.Task {
+	taskArns, err := sdk.CachedECSListTasks(m.ECSClient, aws.ToString(m.Caller.Account), r, clusterArn)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return nil
+	}
+
+	var tasks []ecsTypes.Task
+	batchSize := 100 // maximum value: https://docs.aws.amazon.com/AmazonECS/latest/APIReference/API_DescribeTasks.html#API_DescribeTasks_RequestSyntax
+	for i := 0; i < len(taskArns); i += batchSize {
+		j := i + batchSize
+		if j > len(taskArns) {
+			j = len(taskArns)
+		}
+
+		batch, err := sdk.CachedECSDescribeTasks(m.ECSClient, aws.ToString(m.Caller.Account), r, clusterArn, taskArns[i:j])
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		tasks = append(tasks, batch...)
+	}
+	return tasks
+}
+
+func (m *ECSModule) addTaskDefinitionRoles(ecsService *ECSService, r string) {
+	if ecsService.TaskDefinition == "" {
+		return
+	}
+	taskDefinition, err := sdk.CachedECSDescribeTaskDefinition(m.ECSClient, aws.ToString(m.Caller.Account), r, ecsService.TaskDefinition)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	ecsService.TaskRole = aws.ToString(taskDefinition.TaskRoleArn)
+	ecsService.ExecutionRole = aws.ToString(taskDefinition.ExecutionRoleArn)
+}
+
+// flagBroadTaskRoles looks up the managed policies attached to every task role. The lookup is done once per account
+// after all regions have been enumerated because IAM is global.
+func (m *ECSModule) flagBroadTaskRoles() {
+	if m.IAMClient == nil {
+		return
+	}
+	var hasTaskRoles bool
+	for _, ecsService := range m.ECSServices {
+		if ecsService.TaskRole != "" {
+			hasTaskRoles = true
+			break
+		}
+	}
+	if !hasTaskRoles {
+		return
+	}
+
+	authorizationDetails, err := sdk.CachedIAMGetAccountAuthorizationDetails(m.IAMClient, aws.ToString(m.Caller.Account))
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	broadPolicies := make(map[string][]string)
+	for _, role := range authorizationDetails.RoleDetailList {
+		broadPolicies[aws.ToString(role.Arn)] = getBroadManagedPolicies(role.AttachedManagedPolicies)
+	}
+	for i := range m.ECSServices {
+		m.ECSServices[i].BroadPolicies = broadPolicies[m.ECSServices[i].TaskRole]
+	}
+}
+
+func getBroadManagedPolicies(policies []iamTypes.AttachedPolicy) []string {
+	var broad []string
+	for _, policy := range policies {
+		if internal.Contains(aws.ToString(policy.PolicyName), broadManagedPolicyNames) {
+			broad = append(broad, aws.ToString(policy.PolicyName))
+		}
+	}
+	return broad
+}
+
+func getECSExecTasks(tasks []ecsTypes.Task) []ECSExecTask {
+	var execTasks []ECSExecTask
+	for _, task := range tasks {
+		if !task.EnableExecuteCommand {
+			continue
+		}
+		execTask := ECSExecTask{
+			ID: getIDFromECSTask(aws.ToString(task.TaskArn)),
+		}
+		for _, container := range task.Containers {
+			execTask.Containers = append(execTask.Containers, aws.ToString(container.Name))
+		}
+		execTasks = append(execTasks, execTask)
+	}
+	return execTasks
+}
+
+// execEnabled describes where ECS Exec is turned on. Enabling it on the service only affects tasks started after the
+// change, so running tasks are counted separately.
+func (s ECSService) execEnabled() string {
+	switch {
+	case s.ServiceExecEnabled && len(s.ExecTasks) > 0:
+		return fmt.Sprintf("Service, %d/%d tasks", len(s.ExecTasks), s.RunningTasks)
+	case s.ServiceExecEnabled:
+		return "Service"
+	case len(s.ExecTasks) > 0:
+		return fmt.Sprintf("%d/%d tasks", len(s.ExecTasks), s.RunningTasks)
+	default:
+		return "No"
+	}
+}
+
+func (m *ECSModule) countExecEnabled() int {
+	var count int
+	for _, ecsService := range m.ECSServices {
+		if ecsService.ServiceExecEnabled || len(ecsService.ExecTasks) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func (m *ECSModule) writeLoot() string {
+	var out string
+	out = out + fmt.Sprintln("#############################################")
+	out = out + fmt.Sprintln("# The profile you will use to perform these commands is most likely not the profile you used to run CloudFox")
+	out = out + fmt.Sprintln("# Set the $profile environment variable to the profile you are going to use to access the containers.")
+	out = out + fmt.Sprintln("# E.g., export profile=dev-prod.")
+	out = out + fmt.Sprintln("# execute-command needs the Session Manager plugin for the AWS CLI.")
+	out = out + fmt.Sprintln("#############################################")
+	out = out + fmt.Sprintln("")
+
+	out = out + fmt.Sprintln("# Get a shell in the containers of tasks that have ECS Exec enabled")
+	for _, ecsService := range m.ECSServices {
+		for _, task := range ecsService.ExecTasks {
+			for _, container := range task.Containers {
+				out = out + fmt.Sprintf("aws --profile $profile --region %s ecs execute-command --cluster %s --task %s --container %s --interactive --command /bin/sh\n", ecsService.Region, ecsService.Cluster, task.ID, container)
+			}
+		}
+	}
+	out = out + fmt.Sprintln("")
+
+	out = out + fmt.Sprintln("# Task definitions, look for secrets in the environment variables and the roles the containers run as")
+	seen := make(map[string]bool)
+	for _, ecsService := range m.ECSServices {
+		if ecsService.TaskDefinition == "" || seen[ecsService.TaskDefinition] {
+			continue
+		}
+		seen[ecsService.TaskDefinition] = true
+		out = out + fmt.Sprintf("aws --profile $profile --region %s ecs describe-task-definition --task-definition %s\n", ecsService.Region, ecsService.TaskDefinition)
+	}
+
+	return out
+}
@@ -0,0 +1,227 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecsTypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+const (
+	testECSCluster    = "arn:aws:ecs:us-east-1:111111111111:cluster/prod"
+	testECSWorkerTask = 101
+)
+
+// mockedECSClient has one cluster with an exec-enabled api service, a worker service with more tasks than fit in one
+// DescribeTasks call and a standalone migration task
+type mockedECSClient struct {
+	sdk.MockedECSClient
+	largestDescribeTasksBatch int
+}
+
+func testECSTaskArn(i int) string {
+	return fmt.Sprintf("arn:aws:ecs:us-east-1:111111111111:task/prod/%032d", i)
+}
+
+func (c *mockedECSClient) ListClusters(context.Context, *ecs.ListClustersInput, ...func(*ecs.Options)) (*ecs.ListClustersOutput, error) {
+	return &ecs.ListClustersOutput{ClusterArns: []string{testECSCluster}}, nil
+}
+
+func (c *mockedECSClient) ListServices(ctx context.Context, input *ecs.ListServicesInput, f ...func(*ecs.Options)) (*ecs.ListServicesOutput, error) {
+	return &ecs.ListServicesOutput{ServiceArns: []string{
+		"arn:aws:ecs:us-east-1:111111111111:service/prod/api",
+		"arn:aws:ecs:us-east-1:111111111111:service/prod/worker",
+	}}, nil
+}
+
+func (c *mockedECSClient) DescribeServices(ctx context.Context, input *ecs.DescribeServicesInput, f ...func(*ecs.Options)) (*ecs.DescribeServicesOutput, error) {
+	return &ecs.DescribeServicesOutput{Services: []ecsTypes.Service{
+		{
+			ServiceName:          aws.String("api"),
+			LaunchType:           ecsTypes.LaunchTypeFargate,
+			RunningCount:         1,
+			TaskDefinition:       aws.String("arn:aws:ecs:us-east-1:111111111111:task-definition/api:3"),
+			EnableExecuteCommand: true,
+		},
+		{
+			ServiceName:    aws.String("worker"),
+			LaunchType:     ecsTypes.LaunchTypeEc2,
+			RunningCount:   testECSWorkerTask,
+			TaskDefinition: aws.String("arn:aws:ecs:us-east-1:111111111111:task-definition/worker:1"),
+		},
+	}}, nil
+}
+
+// ListTasks returns the tasks in two pages
+func (c *mockedECSClient) ListTasks(ctx context.Context, input *ecs.ListTasksInput, f ...func(*ecs.Options)) (*ecs.ListTasksOutput, error) {
+	total := testECSWorkerTask + 2
+	if input.NextToken == nil {
+		var taskArns []string
+		for i := 0; i < 60; i++ {
+			taskArns = append(taskArns, testECSTaskArn(i))
+		}
+		return &ecs.ListTasksOutput{TaskArns: taskArns, NextToken: aws.String("page2")}, nil
+	}
+	var taskArns []string
+	for i := 60; i < total; i++ {
+		taskArns = append(taskArns, testECSTaskArn(i))
+	}
+	return &ecs.ListTasksOutput{TaskArns: taskArns}, nil
+}
+
+func (c *mockedECSClient) DescribeTasks(ctx context.Context, input *ecs.DescribeTasksInput, f ...func(*ecs.Options)) (*ecs.DescribeTasksOutput, error) {
+	if len(input.Tasks) > c.largestDescribeTasksBatch {
+		c.largestDescribeTasksBatch = len(input.Tasks)
+	}
+	var tasks []ecsTypes.Task
+	for _, taskArn := range input.Tasks {
+		var i int
+		fmt.Sscanf(taskArn[strings.LastIndex(taskArn, "/")+1:], "%d", &i)
+		task := ecsTypes.Task{
+			TaskArn:    aws.String(taskArn),
+			ClusterArn: input.Cluster,
+		}
+		switch {
+		case i < testECSWorkerTask:
+			task.Group = aws.String("service:worker")
+			task.LaunchType = ecsTypes.LaunchTypeEc2
+			task.TaskDefinitionArn = aws.String("arn:aws:ecs:us-east-1:111111111111:task-definition/worker:1")
+			task.Containers = []ecsTypes.Container{{Name: aws.String("worker")}}
+			// Exec was enabled with run-task overrides on a single worker task
+			task.EnableExecuteCommand = i == 7
+		case i == testECSWorkerTask:
+			task.Group = aws.String("service:api")
+			task.LaunchType = ecsTypes.LaunchTypeFargate
+			task.TaskDefinitionArn = aws.String("arn:aws:ecs:us-east-1:111111111111:task-definition/api:3")
+			task.Containers = []ecsTypes.Container{{Name: aws.String("app")}, {Name: aws.String("envoy")}}
+			task.EnableExecuteCommand = true
+		default:
+			task.Group = aws.String("family:migrate")
+			task.LaunchType = ecsTypes.LaunchTypeFargate
+			task.TaskDefinitionArn = aws.String("arn:aws:ecs:us-east-1:111111111111:task-definition/migrate:5")
+			task.Containers = []ecsTypes.Container{{Name: aws.String("migrate")}}
+		}
+		tasks = append(tasks, task)
+	}
+	return &ecs.DescribeTasksOutput{Tasks: tasks}, nil
+}
+
+func (c *mockedECSClient) DescribeTaskDefinition(ctx context.Context, input *ecs.DescribeTaskDefinitionInput, f ...func(o *ecs.Options)) (*ecs.DescribeTaskDefinitionOutput, error) {
+	family := getNameFromARN(aws.ToString(input.TaskDefinition))
+	family = family[:strings.Index(family, ":")]
+	return &ecs.DescribeTaskDefinitionOutput{TaskDefinition: &ecsTypes.TaskDefinition{
+		Family:           aws.String(family),
+		TaskRoleArn:      aws.String(fmt.Sprintf("arn:aws:iam::111111111111:role/%s-task-role", family)),
+		ExecutionRoleArn: aws.String("arn:aws:iam::111111111111:role/ecsTaskExecutionRole"),
+	}}, nil
+}
+
+type mockedECSIAMClient struct {
+	sdk.MockedIAMClient
+}
+
+func (c *mockedECSIAMClient) GetAccountAuthorizationDetails(ctx context.Context, params *iam.GetAccountAuthorizationDetailsInput, optFns ...func(*iam.Options)) (*iam.GetAccountAuthorizationDetailsOutput, error) {
+	return &iam.GetAccountAuthorizationDetailsOutput{RoleDetailList: []iamTypes.RoleDetail{
+		{
+			Arn: aws.String("arn:aws:iam::111111111111:role/api-task-role"),
+			AttachedManagedPolicies: []iamTypes.AttachedPolicy{
+				{PolicyName: aws.String("AmazonS3ReadOnlyAccess")},
+				{PolicyName: aws.String("AdministratorAccess")},
+			},
+		},
+		{
+			Arn: aws.String("arn:aws:iam::111111111111:role/worker-task-role"),
+			AttachedManagedPolicies: []iamTypes.AttachedPolicy{
+				{PolicyName: aws.String("AmazonSQSFullAccess")},
+			},
+		},
+	}}, nil
+}
+
+func TestECS(t *testing.T) {
+	ecsClient := &mockedECSClient{}
+	m := ECSModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::111111111111:user/Alice"),
+			Account: aws.String("111111111111"),
+		},
+		Goroutines: 3,
+		ECSClient:  ecsClient,
+		IAMClient:  &mockedECSIAMClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+	tmpDir := "."
+
+	m.PrintECS(tmpDir, 2)
+
+	if ecsClient.largestDescribeTasksBatch > 100 {
+		t.Errorf("Expected DescribeTasks batches of at most 100 tasks, got %d", ecsClient.largestDescribeTasksBatch)
+	}
+
+	expected := map[string]struct {
+		runningTasks  int
+		execEnabled   string
+		broadPolicies string
+	}{
+		"api":            {1, "Service, 1/1 tasks", "AdministratorAccess"},
+		"worker":         {testECSWorkerTask, "1/101 tasks", ""},
+		"family:migrate": {1, "No", ""},
+	}
+	if len(m.ECSServices) != len(expected) {
+		t.Fatalf("Expected %d services, got %d", len(expected), len(m.ECSServices))
+	}
+	for _, ecsService := range m.ECSServices {
+		want, ok := expected[ecsService.Name]
+		if !ok {
+			t.Errorf("Unexpected service %s", ecsService.Name)
+			continue
+		}
+		if ecsService.RunningTasks != want.runningTasks {
+			t.Errorf("Expected %d running tasks for %s, got %d", want.runningTasks, ecsService.Name, ecsService.RunningTasks)
+		}
+		if ecsService.execEnabled() != want.execEnabled {
+			t.Errorf("Expected exec enabled %q for %s, got %q", want.execEnabled, ecsService.Name, ecsService.execEnabled())
+		}
+		if strings.Join(ecsService.BroadPolicies, ", ") != want.broadPolicies {
+			t.Errorf("Expected broad policies %q for %s, got %v", want.broadPolicies, ecsService.Name, ecsService.BroadPolicies)
+		}
+		if ecsService.ExecutionRole != "arn:aws:iam::111111111111:role/ecsTaskExecutionRole" {
+			t.Errorf("Expected the execution role for %s, got %s", ecsService.Name, ecsService.ExecutionRole)
+		}
+	}
+
+	lootFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-111111111111/loot/ecs-commands.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	expectedCommands := []string{
+		"aws --profile $profile --region us-east-1 ecs execute-command --cluster prod --task 00000000000000000000000000000101 --container app --interactive --command /bin/sh",
+		"aws --profile $profile --region us-east-1 ecs execute-command --cluster prod --task 00000000000000000000000000000101 --container envoy --interactive --command /bin/sh",
+		"aws --profile $profile --region us-east-1 ecs execute-command --cluster prod --task 00000000000000000000000000000007 --container worker --interactive --command /bin/sh",
+		"aws --profile $profile --region us-east-1 ecs describe-task-definition --task-definition arn:aws:ecs:us-east-1:111111111111:task-definition/migrate:5",
+	}
+	for _, expectedCommand := range expectedCommands {
+		if !strings.Contains(string(lootFile), expectedCommand) {
+			t.Errorf("Expected %s to be in the loot file", expectedCommand)
+		}
+	}
+	if strings.Count(string(lootFile), "execute-command") != 3 {
+		t.Errorf("Expected only the exec-enabled tasks in the loot file, got:\n%s", lootFile)
+	}
+}
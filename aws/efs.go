@@ -0,0 +1,557 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/aws/policy"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	efsTypes "github.com/aws/aws-sdk-go-v2/service/efs/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type EFSModule struct {
+	// General configuration data
+	EFSClient       sdk.AWSEFSClientInterface
+	EFSConfigClient sdk.AWSEFSConfigClientInterface
+	EC2Client       sdk.AWSEC2SecurityGroupsClientInterface
+	LambdaClient    sdk.LambdaClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	FileSystems    []EFSFileSystem
+	LambdaMounts   []EFSLambdaMount
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type EFSFileSystem struct {
+	Region string
+	ID     string
+	Name   string
+	Arn    string
+	// KMSKey is empty for file systems that are not encrypted at rest
+	KMSKey       string
+	Encrypted    bool
+	Lifecycle    []string
+	MountTargets []EFSMountTarget
+	// CrossAccountPrincipals are the principals of other accounts the file system policy lets mount it
+	CrossAccountPrincipals []string
+	Findings               []string
+}
+
+type EFSMountTarget struct {
+	ID             string
+	IPAddress      string
+	SubnetID       string
+	SecurityGroups []string
+	// OpenTo is the source, 0.0.0.0/0 or ::/0, a security group lets NFS in from. Empty if NFS isn't open to anyone.
+	OpenTo string
+}
+
+// EFSLambdaMount is a Lambda function that mounts a file system through an access point. Every invocation of the
+// function, and every function that mounts the same access point, sees the same files.
+type EFSLambdaMount struct {
+	Region         string
+	Function       string
+	FileSystemID   string
+	AccessPoint    string
+	LocalMountPath string
+}
+
+const (
+	efsNFSPort           = 2049
+	efsNoLifecycle       = "No lifecycle management"
+	efsNotEncrypted      = "Not encrypted at rest"
+	efsLambdaSharedState = "Shared state between Lambda invocations"
+)
+
+func (m *EFSModule) PrintEFS(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "efs"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating EFS file systems, their mount targets and Lambda mounts for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create channels to receive the objects
+	fileSystemReceiver := make(chan EFSFileSystem)
+	lambdaReceiver := make(chan EFSLambdaMount)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(fileSystemReceiver, lambdaReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, fileSystemReceiver, lambdaReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.FileSystems, func(i, j int) bool {
+		if m.FileSystems[i].Region != m.FileSystems[j].Region {
+			return m.FileSystems[i].Region < m.FileSystems[j].Region
+		}
+		return m.FileSystems[i].ID < m.FileSystems[j].ID
+	})
+	sort.Slice(m.LambdaMounts, func(i, j int) bool {
+		if m.LambdaMounts[i].Region != m.LambdaMounts[j].Region {
+			return m.LambdaMounts[i].Region < m.LambdaMounts[j].Region
+		}
+		return m.LambdaMounts[i].Function < m.LambdaMounts[j].Function
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"ID",
+		"Name",
+		"Arn",
+		"KMS Key",
+		"Lifecycle",
+		"Mount Targets",
+		"Security Groups",
+		"Cross-Account Principals",
+		"Finding",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"ID",
+			"Name",
+			"Arn",
+			"KMS Key",
+			"Lifecycle",
+			"Mount Targets",
+			"Security Groups",
+			"Cross-Account Principals",
+			"Finding",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"ID",
+			"Name",
+			"Mount Targets",
+			"Security Groups",
+			"Finding",
+		}
+	}
+
+	// Table rows
+	var flagged int
+	for _, fileSystem := range m.FileSystems {
+		finding := strings.Join(fileSystem.Findings, ", ")
+		if finding != "" {
+			flagged++
+			finding = magenta(finding)
+		}
+		var mountTargets, securityGroups []string
+		for _, mountTarget := range fileSystem.MountTargets {
+			mountTargets = append(mountTargets, mountTarget.IPAddress)
+			for _, securityGroup := range mountTarget.SecurityGroups {
+				if !internal.Contains(securityGroup, securityGroups) {
+					securityGroups = append(securityGroups, securityGroup)
+				}
+			}
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				fileSystem.Region,
+				fileSystem.ID,
+				fileSystem.Name,
+				fileSystem.Arn,
+				fileSystem.KMSKey,
+				strings.Join(fileSystem.Lifecycle, ", "),
+				strings.Join(mountTargets, ", "),
+				strings.Join(securityGroups, ", "),
+				strings.Join(fileSystem.CrossAccountPrincipals, ", "),
+				finding,
+			},
+		)
+	}
+
+	lambdaHeaders := []string{
+		"Account",
+		"Region",
+		"Function",
+		"File System",
+		"Access Point",
+		"Local Mount Path",
+		"Finding",
+	}
+	var lambdaBody [][]string
+	for _, mount := range m.LambdaMounts {
+		lambdaBody = append(
+			lambdaBody,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				mount.Region,
+				mount.Function,
+				mount.FileSystemID,
+				mount.AccessPoint,
+				mount.LocalMountPath,
+				magenta(efsLambdaSharedState),
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		if len(lambdaBody) > 0 {
+			o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+				Header: lambdaHeaders,
+				Body:   lambdaBody,
+				Name:   fmt.Sprintf("%s-lambda-mounts", m.output.CallingModule),
+			})
+		}
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     m.output.CallingModule,
+			Contents: m.writeLoot(),
+		})
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d EFS file systems found, %d of them flagged. %d Lambda functions mount a file system.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.FileSystems), flagged, len(m.LambdaMounts))
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No EFS file systems found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *EFSModule) Receiver(fileSystemReceiver chan EFSFileSystem, lambdaReceiver chan EFSLambdaMount, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-fileSystemReceiver:
+			m.FileSystems = append(m.FileSystems, data)
+		case data := <-lambdaReceiver:
+			m.LambdaMounts = append(m.LambdaMounts, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *EFSModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, fileSystemReceiver chan EFSFileSystem, lambdaReceiver chan EFSLambdaMount) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("efs", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getFileSystemsPerRegion(r, wg, semaphore, fileSystemReceiver, lambdaReceiver)
+	}
+}
+
+func (m *EFSModule) getFileSystemsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, fileSystemReceiver chan EFSFileSystem, lambdaReceiver chan EFSLambdaMount) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	accountID := aws.ToString(m.Caller.Account)
+	fileSystems, err := sdk.CachedDescribeFileSystems(m.EFSClient, accountID, r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	if len(fileSystems) == 0 {
+		return
+	}
+
+	securityGroups, err := sdk.CachedEC2DescribeSecurityGroups(m.EC2Client, accountID, r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+
+	// Lambda functions mount access points, the file system is looked up through them
+	accessPointFileSystems := make(map[string]string)
+	for _, fileSystem := range fileSystems {
+		id := aws.ToString(fileSystem.FileSystemId)
+		accessPoints, err := sdk.CachedDescribeAccessPoints(m.EFSClient, accountID, r, id)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+		for _, accessPoint := range accessPoints {
+			accessPointFileSystems[aws.ToString(accessPoint.AccessPointId)] = aws.ToString(accessPoint.FileSystemId)
+		}
+
+		fileSystemReceiver <- m.describeFileSystem(r, fileSystem, securityGroups)
+	}
+
+	functions, err := sdk.CachedLambdaListFunctions(m.LambdaClient, accountID, r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	for _, function := range functions {
+		for _, config := range function.FileSystemConfigs {
+			accessPointArn := aws.ToString(config.Arn)
+			accessPoint := accessPointArn[strings.LastIndex(accessPointArn, "/")+1:]
+			fileSystemID, ok := accessPointFileSystems[accessPoint]
+			if !ok {
+				continue
+			}
+			lambdaReceiver <- EFSLambdaMount{
+				Region:         r,
+				Function:       aws.ToString(function.FunctionName),
+				FileSystemID:   fileSystemID,
+				AccessPoint:    accessPoint,
+				LocalMountPath: aws.ToString(config.LocalMountPath),
+			}
+		}
+	}
+}
+
+func (m *EFSModule) describeFileSystem(r string, fileSystem efsTypes.FileSystemDescription, securityGroups []ec2_types.SecurityGroup) EFSFileSystem {
+	accountID := aws.ToString(m.Caller.Account)
+	id := aws.ToString(fileSystem.FileSystemId)
+	efsFileSystem := EFSFileSystem{
+		Region:    r,
+		ID:        id,
+		Name:      aws.ToString(fileSystem.Name),
+		Arn:       aws.ToString(fileSystem.FileSystemArn),
+		Encrypted: aws.ToBool(fileSystem.Encrypted),
+	}
+	if efsFileSystem.Encrypted {
+		efsFileSystem.KMSKey = aws.ToString(fileSystem.KmsKeyId)
+	}
+
+	lifecyclePolicies, err := sdk.CachedDescribeLifecycleConfiguration(m.EFSConfigClient, accountID, r, id)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	for _, lifecyclePolicy := range lifecyclePolicies {
+		if lifecyclePolicy.TransitionToIA != "" {
+			efsFileSystem.Lifecycle = append(efsFileSystem.Lifecycle, fmt.Sprintf("IA %s", lifecyclePolicy.TransitionToIA))
+		}
+		if lifecyclePolicy.TransitionToArchive != "" {
+			efsFileSystem.Lifecycle = append(efsFileSystem.Lifecycle, fmt.Sprintf("Archive %s", lifecyclePolicy.TransitionToArchive))
+		}
+		if lifecyclePolicy.TransitionToPrimaryStorageClass != "" {
+			efsFileSystem.Lifecycle = append(efsFileSystem.Lifecycle, fmt.Sprintf("Primary %s", lifecyclePolicy.TransitionToPrimaryStorageClass))
+		}
+	}
+
+	mountTargets, err := sdk.CachedDescribeMountTargets(m.EFSClient, accountID, r, id)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	for _, mountTarget := range mountTargets {
+		efsMountTarget := EFSMountTarget{
+			ID:        aws.ToString(mountTarget.MountTargetId),
+			IPAddress: aws.ToString(mountTarget.IpAddress),
+			SubnetID:  aws.ToString(mountTarget.SubnetId),
+		}
+		efsMountTarget.SecurityGroups, err = sdk.CachedDescribeMountTargetSecurityGroups(m.EFSConfigClient, accountID, r, efsMountTarget.ID)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+		efsMountTarget.OpenTo = efsNFSOpenTo(efsMountTarget.SecurityGroups, securityGroups)
+		efsFileSystem.MountTargets = append(efsFileSystem.MountTargets, efsMountTarget)
+	}
+
+	fileSystemPolicy, err := sdk.CachedDescribeFileSystemPolicy(m.EFSClient, id, r, accountID)
+	if err != nil {
+		// File systems without a policy let every principal with IAM permissions in the account mount them
+		if !isNoResourcePolicyError(err) {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+	} else {
+		efsFileSystem.CrossAccountPrincipals = efsCrossAccountMounters(fileSystemPolicy, accountID)
+	}
+
+	efsFileSystem.Findings = efsFileSystemFindings(efsFileSystem)
+	return efsFileSystem
+}
+
+// efsNFSOpenTo returns the source that the mount target's security groups let NFS in from, if it is anywhere on the
+// internet
+func efsNFSOpenTo(groupIDs []string, securityGroups []ec2_types.SecurityGroup) string {
+	var groups []ec2_types.SecurityGroup
+	for _, securityGroup := range securityGroups {
+		if internal.Contains(aws.ToString(securityGroup.GroupId), groupIDs) {
+			groups = append(groups, securityGroup)
+		}
+	}
+	for _, ipv6 := range []bool{false, true} {
+		for _, allowance := range securityGroupAllowances(groups, ipv6) {
+			if allowance.Protocol == "tcp" && allowance.Ports.From <= efsNFSPort && efsNFSPort <= allowance.Ports.To {
+				return allowance.Source
+			}
+		}
+	}
+	return ""
+}
+
+// efsCrossAccountMounters returns the principals of other accounts, or *, that the file system policy allows to mount
+// the file system
+func efsCrossAccountMounters(fileSystemPolicy policy.Policy, accountID string) []string {
+	var principals []string
+	for _, finding := range analyzeResourcePolicy(fileSystemPolicy, accountID) {
+		canMount := strings.HasPrefix(finding.Actions, "All except ")
+		for _, action := range strings.Split(finding.Actions, ", ") {
+			if policy.MatchesAfterExpansion(action, "elasticfilesystem:ClientMount") {
+				canMount = true
+			}
+		}
+		if canMount && !internal.Contains(finding.Principal, principals) {
+			principals = append(principals, finding.Principal)
+		}
+	}
+	return principals
+}
+
+func efsFileSystemFindings(fileSystem EFSFileSystem) []string {
+	var findings []string
+	for _, mountTarget := range fileSystem.MountTargets {
+		if mountTarget.OpenTo != "" {
+			findings = append(findings, fmt.Sprintf("NFS open to %s on %s", mountTarget.OpenTo, mountTarget.ID))
+		}
+	}
+	if len(fileSystem.CrossAccountPrincipals) > 0 {
+		findings = append(findings, "Cross-account mount allowed")
+	}
+	if !fileSystem.Encrypted {
+		findings = append(findings, efsNotEncrypted)
+	}
+	if len(fileSystem.Lifecycle) == 0 {
+		findings = append(findings, efsNoLifecycle)
+	}
+	return findings
+}
+
+func (m *EFSModule) writeLoot() string {
+	var out string
+	out += fmt.Sprintln("#############################################")
+	out += fmt.Sprintln("# Mount the EFS file systems from a host that can reach a mount target on port 2049.")
+	out += fmt.Sprintln("# File systems with a mount target that is open to the internet are listed first.")
+	out += fmt.Sprintln("#############################################")
+	out += fmt.Sprintln("")
+	for _, open := range []bool{true, false} {
+		for _, fileSystem := range m.FileSystems {
+			mountTarget, ok := efsLootMountTarget(fileSystem)
+			if !ok || (mountTarget.OpenTo != "") != open {
+				continue
+			}
+			out += fmt.Sprintf("# %s (%s) in %s through %s\n", fileSystem.ID, fileSystem.Name, fileSystem.Region, mountTarget.ID)
+			out += fmt.Sprintf("sudo mkdir -p /mnt/%s\n", fileSystem.ID)
+			out += fmt.Sprintf("sudo mount -t nfs4 -o nfsvers=4.1,rsize=1048576,wsize=1048576,hard,timeo=600,retrans=2,noresvport %s:/ /mnt/%s\n\n", mountTarget.IPAddress, fileSystem.ID)
+		}
+	}
+	return out
+}
+
+// efsLootMountTarget picks the mount target to mount a file system through, one that is open to the internet if
+// there is one
+func efsLootMountTarget(fileSystem EFSFileSystem) (EFSMountTarget, bool) {
+	var target EFSMountTarget
+	var found bool
+	for _, mountTarget := range fileSystem.MountTargets {
+		if mountTarget.IPAddress == "" {
+			continue
+		}
+		if mountTarget.OpenTo != "" {
+			return mountTarget, true
+		}
+		if !found {
+			target, found = mountTarget, true
+		}
+	}
+	return target, found
+}
@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestEFS(t *testing.T) {
+	m := EFSModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:      3,
+		EFSClient:       &sdk.MockedEfsClient{},
+		EFSConfigClient: &sdk.MockedEfsClient{},
+		EC2Client:       &sdk.MockedEC2SecurityGroupsClient{},
+		LambdaClient:    &sdk.MockedLambdaClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintEFS(".", 2)
+
+	// Both file systems let the backup role of 444455556666 mount them
+	expectedFindings := map[string][]string{
+		"fs-12345678": {"Cross-account mount allowed"},
+		"fs-87654321": {"NFS open to 0.0.0.0/0 on fsmt-87654321", "Cross-account mount allowed", efsNotEncrypted, efsNoLifecycle},
+	}
+	if len(m.FileSystems) != len(expectedFindings) {
+		t.Fatalf("Expected %d file systems, got %d", len(expectedFindings), len(m.FileSystems))
+	}
+	for _, fileSystem := range m.FileSystems {
+		if !reflect.DeepEqual(fileSystem.Findings, expectedFindings[fileSystem.ID]) {
+			t.Errorf("%s: expected findings %v, got %v", fileSystem.ID, expectedFindings[fileSystem.ID], fileSystem.Findings)
+		}
+		if !reflect.DeepEqual(fileSystem.CrossAccountPrincipals, []string{"arn:aws:iam::444455556666:role/backup"}) {
+			t.Errorf("%s: unexpected cross-account principals %v", fileSystem.ID, fileSystem.CrossAccountPrincipals)
+		}
+	}
+
+	expectedMounts := []EFSLambdaMount{
+		{Region: "us-east-1", Function: "my-function2", FileSystemID: "fs-12345678", AccessPoint: "fsap-12345678", LocalMountPath: "/mnt/shared"},
+	}
+	if !reflect.DeepEqual(m.LambdaMounts, expectedMounts) {
+		t.Errorf("Expected Lambda mounts %v, got %v", expectedMounts, m.LambdaMounts)
+	}
+
+	for _, name := range []string{"efs", "efs-lambda-mounts"} {
+		resultsFilePath := filepath.Join(".", "cloudfox-output/aws/unittesting-123456789012/table", name+".txt")
+		if _, err := afero.ReadFile(fs, resultsFilePath); err != nil {
+			t.Errorf("Cannot read output file at %s: %s", resultsFilePath, err)
+		}
+	}
+	lootFilePath := filepath.Join(".", "cloudfox-output/aws/unittesting-123456789012/loot/efs.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	// The file system that is open to the internet comes first
+	if strings.Index(string(lootFile), "fs-87654321") > strings.Index(string(lootFile), "fs-12345678") {
+		t.Errorf("Expected the mount command of fs-87654321 first in the loot file")
+	}
+}
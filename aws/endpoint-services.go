@@ -0,0 +1,365 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type EndpointServicesModule struct {
+	// General configuration data
+	EC2Client sdk.AWSEC2EndpointServicesClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	EndpointServices []EndpointService
+	CommandCounter   internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type EndpointService struct {
+	Region             string
+	ID                 string
+	Name               string
+	Type               string
+	State              string
+	AcceptanceRequired bool
+	LoadBalancers      []string
+	AllowedPrincipals  []string
+	Connections        []EndpointServiceConnection
+	Finding            string
+}
+
+// EndpointServiceConnection is a VPC endpoint in some account that connects, or asked to connect, to the service
+type EndpointServiceConnection struct {
+	VpcEndpointID string
+	Owner         string
+	State         string
+}
+
+const (
+	endpointServiceAnyPrincipalNoAcceptance = "Any account can connect without approval"
+	endpointServiceAnyPrincipal             = "Any account can request a connection"
+	endpointServiceExternalConnections      = "Connected to external accounts"
+)
+
+func (m *EndpointServicesModule) PrintEndpointServices(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "endpoint-services"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating PrivateLink endpoint services for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan EndpointService)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.EndpointServices, func(i, j int) bool {
+		if m.EndpointServices[i].Region != m.EndpointServices[j].Region {
+			return m.EndpointServices[i].Region < m.EndpointServices[j].Region
+		}
+		return m.EndpointServices[i].ID < m.EndpointServices[j].ID
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Service ID",
+		"Service Name",
+		"Type",
+		"State",
+		"Load Balancers",
+		"Acceptance Required",
+		"Allowed Principals",
+		"Connections",
+		"External Accounts",
+		"Finding",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Service ID",
+			"Service Name",
+			"Type",
+			"State",
+			"Load Balancers",
+			"Acceptance Required",
+			"Allowed Principals",
+			"Connections",
+			"External Accounts",
+			"Finding",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Service ID",
+			"Acceptance Required",
+			"Allowed Principals",
+			"Connections",
+			"External Accounts",
+			"Finding",
+		}
+	}
+
+	// Table rows
+	for i := range m.EndpointServices {
+		var connections []string
+		for _, connection := range m.EndpointServices[i].Connections {
+			connections = append(connections, fmt.Sprintf("%s (%s, %s)", connection.VpcEndpointID, connection.Owner, connection.State))
+		}
+		finding := m.EndpointServices[i].Finding
+		if finding != "" {
+			finding = magenta(finding)
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				m.EndpointServices[i].Region,
+				m.EndpointServices[i].ID,
+				m.EndpointServices[i].Name,
+				m.EndpointServices[i].Type,
+				m.EndpointServices[i].State,
+				strings.Join(m.EndpointServices[i].LoadBalancers, ", "),
+				strconv.FormatBool(m.EndpointServices[i].AcceptanceRequired),
+				strings.Join(m.EndpointServices[i].AllowedPrincipals, ", "),
+				strings.Join(connections, ", "),
+				strings.Join(m.EndpointServices[i].externalAccounts(aws.ToString(m.Caller.Account)), ", "),
+				finding,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s endpoint services found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No endpoint services found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *EndpointServicesModule) Receiver(receiver chan EndpointService, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.EndpointServices = append(m.EndpointServices, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *EndpointServicesModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan EndpointService) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("ec2", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getEndpointServicesPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *EndpointServicesModule) getEndpointServicesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan EndpointService) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	serviceConfigurations, err := sdk.CachedEC2DescribeVpcEndpointServiceConfigurations(m.EC2Client, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	if len(serviceConfigurations) == 0 {
+		return
+	}
+
+	// Connections come back for every service in the region at once
+	connections, err := sdk.CachedEC2DescribeVpcEndpointConnections(m.EC2Client, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	connectionsByService := make(map[string][]EndpointServiceConnection)
+	for _, connection := range connections {
+		serviceID := aws.ToString(connection.ServiceId)
+		connectionsByService[serviceID] = append(connectionsByService[serviceID], EndpointServiceConnection{
+			VpcEndpointID: aws.ToString(connection.VpcEndpointId),
+			Owner:         aws.ToString(connection.VpcEndpointOwner),
+			State:         string(connection.VpcEndpointState),
+		})
+	}
+
+	for _, serviceConfiguration := range serviceConfigurations {
+		serviceID := aws.ToString(serviceConfiguration.ServiceId)
+		allowedPrincipals, err := sdk.CachedEC2DescribeVpcEndpointServicePermissions(m.EC2Client, aws.ToString(m.Caller.Account), r, serviceID)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+		endpointService := analyzeEndpointService(serviceConfiguration, allowedPrincipals, connectionsByService[serviceID], aws.ToString(m.Caller.Account))
+		endpointService.Region = r
+		dataReceiver <- endpointService
+	}
+}
+
+// analyzeEndpointService flags services that accept connections from any account, and services that already have
+// endpoints in other accounts connected to them. Both mean another account can reach whatever sits behind the
+// service's load balancer over a private connection that doesn't show up in security groups or route tables.
+func analyzeEndpointService(serviceConfiguration ec2Types.ServiceConfiguration, allowedPrincipals []ec2Types.AllowedPrincipal, connections []EndpointServiceConnection, accountID string) EndpointService {
+	endpointService := EndpointService{
+		ID:                 aws.ToString(serviceConfiguration.ServiceId),
+		Name:               aws.ToString(serviceConfiguration.ServiceName),
+		State:              string(serviceConfiguration.ServiceState),
+		AcceptanceRequired: aws.ToBool(serviceConfiguration.AcceptanceRequired),
+		Connections:        connections,
+	}
+	var serviceTypes []string
+	for _, serviceType := range serviceConfiguration.ServiceType {
+		serviceTypes = append(serviceTypes, string(serviceType.ServiceType))
+	}
+	endpointService.Type = strings.Join(serviceTypes, ", ")
+	for _, loadBalancer := range append(serviceConfiguration.NetworkLoadBalancerArns, serviceConfiguration.GatewayLoadBalancerArns...) {
+		endpointService.LoadBalancers = append(endpointService.LoadBalancers, getNameFromARN(loadBalancer))
+	}
+
+	var anyPrincipal bool
+	for _, allowedPrincipal := range allowedPrincipals {
+		principal := aws.ToString(allowedPrincipal.Principal)
+		if principal == "*" {
+			anyPrincipal = true
+		}
+		endpointService.AllowedPrincipals = append(endpointService.AllowedPrincipals, principal)
+	}
+
+	switch {
+	case anyPrincipal && !endpointService.AcceptanceRequired:
+		endpointService.Finding = endpointServiceAnyPrincipalNoAcceptance
+	case anyPrincipal:
+		endpointService.Finding = endpointServiceAnyPrincipal
+	case len(endpointService.externalAccounts(accountID)) > 0:
+		endpointService.Finding = endpointServiceExternalConnections
+	}
+	return endpointService
+}
+
+// externalAccounts returns the other accounts that have a pending or accepted connection to the service. Rejected,
+// failed and deleted connections don't give the account access.
+func (s EndpointService) externalAccounts(accountID string) []string {
+	var accounts []string
+	for _, connection := range s.Connections {
+		if connection.Owner == accountID || internal.Contains(connection.Owner, accounts) {
+			continue
+		}
+		if connection.State != string(ec2Types.StateAvailable) && connection.State != string(ec2Types.StatePendingAcceptance) {
+			continue
+		}
+		accounts = append(accounts, connection.Owner)
+	}
+	sort.Strings(accounts)
+	return accounts
+}
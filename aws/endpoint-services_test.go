@@ -0,0 +1,70 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+func TestEndpointServices(t *testing.T) {
+
+	m := EndpointServicesModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines: 3,
+		WrapTable:  false,
+		EC2Client:  &sdk.MockedEC2EndpointServicesClient{},
+	}
+
+	internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintEndpointServices(".", 2)
+
+	type expected struct {
+		finding           string
+		allowedPrincipals []string
+		externalAccounts  []string
+	}
+	expectedResults := map[string]expected{
+		"vpce-svc-0a1b2c3d4e5f60001": {
+			finding:           endpointServiceAnyPrincipalNoAcceptance,
+			allowedPrincipals: []string{"*"},
+			externalAccounts:  []string{"999988887777"},
+		},
+		// 444455556666 was rejected, so only the pending connection from 111122223333 counts
+		"vpce-svc-0a1b2c3d4e5f60002": {
+			finding:           endpointServiceExternalConnections,
+			allowedPrincipals: []string{"arn:aws:iam::111122223333:root", "arn:aws:iam::123456789012:root"},
+			externalAccounts:  []string{"111122223333"},
+		},
+	}
+	if len(m.EndpointServices) != len(expectedResults) {
+		t.Fatalf("Expected %d endpoint services, got %d", len(expectedResults), len(m.EndpointServices))
+	}
+	for _, endpointService := range m.EndpointServices {
+		want, ok := expectedResults[endpointService.ID]
+		if !ok {
+			t.Errorf("Unexpected endpoint service %s", endpointService.ID)
+			continue
+		}
+		if endpointService.Finding != want.finding {
+			t.Errorf("Endpoint service %s: expected finding %q, got %q", endpointService.ID, want.finding, endpointService.Finding)
+		}
+		if !reflect.DeepEqual(endpointService.AllowedPrincipals, want.allowedPrincipals) {
+			t.Errorf("Endpoint service %s: expected allowed principals %v, got %v", endpointService.ID, want.allowedPrincipals, endpointService.AllowedPrincipals)
+		}
+		externalAccounts := endpointService.externalAccounts("123456789012")
+		if !reflect.DeepEqual(externalAccounts, want.externalAccounts) {
+			t.Errorf("Endpoint service %s: expected external accounts %v, got %v", endpointService.ID, want.externalAccounts, externalAccounts)
+		}
+	}
+}
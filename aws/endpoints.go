@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
+	"net/url"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -30,7 +30,6 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/opensearch"
 	"github.com/aws/aws-sdk-go-v2/service/rds"
 	"github.com/aws/aws-sdk-go-v2/service/redshift"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/aws/smithy-go"
 	"github.com/bishopfox/awsservicemap"
@@ -39,21 +38,22 @@ import (
 
 type EndpointsModule struct {
 	// General configuration data
-	LambdaClient       sdk.LambdaClientInterface
-	EKSClient          sdk.EKSClientInterface
-	MQClient           *mq.Client
-	OpenSearchClient   *opensearch.Client
-	GrafanaClient      *grafana.Client
-	ELBv2Client        *elasticloadbalancingv2.Client
-	ELBClient          *elasticloadbalancing.Client
-	APIGatewayClient   *apigateway.Client
-	APIGatewayv2Client *apigatewayv2.Client
-	RDSClient          *rds.Client
-	RedshiftClient     *redshift.Client
-	S3Client           *s3.Client
-	CloudfrontClient   *cloudfront.Client
-	AppRunnerClient    *apprunner.Client
-	LightsailClient    *lightsail.Client
+	LambdaClient           sdk.LambdaClientInterface
+	EKSClient              sdk.EKSClientInterface
+	MQClient               *mq.Client
+	OpenSearchClient       *opensearch.Client
+	GrafanaClient          *grafana.Client
+	ELBv2Client            *elasticloadbalancingv2.Client
+	ELBClient              *elasticloadbalancing.Client
+	APIGatewayClient       *apigateway.Client
+	APIGatewayv2Client     *apigatewayv2.Client
+	RDSClient              *rds.Client
+	RedshiftClient         *redshift.Client
+	S3Client               sdk.AWSS3ClientInterface
+	CloudfrontClient       *cloudfront.Client
+	AppRunnerClient        *apprunner.Client
+	LightsailClient        *lightsail.Client
+	ElasticBeanstalkClient sdk.AWSElasticBeanstalkClientInterface
 
 	Caller        sts.GetCallerIdentityOutput
 	AWSRegions    []string
@@ -81,6 +81,7 @@ type Endpoint struct {
 	Port       int32
 	Protocol   string
 	Public     string
+	Auth       string
 }
 
 var oe *smithy.OperationError
@@ -98,8 +99,8 @@ func (m *EndpointsModule) PrintEndpoints(outputDirectory string, verbosity int)
 	}
 
 	fmt.Printf("[%s][%s] Enumerating endpoints for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
-	fmt.Printf("[%s][%s] Supported Services: App Runner, APIGateway, ApiGatewayV2, Cloudfront, EKS, ELB, ELBv2, Grafana, \n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
-	fmt.Printf("[%s][%s] \t\t\tLambda, MQ, OpenSearch, Redshift, RDS\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	fmt.Printf("[%s][%s] Supported Services: App Runner, APIGateway, ApiGatewayV2, Cloudfront, EKS, Elastic Beanstalk, ELB, ELBv2, \n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	fmt.Printf("[%s][%s] \t\t\tGrafana, Lambda, Lightsail, MQ, OpenSearch, Redshift, RDS, S3 (website)\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
 
 	wg := new(sync.WaitGroup)
 	semaphore := make(chan struct{}, m.Goroutines)
@@ -116,9 +117,11 @@ func (m *EndpointsModule) PrintEndpoints(outputDirectory string, verbosity int)
 
 	go m.Receiver(dataReceiver, receiverDone)
 
-	//execute global checks -- removing from now. not sure i want s3 data in here
-	// wg.Add(1)
-	// go m.getS3EndpointsPerRegion(wg)
+	//execute global checks. Only S3 buckets with static website hosting enabled are included, not every bucket.
+	wg.Add(1)
+	m.CommandCounter.Total++
+	m.CommandCounter.Pending++
+	go m.getS3WebsiteEndpoints(wg, semaphore, dataReceiver)
 	wg.Add(1)
 	go m.getCloudfrontEndpoints(wg, semaphore, dataReceiver)
 
@@ -157,6 +160,7 @@ func (m *EndpointsModule) PrintEndpoints(outputDirectory string, verbosity int)
 		"Port",
 		"Protocol",
 		"Public",
+		"Auth",
 	}
 
 	// If the user specified table columns, use those.
@@ -181,6 +185,7 @@ func (m *EndpointsModule) PrintEndpoints(outputDirectory string, verbosity int)
 			"Port",
 			"Protocol",
 			"Public",
+			"Auth",
 		}
 		// Otherwise, use the default columns.
 	} else {
@@ -192,6 +197,7 @@ func (m *EndpointsModule) PrintEndpoints(outputDirectory string, verbosity int)
 			"Port",
 			"Protocol",
 			"Public",
+			"Auth",
 		}
 	}
 
@@ -208,6 +214,7 @@ func (m *EndpointsModule) PrintEndpoints(outputDirectory string, verbosity int)
 				strconv.Itoa(int(m.Endpoints[i].Port)),
 				m.Endpoints[i].Protocol,
 				m.Endpoints[i].Public,
+				m.Endpoints[i].Auth,
 			},
 		)
 
@@ -229,8 +236,19 @@ func (m *EndpointsModule) PrintEndpoints(outputDirectory string, verbosity int)
 		})
 		o.PrefixIdentifier = m.AWSProfile
 		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
-		o.WriteFullOutput(o.Table.TableFiles, nil)
-		m.writeLoot(o.Table.DirectoryName, verbosity)
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     "endpoints-UrlsOnly",
+			Contents: m.writeLoot(),
+		})
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     "endpoints-hostnames",
+			Contents: m.writeHostnameLoot(),
+		})
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
 		fmt.Printf("[%s][%s] %s endpoints found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
 	} else {
 		fmt.Printf("[%s][%s] No endpoints found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
@@ -251,6 +269,9 @@ func (m *EndpointsModule) Receiver(receiver chan Endpoint, receiverDone chan boo
 	for {
 		select {
 		case data := <-receiver:
+			if data.Auth == "" {
+				data.Auth = "Unknown"
+			}
 			m.Endpoints = append(m.Endpoints, data)
 		case <-receiverDone:
 			receiverDone <- true
@@ -373,49 +394,63 @@ func (m *EndpointsModule) executeChecks(r string, wg *sync.WaitGroup, semaphore
 	wg.Add(1)
 	go m.getAppRunnerEndpointsPerRegion(r, wg, semaphore, dataReceiver)
 
-	res, err = servicemap.IsServiceInRegion("lightsail", r)
+	res, err = servicemap.IsServiceInRegion("elasticbeanstalk", r)
 	if err != nil {
 		m.modLog.Error(err)
 	}
 	if res {
 		m.CommandCounter.Total++
 		wg.Add(1)
-		go m.getLightsailContainerEndpointsPerRegion(r, wg, semaphore, dataReceiver)
+		go m.getElasticBeanstalkEnvironmentsPerRegion(r, wg, semaphore, dataReceiver)
 	}
-}
 
-func (m *EndpointsModule) writeLoot(outputDirectory string, verbosity int) {
-	path := filepath.Join(outputDirectory, "loot")
-	err := os.MkdirAll(path, os.ModePerm)
+	res, err = servicemap.IsServiceInRegion("lightsail", r)
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
-		panic(err.Error())
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		go m.getLightsailContainerEndpointsPerRegion(r, wg, semaphore, dataReceiver)
 	}
-	f := filepath.Join(path, "endpoints-UrlsOnly.txt")
+}
 
+// writeLoot lists every endpoint URL, which can be fed into nmap and something like gowitness/aquatone for screenshots.
+func (m *EndpointsModule) writeLoot() string {
 	var out string
-
 	for _, endpoint := range m.Endpoints {
 		out = out + fmt.Sprintln(endpoint.Endpoint)
 	}
+	return out
+}
 
-	err = os.WriteFile(f, []byte(out), 0644)
-	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
-		panic(err.Error())
+// writeHostnameLoot lists each unique hostname once so it can be fed straight into httpx or nuclei.
+func (m *EndpointsModule) writeHostnameLoot() string {
+	var out string
+	seen := make(map[string]bool)
+	for _, endpoint := range m.Endpoints {
+		hostname := endpointHostname(endpoint.Endpoint)
+		if hostname == "" || seen[hostname] {
+			continue
+		}
+		seen[hostname] = true
+		out = out + fmt.Sprintln(hostname)
 	}
+	return out
+}
 
-	if verbosity > 2 {
-		fmt.Println()
-		fmt.Printf("[%s][%s] %s \n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("Feed this endpoints into nmap and something like gowitness/aquatone for screenshots."))
-		fmt.Print(out)
-		fmt.Printf("[%s][%s] %s \n\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("End of loot file."))
+// Some services return full URLs and others return bare DNS names, so strip the scheme, port and path from both.
+func endpointHostname(endpoint string) string {
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return ""
+		}
+		return strings.ToLower(u.Hostname())
 	}
-
-	fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), f)
-
+	hostname := strings.Split(endpoint, "/")[0]
+	hostname = strings.Split(hostname, ":")[0]
+	return strings.ToLower(hostname)
 }
 
 func (m *EndpointsModule) getLambdaFunctionsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Endpoint) {
@@ -455,10 +490,13 @@ func (m *EndpointsModule) getLambdaFunctionsPerRegion(r string, wg *sync.WaitGro
 		}
 		endpoint := aws.ToString(FunctionDetails.FunctionUrl)
 
+		var auth string
 		if FunctionDetails.AuthType == "NONE" {
 			public = "True"
+			auth = "None"
 		} else {
 			public = "False"
+			auth = "Required"
 		}
 
 		dataReceiver <- Endpoint{
@@ -469,6 +507,7 @@ func (m *EndpointsModule) getLambdaFunctionsPerRegion(r string, wg *sync.WaitGro
 			Port:       443,
 			Protocol:   "https",
 			Public:     public,
+			Auth:       auth,
 		}
 		//fmt.Println(endpoint, name, roleArn)
 	}
@@ -532,6 +571,7 @@ func (m *EndpointsModule) getEksClustersPerRegion(r string, wg *sync.WaitGroup,
 			Port:       443,
 			Protocol:   "https",
 			Public:     public,
+			Auth:       "Required",
 		}
 
 	}
@@ -598,6 +638,7 @@ func (m *EndpointsModule) getMqBrokersPerRegion(r string, wg *sync.WaitGroup, se
 			Port:       443,
 			Protocol:   "https",
 			Public:     public,
+			Auth:       "Required",
 		}
 
 	}
@@ -739,6 +780,7 @@ func (m *EndpointsModule) getGrafanaEndPointsPerRegion(r string, wg *sync.WaitGr
 			Port:       port,
 			Protocol:   protocol,
 			Public:     public,
+			Auth:       "Required",
 		}
 
 	}
@@ -1025,6 +1067,7 @@ func (m *EndpointsModule) getEndpointsPerAPIGateway(r string, api apigatewayType
 		}
 		m.modLog.Error(err.Error())
 		m.CommandCounter.Error++
+		return endpoints
 	}
 
 	GetResources, err := sdk.CachedApiGatewayGetResources(m.APIGatewayClient, aws.ToString(m.Caller.Account), r, id)
@@ -1054,6 +1097,7 @@ func (m *EndpointsModule) getEndpointsPerAPIGateway(r string, api apigatewayType
 					Port:       port,
 					Protocol:   protocol,
 					Public:     public,
+					Auth:       getAPIGatewayMethodsAuth(resource.ResourceMethods),
 				})
 			}
 		}
@@ -1062,6 +1106,22 @@ func (m *EndpointsModule) getEndpointsPerAPIGateway(r string, api apigatewayType
 	return endpoints
 }
 
+// GetResources only returns the authorization type of each method when the methods are embedded. If any method is
+// callable without an authorizer or API key, the resource is treated as unauthenticated.
+func getAPIGatewayMethodsAuth(methods map[string]apigatewayTypes.Method) string {
+	auth := "Unknown"
+	for _, method := range methods {
+		if method.AuthorizationType == nil {
+			continue
+		}
+		if aws.ToString(method.AuthorizationType) == "NONE" && !aws.ToBool(method.ApiKeyRequired) {
+			return "None"
+		}
+		auth = "Required"
+	}
+	return auth
+}
+
 func (m *EndpointsModule) getAPIGatewayv2APIsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Endpoint) {
 	defer func() {
 		m.CommandCounter.Executing--
@@ -1242,6 +1302,7 @@ func (m *EndpointsModule) getEndpointsPerAPIGatewayv2(r string, api apigatewayV2
 				Port:       port,
 				Protocol:   protocol,
 				Public:     public,
+				Auth:       getAPIGatewayv2RouteAuth(route),
 			})
 		}
 	}
@@ -1249,6 +1310,20 @@ func (m *EndpointsModule) getEndpointsPerAPIGatewayv2(r string, api apigatewayV2
 	return endpoints
 }
 
+func getAPIGatewayv2RouteAuth(route apigatewayV2Types.Route) string {
+	switch route.AuthorizationType {
+	case "":
+		return "Unknown"
+	case apigatewayV2Types.AuthorizationTypeNone:
+		if aws.ToBool(route.ApiKeyRequired) {
+			return "Required"
+		}
+		return "None"
+	default:
+		return "Required"
+	}
+}
+
 func (m *EndpointsModule) getRdsClustersPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Endpoint) {
 	defer func() {
 		m.CommandCounter.Executing--
@@ -1296,6 +1371,7 @@ func (m *EndpointsModule) getRdsClustersPerRegion(r string, wg *sync.WaitGroup,
 				Port:       aws.ToInt32(port),
 				Protocol:   aws.ToString(instance.Engine),
 				Public:     public,
+				Auth:       "Required",
 			}
 		}
 
@@ -1354,89 +1430,77 @@ func (m *EndpointsModule) getRedshiftEndPointsPerRegion(r string, wg *sync.WaitG
 			Port:       aws.ToInt32(port),
 			Protocol:   protocol,
 			Public:     public,
+			Auth:       "Required",
 		}
 
 	}
 
 }
 
-/*
-UNUSED CODE - PLEASE REVIEW AND DELETE IF IT DOESN'T APPLY
-
-	func (m *EndpointsModule) getS3EndpointsPerRegion(wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Endpoint) {
-		defer func() {
-			m.CommandCounter.Executing--
-			m.CommandCounter.Complete++
-			wg.Done()
-
-		}()
-		semaphore <- struct{}{}
-		defer func() {
-			<-semaphore
-		}()
-		// m.CommandCounter.Total++
-		m.CommandCounter.Pending--
-		m.CommandCounter.Executing++
-
-		// This for loop exits at the end dependeding on whether the output hits its last page (see pagination control block at the end of the loop).
-		ListBuckets, _ := m.S3Client.ListBuckets(
-			context.TODO(),
-			&s3.ListBucketsInput{},
-		)
-
-		var public string
-		for _, bucket := range ListBuckets.Buckets {
-			name := aws.ToString(bucket.Name)
-			endpoint := fmt.Sprintf("https://%s.s3.amazonaws.com", name)
-			awsService := "S3"
-
-			var port int32 = 443
-			protocol := "https"
-			var r string = "Global"
-			public = "False"
+func (m *EndpointsModule) getS3WebsiteEndpoints(wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Endpoint) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
 
-			GetBucketPolicyStatus, err := m.S3Client.GetBucketPolicyStatus(
-				context.TODO(),
-				&s3.GetBucketPolicyStatusInput{
-					Bucket: &name,
-				},
-			)
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
 
-			if err == nil {
-				isPublic := GetBucketPolicyStatus.PolicyStatus.IsPublic
-				if isPublic {
-					public = "True"
-				}
-			}
+	ListBuckets, err := sdk.CachedListBuckets(m.S3Client, aws.ToString(m.Caller.Account))
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
 
-			// GetBucketWebsite, err := m.S3Client.GetBucketWebsite(
-			// 	context.TODO(),
-			// 	&s3.GetBucketWebsiteInput{
-			// 		Bucket: &name,
-			// 	},
-			// )
+	for _, bucket := range ListBuckets {
+		name := aws.ToString(bucket.Name)
+		region, err := sdk.CachedGetBucketLocation(m.S3Client, aws.ToString(m.Caller.Account), name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
 
-			// if err != nil {
-			// 	index := *GetBucketWebsite.IndexDocument.Suffix
-			// 	if index != "" {
-			// 		public = "True"
-			// 	}
+		website, err := sdk.CachedGetBucketWebsite(m.S3Client, aws.ToString(m.Caller.Account), region, name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		if !website {
+			continue
+		}
 
-			// }
+		// Website endpoints are plain http and never check credentials. Whether objects are readable depends on the bucket policy.
+		dataReceiver <- Endpoint{
+			AWSService: "S3",
+			Region:     region,
+			Name:       name,
+			Endpoint:   s3WebsiteEndpoint(name, region),
+			Port:       80,
+			Protocol:   "http",
+			Public:     "True",
+			Auth:       "None",
+		}
+	}
+}
 
-			dataReceiver <- Endpoint{
-				AWSService: awsService,
-				Region:     r,
-				Name:       name,
-				Endpoint:   endpoint,
-				Port:       port,
-				Protocol:   protocol,
-				Public:     public,
-			}
+// s3WebsiteLegacyRegions still use s3-website-<region>, every other region uses s3-website.<region>
+var s3WebsiteLegacyRegions = []string{"us-east-1", "us-west-1", "us-west-2", "ap-southeast-1", "ap-southeast-2", "ap-northeast-1", "eu-west-1", "sa-east-1", "us-gov-west-1"}
 
-		}
+func s3WebsiteEndpoint(bucket string, region string) string {
+	if internal.Contains(region, s3WebsiteLegacyRegions) {
+		return fmt.Sprintf("http://%s.s3-website-%s.amazonaws.com", bucket, region)
 	}
-*/
+	return fmt.Sprintf("http://%s.s3-website.%s.amazonaws.com", bucket, region)
+}
+
 func (m *EndpointsModule) getCloudfrontEndpoints(wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Endpoint) {
 	defer func() {
 		m.CommandCounter.Executing--
@@ -1670,6 +1734,45 @@ func (m *EndpointsModule) appRunnerDescribeCustomDomain(r string, serviceArn str
 
 }
 
+func (m *EndpointsModule) getElasticBeanstalkEnvironmentsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Endpoint) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	Environments, err := sdk.CachedElasticBeanstalkDescribeEnvironments(m.ElasticBeanstalkClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, environment := range Environments {
+		// Worker environments don't have a CNAME because they only pull work from SQS
+		if environment.CNAME == nil {
+			continue
+		}
+
+		dataReceiver <- Endpoint{
+			AWSService: "Elastic Beanstalk",
+			Region:     r,
+			Name:       fmt.Sprintf("%s/%s", aws.ToString(environment.ApplicationName), aws.ToString(environment.EnvironmentName)),
+			Endpoint:   fmt.Sprintf("http://%s", aws.ToString(environment.CNAME)),
+			Port:       80,
+			Protocol:   "http",
+			Public:     "True",
+		}
+	}
+}
+
 func (m *EndpointsModule) getLightsailContainerEndpointsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Endpoint) {
 	defer func() {
 		m.CommandCounter.Executing--
@@ -0,0 +1,108 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	apigatewayTypes "github.com/aws/aws-sdk-go-v2/service/apigateway/types"
+)
+
+func TestEndpointHostname(t *testing.T) {
+	subtests := []struct {
+		endpoint string
+		expected string
+	}{
+		{"https://abcdef.execute-api.us-east-1.amazonaws.com/prod/users", "abcdef.execute-api.us-east-1.amazonaws.com"},
+		{"http://my-alb-123.us-east-1.elb.amazonaws.com:8080", "my-alb-123.us-east-1.elb.amazonaws.com"},
+		{"mydb.cluster-abc.us-east-1.rds.amazonaws.com", "mydb.cluster-abc.us-east-1.rds.amazonaws.com"},
+		{"D111111ABCDEF8.cloudfront.net/static", "d111111abcdef8.cloudfront.net"},
+		{"", ""},
+	}
+
+	for _, subtest := range subtests {
+		if got := endpointHostname(subtest.endpoint); got != subtest.expected {
+			t.Errorf("endpointHostname(%s) = %s, expected %s", subtest.endpoint, got, subtest.expected)
+		}
+	}
+}
+
+func TestS3WebsiteEndpoint(t *testing.T) {
+	subtests := []struct {
+		region   string
+		expected string
+	}{
+		{"us-west-1", "http://bucket1.s3-website-us-west-1.amazonaws.com"},
+		{"eu-central-1", "http://bucket1.s3-website.eu-central-1.amazonaws.com"},
+		{"ap-south-2", "http://bucket1.s3-website.ap-south-2.amazonaws.com"},
+	}
+
+	for _, subtest := range subtests {
+		if got := s3WebsiteEndpoint("bucket1", subtest.region); got != subtest.expected {
+			t.Errorf("s3WebsiteEndpoint(bucket1, %s) = %s, expected %s", subtest.region, got, subtest.expected)
+		}
+	}
+}
+
+func TestWriteHostnameLoot(t *testing.T) {
+	m := EndpointsModule{
+		Endpoints: []Endpoint{
+			{AWSService: "APIGateway", Endpoint: "https://abcdef.execute-api.us-east-1.amazonaws.com/prod/users"},
+			{AWSService: "APIGateway", Endpoint: "https://abcdef.execute-api.us-east-1.amazonaws.com/prod/orders"},
+			{AWSService: "ELBv2", Endpoint: "http://my-alb-123.us-east-1.elb.amazonaws.com:80"},
+			{AWSService: "ELBv2", Endpoint: "https://my-alb-123.us-east-1.elb.amazonaws.com:443"},
+			{AWSService: "S3", Endpoint: "http://bucket1.s3-website-us-west-1.amazonaws.com"},
+		},
+	}
+
+	loot := m.writeHostnameLoot()
+	expected := "abcdef.execute-api.us-east-1.amazonaws.com\nmy-alb-123.us-east-1.elb.amazonaws.com\nbucket1.s3-website-us-west-1.amazonaws.com\n"
+	if loot != expected {
+		t.Errorf("Unexpected hostname loot:\n%s", loot)
+	}
+	if strings.Count(loot, "my-alb-123") != 1 {
+		t.Errorf("Expected each hostname to only be listed once")
+	}
+}
+
+func TestGetAPIGatewayMethodsAuth(t *testing.T) {
+	subtests := []struct {
+		name     string
+		methods  map[string]apigatewayTypes.Method
+		expected string
+	}{
+		{
+			name:     "methods not embedded",
+			methods:  map[string]apigatewayTypes.Method{"GET": {}},
+			expected: "Unknown",
+		},
+		{
+			name: "IAM auth",
+			methods: map[string]apigatewayTypes.Method{
+				"GET": {AuthorizationType: aws.String("AWS_IAM")},
+			},
+			expected: "Required",
+		},
+		{
+			name: "API key only",
+			methods: map[string]apigatewayTypes.Method{
+				"GET": {AuthorizationType: aws.String("NONE"), ApiKeyRequired: aws.Bool(true)},
+			},
+			expected: "Required",
+		},
+		{
+			name: "one open method",
+			methods: map[string]apigatewayTypes.Method{
+				"GET":  {AuthorizationType: aws.String("NONE")},
+				"POST": {AuthorizationType: aws.String("COGNITO_USER_POOLS")},
+			},
+			expected: "None",
+		},
+	}
+
+	for _, subtest := range subtests {
+		if got := getAPIGatewayMethodsAuth(subtest.methods); got != subtest.expected {
+			t.Errorf("%s: got %s, expected %s", subtest.name, got, subtest.expected)
+		}
+	}
+}
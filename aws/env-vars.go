@@ -205,6 +205,8 @@ func (m *EnvsModule) PrintEnvs(outputDirectory string, verbosity int) {
 
 		o.PrefixIdentifier = m.AWSProfile
 		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
 
 		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
 			Header:    m.output.Headers,
@@ -235,12 +237,7 @@ func (m *EnvsModule) PrintEnvs(outputDirectory string, verbosity int) {
 func (m *EnvsModule) determineIfInteresting() {
 	for i, envVar := range m.EnvironmentVariables {
 		// If the environment variable name contains the word secret, key, token, or password, we'll make it magenta to make it stand out.
-		lowerEnvVar := strings.ToLower(envVar.environmentVarName)
-		if (strings.Contains(lowerEnvVar, "secret") && !strings.Contains(lowerEnvVar, "arn")) ||
-			strings.Contains(lowerEnvVar, "key") ||
-			strings.Contains(lowerEnvVar, "token") ||
-			strings.Contains(lowerEnvVar, "user") ||
-			strings.Contains(lowerEnvVar, "pass") {
+		if isSecretLookingName(envVar.environmentVarName) {
 			m.EnvironmentVariables[i].interesting = true
 		}
 	}
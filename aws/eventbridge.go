@@ -0,0 +1,712 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/aws/policy"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	eventbridgeTypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type EventBridgeModule struct {
+	// General configuration data
+	EventBridgeClient sdk.EventBridgeClientInterface
+	// IAMClient is used to simulate which buses the caller can put events on
+	IAMClient sdk.AWSIAMClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Buses []EventBridgeBus
+	// Targets has a row per rule target, and a row without a target for rules that have none
+	Targets        []EventBridgeTarget
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type EventBridgeBus struct {
+	Region string
+	Name   string
+	Arn    string
+	// Writers are the other accounts, organizations or * the resource policy lets put events on the bus
+	Writers            []string
+	CallerCanPutEvents bool
+	Rules              int
+	Findings           []string
+}
+
+type EventBridgeTarget struct {
+	Region    string
+	Bus       string
+	Rule      string
+	RuleState string
+	// Trigger is the schedule expression or a summary of the event pattern of the rule
+	Trigger      string
+	Source       string
+	DetailType   string
+	TargetArn    string
+	TargetType   string
+	TargetRole   string
+	CrossAccount bool
+}
+
+// eventBridgeRegion is everything found in a region, the Receiver splits it up
+type eventBridgeRegion struct {
+	Buses   []EventBridgeBus
+	Targets []EventBridgeTarget
+}
+
+const (
+	eventBridgePutEventsAction   = "events:PutEvents"
+	eventBridgeOtherAccounts     = "Other accounts can put events"
+	eventBridgeOrganization      = "Organization can put events"
+	eventBridgeAnyone            = "Anyone can put events"
+	eventBridgeDefaultTestSource = "cloudfox.test"
+)
+
+// eventBridgeExecutionTargets are the target types that run code or start a workflow when the rule matches
+var eventBridgeExecutionTargets = []string{"Lambda", "Step Functions", "ECS task", "CodeBuild", "SSM", "Batch", "CodePipeline", "SageMaker pipeline"}
+
+func (m *EventBridgeModule) PrintEventBridge(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "eventbridge"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating EventBridge buses, rules and targets for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan eventBridgeRegion)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.Buses, func(i, j int) bool {
+		if m.Buses[i].Region != m.Buses[j].Region {
+			return m.Buses[i].Region < m.Buses[j].Region
+		}
+		return m.Buses[i].Name < m.Buses[j].Name
+	})
+	sort.SliceStable(m.Targets, func(i, j int) bool {
+		if m.Targets[i].Region != m.Targets[j].Region {
+			return m.Targets[i].Region < m.Targets[j].Region
+		}
+		if m.Targets[i].Bus != m.Targets[j].Bus {
+			return m.Targets[i].Bus < m.Targets[j].Bus
+		}
+		return m.Targets[i].Rule < m.Targets[j].Rule
+	})
+
+	if m.IAMClient != nil {
+		m.checkCallerPutEvents()
+	}
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Bus",
+		"Rule",
+		"State",
+		"Trigger",
+		"Target",
+		"Target Type",
+		"Target Role",
+		"Cross-Account",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Bus",
+			"Rule",
+			"State",
+			"Trigger",
+			"Target",
+			"Target Type",
+			"Target Role",
+			"Cross-Account",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Bus",
+			"Rule",
+			"Trigger",
+			"Target",
+			"Cross-Account",
+		}
+	}
+
+	// Table rows
+	var crossAccount int
+	for _, target := range m.Targets {
+		crossAccountColumn := ""
+		if target.CrossAccount {
+			crossAccount++
+			crossAccountColumn = magenta("Yes")
+		} else if target.TargetArn != "" {
+			crossAccountColumn = "No"
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				target.Region,
+				target.Bus,
+				target.Rule,
+				target.RuleState,
+				target.Trigger,
+				target.TargetArn,
+				target.TargetType,
+				target.TargetRole,
+				crossAccountColumn,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 || len(m.Buses) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		if len(m.output.Body) > 0 {
+			o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+				Header:    m.output.Headers,
+				Body:      m.output.Body,
+				TableCols: tableCols,
+				Name:      m.output.CallingModule,
+			})
+		}
+		if len(m.Buses) > 0 {
+			o.Table.TableFiles = append(o.Table.TableFiles, m.busesTable())
+		}
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     m.output.CallingModule,
+			Contents: m.writeLoot(),
+		})
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d rule targets found on %d event buses, %d of them in other accounts.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), len(m.Buses), crossAccount)
+		fmt.Printf("[%s][%s] %d event buses accept events from other accounts.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.countExternallyWritable())
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No EventBridge rules or custom event buses found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *EventBridgeModule) busesTable() internal.TableFile {
+	header := []string{
+		"Account",
+		"Region",
+		"Bus",
+		"Rules",
+		"Writers",
+		"Caller Can PutEvents",
+		"Findings",
+	}
+	var body [][]string
+	for _, bus := range m.Buses {
+		var findings []string
+		for _, finding := range bus.Findings {
+			findings = append(findings, magenta(finding))
+		}
+		body = append(body, []string{
+			aws.ToString(m.Caller.Account),
+			bus.Region,
+			bus.Name,
+			fmt.Sprint(bus.Rules),
+			strings.Join(bus.Writers, "\n"),
+			fmt.Sprint(bus.CallerCanPutEvents),
+			strings.Join(findings, "\n"),
+		})
+	}
+	return internal.TableFile{
+		Header:    header,
+		Body:      body,
+		TableCols: []string{"Region", "Bus", "Rules", "Writers", "Caller Can PutEvents", "Findings"},
+		Name:      "eventbridge-buses",
+	}
+}
+
+func (m *EventBridgeModule) countExternallyWritable() int {
+	var count int
+	for _, bus := range m.Buses {
+		if len(bus.Writers) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// checkCallerPutEvents simulates events:PutEvents on every bus for the caller. The root user can do everything and
+// can't be simulated.
+func (m *EventBridgeModule) checkCallerPutEvents() {
+	if len(m.Buses) == 0 {
+		return
+	}
+	sourceArn, err := policySimulatorSourceArn(m.IAMClient, m.Caller)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	if sourceArn == "" {
+		return
+	}
+	if strings.HasSuffix(sourceArn, ":root") {
+		for i := range m.Buses {
+			m.Buses[i].CallerCanPutEvents = true
+		}
+		return
+	}
+
+	var busArns []string
+	for _, bus := range m.Buses {
+		busArns = append(busArns, bus.Arn)
+	}
+	evaluationResults, err := sdk.CachedIamSimulatePrincipalPolicy(m.IAMClient, aws.ToString(m.Caller.Account), aws.String(sourceArn), []string{eventBridgePutEventsAction}, busArns)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	for _, result := range evaluationResults {
+		if aws.ToString(result.EvalActionName) != eventBridgePutEventsAction || result.EvalDecision != "allowed" {
+			continue
+		}
+		for i := range m.Buses {
+			if m.Buses[i].Arn == aws.ToString(result.EvalResourceName) {
+				m.Buses[i].CallerCanPutEvents = true
+			}
+		}
+	}
+}
+
+// writeLoot has put-events templates for the buses the caller can write to, one that matches each rule with a custom
+// source, and lists the rules that run code as persistence and execution primitives
+func (m *EventBridgeModule) writeLoot() string {
+	var out string
+	out += "#############################################\n"
+	out += "# Put events on the buses you can write to. An event that matches a rule runs its targets, the templates\n"
+	out += "# listed under a rule match its pattern. Custom events can't use a source that starts with aws.\n"
+	out += "# Set the $profile environment variable to the profile you are going to use, e.g. export profile=dev-prod.\n"
+	out += "#############################################\n"
+
+	for _, bus := range m.Buses {
+		if !bus.CallerCanPutEvents {
+			continue
+		}
+		out += fmt.Sprintf("\n# %s in %s\n", bus.Name, bus.Region)
+		out += eventBridgePutEventsCommand(bus, eventBridgeDefaultTestSource, "cloudfox")
+		for _, target := range m.Targets {
+			if target.Region != bus.Region || target.Bus != bus.Name || target.Source == "" || strings.HasPrefix(target.Source, "aws.") {
+				continue
+			}
+			out += fmt.Sprintf("# triggers %s\n", target.Rule)
+			out += eventBridgePutEventsCommand(bus, target.Source, target.DetailType)
+			// One template per rule is enough, the rule has a row per target
+			break
+		}
+	}
+
+	out += "\n#############################################\n"
+	out += "# Rules that run code. Whoever can put matching events runs the target, and whoever can change the rule or\n"
+	out += "# its targets (events:PutRule, events:PutTargets) has a persistence and execution primitive.\n"
+	out += "#############################################\n"
+	for _, target := range m.Targets {
+		if !internal.Contains(target.TargetType, eventBridgeExecutionTargets) {
+			continue
+		}
+		out += fmt.Sprintf("\n# %s on %s (%s) runs %s %s\n", target.Rule, target.Bus, target.Region, target.TargetType, target.TargetArn)
+		if target.TargetRole != "" {
+			out += fmt.Sprintf("# as %s\n", target.TargetRole)
+		}
+		out += fmt.Sprintf("aws --profile $profile --region %s events describe-rule --name %s --event-bus-name %s\n", target.Region, target.Rule, target.Bus)
+		out += fmt.Sprintf("aws --profile $profile --region %s events list-targets-by-rule --rule %s --event-bus-name %s\n", target.Region, target.Rule, target.Bus)
+	}
+	return out
+}
+
+func eventBridgePutEventsCommand(bus EventBridgeBus, source string, detailType string) string {
+	return fmt.Sprintf("aws --profile $profile --region %s events put-events --entries '[{\"EventBusName\":\"%s\",\"Source\":\"%s\",\"DetailType\":\"%s\",\"Detail\":\"{}\"}]'\n", bus.Region, bus.Arn, source, detailType)
+}
+
+func (m *EventBridgeModule) Receiver(receiver chan eventBridgeRegion, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Buses = append(m.Buses, data.Buses...)
+			m.Targets = append(m.Targets, data.Targets...)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *EventBridgeModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan eventBridgeRegion) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("events", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getEventBridgePerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *EventBridgeModule) getEventBridgePerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan eventBridgeRegion) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	buses, err := sdk.CachedEventBridgeListEventBuses(m.EventBridgeClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	var region eventBridgeRegion
+	for _, eventBus := range buses {
+		bus := EventBridgeBus{
+			Region: r,
+			Name:   aws.ToString(eventBus.Name),
+			Arn:    aws.ToString(eventBus.Arn),
+		}
+		if busPolicy := aws.ToString(eventBus.Policy); busPolicy != "" {
+			parsedPolicy, err := policy.ParseJSONPolicy([]byte(busPolicy))
+			if err != nil {
+				m.modLog.Error(fmt.Sprintf("parsing the resource policy of %s: %s", bus.Name, err))
+			} else {
+				bus.Writers, bus.Findings = eventBridgeBusWriters(parsedPolicy, aws.ToString(m.Caller.Account))
+			}
+		}
+
+		rules, err := sdk.CachedEventBridgeListRules(m.EventBridgeClient, aws.ToString(m.Caller.Account), r, bus.Name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+		bus.Rules = len(rules)
+		for _, rule := range rules {
+			region.Targets = append(region.Targets, m.ruleTargets(r, bus.Name, rule)...)
+		}
+
+		// Every region has a default bus, it is only worth a row when something uses it
+		if bus.Name == "default" && bus.Rules == 0 && len(bus.Writers) == 0 {
+			continue
+		}
+		region.Buses = append(region.Buses, bus)
+	}
+
+	dataReceiver <- region
+}
+
+func (m *EventBridgeModule) ruleTargets(r string, busName string, rule eventbridgeTypes.Rule) []EventBridgeTarget {
+	row := EventBridgeTarget{
+		Region:    r,
+		Bus:       busName,
+		Rule:      aws.ToString(rule.Name),
+		RuleState: string(rule.State),
+	}
+	if rule.ScheduleExpression != nil {
+		row.Trigger = fmt.Sprintf("schedule: %s", aws.ToString(rule.ScheduleExpression))
+	} else {
+		row.Trigger, row.Source, row.DetailType = summarizeEventPattern(aws.ToString(rule.EventPattern))
+	}
+
+	targets, err := sdk.CachedEventBridgeListTargetsByRule(m.EventBridgeClient, aws.ToString(m.Caller.Account), r, busName, row.Rule)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	if len(targets) == 0 {
+		return []EventBridgeTarget{row}
+	}
+
+	var rows []EventBridgeTarget
+	for _, target := range targets {
+		targetRow := row
+		targetRow.TargetArn = aws.ToString(target.Arn)
+		targetRow.TargetType = eventBridgeTargetType(targetRow.TargetArn)
+		targetRow.TargetRole = aws.ToString(target.RoleArn)
+		if parsed, err := arn.Parse(targetRow.TargetArn); err == nil && parsed.AccountID != "" {
+			targetRow.CrossAccount = parsed.AccountID != aws.ToString(m.Caller.Account)
+		}
+		rows = append(rows, targetRow)
+	}
+	return rows
+}
+
+// summarizeEventPattern puts the top-level fields of an event pattern on one line, and returns the first source and
+// detail-type so an event that matches can be put
+func summarizeEventPattern(eventPattern string) (string, string, string) {
+	if eventPattern == "" {
+		return "", "", ""
+	}
+	var pattern map[string]interface{}
+	if err := json.Unmarshal([]byte(eventPattern), &pattern); err != nil {
+		return eventPattern, "", ""
+	}
+
+	var keys []string
+	for key := range pattern {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var fields []string
+	var source, detailType string
+	for _, key := range keys {
+		values, isStrings := eventPatternStrings(pattern[key])
+		if !isStrings {
+			value, _ := json.Marshal(pattern[key])
+			fields = append(fields, fmt.Sprintf("%s=%s", key, value))
+			continue
+		}
+		fields = append(fields, fmt.Sprintf("%s=%s", key, strings.Join(values, ",")))
+		switch key {
+		case "source":
+			source = values[0]
+		case "detail-type":
+			detailType = values[0]
+		}
+	}
+	return strings.Join(fields, "; "), source, detailType
+}
+
+// eventPatternStrings returns the values of a pattern field that only lists exact strings
+func eventPatternStrings(value interface{}) ([]string, bool) {
+	list, ok := value.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, false
+	}
+	var values []string
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, s)
+	}
+	return values, true
+}
+
+func eventBridgeTargetType(targetArn string) string {
+	parsed, err := arn.Parse(targetArn)
+	if err != nil {
+		return ""
+	}
+	switch parsed.Service {
+	case "lambda":
+		return "Lambda"
+	case "sqs":
+		return "SQS"
+	case "sns":
+		return "SNS"
+	case "states":
+		return "Step Functions"
+	case "events":
+		if strings.HasPrefix(parsed.Resource, "event-bus/") {
+			return "Event bus"
+		}
+		if strings.HasPrefix(parsed.Resource, "api-destination/") {
+			return "API destination"
+		}
+	case "ecs":
+		return "ECS task"
+	case "codebuild":
+		return "CodeBuild"
+	case "ssm":
+		return "SSM"
+	case "batch":
+		return "Batch"
+	case "codepipeline":
+		return "CodePipeline"
+	case "sagemaker":
+		return "SageMaker pipeline"
+	case "kinesis":
+		return "Kinesis"
+	case "firehose":
+		return "Firehose"
+	case "logs":
+		return "CloudWatch Logs"
+	}
+	return parsed.Service
+}
+
+// eventBridgeBusWriters returns who besides the bus's own account the resource policy lets put events on it, and the
+// findings for them. A wildcard principal is narrowed down by aws:PrincipalOrgID and aws:PrincipalAccount conditions.
+func eventBridgeBusWriters(busPolicy policy.Policy, accountID string) ([]string, []string) {
+	var writers []string
+	var otherAccounts, organization, anyone bool
+	add := func(writer string) {
+		if !internal.Contains(writer, writers) {
+			writers = append(writers, writer)
+		}
+	}
+	for _, statement := range busPolicy.Statement {
+		if !statement.IsAllow() || !eventBridgeStatementGrants(statement, eventBridgePutEventsAction) {
+			continue
+		}
+		if statement.Principal.IsPublic() {
+			orgs := eventBridgeConditionValues(statement.Condition, "aws:PrincipalOrgID")
+			accounts := eventBridgeConditionValues(statement.Condition, "aws:PrincipalAccount")
+			for _, org := range orgs {
+				organization = true
+				add(org)
+			}
+			for _, account := range accounts {
+				if account != accountID {
+					otherAccounts = true
+					add(account)
+				}
+			}
+			if len(orgs) == 0 && len(accounts) == 0 {
+				anyone = true
+				add("*")
+			}
+			continue
+		}
+		for _, principal := range statement.Principal.O.AWS {
+			account := principal
+			if parsed, err := arn.Parse(principal); err == nil {
+				account = parsed.AccountID
+			}
+			if reAccountID.MatchString(account) && account != accountID {
+				otherAccounts = true
+				add(account)
+			}
+		}
+	}
+
+	var findings []string
+	if anyone {
+		findings = append(findings, eventBridgeAnyone)
+	}
+	if organization {
+		findings = append(findings, eventBridgeOrganization)
+	}
+	if otherAccounts {
+		findings = append(findings, eventBridgeOtherAccounts)
+	}
+	return writers, findings
+}
+
+func eventBridgeStatementGrants(statement policy.PolicyStatement, action string) bool {
+	for _, pattern := range statement.Action {
+		if policy.MatchesAfterExpansion(action, pattern) {
+			return true
+		}
+	}
+	if len(statement.NotAction) == 0 {
+		return false
+	}
+	for _, pattern := range statement.NotAction {
+		if policy.MatchesAfterExpansion(action, pattern) {
+			return false
+		}
+	}
+	return true
+}
+
+// eventBridgeConditionValues returns the values a statement's conditions require for a key, whatever the operator
+func eventBridgeConditionValues(condition policy.PolicyStatementCondition, conditionKey string) []string {
+	var values []string
+	for _, keys := range condition {
+		for key, keyValues := range keys {
+			if strings.EqualFold(key, conditionKey) {
+				values = append(values, keyValues...)
+			}
+		}
+	}
+	sort.Strings(values)
+	return values
+}
@@ -0,0 +1,106 @@
+package aws
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestEventBridge(t *testing.T) {
+	m := EventBridgeModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:        3,
+		EventBridgeClient: &sdk.MockedEventBridgeClient{},
+		IAMClient:         &sdk.MockedIAMClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintEventBridge(".", 2)
+
+	expectedBuses := []EventBridgeBus{
+		{
+			Region:             "us-east-1",
+			Name:               "audit",
+			Arn:                "arn:aws:events:us-east-1:123456789012:event-bus/audit",
+			CallerCanPutEvents: true,
+		},
+		{
+			Region:             "us-east-1",
+			Name:               "default",
+			Arn:                "arn:aws:events:us-east-1:123456789012:event-bus/default",
+			Writers:            []string{"111122223333"},
+			CallerCanPutEvents: true,
+			Rules:              2,
+			Findings:           []string{eventBridgeOtherAccounts},
+		},
+		{
+			Region:             "us-east-1",
+			Name:               "orders",
+			Arn:                "arn:aws:events:us-east-1:123456789012:event-bus/orders",
+			Writers:            []string{"o-a1b2c3d4e5"},
+			CallerCanPutEvents: true,
+			Rules:              1,
+			Findings:           []string{eventBridgeOrganization},
+		},
+	}
+	if !reflect.DeepEqual(m.Buses, expectedBuses) {
+		t.Errorf("Expected buses %+v, got %+v", expectedBuses, m.Buses)
+	}
+
+	if len(m.Targets) != 5 {
+		t.Fatalf("Expected 5 rule targets, got %d", len(m.Targets))
+	}
+	for _, target := range m.Targets {
+		switch target.TargetArn {
+		case "arn:aws:events:us-east-1:999988887777:event-bus/security-central":
+			if !target.CrossAccount || target.TargetType != "Event bus" {
+				t.Errorf("Expected security-central to be a cross-account event bus target, got %+v", target)
+			}
+			if target.Trigger != "detail-type=EC2 Instance State-change Notification; source=aws.ec2" {
+				t.Errorf("Unexpected trigger for ec2-state-to-security: %s", target.Trigger)
+			}
+		case "arn:aws:lambda:us-east-1:123456789012:function:cleanup":
+			if target.CrossAccount || target.Trigger != "schedule: rate(1 day)" {
+				t.Errorf("Unexpected nightly-cleanup target %+v", target)
+			}
+		case "arn:aws:states:us-east-1:123456789012:stateMachine:fulfil-order":
+			if target.TargetType != "Step Functions" || target.Source != "com.shop.orders" || target.DetailType != "OrderCreated" {
+				t.Errorf("Unexpected order-created target %+v", target)
+			}
+		}
+	}
+
+	lootFilePath := filepath.Join(".", "cloudfox-output/aws/unittesting-123456789012/loot/eventbridge.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	expectedCommands := []string{
+		`aws --profile $profile --region us-east-1 events put-events --entries '[{"EventBusName":"arn:aws:events:us-east-1:123456789012:event-bus/orders","Source":"com.shop.orders","DetailType":"OrderCreated","Detail":"{}"}]'`,
+		"# nightly-cleanup on default (us-east-1) runs Lambda arn:aws:lambda:us-east-1:123456789012:function:cleanup",
+		"# as arn:aws:iam::123456789012:role/eventbridge-start-fulfilment",
+	}
+	for _, expected := range expectedCommands {
+		if !strings.Contains(string(lootFile), expected) {
+			t.Errorf("Expected %s to be in the loot file", expected)
+		}
+	}
+	// aws.* sources are reserved, there is no template for ec2-state-to-security
+	if strings.Contains(string(lootFile), `"Source":"aws.ec2"`) {
+		t.Errorf("Did not expect a put-events template with an aws.ec2 source")
+	}
+}
@@ -0,0 +1,387 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	glueTypes "github.com/aws/aws-sdk-go-v2/service/glue/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type GlueDataCatalogModule struct {
+	// General configuration data
+	GlueClient sdk.AWSGlueClientInterface
+	S3Client   sdk.AWSS3ClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Tables         []GlueCatalogTable
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type GlueCatalogTable struct {
+	Region         string
+	Database       string
+	Name           string
+	TableType      string
+	Classification string
+	// Location is the table's S3 location with the s3a:// and s3n:// schemes of Hadoop tools turned into s3://, Bucket
+	// the bucket in it
+	Location     string
+	Bucket       string
+	Columns      []string
+	PublicBucket bool
+	Findings     []string
+}
+
+const glueCatalogPublicBucket = "Location in a public bucket"
+
+func (m *GlueDataCatalogModule) PrintGlueDataCatalog(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "glue-catalog"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating Glue Data Catalog databases and tables for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan GlueCatalogTable)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	m.flagPublicLocations()
+
+	sort.Slice(m.Tables, func(i, j int) bool {
+		if m.Tables[i].Region != m.Tables[j].Region {
+			return m.Tables[i].Region < m.Tables[j].Region
+		}
+		if m.Tables[i].Database != m.Tables[j].Database {
+			return m.Tables[i].Database < m.Tables[j].Database
+		}
+		return m.Tables[i].Name < m.Tables[j].Name
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Database",
+		"Table",
+		"Type",
+		"Classification",
+		"Location",
+		"Columns",
+		"Findings",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Database",
+			"Table",
+			"Type",
+			"Classification",
+			"Location",
+			"Columns",
+			"Findings",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Database",
+			"Table",
+			"Classification",
+			"Location",
+			"Findings",
+		}
+	}
+
+	// Table rows
+	for _, table := range m.Tables {
+		var findings []string
+		for _, finding := range table.Findings {
+			findings = append(findings, magenta(finding))
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				table.Region,
+				table.Database,
+				table.Name,
+				table.TableType,
+				table.Classification,
+				table.Location,
+				strings.Join(table.Columns, ", "),
+				strings.Join(findings, "\n"),
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     m.output.CallingModule,
+			Contents: m.writeLoot(),
+		})
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d Glue tables found, %d stored in public buckets.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), m.countPublic())
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No Glue tables found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *GlueDataCatalogModule) countPublic() int {
+	var count int
+	for _, table := range m.Tables {
+		if table.PublicBucket {
+			count++
+		}
+	}
+	return count
+}
+
+// flagPublicLocations makes the same bucket policy check as the buckets module for every bucket a table is stored in.
+// Buckets of other accounts usually deny GetBucketPolicy and stay unflagged.
+func (m *GlueDataCatalogModule) flagPublicLocations() {
+	publicBuckets := make(map[string]bool)
+	for _, table := range m.Tables {
+		if table.Bucket == "" {
+			continue
+		}
+		if _, checked := publicBuckets[table.Bucket]; checked {
+			continue
+		}
+		publicBuckets[table.Bucket] = m.isBucketPublic(table.Bucket)
+	}
+	for i := range m.Tables {
+		if publicBuckets[m.Tables[i].Bucket] {
+			m.Tables[i].PublicBucket = true
+			m.Tables[i].Findings = append(m.Tables[i].Findings, glueCatalogPublicBucket)
+		}
+	}
+}
+
+func (m *GlueDataCatalogModule) isBucketPublic(bucketName string) bool {
+	public, err := isBucketPublic(m.S3Client, aws.ToString(m.Caller.Account), bucketName)
+	if err != nil {
+		m.modLog.Error(err.Error())
+	}
+	return public
+}
+
+// writeLoot lists the table locations, the ones in public buckets first and also without credentials
+func (m *GlueDataCatalogModule) writeLoot() string {
+	var out string
+	out += "#############################################\n"
+	out += "# List the data behind the Glue tables. Locations in public buckets can also be listed without credentials.\n"
+	out += "#############################################\n"
+
+	listed := make(map[string]bool)
+	for _, public := range []bool{true, false} {
+		for _, table := range m.Tables {
+			if table.Location == "" || table.PublicBucket != public || listed[table.Location] {
+				continue
+			}
+			listed[table.Location] = true
+			out += fmt.Sprintf("\n# %s.%s in %s", table.Database, table.Name, table.Region)
+			if table.Classification != "" {
+				out += fmt.Sprintf(", %s", table.Classification)
+			}
+			out += "\n"
+			if public {
+				out += fmt.Sprintf("aws s3 ls %s --no-sign-request\n", table.Location)
+			}
+			out += fmt.Sprintf("aws --profile $profile s3 ls %s --recursive --human-readable --summarize\n", table.Location)
+		}
+	}
+	return out
+}
+
+func (m *GlueDataCatalogModule) Receiver(receiver chan GlueCatalogTable, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Tables = append(m.Tables, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *GlueDataCatalogModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan GlueCatalogTable) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("glue", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getTablesPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *GlueDataCatalogModule) getTablesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan GlueCatalogTable) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	databases, err := sdk.CachedGlueGetDatabases(m.GlueClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, database := range databases {
+		tables, err := sdk.CachedGlueGetTables(m.GlueClient, aws.ToString(m.Caller.Account), r, aws.ToString(database.Name))
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		for _, table := range tables {
+			dataReceiver <- newGlueCatalogTable(r, aws.ToString(database.Name), table)
+		}
+	}
+}
+
+func newGlueCatalogTable(r string, database string, table glueTypes.Table) GlueCatalogTable {
+	catalogTable := GlueCatalogTable{
+		Region:         r,
+		Database:       database,
+		Name:           aws.ToString(table.Name),
+		TableType:      aws.ToString(table.TableType),
+		Classification: table.Parameters["classification"],
+	}
+	if table.StorageDescriptor != nil {
+		// Crawlers put the classification on the table, tables created with DDL often only on the storage descriptor
+		if catalogTable.Classification == "" {
+			catalogTable.Classification = table.StorageDescriptor.Parameters["classification"]
+		}
+		catalogTable.Location, catalogTable.Bucket = glueS3Location(aws.ToString(table.StorageDescriptor.Location))
+		for _, column := range table.StorageDescriptor.Columns {
+			catalogTable.Columns = append(catalogTable.Columns, fmt.Sprintf("%s:%s", aws.ToString(column.Name), aws.ToString(column.Type)))
+		}
+	}
+	for _, column := range table.PartitionKeys {
+		catalogTable.Columns = append(catalogTable.Columns, fmt.Sprintf("%s:%s (partition)", aws.ToString(column.Name), aws.ToString(column.Type)))
+	}
+	return catalogTable
+}
+
+// glueS3Location returns the location as an s3:// URL and its bucket, or the location unchanged and no bucket if it
+// isn't in S3, like the JDBC locations of tables crawled from databases
+func glueS3Location(location string) (string, string) {
+	for _, scheme := range []string{"s3://", "s3a://", "s3n://"} {
+		if !strings.HasPrefix(location, scheme) {
+			continue
+		}
+		path := strings.TrimPrefix(location, scheme)
+		bucket, _, _ := strings.Cut(path, "/")
+		if bucket == "" {
+			return location, ""
+		}
+		if !strings.HasSuffix(path, "/") {
+			path += "/"
+		}
+		return "s3://" + path, bucket
+	}
+	return location, ""
+}
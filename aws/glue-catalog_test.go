@@ -0,0 +1,83 @@
+package aws
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestGlueDataCatalog(t *testing.T) {
+	m := GlueDataCatalogModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines: 3,
+		GlueClient: &sdk.MockedGlueClient{},
+		S3Client:   &sdk.MockedS3Client{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintGlueDataCatalog(".", 2)
+
+	expected := []GlueCatalogTable{
+		{
+			Region:         "us-east-1",
+			Database:       "database1",
+			Name:           "table1",
+			Classification: "csv",
+			Location:       "s3://datalake-public-exports/customers/",
+			Bucket:         "datalake-public-exports",
+			Columns:        []string{"id:bigint", "email:string"},
+			PublicBucket:   true,
+			Findings:       []string{glueCatalogPublicBucket},
+		},
+		{
+			Region:    "us-east-1",
+			Database:  "database1",
+			Name:      "table3",
+			TableType: "VIRTUAL_VIEW",
+		},
+		{
+			Region:         "us-east-1",
+			Database:       "database2",
+			Name:           "table2",
+			Classification: "parquet",
+			Location:       "s3://datalake-raw/events/",
+			Bucket:         "datalake-raw",
+			Columns:        []string{"event_time:timestamp"},
+		},
+	}
+	if !reflect.DeepEqual(m.Tables, expected) {
+		t.Errorf("Expected tables %+v, got %+v", expected, m.Tables)
+	}
+
+	lootFilePath := filepath.Join(".", "cloudfox-output/aws/unittesting-123456789012/loot/glue-catalog.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	expectedCommands := []string{
+		"aws s3 ls s3://datalake-public-exports/customers/ --no-sign-request",
+		"aws --profile $profile s3 ls s3://datalake-raw/events/ --recursive --human-readable --summarize",
+	}
+	for _, expected := range expectedCommands {
+		if !strings.Contains(string(lootFile), expected) {
+			t.Errorf("Expected %s to be in the loot file", expected)
+		}
+	}
+	if strings.Contains(string(lootFile), "datalake-raw/events/ --no-sign-request") {
+		t.Errorf("Did not expect an unsigned request to a private bucket")
+	}
+}
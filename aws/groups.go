@@ -0,0 +1,320 @@
+package aws
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/aws/policy"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/sirupsen/logrus"
+)
+
+type GroupsModule struct {
+	// General configuration data
+	IAMClient      sdk.AWSIAMClientInterface
+	Caller         sts.GetCallerIdentityOutput
+	AWSProfile     string
+	Goroutines     int
+	WrapTable      bool
+	AWSOutputType  string
+	AWSTableCols   string
+	CommandCounter internal.CommandCounter
+
+	// Main module data
+	Groups []IAMGroup
+
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type IAMGroup struct {
+	Name             string
+	Arn              string
+	Members          []string
+	AttachedPolicies []string
+	InlinePolicies   []string
+	// The most powerful policy attached to the group, and what makes it powerful
+	TopPolicy      string
+	TopPolicyPower groupPolicyPower
+	Risk           string
+}
+
+// groupPolicyPower ranks how much a policy lets the group's members do. Lower is more powerful.
+type groupPolicyPower int
+
+const (
+	groupPolicyPowerAdmin groupPolicyPower = iota
+	groupPolicyPowerIAMFull
+	groupPolicyPowerPowerUser
+	groupPolicyPowerOther
+)
+
+var groupPolicyPowerNames = map[groupPolicyPower]string{
+	groupPolicyPowerAdmin:     "full admin",
+	groupPolicyPowerIAMFull:   "iam:*",
+	groupPolicyPowerPowerUser: "power user",
+}
+
+// AWS managed policies we know the power of without reading their documents
+var groupManagedPolicyPower = map[string]groupPolicyPower{
+	"AdministratorAccess": groupPolicyPowerAdmin,
+	"IAMFullAccess":       groupPolicyPowerIAMFull,
+	"PowerUserAccess":     groupPolicyPowerPowerUser,
+}
+
+func (m *GroupsModule) PrintGroups(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "groups"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Mapping IAM group memberships and policies for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+	m.getGroups()
+
+	sort.Slice(m.Groups, func(i, j int) bool {
+		if m.Groups[i].TopPolicyPower != m.Groups[j].TopPolicyPower {
+			return m.Groups[i].TopPolicyPower < m.Groups[j].TopPolicyPower
+		}
+		return m.Groups[i].Name < m.Groups[j].Name
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Group",
+		"Arn",
+		"Member Count",
+		"Members",
+		"Attached Policies",
+		"Inline Policies",
+		"Most Powerful Policy",
+		"Risk",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+		// If the user specified wide as the output format, use these columns.
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Group",
+			"Arn",
+			"Member Count",
+			"Members",
+			"Attached Policies",
+			"Inline Policies",
+			"Most Powerful Policy",
+			"Risk",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Group",
+			"Member Count",
+			"Attached Policies",
+			"Inline Policies",
+			"Most Powerful Policy",
+			"Risk",
+		}
+	}
+
+	// Table rows
+	for _, group := range m.Groups {
+		risk := group.Risk
+		if risk != "" {
+			risk = magenta(risk)
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				group.Name,
+				group.Arn,
+				strconv.Itoa(len(group.Members)),
+				strings.Join(group.Members, ", "),
+				strings.Join(group.AttachedPolicies, ", "),
+				strings.Join(group.InlinePolicies, ", "),
+				group.TopPolicy,
+				risk,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s groups found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No groups found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *GroupsModule) getGroups() {
+	groups, err := sdk.CachedIamListGroups(m.IAMClient, aws.ToString(m.Caller.Account))
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	// The documents of customer managed policies come from the authorization details, so we don't need a
+	// GetPolicyVersion call per policy
+	managedPolicyDocuments := make(map[string]string)
+	authorizationDetails, err := sdk.CachedIAMGetAccountAuthorizationDetails(m.IAMClient, aws.ToString(m.Caller.Account))
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	for _, managedPolicy := range authorizationDetails.Policies {
+		for _, version := range managedPolicy.PolicyVersionList {
+			if version.IsDefaultVersion {
+				managedPolicyDocuments[aws.ToString(managedPolicy.Arn)] = aws.ToString(version.Document)
+			}
+		}
+	}
+
+	for _, g := range groups {
+		group := IAMGroup{
+			Name:           aws.ToString(g.GroupName),
+			Arn:            aws.ToString(g.Arn),
+			TopPolicyPower: groupPolicyPowerOther,
+		}
+
+		members, err := sdk.CachedIamGetGroupMembers(m.IAMClient, aws.ToString(m.Caller.Account), group.Name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+		for _, member := range members {
+			group.Members = append(group.Members, aws.ToString(member.UserName))
+		}
+
+		attachedPolicies, err := sdk.CachedIamListAttachedGroupPolicies(m.IAMClient, aws.ToString(m.Caller.Account), group.Name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+		for _, attachedPolicy := range attachedPolicies {
+			name := aws.ToString(attachedPolicy.PolicyName)
+			group.AttachedPolicies = append(group.AttachedPolicies, name)
+			power, ok := groupManagedPolicyPower[name]
+			if !ok {
+				power = groupPolicyDocumentPower(managedPolicyDocuments[aws.ToString(attachedPolicy.PolicyArn)])
+			}
+			group.addPolicy(name, power)
+		}
+
+		inlinePolicies, err := sdk.CachedIamListGroupPolicies(m.IAMClient, aws.ToString(m.Caller.Account), group.Name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+		for _, name := range inlinePolicies {
+			group.InlinePolicies = append(group.InlinePolicies, name)
+			document, err := sdk.CachedIamGetGroupPolicy(m.IAMClient, aws.ToString(m.Caller.Account), group.Name, name)
+			if err != nil {
+				m.modLog.Error(err.Error())
+				m.CommandCounter.Error++
+				continue
+			}
+			group.addPolicy(fmt.Sprintf("%s (inline)", name), groupPolicyDocumentPower(document))
+		}
+
+		group.TopPolicy = groupTopPolicySummary(group)
+		// A powerful group with a single member is no worse than attaching the policy to the user. Handing it to
+		// several users at once is what gets overlooked.
+		if group.TopPolicyPower <= groupPolicyPowerIAMFull && len(group.Members) > 1 {
+			group.Risk = "HIGH"
+		}
+		m.Groups = append(m.Groups, group)
+	}
+}
+
+// addPolicy keeps track of the most powerful policy attached to the group
+func (g *IAMGroup) addPolicy(name string, power groupPolicyPower) {
+	if g.TopPolicy == "" || power < g.TopPolicyPower {
+		g.TopPolicy = name
+		g.TopPolicyPower = power
+	}
+}
+
+func groupTopPolicySummary(group IAMGroup) string {
+	if group.TopPolicy == "" {
+		return ""
+	}
+	if powerName, ok := groupPolicyPowerNames[group.TopPolicyPower]; ok {
+		return fmt.Sprintf("%s: %s", group.TopPolicy, powerName)
+	}
+	return group.TopPolicy
+}
+
+// groupPolicyDocumentPower looks for statements that allow every action, or every IAM action, on every resource.
+// Documents returned by IAM are URL encoded.
+func groupPolicyDocumentPower(document string) groupPolicyPower {
+	power := groupPolicyPowerOther
+	if document == "" {
+		return power
+	}
+	decoded, err := url.QueryUnescape(document)
+	if err != nil {
+		decoded = document
+	}
+	parsedPolicy, err := policy.ParseJSONPolicy([]byte(decoded))
+	if err != nil {
+		return power
+	}
+
+	for _, statement := range parsedPolicy.Statement {
+		if !statement.IsAllow() || !internal.Contains("*", statement.Resource) {
+			continue
+		}
+		for _, action := range statement.Action {
+			switch strings.ToLower(action) {
+			case "*", "*:*":
+				return groupPolicyPowerAdmin
+			case "iam:*":
+				power = groupPolicyPowerIAMFull
+			}
+		}
+	}
+	return power
+}
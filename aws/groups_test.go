@@ -0,0 +1,67 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+func TestGroups(t *testing.T) {
+	m := GroupsModule{
+		AWSProfile: "unittesting",
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::777777777777:user/Alice"),
+			Account: aws.String("777777777777"),
+		},
+		Goroutines: 3,
+		IAMClient:  &sdk.MockedIAMClient{},
+	}
+
+	internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintGroups(".", 2)
+
+	type summary struct {
+		name      string
+		members   []string
+		topPolicy string
+		risk      string
+	}
+	expected := []summary{
+		{"group1", []string{"user1", "user2"}, "AdministratorAccess: full admin", "HIGH"},
+		{"group2", []string{"user1"}, "manage-iam (inline): iam:*", ""},
+	}
+	var got []summary
+	for _, group := range m.Groups {
+		got = append(got, summary{group.Name, group.Members, group.TopPolicy, group.Risk})
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestGroupPolicyDocumentPower(t *testing.T) {
+	subtests := map[string]struct {
+		document string
+		expected groupPolicyPower
+	}{
+		"admin":            {`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"*","Resource":"*"}]}`, groupPolicyPowerAdmin},
+		"iam full":         {`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject","iam:*"],"Resource":"*"}]}`, groupPolicyPowerIAMFull},
+		"scoped iam":       {`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"iam:*","Resource":"arn:aws:iam::123456789012:user/${aws:username}"}]}`, groupPolicyPowerOther},
+		"deny":             {`{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Action":"*","Resource":"*"}]}`, groupPolicyPowerOther},
+		"url encoded":      {"%7B%22Statement%22%3A%5B%7B%22Effect%22%3A%22Allow%22%2C%22Action%22%3A%22%2A%22%2C%22Resource%22%3A%22%2A%22%7D%5D%7D", groupPolicyPowerAdmin},
+		"missing document": {"", groupPolicyPowerOther},
+	}
+	for name, subtest := range subtests {
+		t.Run(name, func(t *testing.T) {
+			if power := groupPolicyDocumentPower(subtest.document); power != subtest.expected {
+				t.Errorf("Expected %d, got %d", subtest.expected, power)
+			}
+		})
+	}
+}
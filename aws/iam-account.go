@@ -0,0 +1,309 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/sirupsen/logrus"
+)
+
+// Categories of the account wide IAM settings, also used as the row names of iam-summary
+const (
+	IAMCategoryPasswordPolicy     = "Password policy"
+	IAMCategoryPermissionBoundary = "Permission boundaries"
+	IAMCategoryServiceLinkedRole  = "Service-linked roles"
+	IAMCategoryOIDCProvider       = "OIDC providers"
+)
+
+const (
+	iamNoPasswordPolicy             = "No password policy"
+	iamRecommendedMinPasswordLength = 14
+)
+
+// IAMAccountModule lists the account wide IAM settings that no other IAM module covers: the password policy, the
+// principals with a permission boundary, the service-linked roles and the OIDC identity providers.
+type IAMAccountModule struct {
+	// General configuration data
+	IAMClient        sdk.AWSIAMClientInterface
+	IAMAccountClient sdk.AWSIAMAccountClientInterface
+	Caller           sts.GetCallerIdentityOutput
+	AWSProfile       string
+	Goroutines       int
+	WrapTable        bool
+	AWSOutputType    string
+	AWSTableCols     string
+	CommandCounter   internal.CommandCounter
+
+	// Main module data
+	Items []IAMAccountItem
+
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type IAMAccountItem struct {
+	Category string
+	Name     string
+	Arn      string
+	Details  string
+	Finding  string
+}
+
+func (m *IAMAccountModule) PrintIAMAccount(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "iam-account"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Reviewing the password policy, permission boundaries, service-linked roles and OIDC providers of account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+	m.getPasswordPolicy()
+	m.getPermissionBoundariesAndServiceLinkedRoles()
+	m.getOIDCProviders()
+
+	m.output.Headers = []string{
+		"Account",
+		"Category",
+		"Name",
+		"Arn",
+		"Details",
+		"Finding",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+		// If the user specified wide as the output format, use these columns.
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Category",
+			"Name",
+			"Arn",
+			"Details",
+			"Finding",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Category",
+			"Name",
+			"Details",
+			"Finding",
+		}
+	}
+
+	// Table rows
+	for _, item := range m.Items {
+		finding := item.Finding
+		if finding != "" {
+			finding = magenta(finding)
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				item.Category,
+				item.Name,
+				item.Arn,
+				item.Details,
+				finding,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s account wide IAM settings found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+	} else {
+		fmt.Printf("[%s][%s] No account wide IAM settings found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+// Counts is the number of items per category. An account without a password policy counts 0.
+func (m *IAMAccountModule) Counts() map[string]int {
+	counts := map[string]int{
+		IAMCategoryPasswordPolicy:     0,
+		IAMCategoryPermissionBoundary: 0,
+		IAMCategoryServiceLinkedRole:  0,
+		IAMCategoryOIDCProvider:       0,
+	}
+	for _, item := range m.Items {
+		if item.Category == IAMCategoryPasswordPolicy && item.Finding == iamNoPasswordPolicy {
+			continue
+		}
+		counts[item.Category]++
+	}
+	return counts
+}
+
+func (m *IAMAccountModule) getPasswordPolicy() {
+	policy, err := sdk.CachedIamGetAccountPasswordPolicy(m.IAMAccountClient, aws.ToString(m.Caller.Account))
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchEntity" {
+			m.Items = append(m.Items, IAMAccountItem{
+				Category: IAMCategoryPasswordPolicy,
+				Name:     "account",
+				Details:  "none, the AWS default applies",
+				Finding:  iamNoPasswordPolicy,
+			})
+			return
+		}
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	minLength := int(aws.ToInt32(policy.MinimumPasswordLength))
+	details := []string{fmt.Sprintf("min length %d", minLength)}
+	var weaknesses []string
+	if minLength < iamRecommendedMinPasswordLength {
+		weaknesses = append(weaknesses, fmt.Sprintf("shorter than %d characters", iamRecommendedMinPasswordLength))
+	}
+	for _, requirement := range []struct {
+		name     string
+		required bool
+	}{
+		{"uppercase", policy.RequireUppercaseCharacters},
+		{"lowercase", policy.RequireLowercaseCharacters},
+		{"numbers", policy.RequireNumbers},
+		{"symbols", policy.RequireSymbols},
+	} {
+		if requirement.required {
+			details = append(details, requirement.name)
+		} else {
+			weaknesses = append(weaknesses, "no "+requirement.name)
+		}
+	}
+	if policy.ExpirePasswords {
+		details = append(details, fmt.Sprintf("max age %d days", aws.ToInt32(policy.MaxPasswordAge)))
+	}
+	if reuse := aws.ToInt32(policy.PasswordReusePrevention); reuse > 0 {
+		details = append(details, fmt.Sprintf("remembers %d passwords", reuse))
+	}
+
+	item := IAMAccountItem{
+		Category: IAMCategoryPasswordPolicy,
+		Name:     "account",
+		Details:  strings.Join(details, ", "),
+	}
+	if len(weaknesses) > 0 {
+		item.Finding = "Weak password policy: " + strings.Join(weaknesses, ", ")
+	}
+	m.Items = append(m.Items, item)
+}
+
+// getPermissionBoundariesAndServiceLinkedRoles reads both from the authorization details, which, unlike ListUsers and
+// ListRoles, include the permission boundaries
+func (m *IAMAccountModule) getPermissionBoundariesAndServiceLinkedRoles() {
+	authorizationDetails, err := sdk.CachedIAMGetAccountAuthorizationDetails(m.IAMClient, aws.ToString(m.Caller.Account))
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	var boundaries, serviceLinkedRoles []IAMAccountItem
+	for _, user := range authorizationDetails.UserDetailList {
+		if user.PermissionsBoundary == nil {
+			continue
+		}
+		boundaries = append(boundaries, IAMAccountItem{
+			Category: IAMCategoryPermissionBoundary,
+			Name:     aws.ToString(user.UserName),
+			Arn:      aws.ToString(user.Arn),
+			Details:  aws.ToString(user.PermissionsBoundary.PermissionsBoundaryArn),
+		})
+	}
+	for _, role := range authorizationDetails.RoleDetailList {
+		if role.PermissionsBoundary != nil {
+			boundaries = append(boundaries, IAMAccountItem{
+				Category: IAMCategoryPermissionBoundary,
+				Name:     aws.ToString(role.RoleName),
+				Arn:      aws.ToString(role.Arn),
+				Details:  aws.ToString(role.PermissionsBoundary.PermissionsBoundaryArn),
+			})
+		}
+		// Service-linked roles always live under /aws-service-role/<service principal>/
+		if service, ok := strings.CutPrefix(aws.ToString(role.Path), "/aws-service-role/"); ok {
+			serviceLinkedRoles = append(serviceLinkedRoles, IAMAccountItem{
+				Category: IAMCategoryServiceLinkedRole,
+				Name:     aws.ToString(role.RoleName),
+				Arn:      aws.ToString(role.Arn),
+				Details:  strings.TrimSuffix(service, "/"),
+			})
+		}
+	}
+	for _, items := range [][]IAMAccountItem{boundaries, serviceLinkedRoles} {
+		sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+		m.Items = append(m.Items, items...)
+	}
+}
+
+func (m *IAMAccountModule) getOIDCProviders() {
+	providers, err := sdk.CachedIamListOpenIDConnectProviders(m.IAMAccountClient, aws.ToString(m.Caller.Account))
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	var items []IAMAccountItem
+	for _, entry := range providers {
+		provider, err := sdk.CachedIamGetOpenIDConnectProvider(m.IAMAccountClient, aws.ToString(m.Caller.Account), aws.ToString(entry.Arn))
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		items = append(items, IAMAccountItem{
+			Category: IAMCategoryOIDCProvider,
+			Name:     provider.Url,
+			Arn:      provider.Arn,
+			Details:  "audiences: " + strings.Join(provider.ClientIDs, ", "),
+		})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	m.Items = append(m.Items, items...)
+}
@@ -0,0 +1,96 @@
+package aws
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestIAMAccount(t *testing.T) {
+	m := IAMAccountModule{
+		AWSProfile: "unittesting",
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:       3,
+		IAMClient:        &sdk.MockedIAMClient{},
+		IAMAccountClient: &sdk.MockedIAMAccountClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintIAMAccount(".", 2)
+
+	expected := []IAMAccountItem{
+		{
+			Category: IAMCategoryPasswordPolicy,
+			Name:     "account",
+			Details:  "min length 8, uppercase, lowercase, numbers, max age 90 days",
+			Finding:  "Weak password policy: shorter than 14 characters, no symbols",
+		},
+		{
+			Category: IAMCategoryPermissionBoundary,
+			Name:     "role3",
+			Arn:      "arn:aws:iam::123456789012:role/role3",
+			Details:  "arn:aws:iam::123456789012:policy/DeveloperBoundary",
+		},
+		{
+			Category: IAMCategoryPermissionBoundary,
+			Name:     "user2",
+			Arn:      "arn:aws:iam::123456789012:user/user2",
+			Details:  "arn:aws:iam::123456789012:policy/DeveloperBoundary",
+		},
+		{
+			Category: IAMCategoryServiceLinkedRole,
+			Name:     "AWSServiceRoleForSupport",
+			Arn:      "arn:aws:iam::123456789012:role/aws-service-role/support.amazonaws.com/AWSServiceRoleForSupport",
+			Details:  "support.amazonaws.com",
+		},
+		{
+			Category: IAMCategoryOIDCProvider,
+			Name:     "token.actions.githubusercontent.com",
+			Arn:      "arn:aws:iam::123456789012:oidc-provider/token.actions.githubusercontent.com",
+			Details:  "audiences: sts.amazonaws.com",
+		},
+	}
+	if !reflect.DeepEqual(m.Items, expected) {
+		t.Errorf("Expected items %v, got %v", expected, m.Items)
+	}
+
+	expectedCounts := map[string]int{
+		IAMCategoryPasswordPolicy:     1,
+		IAMCategoryPermissionBoundary: 2,
+		IAMCategoryServiceLinkedRole:  1,
+		IAMCategoryOIDCProvider:       1,
+	}
+	if counts := m.Counts(); !reflect.DeepEqual(counts, expectedCounts) {
+		t.Errorf("Expected counts %v, got %v", expectedCounts, counts)
+	}
+
+	summary := IAMSummaryModule{
+		AWSProfile: "unittesting",
+		Caller:     m.Caller,
+		Counts:     map[string]int{IAMCategoryUsers: 2, IAMCategoryPermissionBoundary: 2},
+	}
+	summary.PrintIAMSummary(".", 2)
+
+	summaryFilePath := filepath.Join(".", "cloudfox-output/aws/unittesting-123456789012/table/iam-summary.txt")
+	summaryFile, err := afero.ReadFile(fs, summaryFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read output file at %s: %s", summaryFilePath, err)
+	}
+	for _, category := range IAMSummaryCategories {
+		if !strings.Contains(string(summaryFile), category) {
+			t.Errorf("Expected category %s in the summary", category)
+		}
+	}
+}
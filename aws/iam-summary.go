@@ -0,0 +1,85 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/sirupsen/logrus"
+)
+
+// Categories of the IAM modules that the iam command counts, on top of the ones of IAMAccountModule
+const (
+	IAMCategoryUsers      = "Users"
+	IAMCategoryRoles      = "Roles"
+	IAMCategoryGroups     = "Groups"
+	IAMCategoryPolicies   = "Policies"
+	IAMCategoryAccessKeys = "Access keys"
+)
+
+// IAMSummaryCategories is the order of the rows of iam-summary
+var IAMSummaryCategories = []string{
+	IAMCategoryUsers,
+	IAMCategoryRoles,
+	IAMCategoryGroups,
+	IAMCategoryPolicies,
+	IAMCategoryPasswordPolicy,
+	IAMCategoryAccessKeys,
+	IAMCategoryPermissionBoundary,
+	IAMCategoryServiceLinkedRole,
+	IAMCategoryOIDCProvider,
+}
+
+// IAMSummaryModule writes the counts the IAM modules of the iam command reported, one row per category
+type IAMSummaryModule struct {
+	Caller     sts.GetCallerIdentityOutput
+	AWSProfile string
+	WrapTable  bool
+	Counts     map[string]int
+
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+func (m *IAMSummaryModule) PrintIAMSummary(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "iam-summary"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	m.output.Headers = []string{
+		"Category",
+		"Count",
+	}
+	for _, category := range IAMSummaryCategories {
+		m.output.Body = append(m.output.Body, []string{category, strconv.Itoa(m.Counts[category])})
+	}
+
+	m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+	o := internal.OutputClient{
+		Verbosity:     verbosity,
+		CallingModule: m.output.CallingModule,
+		Table: internal.TableClient{
+			Wrap: m.WrapTable,
+		},
+	}
+	o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+		Header: m.output.Headers,
+		Body:   m.output.Body,
+		Name:   m.output.CallingModule,
+	})
+	o.PrefixIdentifier = m.AWSProfile
+	o.Table.DirectoryName = m.output.FilePath
+	o.AccountID = aws.ToString(m.Caller.Account)
+	o.BaseDirectory = outputDirectory
+	o.WriteFullOutput(o.Table.TableFiles, nil)
+}
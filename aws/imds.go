@@ -0,0 +1,423 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type ImdsModule struct {
+	// General configuration data
+	EC2Client           sdk.AWSEC2ClientInterface
+	IAMClient           sdk.AWSIAMClientInterface
+	Caller              sts.GetCallerIdentityOutput
+	AWSRegions          []string
+	AWSOutputType       string
+	AWSTableCols        string
+	PmapperDataBasePath string
+
+	Goroutines     int
+	AWSProfile     string
+	WrapTable      bool
+	SkipAdminCheck bool
+	pmapperMod     PmapperModule
+	pmapperError   error
+	iamSimClient   IamSimulatorModule
+
+	// Main module data
+	ImdsInstances  []ImdsInstance
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type ImdsInstance struct {
+	Region          string
+	ID              string
+	Name            string
+	State           string
+	PrivateIP       string
+	PublicIP        string
+	MetadataEnabled bool
+	IMDSv1Allowed   bool
+	HopLimit        int32
+	InstanceProfile string
+	Role            string
+	Admin           string
+	CanPrivEsc      string
+}
+
+func (m *ImdsModule) PrintImds(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "imds"
+	localAdminMap := make(map[string]bool)
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating instance metadata service settings for EC2 instances in all regions for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	// Initialized the tools we'll need to check if any instance roles are admin or can privesc to admin
+	m.pmapperMod, m.pmapperError = InitPmapperGraph(m.Caller, m.AWSProfile, m.Goroutines, m.PmapperDataBasePath)
+	m.iamSimClient = InitIamCommandClient(m.IAMClient, m.Caller, m.AWSProfile, m.Goroutines)
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan ImdsInstance)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	// Perform role analysis
+	for i := range m.ImdsInstances {
+		if m.ImdsInstances[i].Role == "" {
+			continue
+		}
+		if m.pmapperError == nil {
+			m.ImdsInstances[i].Admin, m.ImdsInstances[i].CanPrivEsc = GetPmapperResults(m.SkipAdminCheck, m.pmapperMod, &m.ImdsInstances[i].Role)
+		} else {
+			m.ImdsInstances[i].Admin, m.ImdsInstances[i].CanPrivEsc = GetIamSimResult(m.SkipAdminCheck, &m.ImdsInstances[i].Role, m.iamSimClient, localAdminMap)
+		}
+	}
+
+	// Instances that answer IMDSv1 requests with a privileged role attached go first, those are the ones an SSRF turns
+	// into admin credentials
+	sort.SliceStable(m.ImdsInstances, func(i, j int) bool {
+		if m.ImdsInstances[i].priority() != m.ImdsInstances[j].priority() {
+			return m.ImdsInstances[i].priority() < m.ImdsInstances[j].priority()
+		}
+		if m.ImdsInstances[i].Region != m.ImdsInstances[j].Region {
+			return m.ImdsInstances[i].Region < m.ImdsInstances[j].Region
+		}
+		return m.ImdsInstances[i].ID < m.ImdsInstances[j].ID
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Name",
+		"ID",
+		"State",
+		"Internal IP",
+		"External IP",
+		"Metadata Endpoint",
+		"IMDSv1",
+		"Hop Limit",
+		"Role",
+		"IsAdminRole?",
+		"CanPrivEscToAdmin?",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Name",
+			"ID",
+			"State",
+			"Internal IP",
+			"External IP",
+			"Metadata Endpoint",
+			"IMDSv1",
+			"Hop Limit",
+			"Role",
+			"IsAdminRole?",
+			"CanPrivEscToAdmin?",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Name",
+			"ID",
+			"State",
+			"Metadata Endpoint",
+			"IMDSv1",
+			"Hop Limit",
+			"Role",
+			"IsAdminRole?",
+			"CanPrivEscToAdmin?",
+		}
+	}
+
+	// Remove the pmapper row if there is no pmapper data
+	if m.pmapperError != nil {
+		sharedLogger.Errorf("%s - %s - No pmapper data found for this account. Skipping the pmapper column in the output table.", m.output.CallingModule, m.AWSProfile)
+		tableCols = removeStringFromSlice(tableCols, "CanPrivEscToAdmin?")
+	}
+
+	// Table rows
+	for _, instance := range m.ImdsInstances {
+		metadataEndpoint := "Disabled"
+		if instance.MetadataEnabled {
+			metadataEndpoint = "Enabled"
+		}
+		imdsv1 := "No"
+		if instance.IMDSv1Allowed {
+			imdsv1 = magenta("Allowed")
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				instance.Region,
+				instance.Name,
+				instance.ID,
+				instance.State,
+				instance.PrivateIP,
+				instance.PublicIP,
+				metadataEndpoint,
+				imdsv1,
+				strconv.Itoa(int(instance.HopLimit)),
+				instance.Role,
+				instance.Admin,
+				instance.CanPrivEsc,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     "imds-commands",
+			Contents: m.writeLoot(),
+		})
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %s instances found, %d still allow IMDSv1.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)), m.countIMDSv1Allowed())
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No instances found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *ImdsModule) Receiver(receiver chan ImdsInstance, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.ImdsInstances = append(m.ImdsInstances, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *ImdsModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan ImdsInstance) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("ec2", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getInstancesPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *ImdsModule) getInstancesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan ImdsInstance) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	instances, err := sdk.CachedEC2DescribeInstances(m.EC2Client, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, instance := range instances {
+		imdsInstance := analyzeInstanceMetadataOptions(instance)
+		imdsInstance.Region = r
+		if instance.IamInstanceProfile != nil {
+			profileArn := aws.ToString(instance.IamInstanceProfile.Arn)
+			imdsInstance.InstanceProfile = profileArn[strings.LastIndex(profileArn, "/")+1:]
+			instanceProfile, err := sdk.CachedIamGetInstanceProfile(m.IAMClient, aws.ToString(m.Caller.Account), imdsInstance.InstanceProfile)
+			if err != nil {
+				m.modLog.Error(err.Error())
+				m.CommandCounter.Error++
+			}
+			// An instance profile can only hold a single role
+			for _, role := range instanceProfile.Roles {
+				imdsInstance.Role = aws.ToString(role.Arn)
+			}
+		}
+		dataReceiver <- imdsInstance
+	}
+}
+
+// analyzeInstanceMetadataOptions reads the metadata service settings of an instance. IMDSv1 is only reachable when the
+// endpoint is enabled and session tokens are optional. Instances that don't report any options get the EC2 defaults
+// from before IMDSv2 existed, which is what an instance launched from an old launch configuration ends up with.
+func analyzeInstanceMetadataOptions(instance ec2Types.Instance) ImdsInstance {
+	imdsInstance := ImdsInstance{
+		ID:              aws.ToString(instance.InstanceId),
+		PrivateIP:       aws.ToString(instance.PrivateIpAddress),
+		PublicIP:        aws.ToString(instance.PublicIpAddress),
+		MetadataEnabled: true,
+		IMDSv1Allowed:   true,
+		HopLimit:        1,
+	}
+	for _, tag := range instance.Tags {
+		if aws.ToString(tag.Key) == "Name" {
+			imdsInstance.Name = aws.ToString(tag.Value)
+		}
+	}
+	if instance.State != nil {
+		imdsInstance.State = string(instance.State.Name)
+	}
+	if instance.MetadataOptions != nil {
+		imdsInstance.MetadataEnabled = instance.MetadataOptions.HttpEndpoint != ec2Types.InstanceMetadataEndpointStateDisabled
+		imdsInstance.IMDSv1Allowed = imdsInstance.MetadataEnabled && instance.MetadataOptions.HttpTokens != ec2Types.HttpTokensStateRequired
+		if instance.MetadataOptions.HttpPutResponseHopLimit != nil {
+			imdsInstance.HopLimit = aws.ToInt32(instance.MetadataOptions.HttpPutResponseHopLimit)
+		}
+	}
+	return imdsInstance
+}
+
+func (i ImdsInstance) privileged() bool {
+	return i.Admin == "YES" || i.CanPrivEsc == "YES"
+}
+
+// priority ranks instances by how useful their metadata service is to someone with an SSRF, lowest first
+func (i ImdsInstance) priority() int {
+	switch {
+	case i.IMDSv1Allowed && i.Role != "" && i.privileged():
+		return 0
+	case i.IMDSv1Allowed && i.Role != "":
+		return 1
+	case i.IMDSv1Allowed:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func (m *ImdsModule) countIMDSv1Allowed() int {
+	var count int
+	for _, instance := range m.ImdsInstances {
+		if instance.IMDSv1Allowed {
+			count++
+		}
+	}
+	return count
+}
+
+func (m *ImdsModule) writeLoot() string {
+	var out string
+	out = out + fmt.Sprintln("#############################################")
+	out = out + fmt.Sprintln("# Run these from the instance itself, or through an SSRF in something it hosts.")
+	out = out + fmt.Sprintln("# IMDSv1 only needs a GET, so a plain SSRF is enough. IMDSv2 needs a PUT with a custom header first,")
+	out = out + fmt.Sprintln("# and with a hop limit of 1 the token doesn't make it out of a container running on the instance.")
+	out = out + fmt.Sprintln("#############################################")
+	out = out + fmt.Sprintln("")
+
+	for _, instance := range m.ImdsInstances {
+		if instance.Role == "" || !instance.MetadataEnabled {
+			continue
+		}
+		roleName := instance.Role[strings.LastIndex(instance.Role, "/")+1:]
+		var notes []string
+		if instance.IMDSv1Allowed {
+			notes = append(notes, "IMDSv1 allowed")
+		} else {
+			notes = append(notes, "IMDSv2 required")
+		}
+		notes = append(notes, fmt.Sprintf("hop limit %d", instance.HopLimit))
+		if instance.privileged() {
+			notes = append(notes, "privileged role")
+		}
+		out = out + fmt.Sprintf("# %s %s (%s) - %s - %s\n", instance.Region, instance.ID, instance.Name, instance.Role, strings.Join(notes, ", "))
+		if instance.IMDSv1Allowed {
+			out = out + fmt.Sprintf("curl http://169.254.169.254/latest/meta-data/iam/security-credentials/%s\n", roleName)
+		}
+		out = out + fmt.Sprintf("TOKEN=$(curl -s -X PUT http://169.254.169.254/latest/api/token -H \"X-aws-ec2-metadata-token-ttl-seconds: 21600\") && curl -H \"X-aws-ec2-metadata-token: $TOKEN\" http://169.254.169.254/latest/meta-data/iam/security-credentials/%s\n", roleName)
+		out = out + fmt.Sprintln("")
+	}
+
+	return out
+}
@@ -0,0 +1,168 @@
+package aws
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+type mockedImdsEC2Client struct {
+	sdk.MockedEC2Client2
+}
+
+func testImdsInstance(id string, profile string, metadataOptions *ec2Types.InstanceMetadataOptionsResponse) ec2Types.Instance {
+	instance := ec2Types.Instance{
+		InstanceId:       aws.String(id),
+		PrivateIpAddress: aws.String("10.0.0.10"),
+		State:            &ec2Types.InstanceState{Name: ec2Types.InstanceStateNameRunning},
+		Tags:             []ec2Types.Tag{{Key: aws.String("Name"), Value: aws.String(strings.TrimPrefix(id, "i-"))}},
+		MetadataOptions:  metadataOptions,
+	}
+	if profile != "" {
+		instance.IamInstanceProfile = &ec2Types.IamInstanceProfile{Arn: aws.String("arn:aws:iam::333333333333:instance-profile/" + profile)}
+	}
+	return instance
+}
+
+func (c *mockedImdsEC2Client) DescribeInstances(ctx context.Context, input *ec2.DescribeInstancesInput, f ...func(o *ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	return &ec2.DescribeInstancesOutput{Reservations: []ec2Types.Reservation{{Instances: []ec2Types.Instance{
+		testImdsInstance("i-app", "app-profile", &ec2Types.InstanceMetadataOptionsResponse{
+			HttpEndpoint:            ec2Types.InstanceMetadataEndpointStateEnabled,
+			HttpTokens:              ec2Types.HttpTokensStateRequired,
+			HttpPutResponseHopLimit: aws.Int32(2),
+		}),
+		testImdsInstance("i-batch", "batch-profile", &ec2Types.InstanceMetadataOptionsResponse{
+			HttpEndpoint:            ec2Types.InstanceMetadataEndpointStateEnabled,
+			HttpTokens:              ec2Types.HttpTokensStateOptional,
+			HttpPutResponseHopLimit: aws.Int32(1),
+		}),
+		testImdsInstance("i-legacy", "", nil),
+		testImdsInstance("i-locked", "", &ec2Types.InstanceMetadataOptionsResponse{
+			HttpEndpoint:            ec2Types.InstanceMetadataEndpointStateDisabled,
+			HttpTokens:              ec2Types.HttpTokensStateOptional,
+			HttpPutResponseHopLimit: aws.Int32(1),
+		}),
+		testImdsInstance("i-web", "web-profile", &ec2Types.InstanceMetadataOptionsResponse{
+			HttpEndpoint:            ec2Types.InstanceMetadataEndpointStateEnabled,
+			HttpTokens:              ec2Types.HttpTokensStateOptional,
+			HttpPutResponseHopLimit: aws.Int32(1),
+		}),
+	}}}}, nil
+}
+
+// mockedImdsIAMClient maps every instance profile to a role of the same name, and only web-role passes the admin check
+type mockedImdsIAMClient struct {
+	sdk.MockedIAMClient
+}
+
+func (c *mockedImdsIAMClient) GetInstanceProfile(ctx context.Context, params *iam.GetInstanceProfileInput, optFns ...func(*iam.Options)) (*iam.GetInstanceProfileOutput, error) {
+	roleName := strings.TrimSuffix(aws.ToString(params.InstanceProfileName), "-profile") + "-role"
+	return &iam.GetInstanceProfileOutput{InstanceProfile: &iamTypes.InstanceProfile{
+		InstanceProfileName: params.InstanceProfileName,
+		Roles: []iamTypes.Role{
+			{Arn: aws.String("arn:aws:iam::333333333333:role/" + roleName), RoleName: aws.String(roleName)},
+		},
+	}}, nil
+}
+
+func (c *mockedImdsIAMClient) SimulatePrincipalPolicy(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error) {
+	decision := iamTypes.PolicyEvaluationDecisionTypeImplicitDeny
+	if aws.ToString(params.PolicySourceArn) == "arn:aws:iam::333333333333:role/web-role" {
+		decision = iamTypes.PolicyEvaluationDecisionTypeAllowed
+	}
+	var results []iamTypes.EvaluationResult
+	for _, action := range params.ActionNames {
+		results = append(results, iamTypes.EvaluationResult{EvalActionName: aws.String(action), EvalDecision: decision})
+	}
+	return &iam.SimulatePrincipalPolicyOutput{EvaluationResults: results}, nil
+}
+
+func TestImds(t *testing.T) {
+	m := ImdsModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::333333333333:user/Alice"),
+			Account: aws.String("333333333333"),
+		},
+		Goroutines:          3,
+		PmapperDataBasePath: "/nonexistent",
+		EC2Client:           &mockedImdsEC2Client{},
+		IAMClient:           &mockedImdsIAMClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+	tmpDir := "."
+
+	m.PrintImds(tmpDir, 2)
+
+	expected := []struct {
+		id            string
+		imdsv1Allowed bool
+		hopLimit      int32
+		role          string
+		admin         string
+	}{
+		{"i-web", true, 1, "arn:aws:iam::333333333333:role/web-role", "YES"},
+		{"i-batch", true, 1, "arn:aws:iam::333333333333:role/batch-role", "No"},
+		{"i-legacy", true, 1, "", ""},
+		{"i-app", false, 2, "arn:aws:iam::333333333333:role/app-role", "No"},
+		{"i-locked", false, 1, "", ""},
+	}
+	if len(m.ImdsInstances) != len(expected) {
+		t.Fatalf("Expected %d instances, got %d", len(expected), len(m.ImdsInstances))
+	}
+	for i, want := range expected {
+		got := m.ImdsInstances[i]
+		if got.ID != want.id {
+			t.Errorf("Expected %s at position %d, got %s", want.id, i, got.ID)
+			continue
+		}
+		if got.IMDSv1Allowed != want.imdsv1Allowed {
+			t.Errorf("Expected IMDSv1 allowed to be %t for %s, got %t", want.imdsv1Allowed, got.ID, got.IMDSv1Allowed)
+		}
+		if got.HopLimit != want.hopLimit {
+			t.Errorf("Expected hop limit %d for %s, got %d", want.hopLimit, got.ID, got.HopLimit)
+		}
+		if got.Role != want.role {
+			t.Errorf("Expected role %q for %s, got %q", want.role, got.ID, got.Role)
+		}
+		if got.Admin != want.admin {
+			t.Errorf("Expected admin %q for %s, got %q", want.admin, got.ID, got.Admin)
+		}
+	}
+
+	lootFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-333333333333/loot/imds-commands.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	expectedCommands := []string{
+		"curl http://169.254.169.254/latest/meta-data/iam/security-credentials/web-role",
+		"curl http://169.254.169.254/latest/meta-data/iam/security-credentials/batch-role",
+		"curl -H \"X-aws-ec2-metadata-token: $TOKEN\" http://169.254.169.254/latest/meta-data/iam/security-credentials/app-role",
+	}
+	for _, expectedCommand := range expectedCommands {
+		if !strings.Contains(string(lootFile), expectedCommand) {
+			t.Errorf("Expected %s to be in the loot file", expectedCommand)
+		}
+	}
+	if strings.Contains(string(lootFile), "curl http://169.254.169.254/latest/meta-data/iam/security-credentials/app-role") {
+		t.Errorf("Expected no IMDSv1 command for an instance that requires IMDSv2")
+	}
+	if strings.Index(string(lootFile), "web-role") > strings.Index(string(lootFile), "batch-role") {
+		t.Errorf("Expected the instance with the admin role first in the loot file")
+	}
+}
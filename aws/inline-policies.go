@@ -0,0 +1,320 @@
+package aws
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/aws/policy"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/sirupsen/logrus"
+)
+
+type InlinePolicyModule struct {
+	// General configuration data
+	IAMClient      sdk.AWSIAMClientInterface
+	Caller         sts.GetCallerIdentityOutput
+	AWSProfile     string
+	Goroutines     int
+	WrapTable      bool
+	AWSOutputType  string
+	AWSTableCols   string
+	CommandCounter internal.CommandCounter
+
+	// Main module data
+	InlinePolicies []InlinePolicy
+
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type InlinePolicy struct {
+	PrincipalType string
+	PrincipalName string
+	PrincipalArn  string
+	PolicyName    string
+	// Document is the URL decoded policy document
+	Document string
+	Finding  string
+	Risk     string
+}
+
+func (m *InlinePolicyModule) PrintInlinePolicies(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "inline-policies"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Reviewing inline policies of users, roles and groups for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+	m.getUserInlinePolicies()
+	m.getRoleInlinePolicies()
+	m.getGroupInlinePolicies()
+
+	sort.Slice(m.InlinePolicies, func(i, j int) bool {
+		if m.InlinePolicies[i].Risk != m.InlinePolicies[j].Risk {
+			return m.InlinePolicies[i].Risk > m.InlinePolicies[j].Risk
+		}
+		if m.InlinePolicies[i].PrincipalArn != m.InlinePolicies[j].PrincipalArn {
+			return m.InlinePolicies[i].PrincipalArn < m.InlinePolicies[j].PrincipalArn
+		}
+		return m.InlinePolicies[i].PolicyName < m.InlinePolicies[j].PolicyName
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Type",
+		"Principal",
+		"Arn",
+		"Policy Name",
+		"Finding",
+		"Risk",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+		// If the user specified wide as the output format, use these columns.
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Type",
+			"Principal",
+			"Arn",
+			"Policy Name",
+			"Finding",
+			"Risk",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Type",
+			"Principal",
+			"Policy Name",
+			"Finding",
+			"Risk",
+		}
+	}
+
+	// Table rows
+	var highRisk int
+	for _, inlinePolicy := range m.InlinePolicies {
+		risk := inlinePolicy.Risk
+		if risk != "" {
+			highRisk++
+			risk = magenta(risk)
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				inlinePolicy.PrincipalType,
+				inlinePolicy.PrincipalName,
+				inlinePolicy.PrincipalArn,
+				inlinePolicy.PolicyName,
+				inlinePolicy.Finding,
+				risk,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		if highRisk > 0 {
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     "inline-policies-high-risk",
+				Contents: m.writeLoot(),
+			})
+		}
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %s inline policies found, %d of them HIGH risk.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)), highRisk)
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No inline policies found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *InlinePolicyModule) getUserInlinePolicies() {
+	users, err := sdk.CachedIamListUsers(m.IAMClient, aws.ToString(m.Caller.Account))
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	for _, user := range users {
+		userName := aws.ToString(user.UserName)
+		policyNames, err := sdk.CachedIamListUserPolicies(m.IAMClient, aws.ToString(m.Caller.Account), userName)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		for _, policyName := range policyNames {
+			document, err := sdk.CachedIamGetUserPolicy(m.IAMClient, aws.ToString(m.Caller.Account), userName, policyName)
+			if err != nil {
+				m.modLog.Error(err.Error())
+				m.CommandCounter.Error++
+				continue
+			}
+			m.addInlinePolicy("User", userName, aws.ToString(user.Arn), policyName, document)
+		}
+	}
+}
+
+func (m *InlinePolicyModule) getRoleInlinePolicies() {
+	roles, err := sdk.CachedIamListRoles(m.IAMClient, aws.ToString(m.Caller.Account))
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	for _, role := range roles {
+		roleName := aws.ToString(role.RoleName)
+		policyNames, err := sdk.CachedIamListRolePolicies(m.IAMClient, aws.ToString(m.Caller.Account), roleName)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		for _, policyName := range policyNames {
+			document, err := sdk.CachedIamGetRolePolicy(m.IAMClient, aws.ToString(m.Caller.Account), roleName, policyName)
+			if err != nil {
+				m.modLog.Error(err.Error())
+				m.CommandCounter.Error++
+				continue
+			}
+			m.addInlinePolicy("Role", roleName, aws.ToString(role.Arn), policyName, document)
+		}
+	}
+}
+
+func (m *InlinePolicyModule) getGroupInlinePolicies() {
+	groups, err := sdk.CachedIamListGroups(m.IAMClient, aws.ToString(m.Caller.Account))
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	for _, group := range groups {
+		groupName := aws.ToString(group.GroupName)
+		policyNames, err := sdk.CachedIamListGroupPolicies(m.IAMClient, aws.ToString(m.Caller.Account), groupName)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		for _, policyName := range policyNames {
+			document, err := sdk.CachedIamGetGroupPolicy(m.IAMClient, aws.ToString(m.Caller.Account), groupName, policyName)
+			if err != nil {
+				m.modLog.Error(err.Error())
+				m.CommandCounter.Error++
+				continue
+			}
+			m.addInlinePolicy("Group", groupName, aws.ToString(group.Arn), policyName, document)
+		}
+	}
+}
+
+func (m *InlinePolicyModule) addInlinePolicy(principalType, principalName, principalArn, policyName, document string) {
+	// Documents returned by IAM are URL encoded
+	decoded, err := url.QueryUnescape(document)
+	if err != nil {
+		decoded = document
+	}
+	inlinePolicy := InlinePolicy{
+		PrincipalType: principalType,
+		PrincipalName: principalName,
+		PrincipalArn:  principalArn,
+		PolicyName:    policyName,
+		Document:      decoded,
+	}
+	inlinePolicy.Finding = inlinePolicyFinding(decoded)
+	if inlinePolicy.Finding != "" {
+		inlinePolicy.Risk = "HIGH"
+	}
+	m.InlinePolicies = append(m.InlinePolicies, inlinePolicy)
+}
+
+// inlinePolicyFinding describes what makes a policy dangerous: allowing every action, or every IAM action, which is
+// enough to grant yourself everything else
+func inlinePolicyFinding(document string) string {
+	parsedPolicy, err := policy.ParseJSONPolicy([]byte(document))
+	if err != nil {
+		return ""
+	}
+	var finding string
+	for _, statement := range parsedPolicy.Statement {
+		if !statement.IsAllow() {
+			continue
+		}
+		for _, action := range statement.Action {
+			switch strings.ToLower(action) {
+			case "*", "*:*":
+				return "Allows all actions"
+			case "iam:*":
+				finding = "Allows iam:*"
+			}
+		}
+	}
+	return finding
+}
+
+func (m *InlinePolicyModule) writeLoot() string {
+	var out strings.Builder
+	for _, inlinePolicy := range m.InlinePolicies {
+		if inlinePolicy.Risk == "" {
+			continue
+		}
+		out.WriteString("#############################################\n")
+		out.WriteString(fmt.Sprintf("# %s %s, inline policy %s: %s\n", inlinePolicy.PrincipalType, inlinePolicy.PrincipalArn, inlinePolicy.PolicyName, inlinePolicy.Finding))
+		out.WriteString(fmt.Sprintf("# aws --profile $profile iam get-%s-policy --%s-name %s --policy-name %s\n", strings.ToLower(inlinePolicy.PrincipalType), strings.ToLower(inlinePolicy.PrincipalType), inlinePolicy.PrincipalName, inlinePolicy.PolicyName))
+		out.WriteString("#############################################\n")
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, []byte(inlinePolicy.Document), "", "  "); err != nil {
+			out.WriteString(inlinePolicy.Document)
+		} else {
+			out.Write(indented.Bytes())
+		}
+		out.WriteString("\n\n")
+	}
+	return out.String()
+}
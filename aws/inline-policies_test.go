@@ -0,0 +1,78 @@
+package aws
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestInlinePolicies(t *testing.T) {
+	m := InlinePolicyModule{
+		AWSProfile: "unittesting",
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::999999999999:user/Alice"),
+			Account: aws.String("999999999999"),
+		},
+		Goroutines: 3,
+		IAMClient:  &sdk.MockedIAMClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintInlinePolicies(".", 2)
+
+	expectedFindings := map[string]string{
+		"arn:aws:iam::123456789012:user/user1/break-glass":    "Allows all actions",
+		"arn:aws:iam::123456789012:role/role1/read-artifacts": "",
+		"arn:aws:iam::123456789012:role/role3/manage-iam":     "Allows iam:*",
+		// Denying iam:* is not a finding
+		"arn:aws:iam::123456789012:role/role3/deny-iam":     "",
+		"arn:aws:iam::123456789012:group/group2/manage-iam": "Allows iam:*",
+	}
+	if len(m.InlinePolicies) != len(expectedFindings) {
+		t.Fatalf("Expected %d inline policies, got %d", len(expectedFindings), len(m.InlinePolicies))
+	}
+	for _, inlinePolicy := range m.InlinePolicies {
+		key := inlinePolicy.PrincipalArn + "/" + inlinePolicy.PolicyName
+		expected, ok := expectedFindings[key]
+		if !ok {
+			t.Errorf("Unexpected inline policy %s", key)
+			continue
+		}
+		if inlinePolicy.Finding != expected {
+			t.Errorf("Expected finding %q for %s, got %q", expected, key, inlinePolicy.Finding)
+		}
+		if (inlinePolicy.Risk == "HIGH") != (expected != "") {
+			t.Errorf("Unexpected risk %q for %s", inlinePolicy.Risk, key)
+		}
+	}
+	if m.InlinePolicies[0].Risk != "HIGH" {
+		t.Errorf("Expected HIGH risk policies to be sorted first")
+	}
+
+	lootFilePath := filepath.Join(".", "cloudfox-output/aws/unittesting-999999999999/loot/inline-policies-high-risk.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	expectedLoot := []string{
+		"aws --profile $profile iam get-user-policy --user-name user1 --policy-name break-glass",
+		"aws --profile $profile iam get-role-policy --role-name role3 --policy-name manage-iam",
+		"\"Action\": \"*\"",
+	}
+	for _, expected := range expectedLoot {
+		if !strings.Contains(string(lootFile), expected) {
+			t.Errorf("Expected %s to be in the loot file", expected)
+		}
+	}
+	if strings.Contains(string(lootFile), "read-artifacts") {
+		t.Errorf("Did not expect policies without findings in the loot file")
+	}
+}
@@ -96,6 +96,7 @@ type Inventory2Module struct {
 	CommandCounter       internal.CommandCounter
 	GlobalResourceCounts []GlobalResourceCount2
 	serviceMap           map[string]map[string]int
+	deniedMap            map[string]map[string]bool
 	services             []string
 	totalRegionCounts    map[string]int
 	mu                   sync.Mutex
@@ -185,6 +186,7 @@ func (m *Inventory2Module) PrintInventoryPerRegion(outputDirectory string, verbo
 	}
 
 	m.serviceMap = map[string]map[string]int{}
+	m.deniedMap = map[string]map[string]bool{}
 	m.totalRegionCounts = map[string]int{}
 
 	if m.AWSProfile == "" {
@@ -194,6 +196,7 @@ func (m *Inventory2Module) PrintInventoryPerRegion(outputDirectory string, verbo
 	//initialize servicemap and total
 	for _, service := range m.services {
 		m.serviceMap[service] = map[string]int{}
+		m.deniedMap[service] = map[string]bool{}
 
 		for _, region := range m.AWSRegions {
 			m.serviceMap[service][region] = 0
@@ -272,25 +275,25 @@ func (m *Inventory2Module) PrintInventoryPerRegion(outputDirectory string, verbo
 		}
 	}
 
-	//add the regions to the header row
+	// A region gets a column if it has at least one resource, or if we were denied access to something in it
+	var regionColumns []string
 	for _, region := range ss {
-
-		if region.Value != 0 {
-			m.output.Headers = append(m.output.Headers, region.Key)
-
+		if region.Value != 0 || m.regionHasDeniedService(region.Key) {
+			regionColumns = append(regionColumns, region.Key)
 		}
 	}
+
+	//add the regions to the header row
+	m.output.Headers = append(m.output.Headers, regionColumns...)
 	//move total up here.
 	var totalRow []string
 	var temprow []string
 	temprow = append(temprow, "Total")
-	for _, region := range ss {
-		if region.Value != 0 {
-			if m.serviceMap["total"][region.Key] > 0 {
-				temprow = append(temprow, strconv.Itoa(m.serviceMap["total"][region.Key]))
-			} else {
-				temprow = append(temprow, "-")
-			}
+	for _, region := range regionColumns {
+		if m.serviceMap["total"][region] > 0 {
+			temprow = append(temprow, strconv.Itoa(m.serviceMap["total"][region]))
+		} else {
+			temprow = append(temprow, "-")
 		}
 	}
 	for _, val := range temprow {
@@ -305,15 +308,15 @@ func (m *Inventory2Module) PrintInventoryPerRegion(outputDirectory string, verbo
 			var temprow []string
 
 			temprow = append(temprow, service)
-			for _, region := range ss {
-				if region.Value != 0 {
-					if m.serviceMap[service][region.Key] > 0 {
-						temprow = append(temprow, strconv.Itoa(m.serviceMap[service][region.Key]))
-					} else {
-						temprow = append(temprow, "-")
-					}
+			for _, region := range regionColumns {
+				// AccessDenied means we don't know how many resources there are, which is not the same as there being none
+				if m.deniedMap[service][region] {
+					temprow = append(temprow, "?")
+				} else if m.serviceMap[service][region] > 0 {
+					temprow = append(temprow, strconv.Itoa(m.serviceMap[service][region]))
+				} else {
+					temprow = append(temprow, "-")
 				}
-
 			}
 
 			// check to see if all regions have no resources for the service. Skip the first column, which is the resource type.
@@ -358,9 +361,18 @@ func (m *Inventory2Module) PrintInventoryPerRegion(outputDirectory string, verbo
 			Body:   m.output.Body,
 			Name:   m.output.CallingModule,
 		})
+		matrixHeader, matrixBody := m.buildRawMatrix()
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:            matrixHeader,
+			Body:              matrixBody,
+			Name:              fmt.Sprintf("%s-matrix", m.output.CallingModule),
+			SkipPrintToScreen: true,
+		})
 
 		o.PrefixIdentifier = m.AWSProfile
 		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
 
 		o.WriteFullOutput(o.Table.TableFiles, nil)
 		m.writeLoot(o.Table.DirectoryName, verbosity)
@@ -375,6 +387,53 @@ func (m *Inventory2Module) PrintInventoryPerRegion(outputDirectory string, verbo
 	<-receiverDone
 }
 
+// buildRawMatrix returns every service and every region in a fixed order, with 0 for no resources and ? for AccessDenied.
+// Unlike the table, nothing is hidden or reordered based on the counts, so the CSV can be diffed between engagements.
+func (m *Inventory2Module) buildRawMatrix() ([]string, [][]string) {
+	regions := append([]string{"Global"}, m.AWSRegions...)
+	sort.Strings(regions[1:])
+
+	header := append([]string{"Resource Type"}, regions...)
+	var body [][]string
+	for _, service := range m.services {
+		row := []string{service}
+		if service == "total" {
+			row[0] = "Total"
+		}
+		for _, region := range regions {
+			if m.deniedMap[service][region] {
+				row = append(row, "?")
+			} else {
+				row = append(row, strconv.Itoa(m.serviceMap[service][region]))
+			}
+		}
+		body = append(body, row)
+	}
+	return header, body
+}
+
+// recordError logs the error and, if it was an AccessDenied, remembers that the count for this service and region is unknown
+func (m *Inventory2Module) recordError(service string, r string, err error) {
+	m.modLog.Error(err.Error())
+	m.CommandCounter.Error++
+	if isAccessDeniedError(err) {
+		m.mu.Lock()
+		m.deniedMap[service][r] = true
+		m.mu.Unlock()
+	}
+}
+
+func (m *Inventory2Module) regionHasDeniedService(r string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, service := range m.services {
+		if m.deniedMap[service][r] {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *Inventory2Module) writeLoot(outputDirectory string, verbosity int) {
 	path := filepath.Join(outputDirectory, "loot")
 	err := os.MkdirAll(path, os.ModePerm)
@@ -395,7 +454,7 @@ func (m *Inventory2Module) writeLoot(outputDirectory string, verbosity int) {
 		m.CommandCounter.Error++
 	}
 
-	if verbosity > 2 {
+	if internal.ShowLoot(verbosity) {
 		fmt.Println()
 		fmt.Printf("[%s][%s] %s \n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("All identified resources"))
 		fmt.Print(out)
@@ -823,8 +882,7 @@ func (m *Inventory2Module) getLambdaFunctionsPerRegion(r string, wg *sync.WaitGr
 
 	ListFunctions, err := sdk.CachedLambdaListFunctions(m.LambdaClient, aws.ToString(m.Caller.Account), r)
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -866,8 +924,7 @@ func (m *Inventory2Module) getAthenaDatabasesPerRegion(r string, wg *sync.WaitGr
 
 	ListDataCatalogs, err := sdk.CachedAthenaListDataCatalogs(m.AthenaClient, aws.ToString(m.Caller.Account), r)
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -875,8 +932,7 @@ func (m *Inventory2Module) getAthenaDatabasesPerRegion(r string, wg *sync.WaitGr
 
 		ListDatabases, err := sdk.CachedAthenaListDatabases(m.AthenaClient, aws.ToString(m.Caller.Account), r, aws.ToString(dc.CatalogName))
 		if err != nil {
-			m.modLog.Error(err.Error())
-			m.CommandCounter.Error++
+			m.recordError(service, r, err)
 			return
 		}
 
@@ -966,8 +1022,7 @@ func (m *Inventory2Module) getEc2InstancesPerRegion(r string, wg *sync.WaitGroup
 	DescribeInstances, err := sdk.CachedEC2DescribeInstances(m.EC2Client, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1011,8 +1066,7 @@ func (m *Inventory2Module) getEc2ImagesPerRegion(r string, wg *sync.WaitGroup, s
 	DescribeImages, err := sdk.CachedEC2DescribeImages(m.EC2Client, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1055,8 +1109,7 @@ func (m *Inventory2Module) getEc2SnapshotsPerRegion(r string, wg *sync.WaitGroup
 	DescribeSnapshots, err := sdk.CachedEC2DescribeSnapshots(m.EC2Client, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1099,8 +1152,7 @@ func (m *Inventory2Module) getEc2VolumesPerRegion(r string, wg *sync.WaitGroup,
 	DescribeVolumes, err := sdk.CachedEC2DescribeVolumes(m.EC2Client, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1141,8 +1193,7 @@ func (m *Inventory2Module) getEksClustersPerRegion(r string, wg *sync.WaitGroup,
 
 	ListClusters, err := sdk.CachedEKSListClusters(m.EKSClient, aws.ToString(m.Caller.Account), r)
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1184,16 +1235,14 @@ func (m *Inventory2Module) getEKSNodeGroupsPerRegion(r string, wg *sync.WaitGrou
 
 	ListClusters, err := sdk.CachedEKSListClusters(m.EKSClient, aws.ToString(m.Caller.Account), r)
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
 	for _, cluster := range ListClusters {
 		NodeGroups, err := sdk.CachedEKSListNodeGroups(m.EKSClient, aws.ToString(m.Caller.Account), r, cluster)
 		if err != nil {
-			m.modLog.Error(err.Error())
-			m.CommandCounter.Error++
+			m.recordError(service, r, err)
 			return
 		}
 		// Add this page of resources to the total count
@@ -1236,8 +1285,7 @@ func (m *Inventory2Module) getCloudFormationStacksPerRegion(r string, wg *sync.W
 	ListStacks, err := sdk.CachedCloudFormationListStacks(m.CloudFormationClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1281,8 +1329,7 @@ func (m *Inventory2Module) getElasticacheClustersPerRegion(r string, wg *sync.Wa
 	ListClusters, err := sdk.CachedElastiCacheDescribeCacheClusters(m.ElasticacheClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1324,8 +1371,7 @@ func (m *Inventory2Module) getElasticBeanstalkApplicationsPerRegion(r string, wg
 	ListApplications, err := sdk.CachedElasticBeanstalkDescribeApplications(m.ElasticBeanstalkClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1368,8 +1414,7 @@ func (m *Inventory2Module) getEMRClustersPerRegion(r string, wg *sync.WaitGroup,
 	ListClusters, err := sdk.CachedEMRListClusters(m.EMRClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1411,8 +1456,7 @@ func (m *Inventory2Module) GetEMRInstancesPerRegion(r string, wg *sync.WaitGroup
 	ListClusters, err := sdk.CachedEMRListClusters(m.EMRClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1421,8 +1465,7 @@ func (m *Inventory2Module) GetEMRInstancesPerRegion(r string, wg *sync.WaitGroup
 		ListInstances, err := sdk.CachedEMRListInstances(m.EMRClient, aws.ToString(m.Caller.Account), r, aws.ToString(cluster.Id))
 
 		if err != nil {
-			m.modLog.Error(err.Error())
-			m.CommandCounter.Error++
+			m.recordError(service, r, err)
 			return
 		}
 
@@ -1465,8 +1508,7 @@ func (m *Inventory2Module) getSecretsManagerSecretsPerRegion(r string, wg *sync.
 	ListSecrets, err := sdk.CachedSecretsManagerListSecrets(m.SecretsManagerClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1508,8 +1550,7 @@ func (m *Inventory2Module) getRdsClustersPerRegion(r string, wg *sync.WaitGroup,
 
 	DescribeDBInstances, err := sdk.CachedRDSDescribeDBInstances(m.RDSClient, aws.ToString(m.Caller.Account), r)
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1550,8 +1591,7 @@ func (m *Inventory2Module) getAPIGatewayvAPIsPerRegion(r string, wg *sync.WaitGr
 	GetRestApis, err := sdk.CachedApiGatewayGetRestAPIs(m.APIGatewayClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1593,8 +1633,7 @@ func (m *Inventory2Module) getAPIGatewayv2APIsPerRegion(r string, wg *sync.WaitG
 	GetApis, err := sdk.CachedAPIGatewayv2GetAPIs(m.APIGatewayv2Client, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1633,8 +1672,7 @@ func (m *Inventory2Module) getELBv2ListenersPerRegion(r string, wg *sync.WaitGro
 
 	DescribeLoadBalancers, err := sdk.CachedELBv2DescribeLoadBalancers(m.ELBv2Client, aws.ToString(m.Caller.Account), r)
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1676,8 +1714,7 @@ func (m *Inventory2Module) getELBListenersPerRegion(r string, wg *sync.WaitGroup
 	DescribeLoadBalancers, err := sdk.CachedELBDescribeLoadBalancers(m.ELBClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1717,8 +1754,7 @@ func (m *Inventory2Module) getMqBrokersPerRegion(r string, wg *sync.WaitGroup, s
 
 	ListBrokers, err := sdk.CachedMQListBrokers(m.MQClient, aws.ToString(m.Caller.Account), r)
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1758,8 +1794,7 @@ func (m *Inventory2Module) getOpenSearchPerRegion(r string, wg *sync.WaitGroup,
 
 	ListDomainNames, err := sdk.CachedOpenSearchListDomainNames(m.OpenSearchClient, aws.ToString(m.Caller.Account), r)
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1803,8 +1838,7 @@ func (m *Inventory2Module) getGrafanaWorkspacesPerRegion(r string, wg *sync.Wait
 	// This for loop exits at the end depending on whether the output hits its last page (see pagination control block at the end of the loop).
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1847,8 +1881,7 @@ func (m *Inventory2Module) getAppRunnerServicesPerRegion(r string, wg *sync.Wait
 
 	if err != nil {
 		//modLog.Error(err.Error())
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1889,8 +1922,7 @@ func (m *Inventory2Module) getLightsailInstancesAndContainersPerRegion(r string,
 	ContainerServices, err := sdk.CachedLightsailGetContainerServices(m.LightsailClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	} else {
 		// Add this page of resources to the total count
@@ -1905,8 +1937,7 @@ func (m *Inventory2Module) getLightsailInstancesAndContainersPerRegion(r string,
 	Instances, err := sdk.CachedLightsailGetInstances(m.LightsailClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1947,8 +1978,7 @@ func (m *Inventory2Module) getSSMParametersPerRegion(r string, wg *sync.WaitGrou
 	Parameters, err := sdk.CachedSSMDescribeParameters(m.SSMClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -1991,8 +2021,7 @@ func (m *Inventory2Module) getEcsTasksPerRegion(r string, wg *sync.WaitGroup, se
 	Clusters, err := sdk.CachedECSListClusters(m.ECSClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 	for _, cluster := range Clusters {
@@ -2000,8 +2029,7 @@ func (m *Inventory2Module) getEcsTasksPerRegion(r string, wg *sync.WaitGroup, se
 		Tasks, err := sdk.CachedECSListTasks(m.ECSClient, aws.ToString(m.Caller.Account), r, cluster)
 
 		if err != nil {
-			m.modLog.Error(err.Error())
-			m.CommandCounter.Error++
+			m.recordError(service, r, err)
 			return
 		}
 		// Add this page of resources to the total count
@@ -2044,8 +2072,7 @@ func (m *Inventory2Module) getEcsServicesPerRegion(r string, wg *sync.WaitGroup,
 	Clusters, err := sdk.CachedECSListClusters(m.ECSClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 	for _, cluster := range Clusters {
@@ -2053,8 +2080,7 @@ func (m *Inventory2Module) getEcsServicesPerRegion(r string, wg *sync.WaitGroup,
 		Services, err := sdk.CachedECSListServices(m.ECSClient, aws.ToString(m.Caller.Account), r, cluster)
 
 		if err != nil {
-			m.modLog.Error(err.Error())
-			m.CommandCounter.Error++
+			m.recordError(service, r, err)
 			return
 		}
 		// Add this page of resources to the total count
@@ -2098,8 +2124,7 @@ func (m *Inventory2Module) getEcsClustersPerRegion(r string, wg *sync.WaitGroup,
 	Clusters, err := sdk.CachedECSListClusters(m.ECSClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -2142,8 +2167,7 @@ func (m *Inventory2Module) getEcrRepositoriesPerRegion(r string, wg *sync.WaitGr
 	Repositories, err := sdk.CachedECRDescribeRepositories(m.ECRClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -2185,8 +2209,7 @@ func (m *Inventory2Module) getGlueDevEndpointsPerRegion(r string, wg *sync.WaitG
 	DevEndpointNames, err := sdk.CachedGlueListDevEndpoints(m.GlueClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -2229,8 +2252,7 @@ func (m *Inventory2Module) getGlueJobsPerRegion(r string, wg *sync.WaitGroup, se
 	JobNames, err := sdk.CachedGlueListJobs(m.GlueClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -2272,8 +2294,7 @@ func (m *Inventory2Module) getGlueDatabasesPerRegion(r string, wg *sync.WaitGrou
 
 	Databases, err := sdk.CachedGlueGetDatabases(m.GlueClient, aws.ToString(m.Caller.Account), r)
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -2319,16 +2340,14 @@ func (m *Inventory2Module) getGlueTablesPerRegion(r string, wg *sync.WaitGroup,
 
 	Databases, err := sdk.CachedGlueGetDatabases(m.GlueClient, aws.ToString(m.Caller.Account), r)
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
 	for _, database := range Databases {
 		TableNames, err := sdk.CachedGlueGetTables(m.GlueClient, aws.ToString(m.Caller.Account), r, aws.ToString(database.Name))
 		if err != nil {
-			m.modLog.Error(err.Error())
-			m.CommandCounter.Error++
+			m.recordError(service, r, err)
 			return
 
 		}
@@ -2372,8 +2391,7 @@ func (m *Inventory2Module) getKinesisDatastreamsPerRegion(r string, wg *sync.Wai
 
 	Datastreams, err := sdk.CachedKinesisListStreams(m.KinesisClient, aws.ToString(m.Caller.Account), r)
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -2416,8 +2434,7 @@ func (m *Inventory2Module) getSNSTopicsPerRegion(r string, wg *sync.WaitGroup, s
 	Topics, err := sdk.CachedSNSListTopics(m.SNSClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -2456,8 +2473,7 @@ func (m *Inventory2Module) getSQSQueuesPerRegion(r string, wg *sync.WaitGroup, s
 	QueueUrls, err := sdk.CachedSQSListQueues(m.SQSClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -2497,8 +2513,7 @@ func (m *Inventory2Module) getDynamoDBTablesPerRegion(r string, wg *sync.WaitGro
 
 	TableNames, err := sdk.CachedDynamoDBListTables(m.DynamoDBClient, aws.ToString(m.Caller.Account), r)
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -2540,8 +2555,7 @@ func (m *Inventory2Module) getRedshiftClustersPerRegion(r string, wg *sync.WaitG
 
 	Clusters, err := sdk.CachedRedShiftDescribeClusters(m.RedshiftClient, aws.ToString(m.Caller.Account), r)
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -2583,8 +2597,7 @@ func (m *Inventory2Module) getCodeArtifactDomainsPerRegion(r string, wg *sync.Wa
 
 	Domains, err := sdk.CachedCodeArtifactListDomains(m.CodeArtifactClient, aws.ToString(m.Caller.Account), r)
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -2626,8 +2639,7 @@ func (m *Inventory2Module) getCodeBuildProjectsPerRegion(r string, wg *sync.Wait
 
 	projects, err := sdk.CachedCodeBuildListProjects(m.CodeBuildClient, aws.ToString(m.Caller.Account), r)
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -2669,8 +2681,7 @@ func (m *Inventory2Module) getCodeCommitRepositoriesPerRegion(r string, wg *sync
 
 	repos, err := sdk.CachedCodeCommitListRepositories(m.CodeCommitClient, aws.ToString(m.Caller.Account), r)
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -2710,8 +2721,7 @@ func (m *Inventory2Module) getCodeDeployApplicationsPerRegion(r string, wg *sync
 
 	apps, err := sdk.CachedCodeDeployListApplications(m.CodeDeployClient, aws.ToString(m.Caller.Account), r)
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 
 	}
@@ -2752,8 +2762,7 @@ func (m *Inventory2Module) getCodeDeployDeploymentsPerRegion(r string, wg *sync.
 
 	deployments, err := sdk.CachedCodeDeployListDeployments(m.CodeDeployClient, aws.ToString(m.Caller.Account), r)
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 
 	}
@@ -2795,8 +2804,7 @@ func (m *Inventory2Module) getDataPipelinePipelinesPerRegion(r string, wg *sync.
 	pipelines, err := sdk.CachedDataPipelineListPipelines(m.DataPipelineClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 
 	}
@@ -2838,8 +2846,7 @@ func (m *Inventory2Module) getStepFunctionsPerRegion(r string, wg *sync.WaitGrou
 	ListStateMachines, err := sdk.CachedStepFunctionsListStateMachines(m.StepFunctionClient, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -2879,8 +2886,7 @@ func (m *Inventory2Module) getCloud9EnvironmentsPerRegion(r string, wg *sync.Wai
 	Environments, err := sdk.CachedCloud9ListEnvironments(m.Cloud9Client, aws.ToString(m.Caller.Account), r)
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 
 	}
@@ -2916,8 +2922,7 @@ func (m *Inventory2Module) getBuckets(verbosity int, dataReceiver chan GlobalRes
 	Buckets, err := sdk.CachedListBuckets(m.S3Client, aws.ToString(m.Caller.Account))
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -2960,8 +2965,7 @@ func (m *Inventory2Module) getCloudfrontDistros(verbosity int, dataReceiver chan
 	// This for loop exits at the end depending on whether the output hits its last page (see pagination control block at the end of the loop).
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -3000,8 +3004,7 @@ func (m *Inventory2Module) getIAMUsers(verbosity int, dataReceiver chan GlobalRe
 	Users, err := sdk.CachedIamListUsers(m.IAMClient, aws.ToString(m.Caller.Account))
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 	total = total + len(Users)
@@ -3037,8 +3040,7 @@ func (m *Inventory2Module) getIAMRoles(verbosity int, dataReceiver chan GlobalRe
 	Roles, err := sdk.CachedIamListRoles(m.IAMClient, aws.ToString(m.Caller.Account))
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 	total = total + len(Roles)
@@ -3076,8 +3078,7 @@ func (m *Inventory2Module) getIAMGroups(verbosity int, dataReceiver chan GlobalR
 	Groups, err := sdk.CachedIamListGroups(m.IAMClient, aws.ToString(m.Caller.Account))
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 	total = total + len(Groups)
@@ -3115,8 +3116,7 @@ func (m *Inventory2Module) getIAMAccessKeys(verbosity int, dataReceiver chan Glo
 	Users, err := sdk.CachedIamListUsers(m.IAMClient, aws.ToString(m.Caller.Account))
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -3125,8 +3125,7 @@ func (m *Inventory2Module) getIAMAccessKeys(verbosity int, dataReceiver chan Glo
 		AccessKeys, err := sdk.CachedIamListAccessKeys(m.IAMClient, aws.ToString(m.Caller.Account), aws.ToString(user.UserName))
 
 		if err != nil {
-			m.modLog.Error(err.Error())
-			m.CommandCounter.Error++
+			m.recordError(service, r, err)
 			return
 		}
 		total = total + len(AccessKeys)
@@ -3165,8 +3164,7 @@ func (m *Inventory2Module) getRoute53Zones(verbosity int, dataReceiver chan Glob
 	Zones, err := sdk.CachedRoute53ListHostedZones(m.Route53Client, aws.ToString(m.Caller.Account))
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 	total = total + len(Zones)
@@ -3203,8 +3201,7 @@ func (m *Inventory2Module) getRoute53Records(verbosity int, dataReceiver chan Gl
 	Zones, err := sdk.CachedRoute53ListHostedZones(m.Route53Client, aws.ToString(m.Caller.Account))
 
 	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+		m.recordError(service, r, err)
 		return
 	}
 
@@ -3212,8 +3209,7 @@ func (m *Inventory2Module) getRoute53Records(verbosity int, dataReceiver chan Gl
 		Records, err := sdk.CachedRoute53ListResourceRecordSets(m.Route53Client, aws.ToString(m.Caller.Account), aws.ToString(zone.Id))
 
 		if err != nil {
-			m.modLog.Error(err.Error())
-			m.CommandCounter.Error++
+			m.recordError(service, r, err)
 			return
 		}
 		total = total + len(Records)
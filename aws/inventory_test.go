@@ -0,0 +1,50 @@
+package aws
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/smithy-go"
+	"github.com/sirupsen/logrus"
+)
+
+func TestInventoryRawMatrix(t *testing.T) {
+	m := Inventory2Module{
+		AWSRegions: []string{"us-west-2", "us-east-1"},
+		services:   []string{"total", "Lambda Functions", "SecretsManager Secrets"},
+		serviceMap: map[string]map[string]int{},
+		deniedMap:  map[string]map[string]bool{},
+		modLog:     internal.TxtLog.WithFields(logrus.Fields{"module": "inventory"}),
+	}
+	for _, service := range m.services {
+		m.serviceMap[service] = map[string]int{}
+		m.deniedMap[service] = map[string]bool{}
+	}
+	m.serviceMap["Lambda Functions"]["us-east-1"] = 3
+	m.serviceMap["total"]["us-east-1"] = 3
+
+	m.recordError("SecretsManager Secrets", "us-west-2", &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized"})
+	// Errors that aren't permission related shouldn't be shown as unknown
+	m.recordError("SecretsManager Secrets", "us-east-1", errors.New("connection reset"))
+
+	header, body := m.buildRawMatrix()
+
+	expectedHeader := []string{"Resource Type", "Global", "us-east-1", "us-west-2"}
+	if !reflect.DeepEqual(header, expectedHeader) {
+		t.Errorf("Expected header %v, got %v", expectedHeader, header)
+	}
+	expectedBody := [][]string{
+		{"Total", "0", "3", "0"},
+		{"Lambda Functions", "0", "3", "0"},
+		{"SecretsManager Secrets", "0", "0", "?"},
+	}
+	if !reflect.DeepEqual(body, expectedBody) {
+		t.Errorf("Expected body %v, got %v", expectedBody, body)
+	}
+
+	if !m.regionHasDeniedService("us-west-2") || m.regionHasDeniedService("us-east-1") {
+		t.Errorf("Expected only us-west-2 to have a denied service")
+	}
+}
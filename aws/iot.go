@@ -0,0 +1,441 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/aws/policy"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type IoTModule struct {
+	// General configuration data
+	IoTClient sdk.IoTClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Policies []IoTPolicy
+	Things   []IoTThing
+	// Endpoints are the MQTT endpoints of the regions with things or policies
+	Endpoints      map[string]string
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type IoTPolicy struct {
+	Region string
+	Name   string
+	Arn    string
+	// Actions and Resources are what the Allow statements of the policy grant
+	Actions   []string
+	Resources []string
+	Findings  []string
+}
+
+type IoTThing struct {
+	Region     string
+	Name       string
+	Arn        string
+	Type       string
+	Attributes []string
+}
+
+// iotRegion is everything found in a region, the Receiver splits it up
+type iotRegion struct {
+	Region   string
+	Endpoint string
+	Policies []IoTPolicy
+	Things   []IoTThing
+}
+
+const (
+	iotPolicyAllActions   = "Allows iot:*"
+	iotPolicyAllResources = "Allows any resource"
+)
+
+func (m *IoTModule) PrintIoT(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "iot"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+	m.Endpoints = make(map[string]string)
+
+	fmt.Printf("[%s][%s] Enumerating IoT Core things and policies for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan iotRegion)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.Policies, func(i, j int) bool {
+		if m.Policies[i].Region != m.Policies[j].Region {
+			return m.Policies[i].Region < m.Policies[j].Region
+		}
+		return m.Policies[i].Name < m.Policies[j].Name
+	})
+	sort.Slice(m.Things, func(i, j int) bool {
+		if m.Things[i].Region != m.Things[j].Region {
+			return m.Things[i].Region < m.Things[j].Region
+		}
+		return m.Things[i].Name < m.Things[j].Name
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Policy",
+		"Actions",
+		"Resources",
+		"Findings",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Policy",
+			"Actions",
+			"Resources",
+			"Findings",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Policy",
+			"Actions",
+			"Findings",
+		}
+	}
+
+	// Table rows
+	for _, iotPolicy := range m.Policies {
+		var findings []string
+		for _, finding := range iotPolicy.Findings {
+			findings = append(findings, magenta(finding))
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				iotPolicy.Region,
+				iotPolicy.Name,
+				strings.Join(iotPolicy.Actions, ", "),
+				strings.Join(iotPolicy.Resources, "\n"),
+				strings.Join(findings, "\n"),
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 || len(m.Things) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		if len(m.Things) > 0 {
+			o.Table.TableFiles = append(o.Table.TableFiles, m.thingsTable())
+		}
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     m.output.CallingModule,
+			Contents: m.writeLoot(),
+		})
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d IoT policies found, %d overly permissive. %d things found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), m.countPermissive(), len(m.Things))
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No IoT policies or things found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *IoTModule) thingsTable() internal.TableFile {
+	header := []string{
+		"Account",
+		"Region",
+		"Thing",
+		"Type",
+		"Attributes",
+	}
+	var body [][]string
+	for _, thing := range m.Things {
+		body = append(body, []string{
+			aws.ToString(m.Caller.Account),
+			thing.Region,
+			thing.Name,
+			thing.Type,
+			strings.Join(thing.Attributes, "\n"),
+		})
+	}
+	return internal.TableFile{
+		Header:    header,
+		Body:      body,
+		TableCols: []string{"Region", "Thing", "Type", "Attributes"},
+		Name:      "iot-things",
+	}
+}
+
+func (m *IoTModule) countPermissive() int {
+	var count int
+	for _, iotPolicy := range m.Policies {
+		if len(iotPolicy.Findings) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// writeLoot has mosquitto commands for the MQTT endpoint of every region. Connecting takes a device certificate and
+// key that a policy attached to them lets connect, the client ID has to be a thing name for thing-scoped policies.
+func (m *IoTModule) writeLoot() string {
+	var out string
+	out += "#############################################\n"
+	out += "# Probe the IoT Core MQTT endpoints. You need a device certificate and private key, e.g. from a device or its\n"
+	out += "# provisioning files, and the Amazon root CA:\n"
+	out += "# curl -o AmazonRootCA1.pem https://www.amazontrust.com/repository/AmazonRootCA1.pem\n"
+	out += "# With a permissive policy, subscribing to # shows the traffic of every device.\n"
+	out += "#############################################\n"
+
+	var regions []string
+	for region := range m.Endpoints {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	for _, region := range regions {
+		endpoint := m.Endpoints[region]
+		if endpoint == "" {
+			continue
+		}
+		tls := fmt.Sprintf("-h %s -p 8883 --cafile AmazonRootCA1.pem --cert device.pem.crt --key private.pem.key", endpoint)
+		out += fmt.Sprintf("\n# %s\n", region)
+		out += fmt.Sprintf("mosquitto_sub %s -i cloudfox-probe -t '#' -v\n", tls)
+		out += fmt.Sprintf("mosquitto_pub %s -i cloudfox-probe -t 'cloudfox/probe' -m 'cloudfox'\n", tls)
+		for _, thing := range m.Things {
+			if thing.Region != region {
+				continue
+			}
+			out += fmt.Sprintf("mosquitto_sub %s -i %s -t '$aws/things/%s/shadow/#' -v\n", tls, thing.Name, thing.Name)
+		}
+	}
+	return out
+}
+
+func (m *IoTModule) Receiver(receiver chan iotRegion, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Policies = append(m.Policies, data.Policies...)
+			m.Things = append(m.Things, data.Things...)
+			m.Endpoints[data.Region] = data.Endpoint
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *IoTModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan iotRegion) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("iot", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getIoTPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *IoTModule) getIoTPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan iotRegion) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	region := iotRegion{Region: r}
+
+	things, err := sdk.CachedIoTListThings(m.IoTClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	for _, thing := range things {
+		iotThing := IoTThing{
+			Region: r,
+			Name:   aws.ToString(thing.ThingName),
+			Arn:    aws.ToString(thing.ThingArn),
+			Type:   aws.ToString(thing.ThingTypeName),
+		}
+		for key, value := range thing.Attributes {
+			iotThing.Attributes = append(iotThing.Attributes, fmt.Sprintf("%s=%s", key, value))
+		}
+		sort.Strings(iotThing.Attributes)
+		region.Things = append(region.Things, iotThing)
+	}
+
+	policies, err := sdk.CachedIoTListPolicies(m.IoTClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	for _, summary := range policies {
+		iotPolicy := IoTPolicy{
+			Region: r,
+			Name:   aws.ToString(summary.PolicyName),
+			Arn:    aws.ToString(summary.PolicyArn),
+		}
+		document, err := sdk.CachedIoTGetPolicy(m.IoTClient, aws.ToString(m.Caller.Account), r, iotPolicy.Name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		} else if parsedPolicy, err := policy.ParseJSONPolicy([]byte(document)); err != nil {
+			m.modLog.Error(fmt.Sprintf("parsing IoT policy %s: %s", iotPolicy.Name, err))
+		} else {
+			iotPolicy.Actions, iotPolicy.Resources, iotPolicy.Findings = analyzeIoTPolicy(parsedPolicy)
+		}
+		region.Policies = append(region.Policies, iotPolicy)
+	}
+
+	// Without things or policies there is nothing that could connect, so the endpoint isn't worth a call
+	if len(region.Things) > 0 || len(region.Policies) > 0 {
+		region.Endpoint, err = sdk.CachedIoTDescribeEndpoint(m.IoTClient, aws.ToString(m.Caller.Account), r)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+	}
+
+	dataReceiver <- region
+}
+
+// analyzeIoTPolicy returns the actions and resources the Allow statements of an IoT policy grant, and the findings for
+// statements that allow every IoT action or every resource. A NotAction statement allows everything it doesn't list.
+func analyzeIoTPolicy(iotPolicy policy.Policy) ([]string, []string, []string) {
+	var actions, resources, findings []string
+	var allActions, allResources bool
+	for _, statement := range iotPolicy.Statement {
+		if !statement.IsAllow() {
+			continue
+		}
+		statementActions := []string(statement.Action)
+		if len(statement.NotAction) > 0 {
+			statementActions = []string{fmt.Sprintf("* except %s", strings.Join(statement.NotAction, ", "))}
+			allActions = true
+		}
+		for _, action := range statementActions {
+			if action == "*" || strings.EqualFold(action, "iot:*") {
+				allActions = true
+			}
+			if !internal.Contains(action, actions) {
+				actions = append(actions, action)
+			}
+		}
+		statementResources := []string(statement.Resource)
+		if len(statement.NotResource) > 0 {
+			statementResources = []string{fmt.Sprintf("* except %s", strings.Join(statement.NotResource, ", "))}
+			allResources = true
+		}
+		for _, resource := range statementResources {
+			if resource == "*" {
+				allResources = true
+			}
+			if !internal.Contains(resource, resources) {
+				resources = append(resources, resource)
+			}
+		}
+	}
+	if allActions {
+		findings = append(findings, iotPolicyAllActions)
+	}
+	if allResources {
+		findings = append(findings, iotPolicyAllResources)
+	}
+	return actions, resources, findings
+}
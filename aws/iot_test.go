@@ -0,0 +1,98 @@
+package aws
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestIoT(t *testing.T) {
+	m := IoTModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines: 3,
+		IoTClient:  &sdk.MockedIoTClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintIoT(".", 2)
+
+	expectedPolicies := []IoTPolicy{
+		{
+			Region:    "us-east-1",
+			Name:      "device-full-access",
+			Arn:       "arn:aws:iot:us-east-1:123456789012:policy/device-full-access",
+			Actions:   []string{"iot:*"},
+			Resources: []string{"*"},
+			Findings:  []string{iotPolicyAllActions, iotPolicyAllResources},
+		},
+		{
+			Region:    "us-east-1",
+			Name:      "sensor-publish",
+			Arn:       "arn:aws:iot:us-east-1:123456789012:policy/sensor-publish",
+			Actions:   []string{"iot:Connect", "iot:Publish"},
+			Resources: []string{"arn:aws:iot:us-east-1:123456789012:client/${iot:Connection.Thing.ThingName}", "*"},
+			Findings:  []string{iotPolicyAllResources},
+		},
+		{
+			Region:  "us-east-1",
+			Name:    "thing-scoped",
+			Arn:     "arn:aws:iot:us-east-1:123456789012:policy/thing-scoped",
+			Actions: []string{"iot:Connect", "iot:Publish", "iot:Receive"},
+			Resources: []string{
+				"arn:aws:iot:us-east-1:123456789012:client/${iot:Connection.Thing.ThingName}",
+				"arn:aws:iot:us-east-1:123456789012:topic/devices/${iot:Connection.Thing.ThingName}/*",
+			},
+		},
+	}
+	if !reflect.DeepEqual(m.Policies, expectedPolicies) {
+		t.Errorf("Expected policies %+v, got %+v", expectedPolicies, m.Policies)
+	}
+
+	expectedThings := []IoTThing{
+		{
+			Region: "us-east-1",
+			Name:   "gateway",
+			Arn:    "arn:aws:iot:us-east-1:123456789012:thing/gateway",
+		},
+		{
+			Region:     "us-east-1",
+			Name:       "thermostat-01",
+			Arn:        "arn:aws:iot:us-east-1:123456789012:thing/thermostat-01",
+			Type:       "thermostat",
+			Attributes: []string{"firmware=2.1.0", "site=berlin"},
+		},
+	}
+	if !reflect.DeepEqual(m.Things, expectedThings) {
+		t.Errorf("Expected things %+v, got %+v", expectedThings, m.Things)
+	}
+
+	lootFilePath := filepath.Join(".", "cloudfox-output/aws/unittesting-123456789012/loot/iot.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	expectedCommands := []string{
+		"mosquitto_sub -h a1b2c3d4e5f6g7-ats.iot.us-east-1.amazonaws.com -p 8883 --cafile AmazonRootCA1.pem --cert device.pem.crt --key private.pem.key -i cloudfox-probe -t '#' -v",
+		"mosquitto_pub -h a1b2c3d4e5f6g7-ats.iot.us-east-1.amazonaws.com -p 8883 --cafile AmazonRootCA1.pem --cert device.pem.crt --key private.pem.key -i cloudfox-probe -t 'cloudfox/probe' -m 'cloudfox'",
+		"-i thermostat-01 -t '$aws/things/thermostat-01/shadow/#' -v",
+	}
+	for _, expected := range expectedCommands {
+		if !strings.Contains(string(lootFile), expected) {
+			t.Errorf("Expected %s to be in the loot file", expected)
+		}
+	}
+}
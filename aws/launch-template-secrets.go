@@ -0,0 +1,422 @@
+package aws
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type LaunchTemplateSecretsModule struct {
+	// General configuration data
+	EC2Client sdk.AWSEC2LaunchTemplatesClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Secrets        []LaunchTemplateSecret
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+// LaunchTemplateSecret is a secret found in one or more versions of a launch template
+type LaunchTemplateSecret struct {
+	Region       string
+	TemplateID   string
+	TemplateName string
+	// Versions are the version numbers that have the secret, in ascending order
+	Versions      []int64
+	LatestVersion int64
+	// Location is where in the version the secret is: the user data, an instance tag or the other template data
+	Location string
+	Rule     string
+	Line     string
+	Value    string
+}
+
+const (
+	launchTemplateLocationUserData = "UserData"
+	launchTemplateLocationData     = "LaunchTemplateData"
+)
+
+// RemovedFromLatest tells if the secret is only left in older versions of the template
+func (s LaunchTemplateSecret) RemovedFromLatest() bool {
+	return s.Versions[len(s.Versions)-1] < s.LatestVersion
+}
+
+func (m *LaunchTemplateSecretsModule) PrintLaunchTemplateSecrets(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "launch-template-secrets"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Searching every version of the EC2 launch templates for secrets in account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan LaunchTemplateSecret)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.SliceStable(m.Secrets, func(i, j int) bool {
+		if m.Secrets[i].Region != m.Secrets[j].Region {
+			return m.Secrets[i].Region < m.Secrets[j].Region
+		}
+		if m.Secrets[i].TemplateName != m.Secrets[j].TemplateName {
+			return m.Secrets[i].TemplateName < m.Secrets[j].TemplateName
+		}
+		return m.Secrets[i].Location < m.Secrets[j].Location
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Template",
+		"Versions",
+		"Latest",
+		"Location",
+		"Match",
+		"Snippet",
+		"Finding",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Template",
+			"Versions",
+			"Latest",
+			"Location",
+			"Match",
+			"Snippet",
+			"Finding",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Template",
+			"Versions",
+			"Location",
+			"Match",
+			"Snippet",
+			"Finding",
+		}
+	}
+
+	// Table rows. The secret itself is masked here; the loot file has it in full.
+	for _, secret := range m.Secrets {
+		var finding string
+		if secret.RemovedFromLatest() {
+			finding = magenta("Only in old versions")
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				secret.Region,
+				secret.TemplateName,
+				launchTemplateVersionsString(secret.Versions),
+				strconv.FormatInt(secret.LatestVersion, 10),
+				secret.Location,
+				secret.Rule,
+				maskDefinitionSecret(secret.Line, secret.Value),
+				finding,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     m.output.CallingModule,
+			Contents: m.writeLoot(),
+		})
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d secrets found in launch templates.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body))
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No secrets found in launch templates, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+// writeLoot has the unmasked lines the secrets were found on, and the command to pull the user data of the newest
+// version that has them
+func (m *LaunchTemplateSecretsModule) writeLoot() string {
+	var out string
+	out += "#############################################\n"
+	out += "# Set the $profile environment variable to the profile you are going to use, e.g. export profile=dev-prod.\n"
+	out += "#############################################\n"
+
+	for _, secret := range m.Secrets {
+		version := secret.Versions[len(secret.Versions)-1]
+		out += fmt.Sprintf("\n# %s (%s) version %d in %s, %s\n", secret.TemplateName, secret.TemplateID, version, secret.Region, secret.Location)
+		if secret.Location == launchTemplateLocationUserData {
+			out += fmt.Sprintf("aws --profile $profile --region %s ec2 describe-launch-template-versions --launch-template-id %s --versions %d --query 'LaunchTemplateVersions[0].LaunchTemplateData.UserData' --output text | base64 -d\n", secret.Region, secret.TemplateID, version)
+		} else {
+			out += fmt.Sprintf("aws --profile $profile --region %s ec2 describe-launch-template-versions --launch-template-id %s --versions %d --query 'LaunchTemplateVersions[0].LaunchTemplateData'\n", secret.Region, secret.TemplateID, version)
+		}
+		out += fmt.Sprintf("# %s\n%s\n", secret.Rule, secret.Line)
+	}
+	return out
+}
+
+func (m *LaunchTemplateSecretsModule) Receiver(receiver chan LaunchTemplateSecret, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Secrets = append(m.Secrets, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *LaunchTemplateSecretsModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan LaunchTemplateSecret) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("ec2", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getLaunchTemplateSecretsPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *LaunchTemplateSecretsModule) getLaunchTemplateSecretsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan LaunchTemplateSecret) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	launchTemplates, err := sdk.CachedEC2DescribeLaunchTemplates(m.EC2Client, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, launchTemplate := range launchTemplates {
+		versions, err := sdk.CachedEC2DescribeLaunchTemplateVersions(m.EC2Client, aws.ToString(m.Caller.Account), r, aws.ToString(launchTemplate.LaunchTemplateId))
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		for _, secret := range m.scanLaunchTemplateVersions(versions) {
+			secret.Region = r
+			secret.TemplateID = aws.ToString(launchTemplate.LaunchTemplateId)
+			secret.TemplateName = aws.ToString(launchTemplate.LaunchTemplateName)
+			secret.LatestVersion = aws.ToInt64(launchTemplate.LatestVersionNumber)
+			dataReceiver <- secret
+		}
+	}
+}
+
+// scanLaunchTemplateVersions scans every version of a template and reports each secret once, with all the versions
+// that have it
+func (m *LaunchTemplateSecretsModule) scanLaunchTemplateVersions(versions []ec2_types.LaunchTemplateVersion) []LaunchTemplateSecret {
+	sort.Slice(versions, func(i, j int) bool {
+		return aws.ToInt64(versions[i].VersionNumber) < aws.ToInt64(versions[j].VersionNumber)
+	})
+
+	var secrets []LaunchTemplateSecret
+	index := make(map[string]int)
+	for _, version := range versions {
+		for _, secret := range m.scanLaunchTemplateData(version.LaunchTemplateData) {
+			key := secret.Location + "|" + secret.Value
+			i, ok := index[key]
+			if !ok {
+				i = len(secrets)
+				index[key] = i
+				secrets = append(secrets, secret)
+			}
+			secrets[i].Versions = append(secrets[i].Versions, aws.ToInt64(version.VersionNumber))
+		}
+	}
+	return secrets
+}
+
+// scanLaunchTemplateData looks for secrets in the user data, the instance tags and the rest of the template data,
+// where parameters that reference SSM or Secrets Manager are fine and hardcoded values are not
+func (m *LaunchTemplateSecretsModule) scanLaunchTemplateData(data *ec2_types.ResponseLaunchTemplateData) []LaunchTemplateSecret {
+	if data == nil {
+		return nil
+	}
+	var secrets []LaunchTemplateSecret
+	add := func(location string, matches []WorkflowSecret) {
+		for _, match := range matches {
+			secrets = append(secrets, LaunchTemplateSecret{
+				Location: location,
+				Rule:     match.Rule,
+				Line:     match.Line,
+				Value:    match.Value,
+			})
+		}
+	}
+
+	if data.UserData != nil {
+		userData, err := decodeLaunchTemplateUserData(aws.ToString(data.UserData))
+		if err != nil {
+			m.modLog.Error(err.Error())
+		}
+		add(launchTemplateLocationUserData, scanDefinitionForSecrets(userData))
+	}
+	for _, tagSpecification := range data.TagSpecifications {
+		for _, tag := range tagSpecification.Tags {
+			add("Tag "+aws.ToString(tag.Key), scanDefinitionForSecrets(aws.ToString(tag.Key)+"="+aws.ToString(tag.Value)))
+		}
+	}
+
+	rest := *data
+	rest.UserData = nil
+	rest.TagSpecifications = nil
+	// HttpTokens: required is a setting, not a token
+	rest.MetadataOptions = nil
+	document, err := json.MarshalIndent(rest, "", "  ")
+	if err != nil {
+		m.modLog.Error(err.Error())
+		return secrets
+	}
+	add(launchTemplateLocationData, scanDefinitionForSecrets(string(document)))
+	return secrets
+}
+
+// decodeLaunchTemplateUserData base64 decodes user data, and unpacks it if it was gzipped, which cloud-init supports
+// to fit more into the 16 KB limit
+func decodeLaunchTemplateUserData(userData string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(userData)
+	if err != nil {
+		return "", err
+	}
+	if !bytes.HasPrefix(decoded, []byte{0x1f, 0x8b}) {
+		return string(decoded), nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(decoded))
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	unpacked, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return string(unpacked), nil
+}
+
+// launchTemplateVersionsString lists versions, with runs of consecutive versions shortened to ranges, e.g. 1-3, 5
+func launchTemplateVersionsString(versions []int64) string {
+	var parts []string
+	for i := 0; i < len(versions); {
+		j := i
+		for j+1 < len(versions) && versions[j+1] == versions[j]+1 {
+			j++
+		}
+		if j == i {
+			parts = append(parts, strconv.FormatInt(versions[i], 10))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", versions[i], versions[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
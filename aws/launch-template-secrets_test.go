@@ -0,0 +1,91 @@
+package aws
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestLaunchTemplateSecrets(t *testing.T) {
+	m := LaunchTemplateSecretsModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines: 3,
+		EC2Client:  &sdk.MockedEC2LaunchTemplatesClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintLaunchTemplateSecrets(".", 2)
+
+	type expectedSecret struct {
+		template string
+		location string
+		rule     string
+		versions string
+		removed  bool
+	}
+	expected := []expectedSecret{
+		{"batch-workers", "Tag SLACK_TOKEN", "API token", "1", false},
+		{"web-asg", launchTemplateLocationUserData, "Secret-looking assignment", "1-2", true},
+		{"web-asg", launchTemplateLocationUserData, "AWS access key", "1-2", true},
+	}
+	if len(m.Secrets) != len(expected) {
+		t.Fatalf("Expected %d secrets, got %d: %v", len(expected), len(m.Secrets), m.Secrets)
+	}
+	for i, secret := range m.Secrets {
+		got := expectedSecret{secret.TemplateName, secret.Location, secret.Rule, launchTemplateVersionsString(secret.Versions), secret.RemovedFromLatest()}
+		if got != expected[i] {
+			t.Errorf("Expected secret %d to be %v, got %v", i, expected[i], got)
+		}
+	}
+
+	lootFilePath := filepath.Join(".", "cloudfox-output/aws/unittesting-123456789012/loot/launch-template-secrets.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	for _, expected := range []string{
+		"--launch-template-id lt-0123456789abcdef0 --versions 2 --query 'LaunchTemplateVersions[0].LaunchTemplateData.UserData' --output text | base64 -d",
+		"export DB_PASSWORD=Sup3rS3cretPassw0rd",
+	} {
+		if !strings.Contains(string(lootFile), expected) {
+			t.Errorf("Expected %s to be in the loot file", expected)
+		}
+	}
+}
+
+func TestDecodeLaunchTemplateUserData(t *testing.T) {
+	script := "#!/bin/bash\nexport API_KEY=abcdef123456\n"
+	var gzipped bytes.Buffer
+	writer := gzip.NewWriter(&gzipped)
+	writer.Write([]byte(script))
+	writer.Close()
+
+	for name, userData := range map[string]string{
+		"plain":   base64.StdEncoding.EncodeToString([]byte(script)),
+		"gzipped": base64.StdEncoding.EncodeToString(gzipped.Bytes()),
+	} {
+		decoded, err := decodeLaunchTemplateUserData(userData)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if decoded != script {
+			t.Errorf("%s: expected %q, got %q", name, script, decoded)
+		}
+	}
+}
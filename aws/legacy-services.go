@@ -0,0 +1,494 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/aws/policy"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	workMailTypes "github.com/aws/aws-sdk-go-v2/service/workmail/types"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type LegacyServicesModule struct {
+	// General configuration data
+	S3Client         sdk.AWSS3ClientInterface
+	IAMClient        sdk.AWSIAMClientInterface
+	WorkMailClient   sdk.AWSWorkMailClientInterface
+	OpenSearchClient sdk.OpenSearchClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	LegacyResources []LegacyResource
+	CommandCounter  internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type LegacyResource struct {
+	Service  string
+	Region   string
+	Type     string
+	Name     string
+	Arn      string
+	Finding  string
+	IsPublic string
+}
+
+// Service principals of deprecated or shut down services. Roles that still trust them can't be used by the
+// service anymore and are usually forgotten about.
+var legacyServicePrincipals = map[string]string{
+	"gamesparks.amazonaws.com": "GameSparks",
+	"sumerian.amazonaws.com":   "Sumerian",
+}
+
+func (m *LegacyServicesModule) PrintLegacyServices(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "legacy-services"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Looking for artifacts of deprecated services in account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+	fmt.Printf("[%s][%s] Supported Services: Sumerian, GameSparks, WorkMail, Elasticsearch\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "tasks")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan LegacyResource)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	//execute global checks
+	wg.Add(1)
+	m.CommandCounter.Total++
+	m.CommandCounter.Pending++
+	go m.getSumerianBuckets(wg, semaphore, dataReceiver)
+	wg.Add(1)
+	m.CommandCounter.Total++
+	m.CommandCounter.Pending++
+	go m.getLegacyServiceRoles(wg, semaphore, dataReceiver)
+
+	//execute regional checks
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.LegacyResources, func(i, j int) bool {
+		if m.LegacyResources[i].Service != m.LegacyResources[j].Service {
+			return m.LegacyResources[i].Service < m.LegacyResources[j].Service
+		}
+		return m.LegacyResources[i].Name < m.LegacyResources[j].Name
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Service",
+		"Region",
+		"Type",
+		"Name",
+		"Arn",
+		"Finding",
+		"Public?",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Service",
+			"Region",
+			"Type",
+			"Name",
+			"Arn",
+			"Finding",
+			"Public?",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Service",
+			"Region",
+			"Type",
+			"Name",
+			"Finding",
+			"Public?",
+		}
+	}
+
+	// Table rows
+	for i := range m.LegacyResources {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				m.LegacyResources[i].Service,
+				m.LegacyResources[i].Region,
+				m.LegacyResources[i].Type,
+				m.LegacyResources[i].Name,
+				m.LegacyResources[i].Arn,
+				m.LegacyResources[i].Finding,
+				m.LegacyResources[i].IsPublic,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s legacy service artifacts found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No legacy service artifacts found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *LegacyServicesModule) Receiver(receiver chan LegacyResource, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.LegacyResources = append(m.LegacyResources, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *LegacyServicesModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan LegacyResource) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("workmail", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getWorkMailOrganizationsPerRegion(r, wg, semaphore, dataReceiver)
+	}
+	res, err = servicemap.IsServiceInRegion("es", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getLegacyElasticsearchDomainsPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+// getSumerianBuckets finds the buckets Sumerian created to host scenes. Sumerian published scenes as public
+// WebGL sites, so these buckets were often opened up and never closed again after the service went away.
+func (m *LegacyServicesModule) getSumerianBuckets(wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan LegacyResource) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	ListBuckets, err := sdk.CachedListBuckets(m.S3Client, aws.ToString(m.Caller.Account))
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, bucket := range ListBuckets {
+		name := aws.ToString(bucket.Name)
+		if !strings.Contains(strings.ToLower(name), "sumerian") {
+			continue
+		}
+
+		region, err := sdk.CachedGetBucketLocation(m.S3Client, aws.ToString(m.Caller.Account), name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			region = "Unknown"
+		}
+
+		finding := "Sumerian scene bucket"
+		isPublic := "No"
+		website, err := sdk.CachedGetBucketWebsite(m.S3Client, aws.ToString(m.Caller.Account), region, name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+		} else if website {
+			finding = finding + ", static website hosting enabled"
+		}
+		if m.isBucketPolicyPublic(name, region) {
+			isPublic = "YES"
+		}
+
+		dataReceiver <- LegacyResource{
+			Service:  "Sumerian",
+			Region:   region,
+			Type:     "bucket",
+			Name:     name,
+			Arn:      fmt.Sprintf("arn:aws:s3:::%s", name),
+			Finding:  finding,
+			IsPublic: isPublic,
+		}
+	}
+}
+
+func (m *LegacyServicesModule) isBucketPolicyPublic(bucketName string, r string) bool {
+	policyJSON, err := sdk.CachedGetBucketPolicy(m.S3Client, aws.ToString(m.Caller.Account), r, bucketName)
+	if err != nil || policyJSON == "" {
+		return false
+	}
+	bucketPolicy, err := policy.ParseJSONPolicy([]byte(policyJSON))
+	if err != nil {
+		m.modLog.Error(fmt.Sprintf("parsing bucket access policy (%s) as JSON: %s", bucketName, err))
+		return false
+	}
+	if !bucketPolicy.IsPublic() || bucketPolicy.IsConditionallyPublic() {
+		return false
+	}
+	publicAccessBlock, err := sdk.CachedGetPublicAccessBlock(m.S3Client, aws.ToString(m.Caller.Account), r, bucketName)
+	if err != nil {
+		return true
+	}
+	return !(aws.ToBool(publicAccessBlock.IgnorePublicAcls) && aws.ToBool(publicAccessBlock.BlockPublicPolicy) && aws.ToBool(publicAccessBlock.RestrictPublicBuckets))
+}
+
+// getLegacyServiceRoles finds roles that can still be assumed by the service principal of a retired service
+func (m *LegacyServicesModule) getLegacyServiceRoles(wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan LegacyResource) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	ListRoles, err := sdk.CachedIamListRoles(m.IAMClient, aws.ToString(m.Caller.Account))
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, role := range ListRoles {
+		trustsdoc, err := policy.ParseRoleTrustPolicyDocument(role)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		for _, statement := range trustsdoc.Statement {
+			for _, principal := range statement.Principal.Service {
+				service, ok := legacyServicePrincipals[principal]
+				if !ok {
+					continue
+				}
+				dataReceiver <- LegacyResource{
+					Service: service,
+					Region:  "Global",
+					Type:    "role",
+					Name:    aws.ToString(role.RoleName),
+					Arn:     aws.ToString(role.Arn),
+					Finding: fmt.Sprintf("Role trusts %s", principal),
+				}
+			}
+		}
+	}
+}
+
+// getWorkMailOrganizationsPerRegion finds WorkMail organizations that no longer have an enabled mailbox.
+// System users and resources don't count as mailboxes.
+func (m *LegacyServicesModule) getWorkMailOrganizationsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan LegacyResource) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	organizations, err := sdk.CachedWorkMailListOrganizations(m.WorkMailClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, organization := range organizations {
+		if aws.ToString(organization.State) != "Active" {
+			continue
+		}
+		users, err := sdk.CachedWorkMailListUsers(m.WorkMailClient, aws.ToString(m.Caller.Account), r, aws.ToString(organization.OrganizationId))
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		if countActiveMailboxes(users) > 0 {
+			continue
+		}
+
+		name := aws.ToString(organization.Alias)
+		if name == "" {
+			name = aws.ToString(organization.OrganizationId)
+		}
+		dataReceiver <- LegacyResource{
+			Service: "WorkMail",
+			Region:  r,
+			Type:    "organization",
+			Name:    name,
+			Arn:     fmt.Sprintf("arn:aws:workmail:%s:%s:organization/%s", r, aws.ToString(m.Caller.Account), aws.ToString(organization.OrganizationId)),
+			Finding: "Active organization without any enabled mailboxes",
+		}
+	}
+}
+
+func countActiveMailboxes(users []workMailTypes.User) int {
+	var count int
+	for _, user := range users {
+		if user.State == workMailTypes.EntityStateEnabled && user.UserRole == workMailTypes.UserRoleUser {
+			count++
+		}
+	}
+	return count
+}
+
+// getLegacyElasticsearchDomainsPerRegion finds domains that still run Elasticsearch 6.x or older
+func (m *LegacyServicesModule) getLegacyElasticsearchDomainsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan LegacyResource) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	domains, err := sdk.CachedOpenSearchListDomainNames(m.OpenSearchClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, domain := range domains {
+		status, err := sdk.CachedOpenSearchDescribeDomain(m.OpenSearchClient, aws.ToString(m.Caller.Account), r, aws.ToString(domain.DomainName))
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		engineVersion := aws.ToString(status.EngineVersion)
+		if !isLegacyElasticsearchVersion(engineVersion) {
+			continue
+		}
+		dataReceiver <- LegacyResource{
+			Service: "Elasticsearch",
+			Region:  r,
+			Type:    "domain",
+			Name:    aws.ToString(domain.DomainName),
+			Arn:     aws.ToString(status.ARN),
+			Finding: fmt.Sprintf("Running deprecated engine version %s", engineVersion),
+		}
+	}
+}
+
+// isLegacyElasticsearchVersion returns true for engine versions like Elasticsearch_5.6 or Elasticsearch_6.8
+func isLegacyElasticsearchVersion(engineVersion string) bool {
+	if !strings.HasPrefix(engineVersion, "Elasticsearch_") {
+		return false
+	}
+	version := strings.TrimPrefix(engineVersion, "Elasticsearch_")
+	major, err := strconv.Atoi(strings.Split(version, ".")[0])
+	if err != nil {
+		return false
+	}
+	return major < 7
+}
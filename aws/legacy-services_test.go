@@ -0,0 +1,63 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+func TestLegacyServices(t *testing.T) {
+
+	m := LegacyServicesModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:       3,
+		WrapTable:        false,
+		S3Client:         &sdk.MockedS3Client{},
+		IAMClient:        &sdk.MockedIAMClient{},
+		WorkMailClient:   &sdk.MockedWorkMailClient{},
+		OpenSearchClient: &sdk.MockedOpenSearchClient{},
+	}
+
+	internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintLegacyServices(".", 2)
+
+	expectedResults := map[string]string{
+		"Elasticsearch": "domain2",
+		"GameSparks":    "GameSparksServiceRole",
+		"Sumerian":      "scenes-sumerian-123456789012",
+		"WorkMail":      "old-mail",
+	}
+	if len(m.LegacyResources) != len(expectedResults) {
+		t.Errorf("Expected %d legacy resources, got %d", len(expectedResults), len(m.LegacyResources))
+	}
+	for _, resource := range m.LegacyResources {
+		if expectedResults[resource.Service] != resource.Name {
+			t.Errorf("Unexpected %s resource %s", resource.Service, resource.Name)
+		}
+	}
+}
+
+func TestIsLegacyElasticsearchVersion(t *testing.T) {
+	versions := map[string]bool{
+		"Elasticsearch_5.6":  true,
+		"Elasticsearch_6.8":  true,
+		"Elasticsearch_7.10": false,
+		"OpenSearch_2.11":    false,
+		"":                   false,
+	}
+	for version, expected := range versions {
+		if got := isLegacyElasticsearchVersion(version); got != expected {
+			t.Errorf("isLegacyElasticsearchVersion(%s) = %t, expected %t", version, got, expected)
+		}
+	}
+}
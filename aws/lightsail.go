@@ -0,0 +1,440 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	lightsailTypes "github.com/aws/aws-sdk-go-v2/service/lightsail/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type LightsailModule struct {
+	// General configuration data
+	LightsailClient sdk.LightsailClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Resources      []LightsailResource
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type LightsailResource struct {
+	Region    string
+	Type      string
+	Name      string
+	Arn       string
+	State     string
+	Bundle    string
+	Blueprint string
+	PublicIP  string
+	PrivateIP string
+	Endpoint  string
+	OpenPorts []string
+	Username  string
+	SSHKey    string
+	Risks     []string
+}
+
+const (
+	lightsailTypeInstance = "Instance"
+	lightsailTypeDatabase = "Database"
+	// Key pair Lightsail creates in every region, downloadable with lightsail:DownloadDefaultKeyPair
+	lightsailDefaultKeyPair = "LightsailDefaultKeyPair"
+)
+
+func (m *LightsailModule) PrintLightsail(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "lightsail"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating Lightsail instances and databases for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan LightsailResource)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.Resources, func(i, j int) bool {
+		if m.Resources[i].Region != m.Resources[j].Region {
+			return m.Resources[i].Region < m.Resources[j].Region
+		}
+		if m.Resources[i].Type != m.Resources[j].Type {
+			return m.Resources[i].Type < m.Resources[j].Type
+		}
+		return m.Resources[i].Name < m.Resources[j].Name
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Type",
+		"Name",
+		"Arn",
+		"State",
+		"Bundle",
+		"Blueprint",
+		"Public IP",
+		"Private IP",
+		"Endpoint",
+		"Open Ports",
+		"Username",
+		"SSH Key",
+		"Risk",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Type",
+			"Name",
+			"Arn",
+			"State",
+			"Bundle",
+			"Blueprint",
+			"Public IP",
+			"Private IP",
+			"Endpoint",
+			"Open Ports",
+			"Username",
+			"SSH Key",
+			"Risk",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Type",
+			"Name",
+			"Bundle",
+			"Public IP",
+			"Endpoint",
+			"Open Ports",
+			"Username",
+			"Risk",
+		}
+	}
+
+	// Table rows
+	var risky int
+	for i := range m.Resources {
+		if len(m.Resources[i].Risks) > 0 {
+			risky++
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				m.Resources[i].Region,
+				m.Resources[i].Type,
+				m.Resources[i].Name,
+				m.Resources[i].Arn,
+				m.Resources[i].State,
+				m.Resources[i].Bundle,
+				m.Resources[i].Blueprint,
+				m.Resources[i].PublicIP,
+				m.Resources[i].PrivateIP,
+				m.Resources[i].Endpoint,
+				strings.Join(m.Resources[i].OpenPorts, ", "),
+				m.Resources[i].Username,
+				m.Resources[i].SSHKey,
+				strings.Join(m.Resources[i].Risks, ", "),
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		if loot := m.writeLoot(); loot != "" {
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     "lightsail-ssh-commands",
+				Contents: loot,
+			})
+		}
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d Lightsail instances and databases found, %d of them exposed to the internet.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), risky)
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No Lightsail instances or databases found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *LightsailModule) Receiver(receiver chan LightsailResource, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Resources = append(m.Resources, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *LightsailModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan LightsailResource) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("lightsail", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getLightsailResourcesPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *LightsailModule) getLightsailResourcesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan LightsailResource) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	instances, err := sdk.CachedLightsailGetInstances(m.LightsailClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	for _, instance := range instances {
+		resource := analyzeLightsailInstance(instance)
+		resource.Region = r
+		dataReceiver <- resource
+	}
+
+	databases, err := sdk.CachedLightsailGetRelationalDatabases(m.LightsailClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	for _, database := range databases {
+		resource := analyzeLightsailDatabase(database)
+		resource.Region = r
+		dataReceiver <- resource
+	}
+}
+
+// analyzeLightsailInstance lists the ports opened in the instance firewall and flags SSH and RDP when anyone on the
+// internet may connect. Lightsail has no security groups, so this firewall is all that stands in front of the instance.
+func analyzeLightsailInstance(instance lightsailTypes.Instance) LightsailResource {
+	resource := LightsailResource{
+		Type:      lightsailTypeInstance,
+		Name:      aws.ToString(instance.Name),
+		Arn:       aws.ToString(instance.Arn),
+		Bundle:    aws.ToString(instance.BundleId),
+		Blueprint: aws.ToString(instance.BlueprintId),
+		PublicIP:  aws.ToString(instance.PublicIpAddress),
+		PrivateIP: aws.ToString(instance.PrivateIpAddress),
+		Username:  aws.ToString(instance.Username),
+		SSHKey:    aws.ToString(instance.SshKeyName),
+	}
+	if instance.State != nil {
+		resource.State = aws.ToString(instance.State.Name)
+	}
+	if instance.Networking == nil {
+		return resource
+	}
+
+	var sshOpen, rdpOpen bool
+	for _, port := range instance.Networking.Ports {
+		public := lightsailPortIsPublic(port)
+		resource.OpenPorts = append(resource.OpenPorts, lightsailPortString(port, public))
+		if !public {
+			continue
+		}
+		if lightsailPortIncludes(port, 22) {
+			sshOpen = true
+		}
+		if lightsailPortIncludes(port, 3389) {
+			rdpOpen = true
+		}
+	}
+	if sshOpen {
+		resource.Risks = append(resource.Risks, "SSH open to the internet")
+	}
+	if rdpOpen {
+		resource.Risks = append(resource.Risks, "RDP open to the internet")
+	}
+	return resource
+}
+
+func analyzeLightsailDatabase(database lightsailTypes.RelationalDatabase) LightsailResource {
+	resource := LightsailResource{
+		Type:      lightsailTypeDatabase,
+		Name:      aws.ToString(database.Name),
+		Arn:       aws.ToString(database.Arn),
+		State:     aws.ToString(database.State),
+		Bundle:    aws.ToString(database.RelationalDatabaseBundleId),
+		Blueprint: aws.ToString(database.RelationalDatabaseBlueprintId),
+		Username:  aws.ToString(database.MasterUsername),
+	}
+	if resource.Blueprint == "" {
+		resource.Blueprint = aws.ToString(database.Engine)
+	}
+	if database.MasterEndpoint != nil {
+		resource.Endpoint = fmt.Sprintf("%s:%d", aws.ToString(database.MasterEndpoint.Address), aws.ToInt32(database.MasterEndpoint.Port))
+		if database.MasterEndpoint.Port != nil {
+			resource.OpenPorts = append(resource.OpenPorts, fmt.Sprintf("tcp/%d", aws.ToInt32(database.MasterEndpoint.Port)))
+		}
+	}
+	if aws.ToBool(database.PubliclyAccessible) {
+		resource.Risks = append(resource.Risks, "Publicly accessible")
+	}
+	return resource
+}
+
+// lightsailPortIsPublic tells if a firewall rule lets in the whole IPv4 or IPv6 internet
+func lightsailPortIsPublic(port lightsailTypes.InstancePortInfo) bool {
+	for _, cidr := range port.Cidrs {
+		if cidr == "0.0.0.0/0" {
+			return true
+		}
+	}
+	for _, cidr := range port.Ipv6Cidrs {
+		if cidr == "::/0" {
+			return true
+		}
+	}
+	return false
+}
+
+func lightsailPortIncludes(port lightsailTypes.InstancePortInfo, number int32) bool {
+	if port.Protocol != lightsailTypes.NetworkProtocolTcp && port.Protocol != lightsailTypes.NetworkProtocolAll {
+		return false
+	}
+	return port.FromPort <= number && number <= port.ToPort
+}
+
+func lightsailPortString(port lightsailTypes.InstancePortInfo, public bool) string {
+	ports := fmt.Sprintf("%d", port.FromPort)
+	if port.FromPort != port.ToPort {
+		ports = fmt.Sprintf("%d-%d", port.FromPort, port.ToPort)
+	}
+	from := "restricted"
+	if public {
+		from = "internet"
+	}
+	return fmt.Sprintf("%s/%s (%s)", port.Protocol, ports, from)
+}
+
+func (m *LightsailModule) writeLoot() string {
+	var out string
+	for _, resource := range m.Resources {
+		if resource.Type != lightsailTypeInstance || resource.PublicIP == "" {
+			continue
+		}
+		out += fmt.Sprintf("# %s in %s\n", resource.Name, resource.Region)
+		key := resource.SSHKey
+		if key == lightsailDefaultKeyPair || key == "" {
+			out += fmt.Sprintf("aws --profile $profile --region %s lightsail download-default-key-pair --query privateKeyBase64 --output text > %s-%s.pem\n", resource.Region, lightsailDefaultKeyPair, resource.Region)
+			key = fmt.Sprintf("%s-%s", lightsailDefaultKeyPair, resource.Region)
+		}
+		username := resource.Username
+		if username == "" {
+			username = "ec2-user"
+		}
+		out += fmt.Sprintf("ssh -i %s.pem %s@%s\n\n", key, username, resource.PublicIP)
+	}
+	if out == "" {
+		return ""
+	}
+
+	header := fmt.Sprintln("#############################################")
+	header += fmt.Sprintln("# SSH into the Lightsail instances with a public IP. Instances launched with the default key pair use a")
+	header += fmt.Sprintln("# per-region key that lightsail:DownloadDefaultKeyPair hands out.")
+	header += fmt.Sprintln("# Set the $profile environment variable to the profile you are going to use, e.g. export profile=dev-prod.")
+	header += fmt.Sprintln("#############################################")
+	header += fmt.Sprintln("")
+	return header + out
+}
@@ -0,0 +1,77 @@
+package aws
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestLightsail(t *testing.T) {
+
+	m := LightsailModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:      3,
+		WrapTable:       false,
+		LightsailClient: &sdk.MockedLightsailClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+	tmpDir := "."
+
+	m.PrintLightsail(tmpDir, 2)
+
+	expectedRisks := map[string]string{
+		// SSH is open to everyone
+		"instance1": "SSH open to the internet",
+		// SSH is only open to one range, the open range doesn't include SSH or RDP
+		"instance2": "",
+		"database1": "Publicly accessible",
+	}
+	if len(m.Resources) != len(expectedRisks) {
+		t.Fatalf("Expected %d resources, got %d", len(expectedRisks), len(m.Resources))
+	}
+	for _, resource := range m.Resources {
+		want, ok := expectedRisks[resource.Name]
+		if !ok {
+			t.Errorf("Unexpected resource %s", resource.Name)
+			continue
+		}
+		if got := strings.Join(resource.Risks, ", "); got != want {
+			t.Errorf("%s: expected risk %q, got %q", resource.Name, want, got)
+		}
+		if resource.Name == "database1" && resource.Username != "dbmasteruser" {
+			t.Errorf("Expected the master username of database1, got %q", resource.Username)
+		}
+		if resource.Name == "instance2" && strings.Join(resource.OpenPorts, ", ") != "tcp/22 (restricted), tcp/3000-3500 (internet)" {
+			t.Errorf("Unexpected open ports for instance2: %v", resource.OpenPorts)
+		}
+	}
+
+	lootFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/loot/lightsail-ssh-commands.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	expectedLoot := []string{
+		"lightsail download-default-key-pair",
+		"ssh -i LightsailDefaultKeyPair-us-east-1.pem bitnami@1.2.3.4",
+		"ssh -i ops-team.pem ubuntu@2.3.4.4",
+	}
+	for _, expected := range expectedLoot {
+		if !strings.Contains(string(lootFile), expected) {
+			t.Errorf("Expected %s to be in the loot file", expected)
+		}
+	}
+}
@@ -0,0 +1,522 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type LogGroupsModule struct {
+	// General configuration data
+	CloudWatchLogsClient sdk.CloudWatchLogsClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+	// SearchLogs runs FilterLogEvents for SearchTerms over the last logGroupsRecentDays days of every group
+	SearchLogs  bool
+	SearchTerms []string
+	// MinSize skips log groups that store fewer bytes, which keeps accounts with thousands of groups manageable
+	MinSize int64
+
+	// Main module data
+	LogGroups      []LogGroup
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type LogGroup struct {
+	Region      string
+	Name        string
+	Arn         string
+	Retention   string
+	StoredBytes int64
+	KmsKeyID    string
+	LastEvent   time.Time
+	Matches     []LogGroupMatch
+	Finding     string
+	// SubscriptionFilters are the destinations the group's events are streamed to
+	SubscriptionFilters []string
+}
+
+type LogGroupMatch struct {
+	LogStream string
+	Timestamp time.Time
+	Message   string
+}
+
+const (
+	// Groups that received events within this many days are considered in use
+	logGroupsRecentDays = 7
+	// FilterLogEvents can scan a lot of data, so we stop after this many matches per group
+	logGroupsMaxMatches = 25
+)
+
+var defaultLogGroupsSearchTerms = []string{"password", "secret", "token"}
+
+// applicationLogGroupPrefixes are the default log group names of Lambda functions, ECS tasks and API Gateway stages
+var applicationLogGroupPrefixes = []string{
+	"/aws/lambda/",
+	"/ecs/",
+	"/aws/ecs/",
+	"/aws/apigateway/",
+	"API-Gateway-Execution-Logs_",
+}
+
+func (m *LogGroupsModule) PrintLogGroups(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "log-groups"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+	if len(m.SearchTerms) == 0 {
+		m.SearchTerms = defaultLogGroupsSearchTerms
+	}
+
+	fmt.Printf("[%s][%s] Enumerating CloudWatch log groups for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+	if m.SearchLogs {
+		fmt.Printf("[%s][%s] Searching the last %d days of each log group for: %s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), logGroupsRecentDays, strings.Join(m.SearchTerms, ", "))
+	}
+
+	if m.MinSize > 0 {
+		fmt.Printf("[%s][%s] Only including log groups that store at least %d bytes.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.MinSize)
+	}
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan LogGroup)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.LogGroups, func(i, j int) bool {
+		if m.LogGroups[i].Region != m.LogGroups[j].Region {
+			return m.LogGroups[i].Region < m.LogGroups[j].Region
+		}
+		return m.LogGroups[i].Name < m.LogGroups[j].Name
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Name",
+		"Retention",
+		"Stored Bytes",
+		"KMS Key",
+		"Exported To",
+		"Last Event",
+		"Matches",
+		"Finding",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Name",
+			"Retention",
+			"Stored Bytes",
+			"KMS Key",
+			"Exported To",
+			"Last Event",
+			"Matches",
+			"Finding",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Name",
+			"Retention",
+			"Exported To",
+			"Last Event",
+			"Finding",
+		}
+		if m.SearchLogs {
+			tableCols = []string{
+				"Region",
+				"Name",
+				"Retention",
+				"Exported To",
+				"Last Event",
+				"Matches",
+				"Finding",
+			}
+		}
+	}
+
+	// Table rows
+	var flagged int
+	for _, logGroup := range m.LogGroups {
+		lastEvent := "Never"
+		if !logGroup.LastEvent.IsZero() {
+			lastEvent = logGroup.LastEvent.UTC().Format(time.RFC3339)
+		}
+		matches := "-"
+		if m.SearchLogs {
+			matches = strconv.Itoa(len(logGroup.Matches))
+		}
+		finding := logGroup.Finding
+		if finding != "" {
+			flagged++
+			finding = magenta(finding)
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				logGroup.Region,
+				logGroup.Name,
+				logGroup.Retention,
+				strconv.FormatInt(logGroup.StoredBytes, 10),
+				logGroup.KmsKeyID,
+				strings.Join(logGroup.SubscriptionFilters, "\n"),
+				lastEvent,
+				matches,
+				finding,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     "log-groups-search-commands",
+			Contents: m.writeLoot(),
+		})
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     "log-groups-tail-commands",
+			Contents: m.writeTailLoot(),
+		})
+		if m.SearchLogs {
+			if matches := m.writeMatchesLoot(); matches != "" {
+				o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+					Name:     "log-groups-matches",
+					Contents: matches,
+				})
+			}
+		}
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d log groups found, %d of them keep recent events forever.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), flagged)
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No log groups found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *LogGroupsModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan LogGroup) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("logs", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getLogGroupsPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *LogGroupsModule) Receiver(receiver chan LogGroup, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.LogGroups = append(m.LogGroups, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *LogGroupsModule) getLogGroupsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan LogGroup) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	logGroups, err := sdk.CachedCloudWatchLogsDescribeLogGroups(m.CloudWatchLogsClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, group := range logGroups {
+		if m.MinSize > 0 && aws.ToInt64(group.StoredBytes) < m.MinSize {
+			continue
+		}
+		logGroup := LogGroup{
+			Region:      r,
+			Name:        aws.ToString(group.LogGroupName),
+			Arn:         aws.ToString(group.Arn),
+			Retention:   "Never expire",
+			StoredBytes: aws.ToInt64(group.StoredBytes),
+			KmsKeyID:    aws.ToString(group.KmsKeyId),
+		}
+		if group.RetentionInDays != nil {
+			logGroup.Retention = fmt.Sprintf("%d days", aws.ToInt32(group.RetentionInDays))
+		}
+
+		latest, err := sdk.CachedCloudWatchLogsLatestLogStream(m.CloudWatchLogsClient, aws.ToString(m.Caller.Account), r, logGroup.Name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		} else if latest.LastEventTimestamp != nil {
+			logGroup.LastEvent = time.UnixMilli(aws.ToInt64(latest.LastEventTimestamp))
+		}
+
+		subscriptionFilters, err := sdk.CachedCloudWatchLogsDescribeSubscriptionFilters(m.CloudWatchLogsClient, aws.ToString(m.Caller.Account), r, logGroup.Name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+		for _, filter := range subscriptionFilters {
+			logGroup.SubscriptionFilters = append(logGroup.SubscriptionFilters, aws.ToString(filter.DestinationArn))
+		}
+
+		if m.SearchLogs {
+			logGroup.Matches = m.searchLogGroup(r, logGroup.Name)
+		}
+		logGroup.Finding = logGroupFinding(logGroup, time.Now())
+
+		dataReceiver <- logGroup
+	}
+}
+
+// logGroupFinding flags groups that keep everything forever and are still written to, because whatever ends up in
+// them by accident stays readable for good
+func logGroupFinding(logGroup LogGroup, now time.Time) string {
+	if logGroup.Retention != "Never expire" || logGroup.LastEvent.IsZero() {
+		return ""
+	}
+	if now.Sub(logGroup.LastEvent) > logGroupsRecentDays*24*time.Hour {
+		return ""
+	}
+	if len(logGroup.Matches) > 0 {
+		return "Retains forever, recent events match search terms"
+	}
+	return "Retains forever, recent events"
+}
+
+// logGroupsFilterPattern matches events that contain any of the terms
+func logGroupsFilterPattern(terms []string) string {
+	var pattern []string
+	for _, term := range terms {
+		pattern = append(pattern, fmt.Sprintf("?\"%s\"", term))
+	}
+	return strings.Join(pattern, " ")
+}
+
+// searchLogGroup looks for the search terms in the recent events of a log group. The events are deliberately not
+// cached, so they never end up in the on-disk cache.
+func (m *LogGroupsModule) searchLogGroup(r string, logGroupName string) []LogGroupMatch {
+	var matches []LogGroupMatch
+	var PaginationControl *string
+	startTime := time.Now().AddDate(0, 0, -logGroupsRecentDays).UnixMilli()
+
+	for {
+		FilterLogEvents, err := m.CloudWatchLogsClient.FilterLogEvents(
+			context.TODO(),
+			&cloudwatchlogs.FilterLogEventsInput{
+				LogGroupName:  aws.String(logGroupName),
+				FilterPattern: aws.String(logGroupsFilterPattern(m.SearchTerms)),
+				StartTime:     aws.Int64(startTime),
+				NextToken:     PaginationControl,
+			},
+			func(o *cloudwatchlogs.Options) {
+				o.Region = r
+			},
+		)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			return matches
+		}
+
+		for _, event := range FilterLogEvents.Events {
+			matches = append(matches, LogGroupMatch{
+				LogStream: aws.ToString(event.LogStreamName),
+				Timestamp: time.UnixMilli(aws.ToInt64(event.Timestamp)),
+				Message:   strings.TrimSpace(aws.ToString(event.Message)),
+			})
+			if len(matches) >= logGroupsMaxMatches {
+				return matches
+			}
+		}
+
+		// The "NextToken" value is nil when there's no more data to return.
+		if FilterLogEvents.NextToken == nil {
+			break
+		}
+		PaginationControl = FilterLogEvents.NextToken
+	}
+	return matches
+}
+
+func (m *LogGroupsModule) writeLoot() string {
+	var out string
+	out += fmt.Sprintln("#############################################")
+	out += fmt.Sprintln("# Search log groups that keep recent events forever for credentials.")
+	out += fmt.Sprintln("# Set the $profile environment variable to the profile you are going to use, e.g. export profile=dev-prod.")
+	out += fmt.Sprintln("#############################################")
+	out += fmt.Sprintln("")
+	for _, logGroup := range m.LogGroups {
+		if logGroup.Finding == "" {
+			continue
+		}
+		out += fmt.Sprintf("aws --profile $profile --region %s logs filter-log-events --log-group-name %s --filter-pattern '%s' --start-time $(($(date +%%s) - %d*86400))000\n", logGroup.Region, logGroup.Name, logGroupsFilterPattern(m.SearchTerms), logGroupsRecentDays)
+	}
+	return out
+}
+
+// isApplicationLogGroup tells whether a log group is written by Lambda, ECS or API Gateway. Applications tend to log
+// request bodies and connection strings, so these are the groups to read first.
+func isApplicationLogGroup(logGroupName string) bool {
+	for _, prefix := range applicationLogGroupPrefixes {
+		if strings.HasPrefix(logGroupName, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeTailLoot has commands to read every log group, starting with the application log groups
+func (m *LogGroupsModule) writeTailLoot() string {
+	var applicationGroups, otherGroups []LogGroup
+	for _, logGroup := range m.LogGroups {
+		if isApplicationLogGroup(logGroup.Name) {
+			applicationGroups = append(applicationGroups, logGroup)
+		} else {
+			otherGroups = append(otherGroups, logGroup)
+		}
+	}
+
+	var out string
+	out += fmt.Sprintln("#############################################")
+	out += fmt.Sprintln("# Read the latest events of each log group and search it for credentials. Application log groups come first.")
+	out += fmt.Sprintln("# Set the $profile environment variable to the profile you are going to use, e.g. export profile=dev-prod.")
+	out += fmt.Sprintln("#############################################")
+	for _, section := range []struct {
+		title     string
+		logGroups []LogGroup
+	}{
+		{"Application log groups (Lambda, ECS, API Gateway)", applicationGroups},
+		{"Other log groups", otherGroups},
+	} {
+		if len(section.logGroups) == 0 {
+			continue
+		}
+		out += fmt.Sprintf("\n# %s\n", section.title)
+		for _, logGroup := range section.logGroups {
+			out += fmt.Sprintf("aws --profile $profile --region %s logs tail %s --since 1h\n", logGroup.Region, logGroup.Name)
+			out += fmt.Sprintf("aws --profile $profile --region %s logs filter-log-events --log-group-name %s --filter-pattern password\n", logGroup.Region, logGroup.Name)
+		}
+	}
+	return out
+}
+
+// writeMatchesLoot lists the events that matched a search term, grouped by log group
+func (m *LogGroupsModule) writeMatchesLoot() string {
+	var out string
+	for _, logGroup := range m.LogGroups {
+		if len(logGroup.Matches) == 0 {
+			continue
+		}
+		out += fmt.Sprintf("# %s (%s), %d matching events\n", logGroup.Name, logGroup.Region, len(logGroup.Matches))
+		for _, match := range logGroup.Matches {
+			out += fmt.Sprintf("%s %s %s\n", match.Timestamp.UTC().Format(time.RFC3339), match.LogStream, match.Message)
+		}
+		out += "\n"
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+package aws
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestLogGroups(t *testing.T) {
+	m := LogGroupsModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:           3,
+		WrapTable:            false,
+		CloudWatchLogsClient: &sdk.MockedCloudWatchLogsClient{},
+		SearchLogs:           true,
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+	tmpDir := "."
+
+	m.PrintLogGroups(tmpDir, 2)
+
+	expected := map[string]struct {
+		retention string
+		matches   int
+		finding   string
+	}{
+		"/aws/lambda/payments": {retention: "Never expire", matches: 1, finding: "Retains forever, recent events match search terms"},
+		"/ecs/frontend":        {retention: "30 days"},
+		// No events in the last 7 days
+		"/aws/lambda/old-job": {retention: "Never expire"},
+	}
+	if len(m.LogGroups) != len(expected) {
+		t.Fatalf("Expected %d log groups, got %d", len(expected), len(m.LogGroups))
+	}
+	for _, logGroup := range m.LogGroups {
+		want := expected[logGroup.Name]
+		if logGroup.Retention != want.retention || len(logGroup.Matches) != want.matches || logGroup.Finding != want.finding {
+			t.Errorf("Unexpected results for %s: retention %q, %d matches, finding %q", logGroup.Name, logGroup.Retention, len(logGroup.Matches), logGroup.Finding)
+		}
+	}
+
+	lootFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/loot/log-groups-matches.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	if !strings.Contains(string(lootFile), "DEBUG connecting to db with password=Sup3rS3cret!") {
+		t.Errorf("Expected the matching event to be in the loot file")
+	}
+	if strings.Contains(string(lootFile), "charge created") {
+		t.Errorf("Did not expect events without search terms in the loot file")
+	}
+
+	commandsFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/loot/log-groups-search-commands.txt")
+	commandsFile, err := afero.ReadFile(fs, commandsFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", commandsFilePath, err)
+	}
+	expectedCommand := "aws --profile $profile --region us-east-1 logs filter-log-events --log-group-name /aws/lambda/payments --filter-pattern '?\"password\" ?\"secret\" ?\"token\"'"
+	if !strings.Contains(string(commandsFile), expectedCommand) {
+		t.Errorf("Expected %s to be in the loot file", expectedCommand)
+	}
+	if strings.Contains(string(commandsFile), "old-job") {
+		t.Errorf("Did not expect commands for log groups that weren't flagged")
+	}
+	for _, logGroup := range m.LogGroups {
+		if logGroup.Name == "/aws/lambda/payments" && !reflect.DeepEqual(logGroup.SubscriptionFilters, []string{"arn:aws:firehose:us-east-1:123456789012:deliverystream/logs-to-splunk"}) {
+			t.Errorf("Unexpected subscription filters for %s: %v", logGroup.Name, logGroup.SubscriptionFilters)
+		}
+	}
+
+	tailFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/loot/log-groups-tail-commands.txt")
+	tailFile, err := afero.ReadFile(fs, tailFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", tailFilePath, err)
+	}
+	for _, expected := range []string{
+		"aws --profile $profile --region us-east-1 logs tail /aws/lambda/old-job --since 1h",
+		"aws --profile $profile --region us-east-1 logs filter-log-events --log-group-name /ecs/frontend --filter-pattern password",
+	} {
+		if !strings.Contains(string(tailFile), expected) {
+			t.Errorf("Expected %s to be in the loot file", expected)
+		}
+	}
+}
+
+func TestLogGroupsMinSize(t *testing.T) {
+	m := LogGroupsModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:           3,
+		CloudWatchLogsClient: &sdk.MockedCloudWatchLogsClient{},
+		MinSize:              1048576,
+	}
+
+	internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintLogGroups(".", 2)
+
+	var names []string
+	for _, logGroup := range m.LogGroups {
+		names = append(names, logGroup.Name)
+	}
+	// /aws/lambda/old-job only stores 2 KB
+	expected := []string{"/aws/lambda/payments", "/ecs/frontend"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected log groups %v, got %v", expected, names)
+	}
+}
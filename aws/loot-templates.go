@@ -0,0 +1,24 @@
+package aws
+
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/BishopFox/cloudfox/internal/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// lootTemplates are the default loot templates, one <module>.tmpl per module that renders its loot with a
+// utils.LootWriter. Users override them with files of the same name in --loot-template-dir.
+//
+//go:embed loot-templates/*.tmpl
+var lootTemplates embed.FS
+
+func newLootWriter(overrideDir string, modLog *logrus.Entry) utils.LootWriter {
+	defaults, _ := fs.Sub(lootTemplates, "loot-templates")
+	return utils.LootWriter{
+		Defaults:    defaults,
+		OverrideDir: overrideDir,
+		Warn:        modLog.Warnf,
+	}
+}
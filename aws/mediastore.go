@@ -0,0 +1,368 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/aws/policy"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type MediaStoreModule struct {
+	// General configuration data
+	MediaStoreClient sdk.MediaStoreClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Containers     []MediaStoreContainer
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type MediaStoreContainer struct {
+	Region        string
+	Name          string
+	Arn           string
+	Endpoint      string
+	Status        string
+	AccessLogging bool
+	// PublicActions are granted to everyone without a condition, ConditionalActions to everyone under some condition,
+	// like a source IP range
+	PublicActions      []string
+	ConditionalActions []string
+	Findings           []string
+}
+
+const (
+	mediaStorePublicAccess      = "Public access"
+	mediaStoreConditionalAccess = "Public access restricted by a condition"
+)
+
+func (m *MediaStoreModule) PrintMediaStore(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "mediastore"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating MediaStore containers for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan MediaStoreContainer)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.Containers, func(i, j int) bool {
+		if m.Containers[i].Region != m.Containers[j].Region {
+			return m.Containers[i].Region < m.Containers[j].Region
+		}
+		return m.Containers[i].Name < m.Containers[j].Name
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Container",
+		"Endpoint",
+		"Status",
+		"Access Logging",
+		"Public Actions",
+		"Conditional Actions",
+		"Findings",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Container",
+			"Endpoint",
+			"Status",
+			"Access Logging",
+			"Public Actions",
+			"Conditional Actions",
+			"Findings",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Container",
+			"Endpoint",
+			"Public Actions",
+			"Findings",
+		}
+	}
+
+	// Table rows
+	for _, container := range m.Containers {
+		var findings []string
+		for _, finding := range container.Findings {
+			findings = append(findings, magenta(finding))
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				container.Region,
+				container.Name,
+				container.Endpoint,
+				container.Status,
+				fmt.Sprint(container.AccessLogging),
+				strings.Join(container.PublicActions, ", "),
+				strings.Join(container.ConditionalActions, ", "),
+				strings.Join(findings, "\n"),
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     m.output.CallingModule,
+			Contents: m.writeLoot(),
+		})
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d MediaStore containers found, %d open to anonymous requests.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), m.countPublic())
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No MediaStore containers found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *MediaStoreModule) countPublic() int {
+	var count int
+	for _, container := range m.Containers {
+		if len(container.PublicActions) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// writeLoot has curl commands against the data endpoint of the containers that anyone can read, and signed aws cli
+// commands for every container. ListItems only lists one folder, pass a folder with ?Path= to go deeper.
+func (m *MediaStoreModule) writeLoot() string {
+	var out string
+	out += "#############################################\n"
+	out += "# List and download the objects in the MediaStore containers. Public containers answer plain curl requests,\n"
+	out += "# the others need credentials that the container policy allows.\n"
+	out += "#############################################\n"
+
+	for _, container := range m.Containers {
+		if container.Endpoint == "" {
+			continue
+		}
+		out += fmt.Sprintf("\n# %s in %s\n", container.Name, container.Region)
+		if len(container.PublicActions) > 0 || len(container.ConditionalActions) > 0 {
+			out += fmt.Sprintf("curl -s '%s/?MaxResults=1000'\n", container.Endpoint)
+			out += fmt.Sprintf("curl -s -O '%s/<path>'\n", container.Endpoint)
+		}
+		out += fmt.Sprintf("aws --profile $profile --region %s mediastore-data list-items --endpoint-url %s\n", container.Region, container.Endpoint)
+		out += fmt.Sprintf("aws --profile $profile --region %s mediastore-data get-object --endpoint-url %s --path <path> <outfile>\n", container.Region, container.Endpoint)
+	}
+	return out
+}
+
+func (m *MediaStoreModule) Receiver(receiver chan MediaStoreContainer, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Containers = append(m.Containers, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *MediaStoreModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan MediaStoreContainer) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("mediastore", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getContainersPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *MediaStoreModule) getContainersPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan MediaStoreContainer) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	containers, err := sdk.CachedMediaStoreListContainers(m.MediaStoreClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, c := range containers {
+		container := MediaStoreContainer{
+			Region:        r,
+			Name:          aws.ToString(c.Name),
+			Arn:           aws.ToString(c.ARN),
+			Endpoint:      aws.ToString(c.Endpoint),
+			Status:        string(c.Status),
+			AccessLogging: aws.ToBool(c.AccessLoggingEnabled),
+		}
+
+		containerPolicy, err := sdk.CachedMediaStoreGetContainerPolicy(m.MediaStoreClient, aws.ToString(m.Caller.Account), r, container.Name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		} else if containerPolicy != "" {
+			parsedPolicy, err := policy.ParseJSONPolicy([]byte(containerPolicy))
+			if err != nil {
+				m.modLog.Error(fmt.Sprintf("parsing the container policy of %s: %s", container.Name, err))
+			} else {
+				container.PublicActions, container.ConditionalActions = mediaStorePublicActions(parsedPolicy)
+			}
+		}
+
+		if len(container.PublicActions) > 0 {
+			container.Findings = append(container.Findings, mediaStorePublicAccess)
+		} else if len(container.ConditionalActions) > 0 {
+			container.Findings = append(container.Findings, mediaStoreConditionalAccess)
+		}
+		dataReceiver <- container
+	}
+}
+
+// mediaStorePublicActions returns the actions that Allow statements with a wildcard principal grant, split into the
+// ones without a condition and the ones only granted under a condition. The console's public read policy requires
+// aws:SecureTransport, which doesn't limit who can read, so that condition alone still counts as public.
+func mediaStorePublicActions(containerPolicy policy.Policy) ([]string, []string) {
+	var public, conditional []string
+	for _, statement := range containerPolicy.Statement {
+		if !statement.IsAllow() || !statement.Principal.IsPublic() {
+			continue
+		}
+		actions := []string(statement.Action)
+		if len(statement.NotAction) > 0 {
+			actions = []string{fmt.Sprintf("* except %s", strings.Join(statement.NotAction, ", "))}
+		}
+		for _, action := range actions {
+			if !mediaStoreConditionRestricts(statement.Condition) {
+				if !internal.Contains(action, public) {
+					public = append(public, action)
+				}
+			} else if !internal.Contains(action, conditional) {
+				conditional = append(conditional, action)
+			}
+		}
+	}
+	// An action granted without a condition by one statement is public, whatever the others say
+	var onlyConditional []string
+	for _, action := range conditional {
+		if !internal.Contains(action, public) {
+			onlyConditional = append(onlyConditional, action)
+		}
+	}
+	return public, onlyConditional
+}
+
+func mediaStoreConditionRestricts(condition policy.PolicyStatementCondition) bool {
+	for _, keys := range condition {
+		for key := range keys {
+			if !strings.EqualFold(key, "aws:SecureTransport") {
+				return true
+			}
+		}
+	}
+	return false
+}
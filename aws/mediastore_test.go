@@ -0,0 +1,68 @@
+package aws
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestMediaStore(t *testing.T) {
+	m := MediaStoreModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:       3,
+		MediaStoreClient: &sdk.MockedMediaStoreClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintMediaStore(".", 2)
+
+	expectedFindings := map[string][]string{
+		"vod-assets":       {mediaStorePublicAccess},
+		"live-ingest":      {mediaStoreConditionalAccess},
+		"internal-archive": nil,
+	}
+	if len(m.Containers) != len(expectedFindings) {
+		t.Fatalf("Expected %d containers, got %d", len(expectedFindings), len(m.Containers))
+	}
+	for _, container := range m.Containers {
+		if !reflect.DeepEqual(container.Findings, expectedFindings[container.Name]) {
+			t.Errorf("%s: expected findings %v, got %v", container.Name, expectedFindings[container.Name], container.Findings)
+		}
+		if container.Name == "vod-assets" && !reflect.DeepEqual(container.PublicActions, []string{"mediastore:GetObject", "mediastore:DescribeObject", "mediastore:ListItems"}) {
+			t.Errorf("Unexpected public actions for vod-assets: %v", container.PublicActions)
+		}
+	}
+
+	lootFilePath := filepath.Join(".", "cloudfox-output/aws/unittesting-123456789012/loot/mediastore.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	expectedCommands := []string{
+		"curl -s 'https://vod-assets1a2b3c.data.mediastore.us-east-1.amazonaws.com/?MaxResults=1000'",
+		"aws --profile $profile --region us-east-1 mediastore-data list-items --endpoint-url https://internal-archive1a2b3c.data.mediastore.us-east-1.amazonaws.com",
+	}
+	for _, expected := range expectedCommands {
+		if !strings.Contains(string(lootFile), expected) {
+			t.Errorf("Expected %s to be in the loot file", expected)
+		}
+	}
+	// internal-archive has no policy, so plain requests are denied
+	if strings.Contains(string(lootFile), "curl -s 'https://internal-archive") {
+		t.Errorf("Did not expect a curl command for internal-archive")
+	}
+}
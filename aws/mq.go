@@ -0,0 +1,311 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	mqTypes "github.com/aws/aws-sdk-go-v2/service/mq/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type AmazonMQModule struct {
+	// General configuration data
+	MQClient sdk.MQClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Brokers        []MQBroker
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type MQBroker struct {
+	Region        string
+	Name          string
+	ID            string
+	Engine        string
+	EngineVersion string
+	ConsoleURLs   []string
+	Endpoints     []string
+	AuthStrategy  string
+	Users         []string
+	Public        string
+}
+
+func (m *AmazonMQModule) PrintMQBrokers(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "mq"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating Amazon MQ brokers for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan MQBroker)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Name",
+		"Engine",
+		"Version",
+		"Console URL",
+		"Endpoints",
+		"Auth",
+		"Users",
+		"Public?",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Name",
+			"Engine",
+			"Version",
+			"Console URL",
+			"Endpoints",
+			"Auth",
+			"Users",
+			"Public?",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Name",
+			"Engine",
+			"Console URL",
+			"Public?",
+		}
+	}
+
+	// Table rows
+	for i := range m.Brokers {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				m.Brokers[i].Region,
+				m.Brokers[i].Name,
+				m.Brokers[i].Engine,
+				m.Brokers[i].EngineVersion,
+				strings.Join(m.Brokers[i].ConsoleURLs, "\n"),
+				strings.Join(m.Brokers[i].Endpoints, "\n"),
+				m.Brokers[i].AuthStrategy,
+				strings.Join(m.Brokers[i].Users, ", "),
+				m.Brokers[i].Public,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+
+		loot := m.writeLoot()
+		if loot != "" {
+			o.Loot.DirectoryName = o.Table.DirectoryName
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     "mq-default-credentials",
+				Contents: loot,
+			})
+		}
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d brokers found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body))
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No brokers found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+// writeLoot creates curl commands that test the management API of each broker for the default admin/admin credentials
+func (m *AmazonMQModule) writeLoot() string {
+	var out string
+	for _, broker := range m.Brokers {
+		if len(broker.ConsoleURLs) == 0 {
+			continue
+		}
+		out += fmt.Sprintf("# %s (%s, public: %s)\n", broker.Name, broker.Engine, broker.Public)
+		for _, consoleURL := range broker.ConsoleURLs {
+			consoleURL = strings.TrimSuffix(consoleURL, "/")
+			// DescribeBroker returns RabbitMQ and ActiveMQ, the SDK's enum values are upper case
+			switch {
+			case strings.EqualFold(broker.Engine, string(mqTypes.EngineTypeRabbitmq)):
+				out += fmt.Sprintf("curl -s -u admin:admin %s/api/whoami\n", consoleURL)
+				out += fmt.Sprintf("curl -s -u admin:admin %s/api/overview\n", consoleURL)
+			case strings.EqualFold(broker.Engine, string(mqTypes.EngineTypeActivemq)):
+				out += fmt.Sprintf("curl -s -u admin:admin %s/admin/\n", consoleURL)
+				out += fmt.Sprintf("curl -s -u admin:admin -H \"Origin: %s\" %s/api/jolokia/read/org.apache.activemq:type=Broker,brokerName=*\n", consoleURL, consoleURL)
+			}
+		}
+		out += "\n"
+	}
+	return out
+}
+
+func (m *AmazonMQModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan MQBroker) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("mq", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getMQBrokersPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *AmazonMQModule) Receiver(receiver chan MQBroker, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Brokers = append(m.Brokers, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *AmazonMQModule) getMQBrokersPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan MQBroker) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	brokers, err := sdk.CachedMQListBrokers(m.MQClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, summary := range brokers {
+		broker, err := sdk.CachedMQDescribeBroker(m.MQClient, aws.ToString(m.Caller.Account), r, aws.ToString(summary.BrokerId))
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+
+		var consoleURLs, endpoints, users []string
+		for _, instance := range broker.BrokerInstances {
+			if instance.ConsoleURL != nil {
+				consoleURLs = append(consoleURLs, aws.ToString(instance.ConsoleURL))
+			}
+			endpoints = append(endpoints, instance.Endpoints...)
+		}
+		for _, user := range broker.Users {
+			users = append(users, aws.ToString(user.Username))
+		}
+
+		public := "No"
+		if aws.ToBool(broker.PubliclyAccessible) {
+			public = "Yes"
+		}
+
+		dataReceiver <- MQBroker{
+			Region:        r,
+			Name:          aws.ToString(broker.BrokerName),
+			ID:            aws.ToString(broker.BrokerId),
+			Engine:        string(broker.EngineType),
+			EngineVersion: aws.ToString(broker.EngineVersion),
+			ConsoleURLs:   consoleURLs,
+			Endpoints:     endpoints,
+			AuthStrategy:  string(broker.AuthenticationStrategy),
+			Users:         users,
+			Public:        public,
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package aws
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestMQBrokers(t *testing.T) {
+
+	m := AmazonMQModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines: 3,
+		WrapTable:  false,
+		MQClient:   &sdk.MockedMQClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+	tmpDir := "."
+
+	m.PrintMQBrokers(tmpDir, 2)
+
+	lootFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/loot/mq-default-credentials.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+
+	expectedResults := []string{
+		"curl -s -u admin:admin https://b-1111.mq.us-east-1.amazonaws.com/api/overview",
+		"curl -s -u admin:admin https://b-2222-1.mq.us-east-1.amazonaws.com:8162/admin/",
+	}
+	for _, expected := range expectedResults {
+		if !strings.Contains(string(lootFile), expected) {
+			t.Errorf("Expected %s to be in the loot file", expected)
+		}
+	}
+
+	for _, broker := range m.Brokers {
+		if broker.Name == "broker1" && broker.Public != "Yes" {
+			t.Errorf("Expected broker1 to be flagged as public")
+		}
+	}
+}
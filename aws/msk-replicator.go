@@ -0,0 +1,415 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	kafkaTypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type MSKReplicatorModule struct {
+	// General configuration data
+	KafkaClient         sdk.KafkaClientInterface
+	IAMClient           sdk.AWSIAMClientInterface
+	OrganizationsClient sdk.OrganizationsClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Replications   []MSKReplication
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+
+	// Accounts in the caller's organization, if the caller is allowed to list them
+	orgAccounts      []string
+	orgAccountsKnown bool
+}
+
+// MSKReplication is one direction of data flow handled by a replicator. Bidirectional replicators have two.
+type MSKReplication struct {
+	Region             string
+	ReplicatorName     string
+	ReplicatorArn      string
+	State              string
+	SourceCluster      string
+	SourceAccount      string
+	SourceRegion       string
+	TargetCluster      string
+	TargetAccount      string
+	TargetRegion       string
+	Topics             []string
+	Role               string
+	RoleWildcardAccess []string
+	Finding            string
+}
+
+const (
+	mskReplicationOutsideOrganization = "Replicates to an account outside the organization"
+	mskReplicationCrossAccount        = "Replicates to another account"
+)
+
+// Data plane actions the replication role can perform on any cluster and topic, not just the ones it replicates
+var mskReplicatorRoleActions = []string{
+	"kafka-cluster:Connect",
+	"kafka-cluster:ReadData",
+	"kafka-cluster:WriteData",
+	"kafka-cluster:CreateTopic",
+	"kafka-cluster:AlterCluster",
+}
+
+func (m *MSKReplicatorModule) PrintMSKReplicators(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "msk-replicator"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating MSK replicators for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	// Only the management account and delegated admins can list the organization's accounts. Without them, a target
+	// in another account can't be told apart from one outside the organization.
+	if m.OrganizationsClient != nil {
+		accounts, err := sdk.CachedOrganizationsListAccounts(m.OrganizationsClient, aws.ToString(m.Caller.Account))
+		if err != nil {
+			m.modLog.Error(err.Error())
+		} else {
+			for _, account := range accounts {
+				m.orgAccounts = append(m.orgAccounts, aws.ToString(account.Id))
+			}
+			m.orgAccountsKnown = true
+		}
+	}
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan MSKReplication)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.Replications, func(i, j int) bool {
+		if m.Replications[i].Region != m.Replications[j].Region {
+			return m.Replications[i].Region < m.Replications[j].Region
+		}
+		if m.Replications[i].ReplicatorName != m.Replications[j].ReplicatorName {
+			return m.Replications[i].ReplicatorName < m.Replications[j].ReplicatorName
+		}
+		return m.Replications[i].SourceCluster < m.Replications[j].SourceCluster
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Replicator",
+		"State",
+		"Source Cluster",
+		"Source Account",
+		"Source Region",
+		"Target Cluster",
+		"Target Account",
+		"Target Region",
+		"Topics",
+		"Role",
+		"Role Wildcard Access",
+		"Finding",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Replicator",
+			"State",
+			"Source Cluster",
+			"Source Account",
+			"Source Region",
+			"Target Cluster",
+			"Target Account",
+			"Target Region",
+			"Topics",
+			"Role",
+			"Role Wildcard Access",
+			"Finding",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Replicator",
+			"Source Cluster",
+			"Target Cluster",
+			"Target Account",
+			"Target Region",
+			"Role Wildcard Access",
+			"Finding",
+		}
+	}
+
+	// Table rows
+	for i := range m.Replications {
+		finding := m.Replications[i].Finding
+		if finding != "" {
+			finding = magenta(finding)
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				m.Replications[i].Region,
+				m.Replications[i].ReplicatorName,
+				m.Replications[i].State,
+				m.Replications[i].SourceCluster,
+				m.Replications[i].SourceAccount,
+				m.Replications[i].SourceRegion,
+				m.Replications[i].TargetCluster,
+				m.Replications[i].TargetAccount,
+				m.Replications[i].TargetRegion,
+				strings.Join(m.Replications[i].Topics, ", "),
+				m.Replications[i].Role,
+				strings.Join(m.Replications[i].RoleWildcardAccess, ", "),
+				finding,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s replication flows found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No MSK replicators found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *MSKReplicatorModule) Receiver(receiver chan MSKReplication, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Replications = append(m.Replications, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *MSKReplicatorModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan MSKReplication) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("kafka", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getReplicatorsPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *MSKReplicatorModule) getReplicatorsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan MSKReplication) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	replicators, err := sdk.CachedKafkaListReplicators(m.KafkaClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, summary := range replicators {
+		// Cross-region replicators also show up as a reference in the source cluster's region. Only report them
+		// once, from the region they run in.
+		if aws.ToBool(summary.IsReplicatorReference) {
+			continue
+		}
+		replicator, err := sdk.CachedKafkaDescribeReplicator(m.KafkaClient, aws.ToString(m.Caller.Account), r, aws.ToString(summary.ReplicatorArn))
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+
+		role := aws.ToString(replicator.ServiceExecutionRoleArn)
+		roleWildcardAccess := m.replicatorRoleWildcardAccess(role)
+
+		for _, replication := range mskReplicationFlows(replicator.KafkaClusters, replicator.ReplicationInfoList) {
+			replication.Region = r
+			replication.ReplicatorName = aws.ToString(replicator.ReplicatorName)
+			replication.ReplicatorArn = aws.ToString(replicator.ReplicatorArn)
+			replication.State = string(replicator.ReplicatorState)
+			replication.Role = role
+			replication.RoleWildcardAccess = roleWildcardAccess
+			replication.Finding = mskReplicationFinding(replication, aws.ToString(m.Caller.Account), m.orgAccounts, m.orgAccountsKnown)
+			dataReceiver <- replication
+		}
+	}
+}
+
+// replicatorRoleWildcardAccess returns the Kafka data plane actions the replication role is allowed to perform on
+// every resource. A role that is scoped to the replicated clusters and topics won't have any.
+func (m *MSKReplicatorModule) replicatorRoleWildcardAccess(role string) []string {
+	var allowed []string
+	if role == "" {
+		return allowed
+	}
+	evaluationResults, err := sdk.CachedIamSimulatePrincipalPolicy(m.IAMClient, aws.ToString(m.Caller.Account), aws.String(role), mskReplicatorRoleActions, []string{"*"})
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return allowed
+	}
+	for _, result := range evaluationResults {
+		if result.EvalDecision == iamTypes.PolicyEvaluationDecisionTypeAllowed {
+			allowed = append(allowed, aws.ToString(result.EvalActionName))
+		}
+	}
+	sort.Strings(allowed)
+	return allowed
+}
+
+// mskReplicationFlows resolves the cluster aliases a replicator's replication info refers to, and returns one
+// replication per source and target cluster pair
+func mskReplicationFlows(clusters []kafkaTypes.KafkaClusterDescription, replicationInfoList []kafkaTypes.ReplicationInfoDescription) []MSKReplication {
+	var replications []MSKReplication
+	clustersByAlias := make(map[string]kafkaTypes.KafkaClusterDescription)
+	for _, cluster := range clusters {
+		clustersByAlias[aws.ToString(cluster.KafkaClusterAlias)] = cluster
+	}
+
+	for _, replicationInfo := range replicationInfoList {
+		var replication MSKReplication
+		replication.SourceCluster, replication.SourceAccount, replication.SourceRegion = mskClusterLocation(aws.ToString(replicationInfo.SourceKafkaClusterAlias), clustersByAlias)
+		replication.TargetCluster, replication.TargetAccount, replication.TargetRegion = mskClusterLocation(aws.ToString(replicationInfo.TargetKafkaClusterAlias), clustersByAlias)
+		if replicationInfo.TopicReplication != nil {
+			replication.Topics = replicationInfo.TopicReplication.TopicsToReplicate
+		}
+		replications = append(replications, replication)
+	}
+	return replications
+}
+
+// mskClusterLocation returns the name, account and region of the MSK cluster behind an alias. Clusters that can't be
+// resolved are reported by their alias.
+func mskClusterLocation(alias string, clustersByAlias map[string]kafkaTypes.KafkaClusterDescription) (string, string, string) {
+	cluster, ok := clustersByAlias[alias]
+	if !ok || cluster.AmazonMskCluster == nil {
+		return alias, "Unknown", "Unknown"
+	}
+	parsedArn, err := arn.Parse(aws.ToString(cluster.AmazonMskCluster.MskClusterArn))
+	if err != nil {
+		return alias, "Unknown", "Unknown"
+	}
+	// cluster/<name>/<uuid>
+	name := parsedArn.Resource
+	parts := strings.Split(parsedArn.Resource, "/")
+	if len(parts) > 1 {
+		name = parts[1]
+	}
+	return name, parsedArn.AccountID, parsedArn.Region
+}
+
+// mskReplicationFinding flags replication into other accounts. Data that flows to a cluster outside the organization
+// leaves the organization's control entirely, and whoever controls the target cluster can read every replicated
+// topic.
+func mskReplicationFinding(replication MSKReplication, accountID string, orgAccounts []string, orgAccountsKnown bool) string {
+	if replication.TargetAccount == accountID || replication.TargetAccount == "Unknown" {
+		return ""
+	}
+	if orgAccountsKnown && !internal.Contains(replication.TargetAccount, orgAccounts) {
+		return mskReplicationOutsideOrganization
+	}
+	return mskReplicationCrossAccount
+}
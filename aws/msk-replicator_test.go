@@ -0,0 +1,99 @@
+package aws
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// mockedMSKReplicatorIAMClient only lets the partner feed's replication role touch every cluster
+type mockedMSKReplicatorIAMClient struct {
+	sdk.MockedIAMClient
+}
+
+func (c *mockedMSKReplicatorIAMClient) SimulatePrincipalPolicy(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error) {
+	decision := iamTypes.PolicyEvaluationDecisionTypeImplicitDeny
+	if strings.HasSuffix(aws.ToString(params.PolicySourceArn), "role/partner-feed-replication") {
+		decision = iamTypes.PolicyEvaluationDecisionTypeAllowed
+	}
+	var results []iamTypes.EvaluationResult
+	for _, action := range params.ActionNames {
+		results = append(results, iamTypes.EvaluationResult{
+			EvalActionName: aws.String(action),
+			EvalDecision:   decision,
+		})
+	}
+	return &iam.SimulatePrincipalPolicyOutput{EvaluationResults: results}, nil
+}
+
+func TestMSKReplicators(t *testing.T) {
+	m := MSKReplicatorModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:          3,
+		KafkaClient:         &sdk.MockedKafkaClient{},
+		IAMClient:           &mockedMSKReplicatorIAMClient{},
+		OrganizationsClient: &sdk.MockedOrgClient{},
+	}
+
+	internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintMSKReplicators(".", 2)
+
+	type replication struct {
+		replicator string
+		source     string
+		target     string
+		account    string
+		finding    string
+	}
+	expected := []replication{
+		{"analytics-share", "analytics", "events", "123456789012", ""},
+		{"analytics-share", "events", "analytics", "222222222222", mskReplicationCrossAccount},
+		{"orders-dr", "orders", "orders-standby", "123456789012", ""},
+		{"partner-feed", "events", "ingest", "999999999999", mskReplicationOutsideOrganization},
+	}
+	var got []replication
+	for _, r := range m.Replications {
+		got = append(got, replication{r.ReplicatorName, r.SourceCluster, r.TargetCluster, r.TargetAccount, r.Finding})
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Expected replications %v, got %v", expected, got)
+	}
+
+	for _, r := range m.Replications {
+		if r.ReplicatorName == "partner-feed" {
+			if !reflect.DeepEqual(r.Topics, []string{"orders.*"}) {
+				t.Errorf("Expected the partner feed to replicate orders.*, got %v", r.Topics)
+			}
+			if len(r.RoleWildcardAccess) != len(mskReplicatorRoleActions) {
+				t.Errorf("Expected the partner feed role to have wildcard access, got %v", r.RoleWildcardAccess)
+			}
+		} else if len(r.RoleWildcardAccess) != 0 {
+			t.Errorf("Expected no wildcard access for %s, got %v", r.ReplicatorName, r.RoleWildcardAccess)
+		}
+		if r.ReplicatorName == "orders-dr" && r.SourceRegion != "us-east-2" {
+			t.Errorf("Expected orders-dr to replicate from us-east-2, got %s", r.SourceRegion)
+		}
+	}
+}
+
+func TestMSKReplicationFindingWithoutOrganization(t *testing.T) {
+	replication := MSKReplication{TargetAccount: "999999999999"}
+	if finding := mskReplicationFinding(replication, "123456789012", nil, false); finding != mskReplicationCrossAccount {
+		t.Errorf("Expected %q when the organization's accounts are unknown, got %q", mskReplicationCrossAccount, finding)
+	}
+}
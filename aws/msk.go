@@ -0,0 +1,437 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	kafkaTypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type MSKModule struct {
+	// General configuration data
+	KafkaClient sdk.KafkaClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Clusters       []MSKCluster
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type MSKCluster struct {
+	Region       string
+	Name         string
+	Arn          string
+	Type         string
+	State        string
+	KafkaVersion string
+	Brokers      string
+	InstanceType string
+	// PublicAccess is the ConnectivityInfo.PublicAccess.Type of the broker nodes, DISABLED unless someone turned it on
+	PublicAccess     string
+	Authentication   []string
+	BootstrapBrokers []MSKBootstrapBrokers
+	Risks            []string
+}
+
+// MSKBootstrapBrokers is one of the bootstrap broker strings of a cluster, there is one per authentication method
+type MSKBootstrapBrokers struct {
+	Authentication string
+	Public         bool
+	Brokers        string
+}
+
+const mskPublicAccessDisabled = "DISABLED"
+
+func (m *MSKModule) PrintMSK(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "msk"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating MSK clusters for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan MSKCluster)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.Clusters, func(i, j int) bool {
+		if m.Clusters[i].Region != m.Clusters[j].Region {
+			return m.Clusters[i].Region < m.Clusters[j].Region
+		}
+		return m.Clusters[i].Name < m.Clusters[j].Name
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Name",
+		"Arn",
+		"Type",
+		"State",
+		"Kafka Version",
+		"Brokers",
+		"Instance Type",
+		"Public Access",
+		"Authentication",
+		"Risk",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Name",
+			"Arn",
+			"Type",
+			"State",
+			"Kafka Version",
+			"Brokers",
+			"Instance Type",
+			"Public Access",
+			"Authentication",
+			"Risk",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Name",
+			"Type",
+			"State",
+			"Brokers",
+			"Public Access",
+			"Authentication",
+			"Risk",
+		}
+	}
+
+	// Table rows
+	var public int
+	for i := range m.Clusters {
+		if m.Clusters[i].isPublic() {
+			public++
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				m.Clusters[i].Region,
+				m.Clusters[i].Name,
+				m.Clusters[i].Arn,
+				m.Clusters[i].Type,
+				m.Clusters[i].State,
+				m.Clusters[i].KafkaVersion,
+				m.Clusters[i].Brokers,
+				m.Clusters[i].InstanceType,
+				m.Clusters[i].PublicAccess,
+				strings.Join(m.Clusters[i].Authentication, ", "),
+				strings.Join(m.Clusters[i].Risks, ", "),
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		if loot := m.writeLoot(); loot != "" {
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     "msk-bootstrap-brokers",
+				Contents: loot,
+			})
+		}
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d MSK clusters found, %d of them publicly accessible.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), public)
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No MSK clusters found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *MSKModule) Receiver(receiver chan MSKCluster, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Clusters = append(m.Clusters, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *MSKModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan MSKCluster) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("kafka", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getClustersPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+// getClustersPerRegion lists the clusters with ListClustersV2, which includes serverless clusters, and ListClusters,
+// which older read-only policies still allow when they don't grant kafka:ListClustersV2
+func (m *MSKModule) getClustersPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan MSKCluster) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	var clusters []MSKCluster
+	seen := make(map[string]bool)
+	clustersV2, err := sdk.CachedKafkaListClustersV2(m.KafkaClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	for _, cluster := range clustersV2 {
+		seen[aws.ToString(cluster.ClusterArn)] = true
+		clusters = append(clusters, analyzeMSKClusterV2(cluster))
+	}
+	clustersV1, err := sdk.CachedKafkaListClusters(m.KafkaClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	for _, cluster := range clustersV1 {
+		if seen[aws.ToString(cluster.ClusterArn)] {
+			continue
+		}
+		clusters = append(clusters, analyzeMSKCluster(cluster))
+	}
+
+	for _, cluster := range clusters {
+		cluster.Region = r
+		brokers, err := sdk.CachedKafkaGetBootstrapBrokers(m.KafkaClient, aws.ToString(m.Caller.Account), r, cluster.Arn)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		} else {
+			cluster.BootstrapBrokers = mskBootstrapBrokers(brokers.BootstrapBrokerString, brokers.BootstrapBrokerStringTls, brokers.BootstrapBrokerStringSaslScram, brokers.BootstrapBrokerStringSaslIam, brokers.BootstrapBrokerStringPublicTls, brokers.BootstrapBrokerStringPublicSaslScram, brokers.BootstrapBrokerStringPublicSaslIam)
+		}
+		dataReceiver <- cluster
+	}
+}
+
+func analyzeMSKClusterV2(cluster kafkaTypes.Cluster) MSKCluster {
+	result := MSKCluster{
+		Name:         aws.ToString(cluster.ClusterName),
+		Arn:          aws.ToString(cluster.ClusterArn),
+		State:        string(cluster.State),
+		KafkaVersion: "-",
+		Brokers:      "-",
+		InstanceType: "-",
+		PublicAccess: mskPublicAccessDisabled,
+	}
+	switch {
+	case cluster.Provisioned != nil:
+		result.Type = "Provisioned"
+		analyzeMSKProvisioned(&result, cluster.Provisioned.BrokerNodeGroupInfo, cluster.Provisioned.NumberOfBrokerNodes, cluster.Provisioned.CurrentBrokerSoftwareInfo, cluster.Provisioned.ClientAuthentication)
+	case cluster.Serverless != nil:
+		// Serverless clusters can't be made public and only support IAM authentication
+		result.Type = "Serverless"
+		if auth := cluster.Serverless.ClientAuthentication; auth != nil && auth.Sasl != nil && auth.Sasl.Iam != nil && aws.ToBool(auth.Sasl.Iam.Enabled) {
+			result.Authentication = append(result.Authentication, "IAM")
+		}
+	default:
+		result.Type = string(cluster.ClusterType)
+	}
+	return result
+}
+
+func analyzeMSKCluster(cluster kafkaTypes.ClusterInfo) MSKCluster {
+	result := MSKCluster{
+		Name:         aws.ToString(cluster.ClusterName),
+		Arn:          aws.ToString(cluster.ClusterArn),
+		Type:         "Provisioned",
+		State:        string(cluster.State),
+		KafkaVersion: "-",
+		Brokers:      "-",
+		InstanceType: "-",
+		PublicAccess: mskPublicAccessDisabled,
+	}
+	analyzeMSKProvisioned(&result, cluster.BrokerNodeGroupInfo, cluster.NumberOfBrokerNodes, cluster.CurrentBrokerSoftwareInfo, cluster.ClientAuthentication)
+	return result
+}
+
+// analyzeMSKProvisioned fills in the broker details of a provisioned cluster and flags public and unauthenticated
+// access. Both ListClusters and ListClustersV2 return these, in different places.
+func analyzeMSKProvisioned(result *MSKCluster, brokerNodeGroup *kafkaTypes.BrokerNodeGroupInfo, numberOfBrokers *int32, software *kafkaTypes.BrokerSoftwareInfo, auth *kafkaTypes.ClientAuthentication) {
+	if numberOfBrokers != nil {
+		result.Brokers = strconv.Itoa(int(aws.ToInt32(numberOfBrokers)))
+	}
+	if software != nil && software.KafkaVersion != nil {
+		result.KafkaVersion = aws.ToString(software.KafkaVersion)
+	}
+	if brokerNodeGroup != nil {
+		result.InstanceType = aws.ToString(brokerNodeGroup.InstanceType)
+		if brokerNodeGroup.ConnectivityInfo != nil && brokerNodeGroup.ConnectivityInfo.PublicAccess != nil && brokerNodeGroup.ConnectivityInfo.PublicAccess.Type != nil {
+			result.PublicAccess = aws.ToString(brokerNodeGroup.ConnectivityInfo.PublicAccess.Type)
+		}
+	}
+	if result.isPublic() {
+		result.Risks = append(result.Risks, "Publicly accessible")
+	}
+
+	if auth == nil {
+		// Clusters created before client authentication existed accept anyone who reaches the brokers
+		result.Authentication = append(result.Authentication, "Unauthenticated")
+		result.Risks = append(result.Risks, "Unauthenticated access")
+		return
+	}
+	if auth.Sasl != nil && auth.Sasl.Iam != nil && aws.ToBool(auth.Sasl.Iam.Enabled) {
+		result.Authentication = append(result.Authentication, "IAM")
+	}
+	if auth.Sasl != nil && auth.Sasl.Scram != nil && aws.ToBool(auth.Sasl.Scram.Enabled) {
+		result.Authentication = append(result.Authentication, "SCRAM")
+	}
+	if auth.Tls != nil && aws.ToBool(auth.Tls.Enabled) {
+		result.Authentication = append(result.Authentication, "TLS")
+	}
+	if auth.Unauthenticated != nil && aws.ToBool(auth.Unauthenticated.Enabled) {
+		result.Authentication = append(result.Authentication, "Unauthenticated")
+		result.Risks = append(result.Risks, "Unauthenticated access")
+	}
+}
+
+func (c MSKCluster) isPublic() bool {
+	return c.PublicAccess != "" && c.PublicAccess != mskPublicAccessDisabled
+}
+
+// mskBootstrapBrokers labels the non-empty broker strings of GetBootstrapBrokers, in the order of its output
+func mskBootstrapBrokers(plaintext, tls, saslScram, saslIam, publicTls, publicSaslScram, publicSaslIam *string) []MSKBootstrapBrokers {
+	var brokers []MSKBootstrapBrokers
+	for _, b := range []MSKBootstrapBrokers{
+		{Authentication: "Plaintext", Brokers: aws.ToString(plaintext)},
+		{Authentication: "TLS", Brokers: aws.ToString(tls)},
+		{Authentication: "SASL/SCRAM", Brokers: aws.ToString(saslScram)},
+		{Authentication: "SASL/IAM", Brokers: aws.ToString(saslIam)},
+		{Authentication: "TLS", Public: true, Brokers: aws.ToString(publicTls)},
+		{Authentication: "SASL/SCRAM", Public: true, Brokers: aws.ToString(publicSaslScram)},
+		{Authentication: "SASL/IAM", Public: true, Brokers: aws.ToString(publicSaslIam)},
+	} {
+		if b.Brokers != "" {
+			brokers = append(brokers, b)
+		}
+	}
+	return brokers
+}
+
+func (m *MSKModule) writeLoot() string {
+	var out string
+	for _, cluster := range m.Clusters {
+		if len(cluster.BootstrapBrokers) == 0 {
+			continue
+		}
+		out += fmt.Sprintf("# Cluster: %s (%s)\n", cluster.Name, cluster.Region)
+		if len(cluster.Risks) > 0 {
+			out += fmt.Sprintf("# Risk: %s\n", strings.Join(cluster.Risks, ", "))
+		}
+		for _, brokers := range cluster.BootstrapBrokers {
+			access := "private"
+			if brokers.Public {
+				access = "public"
+			}
+			out += fmt.Sprintf("# %s, %s\n", brokers.Authentication, access)
+			out += brokers.Brokers + "\n"
+		}
+		out += "\n"
+	}
+	return out
+}
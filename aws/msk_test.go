@@ -0,0 +1,82 @@
+package aws
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestMSK(t *testing.T) {
+	m := MSKModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:  3,
+		WrapTable:   false,
+		KafkaClient: &sdk.MockedKafkaClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+	tmpDir := "."
+
+	m.PrintMSK(tmpDir, 2)
+
+	type expectedCluster struct {
+		Type           string
+		Brokers        string
+		PublicAccess   string
+		Authentication string
+		Risks          string
+	}
+	// ListClusters returns payments and events again, they must only show up once
+	expectedClusters := map[string]expectedCluster{
+		"payments":  {Type: "Provisioned", Brokers: "3", PublicAccess: "SERVICE_PROVIDED_EIPS", Authentication: "IAM", Risks: "Publicly accessible"},
+		"events":    {Type: "Provisioned", Brokers: "6", PublicAccess: "DISABLED", Authentication: "TLS, Unauthenticated", Risks: "Unauthenticated access"},
+		"telemetry": {Type: "Serverless", Brokers: "-", PublicAccess: "DISABLED", Authentication: "IAM", Risks: ""},
+	}
+	if len(m.Clusters) != len(expectedClusters) {
+		t.Fatalf("Expected %d clusters, got %d", len(expectedClusters), len(m.Clusters))
+	}
+	for _, cluster := range m.Clusters {
+		want, ok := expectedClusters[cluster.Name]
+		if !ok {
+			t.Errorf("Unexpected cluster %s", cluster.Name)
+			continue
+		}
+		got := expectedCluster{
+			Type:           cluster.Type,
+			Brokers:        cluster.Brokers,
+			PublicAccess:   cluster.PublicAccess,
+			Authentication: strings.Join(cluster.Authentication, ", "),
+			Risks:          strings.Join(cluster.Risks, ", "),
+		}
+		if got != want {
+			t.Errorf("%s: expected %+v, got %+v", cluster.Name, want, got)
+		}
+	}
+
+	lootFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/loot/msk-bootstrap-brokers.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	for _, expected := range []string{
+		"# SASL/IAM, public\nb-1-public.payments.abc123.c2.kafka.us-east-1.amazonaws.com:9198",
+		"# Plaintext, private\nb-1.events.def456.c3.kafka.us-east-1.amazonaws.com:9092",
+		"boot-ghi789.c1.kafka-serverless.us-east-1.amazonaws.com:9098",
+	} {
+		if !strings.Contains(string(lootFile), expected) {
+			t.Errorf("Expected %q to be in the loot file", expected)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+const (
+	anyIPv4 = "0.0.0.0/0"
+	anyIPv6 = "::/0"
+)
+
+// ReachablePort is a port range on a public IP that a security group opens to the whole internet and that the
+// subnet's network ACL lets through
+type ReachablePort struct {
+	Region    string
+	PublicIP  string
+	Resource  string
+	Protocol  string
+	FromPort  int32
+	ToPort    int32
+	AllowedBy string
+}
+
+// portRange is an inclusive range of ports
+type portRange struct {
+	From int32
+	To   int32
+}
+
+// securityGroupAllowance is a range of ports a security group rule opens to any source address
+type securityGroupAllowance struct {
+	Protocol string
+	Ports    portRange
+	Source   string
+}
+
+// internetReachablePorts joins a network interface with its security groups and the network ACL of its subnet, and
+// returns the port ranges that are reachable from anywhere on the internet on each of its public addresses.
+//
+// Only security group rules with a 0.0.0.0/0 or ::/0 source count. Rules that reference other security groups or
+// prefix lists only let in traffic from inside AWS. Network ACL rules with a narrower CIDR don't decide whether the
+// internet at large gets in, so they are skipped as well. ACLs are stateless, but the egress rules for the return
+// traffic are not checked.
+func internetReachablePorts(networkInterface ec2_types.NetworkInterface, securityGroups []ec2_types.SecurityGroup, nacls []ec2_types.NetworkAcl) []ReachablePort {
+	var reachablePorts []ReachablePort
+
+	ipv4Addresses, ipv6Addresses := publicAddresses(networkInterface)
+	if len(ipv4Addresses) == 0 && len(ipv6Addresses) == 0 {
+		return reachablePorts
+	}
+
+	var groups []ec2_types.SecurityGroup
+	for _, group := range networkInterface.Groups {
+		for _, securityGroup := range securityGroups {
+			if aws.ToString(securityGroup.GroupId) == aws.ToString(group.GroupId) {
+				groups = append(groups, securityGroup)
+			}
+		}
+	}
+	nacl := subnetNetworkAcl(aws.ToString(networkInterface.SubnetId), aws.ToString(networkInterface.VpcId), nacls)
+	resource := networkInterfaceResource(networkInterface)
+
+	for _, ipv6 := range []bool{false, true} {
+		addresses := ipv4Addresses
+		if ipv6 {
+			addresses = ipv6Addresses
+		}
+		if len(addresses) == 0 {
+			continue
+		}
+		for _, allowance := range securityGroupAllowances(groups, ipv6) {
+			for _, allowed := range naclAllowedPorts(nacl, allowance.Protocol, allowance.Ports, ipv6) {
+				for _, address := range addresses {
+					reachablePorts = append(reachablePorts, ReachablePort{
+						PublicIP:  address,
+						Resource:  resource,
+						Protocol:  allowance.Protocol,
+						FromPort:  allowed.From,
+						ToPort:    allowed.To,
+						AllowedBy: allowance.Source,
+					})
+				}
+			}
+		}
+	}
+	return reachablePorts
+}
+
+// publicAddresses returns the public IPv4 addresses associated with the interface and its IPv6 addresses, which are
+// all globally routable
+func publicAddresses(networkInterface ec2_types.NetworkInterface) ([]string, []string) {
+	var ipv4Addresses, ipv6Addresses []string
+	if networkInterface.Association != nil && networkInterface.Association.PublicIp != nil {
+		ipv4Addresses = addHost(ipv4Addresses, aws.ToString(networkInterface.Association.PublicIp))
+	}
+	for _, address := range networkInterface.PrivateIpAddresses {
+		if address.Association != nil && address.Association.PublicIp != nil {
+			ipv4Addresses = addHost(ipv4Addresses, aws.ToString(address.Association.PublicIp))
+		}
+	}
+	for _, address := range networkInterface.Ipv6Addresses {
+		if address.Ipv6Address != nil {
+			ipv6Addresses = addHost(ipv6Addresses, aws.ToString(address.Ipv6Address))
+		}
+	}
+	return ipv4Addresses, ipv6Addresses
+}
+
+// networkInterfaceResource names what the interface belongs to. Interfaces that AWS manages for load balancers, RDS
+// and the like only describe their owner in the description.
+func networkInterfaceResource(networkInterface ec2_types.NetworkInterface) string {
+	if networkInterface.Attachment != nil && networkInterface.Attachment.InstanceId != nil {
+		return aws.ToString(networkInterface.Attachment.InstanceId)
+	}
+	if aws.ToString(networkInterface.Description) != "" {
+		return fmt.Sprintf("%s (%s)", aws.ToString(networkInterface.Description), aws.ToString(networkInterface.NetworkInterfaceId))
+	}
+	return aws.ToString(networkInterface.NetworkInterfaceId)
+}
+
+// subnetNetworkAcl returns the network ACL associated with the subnet, or the VPC's default ACL, which covers every
+// subnet that isn't explicitly associated with another one
+func subnetNetworkAcl(subnetID string, vpcID string, nacls []ec2_types.NetworkAcl) *ec2_types.NetworkAcl {
+	var defaultNacl *ec2_types.NetworkAcl
+	for i := range nacls {
+		for _, association := range nacls[i].Associations {
+			if aws.ToString(association.SubnetId) == subnetID {
+				return &nacls[i]
+			}
+		}
+		if aws.ToBool(nacls[i].IsDefault) && aws.ToString(nacls[i].VpcId) == vpcID {
+			defaultNacl = &nacls[i]
+		}
+	}
+	return defaultNacl
+}
+
+// securityGroupAllowances returns the tcp and udp port ranges the groups open to any IPv4 or IPv6 source. "All
+// traffic" rules open every tcp and udp port.
+func securityGroupAllowances(groups []ec2_types.SecurityGroup, ipv6 bool) []securityGroupAllowance {
+	var allowances []securityGroupAllowance
+	for _, group := range groups {
+		for _, permission := range group.IpPermissions {
+			source := anyIPv4
+			var openToAnySource bool
+			if ipv6 {
+				source = anyIPv6
+				for _, ipRange := range permission.Ipv6Ranges {
+					if aws.ToString(ipRange.CidrIpv6) == anyIPv6 {
+						openToAnySource = true
+					}
+				}
+			} else {
+				for _, ipRange := range permission.IpRanges {
+					if aws.ToString(ipRange.CidrIp) == anyIPv4 {
+						openToAnySource = true
+					}
+				}
+			}
+			if !openToAnySource {
+				continue
+			}
+
+			var protocols []string
+			ports := portRange{From: 0, To: 65535}
+			switch aws.ToString(permission.IpProtocol) {
+			case "-1":
+				protocols = []string{"tcp", "udp"}
+			case "tcp", "6":
+				protocols = []string{"tcp"}
+			case "udp", "17":
+				protocols = []string{"udp"}
+			default:
+				// icmp and other protocols don't have ports to scan
+				continue
+			}
+			if aws.ToString(permission.IpProtocol) != "-1" && permission.FromPort != nil && permission.ToPort != nil {
+				ports = portRange{From: aws.ToInt32(permission.FromPort), To: aws.ToInt32(permission.ToPort)}
+			}
+
+			groupName := aws.ToString(group.GroupId)
+			if aws.ToString(group.GroupName) != "" {
+				groupName = fmt.Sprintf("%s (%s)", aws.ToString(group.GroupName), aws.ToString(group.GroupId))
+			}
+			for _, protocol := range protocols {
+				allowances = append(allowances, securityGroupAllowance{
+					Protocol: protocol,
+					Ports:    ports,
+					Source:   fmt.Sprintf("%s: %s %s from %s", groupName, securityGroupRuleProtocol(aws.ToString(permission.IpProtocol)), formatPortRange(ports), source),
+				})
+			}
+		}
+	}
+	return allowances
+}
+
+func securityGroupRuleProtocol(protocol string) string {
+	switch protocol {
+	case "-1":
+		return "all traffic"
+	case "6":
+		return "tcp"
+	case "17":
+		return "udp"
+	default:
+		return protocol
+	}
+}
+
+// naclAllowedPorts walks the ACL's inbound rules in rule number order and returns the parts of the port range the
+// first matching rule allows. Ports no rule matches hit the implicit deny at the end of every ACL. Without an ACL,
+// nothing is filtered.
+func naclAllowedPorts(nacl *ec2_types.NetworkAcl, protocol string, ports portRange, ipv6 bool) []portRange {
+	if nacl == nil {
+		return []portRange{ports}
+	}
+
+	var entries []ec2_types.NetworkAclEntry
+	for _, entry := range nacl.Entries {
+		if aws.ToBool(entry.Egress) {
+			continue
+		}
+		if ipv6 && aws.ToString(entry.Ipv6CidrBlock) != anyIPv6 {
+			continue
+		}
+		if !ipv6 && aws.ToString(entry.CidrBlock) != anyIPv4 {
+			continue
+		}
+		if naclToSG[aws.ToString(entry.Protocol)] != protocol && aws.ToString(entry.Protocol) != "-1" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return aws.ToInt32(entries[i].RuleNumber) < aws.ToInt32(entries[j].RuleNumber)
+	})
+
+	var allowed []portRange
+	undecided := []portRange{ports}
+	for _, entry := range entries {
+		entryPorts := portRange{From: 0, To: 65535}
+		if entry.PortRange != nil && aws.ToString(entry.Protocol) != "-1" {
+			entryPorts = portRange{From: aws.ToInt32(entry.PortRange.From), To: aws.ToInt32(entry.PortRange.To)}
+		}
+		var remaining []portRange
+		for _, r := range undecided {
+			matched, rest := splitPortRange(r, entryPorts)
+			if matched != nil && entry.RuleAction == ec2_types.RuleActionAllow {
+				allowed = append(allowed, *matched)
+			}
+			remaining = append(remaining, rest...)
+		}
+		undecided = remaining
+	}
+
+	sort.Slice(allowed, func(i, j int) bool {
+		return allowed[i].From < allowed[j].From
+	})
+	return allowed
+}
+
+// splitPortRange returns the part of r that overlaps with other, and the parts of r outside of it
+func splitPortRange(r portRange, other portRange) (*portRange, []portRange) {
+	if other.To < r.From || other.From > r.To {
+		return nil, []portRange{r}
+	}
+	var rest []portRange
+	matched := portRange{From: r.From, To: r.To}
+	if other.From > r.From {
+		rest = append(rest, portRange{From: r.From, To: other.From - 1})
+		matched.From = other.From
+	}
+	if other.To < r.To {
+		rest = append(rest, portRange{From: other.To + 1, To: r.To})
+		matched.To = other.To
+	}
+	return &matched, rest
+}
+
+func formatPortRange(ports portRange) string {
+	if ports.From == ports.To {
+		return fmt.Sprintf("%d", ports.From)
+	}
+	return fmt.Sprintf("%d-%d", ports.From, ports.To)
+}
+
+// nmapTargets groups the reachable ports per address into nmap port specs, e.g. T:22,443,U:53
+func nmapTargets(reachablePorts []ReachablePort) map[string]string {
+	tcpPorts := make(map[string][]string)
+	udpPorts := make(map[string][]string)
+	for _, reachablePort := range reachablePorts {
+		ports := formatPortRange(portRange{From: reachablePort.FromPort, To: reachablePort.ToPort})
+		switch reachablePort.Protocol {
+		case "tcp":
+			if !strContains(tcpPorts[reachablePort.PublicIP], ports) {
+				tcpPorts[reachablePort.PublicIP] = append(tcpPorts[reachablePort.PublicIP], ports)
+			}
+		case "udp":
+			if !strContains(udpPorts[reachablePort.PublicIP], ports) {
+				udpPorts[reachablePort.PublicIP] = append(udpPorts[reachablePort.PublicIP], ports)
+			}
+		}
+	}
+
+	targets := make(map[string]string)
+	for _, reachablePort := range reachablePorts {
+		var spec []string
+		if len(tcpPorts[reachablePort.PublicIP]) > 0 {
+			spec = append(spec, "T:"+strings.Join(tcpPorts[reachablePort.PublicIP], ","))
+		}
+		if len(udpPorts[reachablePort.PublicIP]) > 0 {
+			spec = append(spec, "U:"+strings.Join(udpPorts[reachablePort.PublicIP], ","))
+		}
+		targets[reachablePort.PublicIP] = strings.Join(spec, ",")
+	}
+	return targets
+}
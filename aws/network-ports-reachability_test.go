@@ -0,0 +1,259 @@
+package aws
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func testNaclEntry(ruleNumber int32, protocol string, cidr string, ports *ec2_types.PortRange, action ec2_types.RuleAction) ec2_types.NetworkAclEntry {
+	entry := ec2_types.NetworkAclEntry{
+		RuleNumber: aws.Int32(ruleNumber),
+		Protocol:   aws.String(protocol),
+		PortRange:  ports,
+		RuleAction: action,
+		Egress:     aws.Bool(false),
+	}
+	if cidr == anyIPv6 {
+		entry.Ipv6CidrBlock = aws.String(cidr)
+	} else {
+		entry.CidrBlock = aws.String(cidr)
+	}
+	return entry
+}
+
+func testPorts(from int32, to int32) *ec2_types.PortRange {
+	return &ec2_types.PortRange{From: aws.Int32(from), To: aws.Int32(to)}
+}
+
+var testReachabilitySecurityGroups = []ec2_types.SecurityGroup{
+	{
+		GroupId:   aws.String("sg-web"),
+		GroupName: aws.String("web"),
+		IpPermissions: []ec2_types.IpPermission{
+			{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int32(443),
+				ToPort:     aws.Int32(443),
+				IpRanges:   []ec2_types.IpRange{{CidrIp: aws.String(anyIPv4)}},
+			},
+			{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int32(8000),
+				ToPort:     aws.Int32(8200),
+				IpRanges:   []ec2_types.IpRange{{CidrIp: aws.String("10.0.0.0/8")}, {CidrIp: aws.String(anyIPv4)}},
+			},
+			// Only reachable from the office and from other security groups, not from the internet
+			{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int32(22),
+				ToPort:     aws.Int32(22),
+				IpRanges:   []ec2_types.IpRange{{CidrIp: aws.String("203.0.113.0/24")}},
+			},
+			{
+				IpProtocol:       aws.String("udp"),
+				FromPort:         aws.Int32(53),
+				ToPort:           aws.Int32(53),
+				UserIdGroupPairs: []ec2_types.UserIdGroupPair{{GroupId: aws.String("sg-internal")}},
+			},
+			{
+				IpProtocol: aws.String("icmp"),
+				FromPort:   aws.Int32(-1),
+				ToPort:     aws.Int32(-1),
+				IpRanges:   []ec2_types.IpRange{{CidrIp: aws.String(anyIPv4)}},
+			},
+		},
+	},
+	{
+		GroupId: aws.String("sg-ipv6"),
+		IpPermissions: []ec2_types.IpPermission{
+			{
+				IpProtocol: aws.String("-1"),
+				FromPort:   aws.Int32(-1),
+				ToPort:     aws.Int32(-1),
+				Ipv6Ranges: []ec2_types.Ipv6Range{{CidrIpv6: aws.String(anyIPv6)}},
+			},
+		},
+	},
+	{
+		GroupId: aws.String("sg-open"),
+		IpPermissions: []ec2_types.IpPermission{
+			{
+				IpProtocol: aws.String("-1"),
+				IpRanges:   []ec2_types.IpRange{{CidrIp: aws.String(anyIPv4)}},
+			},
+		},
+	},
+}
+
+var testReachabilityNacls = []ec2_types.NetworkAcl{
+	{
+		NetworkAclId: aws.String("acl-public"),
+		VpcId:        aws.String("vpc-1"),
+		Associations: []ec2_types.NetworkAclAssociation{{SubnetId: aws.String("subnet-public")}},
+		Entries: []ec2_types.NetworkAclEntry{
+			testNaclEntry(100, "-1", anyIPv4, nil, ec2_types.RuleActionAllow),
+			testNaclEntry(90, "6", anyIPv4, testPorts(8000, 8100), ec2_types.RuleActionDeny),
+			// Narrower rules don't decide what the internet at large can reach
+			testNaclEntry(80, "6", "198.51.100.0/24", testPorts(0, 65535), ec2_types.RuleActionDeny),
+			testNaclEntry(50, "6", anyIPv6, testPorts(22, 22), ec2_types.RuleActionDeny),
+			testNaclEntry(101, "-1", anyIPv6, nil, ec2_types.RuleActionAllow),
+			testNaclEntry(32767, "-1", anyIPv4, nil, ec2_types.RuleActionDeny),
+			{
+				RuleNumber: aws.Int32(100),
+				Protocol:   aws.String("-1"),
+				CidrBlock:  aws.String(anyIPv4),
+				RuleAction: ec2_types.RuleActionDeny,
+				Egress:     aws.Bool(true),
+			},
+		},
+	},
+	{
+		NetworkAclId: aws.String("acl-default"),
+		VpcId:        aws.String("vpc-1"),
+		IsDefault:    aws.Bool(true),
+		Associations: []ec2_types.NetworkAclAssociation{{SubnetId: aws.String("subnet-private")}},
+		Entries: []ec2_types.NetworkAclEntry{
+			testNaclEntry(100, "6", anyIPv4, testPorts(80, 80), ec2_types.RuleActionAllow),
+			testNaclEntry(32767, "-1", anyIPv4, nil, ec2_types.RuleActionDeny),
+		},
+	},
+}
+
+func formatReachablePorts(reachablePorts []ReachablePort) []string {
+	var out []string
+	for _, reachablePort := range reachablePorts {
+		out = append(out, fmt.Sprintf("%s %s %s", reachablePort.PublicIP, reachablePort.Protocol, formatPortRange(portRange{From: reachablePort.FromPort, To: reachablePort.ToPort})))
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestInternetReachablePorts(t *testing.T) {
+	subtests := []struct {
+		name             string
+		networkInterface ec2_types.NetworkInterface
+		expected         []string
+		expectedResource string
+	}{
+		{
+			name: "instance with public IPv4 and IPv6 addresses",
+			networkInterface: ec2_types.NetworkInterface{
+				NetworkInterfaceId: aws.String("eni-instance"),
+				SubnetId:           aws.String("subnet-public"),
+				VpcId:              aws.String("vpc-1"),
+				Attachment:         &ec2_types.NetworkInterfaceAttachment{InstanceId: aws.String("i-web")},
+				Association:        &ec2_types.NetworkInterfaceAssociation{PublicIp: aws.String("54.0.0.1")},
+				PrivateIpAddresses: []ec2_types.NetworkInterfacePrivateIpAddress{
+					{Association: &ec2_types.NetworkInterfaceAssociation{PublicIp: aws.String("54.0.0.1")}},
+					{Association: &ec2_types.NetworkInterfaceAssociation{PublicIp: aws.String("54.0.0.2")}},
+				},
+				Ipv6Addresses: []ec2_types.NetworkInterfaceIpv6Address{{Ipv6Address: aws.String("2600:1f18::1")}},
+				Groups:        []ec2_types.GroupIdentifier{{GroupId: aws.String("sg-web")}, {GroupId: aws.String("sg-ipv6")}},
+			},
+			expected: []string{
+				"2600:1f18::1 tcp 0-21",
+				"2600:1f18::1 tcp 23-65535",
+				"2600:1f18::1 udp 0-65535",
+				"54.0.0.1 tcp 443",
+				"54.0.0.1 tcp 8101-8200",
+				"54.0.0.2 tcp 443",
+				"54.0.0.2 tcp 8101-8200",
+			},
+			expectedResource: "i-web",
+		},
+		{
+			name: "load balancer in a subnet that falls back to the default ACL",
+			networkInterface: ec2_types.NetworkInterface{
+				NetworkInterfaceId: aws.String("eni-lb"),
+				Description:        aws.String("ELB app/public-alb/0123456789abcdef"),
+				SubnetId:           aws.String("subnet-unassociated"),
+				VpcId:              aws.String("vpc-1"),
+				Association:        &ec2_types.NetworkInterfaceAssociation{PublicIp: aws.String("3.0.0.1")},
+				Groups:             []ec2_types.GroupIdentifier{{GroupId: aws.String("sg-open")}},
+			},
+			expected:         []string{"3.0.0.1 tcp 80"},
+			expectedResource: "ELB app/public-alb/0123456789abcdef (eni-lb)",
+		},
+		{
+			name: "interface without a public address",
+			networkInterface: ec2_types.NetworkInterface{
+				NetworkInterfaceId: aws.String("eni-private"),
+				SubnetId:           aws.String("subnet-public"),
+				VpcId:              aws.String("vpc-1"),
+				Groups:             []ec2_types.GroupIdentifier{{GroupId: aws.String("sg-open")}},
+			},
+		},
+	}
+
+	for _, subtest := range subtests {
+		t.Run(subtest.name, func(t *testing.T) {
+			reachablePorts := internetReachablePorts(subtest.networkInterface, testReachabilitySecurityGroups, testReachabilityNacls)
+			got := formatReachablePorts(reachablePorts)
+			if !reflect.DeepEqual(got, subtest.expected) {
+				t.Errorf("Expected reachable ports %v, got %v", subtest.expected, got)
+			}
+			for _, reachablePort := range reachablePorts {
+				if reachablePort.Resource != subtest.expectedResource {
+					t.Errorf("Expected resource %q, got %q", subtest.expectedResource, reachablePort.Resource)
+				}
+				if reachablePort.AllowedBy == "" {
+					t.Errorf("Expected the allowing security group rule for %s %s", reachablePort.PublicIP, reachablePort.Protocol)
+				}
+			}
+		})
+	}
+}
+
+func TestSecurityGroupAllowancesSource(t *testing.T) {
+	allowances := securityGroupAllowances(testReachabilitySecurityGroups[:1], false)
+	expected := []string{
+		"web (sg-web): tcp 443 from 0.0.0.0/0",
+		"web (sg-web): tcp 8000-8200 from 0.0.0.0/0",
+	}
+	var got []string
+	for _, allowance := range allowances {
+		got = append(got, allowance.Source)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected allowances %v, got %v", expected, got)
+	}
+}
+
+func TestNaclAllowedPorts(t *testing.T) {
+	// Without an ACL nothing is filtered
+	if got := naclAllowedPorts(nil, "udp", portRange{From: 53, To: 53}, false); !reflect.DeepEqual(got, []portRange{{From: 53, To: 53}}) {
+		t.Errorf("Expected the whole range without an ACL, got %v", got)
+	}
+	// The lower rule number wins, even if a later rule allows the same ports
+	got := naclAllowedPorts(&testReachabilityNacls[0], "tcp", portRange{From: 7000, To: 9000}, false)
+	expected := []portRange{{From: 7000, To: 7999}, {From: 8101, To: 9000}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	// udp ports that no rule allows hit the deny all rule
+	if got := naclAllowedPorts(&testReachabilityNacls[1], "udp", portRange{From: 0, To: 65535}, false); len(got) != 0 {
+		t.Errorf("Expected no udp ports through the default ACL, got %v", got)
+	}
+}
+
+func TestNmapTargets(t *testing.T) {
+	targets := nmapTargets([]ReachablePort{
+		{PublicIP: "54.0.0.1", Protocol: "tcp", FromPort: 22, ToPort: 22},
+		{PublicIP: "54.0.0.1", Protocol: "tcp", FromPort: 8000, ToPort: 8100},
+		{PublicIP: "54.0.0.1", Protocol: "udp", FromPort: 53, ToPort: 53},
+		{PublicIP: "54.0.0.1", Protocol: "tcp", FromPort: 22, ToPort: 22},
+		{PublicIP: "3.0.0.1", Protocol: "tcp", FromPort: 443, ToPort: 443},
+	})
+	expected := map[string]string{
+		"54.0.0.1": "T:22,8000-8100,U:53",
+		"3.0.0.1":  "T:443",
+	}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Errorf("Expected nmap targets %v, got %v", expected, targets)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/BishopFox/cloudfox/aws/sdk"
 	"github.com/BishopFox/cloudfox/internal"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -76,11 +77,13 @@ type NetworkPortsModule struct {
 	Verbosity  int
 
 	// Main module data
-	IPv4_Private   []NetworkService
-	IPv4_Public    []NetworkService
-	IPv6           []NetworkService
-	nacls          map[string]*[]ec2_types.NetworkAcl
-	securityGroups map[string]*[]ec2_types.SecurityGroup
+	IPv4_Private []NetworkService
+	IPv4_Public  []NetworkService
+	IPv6         []NetworkService
+	// Ports on public IPs that are open to the whole internet
+	InternetReachable []ReachablePort
+	nacls             map[string]*[]ec2_types.NetworkAcl
+	securityGroups    map[string]*[]ec2_types.SecurityGroup
 
 	CommandCounter internal.CommandCounter
 	// Used to store output data for pretty printing
@@ -89,9 +92,10 @@ type NetworkPortsModule struct {
 }
 
 type NetworkServices struct {
-	IPv4_Private []NetworkService
-	IPv4_Public  []NetworkService
-	IPv6         []NetworkService
+	IPv4_Private      []NetworkService
+	IPv4_Public       []NetworkService
+	IPv6              []NetworkService
+	InternetReachable []ReachablePort
 }
 
 type NetworkService struct {
@@ -243,7 +247,56 @@ func (m *NetworkPortsModule) PrintNetworkPorts(outputDirectory string) {
 		}
 	}
 
-	if len(m.IPv4_Private) > 0 || len(m.IPv4_Public) > 0 || len(m.IPv6) > 0 {
+	sort.Slice(m.InternetReachable, func(i, j int) bool {
+		a, b := m.InternetReachable[i], m.InternetReachable[j]
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.PublicIP != b.PublicIP {
+			return a.PublicIP < b.PublicIP
+		}
+		if a.Protocol != b.Protocol {
+			return a.Protocol < b.Protocol
+		}
+		return a.FromPort < b.FromPort
+	})
+	internetHeader := []string{
+		"Account",
+		"Region",
+		"Public IP",
+		"Resource",
+		"Protocol",
+		"Ports",
+		"Allowed By",
+	}
+	internetTableCols := []string{
+		"Region",
+		"Public IP",
+		"Resource",
+		"Protocol",
+		"Ports",
+		"Allowed By",
+	}
+	if m.AWSOutputType == "wide" {
+		internetTableCols = internetHeader
+	}
+	var internetBody [][]string
+	for _, reachablePort := range m.InternetReachable {
+		internetBody = append(
+			internetBody,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				reachablePort.Region,
+				reachablePort.PublicIP,
+				reachablePort.Resource,
+				reachablePort.Protocol,
+				formatPortRange(portRange{From: reachablePort.FromPort, To: reachablePort.ToPort}),
+				reachablePort.AllowedBy,
+			},
+		)
+	}
+
+	if len(m.IPv4_Private) > 0 || len(m.IPv4_Public) > 0 || len(m.IPv6) > 0 || len(m.InternetReachable) > 0 {
 		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
 		o := internal.OutputClient{
 			Verbosity:     m.Verbosity,
@@ -258,11 +311,24 @@ func (m *NetworkPortsModule) PrintNetworkPorts(outputDirectory string) {
 			TableCols: tableCols,
 			Name:      m.output.CallingModule,
 		})
+		if len(internetBody) > 0 {
+			o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+				Header:    internetHeader,
+				Body:      internetBody,
+				TableCols: internetTableCols,
+				Name:      "network-ports-internet",
+			})
+		}
 		o.PrefixIdentifier = m.AWSProfile
 		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
 		o.WriteFullOutput(o.Table.TableFiles, nil)
 		m.writeLoot(o.Table.DirectoryName)
 		fmt.Printf("[%s][%s] %s network services found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+		if len(internetBody) > 0 {
+			fmt.Printf("[%s][%s] %s port ranges reachable from the internet.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(internetBody)))
+		}
 
 	} else {
 		fmt.Printf("[%s][%s] No network services found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
@@ -284,6 +350,8 @@ func (m *NetworkPortsModule) executeChecks(r string, wg *sync.WaitGroup, dataRec
 	if res {
 		m.CommandCounter.Total++
 		m.getEC2NetworkPortsPerRegion(r, dataReceiver)
+		m.CommandCounter.Total++
+		m.getInternetReachablePortsPerRegion(r, dataReceiver)
 	}
 
 	res, err = servicemap.IsServiceInRegion("ecs", r)
@@ -356,6 +424,9 @@ func (m *NetworkPortsModule) Receiver(receiver chan NetworkServices, receiverDon
 			if len(data.IPv6) != 0 {
 				m.IPv6 = append(m.IPv6, data.IPv6...)
 			}
+			if len(data.InternetReachable) != 0 {
+				m.InternetReachable = append(m.InternetReachable, data.InternetReachable...)
+			}
 		case <-receiverDone:
 			receiverDone <- true
 			return
@@ -384,6 +455,49 @@ func (m *NetworkPortsModule) writeLoot(outputDirectory string) {
 		ipv6Filename := filepath.Join(path, "network-ports-public-ipv6.txt")
 		m.writeLootFile(ipv6Filename, IPv6_BANNER, false, m.IPv6)
 	}
+
+	if len(m.InternetReachable) > 0 {
+		m.writeInternetLootFiles(path)
+	}
+}
+
+// writeInternetLootFiles writes the ports that are open to the internet as nmap commands, one per address, and as a
+// plain ip:port list for other scanners
+func (m *NetworkPortsModule) writeInternetLootFiles(path string) {
+	nmapOut := `#############################################
+# These ports are open to 0.0.0.0/0 or ::/0 in both the security groups and the network ACLs.
+# They should be reachable from anywhere on the internet.
+#############################################
+`
+	var targetsOut string
+	targets := nmapTargets(m.InternetReachable)
+	var addresses []string
+	for _, reachablePort := range m.InternetReachable {
+		addresses = addHost(addresses, reachablePort.PublicIP)
+		targetsOut = targetsOut + fmt.Sprintf("%s:%s\n", reachablePort.PublicIP, formatPortRange(portRange{From: reachablePort.FromPort, To: reachablePort.ToPort}))
+	}
+	for _, address := range addresses {
+		scan := TCP_4_SCAN
+		if strings.Contains(address, ":") {
+			scan = TCP_6_SCAN
+		}
+		if strings.Contains(targets[address], "U:") {
+			scan = scan + " -sS -sU"
+		}
+		nmapOut = nmapOut + fmt.Sprintf("%s -p %s %s\n", scan, targets[address], address)
+	}
+
+	for filename, out := range map[string]string{
+		filepath.Join(path, "network-ports-internet-nmap.txt"):    nmapOut,
+		filepath.Join(path, "network-ports-internet-targets.txt"): targetsOut,
+	} {
+		err := os.WriteFile(filename, []byte(out), 0644)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			continue
+		}
+		fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), filename)
+	}
 }
 
 func (m *NetworkPortsModule) writeLootFile(filename string, bannner string, ipv4 bool, services []NetworkService) {
@@ -412,7 +526,7 @@ func (m *NetworkPortsModule) writeLootFile(filename string, bannner string, ipv4
 		m.modLog.Error(err.Error())
 	}
 
-	if m.Verbosity > 2 {
+	if internal.ShowLoot(m.Verbosity) {
 		fmt.Println()
 		fmt.Printf("[%s][%s] %s \n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("Use the commands below to manually inspect certain buckets of interest."))
 		fmt.Print(out)
@@ -526,6 +640,35 @@ func (m *NetworkPortsModule) getEC2NetworkPortsPerRegion(r string, dataReceiver
 	wg.Wait()
 }
 
+// getInternetReachablePortsPerRegion checks every network interface with a public address, which covers instances as
+// well as the interfaces of load balancers, tasks, databases and other managed resources
+func (m *NetworkPortsModule) getInternetReachablePortsPerRegion(r string, dataReceiver chan NetworkServices) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+	}()
+	securityGroups := m.getEC2SecurityGroupsPerRegion(r)
+	nacls := m.getEC2NACLsPerRegion(r)
+
+	networkInterfaces, err := sdk.CachedEC2DescribeNetworkInterfaces(m.EC2Client, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	var networkServices NetworkServices
+	for _, networkInterface := range networkInterfaces {
+		for _, reachablePort := range internetReachablePorts(networkInterface, securityGroups, nacls) {
+			reachablePort.Region = r
+			networkServices.InternetReachable = append(networkServices.InternetReachable, reachablePort)
+		}
+	}
+	if len(networkServices.InternetReachable) > 0 {
+		dataReceiver <- networkServices
+	}
+}
+
 func (m *NetworkPortsModule) getECSNetworkPortsPerRegion(r string, dataReceiver chan NetworkServices) {
 	defer func() {
 		m.CommandCounter.Executing--
@@ -0,0 +1,489 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	openSearchServerlessTypes "github.com/aws/aws-sdk-go-v2/service/opensearchserverless/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type OpenSearchServerlessModule struct {
+	// General configuration data
+	OpenSearchServerlessClient sdk.OpenSearchServerlessClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Collections    []ServerlessCollection
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type ServerlessCollection struct {
+	Region             string
+	Name               string
+	ID                 string
+	Type               string
+	Status             string
+	Endpoint           string
+	DashboardEndpoint  string
+	NetworkPolicies    []string
+	Public             bool
+	VpcEndpoints       []string
+	AccessPolicies     []string
+	Principals         []string
+	WildcardFullAccess bool
+	Risk               string
+}
+
+// openSearchServerlessPolicy is one entry of a network or data access policy document. Both kinds of documents are a
+// JSON list of these, network policies use AllowFromPublic and SourceVPCEs, data access policies use Principal and
+// the rules' Permission.
+type openSearchServerlessPolicy struct {
+	Rules []struct {
+		ResourceType string   `json:"ResourceType"`
+		Resource     []string `json:"Resource"`
+		Permission   []string `json:"Permission"`
+	} `json:"Rules"`
+	AllowFromPublic bool     `json:"AllowFromPublic"`
+	SourceVPCEs     []string `json:"SourceVPCEs"`
+	Principal       []string `json:"Principal"`
+}
+
+type namedOpenSearchServerlessPolicy struct {
+	Name     string
+	Policies []openSearchServerlessPolicy
+}
+
+func (m *OpenSearchServerlessModule) PrintOpenSearchServerless(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "opensearch-serverless"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating OpenSearch Serverless collections for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan ServerlessCollection)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.Collections, func(i, j int) bool {
+		if m.Collections[i].Risk != m.Collections[j].Risk {
+			return m.Collections[i].Risk != ""
+		}
+		if m.Collections[i].Region != m.Collections[j].Region {
+			return m.Collections[i].Region < m.Collections[j].Region
+		}
+		return m.Collections[i].Name < m.Collections[j].Name
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Name",
+		"ID",
+		"Type",
+		"Status",
+		"Endpoint",
+		"Network Policies",
+		"Public",
+		"VPC Endpoints",
+		"Data Access Policies",
+		"Principals",
+		"Risk",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Name",
+			"ID",
+			"Type",
+			"Status",
+			"Endpoint",
+			"Network Policies",
+			"Public",
+			"VPC Endpoints",
+			"Data Access Policies",
+			"Principals",
+			"Risk",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Name",
+			"Type",
+			"Endpoint",
+			"Public",
+			"VPC Endpoints",
+			"Principals",
+			"Risk",
+		}
+	}
+
+	// Table rows
+	for _, collection := range m.Collections {
+		risk := collection.Risk
+		if risk != "" {
+			risk = magenta(risk)
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				collection.Region,
+				collection.Name,
+				collection.ID,
+				collection.Type,
+				collection.Status,
+				collection.Endpoint,
+				strings.Join(collection.NetworkPolicies, ", "),
+				strconv.FormatBool(collection.Public),
+				strings.Join(collection.VpcEndpoints, ", "),
+				strings.Join(collection.AccessPolicies, ", "),
+				strings.Join(collection.Principals, ", "),
+				risk,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     "opensearch-serverless-commands",
+			Contents: m.writeLoot(),
+		})
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %s OpenSearch Serverless collections found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No OpenSearch Serverless collections found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *OpenSearchServerlessModule) Receiver(receiver chan ServerlessCollection, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Collections = append(m.Collections, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *OpenSearchServerlessModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan ServerlessCollection) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("aoss", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getCollectionsPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *OpenSearchServerlessModule) getCollectionsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan ServerlessCollection) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	collectionSummaries, err := sdk.CachedOpenSearchServerlessListCollections(m.OpenSearchServerlessClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	if len(collectionSummaries) == 0 {
+		return
+	}
+
+	var collectionIDs []string
+	for _, collectionSummary := range collectionSummaries {
+		collectionIDs = append(collectionIDs, aws.ToString(collectionSummary.Id))
+	}
+	collectionDetails, err := sdk.CachedOpenSearchServerlessBatchGetCollection(m.OpenSearchServerlessClient, aws.ToString(m.Caller.Account), r, collectionIDs)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	collectionDetailsByID := make(map[string]openSearchServerlessTypes.CollectionDetail)
+	for _, collectionDetail := range collectionDetails {
+		collectionDetailsByID[aws.ToString(collectionDetail.Id)] = collectionDetail
+	}
+
+	networkPolicies := m.getNetworkPolicies(r)
+	accessPolicies := m.getDataAccessPolicies(r)
+
+	for _, collectionSummary := range collectionSummaries {
+		collection := ServerlessCollection{
+			Region: r,
+			Name:   aws.ToString(collectionSummary.Name),
+			ID:     aws.ToString(collectionSummary.Id),
+			Status: string(collectionSummary.Status),
+		}
+		if collectionDetail, ok := collectionDetailsByID[collection.ID]; ok {
+			collection.Type = string(collectionDetail.Type)
+			collection.Endpoint = aws.ToString(collectionDetail.CollectionEndpoint)
+			collection.DashboardEndpoint = aws.ToString(collectionDetail.DashboardEndpoint)
+		}
+		analyzeServerlessCollection(&collection, networkPolicies, accessPolicies)
+		dataReceiver <- collection
+	}
+}
+
+func (m *OpenSearchServerlessModule) getNetworkPolicies(r string) []namedOpenSearchServerlessPolicy {
+	var policies []namedOpenSearchServerlessPolicy
+	policySummaries, err := sdk.CachedOpenSearchServerlessListSecurityPolicies(m.OpenSearchServerlessClient, aws.ToString(m.Caller.Account), r, openSearchServerlessTypes.SecurityPolicyTypeNetwork)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return policies
+	}
+	for _, policySummary := range policySummaries {
+		document, err := sdk.CachedOpenSearchServerlessGetSecurityPolicy(m.OpenSearchServerlessClient, aws.ToString(m.Caller.Account), r, aws.ToString(policySummary.Name), openSearchServerlessTypes.SecurityPolicyTypeNetwork)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		policy := namedOpenSearchServerlessPolicy{Name: aws.ToString(policySummary.Name)}
+		if err := json.Unmarshal([]byte(document), &policy.Policies); err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		policies = append(policies, policy)
+	}
+	return policies
+}
+
+func (m *OpenSearchServerlessModule) getDataAccessPolicies(r string) []namedOpenSearchServerlessPolicy {
+	var policies []namedOpenSearchServerlessPolicy
+	policySummaries, err := sdk.CachedOpenSearchServerlessListAccessPolicies(m.OpenSearchServerlessClient, aws.ToString(m.Caller.Account), r, openSearchServerlessTypes.AccessPolicyTypeData)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return policies
+	}
+	for _, policySummary := range policySummaries {
+		document, err := sdk.CachedOpenSearchServerlessGetAccessPolicy(m.OpenSearchServerlessClient, aws.ToString(m.Caller.Account), r, aws.ToString(policySummary.Name), openSearchServerlessTypes.AccessPolicyTypeData)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		policy := namedOpenSearchServerlessPolicy{Name: aws.ToString(policySummary.Name)}
+		if err := json.Unmarshal([]byte(document), &policy.Policies); err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		policies = append(policies, policy)
+	}
+	return policies
+}
+
+// analyzeServerlessCollection applies the network and data access policies whose rules cover the collection. A
+// collection is HIGH risk when a network policy opens its endpoint to the internet, or when a data access policy
+// gives aoss:* to any principal.
+func analyzeServerlessCollection(collection *ServerlessCollection, networkPolicies []namedOpenSearchServerlessPolicy, accessPolicies []namedOpenSearchServerlessPolicy) {
+	for _, networkPolicy := range networkPolicies {
+		for _, policy := range networkPolicy.Policies {
+			var covered bool
+			for _, rule := range policy.Rules {
+				// Dashboard rules only open up the dashboards, the collection endpoint itself is covered by its own rule
+				if rule.ResourceType == "collection" && openSearchServerlessRuleCovers(rule.Resource, collection.Name) {
+					covered = true
+				}
+			}
+			if !covered {
+				continue
+			}
+			if !internal.Contains(networkPolicy.Name, collection.NetworkPolicies) {
+				collection.NetworkPolicies = append(collection.NetworkPolicies, networkPolicy.Name)
+			}
+			if policy.AllowFromPublic {
+				collection.Public = true
+			}
+			for _, vpcEndpoint := range policy.SourceVPCEs {
+				if !internal.Contains(vpcEndpoint, collection.VpcEndpoints) {
+					collection.VpcEndpoints = append(collection.VpcEndpoints, vpcEndpoint)
+				}
+			}
+		}
+	}
+
+	for _, accessPolicy := range accessPolicies {
+		for _, policy := range accessPolicy.Policies {
+			var covered, fullAccess bool
+			for _, rule := range policy.Rules {
+				if !openSearchServerlessRuleCovers(rule.Resource, collection.Name) {
+					continue
+				}
+				covered = true
+				if internal.Contains("aoss:*", rule.Permission) {
+					fullAccess = true
+				}
+			}
+			if !covered {
+				continue
+			}
+			if !internal.Contains(accessPolicy.Name, collection.AccessPolicies) {
+				collection.AccessPolicies = append(collection.AccessPolicies, accessPolicy.Name)
+			}
+			for _, principal := range policy.Principal {
+				if !internal.Contains(principal, collection.Principals) {
+					collection.Principals = append(collection.Principals, principal)
+				}
+				if principal == "*" && fullAccess {
+					collection.WildcardFullAccess = true
+				}
+			}
+		}
+	}
+
+	if collection.Public || collection.WildcardFullAccess {
+		collection.Risk = "HIGH"
+	}
+}
+
+// openSearchServerlessRuleCovers checks if any of a rule's resources applies to the collection. Collection resources
+// look like collection/<name> and index resources like index/<collection>/<index>, and both parts can end in *.
+func openSearchServerlessRuleCovers(resources []string, collectionName string) bool {
+	for _, resource := range resources {
+		parts := strings.SplitN(resource, "/", 3)
+		if len(parts) < 2 {
+			continue
+		}
+		if backupPatternMatches(collectionName, parts[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *OpenSearchServerlessModule) writeLoot() string {
+	var out string
+	out = out + fmt.Sprintln("#############################################")
+	out = out + fmt.Sprintln("# OpenSearch Serverless only accepts SigV4 signed requests, even from the internet.")
+	out = out + fmt.Sprintln("# Export credentials for the principal you are going to use, e.g. with aws configure export-credentials --profile $profile --format env")
+	out = out + fmt.Sprintln("#############################################")
+	out = out + fmt.Sprintln("")
+
+	for _, collection := range m.Collections {
+		if collection.Endpoint == "" {
+			continue
+		}
+		out = out + fmt.Sprintf("# %s %s", collection.Region, collection.Name)
+		if collection.Risk != "" {
+			out = out + fmt.Sprintf(" - %s", collection.Risk)
+		}
+		out = out + fmt.Sprintln("")
+		signing := fmt.Sprintf("curl --aws-sigv4 \"aws:amz:%s:aoss\" --user \"$AWS_ACCESS_KEY_ID:$AWS_SECRET_ACCESS_KEY\" -H \"x-amz-security-token: $AWS_SESSION_TOKEN\"", collection.Region)
+		out = out + fmt.Sprintf("%s \"%s/_cat/indices?v\"\n", signing, collection.Endpoint)
+		out = out + fmt.Sprintf("%s \"%s/_search?size=100\"\n", signing, collection.Endpoint)
+		out = out + fmt.Sprintln("")
+	}
+
+	return out
+}
@@ -0,0 +1,77 @@
+package aws
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestOpenSearchServerless(t *testing.T) {
+	m := OpenSearchServerlessModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::555555555555:user/Alice"),
+			Account: aws.String("555555555555"),
+		},
+		Goroutines:                 3,
+		OpenSearchServerlessClient: &sdk.MockedOpenSearchServerlessClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+	tmpDir := "."
+
+	m.PrintOpenSearchServerless(tmpDir, 2)
+
+	expected := map[string]struct {
+		public       bool
+		vpcEndpoints []string
+		principals   []string
+		risk         string
+	}{
+		// vectors-readers can describe every collection, so its role shows up everywhere
+		"logs":    {true, nil, []string{"arn:aws:iam::123456789012:role/vectors-reader"}, "HIGH"},
+		"search":  {false, []string{"vpce-0a1b2c3d4e5f60001"}, []string{"*", "arn:aws:iam::123456789012:role/vectors-reader"}, "HIGH"},
+		"vectors": {false, []string{"vpce-0a1b2c3d4e5f60001"}, []string{"arn:aws:iam::123456789012:role/vectors-reader"}, ""},
+	}
+	if len(m.Collections) != len(expected) {
+		t.Fatalf("Expected %d collections, got %d", len(expected), len(m.Collections))
+	}
+	for _, collection := range m.Collections {
+		want, ok := expected[collection.Name]
+		if !ok {
+			t.Errorf("Unexpected collection %s", collection.Name)
+			continue
+		}
+		if collection.Public != want.public {
+			t.Errorf("Expected public to be %t for %s, got %t", want.public, collection.Name, collection.Public)
+		}
+		if !reflect.DeepEqual(collection.VpcEndpoints, want.vpcEndpoints) {
+			t.Errorf("Expected VPC endpoints %v for %s, got %v", want.vpcEndpoints, collection.Name, collection.VpcEndpoints)
+		}
+		if !reflect.DeepEqual(collection.Principals, want.principals) {
+			t.Errorf("Expected principals %v for %s, got %v", want.principals, collection.Name, collection.Principals)
+		}
+		if collection.Risk != want.risk {
+			t.Errorf("Expected risk %q for %s, got %q", want.risk, collection.Name, collection.Risk)
+		}
+	}
+
+	lootFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-555555555555/loot/opensearch-serverless-commands.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	expectedCommand := "curl --aws-sigv4 \"aws:amz:us-east-1:aoss\" --user \"$AWS_ACCESS_KEY_ID:$AWS_SECRET_ACCESS_KEY\" -H \"x-amz-security-token: $AWS_SESSION_TOKEN\" \"https://logs0000000000000001.us-east-1.aoss.amazonaws.com/_cat/indices?v\""
+	if !strings.Contains(string(lootFile), expectedCommand) {
+		t.Errorf("Expected %s to be in the loot file", expectedCommand)
+	}
+}
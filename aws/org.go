@@ -160,6 +160,8 @@ func (m *OrgModule) PrintOrgAccounts(outputDirectory string, verbosity int) {
 			})
 			o.PrefixIdentifier = m.AWSProfile
 			o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+			o.AccountID = aws.ToString(m.Caller.Account)
+			o.BaseDirectory = outputDirectory
 			o.WriteFullOutput(o.Table.TableFiles, nil)
 			//m.writeLoot(o.Table.DirectoryName, verbosity)
 			fmt.Printf("[%s][%s] %d accounts found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body))
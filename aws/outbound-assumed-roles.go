@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -12,6 +13,8 @@ import (
 
 	"github.com/BishopFox/cloudfox/internal"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/ratelimit"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
 	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
 	cloudtrailTypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
@@ -34,6 +37,7 @@ type OutboundAssumedRolesModule struct {
 
 	// Main module data
 	OutboundAssumeRoleEntries []OutboundAssumeRoleEntry
+	AssumeRoleSummaries       []AssumeRoleSummary
 	Days                      int
 	CommandCounter            internal.CommandCounter
 	// Used to store output data for pretty printing
@@ -52,6 +56,18 @@ type OutboundAssumeRoleEntry struct {
 	DestinationPrincipal string
 	Action               string
 	LogTimestamp         string
+	EventTime            time.Time
+	SourceIP             string
+}
+
+// AssumeRoleSummary rolls up all of the AssumeRole events from one source principal into one target role
+type AssumeRoleSummary struct {
+	SourcePrincipal string
+	TargetRole      string
+	TargetAccount   string
+	Count           int
+	LastSeen        time.Time
+	SourceIPs       []string
 }
 
 type CloudTrailEvent struct {
@@ -147,6 +163,10 @@ func (m *OutboundAssumedRolesModule) PrintOutboundRoleTrusts(days int, outputDir
 	if m.AWSProfile == "" {
 		m.AWSProfile = internal.BuildAWSPath(m.Caller)
 	}
+	// Older versions of this flag expected a negative number of days, so accept either
+	if days < 0 {
+		days = -days
+	}
 	m.Days = days
 
 	fmt.Printf("[%s][%s] Enumerating outbound assumed role entries in cloudtrail for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
@@ -182,6 +202,8 @@ func (m *OutboundAssumedRolesModule) PrintOutboundRoleTrusts(days int, outputDir
 	receiverDone <- true
 	<-receiverDone
 
+	m.summarizeAssumeRoleEntries()
+
 	m.output.Headers = []string{
 		"Account",
 		"Region",
@@ -268,17 +290,121 @@ func (m *OutboundAssumedRolesModule) PrintOutboundRoleTrusts(days int, outputDir
 		})
 		o.PrefixIdentifier = m.AWSProfile
 		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
-		o.WriteFullOutput(o.Table.TableFiles, nil)
-		//m.writeLoot(o.Table.DirectoryName, verbosity)
-		fmt.Printf("[%s][%s] %s log entries found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		if len(m.AssumeRoleSummaries) > 0 {
+			summaryHeader := []string{
+				"Source Principal",
+				"Target Role",
+				"Target Account",
+				"Count",
+				"Last Seen",
+				"Source IPs",
+			}
+			var summaryBody [][]string
+			for _, summary := range m.AssumeRoleSummaries {
+				summaryBody = append(summaryBody, []string{
+					summary.SourcePrincipal,
+					summary.TargetRole,
+					summary.TargetAccount,
+					strconv.Itoa(summary.Count),
+					summary.LastSeen.Format("2006-01-02 15:04:05"),
+					strings.Join(summary.SourceIPs, ", "),
+				})
+			}
+			o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+				Header:    summaryHeader,
+				Body:      summaryBody,
+				TableCols: summaryHeader,
+				Name:      fmt.Sprintf("%s-summary", m.output.CallingModule),
+			})
+		}
 
-		//m.writeLoot()
+		loot := m.writeLoot()
+		if loot != "" {
+			o.Loot.DirectoryName = o.Table.DirectoryName
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     "assume-role-commands",
+				Contents: loot,
+			})
+		}
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %s log entries found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+		fmt.Printf("[%s][%s] %d outbound source principal/target role pairs found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.AssumeRoleSummaries))
 	} else {
 		fmt.Printf("[%s][%s] No matching log entries found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
 	}
 	fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
 }
 
+// summarizeAssumeRoleEntries groups the outbound AssumeRole events by source principal and target role
+func (m *OutboundAssumedRolesModule) summarizeAssumeRoleEntries() {
+	summaries := make(map[string]*AssumeRoleSummary)
+	var keys []string
+	for _, entry := range m.OutboundAssumeRoleEntries {
+		if !strings.HasPrefix(entry.Action, "AssumeRole") || entry.DestinationAccount == aws.ToString(m.Caller.Account) {
+			continue
+		}
+		key := entry.SourcePrincipal + "|" + entry.DestinationPrincipal
+		summary, ok := summaries[key]
+		if !ok {
+			summary = &AssumeRoleSummary{
+				SourcePrincipal: entry.SourcePrincipal,
+				TargetRole:      entry.DestinationPrincipal,
+				TargetAccount:   entry.DestinationAccount,
+			}
+			summaries[key] = summary
+			keys = append(keys, key)
+		}
+		summary.Count++
+		if entry.EventTime.After(summary.LastSeen) {
+			summary.LastSeen = entry.EventTime
+		}
+		if entry.SourceIP != "" && !internal.Contains(entry.SourceIP, summary.SourceIPs) {
+			summary.SourceIPs = append(summary.SourceIPs, entry.SourceIP)
+		}
+	}
+	sort.Strings(keys)
+	m.AssumeRoleSummaries = nil
+	for _, key := range keys {
+		m.AssumeRoleSummaries = append(m.AssumeRoleSummaries, *summaries[key])
+	}
+}
+
+// writeLoot creates assume-role commands for the targets that the caller's own principal has been seen assuming
+func (m *OutboundAssumedRolesModule) writeLoot() string {
+	var out string
+	for _, summary := range m.AssumeRoleSummaries {
+		if !isCallerPrincipal(aws.ToString(m.Caller.Arn), summary.SourcePrincipal) {
+			continue
+		}
+		out += fmt.Sprintf("# %s was assumed %d times, last seen %s\n", summary.TargetRole, summary.Count, summary.LastSeen.Format("2006-01-02 15:04:05"))
+		out += fmt.Sprintf("aws --profile $profile sts assume-role --role-arn %s --role-session-name cloudfox\n\n", summary.TargetRole)
+	}
+	return out
+}
+
+// isCallerPrincipal checks whether a principal ARN from CloudTrail belongs to the caller. CloudTrail records the
+// IAM role (arn:aws:iam::account:role/path/name) while the caller is an assumed role session (arn:aws:sts::account:assumed-role/name/session)
+func isCallerPrincipal(callerArn string, principalArn string) bool {
+	if callerArn == principalArn {
+		return true
+	}
+	if !strings.Contains(callerArn, ":assumed-role/") || !strings.Contains(principalArn, ":role/") {
+		return false
+	}
+	callerParts := strings.Split(callerArn, ":")
+	principalParts := strings.Split(principalArn, ":")
+	if len(callerParts) < 6 || len(principalParts) < 6 || callerParts[4] != principalParts[4] {
+		return false
+	}
+	callerResource := strings.Split(callerParts[5], "/")
+	if len(callerResource) < 2 {
+		return false
+	}
+	return callerResource[1] == GetResourceNameFromArn(principalArn)
+}
+
 func (m *OutboundAssumedRolesModule) Receiver(receiver chan OutboundAssumeRoleEntry, receiverDone chan bool) {
 	defer close(receiverDone)
 	for {
@@ -329,9 +455,8 @@ func (m *OutboundAssumedRolesModule) getAssumeRoleLogEntriesPerRegion(r string,
 	//var LookupAttribute types.LookupAttribute
 	var pages int
 
-	days := 0 - m.Days
 	endTime := aws.Time(time.Now())
-	startTime := endTime.AddDate(0, 0, days)
+	startTime := endTime.AddDate(0, 0, -m.Days)
 	for {
 		LookupEvents, err := m.CloudTrailClient.LookupEvents(
 			context.TODO(),
@@ -349,6 +474,7 @@ func (m *OutboundAssumedRolesModule) getAssumeRoleLogEntriesPerRegion(r string,
 
 			func(o *cloudtrail.Options) {
 				o.Region = r
+				o.Retryer = lookupEventsRetryer()
 			},
 		)
 		if err != nil {
@@ -385,8 +511,10 @@ func (m *OutboundAssumedRolesModule) getAssumeRoleLogEntriesPerRegion(r string,
 					SourcePrincipal:      sourcePrincipal,
 					DestinationAccount:   destinationAccount,
 					DestinationPrincipal: destinationPrincipal,
-					Action:               "sts:AssumeRole",
+					Action:               "AssumeRole",
 					LogTimestamp:         logTimestamp,
+					EventTime:            cloudtrailEvent.EventTime,
+					SourceIP:             cloudtrailEvent.SourceIPAddress,
 				}
 			}
 
@@ -422,9 +550,8 @@ func (m *OutboundAssumedRolesModule) getCrossAccountBatchGetImageEntriesPerRegio
 	//var LookupAttribute types.LookupAttribute
 	var pages int
 
-	days := 0 - m.Days
 	endTime := aws.Time(time.Now())
-	startTime := endTime.AddDate(0, 0, days)
+	startTime := endTime.AddDate(0, 0, -m.Days)
 	for {
 		LookupEvents, err := m.CloudTrailClient.LookupEvents(
 			context.TODO(),
@@ -442,6 +569,7 @@ func (m *OutboundAssumedRolesModule) getCrossAccountBatchGetImageEntriesPerRegio
 
 			func(o *cloudtrail.Options) {
 				o.Region = r
+				o.Retryer = lookupEventsRetryer()
 			},
 		)
 		if err != nil {
@@ -475,6 +603,13 @@ func (m *OutboundAssumedRolesModule) getCrossAccountBatchGetImageEntriesPerRegio
 						if cloudtrailEvent.Resources != nil {
 							destinationAccount = cloudtrailEvent.Resources[0].AccountID
 							destinationPrincipal = cloudtrailEvent.Resources[0].Arn
+							// For AssumeRole events the request parameters are the most reliable place to find the target role
+							if strings.HasPrefix(eventName, "AssumeRole") && cloudtrailEvent.RequestParameters.RoleArn != "" {
+								destinationPrincipal = cloudtrailEvent.RequestParameters.RoleArn
+								if arnParts := strings.Split(destinationPrincipal, ":"); len(arnParts) > 4 {
+									destinationAccount = arnParts[4]
+								}
+							}
 							if sourceAccount != destinationAccount {
 								logTimestamp := cloudtrailEvent.EventTime.Format("2006-01-02 15:04:05")
 								dataReceiver <- OutboundAssumeRoleEntry{
@@ -487,6 +622,8 @@ func (m *OutboundAssumedRolesModule) getCrossAccountBatchGetImageEntriesPerRegio
 									DestinationPrincipal: destinationPrincipal,
 									Action:               aws.ToString(event.EventName),
 									LogTimestamp:         logTimestamp,
+									EventTime:            cloudtrailEvent.EventTime,
+									SourceIP:             cloudtrailEvent.SourceIPAddress,
 								}
 							}
 						}
@@ -507,3 +644,12 @@ func (m *OutboundAssumedRolesModule) getCrossAccountBatchGetImageEntriesPerRegio
 	}
 
 }
+
+// LookupEvents is limited to 2 requests per second per region, so back off much harder than the default retryer does
+func lookupEventsRetryer() aws.Retryer {
+	return retry.NewStandard(func(o *retry.StandardOptions) {
+		o.MaxAttempts = 10
+		o.MaxBackoff = 30 * time.Second
+		o.RateLimiter = ratelimit.None
+	})
+}
@@ -0,0 +1,85 @@
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+func TestSummarizeAssumeRoleEntries(t *testing.T) {
+	m := OutboundAssumedRolesModule{
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:sts::123456789012:assumed-role/deployer/session"),
+			Account: aws.String("123456789012"),
+		},
+		OutboundAssumeRoleEntries: []OutboundAssumeRoleEntry{
+			{
+				Action:               "AssumeRole",
+				SourcePrincipal:      "arn:aws:iam::123456789012:role/deployer",
+				DestinationAccount:   "999999999999",
+				DestinationPrincipal: "arn:aws:iam::999999999999:role/prod-admin",
+				EventTime:            time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				SourceIP:             "10.0.0.1",
+			},
+			{
+				Action:               "AssumeRole",
+				SourcePrincipal:      "arn:aws:iam::123456789012:role/deployer",
+				DestinationAccount:   "999999999999",
+				DestinationPrincipal: "arn:aws:iam::999999999999:role/prod-admin",
+				EventTime:            time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+				SourceIP:             "10.0.0.2",
+			},
+			{
+				// same account, so not outbound
+				Action:               "AssumeRole",
+				SourcePrincipal:      "arn:aws:iam::123456789012:role/deployer",
+				DestinationAccount:   "123456789012",
+				DestinationPrincipal: "arn:aws:iam::123456789012:role/local",
+			},
+			{
+				Action:               "BatchGetImage",
+				SourcePrincipal:      "arn:aws:iam::123456789012:role/deployer",
+				DestinationAccount:   "888888888888",
+				DestinationPrincipal: "arn:aws:ecr:us-east-1:888888888888:repository/app",
+			},
+		},
+	}
+
+	m.summarizeAssumeRoleEntries()
+
+	if len(m.AssumeRoleSummaries) != 1 {
+		t.Fatalf("Expected 1 summary, got %d", len(m.AssumeRoleSummaries))
+	}
+	summary := m.AssumeRoleSummaries[0]
+	if summary.Count != 2 || summary.TargetAccount != "999999999999" || len(summary.SourceIPs) != 2 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if !summary.LastSeen.Equal(time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected last seen time: %s", summary.LastSeen)
+	}
+
+	loot := m.writeLoot()
+	if loot == "" {
+		t.Errorf("Expected an assume-role command for the role the caller has assumed")
+	}
+}
+
+func TestIsCallerPrincipal(t *testing.T) {
+	var tests = []struct {
+		callerArn    string
+		principalArn string
+		expected     bool
+	}{
+		{"arn:aws:iam::123456789012:user/alice", "arn:aws:iam::123456789012:user/alice", true},
+		{"arn:aws:sts::123456789012:assumed-role/deployer/session", "arn:aws:iam::123456789012:role/path/deployer", true},
+		{"arn:aws:sts::123456789012:assumed-role/deployer/session", "arn:aws:iam::111111111111:role/deployer", false},
+		{"arn:aws:sts::123456789012:assumed-role/deployer/session", "arn:aws:iam::123456789012:role/other", false},
+	}
+	for _, test := range tests {
+		if isCallerPrincipal(test.callerArn, test.principalArn) != test.expected {
+			t.Errorf("isCallerPrincipal(%s, %s) should be %t", test.callerArn, test.principalArn, test.expected)
+		}
+	}
+}
@@ -0,0 +1,452 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type OutpostsRoutingModule struct {
+	// General configuration data
+	EC2Client sdk.AWSEC2RoutingClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	OutpostsRoutes []OutpostsRoute
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type OutpostsRoute struct {
+	Region         string
+	LocalGatewayID string
+	OutpostArn     string
+	RouteTableID   string
+	Mode           string
+	VPCs           []string
+	Destination    string
+	Target         string
+	Direction      string
+	State          string
+	Reachability   string
+}
+
+const (
+	outpostsDirectionToOnPrem  = "VPC -> on-prem"
+	outpostsDirectionFromLGW   = "LGW -> on-prem"
+	outpostsDirectionToVPC     = "on-prem -> VPC"
+	outpostsVPCRouteTableMode  = "vpc"
+	outpostsDefaultDestination = "0.0.0.0/0"
+)
+
+func (m *OutpostsRoutingModule) PrintOutpostsRouting(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "outposts-routing"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating Outposts local gateway routes for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan OutpostsRoute)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.OutpostsRoutes, func(i, j int) bool {
+		if m.OutpostsRoutes[i].Region != m.OutpostsRoutes[j].Region {
+			return m.OutpostsRoutes[i].Region < m.OutpostsRoutes[j].Region
+		}
+		if m.OutpostsRoutes[i].LocalGatewayID != m.OutpostsRoutes[j].LocalGatewayID {
+			return m.OutpostsRoutes[i].LocalGatewayID < m.OutpostsRoutes[j].LocalGatewayID
+		}
+		if m.OutpostsRoutes[i].RouteTableID != m.OutpostsRoutes[j].RouteTableID {
+			return m.OutpostsRoutes[i].RouteTableID < m.OutpostsRoutes[j].RouteTableID
+		}
+		return m.OutpostsRoutes[i].Destination < m.OutpostsRoutes[j].Destination
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Local Gateway",
+		"Outpost",
+		"Route Table",
+		"Mode",
+		"VPCs",
+		"Destination",
+		"Target",
+		"Direction",
+		"State",
+		"Reachability",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Local Gateway",
+			"Outpost",
+			"Route Table",
+			"Mode",
+			"VPCs",
+			"Destination",
+			"Target",
+			"Direction",
+			"State",
+			"Reachability",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Local Gateway",
+			"Route Table",
+			"Mode",
+			"VPCs",
+			"Destination",
+			"Target",
+			"Direction",
+			"Reachability",
+		}
+	}
+
+	// Table rows
+	for i := range m.OutpostsRoutes {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				m.OutpostsRoutes[i].Region,
+				m.OutpostsRoutes[i].LocalGatewayID,
+				m.OutpostsRoutes[i].OutpostArn,
+				m.OutpostsRoutes[i].RouteTableID,
+				m.OutpostsRoutes[i].Mode,
+				strings.Join(m.OutpostsRoutes[i].VPCs, ", "),
+				m.OutpostsRoutes[i].Destination,
+				m.OutpostsRoutes[i].Target,
+				m.OutpostsRoutes[i].Direction,
+				m.OutpostsRoutes[i].State,
+				m.OutpostsRoutes[i].Reachability,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s local gateway routes found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No local gateway routes found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *OutpostsRoutingModule) Receiver(receiver chan OutpostsRoute, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.OutpostsRoutes = append(m.OutpostsRoutes, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *OutpostsRoutingModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan OutpostsRoute) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("outposts", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getLocalGatewayRoutesPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+// getLocalGatewayRoutesPerRegion walks every local gateway route table and every VPC route table that points at a
+// local gateway. Together they describe both directions of the path between the Outpost VPCs and the on-premises
+// network behind the local gateway.
+func (m *OutpostsRoutingModule) getLocalGatewayRoutesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan OutpostsRoute) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	localGateways, err := sdk.CachedEC2DescribeLocalGateways(m.EC2Client, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	if len(localGateways) == 0 {
+		return
+	}
+
+	outpostArns := make(map[string]string)
+	for _, localGateway := range localGateways {
+		outpostArns[aws.ToString(localGateway.LocalGatewayId)] = aws.ToString(localGateway.OutpostArn)
+	}
+
+	routeTables, err := sdk.CachedEC2DescribeLocalGatewayRouteTables(m.EC2Client, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	associations, err := sdk.CachedEC2DescribeLocalGatewayRouteTableVpcAssociations(m.EC2Client, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	associatedVPCs := make(map[string][]string)
+	for _, association := range associations {
+		routeTableID := aws.ToString(association.LocalGatewayRouteTableId)
+		associatedVPCs[routeTableID] = append(associatedVPCs[routeTableID], aws.ToString(association.VpcId))
+	}
+
+	for _, routeTable := range routeTables {
+		routeTableID := aws.ToString(routeTable.LocalGatewayRouteTableId)
+		routes, err := sdk.CachedEC2SearchLocalGatewayRoutes(m.EC2Client, aws.ToString(m.Caller.Account), r, routeTableID)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		for _, route := range routes {
+			outpostsRoute := analyzeLocalGatewayRoute(routeTable, route, associatedVPCs[routeTableID])
+			outpostsRoute.Region = r
+			outpostsRoute.OutpostArn = outpostArns[outpostsRoute.LocalGatewayID]
+			dataReceiver <- outpostsRoute
+		}
+	}
+
+	vpcRouteTables, err := sdk.CachedEC2DescribeRouteTables(m.EC2Client, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	for _, outpostsRoute := range analyzeVPCRoutesToLocalGateways(vpcRouteTables, associatedVPCs) {
+		outpostsRoute.Region = r
+		outpostsRoute.OutpostArn = outpostArns[outpostsRoute.LocalGatewayID]
+		dataReceiver <- outpostsRoute
+	}
+}
+
+// analyzeLocalGatewayRoute describes a route in a local gateway route table. Routes that target a virtual interface
+// group send traffic from the Outpost to the on-premises network, and in direct VPC routing mode the local gateway
+// advertises the private VPC CIDRs back over BGP, so on-prem hosts reach instances by their private IPs. In CoIP mode
+// only instances with a customer-owned IP are reachable. Routes that target an ENI pull on-prem traffic into a VPC.
+func analyzeLocalGatewayRoute(routeTable ec2Types.LocalGatewayRouteTable, route ec2Types.LocalGatewayRoute, vpcs []string) OutpostsRoute {
+	outpostsRoute := OutpostsRoute{
+		LocalGatewayID: aws.ToString(routeTable.LocalGatewayId),
+		RouteTableID:   aws.ToString(routeTable.LocalGatewayRouteTableId),
+		Mode:           string(routeTable.Mode),
+		VPCs:           vpcs,
+		Destination:    aws.ToString(route.DestinationCidrBlock),
+		State:          string(route.State),
+	}
+	if outpostsRoute.Destination == "" {
+		outpostsRoute.Destination = aws.ToString(route.DestinationPrefixListId)
+	}
+
+	vpcList := "no associated VPC"
+	if len(vpcs) > 0 {
+		vpcList = strings.Join(vpcs, ", ")
+	}
+
+	switch {
+	case aws.ToString(route.NetworkInterfaceId) != "":
+		outpostsRoute.Target = aws.ToString(route.NetworkInterfaceId)
+		outpostsRoute.Direction = outpostsDirectionToVPC
+		outpostsRoute.Reachability = fmt.Sprintf("On-prem traffic for %s is delivered to %s", outpostsRoute.Destination, outpostsRoute.Target)
+	case aws.ToString(route.LocalGatewayVirtualInterfaceGroupId) != "":
+		outpostsRoute.Target = aws.ToString(route.LocalGatewayVirtualInterfaceGroupId)
+		outpostsRoute.Direction = outpostsDirectionFromLGW
+		if routeTable.Mode == ec2Types.LocalGatewayRouteTableModeCoip {
+			outpostsRoute.Reachability = fmt.Sprintf("Instances with a customer-owned IP in %s are reachable from %s", vpcList, outpostsRoute.Destination)
+		} else {
+			outpostsRoute.Reachability = fmt.Sprintf("Private IPs in %s are reachable from %s", vpcList, outpostsRoute.Destination)
+		}
+	default:
+		outpostsRoute.Target = aws.ToString(route.SubnetId)
+		outpostsRoute.Direction = outpostsDirectionFromLGW
+	}
+
+	if route.State == ec2Types.LocalGatewayRouteStateBlackhole {
+		outpostsRoute.Reachability = "None, route is blackholed"
+	} else if outpostsRoute.Destination == outpostsDefaultDestination && outpostsRoute.Direction == outpostsDirectionFromLGW {
+		outpostsRoute.Reachability = outpostsRoute.Reachability + " (default route, the whole on-prem network)"
+	}
+	return outpostsRoute
+}
+
+// analyzeVPCRoutesToLocalGateways returns the VPC routes that send traffic to a local gateway. These decide which
+// subnets can talk to the on-premises network at all, whatever the local gateway route tables allow.
+func analyzeVPCRoutesToLocalGateways(routeTables []ec2Types.RouteTable, associatedVPCs map[string][]string) []OutpostsRoute {
+	associated := make(map[string]bool)
+	for _, vpcs := range associatedVPCs {
+		for _, vpc := range vpcs {
+			associated[vpc] = true
+		}
+	}
+
+	var outpostsRoutes []OutpostsRoute
+	for _, routeTable := range routeTables {
+		vpcID := aws.ToString(routeTable.VpcId)
+		var subnets []string
+		isMain := false
+		for _, association := range routeTable.Associations {
+			if aws.ToBool(association.Main) {
+				isMain = true
+			}
+			if aws.ToString(association.SubnetId) != "" {
+				subnets = append(subnets, aws.ToString(association.SubnetId))
+			}
+		}
+		sources := strings.Join(subnets, ", ")
+		if isMain {
+			if sources != "" {
+				sources = sources + " and "
+			}
+			sources = sources + "all subnets without their own route table"
+		}
+		if sources == "" {
+			sources = "no subnets"
+		}
+
+		for _, route := range routeTable.Routes {
+			localGatewayID := aws.ToString(route.LocalGatewayId)
+			if localGatewayID == "" {
+				continue
+			}
+			destination := aws.ToString(route.DestinationCidrBlock)
+			if destination == "" {
+				destination = aws.ToString(route.DestinationIpv6CidrBlock)
+			}
+			if destination == "" {
+				destination = aws.ToString(route.DestinationPrefixListId)
+			}
+
+			reachability := fmt.Sprintf("%s can reach %s on-prem", sources, destination)
+			if destination == outpostsDefaultDestination {
+				reachability = fmt.Sprintf("%s send all non-local traffic on-prem", sources)
+			}
+			if !associated[vpcID] {
+				reachability = reachability + " (VPC not associated with a local gateway route table, no return path)"
+			}
+			if route.State == ec2Types.RouteStateBlackhole {
+				reachability = "None, route is blackholed"
+			}
+
+			outpostsRoutes = append(outpostsRoutes, OutpostsRoute{
+				LocalGatewayID: localGatewayID,
+				RouteTableID:   aws.ToString(routeTable.RouteTableId),
+				Mode:           outpostsVPCRouteTableMode,
+				VPCs:           []string{vpcID},
+				Destination:    destination,
+				Target:         localGatewayID,
+				Direction:      outpostsDirectionToOnPrem,
+				State:          string(route.State),
+				Reachability:   reachability,
+			})
+		}
+	}
+	return outpostsRoutes
+}
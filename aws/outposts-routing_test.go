@@ -0,0 +1,107 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+func TestOutpostsRouting(t *testing.T) {
+
+	m := OutpostsRoutingModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines: 3,
+		WrapTable:  false,
+		EC2Client:  &sdk.MockedEC2RoutingClient{},
+	}
+
+	internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintOutpostsRouting(".", 2)
+
+	type expected struct {
+		direction    string
+		reachability string
+	}
+	expectedResults := map[string]expected{
+		"lgw-rtb-11111111111111111 192.168.0.0/16": {outpostsDirectionFromLGW, "Private IPs in vpc-11111111 are reachable from 192.168.0.0/16"},
+		"lgw-rtb-11111111111111111 10.1.5.0/24":    {outpostsDirectionToVPC, "On-prem traffic for 10.1.5.0/24 is delivered to eni-0123456789abcdef0"},
+		"lgw-rtb-22222222222222222 0.0.0.0/0":      {outpostsDirectionFromLGW, "None, route is blackholed"},
+		"rtb-11111111 192.168.0.0/16":              {outpostsDirectionToOnPrem, "subnet-11111111, subnet-22222222 can reach 192.168.0.0/16 on-prem"},
+		"rtb-22222222 0.0.0.0/0":                   {outpostsDirectionToOnPrem, "all subnets without their own route table send all non-local traffic on-prem"},
+	}
+	if len(m.OutpostsRoutes) != len(expectedResults) {
+		t.Errorf("Expected %d routes, got %d", len(expectedResults), len(m.OutpostsRoutes))
+	}
+	for _, route := range m.OutpostsRoutes {
+		key := route.RouteTableID + " " + route.Destination
+		want, ok := expectedResults[key]
+		if !ok {
+			t.Errorf("Unexpected route %s", key)
+			continue
+		}
+		if route.Direction != want.direction {
+			t.Errorf("Route %s: expected direction %s, got %s", key, want.direction, route.Direction)
+		}
+		if route.Reachability != want.reachability {
+			t.Errorf("Route %s: expected reachability %q, got %q", key, want.reachability, route.Reachability)
+		}
+		if route.OutpostArn != "arn:aws:outposts:us-east-1:123456789012:outpost/op-0123456789abcdef0" {
+			t.Errorf("Route %s: unexpected outpost %s", key, route.OutpostArn)
+		}
+	}
+}
+
+func TestAnalyzeLocalGatewayRouteCoip(t *testing.T) {
+	routeTable := ec2Types.LocalGatewayRouteTable{
+		LocalGatewayId:           aws.String("lgw-1"),
+		LocalGatewayRouteTableId: aws.String("lgw-rtb-1"),
+		Mode:                     ec2Types.LocalGatewayRouteTableModeCoip,
+	}
+	route := ec2Types.LocalGatewayRoute{
+		DestinationCidrBlock:                aws.String("0.0.0.0/0"),
+		LocalGatewayVirtualInterfaceGroupId: aws.String("lgw-vif-grp-1"),
+		State:                               ec2Types.LocalGatewayRouteStateActive,
+	}
+
+	got := analyzeLocalGatewayRoute(routeTable, route, []string{"vpc-1"})
+	if !strings.HasPrefix(got.Reachability, "Instances with a customer-owned IP in vpc-1") {
+		t.Errorf("Unexpected reachability %q", got.Reachability)
+	}
+	if !strings.HasSuffix(got.Reachability, "(default route, the whole on-prem network)") {
+		t.Errorf("Expected default route note, got %q", got.Reachability)
+	}
+}
+
+func TestAnalyzeVPCRoutesWithoutAssociation(t *testing.T) {
+	routeTables := []ec2Types.RouteTable{
+		{
+			RouteTableId: aws.String("rtb-1"),
+			VpcId:        aws.String("vpc-1"),
+			Associations: []ec2Types.RouteTableAssociation{{SubnetId: aws.String("subnet-1")}},
+			Routes: []ec2Types.Route{
+				{DestinationCidrBlock: aws.String("10.0.0.0/16"), GatewayId: aws.String("local")},
+				{DestinationCidrBlock: aws.String("172.16.0.0/12"), LocalGatewayId: aws.String("lgw-1")},
+			},
+		},
+	}
+
+	got := analyzeVPCRoutesToLocalGateways(routeTables, map[string][]string{})
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 route, got %d", len(got))
+	}
+	if !strings.HasSuffix(got[0].Reachability, "no return path)") {
+		t.Errorf("Expected missing return path note, got %q", got[0].Reachability)
+	}
+}
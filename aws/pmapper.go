@@ -338,6 +338,8 @@ func (m *PmapperModule) PrintPmapperData(outputDirectory string, verbosity int)
 		})
 		o.PrefixIdentifier = m.AWSProfile
 		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
 
 		header, body := m.createPmapperTableData(outputDirectory)
 		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
@@ -494,12 +496,7 @@ func (m *PmapperModule) writeLoot(outputDirectory string, verbosity int) string
 	}
 	out = admins + "\n\n" + out
 
-	if verbosity > 2 {
-		fmt.Println()
-		fmt.Printf("[%s][%s] %s \n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("Beginning of loot file"))
-		fmt.Print(out)
-		fmt.Printf("[%s][%s] %s \n\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("End of loot file"))
-	}
+	// WriteFullOutput prints the loot to the terminal, see internal.ShowLoot
 	fmt.Printf("[%s][%s] %s \n", cyan(m.output.CallingModule), cyan(m.AWSProfile), magenta(fmt.Sprintf("Loot file with ALL potential paths written to: [%s]", lootFilePath)))
 	return out
 
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/BishopFox/cloudfox/aws/sdk"
 	"github.com/BishopFox/cloudfox/internal"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ram"
@@ -19,7 +20,10 @@ import (
 
 type RAMModule struct {
 	// General configuration data
-	RAMClient *ram.Client
+	RAMClient sdk.RAMClientInterface
+	// OrganizationsClient lists the organization's accounts, to tell whether the owner of an inbound share is outside
+	// of it
+	OrganizationsClient sdk.OrganizationsClientInterface
 
 	Caller        sts.GetCallerIdentityOutput
 	AWSRegions    []string
@@ -36,6 +40,9 @@ type RAMModule struct {
 	// Used to store output data for pretty printing
 	output internal.OutputData2
 	modLog *logrus.Entry
+
+	orgAccounts      []string
+	orgAccountsKnown bool
 }
 
 type Resource struct {
@@ -45,6 +52,11 @@ type Resource struct {
 	Owner      string
 	Type       string
 	ShareType  string
+	Direction  string
+	Arn        string
+	Principal  string
+	Status     string
+	External   string
 }
 
 func (m *RAMModule) PrintRAM(outputDirectory string, verbosity int) {
@@ -61,6 +73,20 @@ func (m *RAMModule) PrintRAM(outputDirectory string, verbosity int) {
 
 	fmt.Printf("[%s][%s] Enumerating shared resources for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
 
+	// Only the management account and delegated admins can list the organization's accounts. Without them, the owner
+	// of an inbound share can't be told apart from an account outside the organization.
+	if m.OrganizationsClient != nil {
+		accounts, err := sdk.CachedOrganizationsListAccounts(m.OrganizationsClient, aws.ToString(m.Caller.Account))
+		if err != nil {
+			m.modLog.Error(err.Error())
+		} else {
+			for _, account := range accounts {
+				m.orgAccounts = append(m.orgAccounts, aws.ToString(account.Id))
+			}
+			m.orgAccountsKnown = true
+		}
+	}
+
 	wg := new(sync.WaitGroup)
 
 	// Create a channel to signal the spinner aka task status goroutine to finish
@@ -97,9 +123,13 @@ func (m *RAMModule) PrintRAM(outputDirectory string, verbosity int) {
 		"Account",
 		"Region",
 		"Share Name",
-		"Type",
+		"Direction",
+		"Resource Type",
+		"Resource Arn",
 		"Owner",
-		"Share Type",
+		"Principal",
+		"Status",
+		"External?",
 	}
 
 	// If the user specified table columns, use those.
@@ -118,19 +148,24 @@ func (m *RAMModule) PrintRAM(outputDirectory string, verbosity int) {
 			"Account",
 			"Region",
 			"Share Name",
-			"Type",
+			"Direction",
+			"Resource Type",
+			"Resource Arn",
 			"Owner",
-			"Share Type",
+			"Principal",
+			"Status",
+			"External?",
 		}
 		// Otherwise, use the default columns.
 	} else {
 		tableCols = []string{
-			"Service",
 			"Region",
-			"Share Name",
-			"Type",
-			"Owner",
-			"Share Type",
+			"Direction",
+			"Resource Type",
+			"Resource Arn",
+			"Principal",
+			"Status",
+			"External?",
 		}
 	}
 
@@ -142,9 +177,13 @@ func (m *RAMModule) PrintRAM(outputDirectory string, verbosity int) {
 				aws.ToString(m.Caller.Account),
 				m.Resources[i].Region,
 				m.Resources[i].Name,
+				m.Resources[i].Direction,
 				m.Resources[i].Type,
+				m.Resources[i].Arn,
 				m.Resources[i].Owner,
-				m.Resources[i].ShareType,
+				m.Resources[i].Principal,
+				m.Resources[i].Status,
+				m.Resources[i].External,
 			},
 		)
 
@@ -167,8 +206,17 @@ func (m *RAMModule) PrintRAM(outputDirectory string, verbosity int) {
 		})
 		o.PrefixIdentifier = m.AWSProfile
 		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
-		o.WriteFullOutput(o.Table.TableFiles, nil)
-		//m.writeLoot(o.Table.DirectoryName, verbosity)
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		loot := m.writeLoot()
+		if loot != "" {
+			o.Loot.DirectoryName = o.Table.DirectoryName
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     "ram-shared-in-resources",
+				Contents: loot,
+			})
+		}
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
 		fmt.Printf("[%s][%s] %s resources found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
 	} else {
 		fmt.Printf("[%s][%s] No resources found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
@@ -176,6 +224,39 @@ func (m *RAMModule) PrintRAM(outputDirectory string, verbosity int) {
 	fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
 }
 
+// writeLoot creates commands to look at the resources that have been shared into this account, which are easy to forget about
+func (m *RAMModule) writeLoot() string {
+	var out string
+	var seen []string
+	for _, resource := range m.Resources {
+		if resource.Direction != "Inbound" || internal.Contains(resource.Arn, seen) {
+			continue
+		}
+		seen = append(seen, resource.Arn)
+		out += fmt.Sprintf("# %s shared by %s (share: %s)\n", resource.Type, resource.Owner, resource.Name)
+		out += fmt.Sprintf("%s\n\n", ramSharedResourceCommand(resource))
+	}
+	return out
+}
+
+func ramSharedResourceCommand(resource Resource) string {
+	id := GetResourceNameFromArn(resource.Arn)
+	switch resource.Type {
+	case "ec2:Subnet":
+		return fmt.Sprintf("aws --profile $profile --region %s ec2 describe-subnets --subnet-ids %s", resource.Region, id)
+	case "ec2:TransitGateway":
+		return fmt.Sprintf("aws --profile $profile --region %s ec2 describe-transit-gateways --transit-gateway-ids %s", resource.Region, id)
+	case "ec2:PrefixList":
+		return fmt.Sprintf("aws --profile $profile --region %s ec2 get-managed-prefix-list-entries --prefix-list-id %s", resource.Region, id)
+	case "route53resolver:ResolverRule":
+		return fmt.Sprintf("aws --profile $profile --region %s route53resolver get-resolver-rule --resolver-rule-id %s", resource.Region, id)
+	case "license-manager:LicenseConfiguration":
+		return fmt.Sprintf("aws --profile $profile --region %s license-manager get-license-configuration --license-configuration-arn %s", resource.Region, resource.Arn)
+	default:
+		return fmt.Sprintf("aws --profile $profile --region %s ram list-resources --resource-owner OTHER-ACCOUNTS --resource-arns %s", resource.Region, resource.Arn)
+	}
+}
+
 func (m *RAMModule) executeChecks(r string, wg *sync.WaitGroup, dataReceiver chan Resource) {
 	defer wg.Done()
 	servicemap := &awsservicemap.AwsServiceMap{
@@ -239,6 +320,7 @@ func (m *RAMModule) getRAMResourcesPerRegion(r string, dataReceiver chan Resourc
 				shareName = aws.ToString(resourceShare.Name)
 				resourceShareArns = append(resourceShareArns, aws.ToString(resourceShare.ResourceShareArn))
 				ownerID := aws.ToString(resourceShare.OwningAccountId)
+				principals := m.getRAMSharePrincipals(r, shareType, resourceShare)
 
 				for {
 					ListResources, err := m.RAMClient.ListResources(
@@ -260,20 +342,29 @@ func (m *RAMModule) getRAMResourcesPerRegion(r string, dataReceiver chan Resourc
 
 					for _, resource := range ListResources.Resources {
 						resourceType = aws.ToString(resource.Type)
-						var shareDirection string
+						var shareDirection, direction string
 						if string(shareType) == "OTHER-ACCOUNTS" {
 							shareDirection = "Inbound share (Another account shared this with me)"
+							direction = "Inbound"
 						} else {
 							shareDirection = "Outbound share (I've shared this resource with others)"
+							direction = "Outbound"
 						}
 
-						dataReceiver <- Resource{
-							AWSService: "RAM",
-							Name:       shareName,
-							Type:       resourceType,
-							Region:     r,
-							Owner:      ownerID,
-							ShareType:  shareDirection,
+						for _, principal := range principals {
+							dataReceiver <- Resource{
+								AWSService: "RAM",
+								Name:       shareName,
+								Type:       resourceType,
+								Region:     r,
+								Owner:      ownerID,
+								ShareType:  shareDirection,
+								Direction:  direction,
+								Arn:        aws.ToString(resource.Arn),
+								Principal:  principal.id,
+								Status:     string(resource.Status),
+								External:   principal.external,
+							}
 						}
 					}
 					if ListResources.NextToken != nil {
@@ -296,3 +387,81 @@ func (m *RAMModule) getRAMResourcesPerRegion(r string, dataReceiver chan Resourc
 		}
 	}
 }
+
+type ramPrincipal struct {
+	id       string
+	external string
+}
+
+// getRAMSharePrincipals returns who a resource share is shared with. For outbound shares we can list the principals
+// and RAM tells us if they are outside of our organization. For inbound shares the only principal we can see is us,
+// so we report the owning account and look it up in the organization's accounts.
+func (m *RAMModule) getRAMSharePrincipals(r string, shareType ramTypes.ResourceOwner, resourceShare ramTypes.ResourceShare) []ramPrincipal {
+	var principals []ramPrincipal
+
+	if shareType != ramTypes.ResourceOwnerSelf {
+		owner := aws.ToString(resourceShare.OwningAccountId)
+		return append(principals, ramPrincipal{
+			id:       owner,
+			external: ramOwnerExternal(owner, aws.ToString(m.Caller.Account), m.orgAccounts, m.orgAccountsKnown),
+		})
+	}
+
+	var PaginationControl *string
+	for {
+		ListPrincipals, err := m.RAMClient.ListPrincipals(
+			context.TODO(),
+			&ram.ListPrincipalsInput{
+				NextToken:         PaginationControl,
+				ResourceOwner:     shareType,
+				ResourceShareArns: []string{aws.ToString(resourceShare.ResourceShareArn)},
+			},
+			func(o *ram.Options) {
+				o.Region = r
+			},
+		)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			break
+		}
+
+		for _, principal := range ListPrincipals.Principals {
+			external := "No"
+			if aws.ToBool(principal.External) {
+				external = "Yes"
+			}
+			principals = append(principals, ramPrincipal{
+				id:       aws.ToString(principal.Id),
+				external: external,
+			})
+		}
+
+		if ListPrincipals.NextToken != nil {
+			PaginationControl = ListPrincipals.NextToken
+		} else {
+			break
+		}
+	}
+
+	// Still report the resources in a share that hasn't been shared with anyone yet
+	if len(principals) == 0 {
+		principals = append(principals, ramPrincipal{id: "", external: "No"})
+	}
+	return principals
+}
+
+// ramOwnerExternal says whether the owner of an inbound share is outside of the organization. AllowExternalPrincipals
+// doesn't tell, it only says the owner may share with accounts outside of their organization.
+func ramOwnerExternal(owner string, accountID string, orgAccounts []string, orgAccountsKnown bool) string {
+	if owner == accountID {
+		return "No"
+	}
+	if !orgAccountsKnown {
+		return "Unknown"
+	}
+	if internal.Contains(owner, orgAccounts) {
+		return "No"
+	}
+	return "Yes"
+}
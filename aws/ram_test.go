@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestRAM(t *testing.T) {
+	m := RAMModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:          3,
+		RAMClient:           &sdk.MockedRAMClient{},
+		OrganizationsClient: &sdk.MockedOrgClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintRAM(".", 2)
+
+	// Both inbound shares allow external principals, only the owner's organization membership decides
+	expectedExternal := map[string]string{
+		"network-share/111111111111": "No",
+		"vendor-share/999999999999":  "Yes",
+		"builds-share/222222222222":  "No",
+		"builds-share/444455556666":  "Yes",
+	}
+	if len(m.Resources) != len(expectedExternal) {
+		t.Fatalf("Expected %d rows, got %d", len(expectedExternal), len(m.Resources))
+	}
+	for _, resource := range m.Resources {
+		key := resource.Name + "/" + resource.Principal
+		if resource.External != expectedExternal[key] {
+			t.Errorf("%s: expected External? %s, got %s", key, expectedExternal[key], resource.External)
+		}
+	}
+
+	lootFilePath := filepath.Join(".", "cloudfox-output/aws/unittesting-123456789012/loot/ram-shared-in-resources.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	expected := "aws --profile $profile --region us-east-1 ec2 describe-subnets --subnet-ids subnet-0a1b2c3d"
+	if !strings.Contains(string(lootFile), expected) {
+		t.Errorf("Expected %s to be in the loot file", expected)
+	}
+}
+
+func TestRAMOwnerExternal(t *testing.T) {
+	orgAccounts := []string{"111111111111", "222222222222"}
+	tests := []struct {
+		owner            string
+		orgAccountsKnown bool
+		want             string
+	}{
+		{owner: "111111111111", orgAccountsKnown: true, want: "No"},
+		{owner: "999999999999", orgAccountsKnown: true, want: "Yes"},
+		// Member accounts can't list the organization's accounts
+		{owner: "999999999999", orgAccountsKnown: false, want: "Unknown"},
+		{owner: "123456789012", orgAccountsKnown: false, want: "No"},
+	}
+	for _, test := range tests {
+		if got := ramOwnerExternal(test.owner, "123456789012", orgAccounts, test.orgAccountsKnown); got != test.want {
+			t.Errorf("ramOwnerExternal(%s, known=%v) = %s, want %s", test.owner, test.orgAccountsKnown, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	rdsTypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type RDSProxyModule struct {
+	// General configuration data
+	RDSClient sdk.RDSClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Proxies        []RDSProxy
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type RDSProxy struct {
+	Region     string
+	Name       string
+	Arn        string
+	Engine     string
+	Endpoint   string
+	Status     string
+	VpcId      string
+	Role       string
+	IAMAuth    string
+	RequireTLS string
+	Secrets    []string
+}
+
+func (m *RDSProxyModule) PrintRDSProxies(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "rds-proxy"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating RDS proxies for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan RDSProxy)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Name",
+		"Engine",
+		"Endpoint",
+		"Status",
+		"VPC",
+		"Role",
+		"IAM Auth",
+		"Require TLS",
+		"Secrets",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Name",
+			"Engine",
+			"Endpoint",
+			"Status",
+			"VPC",
+			"Role",
+			"IAM Auth",
+			"Require TLS",
+			"Secrets",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Name",
+			"Engine",
+			"Endpoint",
+			"IAM Auth",
+			"Require TLS",
+			"Secrets",
+		}
+	}
+
+	// Table rows
+	for i := range m.Proxies {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				m.Proxies[i].Region,
+				m.Proxies[i].Name,
+				m.Proxies[i].Engine,
+				m.Proxies[i].Endpoint,
+				m.Proxies[i].Status,
+				m.Proxies[i].VpcId,
+				m.Proxies[i].Role,
+				m.Proxies[i].IAMAuth,
+				m.Proxies[i].RequireTLS,
+				strings.Join(m.Proxies[i].Secrets, ", "),
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+
+		loot := m.writeLoot()
+		if loot != "" {
+			o.Loot.DirectoryName = o.Table.DirectoryName
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     "rds-proxy-secrets",
+				Contents: loot,
+			})
+		}
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d RDS proxies found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body))
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No RDS proxies found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *RDSProxyModule) writeLoot() string {
+	var out string
+	for _, proxy := range m.Proxies {
+		if len(proxy.Secrets) == 0 {
+			continue
+		}
+		out += fmt.Sprintf("# Proxy: %s (%s) - IAM Auth: %s, Require TLS: %s\n", proxy.Name, proxy.Endpoint, proxy.IAMAuth, proxy.RequireTLS)
+		for _, secret := range proxy.Secrets {
+			out += fmt.Sprintf("aws --profile $profile --region %s secretsmanager get-secret-value --secret-id %s\n", proxy.Region, secret)
+		}
+		out += "\n"
+	}
+	return out
+}
+
+func (m *RDSProxyModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan RDSProxy) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("rds", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getRDSProxiesPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *RDSProxyModule) Receiver(receiver chan RDSProxy, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Proxies = append(m.Proxies, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *RDSProxyModule) getRDSProxiesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan RDSProxy) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	proxies, err := sdk.CachedRDSDescribeDBProxies(m.RDSClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, proxy := range proxies {
+		requireTLS := "No"
+		if aws.ToBool(proxy.RequireTLS) {
+			requireTLS = "Yes"
+		}
+
+		var secrets []string
+		for _, auth := range proxy.Auth {
+			if auth.SecretArn != nil {
+				secrets = append(secrets, aws.ToString(auth.SecretArn))
+			}
+		}
+
+		dataReceiver <- RDSProxy{
+			Region:     r,
+			Name:       aws.ToString(proxy.DBProxyName),
+			Arn:        aws.ToString(proxy.DBProxyArn),
+			Engine:     aws.ToString(proxy.EngineFamily),
+			Endpoint:   aws.ToString(proxy.Endpoint),
+			Status:     string(proxy.Status),
+			VpcId:      aws.ToString(proxy.VpcId),
+			Role:       aws.ToString(proxy.RoleArn),
+			IAMAuth:    getProxyIAMAuth(proxy.Auth),
+			RequireTLS: requireTLS,
+			Secrets:    secrets,
+		}
+	}
+}
+
+// A proxy can have multiple auth configs. If any of them allow password auth, so does the proxy.
+func getProxyIAMAuth(authConfigs []rdsTypes.UserAuthConfigInfo) string {
+	if len(authConfigs) == 0 {
+		return "Unknown"
+	}
+	for _, auth := range authConfigs {
+		if auth.IAMAuth != rdsTypes.IAMAuthModeRequired {
+			return string(auth.IAMAuth)
+		}
+	}
+	return string(rdsTypes.IAMAuthModeRequired)
+}
@@ -0,0 +1,403 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	cloudtrailTypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/sirupsen/logrus"
+)
+
+type RecentIAMChangesModule struct {
+	// General configuration data
+	CloudTrailClient sdk.CloudTrailClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSOutputType string
+	AWSTableCols  string
+
+	AWSProfile string
+	WrapTable  bool
+	// Days is how far back we look for IAM events
+	Days int
+	// ExpectedPrincipals are the ARNs that are supposed to change IAM, e.g. the deployment role. Changes by anyone
+	// else are flagged. Assumed role sessions match the ARN of their role.
+	ExpectedPrincipals []string
+	// CorporateCIDRs are the networks changes are supposed to come from. Changes from other IPs are flagged, changes
+	// made by AWS services on someone's behalf have no IP and are not.
+	CorporateCIDRs []string
+
+	// Main module data
+	Changes        []IAMChange
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+// IAMChange is one high-risk IAM API call
+type IAMChange struct {
+	EventID       string
+	EventTime     time.Time
+	EventName     string
+	Principal     string
+	PrincipalType string
+	Target        string
+	Details       string
+	SourceIP      string
+	Result        string
+	Findings      []string
+}
+
+// iamChangeEvent holds the parts of an IAM CloudTrail event we need
+type iamChangeEvent struct {
+	UserIdentity struct {
+		Type           string `json:"type"`
+		Arn            string `json:"arn"`
+		InvokedBy      string `json:"invokedBy"`
+		SessionContext struct {
+			SessionIssuer struct {
+				Arn string `json:"arn"`
+			} `json:"sessionIssuer"`
+		} `json:"sessionContext"`
+	} `json:"userIdentity"`
+	EventTime         time.Time `json:"eventTime"`
+	EventName         string    `json:"eventName"`
+	SourceIPAddress   string    `json:"sourceIPAddress"`
+	ErrorCode         string    `json:"errorCode"`
+	RequestParameters struct {
+		UserName   string `json:"userName"`
+		RoleName   string `json:"roleName"`
+		PolicyArn  string `json:"policyArn"`
+		PolicyName string `json:"policyName"`
+	} `json:"requestParameters"`
+}
+
+const (
+	recentIAMChangesDefaultDays = 7
+	// IAM is a global service, CloudTrail records its events in us-east-1
+	iamEventsRegion = "us-east-1"
+	iamEventSource  = "iam.amazonaws.com"
+)
+
+// highRiskIAMEvents are the IAM calls that create principals, give them permissions or credentials, or change who can
+// assume a role
+var highRiskIAMEvents = []string{
+	"CreateUser",
+	"CreateRole",
+	"AttachRolePolicy",
+	"PutRolePolicy",
+	"CreateAccessKey",
+	"UpdateAssumeRolePolicy",
+}
+
+func (m *RecentIAMChangesModule) PrintRecentIAMChanges(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "recent-iam-changes"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+	if m.Days <= 0 {
+		m.Days = recentIAMChangesDefaultDays
+	}
+
+	corporateNetworks, err := parseCIDRs(m.CorporateCIDRs)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		fmt.Printf("[%s][%s] %s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), err)
+		return
+	}
+
+	fmt.Printf("[%s][%s] Looking for high-risk IAM changes in the last %d days of CloudTrail events for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.Days, aws.ToString(m.Caller.Account))
+	if len(m.ExpectedPrincipals) == 0 && len(corporateNetworks) == 0 {
+		fmt.Printf("[%s][%s] Add --expected-principals and --corporate-cidrs to flag changes by unexpected principals or from unexpected networks.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+
+	endTime := time.Now()
+	startTime := endTime.AddDate(0, 0, -m.Days)
+	m.Changes = m.getIAMChanges(startTime, endTime)
+	for i := range m.Changes {
+		m.Changes[i].Findings = m.flagIAMChange(m.Changes[i], corporateNetworks)
+	}
+	sort.Slice(m.Changes, func(i, j int) bool {
+		return m.Changes[i].EventTime.After(m.Changes[j].EventTime)
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Time",
+		"Event",
+		"Principal",
+		"Principal Type",
+		"Target",
+		"Details",
+		"Source IP",
+		"Result",
+		"Finding",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Time",
+			"Event",
+			"Principal",
+			"Principal Type",
+			"Target",
+			"Details",
+			"Source IP",
+			"Result",
+			"Finding",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Time",
+			"Event",
+			"Principal",
+			"Target",
+			"Source IP",
+			"Result",
+			"Finding",
+		}
+	}
+
+	// Table rows
+	var flagged int
+	for _, change := range m.Changes {
+		finding := strings.Join(change.Findings, ", ")
+		if finding != "" {
+			flagged++
+			finding = magenta(finding)
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				change.EventTime.UTC().Format(time.RFC3339),
+				change.EventName,
+				change.Principal,
+				change.PrincipalType,
+				change.Target,
+				change.Details,
+				change.SourceIP,
+				change.Result,
+				finding,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		if flagged > 0 {
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     m.output.CallingModule,
+				Contents: m.writeLoot(),
+			})
+		}
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d high-risk IAM changes found, %d of them flagged as potentially unauthorized.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), flagged)
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No high-risk IAM changes found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+// getIAMChanges looks up the IAM events in the time window and keeps the high-risk ones. LookupEvents takes a single
+// lookup attribute, so the event names are filtered here. The events are not cached, CloudTrail is the source of truth
+// for them.
+func (m *RecentIAMChangesModule) getIAMChanges(startTime time.Time, endTime time.Time) []IAMChange {
+	var changes []IAMChange
+	var PaginationControl *string
+	for {
+		LookupEvents, err := m.CloudTrailClient.LookupEvents(
+			context.TODO(),
+			&cloudtrail.LookupEventsInput{
+				StartTime: aws.Time(startTime),
+				EndTime:   aws.Time(endTime),
+				LookupAttributes: []cloudtrailTypes.LookupAttribute{
+					{
+						AttributeKey:   cloudtrailTypes.LookupAttributeKeyEventSource,
+						AttributeValue: aws.String(iamEventSource),
+					},
+				},
+				NextToken: PaginationControl,
+			},
+			func(o *cloudtrail.Options) {
+				o.Region = iamEventsRegion
+				o.Retryer = lookupEventsRetryer()
+			},
+		)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			break
+		}
+
+		for _, event := range LookupEvents.Events {
+			if !internal.Contains(aws.ToString(event.EventName), highRiskIAMEvents) {
+				continue
+			}
+			var parsed iamChangeEvent
+			if err := json.Unmarshal([]byte(aws.ToString(event.CloudTrailEvent)), &parsed); err != nil {
+				m.modLog.Error(err.Error())
+				continue
+			}
+			changes = append(changes, analyzeIAMChangeEvent(aws.ToString(event.EventId), aws.ToTime(event.EventTime), parsed))
+		}
+
+		// The "NextToken" value is nil when there's no more data to return.
+		if LookupEvents.NextToken == nil {
+			break
+		}
+		PaginationControl = LookupEvents.NextToken
+	}
+	return changes
+}
+
+func analyzeIAMChangeEvent(eventID string, eventTime time.Time, event iamChangeEvent) IAMChange {
+	change := IAMChange{
+		EventID:       eventID,
+		EventTime:     event.EventTime,
+		EventName:     event.EventName,
+		Principal:     iamChangePrincipal(event),
+		PrincipalType: event.UserIdentity.Type,
+		SourceIP:      event.SourceIPAddress,
+		Result:        "Success",
+	}
+	if change.EventTime.IsZero() {
+		change.EventTime = eventTime
+	}
+	if event.ErrorCode != "" {
+		change.Result = event.ErrorCode
+	}
+
+	params := event.RequestParameters
+	switch {
+	case params.RoleName != "":
+		change.Target = "role/" + params.RoleName
+	case params.UserName != "":
+		change.Target = "user/" + params.UserName
+	case event.EventName == "CreateAccessKey":
+		// Without a user name, CreateAccessKey creates a key for the caller
+		change.Target = change.Principal
+	}
+	switch {
+	case params.PolicyArn != "":
+		change.Details = params.PolicyArn
+	case params.PolicyName != "":
+		change.Details = "inline policy " + params.PolicyName
+	}
+	return change
+}
+
+// iamChangePrincipal returns the role behind assumed role sessions, so --expected-principals can list roles
+func iamChangePrincipal(event iamChangeEvent) string {
+	switch {
+	case event.UserIdentity.Type == "AssumedRole" && event.UserIdentity.SessionContext.SessionIssuer.Arn != "":
+		return event.UserIdentity.SessionContext.SessionIssuer.Arn
+	case event.UserIdentity.Arn != "":
+		return event.UserIdentity.Arn
+	case event.UserIdentity.InvokedBy != "":
+		return event.UserIdentity.InvokedBy
+	}
+	return event.UserIdentity.Type
+}
+
+func (m *RecentIAMChangesModule) flagIAMChange(change IAMChange, corporateNetworks []*net.IPNet) []string {
+	var findings []string
+	if change.PrincipalType == "Root" {
+		findings = append(findings, "Root user")
+	} else if len(m.ExpectedPrincipals) > 0 && !internal.Contains(change.Principal, m.ExpectedPrincipals) {
+		findings = append(findings, "Unexpected principal")
+	}
+	// AWS services acting for someone put their own name here instead of an IP
+	ip := net.ParseIP(change.SourceIP)
+	if ip != nil && len(corporateNetworks) > 0 && !ipInNetworks(ip, corporateNetworks) {
+		findings = append(findings, "Source IP outside corporate CIDRs")
+	}
+	return findings
+}
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func ipInNetworks(ip net.IP, networks []*net.IPNet) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *RecentIAMChangesModule) writeLoot() string {
+	var out string
+	out += fmt.Sprintln("#############################################")
+	out += fmt.Sprintln("# Pull the full CloudTrail events of the IAM changes flagged as potentially unauthorized.")
+	out += fmt.Sprintln("# Set the $profile environment variable to the profile you are going to use, e.g. export profile=dev-prod.")
+	out += fmt.Sprintln("#############################################")
+	out += fmt.Sprintln("")
+	for _, change := range m.Changes {
+		if len(change.Findings) == 0 {
+			continue
+		}
+		out += fmt.Sprintf("# %s %s %s from %s: %s\n", change.Principal, change.EventName, change.Target, change.SourceIP, strings.Join(change.Findings, ", "))
+		out += fmt.Sprintf("aws --profile $profile --region %s cloudtrail lookup-events --lookup-attributes AttributeKey=EventId,AttributeValue=%s\n\n", iamEventsRegion, change.EventID)
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package aws
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestRecentIAMChanges(t *testing.T) {
+	m := RecentIAMChangesModule{
+		AWSProfile: "unittesting",
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		WrapTable:          false,
+		CloudTrailClient:   &sdk.MockedCloudTrailClient{},
+		ExpectedPrincipals: []string{"arn:aws:iam::123456789012:role/deployer", "arn:aws:iam::123456789012:user/admin"},
+		CorporateCIDRs:     []string{"10.0.0.0/8"},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+	tmpDir := "."
+
+	m.PrintRecentIAMChanges(tmpDir, 2)
+
+	// GetRole is not a change and CreateUser is older than the default 7 days
+	expected := map[string]struct {
+		principal string
+		target    string
+		result    string
+		findings  string
+	}{
+		"CreateRole":             {principal: "arn:aws:iam::123456789012:role/deployer", target: "role/app-server", result: "Success"},
+		"AttachRolePolicy":       {principal: "arn:aws:iam::123456789012:role/deployer", target: "role/app-server", result: "Success"},
+		"PutRolePolicy":          {principal: "arn:aws:iam::123456789012:user/admin", target: "role/app-server", result: "Success"},
+		"CreateAccessKey":        {principal: "arn:aws:iam::123456789012:user/mallory", target: "user/admin", result: "Success", findings: "Unexpected principal, Source IP outside corporate CIDRs"},
+		"UpdateAssumeRolePolicy": {principal: "arn:aws:iam::123456789012:user/mallory", target: "role/deployer", result: "AccessDenied", findings: "Unexpected principal, Source IP outside corporate CIDRs"},
+	}
+	if len(m.Changes) != len(expected) {
+		t.Fatalf("Expected %d IAM changes, got %d", len(expected), len(m.Changes))
+	}
+	for _, change := range m.Changes {
+		want, ok := expected[change.EventName]
+		if !ok {
+			t.Errorf("Unexpected IAM change %s", change.EventName)
+			continue
+		}
+		findings := strings.Join(change.Findings, ", ")
+		if change.Principal != want.principal || change.Target != want.target || change.Result != want.result || findings != want.findings {
+			t.Errorf("Expected %s by %s on %s with result %s and findings %q, got %s on %s with result %s and findings %q", change.EventName, want.principal, want.target, want.result, want.findings, change.Principal, change.Target, change.Result, findings)
+		}
+	}
+
+	lootFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/loot/recent-iam-changes.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	if !strings.Contains(string(lootFile), "AttributeKey=EventId,AttributeValue=iam-4") {
+		t.Errorf("Expected the loot file to look up the access key created by mallory")
+	}
+	if strings.Contains(string(lootFile), "iam-1") {
+		t.Errorf("Did not expect changes without findings in the loot file")
+	}
+}
@@ -0,0 +1,468 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type RekognitionStreamingModule struct {
+	// General configuration data
+	RekognitionClient sdk.RekognitionClientInterface
+	S3Client          sdk.AWSS3ClientInterface
+	IAMClient         sdk.AWSIAMClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	StreamProcessors []RekognitionStreamProcessor
+	CommandCounter   internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type RekognitionStreamProcessor struct {
+	Region      string
+	Name        string
+	Arn         string
+	Status      string
+	InputStream string
+	// Results go either to an S3 bucket (face search and connected home) or to a Kinesis data stream (face search only)
+	OutputBucket         string
+	OutputPrefix         string
+	OutputStream         string
+	FaceSearchCollection string
+	Labels               []string
+	Role                 string
+	// RoleStreamActions are the Kinesis Video Streams actions the role is allowed on every stream
+	RoleStreamActions []string
+	PublicOutput      bool
+	// SharedOutputWith are the processors reading other streams that write to the same bucket
+	SharedOutputWith []string
+	Findings         []string
+}
+
+const (
+	rekognitionFaceSearch     = "Face search (biometric data)"
+	rekognitionPublicOutput   = "Output bucket is public"
+	rekognitionRoleAllStreams = "Role can read every video stream"
+	rekognitionSharedOutput   = "Output bucket shared with other cameras"
+)
+
+// rekognitionRoleStreamActions let the processor role read video. The service needs them on the input stream only.
+var rekognitionRoleStreamActions = []string{
+	"kinesisvideo:GetDataEndpoint",
+	"kinesisvideo:GetMedia",
+	"kinesisvideo:GetHLSStreamingSessionURL",
+	"kinesisvideo:GetClip",
+}
+
+func (m *RekognitionStreamingModule) PrintRekognitionStreaming(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "rekognition-streaming"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating Rekognition stream processors for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan RekognitionStreamProcessor)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.StreamProcessors, func(i, j int) bool {
+		if m.StreamProcessors[i].Region != m.StreamProcessors[j].Region {
+			return m.StreamProcessors[i].Region < m.StreamProcessors[j].Region
+		}
+		return m.StreamProcessors[i].Name < m.StreamProcessors[j].Name
+	})
+
+	// Both checks look across processors, so they run once everything is collected
+	m.flagPublicOutputs()
+	flagRekognitionSharedOutputs(m.StreamProcessors)
+	for i := range m.StreamProcessors {
+		m.StreamProcessors[i].Findings = rekognitionStreamProcessorFindings(m.StreamProcessors[i])
+	}
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Processor",
+		"Status",
+		"Input Stream",
+		"Output",
+		"Face Collection",
+		"Labels",
+		"Role",
+		"Role Stream Access",
+		"Findings",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Processor",
+			"Status",
+			"Input Stream",
+			"Output",
+			"Face Collection",
+			"Labels",
+			"Role",
+			"Role Stream Access",
+			"Findings",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Processor",
+			"Status",
+			"Input Stream",
+			"Output",
+			"Face Collection",
+			"Findings",
+		}
+	}
+
+	// Table rows
+	for _, processor := range m.StreamProcessors {
+		var findings []string
+		for _, finding := range processor.Findings {
+			findings = append(findings, magenta(finding))
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				processor.Region,
+				processor.Name,
+				processor.Status,
+				kinesisVideoStreamName(processor.InputStream),
+				processor.outputLocation(),
+				processor.FaceSearchCollection,
+				strings.Join(processor.Labels, ", "),
+				GetResourceNameFromArn(processor.Role),
+				strings.Join(processor.RoleStreamActions, ", "),
+				strings.Join(findings, "\n"),
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     m.output.CallingModule,
+			Contents: m.writeLoot(),
+		})
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d stream processors found, %d run face search.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), m.countFaceSearch())
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No Rekognition stream processors found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *RekognitionStreamingModule) countFaceSearch() int {
+	var count int
+	for _, processor := range m.StreamProcessors {
+		if processor.FaceSearchCollection != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// outputLocation is where the processor writes its results, an S3 location or a Kinesis data stream
+func (p RekognitionStreamProcessor) outputLocation() string {
+	if p.OutputBucket != "" {
+		return fmt.Sprintf("s3://%s/%s", p.OutputBucket, p.OutputPrefix)
+	}
+	return p.OutputStream
+}
+
+// kinesisVideoStreamName returns the name from a stream ARN, which ends in the stream name and its creation time
+func kinesisVideoStreamName(streamArn string) string {
+	parts := strings.Split(streamArn, "/")
+	if len(parts) < 2 {
+		return streamArn
+	}
+	return parts[1]
+}
+
+// writeLoot has the commands to watch the input streams, dump the face collections and read the results
+func (m *RekognitionStreamingModule) writeLoot() string {
+	var out string
+	out += "#############################################\n"
+	out += "# Watch the video the stream processors analyze, list the faces they search for and read their results.\n"
+	out += "# Output in public buckets can also be listed without credentials.\n"
+	out += "#############################################\n"
+
+	for _, processor := range m.StreamProcessors {
+		out += fmt.Sprintf("\n# %s in %s\n", processor.Name, processor.Region)
+		out += fmt.Sprintf("aws --profile $profile --region %s rekognition describe-stream-processor --name %s\n", processor.Region, processor.Name)
+		if processor.FaceSearchCollection != "" {
+			out += fmt.Sprintf("aws --profile $profile --region %s rekognition list-faces --collection-id %s\n", processor.Region, processor.FaceSearchCollection)
+		}
+		if processor.InputStream != "" {
+			out += fmt.Sprintf("ENDPOINT=$(aws --profile $profile --region %s kinesisvideo get-data-endpoint --stream-arn %s --api-name GET_HLS_STREAMING_SESSION_URL --query DataEndpoint --output text)\n", processor.Region, processor.InputStream)
+			out += fmt.Sprintf("aws --profile $profile --region %s kinesis-video-archived-media get-hls-streaming-session-url --endpoint-url $ENDPOINT --stream-arn %s --playback-mode LIVE\n", processor.Region, processor.InputStream)
+		}
+		if processor.OutputBucket != "" {
+			if processor.PublicOutput {
+				out += fmt.Sprintf("aws s3 ls %s --no-sign-request\n", processor.outputLocation())
+			}
+			out += fmt.Sprintf("aws --profile $profile s3 ls %s --recursive --human-readable --summarize\n", processor.outputLocation())
+		} else if processor.OutputStream != "" {
+			out += fmt.Sprintf("aws --profile $profile --region %s kinesis describe-stream-summary --stream-arn %s\n", processor.Region, processor.OutputStream)
+		}
+	}
+	return out
+}
+
+func (m *RekognitionStreamingModule) Receiver(receiver chan RekognitionStreamProcessor, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.StreamProcessors = append(m.StreamProcessors, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *RekognitionStreamingModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan RekognitionStreamProcessor) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("rekognition", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getStreamProcessorsPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *RekognitionStreamingModule) getStreamProcessorsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan RekognitionStreamProcessor) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	streamProcessors, err := sdk.CachedRekognitionListStreamProcessors(m.RekognitionClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, summary := range streamProcessors {
+		processor := RekognitionStreamProcessor{
+			Region: r,
+			Name:   aws.ToString(summary.Name),
+			Status: string(summary.Status),
+		}
+
+		details, err := sdk.CachedRekognitionDescribeStreamProcessor(m.RekognitionClient, aws.ToString(m.Caller.Account), r, processor.Name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			dataReceiver <- processor
+			continue
+		}
+		processor.Arn = aws.ToString(details.StreamProcessorArn)
+		processor.Role = aws.ToString(details.RoleArn)
+		if details.Status != "" {
+			processor.Status = string(details.Status)
+		}
+		if details.Input != nil && details.Input.KinesisVideoStream != nil {
+			processor.InputStream = aws.ToString(details.Input.KinesisVideoStream.Arn)
+		}
+		if details.Output != nil {
+			if details.Output.S3Destination != nil {
+				processor.OutputBucket = aws.ToString(details.Output.S3Destination.Bucket)
+				processor.OutputPrefix = aws.ToString(details.Output.S3Destination.KeyPrefix)
+			}
+			if details.Output.KinesisDataStream != nil {
+				processor.OutputStream = aws.ToString(details.Output.KinesisDataStream.Arn)
+			}
+		}
+		if details.Settings != nil {
+			if details.Settings.FaceSearch != nil {
+				processor.FaceSearchCollection = aws.ToString(details.Settings.FaceSearch.CollectionId)
+			}
+			if details.Settings.ConnectedHome != nil {
+				processor.Labels = details.Settings.ConnectedHome.Labels
+			}
+		}
+		processor.RoleStreamActions = m.roleWildcardStreamAccess(processor.Role)
+
+		dataReceiver <- processor
+	}
+}
+
+// roleWildcardStreamAccess returns the video read actions the role is allowed on every stream, not just its input
+func (m *RekognitionStreamingModule) roleWildcardStreamAccess(role string) []string {
+	var allowed []string
+	if role == "" {
+		return allowed
+	}
+	evaluationResults, err := sdk.CachedIamSimulatePrincipalPolicy(m.IAMClient, aws.ToString(m.Caller.Account), aws.String(role), rekognitionRoleStreamActions, []string{"*"})
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return allowed
+	}
+	for _, result := range evaluationResults {
+		if result.EvalDecision == iamTypes.PolicyEvaluationDecisionTypeAllowed {
+			allowed = append(allowed, aws.ToString(result.EvalActionName))
+		}
+	}
+	sort.Strings(allowed)
+	return allowed
+}
+
+func (m *RekognitionStreamingModule) flagPublicOutputs() {
+	publicBuckets := make(map[string]bool)
+	for _, processor := range m.StreamProcessors {
+		if processor.OutputBucket == "" {
+			continue
+		}
+		if _, checked := publicBuckets[processor.OutputBucket]; checked {
+			continue
+		}
+		public, err := isBucketPublic(m.S3Client, aws.ToString(m.Caller.Account), processor.OutputBucket)
+		if err != nil {
+			m.modLog.Error(err.Error())
+		}
+		publicBuckets[processor.OutputBucket] = public
+	}
+	for i := range m.StreamProcessors {
+		m.StreamProcessors[i].PublicOutput = publicBuckets[m.StreamProcessors[i].OutputBucket]
+	}
+}
+
+// flagRekognitionSharedOutputs records, for every processor, the processors analyzing another stream that write to the
+// same bucket. Anyone who can read one camera's results there can usually read them all.
+func flagRekognitionSharedOutputs(processors []RekognitionStreamProcessor) {
+	for i := range processors {
+		if processors[i].OutputBucket == "" {
+			continue
+		}
+		for j := range processors {
+			if i == j || processors[j].OutputBucket != processors[i].OutputBucket || processors[j].InputStream == processors[i].InputStream {
+				continue
+			}
+			processors[i].SharedOutputWith = append(processors[i].SharedOutputWith, processors[j].Name)
+		}
+	}
+}
+
+func rekognitionStreamProcessorFindings(processor RekognitionStreamProcessor) []string {
+	var findings []string
+	if processor.FaceSearchCollection != "" {
+		findings = append(findings, rekognitionFaceSearch)
+	}
+	if processor.PublicOutput {
+		findings = append(findings, rekognitionPublicOutput)
+	}
+	if internal.Contains("kinesisvideo:GetMedia", processor.RoleStreamActions) || internal.Contains("kinesisvideo:GetHLSStreamingSessionURL", processor.RoleStreamActions) || internal.Contains("kinesisvideo:GetClip", processor.RoleStreamActions) {
+		findings = append(findings, rekognitionRoleAllStreams)
+	}
+	if len(processor.SharedOutputWith) > 0 {
+		findings = append(findings, fmt.Sprintf("%s (%s)", rekognitionSharedOutput, strings.Join(processor.SharedOutputWith, ", ")))
+	}
+	return findings
+}
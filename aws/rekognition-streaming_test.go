@@ -0,0 +1,99 @@
+package aws
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestRekognitionStreaming(t *testing.T) {
+	m := RekognitionStreamingModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:        3,
+		RekognitionClient: &sdk.MockedRekognitionClient{},
+		S3Client:          &sdk.MockedS3Client{},
+		IAMClient:         &sdk.MockedIAMClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintRekognitionStreaming(".", 2)
+
+	expected := []RekognitionStreamProcessor{
+		{
+			Region:               "us-east-1",
+			Name:                 "dock-faces",
+			Arn:                  "arn:aws:rekognition:us-east-1:123456789012:streamprocessor/dock-faces",
+			Status:               "STOPPED",
+			InputStream:          "arn:aws:kinesisvideo:us-east-1:123456789012:stream/dock-camera/1650000000001",
+			OutputBucket:         "camera-analytics",
+			OutputPrefix:         "dock/",
+			FaceSearchCollection: "employees",
+			Role:                 "arn:aws:iam::123456789012:role/rekognition-dock",
+			PublicOutput:         true,
+			SharedOutputWith:     []string{"lobby-faces"},
+			Findings:             []string{rekognitionFaceSearch, rekognitionPublicOutput, rekognitionSharedOutput + " (lobby-faces)"},
+		},
+		{
+			Region:       "us-east-1",
+			Name:         "doorbell-labels",
+			Arn:          "arn:aws:rekognition:us-east-1:123456789012:streamprocessor/doorbell-labels",
+			Status:       "RUNNING",
+			InputStream:  "arn:aws:kinesisvideo:us-east-1:123456789012:stream/doorbell/1650000000002",
+			OutputBucket: "doorbell-events",
+			Labels:       []string{"PACKAGE", "PERSON"},
+			Role:         "arn:aws:iam::123456789012:role/rekognition-doorbell",
+		},
+		{
+			Region:               "us-east-1",
+			Name:                 "lobby-faces",
+			Arn:                  "arn:aws:rekognition:us-east-1:123456789012:streamprocessor/lobby-faces",
+			Status:               "RUNNING",
+			InputStream:          "arn:aws:kinesisvideo:us-east-1:123456789012:stream/lobby-camera/1650000000000",
+			OutputBucket:         "camera-analytics",
+			OutputPrefix:         "lobby/",
+			FaceSearchCollection: "employees",
+			Role:                 "arn:aws:iam::123456789012:role/rekognition-video",
+			RoleStreamActions:    []string{"kinesisvideo:GetDataEndpoint", "kinesisvideo:GetMedia"},
+			PublicOutput:         true,
+			SharedOutputWith:     []string{"dock-faces"},
+			Findings:             []string{rekognitionFaceSearch, rekognitionPublicOutput, rekognitionRoleAllStreams, rekognitionSharedOutput + " (dock-faces)"},
+		},
+	}
+	if !reflect.DeepEqual(m.StreamProcessors, expected) {
+		t.Errorf("Expected stream processors %+v, got %+v", expected, m.StreamProcessors)
+	}
+
+	lootFilePath := filepath.Join(".", "cloudfox-output/aws/unittesting-123456789012/loot/rekognition-streaming.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	expectedCommands := []string{
+		"aws --profile $profile --region us-east-1 rekognition list-faces --collection-id employees",
+		"kinesisvideo get-data-endpoint --stream-arn arn:aws:kinesisvideo:us-east-1:123456789012:stream/lobby-camera/1650000000000 --api-name GET_HLS_STREAMING_SESSION_URL",
+		"aws s3 ls s3://camera-analytics/lobby/ --no-sign-request",
+		"aws --profile $profile s3 ls s3://doorbell-events/ --recursive --human-readable --summarize",
+	}
+	for _, expected := range expectedCommands {
+		if !strings.Contains(string(lootFile), expected) {
+			t.Errorf("Expected %s to be in the loot file", expected)
+		}
+	}
+	if strings.Contains(string(lootFile), "s3://doorbell-events/ --no-sign-request") {
+		t.Errorf("Did not expect an unsigned request to a private bucket")
+	}
+}
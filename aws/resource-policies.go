@@ -0,0 +1,664 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/aws/policy"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type ResourcePolicyModule struct {
+	// General configuration data
+	S3Client             sdk.AWSS3ClientInterface
+	SQSClient            sdk.AWSSQSClientInterface
+	SNSClient            sdk.AWSSNSClientInterface
+	KMSClient            sdk.KMSClientInterface
+	LambdaClient         sdk.LambdaClientInterface
+	SecretsManagerClient sdk.SecretsManagerClientInterface
+	ECRClient            sdk.AWSECRClientInterface
+	EFSClient            sdk.AWSEFSClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	ResourcePolicyFindings []ResourcePolicyFinding
+	CommandCounter         internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type ResourcePolicyFinding struct {
+	Service    string
+	Region     string
+	Name       string
+	Arn        string
+	Sid        string
+	Principal  string
+	Access     string
+	Actions    string
+	Conditions string
+	Severity   string
+}
+
+const (
+	resourcePolicySeverityCritical = "Critical"
+	resourcePolicySeverityHigh     = "High"
+	resourcePolicySeverityMedium   = "Medium"
+	resourcePolicySeverityLow      = "Low"
+)
+
+var resourcePolicySeverityRank = map[string]int{
+	resourcePolicySeverityCritical: 0,
+	resourcePolicySeverityHigh:     1,
+	resourcePolicySeverityMedium:   2,
+	resourcePolicySeverityLow:      3,
+}
+
+// Error codes the services return when a resource simply has no resource policy attached
+var noResourcePolicyErrorCodes = []string{
+	"NoSuchBucketPolicy",
+	"ResourceNotFoundException",
+	"RepositoryPolicyNotFoundException",
+	"PolicyNotFound",
+}
+
+var reAccountID = regexp.MustCompile(`^[0-9]{12}$`)
+
+func (m *ResourcePolicyModule) PrintResourcePolicies(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "resource-policies"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Analyzing resource policies for public and cross-account access in account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+	fmt.Printf("[%s][%s] Supported Services: ECR, EFS, KMS, Lambda, S3, SecretsManager, SNS, SQS\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "tasks")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan ResourcePolicyFinding)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	//execute global checks
+	wg.Add(1)
+	m.CommandCounter.Total++
+	m.CommandCounter.Pending++
+	go m.getS3BucketPolicies(wg, semaphore, dataReceiver)
+
+	//execute regional checks
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sortResourcePolicyFindings(m.ResourcePolicyFindings)
+
+	m.output.Headers = []string{
+		"Account",
+		"Severity",
+		"Service",
+		"Region",
+		"Name",
+		"Arn",
+		"Sid",
+		"Principal",
+		"Access",
+		"Actions",
+		"Conditions",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Severity",
+			"Service",
+			"Region",
+			"Name",
+			"Arn",
+			"Sid",
+			"Principal",
+			"Access",
+			"Actions",
+			"Conditions",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Severity",
+			"Service",
+			"Region",
+			"Name",
+			"Principal",
+			"Access",
+			"Actions",
+		}
+	}
+
+	// Table rows
+	for i := range m.ResourcePolicyFindings {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				m.ResourcePolicyFindings[i].Severity,
+				m.ResourcePolicyFindings[i].Service,
+				m.ResourcePolicyFindings[i].Region,
+				m.ResourcePolicyFindings[i].Name,
+				m.ResourcePolicyFindings[i].Arn,
+				m.ResourcePolicyFindings[i].Sid,
+				m.ResourcePolicyFindings[i].Principal,
+				m.ResourcePolicyFindings[i].Access,
+				m.ResourcePolicyFindings[i].Actions,
+				m.ResourcePolicyFindings[i].Conditions,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s public or cross-account resource policy statements found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No public or cross-account resource policies found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *ResourcePolicyModule) Receiver(receiver chan ResourcePolicyFinding, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.ResourcePolicyFindings = append(m.ResourcePolicyFindings, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *ResourcePolicyModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan ResourcePolicyFinding) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+
+	checks := []struct {
+		service string
+		getter  func(string, *sync.WaitGroup, chan struct{}, chan ResourcePolicyFinding)
+	}{
+		{"sqs", m.getSQSQueuePoliciesPerRegion},
+		{"sns", m.getSNSTopicPoliciesPerRegion},
+		{"kms", m.getKMSKeyPoliciesPerRegion},
+		{"lambda", m.getLambdaPoliciesPerRegion},
+		{"secretsmanager", m.getSecretsManagerPoliciesPerRegion},
+		{"ecr", m.getECRRepositoryPoliciesPerRegion},
+		{"efs", m.getEFSFileSystemPoliciesPerRegion},
+	}
+	for _, check := range checks {
+		res, err := servicemap.IsServiceInRegion(check.service, r)
+		if err != nil {
+			m.modLog.Error(err)
+		}
+		if res {
+			m.CommandCounter.Total++
+			m.CommandCounter.Pending++
+			wg.Add(1)
+			go check.getter(r, wg, semaphore, dataReceiver)
+		}
+	}
+}
+
+// startTask and finishTask wrap the semaphore and task counters every getter in this module shares
+func (m *ResourcePolicyModule) startTask(semaphore chan struct{}) {
+	semaphore <- struct{}{}
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+}
+
+func (m *ResourcePolicyModule) finishTask(wg *sync.WaitGroup, semaphore chan struct{}) {
+	<-semaphore
+	m.CommandCounter.Executing--
+	m.CommandCounter.Complete++
+	wg.Done()
+}
+
+func (m *ResourcePolicyModule) getS3BucketPolicies(wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan ResourcePolicyFinding) {
+	m.startTask(semaphore)
+	defer m.finishTask(wg, semaphore)
+
+	ListBuckets, err := sdk.CachedListBuckets(m.S3Client, aws.ToString(m.Caller.Account))
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, bucket := range ListBuckets {
+		name := aws.ToString(bucket.Name)
+		region, err := sdk.CachedGetBucketLocation(m.S3Client, aws.ToString(m.Caller.Account), name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			continue
+		}
+		policyJSON, err := sdk.CachedGetBucketPolicy(m.S3Client, aws.ToString(m.Caller.Account), region, name)
+		if err != nil {
+			m.handlePolicyError(err)
+			continue
+		}
+		m.sendFindings("S3", region, name, fmt.Sprintf("arn:aws:s3:::%s", name), policyJSON, dataReceiver)
+	}
+}
+
+func (m *ResourcePolicyModule) getSQSQueuePoliciesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan ResourcePolicyFinding) {
+	m.startTask(semaphore)
+	defer m.finishTask(wg, semaphore)
+
+	queueURLs, err := sdk.CachedSQSListQueues(m.SQSClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, queueURL := range queueURLs {
+		attributes, err := sdk.CachedSQSGetQueueAttributes(m.SQSClient, aws.ToString(m.Caller.Account), r, queueURL)
+		if err != nil {
+			m.handlePolicyError(err)
+			continue
+		}
+		name := queueURL[strings.LastIndex(queueURL, "/")+1:]
+		m.sendFindings("SQS", r, name, attributes["QueueArn"], attributes["Policy"], dataReceiver)
+	}
+}
+
+func (m *ResourcePolicyModule) getSNSTopicPoliciesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan ResourcePolicyFinding) {
+	m.startTask(semaphore)
+	defer m.finishTask(wg, semaphore)
+
+	topicArns, err := sdk.CachedSNSListTopics(m.SNSClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, topicArn := range topicArns {
+		attributes, err := sdk.CachedSNSGetTopicAttributes(m.SNSClient, aws.ToString(m.Caller.Account), r, topicArn)
+		if err != nil {
+			m.handlePolicyError(err)
+			continue
+		}
+		name := topicArn[strings.LastIndex(topicArn, ":")+1:]
+		m.sendFindings("SNS", r, name, topicArn, attributes["Policy"], dataReceiver)
+	}
+}
+
+func (m *ResourcePolicyModule) getKMSKeyPoliciesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan ResourcePolicyFinding) {
+	m.startTask(semaphore)
+	defer m.finishTask(wg, semaphore)
+
+	keys, err := sdk.CachedKMSListKeys(m.KMSClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, key := range keys {
+		policyJSON, err := sdk.CachedKMSGetKeyPolicy(m.KMSClient, aws.ToString(m.Caller.Account), r, aws.ToString(key.KeyId))
+		if err != nil {
+			m.handlePolicyError(err)
+			continue
+		}
+		m.sendFindings("KMS", r, aws.ToString(key.KeyId), aws.ToString(key.KeyArn), policyJSON, dataReceiver)
+	}
+}
+
+func (m *ResourcePolicyModule) getLambdaPoliciesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan ResourcePolicyFinding) {
+	m.startTask(semaphore)
+	defer m.finishTask(wg, semaphore)
+
+	functions, err := sdk.CachedLambdaListFunctions(m.LambdaClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, function := range functions {
+		policyJSON, err := sdk.CachedLambdaGetPolicy(m.LambdaClient, aws.ToString(m.Caller.Account), r, aws.ToString(function.FunctionName))
+		if err != nil {
+			m.handlePolicyError(err)
+			continue
+		}
+		m.sendFindings("Lambda", r, aws.ToString(function.FunctionName), aws.ToString(function.FunctionArn), policyJSON, dataReceiver)
+	}
+}
+
+func (m *ResourcePolicyModule) getSecretsManagerPoliciesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan ResourcePolicyFinding) {
+	m.startTask(semaphore)
+	defer m.finishTask(wg, semaphore)
+
+	secrets, err := sdk.CachedSecretsManagerListSecrets(m.SecretsManagerClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, secret := range secrets {
+		secretID := aws.ToString(secret.ARN)
+		if secretID == "" {
+			secretID = aws.ToString(secret.Name)
+		}
+		secretPolicy, err := sdk.CachedSecretsManagerGetResourcePolicy(m.SecretsManagerClient, secretID, r, aws.ToString(m.Caller.Account))
+		if err != nil {
+			m.handlePolicyError(err)
+			continue
+		}
+		m.sendPolicyFindings("SecretsManager", r, aws.ToString(secret.Name), secretID, secretPolicy, dataReceiver)
+	}
+}
+
+func (m *ResourcePolicyModule) getECRRepositoryPoliciesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan ResourcePolicyFinding) {
+	m.startTask(semaphore)
+	defer m.finishTask(wg, semaphore)
+
+	repositories, err := sdk.CachedECRDescribeRepositories(m.ECRClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, repository := range repositories {
+		name := aws.ToString(repository.RepositoryName)
+		policyJSON, err := sdk.CachedECRGetRepositoryPolicy(m.ECRClient, aws.ToString(m.Caller.Account), r, name)
+		if err != nil {
+			m.handlePolicyError(err)
+			continue
+		}
+		repositoryArn := aws.ToString(repository.RepositoryArn)
+		if repositoryArn == "" {
+			repositoryArn = fmt.Sprintf("arn:aws:ecr:%s:%s:repository/%s", r, aws.ToString(m.Caller.Account), name)
+		}
+		m.sendFindings("ECR", r, name, repositoryArn, policyJSON, dataReceiver)
+	}
+}
+
+func (m *ResourcePolicyModule) getEFSFileSystemPoliciesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan ResourcePolicyFinding) {
+	m.startTask(semaphore)
+	defer m.finishTask(wg, semaphore)
+
+	fileSystems, err := sdk.CachedDescribeFileSystems(m.EFSClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, fileSystem := range fileSystems {
+		fileSystemID := aws.ToString(fileSystem.FileSystemId)
+		fileSystemPolicy, err := sdk.CachedDescribeFileSystemPolicy(m.EFSClient, fileSystemID, r, aws.ToString(m.Caller.Account))
+		if err != nil {
+			m.handlePolicyError(err)
+			continue
+		}
+		fileSystemArn := aws.ToString(fileSystem.FileSystemArn)
+		if fileSystemArn == "" {
+			fileSystemArn = fmt.Sprintf("arn:aws:elasticfilesystem:%s:%s:file-system/%s", r, aws.ToString(m.Caller.Account), fileSystemID)
+		}
+		m.sendPolicyFindings("EFS", r, fileSystemID, fileSystemArn, fileSystemPolicy, dataReceiver)
+	}
+}
+
+// handlePolicyError ignores resources that have no policy and counts everything else as an error
+func (m *ResourcePolicyModule) handlePolicyError(err error) {
+	if isNoResourcePolicyError(err) {
+		return
+	}
+	m.modLog.Error(err.Error())
+	m.CommandCounter.Error++
+}
+
+func (m *ResourcePolicyModule) sendFindings(service string, r string, name string, resourceArn string, policyJSON string, dataReceiver chan ResourcePolicyFinding) {
+	if policyJSON == "" {
+		return
+	}
+	resourcePolicy, err := policy.ParseJSONPolicy([]byte(policyJSON))
+	if err != nil {
+		m.modLog.Error(fmt.Sprintf("parsing %s resource policy (%s) as JSON: %s", service, name, err))
+		m.CommandCounter.Error++
+		return
+	}
+	m.sendPolicyFindings(service, r, name, resourceArn, resourcePolicy, dataReceiver)
+}
+
+func (m *ResourcePolicyModule) sendPolicyFindings(service string, r string, name string, resourceArn string, resourcePolicy policy.Policy, dataReceiver chan ResourcePolicyFinding) {
+	for _, finding := range analyzeResourcePolicy(resourcePolicy, aws.ToString(m.Caller.Account)) {
+		finding.Service = service
+		finding.Region = r
+		finding.Name = name
+		finding.Arn = resourceArn
+		dataReceiver <- finding
+	}
+}
+
+// analyzeResourcePolicy returns one finding per allow statement and principal that opens the resource to everyone or
+// to another account. Service principals are skipped, they are how services deliver to their own resources.
+func analyzeResourcePolicy(resourcePolicy policy.Policy, accountID string) []ResourcePolicyFinding {
+	var findings []ResourcePolicyFinding
+	for _, statement := range resourcePolicy.Statement {
+		if !statement.IsAllow() {
+			continue
+		}
+
+		actions := strings.Join(statement.Action, ", ")
+		if len(statement.NotAction) > 0 {
+			actions = "All except " + strings.Join(statement.NotAction, ", ")
+		}
+		conditions := "No"
+		if !statement.Condition.IsEmpty() {
+			conditions = "Yes"
+		}
+		finding := ResourcePolicyFinding{
+			Sid:        statement.Sid,
+			Actions:    actions,
+			Conditions: conditions,
+		}
+
+		if statement.Principal.IsPublic() {
+			finding.Principal = "*"
+			switch {
+			case statement.Condition.IsEmpty():
+				finding.Access = "Public"
+				finding.Severity = resourcePolicySeverityCritical
+			case isConditionScopedToAccount(statement.Condition, accountID):
+				// e.g. the key policies of AWS managed KMS keys, only usable from inside this account
+				continue
+			case statement.Condition.IsScopedOnAccountOrOrganization():
+				finding.Access = "Public, scoped to accounts or organization by condition"
+				finding.Severity = resourcePolicySeverityLow
+			default:
+				finding.Access = "Public with conditions"
+				finding.Severity = resourcePolicySeverityHigh
+			}
+			findings = append(findings, finding)
+			continue
+		}
+
+		for _, principal := range statement.Principal.O.AWS {
+			principalAccount := resourcePolicyPrincipalAccount(principal)
+			if principalAccount == "" || principalAccount == accountID {
+				continue
+			}
+			crossAccountFinding := finding
+			crossAccountFinding.Principal = principal
+			crossAccountFinding.Access = fmt.Sprintf("Cross-account (%s)", principalAccount)
+			crossAccountFinding.Severity = resourcePolicySeverityMedium
+			if hasWildcardAction(statement) {
+				crossAccountFinding.Severity = resourcePolicySeverityHigh
+			}
+			findings = append(findings, crossAccountFinding)
+		}
+	}
+	return findings
+}
+
+// resourcePolicyPrincipalAccount returns the account of an AWS principal, given either as a bare account ID or an ARN
+func resourcePolicyPrincipalAccount(principal string) string {
+	if reAccountID.MatchString(principal) {
+		return principal
+	}
+	parsedArn, err := arn.Parse(principal)
+	if err != nil {
+		return ""
+	}
+	return parsedArn.AccountID
+}
+
+// Condition keys that pin the calling principal to a single account
+var accountConditionKeys = []string{
+	"aws:sourceowner",
+	"aws:sourceaccount",
+	"aws:principalaccount",
+	"kms:calleraccount",
+}
+
+// isConditionScopedToAccount is true if the condition only lets principals of accountID in
+func isConditionScopedToAccount(condition policy.PolicyStatementCondition, accountID string) bool {
+	for operator, kv := range condition {
+		if strings.ToLower(operator) != "stringequals" {
+			continue
+		}
+		for k, v := range kv {
+			if !internal.Contains(strings.ToLower(k), accountConditionKeys) || len(v) == 0 {
+				continue
+			}
+			scoped := true
+			for _, value := range v {
+				if value != accountID {
+					scoped = false
+				}
+			}
+			if scoped {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasWildcardAction is true for statements that grant "*", a whole service like "kms:*", or everything but a list
+func hasWildcardAction(statement policy.PolicyStatement) bool {
+	if len(statement.NotAction) > 0 {
+		return true
+	}
+	for _, action := range statement.Action {
+		if action == "*" || strings.HasSuffix(action, ":*") {
+			return true
+		}
+	}
+	return false
+}
+
+func isNoResourcePolicyError(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, code := range noResourcePolicyErrorCodes {
+		if apiErr.ErrorCode() == code {
+			return true
+		}
+	}
+	return false
+}
+
+func sortResourcePolicyFindings(findings []ResourcePolicyFinding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Severity != findings[j].Severity {
+			return resourcePolicySeverityRank[findings[i].Severity] < resourcePolicySeverityRank[findings[j].Severity]
+		}
+		if findings[i].Service != findings[j].Service {
+			return findings[i].Service < findings[j].Service
+		}
+		return findings[i].Arn < findings[j].Arn
+	})
+}
@@ -0,0 +1,123 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/aws/policy"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+func TestResourcePolicies(t *testing.T) {
+
+	m := ResourcePolicyModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:           3,
+		WrapTable:            false,
+		S3Client:             &sdk.MockedS3Client{},
+		SQSClient:            &sdk.MockedSQSClient{},
+		SNSClient:            &sdk.MockedSNSClient{},
+		KMSClient:            &sdk.MockedKMSClient{},
+		LambdaClient:         &sdk.MockedLambdaClient{},
+		SecretsManagerClient: &sdk.MockedSecretsManagerClient{},
+		ECRClient:            &sdk.MockedECRClient{},
+		EFSClient:            &sdk.MockedEfsClient{},
+	}
+
+	internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintResourcePolicies(".", 2)
+
+	expectedSeverities := map[string]string{
+		"SQS":    resourcePolicySeverityCritical,
+		"SNS":    resourcePolicySeverityHigh,
+		"KMS":    resourcePolicySeverityHigh,
+		"Lambda": resourcePolicySeverityMedium,
+		"EFS":    resourcePolicySeverityMedium,
+	}
+	expectedCounts := map[string]int{
+		"SQS":    2,
+		"SNS":    2,
+		"KMS":    1,
+		"Lambda": 2,
+		"EFS":    2,
+	}
+	counts := make(map[string]int)
+	for _, finding := range m.ResourcePolicyFindings {
+		counts[finding.Service]++
+		if expectedSeverities[finding.Service] != finding.Severity {
+			t.Errorf("%s finding for %s: expected severity %s, got %s", finding.Service, finding.Name, expectedSeverities[finding.Service], finding.Severity)
+		}
+	}
+	for service, expected := range expectedCounts {
+		if counts[service] != expected {
+			t.Errorf("Expected %d %s findings, got %d", expected, service, counts[service])
+		}
+	}
+	if len(m.ResourcePolicyFindings) > 0 && m.ResourcePolicyFindings[0].Severity != resourcePolicySeverityCritical {
+		t.Errorf("Expected findings to be sorted by severity, first one is %s", m.ResourcePolicyFindings[0].Severity)
+	}
+}
+
+func TestAnalyzeResourcePolicy(t *testing.T) {
+	subtests := []struct {
+		name             string
+		policyJSON       string
+		expectedAccess   []string
+		expectedSeverity []string
+	}{
+		{
+			name:             "same account and service principals",
+			policyJSON:       `{"Statement":[{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::123456789012:role/app"},"Action":"s3:*","Resource":"*"},{"Effect":"Allow","Principal":{"Service":"logs.amazonaws.com"},"Action":"s3:PutObject","Resource":"*"}]}`,
+			expectedAccess:   nil,
+			expectedSeverity: nil,
+		},
+		{
+			name:             "bare external account id",
+			policyJSON:       `{"Statement":[{"Effect":"Allow","Principal":{"AWS":["111122223333","123456789012"]},"Action":"sqs:SendMessage","Resource":"*"}]}`,
+			expectedAccess:   []string{"Cross-account (111122223333)"},
+			expectedSeverity: []string{resourcePolicySeverityMedium},
+		},
+		{
+			name:             "public scoped to an organization",
+			policyJSON:       `{"Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"*","Condition":{"StringEquals":{"aws:PrincipalOrgID":"o-abcdefghij"}}}]}`,
+			expectedAccess:   []string{"Public, scoped to accounts or organization by condition"},
+			expectedSeverity: []string{resourcePolicySeverityLow},
+		},
+		{
+			name:             "deny statements are ignored",
+			policyJSON:       `{"Statement":[{"Effect":"Deny","Principal":"*","Action":"*","Resource":"*"}]}`,
+			expectedAccess:   nil,
+			expectedSeverity: nil,
+		},
+	}
+
+	for _, subtest := range subtests {
+		t.Run(subtest.name, func(t *testing.T) {
+			resourcePolicy, err := policy.ParseJSONPolicy([]byte(subtest.policyJSON))
+			if err != nil {
+				t.Fatal(err)
+			}
+			findings := analyzeResourcePolicy(resourcePolicy, "123456789012")
+			if len(findings) != len(subtest.expectedAccess) {
+				t.Fatalf("Expected %d findings, got %d", len(subtest.expectedAccess), len(findings))
+			}
+			for i, finding := range findings {
+				if finding.Access != subtest.expectedAccess[i] {
+					t.Errorf("Expected access %q, got %q", subtest.expectedAccess[i], finding.Access)
+				}
+				if finding.Severity != subtest.expectedSeverity[i] {
+					t.Errorf("Expected severity %s, got %s", subtest.expectedSeverity[i], finding.Severity)
+				}
+			}
+		})
+	}
+}
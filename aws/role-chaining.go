@@ -0,0 +1,303 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/aws/policy"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/sirupsen/logrus"
+)
+
+// A chained role session can't last longer than an hour, no matter what the role's MaxSessionDuration says
+const roleChainedSessionDuration = time.Hour
+
+// roleChainMaxLength stops the walk through the trust graph from running away in accounts with lots of roles that
+// trust each other
+const roleChainMaxLength = 10
+
+type RoleChainingModule struct {
+	// General configuration data
+	IAMClient sdk.AWSIAMClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSProfile    string
+	AWSOutputType string
+	AWSTableCols  string
+	Goroutines    int
+	WrapTable     bool
+
+	// Main module data
+	RoleChains     []RoleChain
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+// RoleChain is a sequence of roles in the same account where each role trusts the one before it. When Circular is
+// set, the last role in Roles is one that already appears earlier in the chain.
+type RoleChain struct {
+	Roles              []string
+	Circular           bool
+	MaxSessionDuration time.Duration
+}
+
+func (m *RoleChainingModule) PrintRoleChaining(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "role-chaining"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Looking for roles that can be chained through sts:AssumeRole in account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	roles, err := sdk.CachedIamListRoles(m.IAMClient, aws.ToString(m.Caller.Account))
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	m.RoleChains = findRoleChains(roles, aws.ToString(m.Caller.Account))
+
+	m.output.Headers = []string{
+		"Account",
+		"Type",
+		"Depth",
+		"Chain",
+		"Max Session",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Type",
+			"Depth",
+			"Chain",
+			"Max Session",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Type",
+			"Depth",
+			"Chain",
+			"Max Session",
+		}
+	}
+
+	// Table rows
+	for _, roleChain := range m.RoleChains {
+		chainType := "Chain"
+		maxSession := roleChain.MaxSessionDuration.String()
+		if roleChain.Circular {
+			chainType = magenta("Circular")
+			maxSession = magenta("Unlimited")
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				chainType,
+				strconv.Itoa(roleChain.depth()),
+				strings.Join(roleChain.Roles, " -> "),
+				maxSession,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s role chains found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No role chains found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+// depth is the number of distinct roles in the chain
+func (c RoleChain) depth() int {
+	if c.Circular {
+		return len(c.Roles) - 1
+	}
+	return len(c.Roles)
+}
+
+// findRoleChains builds a graph with an edge from role A to role B whenever B's trust policy names A as a principal
+// allowed to call sts:AssumeRole. Trusting the account root doesn't add edges, because it only delegates the decision
+// to identity policies. It returns every chain of more than two roles that starts at a role no other role can assume,
+// and every cycle in the graph, which lets whoever holds one of its roles refresh credentials forever.
+func findRoleChains(roles []iamTypes.Role, accountID string) []RoleChain {
+	rolesByArn := make(map[string]iamTypes.Role)
+	roleArnsByName := make(map[string]string)
+	for _, role := range roles {
+		rolesByArn[aws.ToString(role.Arn)] = role
+		roleArnsByName[aws.ToString(role.RoleName)] = aws.ToString(role.Arn)
+	}
+
+	assumableRoles := make(map[string][]string)
+	trustedBy := make(map[string]int)
+	for _, role := range roles {
+		trustsDoc, err := policy.ParseRoleTrustPolicyDocument(role)
+		if err != nil {
+			continue
+		}
+		for _, statement := range trustsDoc.Statement {
+			// The trust policy parser leaves Action empty when the statement lists more than one action
+			if statement.Effect != "Allow" || (statement.Action != "sts:AssumeRole" && statement.Action != "") {
+				continue
+			}
+			for _, principal := range statement.Principal.AWS {
+				trustedRole := resolveTrustedRole(principal, accountID, roleArnsByName)
+				if trustedRole == "" || internal.Contains(aws.ToString(role.Arn), assumableRoles[trustedRole]) {
+					continue
+				}
+				assumableRoles[trustedRole] = append(assumableRoles[trustedRole], aws.ToString(role.Arn))
+				trustedBy[aws.ToString(role.Arn)]++
+			}
+		}
+	}
+
+	var startingRoles []string
+	for roleArn := range assumableRoles {
+		startingRoles = append(startingRoles, roleArn)
+		sort.Strings(assumableRoles[roleArn])
+	}
+	sort.Strings(startingRoles)
+
+	var roleChains []RoleChain
+	seenCycles := make(map[string]bool)
+	var walk func(path []string)
+	walk = func(path []string) {
+		last := path[len(path)-1]
+		extended := false
+		for _, next := range assumableRoles[last] {
+			if index := indexOfString(path, next); index >= 0 {
+				extended = true
+				cycle := append(append([]string{}, path[index:]...), next)
+				if key := cycleKey(cycle); !seenCycles[key] {
+					seenCycles[key] = true
+					roleChains = append(roleChains, RoleChain{Roles: cycle, Circular: true})
+				}
+				// A chain that runs into a cycle from outside of it is reported as well
+				if index > 0 && trustedBy[path[0]] == 0 {
+					roleChains = append(roleChains, RoleChain{Roles: append(append([]string{}, path...), next), Circular: true})
+				}
+				continue
+			}
+			if len(path) < roleChainMaxLength {
+				extended = true
+				walk(append(append([]string{}, path...), next))
+			}
+		}
+		if !extended && len(path) > 2 && trustedBy[path[0]] == 0 {
+			roleChains = append(roleChains, RoleChain{
+				Roles:              path,
+				MaxSessionDuration: roleChainMaxSessionDuration(rolesByArn[path[0]], len(path)-1),
+			})
+		}
+	}
+	for _, roleArn := range startingRoles {
+		walk([]string{roleArn})
+	}
+
+	sort.SliceStable(roleChains, func(i, j int) bool {
+		if roleChains[i].Circular != roleChains[j].Circular {
+			return roleChains[i].Circular
+		}
+		return roleChains[i].depth() > roleChains[j].depth()
+	})
+	return roleChains
+}
+
+// resolveTrustedRole returns the ARN of the role a trust policy principal refers to, if it is a role in this account
+func resolveTrustedRole(principal string, accountID string, roleArnsByName map[string]string) string {
+	arnParts := strings.SplitN(principal, ":", 6)
+	if len(arnParts) != 6 || arnParts[4] != accountID {
+		return ""
+	}
+	var roleName string
+	switch {
+	case arnParts[2] == "iam" && strings.HasPrefix(arnParts[5], "role/"):
+		roleName = arnParts[5][strings.LastIndex(arnParts[5], "/")+1:]
+	case arnParts[2] == "sts" && strings.HasPrefix(arnParts[5], "assumed-role/"):
+		roleName = strings.Split(arnParts[5], "/")[1]
+	default:
+		return ""
+	}
+	return roleArnsByName[roleName]
+}
+
+// roleChainMaxSessionDuration is how long someone can hold credentials for the last role in a chain. The first role
+// keeps its own MaxSessionDuration, and every hop after it can be re-assumed just before the previous session
+// expires to add another hour.
+func roleChainMaxSessionDuration(firstRole iamTypes.Role, hops int) time.Duration {
+	firstSession := time.Duration(aws.ToInt32(firstRole.MaxSessionDuration)) * time.Second
+	if firstSession == 0 {
+		firstSession = time.Hour
+	}
+	return firstSession + time.Duration(hops)*roleChainedSessionDuration
+}
+
+// cycleKey identifies a cycle regardless of which of its roles the walk started from
+func cycleKey(cycle []string) string {
+	roles := append([]string{}, cycle[:len(cycle)-1]...)
+	start := 0
+	for i := range roles {
+		if roles[i] < roles[start] {
+			start = i
+		}
+	}
+	return strings.Join(append(roles[start:], roles[:start]...), ",")
+}
+
+func indexOfString(slice []string, elem string) int {
+	for i, s := range slice {
+		if s == elem {
+			return i
+		}
+	}
+	return -1
+}
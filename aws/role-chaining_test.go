@@ -0,0 +1,110 @@
+package aws
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+type mockedRoleChainingIAMClient struct {
+	sdk.MockedIAMClient
+}
+
+func testRoleChainingRole(name string, maxSessionDuration int32, trustPolicy string) iamTypes.Role {
+	return iamTypes.Role{
+		Arn:                      aws.String("arn:aws:iam::444444444444:role/" + name),
+		RoleName:                 aws.String(name),
+		MaxSessionDuration:       aws.Int32(maxSessionDuration),
+		AssumeRolePolicyDocument: aws.String(trustPolicy),
+	}
+}
+
+func (c *mockedRoleChainingIAMClient) ListRoles(ctx context.Context, input *iam.ListRolesInput, options ...func(*iam.Options)) (*iam.ListRolesOutput, error) {
+	return &iam.ListRolesOutput{Roles: []iamTypes.Role{
+		testRoleChainingRole("ci", 43200, `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"codebuild.amazonaws.com"},"Action":"sts:AssumeRole"}]}`),
+		testRoleChainingRole("deploy", 3600, `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::444444444444:role/ci"},"Action":["sts:AssumeRole","sts:TagSession"]}]}`),
+		testRoleChainingRole("admin", 3600, `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["arn:aws:iam::444444444444:role/deploy","arn:aws:iam::999999999999:role/ci"]},"Action":"sts:AssumeRole"}]}`),
+		testRoleChainingRole("sessions", 3600, `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"arn:aws:sts::444444444444:assumed-role/admin/bob"},"Action":"sts:AssumeRole"}]}`),
+		testRoleChainingRole("readonly", 3600, `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::444444444444:role/ci"},"Action":"sts:AssumeRole"}]}`),
+		testRoleChainingRole("denied", 3600, `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Principal":{"AWS":"arn:aws:iam::444444444444:role/readonly"},"Action":"sts:AssumeRole"}]}`),
+		testRoleChainingRole("break-glass", 3600, `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::444444444444:root"},"Action":"sts:AssumeRole"}]}`),
+		testRoleChainingRole("loop-a", 3600, `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::444444444444:role/loop-b"},"Action":"sts:AssumeRole"}]}`),
+		testRoleChainingRole("loop-b", 3600, `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::444444444444:role/loop-a"},"Action":"sts:AssumeRole"}]}`),
+	}}, nil
+}
+
+func TestRoleChaining(t *testing.T) {
+	m := RoleChainingModule{
+		AWSProfile: "unittesting",
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::444444444444:user/Alice"),
+			Account: aws.String("444444444444"),
+		},
+		Goroutines: 3,
+		IAMClient:  &mockedRoleChainingIAMClient{},
+	}
+
+	internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintRoleChaining(".", 2)
+
+	expected := []RoleChain{
+		{
+			Roles: []string{
+				"arn:aws:iam::444444444444:role/loop-a",
+				"arn:aws:iam::444444444444:role/loop-b",
+				"arn:aws:iam::444444444444:role/loop-a",
+			},
+			Circular: true,
+		},
+		// ci -> readonly is only two roles deep, so it isn't a chain
+		{
+			Roles: []string{
+				"arn:aws:iam::444444444444:role/ci",
+				"arn:aws:iam::444444444444:role/deploy",
+				"arn:aws:iam::444444444444:role/admin",
+				"arn:aws:iam::444444444444:role/sessions",
+			},
+			MaxSessionDuration: 15 * time.Hour,
+		},
+	}
+	if !reflect.DeepEqual(m.RoleChains, expected) {
+		t.Errorf("Expected role chains %v, got %v", expected, m.RoleChains)
+	}
+}
+
+func TestFindRoleChainsIntoCycle(t *testing.T) {
+	roles := []iamTypes.Role{
+		testRoleChainingRole("entry", 3600, `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"lambda.amazonaws.com"},"Action":"sts:AssumeRole"}]}`),
+		testRoleChainingRole("ping", 3600, `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["arn:aws:iam::444444444444:role/entry","arn:aws:iam::444444444444:role/pong"]},"Action":"sts:AssumeRole"}]}`),
+		testRoleChainingRole("pong", 3600, `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::444444444444:role/ping"},"Action":"sts:AssumeRole"}]}`),
+	}
+
+	roleChains := findRoleChains(roles, "444444444444")
+
+	// The ping/pong cycle is reported once even though the walk reaches it from entry, ping and pong
+	var cycles, chainsIntoCycle int
+	for _, roleChain := range roleChains {
+		if !roleChain.Circular {
+			t.Errorf("Expected only circular chains, got %v", roleChain.Roles)
+			continue
+		}
+		if roleChain.Roles[0] == "arn:aws:iam::444444444444:role/entry" {
+			chainsIntoCycle++
+		} else {
+			cycles++
+		}
+	}
+	if cycles != 1 || chainsIntoCycle != 1 {
+		t.Errorf("Expected one cycle and one chain into it, got %v", roleChains)
+	}
+}
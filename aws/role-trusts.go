@@ -115,6 +115,8 @@ func (m *RoleTrustsModule) PrintRoleTrusts(outputDirectory string, verbosity int
 
 	o.PrefixIdentifier = m.AWSProfile
 	o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+	o.AccountID = aws.ToString(m.Caller.Account)
+	o.BaseDirectory = outputDirectory
 
 	principalsHeader, principalsBody, principalTableCols := m.printPrincipalTrusts(outputDirectory)
 	o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
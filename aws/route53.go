@@ -131,6 +131,8 @@ func (m *Route53Module) PrintRoute53(outputDirectory string, verbosity int) {
 		})
 		o.PrefixIdentifier = m.AWSProfile
 		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
 		o.WriteFullOutput(o.Table.TableFiles, nil)
 		m.writeLoot(o.Table.DirectoryName, verbosity)
 		fmt.Printf("[%s][%s] %s DNS records found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
@@ -172,7 +174,7 @@ func (m *Route53Module) writeLoot(outputDirectory string, verbosity int) {
 		panic(err.Error())
 	}
 
-	if verbosity > 2 {
+	if internal.ShowLoot(verbosity) {
 		if len(route53APublicRecords) > 0 {
 			fmt.Println()
 			fmt.Printf("[%s][%s] %s \n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("Feed these public zone A records into nmap and something like gowitness or aquatone."))
@@ -190,7 +192,7 @@ func (m *Route53Module) writeLoot(outputDirectory string, verbosity int) {
 		panic(err.Error())
 	}
 
-	if verbosity > 2 {
+	if internal.ShowLoot(verbosity) {
 		if len(route53APrivateRecords) > 0 {
 			fmt.Println()
 			fmt.Printf("[%s][%s] %s \n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("Feed these private zone A records into nmap and something like gowitness or aquatone."))
@@ -0,0 +1,548 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type SageMakerPipelinesModule struct {
+	// General configuration data
+	SageMakerClient sdk.SageMakerClientInterface
+	IAMClient       sdk.AWSIAMClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Pipelines      []SageMakerPipeline
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type SageMakerPipeline struct {
+	Region string
+	Name   string
+	Arn    string
+	Status string
+	// Role is the pipeline execution role, the steps that start jobs run them with their own RoleArn
+	Role          string
+	RoleS3Actions []string
+	Parameters    []string
+	// Buckets are the S3 buckets the steps read from and write to, the scope the roles need
+	Buckets  []string
+	Secrets  []WorkflowSecret
+	Steps    []SageMakerPipelineStep
+	Findings []string
+}
+
+type SageMakerPipelineStep struct {
+	Name string
+	Type string
+	// Role is the RoleArn argument of the step, with parameter references resolved to their default value
+	Role    string
+	Buckets []string
+	// RoleS3Actions are the S3 actions the role is allowed on every bucket, not just the ones the pipeline uses
+	RoleS3Actions []string
+	Findings      []string
+}
+
+const (
+	sageMakerPipelineHardcodedSecret = "Hardcoded secret in definition"
+	sageMakerPipelineBroadS3         = "Role can access buckets outside the pipeline"
+)
+
+// sageMakerPipelineS3Actions are what processing and training jobs do with their data
+var sageMakerPipelineS3Actions = []string{
+	"s3:GetObject",
+	"s3:PutObject",
+	"s3:DeleteObject",
+	"s3:ListBucket",
+}
+
+// sageMakerPipelineDefinition is the part of the pipeline definition JSON that we care about
+type sageMakerPipelineDefinition struct {
+	Parameters []struct {
+		Name         string
+		Type         string
+		DefaultValue interface{}
+	}
+	Steps []struct {
+		Name      string
+		Type      string
+		Arguments interface{}
+	}
+}
+
+func (m *SageMakerPipelinesModule) PrintSageMakerPipelines(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "sagemaker-pipelines"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating SageMaker pipelines for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan SageMakerPipeline)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.Pipelines, func(i, j int) bool {
+		if m.Pipelines[i].Region != m.Pipelines[j].Region {
+			return m.Pipelines[i].Region < m.Pipelines[j].Region
+		}
+		return m.Pipelines[i].Name < m.Pipelines[j].Name
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Pipeline",
+		"Status",
+		"Role",
+		"Role S3 Access",
+		"Steps",
+		"Buckets",
+		"Hardcoded Secrets",
+		"Findings",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Pipeline",
+			"Status",
+			"Role",
+			"Role S3 Access",
+			"Steps",
+			"Buckets",
+			"Hardcoded Secrets",
+			"Findings",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Pipeline",
+			"Status",
+			"Role",
+			"Steps",
+			"Hardcoded Secrets",
+			"Findings",
+		}
+	}
+
+	// Table rows
+	for _, pipeline := range m.Pipelines {
+		var secrets []string
+		for _, secret := range pipeline.Secrets {
+			secrets = append(secrets, fmt.Sprintf("%s: %s", secret.Rule, maskDefinitionSecret(secret.Line, secret.Value)))
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				pipeline.Region,
+				pipeline.Name,
+				pipeline.Status,
+				GetResourceNameFromArn(pipeline.Role),
+				strings.Join(pipeline.RoleS3Actions, ", "),
+				fmt.Sprint(len(pipeline.Steps)),
+				strings.Join(pipeline.Buckets, ", "),
+				strings.Join(secrets, "\n"),
+				strings.Join(sageMakerPipelineColoredFindings(pipeline.Findings), "\n"),
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.Table.TableFiles = append(o.Table.TableFiles, m.stepsTable())
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     m.output.CallingModule,
+			Contents: m.writeLoot(),
+		})
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d SageMaker pipelines found, %d with findings.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), m.countWithFindings())
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No SageMaker pipelines found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+// stepsTable has a row per pipeline step, so the roles of the steps that override the pipeline's can be told apart
+func (m *SageMakerPipelinesModule) stepsTable() internal.TableFile {
+	header := []string{
+		"Account",
+		"Region",
+		"Pipeline",
+		"Step",
+		"Type",
+		"Role",
+		"Role S3 Access",
+		"Buckets",
+		"Findings",
+	}
+	var body [][]string
+	for _, pipeline := range m.Pipelines {
+		for _, step := range pipeline.Steps {
+			body = append(body, []string{
+				aws.ToString(m.Caller.Account),
+				pipeline.Region,
+				pipeline.Name,
+				step.Name,
+				step.Type,
+				GetResourceNameFromArn(step.Role),
+				strings.Join(step.RoleS3Actions, ", "),
+				strings.Join(step.Buckets, ", "),
+				strings.Join(sageMakerPipelineColoredFindings(step.Findings), "\n"),
+			})
+		}
+	}
+	return internal.TableFile{
+		Header:    header,
+		Body:      body,
+		TableCols: []string{"Region", "Pipeline", "Step", "Type", "Role", "Role S3 Access", "Findings"},
+		Name:      "sagemaker-pipeline-steps",
+	}
+}
+
+func sageMakerPipelineColoredFindings(findings []string) []string {
+	var colored []string
+	for _, finding := range findings {
+		colored = append(colored, magenta(finding))
+	}
+	return colored
+}
+
+func (m *SageMakerPipelinesModule) countWithFindings() int {
+	var count int
+	for _, pipeline := range m.Pipelines {
+		if len(pipeline.Findings) > 0 {
+			count++
+			continue
+		}
+		for _, step := range pipeline.Steps {
+			if len(step.Findings) > 0 {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// writeLoot has the commands to read the full definitions and to list the buckets the pipelines use
+func (m *SageMakerPipelinesModule) writeLoot() string {
+	var out string
+	out += "#############################################\n"
+	out += "# Read the pipeline definitions and their past executions, and list the data the pipelines work on.\n"
+	out += "#############################################\n"
+
+	for _, pipeline := range m.Pipelines {
+		out += fmt.Sprintf("\n# %s in %s\n", pipeline.Name, pipeline.Region)
+		out += fmt.Sprintf("aws --profile $profile --region %s sagemaker describe-pipeline --pipeline-name %s --query PipelineDefinition --output text | jq .\n", pipeline.Region, pipeline.Name)
+		out += fmt.Sprintf("aws --profile $profile --region %s sagemaker list-pipeline-executions --pipeline-name %s\n", pipeline.Region, pipeline.Name)
+		for _, bucket := range pipeline.Buckets {
+			out += fmt.Sprintf("aws --profile $profile s3 ls s3://%s/ --recursive --human-readable --summarize\n", bucket)
+		}
+	}
+	return out
+}
+
+func (m *SageMakerPipelinesModule) Receiver(receiver chan SageMakerPipeline, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Pipelines = append(m.Pipelines, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *SageMakerPipelinesModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan SageMakerPipeline) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("sagemaker", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getPipelinesPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *SageMakerPipelinesModule) getPipelinesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan SageMakerPipeline) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	pipelines, err := sdk.CachedSageMakerListPipelines(m.SageMakerClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, summary := range pipelines {
+		pipeline := SageMakerPipeline{
+			Region: r,
+			Name:   aws.ToString(summary.PipelineName),
+			Arn:    aws.ToString(summary.PipelineArn),
+			Role:   aws.ToString(summary.RoleArn),
+		}
+
+		details, err := sdk.CachedSageMakerDescribePipeline(m.SageMakerClient, aws.ToString(m.Caller.Account), r, pipeline.Name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		} else {
+			pipeline.Status = string(details.PipelineStatus)
+			if details.RoleArn != nil {
+				pipeline.Role = aws.ToString(details.RoleArn)
+			}
+			if err := parseSageMakerPipelineDefinition(&pipeline, aws.ToString(details.PipelineDefinition)); err != nil {
+				m.modLog.Error(fmt.Sprintf("parsing the definition of pipeline %s: %s", pipeline.Name, err))
+			}
+		}
+
+		pipeline.RoleS3Actions = m.roleWildcardS3Access(pipeline.Role)
+		for i := range pipeline.Steps {
+			pipeline.Steps[i].RoleS3Actions = m.roleWildcardS3Access(pipeline.Steps[i].Role)
+			if len(pipeline.Steps[i].RoleS3Actions) > 0 {
+				pipeline.Steps[i].Findings = append(pipeline.Steps[i].Findings, sageMakerPipelineBroadS3)
+			}
+		}
+		if len(pipeline.Secrets) > 0 {
+			pipeline.Findings = append(pipeline.Findings, sageMakerPipelineHardcodedSecret)
+		}
+		if len(pipeline.RoleS3Actions) > 0 {
+			pipeline.Findings = append(pipeline.Findings, sageMakerPipelineBroadS3)
+		}
+
+		dataReceiver <- pipeline
+	}
+}
+
+// roleWildcardS3Access returns the S3 actions the role is allowed on every bucket. A role scoped to the pipeline's
+// buckets is denied these on *, so whatever is left reaches beyond them.
+func (m *SageMakerPipelinesModule) roleWildcardS3Access(role string) []string {
+	var allowed []string
+	if role == "" {
+		return allowed
+	}
+	evaluationResults, err := sdk.CachedIamSimulatePrincipalPolicy(m.IAMClient, aws.ToString(m.Caller.Account), aws.String(role), sageMakerPipelineS3Actions, []string{"*"})
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return allowed
+	}
+	for _, result := range evaluationResults {
+		if result.EvalDecision == iamTypes.PolicyEvaluationDecisionTypeAllowed {
+			allowed = append(allowed, aws.ToString(result.EvalActionName))
+		}
+	}
+	sort.Strings(allowed)
+	return allowed
+}
+
+// parseSageMakerPipelineDefinition fills in the parameters, steps, buckets and hardcoded secrets of a pipeline from
+// its definition JSON
+func parseSageMakerPipelineDefinition(pipeline *SageMakerPipeline, definitionJSON string) error {
+	if definitionJSON == "" {
+		return nil
+	}
+	var definition sageMakerPipelineDefinition
+	if err := json.Unmarshal([]byte(definitionJSON), &definition); err != nil {
+		return err
+	}
+
+	defaults := make(map[string]interface{})
+	var seen []string
+	addSecrets := func(matches []WorkflowSecret) {
+		for _, match := range matches {
+			if internal.Contains(match.Value, seen) {
+				continue
+			}
+			seen = append(seen, match.Value)
+			pipeline.Secrets = append(pipeline.Secrets, match)
+		}
+	}
+	for _, parameter := range definition.Parameters {
+		pipeline.Parameters = append(pipeline.Parameters, parameter.Name)
+		defaults[parameter.Name] = parameter.DefaultValue
+		// Parameter names hold the hint that a default is a secret, so they are scanned as assignments
+		if value, ok := parameter.DefaultValue.(string); ok {
+			addSecrets(scanDefinitionForSecrets(fmt.Sprintf("%s=%s", parameter.Name, value)))
+		}
+	}
+	// The definition is often a single line, indenting it gives the rules one value per line to look at
+	indented, err := json.MarshalIndent(definition.Steps, "", "  ")
+	if err == nil {
+		addSecrets(scanDefinitionForSecrets(string(indented)))
+	}
+
+	for _, definitionStep := range definition.Steps {
+		step := SageMakerPipelineStep{
+			Name: definitionStep.Name,
+			Type: definitionStep.Type,
+		}
+		var values []string
+		collectSageMakerPipelineValues(definitionStep.Arguments, "", defaults, func(key string, value string) {
+			if key == "RoleArn" && step.Role == "" {
+				step.Role = value
+			}
+			values = append(values, value)
+		})
+		step.Buckets = sageMakerPipelineBuckets(values)
+		for _, bucket := range step.Buckets {
+			if !internal.Contains(bucket, pipeline.Buckets) {
+				pipeline.Buckets = append(pipeline.Buckets, bucket)
+			}
+		}
+		pipeline.Steps = append(pipeline.Steps, step)
+	}
+	sort.Strings(pipeline.Buckets)
+	return nil
+}
+
+// collectSageMakerPipelineValues walks the arguments of a step and calls found with every string value and the key it
+// is under. References to pipeline parameters, {"Get": "Parameters.Name"}, are replaced with their default value.
+func collectSageMakerPipelineValues(arguments interface{}, key string, defaults map[string]interface{}, found func(key string, value string)) {
+	switch value := arguments.(type) {
+	case string:
+		found(key, value)
+	case []interface{}:
+		for _, item := range value {
+			collectSageMakerPipelineValues(item, key, defaults, found)
+		}
+	case map[string]interface{}:
+		if reference, ok := value["Get"].(string); ok && len(value) == 1 {
+			if strings.HasPrefix(reference, "Parameters.") {
+				collectSageMakerPipelineValues(defaults[strings.TrimPrefix(reference, "Parameters.")], key, defaults, found)
+			}
+			return
+		}
+		// In key order, so the first RoleArn of a step is always the same one
+		var keys []string
+		for childKey := range value {
+			keys = append(keys, childKey)
+		}
+		sort.Strings(keys)
+		for _, childKey := range keys {
+			collectSageMakerPipelineValues(value[childKey], childKey, defaults, found)
+		}
+	}
+}
+
+// sageMakerPipelineBuckets returns the buckets of the S3 URIs among the values, sorted and without duplicates
+func sageMakerPipelineBuckets(values []string) []string {
+	var buckets []string
+	for _, value := range values {
+		if !strings.HasPrefix(value, "s3://") {
+			continue
+		}
+		bucket := strings.SplitN(strings.TrimPrefix(value, "s3://"), "/", 2)[0]
+		if bucket != "" && !internal.Contains(bucket, buckets) {
+			buckets = append(buckets, bucket)
+		}
+	}
+	sort.Strings(buckets)
+	return buckets
+}
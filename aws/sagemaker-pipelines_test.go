@@ -0,0 +1,92 @@
+package aws
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestSageMakerPipelines(t *testing.T) {
+	m := SageMakerPipelinesModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:      3,
+		SageMakerClient: &sdk.MockedSageMakerClient{},
+		IAMClient:       &sdk.MockedIAMClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintSageMakerPipelines(".", 2)
+
+	expected := []SageMakerPipeline{
+		{
+			Region:     "us-east-1",
+			Name:       "churn-training",
+			Arn:        "arn:aws:sagemaker:us-east-1:123456789012:pipeline/churn-training",
+			Status:     "Active",
+			Role:       "arn:aws:iam::123456789012:role/sagemaker-pipeline",
+			Parameters: []string{"InputData", "ExecutionRole", "WandbApiKey"},
+			Buckets:    []string{"ml-churn-data", "ml-churn-models"},
+			Secrets: []WorkflowSecret{
+				{Rule: "Secret-looking assignment", Line: "WandbApiKey=f3b9c2d1e4a5", Value: "f3b9c2d1e4a5"},
+			},
+			Steps: []SageMakerPipelineStep{
+				{
+					Name:    "Preprocess",
+					Type:    "Processing",
+					Role:    "arn:aws:iam::123456789012:role/sagemaker-evaluation",
+					Buckets: []string{"ml-churn-data"},
+				},
+				{
+					Name:          "Train",
+					Type:          "Training",
+					Role:          "arn:aws:iam::123456789012:role/sagemaker-training",
+					Buckets:       []string{"ml-churn-models"},
+					RoleS3Actions: []string{"s3:GetObject", "s3:ListBucket", "s3:PutObject"},
+					Findings:      []string{sageMakerPipelineBroadS3},
+				},
+				{
+					Name: "CheckAccuracy",
+					Type: "Condition",
+				},
+			},
+			Findings: []string{sageMakerPipelineHardcodedSecret},
+		},
+	}
+	if !reflect.DeepEqual(m.Pipelines, expected) {
+		t.Errorf("Expected pipelines %+v, got %+v", expected, m.Pipelines)
+	}
+
+	stepsFilePath := filepath.Join(".", "cloudfox-output/aws/unittesting-123456789012/table/sagemaker-pipeline-steps.txt")
+	if _, err := afero.ReadFile(fs, stepsFilePath); err != nil {
+		t.Errorf("Cannot read the steps table at %s: %s", stepsFilePath, err)
+	}
+
+	lootFilePath := filepath.Join(".", "cloudfox-output/aws/unittesting-123456789012/loot/sagemaker-pipelines.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	expectedCommands := []string{
+		"aws --profile $profile --region us-east-1 sagemaker describe-pipeline --pipeline-name churn-training --query PipelineDefinition --output text | jq .",
+		"aws --profile $profile s3 ls s3://ml-churn-models/ --recursive --human-readable --summarize",
+	}
+	for _, expected := range expectedCommands {
+		if !strings.Contains(string(lootFile), expected) {
+			t.Errorf("Expected %s to be in the loot file", expected)
+		}
+	}
+}
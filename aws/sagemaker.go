@@ -0,0 +1,466 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	sagemakerTypes "github.com/aws/aws-sdk-go-v2/service/sagemaker/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type SageMakerModule struct {
+	// General configuration data
+	SageMakerClient sdk.SageMakerClientInterface
+	IAMClient       sdk.AWSIAMClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines          int
+	AWSProfile          string
+	SkipAdminCheck      bool
+	WrapTable           bool
+	pmapperMod          PmapperModule
+	pmapperError        error
+	PmapperDataBasePath string
+
+	iamSimClient IamSimulatorModule
+
+	// Main module data
+	Resources      []SageMakerResource
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+// A SageMakerResource is a notebook instance or a Studio domain. Both run code as their execution role.
+type SageMakerResource struct {
+	Region     string
+	Type       string
+	Name       string
+	Arn        string
+	Status     string
+	Network    string
+	Role       string
+	Admin      string
+	CanPrivEsc string
+	// PresignedURL is whether the caller can get a presigned URL to the Jupyter server of an InService notebook
+	PresignedURL string
+}
+
+const sageMakerPresignedNotebookAction = "sagemaker:CreatePresignedNotebookInstanceUrl"
+
+func (m *SageMakerModule) PrintSageMaker(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "sagemaker"
+	localAdminMap := make(map[string]bool)
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating SageMaker notebook instances and domains for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+	m.pmapperMod, m.pmapperError = InitPmapperGraph(m.Caller, m.AWSProfile, m.Goroutines, m.PmapperDataBasePath)
+	m.iamSimClient = InitIamCommandClient(m.IAMClient, m.Caller, m.AWSProfile, m.Goroutines)
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan SageMakerResource)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	// Perform role analysis
+	if m.pmapperError == nil {
+		for i := range m.Resources {
+			m.Resources[i].Admin, m.Resources[i].CanPrivEsc = GetPmapperResults(m.SkipAdminCheck, m.pmapperMod, &m.Resources[i].Role)
+		}
+	} else {
+		for i := range m.Resources {
+			m.Resources[i].Admin, m.Resources[i].CanPrivEsc = GetIamSimResult(m.SkipAdminCheck, &m.Resources[i].Role, m.iamSimClient, localAdminMap)
+		}
+	}
+	m.checkPresignedNotebookAccess()
+
+	sort.Slice(m.Resources, func(i, j int) bool {
+		if m.Resources[i].Region != m.Resources[j].Region {
+			return m.Resources[i].Region < m.Resources[j].Region
+		}
+		if m.Resources[i].Type != m.Resources[j].Type {
+			return m.Resources[i].Type < m.Resources[j].Type
+		}
+		return m.Resources[i].Name < m.Resources[j].Name
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Type",
+		"Name",
+		"Arn",
+		"Status",
+		"Network",
+		"Role",
+		"IsAdminRole?",
+		"CanPrivEscToAdmin?",
+		"Presigned URL?",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Type",
+			"Name",
+			"Arn",
+			"Status",
+			"Network",
+			"Role",
+			"IsAdminRole?",
+			"CanPrivEscToAdmin?",
+			"Presigned URL?",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Type",
+			"Name",
+			"Status",
+			"Role",
+			"IsAdminRole?",
+			"CanPrivEscToAdmin?",
+			"Presigned URL?",
+		}
+	}
+
+	// Remove the pmapper row if there is no pmapper data
+	if m.pmapperError != nil {
+		sharedLogger.Errorf("%s - %s - No pmapper data found for this account. Skipping the pmapper column in the output table.", m.output.CallingModule, m.AWSProfile)
+		tableCols = removeStringFromSlice(tableCols, "CanPrivEscToAdmin?")
+	}
+
+	// Table rows
+	var accessible int
+	for _, resource := range m.Resources {
+		presignedURL := resource.PresignedURL
+		if presignedURL == "Yes" {
+			accessible++
+			presignedURL = magenta(presignedURL)
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				resource.Region,
+				resource.Type,
+				resource.Name,
+				resource.Arn,
+				resource.Status,
+				resource.Network,
+				resource.Role,
+				resource.Admin,
+				resource.CanPrivEsc,
+				presignedURL,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		if accessible > 0 {
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     "sagemaker-presigned-urls",
+				Contents: m.writeLoot(),
+			})
+		}
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d SageMaker notebook instances and domains found, %d notebooks reachable with a presigned URL.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), accessible)
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No SageMaker notebook instances or domains found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *SageMakerModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan SageMakerResource) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("sagemaker", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getNotebookInstancesPerRegion(r, wg, semaphore, dataReceiver)
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getDomainsPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *SageMakerModule) Receiver(receiver chan SageMakerResource, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Resources = append(m.Resources, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *SageMakerModule) getNotebookInstancesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan SageMakerResource) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	notebookInstances, err := sdk.CachedSageMakerListNotebookInstances(m.SageMakerClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, summary := range notebookInstances {
+		resource := SageMakerResource{
+			Region: r,
+			Type:   "Notebook instance",
+			Name:   aws.ToString(summary.NotebookInstanceName),
+			Arn:    aws.ToString(summary.NotebookInstanceArn),
+			Status: string(summary.NotebookInstanceStatus),
+		}
+		notebookInstance, err := sdk.CachedSageMakerDescribeNotebookInstance(m.SageMakerClient, aws.ToString(m.Caller.Account), r, resource.Name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		} else {
+			resource.Status = string(notebookInstance.NotebookInstanceStatus)
+			resource.Role = aws.ToString(notebookInstance.RoleArn)
+			resource.Network = notebookInstanceNetwork(notebookInstance.DirectInternetAccess, aws.ToString(notebookInstance.SubnetId), notebookInstance.RootAccess)
+		}
+		dataReceiver <- resource
+	}
+}
+
+func (m *SageMakerModule) getDomainsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan SageMakerResource) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	domains, err := sdk.CachedSageMakerListDomains(m.SageMakerClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, summary := range domains {
+		resource := SageMakerResource{
+			Region: r,
+			Type:   "Domain",
+			Name:   aws.ToString(summary.DomainName),
+			Arn:    aws.ToString(summary.DomainArn),
+			Status: string(summary.Status),
+			// Presigned notebook URLs only exist for notebook instances
+			PresignedURL: "-",
+		}
+		domain, err := sdk.CachedSageMakerDescribeDomain(m.SageMakerClient, aws.ToString(m.Caller.Account), r, aws.ToString(summary.DomainId))
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		} else {
+			resource.Role = aws.ToString(domain.ExecutionRole)
+			resource.Network = string(domain.AppNetworkAccessType)
+		}
+		dataReceiver <- resource
+	}
+}
+
+// notebookInstanceNetwork summarizes how a notebook reaches the internet and whether its users get root
+func notebookInstanceNetwork(directInternetAccess sagemakerTypes.DirectInternetAccess, subnetID string, rootAccess sagemakerTypes.RootAccess) string {
+	var network []string
+	if directInternetAccess == sagemakerTypes.DirectInternetAccessEnabled {
+		network = append(network, "Direct internet")
+	} else if subnetID != "" {
+		network = append(network, "VPC only ("+subnetID+")")
+	}
+	if rootAccess == sagemakerTypes.RootAccessEnabled {
+		network = append(network, "root access")
+	}
+	return strings.Join(network, ", ")
+}
+
+// checkPresignedNotebookAccess flags InService notebook instances the caller can open in a browser. Anyone who can
+// create a presigned URL gets a Jupyter terminal running as the notebook's execution role.
+func (m *SageMakerModule) checkPresignedNotebookAccess() {
+	callerArn := m.callerPolicySourceArn()
+	for i := range m.Resources {
+		if m.Resources[i].Type != "Notebook instance" {
+			continue
+		}
+		if m.Resources[i].Status != string(sagemakerTypes.NotebookInstanceStatusInService) {
+			m.Resources[i].PresignedURL = "No (not InService)"
+			continue
+		}
+		if callerArn == "" {
+			m.Resources[i].PresignedURL = "Unknown"
+			continue
+		}
+		// The root user can do everything and can't be simulated
+		if strings.HasSuffix(callerArn, ":root") {
+			m.Resources[i].PresignedURL = "Yes"
+			continue
+		}
+		evaluationResults, err := sdk.CachedIamSimulatePrincipalPolicy(m.IAMClient, aws.ToString(m.Caller.Account), aws.String(callerArn), []string{sageMakerPresignedNotebookAction}, []string{m.Resources[i].Arn})
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			m.Resources[i].PresignedURL = "Unknown"
+			continue
+		}
+		m.Resources[i].PresignedURL = "No"
+		for _, result := range evaluationResults {
+			if aws.ToString(result.EvalActionName) == sageMakerPresignedNotebookAction && result.EvalDecision == "allowed" {
+				m.Resources[i].PresignedURL = "Yes"
+			}
+		}
+	}
+}
+
+// callerPolicySourceArn returns an ARN the policy simulator accepts for the caller, or an empty string when the role
+// of an assumed role session can't be found
+func (m *SageMakerModule) callerPolicySourceArn() string {
+	sourceArn, err := policySimulatorSourceArn(m.IAMClient, m.Caller)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	return sourceArn
+}
+
+// policySimulatorSourceArn maps the caller to an ARN the policy simulator accepts. Assumed role sessions are mapped
+// back to their role, which needs the role's path, so it is looked up.
+func policySimulatorSourceArn(IAMClient sdk.AWSIAMClientInterface, caller sts.GetCallerIdentityOutput) (string, error) {
+	callerArn := aws.ToString(caller.Arn)
+	if !strings.Contains(callerArn, ":assumed-role/") {
+		return callerArn, nil
+	}
+	roleName := strings.Split(callerArn[strings.Index(callerArn, ":assumed-role/")+len(":assumed-role/"):], "/")[0]
+	roles, err := sdk.CachedIamListRoles(IAMClient, aws.ToString(caller.Account))
+	if err != nil {
+		return "", err
+	}
+	for _, role := range roles {
+		if aws.ToString(role.RoleName) == roleName {
+			return aws.ToString(role.Arn), nil
+		}
+	}
+	return "", nil
+}
+
+func (m *SageMakerModule) writeLoot() string {
+	var out string
+	out += fmt.Sprintln("#############################################")
+	out += fmt.Sprintln("# Open a Jupyter server that runs as the notebook's execution role. New > Terminal gives you a shell")
+	out += fmt.Sprintln("# where `aws sts get-caller-identity` shows the role.")
+	out += fmt.Sprintln("# Set the $profile environment variable to the profile you are going to use, e.g. export profile=dev-prod.")
+	out += fmt.Sprintln("#############################################")
+	out += fmt.Sprintln("")
+	for _, resource := range m.Resources {
+		if resource.PresignedURL != "Yes" {
+			continue
+		}
+		out += fmt.Sprintf("# %s runs as %s\n", resource.Name, resource.Role)
+		out += fmt.Sprintf("aws --profile $profile --region %s sagemaker create-presigned-notebook-instance-url --notebook-instance-name %s --query AuthorizedUrl --output text\n\n", resource.Region, resource.Name)
+	}
+	return out
+}
@@ -0,0 +1,69 @@
+package aws
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestSageMaker(t *testing.T) {
+	m := SageMakerModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:      3,
+		WrapTable:       false,
+		SkipAdminCheck:  true,
+		SageMakerClient: &sdk.MockedSageMakerClient{},
+		IAMClient:       &sdk.MockedIAMClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+	tmpDir := "."
+
+	m.PrintSageMaker(tmpDir, 2)
+
+	expected := map[string]struct {
+		role         string
+		presignedURL string
+	}{
+		"research-notebook": {role: "arn:aws:iam::123456789012:role/role1", presignedURL: "Yes"},
+		"stopped-notebook":  {role: "arn:aws:iam::123456789012:role/role2", presignedURL: "No (not InService)"},
+		"ml-domain":         {role: "arn:aws:iam::123456789012:role/role3", presignedURL: "-"},
+	}
+	if len(m.Resources) != len(expected) {
+		t.Fatalf("Expected %d SageMaker resources, got %d", len(expected), len(m.Resources))
+	}
+	for _, resource := range m.Resources {
+		want, ok := expected[resource.Name]
+		if !ok {
+			t.Errorf("Unexpected SageMaker resource %s", resource.Name)
+			continue
+		}
+		if resource.Role != want.role || resource.PresignedURL != want.presignedURL {
+			t.Errorf("Expected %s to run as %s with presigned URL %q, got %s and %q", resource.Name, want.role, want.presignedURL, resource.Role, resource.PresignedURL)
+		}
+	}
+
+	lootFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/loot/sagemaker-presigned-urls.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	if !strings.Contains(string(lootFile), "sagemaker create-presigned-notebook-instance-url --notebook-instance-name research-notebook") {
+		t.Errorf("Expected the InService notebook in the loot file")
+	}
+	if strings.Contains(string(lootFile), "stopped-notebook") {
+		t.Errorf("Did not expect the stopped notebook in the loot file")
+	}
+}
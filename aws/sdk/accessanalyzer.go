@@ -0,0 +1,56 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/service/accessanalyzer"
+	accessAnalyzerTypes "github.com/aws/aws-sdk-go-v2/service/accessanalyzer/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type AccessAnalyzerClientInterface interface {
+	ListAnalyzers(ctx context.Context, params *accessanalyzer.ListAnalyzersInput, optFns ...func(*accessanalyzer.Options)) (*accessanalyzer.ListAnalyzersOutput, error)
+}
+
+func init() {
+	gob.Register([]accessAnalyzerTypes.AnalyzerSummary{})
+}
+
+func CachedAccessAnalyzerListAnalyzers(client AccessAnalyzerClientInterface, accountID string, region string) ([]accessAnalyzerTypes.AnalyzerSummary, error) {
+	var PaginationControl *string
+	var analyzers []accessAnalyzerTypes.AnalyzerSummary
+	cacheKey := fmt.Sprintf("%s-accessanalyzer-ListAnalyzers-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]accessAnalyzerTypes.AnalyzerSummary), nil
+	}
+	for {
+		ListAnalyzers, err := client.ListAnalyzers(
+			context.TODO(),
+			&accessanalyzer.ListAnalyzersInput{
+				NextToken: PaginationControl,
+			},
+			func(o *accessanalyzer.Options) {
+				o.Region = region
+			},
+		)
+
+		if err != nil {
+			return analyzers, err
+		}
+
+		analyzers = append(analyzers, ListAnalyzers.Analyzers...)
+
+		//pagination
+		if ListAnalyzers.NextToken == nil {
+			break
+		}
+		PaginationControl = ListAnalyzers.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, analyzers, cache.DefaultExpiration)
+	return analyzers, nil
+}
@@ -0,0 +1,25 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/accessanalyzer"
+	accessAnalyzerTypes "github.com/aws/aws-sdk-go-v2/service/accessanalyzer/types"
+)
+
+type MockedAccessAnalyzerClient struct {
+}
+
+func (m *MockedAccessAnalyzerClient) ListAnalyzers(ctx context.Context, input *accessanalyzer.ListAnalyzersInput, options ...func(*accessanalyzer.Options)) (*accessanalyzer.ListAnalyzersOutput, error) {
+	return &accessanalyzer.ListAnalyzersOutput{
+		Analyzers: []accessAnalyzerTypes.AnalyzerSummary{
+			{
+				Name:   aws.String("org-analyzer"),
+				Arn:    aws.String("arn:aws:access-analyzer:us-east-1:123456789012:analyzer/org-analyzer"),
+				Type:   accessAnalyzerTypes.TypeOrganization,
+				Status: accessAnalyzerTypes.AnalyzerStatusActive,
+			},
+		},
+	}, nil
+}
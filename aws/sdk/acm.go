@@ -0,0 +1,92 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/service/acm"
+	acmTypes "github.com/aws/aws-sdk-go-v2/service/acm/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type ACMClientInterface interface {
+	ListCertificates(ctx context.Context, params *acm.ListCertificatesInput, optFns ...func(*acm.Options)) (*acm.ListCertificatesOutput, error)
+	DescribeCertificate(ctx context.Context, params *acm.DescribeCertificateInput, optFns ...func(*acm.Options)) (*acm.DescribeCertificateOutput, error)
+}
+
+func init() {
+	gob.Register([]acmTypes.CertificateSummary{})
+	gob.Register(acmTypes.CertificateDetail{})
+}
+
+func CachedACMListCertificates(client ACMClientInterface, accountID string, region string) ([]acmTypes.CertificateSummary, error) {
+	var PaginationControl *string
+	var certificates []acmTypes.CertificateSummary
+	cacheKey := fmt.Sprintf("%s-acm-ListCertificates-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]acmTypes.CertificateSummary), nil
+	}
+
+	for {
+		ListCertificates, err := client.ListCertificates(
+			context.TODO(),
+			&acm.ListCertificatesInput{
+				NextToken: PaginationControl,
+				// By default only RSA_1024 and RSA_2048 certificates are returned
+				Includes: &acmTypes.Filters{
+					KeyTypes: acmTypes.KeyAlgorithm("").Values(),
+				},
+			},
+			func(o *acm.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return certificates, err
+		}
+
+		certificates = append(certificates, ListCertificates.CertificateSummaryList...)
+
+		// Pagination control. After the last page of output, the for loop exits.
+		if ListCertificates.NextToken != nil {
+			PaginationControl = ListCertificates.NextToken
+		} else {
+			PaginationControl = nil
+			break
+		}
+	}
+
+	internal.Cache.Set(cacheKey, certificates, cache.DefaultExpiration)
+	return certificates, nil
+}
+
+func CachedACMDescribeCertificate(client ACMClientInterface, accountID string, region string, certificateArn string) (acmTypes.CertificateDetail, error) {
+	var certificate acmTypes.CertificateDetail
+	cacheKey := fmt.Sprintf("%s-acm-DescribeCertificate-%s-%s", accountID, region, certificateArn)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(acmTypes.CertificateDetail), nil
+	}
+
+	DescribeCertificate, err := client.DescribeCertificate(
+		context.TODO(),
+		&acm.DescribeCertificateInput{
+			CertificateArn: &certificateArn,
+		},
+		func(o *acm.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return certificate, err
+	}
+
+	if DescribeCertificate.Certificate != nil {
+		certificate = *DescribeCertificate.Certificate
+	}
+	internal.Cache.Set(cacheKey, certificate, cache.DefaultExpiration)
+	return certificate, nil
+}
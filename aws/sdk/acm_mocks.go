@@ -0,0 +1,77 @@
+package sdk
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/acm"
+	acmTypes "github.com/aws/aws-sdk-go-v2/service/acm/types"
+)
+
+type MockedACMClient struct {
+}
+
+func (m *MockedACMClient) ListCertificates(ctx context.Context, input *acm.ListCertificatesInput, options ...func(*acm.Options)) (*acm.ListCertificatesOutput, error) {
+	return &acm.ListCertificatesOutput{
+		CertificateSummaryList: []acmTypes.CertificateSummary{
+			{
+				CertificateArn: aws.String("arn:aws:acm:us-east-1:123456789012:certificate/11111111-1111-1111-1111-111111111111"),
+				DomainName:     aws.String("www.example.com"),
+			},
+			{
+				CertificateArn: aws.String("arn:aws:acm:us-east-1:123456789012:certificate/22222222-2222-2222-2222-222222222222"),
+				DomainName:     aws.String("legacy.example.com"),
+			},
+			{
+				CertificateArn: aws.String("arn:aws:acm:us-east-1:123456789012:certificate/33333333-3333-3333-3333-333333333333"),
+				DomainName:     aws.String("internal.corp.example.com"),
+			},
+		},
+	}, nil
+}
+
+func (m *MockedACMClient) DescribeCertificate(ctx context.Context, input *acm.DescribeCertificateInput, options ...func(*acm.Options)) (*acm.DescribeCertificateOutput, error) {
+	now := time.Now()
+	certificates := map[string]acmTypes.CertificateDetail{
+		"arn:aws:acm:us-east-1:123456789012:certificate/11111111-1111-1111-1111-111111111111": {
+			CertificateArn:          aws.String("arn:aws:acm:us-east-1:123456789012:certificate/11111111-1111-1111-1111-111111111111"),
+			DomainName:              aws.String("www.example.com"),
+			SubjectAlternativeNames: []string{"www.example.com", "example.com"},
+			Issuer:                  aws.String("Amazon"),
+			Type:                    acmTypes.CertificateTypeAmazonIssued,
+			Status:                  acmTypes.CertificateStatusIssued,
+			NotAfter:                aws.Time(now.AddDate(0, 0, 10)),
+			RenewalEligibility:      acmTypes.RenewalEligibilityEligible,
+			RenewalSummary: &acmTypes.RenewalSummary{
+				RenewalStatus: acmTypes.RenewalStatusFailed,
+			},
+			InUseBy: []string{"arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/alb1/1234567890"},
+		},
+		"arn:aws:acm:us-east-1:123456789012:certificate/22222222-2222-2222-2222-222222222222": {
+			CertificateArn:          aws.String("arn:aws:acm:us-east-1:123456789012:certificate/22222222-2222-2222-2222-222222222222"),
+			DomainName:              aws.String("legacy.example.com"),
+			SubjectAlternativeNames: []string{"legacy.example.com"},
+			Issuer:                  aws.String("DigiCert Inc"),
+			Type:                    acmTypes.CertificateTypeImported,
+			Status:                  acmTypes.CertificateStatusExpired,
+			NotAfter:                aws.Time(now.AddDate(0, 0, -5)),
+			RenewalEligibility:      acmTypes.RenewalEligibilityIneligible,
+		},
+		"arn:aws:acm:us-east-1:123456789012:certificate/33333333-3333-3333-3333-333333333333": {
+			CertificateArn:          aws.String("arn:aws:acm:us-east-1:123456789012:certificate/33333333-3333-3333-3333-333333333333"),
+			DomainName:              aws.String("internal.corp.example.com"),
+			SubjectAlternativeNames: []string{"internal.corp.example.com"},
+			Issuer:                  aws.String("Corp Internal CA"),
+			Type:                    acmTypes.CertificateTypePrivate,
+			Status:                  acmTypes.CertificateStatusIssued,
+			NotAfter:                aws.Time(now.AddDate(1, 0, 0)),
+			RenewalEligibility:      acmTypes.RenewalEligibilityEligible,
+		},
+	}
+
+	certificate := certificates[aws.ToString(input.CertificateArn)]
+	return &acm.DescribeCertificateOutput{
+		Certificate: &certificate,
+	}, nil
+}
@@ -0,0 +1,240 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/amp"
+	ampTypes "github.com/aws/aws-sdk-go-v2/service/amp/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type AMPClientInterface interface {
+	ListWorkspaces(context.Context, *amp.ListWorkspacesInput, ...func(*amp.Options)) (*amp.ListWorkspacesOutput, error)
+	DescribeWorkspace(context.Context, *amp.DescribeWorkspaceInput, ...func(*amp.Options)) (*amp.DescribeWorkspaceOutput, error)
+	DescribeAlertManagerDefinition(context.Context, *amp.DescribeAlertManagerDefinitionInput, ...func(*amp.Options)) (*amp.DescribeAlertManagerDefinitionOutput, error)
+}
+
+// PrometheusRulesClientInterface reads the rules loaded into a workspace through the Prometheus HTTP API. It's not
+// part of the AMP control plane, so the AWS SDK doesn't have a client for it.
+type PrometheusRulesClientInterface interface {
+	ListRules(ctx context.Context, prometheusEndpoint string, region string) ([]PrometheusRuleGroup, error)
+}
+
+func init() {
+	gob.Register([]ampTypes.WorkspaceSummary{})
+	gob.Register(ampTypes.WorkspaceDescription{})
+	gob.Register([]PrometheusRuleGroup{})
+}
+
+func CachedAMPListWorkspaces(client AMPClientInterface, accountID string, region string) ([]ampTypes.WorkspaceSummary, error) {
+	var PaginationControl *string
+	var workspaces []ampTypes.WorkspaceSummary
+	cacheKey := fmt.Sprintf("%s-amp-ListWorkspaces-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]ampTypes.WorkspaceSummary), nil
+	}
+	for {
+		ListWorkspaces, err := client.ListWorkspaces(
+			context.TODO(),
+			&amp.ListWorkspacesInput{
+				NextToken: PaginationControl,
+			},
+			func(o *amp.Options) {
+				o.Region = region
+			},
+		)
+
+		if err != nil {
+			return workspaces, err
+		}
+
+		workspaces = append(workspaces, ListWorkspaces.Workspaces...)
+
+		//pagination
+		if ListWorkspaces.NextToken == nil {
+			break
+		}
+		PaginationControl = ListWorkspaces.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, workspaces, cache.DefaultExpiration)
+	return workspaces, nil
+}
+
+func CachedAMPDescribeWorkspace(client AMPClientInterface, accountID string, region string, workspaceID string) (ampTypes.WorkspaceDescription, error) {
+	var workspace ampTypes.WorkspaceDescription
+	cacheKey := fmt.Sprintf("%s-amp-DescribeWorkspace-%s-%s", accountID, region, workspaceID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(ampTypes.WorkspaceDescription), nil
+	}
+
+	DescribeWorkspace, err := client.DescribeWorkspace(
+		context.TODO(),
+		&amp.DescribeWorkspaceInput{
+			WorkspaceId: &workspaceID,
+		},
+		func(o *amp.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return workspace, err
+	}
+	if DescribeWorkspace.Workspace != nil {
+		workspace = *DescribeWorkspace.Workspace
+	}
+
+	internal.Cache.Set(cacheKey, workspace, cache.DefaultExpiration)
+	return workspace, nil
+}
+
+// CachedAMPDescribeAlertManagerDefinition returns the alert manager definition (YAML) of a workspace. Workspaces
+// without one return an empty definition and no error.
+func CachedAMPDescribeAlertManagerDefinition(client AMPClientInterface, accountID string, region string, workspaceID string) (string, error) {
+	cacheKey := fmt.Sprintf("%s-amp-DescribeAlertManagerDefinition-%s-%s", accountID, region, workspaceID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(string), nil
+	}
+
+	var definition string
+	DescribeAlertManagerDefinition, err := client.DescribeAlertManagerDefinition(
+		context.TODO(),
+		&amp.DescribeAlertManagerDefinitionInput{
+			WorkspaceId: &workspaceID,
+		},
+		func(o *amp.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		var notFound *ampTypes.ResourceNotFoundException
+		if !errors.As(err, &notFound) {
+			return definition, err
+		}
+	} else if DescribeAlertManagerDefinition.AlertManagerDefinition != nil {
+		definition = string(DescribeAlertManagerDefinition.AlertManagerDefinition.Data)
+	}
+
+	internal.Cache.Set(cacheKey, definition, cache.DefaultExpiration)
+	return definition, nil
+}
+
+// PrometheusRuleGroup is a rule group as returned by the Prometheus /api/v1/rules endpoint
+type PrometheusRuleGroup struct {
+	Name  string           `json:"name"`
+	File  string           `json:"file"`
+	Rules []PrometheusRule `json:"rules"`
+}
+
+type PrometheusRule struct {
+	Name        string            `json:"name"`
+	Query       string            `json:"query"`
+	Type        string            `json:"type"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+type prometheusRulesResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		Groups []PrometheusRuleGroup `json:"groups"`
+	} `json:"data"`
+}
+
+// PrometheusRulesClient calls the Prometheus HTTP API of AMP workspaces with SigV4 signed requests
+type PrometheusRulesClient struct {
+	Credentials aws.CredentialsProvider
+	HTTPClient  *http.Client
+	signer      *v4.Signer
+}
+
+func NewPrometheusRulesClient(cfg aws.Config) *PrometheusRulesClient {
+	return &PrometheusRulesClient{
+		Credentials: cfg.Credentials,
+		HTTPClient:  &http.Client{Timeout: 30 * time.Second},
+		signer:      v4.NewSigner(),
+	}
+}
+
+// The SHA-256 of an empty body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func (c *PrometheusRulesClient) ListRules(ctx context.Context, prometheusEndpoint string, region string) ([]PrometheusRuleGroup, error) {
+	url := strings.TrimSuffix(prometheusEndpoint, "/") + "/api/v1/rules"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	credentials, err := c.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = c.signer.SignHTTP(ctx, credentials, req, emptyPayloadHash, "aps", region, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &PrometheusAPIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+	}
+
+	var rules prometheusRulesResponse
+	if err := json.Unmarshal(body, &rules); err != nil {
+		return nil, err
+	}
+	if rules.Status != "success" {
+		return nil, fmt.Errorf("%s: %s", rules.ErrorType, rules.Error)
+	}
+	return rules.Data.Groups, nil
+}
+
+// PrometheusAPIError is returned when the Prometheus HTTP API responds with anything but 200
+type PrometheusAPIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *PrometheusAPIError) Error() string {
+	return fmt.Sprintf("prometheus API returned %d: %s", e.StatusCode, e.Message)
+}
+
+func CachedPrometheusListRules(client PrometheusRulesClientInterface, accountID string, region string, workspaceID string, prometheusEndpoint string) ([]PrometheusRuleGroup, error) {
+	cacheKey := fmt.Sprintf("%s-prometheus-ListRules-%s-%s", accountID, region, workspaceID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]PrometheusRuleGroup), nil
+	}
+
+	groups, err := client.ListRules(context.TODO(), prometheusEndpoint, region)
+	if err != nil {
+		return groups, err
+	}
+
+	internal.Cache.Set(cacheKey, groups, cache.DefaultExpiration)
+	return groups, nil
+}
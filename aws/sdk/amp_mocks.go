@@ -0,0 +1,125 @@
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/amp"
+	ampTypes "github.com/aws/aws-sdk-go-v2/service/amp/types"
+)
+
+// MockedAMPClient has a security monitoring workspace with an alert manager, an infrastructure workspace without
+// one, and a workspace whose rules the caller isn't allowed to read
+type MockedAMPClient struct {
+}
+
+func mockedAMPWorkspace(id string, alias string) ampTypes.WorkspaceSummary {
+	return ampTypes.WorkspaceSummary{
+		WorkspaceId: aws.String(id),
+		Alias:       aws.String(alias),
+		Arn:         aws.String("arn:aws:aps:us-east-1:123456789012:workspace/" + id),
+		Status:      &ampTypes.WorkspaceStatus{StatusCode: ampTypes.WorkspaceStatusCodeActive},
+	}
+}
+
+func (m *MockedAMPClient) ListWorkspaces(ctx context.Context, input *amp.ListWorkspacesInput, options ...func(*amp.Options)) (*amp.ListWorkspacesOutput, error) {
+	return &amp.ListWorkspacesOutput{
+		Workspaces: []ampTypes.WorkspaceSummary{
+			mockedAMPWorkspace("ws-11111111-1111-1111-1111-111111111111", "security-monitoring"),
+			mockedAMPWorkspace("ws-22222222-2222-2222-2222-222222222222", "infra"),
+			mockedAMPWorkspace("ws-33333333-3333-3333-3333-333333333333", "payments"),
+		},
+	}, nil
+}
+
+func (m *MockedAMPClient) DescribeWorkspace(ctx context.Context, input *amp.DescribeWorkspaceInput, options ...func(*amp.Options)) (*amp.DescribeWorkspaceOutput, error) {
+	workspaceID := aws.ToString(input.WorkspaceId)
+	return &amp.DescribeWorkspaceOutput{
+		Workspace: &ampTypes.WorkspaceDescription{
+			WorkspaceId:        input.WorkspaceId,
+			Arn:                aws.String("arn:aws:aps:us-east-1:123456789012:workspace/" + workspaceID),
+			PrometheusEndpoint: aws.String("https://aps-workspaces.us-east-1.amazonaws.com/workspaces/" + workspaceID + "/"),
+			Status:             &ampTypes.WorkspaceStatus{StatusCode: ampTypes.WorkspaceStatusCodeActive},
+		},
+	}, nil
+}
+
+func (m *MockedAMPClient) DescribeAlertManagerDefinition(ctx context.Context, input *amp.DescribeAlertManagerDefinitionInput, options ...func(*amp.Options)) (*amp.DescribeAlertManagerDefinitionOutput, error) {
+	if aws.ToString(input.WorkspaceId) != "ws-11111111-1111-1111-1111-111111111111" {
+		return nil, &ampTypes.ResourceNotFoundException{Message: aws.String("Alert manager definition not found")}
+	}
+	return &amp.DescribeAlertManagerDefinitionOutput{
+		AlertManagerDefinition: &ampTypes.AlertManagerDefinitionDescription{
+			Data: []byte(`alertmanager_config: |
+  route:
+    receiver: security-team
+  receivers:
+    - name: security-team
+      sns_configs:
+        - topic_arn: arn:aws:sns:us-east-1:123456789012:security-alerts
+          sigv4:
+            region: us-east-1
+    - name: blackhole
+`),
+			Status: &ampTypes.AlertManagerDefinitionStatus{StatusCode: ampTypes.AlertManagerDefinitionStatusCodeActive},
+		},
+	}, nil
+}
+
+type MockedPrometheusRulesClient struct {
+}
+
+func (m *MockedPrometheusRulesClient) ListRules(ctx context.Context, prometheusEndpoint string, region string) ([]PrometheusRuleGroup, error) {
+	switch {
+	case strings.Contains(prometheusEndpoint, "ws-11111111-1111-1111-1111-111111111111"):
+		return []PrometheusRuleGroup{
+			{
+				Name: "security",
+				File: "security-rules",
+				Rules: []PrometheusRule{
+					{
+						Name:        "UnauthorizedAccessSpike",
+						Query:       `sum(rate(unauthorized_access_total[5m])) > 10`,
+						Type:        "alerting",
+						Labels:      map[string]string{"severity": "critical"},
+						Annotations: map[string]string{"summary": "Spike in unauthorized API calls"},
+					},
+					{
+						Name:  "FailedLogins",
+						Query: `sum by (user) (increase(failed_login_total[15m])) > 20`,
+						Type:  "alerting",
+					},
+				},
+			},
+			{
+				Name: "recording",
+				File: "recording-rules",
+				Rules: []PrometheusRule{
+					{
+						Name:  "job:http_requests:rate5m",
+						Query: `sum by (job) (rate(http_requests_total[5m]))`,
+						Type:  "recording",
+					},
+				},
+			},
+		}, nil
+	case strings.Contains(prometheusEndpoint, "ws-22222222-2222-2222-2222-222222222222"):
+		return []PrometheusRuleGroup{
+			{
+				Name: "infra",
+				File: "infra-rules",
+				Rules: []PrometheusRule{
+					{
+						Name:  "HighCPU",
+						Query: `avg(node_cpu_utilisation) > 0.9`,
+						Type:  "alerting",
+					},
+				},
+			},
+		}, nil
+	default:
+		return nil, &PrometheusAPIError{StatusCode: http.StatusForbidden, Message: `{"message":"User is not authorized to perform: aps:QueryMetrics"}`}
+	}
+}
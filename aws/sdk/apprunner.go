@@ -13,11 +13,13 @@ import (
 
 type AppRunnerClientInterface interface {
 	ListServices(context.Context, *apprunner.ListServicesInput, ...func(*apprunner.Options)) (*apprunner.ListServicesOutput, error)
+	DescribeService(context.Context, *apprunner.DescribeServiceInput, ...func(*apprunner.Options)) (*apprunner.DescribeServiceOutput, error)
 }
 
 func init() {
 	gob.Register([]apprunnerTypes.Service{})
 	gob.Register([]apprunnerTypes.ServiceSummary{})
+	gob.Register(apprunnerTypes.Service{})
 }
 
 func CachedAppRunnerListServices(client AppRunnerClientInterface, accountID string, region string) ([]apprunnerTypes.ServiceSummary, error) {
@@ -55,3 +57,30 @@ func CachedAppRunnerListServices(client AppRunnerClientInterface, accountID stri
 	internal.Cache.Set(cacheKey, services, cache.DefaultExpiration)
 	return services, nil
 }
+
+func CachedAppRunnerDescribeService(client AppRunnerClientInterface, accountID string, region string, serviceArn string) (apprunnerTypes.Service, error) {
+	var service apprunnerTypes.Service
+	cacheKey := fmt.Sprintf("%s-apprunner-DescribeService-%s-%s", accountID, region, serviceArn)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(apprunnerTypes.Service), nil
+	}
+	DescribeService, err := client.DescribeService(
+		context.TODO(),
+		&apprunner.DescribeServiceInput{
+			ServiceArn: &serviceArn,
+		},
+		func(o *apprunner.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return service, err
+	}
+	if DescribeService.Service != nil {
+		service = *DescribeService.Service
+	}
+
+	internal.Cache.Set(cacheKey, service, cache.DefaultExpiration)
+	return service, nil
+}
@@ -0,0 +1,157 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/backup"
+	backupTypes "github.com/aws/aws-sdk-go-v2/service/backup/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type BackupClientInterface interface {
+	ListBackupPlans(context.Context, *backup.ListBackupPlansInput, ...func(*backup.Options)) (*backup.ListBackupPlansOutput, error)
+	GetBackupPlan(context.Context, *backup.GetBackupPlanInput, ...func(*backup.Options)) (*backup.GetBackupPlanOutput, error)
+	ListBackupSelections(context.Context, *backup.ListBackupSelectionsInput, ...func(*backup.Options)) (*backup.ListBackupSelectionsOutput, error)
+	GetBackupSelection(context.Context, *backup.GetBackupSelectionInput, ...func(*backup.Options)) (*backup.GetBackupSelectionOutput, error)
+}
+
+func init() {
+	gob.Register([]backupTypes.BackupPlansListMember{})
+	gob.Register(backupTypes.BackupPlan{})
+	gob.Register([]backupTypes.BackupSelectionsListMember{})
+	gob.Register(backupTypes.BackupSelection{})
+}
+
+func CachedBackupListBackupPlans(client BackupClientInterface, accountID string, region string) ([]backupTypes.BackupPlansListMember, error) {
+	var PaginationControl *string
+	var plans []backupTypes.BackupPlansListMember
+	cacheKey := fmt.Sprintf("%s-backup-ListBackupPlans-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]backupTypes.BackupPlansListMember), nil
+	}
+
+	for {
+		ListBackupPlans, err := client.ListBackupPlans(
+			context.TODO(),
+			&backup.ListBackupPlansInput{
+				NextToken: PaginationControl,
+			},
+			func(o *backup.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return plans, err
+		}
+
+		plans = append(plans, ListBackupPlans.BackupPlansList...)
+
+		//pagination
+		if ListBackupPlans.NextToken == nil {
+			break
+		}
+		PaginationControl = ListBackupPlans.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, plans, cache.DefaultExpiration)
+	return plans, nil
+}
+
+func CachedBackupGetBackupPlan(client BackupClientInterface, accountID string, region string, planID string) (backupTypes.BackupPlan, error) {
+	cacheKey := fmt.Sprintf("%s-backup-GetBackupPlan-%s-%s", accountID, region, planID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(backupTypes.BackupPlan), nil
+	}
+
+	GetBackupPlan, err := client.GetBackupPlan(
+		context.TODO(),
+		&backup.GetBackupPlanInput{
+			BackupPlanId: aws.String(planID),
+		},
+		func(o *backup.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return backupTypes.BackupPlan{}, err
+	}
+	if GetBackupPlan.BackupPlan == nil {
+		return backupTypes.BackupPlan{}, fmt.Errorf("backup plan %s not found", planID)
+	}
+
+	internal.Cache.Set(cacheKey, *GetBackupPlan.BackupPlan, cache.DefaultExpiration)
+	return *GetBackupPlan.BackupPlan, nil
+}
+
+func CachedBackupListBackupSelections(client BackupClientInterface, accountID string, region string, planID string) ([]backupTypes.BackupSelectionsListMember, error) {
+	var PaginationControl *string
+	var selections []backupTypes.BackupSelectionsListMember
+	cacheKey := fmt.Sprintf("%s-backup-ListBackupSelections-%s-%s", accountID, region, planID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]backupTypes.BackupSelectionsListMember), nil
+	}
+
+	for {
+		ListBackupSelections, err := client.ListBackupSelections(
+			context.TODO(),
+			&backup.ListBackupSelectionsInput{
+				BackupPlanId: aws.String(planID),
+				NextToken:    PaginationControl,
+			},
+			func(o *backup.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return selections, err
+		}
+
+		selections = append(selections, ListBackupSelections.BackupSelectionsList...)
+
+		//pagination
+		if ListBackupSelections.NextToken == nil {
+			break
+		}
+		PaginationControl = ListBackupSelections.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, selections, cache.DefaultExpiration)
+	return selections, nil
+}
+
+// CachedBackupGetBackupSelection returns the resources, tags and conditions a selection assigns to its backup plan.
+// ListBackupSelections only returns the selection names.
+func CachedBackupGetBackupSelection(client BackupClientInterface, accountID string, region string, planID string, selectionID string) (backupTypes.BackupSelection, error) {
+	cacheKey := fmt.Sprintf("%s-backup-GetBackupSelection-%s-%s-%s", accountID, region, planID, selectionID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(backupTypes.BackupSelection), nil
+	}
+
+	GetBackupSelection, err := client.GetBackupSelection(
+		context.TODO(),
+		&backup.GetBackupSelectionInput{
+			BackupPlanId: aws.String(planID),
+			SelectionId:  aws.String(selectionID),
+		},
+		func(o *backup.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return backupTypes.BackupSelection{}, err
+	}
+	if GetBackupSelection.BackupSelection == nil {
+		return backupTypes.BackupSelection{}, fmt.Errorf("backup selection %s not found", selectionID)
+	}
+
+	internal.Cache.Set(cacheKey, *GetBackupSelection.BackupSelection, cache.DefaultExpiration)
+	return *GetBackupSelection.BackupSelection, nil
+}
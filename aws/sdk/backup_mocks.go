@@ -0,0 +1,115 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/backup"
+	backupTypes "github.com/aws/aws-sdk-go-v2/service/backup/types"
+)
+
+// MockedBackupClient has a daily plan that selects resources by ARN and a weekly plan that selects anything tagged
+// backup=weekly
+type MockedBackupClient struct {
+}
+
+func (m *MockedBackupClient) ListBackupPlans(ctx context.Context, input *backup.ListBackupPlansInput, options ...func(*backup.Options)) (*backup.ListBackupPlansOutput, error) {
+	return &backup.ListBackupPlansOutput{
+		BackupPlansList: []backupTypes.BackupPlansListMember{
+			{
+				BackupPlanId:   aws.String("plan-daily"),
+				BackupPlanName: aws.String("daily"),
+				BackupPlanArn:  aws.String("arn:aws:backup:us-east-1:123456789012:backup-plan:plan-daily"),
+			},
+			{
+				BackupPlanId:   aws.String("plan-weekly"),
+				BackupPlanName: aws.String("weekly"),
+				BackupPlanArn:  aws.String("arn:aws:backup:us-east-1:123456789012:backup-plan:plan-weekly"),
+			},
+		},
+	}, nil
+}
+
+func (m *MockedBackupClient) GetBackupPlan(ctx context.Context, input *backup.GetBackupPlanInput, options ...func(*backup.Options)) (*backup.GetBackupPlanOutput, error) {
+	switch aws.ToString(input.BackupPlanId) {
+	case "plan-daily":
+		return &backup.GetBackupPlanOutput{
+			BackupPlan: &backupTypes.BackupPlan{
+				BackupPlanName: aws.String("daily"),
+				Rules: []backupTypes.BackupRule{
+					{
+						RuleName:              aws.String("daily-35d"),
+						TargetBackupVaultName: aws.String("Default"),
+						ScheduleExpression:    aws.String("cron(0 5 ? * * *)"),
+						Lifecycle: &backupTypes.Lifecycle{
+							DeleteAfterDays: aws.Int64(35),
+						},
+					},
+				},
+			},
+		}, nil
+	default:
+		return &backup.GetBackupPlanOutput{
+			BackupPlan: &backupTypes.BackupPlan{
+				BackupPlanName: aws.String("weekly"),
+				Rules: []backupTypes.BackupRule{
+					{
+						RuleName:              aws.String("weekly"),
+						TargetBackupVaultName: aws.String("locked-vault"),
+						ScheduleExpression:    aws.String("cron(0 5 ? * 1 *)"),
+					},
+				},
+			},
+		}, nil
+	}
+}
+
+func (m *MockedBackupClient) ListBackupSelections(ctx context.Context, input *backup.ListBackupSelectionsInput, options ...func(*backup.Options)) (*backup.ListBackupSelectionsOutput, error) {
+	planID := aws.ToString(input.BackupPlanId)
+	return &backup.ListBackupSelectionsOutput{
+		BackupSelectionsList: []backupTypes.BackupSelectionsListMember{
+			{
+				BackupPlanId:  input.BackupPlanId,
+				SelectionId:   aws.String(planID + "-selection"),
+				SelectionName: aws.String(planID + "-selection"),
+			},
+		},
+	}, nil
+}
+
+func (m *MockedBackupClient) GetBackupSelection(ctx context.Context, input *backup.GetBackupSelectionInput, options ...func(*backup.Options)) (*backup.GetBackupSelectionOutput, error) {
+	switch aws.ToString(input.BackupPlanId) {
+	case "plan-daily":
+		return &backup.GetBackupSelectionOutput{
+			BackupPlanId: input.BackupPlanId,
+			SelectionId:  input.SelectionId,
+			BackupSelection: &backupTypes.BackupSelection{
+				SelectionName: aws.String("databases"),
+				IamRoleArn:    aws.String("arn:aws:iam::123456789012:role/service-role/AWSBackupDefaultServiceRole"),
+				Resources: []string{
+					"arn:aws:rds:*:*:cluster:*",
+					"arn:aws:elasticfilesystem:*:*:file-system/fs-12345678",
+				},
+				NotResources: []string{
+					"arn:aws:rds:*:*:cluster:db4",
+				},
+			},
+		}, nil
+	default:
+		return &backup.GetBackupSelectionOutput{
+			BackupPlanId: input.BackupPlanId,
+			SelectionId:  input.SelectionId,
+			BackupSelection: &backupTypes.BackupSelection{
+				SelectionName: aws.String("tagged"),
+				IamRoleArn:    aws.String("arn:aws:iam::123456789012:role/service-role/AWSBackupDefaultServiceRole"),
+				ListOfTags: []backupTypes.Condition{
+					{
+						ConditionType:  backupTypes.ConditionTypeStringequals,
+						ConditionKey:   aws.String("backup"),
+						ConditionValue: aws.String("weekly"),
+					},
+				},
+			},
+		}, nil
+	}
+}
@@ -0,0 +1,166 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type BatchClientInterface interface {
+	ListSchedulingPolicies(ctx context.Context, params *batch.ListSchedulingPoliciesInput, optFns ...func(*batch.Options)) (*batch.ListSchedulingPoliciesOutput, error)
+	DescribeSchedulingPolicies(ctx context.Context, params *batch.DescribeSchedulingPoliciesInput, optFns ...func(*batch.Options)) (*batch.DescribeSchedulingPoliciesOutput, error)
+	DescribeJobQueues(ctx context.Context, params *batch.DescribeJobQueuesInput, optFns ...func(*batch.Options)) (*batch.DescribeJobQueuesOutput, error)
+	DescribeComputeEnvironments(ctx context.Context, params *batch.DescribeComputeEnvironmentsInput, optFns ...func(*batch.Options)) (*batch.DescribeComputeEnvironmentsOutput, error)
+}
+
+func init() {
+	gob.Register([]batchTypes.SchedulingPolicyDetail{})
+	gob.Register([]batchTypes.JobQueueDetail{})
+	gob.Register([]batchTypes.ComputeEnvironmentDetail{})
+}
+
+// CachedBatchDescribeSchedulingPolicies lists the scheduling policies in a region and then describes them. DescribeSchedulingPolicies
+// only accepts up to 100 ARNs per call, so the ARNs are described in chunks.
+func CachedBatchDescribeSchedulingPolicies(client BatchClientInterface, accountID string, region string) ([]batchTypes.SchedulingPolicyDetail, error) {
+	var PaginationControl *string
+	var arns []string
+	var policies []batchTypes.SchedulingPolicyDetail
+	cacheKey := fmt.Sprintf("%s-batch-DescribeSchedulingPolicies-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]batchTypes.SchedulingPolicyDetail), nil
+	}
+
+	for {
+		ListSchedulingPolicies, err := client.ListSchedulingPolicies(
+			context.TODO(),
+			&batch.ListSchedulingPoliciesInput{
+				NextToken: PaginationControl,
+			},
+			func(o *batch.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return policies, err
+		}
+
+		for _, policy := range ListSchedulingPolicies.SchedulingPolicies {
+			if policy.Arn != nil {
+				arns = append(arns, *policy.Arn)
+			}
+		}
+
+		// Pagination control. After the last page of output, the for loop exits.
+		if ListSchedulingPolicies.NextToken != nil {
+			PaginationControl = ListSchedulingPolicies.NextToken
+		} else {
+			PaginationControl = nil
+			break
+		}
+	}
+
+	for start := 0; start < len(arns); start += 100 {
+		end := start + 100
+		if end > len(arns) {
+			end = len(arns)
+		}
+		DescribeSchedulingPolicies, err := client.DescribeSchedulingPolicies(
+			context.TODO(),
+			&batch.DescribeSchedulingPoliciesInput{
+				Arns: arns[start:end],
+			},
+			func(o *batch.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return policies, err
+		}
+		policies = append(policies, DescribeSchedulingPolicies.SchedulingPolicies...)
+	}
+
+	internal.Cache.Set(cacheKey, policies, cache.DefaultExpiration)
+	return policies, nil
+}
+
+func CachedBatchDescribeJobQueues(client BatchClientInterface, accountID string, region string) ([]batchTypes.JobQueueDetail, error) {
+	var PaginationControl *string
+	var jobQueues []batchTypes.JobQueueDetail
+	cacheKey := fmt.Sprintf("%s-batch-DescribeJobQueues-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]batchTypes.JobQueueDetail), nil
+	}
+
+	for {
+		DescribeJobQueues, err := client.DescribeJobQueues(
+			context.TODO(),
+			&batch.DescribeJobQueuesInput{
+				NextToken: PaginationControl,
+			},
+			func(o *batch.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return jobQueues, err
+		}
+
+		jobQueues = append(jobQueues, DescribeJobQueues.JobQueues...)
+
+		// Pagination control. After the last page of output, the for loop exits.
+		if DescribeJobQueues.NextToken != nil {
+			PaginationControl = DescribeJobQueues.NextToken
+		} else {
+			PaginationControl = nil
+			break
+		}
+	}
+
+	internal.Cache.Set(cacheKey, jobQueues, cache.DefaultExpiration)
+	return jobQueues, nil
+}
+
+func CachedBatchDescribeComputeEnvironments(client BatchClientInterface, accountID string, region string) ([]batchTypes.ComputeEnvironmentDetail, error) {
+	var PaginationControl *string
+	var computeEnvironments []batchTypes.ComputeEnvironmentDetail
+	cacheKey := fmt.Sprintf("%s-batch-DescribeComputeEnvironments-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]batchTypes.ComputeEnvironmentDetail), nil
+	}
+
+	for {
+		DescribeComputeEnvironments, err := client.DescribeComputeEnvironments(
+			context.TODO(),
+			&batch.DescribeComputeEnvironmentsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *batch.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return computeEnvironments, err
+		}
+
+		computeEnvironments = append(computeEnvironments, DescribeComputeEnvironments.ComputeEnvironments...)
+
+		// Pagination control. After the last page of output, the for loop exits.
+		if DescribeComputeEnvironments.NextToken != nil {
+			PaginationControl = DescribeComputeEnvironments.NextToken
+		} else {
+			PaginationControl = nil
+			break
+		}
+	}
+
+	internal.Cache.Set(cacheKey, computeEnvironments, cache.DefaultExpiration)
+	return computeEnvironments, nil
+}
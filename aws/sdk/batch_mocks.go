@@ -0,0 +1,107 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
+)
+
+type MockedBatchClient struct {
+}
+
+func (m *MockedBatchClient) ListSchedulingPolicies(ctx context.Context, input *batch.ListSchedulingPoliciesInput, options ...func(*batch.Options)) (*batch.ListSchedulingPoliciesOutput, error) {
+	return &batch.ListSchedulingPoliciesOutput{
+		SchedulingPolicies: []batchTypes.SchedulingPolicyListingDetail{
+			{
+				Arn: aws.String("arn:aws:batch:us-east-1:123456789012:scheduling-policy/fairshare1"),
+			},
+			{
+				Arn: aws.String("arn:aws:batch:us-east-1:123456789012:scheduling-policy/unused"),
+			},
+		},
+	}, nil
+}
+
+func (m *MockedBatchClient) DescribeSchedulingPolicies(ctx context.Context, input *batch.DescribeSchedulingPoliciesInput, options ...func(*batch.Options)) (*batch.DescribeSchedulingPoliciesOutput, error) {
+	return &batch.DescribeSchedulingPoliciesOutput{
+		SchedulingPolicies: []batchTypes.SchedulingPolicyDetail{
+			{
+				Arn:  aws.String("arn:aws:batch:us-east-1:123456789012:scheduling-policy/fairshare1"),
+				Name: aws.String("fairshare1"),
+				FairsharePolicy: &batchTypes.FairsharePolicy{
+					ShareDistribution: []batchTypes.ShareAttributes{
+						{
+							ShareIdentifier: aws.String("teamA"),
+							WeightFactor:    aws.Float32(0.5),
+						},
+						{
+							ShareIdentifier: aws.String("teamB"),
+							WeightFactor:    aws.Float32(1),
+						},
+					},
+				},
+			},
+			{
+				Arn:  aws.String("arn:aws:batch:us-east-1:123456789012:scheduling-policy/unused"),
+				Name: aws.String("unused"),
+			},
+		},
+	}, nil
+}
+
+func (m *MockedBatchClient) DescribeJobQueues(ctx context.Context, input *batch.DescribeJobQueuesInput, options ...func(*batch.Options)) (*batch.DescribeJobQueuesOutput, error) {
+	return &batch.DescribeJobQueuesOutput{
+		JobQueues: []batchTypes.JobQueueDetail{
+			{
+				JobQueueName:        aws.String("queue1"),
+				JobQueueArn:         aws.String("arn:aws:batch:us-east-1:123456789012:job-queue/queue1"),
+				State:               batchTypes.JQStateEnabled,
+				SchedulingPolicyArn: aws.String("arn:aws:batch:us-east-1:123456789012:scheduling-policy/fairshare1"),
+				ComputeEnvironmentOrder: []batchTypes.ComputeEnvironmentOrder{
+					{
+						ComputeEnvironment: aws.String("arn:aws:batch:us-east-1:123456789012:compute-environment/ce1"),
+						Order:              aws.Int32(1),
+					},
+				},
+			},
+			{
+				JobQueueName: aws.String("queue2"),
+				JobQueueArn:  aws.String("arn:aws:batch:us-east-1:123456789012:job-queue/queue2"),
+				State:        batchTypes.JQStateEnabled,
+				ComputeEnvironmentOrder: []batchTypes.ComputeEnvironmentOrder{
+					{
+						ComputeEnvironment: aws.String("arn:aws:batch:us-east-1:123456789012:compute-environment/ce2"),
+						Order:              aws.Int32(1),
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (m *MockedBatchClient) DescribeComputeEnvironments(ctx context.Context, input *batch.DescribeComputeEnvironmentsInput, options ...func(*batch.Options)) (*batch.DescribeComputeEnvironmentsOutput, error) {
+	return &batch.DescribeComputeEnvironmentsOutput{
+		ComputeEnvironments: []batchTypes.ComputeEnvironmentDetail{
+			{
+				ComputeEnvironmentName: aws.String("ce1"),
+				ComputeEnvironmentArn:  aws.String("arn:aws:batch:us-east-1:123456789012:compute-environment/ce1"),
+				Type:                   batchTypes.CETypeManaged,
+				ServiceRole:            aws.String("arn:aws:iam::123456789012:role/BatchServiceRole"),
+				ComputeResources: &batchTypes.ComputeResource{
+					Type: batchTypes.CRTypeEc2,
+				},
+			},
+			{
+				ComputeEnvironmentName: aws.String("ce2"),
+				ComputeEnvironmentArn:  aws.String("arn:aws:batch:us-east-1:123456789012:compute-environment/ce2"),
+				Type:                   batchTypes.CETypeManaged,
+				ServiceRole:            aws.String("arn:aws:iam::123456789012:role/aws-service-role/batch.amazonaws.com/AWSServiceRoleForBatch"),
+				ComputeResources: &batchTypes.ComputeResource{
+					Type: batchTypes.CRTypeFargate,
+				},
+			},
+		},
+	}, nil
+}
@@ -18,7 +18,17 @@ type CloudFormationClientInterface interface {
 	ListStacks(context.Context, *cloudformation.ListStacksInput, ...func(*cloudformation.Options)) (*cloudformation.ListStacksOutput, error)
 }
 
+// CloudFormationStackSetsClientInterface lists stack sets and where they deploy their stacks
+type CloudFormationStackSetsClientInterface interface {
+	ListStackSets(context.Context, *cloudformation.ListStackSetsInput, ...func(*cloudformation.Options)) (*cloudformation.ListStackSetsOutput, error)
+	DescribeStackSet(context.Context, *cloudformation.DescribeStackSetInput, ...func(*cloudformation.Options)) (*cloudformation.DescribeStackSetOutput, error)
+	ListStackSetInstances(context.Context, *cloudformation.ListStackSetInstancesInput, ...func(*cloudformation.Options)) (*cloudformation.ListStackSetInstancesOutput, error)
+}
+
 func init() {
+	gob.Register([]cloudFormationTypes.StackSetSummary{})
+	gob.Register(cloudFormationTypes.StackSet{})
+	gob.Register([]cloudFormationTypes.StackSetInstanceSummary{})
 	gob.Register([]cloudFormationTypes.Stack{})
 	gob.Register([]cloudFormationTypes.StackSummary{})
 }
@@ -117,3 +127,101 @@ func CachedCloudFormationListStacks(client CloudFormationClientInterface, accoun
 	internal.Cache.Set(cacheKey, stacks, cache.DefaultExpiration)
 	return stacks, nil
 }
+
+func CachedCloudFormationListStackSets(client CloudFormationStackSetsClientInterface, accountID string, region string) ([]cloudFormationTypes.StackSetSummary, error) {
+	var PaginationControl *string
+	var stackSets []cloudFormationTypes.StackSetSummary
+	cacheKey := fmt.Sprintf("%s-cloudformation-ListStackSets-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]cloudFormationTypes.StackSetSummary), nil
+	}
+	for {
+		ListStackSets, err := client.ListStackSets(
+			context.TODO(),
+			&cloudformation.ListStackSetsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *cloudformation.Options) {
+				o.Region = region
+			},
+		)
+
+		if err != nil {
+			return stackSets, err
+		}
+
+		stackSets = append(stackSets, ListStackSets.Summaries...)
+		//pagination
+		if ListStackSets.NextToken == nil {
+			break
+		}
+		PaginationControl = ListStackSets.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, stackSets, cache.DefaultExpiration)
+	return stackSets, nil
+}
+
+func CachedCloudFormationDescribeStackSet(client CloudFormationStackSetsClientInterface, accountID string, region string, stackSetName string) (cloudFormationTypes.StackSet, error) {
+	var stackSet cloudFormationTypes.StackSet
+	cacheKey := fmt.Sprintf("%s-cloudformation-DescribeStackSet-%s-%s", accountID, region, stackSetName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(cloudFormationTypes.StackSet), nil
+	}
+
+	DescribeStackSet, err := client.DescribeStackSet(
+		context.TODO(),
+		&cloudformation.DescribeStackSetInput{
+			StackSetName: &stackSetName,
+		},
+		func(o *cloudformation.Options) {
+			o.Region = region
+		},
+	)
+
+	if err != nil {
+		return stackSet, err
+	}
+	stackSet = *DescribeStackSet.StackSet
+
+	internal.Cache.Set(cacheKey, stackSet, cache.DefaultExpiration)
+	return stackSet, nil
+}
+
+func CachedCloudFormationListStackSetInstances(client CloudFormationStackSetsClientInterface, accountID string, region string, stackSetName string) ([]cloudFormationTypes.StackSetInstanceSummary, error) {
+	var PaginationControl *string
+	var instances []cloudFormationTypes.StackSetInstanceSummary
+	cacheKey := fmt.Sprintf("%s-cloudformation-ListStackSetInstances-%s-%s", accountID, region, stackSetName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]cloudFormationTypes.StackSetInstanceSummary), nil
+	}
+	for {
+		ListStackSetInstances, err := client.ListStackSetInstances(
+			context.TODO(),
+			&cloudformation.ListStackSetInstancesInput{
+				StackSetName: &stackSetName,
+				NextToken:    PaginationControl,
+			},
+			func(o *cloudformation.Options) {
+				o.Region = region
+			},
+		)
+
+		if err != nil {
+			return instances, err
+		}
+
+		instances = append(instances, ListStackSetInstances.Summaries...)
+		//pagination
+		if ListStackSetInstances.NextToken == nil {
+			break
+		}
+		PaginationControl = ListStackSetInstances.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, instances, cache.DefaultExpiration)
+	return instances, nil
+}
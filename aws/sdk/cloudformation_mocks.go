@@ -6,6 +6,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 )
@@ -71,3 +72,123 @@ func (m *MockedCloudformationClient) GetTemplate(ctx context.Context, params *cl
 func (m *MockedCloudformationClient) ListStacks(ctx context.Context, params *cloudformation.ListStacksInput, optFns ...func(*cloudformation.Options)) (*cloudformation.ListStacksOutput, error) {
 	return &cloudformation.ListStacksOutput{}, nil
 }
+
+// The org-baseline stack set deploys a role into every account of an OU, logging-buckets only deploys buckets
+const mockedStackSetBaselineTemplate = `AWSTemplateFormatVersion: "2010-09-09"
+Description: Organization baseline
+Resources:
+  # Lets the security team in
+  SecurityAuditRole:
+    Type: AWS::IAM::Role
+    Properties:
+      RoleName: security-audit
+      AssumeRolePolicyDocument:
+        Statement:
+          - Effect: Allow
+            Principal:
+              AWS: !Sub arn:aws:iam::${SecurityAccount}:root
+            Action: sts:AssumeRole
+  AuditPolicy:
+    Type: AWS::IAM::RolePolicy
+    Properties:
+      RoleName: !Ref SecurityAuditRole
+Parameters:
+  SecurityAccount:
+    Type: String
+`
+
+const mockedStackSetBucketsTemplate = `{
+  "Resources": {
+    "LogBucket": {
+      "Type": "AWS::S3::Bucket"
+    },
+    "LogBucketPolicy": {
+      "Type": "AWS::S3::BucketPolicy",
+      "Properties": {"Bucket": {"Ref": "LogBucket"}}
+    }
+  }
+}`
+
+type MockedCloudFormationStackSetsClient struct {
+}
+
+func (m *MockedCloudFormationStackSetsClient) region(optFns []func(*cloudformation.Options)) string {
+	o := cloudformation.Options{}
+	for _, option := range optFns {
+		option(&o)
+	}
+	return o.Region
+}
+
+func (m *MockedCloudFormationStackSetsClient) ListStackSets(ctx context.Context, params *cloudformation.ListStackSetsInput, optFns ...func(*cloudformation.Options)) (*cloudformation.ListStackSetsOutput, error) {
+	if m.region(optFns) != "us-east-1" {
+		return &cloudformation.ListStackSetsOutput{}, nil
+	}
+	return &cloudformation.ListStackSetsOutput{
+		Summaries: []types.StackSetSummary{
+			{
+				StackSetName:    aws.String("org-baseline"),
+				StackSetId:      aws.String("org-baseline:11111111-2222-3333-4444-555555555555"),
+				PermissionModel: types.PermissionModelsServiceManaged,
+				Status:          types.StackSetStatusActive,
+			},
+			{
+				StackSetName:    aws.String("logging-buckets"),
+				StackSetId:      aws.String("logging-buckets:66666666-7777-8888-9999-000000000000"),
+				PermissionModel: types.PermissionModelsSelfManaged,
+				Status:          types.StackSetStatusActive,
+			},
+		},
+	}, nil
+}
+
+func (m *MockedCloudFormationStackSetsClient) DescribeStackSet(ctx context.Context, params *cloudformation.DescribeStackSetInput, optFns ...func(*cloudformation.Options)) (*cloudformation.DescribeStackSetOutput, error) {
+	switch aws.ToString(params.StackSetName) {
+	case "org-baseline":
+		return &cloudformation.DescribeStackSetOutput{
+			StackSet: &types.StackSet{
+				StackSetName:          aws.String("org-baseline"),
+				StackSetARN:           aws.String("arn:aws:cloudformation:us-east-1:123456789012:stackset/org-baseline:11111111-2222-3333-4444-555555555555"),
+				PermissionModel:       types.PermissionModelsServiceManaged,
+				Status:                types.StackSetStatusActive,
+				TemplateBody:          aws.String(mockedStackSetBaselineTemplate),
+				AutoDeployment:        &types.AutoDeployment{Enabled: aws.Bool(true)},
+				Capabilities:          []types.Capability{types.CapabilityCapabilityNamedIam},
+				OrganizationalUnitIds: []string{"ou-abcd-12345678"},
+			},
+		}, nil
+	case "logging-buckets":
+		return &cloudformation.DescribeStackSetOutput{
+			StackSet: &types.StackSet{
+				StackSetName:          aws.String("logging-buckets"),
+				StackSetARN:           aws.String("arn:aws:cloudformation:us-east-1:123456789012:stackset/logging-buckets:66666666-7777-8888-9999-000000000000"),
+				PermissionModel:       types.PermissionModelsSelfManaged,
+				Status:                types.StackSetStatusActive,
+				TemplateBody:          aws.String(mockedStackSetBucketsTemplate),
+				AdministrationRoleARN: aws.String("arn:aws:iam::123456789012:role/AWSCloudFormationStackSetAdministrationRole"),
+				ExecutionRoleName:     aws.String("AWSCloudFormationStackSetExecutionRole"),
+			},
+		}, nil
+	}
+	return nil, &types.StackSetNotFoundException{Message: aws.String("StackSet not found")}
+}
+
+func (m *MockedCloudFormationStackSetsClient) ListStackSetInstances(ctx context.Context, params *cloudformation.ListStackSetInstancesInput, optFns ...func(*cloudformation.Options)) (*cloudformation.ListStackSetInstancesOutput, error) {
+	switch aws.ToString(params.StackSetName) {
+	case "org-baseline":
+		return &cloudformation.ListStackSetInstancesOutput{
+			Summaries: []types.StackSetInstanceSummary{
+				{Account: aws.String("111111111111"), Region: aws.String("us-east-1"), OrganizationalUnitId: aws.String("ou-abcd-12345678"), Status: types.StackInstanceStatusCurrent},
+				{Account: aws.String("222222222222"), Region: aws.String("us-east-1"), OrganizationalUnitId: aws.String("ou-abcd-12345678"), Status: types.StackInstanceStatusCurrent},
+				{Account: aws.String("222222222222"), Region: aws.String("eu-west-1"), OrganizationalUnitId: aws.String("ou-abcd-12345678"), Status: types.StackInstanceStatusOutdated},
+			},
+		}, nil
+	case "logging-buckets":
+		return &cloudformation.ListStackSetInstancesOutput{
+			Summaries: []types.StackSetInstanceSummary{
+				{Account: aws.String("123456789012"), Region: aws.String("us-east-1"), Status: types.StackInstanceStatusCurrent},
+			},
+		}, nil
+	}
+	return nil, &types.StackSetNotFoundException{Message: aws.String("StackSet not found")}
+}
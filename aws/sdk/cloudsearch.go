@@ -0,0 +1,108 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudsearch"
+	cloudsearchTypes "github.com/aws/aws-sdk-go-v2/service/cloudsearch/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type CloudSearchClientInterface interface {
+	DescribeDomains(context.Context, *cloudsearch.DescribeDomainsInput, ...func(*cloudsearch.Options)) (*cloudsearch.DescribeDomainsOutput, error)
+	DescribeDomainEndpointOptions(context.Context, *cloudsearch.DescribeDomainEndpointOptionsInput, ...func(*cloudsearch.Options)) (*cloudsearch.DescribeDomainEndpointOptionsOutput, error)
+	DescribeServiceAccessPolicies(context.Context, *cloudsearch.DescribeServiceAccessPoliciesInput, ...func(*cloudsearch.Options)) (*cloudsearch.DescribeServiceAccessPoliciesOutput, error)
+}
+
+func init() {
+	gob.Register([]cloudsearchTypes.DomainStatus{})
+	gob.Register(cloudsearchTypes.DomainEndpointOptions{})
+}
+
+// CachedCloudSearchDescribeDomains lists every domain of the region. DescribeDomains has no pagination, without domain
+// names it returns all of them.
+func CachedCloudSearchDescribeDomains(client CloudSearchClientInterface, accountID string, region string) ([]cloudsearchTypes.DomainStatus, error) {
+	var domains []cloudsearchTypes.DomainStatus
+	cacheKey := fmt.Sprintf("%s-cloudsearch-DescribeDomains-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]cloudsearchTypes.DomainStatus), nil
+	}
+
+	DescribeDomains, err := client.DescribeDomains(
+		context.TODO(),
+		&cloudsearch.DescribeDomainsInput{},
+		func(o *cloudsearch.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return domains, err
+	}
+	domains = append(domains, DescribeDomains.DomainStatusList...)
+
+	internal.Cache.Set(cacheKey, domains, cache.DefaultExpiration)
+	return domains, nil
+}
+
+func CachedCloudSearchDescribeDomainEndpointOptions(client CloudSearchClientInterface, accountID string, region string, domainName string) (cloudsearchTypes.DomainEndpointOptions, error) {
+	var options cloudsearchTypes.DomainEndpointOptions
+	cacheKey := fmt.Sprintf("%s-cloudsearch-DescribeDomainEndpointOptions-%s-%s", accountID, region, domainName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(cloudsearchTypes.DomainEndpointOptions), nil
+	}
+
+	DescribeDomainEndpointOptions, err := client.DescribeDomainEndpointOptions(
+		context.TODO(),
+		&cloudsearch.DescribeDomainEndpointOptionsInput{
+			DomainName: aws.String(domainName),
+		},
+		func(o *cloudsearch.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return options, err
+	}
+	if DescribeDomainEndpointOptions.DomainEndpointOptions != nil && DescribeDomainEndpointOptions.DomainEndpointOptions.Options != nil {
+		options = *DescribeDomainEndpointOptions.DomainEndpointOptions.Options
+	}
+
+	internal.Cache.Set(cacheKey, options, cache.DefaultExpiration)
+	return options, nil
+}
+
+// CachedCloudSearchDescribeServiceAccessPolicies returns the access policy document of a domain, or an empty string
+// if it has none
+func CachedCloudSearchDescribeServiceAccessPolicies(client CloudSearchClientInterface, accountID string, region string, domainName string) (string, error) {
+	var accessPolicy string
+	cacheKey := fmt.Sprintf("%s-cloudsearch-DescribeServiceAccessPolicies-%s-%s", accountID, region, domainName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(string), nil
+	}
+
+	DescribeServiceAccessPolicies, err := client.DescribeServiceAccessPolicies(
+		context.TODO(),
+		&cloudsearch.DescribeServiceAccessPoliciesInput{
+			DomainName: aws.String(domainName),
+		},
+		func(o *cloudsearch.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return accessPolicy, err
+	}
+	if DescribeServiceAccessPolicies.AccessPolicies != nil {
+		accessPolicy = aws.ToString(DescribeServiceAccessPolicies.AccessPolicies.Options)
+	}
+
+	internal.Cache.Set(cacheKey, accessPolicy, cache.DefaultExpiration)
+	return accessPolicy, nil
+}
@@ -0,0 +1,83 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudsearch"
+	cloudsearchTypes "github.com/aws/aws-sdk-go-v2/service/cloudsearch/types"
+)
+
+type MockedCloudSearchClient struct {
+}
+
+// products lets anyone search and upload documents over HTTP, logs only lets a network range in and enforces HTTPS,
+// internal has no access policy at all
+var mockedCloudSearchDomains = []string{"products", "logs", "internal"}
+
+var mockedCloudSearchPolicies = map[string]string{
+	"products": `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Principal": "*",
+				"Action": ["cloudsearch:search", "cloudsearch:suggest"]
+			},
+			{
+				"Effect": "Allow",
+				"Principal": {"AWS": "*"},
+				"Action": "cloudsearch:document"
+			}
+		]
+	}`,
+	"logs": `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Principal": {"AWS": ["*"]},
+				"Action": "cloudsearch:*",
+				"Condition": {"IpAddress": {"aws:SourceIp": ["10.0.0.0/8"]}}
+			}
+		]
+	}`,
+}
+
+func (m *MockedCloudSearchClient) DescribeDomains(ctx context.Context, input *cloudsearch.DescribeDomainsInput, options ...func(*cloudsearch.Options)) (*cloudsearch.DescribeDomainsOutput, error) {
+	var domains []cloudsearchTypes.DomainStatus
+	for _, name := range mockedCloudSearchDomains {
+		domains = append(domains, cloudsearchTypes.DomainStatus{
+			ARN:                    aws.String(fmt.Sprintf("arn:aws:cloudsearch:us-east-1:123456789012:domain/%s", name)),
+			DomainId:               aws.String(fmt.Sprintf("123456789012/%s", name)),
+			DomainName:             aws.String(name),
+			Created:                aws.Bool(true),
+			Deleted:                aws.Bool(false),
+			RequiresIndexDocuments: aws.Bool(false),
+			DocService:             &cloudsearchTypes.ServiceEndpoint{Endpoint: aws.String(fmt.Sprintf("doc-%s-abcdefghijklmnop.us-east-1.cloudsearch.amazonaws.com", name))},
+			SearchService:          &cloudsearchTypes.ServiceEndpoint{Endpoint: aws.String(fmt.Sprintf("search-%s-abcdefghijklmnop.us-east-1.cloudsearch.amazonaws.com", name))},
+		})
+	}
+	return &cloudsearch.DescribeDomainsOutput{DomainStatusList: domains}, nil
+}
+
+func (m *MockedCloudSearchClient) DescribeDomainEndpointOptions(ctx context.Context, input *cloudsearch.DescribeDomainEndpointOptionsInput, options ...func(*cloudsearch.Options)) (*cloudsearch.DescribeDomainEndpointOptionsOutput, error) {
+	enforceHTTPS := aws.ToString(input.DomainName) != "products"
+	return &cloudsearch.DescribeDomainEndpointOptionsOutput{
+		DomainEndpointOptions: &cloudsearchTypes.DomainEndpointOptionsStatus{
+			Options: &cloudsearchTypes.DomainEndpointOptions{
+				EnforceHTTPS:      aws.Bool(enforceHTTPS),
+				TLSSecurityPolicy: cloudsearchTypes.TLSSecurityPolicyPolicyMinTls12201907,
+			},
+		},
+	}, nil
+}
+
+func (m *MockedCloudSearchClient) DescribeServiceAccessPolicies(ctx context.Context, input *cloudsearch.DescribeServiceAccessPoliciesInput, options ...func(*cloudsearch.Options)) (*cloudsearch.DescribeServiceAccessPoliciesOutput, error) {
+	return &cloudsearch.DescribeServiceAccessPoliciesOutput{
+		AccessPolicies: &cloudsearchTypes.AccessPoliciesStatus{
+			Options: aws.String(mockedCloudSearchPolicies[aws.ToString(input.DomainName)]),
+		},
+	}, nil
+}
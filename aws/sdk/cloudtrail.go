@@ -0,0 +1,104 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	cloudtrailTypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type CloudTrailClientInterface interface {
+	DescribeTrails(ctx context.Context, params *cloudtrail.DescribeTrailsInput, optFns ...func(*cloudtrail.Options)) (*cloudtrail.DescribeTrailsOutput, error)
+	GetTrailStatus(ctx context.Context, params *cloudtrail.GetTrailStatusInput, optFns ...func(*cloudtrail.Options)) (*cloudtrail.GetTrailStatusOutput, error)
+	LookupEvents(ctx context.Context, params *cloudtrail.LookupEventsInput, optFns ...func(*cloudtrail.Options)) (*cloudtrail.LookupEventsOutput, error)
+}
+
+func init() {
+	gob.Register([]cloudtrailTypes.Trail{})
+	gob.Register(CloudTrailStatus{})
+}
+
+// CachedCloudTrailDescribeTrails returns the trails that apply to a region, including multi-region and
+// organization trails that were created in another region
+func CachedCloudTrailDescribeTrails(client CloudTrailClientInterface, accountID string, region string) ([]cloudtrailTypes.Trail, error) {
+	cacheKey := fmt.Sprintf("%s-cloudtrail-DescribeTrails-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]cloudtrailTypes.Trail), nil
+	}
+
+	DescribeTrails, err := client.DescribeTrails(
+		context.TODO(),
+		&cloudtrail.DescribeTrailsInput{
+			IncludeShadowTrails: aws.Bool(true),
+		},
+		func(o *cloudtrail.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	internal.Cache.Set(cacheKey, DescribeTrails.TrailList, cache.DefaultExpiration)
+	return DescribeTrails.TrailList, nil
+}
+
+// CloudTrailStatus is the part of GetTrailStatus we cache. The delivery attempt times are strings in the API.
+type CloudTrailStatus struct {
+	IsLogging                      bool
+	StartLoggingTime               *time.Time
+	StopLoggingTime                *time.Time
+	LatestDeliveryTime             *time.Time
+	LatestDeliveryError            string
+	LatestDeliveryAttemptTime      string
+	LatestDeliveryAttemptSucceeded string
+}
+
+// CachedCloudTrailGetTrailIsLogging returns whether a trail is currently logging. The trail ARN has to be
+// queried in the trail's home region.
+func CachedCloudTrailGetTrailIsLogging(client CloudTrailClientInterface, accountID string, homeRegion string, trailARN string) (bool, error) {
+	status, err := CachedCloudTrailGetTrailStatus(client, accountID, homeRegion, trailARN)
+	return status.IsLogging, err
+}
+
+// CachedCloudTrailGetTrailStatus returns whether a trail is logging and how the delivery of its logs went. The trail
+// ARN has to be queried in the trail's home region.
+func CachedCloudTrailGetTrailStatus(client CloudTrailClientInterface, accountID string, homeRegion string, trailARN string) (CloudTrailStatus, error) {
+	cacheKey := fmt.Sprintf("%s-cloudtrail-GetTrailStatus-%s-%s", accountID, homeRegion, trailARN)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(CloudTrailStatus), nil
+	}
+
+	GetTrailStatus, err := client.GetTrailStatus(
+		context.TODO(),
+		&cloudtrail.GetTrailStatusInput{
+			Name: aws.String(trailARN),
+		},
+		func(o *cloudtrail.Options) {
+			o.Region = homeRegion
+		},
+	)
+	if err != nil {
+		return CloudTrailStatus{}, err
+	}
+
+	status := CloudTrailStatus{
+		IsLogging:                      aws.ToBool(GetTrailStatus.IsLogging),
+		StartLoggingTime:               GetTrailStatus.StartLoggingTime,
+		StopLoggingTime:                GetTrailStatus.StopLoggingTime,
+		LatestDeliveryTime:             GetTrailStatus.LatestDeliveryTime,
+		LatestDeliveryError:            aws.ToString(GetTrailStatus.LatestDeliveryError),
+		LatestDeliveryAttemptTime:      aws.ToString(GetTrailStatus.LatestDeliveryAttemptTime),
+		LatestDeliveryAttemptSucceeded: aws.ToString(GetTrailStatus.LatestDeliveryAttemptSucceeded),
+	}
+	internal.Cache.Set(cacheKey, status, cache.DefaultExpiration)
+	return status, nil
+}
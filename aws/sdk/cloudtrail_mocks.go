@@ -0,0 +1,255 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	cloudtrailTypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+)
+
+type MockedCloudTrailClient struct {
+}
+
+// A logging single-region trail in us-east-1 and a stopped trail in us-west-2
+func (m *MockedCloudTrailClient) DescribeTrails(ctx context.Context, input *cloudtrail.DescribeTrailsInput, options ...func(*cloudtrail.Options)) (*cloudtrail.DescribeTrailsOutput, error) {
+	o := cloudtrail.Options{}
+	for _, option := range options {
+		option(&o)
+	}
+	switch o.Region {
+	case "us-east-1":
+		return &cloudtrail.DescribeTrailsOutput{
+			TrailList: []cloudtrailTypes.Trail{
+				{
+					Name:               aws.String("management-events"),
+					TrailARN:           aws.String("arn:aws:cloudtrail:us-east-1:123456789012:trail/management-events"),
+					HomeRegion:         aws.String("us-east-1"),
+					IsMultiRegionTrail: aws.Bool(false),
+					S3BucketName:       aws.String("cloudtrail-logs-123456789012"),
+					KmsKeyId:           aws.String("arn:aws:kms:us-east-1:123456789012:key/11111111-2222-3333-4444-555555555555"),
+				},
+			},
+		}, nil
+	case "us-west-2":
+		return &cloudtrail.DescribeTrailsOutput{
+			TrailList: []cloudtrailTypes.Trail{
+				{
+					Name:               aws.String("stopped-trail"),
+					TrailARN:           aws.String("arn:aws:cloudtrail:us-west-2:123456789012:trail/stopped-trail"),
+					HomeRegion:         aws.String("us-west-2"),
+					IsMultiRegionTrail: aws.Bool(false),
+					S3BucketName:       aws.String("old-trail-bucket"),
+				},
+			},
+		}, nil
+	}
+	return &cloudtrail.DescribeTrailsOutput{}, nil
+}
+
+// management-events is logging but has been failing to deliver its logs for the last three days, stopped-trail was
+// stopped five days ago after its last successful delivery
+func (m *MockedCloudTrailClient) GetTrailStatus(ctx context.Context, input *cloudtrail.GetTrailStatusInput, options ...func(*cloudtrail.Options)) (*cloudtrail.GetTrailStatusOutput, error) {
+	now := time.Now().UTC().Truncate(time.Hour)
+	if aws.ToString(input.Name) == "arn:aws:cloudtrail:us-east-1:123456789012:trail/management-events" {
+		return &cloudtrail.GetTrailStatusOutput{
+			IsLogging:                      aws.Bool(true),
+			LatestDeliveryError:            aws.String("AccessDenied"),
+			LatestDeliveryAttemptTime:      aws.String(now.Add(-time.Hour).Format(time.RFC3339)),
+			LatestDeliveryAttemptSucceeded: aws.String(now.Add(-72 * time.Hour).Format(time.RFC3339)),
+			LatestDeliveryTime:             aws.Time(now.Add(-72 * time.Hour)),
+		}, nil
+	}
+	lastDelivery := now.Add(-5 * 24 * time.Hour)
+	return &cloudtrail.GetTrailStatusOutput{
+		IsLogging:                      aws.Bool(false),
+		StopLoggingTime:                aws.Time(lastDelivery),
+		LatestDeliveryAttemptTime:      aws.String(lastDelivery.Format(time.RFC3339)),
+		LatestDeliveryAttemptSucceeded: aws.String(lastDelivery.Format(time.RFC3339)),
+		LatestDeliveryTime:             aws.Time(lastDelivery),
+	}, nil
+}
+
+// mockedGetSecretValueAccess describes a principal that read a secret count times, every interval, starting daysAgo
+// days ago
+type mockedGetSecretValueAccess struct {
+	identityType string
+	principalArn string
+	secretID     string
+	daysAgo      int
+	count        int
+	interval     time.Duration
+}
+
+// GetSecretValue events in us-east-1: a handful of roles that read their secrets every other day for the last month,
+// a user that started reading prod/db-password every half hour two days ago and a function that read prod/api-key
+// for the first time yesterday. Lookups by the iam.amazonaws.com event source return the IAM events of
+// mockedIAMEvents instead, lookups of StopLogging events the events of mockedStopLoggingEvents.
+func (m *MockedCloudTrailClient) LookupEvents(ctx context.Context, input *cloudtrail.LookupEventsInput, options ...func(*cloudtrail.Options)) (*cloudtrail.LookupEventsOutput, error) {
+	o := cloudtrail.Options{}
+	for _, option := range options {
+		option(&o)
+	}
+	for _, attribute := range input.LookupAttributes {
+		if attribute.AttributeKey == cloudtrailTypes.LookupAttributeKeyEventName && aws.ToString(attribute.AttributeValue) == "StopLogging" {
+			return &cloudtrail.LookupEventsOutput{Events: mockedStopLoggingEvents(o.Region, input)}, nil
+		}
+	}
+	if o.Region != "us-east-1" {
+		return &cloudtrail.LookupEventsOutput{}, nil
+	}
+	for _, attribute := range input.LookupAttributes {
+		if attribute.AttributeKey == cloudtrailTypes.LookupAttributeKeyEventSource && aws.ToString(attribute.AttributeValue) == "iam.amazonaws.com" {
+			return &cloudtrail.LookupEventsOutput{Events: mockedIAMEvents(input)}, nil
+		}
+		if attribute.AttributeKey == cloudtrailTypes.LookupAttributeKeyEventName && aws.ToString(attribute.AttributeValue) != "GetSecretValue" {
+			return &cloudtrail.LookupEventsOutput{}, nil
+		}
+	}
+
+	day := 24 * time.Hour
+	accesses := []mockedGetSecretValueAccess{
+		{identityType: "AssumedRole", principalArn: "arn:aws:iam::123456789012:role/app-server", secretID: "prod/db-password", daysAgo: 26, count: 10, interval: 2 * day},
+		{identityType: "AssumedRole", principalArn: "arn:aws:iam::123456789012:role/batch", secretID: "prod/db-password", daysAgo: 27, count: 10, interval: 2 * day},
+		{identityType: "AssumedRole", principalArn: "arn:aws:iam::123456789012:role/app-server", secretID: "arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/api-key-AbCdEf", daysAgo: 17, count: 10, interval: day},
+		{identityType: "AssumedRole", principalArn: "arn:aws:iam::123456789012:role/reporting", secretID: "prod/api-key", daysAgo: 18, count: 9, interval: day},
+		{identityType: "AssumedRole", principalArn: "arn:aws:iam::123456789012:role/ci", secretID: "staging/db-password", daysAgo: 18, count: 11, interval: day},
+		{identityType: "IAMUser", principalArn: "arn:aws:iam::123456789012:user/mallory", secretID: "prod/db-password", daysAgo: 2, count: 60, interval: 30 * time.Minute},
+		{identityType: "AssumedRole", principalArn: "arn:aws:iam::123456789012:role/unknown-lambda", secretID: "prod/api-key", daysAgo: 1, count: 1, interval: day},
+	}
+
+	var events []cloudtrailTypes.Event
+	now := time.Now()
+	for _, access := range accesses {
+		for i := 0; i < access.count; i++ {
+			eventTime := now.Add(-time.Duration(access.daysAgo) * day).Add(time.Duration(i) * access.interval)
+			if input.StartTime != nil && eventTime.Before(aws.ToTime(input.StartTime)) {
+				continue
+			}
+			userIdentity := fmt.Sprintf(`{"type":"IAMUser","arn":"%s"}`, access.principalArn)
+			if access.identityType == "AssumedRole" {
+				userIdentity = fmt.Sprintf(`{"type":"AssumedRole","arn":"arn:aws:sts::123456789012:assumed-role/session","sessionContext":{"sessionIssuer":{"type":"Role","arn":"%s"}}}`, access.principalArn)
+			}
+			events = append(events, cloudtrailTypes.Event{
+				EventId:   aws.String(fmt.Sprintf("%s-%s-%d", access.principalArn, access.secretID, i)),
+				EventName: aws.String("GetSecretValue"),
+				EventTime: aws.Time(eventTime),
+				CloudTrailEvent: aws.String(fmt.Sprintf(
+					`{"userIdentity":%s,"eventTime":"%s","eventSource":"secretsmanager.amazonaws.com","eventName":"GetSecretValue","awsRegion":"us-east-1","sourceIPAddress":"10.0.0.1","requestParameters":{"secretId":"%s"}}`,
+					userIdentity,
+					eventTime.UTC().Format(time.RFC3339),
+					access.secretID,
+				)),
+			})
+		}
+	}
+	return &cloudtrail.LookupEventsOutput{Events: events}, nil
+}
+
+// mockedIAMEvents are the deployment role creating a role and attaching a policy to it from the office network, a
+// console user adding an inline policy, a user creating an access key for another user from an unknown IP, the IAM
+// read that is not a change, a failed trust policy update and a role created two weeks ago
+func mockedIAMEvents(input *cloudtrail.LookupEventsInput) []cloudtrailTypes.Event {
+	deployer := `{"type":"AssumedRole","arn":"arn:aws:sts::123456789012:assumed-role/deployer/ci","sessionContext":{"sessionIssuer":{"type":"Role","arn":"arn:aws:iam::123456789012:role/deployer"}}}`
+	admin := `{"type":"IAMUser","arn":"arn:aws:iam::123456789012:user/admin"}`
+	mallory := `{"type":"IAMUser","arn":"arn:aws:iam::123456789012:user/mallory"}`
+	events := []struct {
+		id                string
+		name              string
+		daysAgo           int
+		userIdentity      string
+		sourceIP          string
+		errorCode         string
+		requestParameters string
+	}{
+		{id: "iam-1", name: "CreateRole", daysAgo: 1, userIdentity: deployer, sourceIP: "10.1.2.3", requestParameters: `{"roleName":"app-server"}`},
+		{id: "iam-2", name: "AttachRolePolicy", daysAgo: 1, userIdentity: deployer, sourceIP: "10.1.2.3", requestParameters: `{"roleName":"app-server","policyArn":"arn:aws:iam::aws:policy/AmazonS3ReadOnlyAccess"}`},
+		{id: "iam-3", name: "PutRolePolicy", daysAgo: 2, userIdentity: admin, sourceIP: "cloudformation.amazonaws.com", requestParameters: `{"roleName":"app-server","policyName":"inline-kms"}`},
+		{id: "iam-4", name: "CreateAccessKey", daysAgo: 3, userIdentity: mallory, sourceIP: "203.0.113.7", requestParameters: `{"userName":"admin"}`},
+		{id: "iam-5", name: "GetRole", daysAgo: 3, userIdentity: mallory, sourceIP: "203.0.113.7", requestParameters: `{"roleName":"deployer"}`},
+		{id: "iam-6", name: "UpdateAssumeRolePolicy", daysAgo: 4, userIdentity: mallory, sourceIP: "203.0.113.7", errorCode: "AccessDenied", requestParameters: `{"roleName":"deployer"}`},
+		{id: "iam-7", name: "CreateUser", daysAgo: 14, userIdentity: deployer, sourceIP: "10.1.2.3", requestParameters: `{"userName":"old-user"}`},
+	}
+
+	var out []cloudtrailTypes.Event
+	now := time.Now()
+	for _, event := range events {
+		eventTime := now.Add(-time.Duration(event.daysAgo) * 24 * time.Hour)
+		if input.StartTime != nil && eventTime.Before(aws.ToTime(input.StartTime)) {
+			continue
+		}
+		errorCode := ""
+		if event.errorCode != "" {
+			errorCode = fmt.Sprintf(`"errorCode":"%s",`, event.errorCode)
+		}
+		out = append(out, cloudtrailTypes.Event{
+			EventId:     aws.String(event.id),
+			EventName:   aws.String(event.name),
+			EventSource: aws.String("iam.amazonaws.com"),
+			EventTime:   aws.Time(eventTime),
+			CloudTrailEvent: aws.String(fmt.Sprintf(
+				`{"userIdentity":%s,"eventTime":"%s","eventSource":"iam.amazonaws.com","eventName":"%s","awsRegion":"us-east-1","sourceIPAddress":"%s",%s"requestParameters":%s}`,
+				event.userIdentity,
+				eventTime.UTC().Format(time.RFC3339),
+				event.name,
+				event.sourceIP,
+				errorCode,
+				event.requestParameters,
+			)),
+		})
+	}
+	return out
+}
+
+// mockedStopLoggingEvents are the security automation role pausing management-events ten days ago and a failed
+// attempt by mallory two days ago in us-east-1, mallory stopping stopped-trail five days ago in us-west-2 and an
+// old event outside of the usual 30 days
+func mockedStopLoggingEvents(region string, input *cloudtrail.LookupEventsInput) []cloudtrailTypes.Event {
+	securityAutomation := `{"type":"AssumedRole","arn":"arn:aws:sts::123456789012:assumed-role/security-automation/remediation","sessionContext":{"sessionIssuer":{"type":"Role","arn":"arn:aws:iam::123456789012:role/security-automation"}}}`
+	mallory := `{"type":"IAMUser","arn":"arn:aws:iam::123456789012:user/mallory"}`
+	events := []struct {
+		id           string
+		region       string
+		daysAgo      int
+		userIdentity string
+		sourceIP     string
+		errorCode    string
+		trail        string
+	}{
+		{id: "stop-1", region: "us-east-1", daysAgo: 10, userIdentity: securityAutomation, sourceIP: "10.1.2.3", trail: "management-events"},
+		{id: "stop-2", region: "us-east-1", daysAgo: 2, userIdentity: mallory, sourceIP: "203.0.113.7", errorCode: "AccessDenied", trail: "arn:aws:cloudtrail:us-east-1:123456789012:trail/management-events"},
+		{id: "stop-3", region: "us-west-2", daysAgo: 5, userIdentity: mallory, sourceIP: "203.0.113.7", trail: "arn:aws:cloudtrail:us-west-2:123456789012:trail/stopped-trail"},
+		{id: "stop-4", region: "us-west-2", daysAgo: 45, userIdentity: mallory, sourceIP: "203.0.113.7", trail: "stopped-trail"},
+	}
+
+	var out []cloudtrailTypes.Event
+	now := time.Now()
+	for _, event := range events {
+		eventTime := now.Add(-time.Duration(event.daysAgo) * 24 * time.Hour)
+		if event.region != region || (input.StartTime != nil && eventTime.Before(aws.ToTime(input.StartTime))) {
+			continue
+		}
+		errorCode := ""
+		if event.errorCode != "" {
+			errorCode = fmt.Sprintf(`"errorCode":"%s",`, event.errorCode)
+		}
+		out = append(out, cloudtrailTypes.Event{
+			EventId:     aws.String(event.id),
+			EventName:   aws.String("StopLogging"),
+			EventSource: aws.String("cloudtrail.amazonaws.com"),
+			EventTime:   aws.Time(eventTime),
+			CloudTrailEvent: aws.String(fmt.Sprintf(
+				`{"userIdentity":%s,"eventTime":"%s","eventSource":"cloudtrail.amazonaws.com","eventName":"StopLogging","awsRegion":"%s","sourceIPAddress":"%s",%s"requestParameters":{"name":"%s"}}`,
+				event.userIdentity,
+				eventTime.UTC().Format(time.RFC3339),
+				event.region,
+				event.sourceIP,
+				errorCode,
+				event.trail,
+			)),
+		})
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cloudwatchTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type CloudWatchClientInterface interface {
+	GetMetricStatistics(context.Context, *cloudwatch.GetMetricStatisticsInput, ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error)
+}
+
+func init() {
+	gob.Register([]cloudwatchTypes.Datapoint{})
+}
+
+// CachedCloudWatchGetMetricMaximum returns the Maximum datapoints of a metric with a single dimension over the
+// given period, ending now.
+func CachedCloudWatchGetMetricMaximum(client CloudWatchClientInterface, accountID string, region string, namespace string, metricName string, dimensionName string, dimensionValue string, period time.Duration) ([]cloudwatchTypes.Datapoint, error) {
+	var datapoints []cloudwatchTypes.Datapoint
+	cacheKey := fmt.Sprintf("%s-cloudwatch-GetMetricStatistics-%s-%s-%s-%s-%s", accountID, region, namespace, metricName, dimensionName, dimensionValue)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]cloudwatchTypes.Datapoint), nil
+	}
+
+	endTime := time.Now()
+	GetMetricStatistics, err := client.GetMetricStatistics(
+		context.TODO(),
+		&cloudwatch.GetMetricStatisticsInput{
+			Namespace:  aws.String(namespace),
+			MetricName: aws.String(metricName),
+			Dimensions: []cloudwatchTypes.Dimension{
+				{
+					Name:  aws.String(dimensionName),
+					Value: aws.String(dimensionValue),
+				},
+			},
+			StartTime:  aws.Time(endTime.Add(-period)),
+			EndTime:    aws.Time(endTime),
+			Period:     aws.Int32(int32(period.Seconds())),
+			Statistics: []cloudwatchTypes.Statistic{cloudwatchTypes.StatisticMaximum},
+		},
+		func(o *cloudwatch.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return datapoints, err
+	}
+	datapoints = GetMetricStatistics.Datapoints
+
+	internal.Cache.Set(cacheKey, datapoints, cache.DefaultExpiration)
+	return datapoints, nil
+}
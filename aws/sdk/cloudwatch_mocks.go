@@ -0,0 +1,37 @@
+package sdk
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cloudwatchTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// MockedCloudWatchClient reports Aurora global database replication lag (in milliseconds) for the secondary cluster
+// in us-west-2 and has no datapoints for anything else
+type MockedCloudWatchClient struct {
+}
+
+func (m *MockedCloudWatchClient) GetMetricStatistics(ctx context.Context, input *cloudwatch.GetMetricStatisticsInput, options ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
+	output := &cloudwatch.GetMetricStatisticsOutput{Label: input.MetricName}
+	if aws.ToString(input.MetricName) != "AuroraGlobalDBReplicationLag" || len(input.Dimensions) == 0 {
+		return output, nil
+	}
+	if aws.ToString(input.Dimensions[0].Value) == "orders-secondary" {
+		output.Datapoints = []cloudwatchTypes.Datapoint{
+			{
+				Timestamp: aws.Time(time.Now().Add(-10 * time.Minute)),
+				Maximum:   aws.Float64(640),
+				Unit:      cloudwatchTypes.StandardUnitMilliseconds,
+			},
+			{
+				Timestamp: aws.Time(time.Now().Add(-5 * time.Minute)),
+				Maximum:   aws.Float64(1250),
+				Unit:      cloudwatchTypes.StandardUnitMilliseconds,
+			},
+		}
+	}
+	return output, nil
+}
@@ -0,0 +1,177 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	logsTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type CloudWatchLogsClientInterface interface {
+	DescribeLogGroups(context.Context, *cloudwatchlogs.DescribeLogGroupsInput, ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogGroupsOutput, error)
+	DescribeLogStreams(context.Context, *cloudwatchlogs.DescribeLogStreamsInput, ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogStreamsOutput, error)
+	FilterLogEvents(context.Context, *cloudwatchlogs.FilterLogEventsInput, ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.FilterLogEventsOutput, error)
+	DescribeSubscriptionFilters(context.Context, *cloudwatchlogs.DescribeSubscriptionFiltersInput, ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeSubscriptionFiltersOutput, error)
+}
+
+func init() {
+	gob.Register([]logsTypes.LogGroup{})
+	gob.Register(logsTypes.LogStream{})
+	gob.Register(&logsTypes.LogGroup{})
+	gob.Register([]logsTypes.SubscriptionFilter{})
+}
+
+func CachedCloudWatchLogsDescribeLogGroups(client CloudWatchLogsClientInterface, accountID string, region string) ([]logsTypes.LogGroup, error) {
+	var PaginationControl *string
+	var logGroups []logsTypes.LogGroup
+	cacheKey := fmt.Sprintf("%s-logs-DescribeLogGroups-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]logsTypes.LogGroup), nil
+	}
+
+	for {
+		DescribeLogGroups, err := client.DescribeLogGroups(
+			context.TODO(),
+			&cloudwatchlogs.DescribeLogGroupsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *cloudwatchlogs.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return logGroups, err
+		}
+
+		logGroups = append(logGroups, DescribeLogGroups.LogGroups...)
+
+		//pagination
+		if DescribeLogGroups.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeLogGroups.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, logGroups, cache.DefaultExpiration)
+	return logGroups, nil
+}
+
+// CachedCloudWatchLogsLatestLogStream returns the stream that received the most recent event, or an empty stream when
+// the group has none
+func CachedCloudWatchLogsLatestLogStream(client CloudWatchLogsClientInterface, accountID string, region string, logGroupName string) (logsTypes.LogStream, error) {
+	var logStream logsTypes.LogStream
+	cacheKey := fmt.Sprintf("%s-logs-DescribeLogStreams-%s-%s", accountID, region, logGroupName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(logsTypes.LogStream), nil
+	}
+
+	DescribeLogStreams, err := client.DescribeLogStreams(
+		context.TODO(),
+		&cloudwatchlogs.DescribeLogStreamsInput{
+			LogGroupName: &logGroupName,
+			OrderBy:      logsTypes.OrderByLastEventTime,
+			Descending:   aws.Bool(true),
+			Limit:        aws.Int32(1),
+		},
+		func(o *cloudwatchlogs.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return logStream, err
+	}
+	if len(DescribeLogStreams.LogStreams) > 0 {
+		logStream = DescribeLogStreams.LogStreams[0]
+	}
+
+	internal.Cache.Set(cacheKey, logStream, cache.DefaultExpiration)
+	return logStream, nil
+}
+
+// CachedCloudWatchLogsDescribeLogGroup looks up a single log group by name, it returns nil when the group doesn't exist
+func CachedCloudWatchLogsDescribeLogGroup(client CloudWatchLogsClientInterface, accountID string, region string, logGroupName string) (*logsTypes.LogGroup, error) {
+	var PaginationControl *string
+	cacheKey := fmt.Sprintf("%s-logs-DescribeLogGroup-%s-%s", accountID, region, logGroupName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(*logsTypes.LogGroup), nil
+	}
+
+	for {
+		DescribeLogGroups, err := client.DescribeLogGroups(
+			context.TODO(),
+			&cloudwatchlogs.DescribeLogGroupsInput{
+				LogGroupNamePrefix: aws.String(logGroupName),
+				NextToken:          PaginationControl,
+			},
+			func(o *cloudwatchlogs.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// The prefix also matches longer names, so look for the exact one
+		for i := range DescribeLogGroups.LogGroups {
+			if aws.ToString(DescribeLogGroups.LogGroups[i].LogGroupName) == logGroupName {
+				logGroup := DescribeLogGroups.LogGroups[i]
+				internal.Cache.Set(cacheKey, &logGroup, cache.DefaultExpiration)
+				return &logGroup, nil
+			}
+		}
+
+		//pagination
+		if DescribeLogGroups.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeLogGroups.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, (*logsTypes.LogGroup)(nil), cache.DefaultExpiration)
+	return nil, nil
+}
+
+func CachedCloudWatchLogsDescribeSubscriptionFilters(client CloudWatchLogsClientInterface, accountID string, region string, logGroupName string) ([]logsTypes.SubscriptionFilter, error) {
+	var PaginationControl *string
+	var subscriptionFilters []logsTypes.SubscriptionFilter
+	cacheKey := fmt.Sprintf("%s-logs-DescribeSubscriptionFilters-%s-%s", accountID, region, logGroupName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]logsTypes.SubscriptionFilter), nil
+	}
+
+	for {
+		DescribeSubscriptionFilters, err := client.DescribeSubscriptionFilters(
+			context.TODO(),
+			&cloudwatchlogs.DescribeSubscriptionFiltersInput{
+				LogGroupName: &logGroupName,
+				NextToken:    PaginationControl,
+			},
+			func(o *cloudwatchlogs.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return subscriptionFilters, err
+		}
+
+		subscriptionFilters = append(subscriptionFilters, DescribeSubscriptionFilters.SubscriptionFilters...)
+
+		//pagination
+		if DescribeSubscriptionFilters.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeSubscriptionFilters.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, subscriptionFilters, cache.DefaultExpiration)
+	return subscriptionFilters, nil
+}
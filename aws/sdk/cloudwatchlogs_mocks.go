@@ -0,0 +1,179 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	logsTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+type MockedCloudWatchLogsClient struct {
+}
+
+// /aws/lambda/payments keeps its logs forever and is still written to, /ecs/frontend expires its logs after 30 days
+// and /aws/lambda/old-job keeps them forever but hasn't logged anything for months
+func (m *MockedCloudWatchLogsClient) DescribeLogGroups(ctx context.Context, input *cloudwatchlogs.DescribeLogGroupsInput, options ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+	// aws-waf-logs-alb is only returned when it is looked up by name, so it doesn't show up when listing all groups
+	if aws.ToString(input.LogGroupNamePrefix) == "aws-waf-logs-alb" {
+		return &cloudwatchlogs.DescribeLogGroupsOutput{
+			LogGroups: []logsTypes.LogGroup{
+				{
+					LogGroupName:  aws.String("aws-waf-logs-alb"),
+					Arn:           aws.String("arn:aws:logs:us-east-1:123456789012:log-group:aws-waf-logs-alb:*"),
+					KmsKeyId:      aws.String("arn:aws:kms:us-east-1:123456789012:key/66666666-7777-8888-9999-000000000000"),
+					LogGroupClass: logsTypes.LogGroupClassStandard,
+				},
+			},
+		}, nil
+	}
+	return &cloudwatchlogs.DescribeLogGroupsOutput{
+		LogGroups: []logsTypes.LogGroup{
+			{
+				LogGroupName:  aws.String("/aws/lambda/payments"),
+				Arn:           aws.String("arn:aws:logs:us-east-1:123456789012:log-group:/aws/lambda/payments:*"),
+				StoredBytes:   aws.Int64(52428800),
+				LogGroupClass: logsTypes.LogGroupClassStandard,
+			},
+			{
+				LogGroupName:    aws.String("/ecs/frontend"),
+				Arn:             aws.String("arn:aws:logs:us-east-1:123456789012:log-group:/ecs/frontend:*"),
+				RetentionInDays: aws.Int32(30),
+				StoredBytes:     aws.Int64(1048576),
+				KmsKeyId:        aws.String("arn:aws:kms:us-east-1:123456789012:key/11111111-2222-3333-4444-555555555555"),
+				LogGroupClass:   logsTypes.LogGroupClassStandard,
+			},
+			{
+				LogGroupName:  aws.String("/aws/lambda/old-job"),
+				Arn:           aws.String("arn:aws:logs:us-east-1:123456789012:log-group:/aws/lambda/old-job:*"),
+				StoredBytes:   aws.Int64(2048),
+				LogGroupClass: logsTypes.LogGroupClassStandard,
+			},
+		},
+	}, nil
+}
+
+func (m *MockedCloudWatchLogsClient) DescribeLogStreams(ctx context.Context, input *cloudwatchlogs.DescribeLogStreamsInput, options ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogStreamsOutput, error) {
+	lastEvent := time.Now().Add(-2 * time.Hour)
+	if aws.ToString(input.LogGroupName) == "/aws/lambda/old-job" {
+		lastEvent = time.Now().AddDate(0, -4, 0)
+	}
+	return &cloudwatchlogs.DescribeLogStreamsOutput{
+		LogStreams: []logsTypes.LogStream{
+			{
+				LogStreamName:      aws.String("2024/01/01/[$LATEST]abcdef0123456789"),
+				LastEventTimestamp: aws.Int64(lastEvent.UnixMilli()),
+			},
+		},
+	}, nil
+}
+
+// Events are returned when one of the terms in the filter pattern appears in them. /aws/verified-access/corp returns
+// all of its Verified Access logs without a filter pattern.
+func (m *MockedCloudWatchLogsClient) FilterLogEvents(ctx context.Context, input *cloudwatchlogs.FilterLogEventsInput, options ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.FilterLogEventsOutput, error) {
+	if aws.ToString(input.LogGroupName) == "/aws/verified-access/corp" {
+		var events []logsTypes.FilteredLogEvent
+		for _, event := range mockedVerifiedAccessCloudWatchLogs() {
+			events = append(events, logsTypes.FilteredLogEvent{
+				LogStreamName: aws.String("verified-access/vai-0corp"),
+				Message:       aws.String(event.message()),
+				Timestamp:     aws.Int64(event.eventTime().UnixMilli()),
+			})
+		}
+		return &cloudwatchlogs.FilterLogEventsOutput{Events: events}, nil
+	}
+	events := map[string][]string{
+		"/aws/lambda/payments": {
+			"START RequestId: 6bc28136-0000-4000-8000-000000000001 Version: $LATEST",
+			"DEBUG connecting to db with password=Sup3rS3cret!",
+			"INFO charge created for customer cus_123",
+		},
+		"/ecs/frontend": {
+			"GET /health 200",
+		},
+	}
+	var terms []string
+	for _, term := range strings.Fields(aws.ToString(input.FilterPattern)) {
+		terms = append(terms, strings.Trim(strings.TrimPrefix(term, "?"), "\""))
+	}
+	var matches []logsTypes.FilteredLogEvent
+	for _, message := range events[aws.ToString(input.LogGroupName)] {
+		for _, term := range terms {
+			if strings.Contains(strings.ToLower(message), strings.ToLower(term)) {
+				matches = append(matches, logsTypes.FilteredLogEvent{
+					LogStreamName: aws.String("2024/01/01/[$LATEST]abcdef0123456789"),
+					Message:       aws.String(message),
+					Timestamp:     aws.Int64(time.Now().Add(-3 * time.Hour).UnixMilli()),
+				})
+				break
+			}
+		}
+	}
+	return &cloudwatchlogs.FilterLogEventsOutput{Events: matches}, nil
+}
+
+// /aws/lambda/payments is shipped to a Firehose stream that feeds a SIEM
+func (m *MockedCloudWatchLogsClient) DescribeSubscriptionFilters(ctx context.Context, input *cloudwatchlogs.DescribeSubscriptionFiltersInput, options ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeSubscriptionFiltersOutput, error) {
+	if aws.ToString(input.LogGroupName) != "/aws/lambda/payments" {
+		return &cloudwatchlogs.DescribeSubscriptionFiltersOutput{}, nil
+	}
+	return &cloudwatchlogs.DescribeSubscriptionFiltersOutput{
+		SubscriptionFilters: []logsTypes.SubscriptionFilter{
+			{
+				FilterName:     aws.String("to-splunk"),
+				LogGroupName:   input.LogGroupName,
+				DestinationArn: aws.String("arn:aws:firehose:us-east-1:123456789012:deliverystream/logs-to-splunk"),
+				FilterPattern:  aws.String(""),
+			},
+		},
+	}, nil
+}
+
+// mockedVerifiedAccessLogEvent is an OCSF access event of Verified Access, hoursAgo hours ago
+type mockedVerifiedAccessLogEvent struct {
+	user     string
+	hostname string
+	ip       string
+	country  string
+	denied   bool
+	hoursAgo int
+}
+
+func (e mockedVerifiedAccessLogEvent) eventTime() time.Time {
+	return time.Now().Add(-time.Duration(e.hoursAgo) * time.Hour)
+}
+
+func (e mockedVerifiedAccessLogEvent) message() string {
+	activity, decision, code := "Access Grant", "Allow", 200
+	if e.denied {
+		activity, decision, code = "Access Deny", "Deny", 403
+	}
+	return fmt.Sprintf(
+		`{"time":%d,"activity_name":"%s","actor":{"authorizations":[{"decision":"%s"}],"user":{"email_addr":"%s","uid":"%s"}},"src_endpoint":{"ip":"%s","location":{"country":"%s"}},"http_request":{"http_method":"GET","url":{"hostname":"%s","path":"/"}},"http_response":{"code":%d},"metadata":{"version":"1.0.0-rc.2"}}`,
+		e.eventTime().UnixMilli(), activity, decision, e.user, e.user, e.ip, e.country, e.hostname, code,
+	)
+}
+
+// alice uses the wiki every day and opened jenkins for the first time today, bob's usual jenkins access came from a
+// different country today and mallory was denied four times
+func mockedVerifiedAccessCloudWatchLogs() []mockedVerifiedAccessLogEvent {
+	var events []mockedVerifiedAccessLogEvent
+	for day := 2; day <= 6; day++ {
+		events = append(events,
+			mockedVerifiedAccessLogEvent{user: "alice@example.com", hostname: "wiki.example.com", ip: "198.51.100.10", country: "US", hoursAgo: day * 24},
+			mockedVerifiedAccessLogEvent{user: "bob@example.com", hostname: "jenkins.example.com", ip: "198.51.100.20", country: "US", hoursAgo: day * 24},
+		)
+	}
+	events = append(events,
+		mockedVerifiedAccessLogEvent{user: "alice@example.com", hostname: "wiki.example.com", ip: "198.51.100.10", country: "US", hoursAgo: 3},
+		mockedVerifiedAccessLogEvent{user: "alice@example.com", hostname: "jenkins.example.com", ip: "198.51.100.10", country: "US", hoursAgo: 2},
+		mockedVerifiedAccessLogEvent{user: "bob@example.com", hostname: "jenkins.example.com", ip: "203.0.113.50", country: "RO", hoursAgo: 1},
+	)
+	for i := 0; i < 4; i++ {
+		events = append(events, mockedVerifiedAccessLogEvent{user: "mallory@example.com", hostname: "jenkins.example.com", ip: "192.0.2.66", country: "US", denied: true, hoursAgo: 1})
+	}
+	return events
+}
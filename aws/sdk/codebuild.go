@@ -88,7 +88,11 @@ func CachedCodeBuildBatchGetProjects(CodeBuildClient CodeBuildClientInterface, a
 		return project, err
 	}
 
-	internal.Cache.Set(cacheKey, BatchGetProjects.Projects, cache.DefaultExpiration)
+	if len(BatchGetProjects.Projects) == 0 {
+		return project, fmt.Errorf("project %s not found", projectID)
+	}
+
+	internal.Cache.Set(cacheKey, BatchGetProjects.Projects[0], cache.DefaultExpiration)
 	return BatchGetProjects.Projects[0], nil
 }
 
@@ -0,0 +1,82 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	configServiceTypes "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type AWSConfigServiceClientInterface interface {
+	ListDiscoveredResources(ctx context.Context, params *configservice.ListDiscoveredResourcesInput, optFns ...func(*configservice.Options)) (*configservice.ListDiscoveredResourcesOutput, error)
+	DescribeConfigurationRecorderStatus(ctx context.Context, params *configservice.DescribeConfigurationRecorderStatusInput, optFns ...func(*configservice.Options)) (*configservice.DescribeConfigurationRecorderStatusOutput, error)
+}
+
+func init() {
+	gob.Register([]configServiceTypes.ResourceIdentifier{})
+	gob.Register([]configServiceTypes.ConfigurationRecorderStatus{})
+}
+
+// CachedConfigServiceListDiscoveredResources returns the resources of the given type that AWS Config has recorded in a region
+func CachedConfigServiceListDiscoveredResources(client AWSConfigServiceClientInterface, accountID string, region string, resourceType string) ([]configServiceTypes.ResourceIdentifier, error) {
+	var PaginationControl *string
+	var resources []configServiceTypes.ResourceIdentifier
+	cacheKey := fmt.Sprintf("%s-configservice-ListDiscoveredResources-%s-%s", accountID, region, resourceType)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]configServiceTypes.ResourceIdentifier), nil
+	}
+	for {
+		ListDiscoveredResources, err := client.ListDiscoveredResources(
+			context.TODO(),
+			&configservice.ListDiscoveredResourcesInput{
+				ResourceType: configServiceTypes.ResourceType(resourceType),
+				NextToken:    PaginationControl,
+			},
+			func(o *configservice.Options) {
+				o.Region = region
+			},
+		)
+
+		if err != nil {
+			return resources, err
+		}
+
+		resources = append(resources, ListDiscoveredResources.ResourceIdentifiers...)
+
+		//pagination
+		if ListDiscoveredResources.NextToken == nil {
+			break
+		}
+		PaginationControl = ListDiscoveredResources.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, resources, cache.DefaultExpiration)
+	return resources, nil
+}
+
+func CachedConfigServiceDescribeConfigurationRecorderStatus(client AWSConfigServiceClientInterface, accountID string, region string) ([]configServiceTypes.ConfigurationRecorderStatus, error) {
+	cacheKey := fmt.Sprintf("%s-configservice-DescribeConfigurationRecorderStatus-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]configServiceTypes.ConfigurationRecorderStatus), nil
+	}
+
+	DescribeConfigurationRecorderStatus, err := client.DescribeConfigurationRecorderStatus(
+		context.TODO(),
+		&configservice.DescribeConfigurationRecorderStatusInput{},
+		func(o *configservice.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	internal.Cache.Set(cacheKey, DescribeConfigurationRecorderStatus.ConfigurationRecordersStatus, cache.DefaultExpiration)
+	return DescribeConfigurationRecorderStatus.ConfigurationRecordersStatus, nil
+}
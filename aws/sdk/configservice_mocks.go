@@ -0,0 +1,44 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	configServiceTypes "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+)
+
+type MockedConfigServiceClient struct {
+}
+
+func (m *MockedConfigServiceClient) ListDiscoveredResources(ctx context.Context, input *configservice.ListDiscoveredResourcesInput, options ...func(*configservice.Options)) (*configservice.ListDiscoveredResourcesOutput, error) {
+	if input.ResourceType != configServiceTypes.ResourceType("AWS::SecretsManager::Secret") {
+		return &configservice.ListDiscoveredResourcesOutput{}, nil
+	}
+	return &configservice.ListDiscoveredResourcesOutput{
+		ResourceIdentifiers: []configServiceTypes.ResourceIdentifier{
+			{
+				ResourceId:   aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:secret1-AbCdEf"),
+				ResourceName: aws.String("secret1"),
+				ResourceType: configServiceTypes.ResourceType("AWS::SecretsManager::Secret"),
+			},
+			{
+				ResourceId:   aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:old-db-password-GhIjKl"),
+				ResourceName: aws.String("old-db-password"),
+				ResourceType: configServiceTypes.ResourceType("AWS::SecretsManager::Secret"),
+			},
+		},
+	}, nil
+}
+
+func (m *MockedConfigServiceClient) DescribeConfigurationRecorderStatus(ctx context.Context, input *configservice.DescribeConfigurationRecorderStatusInput, options ...func(*configservice.Options)) (*configservice.DescribeConfigurationRecorderStatusOutput, error) {
+	return &configservice.DescribeConfigurationRecorderStatusOutput{
+		ConfigurationRecordersStatus: []configServiceTypes.ConfigurationRecorderStatus{
+			{
+				Name:       aws.String("default"),
+				Recording:  true,
+				LastStatus: configServiceTypes.RecorderStatusSuccess,
+			},
+		},
+	}, nil
+}
@@ -13,10 +13,18 @@ import (
 
 type AWSDataPipelineClientInterface interface {
 	ListPipelines(ctx context.Context, input *datapipeline.ListPipelinesInput, opts ...func(*datapipeline.Options)) (*datapipeline.ListPipelinesOutput, error)
+	GetPipelineDefinition(ctx context.Context, input *datapipeline.GetPipelineDefinitionInput, opts ...func(*datapipeline.Options)) (*datapipeline.GetPipelineDefinitionOutput, error)
+}
+
+// The full GetPipelineDefinitionOutput can't be gob encoded, so only the parts we need are cached
+type customGetPipelineDefinitionOutput struct {
+	PipelineObjects []dataPipelineTypes.PipelineObject
+	ParameterValues []dataPipelineTypes.ParameterValue
 }
 
 func init() {
 	gob.Register([]dataPipelineTypes.PipelineIdName{})
+	gob.Register(customGetPipelineDefinitionOutput{})
 }
 
 func CachedDataPipelineListPipelines(client AWSDataPipelineClientInterface, accountID string, region string) ([]dataPipelineTypes.PipelineIdName, error) {
@@ -54,3 +62,30 @@ func CachedDataPipelineListPipelines(client AWSDataPipelineClientInterface, acco
 	internal.Cache.Set(cacheKey, pipelines, cache.DefaultExpiration)
 	return pipelines, nil
 }
+
+func CachedDataPipelineGetPipelineDefinition(client AWSDataPipelineClientInterface, accountID string, region string, pipelineID string) (customGetPipelineDefinitionOutput, error) {
+	var definition customGetPipelineDefinitionOutput
+	cacheKey := fmt.Sprintf("%s-datapipeline-GetPipelineDefinition-%s-%s", accountID, region, pipelineID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(customGetPipelineDefinitionOutput), nil
+	}
+
+	GetPipelineDefinition, err := client.GetPipelineDefinition(
+		context.TODO(),
+		&datapipeline.GetPipelineDefinitionInput{
+			PipelineId: &pipelineID,
+		},
+		func(o *datapipeline.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return definition, err
+	}
+
+	definition.PipelineObjects = GetPipelineDefinition.PipelineObjects
+	definition.ParameterValues = GetPipelineDefinition.ParameterValues
+	internal.Cache.Set(cacheKey, definition, cache.DefaultExpiration)
+	return definition, nil
+}
@@ -25,3 +25,70 @@ func (m *MockedDataPipelineClient) ListPipelines(ctx context.Context, input *dat
 		},
 	}, nil
 }
+
+func (m *MockedDataPipelineClient) GetPipelineDefinition(ctx context.Context, input *datapipeline.GetPipelineDefinitionInput, options ...func(*datapipeline.Options)) (*datapipeline.GetPipelineDefinitionOutput, error) {
+	if aws.ToString(input.PipelineId) != "pipeline1" {
+		return &datapipeline.GetPipelineDefinitionOutput{
+			PipelineObjects: []dataPipelineTypes.PipelineObject{
+				{
+					Id:   aws.String("Default"),
+					Name: aws.String("Default"),
+					Fields: []dataPipelineTypes.Field{
+						{Key: aws.String("scheduleType"), StringValue: aws.String("ondemand")},
+					},
+				},
+			},
+		}, nil
+	}
+	return &datapipeline.GetPipelineDefinitionOutput{
+		PipelineObjects: []dataPipelineTypes.PipelineObject{
+			{
+				Id:   aws.String("Default"),
+				Name: aws.String("Default"),
+				Fields: []dataPipelineTypes.Field{
+					{Key: aws.String("scheduleType"), StringValue: aws.String("cron")},
+					{Key: aws.String("role"), StringValue: aws.String("DataPipelineDefaultRole")},
+				},
+			},
+			{
+				Id:   aws.String("SourceDatabase"),
+				Name: aws.String("SourceDatabase"),
+				Fields: []dataPipelineTypes.Field{
+					{Key: aws.String("type"), StringValue: aws.String("JdbcDatabase")},
+					{Key: aws.String("connectionString"), StringValue: aws.String("jdbc:mysql://db.example.com:3306/orders")},
+					{Key: aws.String("username"), StringValue: aws.String("etl")},
+					{Key: aws.String("*password"), StringValue: aws.String("Sup3rS3cret!")},
+				},
+			},
+			{
+				Id:   aws.String("ReportingDatabase"),
+				Name: aws.String("ReportingDatabase"),
+				Fields: []dataPipelineTypes.Field{
+					{Key: aws.String("type"), StringValue: aws.String("JdbcDatabase")},
+					{Key: aws.String("connectionString"), StringValue: aws.String("jdbc:postgresql://reporting.example.com:5432/reports?user=report&password=hunter2")},
+				},
+			},
+			{
+				Id:   aws.String("Warehouse"),
+				Name: aws.String("Warehouse"),
+				Fields: []dataPipelineTypes.Field{
+					{Key: aws.String("type"), StringValue: aws.String("RedshiftDatabase")},
+					{Key: aws.String("clusterId"), StringValue: aws.String("warehouse-cluster")},
+					{Key: aws.String("username"), StringValue: aws.String("loader")},
+					{Key: aws.String("*password"), StringValue: aws.String("#{*myRedshiftPassword}")},
+				},
+			},
+			{
+				Id:   aws.String("OutputLocation"),
+				Name: aws.String("OutputLocation"),
+				Fields: []dataPipelineTypes.Field{
+					{Key: aws.String("type"), StringValue: aws.String("S3DataNode")},
+					{Key: aws.String("directoryPath"), StringValue: aws.String("s3://exports-bucket/orders/")},
+				},
+			},
+		},
+		ParameterValues: []dataPipelineTypes.ParameterValue{
+			{Id: aws.String("*myRedshiftPassword"), StringValue: aws.String("r3dsh1ft")},
+		},
+	}, nil
+}
@@ -21,7 +21,58 @@ type AWSEC2ClientInterface interface {
 	DescribeInstanceAttribute(context.Context, *ec2.DescribeInstanceAttributeInput, ...func(*ec2.Options)) (*ec2.DescribeInstanceAttributeOutput, error)
 }
 
+// AWSEC2RoutingClientInterface covers the VPC, route table and Outposts local gateway calls. It is separate from
+// AWSEC2ClientInterface so the existing EC2 mocks don't have to implement it.
+type AWSEC2RoutingClientInterface interface {
+	DescribeVpcs(context.Context, *ec2.DescribeVpcsInput, ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error)
+	DescribeRouteTables(context.Context, *ec2.DescribeRouteTablesInput, ...func(*ec2.Options)) (*ec2.DescribeRouteTablesOutput, error)
+	DescribeLocalGateways(context.Context, *ec2.DescribeLocalGatewaysInput, ...func(*ec2.Options)) (*ec2.DescribeLocalGatewaysOutput, error)
+	DescribeLocalGatewayRouteTables(context.Context, *ec2.DescribeLocalGatewayRouteTablesInput, ...func(*ec2.Options)) (*ec2.DescribeLocalGatewayRouteTablesOutput, error)
+	DescribeLocalGatewayRouteTableVpcAssociations(context.Context, *ec2.DescribeLocalGatewayRouteTableVpcAssociationsInput, ...func(*ec2.Options)) (*ec2.DescribeLocalGatewayRouteTableVpcAssociationsOutput, error)
+	SearchLocalGatewayRoutes(context.Context, *ec2.SearchLocalGatewayRoutesInput, ...func(*ec2.Options)) (*ec2.SearchLocalGatewayRoutesOutput, error)
+}
+
+// AWSEC2EndpointServicesClientInterface covers the PrivateLink endpoint service calls, kept separate for the same reason
+type AWSEC2EndpointServicesClientInterface interface {
+	DescribeVpcEndpointServiceConfigurations(context.Context, *ec2.DescribeVpcEndpointServiceConfigurationsInput, ...func(*ec2.Options)) (*ec2.DescribeVpcEndpointServiceConfigurationsOutput, error)
+	DescribeVpcEndpointServicePermissions(context.Context, *ec2.DescribeVpcEndpointServicePermissionsInput, ...func(*ec2.Options)) (*ec2.DescribeVpcEndpointServicePermissionsOutput, error)
+	DescribeVpcEndpointConnections(context.Context, *ec2.DescribeVpcEndpointConnectionsInput, ...func(*ec2.Options)) (*ec2.DescribeVpcEndpointConnectionsOutput, error)
+}
+
+// AWSEC2VerifiedAccessClientInterface covers the Verified Access calls, kept separate for the same reason
+type AWSEC2VerifiedAccessClientInterface interface {
+	DescribeVerifiedAccessInstances(context.Context, *ec2.DescribeVerifiedAccessInstancesInput, ...func(*ec2.Options)) (*ec2.DescribeVerifiedAccessInstancesOutput, error)
+	DescribeVerifiedAccessInstanceLoggingConfigurations(context.Context, *ec2.DescribeVerifiedAccessInstanceLoggingConfigurationsInput, ...func(*ec2.Options)) (*ec2.DescribeVerifiedAccessInstanceLoggingConfigurationsOutput, error)
+	DescribeVerifiedAccessEndpoints(context.Context, *ec2.DescribeVerifiedAccessEndpointsInput, ...func(*ec2.Options)) (*ec2.DescribeVerifiedAccessEndpointsOutput, error)
+}
+
+// AWSEC2SecurityGroupsClientInterface covers DescribeSecurityGroups, kept separate for the same reason
+type AWSEC2SecurityGroupsClientInterface interface {
+	DescribeSecurityGroups(context.Context, *ec2.DescribeSecurityGroupsInput, ...func(*ec2.Options)) (*ec2.DescribeSecurityGroupsOutput, error)
+}
+
+// AWSEC2LaunchTemplatesClientInterface covers the launch template calls, kept separate for the same reason
+type AWSEC2LaunchTemplatesClientInterface interface {
+	DescribeLaunchTemplates(context.Context, *ec2.DescribeLaunchTemplatesInput, ...func(*ec2.Options)) (*ec2.DescribeLaunchTemplatesOutput, error)
+	DescribeLaunchTemplateVersions(context.Context, *ec2.DescribeLaunchTemplateVersionsInput, ...func(*ec2.Options)) (*ec2.DescribeLaunchTemplateVersionsOutput, error)
+}
+
 func init() {
+	gob.Register([]ec2Types.LaunchTemplate{})
+	gob.Register([]ec2Types.LaunchTemplateVersion{})
+	gob.Register([]ec2Types.SecurityGroup{})
+	gob.Register([]ec2Types.VerifiedAccessInstance{})
+	gob.Register([]ec2Types.VerifiedAccessInstanceLoggingConfiguration{})
+	gob.Register([]ec2Types.VerifiedAccessEndpoint{})
+	gob.Register([]ec2Types.ServiceConfiguration{})
+	gob.Register([]ec2Types.AllowedPrincipal{})
+	gob.Register([]ec2Types.VpcEndpointConnection{})
+	gob.Register([]ec2Types.Vpc{})
+	gob.Register([]ec2Types.RouteTable{})
+	gob.Register([]ec2Types.LocalGateway{})
+	gob.Register([]ec2Types.LocalGatewayRouteTable{})
+	gob.Register([]ec2Types.LocalGatewayRouteTableVpcAssociation{})
+	gob.Register([]ec2Types.LocalGatewayRoute{})
 	gob.Register([]ec2Types.Instance{})
 	gob.Register([]ec2Types.NetworkInterface{})
 	gob.Register([]ec2Types.Snapshot{})
@@ -226,3 +277,502 @@ func CachedEC2DescribeImages(client AWSEC2ClientInterface, accountID string, reg
 	return Images, nil
 
 }
+
+func CachedEC2DescribeSecurityGroups(client AWSEC2SecurityGroupsClientInterface, accountID string, region string) ([]ec2Types.SecurityGroup, error) {
+	var PaginationControl *string
+	var securityGroups []ec2Types.SecurityGroup
+	cacheKey := fmt.Sprintf("%s-ec2-DescribeSecurityGroups-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]ec2Types.SecurityGroup), nil
+	}
+	for {
+		DescribeSecurityGroups, err := client.DescribeSecurityGroups(
+			context.TODO(),
+			&ec2.DescribeSecurityGroupsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *ec2.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return securityGroups, err
+		}
+		securityGroups = append(securityGroups, DescribeSecurityGroups.SecurityGroups...)
+
+		if DescribeSecurityGroups.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeSecurityGroups.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, securityGroups, cache.DefaultExpiration)
+	return securityGroups, nil
+}
+
+func CachedEC2DescribeLaunchTemplates(client AWSEC2LaunchTemplatesClientInterface, accountID string, region string) ([]ec2Types.LaunchTemplate, error) {
+	var PaginationControl *string
+	var launchTemplates []ec2Types.LaunchTemplate
+	cacheKey := fmt.Sprintf("%s-ec2-DescribeLaunchTemplates-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]ec2Types.LaunchTemplate), nil
+	}
+	for {
+		DescribeLaunchTemplates, err := client.DescribeLaunchTemplates(
+			context.TODO(),
+			&ec2.DescribeLaunchTemplatesInput{
+				NextToken: PaginationControl,
+			},
+			func(o *ec2.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return launchTemplates, err
+		}
+		launchTemplates = append(launchTemplates, DescribeLaunchTemplates.LaunchTemplates...)
+
+		if DescribeLaunchTemplates.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeLaunchTemplates.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, launchTemplates, cache.DefaultExpiration)
+	return launchTemplates, nil
+}
+
+// CachedEC2DescribeLaunchTemplateVersions returns every version of a launch template, not just the default and latest
+func CachedEC2DescribeLaunchTemplateVersions(client AWSEC2LaunchTemplatesClientInterface, accountID string, region string, launchTemplateID string) ([]ec2Types.LaunchTemplateVersion, error) {
+	var PaginationControl *string
+	var versions []ec2Types.LaunchTemplateVersion
+	cacheKey := fmt.Sprintf("%s-ec2-DescribeLaunchTemplateVersions-%s-%s", accountID, region, launchTemplateID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]ec2Types.LaunchTemplateVersion), nil
+	}
+	for {
+		DescribeLaunchTemplateVersions, err := client.DescribeLaunchTemplateVersions(
+			context.TODO(),
+			&ec2.DescribeLaunchTemplateVersionsInput{
+				LaunchTemplateId: aws.String(launchTemplateID),
+				NextToken:        PaginationControl,
+			},
+			func(o *ec2.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return versions, err
+		}
+		versions = append(versions, DescribeLaunchTemplateVersions.LaunchTemplateVersions...)
+
+		if DescribeLaunchTemplateVersions.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeLaunchTemplateVersions.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, versions, cache.DefaultExpiration)
+	return versions, nil
+}
+
+func CachedEC2DescribeVpcs(client AWSEC2RoutingClientInterface, accountID string, region string) ([]ec2Types.Vpc, error) {
+	var PaginationControl *string
+	var vpcs []ec2Types.Vpc
+	cacheKey := fmt.Sprintf("%s-ec2-DescribeVpcs-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]ec2Types.Vpc), nil
+	}
+	for {
+		DescribeVpcs, err := client.DescribeVpcs(
+			context.TODO(),
+			&ec2.DescribeVpcsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *ec2.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return vpcs, err
+		}
+		vpcs = append(vpcs, DescribeVpcs.Vpcs...)
+
+		if DescribeVpcs.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeVpcs.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, vpcs, cache.DefaultExpiration)
+	return vpcs, nil
+}
+
+func CachedEC2DescribeRouteTables(client AWSEC2RoutingClientInterface, accountID string, region string) ([]ec2Types.RouteTable, error) {
+	var PaginationControl *string
+	var routeTables []ec2Types.RouteTable
+	cacheKey := fmt.Sprintf("%s-ec2-DescribeRouteTables-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]ec2Types.RouteTable), nil
+	}
+	for {
+		DescribeRouteTables, err := client.DescribeRouteTables(
+			context.TODO(),
+			&ec2.DescribeRouteTablesInput{
+				NextToken: PaginationControl,
+			},
+			func(o *ec2.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return routeTables, err
+		}
+		routeTables = append(routeTables, DescribeRouteTables.RouteTables...)
+
+		if DescribeRouteTables.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeRouteTables.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, routeTables, cache.DefaultExpiration)
+	return routeTables, nil
+}
+
+func CachedEC2DescribeLocalGateways(client AWSEC2RoutingClientInterface, accountID string, region string) ([]ec2Types.LocalGateway, error) {
+	var PaginationControl *string
+	var localGateways []ec2Types.LocalGateway
+	cacheKey := fmt.Sprintf("%s-ec2-DescribeLocalGateways-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]ec2Types.LocalGateway), nil
+	}
+	for {
+		DescribeLocalGateways, err := client.DescribeLocalGateways(
+			context.TODO(),
+			&ec2.DescribeLocalGatewaysInput{
+				NextToken: PaginationControl,
+			},
+			func(o *ec2.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return localGateways, err
+		}
+		localGateways = append(localGateways, DescribeLocalGateways.LocalGateways...)
+
+		if DescribeLocalGateways.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeLocalGateways.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, localGateways, cache.DefaultExpiration)
+	return localGateways, nil
+}
+
+func CachedEC2DescribeLocalGatewayRouteTables(client AWSEC2RoutingClientInterface, accountID string, region string) ([]ec2Types.LocalGatewayRouteTable, error) {
+	var PaginationControl *string
+	var routeTables []ec2Types.LocalGatewayRouteTable
+	cacheKey := fmt.Sprintf("%s-ec2-DescribeLocalGatewayRouteTables-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]ec2Types.LocalGatewayRouteTable), nil
+	}
+	for {
+		DescribeLocalGatewayRouteTables, err := client.DescribeLocalGatewayRouteTables(
+			context.TODO(),
+			&ec2.DescribeLocalGatewayRouteTablesInput{
+				NextToken: PaginationControl,
+			},
+			func(o *ec2.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return routeTables, err
+		}
+		routeTables = append(routeTables, DescribeLocalGatewayRouteTables.LocalGatewayRouteTables...)
+
+		if DescribeLocalGatewayRouteTables.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeLocalGatewayRouteTables.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, routeTables, cache.DefaultExpiration)
+	return routeTables, nil
+}
+
+func CachedEC2DescribeLocalGatewayRouteTableVpcAssociations(client AWSEC2RoutingClientInterface, accountID string, region string) ([]ec2Types.LocalGatewayRouteTableVpcAssociation, error) {
+	var PaginationControl *string
+	var associations []ec2Types.LocalGatewayRouteTableVpcAssociation
+	cacheKey := fmt.Sprintf("%s-ec2-DescribeLocalGatewayRouteTableVpcAssociations-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]ec2Types.LocalGatewayRouteTableVpcAssociation), nil
+	}
+	for {
+		DescribeLocalGatewayRouteTableVpcAssociations, err := client.DescribeLocalGatewayRouteTableVpcAssociations(
+			context.TODO(),
+			&ec2.DescribeLocalGatewayRouteTableVpcAssociationsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *ec2.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return associations, err
+		}
+		associations = append(associations, DescribeLocalGatewayRouteTableVpcAssociations.LocalGatewayRouteTableVpcAssociations...)
+
+		if DescribeLocalGatewayRouteTableVpcAssociations.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeLocalGatewayRouteTableVpcAssociations.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, associations, cache.DefaultExpiration)
+	return associations, nil
+}
+
+func CachedEC2SearchLocalGatewayRoutes(client AWSEC2RoutingClientInterface, accountID string, region string, routeTableID string) ([]ec2Types.LocalGatewayRoute, error) {
+	var PaginationControl *string
+	var routes []ec2Types.LocalGatewayRoute
+	cacheKey := fmt.Sprintf("%s-ec2-SearchLocalGatewayRoutes-%s-%s", accountID, region, routeTableID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]ec2Types.LocalGatewayRoute), nil
+	}
+	for {
+		SearchLocalGatewayRoutes, err := client.SearchLocalGatewayRoutes(
+			context.TODO(),
+			&ec2.SearchLocalGatewayRoutesInput{
+				LocalGatewayRouteTableId: aws.String(routeTableID),
+				NextToken:                PaginationControl,
+			},
+			func(o *ec2.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return routes, err
+		}
+		routes = append(routes, SearchLocalGatewayRoutes.Routes...)
+
+		if SearchLocalGatewayRoutes.NextToken == nil {
+			break
+		}
+		PaginationControl = SearchLocalGatewayRoutes.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, routes, cache.DefaultExpiration)
+	return routes, nil
+}
+
+func CachedEC2DescribeVpcEndpointServiceConfigurations(client AWSEC2EndpointServicesClientInterface, accountID string, region string) ([]ec2Types.ServiceConfiguration, error) {
+	var PaginationControl *string
+	var serviceConfigurations []ec2Types.ServiceConfiguration
+	cacheKey := fmt.Sprintf("%s-ec2-DescribeVpcEndpointServiceConfigurations-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]ec2Types.ServiceConfiguration), nil
+	}
+	for {
+		DescribeVpcEndpointServiceConfigurations, err := client.DescribeVpcEndpointServiceConfigurations(
+			context.TODO(),
+			&ec2.DescribeVpcEndpointServiceConfigurationsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *ec2.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return serviceConfigurations, err
+		}
+		serviceConfigurations = append(serviceConfigurations, DescribeVpcEndpointServiceConfigurations.ServiceConfigurations...)
+
+		if DescribeVpcEndpointServiceConfigurations.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeVpcEndpointServiceConfigurations.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, serviceConfigurations, cache.DefaultExpiration)
+	return serviceConfigurations, nil
+}
+
+func CachedEC2DescribeVpcEndpointServicePermissions(client AWSEC2EndpointServicesClientInterface, accountID string, region string, serviceID string) ([]ec2Types.AllowedPrincipal, error) {
+	var PaginationControl *string
+	var allowedPrincipals []ec2Types.AllowedPrincipal
+	cacheKey := fmt.Sprintf("%s-ec2-DescribeVpcEndpointServicePermissions-%s-%s", accountID, region, serviceID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]ec2Types.AllowedPrincipal), nil
+	}
+	for {
+		DescribeVpcEndpointServicePermissions, err := client.DescribeVpcEndpointServicePermissions(
+			context.TODO(),
+			&ec2.DescribeVpcEndpointServicePermissionsInput{
+				ServiceId: aws.String(serviceID),
+				NextToken: PaginationControl,
+			},
+			func(o *ec2.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return allowedPrincipals, err
+		}
+		allowedPrincipals = append(allowedPrincipals, DescribeVpcEndpointServicePermissions.AllowedPrincipals...)
+
+		if DescribeVpcEndpointServicePermissions.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeVpcEndpointServicePermissions.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, allowedPrincipals, cache.DefaultExpiration)
+	return allowedPrincipals, nil
+}
+
+func CachedEC2DescribeVpcEndpointConnections(client AWSEC2EndpointServicesClientInterface, accountID string, region string) ([]ec2Types.VpcEndpointConnection, error) {
+	var PaginationControl *string
+	var connections []ec2Types.VpcEndpointConnection
+	cacheKey := fmt.Sprintf("%s-ec2-DescribeVpcEndpointConnections-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]ec2Types.VpcEndpointConnection), nil
+	}
+	for {
+		DescribeVpcEndpointConnections, err := client.DescribeVpcEndpointConnections(
+			context.TODO(),
+			&ec2.DescribeVpcEndpointConnectionsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *ec2.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return connections, err
+		}
+		connections = append(connections, DescribeVpcEndpointConnections.VpcEndpointConnections...)
+
+		if DescribeVpcEndpointConnections.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeVpcEndpointConnections.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, connections, cache.DefaultExpiration)
+	return connections, nil
+}
+
+func CachedEC2DescribeVerifiedAccessInstances(client AWSEC2VerifiedAccessClientInterface, accountID string, region string) ([]ec2Types.VerifiedAccessInstance, error) {
+	var PaginationControl *string
+	var instances []ec2Types.VerifiedAccessInstance
+	cacheKey := fmt.Sprintf("%s-ec2-DescribeVerifiedAccessInstances-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]ec2Types.VerifiedAccessInstance), nil
+	}
+	for {
+		DescribeVerifiedAccessInstances, err := client.DescribeVerifiedAccessInstances(
+			context.TODO(),
+			&ec2.DescribeVerifiedAccessInstancesInput{
+				NextToken: PaginationControl,
+			},
+			func(o *ec2.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return instances, err
+		}
+		instances = append(instances, DescribeVerifiedAccessInstances.VerifiedAccessInstances...)
+
+		if DescribeVerifiedAccessInstances.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeVerifiedAccessInstances.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, instances, cache.DefaultExpiration)
+	return instances, nil
+}
+
+func CachedEC2DescribeVerifiedAccessInstanceLoggingConfigurations(client AWSEC2VerifiedAccessClientInterface, accountID string, region string) ([]ec2Types.VerifiedAccessInstanceLoggingConfiguration, error) {
+	var PaginationControl *string
+	var loggingConfigurations []ec2Types.VerifiedAccessInstanceLoggingConfiguration
+	cacheKey := fmt.Sprintf("%s-ec2-DescribeVerifiedAccessInstanceLoggingConfigurations-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]ec2Types.VerifiedAccessInstanceLoggingConfiguration), nil
+	}
+	for {
+		DescribeVerifiedAccessInstanceLoggingConfigurations, err := client.DescribeVerifiedAccessInstanceLoggingConfigurations(
+			context.TODO(),
+			&ec2.DescribeVerifiedAccessInstanceLoggingConfigurationsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *ec2.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return loggingConfigurations, err
+		}
+		loggingConfigurations = append(loggingConfigurations, DescribeVerifiedAccessInstanceLoggingConfigurations.LoggingConfigurations...)
+
+		if DescribeVerifiedAccessInstanceLoggingConfigurations.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeVerifiedAccessInstanceLoggingConfigurations.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, loggingConfigurations, cache.DefaultExpiration)
+	return loggingConfigurations, nil
+}
+
+func CachedEC2DescribeVerifiedAccessEndpoints(client AWSEC2VerifiedAccessClientInterface, accountID string, region string) ([]ec2Types.VerifiedAccessEndpoint, error) {
+	var PaginationControl *string
+	var endpoints []ec2Types.VerifiedAccessEndpoint
+	cacheKey := fmt.Sprintf("%s-ec2-DescribeVerifiedAccessEndpoints-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]ec2Types.VerifiedAccessEndpoint), nil
+	}
+	for {
+		DescribeVerifiedAccessEndpoints, err := client.DescribeVerifiedAccessEndpoints(
+			context.TODO(),
+			&ec2.DescribeVerifiedAccessEndpointsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *ec2.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return endpoints, err
+		}
+		endpoints = append(endpoints, DescribeVerifiedAccessEndpoints.VerifiedAccessEndpoints...)
+
+		if DescribeVerifiedAccessEndpoints.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeVerifiedAccessEndpoints.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, endpoints, cache.DefaultExpiration)
+	return endpoints, nil
+}
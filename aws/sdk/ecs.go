@@ -18,6 +18,7 @@ type AWSECSClientInterface interface {
 	ListServices(ctx context.Context, params *ecs.ListServicesInput, optFns ...func(*ecs.Options)) (*ecs.ListServicesOutput, error)
 	DescribeTasks(ctx context.Context, params *ecs.DescribeTasksInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTasksOutput, error)
 	DescribeTaskDefinition(ctx context.Context, params *ecs.DescribeTaskDefinitionInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTaskDefinitionOutput, error)
+	DescribeServices(ctx context.Context, params *ecs.DescribeServicesInput, optFns ...func(*ecs.Options)) (*ecs.DescribeServicesOutput, error)
 }
 
 func init() {
@@ -25,6 +26,7 @@ func init() {
 	gob.Register(ecsTypes.Task{})
 	gob.Register([]ecsTypes.Task{})
 	gob.Register(ecsTypes.TaskDefinition{})
+	gob.Register([]ecsTypes.Service{})
 
 }
 
@@ -117,7 +119,12 @@ func CachedECSDescribeTasks(ECSClient AWSECSClientInterface, accountID string, r
 	//replace semi-colons with underscores in task definition name
 	clusterFileSystemSafe := strings.ReplaceAll(cluster, ":", "_")
 	clusterFileSystemSafe = strings.ReplaceAll(clusterFileSystemSafe, "/", "_")
-	cacheKey := fmt.Sprintf("%s-ecs-DescribeTasks-%s-%s", accountID, region, clusterFileSystemSafe)
+	// DescribeTasks is called in batches of up to 100 tasks, so the first task and the batch size identify the batch
+	var firstTask string
+	if len(tasks) > 0 {
+		firstTask = tasks[0][strings.LastIndex(tasks[0], "/")+1:]
+	}
+	cacheKey := fmt.Sprintf("%s-ecs-DescribeTasks-%s-%s-%s-%d", accountID, region, clusterFileSystemSafe, firstTask, len(tasks))
 	cached, found := internal.Cache.Get(cacheKey)
 	if found {
 		sharedLogger.Debug("Using cached ECS task details data")
@@ -220,3 +227,45 @@ func CachedECSListServices(ECSClient AWSECSClientInterface, accountID string, re
 	internal.Cache.Set(cacheKey, services, cache.DefaultExpiration)
 	return services, nil
 }
+
+// CachedECSDescribeServices describes the services of a cluster. DescribeServices takes at most 10 services per call,
+// so the services are described in batches.
+func CachedECSDescribeServices(ECSClient AWSECSClientInterface, accountID string, region string, cluster string, services []string) ([]ecsTypes.Service, error) {
+	var serviceDetails []ecsTypes.Service
+	clusterName := cluster[strings.LastIndex(cluster, "/")+1:]
+
+	cacheKey := fmt.Sprintf("%s-ecs-DescribeServices-%s-%s", accountID, region, clusterName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		sharedLogger.Debug("Using cached ECS service details data")
+		return cached.([]ecsTypes.Service), nil
+	}
+
+	batchSize := 10 // maximum value: https://docs.aws.amazon.com/AmazonECS/latest/APIReference/API_DescribeServices.html#API_DescribeServices_RequestSyntax
+	for i := 0; i < len(services); i += batchSize {
+		j := i + batchSize
+		if j > len(services) {
+			j = len(services)
+		}
+
+		DescribeServices, err := ECSClient.DescribeServices(
+			context.TODO(),
+			&ecs.DescribeServicesInput{
+				Cluster:  &cluster,
+				Services: services[i:j],
+			},
+			func(o *ecs.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			sharedLogger.Error(err.Error())
+			return serviceDetails, err
+		}
+
+		serviceDetails = append(serviceDetails, DescribeServices.Services...)
+	}
+
+	internal.Cache.Set(cacheKey, serviceDetails, cache.DefaultExpiration)
+	return serviceDetails, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
@@ -154,3 +155,18 @@ func (c *MockedECSClient) DescribeTaskDefinition(ctx context.Context, input *ecs
 	testTaskDefinition.TaskRoleArn = aws.String("test123")
 	return &ecs.DescribeTaskDefinitionOutput{TaskDefinition: &testTaskDefinition}, nil
 }
+
+func (c *MockedECSClient) DescribeServices(ctx context.Context, input *ecs.DescribeServicesInput, f ...func(o *ecs.Options)) (*ecs.DescribeServicesOutput, error) {
+	var services []ecsTypes.Service
+	for _, service := range input.Services {
+		services = append(services, ecsTypes.Service{
+			ServiceArn:     aws.String(service),
+			ServiceName:    aws.String(service[strings.LastIndex(service, "/")+1:]),
+			ClusterArn:     input.Cluster,
+			LaunchType:     ecsTypes.LaunchTypeFargate,
+			RunningCount:   2,
+			TaskDefinition: aws.String("arn:aws:ecs:us-east-1:123456789012:task-definition/webserver:2"),
+		})
+	}
+	return &ecs.DescribeServicesOutput{Services: services}, nil
+}
@@ -20,7 +20,15 @@ type AWSEFSClientInterface interface {
 	DescribeFileSystemPolicy(ctx context.Context, params *efs.DescribeFileSystemPolicyInput, optFns ...func(*efs.Options)) (*efs.DescribeFileSystemPolicyOutput, error)
 }
 
+// AWSEFSConfigClientInterface covers the lifecycle and mount target security group calls. It is separate from
+// AWSEFSClientInterface so the existing modules don't have to be given more than they use.
+type AWSEFSConfigClientInterface interface {
+	DescribeLifecycleConfiguration(ctx context.Context, params *efs.DescribeLifecycleConfigurationInput, optFns ...func(*efs.Options)) (*efs.DescribeLifecycleConfigurationOutput, error)
+	DescribeMountTargetSecurityGroups(ctx context.Context, params *efs.DescribeMountTargetSecurityGroupsInput, optFns ...func(*efs.Options)) (*efs.DescribeMountTargetSecurityGroupsOutput, error)
+}
+
 func init() {
+	gob.Register([]efsTypes.LifecyclePolicy{})
 	gob.Register([]efsTypes.FileSystemDescription{})
 	gob.Register([]efsTypes.MountTargetDescription{})
 	gob.Register([]efsTypes.AccessPointDescription{})
@@ -176,3 +184,53 @@ func CachedDescribeFileSystemPolicy(EFSClient AWSEFSClientInterface, filesystemI
 	internal.Cache.Set(cacheKey, efsPolicy, cache.DefaultExpiration)
 	return efsPolicy, nil
 }
+
+func CachedDescribeLifecycleConfiguration(EFSClient AWSEFSConfigClientInterface, accountID string, r string, filesystemId string) ([]efsTypes.LifecyclePolicy, error) {
+	cacheKey := fmt.Sprintf("%s-efs-DescribeLifecycleConfiguration-%s-%s", accountID, r, filesystemId)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]efsTypes.LifecyclePolicy), nil
+	}
+
+	DescribeLifecycleConfiguration, err := EFSClient.DescribeLifecycleConfiguration(
+		context.TODO(),
+		&efs.DescribeLifecycleConfigurationInput{
+			FileSystemId: aws.String(filesystemId),
+		},
+		func(o *efs.Options) {
+			o.Region = r
+		},
+	)
+	if err != nil {
+		sharedLogger.Error(err.Error())
+		return nil, err
+	}
+
+	internal.Cache.Set(cacheKey, DescribeLifecycleConfiguration.LifecyclePolicies, cache.DefaultExpiration)
+	return DescribeLifecycleConfiguration.LifecyclePolicies, nil
+}
+
+func CachedDescribeMountTargetSecurityGroups(EFSClient AWSEFSConfigClientInterface, accountID string, r string, mountTargetId string) ([]string, error) {
+	cacheKey := fmt.Sprintf("%s-efs-DescribeMountTargetSecurityGroups-%s-%s", accountID, r, mountTargetId)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]string), nil
+	}
+
+	DescribeMountTargetSecurityGroups, err := EFSClient.DescribeMountTargetSecurityGroups(
+		context.TODO(),
+		&efs.DescribeMountTargetSecurityGroupsInput{
+			MountTargetId: aws.String(mountTargetId),
+		},
+		func(o *efs.Options) {
+			o.Region = r
+		},
+	)
+	if err != nil {
+		sharedLogger.Error(err.Error())
+		return nil, err
+	}
+
+	internal.Cache.Set(cacheKey, DescribeMountTargetSecurityGroups.SecurityGroups, cache.DefaultExpiration)
+	return DescribeMountTargetSecurityGroups.SecurityGroups, nil
+}
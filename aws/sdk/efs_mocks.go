@@ -15,17 +15,24 @@ func (m *MockedEfsClient) DescribeFileSystems(ctx context.Context, input *efs.De
 	return &efs.DescribeFileSystemsOutput{
 		FileSystems: []efsTypes.FileSystemDescription{
 			{
-				FileSystemId: aws.String("fs-12345678"),
+				FileSystemId:  aws.String("fs-12345678"),
+				FileSystemArn: aws.String("arn:aws:elasticfilesystem:us-east-1:123456789012:file-system/fs-12345678"),
+				Name:          aws.String("app-shared"),
+				Encrypted:     aws.Bool(true),
+				KmsKeyId:      aws.String("arn:aws:kms:us-east-1:123456789012:key/11111111-2222-3333-4444-555555555555"),
 			},
 			{
-				FileSystemId: aws.String("fs-87654321"),
+				FileSystemId:  aws.String("fs-87654321"),
+				FileSystemArn: aws.String("arn:aws:elasticfilesystem:us-east-1:123456789012:file-system/fs-87654321"),
+				Name:          aws.String("scratch"),
+				Encrypted:     aws.Bool(false),
 			},
 		},
 	}, nil
 }
 
 func (m *MockedEfsClient) DescribeMountTargets(ctx context.Context, input *efs.DescribeMountTargetsInput, options ...func(*efs.Options)) (*efs.DescribeMountTargetsOutput, error) {
-	return &efs.DescribeMountTargetsOutput{
+	output := &efs.DescribeMountTargetsOutput{
 		MountTargets: []efsTypes.MountTargetDescription{
 			{
 				MountTargetId: aws.String("fsmt-12345678"),
@@ -38,7 +45,17 @@ func (m *MockedEfsClient) DescribeMountTargets(ctx context.Context, input *efs.D
 				IpAddress:     aws.String("10.2.2.2.2"),
 			},
 		},
-	}, nil
+	}
+	if input.FileSystemId != nil {
+		var mountTargets []efsTypes.MountTargetDescription
+		for _, mountTarget := range output.MountTargets {
+			if aws.ToString(mountTarget.FileSystemId) == aws.ToString(input.FileSystemId) {
+				mountTargets = append(mountTargets, mountTarget)
+			}
+		}
+		output.MountTargets = mountTargets
+	}
+	return output, nil
 }
 
 func (m *MockedEfsClient) DescribeAccessPoints(ctx context.Context, input *efs.DescribeAccessPointsInput, options ...func(*efs.Options)) (*efs.DescribeAccessPointsOutput, error) {
@@ -65,3 +82,31 @@ func (m *MockedEfsClient) DescribeAccessPoints(ctx context.Context, input *efs.D
 		},
 	}, nil
 }
+
+func (m *MockedEfsClient) DescribeFileSystemPolicy(ctx context.Context, input *efs.DescribeFileSystemPolicyInput, options ...func(*efs.Options)) (*efs.DescribeFileSystemPolicyOutput, error) {
+	return &efs.DescribeFileSystemPolicyOutput{
+		FileSystemId: input.FileSystemId,
+		Policy:       aws.String(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::444455556666:role/backup"},"Action":["elasticfilesystem:ClientMount","elasticfilesystem:ClientWrite"],"Resource":"*","Condition":{"Bool":{"aws:SecureTransport":"true"}}}]}`),
+	}, nil
+}
+
+// fs-12345678 moves files to infrequent access after 30 days, fs-87654321 has no lifecycle management
+func (m *MockedEfsClient) DescribeLifecycleConfiguration(ctx context.Context, input *efs.DescribeLifecycleConfigurationInput, options ...func(*efs.Options)) (*efs.DescribeLifecycleConfigurationOutput, error) {
+	if aws.ToString(input.FileSystemId) == "fs-12345678" {
+		return &efs.DescribeLifecycleConfigurationOutput{
+			LifecyclePolicies: []efsTypes.LifecyclePolicy{
+				{TransitionToIA: efsTypes.TransitionToIARulesAfter30Days},
+			},
+		}, nil
+	}
+	return &efs.DescribeLifecycleConfigurationOutput{}, nil
+}
+
+// The mount target of fs-12345678 only lets in the VPC, the one of fs-87654321 is in sg-0e0e0e0e0e0e0e0e0 of the
+// MockedEC2SecurityGroupsClient, which lets in NFS from anywhere
+func (m *MockedEfsClient) DescribeMountTargetSecurityGroups(ctx context.Context, input *efs.DescribeMountTargetSecurityGroupsInput, options ...func(*efs.Options)) (*efs.DescribeMountTargetSecurityGroupsOutput, error) {
+	if aws.ToString(input.MountTargetId) == "fsmt-87654321" {
+		return &efs.DescribeMountTargetSecurityGroupsOutput{SecurityGroups: []string{"sg-0e0e0e0e0e0e0e0e0"}}, nil
+	}
+	return &efs.DescribeMountTargetSecurityGroupsOutput{SecurityGroups: []string{"sg-0a0a0a0a0a0a0a0a0"}}, nil
+}
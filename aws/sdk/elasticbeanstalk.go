@@ -3,17 +3,24 @@ package sdk
 import (
 	"context"
 	"encoding/gob"
+	"fmt"
 
+	"github.com/BishopFox/cloudfox/internal"
 	"github.com/aws/aws-sdk-go-v2/service/elasticbeanstalk"
 	elasticbeanstalkTypes "github.com/aws/aws-sdk-go-v2/service/elasticbeanstalk/types"
+	"github.com/patrickmn/go-cache"
 )
 
 type AWSElasticBeanstalkClientInterface interface {
 	DescribeApplications(context.Context, *elasticbeanstalk.DescribeApplicationsInput, ...func(*elasticbeanstalk.Options)) (*elasticbeanstalk.DescribeApplicationsOutput, error)
+	DescribeEnvironments(context.Context, *elasticbeanstalk.DescribeEnvironmentsInput, ...func(*elasticbeanstalk.Options)) (*elasticbeanstalk.DescribeEnvironmentsOutput, error)
+	DescribeConfigurationSettings(context.Context, *elasticbeanstalk.DescribeConfigurationSettingsInput, ...func(*elasticbeanstalk.Options)) (*elasticbeanstalk.DescribeConfigurationSettingsOutput, error)
 }
 
 func init() {
 	gob.Register([]elasticbeanstalkTypes.ApplicationDescription{})
+	gob.Register([]elasticbeanstalkTypes.EnvironmentDescription{})
+	gob.Register([]elasticbeanstalkTypes.ConfigurationSettingsDescription{})
 }
 
 func CachedElasticBeanstalkDescribeApplications(client AWSElasticBeanstalkClientInterface, accountID string, region string) ([]elasticbeanstalkTypes.ApplicationDescription, error) {
@@ -33,3 +40,66 @@ func CachedElasticBeanstalkDescribeApplications(client AWSElasticBeanstalkClient
 	applications = append(applications, DescribeApplications.Applications...)
 	return applications, nil
 }
+
+func CachedElasticBeanstalkDescribeEnvironments(client AWSElasticBeanstalkClientInterface, accountID string, region string) ([]elasticbeanstalkTypes.EnvironmentDescription, error) {
+	var environments []elasticbeanstalkTypes.EnvironmentDescription
+	cacheKey := fmt.Sprintf("%s-elasticbeanstalk-DescribeEnvironments-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]elasticbeanstalkTypes.EnvironmentDescription), nil
+	}
+	var PaginationControl *string
+	for {
+		DescribeEnvironments, err := client.DescribeEnvironments(
+			context.TODO(),
+			&elasticbeanstalk.DescribeEnvironmentsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *elasticbeanstalk.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return environments, err
+		}
+
+		environments = append(environments, DescribeEnvironments.Environments...)
+
+		// Pagination control. After the last page of output, the for loop exits.
+		if DescribeEnvironments.NextToken != nil {
+			PaginationControl = DescribeEnvironments.NextToken
+		} else {
+			PaginationControl = nil
+			break
+		}
+	}
+
+	internal.Cache.Set(cacheKey, environments, cache.DefaultExpiration)
+	return environments, nil
+}
+
+func CachedElasticBeanstalkDescribeConfigurationSettings(client AWSElasticBeanstalkClientInterface, accountID string, region string, applicationName string, environmentName string) ([]elasticbeanstalkTypes.ConfigurationSettingsDescription, error) {
+	var settings []elasticbeanstalkTypes.ConfigurationSettingsDescription
+	cacheKey := fmt.Sprintf("%s-elasticbeanstalk-DescribeConfigurationSettings-%s-%s-%s", accountID, region, applicationName, environmentName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]elasticbeanstalkTypes.ConfigurationSettingsDescription), nil
+	}
+	DescribeConfigurationSettings, err := client.DescribeConfigurationSettings(
+		context.TODO(),
+		&elasticbeanstalk.DescribeConfigurationSettingsInput{
+			ApplicationName: &applicationName,
+			EnvironmentName: &environmentName,
+		},
+		func(o *elasticbeanstalk.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return settings, err
+	}
+
+	settings = append(settings, DescribeConfigurationSettings.ConfigurationSettings...)
+	internal.Cache.Set(cacheKey, settings, cache.DefaultExpiration)
+	return settings, nil
+}
@@ -31,3 +31,70 @@ func (m *MockedElasticBeanstalkClient) DescribeApplications(ctx context.Context,
 		},
 	}, nil
 }
+
+func (m *MockedElasticBeanstalkClient) DescribeEnvironments(ctx context.Context, input *elasticbeanstalk.DescribeEnvironmentsInput, options ...func(*elasticbeanstalk.Options)) (*elasticbeanstalk.DescribeEnvironmentsOutput, error) {
+	return &elasticbeanstalk.DescribeEnvironmentsOutput{
+		Environments: []elasticbeanstalkTypes.EnvironmentDescription{
+			{
+				EnvironmentName:   aws.String("app1-prod"),
+				ApplicationName:   aws.String("app1"),
+				CNAME:             aws.String("app1-prod.us-east-1.elasticbeanstalk.com"),
+				EndpointURL:       aws.String("awseb-e-a-AWSEBLoa-1234567890.us-east-1.elb.amazonaws.com"),
+				Status:            elasticbeanstalkTypes.EnvironmentStatusReady,
+				SolutionStackName: aws.String("64bit Amazon Linux 2023 v6.1.0 running Node.js 20"),
+			},
+			{
+				EnvironmentName:   aws.String("app2-worker"),
+				ApplicationName:   aws.String("app2"),
+				Status:            elasticbeanstalkTypes.EnvironmentStatusReady,
+				SolutionStackName: aws.String("64bit Amazon Linux 2023 v4.0.0 running Python 3.11"),
+			},
+		},
+	}, nil
+}
+
+// app1-prod has credentials in its environment properties, app2-worker doesn't
+func (m *MockedElasticBeanstalkClient) DescribeConfigurationSettings(ctx context.Context, input *elasticbeanstalk.DescribeConfigurationSettingsInput, options ...func(*elasticbeanstalk.Options)) (*elasticbeanstalk.DescribeConfigurationSettingsOutput, error) {
+	environmentNamespace := "aws:elasticbeanstalk:application:environment"
+	optionSettings := []elasticbeanstalkTypes.ConfigurationOptionSetting{
+		{
+			Namespace:  aws.String("aws:autoscaling:launchconfiguration"),
+			OptionName: aws.String("IamInstanceProfile"),
+			Value:      aws.String("aws-elasticbeanstalk-ec2-role"),
+		},
+		{
+			Namespace:  aws.String(environmentNamespace),
+			OptionName: aws.String("LOG_LEVEL"),
+			Value:      aws.String("info"),
+		},
+	}
+	if aws.ToString(input.EnvironmentName) == "app1-prod" {
+		optionSettings = append(optionSettings,
+			elasticbeanstalkTypes.ConfigurationOptionSetting{
+				Namespace:  aws.String(environmentNamespace),
+				OptionName: aws.String("DB_PASSWORD"),
+				Value:      aws.String("hunter2"),
+			},
+			elasticbeanstalkTypes.ConfigurationOptionSetting{
+				Namespace:  aws.String(environmentNamespace),
+				OptionName: aws.String("STRIPE_API_KEY"),
+				Value:      aws.String("sk_live_0123456789"),
+			},
+			// Empty values are placeholders, not secrets
+			elasticbeanstalkTypes.ConfigurationOptionSetting{
+				Namespace:  aws.String(environmentNamespace),
+				OptionName: aws.String("GITHUB_TOKEN"),
+				Value:      aws.String(""),
+			},
+		)
+	}
+	return &elasticbeanstalk.DescribeConfigurationSettingsOutput{
+		ConfigurationSettings: []elasticbeanstalkTypes.ConfigurationSettingsDescription{
+			{
+				ApplicationName: input.ApplicationName,
+				EnvironmentName: input.EnvironmentName,
+				OptionSettings:  optionSettings,
+			},
+		},
+	}, nil
+}
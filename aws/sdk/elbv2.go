@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
 	elbV2Types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
 	"github.com/patrickmn/go-cache"
@@ -13,10 +14,14 @@ import (
 
 type ELBv2ClientInterface interface {
 	DescribeLoadBalancers(context.Context, *elasticloadbalancingv2.DescribeLoadBalancersInput, ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeLoadBalancersOutput, error)
+	DescribeListeners(context.Context, *elasticloadbalancingv2.DescribeListenersInput, ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeListenersOutput, error)
+	DescribeRules(context.Context, *elasticloadbalancingv2.DescribeRulesInput, ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeRulesOutput, error)
 }
 
 func init() {
 	gob.Register([]elbV2Types.LoadBalancer{})
+	gob.Register([]elbV2Types.Listener{})
+	gob.Register([]elbV2Types.Rule{})
 }
 
 func CachedELBv2DescribeLoadBalancers(client ELBv2ClientInterface, accountID string, region string) ([]elbV2Types.LoadBalancer, error) {
@@ -54,3 +59,75 @@ func CachedELBv2DescribeLoadBalancers(client ELBv2ClientInterface, accountID str
 	internal.Cache.Set(cacheKey, loadbalancers, cache.DefaultExpiration)
 	return loadbalancers, nil
 }
+
+func CachedELBv2DescribeListeners(client ELBv2ClientInterface, accountID string, region string, loadBalancerArn string) ([]elbV2Types.Listener, error) {
+	var PaginationControl *string
+	var listeners []elbV2Types.Listener
+	cacheKey := fmt.Sprintf("%s-elbv2-DescribeListeners-%s-%s", accountID, region, loadBalancerArn)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]elbV2Types.Listener), nil
+	}
+	for {
+		DescribeListeners, err := client.DescribeListeners(
+			context.TODO(),
+			&elasticloadbalancingv2.DescribeListenersInput{
+				LoadBalancerArn: aws.String(loadBalancerArn),
+				Marker:          PaginationControl,
+			},
+			func(o *elasticloadbalancingv2.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return listeners, err
+		}
+
+		listeners = append(listeners, DescribeListeners.Listeners...)
+
+		//pagination
+		if DescribeListeners.NextMarker == nil {
+			break
+		}
+		PaginationControl = DescribeListeners.NextMarker
+	}
+
+	internal.Cache.Set(cacheKey, listeners, cache.DefaultExpiration)
+	return listeners, nil
+}
+
+func CachedELBv2DescribeRules(client ELBv2ClientInterface, accountID string, region string, listenerArn string) ([]elbV2Types.Rule, error) {
+	var PaginationControl *string
+	var rules []elbV2Types.Rule
+	cacheKey := fmt.Sprintf("%s-elbv2-DescribeRules-%s-%s", accountID, region, listenerArn)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]elbV2Types.Rule), nil
+	}
+	for {
+		DescribeRules, err := client.DescribeRules(
+			context.TODO(),
+			&elasticloadbalancingv2.DescribeRulesInput{
+				ListenerArn: aws.String(listenerArn),
+				Marker:      PaginationControl,
+			},
+			func(o *elasticloadbalancingv2.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return rules, err
+		}
+
+		rules = append(rules, DescribeRules.Rules...)
+
+		//pagination
+		if DescribeRules.NextMarker == nil {
+			break
+		}
+		PaginationControl = DescribeRules.NextMarker
+	}
+
+	internal.Cache.Set(cacheKey, rules, cache.DefaultExpiration)
+	return rules, nil
+}
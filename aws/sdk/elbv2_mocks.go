@@ -56,3 +56,97 @@ func (m *MockedElbv2Client) DescribeLoadBalancers(ctx context.Context, input *el
 		},
 	}, nil
 }
+
+func (m *MockedElbv2Client) DescribeListeners(ctx context.Context, input *elasticloadbalancingv2.DescribeListenersInput, options ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeListenersOutput, error) {
+	return &elasticloadbalancingv2.DescribeListenersOutput{
+		Listeners: []elbv2Types.Listener{
+			{
+				ListenerArn:     aws.String("arn:aws:elasticloadbalancing:us-east-1:123456789012:listener/app/my-load-balancer/50dc6c495c0c9188/f2f7dc8efc522ab2"),
+				LoadBalancerArn: input.LoadBalancerArn,
+				Port:            aws.Int32(443),
+				Protocol:        elbv2Types.ProtocolEnumHttps,
+			},
+		},
+	}, nil
+}
+
+func (m *MockedElbv2Client) DescribeRules(ctx context.Context, input *elasticloadbalancingv2.DescribeRulesInput, options ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeRulesOutput, error) {
+	forward := func(targetGroup string) elbv2Types.Action {
+		return elbv2Types.Action{
+			Type:           elbv2Types.ActionTypeEnumForward,
+			Order:          aws.Int32(2),
+			TargetGroupArn: aws.String("arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/" + targetGroup + "/73e2d6bc24d8a067"),
+		}
+	}
+	pathPattern := func(values ...string) elbv2Types.RuleCondition {
+		return elbv2Types.RuleCondition{
+			Field:             aws.String("path-pattern"),
+			PathPatternConfig: &elbv2Types.PathPatternConditionConfig{Values: values},
+		}
+	}
+	hostHeader := func(values ...string) elbv2Types.RuleCondition {
+		return elbv2Types.RuleCondition{
+			Field:            aws.String("host-header"),
+			HostHeaderConfig: &elbv2Types.HostHeaderConditionConfig{Values: values},
+		}
+	}
+	authenticateOidc := elbv2Types.Action{
+		Type:  elbv2Types.ActionTypeEnumAuthenticateOidc,
+		Order: aws.Int32(1),
+		AuthenticateOidcConfig: &elbv2Types.AuthenticateOidcActionConfig{
+			Issuer:   aws.String("https://login.example.com"),
+			ClientId: aws.String("cloudfox"),
+		},
+	}
+	authenticateCognito := elbv2Types.Action{
+		Type:  elbv2Types.ActionTypeEnumAuthenticateCognito,
+		Order: aws.Int32(1),
+		AuthenticateCognitoConfig: &elbv2Types.AuthenticateCognitoActionConfig{
+			UserPoolArn:      aws.String("arn:aws:cognito-idp:us-east-1:123456789012:userpool/us-east-1_EXAMPLE"),
+			UserPoolClientId: aws.String("cloudfox"),
+			UserPoolDomain:   aws.String("cloudfox"),
+		},
+	}
+	// Returned out of priority order on purpose
+	return &elasticloadbalancingv2.DescribeRulesOutput{
+		Rules: []elbv2Types.Rule{
+			{
+				RuleArn:    aws.String("arn:aws:elasticloadbalancing:us-east-1:123456789012:listener-rule/app/my-load-balancer/50dc6c495c0c9188/f2f7dc8efc522ab2/9683b2d02a6cabee"),
+				Priority:   aws.String("default"),
+				IsDefault:  aws.Bool(true),
+				Conditions: []elbv2Types.RuleCondition{},
+				Actions:    []elbv2Types.Action{forward("web")},
+			},
+			{
+				RuleArn:    aws.String("arn:aws:elasticloadbalancing:us-east-1:123456789012:listener-rule/app/my-load-balancer/50dc6c495c0c9188/f2f7dc8efc522ab2/10a6cd7ab1b0dc6c"),
+				Priority:   aws.String("10"),
+				Conditions: []elbv2Types.RuleCondition{pathPattern("/api/admin/*")},
+				Actions:    []elbv2Types.Action{authenticateOidc, forward("admin")},
+			},
+			{
+				RuleArn:    aws.String("arn:aws:elasticloadbalancing:us-east-1:123456789012:listener-rule/app/my-load-balancer/50dc6c495c0c9188/f2f7dc8efc522ab2/5f1b2c3d4e5f6a7b"),
+				Priority:   aws.String("5"),
+				Conditions: []elbv2Types.RuleCondition{pathPattern("/api/*")},
+				Actions:    []elbv2Types.Action{forward("api")},
+			},
+			{
+				RuleArn:    aws.String("arn:aws:elasticloadbalancing:us-east-1:123456789012:listener-rule/app/my-load-balancer/50dc6c495c0c9188/f2f7dc8efc522ab2/20c4e7d1a9b3f5e2"),
+				Priority:   aws.String("20"),
+				Conditions: []elbv2Types.RuleCondition{pathPattern("/reports/*")},
+				Actions:    []elbv2Types.Action{authenticateCognito, forward("reports")},
+			},
+			{
+				RuleArn:    aws.String("arn:aws:elasticloadbalancing:us-east-1:123456789012:listener-rule/app/my-load-balancer/50dc6c495c0c9188/f2f7dc8efc522ab2/30e8b6a2c4d1f7a9"),
+				Priority:   aws.String("30"),
+				Conditions: []elbv2Types.RuleCondition{hostHeader("internal.example.com"), pathPattern("/console/*")},
+				Actions:    []elbv2Types.Action{forward("console")},
+			},
+			{
+				RuleArn:    aws.String("arn:aws:elasticloadbalancing:us-east-1:123456789012:listener-rule/app/my-load-balancer/50dc6c495c0c9188/f2f7dc8efc522ab2/40d2f9b7e1c3a5b8"),
+				Priority:   aws.String("40"),
+				Conditions: []elbv2Types.RuleCondition{hostHeader("app.example.com"), pathPattern("/console/*")},
+				Actions:    []elbv2Types.Action{authenticateOidc, forward("console")},
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,138 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	eventbridgeTypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type EventBridgeClientInterface interface {
+	ListEventBuses(context.Context, *eventbridge.ListEventBusesInput, ...func(*eventbridge.Options)) (*eventbridge.ListEventBusesOutput, error)
+	ListRules(context.Context, *eventbridge.ListRulesInput, ...func(*eventbridge.Options)) (*eventbridge.ListRulesOutput, error)
+	ListTargetsByRule(context.Context, *eventbridge.ListTargetsByRuleInput, ...func(*eventbridge.Options)) (*eventbridge.ListTargetsByRuleOutput, error)
+}
+
+func init() {
+	gob.Register([]eventbridgeTypes.EventBus{})
+	gob.Register([]eventbridgeTypes.Rule{})
+	gob.Register([]eventbridgeTypes.Target{})
+}
+
+// CachedEventBridgeListEventBuses lists the event buses of a region, including the default bus. The resource policy of
+// each bus comes with it.
+func CachedEventBridgeListEventBuses(client EventBridgeClientInterface, accountID string, region string) ([]eventbridgeTypes.EventBus, error) {
+	var PaginationControl *string
+	var buses []eventbridgeTypes.EventBus
+	cacheKey := fmt.Sprintf("%s-eventbridge-ListEventBuses-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]eventbridgeTypes.EventBus), nil
+	}
+
+	for {
+		ListEventBuses, err := client.ListEventBuses(
+			context.TODO(),
+			&eventbridge.ListEventBusesInput{
+				NextToken: PaginationControl,
+			},
+			func(o *eventbridge.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return buses, err
+		}
+
+		buses = append(buses, ListEventBuses.EventBuses...)
+
+		//pagination
+		if ListEventBuses.NextToken == nil {
+			break
+		}
+		PaginationControl = ListEventBuses.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, buses, cache.DefaultExpiration)
+	return buses, nil
+}
+
+func CachedEventBridgeListRules(client EventBridgeClientInterface, accountID string, region string, eventBusName string) ([]eventbridgeTypes.Rule, error) {
+	var PaginationControl *string
+	var rules []eventbridgeTypes.Rule
+	cacheKey := fmt.Sprintf("%s-eventbridge-ListRules-%s-%s", accountID, region, eventBusName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]eventbridgeTypes.Rule), nil
+	}
+
+	for {
+		ListRules, err := client.ListRules(
+			context.TODO(),
+			&eventbridge.ListRulesInput{
+				EventBusName: aws.String(eventBusName),
+				NextToken:    PaginationControl,
+			},
+			func(o *eventbridge.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return rules, err
+		}
+
+		rules = append(rules, ListRules.Rules...)
+
+		//pagination
+		if ListRules.NextToken == nil {
+			break
+		}
+		PaginationControl = ListRules.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, rules, cache.DefaultExpiration)
+	return rules, nil
+}
+
+func CachedEventBridgeListTargetsByRule(client EventBridgeClientInterface, accountID string, region string, eventBusName string, ruleName string) ([]eventbridgeTypes.Target, error) {
+	var PaginationControl *string
+	var targets []eventbridgeTypes.Target
+	cacheKey := fmt.Sprintf("%s-eventbridge-ListTargetsByRule-%s-%s-%s", accountID, region, eventBusName, ruleName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]eventbridgeTypes.Target), nil
+	}
+
+	for {
+		ListTargetsByRule, err := client.ListTargetsByRule(
+			context.TODO(),
+			&eventbridge.ListTargetsByRuleInput{
+				EventBusName: aws.String(eventBusName),
+				Rule:         aws.String(ruleName),
+				NextToken:    PaginationControl,
+			},
+			func(o *eventbridge.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return targets, err
+		}
+
+		targets = append(targets, ListTargetsByRule.Targets...)
+
+		//pagination
+		if ListTargetsByRule.NextToken == nil {
+			break
+		}
+		PaginationControl = ListTargetsByRule.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, targets, cache.DefaultExpiration)
+	return targets, nil
+}
@@ -0,0 +1,118 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	eventbridgeTypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+)
+
+type MockedEventBridgeClient struct {
+}
+
+// The default bus takes events from a partner account, orders takes them from the whole organization and audit has no
+// resource policy
+var mockedEventBuses = []eventbridgeTypes.EventBus{
+	{
+		Name: aws.String("default"),
+		Arn:  aws.String("arn:aws:events:us-east-1:123456789012:event-bus/default"),
+		Policy: aws.String(`{
+			"Version": "2012-10-17",
+			"Statement": [
+				{
+					"Sid": "partner-events",
+					"Effect": "Allow",
+					"Principal": {"AWS": "arn:aws:iam::111122223333:root"},
+					"Action": "events:PutEvents",
+					"Resource": "arn:aws:events:us-east-1:123456789012:event-bus/default"
+				}
+			]
+		}`),
+	},
+	{
+		Name: aws.String("orders"),
+		Arn:  aws.String("arn:aws:events:us-east-1:123456789012:event-bus/orders"),
+		Policy: aws.String(`{
+			"Version": "2012-10-17",
+			"Statement": [
+				{
+					"Sid": "organization-events",
+					"Effect": "Allow",
+					"Principal": "*",
+					"Action": ["events:PutEvents", "events:PutRule"],
+					"Resource": "arn:aws:events:us-east-1:123456789012:event-bus/orders",
+					"Condition": {"StringEquals": {"aws:PrincipalOrgID": "o-a1b2c3d4e5"}}
+				}
+			]
+		}`),
+	},
+	{
+		Name: aws.String("audit"),
+		Arn:  aws.String("arn:aws:events:us-east-1:123456789012:event-bus/audit"),
+	},
+}
+
+// nightly-cleanup runs a function, ec2-state-to-security forwards EC2 events to a security account and order-created
+// starts the order workflow
+var mockedEventBridgeRules = map[string][]eventbridgeTypes.Rule{
+	"default": {
+		{
+			Name:               aws.String("nightly-cleanup"),
+			Arn:                aws.String("arn:aws:events:us-east-1:123456789012:rule/nightly-cleanup"),
+			EventBusName:       aws.String("default"),
+			ScheduleExpression: aws.String("rate(1 day)"),
+			State:              eventbridgeTypes.RuleStateEnabled,
+		},
+		{
+			Name:         aws.String("ec2-state-to-security"),
+			Arn:          aws.String("arn:aws:events:us-east-1:123456789012:rule/ec2-state-to-security"),
+			EventBusName: aws.String("default"),
+			EventPattern: aws.String(`{"source":["aws.ec2"],"detail-type":["EC2 Instance State-change Notification"]}`),
+			State:        eventbridgeTypes.RuleStateEnabled,
+		},
+	},
+	"orders": {
+		{
+			Name:         aws.String("order-created"),
+			Arn:          aws.String("arn:aws:events:us-east-1:123456789012:rule/orders/order-created"),
+			EventBusName: aws.String("orders"),
+			EventPattern: aws.String(`{"source":["com.shop.orders"],"detail-type":["OrderCreated"],"detail":{"total":[{"numeric":[">",0]}]}}`),
+			State:        eventbridgeTypes.RuleStateEnabled,
+		},
+	},
+}
+
+var mockedEventBridgeTargets = map[string][]eventbridgeTypes.Target{
+	"nightly-cleanup": {
+		{Id: aws.String("cleanup"), Arn: aws.String("arn:aws:lambda:us-east-1:123456789012:function:cleanup")},
+	},
+	"ec2-state-to-security": {
+		{
+			Id:      aws.String("security-central"),
+			Arn:     aws.String("arn:aws:events:us-east-1:999988887777:event-bus/security-central"),
+			RoleArn: aws.String("arn:aws:iam::123456789012:role/eventbridge-forwarder"),
+		},
+		{Id: aws.String("ops-alerts"), Arn: aws.String("arn:aws:sns:us-east-1:123456789012:ops-alerts")},
+	},
+	"order-created": {
+		{Id: aws.String("queue"), Arn: aws.String("arn:aws:sqs:us-east-1:123456789012:order-processing")},
+		{
+			Id:      aws.String("workflow"),
+			Arn:     aws.String("arn:aws:states:us-east-1:123456789012:stateMachine:fulfil-order"),
+			RoleArn: aws.String("arn:aws:iam::123456789012:role/eventbridge-start-fulfilment"),
+		},
+	},
+}
+
+func (m *MockedEventBridgeClient) ListEventBuses(ctx context.Context, input *eventbridge.ListEventBusesInput, options ...func(*eventbridge.Options)) (*eventbridge.ListEventBusesOutput, error) {
+	return &eventbridge.ListEventBusesOutput{EventBuses: mockedEventBuses}, nil
+}
+
+func (m *MockedEventBridgeClient) ListRules(ctx context.Context, input *eventbridge.ListRulesInput, options ...func(*eventbridge.Options)) (*eventbridge.ListRulesOutput, error) {
+	return &eventbridge.ListRulesOutput{Rules: mockedEventBridgeRules[aws.ToString(input.EventBusName)]}, nil
+}
+
+func (m *MockedEventBridgeClient) ListTargetsByRule(ctx context.Context, input *eventbridge.ListTargetsByRuleInput, options ...func(*eventbridge.Options)) (*eventbridge.ListTargetsByRuleOutput, error) {
+	return &eventbridge.ListTargetsByRuleOutput{Targets: mockedEventBridgeTargets[aws.ToString(input.Rule)]}, nil
+}
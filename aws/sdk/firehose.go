@@ -0,0 +1,45 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	firehoseTypes "github.com/aws/aws-sdk-go-v2/service/firehose/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type FirehoseClientInterface interface {
+	DescribeDeliveryStream(context.Context, *firehose.DescribeDeliveryStreamInput, ...func(*firehose.Options)) (*firehose.DescribeDeliveryStreamOutput, error)
+}
+
+func init() {
+	gob.Register(&firehoseTypes.DeliveryStreamDescription{})
+}
+
+func CachedFirehoseDescribeDeliveryStream(client FirehoseClientInterface, accountID string, region string, deliveryStreamName string) (*firehoseTypes.DeliveryStreamDescription, error) {
+	cacheKey := fmt.Sprintf("%s-firehose-DescribeDeliveryStream-%s-%s", accountID, region, deliveryStreamName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(*firehoseTypes.DeliveryStreamDescription), nil
+	}
+
+	DescribeDeliveryStream, err := client.DescribeDeliveryStream(
+		context.TODO(),
+		&firehose.DescribeDeliveryStreamInput{
+			DeliveryStreamName: aws.String(deliveryStreamName),
+		},
+		func(o *firehose.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	internal.Cache.Set(cacheKey, DescribeDeliveryStream.DeliveryStreamDescription, cache.DefaultExpiration)
+	return DescribeDeliveryStream.DeliveryStreamDescription, nil
+}
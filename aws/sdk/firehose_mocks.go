@@ -0,0 +1,27 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	firehoseTypes "github.com/aws/aws-sdk-go-v2/service/firehose/types"
+)
+
+type MockedFirehoseClient struct {
+}
+
+// aws-waf-logs-cloudfront has server side encryption turned off
+func (m *MockedFirehoseClient) DescribeDeliveryStream(ctx context.Context, input *firehose.DescribeDeliveryStreamInput, options ...func(*firehose.Options)) (*firehose.DescribeDeliveryStreamOutput, error) {
+	return &firehose.DescribeDeliveryStreamOutput{
+		DeliveryStreamDescription: &firehoseTypes.DeliveryStreamDescription{
+			DeliveryStreamName:   input.DeliveryStreamName,
+			DeliveryStreamARN:    aws.String("arn:aws:firehose:us-east-1:123456789012:deliverystream/" + aws.ToString(input.DeliveryStreamName)),
+			DeliveryStreamStatus: firehoseTypes.DeliveryStreamStatusActive,
+			DeliveryStreamType:   firehoseTypes.DeliveryStreamTypeDirectPut,
+			DeliveryStreamEncryptionConfiguration: &firehoseTypes.DeliveryStreamEncryptionConfiguration{
+				Status: firehoseTypes.DeliveryStreamEncryptionStatusDisabled,
+			},
+		},
+	}, nil
+}
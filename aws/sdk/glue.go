@@ -115,7 +115,7 @@ func CachedGlueListJobs(GlueClient AWSGlueClientInterface, accountID string, reg
 func CachedGlueGetTables(GlueClient AWSGlueClientInterface, accountID string, region string, dbName string) ([]glueTypes.Table, error) {
 	var PaginationControl *string
 	var tables []glueTypes.Table
-	cacheKey := "glue-GetTables-" + accountID + "-" + region
+	cacheKey := "glue-GetTables-" + accountID + "-" + region + "-" + dbName
 	cached, found := internal.Cache.Get(cacheKey)
 	if found {
 		sharedLogger.Debug("Using cached Glue tables data")
@@ -134,8 +134,7 @@ func CachedGlueGetTables(GlueClient AWSGlueClientInterface, accountID string, re
 			},
 		)
 		if err != nil {
-			sharedLogger.Error(err.Error())
-			break
+			return tables, err
 		}
 
 		tables = append(tables, GetTables.TableList...)
@@ -175,8 +174,7 @@ func CachedGlueGetDatabases(GlueClient AWSGlueClientInterface, accountID string,
 			},
 		)
 		if err != nil {
-			sharedLogger.Error(err.Error())
-			break
+			return databases, err
 		}
 
 		databases = append(databases, GetDatabases.DatabaseList...)
@@ -29,28 +29,57 @@ func (m *MockedGlueClient) ListJobs(ctx context.Context, input *glue.ListJobsInp
 	}, nil
 }
 
-func (m *MockedGlueClient) GetTables(ctx context.Context, input *glue.GetTablesInput, options ...func(*glue.Options)) (*glue.GetTablesOutput, error) {
-	return &glue.GetTablesOutput{
-		TableList: []glueTypes.Table{
-			{
-				Name:         aws.String("table1"),
-				DatabaseName: aws.String("database1"),
-				Description:  aws.String("description1"),
-				Parameters: map[string]string{
-					"param1": "value1",
-					"param2": "value2",
+// table1 is exported to a bucket anyone can read, table2 sits in a private bucket and table3 has no storage location
+var mockedGlueTables = map[string][]glueTypes.Table{
+	"database1": {
+		{
+			Name:         aws.String("table1"),
+			DatabaseName: aws.String("database1"),
+			Description:  aws.String("description1"),
+			Parameters: map[string]string{
+				"classification": "csv",
+				"param1":         "value1",
+			},
+			StorageDescriptor: &glueTypes.StorageDescriptor{
+				Location: aws.String("s3://datalake-public-exports/customers/"),
+				Columns: []glueTypes.Column{
+					{Name: aws.String("id"), Type: aws.String("bigint")},
+					{Name: aws.String("email"), Type: aws.String("string")},
 				},
 			},
-			{
-				Name:         aws.String("table2"),
-				DatabaseName: aws.String("database2"),
-				Description:  aws.String("description2"),
+		},
+		{
+			Name:         aws.String("table3"),
+			DatabaseName: aws.String("database1"),
+			Description:  aws.String("description3"),
+			TableType:    aws.String("VIRTUAL_VIEW"),
+		},
+	},
+	"database2": {
+		{
+			Name:         aws.String("table2"),
+			DatabaseName: aws.String("database2"),
+			Description:  aws.String("description2"),
+			Parameters: map[string]string{
+				"param1": "value1",
+				"param2": "value2",
+			},
+			StorageDescriptor: &glueTypes.StorageDescriptor{
+				Location: aws.String("s3a://datalake-raw/events"),
+				Columns: []glueTypes.Column{
+					{Name: aws.String("event_time"), Type: aws.String("timestamp")},
+				},
 				Parameters: map[string]string{
-					"param1": "value1",
-					"param2": "value2",
+					"classification": "parquet",
 				},
 			},
 		},
+	},
+}
+
+func (m *MockedGlueClient) GetTables(ctx context.Context, input *glue.GetTablesInput, options ...func(*glue.Options)) (*glue.GetTablesOutput, error) {
+	return &glue.GetTablesOutput{
+		TableList: mockedGlueTables[aws.ToString(input.DatabaseName)],
 	}, nil
 }
 
@@ -78,3 +107,7 @@ func (m *MockedGlueClient) GetDatabases(ctx context.Context, input *glue.GetData
 		},
 	}, nil
 }
+
+func (m *MockedGlueClient) GetResourcePolicies(ctx context.Context, input *glue.GetResourcePoliciesInput, options ...func(*glue.Options)) (*glue.GetResourcePoliciesOutput, error) {
+	return &glue.GetResourcePoliciesOutput{}, nil
+}
@@ -0,0 +1,92 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/guardduty"
+	"github.com/patrickmn/go-cache"
+)
+
+type GuardDutyClientInterface interface {
+	ListDetectors(ctx context.Context, params *guardduty.ListDetectorsInput, optFns ...func(*guardduty.Options)) (*guardduty.ListDetectorsOutput, error)
+	GetDetector(ctx context.Context, params *guardduty.GetDetectorInput, optFns ...func(*guardduty.Options)) (*guardduty.GetDetectorOutput, error)
+}
+
+// The full GetDetectorOutput can't be gob encoded, so only the parts we need are cached
+type customGetDetectorOutput struct {
+	DetectorID                 string
+	Status                     string
+	FindingPublishingFrequency string
+}
+
+func init() {
+	gob.Register(customGetDetectorOutput{})
+}
+
+func CachedGuardDutyListDetectors(client GuardDutyClientInterface, accountID string, region string) ([]string, error) {
+	var PaginationControl *string
+	var detectors []string
+	cacheKey := fmt.Sprintf("%s-guardduty-ListDetectors-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]string), nil
+	}
+	for {
+		ListDetectors, err := client.ListDetectors(
+			context.TODO(),
+			&guardduty.ListDetectorsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *guardduty.Options) {
+				o.Region = region
+			},
+		)
+
+		if err != nil {
+			return detectors, err
+		}
+
+		detectors = append(detectors, ListDetectors.DetectorIds...)
+
+		//pagination
+		if ListDetectors.NextToken == nil {
+			break
+		}
+		PaginationControl = ListDetectors.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, detectors, cache.DefaultExpiration)
+	return detectors, nil
+}
+
+func CachedGuardDutyGetDetector(client GuardDutyClientInterface, accountID string, region string, detectorID string) (customGetDetectorOutput, error) {
+	var detector customGetDetectorOutput
+	cacheKey := fmt.Sprintf("%s-guardduty-GetDetector-%s-%s", accountID, region, detectorID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(customGetDetectorOutput), nil
+	}
+
+	GetDetector, err := client.GetDetector(
+		context.TODO(),
+		&guardduty.GetDetectorInput{
+			DetectorId: aws.String(detectorID),
+		},
+		func(o *guardduty.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return detector, err
+	}
+
+	detector.DetectorID = detectorID
+	detector.Status = string(GetDetector.Status)
+	detector.FindingPublishingFrequency = string(GetDetector.FindingPublishingFrequency)
+	internal.Cache.Set(cacheKey, detector, cache.DefaultExpiration)
+	return detector, nil
+}
@@ -0,0 +1,24 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/guardduty"
+	guardDutyTypes "github.com/aws/aws-sdk-go-v2/service/guardduty/types"
+)
+
+type MockedGuardDutyClient struct {
+}
+
+func (m *MockedGuardDutyClient) ListDetectors(ctx context.Context, input *guardduty.ListDetectorsInput, options ...func(*guardduty.Options)) (*guardduty.ListDetectorsOutput, error) {
+	return &guardduty.ListDetectorsOutput{
+		DetectorIds: []string{"12abc34d567e8fa901bc2d34e56789f0"},
+	}, nil
+}
+
+func (m *MockedGuardDutyClient) GetDetector(ctx context.Context, input *guardduty.GetDetectorInput, options ...func(*guardduty.Options)) (*guardduty.GetDetectorOutput, error) {
+	return &guardduty.GetDetectorOutput{
+		Status:                     guardDutyTypes.DetectorStatusEnabled,
+		FindingPublishingFrequency: guardDutyTypes.FindingPublishingFrequencySixHours,
+	}, nil
+}
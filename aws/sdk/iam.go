@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
@@ -24,9 +25,35 @@ type AWSIAMClientInterface interface {
 	ListInstanceProfiles(ctx context.Context, params *iam.ListInstanceProfilesInput, optFns ...func(*iam.Options)) (*iam.ListInstanceProfilesOutput, error)
 	ListGroups(ctx context.Context, params *iam.ListGroupsInput, optFns ...func(*iam.Options)) (*iam.ListGroupsOutput, error)
 	GetInstanceProfile(ctx context.Context, params *iam.GetInstanceProfileInput, optFns ...func(*iam.Options)) (*iam.GetInstanceProfileOutput, error)
+	GetGroup(ctx context.Context, params *iam.GetGroupInput, optFns ...func(*iam.Options)) (*iam.GetGroupOutput, error)
+	ListAttachedGroupPolicies(ctx context.Context, params *iam.ListAttachedGroupPoliciesInput, optFns ...func(*iam.Options)) (*iam.ListAttachedGroupPoliciesOutput, error)
+	ListGroupPolicies(ctx context.Context, params *iam.ListGroupPoliciesInput, optFns ...func(*iam.Options)) (*iam.ListGroupPoliciesOutput, error)
+	GetGroupPolicy(ctx context.Context, params *iam.GetGroupPolicyInput, optFns ...func(*iam.Options)) (*iam.GetGroupPolicyOutput, error)
+	ListUserPolicies(ctx context.Context, params *iam.ListUserPoliciesInput, optFns ...func(*iam.Options)) (*iam.ListUserPoliciesOutput, error)
+	GetUserPolicy(ctx context.Context, params *iam.GetUserPolicyInput, optFns ...func(*iam.Options)) (*iam.GetUserPolicyOutput, error)
+	ListRolePolicies(ctx context.Context, params *iam.ListRolePoliciesInput, optFns ...func(*iam.Options)) (*iam.ListRolePoliciesOutput, error)
+	GetRolePolicy(ctx context.Context, params *iam.GetRolePolicyInput, optFns ...func(*iam.Options)) (*iam.GetRolePolicyOutput, error)
+}
+
+// AWSIAMAccountClientInterface covers the account wide IAM settings the iam command reports on
+type AWSIAMAccountClientInterface interface {
+	GetAccountPasswordPolicy(ctx context.Context, params *iam.GetAccountPasswordPolicyInput, optFns ...func(*iam.Options)) (*iam.GetAccountPasswordPolicyOutput, error)
+	ListOpenIDConnectProviders(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput, optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error)
+	GetOpenIDConnectProvider(ctx context.Context, params *iam.GetOpenIDConnectProviderInput, optFns ...func(*iam.Options)) (*iam.GetOpenIDConnectProviderOutput, error)
+}
+
+// OpenIDConnectProvider is the part of GetOpenIDConnectProvider we cache
+type OpenIDConnectProvider struct {
+	Arn         string
+	Url         string
+	ClientIDs   []string
+	Thumbprints []string
 }
 
 func init() {
+	gob.Register(iamTypes.PasswordPolicy{})
+	gob.Register([]iamTypes.OpenIDConnectProviderListEntry{})
+	gob.Register(OpenIDConnectProvider{})
 	gob.Register([]iamTypes.User{})
 	gob.Register([]iamTypes.AccessKeyMetadata{})
 	gob.Register([]iamTypes.Role{})
@@ -36,6 +63,7 @@ func init() {
 	gob.Register([]iamTypes.EvaluationResult{})
 	gob.Register(customGAADOutput{})
 	gob.Register(iamTypes.InstanceProfile{})
+	gob.Register([]iamTypes.AttachedPolicy{})
 }
 
 func CachedIamListUsers(IAMClient AWSIAMClientInterface, accountID string) ([]iamTypes.User, error) {
@@ -315,3 +343,330 @@ func CachedIamGetInstanceProfile(IAMClient AWSIAMClientInterface, accountID stri
 	return InstanceProfile, nil
 
 }
+
+func CachedIamGetGroupMembers(IAMClient AWSIAMClientInterface, accountID string, groupName string) ([]iamTypes.User, error) {
+	var PaginationControl *string
+	var Users []iamTypes.User
+	cacheKey := fmt.Sprintf("%s-iam-GetGroup-%s", accountID, groupName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]iamTypes.User), nil
+	}
+
+	for {
+		GetGroup, err := IAMClient.GetGroup(
+			context.TODO(),
+			&iam.GetGroupInput{
+				GroupName: &groupName,
+				Marker:    PaginationControl,
+			},
+		)
+		if err != nil {
+			return Users, err
+		}
+
+		Users = append(Users, GetGroup.Users...)
+
+		// Pagination control.
+		if GetGroup.Marker != nil {
+			PaginationControl = GetGroup.Marker
+		} else {
+			PaginationControl = nil
+			break
+		}
+	}
+
+	internal.Cache.Set(cacheKey, Users, cache.DefaultExpiration)
+	return Users, nil
+
+}
+
+func CachedIamListAttachedGroupPolicies(IAMClient AWSIAMClientInterface, accountID string, groupName string) ([]iamTypes.AttachedPolicy, error) {
+	var PaginationControl *string
+	var AttachedPolicies []iamTypes.AttachedPolicy
+	cacheKey := fmt.Sprintf("%s-iam-ListAttachedGroupPolicies-%s", accountID, groupName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]iamTypes.AttachedPolicy), nil
+	}
+
+	for {
+		ListAttachedGroupPolicies, err := IAMClient.ListAttachedGroupPolicies(
+			context.TODO(),
+			&iam.ListAttachedGroupPoliciesInput{
+				GroupName: &groupName,
+				Marker:    PaginationControl,
+			},
+		)
+		if err != nil {
+			return AttachedPolicies, err
+		}
+
+		AttachedPolicies = append(AttachedPolicies, ListAttachedGroupPolicies.AttachedPolicies...)
+
+		// Pagination control.
+		if ListAttachedGroupPolicies.Marker != nil {
+			PaginationControl = ListAttachedGroupPolicies.Marker
+		} else {
+			PaginationControl = nil
+			break
+		}
+	}
+
+	internal.Cache.Set(cacheKey, AttachedPolicies, cache.DefaultExpiration)
+	return AttachedPolicies, nil
+
+}
+
+func CachedIamListGroupPolicies(IAMClient AWSIAMClientInterface, accountID string, groupName string) ([]string, error) {
+	var PaginationControl *string
+	var PolicyNames []string
+	cacheKey := fmt.Sprintf("%s-iam-ListGroupPolicies-%s", accountID, groupName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]string), nil
+	}
+
+	for {
+		ListGroupPolicies, err := IAMClient.ListGroupPolicies(
+			context.TODO(),
+			&iam.ListGroupPoliciesInput{
+				GroupName: &groupName,
+				Marker:    PaginationControl,
+			},
+		)
+		if err != nil {
+			return PolicyNames, err
+		}
+
+		PolicyNames = append(PolicyNames, ListGroupPolicies.PolicyNames...)
+
+		// Pagination control.
+		if ListGroupPolicies.Marker != nil {
+			PaginationControl = ListGroupPolicies.Marker
+		} else {
+			PaginationControl = nil
+			break
+		}
+	}
+
+	internal.Cache.Set(cacheKey, PolicyNames, cache.DefaultExpiration)
+	return PolicyNames, nil
+
+}
+
+// CachedIamGetGroupPolicy returns the URL encoded document of a group's inline policy
+func CachedIamGetGroupPolicy(IAMClient AWSIAMClientInterface, accountID string, groupName string, policyName string) (string, error) {
+	cacheKey := fmt.Sprintf("%s-iam-GetGroupPolicy-%s-%s", accountID, groupName, policyName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(string), nil
+	}
+
+	GetGroupPolicy, err := IAMClient.GetGroupPolicy(
+		context.TODO(),
+		&iam.GetGroupPolicyInput{
+			GroupName:  &groupName,
+			PolicyName: &policyName,
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	document := aws.ToString(GetGroupPolicy.PolicyDocument)
+	internal.Cache.Set(cacheKey, document, cache.DefaultExpiration)
+	return document, nil
+
+}
+
+func CachedIamListUserPolicies(IAMClient AWSIAMClientInterface, accountID string, userName string) ([]string, error) {
+	var PaginationControl *string
+	var PolicyNames []string
+	cacheKey := fmt.Sprintf("%s-iam-ListUserPolicies-%s", accountID, userName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]string), nil
+	}
+
+	for {
+		ListUserPolicies, err := IAMClient.ListUserPolicies(
+			context.TODO(),
+			&iam.ListUserPoliciesInput{
+				UserName: &userName,
+				Marker:   PaginationControl,
+			},
+		)
+		if err != nil {
+			return PolicyNames, err
+		}
+
+		PolicyNames = append(PolicyNames, ListUserPolicies.PolicyNames...)
+
+		// Pagination control.
+		if ListUserPolicies.Marker != nil {
+			PaginationControl = ListUserPolicies.Marker
+		} else {
+			PaginationControl = nil
+			break
+		}
+	}
+
+	internal.Cache.Set(cacheKey, PolicyNames, cache.DefaultExpiration)
+	return PolicyNames, nil
+
+}
+
+// CachedIamGetUserPolicy returns the URL encoded document of a user's inline policy
+func CachedIamGetUserPolicy(IAMClient AWSIAMClientInterface, accountID string, userName string, policyName string) (string, error) {
+	cacheKey := fmt.Sprintf("%s-iam-GetUserPolicy-%s-%s", accountID, userName, policyName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(string), nil
+	}
+
+	GetUserPolicy, err := IAMClient.GetUserPolicy(
+		context.TODO(),
+		&iam.GetUserPolicyInput{
+			UserName:   &userName,
+			PolicyName: &policyName,
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	document := aws.ToString(GetUserPolicy.PolicyDocument)
+	internal.Cache.Set(cacheKey, document, cache.DefaultExpiration)
+	return document, nil
+
+}
+
+func CachedIamListRolePolicies(IAMClient AWSIAMClientInterface, accountID string, roleName string) ([]string, error) {
+	var PaginationControl *string
+	var PolicyNames []string
+	cacheKey := fmt.Sprintf("%s-iam-ListRolePolicies-%s", accountID, roleName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]string), nil
+	}
+
+	for {
+		ListRolePolicies, err := IAMClient.ListRolePolicies(
+			context.TODO(),
+			&iam.ListRolePoliciesInput{
+				RoleName: &roleName,
+				Marker:   PaginationControl,
+			},
+		)
+		if err != nil {
+			return PolicyNames, err
+		}
+
+		PolicyNames = append(PolicyNames, ListRolePolicies.PolicyNames...)
+
+		// Pagination control.
+		if ListRolePolicies.Marker != nil {
+			PaginationControl = ListRolePolicies.Marker
+		} else {
+			PaginationControl = nil
+			break
+		}
+	}
+
+	internal.Cache.Set(cacheKey, PolicyNames, cache.DefaultExpiration)
+	return PolicyNames, nil
+
+}
+
+// CachedIamGetRolePolicy returns the URL encoded document of a role's inline policy
+func CachedIamGetRolePolicy(IAMClient AWSIAMClientInterface, accountID string, roleName string, policyName string) (string, error) {
+	cacheKey := fmt.Sprintf("%s-iam-GetRolePolicy-%s-%s", accountID, roleName, policyName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(string), nil
+	}
+
+	GetRolePolicy, err := IAMClient.GetRolePolicy(
+		context.TODO(),
+		&iam.GetRolePolicyInput{
+			RoleName:   &roleName,
+			PolicyName: &policyName,
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	document := aws.ToString(GetRolePolicy.PolicyDocument)
+	internal.Cache.Set(cacheKey, document, cache.DefaultExpiration)
+	return document, nil
+
+}
+
+func CachedIamGetAccountPasswordPolicy(IAMClient AWSIAMAccountClientInterface, accountID string) (iamTypes.PasswordPolicy, error) {
+	var PasswordPolicy iamTypes.PasswordPolicy
+	cacheKey := fmt.Sprintf("%s-iam-GetAccountPasswordPolicy", accountID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(iamTypes.PasswordPolicy), nil
+	}
+
+	GetAccountPasswordPolicy, err := IAMClient.GetAccountPasswordPolicy(
+		context.TODO(),
+		&iam.GetAccountPasswordPolicyInput{},
+	)
+	if err != nil {
+		return PasswordPolicy, err
+	}
+
+	PasswordPolicy = *GetAccountPasswordPolicy.PasswordPolicy
+	internal.Cache.Set(cacheKey, PasswordPolicy, cache.DefaultExpiration)
+	return PasswordPolicy, nil
+}
+
+func CachedIamListOpenIDConnectProviders(IAMClient AWSIAMAccountClientInterface, accountID string) ([]iamTypes.OpenIDConnectProviderListEntry, error) {
+	cacheKey := fmt.Sprintf("%s-iam-ListOpenIDConnectProviders", accountID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]iamTypes.OpenIDConnectProviderListEntry), nil
+	}
+
+	// ListOpenIDConnectProviders is not paginated
+	ListOpenIDConnectProviders, err := IAMClient.ListOpenIDConnectProviders(
+		context.TODO(),
+		&iam.ListOpenIDConnectProvidersInput{},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	providers := ListOpenIDConnectProviders.OpenIDConnectProviderList
+	internal.Cache.Set(cacheKey, providers, cache.DefaultExpiration)
+	return providers, nil
+}
+
+func CachedIamGetOpenIDConnectProvider(IAMClient AWSIAMAccountClientInterface, accountID string, providerArn string) (OpenIDConnectProvider, error) {
+	provider := OpenIDConnectProvider{Arn: providerArn}
+	cacheKey := fmt.Sprintf("%s-iam-GetOpenIDConnectProvider-%s", accountID, providerArn)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(OpenIDConnectProvider), nil
+	}
+
+	GetOpenIDConnectProvider, err := IAMClient.GetOpenIDConnectProvider(
+		context.TODO(),
+		&iam.GetOpenIDConnectProviderInput{
+			OpenIDConnectProviderArn: &providerArn,
+		},
+	)
+	if err != nil {
+		return provider, err
+	}
+
+	provider.Url = aws.ToString(GetOpenIDConnectProvider.Url)
+	provider.ClientIDs = GetOpenIDConnectProvider.ClientIDList
+	provider.Thumbprints = GetOpenIDConnectProvider.ThumbprintList
+	internal.Cache.Set(cacheKey, provider, cache.DefaultExpiration)
+	return provider, nil
+}
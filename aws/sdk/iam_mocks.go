@@ -2,6 +2,7 @@ package sdk
 
 import (
 	"context"
+	"net/url"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -102,6 +103,14 @@ func (m *MockedIAMClient) ListRoles(ctx context.Context, input *iam.ListRolesInp
 				RoleName:                 aws.String("role3"),
 				Path:                     aws.String("/"),
 			},
+			{
+				Arn:                      aws.String("arn:aws:iam::123456789012:role/GameSparksServiceRole"),
+				AssumeRolePolicyDocument: aws.String("{\"Version\":\"2012-10-17\",\"Statement\":[{\"Effect\":\"Allow\",\"Principal\":{\"Service\":\"gamesparks.amazonaws.com\"},\"Action\":\"sts:AssumeRole\"}]}"),
+				CreateDate:               aws.Time(time.Now()),
+				RoleId:                   aws.String("123456789012"),
+				RoleName:                 aws.String("GameSparksServiceRole"),
+				Path:                     aws.String("/"),
+			},
 		},
 	}, nil
 
@@ -150,6 +159,18 @@ func (m *MockedIAMClient) GetAccountAuthorizationDetails(ctx context.Context, pa
 
 				RoleName: aws.String("role3"),
 				Path:     aws.String("/"),
+				PermissionsBoundary: &iamTypes.AttachedPermissionsBoundary{
+					PermissionsBoundaryArn:  aws.String("arn:aws:iam::123456789012:policy/DeveloperBoundary"),
+					PermissionsBoundaryType: iamTypes.PermissionsBoundaryAttachmentTypePolicy,
+				},
+			},
+			{
+				Arn:                      aws.String("arn:aws:iam::123456789012:role/aws-service-role/support.amazonaws.com/AWSServiceRoleForSupport"),
+				AssumeRolePolicyDocument: aws.String("{\"Version\":\"2012-10-17\",\"Statement\":[{\"Effect\":\"Allow\",\"Principal\":{\"Service\":\"support.amazonaws.com\"},\"Action\":\"sts:AssumeRole\"}]}"),
+				CreateDate:               aws.Time(time.Now()),
+				RoleId:                   aws.String("123456789012"),
+				RoleName:                 aws.String("AWSServiceRoleForSupport"),
+				Path:                     aws.String("/aws-service-role/support.amazonaws.com/"),
 			},
 		},
 		UserDetailList: []iamTypes.UserDetail{
@@ -168,14 +189,49 @@ func (m *MockedIAMClient) GetAccountAuthorizationDetails(ctx context.Context, pa
 				UserId:   aws.String("123456789012"),
 				UserName: aws.String("user2"),
 				Path:     aws.String("/"),
+				PermissionsBoundary: &iamTypes.AttachedPermissionsBoundary{
+					PermissionsBoundaryArn:  aws.String("arn:aws:iam::123456789012:policy/DeveloperBoundary"),
+					PermissionsBoundaryType: iamTypes.PermissionsBoundaryAttachmentTypePolicy,
+				},
 			},
 		},
 	}, nil
 }
 
+// mockedIAMSimulateAllowedActions are allowed for a principal on top of the sts:AssumeRole every principal gets
+var mockedIAMSimulateAllowedActions = map[string][]string{
+	"arn:aws:iam::123456789012:user/Alice":              {"sagemaker:CreatePresignedNotebookInstanceUrl", "events:PutEvents"},
+	"arn:aws:iam::123456789012:user/user1":              {"ssm:GetParameter"},
+	"arn:aws:iam::123456789012:role/role1":              {"secretsmanager:GetSecretValue", "ssm:GetParameter"},
+	"arn:aws:iam::123456789012:role/rekognition-video":  {"kinesisvideo:GetDataEndpoint", "kinesisvideo:GetMedia"},
+	"arn:aws:iam::123456789012:role/sagemaker-training": {"s3:GetObject", "s3:ListBucket", "s3:PutObject"},
+}
+
 func (m *MockedIAMClient) SimulatePrincipalPolicy(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error) {
+	var allowed []iamTypes.EvaluationResult
+	for _, action := range mockedIAMSimulateAllowedActions[aws.ToString(params.PolicySourceArn)] {
+		for _, requested := range params.ActionNames {
+			if requested != action {
+				continue
+			}
+			if len(params.ResourceArns) == 0 {
+				allowed = append(allowed, iamTypes.EvaluationResult{
+					EvalActionName: aws.String(action),
+					EvalDecision:   iamTypes.PolicyEvaluationDecisionTypeAllowed,
+				})
+			}
+			// Like the real API, there is a result for every resource
+			for _, resourceArn := range params.ResourceArns {
+				allowed = append(allowed, iamTypes.EvaluationResult{
+					EvalActionName:   aws.String(action),
+					EvalDecision:     iamTypes.PolicyEvaluationDecisionTypeAllowed,
+					EvalResourceName: aws.String(resourceArn),
+				})
+			}
+		}
+	}
 	return &iam.SimulatePrincipalPolicyOutput{
-		EvaluationResults: []iamTypes.EvaluationResult{
+		EvaluationResults: append([]iamTypes.EvaluationResult{
 			{
 				EvalActionName:   aws.String("sts:AssumeRole"),
 				EvalDecision:     iamTypes.PolicyEvaluationDecisionTypeAllowed,
@@ -191,7 +247,7 @@ func (m *MockedIAMClient) SimulatePrincipalPolicy(ctx context.Context, params *i
 					},
 				},
 			},
-		},
+		}, allowed...),
 	}, nil
 
 }
@@ -220,3 +276,174 @@ func (m *MockedIAMClient) ListInstanceProfiles(ctx context.Context, params *iam.
 	}, nil
 
 }
+
+func (m *MockedIAMClient) GetInstanceProfile(ctx context.Context, params *iam.GetInstanceProfileInput, optFns ...func(*iam.Options)) (*iam.GetInstanceProfileOutput, error) {
+	return &iam.GetInstanceProfileOutput{
+		InstanceProfile: &iamTypes.InstanceProfile{
+			Arn:                 aws.String("arn:aws:iam::123456789012:instance-profile/" + aws.ToString(params.InstanceProfileName)),
+			CreateDate:          aws.Time(time.Now()),
+			InstanceProfileId:   aws.String("123456789012"),
+			InstanceProfileName: params.InstanceProfileName,
+			Path:                aws.String("/"),
+			Roles: []iamTypes.Role{
+				{
+					Arn:                      aws.String("arn:aws:iam::123456789012:role/role1"),
+					AssumeRolePolicyDocument: aws.String("{\"Version\":\"2012-10-17\",\"Statement\":[{\"Effect\":\"Allow\",\"Principal\":{\"Service\":\"ec2.amazonaws.com\"},\"Action\":\"sts:AssumeRole\"}]}"),
+					CreateDate:               aws.Time(time.Now()),
+					RoleId:                   aws.String("123456789012"),
+					RoleName:                 aws.String("role1"),
+					Path:                     aws.String("/"),
+				},
+			},
+		},
+	}, nil
+}
+
+var mockedIAMGroupMembers = map[string][]string{
+	"group1": {"user1", "user2"},
+	"group2": {"user1"},
+}
+
+func (m *MockedIAMClient) GetGroup(ctx context.Context, params *iam.GetGroupInput, optFns ...func(*iam.Options)) (*iam.GetGroupOutput, error) {
+	groupName := aws.ToString(params.GroupName)
+	output := &iam.GetGroupOutput{
+		Group: &iamTypes.Group{
+			Arn:       aws.String("arn:aws:iam::123456789012:group/" + groupName),
+			GroupName: aws.String(groupName),
+			Path:      aws.String("/"),
+		},
+	}
+	for _, userName := range mockedIAMGroupMembers[groupName] {
+		output.Users = append(output.Users, iamTypes.User{
+			Arn:      aws.String("arn:aws:iam::123456789012:user/" + userName),
+			UserName: aws.String(userName),
+			Path:     aws.String("/"),
+		})
+	}
+	return output, nil
+}
+
+func (m *MockedIAMClient) ListAttachedGroupPolicies(ctx context.Context, params *iam.ListAttachedGroupPoliciesInput, optFns ...func(*iam.Options)) (*iam.ListAttachedGroupPoliciesOutput, error) {
+	output := &iam.ListAttachedGroupPoliciesOutput{}
+	switch aws.ToString(params.GroupName) {
+	case "group1":
+		output.AttachedPolicies = []iamTypes.AttachedPolicy{
+			{
+				PolicyArn:  aws.String("arn:aws:iam::aws:policy/AdministratorAccess"),
+				PolicyName: aws.String("AdministratorAccess"),
+			},
+		}
+	case "group2":
+		output.AttachedPolicies = []iamTypes.AttachedPolicy{
+			{
+				PolicyArn:  aws.String("arn:aws:iam::aws:policy/ReadOnlyAccess"),
+				PolicyName: aws.String("ReadOnlyAccess"),
+			},
+		}
+	}
+	return output, nil
+}
+
+func (m *MockedIAMClient) ListGroupPolicies(ctx context.Context, params *iam.ListGroupPoliciesInput, optFns ...func(*iam.Options)) (*iam.ListGroupPoliciesOutput, error) {
+	output := &iam.ListGroupPoliciesOutput{}
+	if aws.ToString(params.GroupName) == "group2" {
+		output.PolicyNames = []string{"manage-iam"}
+	}
+	return output, nil
+}
+
+func (m *MockedIAMClient) GetGroupPolicy(ctx context.Context, params *iam.GetGroupPolicyInput, optFns ...func(*iam.Options)) (*iam.GetGroupPolicyOutput, error) {
+	return &iam.GetGroupPolicyOutput{
+		GroupName:      params.GroupName,
+		PolicyName:     params.PolicyName,
+		PolicyDocument: aws.String("%7B%22Version%22%3A%222012-10-17%22%2C%22Statement%22%3A%5B%7B%22Effect%22%3A%22Allow%22%2C%22Action%22%3A%22iam%3A%2A%22%2C%22Resource%22%3A%22%2A%22%7D%5D%7D"),
+	}, nil
+}
+
+// Inline policies of the mocked users and roles. user1 can do anything, role3 can manage IAM and role1 can only read
+// from one bucket.
+var mockedIAMInlinePolicies = map[string]map[string]string{
+	"user1": {
+		"break-glass": `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"*","Resource":"*"}]}`,
+	},
+	"role1": {
+		"read-artifacts": `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject","s3:ListBucket"],"Resource":["arn:aws:s3:::artifacts","arn:aws:s3:::artifacts/*"]}]}`,
+	},
+	"role3": {
+		"manage-iam": `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["iam:*","sts:AssumeRole"],"Resource":"*"}]}`,
+		"deny-iam":   `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Action":"iam:*","Resource":"*"}]}`,
+	},
+}
+
+func mockedIAMInlinePolicyNames(principalName string) []string {
+	var names []string
+	for name := range mockedIAMInlinePolicies[principalName] {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (m *MockedIAMClient) ListUserPolicies(ctx context.Context, params *iam.ListUserPoliciesInput, optFns ...func(*iam.Options)) (*iam.ListUserPoliciesOutput, error) {
+	return &iam.ListUserPoliciesOutput{
+		PolicyNames: mockedIAMInlinePolicyNames(aws.ToString(params.UserName)),
+	}, nil
+}
+
+func (m *MockedIAMClient) GetUserPolicy(ctx context.Context, params *iam.GetUserPolicyInput, optFns ...func(*iam.Options)) (*iam.GetUserPolicyOutput, error) {
+	return &iam.GetUserPolicyOutput{
+		UserName:       params.UserName,
+		PolicyName:     params.PolicyName,
+		PolicyDocument: aws.String(url.QueryEscape(mockedIAMInlinePolicies[aws.ToString(params.UserName)][aws.ToString(params.PolicyName)])),
+	}, nil
+}
+
+func (m *MockedIAMClient) ListRolePolicies(ctx context.Context, params *iam.ListRolePoliciesInput, optFns ...func(*iam.Options)) (*iam.ListRolePoliciesOutput, error) {
+	return &iam.ListRolePoliciesOutput{
+		PolicyNames: mockedIAMInlinePolicyNames(aws.ToString(params.RoleName)),
+	}, nil
+}
+
+func (m *MockedIAMClient) GetRolePolicy(ctx context.Context, params *iam.GetRolePolicyInput, optFns ...func(*iam.Options)) (*iam.GetRolePolicyOutput, error) {
+	return &iam.GetRolePolicyOutput{
+		RoleName:       params.RoleName,
+		PolicyName:     params.PolicyName,
+		PolicyDocument: aws.String(url.QueryEscape(mockedIAMInlinePolicies[aws.ToString(params.RoleName)][aws.ToString(params.PolicyName)])),
+	}, nil
+}
+
+type MockedIAMAccountClient struct {
+}
+
+// The password policy is weaker than the CIS benchmark asks for
+func (m *MockedIAMAccountClient) GetAccountPasswordPolicy(ctx context.Context, params *iam.GetAccountPasswordPolicyInput, optFns ...func(*iam.Options)) (*iam.GetAccountPasswordPolicyOutput, error) {
+	return &iam.GetAccountPasswordPolicyOutput{
+		PasswordPolicy: &iamTypes.PasswordPolicy{
+			MinimumPasswordLength:      aws.Int32(8),
+			RequireLowercaseCharacters: true,
+			RequireUppercaseCharacters: true,
+			RequireNumbers:             true,
+			RequireSymbols:             false,
+			ExpirePasswords:            true,
+			MaxPasswordAge:             aws.Int32(90),
+		},
+	}, nil
+}
+
+func (m *MockedIAMAccountClient) ListOpenIDConnectProviders(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput, optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error) {
+	return &iam.ListOpenIDConnectProvidersOutput{
+		OpenIDConnectProviderList: []iamTypes.OpenIDConnectProviderListEntry{
+			{Arn: aws.String("arn:aws:iam::123456789012:oidc-provider/token.actions.githubusercontent.com")},
+		},
+	}, nil
+}
+
+func (m *MockedIAMAccountClient) GetOpenIDConnectProvider(ctx context.Context, params *iam.GetOpenIDConnectProviderInput, optFns ...func(*iam.Options)) (*iam.GetOpenIDConnectProviderOutput, error) {
+	if aws.ToString(params.OpenIDConnectProviderArn) != "arn:aws:iam::123456789012:oidc-provider/token.actions.githubusercontent.com" {
+		return nil, &iamTypes.NoSuchEntityException{Message: aws.String("OpenIDConnect provider not found")}
+	}
+	return &iam.GetOpenIDConnectProviderOutput{
+		Url:            aws.String("token.actions.githubusercontent.com"),
+		ClientIDList:   []string{"sts.amazonaws.com"},
+		ThumbprintList: []string{"6938fd4d98bab03faadb97b34396831e3780aea1"},
+	}, nil
+}
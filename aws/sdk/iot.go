@@ -0,0 +1,147 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iot"
+	iotTypes "github.com/aws/aws-sdk-go-v2/service/iot/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type IoTClientInterface interface {
+	ListThings(context.Context, *iot.ListThingsInput, ...func(*iot.Options)) (*iot.ListThingsOutput, error)
+	ListPolicies(context.Context, *iot.ListPoliciesInput, ...func(*iot.Options)) (*iot.ListPoliciesOutput, error)
+	GetPolicy(context.Context, *iot.GetPolicyInput, ...func(*iot.Options)) (*iot.GetPolicyOutput, error)
+	DescribeEndpoint(context.Context, *iot.DescribeEndpointInput, ...func(*iot.Options)) (*iot.DescribeEndpointOutput, error)
+}
+
+func init() {
+	gob.Register([]iotTypes.ThingAttribute{})
+	gob.Register([]iotTypes.Policy{})
+}
+
+func CachedIoTListThings(client IoTClientInterface, accountID string, region string) ([]iotTypes.ThingAttribute, error) {
+	var PaginationControl *string
+	var things []iotTypes.ThingAttribute
+	cacheKey := fmt.Sprintf("%s-iot-ListThings-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]iotTypes.ThingAttribute), nil
+	}
+
+	for {
+		ListThings, err := client.ListThings(
+			context.TODO(),
+			&iot.ListThingsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *iot.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return things, err
+		}
+
+		things = append(things, ListThings.Things...)
+
+		//pagination
+		if ListThings.NextToken == nil {
+			break
+		}
+		PaginationControl = ListThings.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, things, cache.DefaultExpiration)
+	return things, nil
+}
+
+func CachedIoTListPolicies(client IoTClientInterface, accountID string, region string) ([]iotTypes.Policy, error) {
+	var PaginationControl *string
+	var policies []iotTypes.Policy
+	cacheKey := fmt.Sprintf("%s-iot-ListPolicies-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]iotTypes.Policy), nil
+	}
+
+	for {
+		ListPolicies, err := client.ListPolicies(
+			context.TODO(),
+			&iot.ListPoliciesInput{
+				Marker: PaginationControl,
+			},
+			func(o *iot.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return policies, err
+		}
+
+		policies = append(policies, ListPolicies.Policies...)
+
+		//pagination
+		if ListPolicies.NextMarker == nil {
+			break
+		}
+		PaginationControl = ListPolicies.NextMarker
+	}
+
+	internal.Cache.Set(cacheKey, policies, cache.DefaultExpiration)
+	return policies, nil
+}
+
+// CachedIoTGetPolicy returns the document of the default version of a policy
+func CachedIoTGetPolicy(client IoTClientInterface, accountID string, region string, policyName string) (string, error) {
+	cacheKey := fmt.Sprintf("%s-iot-GetPolicy-%s-%s", accountID, region, policyName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(string), nil
+	}
+
+	GetPolicy, err := client.GetPolicy(
+		context.TODO(),
+		&iot.GetPolicyInput{
+			PolicyName: &policyName,
+		},
+		func(o *iot.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	internal.Cache.Set(cacheKey, aws.ToString(GetPolicy.PolicyDocument), cache.DefaultExpiration)
+	return aws.ToString(GetPolicy.PolicyDocument), nil
+}
+
+// CachedIoTDescribeEndpoint returns the ATS signed data endpoint devices connect to over MQTT
+func CachedIoTDescribeEndpoint(client IoTClientInterface, accountID string, region string) (string, error) {
+	cacheKey := fmt.Sprintf("%s-iot-DescribeEndpoint-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(string), nil
+	}
+
+	DescribeEndpoint, err := client.DescribeEndpoint(
+		context.TODO(),
+		&iot.DescribeEndpointInput{
+			EndpointType: aws.String("iot:Data-ATS"),
+		},
+		func(o *iot.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	internal.Cache.Set(cacheKey, aws.ToString(DescribeEndpoint.EndpointAddress), cache.DefaultExpiration)
+	return aws.ToString(DescribeEndpoint.EndpointAddress), nil
+}
@@ -0,0 +1,105 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iot"
+	iotTypes "github.com/aws/aws-sdk-go-v2/service/iot/types"
+)
+
+type MockedIoTClient struct {
+}
+
+// device-full-access is the policy from the getting started guide, sensor-publish lets a device publish to any topic
+// and thing-scoped only lets a device use its own client ID and topics
+var mockedIoTPolicies = map[string]string{
+	"device-full-access": `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Action": "iot:*",
+				"Resource": "*"
+			}
+		]
+	}`,
+	"sensor-publish": `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Action": "iot:Connect",
+				"Resource": "arn:aws:iot:us-east-1:123456789012:client/${iot:Connection.Thing.ThingName}"
+			},
+			{
+				"Effect": "Allow",
+				"Action": ["iot:Publish"],
+				"Resource": "*"
+			}
+		]
+	}`,
+	"thing-scoped": `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Action": "iot:Connect",
+				"Resource": "arn:aws:iot:us-east-1:123456789012:client/${iot:Connection.Thing.ThingName}"
+			},
+			{
+				"Effect": "Allow",
+				"Action": ["iot:Publish", "iot:Receive"],
+				"Resource": "arn:aws:iot:us-east-1:123456789012:topic/devices/${iot:Connection.Thing.ThingName}/*"
+			}
+		]
+	}`,
+}
+
+func (m *MockedIoTClient) ListThings(ctx context.Context, input *iot.ListThingsInput, options ...func(*iot.Options)) (*iot.ListThingsOutput, error) {
+	return &iot.ListThingsOutput{
+		Things: []iotTypes.ThingAttribute{
+			{
+				ThingName:     aws.String("thermostat-01"),
+				ThingArn:      aws.String("arn:aws:iot:us-east-1:123456789012:thing/thermostat-01"),
+				ThingTypeName: aws.String("thermostat"),
+				Attributes:    map[string]string{"site": "berlin", "firmware": "2.1.0"},
+			},
+			{
+				ThingName: aws.String("gateway"),
+				ThingArn:  aws.String("arn:aws:iot:us-east-1:123456789012:thing/gateway"),
+			},
+		},
+	}, nil
+}
+
+func (m *MockedIoTClient) ListPolicies(ctx context.Context, input *iot.ListPoliciesInput, options ...func(*iot.Options)) (*iot.ListPoliciesOutput, error) {
+	var policies []iotTypes.Policy
+	for _, name := range []string{"device-full-access", "sensor-publish", "thing-scoped"} {
+		policies = append(policies, iotTypes.Policy{
+			PolicyName: aws.String(name),
+			PolicyArn:  aws.String(fmt.Sprintf("arn:aws:iot:us-east-1:123456789012:policy/%s", name)),
+		})
+	}
+	return &iot.ListPoliciesOutput{Policies: policies}, nil
+}
+
+func (m *MockedIoTClient) GetPolicy(ctx context.Context, input *iot.GetPolicyInput, options ...func(*iot.Options)) (*iot.GetPolicyOutput, error) {
+	document, ok := mockedIoTPolicies[aws.ToString(input.PolicyName)]
+	if !ok {
+		return nil, fmt.Errorf("policy %s not found", aws.ToString(input.PolicyName))
+	}
+	return &iot.GetPolicyOutput{
+		PolicyName:       input.PolicyName,
+		PolicyArn:        aws.String(fmt.Sprintf("arn:aws:iot:us-east-1:123456789012:policy/%s", aws.ToString(input.PolicyName))),
+		PolicyDocument:   aws.String(document),
+		DefaultVersionId: aws.String("1"),
+	}, nil
+}
+
+func (m *MockedIoTClient) DescribeEndpoint(ctx context.Context, input *iot.DescribeEndpointInput, options ...func(*iot.Options)) (*iot.DescribeEndpointOutput, error) {
+	return &iot.DescribeEndpointOutput{
+		EndpointAddress: aws.String("a1b2c3d4e5f6g7-ats.iot.us-east-1.amazonaws.com"),
+	}, nil
+}
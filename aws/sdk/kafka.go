@@ -0,0 +1,234 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	kafkaTypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type KafkaClientInterface interface {
+	ListReplicators(context.Context, *kafka.ListReplicatorsInput, ...func(*kafka.Options)) (*kafka.ListReplicatorsOutput, error)
+	DescribeReplicator(context.Context, *kafka.DescribeReplicatorInput, ...func(*kafka.Options)) (*kafka.DescribeReplicatorOutput, error)
+	ListClusters(context.Context, *kafka.ListClustersInput, ...func(*kafka.Options)) (*kafka.ListClustersOutput, error)
+	ListClustersV2(context.Context, *kafka.ListClustersV2Input, ...func(*kafka.Options)) (*kafka.ListClustersV2Output, error)
+	GetBootstrapBrokers(context.Context, *kafka.GetBootstrapBrokersInput, ...func(*kafka.Options)) (*kafka.GetBootstrapBrokersOutput, error)
+}
+
+func init() {
+	gob.Register([]kafkaTypes.ReplicatorSummary{})
+	gob.Register(customDescribeReplicatorOutput{})
+	gob.Register([]kafkaTypes.ClusterInfo{})
+	gob.Register([]kafkaTypes.Cluster{})
+	gob.Register(customGetBootstrapBrokersOutput{})
+}
+
+// create CachedKafkaListReplicators function that uses go-cache and pagination
+func CachedKafkaListReplicators(client KafkaClientInterface, accountID string, region string) ([]kafkaTypes.ReplicatorSummary, error) {
+	var PaginationControl *string
+	var replicators []kafkaTypes.ReplicatorSummary
+	cacheKey := fmt.Sprintf("%s-kafka-ListReplicators-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]kafkaTypes.ReplicatorSummary), nil
+	}
+
+	for {
+		ListReplicators, err := client.ListReplicators(
+			context.TODO(),
+			&kafka.ListReplicatorsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *kafka.Options) {
+				o.Region = region
+			},
+		)
+
+		if err != nil {
+			return replicators, err
+		}
+
+		replicators = append(replicators, ListReplicators.Replicators...)
+
+		//pagination
+		if ListReplicators.NextToken == nil {
+			break
+		}
+		PaginationControl = ListReplicators.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, replicators, cache.DefaultExpiration)
+	return replicators, nil
+}
+
+// The parts of DescribeReplicatorOutput that we care about. The full output can't be gob encoded for the cache.
+type customDescribeReplicatorOutput struct {
+	ReplicatorArn           *string
+	ReplicatorName          *string
+	ReplicatorState         kafkaTypes.ReplicatorState
+	ServiceExecutionRoleArn *string
+	KafkaClusters           []kafkaTypes.KafkaClusterDescription
+	ReplicationInfoList     []kafkaTypes.ReplicationInfoDescription
+	IsReplicatorReference   *bool
+}
+
+func CachedKafkaDescribeReplicator(client KafkaClientInterface, accountID string, region string, replicatorArn string) (customDescribeReplicatorOutput, error) {
+	var replicator customDescribeReplicatorOutput
+	cacheKey := fmt.Sprintf("%s-kafka-DescribeReplicator-%s-%s", accountID, region, replicatorArn)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(customDescribeReplicatorOutput), nil
+	}
+
+	DescribeReplicator, err := client.DescribeReplicator(
+		context.TODO(),
+		&kafka.DescribeReplicatorInput{
+			ReplicatorArn: &replicatorArn,
+		},
+		func(o *kafka.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return replicator, err
+	}
+
+	replicator = customDescribeReplicatorOutput{
+		ReplicatorArn:           DescribeReplicator.ReplicatorArn,
+		ReplicatorName:          DescribeReplicator.ReplicatorName,
+		ReplicatorState:         DescribeReplicator.ReplicatorState,
+		ServiceExecutionRoleArn: DescribeReplicator.ServiceExecutionRoleArn,
+		KafkaClusters:           DescribeReplicator.KafkaClusters,
+		ReplicationInfoList:     DescribeReplicator.ReplicationInfoList,
+		IsReplicatorReference:   DescribeReplicator.IsReplicatorReference,
+	}
+
+	internal.Cache.Set(cacheKey, replicator, cache.DefaultExpiration)
+	return replicator, nil
+}
+
+// ListClusters only returns provisioned clusters, but works with policies that predate ListClustersV2
+func CachedKafkaListClusters(client KafkaClientInterface, accountID string, region string) ([]kafkaTypes.ClusterInfo, error) {
+	var PaginationControl *string
+	var clusters []kafkaTypes.ClusterInfo
+	cacheKey := fmt.Sprintf("%s-kafka-ListClusters-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]kafkaTypes.ClusterInfo), nil
+	}
+
+	for {
+		ListClusters, err := client.ListClusters(
+			context.TODO(),
+			&kafka.ListClustersInput{
+				NextToken: PaginationControl,
+			},
+			func(o *kafka.Options) {
+				o.Region = region
+			},
+		)
+
+		if err != nil {
+			return clusters, err
+		}
+
+		clusters = append(clusters, ListClusters.ClusterInfoList...)
+
+		//pagination
+		if ListClusters.NextToken == nil {
+			break
+		}
+		PaginationControl = ListClusters.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, clusters, cache.DefaultExpiration)
+	return clusters, nil
+}
+
+// ListClustersV2 returns provisioned and serverless clusters
+func CachedKafkaListClustersV2(client KafkaClientInterface, accountID string, region string) ([]kafkaTypes.Cluster, error) {
+	var PaginationControl *string
+	var clusters []kafkaTypes.Cluster
+	cacheKey := fmt.Sprintf("%s-kafka-ListClustersV2-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]kafkaTypes.Cluster), nil
+	}
+
+	for {
+		ListClustersV2, err := client.ListClustersV2(
+			context.TODO(),
+			&kafka.ListClustersV2Input{
+				NextToken: PaginationControl,
+			},
+			func(o *kafka.Options) {
+				o.Region = region
+			},
+		)
+
+		if err != nil {
+			return clusters, err
+		}
+
+		clusters = append(clusters, ListClustersV2.ClusterInfoList...)
+
+		//pagination
+		if ListClustersV2.NextToken == nil {
+			break
+		}
+		PaginationControl = ListClustersV2.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, clusters, cache.DefaultExpiration)
+	return clusters, nil
+}
+
+// The bootstrap broker strings of GetBootstrapBrokersOutput. The full output can't be gob encoded for the cache.
+type customGetBootstrapBrokersOutput struct {
+	BootstrapBrokerString                *string
+	BootstrapBrokerStringTls             *string
+	BootstrapBrokerStringSaslScram       *string
+	BootstrapBrokerStringSaslIam         *string
+	BootstrapBrokerStringPublicTls       *string
+	BootstrapBrokerStringPublicSaslScram *string
+	BootstrapBrokerStringPublicSaslIam   *string
+}
+
+func CachedKafkaGetBootstrapBrokers(client KafkaClientInterface, accountID string, region string, clusterArn string) (customGetBootstrapBrokersOutput, error) {
+	var brokers customGetBootstrapBrokersOutput
+	cacheKey := fmt.Sprintf("%s-kafka-GetBootstrapBrokers-%s-%s", accountID, region, clusterArn)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(customGetBootstrapBrokersOutput), nil
+	}
+
+	GetBootstrapBrokers, err := client.GetBootstrapBrokers(
+		context.TODO(),
+		&kafka.GetBootstrapBrokersInput{
+			ClusterArn: &clusterArn,
+		},
+		func(o *kafka.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return brokers, err
+	}
+
+	brokers = customGetBootstrapBrokersOutput{
+		BootstrapBrokerString:                GetBootstrapBrokers.BootstrapBrokerString,
+		BootstrapBrokerStringTls:             GetBootstrapBrokers.BootstrapBrokerStringTls,
+		BootstrapBrokerStringSaslScram:       GetBootstrapBrokers.BootstrapBrokerStringSaslScram,
+		BootstrapBrokerStringSaslIam:         GetBootstrapBrokers.BootstrapBrokerStringSaslIam,
+		BootstrapBrokerStringPublicTls:       GetBootstrapBrokers.BootstrapBrokerStringPublicTls,
+		BootstrapBrokerStringPublicSaslScram: GetBootstrapBrokers.BootstrapBrokerStringPublicSaslScram,
+		BootstrapBrokerStringPublicSaslIam:   GetBootstrapBrokers.BootstrapBrokerStringPublicSaslIam,
+	}
+
+	internal.Cache.Set(cacheKey, brokers, cache.DefaultExpiration)
+	return brokers, nil
+}
@@ -0,0 +1,222 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	kafkaTypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+)
+
+// MockedKafkaClient has a disaster recovery replicator within the account, a bidirectional replicator with another
+// account in the organization, a replicator that feeds a partner's cluster, and a reference to a replicator that runs
+// in another region
+type MockedKafkaClient struct {
+}
+
+func mockedKafkaCluster(alias string, clusterArn string) kafkaTypes.KafkaClusterDescription {
+	return kafkaTypes.KafkaClusterDescription{
+		KafkaClusterAlias: aws.String(alias),
+		AmazonMskCluster:  &kafkaTypes.AmazonMskCluster{MskClusterArn: aws.String(clusterArn)},
+	}
+}
+
+func mockedKafkaReplicationInfo(source string, target string, topics ...string) kafkaTypes.ReplicationInfoDescription {
+	return kafkaTypes.ReplicationInfoDescription{
+		SourceKafkaClusterAlias: aws.String(source),
+		TargetKafkaClusterAlias: aws.String(target),
+		TopicReplication:        &kafkaTypes.TopicReplication{TopicsToReplicate: topics},
+	}
+}
+
+var mockedKafkaReplicators = map[string]*kafka.DescribeReplicatorOutput{
+	"orders-dr": {
+		ReplicatorName:          aws.String("orders-dr"),
+		ReplicatorState:         kafkaTypes.ReplicatorStateRunning,
+		ServiceExecutionRoleArn: aws.String("arn:aws:iam::123456789012:role/orders-dr-replication"),
+		KafkaClusters: []kafkaTypes.KafkaClusterDescription{
+			mockedKafkaCluster("orders-primary", "arn:aws:kafka:us-east-2:123456789012:cluster/orders/11111111-1111-1111-1111-111111111111-1"),
+			mockedKafkaCluster("orders-standby", "arn:aws:kafka:us-east-1:123456789012:cluster/orders-standby/22222222-2222-2222-2222-222222222222-1"),
+		},
+		ReplicationInfoList: []kafkaTypes.ReplicationInfoDescription{
+			mockedKafkaReplicationInfo("orders-primary", "orders-standby", ".*"),
+		},
+	},
+	"analytics-share": {
+		ReplicatorName:          aws.String("analytics-share"),
+		ReplicatorState:         kafkaTypes.ReplicatorStateRunning,
+		ServiceExecutionRoleArn: aws.String("arn:aws:iam::123456789012:role/analytics-share-replication"),
+		KafkaClusters: []kafkaTypes.KafkaClusterDescription{
+			mockedKafkaCluster("events", "arn:aws:kafka:us-east-1:123456789012:cluster/events/33333333-3333-3333-3333-333333333333-1"),
+			mockedKafkaCluster("analytics", "arn:aws:kafka:us-east-1:222222222222:cluster/analytics/44444444-4444-4444-4444-444444444444-1"),
+		},
+		ReplicationInfoList: []kafkaTypes.ReplicationInfoDescription{
+			mockedKafkaReplicationInfo("events", "analytics", "clickstream", "pageviews"),
+			mockedKafkaReplicationInfo("analytics", "events", "aggregates"),
+		},
+	},
+	"partner-feed": {
+		ReplicatorName:          aws.String("partner-feed"),
+		ReplicatorState:         kafkaTypes.ReplicatorStateRunning,
+		ServiceExecutionRoleArn: aws.String("arn:aws:iam::123456789012:role/partner-feed-replication"),
+		KafkaClusters: []kafkaTypes.KafkaClusterDescription{
+			mockedKafkaCluster("events", "arn:aws:kafka:us-east-1:123456789012:cluster/events/33333333-3333-3333-3333-333333333333-1"),
+			mockedKafkaCluster("partner", "arn:aws:kafka:us-east-1:999999999999:cluster/ingest/55555555-5555-5555-5555-555555555555-1"),
+		},
+		ReplicationInfoList: []kafkaTypes.ReplicationInfoDescription{
+			mockedKafkaReplicationInfo("events", "partner", "orders.*"),
+		},
+	},
+}
+
+func (m *MockedKafkaClient) ListReplicators(ctx context.Context, input *kafka.ListReplicatorsInput, options ...func(*kafka.Options)) (*kafka.ListReplicatorsOutput, error) {
+	return &kafka.ListReplicatorsOutput{
+		Replicators: []kafkaTypes.ReplicatorSummary{
+			{
+				ReplicatorArn:   aws.String("arn:aws:kafka:us-east-1:123456789012:replicator/orders-dr/66666666-6666-6666-6666-666666666666"),
+				ReplicatorName:  aws.String("orders-dr"),
+				ReplicatorState: kafkaTypes.ReplicatorStateRunning,
+			},
+			{
+				ReplicatorArn:   aws.String("arn:aws:kafka:us-east-1:123456789012:replicator/analytics-share/77777777-7777-7777-7777-777777777777"),
+				ReplicatorName:  aws.String("analytics-share"),
+				ReplicatorState: kafkaTypes.ReplicatorStateRunning,
+			},
+			{
+				ReplicatorArn:   aws.String("arn:aws:kafka:us-east-1:123456789012:replicator/partner-feed/88888888-8888-8888-8888-888888888888"),
+				ReplicatorName:  aws.String("partner-feed"),
+				ReplicatorState: kafkaTypes.ReplicatorStateRunning,
+			},
+			{
+				ReplicatorArn:         aws.String("arn:aws:kafka:us-west-2:123456789012:replicator/inventory-dr/99999999-9999-9999-9999-999999999999"),
+				ReplicatorName:        aws.String("inventory-dr"),
+				ReplicatorState:       kafkaTypes.ReplicatorStateRunning,
+				IsReplicatorReference: aws.Bool(true),
+			},
+		},
+	}, nil
+}
+
+func (m *MockedKafkaClient) DescribeReplicator(ctx context.Context, input *kafka.DescribeReplicatorInput, options ...func(*kafka.Options)) (*kafka.DescribeReplicatorOutput, error) {
+	// arn:aws:kafka:<region>:<account>:replicator/<name>/<uuid>
+	parts := strings.Split(aws.ToString(input.ReplicatorArn), "/")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid replicator arn %s", aws.ToString(input.ReplicatorArn))
+	}
+	replicator, ok := mockedKafkaReplicators[parts[1]]
+	if !ok {
+		return nil, fmt.Errorf("replicator %s not found", aws.ToString(input.ReplicatorArn))
+	}
+	output := *replicator
+	output.ReplicatorArn = input.ReplicatorArn
+	return &output, nil
+}
+
+func mockedKafkaBrokerNodeGroup(publicAccess string) *kafkaTypes.BrokerNodeGroupInfo {
+	return &kafkaTypes.BrokerNodeGroupInfo{
+		InstanceType:   aws.String("kafka.m5.large"),
+		ClientSubnets:  []string{"subnet-11111111", "subnet-22222222", "subnet-33333333"},
+		SecurityGroups: []string{"sg-11111111"},
+		ConnectivityInfo: &kafkaTypes.ConnectivityInfo{
+			PublicAccess: &kafkaTypes.PublicAccess{Type: aws.String(publicAccess)},
+		},
+	}
+}
+
+// mockedKafkaClusters has a public cluster with IAM authentication, a private cluster that also allows
+// unauthenticated clients, and a serverless cluster
+var mockedKafkaClusters = []kafkaTypes.Cluster{
+	{
+		ClusterName: aws.String("payments"),
+		ClusterArn:  aws.String("arn:aws:kafka:us-east-1:123456789012:cluster/payments/aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa-1"),
+		ClusterType: kafkaTypes.ClusterTypeProvisioned,
+		State:       kafkaTypes.ClusterStateActive,
+		Provisioned: &kafkaTypes.Provisioned{
+			BrokerNodeGroupInfo:       mockedKafkaBrokerNodeGroup("SERVICE_PROVIDED_EIPS"),
+			NumberOfBrokerNodes:       aws.Int32(3),
+			CurrentBrokerSoftwareInfo: &kafkaTypes.BrokerSoftwareInfo{KafkaVersion: aws.String("3.5.1")},
+			ClientAuthentication: &kafkaTypes.ClientAuthentication{
+				Sasl: &kafkaTypes.Sasl{Iam: &kafkaTypes.Iam{Enabled: aws.Bool(true)}},
+			},
+		},
+	},
+	{
+		ClusterName: aws.String("events"),
+		ClusterArn:  aws.String("arn:aws:kafka:us-east-1:123456789012:cluster/events/33333333-3333-3333-3333-333333333333-1"),
+		ClusterType: kafkaTypes.ClusterTypeProvisioned,
+		State:       kafkaTypes.ClusterStateActive,
+		Provisioned: &kafkaTypes.Provisioned{
+			BrokerNodeGroupInfo:       mockedKafkaBrokerNodeGroup("DISABLED"),
+			NumberOfBrokerNodes:       aws.Int32(6),
+			CurrentBrokerSoftwareInfo: &kafkaTypes.BrokerSoftwareInfo{KafkaVersion: aws.String("2.8.1")},
+			ClientAuthentication: &kafkaTypes.ClientAuthentication{
+				Tls:             &kafkaTypes.Tls{Enabled: aws.Bool(true)},
+				Unauthenticated: &kafkaTypes.Unauthenticated{Enabled: aws.Bool(true)},
+			},
+		},
+	},
+	{
+		ClusterName: aws.String("telemetry"),
+		ClusterArn:  aws.String("arn:aws:kafka:us-east-1:123456789012:cluster/telemetry/bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb-s1"),
+		ClusterType: kafkaTypes.ClusterTypeServerless,
+		State:       kafkaTypes.ClusterStateActive,
+		Serverless: &kafkaTypes.Serverless{
+			VpcConfigs: []kafkaTypes.ServerlessVpcConfig{
+				{SubnetIds: []string{"subnet-11111111"}, SecurityGroupIds: []string{"sg-22222222"}},
+			},
+			ClientAuthentication: &kafkaTypes.ServerlessClientAuthentication{
+				Sasl: &kafkaTypes.ServerlessSasl{Iam: &kafkaTypes.Iam{Enabled: aws.Bool(true)}},
+			},
+		},
+	},
+}
+
+func (m *MockedKafkaClient) ListClustersV2(ctx context.Context, input *kafka.ListClustersV2Input, options ...func(*kafka.Options)) (*kafka.ListClustersV2Output, error) {
+	return &kafka.ListClustersV2Output{
+		ClusterInfoList: mockedKafkaClusters,
+	}, nil
+}
+
+// ListClusters returns the provisioned clusters only, like the real API
+func (m *MockedKafkaClient) ListClusters(ctx context.Context, input *kafka.ListClustersInput, options ...func(*kafka.Options)) (*kafka.ListClustersOutput, error) {
+	var clusters []kafkaTypes.ClusterInfo
+	for _, cluster := range mockedKafkaClusters {
+		if cluster.Provisioned == nil {
+			continue
+		}
+		clusters = append(clusters, kafkaTypes.ClusterInfo{
+			ClusterName:               cluster.ClusterName,
+			ClusterArn:                cluster.ClusterArn,
+			State:                     cluster.State,
+			BrokerNodeGroupInfo:       cluster.Provisioned.BrokerNodeGroupInfo,
+			NumberOfBrokerNodes:       cluster.Provisioned.NumberOfBrokerNodes,
+			CurrentBrokerSoftwareInfo: cluster.Provisioned.CurrentBrokerSoftwareInfo,
+			ClientAuthentication:      cluster.Provisioned.ClientAuthentication,
+		})
+	}
+	return &kafka.ListClustersOutput{
+		ClusterInfoList: clusters,
+	}, nil
+}
+
+func (m *MockedKafkaClient) GetBootstrapBrokers(ctx context.Context, input *kafka.GetBootstrapBrokersInput, options ...func(*kafka.Options)) (*kafka.GetBootstrapBrokersOutput, error) {
+	switch aws.ToString(input.ClusterArn) {
+	case aws.ToString(mockedKafkaClusters[0].ClusterArn):
+		return &kafka.GetBootstrapBrokersOutput{
+			BootstrapBrokerStringSaslIam:       aws.String("b-1.payments.abc123.c2.kafka.us-east-1.amazonaws.com:9098,b-2.payments.abc123.c2.kafka.us-east-1.amazonaws.com:9098"),
+			BootstrapBrokerStringPublicSaslIam: aws.String("b-1-public.payments.abc123.c2.kafka.us-east-1.amazonaws.com:9198,b-2-public.payments.abc123.c2.kafka.us-east-1.amazonaws.com:9198"),
+		}, nil
+	case aws.ToString(mockedKafkaClusters[1].ClusterArn):
+		return &kafka.GetBootstrapBrokersOutput{
+			BootstrapBrokerString:    aws.String("b-1.events.def456.c3.kafka.us-east-1.amazonaws.com:9092,b-2.events.def456.c3.kafka.us-east-1.amazonaws.com:9092"),
+			BootstrapBrokerStringTls: aws.String("b-1.events.def456.c3.kafka.us-east-1.amazonaws.com:9094,b-2.events.def456.c3.kafka.us-east-1.amazonaws.com:9094"),
+		}, nil
+	case aws.ToString(mockedKafkaClusters[2].ClusterArn):
+		return &kafka.GetBootstrapBrokersOutput{
+			BootstrapBrokerStringSaslIam: aws.String("boot-ghi789.c1.kafka-serverless.us-east-1.amazonaws.com:9098"),
+		}, nil
+	}
+	return nil, fmt.Errorf("cluster %s not found", aws.ToString(input.ClusterArn))
+}
@@ -0,0 +1,85 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmsTypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type KMSClientInterface interface {
+	ListKeys(context.Context, *kms.ListKeysInput, ...func(*kms.Options)) (*kms.ListKeysOutput, error)
+	GetKeyPolicy(context.Context, *kms.GetKeyPolicyInput, ...func(*kms.Options)) (*kms.GetKeyPolicyOutput, error)
+}
+
+func init() {
+	gob.Register([]kmsTypes.KeyListEntry{})
+}
+
+func CachedKMSListKeys(client KMSClientInterface, accountID string, region string) ([]kmsTypes.KeyListEntry, error) {
+	var PaginationControl *string
+	var keys []kmsTypes.KeyListEntry
+	cacheKey := fmt.Sprintf("%s-kms-ListKeys-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]kmsTypes.KeyListEntry), nil
+	}
+
+	for {
+		ListKeys, err := client.ListKeys(
+			context.TODO(),
+			&kms.ListKeysInput{
+				Marker: PaginationControl,
+			},
+			func(o *kms.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return keys, err
+		}
+
+		keys = append(keys, ListKeys.Keys...)
+
+		//pagination
+		if !ListKeys.Truncated || ListKeys.NextMarker == nil {
+			break
+		}
+		PaginationControl = ListKeys.NextMarker
+	}
+
+	internal.Cache.Set(cacheKey, keys, cache.DefaultExpiration)
+	return keys, nil
+}
+
+// CachedKMSGetKeyPolicy returns the key policy as JSON. Keys only ever have a single policy called "default".
+func CachedKMSGetKeyPolicy(client KMSClientInterface, accountID string, region string, keyID string) (string, error) {
+	cacheKey := fmt.Sprintf("%s-kms-GetKeyPolicy-%s-%s", accountID, region, keyID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(string), nil
+	}
+
+	GetKeyPolicy, err := client.GetKeyPolicy(
+		context.TODO(),
+		&kms.GetKeyPolicyInput{
+			KeyId:      aws.String(keyID),
+			PolicyName: aws.String("default"),
+		},
+		func(o *kms.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	policyJSON := aws.ToString(GetKeyPolicy.Policy)
+	internal.Cache.Set(cacheKey, policyJSON, cache.DefaultExpiration)
+	return policyJSON, nil
+}
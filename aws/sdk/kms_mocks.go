@@ -0,0 +1,76 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmsTypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+type MockedKMSClient struct {
+}
+
+func (m *MockedKMSClient) ListKeys(ctx context.Context, input *kms.ListKeysInput, options ...func(*kms.Options)) (*kms.ListKeysOutput, error) {
+	return &kms.ListKeysOutput{
+		Keys: []kmsTypes.KeyListEntry{
+			{
+				KeyId:  aws.String("11111111-2222-3333-4444-555555555555"),
+				KeyArn: aws.String("arn:aws:kms:us-east-1:123456789012:key/11111111-2222-3333-4444-555555555555"),
+			},
+			{
+				KeyId:  aws.String("66666666-7777-8888-9999-000000000000"),
+				KeyArn: aws.String("arn:aws:kms:us-east-1:123456789012:key/66666666-7777-8888-9999-000000000000"),
+			},
+		},
+	}, nil
+}
+
+// The first key is shared with account 111122223333, the second one is an AWS managed key
+func (m *MockedKMSClient) GetKeyPolicy(ctx context.Context, input *kms.GetKeyPolicyInput, options ...func(*kms.Options)) (*kms.GetKeyPolicyOutput, error) {
+	if aws.ToString(input.KeyId) == "11111111-2222-3333-4444-555555555555" {
+		return &kms.GetKeyPolicyOutput{
+			PolicyName: aws.String("default"),
+			Policy: aws.String(`{
+				"Version": "2012-10-17",
+				"Statement": [
+					{
+						"Sid": "Enable IAM User Permissions",
+						"Effect": "Allow",
+						"Principal": {"AWS": "arn:aws:iam::123456789012:root"},
+						"Action": "kms:*",
+						"Resource": "*"
+					},
+					{
+						"Sid": "Allow use of the key by the partner account",
+						"Effect": "Allow",
+						"Principal": {"AWS": ["arn:aws:iam::111122223333:root"]},
+						"Action": "kms:*",
+						"Resource": "*"
+					}
+				]
+			}`),
+		}, nil
+	}
+	return &kms.GetKeyPolicyOutput{
+		PolicyName: aws.String("default"),
+		Policy: aws.String(`{
+			"Version": "2012-10-17",
+			"Statement": [
+				{
+					"Sid": "Allow access through EBS for all principals in the account that are authorized to use EBS",
+					"Effect": "Allow",
+					"Principal": {"AWS": "*"},
+					"Action": ["kms:Encrypt", "kms:Decrypt", "kms:ReEncrypt*", "kms:GenerateDataKey*", "kms:CreateGrant", "kms:DescribeKey"],
+					"Resource": "*",
+					"Condition": {
+						"StringEquals": {
+							"kms:ViaService": "ec2.us-east-1.amazonaws.com",
+							"kms:CallerAccount": "123456789012"
+						}
+					}
+				}
+			]
+		}`),
+	}, nil
+}
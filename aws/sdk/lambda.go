@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
 	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
 	"github.com/patrickmn/go-cache"
@@ -15,6 +16,7 @@ type LambdaClientInterface interface {
 	ListFunctions(context.Context, *lambda.ListFunctionsInput, ...func(*lambda.Options)) (*lambda.ListFunctionsOutput, error)
 	GetFunction(context.Context, *lambda.GetFunctionInput, ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error)
 	GetFunctionUrlConfig(context.Context, *lambda.GetFunctionUrlConfigInput, ...func(*lambda.Options)) (*lambda.GetFunctionUrlConfigOutput, error)
+	GetPolicy(context.Context, *lambda.GetPolicyInput, ...func(*lambda.Options)) (*lambda.GetPolicyOutput, error)
 }
 
 func init() {
@@ -145,3 +147,30 @@ func CachedLambdaGetFunctionUrlConfig(client LambdaClientInterface, accountID st
 
 	return functionUrlConfigOutput, nil
 }
+
+// CachedLambdaGetPolicy returns the resource-based policy of a function as JSON. Functions without a policy return a
+// ResourceNotFoundException, which is passed on to the caller.
+func CachedLambdaGetPolicy(client LambdaClientInterface, accountID string, region string, functionName string) (string, error) {
+	cacheKey := fmt.Sprintf("%s-lambda-GetPolicy-%s-%s", accountID, region, functionName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(string), nil
+	}
+
+	GetPolicy, err := client.GetPolicy(
+		context.TODO(),
+		&lambda.GetPolicyInput{
+			FunctionName: &functionName,
+		},
+		func(o *lambda.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	policyJSON := aws.ToString(GetPolicy.Policy)
+	internal.Cache.Set(cacheKey, policyJSON, cache.DefaultExpiration)
+	return policyJSON, nil
+}
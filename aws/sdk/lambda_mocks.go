@@ -31,6 +31,12 @@ func (m *MockedLambdaClient) ListFunctions(ctx context.Context, input *lambda.Li
 				FunctionName: aws.String("my-function2"),
 				Handler:      aws.String("index.handler"),
 				Runtime:      lambdaTypes.RuntimeNodejs18x,
+				FileSystemConfigs: []lambdaTypes.FileSystemConfig{
+					{
+						Arn:            aws.String("arn:aws:elasticfilesystem:us-east-1:123456789012:access-point/fsap-12345678"),
+						LocalMountPath: aws.String("/mnt/shared"),
+					},
+				},
 			},
 		},
 	}, nil
@@ -53,10 +59,16 @@ func (m *MockedLambdaClient) GetFunction(ctx context.Context, input *lambda.GetF
 	}, nil
 }
 
-func (m *MockedLambdaClient) GetFunctionUrlConfig(ctx context.Context, input *lambda.GetFunctionInput, options ...func(*lambda.Options)) (*lambda.GetFunctionUrlConfigOutput, error) {
+func (m *MockedLambdaClient) GetFunctionUrlConfig(ctx context.Context, input *lambda.GetFunctionUrlConfigInput, options ...func(*lambda.Options)) (*lambda.GetFunctionUrlConfigOutput, error) {
 	return &lambda.GetFunctionUrlConfigOutput{
 		FunctionUrl: aws.String("https://my-function.us-east-1.amazonaws.com/Prod/"),
 		FunctionArn: aws.String("arn:aws:lambda:us-east-1:123456789012:function:my-function"),
 		AuthType:    lambdaTypes.FunctionUrlAuthTypeNone,
 	}, nil
 }
+
+func (m *MockedLambdaClient) GetPolicy(ctx context.Context, input *lambda.GetPolicyInput, options ...func(*lambda.Options)) (*lambda.GetPolicyOutput, error) {
+	return &lambda.GetPolicyOutput{
+		Policy: aws.String(`{"Version":"2012-10-17","Id":"default","Statement":[{"Sid":"partner-invoke","Effect":"Allow","Principal":{"AWS":"arn:aws:iam::999999999999:root"},"Action":"lambda:InvokeFunction","Resource":"arn:aws:lambda:us-east-1:123456789012:function:my-function"}]}`),
+	}, nil
+}
@@ -11,9 +11,10 @@ import (
 	"github.com/patrickmn/go-cache"
 )
 
-type lightsailClientInterface interface {
+type LightsailClientInterface interface {
 	GetInstances(context.Context, *lightsail.GetInstancesInput, ...func(*lightsail.Options)) (*lightsail.GetInstancesOutput, error)
 	GetContainerServices(context.Context, *lightsail.GetContainerServicesInput, ...func(*lightsail.Options)) (*lightsail.GetContainerServicesOutput, error)
+	GetRelationalDatabases(context.Context, *lightsail.GetRelationalDatabasesInput, ...func(*lightsail.Options)) (*lightsail.GetRelationalDatabasesOutput, error)
 }
 
 func init() {
@@ -22,10 +23,11 @@ func init() {
 	type lightsailInstance lightsailTypes.Instance
 	gob.Register([]lightsailInstance{})
 	gob.Register([]lightsailTypes.ContainerService{})
+	gob.Register([]lightsailTypes.RelationalDatabase{})
 
 }
 
-func CachedLightsailGetInstances(client lightsailClientInterface, accountID string, region string) ([]lightsailTypes.Instance, error) {
+func CachedLightsailGetInstances(client LightsailClientInterface, accountID string, region string) ([]lightsailTypes.Instance, error) {
 	var PaginationControl *string
 	var services []lightsailTypes.Instance
 
@@ -64,7 +66,7 @@ func CachedLightsailGetInstances(client lightsailClientInterface, accountID stri
 	return services, nil
 }
 
-func CachedLightsailGetContainerServices(client lightsailClientInterface, accountID string, region string) ([]lightsailTypes.ContainerService, error) {
+func CachedLightsailGetContainerServices(client LightsailClientInterface, accountID string, region string) ([]lightsailTypes.ContainerService, error) {
 	var services []lightsailTypes.ContainerService
 	cacheKey := fmt.Sprintf("%s-lightsail-GetContainerService-%s", accountID, region)
 	cached, found := internal.Cache.Get(cacheKey)
@@ -88,3 +90,39 @@ func CachedLightsailGetContainerServices(client lightsailClientInterface, accoun
 	internal.Cache.Set(cacheKey, services, cache.DefaultExpiration)
 	return services, nil
 }
+
+func CachedLightsailGetRelationalDatabases(client LightsailClientInterface, accountID string, region string) ([]lightsailTypes.RelationalDatabase, error) {
+	var PaginationControl *string
+	var databases []lightsailTypes.RelationalDatabase
+	cacheKey := fmt.Sprintf("%s-lightsail-GetRelationalDatabases-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]lightsailTypes.RelationalDatabase), nil
+	}
+	for {
+		GetRelationalDatabases, err := client.GetRelationalDatabases(
+			context.TODO(),
+			&lightsail.GetRelationalDatabasesInput{
+				PageToken: PaginationControl,
+			},
+			func(o *lightsail.Options) {
+				o.Region = region
+			},
+		)
+
+		if err != nil {
+			return databases, err
+		}
+
+		databases = append(databases, GetRelationalDatabases.RelationalDatabases...)
+
+		//pagination
+		if GetRelationalDatabases.NextPageToken == nil {
+			break
+		}
+		PaginationControl = GetRelationalDatabases.NextPageToken
+	}
+
+	internal.Cache.Set(cacheKey, databases, cache.DefaultExpiration)
+	return databases, nil
+}
@@ -28,9 +28,25 @@ func (m *MockedLightsailClient) GetInstances(ctx context.Context, input *lightsa
 					MonthlyTransfer: &lightsailTypes.MonthlyTransfer{
 						GbPerMonthAllocated: aws.Int32(1),
 					},
+					Ports: []lightsailTypes.InstancePortInfo{
+						{
+							FromPort: 22,
+							ToPort:   22,
+							Protocol: lightsailTypes.NetworkProtocolTcp,
+							Cidrs:    []string{"0.0.0.0/0"},
+						},
+						{
+							FromPort: 80,
+							ToPort:   80,
+							Protocol: lightsailTypes.NetworkProtocolTcp,
+							Cidrs:    []string{"0.0.0.0/0"},
+						},
+					},
 				},
 				PrivateIpAddress: aws.String("10.1.1.1"),
 				PublicIpAddress:  aws.String("1.2.3.4"),
+				SshKeyName:       aws.String("LightsailDefaultKeyPair"),
+				Username:         aws.String("bitnami"),
 			},
 			{
 				BlueprintId: aws.String("blueprint2"),
@@ -45,9 +61,25 @@ func (m *MockedLightsailClient) GetInstances(ctx context.Context, input *lightsa
 					MonthlyTransfer: &lightsailTypes.MonthlyTransfer{
 						GbPerMonthAllocated: aws.Int32(2),
 					},
+					Ports: []lightsailTypes.InstancePortInfo{
+						{
+							FromPort: 22,
+							ToPort:   22,
+							Protocol: lightsailTypes.NetworkProtocolTcp,
+							Cidrs:    []string{"203.0.113.0/24"},
+						},
+						{
+							FromPort: 3000,
+							ToPort:   3500,
+							Protocol: lightsailTypes.NetworkProtocolTcp,
+							Cidrs:    []string{"0.0.0.0/0"},
+						},
+					},
 				},
 				PrivateIpAddress: aws.String("10.2.2.2"),
 				PublicIpAddress:  aws.String("2.3.4.4"),
+				SshKeyName:       aws.String("ops-team"),
+				Username:         aws.String("ubuntu"),
 			},
 		},
 	}, nil
@@ -78,3 +110,21 @@ func (m *MockedLightsailClient) GetContainerServices(ctx context.Context, input
 		},
 	}, nil
 }
+
+func (m *MockedLightsailClient) GetRelationalDatabases(ctx context.Context, input *lightsail.GetRelationalDatabasesInput, options ...func(*lightsail.Options)) (*lightsail.GetRelationalDatabasesOutput, error) {
+	return &lightsail.GetRelationalDatabasesOutput{
+		RelationalDatabases: []lightsailTypes.RelationalDatabase{
+			{
+				Name:               aws.String("database1"),
+				Arn:                aws.String("arn:aws:lightsail:us-east-1:123456789012:RelationalDatabase/33333333-3333-3333-3333-333333333333"),
+				Engine:             aws.String("mysql"),
+				MasterUsername:     aws.String("dbmasteruser"),
+				PubliclyAccessible: aws.Bool(true),
+				MasterEndpoint: &lightsailTypes.RelationalDatabaseEndpoint{
+					Address: aws.String("ls-0123456789abcdef.czowadgeezqi.us-east-1.rds.amazonaws.com"),
+					Port:    aws.Int32(3306),
+				},
+			},
+		},
+	}, nil
+}
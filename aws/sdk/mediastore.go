@@ -0,0 +1,90 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/mediastore"
+	mediastoreTypes "github.com/aws/aws-sdk-go-v2/service/mediastore/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type MediaStoreClientInterface interface {
+	ListContainers(context.Context, *mediastore.ListContainersInput, ...func(*mediastore.Options)) (*mediastore.ListContainersOutput, error)
+	GetContainerPolicy(context.Context, *mediastore.GetContainerPolicyInput, ...func(*mediastore.Options)) (*mediastore.GetContainerPolicyOutput, error)
+}
+
+func init() {
+	gob.Register([]mediastoreTypes.Container{})
+}
+
+func CachedMediaStoreListContainers(client MediaStoreClientInterface, accountID string, region string) ([]mediastoreTypes.Container, error) {
+	var PaginationControl *string
+	var containers []mediastoreTypes.Container
+	cacheKey := fmt.Sprintf("%s-mediastore-ListContainers-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]mediastoreTypes.Container), nil
+	}
+
+	for {
+		ListContainers, err := client.ListContainers(
+			context.TODO(),
+			&mediastore.ListContainersInput{
+				NextToken: PaginationControl,
+			},
+			func(o *mediastore.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return containers, err
+		}
+
+		containers = append(containers, ListContainers.Containers...)
+
+		//pagination
+		if ListContainers.NextToken == nil {
+			break
+		}
+		PaginationControl = ListContainers.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, containers, cache.DefaultExpiration)
+	return containers, nil
+}
+
+// CachedMediaStoreGetContainerPolicy returns the policy document of a container, or an empty string when the container
+// has no policy
+func CachedMediaStoreGetContainerPolicy(client MediaStoreClientInterface, accountID string, region string, containerName string) (string, error) {
+	cacheKey := fmt.Sprintf("%s-mediastore-GetContainerPolicy-%s-%s", accountID, region, containerName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(string), nil
+	}
+
+	GetContainerPolicy, err := client.GetContainerPolicy(
+		context.TODO(),
+		&mediastore.GetContainerPolicyInput{
+			ContainerName: aws.String(containerName),
+		},
+		func(o *mediastore.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		var notFound *mediastoreTypes.PolicyNotFoundException
+		if errors.As(err, &notFound) {
+			internal.Cache.Set(cacheKey, "", cache.DefaultExpiration)
+			return "", nil
+		}
+		return "", err
+	}
+
+	internal.Cache.Set(cacheKey, aws.ToString(GetContainerPolicy.Policy), cache.DefaultExpiration)
+	return aws.ToString(GetContainerPolicy.Policy), nil
+}
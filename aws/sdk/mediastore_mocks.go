@@ -0,0 +1,65 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/mediastore"
+	mediastoreTypes "github.com/aws/aws-sdk-go-v2/service/mediastore/types"
+)
+
+type MockedMediaStoreClient struct {
+}
+
+// vod-assets has the public read policy from the MediaStore console, live-ingest only lets a CIDR range read and
+// internal-archive has no policy
+var mockedMediaStoreContainerPolicies = map[string]string{
+	"vod-assets": `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Sid": "PublicReadOverHttps",
+				"Effect": "Allow",
+				"Principal": "*",
+				"Action": ["mediastore:GetObject", "mediastore:DescribeObject", "mediastore:ListItems"],
+				"Resource": "arn:aws:mediastore:us-east-1:123456789012:container/vod-assets/*",
+				"Condition": {"Bool": {"aws:SecureTransport": "true"}}
+			}
+		]
+	}`,
+	"live-ingest": `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Principal": {"AWS": "*"},
+				"Action": "mediastore:GetObject",
+				"Resource": "arn:aws:mediastore:us-east-1:123456789012:container/live-ingest/*",
+				"Condition": {"IpAddress": {"aws:SourceIp": "203.0.113.0/24"}}
+			}
+		]
+	}`,
+}
+
+func (m *MockedMediaStoreClient) ListContainers(ctx context.Context, input *mediastore.ListContainersInput, options ...func(*mediastore.Options)) (*mediastore.ListContainersOutput, error) {
+	var containers []mediastoreTypes.Container
+	for _, name := range []string{"vod-assets", "live-ingest", "internal-archive"} {
+		containers = append(containers, mediastoreTypes.Container{
+			Name:                 aws.String(name),
+			ARN:                  aws.String(fmt.Sprintf("arn:aws:mediastore:us-east-1:123456789012:container/%s", name)),
+			Endpoint:             aws.String(fmt.Sprintf("https://%s1a2b3c.data.mediastore.us-east-1.amazonaws.com", name)),
+			Status:               mediastoreTypes.ContainerStatusActive,
+			AccessLoggingEnabled: aws.Bool(name == "internal-archive"),
+		})
+	}
+	return &mediastore.ListContainersOutput{Containers: containers}, nil
+}
+
+func (m *MockedMediaStoreClient) GetContainerPolicy(ctx context.Context, input *mediastore.GetContainerPolicyInput, options ...func(*mediastore.Options)) (*mediastore.GetContainerPolicyOutput, error) {
+	policy, ok := mockedMediaStoreContainerPolicies[aws.ToString(input.ContainerName)]
+	if !ok {
+		return nil, &mediastoreTypes.PolicyNotFoundException{Message: aws.String("The policy does not exist within the specified container.")}
+	}
+	return &mediastore.GetContainerPolicyOutput{Policy: aws.String(policy)}, nil
+}
@@ -13,10 +13,12 @@ import (
 
 type MQClientInterface interface {
 	ListBrokers(context.Context, *mq.ListBrokersInput, ...func(*mq.Options)) (*mq.ListBrokersOutput, error)
+	DescribeBroker(context.Context, *mq.DescribeBrokerInput, ...func(*mq.Options)) (*mq.DescribeBrokerOutput, error)
 }
 
 func init() {
 	gob.Register([]mqTypes.BrokerSummary{})
+	gob.Register(customDescribeBrokerOutput{})
 }
 
 // create CachedMQListBrokers function that uses go-cache and pagination
@@ -56,3 +58,53 @@ func CachedMQListBrokers(client MQClientInterface, accountID string, region stri
 	internal.Cache.Set(cacheKey, brokers, cache.DefaultExpiration)
 	return brokers, nil
 }
+
+// The parts of DescribeBrokerOutput that we care about. The full output can't be gob encoded for the cache.
+type customDescribeBrokerOutput struct {
+	AuthenticationStrategy mqTypes.AuthenticationStrategy
+	BrokerArn              *string
+	BrokerId               *string
+	BrokerInstances        []mqTypes.BrokerInstance
+	BrokerName             *string
+	EngineType             mqTypes.EngineType
+	EngineVersion          *string
+	PubliclyAccessible     *bool
+	Users                  []mqTypes.UserSummary
+}
+
+func CachedMQDescribeBroker(client MQClientInterface, accountID string, region string, brokerID string) (customDescribeBrokerOutput, error) {
+	var broker customDescribeBrokerOutput
+	cacheKey := fmt.Sprintf("%s-mq-DescribeBroker-%s-%s", accountID, region, brokerID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(customDescribeBrokerOutput), nil
+	}
+
+	DescribeBroker, err := client.DescribeBroker(
+		context.TODO(),
+		&mq.DescribeBrokerInput{
+			BrokerId: &brokerID,
+		},
+		func(o *mq.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return broker, err
+	}
+
+	broker = customDescribeBrokerOutput{
+		AuthenticationStrategy: DescribeBroker.AuthenticationStrategy,
+		BrokerArn:              DescribeBroker.BrokerArn,
+		BrokerId:               DescribeBroker.BrokerId,
+		BrokerInstances:        DescribeBroker.BrokerInstances,
+		BrokerName:             DescribeBroker.BrokerName,
+		EngineType:             DescribeBroker.EngineType,
+		EngineVersion:          DescribeBroker.EngineVersion,
+		PubliclyAccessible:     DescribeBroker.PubliclyAccessible,
+		Users:                  DescribeBroker.Users,
+	}
+
+	internal.Cache.Set(cacheKey, broker, cache.DefaultExpiration)
+	return broker, nil
+}
@@ -22,7 +22,7 @@ func (m *MockedMQClient) ListBrokers(ctx context.Context, input *mq.ListBrokersI
 				BrokerState:      mqTypes.BrokerStateRunning,
 				Created:          aws.Time(time.Now()),
 				DeploymentMode:   mqTypes.DeploymentModeSingleInstance,
-				EngineType:       mqTypes.EngineTypeRabbitmq,
+				EngineType:       mqTypes.EngineType("RabbitMQ"),
 				HostInstanceType: aws.String("host1"),
 			},
 			{
@@ -32,9 +32,42 @@ func (m *MockedMQClient) ListBrokers(ctx context.Context, input *mq.ListBrokersI
 				BrokerState:      mqTypes.BrokerStateRunning,
 				Created:          aws.Time(time.Now()),
 				DeploymentMode:   mqTypes.DeploymentModeSingleInstance,
-				EngineType:       mqTypes.EngineTypeActivemq,
+				EngineType:       mqTypes.EngineType("ActiveMQ"),
 				HostInstanceType: aws.String("host2"),
 			},
 		},
 	}, nil
 }
+
+func (m *MockedMQClient) DescribeBroker(ctx context.Context, input *mq.DescribeBrokerInput, options ...func(*mq.Options)) (*mq.DescribeBrokerOutput, error) {
+	switch aws.ToString(input.BrokerId) {
+	case "broker1":
+		return &mq.DescribeBrokerOutput{
+			BrokerId:           aws.String("broker1"),
+			BrokerName:         aws.String("broker1"),
+			EngineType:         mqTypes.EngineType("RabbitMQ"),
+			EngineVersion:      aws.String("3.11.20"),
+			PubliclyAccessible: aws.Bool(true),
+			BrokerInstances: []mqTypes.BrokerInstance{
+				{
+					ConsoleURL: aws.String("https://b-1111.mq.us-east-1.amazonaws.com"),
+					Endpoints:  []string{"amqps://b-1111.mq.us-east-1.amazonaws.com:5671"},
+				},
+			},
+		}, nil
+	default:
+		return &mq.DescribeBrokerOutput{
+			BrokerId:           aws.String("broker2"),
+			BrokerName:         aws.String("broker2"),
+			EngineType:         mqTypes.EngineType("ActiveMQ"),
+			EngineVersion:      aws.String("5.17.6"),
+			PubliclyAccessible: aws.Bool(false),
+			BrokerInstances: []mqTypes.BrokerInstance{
+				{
+					ConsoleURL: aws.String("https://b-2222-1.mq.us-east-1.amazonaws.com:8162"),
+					Endpoints:  []string{"ssl://b-2222-1.mq.us-east-1.amazonaws.com:61617"},
+				},
+			},
+		}, nil
+	}
+}
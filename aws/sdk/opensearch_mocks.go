@@ -54,10 +54,21 @@ func (m *MockedOpenSearchClient) DescribeDomainConfig(ctx context.Context, input
 }
 
 func (m *MockedOpenSearchClient) DescribeDomain(ctx context.Context, input *opensearch.DescribeDomainInput, options ...func(*opensearch.Options)) (*opensearch.DescribeDomainOutput, error) {
+	if aws.ToString(input.DomainName) == "domain2" {
+		return &opensearch.DescribeDomainOutput{
+			DomainStatus: &openSearchTypes.DomainStatus{
+				ARN:           aws.String("arn:aws:es:us-east-1:123456789012:domain/domain2"),
+				DomainName:    aws.String("domain2"),
+				Endpoint:      aws.String("https://domain2.us-east-1.es.amazonaws.com"),
+				EngineVersion: aws.String("Elasticsearch_6.8"),
+			},
+		}, nil
+	}
 	return &opensearch.DescribeDomainOutput{
 		DomainStatus: &openSearchTypes.DomainStatus{
-			DomainName: aws.String("domain1"),
-			Endpoint:   aws.String("https://domain1.us-east-1.es.amazonaws.com"),
+			DomainName:    aws.String("domain1"),
+			Endpoint:      aws.String("https://domain1.us-east-1.es.amazonaws.com"),
+			EngineVersion: aws.String("OpenSearch_2.11"),
 		},
 	}, nil
 }
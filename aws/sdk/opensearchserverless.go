@@ -0,0 +1,245 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/opensearchserverless"
+	openSearchServerlessTypes "github.com/aws/aws-sdk-go-v2/service/opensearchserverless/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type OpenSearchServerlessClientInterface interface {
+	ListCollections(context.Context, *opensearchserverless.ListCollectionsInput, ...func(*opensearchserverless.Options)) (*opensearchserverless.ListCollectionsOutput, error)
+	BatchGetCollection(context.Context, *opensearchserverless.BatchGetCollectionInput, ...func(*opensearchserverless.Options)) (*opensearchserverless.BatchGetCollectionOutput, error)
+	ListSecurityPolicies(context.Context, *opensearchserverless.ListSecurityPoliciesInput, ...func(*opensearchserverless.Options)) (*opensearchserverless.ListSecurityPoliciesOutput, error)
+	GetSecurityPolicy(context.Context, *opensearchserverless.GetSecurityPolicyInput, ...func(*opensearchserverless.Options)) (*opensearchserverless.GetSecurityPolicyOutput, error)
+	ListAccessPolicies(context.Context, *opensearchserverless.ListAccessPoliciesInput, ...func(*opensearchserverless.Options)) (*opensearchserverless.ListAccessPoliciesOutput, error)
+	GetAccessPolicy(context.Context, *opensearchserverless.GetAccessPolicyInput, ...func(*opensearchserverless.Options)) (*opensearchserverless.GetAccessPolicyOutput, error)
+}
+
+func init() {
+	gob.Register([]openSearchServerlessTypes.CollectionSummary{})
+	gob.Register([]openSearchServerlessTypes.CollectionDetail{})
+	gob.Register([]openSearchServerlessTypes.SecurityPolicySummary{})
+	gob.Register([]openSearchServerlessTypes.AccessPolicySummary{})
+}
+
+func CachedOpenSearchServerlessListCollections(client OpenSearchServerlessClientInterface, accountID string, region string) ([]openSearchServerlessTypes.CollectionSummary, error) {
+	var PaginationControl *string
+	var collections []openSearchServerlessTypes.CollectionSummary
+	cacheKey := fmt.Sprintf("%s-opensearchserverless-ListCollections-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]openSearchServerlessTypes.CollectionSummary), nil
+	}
+
+	for {
+		ListCollections, err := client.ListCollections(
+			context.TODO(),
+			&opensearchserverless.ListCollectionsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *opensearchserverless.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return collections, err
+		}
+
+		collections = append(collections, ListCollections.CollectionSummaries...)
+
+		//pagination
+		if ListCollections.NextToken == nil {
+			break
+		}
+		PaginationControl = ListCollections.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, collections, cache.DefaultExpiration)
+	return collections, nil
+}
+
+// CachedOpenSearchServerlessBatchGetCollection returns the endpoints of the collections. BatchGetCollection takes at
+// most 100 collection IDs per call.
+func CachedOpenSearchServerlessBatchGetCollection(client OpenSearchServerlessClientInterface, accountID string, region string, collectionIDs []string) ([]openSearchServerlessTypes.CollectionDetail, error) {
+	var collections []openSearchServerlessTypes.CollectionDetail
+	if len(collectionIDs) == 0 {
+		return collections, nil
+	}
+	cacheKey := fmt.Sprintf("%s-opensearchserverless-BatchGetCollection-%s-%s-%d", accountID, region, collectionIDs[0], len(collectionIDs))
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]openSearchServerlessTypes.CollectionDetail), nil
+	}
+
+	for i := 0; i < len(collectionIDs); i += 100 {
+		end := i + 100
+		if end > len(collectionIDs) {
+			end = len(collectionIDs)
+		}
+		BatchGetCollection, err := client.BatchGetCollection(
+			context.TODO(),
+			&opensearchserverless.BatchGetCollectionInput{
+				Ids: collectionIDs[i:end],
+			},
+			func(o *opensearchserverless.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return collections, err
+		}
+
+		collections = append(collections, BatchGetCollection.CollectionDetails...)
+	}
+
+	internal.Cache.Set(cacheKey, collections, cache.DefaultExpiration)
+	return collections, nil
+}
+
+func CachedOpenSearchServerlessListSecurityPolicies(client OpenSearchServerlessClientInterface, accountID string, region string, policyType openSearchServerlessTypes.SecurityPolicyType) ([]openSearchServerlessTypes.SecurityPolicySummary, error) {
+	var PaginationControl *string
+	var policies []openSearchServerlessTypes.SecurityPolicySummary
+	cacheKey := fmt.Sprintf("%s-opensearchserverless-ListSecurityPolicies-%s-%s", accountID, region, policyType)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]openSearchServerlessTypes.SecurityPolicySummary), nil
+	}
+
+	for {
+		ListSecurityPolicies, err := client.ListSecurityPolicies(
+			context.TODO(),
+			&opensearchserverless.ListSecurityPoliciesInput{
+				Type:      policyType,
+				NextToken: PaginationControl,
+			},
+			func(o *opensearchserverless.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return policies, err
+		}
+
+		policies = append(policies, ListSecurityPolicies.SecurityPolicySummaries...)
+
+		//pagination
+		if ListSecurityPolicies.NextToken == nil {
+			break
+		}
+		PaginationControl = ListSecurityPolicies.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, policies, cache.DefaultExpiration)
+	return policies, nil
+}
+
+// CachedOpenSearchServerlessGetSecurityPolicy returns the policy document as JSON. The SDK hands it back as a smithy
+// document, which can't be stored in the cache.
+func CachedOpenSearchServerlessGetSecurityPolicy(client OpenSearchServerlessClientInterface, accountID string, region string, policyName string, policyType openSearchServerlessTypes.SecurityPolicyType) (string, error) {
+	cacheKey := fmt.Sprintf("%s-opensearchserverless-GetSecurityPolicy-%s-%s-%s", accountID, region, policyType, policyName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(string), nil
+	}
+
+	GetSecurityPolicy, err := client.GetSecurityPolicy(
+		context.TODO(),
+		&opensearchserverless.GetSecurityPolicyInput{
+			Name: aws.String(policyName),
+			Type: policyType,
+		},
+		func(o *opensearchserverless.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+	if GetSecurityPolicy.SecurityPolicyDetail == nil || GetSecurityPolicy.SecurityPolicyDetail.Policy == nil {
+		return "", fmt.Errorf("security policy %s not found", policyName)
+	}
+	policy, err := GetSecurityPolicy.SecurityPolicyDetail.Policy.MarshalSmithyDocument()
+	if err != nil {
+		return "", err
+	}
+
+	internal.Cache.Set(cacheKey, string(policy), cache.DefaultExpiration)
+	return string(policy), nil
+}
+
+func CachedOpenSearchServerlessListAccessPolicies(client OpenSearchServerlessClientInterface, accountID string, region string, policyType openSearchServerlessTypes.AccessPolicyType) ([]openSearchServerlessTypes.AccessPolicySummary, error) {
+	var PaginationControl *string
+	var policies []openSearchServerlessTypes.AccessPolicySummary
+	cacheKey := fmt.Sprintf("%s-opensearchserverless-ListAccessPolicies-%s-%s", accountID, region, policyType)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]openSearchServerlessTypes.AccessPolicySummary), nil
+	}
+
+	for {
+		ListAccessPolicies, err := client.ListAccessPolicies(
+			context.TODO(),
+			&opensearchserverless.ListAccessPoliciesInput{
+				Type:      policyType,
+				NextToken: PaginationControl,
+			},
+			func(o *opensearchserverless.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return policies, err
+		}
+
+		policies = append(policies, ListAccessPolicies.AccessPolicySummaries...)
+
+		//pagination
+		if ListAccessPolicies.NextToken == nil {
+			break
+		}
+		PaginationControl = ListAccessPolicies.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, policies, cache.DefaultExpiration)
+	return policies, nil
+}
+
+// CachedOpenSearchServerlessGetAccessPolicy returns the policy document as JSON, like
+// CachedOpenSearchServerlessGetSecurityPolicy
+func CachedOpenSearchServerlessGetAccessPolicy(client OpenSearchServerlessClientInterface, accountID string, region string, policyName string, policyType openSearchServerlessTypes.AccessPolicyType) (string, error) {
+	cacheKey := fmt.Sprintf("%s-opensearchserverless-GetAccessPolicy-%s-%s-%s", accountID, region, policyType, policyName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(string), nil
+	}
+
+	GetAccessPolicy, err := client.GetAccessPolicy(
+		context.TODO(),
+		&opensearchserverless.GetAccessPolicyInput{
+			Name: aws.String(policyName),
+			Type: policyType,
+		},
+		func(o *opensearchserverless.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+	if GetAccessPolicy.AccessPolicyDetail == nil || GetAccessPolicy.AccessPolicyDetail.Policy == nil {
+		return "", fmt.Errorf("access policy %s not found", policyName)
+	}
+	policy, err := GetAccessPolicy.AccessPolicyDetail.Policy.MarshalSmithyDocument()
+	if err != nil {
+		return "", err
+	}
+
+	internal.Cache.Set(cacheKey, string(policy), cache.DefaultExpiration)
+	return string(policy), nil
+}
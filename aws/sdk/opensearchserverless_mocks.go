@@ -0,0 +1,128 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/opensearchserverless"
+	"github.com/aws/aws-sdk-go-v2/service/opensearchserverless/document"
+	openSearchServerlessTypes "github.com/aws/aws-sdk-go-v2/service/opensearchserverless/types"
+)
+
+// MockedOpenSearchServerlessClient has a logs collection that is reachable from the internet, a search collection
+// that is only reachable through a VPC endpoint but grants aoss:* to everyone, and a locked down vectors collection
+// whose dashboards are public
+type MockedOpenSearchServerlessClient struct {
+}
+
+var mockedOpenSearchServerlessPolicies = map[string]string{
+	"public-logs":       `[{"Rules":[{"ResourceType":"collection","Resource":["collection/logs*"]}],"AllowFromPublic":true}]`,
+	"vpc-only":          `[{"Rules":[{"ResourceType":"collection","Resource":["collection/search","collection/vectors"]}],"AllowFromPublic":false,"SourceVPCEs":["vpce-0a1b2c3d4e5f60001"]}]`,
+	"public-dashboards": `[{"Rules":[{"ResourceType":"dashboard","Resource":["collection/vectors"]}],"AllowFromPublic":true}]`,
+	"open-search":       `[{"Rules":[{"ResourceType":"index","Resource":["index/search/*"],"Permission":["aoss:*"]}],"Principal":["*"]}]`,
+	"vectors-readers":   `[{"Rules":[{"ResourceType":"index","Resource":["index/vectors/*"],"Permission":["aoss:ReadDocument"]},{"ResourceType":"collection","Resource":["collection/*"],"Permission":["aoss:DescribeCollectionItems"]}],"Principal":["arn:aws:iam::123456789012:role/vectors-reader"]}]`,
+}
+
+func mockedOpenSearchServerlessPolicy(name string) (document.Interface, error) {
+	policy, ok := mockedOpenSearchServerlessPolicies[name]
+	if !ok {
+		return nil, fmt.Errorf("policy %s not found", name)
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(policy), &v); err != nil {
+		return nil, err
+	}
+	return document.NewLazyDocument(v), nil
+}
+
+func (m *MockedOpenSearchServerlessClient) ListCollections(ctx context.Context, input *opensearchserverless.ListCollectionsInput, options ...func(*opensearchserverless.Options)) (*opensearchserverless.ListCollectionsOutput, error) {
+	return &opensearchserverless.ListCollectionsOutput{
+		CollectionSummaries: []openSearchServerlessTypes.CollectionSummary{
+			{
+				Id:     aws.String("logs0000000000000001"),
+				Name:   aws.String("logs"),
+				Status: openSearchServerlessTypes.CollectionStatusActive,
+			},
+			{
+				Id:     aws.String("search00000000000002"),
+				Name:   aws.String("search"),
+				Status: openSearchServerlessTypes.CollectionStatusActive,
+			},
+			{
+				Id:     aws.String("vectors0000000000003"),
+				Name:   aws.String("vectors"),
+				Status: openSearchServerlessTypes.CollectionStatusActive,
+			},
+		},
+	}, nil
+}
+
+func (m *MockedOpenSearchServerlessClient) BatchGetCollection(ctx context.Context, input *opensearchserverless.BatchGetCollectionInput, options ...func(*opensearchserverless.Options)) (*opensearchserverless.BatchGetCollectionOutput, error) {
+	collectionTypes := map[string]openSearchServerlessTypes.CollectionType{
+		"logs0000000000000001": openSearchServerlessTypes.CollectionTypeTimeseries,
+		"search00000000000002": openSearchServerlessTypes.CollectionTypeSearch,
+		"vectors0000000000003": openSearchServerlessTypes.CollectionTypeVectorsearch,
+	}
+	var collections []openSearchServerlessTypes.CollectionDetail
+	for _, id := range input.Ids {
+		collections = append(collections, openSearchServerlessTypes.CollectionDetail{
+			Id:                 aws.String(id),
+			Type:               collectionTypes[id],
+			CollectionEndpoint: aws.String(fmt.Sprintf("https://%s.us-east-1.aoss.amazonaws.com", id)),
+			DashboardEndpoint:  aws.String(fmt.Sprintf("https://%s.us-east-1.aoss.amazonaws.com/_dashboards", id)),
+		})
+	}
+	return &opensearchserverless.BatchGetCollectionOutput{CollectionDetails: collections}, nil
+}
+
+func (m *MockedOpenSearchServerlessClient) ListSecurityPolicies(ctx context.Context, input *opensearchserverless.ListSecurityPoliciesInput, options ...func(*opensearchserverless.Options)) (*opensearchserverless.ListSecurityPoliciesOutput, error) {
+	if input.Type != openSearchServerlessTypes.SecurityPolicyTypeNetwork {
+		return &opensearchserverless.ListSecurityPoliciesOutput{}, nil
+	}
+	return &opensearchserverless.ListSecurityPoliciesOutput{
+		SecurityPolicySummaries: []openSearchServerlessTypes.SecurityPolicySummary{
+			{Name: aws.String("public-logs"), Type: openSearchServerlessTypes.SecurityPolicyTypeNetwork},
+			{Name: aws.String("vpc-only"), Type: openSearchServerlessTypes.SecurityPolicyTypeNetwork},
+			{Name: aws.String("public-dashboards"), Type: openSearchServerlessTypes.SecurityPolicyTypeNetwork},
+		},
+	}, nil
+}
+
+func (m *MockedOpenSearchServerlessClient) GetSecurityPolicy(ctx context.Context, input *opensearchserverless.GetSecurityPolicyInput, options ...func(*opensearchserverless.Options)) (*opensearchserverless.GetSecurityPolicyOutput, error) {
+	policy, err := mockedOpenSearchServerlessPolicy(aws.ToString(input.Name))
+	if err != nil {
+		return nil, err
+	}
+	return &opensearchserverless.GetSecurityPolicyOutput{
+		SecurityPolicyDetail: &openSearchServerlessTypes.SecurityPolicyDetail{
+			Name:   input.Name,
+			Type:   input.Type,
+			Policy: policy,
+		},
+	}, nil
+}
+
+func (m *MockedOpenSearchServerlessClient) ListAccessPolicies(ctx context.Context, input *opensearchserverless.ListAccessPoliciesInput, options ...func(*opensearchserverless.Options)) (*opensearchserverless.ListAccessPoliciesOutput, error) {
+	return &opensearchserverless.ListAccessPoliciesOutput{
+		AccessPolicySummaries: []openSearchServerlessTypes.AccessPolicySummary{
+			{Name: aws.String("open-search"), Type: openSearchServerlessTypes.AccessPolicyTypeData},
+			{Name: aws.String("vectors-readers"), Type: openSearchServerlessTypes.AccessPolicyTypeData},
+		},
+	}, nil
+}
+
+func (m *MockedOpenSearchServerlessClient) GetAccessPolicy(ctx context.Context, input *opensearchserverless.GetAccessPolicyInput, options ...func(*opensearchserverless.Options)) (*opensearchserverless.GetAccessPolicyOutput, error) {
+	policy, err := mockedOpenSearchServerlessPolicy(aws.ToString(input.Name))
+	if err != nil {
+		return nil, err
+	}
+	return &opensearchserverless.GetAccessPolicyOutput{
+		AccessPolicyDetail: &openSearchServerlessTypes.AccessPolicyDetail{
+			Name:   input.Name,
+			Type:   input.Type,
+			Policy: policy,
+		},
+	}, nil
+}
@@ -0,0 +1,93 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ram"
+	ramTypes "github.com/aws/aws-sdk-go-v2/service/ram/types"
+)
+
+type MockedRAMClient struct {
+}
+
+// network-share is shared by another account of the organization, vendor-share by an account outside of it. Both
+// allow external principals, which only says the owner may share outside the organization. builds-share is ours.
+var mockedRAMResourceShares = map[ramTypes.ResourceOwner][]ramTypes.ResourceShare{
+	ramTypes.ResourceOwnerOtherAccounts: {
+		{
+			Name:                    aws.String("network-share"),
+			ResourceShareArn:        aws.String("arn:aws:ram:us-east-1:111111111111:resource-share/network-share"),
+			OwningAccountId:         aws.String("111111111111"),
+			AllowExternalPrincipals: aws.Bool(true),
+			Status:                  ramTypes.ResourceShareStatusActive,
+		},
+		{
+			Name:                    aws.String("vendor-share"),
+			ResourceShareArn:        aws.String("arn:aws:ram:us-east-1:999999999999:resource-share/vendor-share"),
+			OwningAccountId:         aws.String("999999999999"),
+			AllowExternalPrincipals: aws.Bool(true),
+			Status:                  ramTypes.ResourceShareStatusActive,
+		},
+	},
+	ramTypes.ResourceOwnerSelf: {
+		{
+			Name:                    aws.String("builds-share"),
+			ResourceShareArn:        aws.String("arn:aws:ram:us-east-1:123456789012:resource-share/builds-share"),
+			OwningAccountId:         aws.String("123456789012"),
+			AllowExternalPrincipals: aws.Bool(true),
+			Status:                  ramTypes.ResourceShareStatusActive,
+		},
+	},
+}
+
+var mockedRAMResources = map[string][]ramTypes.Resource{
+	"arn:aws:ram:us-east-1:111111111111:resource-share/network-share": {
+		{
+			Arn:    aws.String("arn:aws:ec2:us-east-1:111111111111:subnet/subnet-0a1b2c3d"),
+			Type:   aws.String("ec2:Subnet"),
+			Status: ramTypes.ResourceStatusAvailable,
+		},
+	},
+	"arn:aws:ram:us-east-1:999999999999:resource-share/vendor-share": {
+		{
+			Arn:    aws.String("arn:aws:ec2:us-east-1:999999999999:prefix-list/pl-0a1b2c3d"),
+			Type:   aws.String("ec2:PrefixList"),
+			Status: ramTypes.ResourceStatusAvailable,
+		},
+	},
+	"arn:aws:ram:us-east-1:123456789012:resource-share/builds-share": {
+		{
+			Arn:    aws.String("arn:aws:codebuild:us-east-1:123456789012:project/builds"),
+			Type:   aws.String("codebuild:Project"),
+			Status: ramTypes.ResourceStatusAvailable,
+		},
+	},
+}
+
+var mockedRAMPrincipals = map[string][]ramTypes.Principal{
+	"arn:aws:ram:us-east-1:123456789012:resource-share/builds-share": {
+		{Id: aws.String("222222222222"), External: aws.Bool(false)},
+		{Id: aws.String("444455556666"), External: aws.Bool(true)},
+	},
+}
+
+func (m *MockedRAMClient) GetResourceShares(ctx context.Context, input *ram.GetResourceSharesInput, options ...func(*ram.Options)) (*ram.GetResourceSharesOutput, error) {
+	return &ram.GetResourceSharesOutput{ResourceShares: mockedRAMResourceShares[input.ResourceOwner]}, nil
+}
+
+func (m *MockedRAMClient) ListResources(ctx context.Context, input *ram.ListResourcesInput, options ...func(*ram.Options)) (*ram.ListResourcesOutput, error) {
+	var resources []ramTypes.Resource
+	for _, shareArn := range input.ResourceShareArns {
+		resources = append(resources, mockedRAMResources[shareArn]...)
+	}
+	return &ram.ListResourcesOutput{Resources: resources}, nil
+}
+
+func (m *MockedRAMClient) ListPrincipals(ctx context.Context, input *ram.ListPrincipalsInput, options ...func(*ram.Options)) (*ram.ListPrincipalsOutput, error) {
+	var principals []ramTypes.Principal
+	for _, shareArn := range input.ResourceShareArns {
+		principals = append(principals, mockedRAMPrincipals[shareArn]...)
+	}
+	return &ram.ListPrincipalsOutput{Principals: principals}, nil
+}
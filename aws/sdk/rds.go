@@ -15,11 +15,15 @@ import (
 type RDSClientInterface interface {
 	DescribeDBInstances(context.Context, *rds.DescribeDBInstancesInput, ...func(*rds.Options)) (*rds.DescribeDBInstancesOutput, error)
 	DescribeDBClusters(context.Context, *rds.DescribeDBClustersInput, ...func(*rds.Options)) (*rds.DescribeDBClustersOutput, error)
+	DescribeDBProxies(context.Context, *rds.DescribeDBProxiesInput, ...func(*rds.Options)) (*rds.DescribeDBProxiesOutput, error)
+	DescribeGlobalClusters(context.Context, *rds.DescribeGlobalClustersInput, ...func(*rds.Options)) (*rds.DescribeGlobalClustersOutput, error)
 }
 
 func init() {
 	gob.Register([]rdsTypes.DBInstance{})
 	gob.Register([]rdsTypes.DBCluster{})
+	gob.Register([]rdsTypes.DBProxy{})
+	gob.Register([]rdsTypes.GlobalCluster{})
 
 }
 
@@ -94,3 +98,75 @@ func CachedRDSDescribeDBClusters(client RDSClientInterface, accountID string, re
 	internal.Cache.Set(cacheKey, clusters, cache.DefaultExpiration)
 	return clusters, nil
 }
+
+func CachedRDSDescribeDBProxies(client RDSClientInterface, accountID string, region string) ([]rdsTypes.DBProxy, error) {
+	var PaginationControl *string
+	var proxies []rdsTypes.DBProxy
+	cacheKey := fmt.Sprintf("%s-rds-DescribeDBProxies-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]rdsTypes.DBProxy), nil
+	}
+	for {
+		DescribeDBProxies, err := client.DescribeDBProxies(
+			context.TODO(),
+			&rds.DescribeDBProxiesInput{
+				Marker: PaginationControl,
+			},
+			func(o *rds.Options) {
+				o.Region = region
+			},
+		)
+
+		if err != nil {
+			return proxies, err
+		}
+
+		proxies = append(proxies, DescribeDBProxies.DBProxies...)
+
+		//pagination
+		if DescribeDBProxies.Marker == nil {
+			break
+		}
+		PaginationControl = DescribeDBProxies.Marker
+	}
+
+	internal.Cache.Set(cacheKey, proxies, cache.DefaultExpiration)
+	return proxies, nil
+}
+
+func CachedRDSDescribeGlobalClusters(client RDSClientInterface, accountID string, region string) ([]rdsTypes.GlobalCluster, error) {
+	var PaginationControl *string
+	var globalClusters []rdsTypes.GlobalCluster
+	cacheKey := fmt.Sprintf("%s-rds-DescribeGlobalClusters-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]rdsTypes.GlobalCluster), nil
+	}
+	for {
+		DescribeGlobalClusters, err := client.DescribeGlobalClusters(
+			context.TODO(),
+			&rds.DescribeGlobalClustersInput{
+				Marker: PaginationControl,
+			},
+			func(o *rds.Options) {
+				o.Region = region
+			},
+		)
+
+		if err != nil {
+			return globalClusters, err
+		}
+
+		globalClusters = append(globalClusters, DescribeGlobalClusters.GlobalClusters...)
+
+		//pagination
+		if DescribeGlobalClusters.Marker == nil {
+			break
+		}
+		PaginationControl = DescribeGlobalClusters.Marker
+	}
+
+	internal.Cache.Set(cacheKey, globalClusters, cache.DefaultExpiration)
+	return globalClusters, nil
+}
@@ -90,3 +90,91 @@ func (m *MockedRDSClient) DescribeDBClusters(ctx context.Context, input *rds.Des
 		},
 	}, nil
 }
+
+func (m *MockedRDSClient) DescribeDBProxies(ctx context.Context, input *rds.DescribeDBProxiesInput, options ...func(*rds.Options)) (*rds.DescribeDBProxiesOutput, error) {
+	return &rds.DescribeDBProxiesOutput{
+		DBProxies: []rdsTypes.DBProxy{
+			{
+				DBProxyName:  aws.String("proxy1"),
+				DBProxyArn:   aws.String("arn:aws:rds:us-east-1:123456789012:db-proxy:prx-1111"),
+				EngineFamily: aws.String("POSTGRESQL"),
+				Endpoint:     aws.String("proxy1.proxy-blah.us-east-1.rds.amazonaws.com"),
+				Status:       rdsTypes.DBProxyStatusAvailable,
+				RequireTLS:   aws.Bool(true),
+				RoleArn:      aws.String("arn:aws:iam::123456789012:role/proxy1-role"),
+				VpcId:        aws.String("vpc-11111111"),
+				Auth: []rdsTypes.UserAuthConfigInfo{
+					{
+						AuthScheme: rdsTypes.AuthSchemeSecrets,
+						IAMAuth:    rdsTypes.IAMAuthModeRequired,
+						SecretArn:  aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:proxy1-secret"),
+					},
+				},
+			},
+			{
+				DBProxyName:  aws.String("proxy2"),
+				DBProxyArn:   aws.String("arn:aws:rds:us-east-1:123456789012:db-proxy:prx-2222"),
+				EngineFamily: aws.String("MYSQL"),
+				Endpoint:     aws.String("proxy2.proxy-blah.us-east-1.rds.amazonaws.com"),
+				Status:       rdsTypes.DBProxyStatusAvailable,
+				RequireTLS:   aws.Bool(false),
+				RoleArn:      aws.String("arn:aws:iam::123456789012:role/proxy2-role"),
+				VpcId:        aws.String("vpc-22222222"),
+				Auth: []rdsTypes.UserAuthConfigInfo{
+					{
+						AuthScheme: rdsTypes.AuthSchemeSecrets,
+						IAMAuth:    rdsTypes.IAMAuthModeDisabled,
+						SecretArn:  aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:proxy2-secret"),
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (m *MockedRDSClient) DescribeGlobalClusters(ctx context.Context, input *rds.DescribeGlobalClustersInput, options ...func(*rds.Options)) (*rds.DescribeGlobalClustersOutput, error) {
+	return &rds.DescribeGlobalClustersOutput{
+		GlobalClusters: []rdsTypes.GlobalCluster{
+			{
+				GlobalClusterIdentifier: aws.String("orders-global"),
+				GlobalClusterArn:        aws.String("arn:aws:rds::123456789012:global-cluster:orders-global"),
+				Engine:                  aws.String("aurora-postgresql"),
+				EngineVersion:           aws.String("15.4"),
+				Status:                  aws.String("available"),
+				StorageEncrypted:        aws.Bool(true),
+				GlobalClusterMembers: []rdsTypes.GlobalClusterMember{
+					{
+						DBClusterArn: aws.String("arn:aws:rds:us-east-1:123456789012:cluster:orders-primary"),
+						IsWriter:     aws.Bool(true),
+						Readers: []string{
+							"arn:aws:rds:us-west-2:123456789012:cluster:orders-secondary",
+							"arn:aws:rds:eu-west-1:210987654321:cluster:orders-partner",
+						},
+					},
+					{
+						DBClusterArn: aws.String("arn:aws:rds:us-west-2:123456789012:cluster:orders-secondary"),
+						IsWriter:     aws.Bool(false),
+					},
+					{
+						DBClusterArn: aws.String("arn:aws:rds:eu-west-1:210987654321:cluster:orders-partner"),
+						IsWriter:     aws.Bool(false),
+					},
+				},
+			},
+			{
+				GlobalClusterIdentifier: aws.String("inventory-global"),
+				GlobalClusterArn:        aws.String("arn:aws:rds::123456789012:global-cluster:inventory-global"),
+				Engine:                  aws.String("aurora-mysql"),
+				EngineVersion:           aws.String("8.0.mysql_aurora.3.04.0"),
+				Status:                  aws.String("available"),
+				StorageEncrypted:        aws.Bool(false),
+				GlobalClusterMembers: []rdsTypes.GlobalClusterMember{
+					{
+						DBClusterArn: aws.String("arn:aws:rds:us-east-1:123456789012:cluster:inventory"),
+						IsWriter:     aws.Bool(true),
+					},
+				},
+			},
+		},
+	}, nil
+}
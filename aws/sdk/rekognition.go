@@ -0,0 +1,106 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/service/rekognition"
+	rekognitionTypes "github.com/aws/aws-sdk-go-v2/service/rekognition/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type RekognitionClientInterface interface {
+	ListStreamProcessors(context.Context, *rekognition.ListStreamProcessorsInput, ...func(*rekognition.Options)) (*rekognition.ListStreamProcessorsOutput, error)
+	DescribeStreamProcessor(context.Context, *rekognition.DescribeStreamProcessorInput, ...func(*rekognition.Options)) (*rekognition.DescribeStreamProcessorOutput, error)
+}
+
+func init() {
+	gob.Register([]rekognitionTypes.StreamProcessor{})
+	gob.Register(customDescribeStreamProcessorOutput{})
+}
+
+func CachedRekognitionListStreamProcessors(client RekognitionClientInterface, accountID string, region string) ([]rekognitionTypes.StreamProcessor, error) {
+	var PaginationControl *string
+	var streamProcessors []rekognitionTypes.StreamProcessor
+	cacheKey := fmt.Sprintf("%s-rekognition-ListStreamProcessors-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]rekognitionTypes.StreamProcessor), nil
+	}
+
+	for {
+		ListStreamProcessors, err := client.ListStreamProcessors(
+			context.TODO(),
+			&rekognition.ListStreamProcessorsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *rekognition.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return streamProcessors, err
+		}
+
+		streamProcessors = append(streamProcessors, ListStreamProcessors.StreamProcessors...)
+
+		//pagination
+		if ListStreamProcessors.NextToken == nil {
+			break
+		}
+		PaginationControl = ListStreamProcessors.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, streamProcessors, cache.DefaultExpiration)
+	return streamProcessors, nil
+}
+
+// The parts of DescribeStreamProcessorOutput that we care about. The full output can't be gob encoded for the cache.
+type customDescribeStreamProcessorOutput struct {
+	Name               *string
+	StreamProcessorArn *string
+	Status             rekognitionTypes.StreamProcessorStatus
+	Input              *rekognitionTypes.StreamProcessorInput
+	Output             *rekognitionTypes.StreamProcessorOutput
+	Settings           *rekognitionTypes.StreamProcessorSettings
+	RoleArn            *string
+	KmsKeyId           *string
+}
+
+func CachedRekognitionDescribeStreamProcessor(client RekognitionClientInterface, accountID string, region string, name string) (customDescribeStreamProcessorOutput, error) {
+	var streamProcessor customDescribeStreamProcessorOutput
+	cacheKey := fmt.Sprintf("%s-rekognition-DescribeStreamProcessor-%s-%s", accountID, region, name)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(customDescribeStreamProcessorOutput), nil
+	}
+
+	DescribeStreamProcessor, err := client.DescribeStreamProcessor(
+		context.TODO(),
+		&rekognition.DescribeStreamProcessorInput{
+			Name: &name,
+		},
+		func(o *rekognition.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return streamProcessor, err
+	}
+
+	streamProcessor = customDescribeStreamProcessorOutput{
+		Name:               DescribeStreamProcessor.Name,
+		StreamProcessorArn: DescribeStreamProcessor.StreamProcessorArn,
+		Status:             DescribeStreamProcessor.Status,
+		Input:              DescribeStreamProcessor.Input,
+		Output:             DescribeStreamProcessor.Output,
+		Settings:           DescribeStreamProcessor.Settings,
+		RoleArn:            DescribeStreamProcessor.RoleArn,
+		KmsKeyId:           DescribeStreamProcessor.KmsKeyId,
+	}
+
+	internal.Cache.Set(cacheKey, streamProcessor, cache.DefaultExpiration)
+	return streamProcessor, nil
+}
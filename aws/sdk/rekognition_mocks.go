@@ -0,0 +1,82 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rekognition"
+	rekognitionTypes "github.com/aws/aws-sdk-go-v2/service/rekognition/types"
+)
+
+type MockedRekognitionClient struct {
+}
+
+// lobby-faces and dock-faces search faces from two cameras and write the matches to the same public bucket, lobby-faces
+// with a role that can read every video stream. doorbell-labels only looks for packages and writes to a private bucket.
+var mockedRekognitionStreamProcessors = map[string]rekognition.DescribeStreamProcessorOutput{
+	"lobby-faces": {
+		Name:   aws.String("lobby-faces"),
+		Status: rekognitionTypes.StreamProcessorStatusRunning,
+		Input: &rekognitionTypes.StreamProcessorInput{
+			KinesisVideoStream: &rekognitionTypes.KinesisVideoStream{Arn: aws.String("arn:aws:kinesisvideo:us-east-1:123456789012:stream/lobby-camera/1650000000000")},
+		},
+		Output: &rekognitionTypes.StreamProcessorOutput{
+			S3Destination: &rekognitionTypes.S3Destination{Bucket: aws.String("camera-analytics"), KeyPrefix: aws.String("lobby/")},
+		},
+		Settings: &rekognitionTypes.StreamProcessorSettings{
+			FaceSearch: &rekognitionTypes.FaceSearchSettings{CollectionId: aws.String("employees"), FaceMatchThreshold: aws.Float32(85)},
+		},
+		RoleArn: aws.String("arn:aws:iam::123456789012:role/rekognition-video"),
+	},
+	"dock-faces": {
+		Name:   aws.String("dock-faces"),
+		Status: rekognitionTypes.StreamProcessorStatusStopped,
+		Input: &rekognitionTypes.StreamProcessorInput{
+			KinesisVideoStream: &rekognitionTypes.KinesisVideoStream{Arn: aws.String("arn:aws:kinesisvideo:us-east-1:123456789012:stream/dock-camera/1650000000001")},
+		},
+		Output: &rekognitionTypes.StreamProcessorOutput{
+			S3Destination: &rekognitionTypes.S3Destination{Bucket: aws.String("camera-analytics"), KeyPrefix: aws.String("dock/")},
+		},
+		Settings: &rekognitionTypes.StreamProcessorSettings{
+			FaceSearch: &rekognitionTypes.FaceSearchSettings{CollectionId: aws.String("employees")},
+		},
+		RoleArn: aws.String("arn:aws:iam::123456789012:role/rekognition-dock"),
+	},
+	"doorbell-labels": {
+		Name:   aws.String("doorbell-labels"),
+		Status: rekognitionTypes.StreamProcessorStatusRunning,
+		Input: &rekognitionTypes.StreamProcessorInput{
+			KinesisVideoStream: &rekognitionTypes.KinesisVideoStream{Arn: aws.String("arn:aws:kinesisvideo:us-east-1:123456789012:stream/doorbell/1650000000002")},
+		},
+		Output: &rekognitionTypes.StreamProcessorOutput{
+			S3Destination: &rekognitionTypes.S3Destination{Bucket: aws.String("doorbell-events")},
+		},
+		Settings: &rekognitionTypes.StreamProcessorSettings{
+			ConnectedHome: &rekognitionTypes.ConnectedHomeSettings{Labels: []string{"PACKAGE", "PERSON"}},
+		},
+		RoleArn: aws.String("arn:aws:iam::123456789012:role/rekognition-doorbell"),
+	},
+}
+
+func (m *MockedRekognitionClient) ListStreamProcessors(ctx context.Context, input *rekognition.ListStreamProcessorsInput, options ...func(*rekognition.Options)) (*rekognition.ListStreamProcessorsOutput, error) {
+	var streamProcessors []rekognitionTypes.StreamProcessor
+	for _, name := range []string{"lobby-faces", "dock-faces", "doorbell-labels"} {
+		streamProcessors = append(streamProcessors, rekognitionTypes.StreamProcessor{
+			Name:   aws.String(name),
+			Status: mockedRekognitionStreamProcessors[name].Status,
+		})
+	}
+	return &rekognition.ListStreamProcessorsOutput{
+		StreamProcessors: streamProcessors,
+	}, nil
+}
+
+func (m *MockedRekognitionClient) DescribeStreamProcessor(ctx context.Context, input *rekognition.DescribeStreamProcessorInput, options ...func(*rekognition.Options)) (*rekognition.DescribeStreamProcessorOutput, error) {
+	streamProcessor, ok := mockedRekognitionStreamProcessors[aws.ToString(input.Name)]
+	if !ok {
+		return nil, fmt.Errorf("stream processor %s not found", aws.ToString(input.Name))
+	}
+	streamProcessor.StreamProcessorArn = aws.String(fmt.Sprintf("arn:aws:rekognition:us-east-1:123456789012:streamprocessor/%s", aws.ToString(input.Name)))
+	return &streamProcessor, nil
+}
@@ -0,0 +1,211 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/service/route53resolver"
+	route53resolverTypes "github.com/aws/aws-sdk-go-v2/service/route53resolver/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type Route53ResolverClientInterface interface {
+	ListFirewallRuleGroups(context.Context, *route53resolver.ListFirewallRuleGroupsInput, ...func(*route53resolver.Options)) (*route53resolver.ListFirewallRuleGroupsOutput, error)
+	ListFirewallRuleGroupAssociations(context.Context, *route53resolver.ListFirewallRuleGroupAssociationsInput, ...func(*route53resolver.Options)) (*route53resolver.ListFirewallRuleGroupAssociationsOutput, error)
+	ListFirewallRules(context.Context, *route53resolver.ListFirewallRulesInput, ...func(*route53resolver.Options)) (*route53resolver.ListFirewallRulesOutput, error)
+	ListFirewallDomainLists(context.Context, *route53resolver.ListFirewallDomainListsInput, ...func(*route53resolver.Options)) (*route53resolver.ListFirewallDomainListsOutput, error)
+	ListFirewallDomains(context.Context, *route53resolver.ListFirewallDomainsInput, ...func(*route53resolver.Options)) (*route53resolver.ListFirewallDomainsOutput, error)
+}
+
+func init() {
+	gob.Register([]route53resolverTypes.FirewallRuleGroupMetadata{})
+	gob.Register([]route53resolverTypes.FirewallRuleGroupAssociation{})
+	gob.Register([]route53resolverTypes.FirewallRule{})
+	gob.Register([]route53resolverTypes.FirewallDomainListMetadata{})
+}
+
+func CachedRoute53ResolverListFirewallRuleGroups(client Route53ResolverClientInterface, accountID string, region string) ([]route53resolverTypes.FirewallRuleGroupMetadata, error) {
+	var PaginationControl *string
+	var ruleGroups []route53resolverTypes.FirewallRuleGroupMetadata
+	cacheKey := fmt.Sprintf("%s-route53resolver-ListFirewallRuleGroups-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]route53resolverTypes.FirewallRuleGroupMetadata), nil
+	}
+
+	for {
+		ListFirewallRuleGroups, err := client.ListFirewallRuleGroups(
+			context.TODO(),
+			&route53resolver.ListFirewallRuleGroupsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *route53resolver.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return ruleGroups, err
+		}
+
+		ruleGroups = append(ruleGroups, ListFirewallRuleGroups.FirewallRuleGroups...)
+
+		//pagination
+		if ListFirewallRuleGroups.NextToken == nil {
+			break
+		}
+		PaginationControl = ListFirewallRuleGroups.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, ruleGroups, cache.DefaultExpiration)
+	return ruleGroups, nil
+}
+
+func CachedRoute53ResolverListFirewallRuleGroupAssociations(client Route53ResolverClientInterface, accountID string, region string) ([]route53resolverTypes.FirewallRuleGroupAssociation, error) {
+	var PaginationControl *string
+	var associations []route53resolverTypes.FirewallRuleGroupAssociation
+	cacheKey := fmt.Sprintf("%s-route53resolver-ListFirewallRuleGroupAssociations-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]route53resolverTypes.FirewallRuleGroupAssociation), nil
+	}
+
+	for {
+		ListFirewallRuleGroupAssociations, err := client.ListFirewallRuleGroupAssociations(
+			context.TODO(),
+			&route53resolver.ListFirewallRuleGroupAssociationsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *route53resolver.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return associations, err
+		}
+
+		associations = append(associations, ListFirewallRuleGroupAssociations.FirewallRuleGroupAssociations...)
+
+		//pagination
+		if ListFirewallRuleGroupAssociations.NextToken == nil {
+			break
+		}
+		PaginationControl = ListFirewallRuleGroupAssociations.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, associations, cache.DefaultExpiration)
+	return associations, nil
+}
+
+func CachedRoute53ResolverListFirewallRules(client Route53ResolverClientInterface, accountID string, region string, ruleGroupID string) ([]route53resolverTypes.FirewallRule, error) {
+	var PaginationControl *string
+	var rules []route53resolverTypes.FirewallRule
+	cacheKey := fmt.Sprintf("%s-route53resolver-ListFirewallRules-%s-%s", accountID, region, ruleGroupID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]route53resolverTypes.FirewallRule), nil
+	}
+
+	for {
+		ListFirewallRules, err := client.ListFirewallRules(
+			context.TODO(),
+			&route53resolver.ListFirewallRulesInput{
+				FirewallRuleGroupId: &ruleGroupID,
+				NextToken:           PaginationControl,
+			},
+			func(o *route53resolver.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return rules, err
+		}
+
+		rules = append(rules, ListFirewallRules.FirewallRules...)
+
+		//pagination
+		if ListFirewallRules.NextToken == nil {
+			break
+		}
+		PaginationControl = ListFirewallRules.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, rules, cache.DefaultExpiration)
+	return rules, nil
+}
+
+func CachedRoute53ResolverListFirewallDomainLists(client Route53ResolverClientInterface, accountID string, region string) ([]route53resolverTypes.FirewallDomainListMetadata, error) {
+	var PaginationControl *string
+	var domainLists []route53resolverTypes.FirewallDomainListMetadata
+	cacheKey := fmt.Sprintf("%s-route53resolver-ListFirewallDomainLists-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]route53resolverTypes.FirewallDomainListMetadata), nil
+	}
+
+	for {
+		ListFirewallDomainLists, err := client.ListFirewallDomainLists(
+			context.TODO(),
+			&route53resolver.ListFirewallDomainListsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *route53resolver.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return domainLists, err
+		}
+
+		domainLists = append(domainLists, ListFirewallDomainLists.FirewallDomainLists...)
+
+		//pagination
+		if ListFirewallDomainLists.NextToken == nil {
+			break
+		}
+		PaginationControl = ListFirewallDomainLists.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, domainLists, cache.DefaultExpiration)
+	return domainLists, nil
+}
+
+// CachedRoute53ResolverListFirewallDomains returns the domains of a customer managed domain list. The contents of
+// AWS managed domain lists can't be listed.
+func CachedRoute53ResolverListFirewallDomains(client Route53ResolverClientInterface, accountID string, region string, domainListID string) ([]string, error) {
+	var PaginationControl *string
+	var domains []string
+	cacheKey := fmt.Sprintf("%s-route53resolver-ListFirewallDomains-%s-%s", accountID, region, domainListID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]string), nil
+	}
+
+	for {
+		ListFirewallDomains, err := client.ListFirewallDomains(
+			context.TODO(),
+			&route53resolver.ListFirewallDomainsInput{
+				FirewallDomainListId: &domainListID,
+				NextToken:            PaginationControl,
+			},
+			func(o *route53resolver.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return domains, err
+		}
+
+		domains = append(domains, ListFirewallDomains.Domains...)
+
+		//pagination
+		if ListFirewallDomains.NextToken == nil {
+			break
+		}
+		PaginationControl = ListFirewallDomains.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, domains, cache.DefaultExpiration)
+	return domains, nil
+}
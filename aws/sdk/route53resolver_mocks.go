@@ -0,0 +1,158 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53resolver"
+	route53resolverTypes "github.com/aws/aws-sdk-go-v2/service/route53resolver/types"
+)
+
+// MockedRoute53ResolverClient protects vpc-11111111 with a rule group that blocks the AWS managed malware and botnet
+// lists, and vpc-22222222 with a rule group that allows every domain. vpc-33333333 has no DNS firewall.
+type MockedRoute53ResolverClient struct {
+}
+
+var mockedFirewallDomains = map[string][]string{
+	"rslvr-fdl-allowall": {"*"},
+	"rslvr-fdl-corp":     {"*.corp.example.com", "corp.example.com"},
+}
+
+var mockedFirewallRules = map[string][]route53resolverTypes.FirewallRule{
+	"rslvr-frg-baseline": {
+		{
+			Name:                 aws.String("allow-corp"),
+			Action:               route53resolverTypes.ActionAllow,
+			FirewallDomainListId: aws.String("rslvr-fdl-corp"),
+			FirewallRuleGroupId:  aws.String("rslvr-frg-baseline"),
+			Priority:             aws.Int32(50),
+		},
+		{
+			Name:                 aws.String("block-malware"),
+			Action:               route53resolverTypes.ActionBlock,
+			BlockResponse:        route53resolverTypes.BlockResponseNxdomain,
+			FirewallDomainListId: aws.String("rslvr-fdl-malware"),
+			FirewallRuleGroupId:  aws.String("rslvr-frg-baseline"),
+			Priority:             aws.Int32(100),
+		},
+		{
+			Name:                 aws.String("block-botnet"),
+			Action:               route53resolverTypes.ActionBlock,
+			BlockResponse:        route53resolverTypes.BlockResponseNxdomain,
+			FirewallDomainListId: aws.String("rslvr-fdl-botnet"),
+			FirewallRuleGroupId:  aws.String("rslvr-frg-baseline"),
+			Priority:             aws.Int32(200),
+		},
+	},
+	"rslvr-frg-allowall": {
+		{
+			Name:                 aws.String("allow-everything"),
+			Action:               route53resolverTypes.ActionAllow,
+			FirewallDomainListId: aws.String("rslvr-fdl-allowall"),
+			FirewallRuleGroupId:  aws.String("rslvr-frg-allowall"),
+			Priority:             aws.Int32(10),
+		},
+		{
+			Name:                 aws.String("alert-corp"),
+			Action:               route53resolverTypes.ActionAlert,
+			FirewallDomainListId: aws.String("rslvr-fdl-corp"),
+			FirewallRuleGroupId:  aws.String("rslvr-frg-allowall"),
+			Priority:             aws.Int32(20),
+		},
+	},
+}
+
+func (m *MockedRoute53ResolverClient) ListFirewallRuleGroups(ctx context.Context, input *route53resolver.ListFirewallRuleGroupsInput, options ...func(*route53resolver.Options)) (*route53resolver.ListFirewallRuleGroupsOutput, error) {
+	return &route53resolver.ListFirewallRuleGroupsOutput{
+		FirewallRuleGroups: []route53resolverTypes.FirewallRuleGroupMetadata{
+			{
+				Id:      aws.String("rslvr-frg-baseline"),
+				Arn:     aws.String("arn:aws:route53resolver:us-east-1:123456789012:firewall-rule-group/rslvr-frg-baseline"),
+				Name:    aws.String("baseline-protection"),
+				OwnerId: aws.String("123456789012"),
+			},
+			{
+				Id:      aws.String("rslvr-frg-allowall"),
+				Arn:     aws.String("arn:aws:route53resolver:us-east-1:123456789012:firewall-rule-group/rslvr-frg-allowall"),
+				Name:    aws.String("dev-allow-all"),
+				OwnerId: aws.String("123456789012"),
+			},
+		},
+	}, nil
+}
+
+func (m *MockedRoute53ResolverClient) ListFirewallRuleGroupAssociations(ctx context.Context, input *route53resolver.ListFirewallRuleGroupAssociationsInput, options ...func(*route53resolver.Options)) (*route53resolver.ListFirewallRuleGroupAssociationsOutput, error) {
+	return &route53resolver.ListFirewallRuleGroupAssociationsOutput{
+		FirewallRuleGroupAssociations: []route53resolverTypes.FirewallRuleGroupAssociation{
+			{
+				Id:                  aws.String("rslvr-frgassoc-1"),
+				FirewallRuleGroupId: aws.String("rslvr-frg-baseline"),
+				VpcId:               aws.String("vpc-11111111"),
+				Priority:            aws.Int32(101),
+				Status:              route53resolverTypes.FirewallRuleGroupAssociationStatusComplete,
+			},
+			{
+				Id:                  aws.String("rslvr-frgassoc-2"),
+				FirewallRuleGroupId: aws.String("rslvr-frg-allowall"),
+				VpcId:               aws.String("vpc-22222222"),
+				Priority:            aws.Int32(101),
+				Status:              route53resolverTypes.FirewallRuleGroupAssociationStatusComplete,
+			},
+			{
+				Id:                  aws.String("rslvr-frgassoc-3"),
+				FirewallRuleGroupId: aws.String("rslvr-frg-baseline"),
+				VpcId:               aws.String("vpc-33333333"),
+				Priority:            aws.Int32(101),
+				Status:              route53resolverTypes.FirewallRuleGroupAssociationStatusDeleting,
+			},
+		},
+	}, nil
+}
+
+func (m *MockedRoute53ResolverClient) ListFirewallRules(ctx context.Context, input *route53resolver.ListFirewallRulesInput, options ...func(*route53resolver.Options)) (*route53resolver.ListFirewallRulesOutput, error) {
+	rules, ok := mockedFirewallRules[aws.ToString(input.FirewallRuleGroupId)]
+	if !ok {
+		return nil, fmt.Errorf("rule group %s not found", aws.ToString(input.FirewallRuleGroupId))
+	}
+	return &route53resolver.ListFirewallRulesOutput{
+		FirewallRuleGroupId: input.FirewallRuleGroupId,
+		FirewallRules:       rules,
+	}, nil
+}
+
+func (m *MockedRoute53ResolverClient) ListFirewallDomainLists(ctx context.Context, input *route53resolver.ListFirewallDomainListsInput, options ...func(*route53resolver.Options)) (*route53resolver.ListFirewallDomainListsOutput, error) {
+	return &route53resolver.ListFirewallDomainListsOutput{
+		FirewallDomainLists: []route53resolverTypes.FirewallDomainListMetadata{
+			{
+				Id:               aws.String("rslvr-fdl-malware"),
+				Name:             aws.String("AWSManagedDomainsMalwareDomainList"),
+				ManagedOwnerName: aws.String("Route 53 Resolver DNS Firewall"),
+			},
+			{
+				Id:               aws.String("rslvr-fdl-botnet"),
+				Name:             aws.String("AWSManagedDomainsBotnetCommandandControl"),
+				ManagedOwnerName: aws.String("Route 53 Resolver DNS Firewall"),
+			},
+			{
+				Id:   aws.String("rslvr-fdl-allowall"),
+				Name: aws.String("allow-everything"),
+			},
+			{
+				Id:   aws.String("rslvr-fdl-corp"),
+				Name: aws.String("corp-domains"),
+			},
+		},
+	}, nil
+}
+
+func (m *MockedRoute53ResolverClient) ListFirewallDomains(ctx context.Context, input *route53resolver.ListFirewallDomainsInput, options ...func(*route53resolver.Options)) (*route53resolver.ListFirewallDomainsOutput, error) {
+	domains, ok := mockedFirewallDomains[aws.ToString(input.FirewallDomainListId)]
+	if !ok {
+		return nil, fmt.Errorf("the domains of %s can't be listed", aws.ToString(input.FirewallDomainListId))
+	}
+	return &route53resolver.ListFirewallDomainsOutput{
+		FirewallDomainListId: input.FirewallDomainListId,
+		Domains:              domains,
+	}, nil
+}
@@ -3,12 +3,14 @@ package sdk
 import (
 	"context"
 	"encoding/gob"
+	"errors"
 	"fmt"
 
 	"github.com/BishopFox/cloudfox/internal"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 	"github.com/patrickmn/go-cache"
 )
 
@@ -17,12 +19,22 @@ type AWSS3ClientInterface interface {
 	GetBucketPolicy(ctx context.Context, params *s3.GetBucketPolicyInput, optFns ...func(*s3.Options)) (*s3.GetBucketPolicyOutput, error)
 	GetBucketLocation(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error)
 	GetPublicAccessBlock(ctx context.Context, params *s3.GetPublicAccessBlockInput, optFns ...func(*s3.Options)) (*s3.GetPublicAccessBlockOutput, error)
+	GetBucketWebsite(ctx context.Context, params *s3.GetBucketWebsiteInput, optFns ...func(*s3.Options)) (*s3.GetBucketWebsiteOutput, error)
+	GetBucketEncryption(ctx context.Context, params *s3.GetBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error)
+}
+
+// AWSS3ObjectsClientInterface reads objects, for modules that analyze the logs services deliver to S3. Log objects change
+// all the time, so these calls are not cached.
+type AWSS3ObjectsClientInterface interface {
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
 }
 
 func init() {
 	gob.Register([]s3Types.Bucket{})
 	gob.Register(s3Types.Bucket{})
 	gob.Register(&s3Types.PublicAccessBlockConfiguration{})
+	gob.Register(&s3Types.ServerSideEncryptionConfiguration{})
 }
 
 func CachedListBuckets(S3Client AWSS3ClientInterface, accountID string) ([]s3Types.Bucket, error) {
@@ -123,3 +135,67 @@ func CachedGetPublicAccessBlock(S3Client AWSS3ClientInterface, accountID string,
 	internal.Cache.Set(cacheKey, PublicAccessBlock.PublicAccessBlockConfiguration, cache.DefaultExpiration)
 	return PublicAccessBlock.PublicAccessBlockConfiguration, err
 }
+
+// CachedGetBucketWebsite returns true if static website hosting is enabled on the bucket. Buckets without a website
+// configuration return a NoSuchWebsiteConfiguration error, which is treated as hosting being disabled.
+func CachedGetBucketWebsite(S3Client AWSS3ClientInterface, accountID string, r string, bucketName string) (bool, error) {
+	cacheKey := fmt.Sprintf("%s-s3-GetBucketWebsite-%s-%s", accountID, r, bucketName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		sharedLogger.Debug("Using cached data for GetBucketWebsite data")
+		return cached.(bool), nil
+	}
+
+	_, err := S3Client.GetBucketWebsite(
+		context.TODO(),
+		&s3.GetBucketWebsiteInput{
+			Bucket: &bucketName,
+		},
+		func(o *s3.Options) {
+			o.Region = r
+		},
+	)
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchWebsiteConfiguration" {
+			internal.Cache.Set(cacheKey, false, cache.DefaultExpiration)
+			return false, nil
+		}
+		return false, err
+	}
+
+	internal.Cache.Set(cacheKey, true, cache.DefaultExpiration)
+	return true, nil
+}
+
+// CachedGetBucketEncryption returns the default encryption of a bucket. Buckets without one return a
+// ServerSideEncryptionConfigurationNotFoundError, which is returned as a nil configuration.
+func CachedGetBucketEncryption(S3Client AWSS3ClientInterface, accountID string, r string, bucketName string) (*s3Types.ServerSideEncryptionConfiguration, error) {
+	cacheKey := fmt.Sprintf("%s-s3-GetBucketEncryption-%s-%s", accountID, r, bucketName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		sharedLogger.Debug("Using cached data for GetBucketEncryption data")
+		return cached.(*s3Types.ServerSideEncryptionConfiguration), nil
+	}
+
+	BucketEncryption, err := S3Client.GetBucketEncryption(
+		context.TODO(),
+		&s3.GetBucketEncryptionInput{
+			Bucket: &bucketName,
+		},
+		func(o *s3.Options) {
+			o.Region = r
+		},
+	)
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "ServerSideEncryptionConfigurationNotFoundError" {
+			internal.Cache.Set(cacheKey, (*s3Types.ServerSideEncryptionConfiguration)(nil), cache.DefaultExpiration)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	internal.Cache.Set(cacheKey, BucketEncryption.ServerSideEncryptionConfiguration, cache.DefaultExpiration)
+	return BucketEncryption.ServerSideEncryptionConfiguration, nil
+}
@@ -1,11 +1,18 @@
 package sdk
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 )
 
 type MockedS3Client struct {
@@ -20,6 +27,9 @@ func (m *MockedS3Client) ListBuckets(ctx context.Context, input *s3.ListBucketsI
 			{
 				Name: aws.String("bucket2"),
 			},
+			{
+				Name: aws.String("scenes-sumerian-123456789012"),
+			},
 		},
 	}, nil
 }
@@ -31,6 +41,23 @@ func (m *MockedS3Client) GetBucketLocation(ctx context.Context, input *s3.GetBuc
 }
 
 func (m *MockedS3Client) GetBucketPolicy(ctx context.Context, input *s3.GetBucketPolicyInput, options ...func(*s3.Options)) (*s3.GetBucketPolicyOutput, error) {
+	// The export location of the mocked Glue tables and the output bucket of the mocked face search stream processors
+	// let anyone read them
+	if aws.ToString(input.Bucket) == "datalake-public-exports" || aws.ToString(input.Bucket) == "camera-analytics" {
+		return &s3.GetBucketPolicyOutput{
+			Policy: aws.String(fmt.Sprintf(`{
+				"Version": "2012-10-17",
+				"Statement": [
+					{
+						"Effect": "Allow",
+						"Principal": "*",
+						"Action": "s3:GetObject",
+						"Resource": "arn:aws:s3:::%s/*"
+					}
+				]
+			}`, aws.ToString(input.Bucket))),
+		}, nil
+	}
 	return &s3.GetBucketPolicyOutput{
 		Policy: aws.String(`{
 			"Version": "2012-10-17",
@@ -64,6 +91,13 @@ func (m *MockedS3Client) GetBucketPolicy(ctx context.Context, input *s3.GetBucke
 }
 
 func (m *MockedS3Client) GetPublicAccessBlock(ctx context.Context, input *s3.GetPublicAccessBlockInput, options ...func(*s3.Options)) (*s3.GetPublicAccessBlockOutput, error) {
+	switch aws.ToString(input.Bucket) {
+	case "aws-waf-logs-api", "datalake-public-exports", "camera-analytics":
+		return nil, &smithy.GenericAPIError{
+			Code:    "NoSuchPublicAccessBlockConfiguration",
+			Message: "The public access block configuration was not found",
+		}
+	}
 	return &s3.GetPublicAccessBlockOutput{
 		PublicAccessBlockConfiguration: &s3Types.PublicAccessBlockConfiguration{
 			BlockPublicAcls:       aws.Bool(true),
@@ -73,3 +107,64 @@ func (m *MockedS3Client) GetPublicAccessBlock(ctx context.Context, input *s3.Get
 		},
 	}, nil
 }
+
+func (m *MockedS3Client) GetBucketWebsite(ctx context.Context, input *s3.GetBucketWebsiteInput, options ...func(*s3.Options)) (*s3.GetBucketWebsiteOutput, error) {
+	if aws.ToString(input.Bucket) != "bucket1" {
+		return nil, &smithy.GenericAPIError{
+			Code:    "NoSuchWebsiteConfiguration",
+			Message: "The specified bucket does not have a website configuration",
+		}
+	}
+	return &s3.GetBucketWebsiteOutput{
+		IndexDocument: &s3Types.IndexDocument{
+			Suffix: aws.String("index.html"),
+		},
+	}, nil
+}
+
+func (m *MockedS3Client) GetBucketEncryption(ctx context.Context, input *s3.GetBucketEncryptionInput, options ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error) {
+	return &s3.GetBucketEncryptionOutput{
+		ServerSideEncryptionConfiguration: &s3Types.ServerSideEncryptionConfiguration{
+			Rules: []s3Types.ServerSideEncryptionRule{
+				{
+					ApplyServerSideEncryptionByDefault: &s3Types.ServerSideEncryptionByDefault{
+						SSEAlgorithm: s3Types.ServerSideEncryptionAes256,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// MockedS3ObjectsClient has one gzipped Verified Access log object of the corp instance, with carol reading the wiki
+// three days ago
+type MockedS3ObjectsClient struct {
+}
+
+const mockedVerifiedAccessLogKey = "corp/AWSLogs/123456789012/verified_access/us-east-1/2024/05/01/123456789012_verified_access_us-east-1_vai-0corp.log.gz"
+
+func (m *MockedS3ObjectsClient) ListObjectsV2(ctx context.Context, input *s3.ListObjectsV2Input, options ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	if aws.ToString(input.Bucket) != "va-logs-123456789012" || !strings.HasPrefix(mockedVerifiedAccessLogKey, aws.ToString(input.Prefix)) {
+		return &s3.ListObjectsV2Output{}, nil
+	}
+	return &s3.ListObjectsV2Output{
+		Contents: []s3Types.Object{
+			{
+				Key:          aws.String(mockedVerifiedAccessLogKey),
+				LastModified: aws.Time(time.Now().Add(-72 * time.Hour)),
+			},
+		},
+	}, nil
+}
+
+func (m *MockedS3ObjectsClient) GetObject(ctx context.Context, input *s3.GetObjectInput, options ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	if aws.ToString(input.Key) != mockedVerifiedAccessLogKey {
+		return nil, &s3Types.NoSuchKey{Message: aws.String("The specified key does not exist.")}
+	}
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	event := mockedVerifiedAccessLogEvent{user: "carol@example.com", hostname: "wiki.example.com", ip: "198.51.100.30", country: "US", hoursAgo: 72}
+	gz.Write([]byte(event.message() + "\n"))
+	gz.Close()
+	return &s3.GetObjectOutput{Body: io.NopCloser(&body)}, nil
+}
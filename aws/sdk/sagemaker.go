@@ -0,0 +1,279 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
+	sagemakerTypes "github.com/aws/aws-sdk-go-v2/service/sagemaker/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type SageMakerClientInterface interface {
+	ListNotebookInstances(context.Context, *sagemaker.ListNotebookInstancesInput, ...func(*sagemaker.Options)) (*sagemaker.ListNotebookInstancesOutput, error)
+	DescribeNotebookInstance(context.Context, *sagemaker.DescribeNotebookInstanceInput, ...func(*sagemaker.Options)) (*sagemaker.DescribeNotebookInstanceOutput, error)
+	ListDomains(context.Context, *sagemaker.ListDomainsInput, ...func(*sagemaker.Options)) (*sagemaker.ListDomainsOutput, error)
+	DescribeDomain(context.Context, *sagemaker.DescribeDomainInput, ...func(*sagemaker.Options)) (*sagemaker.DescribeDomainOutput, error)
+	ListPipelines(context.Context, *sagemaker.ListPipelinesInput, ...func(*sagemaker.Options)) (*sagemaker.ListPipelinesOutput, error)
+	DescribePipeline(context.Context, *sagemaker.DescribePipelineInput, ...func(*sagemaker.Options)) (*sagemaker.DescribePipelineOutput, error)
+}
+
+func init() {
+	gob.Register([]sagemakerTypes.NotebookInstanceSummary{})
+	gob.Register([]sagemakerTypes.DomainDetails{})
+	gob.Register(customDescribeNotebookInstanceOutput{})
+	gob.Register(customDescribeDomainOutput{})
+	gob.Register([]sagemakerTypes.PipelineSummary{})
+	gob.Register(customDescribePipelineOutput{})
+}
+
+func CachedSageMakerListNotebookInstances(client SageMakerClientInterface, accountID string, region string) ([]sagemakerTypes.NotebookInstanceSummary, error) {
+	var PaginationControl *string
+	var notebookInstances []sagemakerTypes.NotebookInstanceSummary
+	cacheKey := fmt.Sprintf("%s-sagemaker-ListNotebookInstances-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]sagemakerTypes.NotebookInstanceSummary), nil
+	}
+
+	for {
+		ListNotebookInstances, err := client.ListNotebookInstances(
+			context.TODO(),
+			&sagemaker.ListNotebookInstancesInput{
+				NextToken: PaginationControl,
+			},
+			func(o *sagemaker.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return notebookInstances, err
+		}
+
+		notebookInstances = append(notebookInstances, ListNotebookInstances.NotebookInstances...)
+
+		//pagination
+		if ListNotebookInstances.NextToken == nil {
+			break
+		}
+		PaginationControl = ListNotebookInstances.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, notebookInstances, cache.DefaultExpiration)
+	return notebookInstances, nil
+}
+
+// The parts of DescribeNotebookInstanceOutput that we care about. The full output can't be gob encoded for the cache.
+type customDescribeNotebookInstanceOutput struct {
+	NotebookInstanceArn    *string
+	NotebookInstanceName   *string
+	NotebookInstanceStatus sagemakerTypes.NotebookInstanceStatus
+	RoleArn                *string
+	DirectInternetAccess   sagemakerTypes.DirectInternetAccess
+	RootAccess             sagemakerTypes.RootAccess
+	SubnetId               *string
+	Url                    *string
+}
+
+func CachedSageMakerDescribeNotebookInstance(client SageMakerClientInterface, accountID string, region string, notebookInstanceName string) (customDescribeNotebookInstanceOutput, error) {
+	var notebookInstance customDescribeNotebookInstanceOutput
+	cacheKey := fmt.Sprintf("%s-sagemaker-DescribeNotebookInstance-%s-%s", accountID, region, notebookInstanceName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(customDescribeNotebookInstanceOutput), nil
+	}
+
+	DescribeNotebookInstance, err := client.DescribeNotebookInstance(
+		context.TODO(),
+		&sagemaker.DescribeNotebookInstanceInput{
+			NotebookInstanceName: &notebookInstanceName,
+		},
+		func(o *sagemaker.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return notebookInstance, err
+	}
+
+	notebookInstance = customDescribeNotebookInstanceOutput{
+		NotebookInstanceArn:    DescribeNotebookInstance.NotebookInstanceArn,
+		NotebookInstanceName:   DescribeNotebookInstance.NotebookInstanceName,
+		NotebookInstanceStatus: DescribeNotebookInstance.NotebookInstanceStatus,
+		RoleArn:                DescribeNotebookInstance.RoleArn,
+		DirectInternetAccess:   DescribeNotebookInstance.DirectInternetAccess,
+		RootAccess:             DescribeNotebookInstance.RootAccess,
+		SubnetId:               DescribeNotebookInstance.SubnetId,
+		Url:                    DescribeNotebookInstance.Url,
+	}
+
+	internal.Cache.Set(cacheKey, notebookInstance, cache.DefaultExpiration)
+	return notebookInstance, nil
+}
+
+func CachedSageMakerListDomains(client SageMakerClientInterface, accountID string, region string) ([]sagemakerTypes.DomainDetails, error) {
+	var PaginationControl *string
+	var domains []sagemakerTypes.DomainDetails
+	cacheKey := fmt.Sprintf("%s-sagemaker-ListDomains-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]sagemakerTypes.DomainDetails), nil
+	}
+
+	for {
+		ListDomains, err := client.ListDomains(
+			context.TODO(),
+			&sagemaker.ListDomainsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *sagemaker.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return domains, err
+		}
+
+		domains = append(domains, ListDomains.Domains...)
+
+		//pagination
+		if ListDomains.NextToken == nil {
+			break
+		}
+		PaginationControl = ListDomains.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, domains, cache.DefaultExpiration)
+	return domains, nil
+}
+
+// The parts of DescribeDomainOutput that we care about. The full output can't be gob encoded for the cache.
+type customDescribeDomainOutput struct {
+	DomainArn            *string
+	DomainId             *string
+	DomainName           *string
+	Status               sagemakerTypes.DomainStatus
+	AuthMode             sagemakerTypes.AuthMode
+	AppNetworkAccessType sagemakerTypes.AppNetworkAccessType
+	// ExecutionRole is the default execution role of the domain's user profiles
+	ExecutionRole *string
+	Url           *string
+}
+
+func CachedSageMakerDescribeDomain(client SageMakerClientInterface, accountID string, region string, domainID string) (customDescribeDomainOutput, error) {
+	var domain customDescribeDomainOutput
+	cacheKey := fmt.Sprintf("%s-sagemaker-DescribeDomain-%s-%s", accountID, region, domainID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(customDescribeDomainOutput), nil
+	}
+
+	DescribeDomain, err := client.DescribeDomain(
+		context.TODO(),
+		&sagemaker.DescribeDomainInput{
+			DomainId: &domainID,
+		},
+		func(o *sagemaker.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return domain, err
+	}
+
+	domain = customDescribeDomainOutput{
+		DomainArn:            DescribeDomain.DomainArn,
+		DomainId:             DescribeDomain.DomainId,
+		DomainName:           DescribeDomain.DomainName,
+		Status:               DescribeDomain.Status,
+		AuthMode:             DescribeDomain.AuthMode,
+		AppNetworkAccessType: DescribeDomain.AppNetworkAccessType,
+		Url:                  DescribeDomain.Url,
+	}
+	if DescribeDomain.DefaultUserSettings != nil {
+		domain.ExecutionRole = DescribeDomain.DefaultUserSettings.ExecutionRole
+	}
+
+	internal.Cache.Set(cacheKey, domain, cache.DefaultExpiration)
+	return domain, nil
+}
+
+func CachedSageMakerListPipelines(client SageMakerClientInterface, accountID string, region string) ([]sagemakerTypes.PipelineSummary, error) {
+	var PaginationControl *string
+	var pipelines []sagemakerTypes.PipelineSummary
+	cacheKey := fmt.Sprintf("%s-sagemaker-ListPipelines-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]sagemakerTypes.PipelineSummary), nil
+	}
+
+	for {
+		ListPipelines, err := client.ListPipelines(
+			context.TODO(),
+			&sagemaker.ListPipelinesInput{
+				NextToken: PaginationControl,
+			},
+			func(o *sagemaker.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return pipelines, err
+		}
+
+		pipelines = append(pipelines, ListPipelines.PipelineSummaries...)
+
+		//pagination
+		if ListPipelines.NextToken == nil {
+			break
+		}
+		PaginationControl = ListPipelines.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, pipelines, cache.DefaultExpiration)
+	return pipelines, nil
+}
+
+// The parts of DescribePipelineOutput that we care about. The full output can't be gob encoded for the cache.
+type customDescribePipelineOutput struct {
+	PipelineArn        *string
+	PipelineName       *string
+	PipelineStatus     sagemakerTypes.PipelineStatus
+	PipelineDefinition *string
+	RoleArn            *string
+}
+
+func CachedSageMakerDescribePipeline(client SageMakerClientInterface, accountID string, region string, pipelineName string) (customDescribePipelineOutput, error) {
+	var pipeline customDescribePipelineOutput
+	cacheKey := fmt.Sprintf("%s-sagemaker-DescribePipeline-%s-%s", accountID, region, pipelineName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(customDescribePipelineOutput), nil
+	}
+
+	DescribePipeline, err := client.DescribePipeline(
+		context.TODO(),
+		&sagemaker.DescribePipelineInput{
+			PipelineName: &pipelineName,
+		},
+		func(o *sagemaker.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return pipeline, err
+	}
+
+	pipeline = customDescribePipelineOutput{
+		PipelineArn:        DescribePipeline.PipelineArn,
+		PipelineName:       DescribePipeline.PipelineName,
+		PipelineStatus:     DescribePipeline.PipelineStatus,
+		PipelineDefinition: DescribePipeline.PipelineDefinition,
+		RoleArn:            DescribePipeline.RoleArn,
+	}
+
+	internal.Cache.Set(cacheKey, pipeline, cache.DefaultExpiration)
+	return pipeline, nil
+}
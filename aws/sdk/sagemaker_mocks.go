@@ -0,0 +1,146 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
+	sagemakerTypes "github.com/aws/aws-sdk-go-v2/service/sagemaker/types"
+)
+
+type MockedSageMakerClient struct {
+}
+
+var mockedNotebookInstances = map[string]customDescribeNotebookInstanceOutput{
+	"research-notebook": {
+		NotebookInstanceArn:    aws.String("arn:aws:sagemaker:us-east-1:123456789012:notebook-instance/research-notebook"),
+		NotebookInstanceName:   aws.String("research-notebook"),
+		NotebookInstanceStatus: sagemakerTypes.NotebookInstanceStatusInService,
+		RoleArn:                aws.String("arn:aws:iam::123456789012:role/role1"),
+		DirectInternetAccess:   sagemakerTypes.DirectInternetAccessEnabled,
+		RootAccess:             sagemakerTypes.RootAccessEnabled,
+		Url:                    aws.String("research-notebook.notebook.us-east-1.sagemaker.aws"),
+	},
+	"stopped-notebook": {
+		NotebookInstanceArn:    aws.String("arn:aws:sagemaker:us-east-1:123456789012:notebook-instance/stopped-notebook"),
+		NotebookInstanceName:   aws.String("stopped-notebook"),
+		NotebookInstanceStatus: sagemakerTypes.NotebookInstanceStatusStopped,
+		RoleArn:                aws.String("arn:aws:iam::123456789012:role/role2"),
+		DirectInternetAccess:   sagemakerTypes.DirectInternetAccessDisabled,
+		RootAccess:             sagemakerTypes.RootAccessDisabled,
+		SubnetId:               aws.String("subnet-11111111"),
+	},
+}
+
+func (m *MockedSageMakerClient) ListNotebookInstances(ctx context.Context, input *sagemaker.ListNotebookInstancesInput, options ...func(*sagemaker.Options)) (*sagemaker.ListNotebookInstancesOutput, error) {
+	var notebookInstances []sagemakerTypes.NotebookInstanceSummary
+	for _, name := range []string{"research-notebook", "stopped-notebook"} {
+		notebookInstance := mockedNotebookInstances[name]
+		notebookInstances = append(notebookInstances, sagemakerTypes.NotebookInstanceSummary{
+			NotebookInstanceArn:    notebookInstance.NotebookInstanceArn,
+			NotebookInstanceName:   notebookInstance.NotebookInstanceName,
+			NotebookInstanceStatus: notebookInstance.NotebookInstanceStatus,
+			Url:                    notebookInstance.Url,
+		})
+	}
+	return &sagemaker.ListNotebookInstancesOutput{NotebookInstances: notebookInstances}, nil
+}
+
+func (m *MockedSageMakerClient) DescribeNotebookInstance(ctx context.Context, input *sagemaker.DescribeNotebookInstanceInput, options ...func(*sagemaker.Options)) (*sagemaker.DescribeNotebookInstanceOutput, error) {
+	notebookInstance := mockedNotebookInstances[aws.ToString(input.NotebookInstanceName)]
+	return &sagemaker.DescribeNotebookInstanceOutput{
+		NotebookInstanceArn:    notebookInstance.NotebookInstanceArn,
+		NotebookInstanceName:   notebookInstance.NotebookInstanceName,
+		NotebookInstanceStatus: notebookInstance.NotebookInstanceStatus,
+		RoleArn:                notebookInstance.RoleArn,
+		DirectInternetAccess:   notebookInstance.DirectInternetAccess,
+		RootAccess:             notebookInstance.RootAccess,
+		SubnetId:               notebookInstance.SubnetId,
+		Url:                    notebookInstance.Url,
+	}, nil
+}
+
+func (m *MockedSageMakerClient) ListDomains(ctx context.Context, input *sagemaker.ListDomainsInput, options ...func(*sagemaker.Options)) (*sagemaker.ListDomainsOutput, error) {
+	return &sagemaker.ListDomainsOutput{
+		Domains: []sagemakerTypes.DomainDetails{
+			{
+				DomainArn:  aws.String("arn:aws:sagemaker:us-east-1:123456789012:domain/d-abcdefghijkl"),
+				DomainId:   aws.String("d-abcdefghijkl"),
+				DomainName: aws.String("ml-domain"),
+				Status:     sagemakerTypes.DomainStatusInService,
+			},
+		},
+	}, nil
+}
+
+func (m *MockedSageMakerClient) DescribeDomain(ctx context.Context, input *sagemaker.DescribeDomainInput, options ...func(*sagemaker.Options)) (*sagemaker.DescribeDomainOutput, error) {
+	return &sagemaker.DescribeDomainOutput{
+		DomainArn:            aws.String("arn:aws:sagemaker:us-east-1:123456789012:domain/d-abcdefghijkl"),
+		DomainId:             input.DomainId,
+		DomainName:           aws.String("ml-domain"),
+		Status:               sagemakerTypes.DomainStatusInService,
+		AuthMode:             sagemakerTypes.AuthModeIam,
+		AppNetworkAccessType: sagemakerTypes.AppNetworkAccessTypePublicInternetOnly,
+		Url:                  aws.String("https://d-abcdefghijkl.studio.us-east-1.sagemaker.aws"),
+		DefaultUserSettings: &sagemakerTypes.UserSettings{
+			ExecutionRole: aws.String("arn:aws:iam::123456789012:role/role3"),
+		},
+	}, nil
+}
+
+// churn-training trains with a role that can read and write every bucket and has an API token as a parameter default.
+// Its evaluation step uses a role scoped to the pipeline's bucket, and the condition step has no role at all.
+var mockedSageMakerPipelineDefinition = `{
+	"Version": "2020-12-01",
+	"Parameters": [
+		{"Name": "InputData", "Type": "String", "DefaultValue": "s3://ml-churn-data/input/"},
+		{"Name": "ExecutionRole", "Type": "String", "DefaultValue": "arn:aws:iam::123456789012:role/sagemaker-training"},
+		{"Name": "WandbApiKey", "Type": "String", "DefaultValue": "f3b9c2d1e4a5"}
+	],
+	"Steps": [
+		{
+			"Name": "Preprocess",
+			"Type": "Processing",
+			"Arguments": {
+				"RoleArn": "arn:aws:iam::123456789012:role/sagemaker-evaluation",
+				"ProcessingInputs": [{"S3Input": {"S3Uri": {"Get": "Parameters.InputData"}}}],
+				"ProcessingOutputConfig": {"Outputs": [{"S3Output": {"S3Uri": "s3://ml-churn-data/processed/"}}]}
+			}
+		},
+		{
+			"Name": "Train",
+			"Type": "Training",
+			"Arguments": {
+				"RoleArn": {"Get": "Parameters.ExecutionRole"},
+				"OutputDataConfig": {"S3OutputPath": "s3://ml-churn-models/"}
+			}
+		},
+		{
+			"Name": "CheckAccuracy",
+			"Type": "Condition",
+			"Arguments": {"Conditions": []}
+		}
+	]
+}`
+
+func (m *MockedSageMakerClient) ListPipelines(ctx context.Context, input *sagemaker.ListPipelinesInput, options ...func(*sagemaker.Options)) (*sagemaker.ListPipelinesOutput, error) {
+	return &sagemaker.ListPipelinesOutput{
+		PipelineSummaries: []sagemakerTypes.PipelineSummary{
+			{
+				PipelineArn:  aws.String("arn:aws:sagemaker:us-east-1:123456789012:pipeline/churn-training"),
+				PipelineName: aws.String("churn-training"),
+				RoleArn:      aws.String("arn:aws:iam::123456789012:role/sagemaker-pipeline"),
+			},
+		},
+	}, nil
+}
+
+func (m *MockedSageMakerClient) DescribePipeline(ctx context.Context, input *sagemaker.DescribePipelineInput, options ...func(*sagemaker.Options)) (*sagemaker.DescribePipelineOutput, error) {
+	return &sagemaker.DescribePipelineOutput{
+		PipelineArn:        aws.String("arn:aws:sagemaker:us-east-1:123456789012:pipeline/churn-training"),
+		PipelineName:       input.PipelineName,
+		PipelineStatus:     sagemakerTypes.PipelineStatusActive,
+		PipelineDefinition: aws.String(mockedSageMakerPipelineDefinition),
+		RoleArn:            aws.String("arn:aws:iam::123456789012:role/sagemaker-pipeline"),
+	}, nil
+}
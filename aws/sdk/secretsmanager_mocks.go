@@ -2,6 +2,7 @@ package sdk
 
 import (
 	"context"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
@@ -11,19 +12,58 @@ import (
 type MockedSecretsManagerClient struct {
 }
 
+var mockedSecrets = []secretsmanagerTypes.SecretListEntry{
+	{
+		Name:              aws.String("secret1"),
+		ARN:               aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:secret1-AbCdEf"),
+		CreatedDate:       aws.Time(time.Date(2023, 1, 10, 9, 0, 0, 0, time.UTC)),
+		LastChangedDate:   aws.Time(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)),
+		RotationEnabled:   aws.Bool(true),
+		RotationLambdaARN: aws.String("arn:aws:lambda:us-east-1:123456789012:function:rotate-secret1"),
+		RotationRules: &secretsmanagerTypes.RotationRulesType{
+			AutomaticallyAfterDays: aws.Int64(30),
+		},
+		SecretVersionsToStages: map[string][]string{
+			"11111111-1111-1111-1111-111111111111": {"AWSCURRENT"},
+			"00000000-0000-0000-0000-000000000000": {"AWSPREVIOUS"},
+		},
+		Tags: []secretsmanagerTypes.Tag{
+			{Key: aws.String("env"), Value: aws.String("prod")},
+		},
+	},
+	{
+		Name:        aws.String("secret2"),
+		ARN:         aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:secret2-MnOpQr"),
+		CreatedDate: aws.Time(time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC)),
+	},
+}
+
 func (m *MockedSecretsManagerClient) ListSecrets(ctx context.Context, input *secretsmanager.ListSecretsInput, options ...func(*secretsmanager.Options)) (*secretsmanager.ListSecretsOutput, error) {
 	return &secretsmanager.ListSecretsOutput{
-		SecretList: []secretsmanagerTypes.SecretListEntry{
-			{
-				Name: aws.String("secret1"),
-			},
-			{
-				Name: aws.String("secret2"),
-			},
-		},
+		SecretList: mockedSecrets,
 	}, nil
 }
 
+func (m *MockedSecretsManagerClient) DescribeSecret(ctx context.Context, input *secretsmanager.DescribeSecretInput, options ...func(*secretsmanager.Options)) (*secretsmanager.DescribeSecretOutput, error) {
+	for _, secret := range mockedSecrets {
+		if aws.ToString(input.SecretId) == aws.ToString(secret.Name) || aws.ToString(input.SecretId) == aws.ToString(secret.ARN) {
+			return &secretsmanager.DescribeSecretOutput{
+				Name:               secret.Name,
+				ARN:                secret.ARN,
+				Description:        secret.Description,
+				CreatedDate:        secret.CreatedDate,
+				LastChangedDate:    secret.LastChangedDate,
+				RotationEnabled:    secret.RotationEnabled,
+				RotationLambdaARN:  secret.RotationLambdaARN,
+				RotationRules:      secret.RotationRules,
+				VersionIdsToStages: secret.SecretVersionsToStages,
+				Tags:               secret.Tags,
+			}, nil
+		}
+	}
+	return nil, &secretsmanagerTypes.ResourceNotFoundException{Message: aws.String("Secrets Manager can't find the specified secret.")}
+}
+
 func (m *MockedSecretsManagerClient) GetResourcePolicy(ctx context.Context, input *secretsmanager.GetResourcePolicyInput, options ...func(*secretsmanager.Options)) (*secretsmanager.GetResourcePolicyOutput, error) {
 	return &secretsmanager.GetResourcePolicyOutput{
 		ResourcePolicy: aws.String(`{
@@ -58,3 +98,21 @@ func (m *MockedSecretsManagerClient) GetResourcePolicy(ctx context.Context, inpu
 		}`),
 	}, nil
 }
+
+// secret1 holds a credential the mocked secret validator accepts, secret2 one it rejects
+func (m *MockedSecretsManagerClient) GetSecretValue(ctx context.Context, input *secretsmanager.GetSecretValueInput, options ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	values := map[string]string{
+		"secret1": `{"token":"active-credential"}`,
+		"secret2": "revoked-credential",
+	}
+	for _, secret := range mockedSecrets {
+		if aws.ToString(input.SecretId) == aws.ToString(secret.Name) || aws.ToString(input.SecretId) == aws.ToString(secret.ARN) {
+			return &secretsmanager.GetSecretValueOutput{
+				Name:         secret.Name,
+				ARN:          secret.ARN,
+				SecretString: aws.String(values[aws.ToString(secret.Name)]),
+			}, nil
+		}
+	}
+	return nil, &secretsmanagerTypes.ResourceNotFoundException{Message: aws.String("Secrets Manager can't find the specified secret.")}
+}
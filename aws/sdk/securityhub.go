@@ -0,0 +1,56 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub"
+	"github.com/patrickmn/go-cache"
+)
+
+type SecurityHubClientInterface interface {
+	DescribeHub(ctx context.Context, params *securityhub.DescribeHubInput, optFns ...func(*securityhub.Options)) (*securityhub.DescribeHubOutput, error)
+}
+
+// The full DescribeHubOutput can't be gob encoded, so only the parts we need are cached
+type customDescribeHubOutput struct {
+	HubArn       string
+	SubscribedAt string
+}
+
+func init() {
+	gob.Register(customDescribeHubOutput{})
+}
+
+// CachedSecurityHubDescribeHub returns the hub for the region. AWS returns an InvalidAccessException if the
+// account isn't subscribed to Security Hub in that region.
+func CachedSecurityHubDescribeHub(client SecurityHubClientInterface, accountID string, region string) (customDescribeHubOutput, error) {
+	var hub customDescribeHubOutput
+	cacheKey := fmt.Sprintf("%s-securityhub-DescribeHub-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(customDescribeHubOutput), nil
+	}
+
+	DescribeHub, err := client.DescribeHub(
+		context.TODO(),
+		&securityhub.DescribeHubInput{},
+		func(o *securityhub.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return hub, err
+	}
+
+	hub.HubArn = aws.ToString(DescribeHub.HubArn)
+	if subscribedAt, err := time.Parse(time.RFC3339, aws.ToString(DescribeHub.SubscribedAt)); err == nil {
+		hub.SubscribedAt = subscribedAt.Format("2006-01-02")
+	}
+	internal.Cache.Set(cacheKey, hub, cache.DefaultExpiration)
+	return hub, nil
+}
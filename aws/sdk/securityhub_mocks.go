@@ -0,0 +1,30 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub"
+	"github.com/aws/smithy-go"
+)
+
+type MockedSecurityHubClient struct {
+}
+
+// Security Hub is only enabled in us-east-1
+func (m *MockedSecurityHubClient) DescribeHub(ctx context.Context, input *securityhub.DescribeHubInput, options ...func(*securityhub.Options)) (*securityhub.DescribeHubOutput, error) {
+	o := securityhub.Options{}
+	for _, option := range options {
+		option(&o)
+	}
+	if o.Region != "us-east-1" {
+		return nil, &smithy.GenericAPIError{
+			Code:    "InvalidAccessException",
+			Message: "Account 123456789012 is not subscribed to AWS Security Hub",
+		}
+	}
+	return &securityhub.DescribeHubOutput{
+		HubArn:       aws.String("arn:aws:securityhub:us-east-1:123456789012:hub/default"),
+		SubscribedAt: aws.String("2023-05-01T12:00:00.000Z"),
+	}, nil
+}
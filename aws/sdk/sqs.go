@@ -6,15 +6,18 @@ import (
 
 	"github.com/BishopFox/cloudfox/internal"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"github.com/patrickmn/go-cache"
 )
 
 type AWSSQSClientInterface interface {
 	ListQueues(ctx context.Context, params *sqs.ListQueuesInput, optFns ...func(*sqs.Options)) (*sqs.ListQueuesOutput, error)
+	GetQueueAttributes(ctx context.Context, params *sqs.GetQueueAttributesInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error)
 }
 
 func init() {
 	gob.Register([]string{})
+	gob.Register(map[string]string{})
 }
 
 func CachedSQSListQueues(SQSClient AWSSQSClientInterface, accountID string, region string) ([]string, error) {
@@ -56,3 +59,33 @@ func CachedSQSListQueues(SQSClient AWSSQSClientInterface, accountID string, regi
 	internal.Cache.Set(cacheKey, queues, cache.DefaultExpiration)
 	return queues, nil
 }
+
+// CachedSQSGetQueueAttributes returns the queue ARN and the access policy of a queue, keyed by attribute name
+func CachedSQSGetQueueAttributes(SQSClient AWSSQSClientInterface, accountID string, region string, queueURL string) (map[string]string, error) {
+	cacheKey := "sqs-GetQueueAttributes-" + accountID + "-" + region + "-" + queueURL
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		sharedLogger.Debug("Using cached SQS queue attributes data")
+		return cached.(map[string]string), nil
+	}
+
+	GetQueueAttributes, err := SQSClient.GetQueueAttributes(
+		context.TODO(),
+		&sqs.GetQueueAttributesInput{
+			QueueUrl: &queueURL,
+			AttributeNames: []sqsTypes.QueueAttributeName{
+				sqsTypes.QueueAttributeNameQueueArn,
+				sqsTypes.QueueAttributeNamePolicy,
+			},
+		},
+		func(o *sqs.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	internal.Cache.Set(cacheKey, GetQueueAttributes.Attributes, cache.DefaultExpiration)
+	return GetQueueAttributes.Attributes, nil
+}
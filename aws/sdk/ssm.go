@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/gob"
 	"fmt"
+	"time"
 
 	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
 	"github.com/patrickmn/go-cache"
@@ -13,10 +15,21 @@ import (
 
 type AWSSSMClientInterface interface {
 	DescribeParameters(ctx context.Context, params *ssm.DescribeParametersInput, optFns ...func(*ssm.Options)) (*ssm.DescribeParametersOutput, error)
+	DescribeAutomationExecutions(ctx context.Context, params *ssm.DescribeAutomationExecutionsInput, optFns ...func(*ssm.Options)) (*ssm.DescribeAutomationExecutionsOutput, error)
+	DescribeAutomationStepExecutions(ctx context.Context, params *ssm.DescribeAutomationStepExecutionsInput, optFns ...func(*ssm.Options)) (*ssm.DescribeAutomationStepExecutionsOutput, error)
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+	GetParameterHistory(ctx context.Context, params *ssm.GetParameterHistoryInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterHistoryOutput, error)
+	ListDocuments(ctx context.Context, params *ssm.ListDocumentsInput, optFns ...func(*ssm.Options)) (*ssm.ListDocumentsOutput, error)
+	GetDocument(ctx context.Context, params *ssm.GetDocumentInput, optFns ...func(*ssm.Options)) (*ssm.GetDocumentOutput, error)
+	DescribeDocumentPermission(ctx context.Context, params *ssm.DescribeDocumentPermissionInput, optFns ...func(*ssm.Options)) (*ssm.DescribeDocumentPermissionOutput, error)
+	ListTagsForResource(ctx context.Context, params *ssm.ListTagsForResourceInput, optFns ...func(*ssm.Options)) (*ssm.ListTagsForResourceOutput, error)
 }
 
 func init() {
 	gob.Register([]types.ParameterMetadata{})
+	gob.Register([]types.AutomationExecutionMetadata{})
+	gob.Register([]types.StepExecution{})
+	gob.Register([]types.DocumentIdentifier{})
 }
 
 // create a CachedSSMDescribeParameters function that uses go-cache line the other Cached* functions. It should accept a ssm client, account id, and region. Make sure it handles the region option and pagination if needed
@@ -59,3 +72,193 @@ func CachedSSMDescribeParameters(SSMClient AWSSSMClientInterface, accountID stri
 	internal.Cache.Set(cacheKey, parameters, cache.DefaultExpiration)
 	return parameters, nil
 }
+
+// CachedSSMDescribeAutomationExecutions returns the automation executions that were started after the supplied time
+func CachedSSMDescribeAutomationExecutions(SSMClient AWSSSMClientInterface, accountID string, region string, startTimeAfter time.Time) ([]types.AutomationExecutionMetadata, error) {
+	var PaginationControl *string
+	var executions []types.AutomationExecutionMetadata
+	startTime := startTimeAfter.UTC().Format(time.RFC3339)
+	cacheKey := fmt.Sprintf("%s-ssm-DescribeAutomationExecutions-%s-%s", accountID, region, startTime)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		sharedLogger.Debug("Using cached SSM automation executions data")
+		return cached.([]types.AutomationExecutionMetadata), nil
+	}
+
+	for {
+		DescribeAutomationExecutions, err := SSMClient.DescribeAutomationExecutions(
+			context.TODO(),
+			&ssm.DescribeAutomationExecutionsInput{
+				NextToken: PaginationControl,
+				Filters: []types.AutomationExecutionFilter{
+					{
+						Key:    types.AutomationExecutionFilterKeyStartTimeAfter,
+						Values: []string{startTime},
+					},
+				},
+			},
+			func(o *ssm.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return executions, err
+		}
+
+		executions = append(executions, DescribeAutomationExecutions.AutomationExecutionMetadataList...)
+
+		// Pagination control.
+		if DescribeAutomationExecutions.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeAutomationExecutions.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, executions, cache.DefaultExpiration)
+	return executions, nil
+}
+
+func CachedSSMDescribeAutomationStepExecutions(SSMClient AWSSSMClientInterface, accountID string, region string, executionID string) ([]types.StepExecution, error) {
+	var PaginationControl *string
+	var steps []types.StepExecution
+	cacheKey := fmt.Sprintf("%s-ssm-DescribeAutomationStepExecutions-%s-%s", accountID, region, executionID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]types.StepExecution), nil
+	}
+
+	for {
+		DescribeAutomationStepExecutions, err := SSMClient.DescribeAutomationStepExecutions(
+			context.TODO(),
+			&ssm.DescribeAutomationStepExecutionsInput{
+				AutomationExecutionId: &executionID,
+				NextToken:             PaginationControl,
+			},
+			func(o *ssm.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return steps, err
+		}
+
+		steps = append(steps, DescribeAutomationStepExecutions.StepExecutions...)
+
+		// Pagination control.
+		if DescribeAutomationStepExecutions.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeAutomationStepExecutions.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, steps, cache.DefaultExpiration)
+	return steps, nil
+}
+
+// CachedSSMListOwnedDocuments returns the documents owned by the account. AWS and third party documents are left out.
+func CachedSSMListOwnedDocuments(SSMClient AWSSSMClientInterface, accountID string, region string) ([]types.DocumentIdentifier, error) {
+	var PaginationControl *string
+	var documents []types.DocumentIdentifier
+	cacheKey := fmt.Sprintf("%s-ssm-ListDocuments-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]types.DocumentIdentifier), nil
+	}
+
+	for {
+		ListDocuments, err := SSMClient.ListDocuments(
+			context.TODO(),
+			&ssm.ListDocumentsInput{
+				NextToken: PaginationControl,
+				Filters: []types.DocumentKeyValuesFilter{
+					{
+						Key:    aws.String("Owner"),
+						Values: []string{"Self"},
+					},
+				},
+			},
+			func(o *ssm.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return documents, err
+		}
+
+		documents = append(documents, ListDocuments.DocumentIdentifiers...)
+
+		// Pagination control.
+		if ListDocuments.NextToken == nil {
+			break
+		}
+		PaginationControl = ListDocuments.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, documents, cache.DefaultExpiration)
+	return documents, nil
+}
+
+// CachedSSMGetDocumentContent returns the content of the default version of a document
+func CachedSSMGetDocumentContent(SSMClient AWSSSMClientInterface, accountID string, region string, documentName string) (string, error) {
+	cacheKey := fmt.Sprintf("%s-ssm-GetDocument-%s-%s", accountID, region, documentName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(string), nil
+	}
+
+	GetDocument, err := SSMClient.GetDocument(
+		context.TODO(),
+		&ssm.GetDocumentInput{
+			Name: &documentName,
+		},
+		func(o *ssm.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	content := aws.ToString(GetDocument.Content)
+	internal.Cache.Set(cacheKey, content, cache.DefaultExpiration)
+	return content, nil
+}
+
+// CachedSSMDescribeDocumentSharing returns the accounts a document is shared with. "all" means it is public.
+func CachedSSMDescribeDocumentSharing(SSMClient AWSSSMClientInterface, accountID string, region string, documentName string) ([]string, error) {
+	var PaginationControl *string
+	var accountIDs []string
+	cacheKey := fmt.Sprintf("%s-ssm-DescribeDocumentPermission-%s-%s", accountID, region, documentName)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]string), nil
+	}
+
+	for {
+		DescribeDocumentPermission, err := SSMClient.DescribeDocumentPermission(
+			context.TODO(),
+			&ssm.DescribeDocumentPermissionInput{
+				Name:           &documentName,
+				PermissionType: types.DocumentPermissionTypeShare,
+				NextToken:      PaginationControl,
+			},
+			func(o *ssm.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return accountIDs, err
+		}
+
+		accountIDs = append(accountIDs, DescribeDocumentPermission.AccountIds...)
+
+		// Pagination control.
+		if DescribeDocumentPermission.NextToken == nil {
+			break
+		}
+		PaginationControl = DescribeDocumentPermission.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, accountIDs, cache.DefaultExpiration)
+	return accountIDs, nil
+}
@@ -13,10 +13,12 @@ import (
 
 type StepFunctionsClientInterface interface {
 	ListStateMachines(context.Context, *sfn.ListStateMachinesInput, ...func(*sfn.Options)) (*sfn.ListStateMachinesOutput, error)
+	DescribeStateMachine(context.Context, *sfn.DescribeStateMachineInput, ...func(*sfn.Options)) (*sfn.DescribeStateMachineOutput, error)
 }
 
 func init() {
 	gob.Register([]sfnTypes.StateMachineListItem{})
+	gob.Register(customDescribeStateMachineOutput{})
 }
 
 func CachedStepFunctionsListStateMachines(client StepFunctionsClientInterface, accountID string, region string) ([]sfnTypes.StateMachineListItem, error) {
@@ -54,3 +56,45 @@ func CachedStepFunctionsListStateMachines(client StepFunctionsClientInterface, a
 	internal.Cache.Set(cacheKey, stateMachines, cache.DefaultExpiration)
 	return stateMachines, nil
 }
+
+// The parts of DescribeStateMachineOutput that we care about. The full output can't be gob encoded for the cache.
+type customDescribeStateMachineOutput struct {
+	Name            *string
+	StateMachineArn *string
+	Definition      *string
+	RoleArn         *string
+	Type            sfnTypes.StateMachineType
+}
+
+func CachedStepFunctionsDescribeStateMachine(client StepFunctionsClientInterface, accountID string, region string, stateMachineArn string) (customDescribeStateMachineOutput, error) {
+	var stateMachine customDescribeStateMachineOutput
+	cacheKey := fmt.Sprintf("%s-stepfunctions-DescribeStateMachine-%s-%s", accountID, region, stateMachineArn)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(customDescribeStateMachineOutput), nil
+	}
+
+	DescribeStateMachine, err := client.DescribeStateMachine(
+		context.TODO(),
+		&sfn.DescribeStateMachineInput{
+			StateMachineArn: &stateMachineArn,
+		},
+		func(o *sfn.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return stateMachine, err
+	}
+
+	stateMachine = customDescribeStateMachineOutput{
+		Name:            DescribeStateMachine.Name,
+		StateMachineArn: DescribeStateMachine.StateMachineArn,
+		Definition:      DescribeStateMachine.Definition,
+		RoleArn:         DescribeStateMachine.RoleArn,
+		Type:            DescribeStateMachine.Type,
+	}
+
+	internal.Cache.Set(cacheKey, stateMachine, cache.DefaultExpiration)
+	return stateMachine, nil
+}
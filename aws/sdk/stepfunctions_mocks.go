@@ -2,6 +2,8 @@ package sdk
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sfn"
@@ -25,3 +27,53 @@ func (m *MockedStepFunctionsClient) ListStateMachines(ctx context.Context, input
 		},
 	}, nil
 }
+
+var mockedStepFunctionsDefinitions = map[string]string{
+	"state_machine1": `{
+  "Comment": "Nightly export",
+  "StartAt": "Export",
+  "States": {
+    "Export": {
+      "Type": "Task",
+      "Resource": "arn:aws:states:::http:invoke",
+      "Parameters": {
+        "ApiEndpoint": "https://reports.internal.example.com/export",
+        "Headers": {
+          "api_key": "9f8e7d6c5b4a39281706f5e4d3c2b1a0"
+        },
+        "RequestBody": {
+          "password.$": "$.password"
+        }
+      },
+      "End": true
+    }
+  }
+}`,
+	"state_machine2": `{
+  "StartAt": "Wait",
+  "States": {
+    "Wait": {
+      "Type": "Wait",
+      "Seconds": 10,
+      "End": true
+    }
+  }
+}`,
+}
+
+func (m *MockedStepFunctionsClient) DescribeStateMachine(ctx context.Context, input *sfn.DescribeStateMachineInput, options ...func(*sfn.Options)) (*sfn.DescribeStateMachineOutput, error) {
+	// arn:aws:states:<region>:<account>:stateMachine:<name>
+	arn := aws.ToString(input.StateMachineArn)
+	name := arn[strings.LastIndex(arn, ":")+1:]
+	definition, ok := mockedStepFunctionsDefinitions[name]
+	if !ok {
+		return nil, fmt.Errorf("state machine %s not found", arn)
+	}
+	return &sfn.DescribeStateMachineOutput{
+		Name:            aws.String(name),
+		StateMachineArn: input.StateMachineArn,
+		Definition:      aws.String(definition),
+		RoleArn:         aws.String("arn:aws:iam::123456789012:role/" + name + "-role"),
+		Type:            sfnTypes.StateMachineTypeStandard,
+	}, nil
+}
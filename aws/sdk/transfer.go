@@ -0,0 +1,124 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/transfer"
+	transferTypes "github.com/aws/aws-sdk-go-v2/service/transfer/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type TransferClientInterface interface {
+	ListServers(context.Context, *transfer.ListServersInput, ...func(*transfer.Options)) (*transfer.ListServersOutput, error)
+	DescribeServer(context.Context, *transfer.DescribeServerInput, ...func(*transfer.Options)) (*transfer.DescribeServerOutput, error)
+	ListUsers(context.Context, *transfer.ListUsersInput, ...func(*transfer.Options)) (*transfer.ListUsersOutput, error)
+}
+
+func init() {
+	gob.Register([]transferTypes.ListedServer{})
+	gob.Register(transferTypes.DescribedServer{})
+	gob.Register([]transferTypes.ListedUser{})
+}
+
+func CachedTransferListServers(client TransferClientInterface, accountID string, region string) ([]transferTypes.ListedServer, error) {
+	var PaginationControl *string
+	var servers []transferTypes.ListedServer
+	cacheKey := fmt.Sprintf("%s-transfer-ListServers-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]transferTypes.ListedServer), nil
+	}
+
+	for {
+		ListServers, err := client.ListServers(
+			context.TODO(),
+			&transfer.ListServersInput{
+				NextToken: PaginationControl,
+			},
+			func(o *transfer.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return servers, err
+		}
+		servers = append(servers, ListServers.Servers...)
+
+		if ListServers.NextToken == nil {
+			break
+		}
+		PaginationControl = ListServers.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, servers, cache.DefaultExpiration)
+	return servers, nil
+}
+
+func CachedTransferDescribeServer(client TransferClientInterface, accountID string, region string, serverID string) (transferTypes.DescribedServer, error) {
+	var server transferTypes.DescribedServer
+	cacheKey := fmt.Sprintf("%s-transfer-DescribeServer-%s-%s", accountID, region, serverID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(transferTypes.DescribedServer), nil
+	}
+
+	DescribeServer, err := client.DescribeServer(
+		context.TODO(),
+		&transfer.DescribeServerInput{
+			ServerId: aws.String(serverID),
+		},
+		func(o *transfer.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return server, err
+	}
+	if DescribeServer.Server != nil {
+		server = *DescribeServer.Server
+	}
+
+	internal.Cache.Set(cacheKey, server, cache.DefaultExpiration)
+	return server, nil
+}
+
+// CachedTransferListUsers lists the users of a server with SERVICE_MANAGED identities. The other identity providers keep
+// their users outside of Transfer Family.
+func CachedTransferListUsers(client TransferClientInterface, accountID string, region string, serverID string) ([]transferTypes.ListedUser, error) {
+	var PaginationControl *string
+	var users []transferTypes.ListedUser
+	cacheKey := fmt.Sprintf("%s-transfer-ListUsers-%s-%s", accountID, region, serverID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]transferTypes.ListedUser), nil
+	}
+
+	for {
+		ListUsers, err := client.ListUsers(
+			context.TODO(),
+			&transfer.ListUsersInput{
+				ServerId:  aws.String(serverID),
+				NextToken: PaginationControl,
+			},
+			func(o *transfer.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return users, err
+		}
+		users = append(users, ListUsers.Users...)
+
+		if ListUsers.NextToken == nil {
+			break
+		}
+		PaginationControl = ListUsers.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, users, cache.DefaultExpiration)
+	return users, nil
+}
@@ -0,0 +1,90 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/transfer"
+	transferTypes "github.com/aws/aws-sdk-go-v2/service/transfer/types"
+)
+
+type MockedTransferClient struct {
+}
+
+// s-1111 is a public SFTP server with service managed users, s-2222 is a VPC endpoint with an Elastic IP that
+// authenticates through API Gateway, s-3333 is an internal FTPS server backed by a directory.
+var mockedTransferServers = map[string]transferTypes.DescribedServer{
+	"s-11111111111111111": {
+		Arn:                  aws.String("arn:aws:transfer:us-east-1:123456789012:server/s-11111111111111111"),
+		ServerId:             aws.String("s-11111111111111111"),
+		EndpointType:         transferTypes.EndpointTypePublic,
+		IdentityProviderType: transferTypes.IdentityProviderTypeServiceManaged,
+		Protocols:            []transferTypes.Protocol{transferTypes.ProtocolSftp},
+		State:                transferTypes.StateOnline,
+	},
+	"s-22222222222222222": {
+		Arn:                  aws.String("arn:aws:transfer:us-east-1:123456789012:server/s-22222222222222222"),
+		ServerId:             aws.String("s-22222222222222222"),
+		EndpointType:         transferTypes.EndpointTypeVpc,
+		EndpointDetails:      &transferTypes.EndpointDetails{VpcId: aws.String("vpc-12345678"), AddressAllocationIds: []string{"eipalloc-12345678"}},
+		IdentityProviderType: transferTypes.IdentityProviderTypeApiGateway,
+		IdentityProviderDetails: &transferTypes.IdentityProviderDetails{
+			Url: aws.String("https://abcdefghij.execute-api.us-east-1.amazonaws.com/prod"),
+		},
+		Protocols: []transferTypes.Protocol{transferTypes.ProtocolSftp, transferTypes.ProtocolFtps},
+		State:     transferTypes.StateOnline,
+	},
+	"s-33333333333333333": {
+		Arn:                  aws.String("arn:aws:transfer:us-east-1:123456789012:server/s-33333333333333333"),
+		ServerId:             aws.String("s-33333333333333333"),
+		EndpointType:         transferTypes.EndpointTypeVpc,
+		EndpointDetails:      &transferTypes.EndpointDetails{VpcId: aws.String("vpc-12345678")},
+		IdentityProviderType: transferTypes.IdentityProviderTypeAwsDirectoryService,
+		IdentityProviderDetails: &transferTypes.IdentityProviderDetails{
+			DirectoryId: aws.String("d-1234567890"),
+		},
+		Protocols: []transferTypes.Protocol{transferTypes.ProtocolFtps},
+		State:     transferTypes.StateOnline,
+	},
+}
+
+func (m *MockedTransferClient) ListServers(ctx context.Context, input *transfer.ListServersInput, options ...func(*transfer.Options)) (*transfer.ListServersOutput, error) {
+	var servers []transferTypes.ListedServer
+	for _, id := range []string{"s-11111111111111111", "s-22222222222222222", "s-33333333333333333"} {
+		server := mockedTransferServers[id]
+		servers = append(servers, transferTypes.ListedServer{
+			Arn:                  server.Arn,
+			ServerId:             server.ServerId,
+			EndpointType:         server.EndpointType,
+			IdentityProviderType: server.IdentityProviderType,
+			State:                server.State,
+		})
+	}
+	return &transfer.ListServersOutput{Servers: servers}, nil
+}
+
+func (m *MockedTransferClient) DescribeServer(ctx context.Context, input *transfer.DescribeServerInput, options ...func(*transfer.Options)) (*transfer.DescribeServerOutput, error) {
+	server := mockedTransferServers[aws.ToString(input.ServerId)]
+	return &transfer.DescribeServerOutput{Server: &server}, nil
+}
+
+func (m *MockedTransferClient) ListUsers(ctx context.Context, input *transfer.ListUsersInput, options ...func(*transfer.Options)) (*transfer.ListUsersOutput, error) {
+	if aws.ToString(input.ServerId) != "s-11111111111111111" {
+		return &transfer.ListUsersOutput{ServerId: input.ServerId}, nil
+	}
+	return &transfer.ListUsersOutput{
+		ServerId: input.ServerId,
+		Users: []transferTypes.ListedUser{
+			{
+				UserName:          aws.String("partner-acme"),
+				Role:              aws.String("arn:aws:iam::123456789012:role/transfer-partner-acme"),
+				SshPublicKeyCount: aws.Int32(1),
+			},
+			{
+				UserName:          aws.String("reports"),
+				Role:              aws.String("arn:aws:iam::123456789012:role/transfer-reports"),
+				SshPublicKeyCount: aws.Int32(0),
+			},
+		},
+	}, nil
+}
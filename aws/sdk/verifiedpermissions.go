@@ -0,0 +1,181 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/verifiedpermissions"
+	verifiedpermissionsTypes "github.com/aws/aws-sdk-go-v2/service/verifiedpermissions/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type VerifiedPermissionsClientInterface interface {
+	ListPolicyStores(context.Context, *verifiedpermissions.ListPolicyStoresInput, ...func(*verifiedpermissions.Options)) (*verifiedpermissions.ListPolicyStoresOutput, error)
+	ListPolicyTemplates(context.Context, *verifiedpermissions.ListPolicyTemplatesInput, ...func(*verifiedpermissions.Options)) (*verifiedpermissions.ListPolicyTemplatesOutput, error)
+	GetPolicyTemplate(context.Context, *verifiedpermissions.GetPolicyTemplateInput, ...func(*verifiedpermissions.Options)) (*verifiedpermissions.GetPolicyTemplateOutput, error)
+	ListPolicies(context.Context, *verifiedpermissions.ListPoliciesInput, ...func(*verifiedpermissions.Options)) (*verifiedpermissions.ListPoliciesOutput, error)
+}
+
+func init() {
+	gob.Register([]verifiedpermissionsTypes.PolicyStoreItem{})
+	gob.Register([]verifiedpermissionsTypes.PolicyTemplateItem{})
+	gob.Register([]VerifiedPermissionsLinkedPolicy{})
+}
+
+// VerifiedPermissionsLinkedPolicy is a policy instantiated from a template. PolicyItem itself holds a union type and
+// can't be gob encoded.
+type VerifiedPermissionsLinkedPolicy struct {
+	PolicyID  string
+	Principal string
+}
+
+func CachedVerifiedPermissionsListPolicyStores(client VerifiedPermissionsClientInterface, accountID string, region string) ([]verifiedpermissionsTypes.PolicyStoreItem, error) {
+	var PaginationControl *string
+	var policyStores []verifiedpermissionsTypes.PolicyStoreItem
+	cacheKey := fmt.Sprintf("%s-verifiedpermissions-ListPolicyStores-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]verifiedpermissionsTypes.PolicyStoreItem), nil
+	}
+	for {
+		ListPolicyStores, err := client.ListPolicyStores(
+			context.TODO(),
+			&verifiedpermissions.ListPolicyStoresInput{
+				NextToken: PaginationControl,
+			},
+			func(o *verifiedpermissions.Options) {
+				o.Region = region
+			},
+		)
+
+		if err != nil {
+			return policyStores, err
+		}
+
+		policyStores = append(policyStores, ListPolicyStores.PolicyStores...)
+
+		//pagination
+		if ListPolicyStores.NextToken == nil {
+			break
+		}
+		PaginationControl = ListPolicyStores.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, policyStores, cache.DefaultExpiration)
+	return policyStores, nil
+}
+
+func CachedVerifiedPermissionsListPolicyTemplates(client VerifiedPermissionsClientInterface, accountID string, region string, policyStoreID string) ([]verifiedpermissionsTypes.PolicyTemplateItem, error) {
+	var PaginationControl *string
+	var policyTemplates []verifiedpermissionsTypes.PolicyTemplateItem
+	cacheKey := fmt.Sprintf("%s-verifiedpermissions-ListPolicyTemplates-%s-%s", accountID, region, policyStoreID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]verifiedpermissionsTypes.PolicyTemplateItem), nil
+	}
+	for {
+		ListPolicyTemplates, err := client.ListPolicyTemplates(
+			context.TODO(),
+			&verifiedpermissions.ListPolicyTemplatesInput{
+				PolicyStoreId: &policyStoreID,
+				NextToken:     PaginationControl,
+			},
+			func(o *verifiedpermissions.Options) {
+				o.Region = region
+			},
+		)
+
+		if err != nil {
+			return policyTemplates, err
+		}
+
+		policyTemplates = append(policyTemplates, ListPolicyTemplates.PolicyTemplates...)
+
+		//pagination
+		if ListPolicyTemplates.NextToken == nil {
+			break
+		}
+		PaginationControl = ListPolicyTemplates.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, policyTemplates, cache.DefaultExpiration)
+	return policyTemplates, nil
+}
+
+// CachedVerifiedPermissionsGetPolicyTemplate returns the Cedar statement of a policy template
+func CachedVerifiedPermissionsGetPolicyTemplate(client VerifiedPermissionsClientInterface, accountID string, region string, policyStoreID string, policyTemplateID string) (string, error) {
+	cacheKey := fmt.Sprintf("%s-verifiedpermissions-GetPolicyTemplate-%s-%s-%s", accountID, region, policyStoreID, policyTemplateID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(string), nil
+	}
+
+	GetPolicyTemplate, err := client.GetPolicyTemplate(
+		context.TODO(),
+		&verifiedpermissions.GetPolicyTemplateInput{
+			PolicyStoreId:    &policyStoreID,
+			PolicyTemplateId: &policyTemplateID,
+		},
+		func(o *verifiedpermissions.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+	statement := aws.ToString(GetPolicyTemplate.Statement)
+
+	internal.Cache.Set(cacheKey, statement, cache.DefaultExpiration)
+	return statement, nil
+}
+
+// CachedVerifiedPermissionsListTemplateLinkedPolicies returns the policies that were instantiated from a template
+func CachedVerifiedPermissionsListTemplateLinkedPolicies(client VerifiedPermissionsClientInterface, accountID string, region string, policyStoreID string, policyTemplateID string) ([]VerifiedPermissionsLinkedPolicy, error) {
+	var PaginationControl *string
+	var policies []VerifiedPermissionsLinkedPolicy
+	cacheKey := fmt.Sprintf("%s-verifiedpermissions-ListPolicies-%s-%s-%s", accountID, region, policyStoreID, policyTemplateID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]VerifiedPermissionsLinkedPolicy), nil
+	}
+	for {
+		ListPolicies, err := client.ListPolicies(
+			context.TODO(),
+			&verifiedpermissions.ListPoliciesInput{
+				PolicyStoreId: &policyStoreID,
+				Filter: &verifiedpermissionsTypes.PolicyFilter{
+					PolicyTemplateId: &policyTemplateID,
+					PolicyType:       verifiedpermissionsTypes.PolicyTypeTemplateLinked,
+				},
+				NextToken: PaginationControl,
+			},
+			func(o *verifiedpermissions.Options) {
+				o.Region = region
+			},
+		)
+
+		if err != nil {
+			return policies, err
+		}
+
+		for _, policy := range ListPolicies.Policies {
+			linkedPolicy := VerifiedPermissionsLinkedPolicy{PolicyID: aws.ToString(policy.PolicyId)}
+			if policy.Principal != nil {
+				linkedPolicy.Principal = fmt.Sprintf("%s::\"%s\"", aws.ToString(policy.Principal.EntityType), aws.ToString(policy.Principal.EntityId))
+			}
+			policies = append(policies, linkedPolicy)
+		}
+
+		//pagination
+		if ListPolicies.NextToken == nil {
+			break
+		}
+		PaginationControl = ListPolicies.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, policies, cache.DefaultExpiration)
+	return policies, nil
+}
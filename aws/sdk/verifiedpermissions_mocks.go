@@ -0,0 +1,118 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/verifiedpermissions"
+	verifiedpermissionsTypes "github.com/aws/aws-sdk-go-v2/service/verifiedpermissions/types"
+)
+
+// MockedVerifiedPermissionsClient has a document store with templates ranging from a condition-scoped read template
+// to one that hands any principal every action, and an empty store
+type MockedVerifiedPermissionsClient struct {
+}
+
+var mockedVerifiedPermissionsTemplates = map[string]struct {
+	description string
+	statement   string
+}{
+	"TMPLadmin1111111111111": {
+		description: "Document editors",
+		statement: `permit (
+    principal == ?principal,
+    action in [DocumentApp::Action::"DeleteDocument", DocumentApp::Action::"UpdateDocument"],
+    resource == ?resource
+);`,
+	},
+	"TMPLowner2222222222222": {
+		description: "Owners read their documents",
+		statement: `permit (
+    principal == ?principal,
+    action == DocumentApp::Action::"ReadDocument",
+    resource == ?resource
+)
+when { resource.owner == principal };`,
+	},
+	"TMPLanyaction33333333": {
+		description: "Delegated access",
+		statement: `@id("delegated-access")
+permit (principal in ?principal, action, resource in ?resource);`,
+	},
+	"TMPLview4444444444444": {
+		description: "Report viewers",
+		statement: `permit (
+    principal == ?principal,
+    action == DocumentApp::Action::"ViewReport",
+    resource
+);`,
+	},
+	"TMPLdeny5555555555555": {
+		description: "Suspended users",
+		statement:   `forbid (principal == ?principal, action, resource);`,
+	},
+}
+
+func (m *MockedVerifiedPermissionsClient) ListPolicyStores(ctx context.Context, input *verifiedpermissions.ListPolicyStoresInput, options ...func(*verifiedpermissions.Options)) (*verifiedpermissions.ListPolicyStoresOutput, error) {
+	return &verifiedpermissions.ListPolicyStoresOutput{
+		PolicyStores: []verifiedpermissionsTypes.PolicyStoreItem{
+			{
+				PolicyStoreId: aws.String("PSdocuments1111111111"),
+				Arn:           aws.String("arn:aws:verifiedpermissions::123456789012:policy-store/PSdocuments1111111111"),
+			},
+			{
+				PolicyStoreId: aws.String("PSempty22222222222222"),
+				Arn:           aws.String("arn:aws:verifiedpermissions::123456789012:policy-store/PSempty22222222222222"),
+			},
+		},
+	}, nil
+}
+
+func (m *MockedVerifiedPermissionsClient) ListPolicyTemplates(ctx context.Context, input *verifiedpermissions.ListPolicyTemplatesInput, options ...func(*verifiedpermissions.Options)) (*verifiedpermissions.ListPolicyTemplatesOutput, error) {
+	var templates []verifiedpermissionsTypes.PolicyTemplateItem
+	if aws.ToString(input.PolicyStoreId) == "PSdocuments1111111111" {
+		for id, template := range mockedVerifiedPermissionsTemplates {
+			templates = append(templates, verifiedpermissionsTypes.PolicyTemplateItem{
+				PolicyStoreId:    input.PolicyStoreId,
+				PolicyTemplateId: aws.String(id),
+				Description:      aws.String(template.description),
+			})
+		}
+	}
+	return &verifiedpermissions.ListPolicyTemplatesOutput{
+		PolicyTemplates: templates,
+	}, nil
+}
+
+func (m *MockedVerifiedPermissionsClient) GetPolicyTemplate(ctx context.Context, input *verifiedpermissions.GetPolicyTemplateInput, options ...func(*verifiedpermissions.Options)) (*verifiedpermissions.GetPolicyTemplateOutput, error) {
+	template, ok := mockedVerifiedPermissionsTemplates[aws.ToString(input.PolicyTemplateId)]
+	if !ok {
+		return nil, &verifiedpermissionsTypes.ResourceNotFoundException{Message: aws.String("Policy template not found")}
+	}
+	return &verifiedpermissions.GetPolicyTemplateOutput{
+		PolicyStoreId:    input.PolicyStoreId,
+		PolicyTemplateId: input.PolicyTemplateId,
+		Description:      aws.String(template.description),
+		Statement:        aws.String(template.statement),
+	}, nil
+}
+
+func (m *MockedVerifiedPermissionsClient) ListPolicies(ctx context.Context, input *verifiedpermissions.ListPoliciesInput, options ...func(*verifiedpermissions.Options)) (*verifiedpermissions.ListPoliciesOutput, error) {
+	var policies []verifiedpermissionsTypes.PolicyItem
+	if input.Filter != nil && aws.ToString(input.Filter.PolicyTemplateId) == "TMPLadmin1111111111111" {
+		for _, user := range []string{"alice", "bob"} {
+			policies = append(policies, verifiedpermissionsTypes.PolicyItem{
+				PolicyId:      aws.String("POLICY" + user),
+				PolicyStoreId: input.PolicyStoreId,
+				PolicyType:    verifiedpermissionsTypes.PolicyTypeTemplateLinked,
+				Principal: &verifiedpermissionsTypes.EntityIdentifier{
+					EntityType: aws.String("DocumentApp::User"),
+					EntityId:   aws.String(user),
+				},
+			})
+		}
+	}
+	return &verifiedpermissions.ListPoliciesOutput{
+		Policies: policies,
+	}, nil
+}
@@ -0,0 +1,120 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/wafv2"
+	wafv2Types "github.com/aws/aws-sdk-go-v2/service/wafv2/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type WAFv2ClientInterface interface {
+	ListWebACLs(context.Context, *wafv2.ListWebACLsInput, ...func(*wafv2.Options)) (*wafv2.ListWebACLsOutput, error)
+	ListResourcesForWebACL(context.Context, *wafv2.ListResourcesForWebACLInput, ...func(*wafv2.Options)) (*wafv2.ListResourcesForWebACLOutput, error)
+	GetLoggingConfiguration(context.Context, *wafv2.GetLoggingConfigurationInput, ...func(*wafv2.Options)) (*wafv2.GetLoggingConfigurationOutput, error)
+}
+
+func init() {
+	gob.Register([]wafv2Types.WebACLSummary{})
+	gob.Register(&wafv2Types.LoggingConfiguration{})
+}
+
+// CachedWAFv2ListWebACLs lists the web ACLs for a scope. CLOUDFRONT scoped web ACLs can only be listed from us-east-1.
+func CachedWAFv2ListWebACLs(client WAFv2ClientInterface, accountID string, region string, scope wafv2Types.Scope) ([]wafv2Types.WebACLSummary, error) {
+	var PaginationControl *string
+	var webACLs []wafv2Types.WebACLSummary
+	cacheKey := fmt.Sprintf("%s-wafv2-ListWebACLs-%s-%s", accountID, region, scope)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]wafv2Types.WebACLSummary), nil
+	}
+
+	for {
+		ListWebACLs, err := client.ListWebACLs(
+			context.TODO(),
+			&wafv2.ListWebACLsInput{
+				Scope:      scope,
+				NextMarker: PaginationControl,
+			},
+			func(o *wafv2.Options) {
+				o.Region = region
+			},
+		)
+
+		if err != nil {
+			return webACLs, err
+		}
+
+		webACLs = append(webACLs, ListWebACLs.WebACLs...)
+
+		// ListWebACLs can return a NextMarker on the last page, so also stop when a page comes back empty
+		if ListWebACLs.NextMarker == nil || len(ListWebACLs.WebACLs) == 0 {
+			break
+		}
+		PaginationControl = ListWebACLs.NextMarker
+	}
+
+	internal.Cache.Set(cacheKey, webACLs, cache.DefaultExpiration)
+	return webACLs, nil
+}
+
+func CachedWAFv2ListResourcesForWebACL(client WAFv2ClientInterface, accountID string, region string, webACLArn string, resourceType wafv2Types.ResourceType) ([]string, error) {
+	cacheKey := fmt.Sprintf("%s-wafv2-ListResourcesForWebACL-%s-%s-%s", accountID, region, webACLArn, resourceType)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]string), nil
+	}
+
+	ListResourcesForWebACL, err := client.ListResourcesForWebACL(
+		context.TODO(),
+		&wafv2.ListResourcesForWebACLInput{
+			WebACLArn:    aws.String(webACLArn),
+			ResourceType: resourceType,
+		},
+		func(o *wafv2.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	internal.Cache.Set(cacheKey, ListResourcesForWebACL.ResourceArns, cache.DefaultExpiration)
+	return ListResourcesForWebACL.ResourceArns, nil
+}
+
+// CachedWAFv2GetLoggingConfiguration returns the logging configuration of a web ACL. Web ACLs without logging return a
+// WAFNonexistentItemException, which is returned as a nil configuration.
+func CachedWAFv2GetLoggingConfiguration(client WAFv2ClientInterface, accountID string, region string, webACLArn string) (*wafv2Types.LoggingConfiguration, error) {
+	cacheKey := fmt.Sprintf("%s-wafv2-GetLoggingConfiguration-%s-%s", accountID, region, webACLArn)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.(*wafv2Types.LoggingConfiguration), nil
+	}
+
+	GetLoggingConfiguration, err := client.GetLoggingConfiguration(
+		context.TODO(),
+		&wafv2.GetLoggingConfigurationInput{
+			ResourceArn: aws.String(webACLArn),
+		},
+		func(o *wafv2.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		var notFound *wafv2Types.WAFNonexistentItemException
+		if errors.As(err, &notFound) {
+			internal.Cache.Set(cacheKey, (*wafv2Types.LoggingConfiguration)(nil), cache.DefaultExpiration)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	internal.Cache.Set(cacheKey, GetLoggingConfiguration.LoggingConfiguration, cache.DefaultExpiration)
+	return GetLoggingConfiguration.LoggingConfiguration, nil
+}
@@ -0,0 +1,102 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/wafv2"
+	wafv2Types "github.com/aws/aws-sdk-go-v2/service/wafv2/types"
+)
+
+type MockedWAFv2Client struct {
+}
+
+func (m *MockedWAFv2Client) ListWebACLs(ctx context.Context, input *wafv2.ListWebACLsInput, options ...func(*wafv2.Options)) (*wafv2.ListWebACLsOutput, error) {
+	if input.Scope == wafv2Types.ScopeCloudfront {
+		return &wafv2.ListWebACLsOutput{
+			WebACLs: []wafv2Types.WebACLSummary{
+				{
+					Name: aws.String("cloudfront-acl"),
+					Id:   aws.String("33333333"),
+					ARN:  aws.String("arn:aws:wafv2:us-east-1:123456789012:global/webacl/cloudfront-acl/33333333"),
+				},
+			},
+		}, nil
+	}
+	var o wafv2.Options
+	for _, option := range options {
+		option(&o)
+	}
+	if o.Region == "eu-west-1" {
+		return &wafv2.ListWebACLsOutput{
+			WebACLs: []wafv2Types.WebACLSummary{
+				{
+					Name: aws.String("api-acl"),
+					Id:   aws.String("44444444"),
+					ARN:  aws.String("arn:aws:wafv2:eu-west-1:123456789012:regional/webacl/api-acl/44444444"),
+				},
+			},
+		}, nil
+	}
+	return &wafv2.ListWebACLsOutput{
+		WebACLs: []wafv2Types.WebACLSummary{
+			{
+				Name: aws.String("alb-acl"),
+				Id:   aws.String("11111111"),
+				ARN:  aws.String("arn:aws:wafv2:us-east-1:123456789012:regional/webacl/alb-acl/11111111"),
+			},
+			{
+				Name: aws.String("orphaned-acl"),
+				Id:   aws.String("22222222"),
+				ARN:  aws.String("arn:aws:wafv2:us-east-1:123456789012:regional/webacl/orphaned-acl/22222222"),
+			},
+		},
+	}, nil
+}
+
+func (m *MockedWAFv2Client) ListResourcesForWebACL(ctx context.Context, input *wafv2.ListResourcesForWebACLInput, options ...func(*wafv2.Options)) (*wafv2.ListResourcesForWebACLOutput, error) {
+	if aws.ToString(input.WebACLArn) == "arn:aws:wafv2:us-east-1:123456789012:regional/webacl/alb-acl/11111111" && input.ResourceType == wafv2Types.ResourceType("APPLICATION_LOAD_BALANCER") {
+		return &wafv2.ListResourcesForWebACLOutput{
+			ResourceArns: []string{"arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/alb1/1234567890"},
+		}, nil
+	}
+	return &wafv2.ListResourcesForWebACLOutput{}, nil
+}
+
+// alb-acl logs to CloudWatch and redacts the Authorization and Cookie headers, cloudfront-acl logs to Firehose and api-acl to S3
+// without redacting anything. orphaned-acl doesn't log at all.
+func (m *MockedWAFv2Client) GetLoggingConfiguration(ctx context.Context, input *wafv2.GetLoggingConfigurationInput, options ...func(*wafv2.Options)) (*wafv2.GetLoggingConfigurationOutput, error) {
+	arn := aws.ToString(input.ResourceArn)
+	switch arn {
+	case "arn:aws:wafv2:us-east-1:123456789012:regional/webacl/alb-acl/11111111":
+		return &wafv2.GetLoggingConfigurationOutput{
+			LoggingConfiguration: &wafv2Types.LoggingConfiguration{
+				ResourceArn:           input.ResourceArn,
+				LogDestinationConfigs: []string{"arn:aws:logs:us-east-1:123456789012:log-group:aws-waf-logs-alb"},
+				RedactedFields: []wafv2Types.FieldToMatch{
+					{
+						SingleHeader: &wafv2Types.SingleHeader{Name: aws.String("Authorization")},
+					},
+					{
+						SingleHeader: &wafv2Types.SingleHeader{Name: aws.String("cookie")},
+					},
+				},
+			},
+		}, nil
+	case "arn:aws:wafv2:us-east-1:123456789012:global/webacl/cloudfront-acl/33333333":
+		return &wafv2.GetLoggingConfigurationOutput{
+			LoggingConfiguration: &wafv2Types.LoggingConfiguration{
+				ResourceArn:           input.ResourceArn,
+				LogDestinationConfigs: []string{"arn:aws:firehose:us-east-1:123456789012:deliverystream/aws-waf-logs-cloudfront"},
+			},
+		}, nil
+	case "arn:aws:wafv2:eu-west-1:123456789012:regional/webacl/api-acl/44444444":
+		return &wafv2.GetLoggingConfigurationOutput{
+			LoggingConfiguration: &wafv2Types.LoggingConfiguration{
+				ResourceArn:           input.ResourceArn,
+				LogDestinationConfigs: []string{"arn:aws:s3:::aws-waf-logs-api/prefix"},
+			},
+		}, nil
+	}
+	return nil, &wafv2Types.WAFNonexistentItemException{Message: aws.String("AWS WAF could not perform the operation because your resource does not exist.")}
+}
@@ -0,0 +1,95 @@
+package sdk
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/service/workmail"
+	workMailTypes "github.com/aws/aws-sdk-go-v2/service/workmail/types"
+	"github.com/patrickmn/go-cache"
+)
+
+type AWSWorkMailClientInterface interface {
+	ListOrganizations(ctx context.Context, params *workmail.ListOrganizationsInput, optFns ...func(*workmail.Options)) (*workmail.ListOrganizationsOutput, error)
+	ListUsers(ctx context.Context, params *workmail.ListUsersInput, optFns ...func(*workmail.Options)) (*workmail.ListUsersOutput, error)
+}
+
+func init() {
+	gob.Register([]workMailTypes.OrganizationSummary{})
+	gob.Register([]workMailTypes.User{})
+}
+
+func CachedWorkMailListOrganizations(client AWSWorkMailClientInterface, accountID string, region string) ([]workMailTypes.OrganizationSummary, error) {
+	var PaginationControl *string
+	var organizations []workMailTypes.OrganizationSummary
+	cacheKey := fmt.Sprintf("%s-workmail-ListOrganizations-%s", accountID, region)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]workMailTypes.OrganizationSummary), nil
+	}
+	for {
+		ListOrganizations, err := client.ListOrganizations(
+			context.TODO(),
+			&workmail.ListOrganizationsInput{
+				NextToken: PaginationControl,
+			},
+			func(o *workmail.Options) {
+				o.Region = region
+			},
+		)
+
+		if err != nil {
+			return organizations, err
+		}
+
+		organizations = append(organizations, ListOrganizations.OrganizationSummaries...)
+
+		//pagination
+		if ListOrganizations.NextToken == nil {
+			break
+		}
+		PaginationControl = ListOrganizations.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, organizations, cache.DefaultExpiration)
+	return organizations, nil
+}
+
+func CachedWorkMailListUsers(client AWSWorkMailClientInterface, accountID string, region string, organizationID string) ([]workMailTypes.User, error) {
+	var PaginationControl *string
+	var users []workMailTypes.User
+	cacheKey := fmt.Sprintf("%s-workmail-ListUsers-%s-%s", accountID, region, organizationID)
+	cached, found := internal.Cache.Get(cacheKey)
+	if found {
+		return cached.([]workMailTypes.User), nil
+	}
+	for {
+		ListUsers, err := client.ListUsers(
+			context.TODO(),
+			&workmail.ListUsersInput{
+				OrganizationId: &organizationID,
+				NextToken:      PaginationControl,
+			},
+			func(o *workmail.Options) {
+				o.Region = region
+			},
+		)
+
+		if err != nil {
+			return users, err
+		}
+
+		users = append(users, ListUsers.Users...)
+
+		//pagination
+		if ListUsers.NextToken == nil {
+			break
+		}
+		PaginationControl = ListUsers.NextToken
+	}
+
+	internal.Cache.Set(cacheKey, users, cache.DefaultExpiration)
+	return users, nil
+}
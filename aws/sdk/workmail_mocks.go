@@ -0,0 +1,61 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/workmail"
+	workMailTypes "github.com/aws/aws-sdk-go-v2/service/workmail/types"
+)
+
+type MockedWorkMailClient struct {
+}
+
+func (m *MockedWorkMailClient) ListOrganizations(ctx context.Context, input *workmail.ListOrganizationsInput, options ...func(*workmail.Options)) (*workmail.ListOrganizationsOutput, error) {
+	return &workmail.ListOrganizationsOutput{
+		OrganizationSummaries: []workMailTypes.OrganizationSummary{
+			{
+				OrganizationId: aws.String("m-11111111111111111111111111111111"),
+				Alias:          aws.String("corp-mail"),
+				State:          aws.String("Active"),
+			},
+			{
+				OrganizationId: aws.String("m-22222222222222222222222222222222"),
+				Alias:          aws.String("old-mail"),
+				State:          aws.String("Active"),
+			},
+		},
+	}, nil
+}
+
+func (m *MockedWorkMailClient) ListUsers(ctx context.Context, input *workmail.ListUsersInput, options ...func(*workmail.Options)) (*workmail.ListUsersOutput, error) {
+	if aws.ToString(input.OrganizationId) == "m-11111111111111111111111111111111" {
+		return &workmail.ListUsersOutput{
+			Users: []workMailTypes.User{
+				{
+					Id:       aws.String("user1"),
+					Name:     aws.String("alice"),
+					Email:    aws.String("alice@corp.example.com"),
+					State:    workMailTypes.EntityStateEnabled,
+					UserRole: workMailTypes.UserRoleUser,
+				},
+			},
+		}, nil
+	}
+	return &workmail.ListUsersOutput{
+		Users: []workMailTypes.User{
+			{
+				Id:       aws.String("S-1-1-11-1111111111-2222222222-3333333333-3"),
+				Name:     aws.String("workmail-system"),
+				State:    workMailTypes.EntityStateEnabled,
+				UserRole: workMailTypes.UserRoleSystemUser,
+			},
+			{
+				Id:       aws.String("user2"),
+				Name:     aws.String("bob"),
+				State:    workMailTypes.EntityStateDisabled,
+				UserRole: workMailTypes.UserRoleUser,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,459 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	cloudtrailTypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type SecretAccessAnomalyModule struct {
+	// General configuration data
+	CloudTrailClient sdk.CloudTrailClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+	// Days is how far back we look for GetSecretValue events
+	Days int
+
+	// Main module data
+	SecretAccesses    []SecretAccess
+	Mean              float64
+	StandardDeviation float64
+	CommandCounter    internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+// SecretAccess is how often one principal read one secret in the time window
+type SecretAccess struct {
+	Region        string
+	Secret        string
+	Principal     string
+	PrincipalType string
+	Accesses      int
+	FirstAccess   time.Time
+	LastAccess    time.Time
+	ZScore        float64
+	Finding       string
+}
+
+// secretAccessEvent holds the parts of a GetSecretValue CloudTrail event we need
+type secretAccessEvent struct {
+	UserIdentity struct {
+		Type           string `json:"type"`
+		Arn            string `json:"arn"`
+		InvokedBy      string `json:"invokedBy"`
+		SessionContext struct {
+			SessionIssuer struct {
+				Arn string `json:"arn"`
+			} `json:"sessionIssuer"`
+		} `json:"sessionContext"`
+	} `json:"userIdentity"`
+	EventTime         time.Time `json:"eventTime"`
+	RequestParameters struct {
+		SecretID string `json:"secretId"`
+	} `json:"requestParameters"`
+}
+
+const (
+	secretAccessDefaultDays = 30
+	// Combinations whose access count is more than this many standard deviations away from the mean are flagged
+	secretAccessMaxZScore = 2
+	// Principals that read a secret for the first time within this many days of the end of the window are flagged
+	secretAccessNewPrincipalDays = 7
+)
+
+// Secrets manager appends a dash and six random characters to the name in secret ARNs
+var secretARNSuffix = regexp.MustCompile(`-[A-Za-z0-9]{6}$`)
+
+func (m *SecretAccessAnomalyModule) PrintSecretAccessAnomalies(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "secret-access-anomalies"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+	if m.Days <= 0 {
+		m.Days = secretAccessDefaultDays
+	}
+
+	fmt.Printf("[%s][%s] Analyzing the last %d days of GetSecretValue events for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.Days, aws.ToString(m.Caller.Account))
+	fmt.Printf("[%s][%s] Depending on the number of events, this can take a while.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan SecretAccess)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	endTime := time.Now()
+	startTime := endTime.AddDate(0, 0, -m.Days)
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, startTime, endTime, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	m.Mean, m.StandardDeviation = flagSecretAccessAnomalies(m.SecretAccesses, startTime, endTime)
+
+	sort.Slice(m.SecretAccesses, func(i, j int) bool {
+		if m.SecretAccesses[i].Region != m.SecretAccesses[j].Region {
+			return m.SecretAccesses[i].Region < m.SecretAccesses[j].Region
+		}
+		if m.SecretAccesses[i].Secret != m.SecretAccesses[j].Secret {
+			return m.SecretAccesses[i].Secret < m.SecretAccesses[j].Secret
+		}
+		return m.SecretAccesses[i].Principal < m.SecretAccesses[j].Principal
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Secret",
+		"Principal",
+		"Principal Type",
+		"Accesses",
+		"First Access",
+		"Last Access",
+		"Z-Score",
+		"Finding",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Secret",
+			"Principal",
+			"Principal Type",
+			"Accesses",
+			"First Access",
+			"Last Access",
+			"Z-Score",
+			"Finding",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Secret",
+			"Principal",
+			"Accesses",
+			"First Access",
+			"Z-Score",
+			"Finding",
+		}
+	}
+
+	// Table rows
+	var flagged int
+	for _, access := range m.SecretAccesses {
+		finding := access.Finding
+		if finding != "" {
+			flagged++
+			finding = magenta(finding)
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				access.Region,
+				access.Secret,
+				access.Principal,
+				access.PrincipalType,
+				fmt.Sprintf("%d", access.Accesses),
+				access.FirstAccess.UTC().Format(time.RFC3339),
+				access.LastAccess.UTC().Format(time.RFC3339),
+				fmt.Sprintf("%.2f", access.ZScore),
+				finding,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		if flagged > 0 {
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     m.output.CallingModule,
+				Contents: m.writeLoot(startTime),
+			})
+		}
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] Baseline: %.1f accesses per secret and principal, standard deviation %.1f.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.Mean, m.StandardDeviation)
+		fmt.Printf("[%s][%s] %d secret and principal combinations found, %d of them anomalous.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), flagged)
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No GetSecretValue events found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *SecretAccessAnomalyModule) executeChecks(r string, startTime time.Time, endTime time.Time, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan SecretAccess) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("cloudtrail", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getSecretAccessesPerRegion(r, startTime, endTime, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *SecretAccessAnomalyModule) Receiver(receiver chan SecretAccess, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.SecretAccesses = append(m.SecretAccesses, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+// getSecretAccessesPerRegion counts the GetSecretValue events of every secret and principal combination in a region.
+// The events themselves are not cached, CloudTrail is the source of truth for them.
+func (m *SecretAccessAnomalyModule) getSecretAccessesPerRegion(r string, startTime time.Time, endTime time.Time, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan SecretAccess) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	accesses := make(map[string]*SecretAccess)
+	var keys []string
+	var PaginationControl *string
+	for {
+		LookupEvents, err := m.CloudTrailClient.LookupEvents(
+			context.TODO(),
+			&cloudtrail.LookupEventsInput{
+				StartTime: aws.Time(startTime),
+				EndTime:   aws.Time(endTime),
+				LookupAttributes: []cloudtrailTypes.LookupAttribute{
+					{
+						AttributeKey:   cloudtrailTypes.LookupAttributeKeyEventName,
+						AttributeValue: aws.String("GetSecretValue"),
+					},
+				},
+				NextToken: PaginationControl,
+			},
+			func(o *cloudtrail.Options) {
+				o.Region = r
+				o.Retryer = lookupEventsRetryer()
+			},
+		)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			break
+		}
+
+		for _, event := range LookupEvents.Events {
+			var parsed secretAccessEvent
+			if err := json.Unmarshal([]byte(aws.ToString(event.CloudTrailEvent)), &parsed); err != nil {
+				m.modLog.Error(err.Error())
+				continue
+			}
+			secret := secretNameFromID(parsed.RequestParameters.SecretID)
+			if secret == "" {
+				continue
+			}
+			principal := secretAccessPrincipal(parsed)
+			eventTime := parsed.EventTime
+			if eventTime.IsZero() {
+				eventTime = aws.ToTime(event.EventTime)
+			}
+
+			key := secret + "|" + principal
+			access, ok := accesses[key]
+			if !ok {
+				access = &SecretAccess{
+					Region:        r,
+					Secret:        secret,
+					Principal:     principal,
+					PrincipalType: parsed.UserIdentity.Type,
+					FirstAccess:   eventTime,
+					LastAccess:    eventTime,
+				}
+				accesses[key] = access
+				keys = append(keys, key)
+			}
+			access.Accesses++
+			if eventTime.Before(access.FirstAccess) {
+				access.FirstAccess = eventTime
+			}
+			if eventTime.After(access.LastAccess) {
+				access.LastAccess = eventTime
+			}
+		}
+
+		// The "NextToken" value is nil when there's no more data to return.
+		if LookupEvents.NextToken == nil {
+			break
+		}
+		PaginationControl = LookupEvents.NextToken
+	}
+
+	for _, key := range keys {
+		dataReceiver <- *accesses[key]
+	}
+}
+
+// secretNameFromID turns the secretId request parameter, which is either a name or an ARN, into the secret name so
+// both ways of reading a secret are counted together
+func secretNameFromID(secretID string) string {
+	if !strings.HasPrefix(secretID, "arn:") {
+		return secretID
+	}
+	_, name, found := strings.Cut(secretID, ":secret:")
+	if !found {
+		return secretID
+	}
+	return secretARNSuffix.ReplaceAllString(name, "")
+}
+
+// secretAccessPrincipal returns the role behind assumed role sessions, so every session of a role counts as the same
+// principal
+func secretAccessPrincipal(event secretAccessEvent) string {
+	switch {
+	case event.UserIdentity.Type == "AssumedRole" && event.UserIdentity.SessionContext.SessionIssuer.Arn != "":
+		return event.UserIdentity.SessionContext.SessionIssuer.Arn
+	case event.UserIdentity.Arn != "":
+		return event.UserIdentity.Arn
+	case event.UserIdentity.InvokedBy != "":
+		return event.UserIdentity.InvokedBy
+	}
+	return event.UserIdentity.Type
+}
+
+// flagSecretAccessAnomalies uses the access counts of all secret and principal combinations as the baseline and flags
+// combinations whose count is more than secretAccessMaxZScore standard deviations away from it. Principals that only
+// started reading a secret in the last secretAccessNewPrincipalDays days of the window are flagged as new, unless the
+// window is too short to tell. It returns the mean and standard deviation of the baseline.
+func flagSecretAccessAnomalies(accesses []SecretAccess, startTime time.Time, endTime time.Time) (float64, float64) {
+	var counts []float64
+	for _, access := range accesses {
+		counts = append(counts, float64(access.Accesses))
+	}
+	mean := utils.Mean(counts)
+	standardDeviation := utils.StandardDeviation(counts)
+
+	newSince := endTime.AddDate(0, 0, -secretAccessNewPrincipalDays)
+	for i := range accesses {
+		var findings []string
+		accesses[i].ZScore = utils.ZScore(float64(accesses[i].Accesses), mean, standardDeviation)
+		if accesses[i].ZScore > secretAccessMaxZScore {
+			findings = append(findings, "Unusually frequent access")
+		} else if accesses[i].ZScore < -secretAccessMaxZScore {
+			findings = append(findings, "Unusually rare access")
+		}
+		if newSince.After(startTime) && accesses[i].FirstAccess.After(newSince) {
+			findings = append(findings, "New principal")
+		}
+		accesses[i].Finding = strings.Join(findings, ", ")
+	}
+	return mean, standardDeviation
+}
+
+func (m *SecretAccessAnomalyModule) writeLoot(startTime time.Time) string {
+	var out string
+	out += fmt.Sprintln("#############################################")
+	out += fmt.Sprintln("# Review the GetSecretValue events of anomalous secret and principal combinations.")
+	out += fmt.Sprintln("# Set the $profile environment variable to the profile you are going to use, e.g. export profile=dev-prod.")
+	out += fmt.Sprintln("#############################################")
+	out += fmt.Sprintln("")
+	for _, access := range m.SecretAccesses {
+		if access.Finding == "" {
+			continue
+		}
+		out += fmt.Sprintf("# %s read %s %d times: %s\n", access.Principal, access.Secret, access.Accesses, access.Finding)
+		out += fmt.Sprintf("aws --profile $profile --region %s cloudtrail lookup-events --lookup-attributes AttributeKey=EventName,AttributeValue=GetSecretValue --start-time %s --query \"Events[?contains(CloudTrailEvent, '%s') && contains(CloudTrailEvent, '%s')]\"\n\n", access.Region, startTime.UTC().Format(time.RFC3339), access.Secret, access.Principal)
+	}
+	return out
+}
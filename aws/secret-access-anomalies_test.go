@@ -0,0 +1,84 @@
+package aws
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestSecretAccessAnomalies(t *testing.T) {
+	m := SecretAccessAnomalyModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1", "us-west-2"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:       3,
+		WrapTable:        false,
+		CloudTrailClient: &sdk.MockedCloudTrailClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+	tmpDir := "."
+
+	m.PrintSecretAccessAnomalies(tmpDir, 2)
+
+	expected := map[string]struct {
+		accesses int
+		finding  string
+	}{
+		"prod/db-password|arn:aws:iam::123456789012:role/app-server": {accesses: 10},
+		"prod/db-password|arn:aws:iam::123456789012:role/batch":      {accesses: 10},
+		// Read by ARN, counted under the secret name
+		"prod/api-key|arn:aws:iam::123456789012:role/app-server":     {accesses: 10},
+		"prod/api-key|arn:aws:iam::123456789012:role/reporting":      {accesses: 9},
+		"staging/db-password|arn:aws:iam::123456789012:role/ci":      {accesses: 11},
+		"prod/db-password|arn:aws:iam::123456789012:user/mallory":    {accesses: 60, finding: "Unusually frequent access, New principal"},
+		"prod/api-key|arn:aws:iam::123456789012:role/unknown-lambda": {accesses: 1, finding: "New principal"},
+	}
+	if len(m.SecretAccesses) != len(expected) {
+		t.Fatalf("Expected %d secret and principal combinations, got %d", len(expected), len(m.SecretAccesses))
+	}
+	for _, access := range m.SecretAccesses {
+		want, ok := expected[access.Secret+"|"+access.Principal]
+		if !ok {
+			t.Errorf("Unexpected combination of %s and %s", access.Secret, access.Principal)
+			continue
+		}
+		if access.Accesses != want.accesses || access.Finding != want.finding {
+			t.Errorf("Expected %s reading %s %d times with finding %q, got %d times with finding %q", access.Principal, access.Secret, want.accesses, want.finding, access.Accesses, access.Finding)
+		}
+	}
+
+	lootFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/loot/secret-access-anomalies.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	if !strings.Contains(string(lootFile), "# arn:aws:iam::123456789012:user/mallory read prod/db-password 60 times") {
+		t.Errorf("Expected the loot file to list the anomalous access by mallory")
+	}
+	if strings.Contains(string(lootFile), "role/batch") {
+		t.Errorf("Did not expect combinations without findings in the loot file")
+	}
+}
+
+func TestSecretNameFromID(t *testing.T) {
+	subtests := map[string]string{
+		"prod/db-password": "prod/db-password",
+		"arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/api-key-AbCdEf": "prod/api-key",
+	}
+	for secretID, expected := range subtests {
+		if name := secretNameFromID(secretID); name != expected {
+			t.Errorf("Expected %s to be read as %s, got %s", secretID, expected, name)
+		}
+	}
+}
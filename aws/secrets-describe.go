@@ -0,0 +1,373 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmTypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/bishopfox/awsservicemap"
+)
+
+// SecretDetail is one secret or parameter found by DescribeName, with its metadata as key/value pairs in the order
+// they are shown
+type SecretDetail struct {
+	Secret Secret
+	Fields []SecretDetailField
+}
+
+type SecretDetailField struct {
+	Key   string
+	Value string
+}
+
+func (d *SecretDetail) add(key string, value string) {
+	if value == "" {
+		return
+	}
+	d.Fields = append(d.Fields, SecretDetailField{Key: key, Value: value})
+}
+
+// describeTarget is what DescribeName points to. An ARN names the service and the region, a plain name is looked up
+// in both services.
+type describeTarget struct {
+	name           string
+	region         string
+	secretsManager bool
+	ssm            bool
+}
+
+func parseDescribeTarget(nameOrArn string) describeTarget {
+	parts := strings.SplitN(nameOrArn, ":", 6)
+	if len(parts) == 6 && parts[0] == "arn" {
+		switch {
+		case parts[2] == "secretsmanager":
+			// Secrets Manager takes the full ARN as secret id
+			return describeTarget{name: nameOrArn, region: parts[3], secretsManager: true}
+		case parts[2] == "ssm" && strings.HasPrefix(parts[5], "parameter/"):
+			// The ARN of /app/db is parameter/app/db, the ARN of db is parameter/db. Parameters in a path are far more
+			// common, so a name with a slash gets its leading slash back.
+			name := strings.TrimPrefix(parts[5], "parameter")
+			if strings.Count(name, "/") == 1 {
+				name = strings.TrimPrefix(name, "/")
+			}
+			return describeTarget{name: name, region: parts[3], ssm: true}
+		}
+	}
+	return describeTarget{name: nameOrArn, secretsManager: true, ssm: true}
+}
+
+// describeSecret is the single-secret mode of the module. It looks up DescribeName with DescribeSecret and with a Name
+// filter on DescribeParameters instead of listing everything, and prints every match as a vertical key/value table.
+// Both services are searched for plain names, so a name used in both shows up twice.
+func (m *SecretsModule) describeSecret(outputDirectory string, verbosity int) {
+	target := parseDescribeTarget(m.DescribeName)
+	regions := m.AWSRegions
+	if target.region != "" {
+		regions = []string{target.region}
+	}
+	fmt.Printf("[%s][%s] Describing %s in %d regions.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), target.name, len(regions))
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+	var details []SecretDetail
+	var detailsMu sync.Mutex
+	lookup := func(r string, describe func(r string, name string) (SecretDetail, bool)) {
+		defer wg.Done()
+		semaphore <- struct{}{}
+		defer func() {
+			<-semaphore
+		}()
+		if detail, found := describe(r, target.name); found {
+			detailsMu.Lock()
+			details = append(details, detail)
+			detailsMu.Unlock()
+		}
+	}
+	for _, r := range regions {
+		if target.secretsManager {
+			if res, err := servicemap.IsServiceInRegion("secretsmanager", r); err != nil {
+				m.modLog.Error(err)
+			} else if res {
+				wg.Add(1)
+				go lookup(r, m.describeSecretsManagerSecret)
+			}
+		}
+		if target.ssm {
+			if res, err := servicemap.IsServiceInRegion("ssm", r); err != nil {
+				m.modLog.Error(err)
+			} else if res {
+				wg.Add(1)
+				go lookup(r, m.describeSSMParameter)
+			}
+		}
+	}
+	wg.Wait()
+
+	sort.Slice(details, func(i, j int) bool {
+		if details[i].Secret.AWSService != details[j].Secret.AWSService {
+			return details[i].Secret.AWSService < details[j].Secret.AWSService
+		}
+		return details[i].Secret.Region < details[j].Secret.Region
+	})
+
+	var body [][]string
+	for _, detail := range details {
+		m.Secrets = append(m.Secrets, detail.Secret)
+		for _, field := range detail.Fields {
+			body = append(body, []string{field.Key, field.Value})
+		}
+	}
+
+	directory := filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+	if len(body) > 0 {
+		m.output.FilePath = directory
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header: []string{"Field", "Value"},
+			Body:   body,
+			Name:   "secret-details",
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = directory
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = directory
+		// A loot file of its own, so a describe doesn't overwrite the pull-secrets-commands of the last full scan
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     "describe-secret-commands",
+			Contents: newLootWriter(m.LootTemplateDir, m.modLog).Render("secrets", m.Secrets),
+		})
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d matches for %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(details), target.name)
+		if len(details) > 1 && target.secretsManager && target.ssm {
+			var services []string
+			for _, detail := range details {
+				services = append(services, fmt.Sprintf("%s in %s", detail.Secret.AWSService, detail.Secret.Region))
+			}
+			fmt.Printf("[%s][%s] The name is ambiguous, showing all of: %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strings.Join(services, ", "))
+		}
+	} else {
+		fmt.Printf("[%s][%s] %s not found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), target.name)
+	}
+	if len(m.ErrorSummary) > 0 {
+		m.printErrorSummary(directory)
+	}
+}
+
+// describeSecretsManagerSecret returns the metadata of a secret with its rotation, version stages, tags and resource
+// policy. The value is never read.
+func (m *SecretsModule) describeSecretsManagerSecret(r string, name string) (SecretDetail, bool) {
+	DescribeSecret, err := m.SecretsManagerClient.DescribeSecret(
+		context.TODO(),
+		&secretsmanager.DescribeSecretInput{
+			SecretId: aws.String(name),
+		},
+		func(o *secretsmanager.Options) {
+			o.Region = r
+		},
+	)
+	if err != nil {
+		if !isSecretNotFound(err) {
+			m.recordError(r, "secretsmanager:DescribeSecret", err)
+		}
+		return SecretDetail{}, false
+	}
+
+	detail := SecretDetail{
+		Secret: Secret{
+			AWSService:   "SecretsManager",
+			Region:       r,
+			Name:         aws.ToString(DescribeSecret.Name),
+			Arn:          aws.ToString(DescribeSecret.ARN),
+			Description:  aws.ToString(DescribeSecret.Description),
+			LastModified: secretLastModified(DescribeSecret.LastChangedDate, DescribeSecret.CreatedDate),
+		},
+	}
+	if DescribeSecret.DeletedDate != nil {
+		detail.Secret.Status = "Scheduled for deletion"
+	}
+	detail.add("Service", detail.Secret.AWSService)
+	detail.add("Region", r)
+	detail.add("Name", detail.Secret.Name)
+	detail.add("ARN", detail.Secret.Arn)
+	detail.add("Description", detail.Secret.Description)
+	detail.add("Status", detail.Secret.Status)
+	kmsKey := aws.ToString(DescribeSecret.KmsKeyId)
+	if kmsKey == "" {
+		kmsKey = "aws/secretsmanager (AWS managed)"
+	}
+	detail.add("KMS Key", kmsKey)
+	detail.add("Created", formatDetailTime(DescribeSecret.CreatedDate))
+	detail.add("Last Changed", formatDetailTime(DescribeSecret.LastChangedDate))
+	detail.add("Last Accessed", formatDetailTime(DescribeSecret.LastAccessedDate))
+	detail.add("Deleted", formatDetailTime(DescribeSecret.DeletedDate))
+
+	detail.add("Rotation Enabled", fmt.Sprint(aws.ToBool(DescribeSecret.RotationEnabled)))
+	detail.add("Rotation Lambda", aws.ToString(DescribeSecret.RotationLambdaARN))
+	if rules := DescribeSecret.RotationRules; rules != nil {
+		switch {
+		case rules.ScheduleExpression != nil:
+			detail.add("Rotation Schedule", aws.ToString(rules.ScheduleExpression))
+		case rules.AutomaticallyAfterDays != nil:
+			detail.add("Rotation Schedule", fmt.Sprintf("every %d days", aws.ToInt64(rules.AutomaticallyAfterDays)))
+		}
+	}
+	detail.add("Last Rotated", formatDetailTime(DescribeSecret.LastRotatedDate))
+	detail.add("Next Rotation", formatDetailTime(DescribeSecret.NextRotationDate))
+
+	var versions []string
+	for versionID, stages := range DescribeSecret.VersionIdsToStages {
+		versions = append(versions, fmt.Sprintf("%s: %s", versionID, strings.Join(stages, ", ")))
+	}
+	sort.Strings(versions)
+	detail.add("Version Stages", strings.Join(versions, "\n"))
+
+	var tags []string
+	for _, tag := range DescribeSecret.Tags {
+		tags = append(tags, fmt.Sprintf("%s=%s", aws.ToString(tag.Key), aws.ToString(tag.Value)))
+	}
+	sort.Strings(tags)
+	detail.add("Tags", strings.Join(tags, "\n"))
+
+	detail.add("Primary Region", aws.ToString(DescribeSecret.PrimaryRegion))
+	var replicas []string
+	for _, replica := range DescribeSecret.ReplicationStatus {
+		replicas = append(replicas, fmt.Sprintf("%s (%s)", aws.ToString(replica.Region), replica.Status))
+	}
+	detail.add("Replicas", strings.Join(replicas, "\n"))
+
+	GetResourcePolicy, err := m.SecretsManagerClient.GetResourcePolicy(
+		context.TODO(),
+		&secretsmanager.GetResourcePolicyInput{
+			SecretId: DescribeSecret.ARN,
+		},
+		func(o *secretsmanager.Options) {
+			o.Region = r
+		},
+	)
+	if err != nil {
+		m.recordError(r, "secretsmanager:GetResourcePolicy", err)
+		detail.add("Resource Policy", "Unknown, GetResourcePolicy failed")
+	} else if GetResourcePolicy.ResourcePolicy == nil {
+		detail.add("Resource Policy", "None")
+	} else {
+		detail.add("Resource Policy", aws.ToString(GetResourcePolicy.ResourcePolicy))
+	}
+	return detail, true
+}
+
+// describeSSMParameter returns the metadata of a parameter and who changed it in every version. DescribeParameters is
+// filtered by name on the server, so it doesn't list the whole region. Values of the history are never shown.
+func (m *SecretsModule) describeSSMParameter(r string, name string) (SecretDetail, bool) {
+	DescribeParameters, err := m.SSMClient.DescribeParameters(
+		context.TODO(),
+		&ssm.DescribeParametersInput{
+			ParameterFilters: []ssmTypes.ParameterStringFilter{
+				{
+					Key:    aws.String("Name"),
+					Option: aws.String("Equals"),
+					Values: []string{name},
+				},
+			},
+		},
+		func(o *ssm.Options) {
+			o.Region = r
+		},
+	)
+	if err != nil {
+		m.recordError(r, "ssm:DescribeParameters", err)
+		return SecretDetail{}, false
+	}
+	if len(DescribeParameters.Parameters) == 0 {
+		return SecretDetail{}, false
+	}
+
+	parameter := DescribeParameters.Parameters[0]
+	arn := fmt.Sprintf("arn:aws:ssm:%s:%s:parameter/%s", r, aws.ToString(m.Caller.Account), strings.TrimPrefix(aws.ToString(parameter.Name), "/"))
+	detail := SecretDetail{
+		Secret: Secret{
+			AWSService:   "SSM",
+			Region:       r,
+			Name:         aws.ToString(parameter.Name),
+			Arn:          arn,
+			Description:  aws.ToString(parameter.Description),
+			Type:         string(parameter.Type),
+			LastModified: aws.ToTime(parameter.LastModifiedDate),
+		},
+	}
+	detail.add("Service", detail.Secret.AWSService)
+	detail.add("Region", r)
+	detail.add("Name", detail.Secret.Name)
+	detail.add("ARN", arn)
+	detail.add("Description", detail.Secret.Description)
+	detail.add("Type", detail.Secret.Type)
+	detail.add("Tier", string(parameter.Tier))
+	detail.add("Data Type", aws.ToString(parameter.DataType))
+	detail.add("KMS Key", aws.ToString(parameter.KeyId))
+	detail.add("Allowed Pattern", aws.ToString(parameter.AllowedPattern))
+	detail.add("Version", fmt.Sprint(parameter.Version))
+	detail.add("Last Modified", formatDetailTime(parameter.LastModifiedDate))
+	detail.add("Last Modified By", aws.ToString(parameter.LastModifiedUser))
+	var policies []string
+	for _, parameterPolicy := range parameter.Policies {
+		policies = append(policies, aws.ToString(parameterPolicy.PolicyText))
+	}
+	detail.add("Policies", strings.Join(policies, "\n"))
+
+	var history []string
+	var PaginationControl *string
+	for {
+		GetParameterHistory, err := m.SSMClient.GetParameterHistory(
+			context.TODO(),
+			&ssm.GetParameterHistoryInput{
+				Name:      parameter.Name,
+				NextToken: PaginationControl,
+			},
+			func(o *ssm.Options) {
+				o.Region = r
+			},
+		)
+		if err != nil {
+			m.recordError(r, "ssm:GetParameterHistory", err)
+			break
+		}
+		for _, version := range GetParameterHistory.Parameters {
+			line := fmt.Sprintf("v%d %s by %s", version.Version, formatDetailTime(version.LastModifiedDate), aws.ToString(version.LastModifiedUser))
+			if len(version.Labels) > 0 {
+				line += fmt.Sprintf(" [%s]", strings.Join(version.Labels, ", "))
+			}
+			history = append(history, line)
+		}
+		if GetParameterHistory.NextToken == nil {
+			break
+		}
+		PaginationControl = GetParameterHistory.NextToken
+	}
+	detail.add("History", strings.Join(history, "\n"))
+	return detail, true
+}
+
+func formatDetailTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,166 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// nucleiSecretCheck is the signed API call that reads one kind of secret. Every secret of that kind goes into the same
+// template as a payload, so the template tells which of them the credentials it runs with can still read.
+type nucleiSecretCheck struct {
+	ID string
+	// Kind names the secrets in the template info, e.g. SSM SecureString parameters
+	Kind     string
+	Service  string
+	Severity string
+	Target   string
+	// Body is the JSON request body, {{region}} and {{secret}} come from the payloads
+	Body string
+	// Word is only in the response to a successful read, the extractor pulls the name of the secret from it
+	Word      string
+	Extractor string
+	Secrets   []Secret
+}
+
+// nucleiSecretCheckFor returns the check for the service and type of a secret, or false for the sources that have no
+// API to read a single secret, like App Runner environment variables
+func nucleiSecretCheckFor(secret Secret) (nucleiSecretCheck, bool) {
+	switch secret.AWSService {
+	case "SecretsManager":
+		return nucleiSecretCheck{
+			ID:        "cloudfox-secretsmanager-getsecretvalue",
+			Kind:      "Secrets Manager secrets",
+			Service:   "secretsmanager",
+			Severity:  "high",
+			Target:    "secretsmanager.GetSecretValue",
+			Body:      `{"SecretId": "{{secret}}"}`,
+			Word:      `"ARN"`,
+			Extractor: ".Name",
+		}, true
+	case "SSM":
+		parameterType := secret.Type
+		if parameterType == "" {
+			parameterType = "String"
+		}
+		check := nucleiSecretCheck{
+			ID:        fmt.Sprintf("cloudfox-ssm-getparameter-%s", strings.ToLower(parameterType)),
+			Kind:      fmt.Sprintf("SSM %s parameters", parameterType),
+			Service:   "ssm",
+			Severity:  "medium",
+			Target:    "AmazonSSM.GetParameter",
+			Body:      `{"Name": "{{secret}}"}`,
+			Word:      `"Parameter"`,
+			Extractor: ".Parameter.Name",
+		}
+		if parameterType == "SecureString" {
+			check.Severity = "high"
+			check.Body = `{"Name": "{{secret}}", "WithDecryption": true}`
+		}
+		return check, true
+	}
+	return nucleiSecretCheck{}, false
+}
+
+// nucleiSecretChecks groups the secrets by service and type, in the order of their ids
+func (m *SecretsModule) nucleiSecretChecks() []nucleiSecretCheck {
+	checks := make(map[string]nucleiSecretCheck)
+	for _, secret := range m.Secrets {
+		// These have to be restored before they can be read
+		if secret.Status == "Scheduled for deletion" {
+			continue
+		}
+		check, ok := nucleiSecretCheckFor(secret)
+		if !ok {
+			continue
+		}
+		if existing, found := checks[check.ID]; found {
+			check = existing
+		}
+		check.Secrets = append(check.Secrets, secret)
+		checks[check.ID] = check
+	}
+
+	var ids []string
+	for id := range checks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var sorted []nucleiSecretCheck
+	for _, id := range ids {
+		sorted = append(sorted, checks[id])
+	}
+	return sorted
+}
+
+// writeNucleiTemplates returns one loot file per check. The requests are signed with Nuclei's AWS signature, which
+// takes the credentials from the aws-id and aws-secret variables.
+func (m *SecretsModule) writeNucleiTemplates() []internal.LootFile {
+	var lootFiles []internal.LootFile
+	for _, check := range m.nucleiSecretChecks() {
+		var out string
+		out = out + fmt.Sprintf("# Checks which of the %d secrets below the given credentials can read. Values are never extracted.\n", len(check.Secrets))
+		out = out + fmt.Sprintf("# Run with: nuclei -t %s.yaml -var aws-id=$AWS_ACCESS_KEY_ID -var aws-secret=$AWS_SECRET_ACCESS_KEY\n", check.ID)
+		out = out + fmt.Sprintf("id: %s\n", check.ID)
+		out = out + fmt.Sprintln("")
+		out = out + fmt.Sprintln("info:")
+		out = out + fmt.Sprintf("  name: Read access to %s found by CloudFox\n", check.Kind)
+		out = out + fmt.Sprintln("  author: cloudfox")
+		out = out + fmt.Sprintf("  severity: %s\n", check.Severity)
+		out = out + fmt.Sprintf("  description: Calls %s for every secret in account %s that CloudFox found with profile %s.\n", check.Target, aws.ToString(m.Caller.Account), m.AWSProfile)
+		out = out + fmt.Sprintf("  tags: aws,cloud,cloudfox,%s\n", check.Service)
+		out = out + fmt.Sprintln("")
+		out = out + fmt.Sprintln("self-contained: true")
+		out = out + fmt.Sprintln("")
+		out = out + fmt.Sprintln("variables:")
+		out = out + fmt.Sprintf("  service: %s\n", check.Service)
+		out = out + fmt.Sprintln("")
+		out = out + fmt.Sprintln("http:")
+		out = out + fmt.Sprintln("  - raw:")
+		out = out + fmt.Sprintln("      - |")
+		out = out + fmt.Sprintln("        POST / HTTP/1.1")
+		out = out + fmt.Sprintf("        Host: %s.{{region}}.amazonaws.com\n", check.Service)
+		out = out + fmt.Sprintln("        Content-Type: application/x-amz-json-1.1")
+		out = out + fmt.Sprintf("        X-Amz-Target: %s\n", check.Target)
+		out = out + fmt.Sprintln("")
+		out = out + fmt.Sprintf("        %s\n", check.Body)
+		out = out + fmt.Sprintln("")
+		out = out + fmt.Sprintln("    signature: AWS")
+		out = out + fmt.Sprintln("    attack: pitchfork")
+		out = out + fmt.Sprintln("    payloads:")
+		out = out + fmt.Sprintln("      region:")
+		for _, secret := range check.Secrets {
+			out = out + fmt.Sprintf("        - %q\n", secret.Region)
+		}
+		out = out + fmt.Sprintln("      secret:")
+		for _, secret := range check.Secrets {
+			out = out + fmt.Sprintf("        - %q\n", secret.Name)
+		}
+		out = out + fmt.Sprintln("")
+		out = out + fmt.Sprintln("    matchers-condition: and")
+		out = out + fmt.Sprintln("    matchers:")
+		out = out + fmt.Sprintln("      - type: status")
+		out = out + fmt.Sprintln("        status:")
+		out = out + fmt.Sprintln("          - 200")
+		out = out + fmt.Sprintln("      - type: word")
+		out = out + fmt.Sprintln("        part: body")
+		out = out + fmt.Sprintln("        words:")
+		out = out + fmt.Sprintf("          - '%s'\n", check.Word)
+		out = out + fmt.Sprintln("")
+		out = out + fmt.Sprintln("    extractors:")
+		out = out + fmt.Sprintln("      - type: json")
+		out = out + fmt.Sprintln("        part: body")
+		out = out + fmt.Sprintln("        json:")
+		out = out + fmt.Sprintf("          - '%s'\n", check.Extractor)
+
+		lootFiles = append(lootFiles, internal.LootFile{
+			Name:      check.ID,
+			Contents:  out,
+			Extension: "yaml",
+		})
+	}
+	return lootFiles
+}
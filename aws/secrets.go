@@ -2,26 +2,47 @@ package aws
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"os"
+	"net"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/BishopFox/cloudfox/aws/sdk"
 	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/onepassword"
+	"github.com/BishopFox/cloudfox/internal/secretvalidation"
+	"github.com/BishopFox/cloudfox/internal/slack"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	secretsmanagerTypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmTypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
 	"github.com/bishopfox/awsservicemap"
 	"github.com/sirupsen/logrus"
 )
 
 type SecretsModule struct {
 	// General configuration data
-	SecretsManagerClient *secretsmanager.Client
-	SSMClient            *ssm.Client
+	SecretsManagerClient sdk.SecretsManagerClientInterface
+	SSMClient            sdk.AWSSSMClientInterface
+	ConfigClient         sdk.AWSConfigServiceClientInterface
+	AppRunnerClient      sdk.AppRunnerClientInterface
+	IAMClient            sdk.AWSIAMClientInterface
+	// DynamoDBClient is set to look for credentials in the items of DynamoDB tables, see DynamoTables and DynamoRegex
+	DynamoDBClient sdk.DynamoDBScanClientInterface
 
 	Caller        sts.GetCallerIdentityOutput
 	AWSRegions    []string
@@ -30,24 +51,148 @@ type SecretsModule struct {
 	WrapTable     bool
 	AWSOutputType string
 	AWSTableCols  string
+	AnsibleLoot   bool
+	TerraformLoot bool
+	// NucleiTemplates writes a Nuclei template per service and secret type that checks which secrets can be read
+	NucleiTemplates bool
+	// ResolveValues decrypts SecureString parameters and shows their values. It only takes effect together with
+	// ConfirmShowValues, because the values end up in the table and in the output files.
+	ResolveValues     bool
+	ConfirmShowValues bool
+	// SecretNames are looked up directly with DescribeSecret and GetParameter in every region. When set, ListSecrets
+	// and DescribeParameters are not called at all, so the scan works without those permissions.
+	SecretNames []string
+	// DescribeName is a secret or parameter name or ARN to describe on its own instead of scanning. Only it is looked
+	// up, in Secrets Manager and SSM, and its metadata is shown as a key/value table.
+	DescribeName string
+	// Since keeps only the secrets created or changed after it. Neither ListSecrets nor DescribeParameters can filter
+	// by date, so this happens as the secrets come in.
+	Since time.Time
+	// AnalyzeAccess simulates the policies of every IAM user and role against every secret to find out who can read
+	// it. This takes two SimulatePrincipalPolicy calls per principal, so it is off by default.
+	AnalyzeAccess bool
+	// OnePasswordClient is set to compare the Secrets Manager secrets with the items of a 1Password Connect server by
+	// name. Items are only listed, their fields are never read.
+	OnePasswordClient onepassword.ConnectClientInterface
+	// LootTemplateDir may hold a secrets.tmpl that replaces the default pull-secrets-commands loot
+	LootTemplateDir string
+	// Validator is set to check if the secrets named after GitHub, Slack, Stripe or Twilio still hold working
+	// credentials. Their values are read for the check but never shown.
+	Validator secretvalidation.ValidatorInterface
+	// Checkpoint records every region and check as it completes. When it was loaded for --resume, the completed checks
+	// are skipped and their secrets are added to the new ones.
+	Checkpoint *internal.Checkpoint
+	// DynamoTables are the tables scanned for secrets in every region that has them. When empty, all tables are listed.
+	DynamoTables []string
+	// DynamoRegex replaces the built-in credential patterns for DynamoDB attribute values, which are noisy on tables
+	// that hold free text
+	DynamoRegex *regexp.Regexp
+	// SummarizePaths groups the SSM parameters by the first two components of their path into a summary table, and
+	// the loot fetches each group with one get-parameters-by-path call. The full flat list is still written to disk.
+	SummarizePaths bool
+	// Slack is set to post the top secrets by estimated severity to a Slack webhook when the scan is done. Names,
+	// regions and services are sent, values never are.
+	Slack slack.WebhookClientInterface
+	// SlackRealtime also posts each HIGH or CRITICAL secret as soon as it is found
+	SlackRealtime bool
+	// Stream prints every secret to stdout as a tab-separated row as soon as it is found. The status line then goes to
+	// stderr, one line per completed region, and the table and output files are written at the end as usual.
+	Stream bool
+	// PreferProd moves the secrets that ProdPattern matches to the top of the table and loot, above a separator row.
+	// SSM parameters don't come with their tags, so this takes a ListTagsForResource call per parameter.
+	PreferProd bool
+	// ProdPattern is matched against the name and every key=value tag of a secret, defaultSecretsProdPattern if nil
+	ProdPattern *regexp.Regexp
 
 	// Main module data
-	Secrets []Secret
+	Secrets      []Secret
+	ErrorSummary []ErrorEntry
+	// SecretStoreDrift are the secrets that are only in one of Secrets Manager and 1Password, or were changed in one
+	// long after the other
+	SecretStoreDrift []SecretStoreDrift
+	// SSMPathSummaries are the SSM parameters grouped by path prefix, only set with SummarizePaths
+	SSMPathSummaries []SSMPathSummary
 
 	CommandCounter internal.CommandCounter
 	// Used to store output data for pretty printing
 	output internal.OutputData2
 
 	modLog *logrus.Entry
+	mu     sync.Mutex
+	// regionErrors counts the errors per region, a check that saw new errors in its region is not checkpointed
+	regionErrors map[string]int
+	// serviceCounts and regionsCompleted make up the running tally of the status line, only the Receiver touches them
+	serviceCounts    map[string]int
+	regionsCompleted int
+	// slackFindings feeds the realtime Slack worker, so a slow webhook doesn't hold up the Receiver
+	slackFindings chan Secret
 }
 
 type Secret struct {
 	AWSService  string
 	Region      string
 	Name        string
+	Arn         string
 	Description string
+	Status      string
+	Type        string
+	Value       string
+	// LastModified is when the secret was last changed, or zero if the source doesn't tell
+	LastModified time.Time
+	// ReadablePrincipals are the IAM users and roles whose policies allow reading the value, only set with AnalyzeAccess
+	ReadablePrincipals []string
+	// Validation is ACTIVE or UNVERIFIED for secrets that look like third-party credentials, only set with a Validator
+	Validation string
+	// Tags are the tags of Secrets Manager secrets, and of SSM parameters with PreferProd
+	Tags map[string]string
+	// envValue is the full value of an AppRunner environment variable or DynamoDB attribute, kept for the Validator
+	envValue string
 }
 
+// SecretStoreDrift is a secret name whose Secrets Manager secret and 1Password item don't line up
+type SecretStoreDrift struct {
+	Name   string
+	Status string
+	// SecretsManagerRegions are the regions with a secret of this name
+	SecretsManagerRegions  []string
+	SecretsManagerModified time.Time
+	OnePasswordVault       string
+	OnePasswordModified    time.Time
+}
+
+// SSMPathSummary counts the SSM parameters of a region under a path prefix
+type SSMPathSummary struct {
+	Region string
+	// Prefix is the first two components of the parameter paths, e.g. /app/prod, or ssmNoPathPrefix
+	Prefix        string
+	Parameters    int
+	SecureStrings int
+	// Examples are the first few parameter names in alphabetical order
+	Examples []string
+}
+
+// ssmNoPathPrefix is the group of the parameters whose names don't start with a slash or sit directly under the root.
+// They are fetched one by one, a get-parameters-by-path on / would pull every parameter of the region.
+const ssmNoPathPrefix = "(no path)"
+
+// How many parameter names the summary table shows per prefix
+const ssmPathSummaryExamples = 3
+
+// Resolved values are cut down to this many characters so long certificates and JSON blobs don't break the table
+const secretValuePreviewLength = 80
+
+// ErrorEntry is an API call that failed during the scan. It tells operators whether resources were missed because of
+// missing permissions or because of transient failures.
+type ErrorEntry struct {
+	Region    string `json:"region"`
+	Service   string `json:"service"`
+	ErrorCode string `json:"errorCode"`
+	Message   string `json:"message"`
+}
+
+// AWS Config resource type used to find secrets that no longer show up in ListSecrets
+const configSecretResourceType = "AWS::SecretsManager::Secret"
+
 func (m *SecretsModule) PrintSecrets(outputDirectory string, verbosity int) {
 	// These struct values are used by the output module
 	m.output.Verbosity = verbosity
@@ -59,40 +204,108 @@ func (m *SecretsModule) PrintSecrets(outputDirectory string, verbosity int) {
 	if m.AWSProfile == "" {
 		m.AWSProfile = internal.BuildAWSPath(m.Caller)
 	}
+	if m.DescribeName != "" {
+		m.describeSecret(outputDirectory, verbosity)
+		return
+	}
 
 	fmt.Printf("[%s][%s] Enumerating secrets for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
-	fmt.Printf("[%s][%s] Supported Services: SecretsManager, SSM Parameters\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	fmt.Printf("[%s][%s] Supported Services: SecretsManager, SSM Parameters, AppRunner environment variables\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	if m.DynamoDBClient != nil {
+		fmt.Printf("[%s][%s] Also scanning the first %d items of DynamoDB tables for credentials, expect some noise.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), dynamoSecretsMaxItems)
+	}
+	if m.ResolveValues && !m.ConfirmShowValues {
+		fmt.Printf("[%s][%s] Not resolving SSM parameter values: add --confirm-show-values to print decrypted values to the screen and output files.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+		m.ResolveValues = false
+	}
+	if !m.Since.IsZero() {
+		fmt.Printf("[%s][%s] Only showing secrets changed after %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.Since.Format(time.RFC3339))
+	}
+	if len(m.SecretNames) > 0 {
+		fmt.Printf("[%s][%s] Looking up %d secret names in every region instead of listing secrets.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.SecretNames))
+	}
+
+	if dropped := m.Checkpoint.MatchFlags(m.checkpointFlags()); dropped > 0 {
+		fmt.Printf("[%s][%s] Resuming: running %d region checks again, they completed with other flags.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), dropped)
+	}
+	if m.Checkpoint.Resumed() > 0 {
+		previous, err := internal.CheckpointRows[Secret](m.Checkpoint)
+		if err != nil {
+			m.modLog.Error(err.Error())
+		}
+		m.Secrets = append(m.Secrets, previous...)
+		fmt.Printf("[%s][%s] Resuming: skipping %d completed region checks with %d secrets.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.Checkpoint.Resumed(), len(previous))
+	}
+
+	// The header goes out before the Receiver starts so the banner above can't end up between it and the rows
+	if m.Stream {
+		internal.PrintStreamRow("%s\n", strings.Join([]string{"Service", "Region", "Name", "Type", "Status"}, "\t"))
+		for _, secret := range m.Secrets {
+			internal.PrintStreamRow("%s\n", secretStreamRow(secret))
+		}
+	}
 
 	wg := new(sync.WaitGroup)
 	semaphore := make(chan struct{}, m.Goroutines)
 
-	// Create a channel to signal the spinner aka task status goroutine to finish
-	spinnerDone := make(chan bool)
-	//fire up the the task status spinner/updated
-	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "tasks")
-
 	//create a channel to receive the objects
 	dataReceiver := make(chan Secret)
+	// The Receiver keeps the status line instead of the spinner, it redraws the tally whenever a region completes
+	regionReceiver := make(chan string)
 
 	// Create a channel to signal to stop
 	receiverDone := make(chan bool)
 
-	go m.Receiver(dataReceiver, receiverDone)
+	var slackDone chan struct{}
+	if m.SlackRealtime && m.Slack != nil {
+		m.slackFindings = make(chan Secret, slackFindingsBuffer)
+		slackDone = make(chan struct{})
+		go m.slackWorker(slackDone)
+	}
+
+	go m.Receiver(dataReceiver, regionReceiver, receiverDone)
 
 	for _, region := range m.AWSRegions {
 		wg.Add(1)
 		m.CommandCounter.Pending++
-		go m.executeChecks(region, wg, semaphore, dataReceiver)
+		go m.executeChecks(region, wg, semaphore, dataReceiver, regionReceiver)
 	}
 
 	wg.Wait()
-	//time.Sleep(time.Second * 2)
 
-	// Send a message to the spinner goroutine to close the channel and stop
-	spinnerDone <- true
-	<-spinnerDone
 	receiverDone <- true
 	<-receiverDone
+	if m.slackFindings != nil {
+		// Let the worker post what is still queued before the summary goes out
+		close(m.slackFindings)
+		<-slackDone
+		m.slackFindings = nil
+	}
+
+	if m.AnalyzeAccess {
+		m.analyzeAccess()
+	}
+	if m.OnePasswordClient != nil {
+		m.compareOnePassword()
+	}
+	if m.Validator != nil {
+		m.validateSecrets()
+	}
+	if m.SummarizePaths {
+		m.SSMPathSummaries = summarizeSSMPaths(m.Secrets)
+	}
+	if m.Slack != nil {
+		m.postSlackSummary()
+	}
+	var prodSecrets int
+	if m.PreferProd {
+		prodSecrets = m.sortProductionFirst()
+	}
+
+	slowest, fastest := m.CommandCounter.SlowestAndFastest()
+	if slowest != "" {
+		m.modLog.Infof("Slowest region: %s (%s), fastest region: %s (%s)", slowest, m.CommandCounter.Duration[slowest], fastest, m.CommandCounter.Duration[fastest])
+	}
 
 	//	fmt.Printf("\nAnalyzed Resources by Region\n\n")
 
@@ -102,6 +315,19 @@ func (m *SecretsModule) PrintSecrets(outputDirectory string, verbosity int) {
 		"Region",
 		"Name",
 		"Description",
+		"Status",
+	}
+	if !m.Since.IsZero() {
+		m.output.Headers = append(m.output.Headers, "LastModified")
+	}
+	if m.ResolveValues {
+		m.output.Headers = append(m.output.Headers, "Type", "Value")
+	}
+	if m.AnalyzeAccess {
+		m.output.Headers = append(m.output.Headers, "Readable By")
+	}
+	if m.Validator != nil {
+		m.output.Headers = append(m.output.Headers, "Validation")
 	}
 
 	// If the user specified table columns, use those.
@@ -122,6 +348,19 @@ func (m *SecretsModule) PrintSecrets(outputDirectory string, verbosity int) {
 			"Region",
 			"Name",
 			"Description",
+			"Status",
+		}
+		if !m.Since.IsZero() {
+			tableCols = append(tableCols, "LastModified")
+		}
+		if m.ResolveValues {
+			tableCols = append(tableCols, "Type", "Value")
+		}
+		if m.AnalyzeAccess {
+			tableCols = append(tableCols, "Readable By")
+		}
+		if m.Validator != nil {
+			tableCols = append(tableCols, "Validation")
 		}
 		// Otherwise, use the default columns.
 	} else {
@@ -130,24 +369,53 @@ func (m *SecretsModule) PrintSecrets(outputDirectory string, verbosity int) {
 			"Region",
 			"Name",
 			"Description",
+			"Status",
+		}
+		if !m.Since.IsZero() {
+			tableCols = append(tableCols, "LastModified")
+		}
+		if m.ResolveValues {
+			tableCols = append(tableCols, "Value")
+		}
+		if m.AnalyzeAccess {
+			tableCols = append(tableCols, "Readable By")
+		}
+		if m.Validator != nil {
+			tableCols = append(tableCols, "Validation")
 		}
 	}
 
 	// Table rows
 	for i := range m.Secrets {
-		m.output.Body = append(
-			m.output.Body,
-			[]string{
-				aws.ToString(m.Caller.Account),
-				m.Secrets[i].AWSService,
-				m.Secrets[i].Region,
-				m.Secrets[i].Name,
-				m.Secrets[i].Description,
-			},
-		)
+		row := []string{
+			aws.ToString(m.Caller.Account),
+			m.Secrets[i].AWSService,
+			m.Secrets[i].Region,
+			m.Secrets[i].Name,
+			m.Secrets[i].Description,
+			m.Secrets[i].Status,
+		}
+		if !m.Since.IsZero() {
+			row = append(row, m.Secrets[i].LastModified.Format(time.RFC3339))
+		}
+		if m.ResolveValues {
+			row = append(row, m.Secrets[i].Type, m.Secrets[i].Value)
+		}
+		if m.AnalyzeAccess {
+			row = append(row, strings.Join(m.Secrets[i].ReadablePrincipals, "\n"))
+		}
+		if m.Validator != nil {
+			row = append(row, m.Secrets[i].Validation)
+		}
+		m.output.Body = append(m.output.Body, row)
 
 	}
 	if len(m.output.Body) > 0 {
+		// Only the screen and txt tables get the separator, so the csv and json files keep one row per secret
+		var separatorAfter int
+		if prodSecrets > 0 && prodSecrets < len(m.output.Body) {
+			separatorAfter = prodSecrets
+		}
 
 		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
 
@@ -163,206 +431,1171 @@ func (m *SecretsModule) PrintSecrets(outputDirectory string, verbosity int) {
 			Body:      m.output.Body,
 			TableCols: tableCols,
 			Name:      m.output.CallingModule,
+			// With a summary, the flat list of every parameter only goes to the output files
+			SkipPrintToScreen: m.SummarizePaths && len(m.SSMPathSummaries) > 0,
+			SeparatorAfter:    separatorAfter,
 		})
+		if m.SummarizePaths && len(m.SSMPathSummaries) > 0 {
+			o.Table.TableFiles = append(o.Table.TableFiles, m.ssmPathSummaryTable())
+		}
 		o.PrefixIdentifier = m.AWSProfile
 		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
-		o.WriteFullOutput(o.Table.TableFiles, nil)
-		m.writeLoot(o.Table.DirectoryName, verbosity)
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     "pull-secrets-commands",
+			Contents: m.writeLoot(),
+		})
+		if m.AnsibleLoot {
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:      "retrieve-secrets",
+				Contents:  m.writeAnsibleLoot(),
+				Extension: "yml",
+			})
+		}
+		if m.TerraformLoot {
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:      "secrets-data",
+				Contents:  m.writeTerraformLoot(),
+				Extension: "tf",
+			})
+		}
+		if m.NucleiTemplates {
+			o.Loot.LootFiles = append(o.Loot.LootFiles, m.writeNucleiTemplates()...)
+		}
+		if len(m.SecretStoreDrift) > 0 {
+			o.Table.TableFiles = append(o.Table.TableFiles, m.secretStoreDriftTable())
+		}
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		if m.AWSOutputType == "sarif" {
+			m.writeSarifFile(o.Table.DirectoryName)
+		}
 		fmt.Printf("[%s][%s] %s secrets found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+		if m.PreferProd {
+			fmt.Printf("[%s][%s] %d of them look like production secrets and are listed first.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), prodSecrets)
+		}
+		if m.SummarizePaths {
+			fmt.Printf("[%s][%s] SSM parameters grouped under %d path prefixes.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.SSMPathSummaries))
+		}
+		if m.OnePasswordClient != nil {
+			fmt.Printf("[%s][%s] %d secrets differ between Secrets Manager and 1Password.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.SecretStoreDrift))
+		}
 
 	} else {
 		fmt.Printf("[%s][%s] No secrets found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
 	}
+	if len(m.ErrorSummary) > 0 {
+		m.printErrorSummary(filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account))))
+	}
 	fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
 }
 
-func (m *SecretsModule) Receiver(receiver chan Secret, receiverDone chan bool) {
+// recordError logs the error and keeps it for the error summary that is shown at the end of the scan
+func (m *SecretsModule) recordError(r string, service string, err error) {
+	m.modLog.Error(err.Error())
+	m.CommandCounter.Error++
+
+	entry := ErrorEntry{
+		Region:    r,
+		Service:   service,
+		ErrorCode: "Unknown",
+		Message:   err.Error(),
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		entry.ErrorCode = apiErr.ErrorCode()
+		entry.Message = apiErr.ErrorMessage()
+	}
+	m.mu.Lock()
+	m.ErrorSummary = append(m.ErrorSummary, entry)
+	if m.regionErrors == nil {
+		m.regionErrors = make(map[string]int)
+	}
+	m.regionErrors[r]++
+	m.mu.Unlock()
+}
+
+func (m *SecretsModule) errorsInRegion(r string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.regionErrors[r]
+}
+
+// printErrorSummary shows which regions and APIs failed and writes the same list to cloudfox-errors.json
+func (m *SecretsModule) printErrorSummary(directory string) {
+	sort.Slice(m.ErrorSummary, func(i, j int) bool {
+		if m.ErrorSummary[i].Region != m.ErrorSummary[j].Region {
+			return m.ErrorSummary[i].Region < m.ErrorSummary[j].Region
+		}
+		return m.ErrorSummary[i].Service < m.ErrorSummary[j].Service
+	})
+
+	if internal.ShowErrors(m.output.Verbosity) {
+		fmt.Printf("[%s][%s] %d API calls returned errors, some secrets may be missing:\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.ErrorSummary))
+		var body [][]string
+		for _, entry := range m.ErrorSummary {
+			body = append(body, []string{entry.Region, entry.Service, entry.ErrorCode, entry.Message})
+		}
+		internal.PrintTableToScreen([]string{"Region", "Service", "Error Code", "Message"}, body, m.WrapTable)
+	} else {
+		fmt.Printf("[%s][%s] %d API calls returned errors, some secrets may be missing.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.ErrorSummary))
+	}
+
+	errorFile, err := internal.WriteJSONFile(directory, "cloudfox-errors.json", m.ErrorSummary)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		return
+	}
+	fmt.Printf("[%s][%s] Error summary written to %s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), errorFile)
+}
+
+func (m *SecretsModule) Receiver(receiver chan Secret, regionReceiver chan string, receiverDone chan bool) {
 	defer close(receiverDone)
+	// Secrets carried over from a resumed run are part of the tally from the start
+	m.serviceCounts = make(map[string]int)
+	for _, secret := range m.Secrets {
+		m.serviceCounts[secret.AWSService]++
+	}
+	if !m.Stream {
+		internal.PrintWhileSpinning("%s", m.tallyLine())
+	}
 	for {
 		select {
 		case data := <-receiver:
+			if !m.changedSince(data.LastModified) {
+				continue
+			}
 			m.Secrets = append(m.Secrets, data)
+			m.serviceCounts[data.AWSService]++
+			if m.Stream {
+				internal.PrintStreamRow("%s\n", secretStreamRow(data))
+			} else {
+				// Stream each secret as it is found so operators can follow along on long runs
+				if m.output.Verbosity >= internal.VerbosityAll {
+					internal.PrintWhileSpinning("[%s][%s] Found %s secret in %s: %s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), data.AWSService, data.Region, data.Name)
+				}
+				internal.PrintWhileSpinning("%s", m.tallyLine())
+			}
+			if m.slackFindings != nil {
+				if score, _ := secretSeverity(data); score >= secretSeverityHigh {
+					m.slackFindings <- data
+				}
+			}
+		case <-regionReceiver:
+			m.regionsCompleted++
+			if m.Stream {
+				internal.PrintStatusToStderr("%s", m.tallyLine())
+			} else {
+				internal.PrintWhileSpinning("%s", m.tallyLine())
+			}
 		case <-receiverDone:
+			if !m.Stream {
+				internal.PrintWhileSpinning("%s\n", m.tallyLine())
+			}
 			receiverDone <- true
 			return
 		}
 	}
 }
 
-func (m *SecretsModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Secret) {
-	defer wg.Done()
+// secretStreamColors tell the services apart in the streamed rows
+var secretStreamColors = map[string]func(a ...interface{}) string{
+	"SecretsManager": green,
+	"SSM":            blue,
+	"AppRunner":      magenta,
+	"DynamoDB":       cyan,
+}
 
-	servicemap := &awsservicemap.AwsServiceMap{
-		JsonFileSource: "DOWNLOAD_FROM_AWS",
-	}
-	res, err := servicemap.IsServiceInRegion("secretsmanager", r)
-	if err != nil {
-		m.modLog.Error(err)
+// secretStreamRow is the tab-separated row printed for a secret with --stream, with the service in its color
+func secretStreamRow(secret Secret) string {
+	service := secret.AWSService
+	if color, ok := secretStreamColors[service]; ok {
+		service = color(service)
 	}
-	if res {
-		m.CommandCounter.Total++
-		wg.Add(1)
-		go m.getSecretsManagerSecretsPerRegion(r, wg, semaphore, dataReceiver)
-	}
-	res, err = servicemap.IsServiceInRegion("ssm", r)
-	if err != nil {
-		m.modLog.Error(err)
+	return strings.Join([]string{service, secret.Region, secret.Name, secret.Type, secret.Status}, "\t")
+}
+
+// tallyLine is the status line of the scan, e.g. [secrets] Completed 5/20 regions | SecretsManager: 42 | SSM: 387.
+// Every service the scan looks at is listed, even before it found anything.
+func (m *SecretsModule) tallyLine() string {
+	line := fmt.Sprintf("[%s] Completed %d/%d regions", cyan(m.output.CallingModule), m.regionsCompleted, len(m.AWSRegions))
+	services := []string{"SecretsManager", "SSM"}
+	if len(m.SecretNames) == 0 {
+		if m.AppRunnerClient != nil {
+			services = append(services, "AppRunner")
+		}
+		if m.DynamoDBClient != nil {
+			services = append(services, "DynamoDB")
+		}
 	}
-	if res {
-		m.CommandCounter.Total++
-		wg.Add(1)
-		go m.getSSMParametersPerRegion(r, wg, semaphore, dataReceiver)
+	for _, service := range services {
+		line += fmt.Sprintf(" | %s: %d", service, m.serviceCounts[service])
 	}
-
+	return line
 }
 
-func (m *SecretsModule) writeLoot(outputDirectory string, verbosity int) {
-	path := filepath.Join(outputDirectory, "loot")
-	err := os.MkdirAll(path, os.ModePerm)
-	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+// changedSince tells if a secret last changed at lastModified passes the --since filter. Secrets without a known
+// change date are left out, as nothing says they are recent.
+func (m *SecretsModule) changedSince(lastModified time.Time) bool {
+	if m.Since.IsZero() {
+		return true
 	}
-	pullFile := filepath.Join(path, "pull-secrets-commands.txt")
+	return lastModified.After(m.Since)
+}
 
-	var out string
-	out = out + fmt.Sprintln("#############################################")
-	out = out + fmt.Sprintln("# The profile you will use to perform these commands is most likely not the profile you used to run CloudFox")
-	out = out + fmt.Sprintln("# Set the $profile environment variable to the profile you are going to use to pull the secrets/parameters.")
-	out = out + fmt.Sprintln("# E.g., export profile=dev-prod.")
-	out = out + fmt.Sprintln("#############################################")
-	out = out + fmt.Sprintln("")
+// secretReadActions is the action that reads the value of a secret, per service. AppRunner environment variables are
+// readable by anyone who can describe the service, so they are left out.
+var secretReadActions = map[string]string{
+	"SecretsManager": "secretsmanager:GetSecretValue",
+	"SSM":            "ssm:GetParameter",
+}
 
+// analyzeAccess fills in ReadablePrincipals. Every principal is simulated once per action with the ARNs of all
+// secrets of that service, and the results are matched back to the secrets by resource name. Resource policies on
+// the secrets and SCPs are not part of the simulation.
+func (m *SecretsModule) analyzeAccess() {
+	secretArnsByAction := make(map[string][]string)
 	for _, secret := range m.Secrets {
-		if secret.AWSService == "SecretsManager" {
-			out = out + fmt.Sprintf("aws --profile $profile --region %s secretsmanager get-secret-value --secret-id %s\n", secret.Region, secret.Name)
-		}
-		if secret.AWSService == "SSM" {
-			out = out + fmt.Sprintf("aws --profile $profile --region %s ssm get-parameter --with-decryption --name %s\n", secret.Region, secret.Name)
+		action, ok := secretReadActions[secret.AWSService]
+		if !ok || secret.Arn == "" {
+			continue
 		}
+		secretArnsByAction[action] = append(secretArnsByAction[action], secret.Arn)
 	}
-	err = os.WriteFile(pullFile, []byte(out), 0644)
-	if err != nil {
-		m.modLog.Error(err.Error())
-		m.CommandCounter.Error++
+	if len(secretArnsByAction) == 0 {
+		return
 	}
 
-	if verbosity > 2 {
-		fmt.Println()
-		fmt.Printf("[%s][%s] %s \n\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("Use the commands below to retrieve the secrets that look interesting"))
-
-		fmt.Print(out)
-		fmt.Printf("[%s][%s] %s \n\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("End of loot file."))
+	var principals []string
+	users, err := sdk.CachedIamListUsers(m.IAMClient, aws.ToString(m.Caller.Account))
+	if err != nil {
+		m.recordError("Global", "iam:ListUsers", err)
+	}
+	for _, user := range users {
+		principals = append(principals, aws.ToString(user.Arn))
+	}
+	roles, err := sdk.CachedIamListRoles(m.IAMClient, aws.ToString(m.Caller.Account))
+	if err != nil {
+		m.recordError("Global", "iam:ListRoles", err)
+	}
+	for _, role := range roles {
+		principals = append(principals, aws.ToString(role.Arn))
 	}
+	fmt.Printf("[%s][%s] Simulating read access to %d secrets for %d principals.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.Secrets), len(principals))
 
-	fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), pullFile)
+	readablePrincipals := make(map[string][]string)
+	var readableMu sync.Mutex
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+	for _, principal := range principals {
+		for action, secretArns := range secretArnsByAction {
+			wg.Add(1)
+			go func(principal string, action string, secretArns []string) {
+				defer wg.Done()
+				semaphore <- struct{}{}
+				defer func() {
+					<-semaphore
+				}()
+				evaluationResults, err := sdk.CachedIamSimulatePrincipalPolicy(m.IAMClient, aws.ToString(m.Caller.Account), aws.String(principal), []string{action}, secretArns)
+				if err != nil {
+					m.recordError("Global", "iam:SimulatePrincipalPolicy", err)
+					return
+				}
+				readableMu.Lock()
+				defer readableMu.Unlock()
+				for _, result := range evaluationResults {
+					if result.EvalDecision == iamTypes.PolicyEvaluationDecisionTypeAllowed && aws.ToString(result.EvalActionName) == action {
+						resource := aws.ToString(result.EvalResourceName)
+						readablePrincipals[resource] = append(readablePrincipals[resource], principal)
+					}
+				}
+			}(principal, action, secretArns)
+		}
+	}
+	wg.Wait()
 
+	for i := range m.Secrets {
+		m.Secrets[i].ReadablePrincipals = readablePrincipals[m.Secrets[i].Arn]
+		sort.Strings(m.Secrets[i].ReadablePrincipals)
+	}
 }
 
-func (m *SecretsModule) getSecretsManagerSecretsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Secret) {
-	defer func() {
-		m.CommandCounter.Executing--
-		m.CommandCounter.Complete++
-		wg.Done()
+// validateSecrets fills in Validation for the secrets whose name points to a service with a validation call. The
+// checks run one after the other to stay well below the rate limits of the services.
+func (m *SecretsModule) validateSecrets() {
+	var checked, active int
+	for i := range m.Secrets {
+		service := secretvalidation.ServiceForSecretName(m.Secrets[i].Name)
+		if service == "" {
+			continue
+		}
+		checked++
+		value, ok := m.getSecretValue(m.Secrets[i])
+		if !ok {
+			m.Secrets[i].Validation = secretvalidation.StatusUnverified
+			continue
+		}
+		result := m.Validator.Validate(context.TODO(), service, value)
+		m.Secrets[i].Validation = result.Status
+		if result.Reason != "" {
+			m.modLog.Infof("%s credential in %s %s: %s", service, m.Secrets[i].Name, strings.ToLower(result.Status), result.Reason)
+		}
+		if result.Status == secretvalidation.StatusActive {
+			active++
+		}
+	}
+	fmt.Printf("[%s][%s] %d of %d secrets that look like GitHub, Slack, Stripe or Twilio credentials are %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), active, checked, secretvalidation.StatusActive)
+}
 
-	}()
-	semaphore <- struct{}{}
-	defer func() {
-		<-semaphore
-	}()
-	// m.CommandCounter.Total++
-	m.CommandCounter.Pending--
-	m.CommandCounter.Executing++
-	// "PaginationMarker" is a control variable used for output continuity, as AWS return the output in pages.
-	var PaginationControl *string
-	for {
-		ListSecrets, err := m.SecretsManagerClient.ListSecrets(
+// getSecretValue reads the full value of a secret for validation. Like resolved SSM values, it is never cached.
+func (m *SecretsModule) getSecretValue(secret Secret) (string, bool) {
+	switch secret.AWSService {
+	case "SecretsManager":
+		secretID := secret.Arn
+		if secretID == "" {
+			secretID = secret.Name
+		}
+		GetSecretValue, err := m.SecretsManagerClient.GetSecretValue(
 			context.TODO(),
-			&(secretsmanager.ListSecretsInput{
-				NextToken: PaginationControl,
-			}),
+			&secretsmanager.GetSecretValueInput{
+				SecretId: aws.String(secretID),
+			},
 			func(o *secretsmanager.Options) {
-				o.Region = r
+				o.Region = secret.Region
 			},
 		)
 		if err != nil {
-			m.modLog.Error(err.Error())
-			m.CommandCounter.Error++
-			break
+			m.recordError(secret.Region, "secretsmanager:GetSecretValue", err)
+			return "", false
 		}
+		return aws.ToString(GetSecretValue.SecretString), GetSecretValue.SecretString != nil
+	case "SSM":
+		value := m.getSSMParameterValue(secret.Region, secret.Name)
+		return value, value != ""
+	case "AppRunner", "DynamoDB":
+		return secret.envValue, secret.envValue != ""
+	}
+	return "", false
+}
 
-		for _, secret := range ListSecrets.SecretList {
-			name := aws.ToString(secret.Name)
-			var description string
-			if secret.Description != nil {
-				description = aws.ToString(secret.Description)
-			}
+// Secrets Manager and 1Password timestamps further apart than this mean a secret was rotated in one store only.
+// Syncing a rotation by hand usually happens the same day.
+const secretStoreDriftTolerance = 24 * time.Hour
 
-			dataReceiver <- Secret{
-				AWSService:  "SecretsManager",
-				Region:      r,
-				Name:        name,
-				Description: description,
-			}
+// compareOnePassword matches the Secrets Manager secrets with the 1Password items by name, ignoring case, and fills in
+// SecretStoreDrift
+func (m *SecretsModule) compareOnePassword() {
+	vaults, err := m.OnePasswordClient.ListVaults(context.TODO())
+	if err != nil {
+		m.recordError("Global", "1password:ListVaults", err)
+		return
+	}
+	items := make(map[string]onepassword.Item)
+	vaultNames := make(map[string]string)
+	for _, vault := range vaults {
+		vaultNames[vault.ID] = vault.Name
+		vaultItems, err := m.OnePasswordClient.ListItems(context.TODO(), vault.ID)
+		if err != nil {
+			m.recordError("Global", "1password:ListItems", err)
+			continue
+		}
+		for _, item := range vaultItems {
+			items[strings.ToLower(item.Title)] = item
+		}
+	}
+	fmt.Printf("[%s][%s] Comparing secrets with %d items in %d 1Password vaults.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(items), len(vaults))
 
+	secrets := make(map[string]*SecretStoreDrift)
+	for _, secret := range m.Secrets {
+		if secret.AWSService != "SecretsManager" {
+			continue
+		}
+		key := strings.ToLower(secret.Name)
+		drift, ok := secrets[key]
+		if !ok {
+			drift = &SecretStoreDrift{Name: secret.Name}
+			secrets[key] = drift
 		}
+		drift.SecretsManagerRegions = append(drift.SecretsManagerRegions, secret.Region)
+		if secret.LastModified.After(drift.SecretsManagerModified) {
+			drift.SecretsManagerModified = secret.LastModified
+		}
+	}
 
-		// The "NextToken" value is nil when there's no more data to return.
-		if ListSecrets.NextToken != nil {
-			PaginationControl = ListSecrets.NextToken
-		} else {
-			PaginationControl = nil
-			break
+	var drifts []SecretStoreDrift
+	for key, drift := range secrets {
+		item, ok := items[key]
+		if !ok {
+			drift.Status = "Only in Secrets Manager"
+			drifts = append(drifts, *drift)
+			continue
+		}
+		drift.OnePasswordVault = vaultNames[item.Vault.ID]
+		drift.OnePasswordModified = item.UpdatedAt
+		if drift.SecretsManagerModified.IsZero() || item.UpdatedAt.IsZero() {
+			continue
+		}
+		if item.UpdatedAt.Sub(drift.SecretsManagerModified) > secretStoreDriftTolerance {
+			drift.Status = "Newer in 1Password"
+			drifts = append(drifts, *drift)
+		} else if drift.SecretsManagerModified.Sub(item.UpdatedAt) > secretStoreDriftTolerance {
+			drift.Status = "Newer in Secrets Manager"
+			drifts = append(drifts, *drift)
 		}
 	}
+	for key, item := range items {
+		if _, ok := secrets[key]; !ok {
+			drifts = append(drifts, SecretStoreDrift{
+				Name:                item.Title,
+				Status:              "Only in 1Password",
+				OnePasswordVault:    vaultNames[item.Vault.ID],
+				OnePasswordModified: item.UpdatedAt,
+			})
+		}
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Status != drifts[j].Status {
+			return drifts[i].Status < drifts[j].Status
+		}
+		return drifts[i].Name < drifts[j].Name
+	})
+	m.SecretStoreDrift = drifts
 }
 
-func (m *SecretsModule) getSSMParametersPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Secret) {
-	defer func() {
-		m.CommandCounter.Executing--
-		m.CommandCounter.Complete++
-		wg.Done()
+func (m *SecretsModule) secretStoreDriftTable() internal.TableFile {
+	header := []string{
+		"Account",
+		"Name",
+		"Status",
+		"Secrets Manager Regions",
+		"Secrets Manager Modified",
+		"1Password Vault",
+		"1Password Modified",
+	}
+	var body [][]string
+	for _, drift := range m.SecretStoreDrift {
+		sort.Strings(drift.SecretsManagerRegions)
+		body = append(body, []string{
+			aws.ToString(m.Caller.Account),
+			drift.Name,
+			drift.Status,
+			strings.Join(drift.SecretsManagerRegions, ", "),
+			formatSecretStoreTime(drift.SecretsManagerModified),
+			drift.OnePasswordVault,
+			formatSecretStoreTime(drift.OnePasswordModified),
+		})
+	}
+	return internal.TableFile{
+		Header:    header,
+		Body:      body,
+		TableCols: header[1:],
+		Name:      "secrets-1password-drift",
+	}
+}
 
-	}()
-	semaphore <- struct{}{}
+func formatSecretStoreTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+func (m *SecretsModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Secret, regionReceiver chan string) {
+	defer wg.Done()
 	defer func() {
-		<-semaphore
+		regionReceiver <- r
 	}()
-	// m.CommandCounter.Total++
-	m.CommandCounter.Pending--
-	m.CommandCounter.Executing++
-	// "PaginationMarker" is a control variable used for output continuity, as AWS return the output in pages.
-	var PaginationControl *string
-
-	for {
-		DescribeParameters, err := m.SSMClient.DescribeParameters(
-			context.TODO(),
-			&(ssm.DescribeParametersInput{
-				NextToken: PaginationControl,
-			}),
-			func(o *ssm.Options) {
-				o.Region = r
-			},
-		)
-		if err != nil {
-			m.modLog.Error(err.Error())
-			m.CommandCounter.Error++
-			break
-		}
+	// Track how long each region takes so we can tell where API throttling is worst
+	start := time.Now()
+	regionWg := new(sync.WaitGroup)
 
-		for _, parameter := range DescribeParameters.Parameters {
-			var description string
-			name := aws.ToString(parameter.Name)
-			if parameter.Description != nil {
-				description = aws.ToString(parameter.Description)
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("secretsmanager", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.startCheck(r, "secretsmanager", regionWg, dataReceiver, func(wg *sync.WaitGroup, receiver chan Secret) {
+			if len(m.SecretNames) > 0 {
+				m.getNamedSecretsManagerSecretsPerRegion(r, wg, semaphore, receiver)
+			} else {
+				m.getSecretsManagerSecretsPerRegion(r, wg, semaphore, receiver)
 			}
-
-			dataReceiver <- Secret{
-				AWSService:  "SSM",
-				Region:      r,
-				Name:        name,
-				Description: description,
+		})
+	}
+	res, err = servicemap.IsServiceInRegion("ssm", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.startCheck(r, "ssm", regionWg, dataReceiver, func(wg *sync.WaitGroup, receiver chan Secret) {
+			if len(m.SecretNames) > 0 {
+				m.getNamedSSMParametersPerRegion(r, wg, semaphore, receiver)
+			} else {
+				m.getSSMParametersPerRegion(r, wg, semaphore, receiver)
 			}
+		})
+	}
+	// The other sources can't be narrowed down to a list of names, so a targeted scan leaves them out
+	if len(m.SecretNames) > 0 {
+		regionWg.Wait()
+		m.CommandCounter.RecordDuration(r, time.Since(start))
+		return
+	}
+	if m.ConfigClient != nil {
+		res, err = servicemap.IsServiceInRegion("config", r)
+		if err != nil {
+			m.modLog.Error(err)
+		}
+		if res {
+			m.startCheck(r, "config", regionWg, dataReceiver, func(wg *sync.WaitGroup, receiver chan Secret) {
+				m.getDeletedSecretsPerRegion(r, wg, semaphore, receiver)
+			})
+		}
+	}
+	// apprunner is not supported by the aws json so we call it in every region and skip the ones where it's unavailable
+	if m.AppRunnerClient != nil {
+		m.startCheck(r, "apprunner", regionWg, dataReceiver, func(wg *sync.WaitGroup, receiver chan Secret) {
+			m.getAppRunnerEnvSecretsPerRegion(r, wg, semaphore, receiver)
+		})
+	}
+	if m.DynamoDBClient != nil {
+		res, err = servicemap.IsServiceInRegion("dynamodb", r)
+		if err != nil {
+			m.modLog.Error(err)
+		}
+		if res {
+			m.startCheck(r, "dynamodb", regionWg, dataReceiver, func(wg *sync.WaitGroup, receiver chan Secret) {
+				m.getDynamoDBSecretsPerRegion(r, wg, semaphore, receiver)
+			})
+		}
+	}
+
+	regionWg.Wait()
+	m.CommandCounter.RecordDuration(r, time.Since(start))
+}
+
+// checkpointFlags fingerprints the flags that change what the checks find, so --resume only skips the checks of a run
+// that used the same ones
+func (m *SecretsModule) checkpointFlags() string {
+	var dynamoRegex string
+	if m.DynamoRegex != nil {
+		dynamoRegex = m.DynamoRegex.String()
+	}
+	flags := strings.Join([]string{
+		m.Since.Format(time.RFC3339),
+		strconv.FormatBool(m.ResolveValues && m.ConfirmShowValues),
+		strings.Join(m.DynamoTables, ","),
+		dynamoRegex,
+		strings.Join(m.SecretNames, ","),
+	}, "\n")
+	sum := sha256.Sum256([]byte(flags))
+	return hex.EncodeToString(sum[:8])
+}
+
+// startCheck runs one check of a region unless the run being resumed completed it. The secrets of the check are
+// collected on their way to the Receiver and checkpointed together once the check completed without errors, so a check
+// that was interrupted or failed starts over on --resume.
+func (m *SecretsModule) startCheck(r string, check string, regionWg *sync.WaitGroup, dataReceiver chan Secret, run func(wg *sync.WaitGroup, receiver chan Secret)) {
+	if m.Checkpoint.Completed(r, check) {
+		return
+	}
+	m.CommandCounter.Total++
+	regionWg.Add(1)
+	go func() {
+		defer regionWg.Done()
+		errorsBefore := m.errorsInRegion(r)
+
+		checkReceiver := make(chan Secret)
+		checkSecrets := make(chan []Secret)
+		go func() {
+			var secrets []Secret
+			for secret := range checkReceiver {
+				secrets = append(secrets, secret)
+				dataReceiver <- secret
+			}
+			checkSecrets <- secrets
+		}()
+		checkWg := new(sync.WaitGroup)
+		checkWg.Add(1)
+		run(checkWg, checkReceiver)
+		checkWg.Wait()
+		close(checkReceiver)
+		secrets := <-checkSecrets
+
+		if m.errorsInRegion(r) != errorsBefore {
+			return
+		}
+		if err := m.Checkpoint.Complete(r, check, secrets); err != nil {
+			m.modLog.Error(err.Error())
+		}
+	}()
+}
+
+// writeLoot renders secrets.tmpl with the secrets, from --loot-template-dir if it has one. With SummarizePaths, the
+// SSM parameters that have a path are left to one get-parameters-by-path command per prefix instead.
+func (m *SecretsModule) writeLoot() string {
+	if !m.SummarizePaths {
+		return newLootWriter(m.LootTemplateDir, m.modLog).Render("secrets", m.Secrets)
+	}
+	var secrets []Secret
+	for _, secret := range m.Secrets {
+		if secret.AWSService != "SSM" || ssmPathPrefix(secret.Name) == ssmNoPathPrefix {
+			secrets = append(secrets, secret)
+		}
+	}
+	out := newLootWriter(m.LootTemplateDir, m.modLog).Render("secrets", secrets)
+	for _, summary := range m.SSMPathSummaries {
+		if summary.Prefix == ssmNoPathPrefix {
+			continue
+		}
+		out += fmt.Sprintf("aws --profile $profile --region %s ssm get-parameters-by-path --recursive --with-decryption --path %s\n", summary.Region, summary.Prefix)
+	}
+	return out
+}
+
+// ssmPathPrefix returns the first two components of the path of an SSM parameter, without the parameter's own name,
+// so /app/prod/db/password and /app/prod/api-key both belong to /app/prod and /app/key belongs to /app. Top-level
+// parameters like /key have no path to group by.
+func ssmPathPrefix(name string) string {
+	if !strings.HasPrefix(name, "/") {
+		return ssmNoPathPrefix
+	}
+	components := strings.Split(strings.Trim(name, "/"), "/")
+	components = components[:len(components)-1]
+	if len(components) == 0 {
+		return ssmNoPathPrefix
+	}
+	if len(components) > 2 {
+		components = components[:2]
+	}
+	return "/" + strings.Join(components, "/")
+}
+
+// summarizeSSMPaths groups the SSM parameters by region and path prefix
+func summarizeSSMPaths(secrets []Secret) []SSMPathSummary {
+	var summaries []SSMPathSummary
+	index := make(map[string]int)
+	for _, secret := range secrets {
+		if secret.AWSService != "SSM" {
+			continue
+		}
+		prefix := ssmPathPrefix(secret.Name)
+		key := secret.Region + prefix
+		i, ok := index[key]
+		if !ok {
+			i = len(summaries)
+			index[key] = i
+			summaries = append(summaries, SSMPathSummary{Region: secret.Region, Prefix: prefix})
+		}
+		summaries[i].Parameters++
+		if secret.Type == string(ssmTypes.ParameterTypeSecureString) {
+			summaries[i].SecureStrings++
+		}
+		summaries[i].Examples = append(summaries[i].Examples, secret.Name)
+	}
+	for i := range summaries {
+		sort.Strings(summaries[i].Examples)
+		if len(summaries[i].Examples) > ssmPathSummaryExamples {
+			summaries[i].Examples = summaries[i].Examples[:ssmPathSummaryExamples]
+		}
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Region != summaries[j].Region {
+			return summaries[i].Region < summaries[j].Region
+		}
+		return summaries[i].Prefix < summaries[j].Prefix
+	})
+	return summaries
+}
+
+func (m *SecretsModule) ssmPathSummaryTable() internal.TableFile {
+	header := []string{
+		"Account",
+		"Region",
+		"Prefix",
+		"Parameters",
+		"SecureStrings",
+		"Examples",
+	}
+	var body [][]string
+	for _, summary := range m.SSMPathSummaries {
+		body = append(body, []string{
+			aws.ToString(m.Caller.Account),
+			summary.Region,
+			summary.Prefix,
+			strconv.Itoa(summary.Parameters),
+			strconv.Itoa(summary.SecureStrings),
+			strings.Join(summary.Examples, "\n"),
+		})
+	}
+	return internal.TableFile{
+		Header:    header,
+		Body:      body,
+		TableCols: header[1:],
+		Name:      fmt.Sprintf("%s-ssm-paths", m.output.CallingModule),
+	}
+}
+
+// SARIF rules for the secrets module. Results use the rule's level unless the secret's value was decrypted during the
+// scan, which makes them errors.
+var secretsSarifRules = []internal.SarifRule{
+	{
+		ID:                   "AWS001-exposed-secretsmanager-secret",
+		Name:                 "ExposedSecretsManagerSecret",
+		ShortDescription:     internal.SarifMessage{Text: "Secrets Manager secret can be enumerated by the scanning principal"},
+		HelpURI:              "https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#secrets",
+		DefaultConfiguration: internal.SarifRuleConfiguration{Level: internal.SarifLevelWarning},
+		Properties:           internal.SarifPropertyBag{"security-severity": "7.0", "tags": []string{"security", "secrets"}},
+	},
+	{
+		ID:                   "AWS002-exposed-ssm-securestring",
+		Name:                 "ExposedSSMSecureString",
+		ShortDescription:     internal.SarifMessage{Text: "SSM SecureString parameter can be enumerated by the scanning principal"},
+		HelpURI:              "https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#secrets",
+		DefaultConfiguration: internal.SarifRuleConfiguration{Level: internal.SarifLevelWarning},
+		Properties:           internal.SarifPropertyBag{"security-severity": "7.0", "tags": []string{"security", "secrets"}},
+	},
+	{
+		ID:                   "AWS003-exposed-ssm-parameter",
+		Name:                 "ExposedSSMParameter",
+		ShortDescription:     internal.SarifMessage{Text: "Plaintext SSM parameter can be enumerated by the scanning principal"},
+		HelpURI:              "https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#secrets",
+		DefaultConfiguration: internal.SarifRuleConfiguration{Level: internal.SarifLevelNote},
+		Properties:           internal.SarifPropertyBag{"security-severity": "4.0", "tags": []string{"security", "secrets"}},
+	},
+	{
+		ID:                   "AWS004-restorable-deleted-secret",
+		Name:                 "RestorableDeletedSecret",
+		ShortDescription:     internal.SarifMessage{Text: "Secrets Manager secret is scheduled for deletion but can still be restored"},
+		HelpURI:              "https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#secrets",
+		DefaultConfiguration: internal.SarifRuleConfiguration{Level: internal.SarifLevelWarning},
+		Properties:           internal.SarifPropertyBag{"security-severity": "5.0", "tags": []string{"security", "secrets"}},
+	},
+	{
+		ID:                   "AWS005-apprunner-environment-secret",
+		Name:                 "AppRunnerEnvironmentSecret",
+		ShortDescription:     internal.SarifMessage{Text: "App Runner service stores a credential in a plaintext environment variable"},
+		HelpURI:              "https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#secrets",
+		DefaultConfiguration: internal.SarifRuleConfiguration{Level: internal.SarifLevelError},
+		Properties:           internal.SarifPropertyBag{"security-severity": "8.0", "tags": []string{"security", "secrets"}},
+	},
+	{
+		ID:                   "AWS006-dynamodb-item-secret",
+		Name:                 "DynamoDBItemSecret",
+		ShortDescription:     internal.SarifMessage{Text: "DynamoDB item stores a credential in a string attribute"},
+		HelpURI:              "https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#secrets",
+		DefaultConfiguration: internal.SarifRuleConfiguration{Level: internal.SarifLevelError},
+		Properties:           internal.SarifPropertyBag{"security-severity": "8.0", "tags": []string{"security", "secrets"}},
+	},
+}
+
+// secretSarifRule picks the rule a secret is reported under
+func secretSarifRule(secret Secret) internal.SarifRule {
+	switch {
+	case secret.AWSService == "SecretsManager" && secret.Status == "Scheduled for deletion":
+		return secretsSarifRules[3]
+	case secret.AWSService == "SecretsManager":
+		return secretsSarifRules[0]
+	case secret.AWSService == "AppRunner":
+		return secretsSarifRules[4]
+	case secret.AWSService == "DynamoDB":
+		return secretsSarifRules[5]
+	case secret.Type == string(ssmTypes.ParameterTypeSecureString):
+		return secretsSarifRules[1]
+	default:
+		return secretsSarifRules[2]
+	}
+}
+
+// writeSarif turns every discovered secret into a SARIF result located at the secret's ARN, so the scan can be
+// uploaded to GitHub code scanning or fail a CI security gate
+func (m *SecretsModule) writeSarif() internal.SarifLog {
+	var results []internal.SarifResult
+	for _, secret := range m.Secrets {
+		rule := secretSarifRule(secret)
+		level := rule.DefaultConfiguration.Level
+		message := fmt.Sprintf("%s %s in %s can be enumerated by %s", secret.AWSService, secret.Name, secret.Region, aws.ToString(m.Caller.Arn))
+		if secret.Status == "Scheduled for deletion" {
+			message = fmt.Sprintf("%s %s in %s is scheduled for deletion and can be restored by %s", secret.AWSService, secret.Name, secret.Region, aws.ToString(m.Caller.Arn))
+		}
+		if secret.Value != "" {
+			level = internal.SarifLevelError
+			message += " and its value was decrypted during the scan"
+		}
+		location := secret.Arn
+		if location == "" {
+			location = secret.Name
+		}
+		results = append(results, internal.SarifResult{
+			RuleID:    rule.ID,
+			Level:     level,
+			Message:   internal.SarifMessage{Text: message},
+			Locations: []internal.SarifLocation{internal.NewSarifResourceLocation(location)},
+		})
+	}
+	return internal.NewSarifLog(secretsSarifRules, results)
+}
+
+func (m *SecretsModule) writeSarifFile(directory string) {
+	sarifFile, err := internal.WriteJSONFile(directory, m.output.CallingModule+".sarif", m.writeSarif())
+	if err != nil {
+		m.modLog.Error(err.Error())
+		return
+	}
+	fmt.Printf("[%s][%s] SARIF report written to %s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), sarifFile)
+}
+
+// Lower bounds of the severity labels, on the same scale as the security-severity of the SARIF rules
+const (
+	secretSeverityCritical = 9.0
+	secretSeverityHigh     = 7.0
+	secretSeverityMedium   = 4.0
+)
+
+// How many secrets the Slack summary lists
+const slackSummarySecrets = 10
+
+// How many realtime findings can wait for the Slack worker before the Receiver blocks, and how long one post may take
+const (
+	slackFindingsBuffer = 100
+	slackPostTimeout    = 10 * time.Second
+)
+
+// secretSeverity estimates how valuable a secret is from the security-severity of its SARIF rule. Credentials that
+// were just validated as working are CRITICAL.
+func secretSeverity(secret Secret) (float64, string) {
+	score, _ := strconv.ParseFloat(secretSarifRule(secret).Properties["security-severity"].(string), 64)
+	if secret.Validation == secretvalidation.StatusActive {
+		score = secretSeverityCritical
+	}
+	switch {
+	case score >= secretSeverityCritical:
+		return score, "CRITICAL"
+	case score >= secretSeverityHigh:
+		return score, "HIGH"
+	case score >= secretSeverityMedium:
+		return score, "MEDIUM"
+	}
+	return score, "LOW"
+}
+
+func slackSeverityColor(score float64) string {
+	switch {
+	case score >= secretSeverityHigh:
+		return slack.ColorDanger
+	case score >= secretSeverityMedium:
+		return slack.ColorWarning
+	}
+	return slack.ColorGood
+}
+
+func slackSecretLine(secret Secret) string {
+	score, label := secretSeverity(secret)
+	return fmt.Sprintf("`%s %.1f` %s in %s: %s", label, score, secret.AWSService, secret.Region, secret.Name)
+}
+
+// postSlackSummary posts the number of secrets per service and the top secrets by severity
+func (m *SecretsModule) postSlackSummary() {
+	secrets := append([]Secret{}, m.Secrets...)
+	sort.SliceStable(secrets, func(i, j int) bool {
+		scoreI, _ := secretSeverity(secrets[i])
+		scoreJ, _ := secretSeverity(secrets[j])
+		if scoreI != scoreJ {
+			return scoreI > scoreJ
+		}
+		if secrets[i].AWSService != secrets[j].AWSService {
+			return secrets[i].AWSService < secrets[j].AWSService
+		}
+		return secrets[i].Name < secrets[j].Name
+	})
+
+	message := slack.Message{
+		Text: fmt.Sprintf("cloudfox secrets found %d secrets in account %s (%s)", len(m.Secrets), aws.ToString(m.Caller.Account), m.AWSProfile),
+	}
+	if len(secrets) > 0 {
+		counts := make(map[string]int)
+		var services []string
+		for _, secret := range secrets {
+			if counts[secret.AWSService] == 0 {
+				services = append(services, secret.AWSService)
+			}
+			counts[secret.AWSService]++
+		}
+		sort.Strings(services)
+		var fields []slack.Field
+		for _, service := range services {
+			fields = append(fields, slack.Field{Title: service, Value: strconv.Itoa(counts[service]), Short: true})
+		}
+
+		var lines []string
+		for _, secret := range secrets {
+			if len(lines) == slackSummarySecrets {
+				break
+			}
+			lines = append(lines, slackSecretLine(secret))
+		}
+		topScore, _ := secretSeverity(secrets[0])
+		message.Attachments = []slack.Attachment{{
+			Color:  slackSeverityColor(topScore),
+			Title:  fmt.Sprintf("Top %d secrets by estimated severity", len(lines)),
+			Text:   strings.Join(lines, "\n"),
+			Fields: fields,
+			Footer: aws.ToString(m.Caller.Arn),
+		}}
+	}
+	if err := m.Slack.Post(context.TODO(), message); err != nil {
+		m.recordError("Global", "slack:PostWebhook", err)
+		return
+	}
+	fmt.Printf("[%s][%s] Posted the summary to Slack.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+}
+
+// slackWorker posts the secrets the Receiver queues on slackFindings until the channel is closed
+func (m *SecretsModule) slackWorker(done chan struct{}) {
+	defer close(done)
+	for secret := range m.slackFindings {
+		m.postSlackFinding(secret)
+	}
+}
+
+// postSlackFinding posts a single secret right when the Receiver gets it
+func (m *SecretsModule) postSlackFinding(secret Secret) {
+	score, _ := secretSeverity(secret)
+	message := slack.Message{
+		Text: fmt.Sprintf("cloudfox secrets found a secret in account %s (%s)", aws.ToString(m.Caller.Account), m.AWSProfile),
+		Attachments: []slack.Attachment{{
+			Color:  slackSeverityColor(score),
+			Text:   slackSecretLine(secret),
+			Footer: secret.Arn,
+		}},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), slackPostTimeout)
+	defer cancel()
+	if err := m.Slack.Post(ctx, message); err != nil {
+		m.recordError(secret.Region, "slack:PostWebhook", err)
+	}
+}
+
+// writeAnsibleLoot creates a playbook that pulls every discovered secret and parameter into Ansible variables.
+// Parameters are read with the amazon.aws.aws_ssm lookup because community.aws.aws_ssm_parameter_store only
+// manages parameters and can't return their values.
+func (m *SecretsModule) writeAnsibleLoot() string {
+	var out string
+	out = out + fmt.Sprintln("# Requires the amazon.aws collection: ansible-galaxy collection install amazon.aws")
+	out = out + fmt.Sprintln("# Set the $profile environment variable to the profile you are going to use to pull the secrets/parameters.")
+	out = out + fmt.Sprintln("# Run with: ansible-playbook retrieve-secrets.yml")
+	out = out + fmt.Sprintln("- name: Retrieve secrets found by CloudFox")
+	out = out + fmt.Sprintln("  hosts: localhost")
+	out = out + fmt.Sprintln("  connection: local")
+	out = out + fmt.Sprintln("  gather_facts: false")
+	out = out + fmt.Sprintln("  vars:")
+	out = out + fmt.Sprintln("    aws_profile: \"{{ lookup('ansible.builtin.env', 'profile') }}\"")
+	out = out + fmt.Sprintln("  tasks:")
+	out = out + fmt.Sprintln("    - name: Validate AWS credentials")
+	out = out + fmt.Sprintln("      amazon.aws.aws_caller_info:")
+	out = out + fmt.Sprintln("        profile: \"{{ aws_profile }}\"")
+	out = out + fmt.Sprintln("      register: caller_info")
+	out = out + fmt.Sprintln("")
+	out = out + fmt.Sprintln("    - name: Show the identity used to retrieve secrets")
+	out = out + fmt.Sprintln("      ansible.builtin.debug:")
+	out = out + fmt.Sprintln("        msg: \"Retrieving secrets as {{ caller_info.arn }}\"")
+
+	for _, secret := range m.Secrets {
+		var lookup string
+		switch {
+		case secret.Status == "Scheduled for deletion":
+			// These have to be restored before they can be read
+			continue
+		case secret.AWSService == "SecretsManager":
+			lookup = fmt.Sprintf("lookup('amazon.aws.aws_secret', '%s', region='%s', profile=aws_profile)", secret.Name, secret.Region)
+		case secret.AWSService == "SSM":
+			lookup = fmt.Sprintf("lookup('amazon.aws.aws_ssm', '%s', region='%s', profile=aws_profile, decrypt=true)", secret.Name, secret.Region)
+		default:
+			continue
+		}
+		out = out + fmt.Sprintln("")
+		out = out + fmt.Sprintf("    - name: Retrieve %s %s from %s\n", secret.AWSService, secret.Name, secret.Region)
+		out = out + fmt.Sprintln("      ansible.builtin.set_fact:")
+		out = out + fmt.Sprintf("        %s: \"{{ %s }}\"\n", secretVariableName(secret), lookup)
+	}
+
+	return out
+}
+
+// writeTerraformLoot creates data sources for every discovered secret and parameter, with a provider alias per region,
+// so a Terraform configuration can check that it reads the same secrets.
+func (m *SecretsModule) writeTerraformLoot() string {
+	var out string
+	out = out + fmt.Sprintln("# Data sources for the secrets and parameters found by CloudFox.")
+	out = out + fmt.Sprintln("# The providers pick up credentials from the environment, e.g. export AWS_PROFILE=dev-prod.")
+	out = out + fmt.Sprintln("# Run terraform plan to check that every secret can be read.")
+
+	var secrets []Secret
+	var regions []string
+	for _, secret := range m.Secrets {
+		// These have to be restored before they can be read
+		if secret.Status == "Scheduled for deletion" {
+			continue
+		}
+		if secret.AWSService != "SecretsManager" && secret.AWSService != "SSM" {
+			continue
+		}
+		secrets = append(secrets, secret)
+		if !internal.Contains(secret.Region, regions) {
+			regions = append(regions, secret.Region)
+		}
+	}
+	sort.Strings(regions)
+
+	for _, region := range regions {
+		out = out + fmt.Sprintln("")
+		out = out + fmt.Sprintln("provider \"aws\" {")
+		out = out + fmt.Sprintf("  alias  = \"%s\"\n", terraformProviderAlias(region))
+		out = out + fmt.Sprintf("  region = \"%s\"\n", region)
+		out = out + fmt.Sprintln("}")
+	}
+
+	for _, secret := range secrets {
+		out = out + fmt.Sprintln("")
+		if secret.AWSService == "SecretsManager" {
+			out = out + fmt.Sprintf("data \"aws_secretsmanager_secret_version\" \"%s\" {\n", secretVariableName(secret))
+			out = out + fmt.Sprintf("  provider  = aws.%s\n", terraformProviderAlias(secret.Region))
+			out = out + fmt.Sprintf("  secret_id = \"%s\"\n", secret.Name)
+		} else {
+			out = out + fmt.Sprintf("data \"aws_ssm_parameter\" \"%s\" {\n", secretVariableName(secret))
+			out = out + fmt.Sprintf("  provider        = aws.%s\n", terraformProviderAlias(secret.Region))
+			out = out + fmt.Sprintf("  name            = \"%s\"\n", secret.Name)
+			out = out + fmt.Sprintln("  with_decryption = true")
+		}
+		out = out + fmt.Sprintln("}")
+	}
+
+	return out
+}
+
+// terraformProviderAlias turns a region into a provider alias, e.g. us_east_1
+func terraformProviderAlias(region string) string {
+	return strings.ReplaceAll(region, "-", "_")
+}
+
+// secretVariableName turns a secret into a valid and unique name for an Ansible variable or a Terraform data source,
+// e.g. ssm_us_east_1_parameter_param1
+func secretVariableName(secret Secret) string {
+	name := strings.ToLower(fmt.Sprintf("%s_%s_%s", secret.AWSService, secret.Region, secret.Name))
+	var variable strings.Builder
+	for _, c := range name {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+			variable.WriteRune(c)
+		} else if !strings.HasSuffix(variable.String(), "_") {
+			variable.WriteRune('_')
+		}
+	}
+	return strings.TrimSuffix(variable.String(), "_")
+}
+
+func (m *SecretsModule) getSecretsManagerSecretsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Secret) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	// m.CommandCounter.Total++
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+	secrets, err := sdk.CachedSecretsManagerListSecrets(m.SecretsManagerClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.recordError(r, "secretsmanager:ListSecrets", err)
+		return
+	}
+
+	for _, secret := range secrets {
+		name := aws.ToString(secret.Name)
+		var description string
+		if secret.Description != nil {
+			description = aws.ToString(secret.Description)
+		}
+
+		dataReceiver <- Secret{
+			AWSService:   "SecretsManager",
+			Region:       r,
+			Name:         name,
+			Arn:          aws.ToString(secret.ARN),
+			Description:  description,
+			LastModified: secretLastModified(secret.LastChangedDate, secret.CreatedDate),
+			Tags:         secretsManagerTags(secret.Tags),
+		}
+
+	}
+}
+
+func (m *SecretsModule) getSSMParametersPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Secret) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	// m.CommandCounter.Total++
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+	// "PaginationMarker" is a control variable used for output continuity, as AWS return the output in pages.
+	var PaginationControl *string
+
+	for {
+		DescribeParameters, err := m.SSMClient.DescribeParameters(
+			context.TODO(),
+			&(ssm.DescribeParametersInput{
+				NextToken: PaginationControl,
+			}),
+			func(o *ssm.Options) {
+				o.Region = r
+			},
+		)
+		if err != nil {
+			m.recordError(r, "ssm:DescribeParameters", err)
+			break
+		}
+
+		for _, parameter := range DescribeParameters.Parameters {
+			var description string
+			name := aws.ToString(parameter.Name)
+			if parameter.Description != nil {
+				description = aws.ToString(parameter.Description)
+			}
+
+			secret := Secret{
+				AWSService:   "SSM",
+				Region:       r,
+				Name:         name,
+				Arn:          fmt.Sprintf("arn:aws:ssm:%s:%s:parameter/%s", r, aws.ToString(m.Caller.Account), strings.TrimPrefix(name, "/")),
+				Description:  description,
+				Type:         string(parameter.Type),
+				LastModified: aws.ToTime(parameter.LastModifiedDate),
+			}
+			// Skip old parameters before decrypting them
+			if !m.changedSince(secret.LastModified) {
+				continue
+			}
+			if m.ResolveValues && parameter.Type == ssmTypes.ParameterTypeSecureString {
+				secret.Value = previewSecretValue(m.getSSMParameterValue(r, name))
+			}
+			if m.PreferProd {
+				secret.Tags = m.getSSMParameterTags(r, name)
+			}
+			dataReceiver <- secret
 
 		}
 
@@ -375,3 +1608,596 @@ func (m *SecretsModule) getSSMParametersPerRegion(r string, wg *sync.WaitGroup,
 		}
 	}
 }
+
+// getNamedSecretsManagerSecretsPerRegion looks up each of SecretNames with DescribeSecret. Names that don't exist in
+// the region are skipped quietly, every other error ends up in the error summary.
+func (m *SecretsModule) getNamedSecretsManagerSecretsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Secret) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	for _, name := range m.SecretNames {
+		DescribeSecret, err := m.SecretsManagerClient.DescribeSecret(
+			context.TODO(),
+			&secretsmanager.DescribeSecretInput{
+				SecretId: aws.String(name),
+			},
+			func(o *secretsmanager.Options) {
+				o.Region = r
+			},
+		)
+		if err != nil {
+			if !isSecretNotFound(err) {
+				m.recordError(r, "secretsmanager:DescribeSecret", err)
+			}
+			continue
+		}
+
+		secret := Secret{
+			AWSService:   "SecretsManager",
+			Region:       r,
+			Name:         aws.ToString(DescribeSecret.Name),
+			Arn:          aws.ToString(DescribeSecret.ARN),
+			Description:  aws.ToString(DescribeSecret.Description),
+			LastModified: secretLastModified(DescribeSecret.LastChangedDate, DescribeSecret.CreatedDate),
+			Tags:         secretsManagerTags(DescribeSecret.Tags),
+		}
+		if DescribeSecret.DeletedDate != nil {
+			secret.Status = "Scheduled for deletion"
+		}
+		dataReceiver <- secret
+	}
+}
+
+// getNamedSSMParametersPerRegion looks up each of SecretNames with GetParameter. Parameters are only decrypted when
+// their values are resolved, so the lookup doesn't need kms:Decrypt otherwise.
+func (m *SecretsModule) getNamedSSMParametersPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Secret) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	for _, name := range m.SecretNames {
+		GetParameter, err := m.SSMClient.GetParameter(
+			context.TODO(),
+			&ssm.GetParameterInput{
+				Name:           aws.String(name),
+				WithDecryption: aws.Bool(m.ResolveValues),
+			},
+			func(o *ssm.Options) {
+				o.Region = r
+			},
+		)
+		if err != nil {
+			if !isSecretNotFound(err) {
+				m.recordError(r, "ssm:GetParameter", err)
+			}
+			continue
+		}
+		if GetParameter.Parameter == nil {
+			continue
+		}
+
+		parameter := GetParameter.Parameter
+		arn := aws.ToString(parameter.ARN)
+		if arn == "" {
+			arn = fmt.Sprintf("arn:aws:ssm:%s:%s:parameter/%s", r, aws.ToString(m.Caller.Account), strings.TrimPrefix(aws.ToString(parameter.Name), "/"))
+		}
+		secret := Secret{
+			AWSService:   "SSM",
+			Region:       r,
+			Name:         aws.ToString(parameter.Name),
+			Arn:          arn,
+			Type:         string(parameter.Type),
+			LastModified: aws.ToTime(parameter.LastModifiedDate),
+		}
+		if m.ResolveValues && parameter.Type == ssmTypes.ParameterTypeSecureString {
+			secret.Value = previewSecretValue(aws.ToString(parameter.Value))
+		}
+		if m.PreferProd {
+			secret.Tags = m.getSSMParameterTags(r, secret.Name)
+		}
+		dataReceiver <- secret
+	}
+}
+
+// secretLastModified returns when a Secrets Manager secret last changed. Secrets that were never changed have no
+// LastChangedDate, so their creation date is used.
+func secretLastModified(lastChangedDate *time.Time, createdDate *time.Time) time.Time {
+	if lastChangedDate != nil {
+		return *lastChangedDate
+	}
+	return aws.ToTime(createdDate)
+}
+
+// isSecretNotFound tells if a lookup by name failed only because the secret or parameter doesn't exist in the region
+func isSecretNotFound(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode() == "ResourceNotFoundException" || apiErr.ErrorCode() == "ParameterNotFound"
+	}
+	return false
+}
+
+// getSSMParameterValue decrypts a SecureString parameter. The value is deliberately not cached, so it never ends up in
+// the on-disk cache.
+func (m *SecretsModule) getSSMParameterValue(r string, name string) string {
+	GetParameter, err := m.SSMClient.GetParameter(
+		context.TODO(),
+		&ssm.GetParameterInput{
+			Name:           aws.String(name),
+			WithDecryption: aws.Bool(true),
+		},
+		func(o *ssm.Options) {
+			o.Region = r
+		},
+	)
+	if err != nil {
+		m.recordError(r, "ssm:GetParameter", err)
+		return ""
+	}
+	if GetParameter.Parameter == nil {
+		return ""
+	}
+	return aws.ToString(GetParameter.Parameter.Value)
+}
+
+func (m *SecretsModule) getSSMParameterTags(r string, name string) map[string]string {
+	ListTagsForResource, err := m.SSMClient.ListTagsForResource(
+		context.TODO(),
+		&ssm.ListTagsForResourceInput{
+			ResourceType: ssmTypes.ResourceTypeForTaggingParameter,
+			ResourceId:   aws.String(name),
+		},
+		func(o *ssm.Options) {
+			o.Region = r
+		},
+	)
+	if err != nil {
+		m.recordError(r, "ssm:ListTagsForResource", err)
+		return nil
+	}
+	var tags map[string]string
+	for _, tag := range ListTagsForResource.TagList {
+		if tags == nil {
+			tags = make(map[string]string)
+		}
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags
+}
+
+func secretsManagerTags(secretTags []secretsmanagerTypes.Tag) map[string]string {
+	var tags map[string]string
+	for _, tag := range secretTags {
+		if tags == nil {
+			tags = make(map[string]string)
+		}
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags
+}
+
+// defaultSecretsProdPattern matches the usual production environment tags and names under a prod/ path
+var defaultSecretsProdPattern = regexp.MustCompile(`(?i)^(env|environment|stage)=prod(uction)?$|prod/`)
+
+// isProductionSecret tells if the name or one of the key=value tags of a secret matches the production pattern
+func (m *SecretsModule) isProductionSecret(secret Secret) bool {
+	pattern := m.ProdPattern
+	if pattern == nil {
+		pattern = defaultSecretsProdPattern
+	}
+	if pattern.MatchString(secret.Name) {
+		return true
+	}
+	for key, value := range secret.Tags {
+		if pattern.MatchString(fmt.Sprintf("%s=%s", key, value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortProductionFirst moves the production secrets to the top, keeping the order within both groups, and returns how
+// many there are
+func (m *SecretsModule) sortProductionFirst() int {
+	var production, other []Secret
+	for _, secret := range m.Secrets {
+		if m.isProductionSecret(secret) {
+			production = append(production, secret)
+		} else {
+			other = append(other, secret)
+		}
+	}
+	m.Secrets = append(production, other...)
+	return len(production)
+}
+
+// previewSecretValue puts the value on one line and truncates it to secretValuePreviewLength characters
+func previewSecretValue(value string) string {
+	value = strings.ReplaceAll(value, "\r", "")
+	value = strings.ReplaceAll(value, "\n", "\\n")
+	runes := []rune(value)
+	if len(runes) > secretValuePreviewLength {
+		return string(runes[:secretValuePreviewLength-3]) + "..."
+	}
+	return value
+}
+
+// getDeletedSecretsPerRegion looks for secrets that AWS Config still knows about but that ListSecrets no longer returns.
+// ListSecrets hides secrets that are scheduled for deletion, and those can be restored with RestoreSecret until the
+// 7-30 day recovery window ends.
+func (m *SecretsModule) getDeletedSecretsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Secret) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	recorded, err := sdk.CachedConfigServiceListDiscoveredResources(m.ConfigClient, aws.ToString(m.Caller.Account), r, configSecretResourceType)
+	if err != nil {
+		m.recordError(r, "config:ListDiscoveredResources", err)
+		return
+	}
+	if len(recorded) == 0 {
+		return
+	}
+
+	live, err := sdk.CachedSecretsManagerListSecrets(m.SecretsManagerClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.recordError(r, "secretsmanager:ListSecrets", err)
+		return
+	}
+	liveSecrets := make(map[string]bool)
+	for _, secret := range live {
+		liveSecrets[aws.ToString(secret.Name)] = true
+		liveSecrets[aws.ToString(secret.ARN)] = true
+	}
+
+	for _, resource := range recorded {
+		name := aws.ToString(resource.ResourceName)
+		if liveSecrets[name] || liveSecrets[aws.ToString(resource.ResourceId)] {
+			continue
+		}
+		dataReceiver <- Secret{
+			AWSService:   "SecretsManager",
+			Region:       r,
+			Name:         name,
+			Arn:          aws.ToString(resource.ResourceId),
+			Description:  "Recorded by AWS Config but missing from ListSecrets",
+			Status:       "Scheduled for deletion",
+			LastModified: aws.ToTime(resource.ResourceDeletionTime),
+		}
+	}
+}
+
+// getAppRunnerEnvSecretsPerRegion looks for credentials in the plaintext environment variables of App Runner services.
+// Variables that reference Secrets Manager or SSM are kept in RuntimeEnvironmentSecrets and are not scanned.
+func (m *SecretsModule) getAppRunnerEnvSecretsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Secret) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	services, err := sdk.CachedAppRunnerListServices(m.AppRunnerClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		if isServiceUnavailableInRegion(err) {
+			m.modLog.Debugf("AppRunner is not available in %s", r)
+			return
+		}
+		m.recordError(r, "apprunner:ListServices", err)
+		return
+	}
+
+	for _, summary := range services {
+		service, err := sdk.CachedAppRunnerDescribeService(m.AppRunnerClient, aws.ToString(m.Caller.Account), r, aws.ToString(summary.ServiceArn))
+		if err != nil {
+			m.recordError(r, "apprunner:DescribeService", err)
+			continue
+		}
+		if service.SourceConfiguration == nil || service.SourceConfiguration.ImageRepository == nil || service.SourceConfiguration.ImageRepository.ImageConfiguration == nil {
+			continue
+		}
+		variables := service.SourceConfiguration.ImageRepository.ImageConfiguration.RuntimeEnvironmentVariables
+		var keys []string
+		for key := range variables {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			for _, match := range scanDefinitionForSecrets(fmt.Sprintf("%s=%s", key, variables[key])) {
+				secret := Secret{
+					AWSService:   "AppRunner",
+					Region:       r,
+					Name:         fmt.Sprintf("%s/%s", aws.ToString(service.ServiceName), key),
+					Arn:          aws.ToString(service.ServiceArn),
+					Description:  fmt.Sprintf("%s in environment variable: %s", match.Rule, maskDefinitionSecret(match.Line, match.Value)),
+					Type:         "Environment variable",
+					LastModified: aws.ToTime(service.UpdatedAt),
+				}
+				if m.ResolveValues {
+					secret.Value = previewSecretValue(match.Value)
+				}
+				if m.Validator != nil {
+					secret.envValue = match.Value
+				}
+				dataReceiver <- secret
+			}
+		}
+	}
+}
+
+// dynamoSecretsMaxItems is how many items of a table are scanned. Secrets tables are small, and scanning more of a
+// big table costs read capacity for mostly noise.
+const dynamoSecretsMaxItems = 100
+
+// dynamoSecretsSampleItems are read first to learn the attribute names of a table, DescribeTable only knows the keys
+// and the attributes projected into indexes
+const dynamoSecretsSampleItems = 10
+
+// getDynamoDBSecretsPerRegion scans the first items of every table, or of DynamoTables, for credentials in string
+// attributes. The scan projects only the string attributes, so large number and binary attributes are not read.
+func (m *SecretsModule) getDynamoDBSecretsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Secret) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	tables := m.DynamoTables
+	if len(tables) == 0 {
+		var err error
+		tables, err = sdk.CachedDynamoDBListTables(m.DynamoDBClient, aws.ToString(m.Caller.Account), r)
+		if err != nil {
+			m.recordError(r, "dynamodb:ListTables", err)
+			return
+		}
+	}
+
+	for _, tableName := range tables {
+		table, err := sdk.CachedDynamoDBDescribeTable(m.DynamoDBClient, aws.ToString(m.Caller.Account), r, tableName)
+		if err != nil {
+			// Tables given with --dynamo-tables usually only exist in some regions
+			var notFound *dynamodbTypes.ResourceNotFoundException
+			if !errors.As(err, &notFound) {
+				m.recordError(r, "dynamodb:DescribeTable", err)
+			}
+			continue
+		}
+		items, err := m.scanDynamoDBStringAttributes(r, table)
+		if err != nil {
+			m.recordError(r, "dynamodb:Scan", err)
+			continue
+		}
+		for _, item := range items {
+			key := dynamoItemKey(table, item)
+			var attributes []string
+			for attribute := range item {
+				attributes = append(attributes, attribute)
+			}
+			sort.Strings(attributes)
+			for _, attribute := range attributes {
+				for _, value := range dynamoStringValues(item[attribute]) {
+					for _, match := range m.scanDynamoValueForSecrets(attribute, value) {
+						secret := Secret{
+							AWSService:  "DynamoDB",
+							Region:      r,
+							Name:        fmt.Sprintf("%s/%s/%s", tableName, key, attribute),
+							Arn:         aws.ToString(table.TableArn),
+							Description: fmt.Sprintf("%s in item attribute: %s", match.Rule, maskDefinitionSecret(match.Line, match.Value)),
+							Type:        "DynamoDB item",
+						}
+						if m.ResolveValues {
+							secret.Value = previewSecretValue(match.Value)
+						}
+						if m.Validator != nil {
+							secret.envValue = match.Value
+						}
+						dataReceiver <- secret
+					}
+				}
+			}
+		}
+	}
+}
+
+// scanDynamoDBStringAttributes returns up to dynamoSecretsMaxItems items with only their string attributes. The names
+// come from the string keys of the table and its indexes, the non-key attributes projected into its indexes, and the
+// string attributes of a small sample of items. The table's key is always projected to tell the items apart.
+func (m *SecretsModule) scanDynamoDBStringAttributes(r string, table dynamodbTypes.TableDescription) ([]map[string]dynamodbTypes.AttributeValue, error) {
+	names := make(map[string]bool)
+	for _, element := range table.KeySchema {
+		names[aws.ToString(element.AttributeName)] = true
+	}
+	for _, definition := range table.AttributeDefinitions {
+		if definition.AttributeType == dynamodbTypes.ScalarAttributeTypeS {
+			names[aws.ToString(definition.AttributeName)] = true
+		}
+	}
+	for _, index := range table.GlobalSecondaryIndexes {
+		if index.Projection != nil {
+			for _, name := range index.Projection.NonKeyAttributes {
+				names[name] = true
+			}
+		}
+	}
+	for _, index := range table.LocalSecondaryIndexes {
+		if index.Projection != nil {
+			for _, name := range index.Projection.NonKeyAttributes {
+				names[name] = true
+			}
+		}
+	}
+
+	sample, err := m.DynamoDBClient.Scan(
+		context.TODO(),
+		&dynamodb.ScanInput{
+			TableName: table.TableName,
+			Limit:     aws.Int32(dynamoSecretsSampleItems),
+		},
+		func(o *dynamodb.Options) {
+			o.Region = r
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range sample.Items {
+		for name, value := range item {
+			if len(dynamoStringValues(value)) > 0 {
+				names[name] = true
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var sortedNames []string
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+	// Placeholders keep attribute names that are reserved words, like "name" or "password", from breaking the expression
+	var projection []string
+	expressionAttributeNames := make(map[string]string)
+	for i, name := range sortedNames {
+		placeholder := fmt.Sprintf("#a%d", i)
+		projection = append(projection, placeholder)
+		expressionAttributeNames[placeholder] = name
+	}
+
+	var items []map[string]dynamodbTypes.AttributeValue
+	var PaginationControl map[string]dynamodbTypes.AttributeValue
+	for len(items) < dynamoSecretsMaxItems {
+		Scan, err := m.DynamoDBClient.Scan(
+			context.TODO(),
+			&dynamodb.ScanInput{
+				TableName:                table.TableName,
+				ProjectionExpression:     aws.String(strings.Join(projection, ", ")),
+				ExpressionAttributeNames: expressionAttributeNames,
+				Limit:                    aws.Int32(int32(dynamoSecretsMaxItems - len(items))),
+				ExclusiveStartKey:        PaginationControl,
+			},
+			func(o *dynamodb.Options) {
+				o.Region = r
+			},
+		)
+		if err != nil {
+			return items, err
+		}
+		items = append(items, Scan.Items...)
+
+		if len(Scan.LastEvaluatedKey) == 0 {
+			break
+		}
+		PaginationControl = Scan.LastEvaluatedKey
+	}
+	if len(items) > dynamoSecretsMaxItems {
+		items = items[:dynamoSecretsMaxItems]
+	}
+	return items, nil
+}
+
+// scanDynamoValueForSecrets applies DynamoRegex, or the credential patterns of workflow-secrets to "attribute=value"
+// so that attribute names like password count as well
+func (m *SecretsModule) scanDynamoValueForSecrets(attribute string, value string) []WorkflowSecret {
+	if m.DynamoRegex == nil {
+		return scanDefinitionForSecrets(fmt.Sprintf("%s=%s", attribute, value))
+	}
+	var matches []WorkflowSecret
+	var seen []string
+	for _, match := range m.DynamoRegex.FindAllString(value, -1) {
+		if match == "" || internal.Contains(match, seen) {
+			continue
+		}
+		seen = append(seen, match)
+		matches = append(matches, WorkflowSecret{
+			Rule:  "Custom pattern",
+			Line:  fmt.Sprintf("%s=%s", attribute, value),
+			Value: match,
+		})
+	}
+	return matches
+}
+
+// dynamoStringValues returns the value of a string attribute, or the members of a string set
+func dynamoStringValues(value dynamodbTypes.AttributeValue) []string {
+	switch v := value.(type) {
+	case *dynamodbTypes.AttributeValueMemberS:
+		return []string{v.Value}
+	case *dynamodbTypes.AttributeValueMemberSS:
+		return v.Value
+	}
+	return nil
+}
+
+// dynamoItemKey identifies an item by the values of its key attributes, joined with "/"
+func dynamoItemKey(table dynamodbTypes.TableDescription, item map[string]dynamodbTypes.AttributeValue) string {
+	var key []string
+	for _, element := range table.KeySchema {
+		switch v := item[aws.ToString(element.AttributeName)].(type) {
+		case *dynamodbTypes.AttributeValueMemberS:
+			key = append(key, v.Value)
+		case *dynamodbTypes.AttributeValueMemberN:
+			key = append(key, v.Value)
+		}
+	}
+	if len(key) == 0 {
+		return "-"
+	}
+	return strings.Join(key, "/")
+}
+
+// isServiceUnavailableInRegion tells if a call failed because the service has no endpoint in the region, as opposed
+// to a permission or throttling error worth reporting
+func isServiceUnavailableInRegion(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode() == "UnrecognizedClientException"
+	}
+	return false
+}
@@ -187,3 +187,19 @@ func removeStringFromSlice(slice []string, element string) []string {
 	}
 	return slice
 }
+
+// isAccessDeniedError returns true if the error came back from AWS because the caller isn't allowed to make the call
+func isAccessDeniedError(err error) bool {
+	return internal.IsAccessDeniedError(err)
+}
+
+// isSecretLookingName returns true if the name of a variable suggests it holds a credential. It's the heuristic the
+// env-vars module has always used to highlight interesting variables.
+func isSecretLookingName(name string) bool {
+	lowerName := strings.ToLower(name)
+	return (strings.Contains(lowerName, "secret") && !strings.Contains(lowerName, "arn")) ||
+		strings.Contains(lowerName, "key") ||
+		strings.Contains(lowerName, "token") ||
+		strings.Contains(lowerName, "user") ||
+		strings.Contains(lowerName, "pass")
+}
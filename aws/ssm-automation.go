@@ -0,0 +1,355 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type SSMAutomationHistoryModule struct {
+	// General configuration data
+	SSMClient sdk.AWSSSMClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+	Days       int
+
+	// Main module data
+	AutomationExecutions []AutomationExecution
+	CommandCounter       internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type AutomationExecution struct {
+	Region             string
+	ID                 string
+	DocumentName       string
+	Status             string
+	ExecutedBy         string
+	StartTime          string
+	Steps              string
+	HighRiskDocument   string
+	UnexpectedExecutor string
+}
+
+// Documents that AWS ships which grant or create administrative access when executed
+var highRiskAutomationDocuments = []string{
+	"AWS-CreateIAMUserWithAdminPermissions",
+	"AWS-GrantAdminPermissionsToUser",
+}
+
+// Substrings that identify the principals we expect to be running automation documents
+var expectedAutomationExecutors = []string{
+	"AutomationAdministrationRole",
+	"AutomationExecutionRole",
+	"AWSServiceRoleForAmazonSSM",
+	"ssm.amazonaws.com",
+}
+
+func (m *SSMAutomationHistoryModule) PrintSSMAutomationHistory(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "ssm-automation"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+	if m.Days <= 0 {
+		m.Days = 7
+	}
+
+	fmt.Printf("[%s][%s] Enumerating SSM automation executions from the last %d days for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.Days, aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan AutomationExecution)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Execution ID",
+		"Document",
+		"Status",
+		"Executed By",
+		"Start Time",
+		"Steps",
+		"High Risk Document?",
+		"Unexpected Executor?",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Execution ID",
+			"Document",
+			"Status",
+			"Executed By",
+			"Start Time",
+			"Steps",
+			"High Risk Document?",
+			"Unexpected Executor?",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Document",
+			"Status",
+			"Executed By",
+			"Start Time",
+			"High Risk Document?",
+			"Unexpected Executor?",
+		}
+	}
+
+	// Table rows
+	for i := range m.AutomationExecutions {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				m.AutomationExecutions[i].Region,
+				m.AutomationExecutions[i].ID,
+				m.AutomationExecutions[i].DocumentName,
+				m.AutomationExecutions[i].Status,
+				m.AutomationExecutions[i].ExecutedBy,
+				m.AutomationExecutions[i].StartTime,
+				m.AutomationExecutions[i].Steps,
+				m.AutomationExecutions[i].HighRiskDocument,
+				m.AutomationExecutions[i].UnexpectedExecutor,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+
+		loot := m.writeLoot()
+		if loot != "" {
+			o.Loot.DirectoryName = o.Table.DirectoryName
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     "ssm-automation-executions",
+				Contents: loot,
+			})
+		}
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d automation executions found (%d flagged).\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), m.countFlagged())
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No automation executions found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *SSMAutomationHistoryModule) countFlagged() int {
+	var count int
+	for _, execution := range m.AutomationExecutions {
+		if execution.HighRiskDocument == "Yes" || execution.UnexpectedExecutor == "Yes" {
+			count++
+		}
+	}
+	return count
+}
+
+func (m *SSMAutomationHistoryModule) writeLoot() string {
+	var out string
+	for _, execution := range m.AutomationExecutions {
+		if execution.HighRiskDocument != "Yes" && execution.UnexpectedExecutor != "Yes" {
+			continue
+		}
+		out += fmt.Sprintf("# %s executed %s (%s)\n", execution.ExecutedBy, execution.DocumentName, execution.Status)
+		out += fmt.Sprintf("aws --profile $profile --region %s ssm get-automation-execution --automation-execution-id %s\n", execution.Region, execution.ID)
+		out += fmt.Sprintf("aws --profile $profile --region %s ssm describe-automation-step-executions --automation-execution-id %s\n\n", execution.Region, execution.ID)
+	}
+	return out
+}
+
+func (m *SSMAutomationHistoryModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan AutomationExecution) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("ssm", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getAutomationExecutionsPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *SSMAutomationHistoryModule) Receiver(receiver chan AutomationExecution, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.AutomationExecutions = append(m.AutomationExecutions, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *SSMAutomationHistoryModule) getAutomationExecutionsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan AutomationExecution) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	startTimeAfter := time.Now().AddDate(0, 0, -m.Days)
+	executions, err := sdk.CachedSSMDescribeAutomationExecutions(m.SSMClient, aws.ToString(m.Caller.Account), r, startTimeAfter)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, execution := range executions {
+		var startTime string
+		if execution.ExecutionStartTime != nil {
+			startTime = execution.ExecutionStartTime.Format(time.RFC3339)
+		}
+		documentName := aws.ToString(execution.DocumentName)
+		executedBy := aws.ToString(execution.ExecutedBy)
+
+		automationExecution := AutomationExecution{
+			Region:             r,
+			ID:                 aws.ToString(execution.AutomationExecutionId),
+			DocumentName:       documentName,
+			Status:             string(execution.AutomationExecutionStatus),
+			ExecutedBy:         executedBy,
+			StartTime:          startTime,
+			HighRiskDocument:   "No",
+			UnexpectedExecutor: "No",
+		}
+		if isHighRiskAutomationDocument(documentName) {
+			automationExecution.HighRiskDocument = "Yes"
+		}
+		if !isExpectedAutomationExecutor(executedBy) {
+			automationExecution.UnexpectedExecutor = "Yes"
+		}
+
+		// Only pull the step details for the executions we care about to keep the number of API calls down
+		if automationExecution.HighRiskDocument == "Yes" || automationExecution.UnexpectedExecutor == "Yes" {
+			steps, err := sdk.CachedSSMDescribeAutomationStepExecutions(m.SSMClient, aws.ToString(m.Caller.Account), r, automationExecution.ID)
+			if err != nil {
+				m.modLog.Error(err.Error())
+			}
+			var stepSummaries []string
+			for _, step := range steps {
+				stepSummaries = append(stepSummaries, fmt.Sprintf("%s (%s)", aws.ToString(step.StepName), aws.ToString(step.Action)))
+			}
+			automationExecution.Steps = strings.Join(stepSummaries, ", ")
+		}
+
+		dataReceiver <- automationExecution
+	}
+}
+
+// Custom documents (anything not published by AWS) are treated as high risk since we can't know what they do
+func isHighRiskAutomationDocument(documentName string) bool {
+	for _, document := range highRiskAutomationDocuments {
+		if documentName == document {
+			return true
+		}
+	}
+	// Documents shared from other accounts are referenced by ARN
+	if strings.HasPrefix(documentName, "arn:") {
+		return true
+	}
+	return !strings.HasPrefix(documentName, "AWS") && !strings.HasPrefix(documentName, "Amazon")
+}
+
+func isExpectedAutomationExecutor(executedBy string) bool {
+	for _, executor := range expectedAutomationExecutors {
+		if strings.Contains(executedBy, executor) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package aws
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestSSMAutomationHistory(t *testing.T) {
+
+	m := SSMAutomationHistoryModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:    3,
+		WrapTable:     false,
+		AWSOutputType: "wide",
+		SSMClient:     &sdk.MockedSSMClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+	tmpDir := "."
+
+	m.PrintSSMAutomationHistory(tmpDir, 2)
+
+	resultsFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/table/ssm-automation.txt")
+	resultsFile, err := afero.ReadFile(fs, resultsFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read output file at %s: %s", resultsFilePath, err)
+	}
+
+	for _, expected := range []string{"AWS-RestartEC2Instance", "AWS-GrantAdminPermissionsToUser", "attachPolicy (aws:executeAwsApi)"} {
+		if !strings.Contains(string(resultsFile), expected) {
+			t.Errorf("Expected %s to be in the results file", expected)
+		}
+	}
+
+	lootFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/loot/ssm-automation-executions.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	if !strings.Contains(string(lootFile), "22222222-2222-2222-2222-222222222222") {
+		t.Errorf("Expected the flagged execution to be in the loot file")
+	}
+	if strings.Contains(string(lootFile), "11111111-1111-1111-1111-111111111111") {
+		t.Errorf("Did not expect the execution run by the automation role to be in the loot file")
+	}
+}
+
+func TestIsHighRiskAutomationDocument(t *testing.T) {
+	cases := map[string]bool{
+		"AWS-GrantAdminPermissionsToUser":                    true,
+		"AWS-CreateIAMUserWithAdminPermissions":              true,
+		"AWS-RestartEC2Instance":                             false,
+		"MyCustomDocument":                                   true,
+		"arn:aws:ssm:us-east-1:111111111111:document/Shared": true,
+	}
+	for document, expected := range cases {
+		if isHighRiskAutomationDocument(document) != expected {
+			t.Errorf("isHighRiskAutomationDocument(%s) should be %t", document, expected)
+		}
+	}
+}
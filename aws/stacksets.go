@@ -0,0 +1,423 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type StackSetsModule struct {
+	// General configuration data
+	CloudFormationClient sdk.CloudFormationStackSetsClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	StackSets      []StackSet
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type StackSet struct {
+	Region          string
+	Name            string
+	Arn             string
+	Status          string
+	PermissionModel string
+	AutoDeployment  bool
+	// ExecutionRole is the role the stacks are deployed with in the target accounts of a self-managed stack set
+	ExecutionRole string
+	// IAMRoles are the logical IDs of the AWS::IAM::Role resources of the template
+	IAMRoles  []string
+	Instances []StackSetInstance
+}
+
+type StackSetInstance struct {
+	Account            string
+	Region             string
+	OrganizationalUnit string
+	Status             string
+}
+
+func (m *StackSetsModule) PrintStackSets(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "stacksets"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating CloudFormation stack sets and their deployment targets for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan StackSet)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.StackSets, func(i, j int) bool {
+		if m.StackSets[i].Region != m.StackSets[j].Region {
+			return m.StackSets[i].Region < m.StackSets[j].Region
+		}
+		return m.StackSets[i].Name < m.StackSets[j].Name
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Stack Set",
+		"Arn",
+		"Status",
+		"Permission Model",
+		"Auto Deployment",
+		"Execution Role",
+		"Target Account",
+		"Target Region",
+		"Target OU",
+		"Instance Status",
+		"IAM Roles",
+		"Finding",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Stack Set",
+			"Arn",
+			"Status",
+			"Permission Model",
+			"Auto Deployment",
+			"Execution Role",
+			"Target Account",
+			"Target Region",
+			"Target OU",
+			"Instance Status",
+			"IAM Roles",
+			"Finding",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Stack Set",
+			"Permission Model",
+			"Target Account",
+			"Target Region",
+			"Target OU",
+			"IAM Roles",
+			"Finding",
+		}
+	}
+
+	// Table rows, one per stack instance. Stack sets without instances get a row of their own.
+	var flagged int
+	targetAccounts := make(map[string]bool)
+	for _, stackSet := range m.StackSets {
+		instances := stackSet.Instances
+		if len(instances) == 0 {
+			instances = []StackSetInstance{{Account: "-", Region: "-", OrganizationalUnit: "-", Status: "-"}}
+		}
+		for _, instance := range instances {
+			finding := m.stackSetInstanceFinding(stackSet, instance)
+			if finding != "" {
+				flagged++
+				finding = magenta(finding)
+			}
+			if instance.Account != "-" {
+				targetAccounts[instance.Account] = true
+			}
+			m.output.Body = append(
+				m.output.Body,
+				[]string{
+					aws.ToString(m.Caller.Account),
+					stackSet.Region,
+					stackSet.Name,
+					stackSet.Arn,
+					stackSet.Status,
+					stackSet.PermissionModel,
+					fmt.Sprintf("%t", stackSet.AutoDeployment),
+					stackSetColumn(stackSet.ExecutionRole),
+					instance.Account,
+					instance.Region,
+					stackSetColumn(instance.OrganizationalUnit),
+					instance.Status,
+					stackSetColumn(strings.Join(stackSet.IAMRoles, ", ")),
+					finding,
+				},
+			)
+		}
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     "stacksets-templates",
+			Contents: m.writeLoot(),
+		})
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d stack sets found, deploying to %d accounts. %d stack instances deploy IAM roles.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.StackSets), len(targetAccounts), flagged)
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No stack sets found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *StackSetsModule) Receiver(receiver chan StackSet, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.StackSets = append(m.StackSets, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *StackSetsModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan StackSet) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("cloudformation", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getStackSetsPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *StackSetsModule) getStackSetsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan StackSet) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	summaries, err := sdk.CachedCloudFormationListStackSets(m.CloudFormationClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, summary := range summaries {
+		name := aws.ToString(summary.StackSetName)
+		stackSet := StackSet{
+			Region:          r,
+			Name:            name,
+			Status:          string(summary.Status),
+			PermissionModel: string(summary.PermissionModel),
+		}
+
+		details, err := sdk.CachedCloudFormationDescribeStackSet(m.CloudFormationClient, aws.ToString(m.Caller.Account), r, name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		} else {
+			stackSet.Arn = aws.ToString(details.StackSetARN)
+			stackSet.ExecutionRole = aws.ToString(details.ExecutionRoleName)
+			if details.AutoDeployment != nil {
+				stackSet.AutoDeployment = aws.ToBool(details.AutoDeployment.Enabled)
+			}
+			stackSet.IAMRoles = stackSetTemplateIAMRoles(aws.ToString(details.TemplateBody))
+		}
+
+		instances, err := sdk.CachedCloudFormationListStackSetInstances(m.CloudFormationClient, aws.ToString(m.Caller.Account), r, name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+		for _, instance := range instances {
+			stackSet.Instances = append(stackSet.Instances, StackSetInstance{
+				Account:            aws.ToString(instance.Account),
+				Region:             aws.ToString(instance.Region),
+				OrganizationalUnit: aws.ToString(instance.OrganizationalUnitId),
+				Status:             string(instance.Status),
+			})
+		}
+		sort.Slice(stackSet.Instances, func(i, j int) bool {
+			if stackSet.Instances[i].Account != stackSet.Instances[j].Account {
+				return stackSet.Instances[i].Account < stackSet.Instances[j].Account
+			}
+			return stackSet.Instances[i].Region < stackSet.Instances[j].Region
+		})
+
+		dataReceiver <- stackSet
+	}
+}
+
+// stackSetInstanceFinding flags instances whose template deploys IAM roles. Whoever can update the stack set's
+// template can deploy a backdoor role into every target account.
+func (m *StackSetsModule) stackSetInstanceFinding(stackSet StackSet, instance StackSetInstance) string {
+	if len(stackSet.IAMRoles) == 0 || instance.Account == "-" {
+		return ""
+	}
+	if instance.Account != aws.ToString(m.Caller.Account) {
+		return "Deploys IAM roles cross-account"
+	}
+	return "Deploys IAM roles"
+}
+
+// stackSetTemplateIAMRoles returns the logical IDs of the AWS::IAM::Role resources of a JSON or YAML template
+func stackSetTemplateIAMRoles(template string) []string {
+	var roles []string
+	var parsed struct {
+		Resources map[string]struct {
+			Type string
+		}
+	}
+	if err := json.Unmarshal([]byte(template), &parsed); err == nil {
+		for logicalID, resource := range parsed.Resources {
+			if resource.Type == "AWS::IAM::Role" {
+				roles = append(roles, logicalID)
+			}
+		}
+		sort.Strings(roles)
+		return roles
+	}
+
+	// YAML templates are read line by line, a YAML parser chokes on the short form intrinsic functions like !Ref. The
+	// logical IDs are the keys one level below Resources, their Type is indented below them.
+	inResources := false
+	resourceIndent := -1
+	var logicalID string
+	for _, line := range strings.Split(template, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if indent == 0 {
+			inResources = strings.HasPrefix(trimmed, "Resources:")
+			resourceIndent = -1
+			continue
+		}
+		if !inResources {
+			continue
+		}
+		key, value, _ := strings.Cut(trimmed, ":")
+		key = strings.Trim(key, `"'`)
+		if resourceIndent == -1 || indent == resourceIndent {
+			resourceIndent = indent
+			logicalID = key
+			continue
+		}
+		if key == "Type" && strings.Trim(strings.TrimSpace(value), `"'`) == "AWS::IAM::Role" && !internal.Contains(logicalID, roles) {
+			roles = append(roles, logicalID)
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+func stackSetColumn(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func (m *StackSetsModule) writeLoot() string {
+	var out string
+	out += fmt.Sprintln("#############################################")
+	out += fmt.Sprintln("# Read the templates of the stack sets and the parameters they are deployed with.")
+	out += fmt.Sprintln("# Set the $profile environment variable to the profile you are going to use, e.g. export profile=dev-prod.")
+	out += fmt.Sprintln("#############################################")
+	out += fmt.Sprintln("")
+	for _, stackSet := range m.StackSets {
+		out += fmt.Sprintf("# Stack set: %s (%s)\n", stackSet.Name, stackSet.Region)
+		out += fmt.Sprintf("aws --profile $profile --region %s cloudformation describe-stack-set --stack-set-name %s --query StackSet.TemplateBody --output text\n", stackSet.Region, stackSet.Name)
+		out += fmt.Sprintf("aws --profile $profile --region %s cloudformation describe-stack-set --stack-set-name %s --query StackSet.Parameters\n", stackSet.Region, stackSet.Name)
+		out += fmt.Sprintln("")
+	}
+	return out
+}
@@ -0,0 +1,111 @@
+package aws
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestStackSets(t *testing.T) {
+
+	m := StackSetsModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1", "eu-west-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:           3,
+		WrapTable:            false,
+		CloudFormationClient: &sdk.MockedCloudFormationStackSetsClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+	tmpDir := "."
+
+	m.PrintStackSets(tmpDir, 2)
+
+	if len(m.StackSets) != 2 {
+		t.Fatalf("Expected 2 stack sets, got %d", len(m.StackSets))
+	}
+	expectedRoles := map[string][]string{
+		"org-baseline":    {"SecurityAuditRole"},
+		"logging-buckets": nil,
+	}
+	for _, stackSet := range m.StackSets {
+		if !reflect.DeepEqual(stackSet.IAMRoles, expectedRoles[stackSet.Name]) {
+			t.Errorf("%s: expected IAM roles %v, got %v", stackSet.Name, expectedRoles[stackSet.Name], stackSet.IAMRoles)
+		}
+	}
+
+	// Every instance of org-baseline is in another account
+	expectedFindings := map[string]string{
+		"org-baseline|111111111111|us-east-1":    "Deploys IAM roles cross-account",
+		"org-baseline|222222222222|us-east-1":    "Deploys IAM roles cross-account",
+		"org-baseline|222222222222|eu-west-1":    "Deploys IAM roles cross-account",
+		"logging-buckets|123456789012|us-east-1": "",
+	}
+	var instances int
+	for _, stackSet := range m.StackSets {
+		for _, instance := range stackSet.Instances {
+			instances++
+			key := stackSet.Name + "|" + instance.Account + "|" + instance.Region
+			want, ok := expectedFindings[key]
+			if !ok {
+				t.Errorf("Unexpected stack instance %s", key)
+				continue
+			}
+			if got := m.stackSetInstanceFinding(stackSet, instance); got != want {
+				t.Errorf("%s: expected finding %q, got %q", key, want, got)
+			}
+		}
+	}
+	if instances != len(expectedFindings) {
+		t.Errorf("Expected %d stack instances, got %d", len(expectedFindings), instances)
+	}
+
+	resultsFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/table/stacksets.txt")
+	if _, err := afero.ReadFile(fs, resultsFilePath); err != nil {
+		t.Errorf("Cannot read output file at %s: %s", resultsFilePath, err)
+	}
+	lootFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/loot/stacksets-templates.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	if !strings.Contains(string(lootFile), "cloudformation describe-stack-set --stack-set-name org-baseline --query StackSet.TemplateBody") {
+		t.Errorf("Expected the org-baseline template command in the loot file")
+	}
+}
+
+func TestStackSetTemplateIAMRoles(t *testing.T) {
+	subtests := []struct {
+		template string
+		expected []string
+	}{
+		{
+			template: `{"Resources": {"Role": {"Type": "AWS::IAM::Role"}, "Policy": {"Type": "AWS::IAM::Policy"}}}`,
+			expected: []string{"Role"},
+		},
+		{
+			template: "Resources:\n  Bucket:\n    Type: AWS::S3::Bucket\n",
+		},
+		{
+			template: "Resources:\n  \"Deployer\":\n    DependsOn: Bucket\n    Type: 'AWS::IAM::Role'\n  Admin:\n    Type: AWS::IAM::Role\nOutputs:\n  Role:\n    Value: !Ref Admin\n",
+			expected: []string{"Admin", "Deployer"},
+		},
+	}
+	for _, subtest := range subtests {
+		if got := stackSetTemplateIAMRoles(subtest.template); !reflect.DeepEqual(got, subtest.expected) {
+			t.Errorf("Expected roles %v for %q, got %v", subtest.expected, subtest.template, got)
+		}
+	}
+}
@@ -35,6 +35,9 @@ type TagsModule struct {
 	AWSProfile            string
 	WrapTable             bool
 	MaxResourcesPerRegion int
+	TagKey                string
+	TagValues             []string
+	IncludeUntagged       bool
 
 	// Main module data
 	Tags               []Tag
@@ -109,7 +112,7 @@ func (m *TagsModule) PrintTags(outputDirectory string, verbosity int) {
 		"Region",
 		"Type",
 		//"Name",
-		//"Resource Arn",
+		"Resource Arn",
 		"Key",
 		"Value",
 	}
@@ -132,7 +135,7 @@ func (m *TagsModule) PrintTags(outputDirectory string, verbosity int) {
 			"Region",
 			"Type",
 			//"Name",
-			//"Resource Arn",
+			"Resource Arn",
 			"Key",
 			"Value",
 		}
@@ -163,7 +166,7 @@ func (m *TagsModule) PrintTags(outputDirectory string, verbosity int) {
 				m.Tags[i].AWSService,
 				m.Tags[i].Region,
 				m.Tags[i].Type,
-				//m.Tags[i].Arn,
+				m.Tags[i].Arn,
 				//m.Tags[i].Name,
 				m.Tags[i].Key,
 				m.Tags[i].Value,
@@ -189,11 +192,16 @@ func (m *TagsModule) PrintTags(outputDirectory string, verbosity int) {
 		})
 		o.PrefixIdentifier = m.AWSProfile
 		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
 		o.WriteFullOutput(o.Table.TableFiles, nil)
 		//m.writeLoot(o.Table.DirectoryName, verbosity, m.AWSProfile)
 		fmt.Printf("[%s][%s] %s tags found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
 		count := m.countUniqueResourcesWithTags()
 		fmt.Printf("[%s][%s] %d unique resources with tags found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), count)
+		if m.IncludeUntagged {
+			fmt.Printf("[%s][%s] %d resources without any tags found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.countUntaggedResources())
+		}
 		if m.MaxResourcesPerRegion != 0 {
 			fmt.Printf("[%s][%s] NOTE: Only looked at %d resources per region. To enum all tags for all resources,\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.MaxResourcesPerRegion)
 			fmt.Printf("[%s][%s] NOTE: run the tags command without the -m/--max-resources-per-region flag set.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
@@ -207,6 +215,9 @@ func (m *TagsModule) PrintTags(outputDirectory string, verbosity int) {
 func (m *TagsModule) countUniqueResourcesWithTags() int {
 	var uniqueResources []string
 	for i := range m.Tags {
+		if m.Tags[i].Key == "" {
+			continue
+		}
 		if !internal.Contains(m.Tags[i].Name, uniqueResources) {
 			uniqueResources = append(uniqueResources, m.Tags[i].Name)
 		}
@@ -215,6 +226,17 @@ func (m *TagsModule) countUniqueResourcesWithTags() int {
 	return len(uniqueResources)
 }
 
+// countUntaggedResources returns the number of resources that were returned with an empty tag list
+func (m *TagsModule) countUntaggedResources() int {
+	var count int
+	for i := range m.Tags {
+		if m.Tags[i].Key == "" {
+			count++
+		}
+	}
+	return count
+}
+
 // TODO: Make summary table
 
 // func (m *TagsModule) createTagsSummary() {
@@ -275,7 +297,8 @@ func (m *TagsModule) getTagsPerRegion(r string, wg *sync.WaitGroup, semaphore ch
 	defer func() {
 		<-semaphore
 	}()
-	// "PaginationMarker" is a control variable used for output continuity, as AWS return the output in pages.
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
 
 	resources, err := m.getResources(r)
 	if err != nil {
@@ -287,6 +310,10 @@ func (m *TagsModule) getTagsPerRegion(r string, wg *sync.WaitGroup, semaphore ch
 		var resourceType string
 		resourceArn := aws.ToString(resource.ResourceARN)
 		parsedArn, err := arn.Parse(resourceArn)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			continue
+		}
 		if parsedArn.Service != "s3" {
 			resourceType = strings.Split(parsedArn.Resource, ":")[0]
 			resourceType = strings.Split(resourceType, "/")[0]
@@ -294,8 +321,18 @@ func (m *TagsModule) getTagsPerRegion(r string, wg *sync.WaitGroup, semaphore ch
 			resourceType = "bucket"
 		}
 		//resourceName := strings.Split(parsedArn.Resource, ":")[]
-		if err != nil {
-			break
+
+		// Resources that once had tags but no longer do come back with an empty tag list. These are
+		// often unmanaged leftovers, so list them with an empty key if the user asked for them.
+		if len(resource.Tags) == 0 && m.IncludeUntagged {
+			dataReceiver <- Tag{
+				AWSService: parsedArn.Service,
+				Arn:        resourceArn,
+				Name:       parsedArn.Resource,
+				Region:     r,
+				Type:       resourceType,
+			}
+			continue
 		}
 
 		for _, tag := range resource.Tags {
@@ -319,41 +356,54 @@ func (m *TagsModule) getTagsPerRegion(r string, wg *sync.WaitGroup, semaphore ch
 }
 
 func (m *TagsModule) getResources(r string) ([]types.ResourceTagMapping, error) {
+	// "PaginationMarker" is a control variable used for output continuity, as AWS return the output in pages.
 	var PaginationControl *string
 	var resources []types.ResourceTagMapping
+	tagFilters := m.getTagFilters()
 
-	// a for loop that accepts user input. If no user input, it will continue to paginate until there are no more pages. If there is user input, it will paginate until the user input is reached.
-
+	// Keep paginating until there are no more pages, or until the user supplied max resources per region is reached.
 	for {
-		if len(resources) < m.MaxResourcesPerRegion || m.MaxResourcesPerRegion == 0 {
-			GetResources, err := m.ResourceGroupsTaggingApiInterface.GetResources(
-				context.TODO(),
-				&resourcegroupstaggingapi.GetResourcesInput{
-					PaginationToken: PaginationControl,
-				},
-				func(o *resourcegroupstaggingapi.Options) {
-					o.Region = r
-				},
-			)
-			if err != nil {
-				m.modLog.Error(err.Error())
-				m.CommandCounter.Error++
-				return resources, err
-			}
-
-			resources = append(resources, GetResources.ResourceTagMappingList...)
+		GetResources, err := m.ResourceGroupsTaggingApiInterface.GetResources(
+			context.TODO(),
+			&resourcegroupstaggingapi.GetResourcesInput{
+				PaginationToken: PaginationControl,
+				// 100 is the most resources the API will return in a single page
+				ResourcesPerPage: aws.Int32(100),
+				TagFilters:       tagFilters,
+			},
+			func(o *resourcegroupstaggingapi.Options) {
+				o.Region = r
+			},
+		)
+		if err != nil {
+			return resources, err
+		}
 
-			if aws.ToString(GetResources.PaginationToken) != "" {
-				PaginationControl = GetResources.PaginationToken
-			} else {
-				PaginationControl = nil
-				break
-			}
+		resources = append(resources, GetResources.ResourceTagMappingList...)
 
-		} else {
-			return resources, nil
+		if m.MaxResourcesPerRegion != 0 && len(resources) >= m.MaxResourcesPerRegion {
+			return resources[:m.MaxResourcesPerRegion], nil
 		}
 
+		// The PaginationToken is an empty string, not nil, when there are no more pages.
+		if aws.ToString(GetResources.PaginationToken) == "" {
+			break
+		}
+		PaginationControl = GetResources.PaginationToken
 	}
 	return resources, nil
 }
+
+// getTagFilters builds the GetResources tag filter from the --tag-key/--tag-value flags. Note that AWS only
+// returns resources that match the filter, so untagged resources are never returned when a filter is set.
+func (m *TagsModule) getTagFilters() []types.TagFilter {
+	if m.TagKey == "" {
+		return nil
+	}
+	return []types.TagFilter{
+		{
+			Key:    aws.String(m.TagKey),
+			Values: m.TagValues,
+		},
+	}
+}
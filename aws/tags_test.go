@@ -92,3 +92,75 @@ func TestTags(t *testing.T) {
 	}
 
 }
+
+// MockedTagsGetResourcesPaginated returns two pages, the second of which contains an untagged resource
+type MockedTagsGetResourcesPaginated struct {
+	TagFilters []types.TagFilter
+}
+
+func (m *MockedTagsGetResourcesPaginated) GetResources(ctx context.Context, params *resourcegroupstaggingapi.GetResourcesInput, optFns ...func(*resourcegroupstaggingapi.Options)) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+	m.TagFilters = params.TagFilters
+	if aws.ToString(params.PaginationToken) == "" {
+		return &resourcegroupstaggingapi.GetResourcesOutput{
+			PaginationToken: aws.String("page2"),
+			ResourceTagMappingList: []types.ResourceTagMapping{
+				{
+					ResourceARN: aws.String("arn:aws:s3:::prod-bucket"),
+					Tags: []types.Tag{
+						{Key: aws.String("environment"), Value: aws.String("prod")},
+					},
+				},
+			},
+		}, nil
+	}
+	return &resourcegroupstaggingapi.GetResourcesOutput{
+		PaginationToken: aws.String(""),
+		ResourceTagMappingList: []types.ResourceTagMapping{
+			{
+				ResourceARN: aws.String("arn:aws:lambda:us-east-1:123456789012:function:shadow-function"),
+				Tags:        []types.Tag{},
+			},
+		},
+	}, nil
+}
+
+func TestTagsPaginationAndUntagged(t *testing.T) {
+	internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	for _, includeUntagged := range []bool{false, true} {
+		client := &MockedTagsGetResourcesPaginated{}
+		m := TagsModule{
+			ResourceGroupsTaggingApiInterface: client,
+			Caller: sts.GetCallerIdentityOutput{
+				Account: aws.String("123456789012"),
+				Arn:     aws.String("arn:aws:iam::123456789012:user/test"),
+			},
+			AWSRegions:      []string{"us-east-1"},
+			Goroutines:      3,
+			AWSProfile:      "test",
+			TagKey:          "environment",
+			TagValues:       []string{"prod"},
+			IncludeUntagged: includeUntagged,
+		}
+		m.PrintTags(".", 2)
+
+		if len(client.TagFilters) != 1 || aws.ToString(client.TagFilters[0].Key) != "environment" || client.TagFilters[0].Values[0] != "prod" {
+			t.Errorf("Expected the tag filter to be passed to GetResources, got %v", client.TagFilters)
+		}
+
+		expected := 1
+		if includeUntagged {
+			expected = 2
+		}
+		if len(m.Tags) != expected {
+			t.Fatalf("includeUntagged=%t: expected %d results, got %d", includeUntagged, expected, len(m.Tags))
+		}
+		if includeUntagged && m.countUntaggedResources() != 1 {
+			t.Errorf("Expected one untagged resource")
+		}
+		if m.countUniqueResourcesWithTags() != 1 {
+			t.Errorf("Expected one resource with tags, got %d", m.countUniqueResourcesWithTags())
+		}
+	}
+}
@@ -0,0 +1,389 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	transferTypes "github.com/aws/aws-sdk-go-v2/service/transfer/types"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type TransferModule struct {
+	// General configuration data
+	TransferClient sdk.TransferClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Servers        []TransferServer
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type TransferServer struct {
+	Region       string
+	ID           string
+	EndpointType string
+	// IdentityProvider is the identity provider type, IdentityProviderDetail the URL, directory or function behind it
+	IdentityProvider       string
+	IdentityProviderDetail string
+	Protocols              []string
+	State                  string
+	Host                   string
+	// Users are only known for SERVICE_MANAGED servers, the other identity providers keep them elsewhere
+	Users    []string
+	Findings []string
+}
+
+const (
+	transferPublicEndpoint    = "Internet-reachable public endpoint"
+	transferInternetFacingVPC = "Internet-facing VPC endpoint"
+	transferPlaintextFTP      = "Plaintext FTP enabled"
+	transferUsernamesFile     = "usernames.txt"
+)
+
+// InternetReachable tells if the server's endpoint can be reached from the internet, either as a public endpoint or as
+// a VPC endpoint with Elastic IPs
+func (s TransferServer) InternetReachable() bool {
+	return internal.Contains(transferPublicEndpoint, s.Findings) || internal.Contains(transferInternetFacingVPC, s.Findings)
+}
+
+func (m *TransferModule) PrintTransfer(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "transfer"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating Transfer Family servers for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan TransferServer)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.Servers, func(i, j int) bool {
+		if m.Servers[i].Region != m.Servers[j].Region {
+			return m.Servers[i].Region < m.Servers[j].Region
+		}
+		return m.Servers[i].ID < m.Servers[j].ID
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Server",
+		"Endpoint",
+		"Identity Provider",
+		"Protocols",
+		"State",
+		"Host",
+		"Users",
+		"Findings",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Server",
+			"Endpoint",
+			"Identity Provider",
+			"Protocols",
+			"State",
+			"Host",
+			"Users",
+			"Findings",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Server",
+			"Endpoint",
+			"Identity Provider",
+			"Protocols",
+			"Host",
+			"Findings",
+		}
+	}
+
+	// Table rows
+	for _, server := range m.Servers {
+		identityProvider := server.IdentityProvider
+		if server.IdentityProviderDetail != "" {
+			identityProvider = fmt.Sprintf("%s (%s)", identityProvider, server.IdentityProviderDetail)
+		}
+		var findings []string
+		for _, finding := range server.Findings {
+			findings = append(findings, magenta(finding))
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				server.Region,
+				server.ID,
+				server.EndpointType,
+				identityProvider,
+				strings.Join(server.Protocols, ", "),
+				server.State,
+				server.Host,
+				strings.Join(server.Users, ", "),
+				strings.Join(findings, "\n"),
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     m.output.CallingModule,
+			Contents: m.writeLoot(),
+		})
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d Transfer Family servers found, %d reachable from the internet.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), m.countInternetReachable())
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No Transfer Family servers found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *TransferModule) countInternetReachable() int {
+	var count int
+	for _, server := range m.Servers {
+		if server.InternetReachable() {
+			count++
+		}
+	}
+	return count
+}
+
+// writeLoot has sftp commands for the known users of each SFTP server and a loop that tries the names in a username
+// list, with the internet-reachable servers first
+func (m *TransferModule) writeLoot() string {
+	var out string
+	out += "#############################################\n"
+	out += "# Connect to the SFTP servers. BatchMode makes sftp fail instead of prompting, so the loop only shows which\n"
+	out += fmt.Sprintf("# names get a password or key prompt. Put the usernames to try in %s.\n", transferUsernamesFile)
+	out += "#############################################\n"
+
+	for _, reachable := range []bool{true, false} {
+		for _, server := range m.Servers {
+			if server.InternetReachable() != reachable || !internal.Contains(string(transferTypes.ProtocolSftp), server.Protocols) {
+				continue
+			}
+			out += fmt.Sprintf("\n# %s in %s, %s endpoint, %s identities", server.ID, server.Region, server.EndpointType, server.IdentityProvider)
+			if !reachable {
+				out += ", only reachable from inside the VPC"
+			}
+			out += "\n"
+			for _, user := range server.Users {
+				out += fmt.Sprintf("sftp -o StrictHostKeyChecking=accept-new %s@%s\n", user, server.Host)
+			}
+			out += fmt.Sprintf("for user in $(cat %s); do echo \"$user: $(sftp -o BatchMode=yes -o ConnectTimeout=5 -o StrictHostKeyChecking=accept-new $user@%s 2>&1 </dev/null | tail -n 1)\"; done\n", transferUsernamesFile, server.Host)
+		}
+	}
+	return out
+}
+
+func (m *TransferModule) Receiver(receiver chan TransferServer, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Servers = append(m.Servers, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *TransferModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan TransferServer) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("transfer", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		wg.Add(1)
+		m.getServersPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *TransferModule) getServersPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan TransferServer) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	servers, err := sdk.CachedTransferListServers(m.TransferClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, listedServer := range servers {
+		serverID := aws.ToString(listedServer.ServerId)
+		server, err := sdk.CachedTransferDescribeServer(m.TransferClient, aws.ToString(m.Caller.Account), r, serverID)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+
+		var users []string
+		if server.IdentityProviderType == transferTypes.IdentityProviderTypeServiceManaged {
+			listedUsers, err := sdk.CachedTransferListUsers(m.TransferClient, aws.ToString(m.Caller.Account), r, serverID)
+			if err != nil {
+				m.modLog.Error(err.Error())
+				m.CommandCounter.Error++
+			}
+			for _, user := range listedUsers {
+				users = append(users, aws.ToString(user.UserName))
+			}
+		}
+
+		var protocols []string
+		for _, protocol := range server.Protocols {
+			protocols = append(protocols, string(protocol))
+		}
+		dataReceiver <- TransferServer{
+			Region:                 r,
+			ID:                     serverID,
+			EndpointType:           string(server.EndpointType),
+			IdentityProvider:       string(server.IdentityProviderType),
+			IdentityProviderDetail: transferIdentityProviderDetail(server.IdentityProviderDetails),
+			Protocols:              protocols,
+			State:                  string(server.State),
+			Host:                   fmt.Sprintf("%s.server.transfer.%s.amazonaws.com", serverID, r),
+			Users:                  users,
+			Findings:               transferServerFindings(server),
+		}
+	}
+}
+
+// transferIdentityProviderDetail returns what authenticates the users of a server that doesn't manage them itself
+func transferIdentityProviderDetail(details *transferTypes.IdentityProviderDetails) string {
+	if details == nil {
+		return ""
+	}
+	switch {
+	case details.Url != nil:
+		return aws.ToString(details.Url)
+	case details.Function != nil:
+		return aws.ToString(details.Function)
+	case details.DirectoryId != nil:
+		return aws.ToString(details.DirectoryId)
+	}
+	return ""
+}
+
+func transferServerFindings(server transferTypes.DescribedServer) []string {
+	var findings []string
+	switch server.EndpointType {
+	case transferTypes.EndpointTypePublic:
+		findings = append(findings, transferPublicEndpoint)
+	case transferTypes.EndpointTypeVpc:
+		// Elastic IPs on the endpoint make a VPC server reachable from the internet, subject to its security groups
+		if server.EndpointDetails != nil && len(server.EndpointDetails.AddressAllocationIds) > 0 {
+			findings = append(findings, transferInternetFacingVPC)
+		}
+	}
+	for _, protocol := range server.Protocols {
+		if protocol == transferTypes.ProtocolFtp {
+			findings = append(findings, transferPlaintextFTP)
+		}
+	}
+	return findings
+}
@@ -0,0 +1,71 @@
+package aws
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestTransfer(t *testing.T) {
+	m := TransferModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:     3,
+		TransferClient: &sdk.MockedTransferClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintTransfer(".", 2)
+
+	expectedFindings := map[string][]string{
+		"s-11111111111111111": {transferPublicEndpoint},
+		"s-22222222222222222": {transferInternetFacingVPC},
+		"s-33333333333333333": nil,
+	}
+	if len(m.Servers) != len(expectedFindings) {
+		t.Fatalf("Expected %d servers, got %d", len(expectedFindings), len(m.Servers))
+	}
+	for _, server := range m.Servers {
+		if !reflect.DeepEqual(server.Findings, expectedFindings[server.ID]) {
+			t.Errorf("%s: expected findings %v, got %v", server.ID, expectedFindings[server.ID], server.Findings)
+		}
+	}
+	if users := m.Servers[0].Users; !reflect.DeepEqual(users, []string{"partner-acme", "reports"}) {
+		t.Errorf("Expected the service managed users of s-11111111111111111, got %v", users)
+	}
+	if detail := m.Servers[1].IdentityProviderDetail; detail != "https://abcdefghij.execute-api.us-east-1.amazonaws.com/prod" {
+		t.Errorf("Expected the API Gateway URL of s-22222222222222222, got %s", detail)
+	}
+
+	lootFilePath := filepath.Join(".", "cloudfox-output/aws/unittesting-123456789012/loot/transfer.txt")
+	lootFile, err := afero.ReadFile(fs, lootFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read loot file at %s: %s", lootFilePath, err)
+	}
+	expectedCommands := []string{
+		"sftp -o StrictHostKeyChecking=accept-new partner-acme@s-11111111111111111.server.transfer.us-east-1.amazonaws.com",
+		"$user@s-22222222222222222.server.transfer.us-east-1.amazonaws.com",
+	}
+	for _, expected := range expectedCommands {
+		if !strings.Contains(string(lootFile), expected) {
+			t.Errorf("Expected %s to be in the loot file", expected)
+		}
+	}
+	// s-33333333333333333 only speaks FTPS
+	if strings.Contains(string(lootFile), "s-33333333333333333") {
+		t.Errorf("Did not expect sftp commands for a server without SFTP")
+	}
+}
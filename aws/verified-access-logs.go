@@ -0,0 +1,842 @@
+package aws
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type VerifiedAccessLogsModule struct {
+	// General configuration data
+	EC2Client            sdk.AWSEC2VerifiedAccessClientInterface
+	CloudWatchLogsClient sdk.CloudWatchLogsClientInterface
+	S3Client             sdk.AWSS3ObjectsClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+	// Days is how far back the logs are read. The last day is compared with the days before it.
+	Days int
+
+	// Main module data
+	Endpoints      []VerifiedAccessEndpointLogging
+	AccessPatterns []VerifiedAccessPattern
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+// VerifiedAccessEndpointLogging is the logging configuration of the instance an endpoint belongs to. Instances without
+// endpoints get a row of their own.
+type VerifiedAccessEndpointLogging struct {
+	Region             string
+	InstanceID         string
+	InstanceName       string
+	EndpointID         string
+	Domain             string
+	CloudWatchLogGroup string
+	S3Bucket           string
+	S3Prefix           string
+	FirehoseStream     string
+	TrustContext       bool
+	Finding            string
+}
+
+// VerifiedAccessPattern is the access of one user to one resource over the days that were read
+type VerifiedAccessPattern struct {
+	Region     string
+	InstanceID string
+	User       string
+	Resource   string
+	Requests   int
+	Denied     int
+	// RecentCountries and RecentSourceIPs are where the requests of the last day came from
+	RecentCountries []string
+	RecentSourceIPs []string
+	FirstSeen       time.Time
+	LastSeen        time.Time
+	Findings        []string
+}
+
+// verifiedAccessLogEvent is the part of an OCSF access log entry the patterns are built from
+type verifiedAccessLogEvent struct {
+	Time     time.Time
+	User     string
+	Resource string
+	IP       string
+	Country  string
+	Denied   bool
+}
+
+const (
+	verifiedAccessDefaultDays = 7
+	// verifiedAccessRecentWindow is compared with the baseline of the days before it
+	verifiedAccessRecentWindow = 24 * time.Hour
+	// Reading logs can take long on busy instances, so we stop after this many events or S3 objects per instance
+	verifiedAccessMaxEvents  = 10000
+	verifiedAccessMaxObjects = 100
+	verifiedAccessNoUser     = "-"
+)
+
+func (m *VerifiedAccessLogsModule) PrintVerifiedAccessLogs(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "verified-access-logs"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+	if m.Days <= 0 {
+		m.Days = verifiedAccessDefaultDays
+	}
+
+	fmt.Printf("[%s][%s] Enumerating Verified Access logging and the last %d days of access logs for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.Days, aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan VerifiedAccessEndpointLogging)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.Endpoints, func(i, j int) bool {
+		if m.Endpoints[i].Region != m.Endpoints[j].Region {
+			return m.Endpoints[i].Region < m.Endpoints[j].Region
+		}
+		if m.Endpoints[i].InstanceID != m.Endpoints[j].InstanceID {
+			return m.Endpoints[i].InstanceID < m.Endpoints[j].InstanceID
+		}
+		return m.Endpoints[i].Domain < m.Endpoints[j].Domain
+	})
+
+	m.readAccessLogs()
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Instance",
+		"User",
+		"Resource",
+		"Requests",
+		"Denied",
+		"Recent Countries",
+		"Recent Source IPs",
+		"First Seen",
+		"Last Seen",
+		"Finding",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Instance",
+			"User",
+			"Resource",
+			"Requests",
+			"Denied",
+			"Recent Countries",
+			"Recent Source IPs",
+			"First Seen",
+			"Last Seen",
+			"Finding",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"User",
+			"Resource",
+			"Requests",
+			"Denied",
+			"Recent Countries",
+			"Last Seen",
+			"Finding",
+		}
+	}
+
+	// Table rows
+	var flagged int
+	for _, pattern := range m.AccessPatterns {
+		if len(pattern.Findings) > 0 {
+			flagged++
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				pattern.Region,
+				pattern.InstanceID,
+				pattern.User,
+				pattern.Resource,
+				fmt.Sprintf("%d", pattern.Requests),
+				fmt.Sprintf("%d", pattern.Denied),
+				strings.Join(pattern.RecentCountries, ", "),
+				strings.Join(pattern.RecentSourceIPs, ", "),
+				pattern.FirstSeen.UTC().Format(time.RFC3339),
+				pattern.LastSeen.UTC().Format(time.RFC3339),
+				strings.Join(pattern.Findings, ", "),
+			},
+		)
+	}
+
+	loggingHeader := []string{
+		"Account",
+		"Region",
+		"Instance",
+		"Endpoint",
+		"Domain",
+		"CloudWatch Log Group",
+		"S3 Destination",
+		"Firehose Stream",
+		"Trust Context",
+		"Finding",
+	}
+	var loggingBody [][]string
+	var disabled int
+	for _, endpoint := range m.Endpoints {
+		if endpoint.Finding != "" {
+			disabled++
+		}
+		s3Destination := "-"
+		if endpoint.S3Bucket != "" {
+			s3Destination = fmt.Sprintf("s3://%s/%s", endpoint.S3Bucket, endpoint.S3Prefix)
+		}
+		loggingBody = append(loggingBody, []string{
+			aws.ToString(m.Caller.Account),
+			endpoint.Region,
+			verifiedAccessInstanceLabel(endpoint.InstanceID, endpoint.InstanceName),
+			endpoint.EndpointID,
+			endpoint.Domain,
+			verifiedAccessColumn(endpoint.CloudWatchLogGroup),
+			s3Destination,
+			verifiedAccessColumn(endpoint.FirehoseStream),
+			fmt.Sprintf("%t", endpoint.TrustContext),
+			endpoint.Finding,
+		})
+	}
+
+	if len(m.output.Body) > 0 || len(loggingBody) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		if len(m.output.Body) > 0 {
+			o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+				Header:    m.output.Headers,
+				Body:      m.output.Body,
+				TableCols: tableCols,
+				Name:      m.output.CallingModule,
+			})
+		}
+		if len(loggingBody) > 0 {
+			o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+				Header: loggingHeader,
+				Body:   loggingBody,
+				Name:   "verified-access-logging",
+			})
+		}
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		if loot := m.writeLoot(); loot != "" {
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     "verified-access-logs-commands",
+				Contents: loot,
+			})
+		}
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d Verified Access endpoints found, %d of them without logging.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(loggingBody), disabled)
+		fmt.Printf("[%s][%s] %d user and resource pairs found in the access logs, %d of them flagged.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), flagged)
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No Verified Access instances found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *VerifiedAccessLogsModule) Receiver(receiver chan VerifiedAccessEndpointLogging, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Endpoints = append(m.Endpoints, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *VerifiedAccessLogsModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan VerifiedAccessEndpointLogging) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("ec2", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getLoggingPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *VerifiedAccessLogsModule) getLoggingPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan VerifiedAccessEndpointLogging) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	instances, err := sdk.CachedEC2DescribeVerifiedAccessInstances(m.EC2Client, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	if len(instances) == 0 {
+		return
+	}
+	loggingConfigurations, err := sdk.CachedEC2DescribeVerifiedAccessInstanceLoggingConfigurations(m.EC2Client, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	accessLogs := make(map[string]*ec2Types.VerifiedAccessLogs)
+	for _, loggingConfiguration := range loggingConfigurations {
+		accessLogs[aws.ToString(loggingConfiguration.VerifiedAccessInstanceId)] = loggingConfiguration.AccessLogs
+	}
+	endpoints, err := sdk.CachedEC2DescribeVerifiedAccessEndpoints(m.EC2Client, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	endpointsByInstance := make(map[string][]ec2Types.VerifiedAccessEndpoint)
+	for _, endpoint := range endpoints {
+		instanceID := aws.ToString(endpoint.VerifiedAccessInstanceId)
+		endpointsByInstance[instanceID] = append(endpointsByInstance[instanceID], endpoint)
+	}
+
+	for _, instance := range instances {
+		instanceID := aws.ToString(instance.VerifiedAccessInstanceId)
+		logging := analyzeVerifiedAccessLogging(accessLogs[instanceID])
+		logging.Region = r
+		logging.InstanceID = instanceID
+		logging.InstanceName = verifiedAccessNameTag(instance.Tags)
+		if len(endpointsByInstance[instanceID]) == 0 {
+			logging.EndpointID = "-"
+			logging.Domain = "-"
+			dataReceiver <- logging
+			continue
+		}
+		for _, endpoint := range endpointsByInstance[instanceID] {
+			endpointLogging := logging
+			endpointLogging.EndpointID = aws.ToString(endpoint.VerifiedAccessEndpointId)
+			endpointLogging.Domain = aws.ToString(endpoint.ApplicationDomain)
+			dataReceiver <- endpointLogging
+		}
+	}
+}
+
+// analyzeVerifiedAccessLogging reads the enabled destinations of an instance. Logging is configured per instance, so
+// all of its endpoints share the result.
+func analyzeVerifiedAccessLogging(accessLogs *ec2Types.VerifiedAccessLogs) VerifiedAccessEndpointLogging {
+	var logging VerifiedAccessEndpointLogging
+	if accessLogs == nil {
+		logging.Finding = "Logging disabled"
+		return logging
+	}
+	if accessLogs.CloudWatchLogs != nil && aws.ToBool(accessLogs.CloudWatchLogs.Enabled) {
+		logging.CloudWatchLogGroup = aws.ToString(accessLogs.CloudWatchLogs.LogGroup)
+	}
+	if accessLogs.S3 != nil && aws.ToBool(accessLogs.S3.Enabled) {
+		logging.S3Bucket = aws.ToString(accessLogs.S3.BucketName)
+		logging.S3Prefix = aws.ToString(accessLogs.S3.Prefix)
+	}
+	if accessLogs.KinesisDataFirehose != nil && aws.ToBool(accessLogs.KinesisDataFirehose.Enabled) {
+		logging.FirehoseStream = aws.ToString(accessLogs.KinesisDataFirehose.DeliveryStream)
+	}
+	logging.TrustContext = aws.ToBool(accessLogs.IncludeTrustContext)
+	if logging.CloudWatchLogGroup == "" && logging.S3Bucket == "" && logging.FirehoseStream == "" {
+		logging.Finding = "Logging disabled"
+	}
+	return logging
+}
+
+func verifiedAccessNameTag(tags []ec2Types.Tag) string {
+	for _, tag := range tags {
+		if aws.ToString(tag.Key) == "Name" {
+			return aws.ToString(tag.Value)
+		}
+	}
+	return ""
+}
+
+func verifiedAccessColumn(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func verifiedAccessInstanceLabel(instanceID string, name string) string {
+	if name == "" {
+		return instanceID
+	}
+	return fmt.Sprintf("%s (%s)", instanceID, name)
+}
+
+// readAccessLogs reads the logs every instance delivers to CloudWatch Logs or S3 and builds the access patterns. Logs
+// that only go to Firehose can't be read back.
+func (m *VerifiedAccessLogsModule) readAccessLogs() {
+	seen := make(map[string]bool)
+	startTime := time.Now().AddDate(0, 0, -m.Days)
+	for _, instance := range m.Endpoints {
+		key := instance.Region + "|" + instance.InstanceID
+		if seen[key] || (instance.CloudWatchLogGroup == "" && instance.S3Bucket == "") {
+			continue
+		}
+		seen[key] = true
+
+		// Instances that log to both destinations have the same entries in each of them
+		var lines []string
+		seenLines := make(map[string]bool)
+		for _, line := range append(m.readCloudWatchLogs(instance, startTime), m.readS3Logs(instance, startTime)...) {
+			if !seenLines[line] {
+				seenLines[line] = true
+				lines = append(lines, line)
+			}
+		}
+		var events []verifiedAccessLogEvent
+		for _, line := range lines {
+			event, ok := parseVerifiedAccessLogEvent(line)
+			if !ok || event.Time.Before(startTime) {
+				continue
+			}
+			events = append(events, event)
+		}
+		m.AccessPatterns = append(m.AccessPatterns, analyzeVerifiedAccessEvents(instance.Region, instance.InstanceID, events, time.Now())...)
+	}
+}
+
+// readCloudWatchLogs returns the raw log entries of an instance. The events are deliberately not cached, so they never
+// end up in the on-disk cache.
+func (m *VerifiedAccessLogsModule) readCloudWatchLogs(instance VerifiedAccessEndpointLogging, startTime time.Time) []string {
+	if instance.CloudWatchLogGroup == "" {
+		return nil
+	}
+	var lines []string
+	var PaginationControl *string
+	for {
+		FilterLogEvents, err := m.CloudWatchLogsClient.FilterLogEvents(
+			context.TODO(),
+			&cloudwatchlogs.FilterLogEventsInput{
+				LogGroupName: aws.String(instance.CloudWatchLogGroup),
+				StartTime:    aws.Int64(startTime.UnixMilli()),
+				NextToken:    PaginationControl,
+			},
+			func(o *cloudwatchlogs.Options) {
+				o.Region = instance.Region
+			},
+		)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			return lines
+		}
+		for _, event := range FilterLogEvents.Events {
+			lines = append(lines, strings.TrimSpace(aws.ToString(event.Message)))
+			if len(lines) >= verifiedAccessMaxEvents {
+				m.modLog.Warnf("Stopped reading %s after %d events", instance.CloudWatchLogGroup, verifiedAccessMaxEvents)
+				return lines
+			}
+		}
+
+		// The "NextToken" value is nil when there's no more data to return.
+		if FilterLogEvents.NextToken == nil {
+			break
+		}
+		PaginationControl = FilterLogEvents.NextToken
+	}
+	return lines
+}
+
+// verifiedAccessS3Prefix is where Verified Access delivers the logs of a region, below the prefix of the destination
+func verifiedAccessS3Prefix(prefix string, accountID string, region string) string {
+	return path.Join(prefix, "AWSLogs", accountID, "verified_access", region) + "/"
+}
+
+// readS3Logs returns the raw log entries of the objects delivered since startTime
+func (m *VerifiedAccessLogsModule) readS3Logs(instance VerifiedAccessEndpointLogging, startTime time.Time) []string {
+	if instance.S3Bucket == "" {
+		return nil
+	}
+	var keys []string
+	var PaginationControl *string
+	for len(keys) < verifiedAccessMaxObjects {
+		ListObjectsV2, err := m.S3Client.ListObjectsV2(
+			context.TODO(),
+			&s3.ListObjectsV2Input{
+				Bucket:            aws.String(instance.S3Bucket),
+				Prefix:            aws.String(verifiedAccessS3Prefix(instance.S3Prefix, aws.ToString(m.Caller.Account), instance.Region)),
+				ContinuationToken: PaginationControl,
+			},
+			func(o *s3.Options) {
+				o.Region = instance.Region
+			},
+		)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			break
+		}
+		for _, object := range ListObjectsV2.Contents {
+			// Objects of other instances in the same destination are skipped by their name
+			if object.LastModified == nil || object.LastModified.Before(startTime) || !strings.Contains(aws.ToString(object.Key), instance.InstanceID) {
+				continue
+			}
+			keys = append(keys, aws.ToString(object.Key))
+		}
+
+		if ListObjectsV2.NextContinuationToken == nil {
+			break
+		}
+		PaginationControl = ListObjectsV2.NextContinuationToken
+	}
+	if len(keys) > verifiedAccessMaxObjects {
+		m.modLog.Warnf("Only reading the first %d log objects of s3://%s", verifiedAccessMaxObjects, instance.S3Bucket)
+		keys = keys[:verifiedAccessMaxObjects]
+	}
+
+	var lines []string
+	for _, key := range keys {
+		objectLines, err := m.readS3LogObject(instance.S3Bucket, key, instance.Region)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		lines = append(lines, objectLines...)
+		if len(lines) >= verifiedAccessMaxEvents {
+			m.modLog.Warnf("Stopped reading s3://%s after %d events", instance.S3Bucket, verifiedAccessMaxEvents)
+			return lines[:verifiedAccessMaxEvents]
+		}
+	}
+	return lines
+}
+
+func (m *VerifiedAccessLogsModule) readS3LogObject(bucket string, key string, region string) ([]string, error) {
+	GetObject, err := m.S3Client.GetObject(
+		context.TODO(),
+		&s3.GetObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		},
+		func(o *s3.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer GetObject.Body.Close()
+
+	var body io.Reader = GetObject.Body
+	if strings.HasSuffix(key, ".gz") {
+		gz, err := gzip.NewReader(GetObject.Body)
+		if err != nil {
+			return nil, fmt.Errorf("s3://%s/%s: %w", bucket, key, err)
+		}
+		defer gz.Close()
+		body = gz
+	}
+	var lines []string
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// verifiedAccessOCSFEvent has the fields we use of the OCSF access activity events Verified Access logs
+type verifiedAccessOCSFEvent struct {
+	Time         int64  `json:"time"`
+	ActivityName string `json:"activity_name"`
+	Actor        struct {
+		User struct {
+			EmailAddr string `json:"email_addr"`
+			Name      string `json:"name"`
+			UID       string `json:"uid"`
+		} `json:"user"`
+	} `json:"actor"`
+	SrcEndpoint struct {
+		IP       string `json:"ip"`
+		Location struct {
+			Country string `json:"country"`
+		} `json:"location"`
+	} `json:"src_endpoint"`
+	HTTPRequest struct {
+		URL struct {
+			Hostname string `json:"hostname"`
+		} `json:"url"`
+	} `json:"http_request"`
+	HTTPResponse struct {
+		Code int `json:"code"`
+	} `json:"http_response"`
+}
+
+func parseVerifiedAccessLogEvent(line string) (verifiedAccessLogEvent, bool) {
+	var raw verifiedAccessOCSFEvent
+	if err := json.Unmarshal([]byte(line), &raw); err != nil || raw.Time == 0 {
+		return verifiedAccessLogEvent{}, false
+	}
+	event := verifiedAccessLogEvent{
+		Time:     time.UnixMilli(raw.Time),
+		User:     raw.Actor.User.EmailAddr,
+		Resource: raw.HTTPRequest.URL.Hostname,
+		IP:       raw.SrcEndpoint.IP,
+		Country:  raw.SrcEndpoint.Location.Country,
+	}
+	if event.User == "" {
+		event.User = raw.Actor.User.Name
+	}
+	if event.User == "" {
+		event.User = raw.Actor.User.UID
+	}
+	// Requests denied before the user authenticated have no identity
+	if event.User == "" {
+		event.User = verifiedAccessNoUser
+	}
+	if event.Resource == "" {
+		event.Resource = "-"
+	}
+	activity := strings.ToLower(raw.ActivityName)
+	event.Denied = strings.Contains(activity, "deny") || strings.Contains(activity, "denied") || raw.HTTPResponse.Code == 403
+	return event, true
+}
+
+// verifiedAccessNetwork is the /16 of IPv4 and the /48 of IPv6 addresses, used as the location when the logs have no
+// country
+func verifiedAccessNetwork(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return (&net.IPNet{IP: v4.Mask(net.CIDRMask(16, 32)), Mask: net.CIDRMask(16, 32)}).String()
+	}
+	return (&net.IPNet{IP: parsed.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
+
+// verifiedAccessLocation is the country of an event, or its network when the logs have no country
+func verifiedAccessLocation(event verifiedAccessLogEvent) string {
+	if event.Country != "" {
+		return event.Country
+	}
+	return verifiedAccessNetwork(event.IP)
+}
+
+// analyzeVerifiedAccessEvents groups the events by user and resource and compares the last day with the days before it.
+// A user needs activity before the last day to be compared at all, so the first days after logging was turned on don't
+// flag everyone.
+func analyzeVerifiedAccessEvents(region string, instanceID string, events []verifiedAccessLogEvent, now time.Time) []VerifiedAccessPattern {
+	recentStart := now.Add(-verifiedAccessRecentWindow)
+	baselineResources := make(map[string]map[string]bool)
+	baselineLocations := make(map[string]map[string]bool)
+	for _, event := range events {
+		if !event.Time.Before(recentStart) || event.Denied {
+			continue
+		}
+		if baselineResources[event.User] == nil {
+			baselineResources[event.User] = make(map[string]bool)
+			baselineLocations[event.User] = make(map[string]bool)
+		}
+		baselineResources[event.User][event.Resource] = true
+		if location := verifiedAccessLocation(event); location != "" {
+			baselineLocations[event.User][location] = true
+		}
+	}
+
+	patterns := make(map[string]*VerifiedAccessPattern)
+	findings := make(map[string]map[string]bool)
+	var keys []string
+	for _, event := range events {
+		key := event.User + "|" + event.Resource
+		pattern, ok := patterns[key]
+		if !ok {
+			pattern = &VerifiedAccessPattern{
+				Region:     region,
+				InstanceID: instanceID,
+				User:       event.User,
+				Resource:   event.Resource,
+				FirstSeen:  event.Time,
+				LastSeen:   event.Time,
+			}
+			patterns[key] = pattern
+			findings[key] = make(map[string]bool)
+			keys = append(keys, key)
+		}
+		pattern.Requests++
+		if event.Time.Before(pattern.FirstSeen) {
+			pattern.FirstSeen = event.Time
+		}
+		if event.Time.After(pattern.LastSeen) {
+			pattern.LastSeen = event.Time
+		}
+		if event.Denied {
+			pattern.Denied++
+			findings[key]["Denied requests"] = true
+		}
+		if event.Time.Before(recentStart) {
+			continue
+		}
+		if event.Country != "" && !internal.Contains(event.Country, pattern.RecentCountries) {
+			pattern.RecentCountries = append(pattern.RecentCountries, event.Country)
+		}
+		if event.IP != "" && !internal.Contains(event.IP, pattern.RecentSourceIPs) {
+			pattern.RecentSourceIPs = append(pattern.RecentSourceIPs, event.IP)
+		}
+		if event.User == verifiedAccessNoUser || baselineResources[event.User] == nil || event.Denied {
+			continue
+		}
+		if !baselineResources[event.User][event.Resource] {
+			findings[key]["New resource for user"] = true
+		}
+		if location := verifiedAccessLocation(event); location != "" && !baselineLocations[event.User][location] {
+			findings[key]["Unusual location"] = true
+		}
+	}
+
+	var result []VerifiedAccessPattern
+	sort.Strings(keys)
+	for _, key := range keys {
+		pattern := patterns[key]
+		// Keep the findings in the same order for every pattern
+		for _, finding := range []string{"New resource for user", "Unusual location", "Denied requests"} {
+			if findings[key][finding] {
+				pattern.Findings = append(pattern.Findings, finding)
+			}
+		}
+		sort.Strings(pattern.RecentCountries)
+		sort.Strings(pattern.RecentSourceIPs)
+		result = append(result, *pattern)
+	}
+	return result
+}
+
+func (m *VerifiedAccessLogsModule) writeLoot() string {
+	var out string
+	seen := make(map[string]bool)
+	for _, instance := range m.Endpoints {
+		key := instance.Region + "|" + instance.InstanceID
+		if seen[key] || (instance.CloudWatchLogGroup == "" && instance.S3Bucket == "") {
+			continue
+		}
+		seen[key] = true
+		if out == "" {
+			out += fmt.Sprintln("#############################################")
+			out += fmt.Sprintln("# Read the Verified Access logs of flagged users.")
+			out += fmt.Sprintln("# Set the $profile environment variable to the profile you are going to use, e.g. export profile=dev-prod.")
+			out += fmt.Sprintln("#############################################")
+			out += fmt.Sprintln("")
+		}
+		out += fmt.Sprintf("# Instance: %s (%s)\n", verifiedAccessInstanceLabel(instance.InstanceID, instance.InstanceName), instance.Region)
+		if instance.CloudWatchLogGroup != "" {
+			var users []string
+			for _, pattern := range m.AccessPatterns {
+				if pattern.Region == instance.Region && pattern.InstanceID == instance.InstanceID && len(pattern.Findings) > 0 && pattern.User != verifiedAccessNoUser && !internal.Contains(pattern.User, users) {
+					users = append(users, pattern.User)
+				}
+			}
+			for _, user := range users {
+				out += fmt.Sprintf("aws --profile $profile --region %s logs filter-log-events --log-group-name %s --start-time $(date -d '-%d days' +%%s000) --filter-pattern '{ $.actor.user.email_addr = \"%s\" }' --query 'events[].message' --output text\n", instance.Region, instance.CloudWatchLogGroup, m.Days, user)
+			}
+		}
+		if instance.S3Bucket != "" {
+			out += fmt.Sprintf("aws --profile $profile --region %s s3 sync s3://%s/%s ./verified-access-logs/%s/\n", instance.Region, instance.S3Bucket, verifiedAccessS3Prefix(instance.S3Prefix, aws.ToString(m.Caller.Account), instance.Region), instance.InstanceID)
+		}
+		out += fmt.Sprintln("")
+	}
+	return out
+}
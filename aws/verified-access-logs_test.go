@@ -0,0 +1,85 @@
+package aws
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestVerifiedAccessLogs(t *testing.T) {
+
+	m := VerifiedAccessLogsModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1", "eu-west-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:           3,
+		WrapTable:            false,
+		EC2Client:            &sdk.MockedEC2VerifiedAccessClient{},
+		CloudWatchLogsClient: &sdk.MockedCloudWatchLogsClient{},
+		S3Client:             &sdk.MockedS3ObjectsClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+	tmpDir := "."
+
+	m.PrintVerifiedAccessLogs(tmpDir, 2)
+
+	expectedLogging := map[string]string{
+		"vae-0wiki":    "",
+		"vae-0jenkins": "",
+		"vae-0admin":   "Logging disabled",
+	}
+	if len(m.Endpoints) != len(expectedLogging) {
+		t.Fatalf("Expected %d endpoints, got %d", len(expectedLogging), len(m.Endpoints))
+	}
+	for _, endpoint := range m.Endpoints {
+		want, ok := expectedLogging[endpoint.EndpointID]
+		if !ok {
+			t.Errorf("Unexpected endpoint %s", endpoint.EndpointID)
+			continue
+		}
+		if endpoint.Finding != want {
+			t.Errorf("%s: expected finding %q, got %q", endpoint.EndpointID, want, endpoint.Finding)
+		}
+	}
+
+	// carol's access only comes from the S3 destination
+	expectedFindings := map[string]string{
+		"alice@example.com|wiki.example.com":      "",
+		"alice@example.com|jenkins.example.com":   "New resource for user",
+		"bob@example.com|jenkins.example.com":     "Unusual location",
+		"mallory@example.com|jenkins.example.com": "Denied requests",
+		"carol@example.com|wiki.example.com":      "",
+	}
+	if len(m.AccessPatterns) != len(expectedFindings) {
+		t.Fatalf("Expected %d access patterns, got %d", len(expectedFindings), len(m.AccessPatterns))
+	}
+	for _, pattern := range m.AccessPatterns {
+		key := pattern.User + "|" + pattern.Resource
+		want, ok := expectedFindings[key]
+		if !ok {
+			t.Errorf("Unexpected access pattern %s", key)
+			continue
+		}
+		if got := strings.Join(pattern.Findings, ", "); got != want {
+			t.Errorf("%s: expected findings %q, got %q", key, want, got)
+		}
+	}
+
+	for _, name := range []string{"verified-access-logs", "verified-access-logging"} {
+		resultsFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/table", name+".txt")
+		if _, err := afero.ReadFile(fs, resultsFilePath); err != nil {
+			t.Errorf("Cannot read output file at %s: %s", resultsFilePath, err)
+		}
+	}
+}
@@ -0,0 +1,425 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type VerifiedPermissionsTemplatesModule struct {
+	// General configuration data
+	VerifiedPermissionsClient sdk.VerifiedPermissionsClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Templates      []VerifiedPermissionsTemplate
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type VerifiedPermissionsTemplate struct {
+	Region         string
+	PolicyStoreID  string
+	ID             string
+	Description    string
+	Statement      string
+	Analysis       cedarTemplateAnalysis
+	LinkedPolicies []sdk.VerifiedPermissionsLinkedPolicy
+	Risk           string
+	Finding        string
+}
+
+// cedarTemplateAnalysis is what matters about a template when deciding whether linking it to yourself is worth it
+type cedarTemplateAnalysis struct {
+	Effect        string
+	PrincipalSlot bool
+	ResourceSlot  bool
+	Conditions    bool
+	// Nil when the template applies to every action
+	Actions           []string
+	PrivilegedActions []string
+}
+
+var (
+	cedarCommentRegex       = regexp.MustCompile(`//[^\n]*`)
+	cedarHeadRegex          = regexp.MustCompile(`(?s)\b(permit|forbid)\s*\(([^)]*)\)(.*)`)
+	cedarConditionRegex     = regexp.MustCompile(`\b(when|unless)\s*\{`)
+	cedarPrincipalSlotRegex = regexp.MustCompile(`\bprincipal\s*(==|in)\s*\?principal\b|"principal"\s*:\s*"\?principal"`)
+	cedarResourceSlotRegex  = regexp.MustCompile(`\bresource\s*(==|in)\s*\?resource\b`)
+	cedarActionScopeRegex   = regexp.MustCompile(`\baction\s*(==|in)\s*(\[[^\]]*\]|[A-Za-z0-9_:]*Action::"[^"]*")`)
+	cedarActionNameRegex    = regexp.MustCompile(`Action::"([^"]+)"`)
+	// Action names that change data or access rather than read it
+	cedarPrivilegedActionRegex = regexp.MustCompile(`(?i)admin|delete|remove|update|create|put|write|grant|manage|modify|approve|transfer|assign|share|\*`)
+)
+
+const (
+	policyTemplateFindingAnyPrincipal = "Any principal can be linked"
+	policyTemplateAllActions          = "All actions"
+)
+
+func (m *VerifiedPermissionsTemplatesModule) PrintVerifiedPermissionsTemplates(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "verified-permissions-templates"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating Verified Permissions policy templates for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "regions")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan VerifiedPermissionsTemplate)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.Templates, func(i, j int) bool {
+		if m.Templates[i].Region != m.Templates[j].Region {
+			return m.Templates[i].Region < m.Templates[j].Region
+		}
+		if m.Templates[i].PolicyStoreID != m.Templates[j].PolicyStoreID {
+			return m.Templates[i].PolicyStoreID < m.Templates[j].PolicyStoreID
+		}
+		return m.Templates[i].ID < m.Templates[j].ID
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Policy Store",
+		"Template ID",
+		"Description",
+		"Effect",
+		"Principal Slot",
+		"Conditions",
+		"Actions",
+		"Linked Policies",
+		"Risk",
+		"Finding",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Policy Store",
+			"Template ID",
+			"Description",
+			"Effect",
+			"Principal Slot",
+			"Conditions",
+			"Actions",
+			"Linked Policies",
+			"Risk",
+			"Finding",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Policy Store",
+			"Template ID",
+			"Effect",
+			"Actions",
+			"Linked Policies",
+			"Risk",
+			"Finding",
+		}
+	}
+
+	// Table rows
+	for _, template := range m.Templates {
+		principalSlot, conditions := "No", "No"
+		if template.Analysis.PrincipalSlot {
+			principalSlot = "Yes"
+		}
+		if template.Analysis.Conditions {
+			conditions = "Yes"
+		}
+		actions := policyTemplateAllActions
+		if template.Analysis.Actions != nil {
+			actions = strings.Join(template.Analysis.Actions, ", ")
+		}
+		risk := template.Risk
+		if risk != "" {
+			risk = magenta(risk)
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				template.Region,
+				template.PolicyStoreID,
+				template.ID,
+				template.Description,
+				template.Analysis.Effect,
+				principalSlot,
+				conditions,
+				actions,
+				strconv.Itoa(len(template.LinkedPolicies)),
+				risk,
+				template.Finding,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		if m.countUnconstrainedTemplates() > 0 {
+			o.Loot.DirectoryName = o.Table.DirectoryName
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     m.output.CallingModule,
+				Contents: m.writeLoot(),
+			})
+		}
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %s policy templates found (%d can be linked to any principal).\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)), m.countUnconstrainedTemplates())
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No Verified Permissions policy templates found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *VerifiedPermissionsTemplatesModule) countUnconstrainedTemplates() int {
+	var count int
+	for _, template := range m.Templates {
+		if template.Risk != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// writeLoot has, for every template any principal can be linked to, the create-policy call that links it to a
+// principal of your choosing. It needs verifiedpermissions:CreatePolicy on the policy store.
+func (m *VerifiedPermissionsTemplatesModule) writeLoot() string {
+	var out string
+	out += "#############################################\n"
+	out += "# Set the $profile environment variable to the profile you are going to use, e.g. export profile=dev-prod.\n"
+	out += "# Replace ENTITY_TYPE and ENTITY_ID with the principal (and resource) the policy should be linked to.\n"
+	out += "#############################################\n"
+
+	for _, template := range m.Templates {
+		if template.Risk == "" {
+			continue
+		}
+		out += fmt.Sprintf("\n# %s in policy store %s (%s): %s\n", template.ID, template.PolicyStoreID, template.Region, template.Finding)
+		out += fmt.Sprintf("aws --profile $profile --region %s verifiedpermissions get-policy-template --policy-store-id %s --policy-template-id %s\n", template.Region, template.PolicyStoreID, template.ID)
+		definition := fmt.Sprintf(`{"templateLinked":{"policyTemplateId":"%s","principal":{"entityType":"ENTITY_TYPE","entityId":"ENTITY_ID"}`, template.ID)
+		if template.Analysis.ResourceSlot {
+			definition += `,"resource":{"entityType":"ENTITY_TYPE","entityId":"ENTITY_ID"}`
+		}
+		definition += "}}"
+		out += fmt.Sprintf("aws --profile $profile --region %s verifiedpermissions create-policy --policy-store-id %s --definition '%s'\n", template.Region, template.PolicyStoreID, definition)
+	}
+	return out
+}
+
+func (m *VerifiedPermissionsTemplatesModule) Receiver(receiver chan VerifiedPermissionsTemplate, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Templates = append(m.Templates, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *VerifiedPermissionsTemplatesModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan VerifiedPermissionsTemplate) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("verifiedpermissions", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getPolicyTemplatesPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *VerifiedPermissionsTemplatesModule) getPolicyTemplatesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan VerifiedPermissionsTemplate) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	policyStores, err := sdk.CachedVerifiedPermissionsListPolicyStores(m.VerifiedPermissionsClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, policyStore := range policyStores {
+		policyStoreID := aws.ToString(policyStore.PolicyStoreId)
+		templates, err := sdk.CachedVerifiedPermissionsListPolicyTemplates(m.VerifiedPermissionsClient, aws.ToString(m.Caller.Account), r, policyStoreID)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+
+		for _, t := range templates {
+			template := VerifiedPermissionsTemplate{
+				Region:        r,
+				PolicyStoreID: policyStoreID,
+				ID:            aws.ToString(t.PolicyTemplateId),
+				Description:   aws.ToString(t.Description),
+			}
+			template.Statement, err = sdk.CachedVerifiedPermissionsGetPolicyTemplate(m.VerifiedPermissionsClient, aws.ToString(m.Caller.Account), r, policyStoreID, template.ID)
+			if err != nil {
+				m.modLog.Error(err.Error())
+				m.CommandCounter.Error++
+				template.Finding = "Template body not readable"
+				dataReceiver <- template
+				continue
+			}
+			template.LinkedPolicies, err = sdk.CachedVerifiedPermissionsListTemplateLinkedPolicies(m.VerifiedPermissionsClient, aws.ToString(m.Caller.Account), r, policyStoreID, template.ID)
+			if err != nil {
+				m.modLog.Error(err.Error())
+				m.CommandCounter.Error++
+			}
+
+			template.Analysis = analyzeCedarTemplate(template.Statement)
+			template.Risk, template.Finding = policyTemplateRisk(template.Analysis)
+			dataReceiver <- template
+		}
+	}
+}
+
+// analyzeCedarTemplate pulls the effect, slots, action scope and conditions out of a Cedar policy template. A
+// template is a single policy, so only the first permit or forbid is looked at.
+func analyzeCedarTemplate(statement string) cedarTemplateAnalysis {
+	var analysis cedarTemplateAnalysis
+	statement = cedarCommentRegex.ReplaceAllString(statement, "")
+	match := cedarHeadRegex.FindStringSubmatch(statement)
+	if match == nil {
+		// Not Cedar we understand, so all we can go on is the slot
+		analysis.PrincipalSlot = cedarPrincipalSlotRegex.MatchString(statement)
+		return analysis
+	}
+	effect, scope, body := match[1], match[2], match[3]
+
+	analysis.Effect = effect
+	analysis.PrincipalSlot = cedarPrincipalSlotRegex.MatchString(scope)
+	analysis.ResourceSlot = cedarResourceSlotRegex.MatchString(scope)
+	analysis.Conditions = cedarConditionRegex.MatchString(body)
+
+	if actionScope := cedarActionScopeRegex.FindString(scope); actionScope != "" {
+		analysis.Actions = []string{}
+		for _, name := range cedarActionNameRegex.FindAllStringSubmatch(actionScope, -1) {
+			analysis.Actions = append(analysis.Actions, name[1])
+			if cedarPrivilegedActionRegex.MatchString(name[1]) {
+				analysis.PrivilegedActions = append(analysis.PrivilegedActions, name[1])
+			}
+		}
+	}
+	return analysis
+}
+
+// policyTemplateRisk flags permit templates whose principal slot isn't narrowed down by a condition. Whoever can call
+// CreatePolicy with the template can link it to any principal, themselves included.
+func policyTemplateRisk(analysis cedarTemplateAnalysis) (string, string) {
+	if analysis.Effect != "permit" || !analysis.PrincipalSlot || analysis.Conditions {
+		return "", ""
+	}
+	if analysis.Actions == nil {
+		return "HIGH", fmt.Sprintf("%s: %s", policyTemplateFindingAnyPrincipal, strings.ToLower(policyTemplateAllActions))
+	}
+	if len(analysis.PrivilegedActions) > 0 {
+		return "HIGH", fmt.Sprintf("%s: %s", policyTemplateFindingAnyPrincipal, strings.Join(analysis.PrivilegedActions, ", "))
+	}
+	return "LOW", policyTemplateFindingAnyPrincipal
+}
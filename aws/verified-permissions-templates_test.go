@@ -0,0 +1,91 @@
+package aws
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+func TestVerifiedPermissionsTemplates(t *testing.T) {
+	m := VerifiedPermissionsTemplatesModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:                3,
+		VerifiedPermissionsClient: &sdk.MockedVerifiedPermissionsClient{},
+	}
+
+	internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+
+	m.PrintVerifiedPermissionsTemplates(".", 2)
+
+	type summary struct {
+		id             string
+		linkedPolicies int
+		risk           string
+		finding        string
+	}
+	expected := []summary{
+		{"TMPLadmin1111111111111", 2, "HIGH", "Any principal can be linked: DeleteDocument, UpdateDocument"},
+		{"TMPLanyaction33333333", 0, "HIGH", "Any principal can be linked: all actions"},
+		{"TMPLdeny5555555555555", 0, "", ""},
+		{"TMPLowner2222222222222", 0, "", ""},
+		{"TMPLview4444444444444", 0, "LOW", "Any principal can be linked"},
+	}
+	var got []summary
+	for _, template := range m.Templates {
+		got = append(got, summary{template.ID, len(template.LinkedPolicies), template.Risk, template.Finding})
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	loot := m.writeLoot()
+	expectedCommand := `aws --profile $profile --region us-east-1 verifiedpermissions create-policy --policy-store-id PSdocuments1111111111 --definition '{"templateLinked":{"policyTemplateId":"TMPLanyaction33333333","principal":{"entityType":"ENTITY_TYPE","entityId":"ENTITY_ID"},"resource":{"entityType":"ENTITY_TYPE","entityId":"ENTITY_ID"}}}'`
+	if !strings.Contains(loot, expectedCommand) {
+		t.Errorf("Expected %s to be in the loot", expectedCommand)
+	}
+	if strings.Contains(loot, "TMPLowner2222222222222") {
+		t.Errorf("Did not expect templates with conditions to be in the loot")
+	}
+}
+
+func TestAnalyzeCedarTemplate(t *testing.T) {
+	subtests := map[string]struct {
+		statement string
+		expected  cedarTemplateAnalysis
+	}{
+		"single action": {
+			`permit(principal == ?principal, action == Action::"view", resource);`,
+			cedarTemplateAnalysis{Effect: "permit", PrincipalSlot: true, Actions: []string{"view"}},
+		},
+		"action list with condition": {
+			"// editors\npermit (\n  principal in ?principal,\n  action in [App::Action::\"updateDoc\", App::Action::\"readDoc\"],\n  resource == ?resource\n) unless { resource.locked };",
+			cedarTemplateAnalysis{Effect: "permit", PrincipalSlot: true, ResourceSlot: true, Conditions: true, Actions: []string{"updateDoc", "readDoc"}, PrivilegedActions: []string{"updateDoc"}},
+		},
+		"fixed principal": {
+			`permit(principal == User::"admin", action, resource == ?resource);`,
+			cedarTemplateAnalysis{Effect: "permit", ResourceSlot: true},
+		},
+		"forbid": {
+			`forbid(principal == ?principal, action, resource);`,
+			cedarTemplateAnalysis{Effect: "forbid", PrincipalSlot: true},
+		},
+	}
+	for name, subtest := range subtests {
+		t.Run(name, func(t *testing.T) {
+			if analysis := analyzeCedarTemplate(subtest.statement); !reflect.DeepEqual(analysis, subtest.expected) {
+				t.Errorf("Expected %+v, got %+v", subtest.expected, analysis)
+			}
+		})
+	}
+}
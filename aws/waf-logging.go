@@ -0,0 +1,461 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	firehoseTypes "github.com/aws/aws-sdk-go-v2/service/firehose/types"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	wafv2Types "github.com/aws/aws-sdk-go-v2/service/wafv2/types"
+	"github.com/aws/smithy-go"
+	"github.com/sirupsen/logrus"
+)
+
+type WAFLoggingModule struct {
+	// General configuration data
+	WAFv2Client          sdk.WAFv2ClientInterface
+	S3Client             sdk.AWSS3ClientInterface
+	CloudWatchLogsClient sdk.CloudWatchLogsClientInterface
+	FirehoseClient       sdk.FirehoseClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	WebACLs        []WebACLLogging
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type WebACLLogging struct {
+	Region          string
+	Scope           string
+	Name            string
+	Arn             string
+	Logging         string
+	DestinationType string
+	Destination     string
+	Encryption      string
+	// Only S3 destinations have a public access block, it is "-" for the others
+	PublicAccessBlocked string
+	RedactedFields      []string
+	Issues              []string
+}
+
+// Headers that carry credentials and end up in the logs in clear text unless they are redacted
+var wafSensitiveHeaders = []string{"Authorization", "Cookie"}
+
+func (m *WAFLoggingModule) PrintWAFLogging(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "waf-logging"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating the logging configuration of WAF web ACLs for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "tasks")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan WebACLLogging)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	// CloudFront web ACLs are global and only listed from us-east-1
+	wg.Add(1)
+	m.CommandCounter.Total++
+	m.CommandCounter.Pending++
+	go m.getWebACLLoggingPerScope("us-east-1", wafv2Types.ScopeCloudfront, wg, semaphore, dataReceiver)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		go m.getWebACLLoggingPerScope(region, wafv2Types.ScopeRegional, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Scope",
+		"Name",
+		"Arn",
+		"Logging",
+		"Destination Type",
+		"Destination",
+		"Encryption",
+		"Public Access Blocked",
+		"Redacted Fields",
+		"Issues",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Scope",
+			"Name",
+			"Arn",
+			"Logging",
+			"Destination Type",
+			"Destination",
+			"Encryption",
+			"Public Access Blocked",
+			"Redacted Fields",
+			"Issues",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Scope",
+			"Name",
+			"Logging",
+			"Destination Type",
+			"Encryption",
+			"Issues",
+		}
+	}
+
+	// Table rows
+	for i := range m.WebACLs {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				m.WebACLs[i].Region,
+				m.WebACLs[i].Scope,
+				m.WebACLs[i].Name,
+				m.WebACLs[i].Arn,
+				m.WebACLs[i].Logging,
+				m.WebACLs[i].DestinationType,
+				m.WebACLs[i].Destination,
+				m.WebACLs[i].Encryption,
+				m.WebACLs[i].PublicAccessBlocked,
+				strings.Join(m.WebACLs[i].RedactedFields, "\n"),
+				strings.Join(m.WebACLs[i].Issues, "\n"),
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %d web ACLs found, %d of them without logging and %d with logging issues.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), m.countLoggingDisabled(), m.countLoggingIssues())
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No web ACLs found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *WAFLoggingModule) countLoggingDisabled() int {
+	var count int
+	for _, webACL := range m.WebACLs {
+		if webACL.Logging == "Disabled" {
+			count++
+		}
+	}
+	return count
+}
+
+func (m *WAFLoggingModule) countLoggingIssues() int {
+	var count int
+	for _, webACL := range m.WebACLs {
+		if webACL.Logging == "Enabled" && len(webACL.Issues) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func (m *WAFLoggingModule) Receiver(receiver chan WebACLLogging, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.WebACLs = append(m.WebACLs, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *WAFLoggingModule) getWebACLLoggingPerScope(r string, scope wafv2Types.Scope, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan WebACLLogging) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	webACLs, err := sdk.CachedWAFv2ListWebACLs(m.WAFv2Client, aws.ToString(m.Caller.Account), r, scope)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	region := r
+	if scope == wafv2Types.ScopeCloudfront {
+		region = "Global"
+	}
+	for _, webACL := range webACLs {
+		webACLArn := aws.ToString(webACL.ARN)
+		loggingConfiguration, err := sdk.CachedWAFv2GetLoggingConfiguration(m.WAFv2Client, aws.ToString(m.Caller.Account), r, webACLArn)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+
+		webACLLogging := WebACLLogging{
+			Region: region,
+			Scope:  string(scope),
+			Name:   aws.ToString(webACL.Name),
+			Arn:    webACLArn,
+		}
+		m.analyzeLoggingConfiguration(&webACLLogging, loggingConfiguration)
+		dataReceiver <- webACLLogging
+	}
+}
+
+// analyzeLoggingConfiguration fills in where the logs of a web ACL go and flags the gaps
+func (m *WAFLoggingModule) analyzeLoggingConfiguration(webACL *WebACLLogging, loggingConfiguration *wafv2Types.LoggingConfiguration) {
+	if loggingConfiguration == nil || len(loggingConfiguration.LogDestinationConfigs) == 0 {
+		webACL.Logging = "Disabled"
+		webACL.DestinationType = "-"
+		webACL.Destination = "-"
+		webACL.Encryption = "-"
+		webACL.PublicAccessBlocked = "-"
+		webACL.Issues = append(webACL.Issues, "Logging disabled")
+		return
+	}
+
+	webACL.Logging = "Enabled"
+	webACL.PublicAccessBlocked = "-"
+	// A web ACL can only have one log destination
+	destination := loggingConfiguration.LogDestinationConfigs[0]
+	webACL.Destination = destination
+
+	parsedArn, err := arn.Parse(destination)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		webACL.DestinationType = "Unknown"
+		webACL.Encryption = "Unknown"
+	} else {
+		switch parsedArn.Service {
+		case "s3":
+			webACL.DestinationType = "S3"
+			bucketName := strings.SplitN(parsedArn.Resource, "/", 2)[0]
+			webACL.Encryption, webACL.PublicAccessBlocked = m.getS3DestinationSecurity(bucketName)
+			if webACL.PublicAccessBlocked == "No" {
+				webACL.Issues = append(webACL.Issues, "Public access not blocked")
+			}
+		case "logs":
+			webACL.DestinationType = "CloudWatch Logs"
+			logGroupName := strings.TrimPrefix(parsedArn.Resource, "log-group:")
+			webACL.Encryption = m.getLogGroupEncryption(parsedArn.Region, strings.TrimSuffix(logGroupName, ":*"))
+		case "firehose":
+			webACL.DestinationType = "Firehose"
+			webACL.Encryption = m.getDeliveryStreamEncryption(parsedArn.Region, strings.TrimPrefix(parsedArn.Resource, "deliverystream/"))
+		default:
+			webACL.DestinationType = parsedArn.Service
+			webACL.Encryption = "Unknown"
+		}
+	}
+	if webACL.Encryption == "Not encrypted" {
+		webACL.Issues = append(webACL.Issues, "Destination not encrypted")
+	}
+
+	for _, field := range loggingConfiguration.RedactedFields {
+		webACL.RedactedFields = append(webACL.RedactedFields, describeRedactedField(field))
+	}
+	for _, header := range wafSensitiveHeaders {
+		if !isHeaderRedacted(loggingConfiguration.RedactedFields, header) {
+			webACL.Issues = append(webACL.Issues, fmt.Sprintf("%s header not redacted", header))
+		}
+	}
+}
+
+// getS3DestinationSecurity returns the default encryption of the bucket and whether all public access is blocked
+func (m *WAFLoggingModule) getS3DestinationSecurity(bucketName string) (string, string) {
+	region, err := sdk.CachedGetBucketLocation(m.S3Client, aws.ToString(m.Caller.Account), bucketName)
+	if err != nil {
+		// The bucket can live in another account
+		m.modLog.Error(err.Error())
+		return "Unknown", "Unknown"
+	}
+
+	encryption := "Unknown"
+	encryptionConfiguration, err := sdk.CachedGetBucketEncryption(m.S3Client, aws.ToString(m.Caller.Account), region, bucketName)
+	if err != nil {
+		m.modLog.Error(err.Error())
+	} else {
+		encryption = s3EncryptionName(encryptionConfiguration)
+	}
+
+	publicAccessBlocked := "Unknown"
+	publicAccessBlock, err := sdk.CachedGetPublicAccessBlock(m.S3Client, aws.ToString(m.Caller.Account), region, bucketName)
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchPublicAccessBlockConfiguration" {
+			publicAccessBlocked = "No"
+		} else {
+			m.modLog.Error(err.Error())
+		}
+	} else if aws.ToBool(publicAccessBlock.BlockPublicAcls) && aws.ToBool(publicAccessBlock.IgnorePublicAcls) && aws.ToBool(publicAccessBlock.BlockPublicPolicy) && aws.ToBool(publicAccessBlock.RestrictPublicBuckets) {
+		publicAccessBlocked = "Yes"
+	} else {
+		publicAccessBlocked = "No"
+	}
+	return encryption, publicAccessBlocked
+}
+
+func s3EncryptionName(encryptionConfiguration *s3Types.ServerSideEncryptionConfiguration) string {
+	if encryptionConfiguration == nil {
+		return "Not encrypted"
+	}
+	for _, rule := range encryptionConfiguration.Rules {
+		if rule.ApplyServerSideEncryptionByDefault == nil {
+			continue
+		}
+		switch rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm {
+		case s3Types.ServerSideEncryptionAes256:
+			return "SSE-S3"
+		case s3Types.ServerSideEncryptionAwsKms:
+			return "SSE-KMS"
+		case s3Types.ServerSideEncryptionAwsKmsDsse:
+			return "DSSE-KMS"
+		}
+	}
+	return "Not encrypted"
+}
+
+// getLogGroupEncryption tells service managed encryption apart from a KMS key. CloudWatch Logs always encrypts at rest.
+func (m *WAFLoggingModule) getLogGroupEncryption(r string, logGroupName string) string {
+	logGroup, err := sdk.CachedCloudWatchLogsDescribeLogGroup(m.CloudWatchLogsClient, aws.ToString(m.Caller.Account), r, logGroupName)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		return "Unknown"
+	}
+	if logGroup == nil {
+		return "Unknown"
+	}
+	if aws.ToString(logGroup.KmsKeyId) != "" {
+		return "KMS"
+	}
+	return "Service managed"
+}
+
+func (m *WAFLoggingModule) getDeliveryStreamEncryption(r string, deliveryStreamName string) string {
+	deliveryStream, err := sdk.CachedFirehoseDescribeDeliveryStream(m.FirehoseClient, aws.ToString(m.Caller.Account), r, deliveryStreamName)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		return "Unknown"
+	}
+	encryption := deliveryStream.DeliveryStreamEncryptionConfiguration
+	if encryption == nil || encryption.Status != firehoseTypes.DeliveryStreamEncryptionStatusEnabled {
+		return "Not encrypted"
+	}
+	if encryption.KeyType == firehoseTypes.KeyTypeCustomerManagedCmk {
+		return "SSE-KMS (customer managed)"
+	}
+	return "SSE-KMS (AWS owned)"
+}
+
+func describeRedactedField(field wafv2Types.FieldToMatch) string {
+	switch {
+	case field.SingleHeader != nil:
+		return "Header: " + aws.ToString(field.SingleHeader.Name)
+	case field.SingleQueryArgument != nil:
+		return "Query argument: " + aws.ToString(field.SingleQueryArgument.Name)
+	case field.QueryString != nil:
+		return "Query string"
+	case field.UriPath != nil:
+		return "URI path"
+	case field.Method != nil:
+		return "Method"
+	}
+	return "Other"
+}
+
+func isHeaderRedacted(fields []wafv2Types.FieldToMatch, header string) bool {
+	for _, field := range fields {
+		if field.SingleHeader != nil && strings.EqualFold(aws.ToString(field.SingleHeader.Name), header) {
+			return true
+		}
+	}
+	return false
+}
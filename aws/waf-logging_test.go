@@ -0,0 +1,80 @@
+package aws
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestWAFLogging(t *testing.T) {
+
+	m := WAFLoggingModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1", "eu-west-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:           3,
+		WrapTable:            false,
+		WAFv2Client:          &sdk.MockedWAFv2Client{},
+		S3Client:             &sdk.MockedS3Client{},
+		CloudWatchLogsClient: &sdk.MockedCloudWatchLogsClient{},
+		FirehoseClient:       &sdk.MockedFirehoseClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+	tmpDir := "."
+
+	m.PrintWAFLogging(tmpDir, 2)
+
+	type expectedWebACL struct {
+		DestinationType     string
+		Encryption          string
+		PublicAccessBlocked string
+		Issues              string
+	}
+	expectedWebACLs := map[string]expectedWebACL{
+		"alb-acl":        {DestinationType: "CloudWatch Logs", Encryption: "KMS", PublicAccessBlocked: "-", Issues: ""},
+		"orphaned-acl":   {DestinationType: "-", Encryption: "-", PublicAccessBlocked: "-", Issues: "Logging disabled"},
+		"cloudfront-acl": {DestinationType: "Firehose", Encryption: "Not encrypted", PublicAccessBlocked: "-", Issues: "Destination not encrypted, Authorization header not redacted, Cookie header not redacted"},
+		"api-acl":        {DestinationType: "S3", Encryption: "SSE-S3", PublicAccessBlocked: "No", Issues: "Public access not blocked, Authorization header not redacted, Cookie header not redacted"},
+	}
+	if len(m.WebACLs) != len(expectedWebACLs) {
+		t.Fatalf("Expected %d web ACLs, got %d", len(expectedWebACLs), len(m.WebACLs))
+	}
+	for _, webACL := range m.WebACLs {
+		want, ok := expectedWebACLs[webACL.Name]
+		if !ok {
+			t.Errorf("Unexpected web ACL %s", webACL.Name)
+			continue
+		}
+		got := expectedWebACL{
+			DestinationType:     webACL.DestinationType,
+			Encryption:          webACL.Encryption,
+			PublicAccessBlocked: webACL.PublicAccessBlocked,
+			Issues:              strings.Join(webACL.Issues, ", "),
+		}
+		if got != want {
+			t.Errorf("%s: expected %+v, got %+v", webACL.Name, want, got)
+		}
+	}
+
+	resultsFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/table/waf-logging.txt")
+	resultsFile, err := afero.ReadFile(fs, resultsFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read output file at %s: %s", resultsFilePath, err)
+	}
+	for _, expected := range []string{"alb-acl", "orphaned-acl", "cloudfront-acl", "api-acl"} {
+		if !strings.Contains(string(resultsFile), expected) {
+			t.Errorf("Expected %s to be in the output file", expected)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	wafv2Types "github.com/aws/aws-sdk-go-v2/service/wafv2/types"
+	"github.com/sirupsen/logrus"
+)
+
+type WAFModule struct {
+	// General configuration data
+	WAFv2Client      sdk.WAFv2ClientInterface
+	CloudFrontClient sdk.AWSCloudFrontClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	WebACLs        []WebACL
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type WebACL struct {
+	Region       string
+	Scope        string
+	Name         string
+	Arn          string
+	Resources    []string
+	Unassociated string
+}
+
+// The regional resource types that a web ACL can be associated with. ListResourcesForWebACL
+// only returns one resource type per call and defaults to APPLICATION_LOAD_BALANCER.
+var webACLResourceTypes = []wafv2Types.ResourceType{
+	wafv2Types.ResourceType("APPLICATION_LOAD_BALANCER"),
+	wafv2Types.ResourceType("API_GATEWAY"),
+	wafv2Types.ResourceType("APPSYNC"),
+	wafv2Types.ResourceType("COGNITO_USER_POOL"),
+	wafv2Types.ResourceType("APP_RUNNER_SERVICE"),
+	wafv2Types.ResourceType("VERIFIED_ACCESS_INSTANCE"),
+}
+
+func (m *WAFModule) PrintWebACLs(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "waf"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating WAF web ACLs for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "tasks")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan WebACL)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	// CloudFront web ACLs are global, so they only need to be checked once
+	wg.Add(1)
+	m.CommandCounter.Total++
+	m.CommandCounter.Pending++
+	go m.getCloudFrontWebACLs(wg, semaphore, dataReceiver)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Scope",
+		"Name",
+		"Arn",
+		"Associated Resources",
+		"Unassociated?",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Scope",
+			"Name",
+			"Arn",
+			"Associated Resources",
+			"Unassociated?",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Scope",
+			"Name",
+			"Associated Resources",
+			"Unassociated?",
+		}
+	}
+
+	// Table rows
+	for i := range m.WebACLs {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				m.WebACLs[i].Region,
+				m.WebACLs[i].Scope,
+				m.WebACLs[i].Name,
+				m.WebACLs[i].Arn,
+				strings.Join(m.WebACLs[i].Resources, "\n"),
+				m.WebACLs[i].Unassociated,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %d web ACLs found (%d not associated with any resource).\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), m.countUnassociated())
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No web ACLs found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *WAFModule) countUnassociated() int {
+	var count int
+	for _, webACL := range m.WebACLs {
+		if webACL.Unassociated == "Yes" {
+			count++
+		}
+	}
+	return count
+}
+
+func (m *WAFModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan WebACL) {
+	defer wg.Done()
+
+	// WAFv2 is available in every region that supports the resources it protects, so there is no service map check here
+	m.CommandCounter.Total++
+	wg.Add(1)
+	m.getRegionalWebACLsPerRegion(r, wg, semaphore, dataReceiver)
+}
+
+func (m *WAFModule) Receiver(receiver chan WebACL, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.WebACLs = append(m.WebACLs, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *WAFModule) getRegionalWebACLsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan WebACL) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	webACLs, err := sdk.CachedWAFv2ListWebACLs(m.WAFv2Client, aws.ToString(m.Caller.Account), r, wafv2Types.ScopeRegional)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, webACL := range webACLs {
+		arn := aws.ToString(webACL.ARN)
+		var resources []string
+		for _, resourceType := range webACLResourceTypes {
+			resourceArns, err := sdk.CachedWAFv2ListResourcesForWebACL(m.WAFv2Client, aws.ToString(m.Caller.Account), r, arn, resourceType)
+			if err != nil {
+				// Not every resource type is supported in every region
+				m.modLog.Error(err.Error())
+				continue
+			}
+			resources = append(resources, resourceArns...)
+		}
+
+		dataReceiver <- newWebACL(r, string(wafv2Types.ScopeRegional), aws.ToString(webACL.Name), arn, resources)
+	}
+}
+
+func (m *WAFModule) getCloudFrontWebACLs(wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan WebACL) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	// CloudFront scoped web ACLs only live in us-east-1
+	webACLs, err := sdk.CachedWAFv2ListWebACLs(m.WAFv2Client, aws.ToString(m.Caller.Account), "us-east-1", wafv2Types.ScopeCloudfront)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	if len(webACLs) == 0 {
+		return
+	}
+
+	// ListResourcesForWebACL doesn't support CloudFront, so use the web ACL attached to each distribution instead
+	distributions, err := sdk.CachedCloudFrontListDistributions(m.CloudFrontClient, aws.ToString(m.Caller.Account))
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+
+	for _, webACL := range webACLs {
+		arn := aws.ToString(webACL.ARN)
+		var resources []string
+		for _, distribution := range distributions {
+			if aws.ToString(distribution.WebACLId) == arn {
+				resources = append(resources, aws.ToString(distribution.ARN))
+			}
+		}
+
+		dataReceiver <- newWebACL("Global", string(wafv2Types.ScopeCloudfront), aws.ToString(webACL.Name), arn, resources)
+	}
+}
+
+func newWebACL(region string, scope string, name string, arn string, resources []string) WebACL {
+	unassociated := "No"
+	if len(resources) == 0 {
+		unassociated = "Yes"
+	}
+	return WebACL{
+		Region:       region,
+		Scope:        scope,
+		Name:         name,
+		Arn:          arn,
+		Resources:    resources,
+		Unassociated: unassociated,
+	}
+}
@@ -0,0 +1,62 @@
+package aws
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/afero"
+)
+
+func TestWebACLs(t *testing.T) {
+
+	m := WAFModule{
+		AWSProfile: "unittesting",
+		AWSRegions: []string{"us-east-1"},
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::123456789012:user/Alice"),
+			Account: aws.String("123456789012"),
+		},
+		Goroutines:       3,
+		WrapTable:        false,
+		WAFv2Client:      &sdk.MockedWAFv2Client{},
+		CloudFrontClient: &sdk.MockedAWSCloudFrontClient{},
+	}
+
+	fs := internal.MockFileSystem(true)
+	defer internal.MockFileSystem(false)
+	tmpDir := "."
+
+	m.PrintWebACLs(tmpDir, 2)
+
+	resultsFilePath := filepath.Join(tmpDir, "cloudfox-output/aws/unittesting-123456789012/table/waf.txt")
+	resultsFile, err := afero.ReadFile(fs, resultsFilePath)
+	if err != nil {
+		t.Fatalf("Cannot read output file at %s: %s", resultsFilePath, err)
+	}
+
+	expectedResults := []string{
+		"alb-acl",
+		"orphaned-acl",
+		"cloudfront-acl",
+		"loadbalancer/app/alb1/1234567890",
+	}
+	for _, expected := range expectedResults {
+		if !strings.Contains(string(resultsFile), expected) {
+			t.Errorf("Expected %s to be in the results file", expected)
+		}
+	}
+
+	for _, webACL := range m.WebACLs {
+		if webACL.Name == "alb-acl" && webACL.Unassociated != "No" {
+			t.Errorf("Expected alb-acl to be associated")
+		}
+		if webACL.Name == "orphaned-acl" && webACL.Unassociated != "Yes" {
+			t.Errorf("Expected orphaned-acl to be flagged as unassociated")
+		}
+	}
+}
@@ -0,0 +1,464 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type WorkflowSecretsModule struct {
+	// General configuration data
+	StepFunctionsClient sdk.StepFunctionsClientInterface
+	SSMClient           sdk.AWSSSMClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// Main module data
+	Findings       []WorkflowSecret
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+// WorkflowSecret is a secret found in a state machine definition or SSM document, or an SSM document that is shared
+// outside the account. A shared document without secrets has an empty Rule.
+type WorkflowSecret struct {
+	Region     string
+	Service    string
+	Name       string
+	SharedWith []string
+	Rule       string
+	Line       string
+	Value      string
+}
+
+const (
+	workflowServiceStepFunctions = "Step Functions"
+	workflowServiceSSMDocument   = "SSM document"
+)
+
+type definitionSecretRule struct {
+	name    string
+	pattern *regexp.Regexp
+	// The submatch that holds the secret. 0 is the whole match.
+	group int
+}
+
+// Rules are tried in order. A value is only reported by the first rule that finds it.
+var definitionSecretRules = []definitionSecretRule{
+	{"AWS access key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), 0},
+	{"Private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`), 0},
+	{"Credentials in URL", regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^:/@\s"']+:([^@/\s"']+)@`), 1},
+	{"API token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36}|xox[abprs]-[A-Za-z0-9-]{10,}|sk_live_[A-Za-z0-9]{10,})\b`), 0},
+	{"Secret-looking assignment", regexp.MustCompile(`(?i)["']?([A-Za-z0-9_.$-]*(?:password|passwd|pwd|secret|token|api[_-]?key|apikey|access[_-]?key|private[_-]?key|credential)[A-Za-z0-9_.$-]*)["']?\s*[:=]\s*["']?([^"'\s,{}\[\]]+)`), 2},
+}
+
+// Values that point somewhere else instead of holding the secret: ASL paths and intrinsic functions, SSM parameters,
+// dynamic references and ARNs
+var definitionSecretReferencePrefixes = []string{"$", "{{", "arn:", "States.", "ssm:", "resolve:"}
+
+func (m *WorkflowSecretsModule) PrintWorkflowSecrets(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "workflow-secrets"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Searching Step Functions state machines and SSM documents for secrets in account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "tasks")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan WorkflowSecret)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		go m.executeChecks(region, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.SliceStable(m.Findings, func(i, j int) bool {
+		if m.Findings[i].Region != m.Findings[j].Region {
+			return m.Findings[i].Region < m.Findings[j].Region
+		}
+		if m.Findings[i].Service != m.Findings[j].Service {
+			return m.Findings[i].Service < m.Findings[j].Service
+		}
+		return m.Findings[i].Name < m.Findings[j].Name
+	})
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Service",
+		"Name",
+		"Shared With",
+		"Match",
+		"Snippet",
+	}
+
+	// If the user specified table columns, use those.
+	// If the user specified -o wide, use the wide default cols for this module.
+	// Otherwise, use the hardcoded default cols for this module.
+	var tableCols []string
+	// If the user specified table columns, use those.
+	if m.AWSTableCols != "" {
+		// If the user specified wide as the output format, use these columns.
+		// remove any spaces between any commas and the first letter after the commas
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Service",
+			"Name",
+			"Shared With",
+			"Match",
+			"Snippet",
+		}
+		// Otherwise, use the default columns.
+	} else {
+		tableCols = []string{
+			"Region",
+			"Service",
+			"Name",
+			"Shared With",
+			"Match",
+			"Snippet",
+		}
+	}
+
+	// Table rows. The secret itself is masked here; the loot file has it in full.
+	for _, finding := range m.Findings {
+		sharedWith := workflowSharedWithString(finding.SharedWith)
+		if sharedWith != "" {
+			sharedWith = magenta(sharedWith)
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				finding.Region,
+				finding.Service,
+				finding.Name,
+				sharedWith,
+				finding.Rule,
+				maskDefinitionSecret(finding.Line, finding.Value),
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+			Name:     m.output.CallingModule,
+			Contents: m.writeLoot(),
+		})
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
+		fmt.Printf("[%s][%s] %d findings (%d secrets).\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), len(m.output.Body), m.countSecrets())
+		fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+	} else {
+		fmt.Printf("[%s][%s] No secrets or shared documents found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *WorkflowSecretsModule) countSecrets() int {
+	var count int
+	for _, finding := range m.Findings {
+		if finding.Rule != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// writeLoot has the unmasked lines the secrets were found on, and the command to pull the whole definition
+func (m *WorkflowSecretsModule) writeLoot() string {
+	var out string
+	out += "#############################################\n"
+	out += "# Set the $profile environment variable to the profile you are going to use, e.g. export profile=dev-prod.\n"
+	out += "#############################################\n"
+
+	var lastResource string
+	for _, finding := range m.Findings {
+		resource := fmt.Sprintf("%s/%s/%s", finding.Region, finding.Service, finding.Name)
+		if resource != lastResource {
+			lastResource = resource
+			out += fmt.Sprintf("\n# %s %s (%s)", finding.Service, finding.Name, finding.Region)
+			if sharedWith := workflowSharedWithString(finding.SharedWith); sharedWith != "" {
+				out += fmt.Sprintf(" - shared with %s", sharedWith)
+			}
+			out += "\n"
+			if finding.Service == workflowServiceStepFunctions {
+				out += fmt.Sprintf("aws --profile $profile --region %s stepfunctions describe-state-machine --state-machine-arn arn:aws:states:%s:%s:stateMachine:%s --query definition --output text\n", finding.Region, finding.Region, aws.ToString(m.Caller.Account), finding.Name)
+			} else {
+				out += fmt.Sprintf("aws --profile $profile --region %s ssm get-document --name %s --query Content --output text\n", finding.Region, finding.Name)
+			}
+		}
+		if finding.Rule != "" {
+			out += fmt.Sprintf("# %s\n%s\n", finding.Rule, finding.Line)
+		}
+	}
+	return out
+}
+
+func (m *WorkflowSecretsModule) Receiver(receiver chan WorkflowSecret, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.Findings = append(m.Findings, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *WorkflowSecretsModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan WorkflowSecret) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("stepfunctions", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getStateMachinesPerRegion(r, wg, semaphore, dataReceiver)
+	}
+	res, err = servicemap.IsServiceInRegion("ssm", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		wg.Add(1)
+		go m.getSSMDocumentsPerRegion(r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *WorkflowSecretsModule) getStateMachinesPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan WorkflowSecret) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	stateMachines, err := sdk.CachedStepFunctionsListStateMachines(m.StepFunctionsClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, item := range stateMachines {
+		stateMachine, err := sdk.CachedStepFunctionsDescribeStateMachine(m.StepFunctionsClient, aws.ToString(m.Caller.Account), r, aws.ToString(item.StateMachineArn))
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		for _, match := range scanDefinitionForSecrets(aws.ToString(stateMachine.Definition)) {
+			match.Region = r
+			match.Service = workflowServiceStepFunctions
+			match.Name = aws.ToString(item.Name)
+			dataReceiver <- match
+		}
+	}
+}
+
+func (m *WorkflowSecretsModule) getSSMDocumentsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan WorkflowSecret) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	// Only documents the account owns. AWS owned documents are public by design and there are hundreds of them.
+	documents, err := sdk.CachedSSMListOwnedDocuments(m.SSMClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, document := range documents {
+		name := aws.ToString(document.Name)
+		sharedWith, err := sdk.CachedSSMDescribeDocumentSharing(m.SSMClient, aws.ToString(m.Caller.Account), r, name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+		content, err := sdk.CachedSSMGetDocumentContent(m.SSMClient, aws.ToString(m.Caller.Account), r, name)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+
+		matches := scanDefinitionForSecrets(content)
+		// Shared documents are reported even without secrets, since whoever they are shared with can read them
+		if len(matches) == 0 && len(sharedWith) > 0 {
+			matches = append(matches, WorkflowSecret{})
+		}
+		for _, match := range matches {
+			match.Region = r
+			match.Service = workflowServiceSSMDocument
+			match.Name = name
+			match.SharedWith = sharedWith
+			dataReceiver <- match
+		}
+	}
+}
+
+// scanDefinitionForSecrets runs the secret rules over every line of a definition. Each secret value is only reported
+// once per definition.
+func scanDefinitionForSecrets(definition string) []WorkflowSecret {
+	var matches []WorkflowSecret
+	var seen []string
+	for _, line := range strings.Split(definition, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		for _, rule := range definitionSecretRules {
+			for _, submatch := range rule.pattern.FindAllStringSubmatch(line, -1) {
+				value := submatch[rule.group]
+				if internal.Contains(value, seen) || !isDefinitionSecretValue(rule, submatch) {
+					continue
+				}
+				seen = append(seen, value)
+				matches = append(matches, WorkflowSecret{
+					Rule:  rule.name,
+					Line:  line,
+					Value: value,
+				})
+			}
+		}
+	}
+	return matches
+}
+
+// isDefinitionSecretValue drops keyword matches that hold a reference or an identifier instead of a secret
+func isDefinitionSecretValue(rule definitionSecretRule, submatch []string) bool {
+	value := submatch[rule.group]
+	if len(value) < 4 {
+		return false
+	}
+	for _, prefix := range definitionSecretReferencePrefixes {
+		if strings.HasPrefix(value, prefix) {
+			return false
+		}
+	}
+	if rule.group == 2 {
+		// e.g. SecretId, SecretArn, TokenName and ASL paths like Password.$
+		key := strings.ToLower(submatch[1])
+		for _, suffix := range []string{"id", "arn", "name", "path", ".$"} {
+			if strings.HasSuffix(key, suffix) {
+				return false
+			}
+		}
+		switch strings.ToLower(value) {
+		case "true", "false", "null", "none", "string", "securestring":
+			return false
+		}
+	}
+	return true
+}
+
+// maskDefinitionSecret replaces the secret in a line with its first two characters followed by asterisks
+func maskDefinitionSecret(line string, value string) string {
+	if value == "" {
+		return line
+	}
+	masked := strings.Repeat("*", 8)
+	if len(value) > 6 {
+		masked = value[:2] + masked
+	}
+	return strings.ReplaceAll(line, value, masked)
+}
+
+func workflowSharedWithString(accountIDs []string) string {
+	if internal.Contains("all", accountIDs) {
+		return "Public"
+	}
+	return strings.Join(accountIDs, ", ")
+}
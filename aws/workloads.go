@@ -13,6 +13,7 @@ import (
 	"github.com/BishopFox/cloudfox/aws/sdk"
 	"github.com/BishopFox/cloudfox/internal"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	elasticbeanstalkTypes "github.com/aws/aws-sdk-go-v2/service/elasticbeanstalk/types"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
@@ -32,12 +33,13 @@ type WorkloadsModule struct {
 	SkipAdminCheck bool
 
 	// Service Clients
-	EC2Client       sdk.AWSEC2ClientInterface
-	ECSClient       sdk.AWSECSClientInterface
-	LambdaClient    sdk.LambdaClientInterface
-	AppRunnerClient sdk.AppRunnerClientInterface
-	IAMClient       sdk.AWSIAMClientInterface
-	//LightsailClient sdk.MockedLightsailClient
+	EC2Client              sdk.AWSEC2ClientInterface
+	ECSClient              sdk.AWSECSClientInterface
+	LambdaClient           sdk.LambdaClientInterface
+	AppRunnerClient        sdk.AppRunnerClientInterface
+	ElasticBeanstalkClient sdk.AWSElasticBeanstalkClientInterface
+	LightsailClient        sdk.LightsailClientInterface
+	IAMClient              sdk.AWSIAMClientInterface
 	//SagemakerClient *sagemaker.Client
 
 	pmapperMod          PmapperModule
@@ -62,12 +64,19 @@ type Workload struct {
 	Type       string
 	Name       string
 	Arn        string
+	Endpoint   string
+	Public     string
+	Details    string
 	Role       string
 	Admin      string
 	CanPrivEsc string
-	Public     string
+	// Environment variables that look like credentials, as NAME=value
+	SecretEnvVars []string
 }
 
+// Elastic Beanstalk keeps environment properties in this option namespace
+const beanstalkEnvironmentNamespace = "aws:elasticbeanstalk:application:environment"
+
 func (m *WorkloadsModule) PrintWorkloads(outputDirectory string, verbosity int) {
 	// These struct values are used by the output module
 	m.output.Verbosity = verbosity
@@ -82,7 +91,7 @@ func (m *WorkloadsModule) PrintWorkloads(outputDirectory string, verbosity int)
 	}
 
 	fmt.Printf("[%s][%s] Enumerating compute workloads in all regions for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
-	fmt.Printf("[%s][%s] Supported Services: App Runner, EC2, ECS, Lambda \n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	fmt.Printf("[%s][%s] Supported Services: App Runner, EC2, ECS, Elastic Beanstalk, Lambda, Lightsail \n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
 
 	m.pmapperMod, m.pmapperError = InitPmapperGraph(m.Caller, m.AWSProfile, m.Goroutines, m.PmapperDataBasePath)
 	m.iamSimClient = InitIamCommandClient(m.IAMClient, m.Caller, m.AWSProfile, m.Goroutines)
@@ -140,6 +149,9 @@ func (m *WorkloadsModule) PrintWorkloads(outputDirectory string, verbosity int)
 		"Region",
 		"Name",
 		"Arn",
+		"Endpoint",
+		"Public",
+		"Details",
 		"Role",
 		"IsAdminRole?",
 		"CanPrivEscToAdmin?",
@@ -162,6 +174,9 @@ func (m *WorkloadsModule) PrintWorkloads(outputDirectory string, verbosity int)
 			"Service",
 			"Region",
 			"Arn",
+			"Endpoint",
+			"Public",
+			"Details",
 			"Role",
 			"IsAdminRole?",
 			"CanPrivEscToAdmin?",
@@ -172,6 +187,8 @@ func (m *WorkloadsModule) PrintWorkloads(outputDirectory string, verbosity int)
 			"Service",
 			"Region",
 			"Name",
+			"Endpoint",
+			"Public",
 			"Role",
 			"IsAdminRole?",
 			"CanPrivEscToAdmin?",
@@ -187,6 +204,10 @@ func (m *WorkloadsModule) PrintWorkloads(outputDirectory string, verbosity int)
 		return m.Workloads[i].AWSService < m.Workloads[j].AWSService
 	})
 
+	// The loot is built before the table rows are colored
+	publicURLsLoot := m.writePublicURLsLoot()
+	beanstalkSecretsLoot := m.writeBeanstalkSecretsLoot()
+
 	// Table rows
 	for i := range m.Workloads {
 		// If the role is an admin or can privesc to admin, make it magenta
@@ -207,6 +228,9 @@ func (m *WorkloadsModule) PrintWorkloads(outputDirectory string, verbosity int)
 				m.Workloads[i].Region,
 				m.Workloads[i].Name,
 				m.Workloads[i].Arn,
+				m.Workloads[i].Endpoint,
+				m.Workloads[i].Public,
+				m.Workloads[i].Details,
 				m.Workloads[i].Role,
 				m.Workloads[i].Admin,
 				m.Workloads[i].CanPrivEsc,
@@ -228,6 +252,8 @@ func (m *WorkloadsModule) PrintWorkloads(outputDirectory string, verbosity int)
 
 		o.PrefixIdentifier = m.AWSProfile
 		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.AccountID = aws.ToString(m.Caller.Account)
+		o.BaseDirectory = outputDirectory
 
 		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
 			Header:    m.output.Headers,
@@ -247,7 +273,21 @@ func (m *WorkloadsModule) PrintWorkloads(outputDirectory string, verbosity int)
 			SkipPrintToScreen: true,
 		})
 
-		o.WriteFullOutput(o.Table.TableFiles, nil)
+		o.Loot.DirectoryName = o.Table.DirectoryName
+		if publicURLsLoot != "" {
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     fmt.Sprintf("%s-public-urls", m.output.CallingModule),
+				Contents: publicURLsLoot,
+			})
+		}
+		if beanstalkSecretsLoot != "" {
+			o.Loot.LootFiles = append(o.Loot.LootFiles, internal.LootFile{
+				Name:     fmt.Sprintf("%s-beanstalk-env-secrets", m.output.CallingModule),
+				Contents: beanstalkSecretsLoot,
+			})
+		}
+
+		o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
 		fmt.Printf("[%s][%s] %s compute workloads found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
 
 	} else {
@@ -304,6 +344,30 @@ func (m *WorkloadsModule) executeChecks(r string, wg *sync.WaitGroup, semaphore
 	wg.Add(1)
 	go m.getAppRunnerWorkloadsPerRegion(r, wg, semaphore, dataReceiver)
 
+	if m.ElasticBeanstalkClient != nil {
+		res, err = servicemap.IsServiceInRegion("elasticbeanstalk", r)
+		if err != nil {
+			m.modLog.Error(err)
+		}
+		if res {
+			m.CommandCounter.Total++
+			wg.Add(1)
+			go m.getElasticBeanstalkWorkloadsPerRegion(r, wg, semaphore, dataReceiver)
+		}
+	}
+
+	if m.LightsailClient != nil {
+		res, err = servicemap.IsServiceInRegion("lightsail", r)
+		if err != nil {
+			m.modLog.Error(err)
+		}
+		if res {
+			m.CommandCounter.Total++
+			wg.Add(1)
+			go m.getLightsailWorkloadsPerRegion(r, wg, semaphore, dataReceiver)
+		}
+	}
+
 }
 
 func (m *WorkloadsModule) getEC2WorkloadsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Workload) {
@@ -469,19 +533,206 @@ func (m *WorkloadsModule) getAppRunnerWorkloadsPerRegion(r string, wg *sync.Wait
 		m.modLog.Error(err)
 	}
 	for _, service := range appRunnerServices {
-		var role string
-		if service.ServiceArn != nil {
-			role = aws.ToString(service.ServiceArn)
-		}
-		dataReceiver <- Workload{
+		workload := Workload{
 			AWSService: "App Runner",
 			Region:     r,
 			Type:       "service",
 			Name:       aws.ToString(service.ServiceName),
 			Arn:        aws.ToString(service.ServiceArn),
-			Role:       role,
 		}
+		if service.ServiceUrl != nil {
+			workload.Endpoint = fmt.Sprintf("https://%s", aws.ToString(service.ServiceUrl))
+		}
+
+		// The summary doesn't say who can reach the service or which role the code runs as
+		details, err := sdk.CachedAppRunnerDescribeService(m.AppRunnerClient, aws.ToString(m.Caller.Account), r, aws.ToString(service.ServiceArn))
+		if err != nil {
+			m.modLog.Error(err)
+		} else {
+			if details.InstanceConfiguration != nil {
+				workload.Role = aws.ToString(details.InstanceConfiguration.InstanceRoleArn)
+			}
+			if details.NetworkConfiguration != nil && details.NetworkConfiguration.IngressConfiguration != nil {
+				workload.Public = publicString(details.NetworkConfiguration.IngressConfiguration.IsPubliclyAccessible)
+			}
+		}
+		dataReceiver <- workload
+	}
+}
+
+func (m *WorkloadsModule) getElasticBeanstalkWorkloadsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Workload) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+
+	environments, err := sdk.CachedElasticBeanstalkDescribeEnvironments(m.ElasticBeanstalkClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err)
+		return
+	}
+	for _, environment := range environments {
+		workload := Workload{
+			AWSService: "Elastic Beanstalk",
+			Region:     r,
+			Type:       "environment",
+			Name:       fmt.Sprintf("%s/%s", aws.ToString(environment.ApplicationName), aws.ToString(environment.EnvironmentName)),
+			Arn:        aws.ToString(environment.EnvironmentArn),
+			Details:    aws.ToString(environment.SolutionStackName),
+		}
+
+		settings, err := sdk.CachedElasticBeanstalkDescribeConfigurationSettings(m.ElasticBeanstalkClient, aws.ToString(m.Caller.Account), r, aws.ToString(environment.ApplicationName), aws.ToString(environment.EnvironmentName))
+		if err != nil {
+			m.modLog.Error(err)
+		}
+		instanceProfile, internalLoadBalancer, secretEnvVars := analyzeBeanstalkConfigurationSettings(settings)
+		workload.SecretEnvVars = secretEnvVars
+
+		// Worker environments don't have a CNAME because they only pull work from SQS
+		if environment.CNAME != nil {
+			workload.Endpoint = fmt.Sprintf("http://%s", aws.ToString(environment.CNAME))
+			workload.Public = publicString(!internalLoadBalancer)
+		}
+		if len(secretEnvVars) > 0 {
+			var names []string
+			for _, envVar := range secretEnvVars {
+				names = append(names, strings.SplitN(envVar, "=", 2)[0])
+			}
+			workload.Details = fmt.Sprintf("%s (secrets in env: %s)", workload.Details, strings.Join(names, ", "))
+		}
+
+		// The application runs on EC2 instances with the environment's instance profile
+		if instanceProfile != "" {
+			profileOutput, err := sdk.CachedIamGetInstanceProfile(m.IAMClient, aws.ToString(m.Caller.Account), instanceProfile)
+			if err != nil {
+				m.modLog.Error(err)
+			} else {
+				for _, role := range profileOutput.Roles {
+					workload.Role = aws.ToString(role.Arn)
+				}
+			}
+		}
+		dataReceiver <- workload
+	}
+}
+
+// analyzeBeanstalkConfigurationSettings returns the environment's instance profile, whether its load balancer is
+// internal, and the environment properties that look like credentials
+func analyzeBeanstalkConfigurationSettings(settings []elasticbeanstalkTypes.ConfigurationSettingsDescription) (string, bool, []string) {
+	var instanceProfile string
+	var internalLoadBalancer bool
+	var secretEnvVars []string
+	for _, setting := range settings {
+		for _, option := range setting.OptionSettings {
+			namespace := aws.ToString(option.Namespace)
+			name := aws.ToString(option.OptionName)
+			value := aws.ToString(option.Value)
+			switch {
+			case namespace == "aws:autoscaling:launchconfiguration" && name == "IamInstanceProfile":
+				// This can be a name or an ARN
+				instanceProfile = value[strings.LastIndex(value, "/")+1:]
+			case namespace == "aws:ec2:vpc" && name == "ELBScheme":
+				internalLoadBalancer = value == "internal"
+			case namespace == beanstalkEnvironmentNamespace && value != "" && isSecretLookingName(name):
+				secretEnvVars = append(secretEnvVars, fmt.Sprintf("%s=%s", name, value))
+			}
+		}
+	}
+	sort.Strings(secretEnvVars)
+	return instanceProfile, internalLoadBalancer, secretEnvVars
+}
+
+func (m *WorkloadsModule) getLightsailWorkloadsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Workload) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+
+	instances, err := sdk.CachedLightsailGetInstances(m.LightsailClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	for _, instance := range instances {
+		workload := Workload{
+			AWSService: "Lightsail",
+			Region:     r,
+			Type:       "instance",
+			Name:       aws.ToString(instance.Name),
+			Arn:        aws.ToString(instance.Arn),
+			Endpoint:   aws.ToString(instance.PublicIpAddress),
+			Public:     publicString(instance.PublicIpAddress != nil),
+		}
+		if instance.SshKeyName != nil {
+			workload.Details = fmt.Sprintf("key pair: %s", aws.ToString(instance.SshKeyName))
+		}
+		dataReceiver <- workload
+	}
+
+	databases, err := sdk.CachedLightsailGetRelationalDatabases(m.LightsailClient, aws.ToString(m.Caller.Account), r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	for _, database := range databases {
+		workload := Workload{
+			AWSService: "Lightsail",
+			Region:     r,
+			Type:       "database",
+			Name:       aws.ToString(database.Name),
+			Arn:        aws.ToString(database.Arn),
+			Public:     publicString(aws.ToBool(database.PubliclyAccessible)),
+			Details:    fmt.Sprintf("%s, master user: %s", aws.ToString(database.Engine), aws.ToString(database.MasterUsername)),
+		}
+		if database.MasterEndpoint != nil {
+			workload.Endpoint = fmt.Sprintf("%s:%d", aws.ToString(database.MasterEndpoint.Address), aws.ToInt32(database.MasterEndpoint.Port))
+		}
+		dataReceiver <- workload
+	}
+}
+
+// publicString matches the True/False values the endpoints module uses for the Public column
+func publicString(public bool) string {
+	if public {
+		return "True"
+	}
+	return "False"
+}
+
+// writePublicURLsLoot lists the endpoints of workloads that can be reached from the internet, one per line
+func (m *WorkloadsModule) writePublicURLsLoot() string {
+	var out string
+	for _, workload := range m.Workloads {
+		if workload.Public == "True" && workload.Endpoint != "" {
+			out = out + fmt.Sprintln(workload.Endpoint)
+		}
+	}
+	return out
+}
+
+func (m *WorkloadsModule) writeBeanstalkSecretsLoot() string {
+	var out string
+	for _, workload := range m.Workloads {
+		if len(workload.SecretEnvVars) == 0 {
+			continue
+		}
+		out = out + fmt.Sprintf("# %s (%s)\n", workload.Name, workload.Region)
+		for _, envVar := range workload.SecretEnvVars {
+			out = out + fmt.Sprintln(envVar)
+		}
+		out = out + fmt.Sprintln("")
 	}
+	return out
 }
 
 func (m *WorkloadsModule) adminOnlyResults(headers []string, body [][]string) [][]string {
@@ -0,0 +1,123 @@
+package aws
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/sirupsen/logrus"
+)
+
+// collectWorkloads runs a single per-region getter and returns what it sends
+func collectWorkloads(getter func(string, *sync.WaitGroup, chan struct{}, chan Workload)) []Workload {
+	var workloads []Workload
+	dataReceiver := make(chan Workload)
+	done := make(chan bool)
+	go func() {
+		for workload := range dataReceiver {
+			workloads = append(workloads, workload)
+		}
+		done <- true
+	}()
+
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	getter("us-east-1", wg, make(chan struct{}, 1), dataReceiver)
+	wg.Wait()
+	close(dataReceiver)
+	<-done
+
+	sort.Slice(workloads, func(i, j int) bool {
+		return workloads[i].Name < workloads[j].Name
+	})
+	return workloads
+}
+
+func TestWorkloadsSecondTierServices(t *testing.T) {
+	m := WorkloadsModule{
+		AWSProfile: "unittesting",
+		Caller: sts.GetCallerIdentityOutput{
+			Arn:     aws.String("arn:aws:iam::666666666666:user/Alice"),
+			Account: aws.String("666666666666"),
+		},
+		AppRunnerClient:        &sdk.MockedAppRunnerClient{},
+		ElasticBeanstalkClient: &sdk.MockedElasticBeanstalkClient{},
+		LightsailClient:        &sdk.MockedLightsailClient{},
+		IAMClient:              &sdk.MockedIAMClient{},
+		modLog:                 internal.TxtLog.WithFields(logrus.Fields{"module": "workloads"}),
+	}
+
+	type summary struct {
+		name     string
+		endpoint string
+		public   string
+		role     string
+	}
+	subtests := []struct {
+		name     string
+		getter   func(string, *sync.WaitGroup, chan struct{}, chan Workload)
+		expected []summary
+	}{
+		{
+			name:   "App Runner",
+			getter: m.getAppRunnerWorkloadsPerRegion,
+			expected: []summary{
+				{"service1", "https://abcdefgh12.us-east-1.awsapprunner.com", "True", "arn:aws:iam::123456789012:role/service1-instance-role"},
+				{"service2", "https://ijklmnop34.us-east-1.awsapprunner.com", "False", "arn:aws:iam::123456789012:role/service2-instance-role"},
+			},
+		},
+		{
+			name:   "Elastic Beanstalk",
+			getter: m.getElasticBeanstalkWorkloadsPerRegion,
+			expected: []summary{
+				{"app1/app1-prod", "http://app1-prod.us-east-1.elasticbeanstalk.com", "True", "arn:aws:iam::123456789012:role/role1"},
+				{"app2/app2-worker", "", "", "arn:aws:iam::123456789012:role/role1"},
+			},
+		},
+		{
+			name:   "Lightsail",
+			getter: m.getLightsailWorkloadsPerRegion,
+			expected: []summary{
+				{"database1", "ls-0123456789abcdef.czowadgeezqi.us-east-1.rds.amazonaws.com:3306", "True", ""},
+				{"instance1", "1.2.3.4", "True", ""},
+				{"instance2", "2.3.4.4", "True", ""},
+			},
+		},
+	}
+
+	for _, subtest := range subtests {
+		t.Run(subtest.name, func(t *testing.T) {
+			workloads := collectWorkloads(subtest.getter)
+			var got []summary
+			for _, workload := range workloads {
+				got = append(got, summary{workload.Name, workload.Endpoint, workload.Public, workload.Role})
+				m.Workloads = append(m.Workloads, workload)
+			}
+			if !reflect.DeepEqual(got, subtest.expected) {
+				t.Errorf("Expected %v, got %v", subtest.expected, got)
+			}
+		})
+	}
+
+	urls := m.writePublicURLsLoot()
+	for _, expected := range []string{"https://abcdefgh12.us-east-1.awsapprunner.com", "http://app1-prod.us-east-1.elasticbeanstalk.com", "1.2.3.4"} {
+		if !strings.Contains(urls, expected) {
+			t.Errorf("Expected %s to be in the public URLs loot", expected)
+		}
+	}
+	if strings.Contains(urls, "ijklmnop34") {
+		t.Errorf("Did not expect the private App Runner service in the public URLs loot")
+	}
+
+	secrets := m.writeBeanstalkSecretsLoot()
+	expectedSecrets := "# app1/app1-prod (us-east-1)\nDB_PASSWORD=hunter2\nSTRIPE_API_KEY=sk_live_0123456789\n\n"
+	if secrets != expectedSecrets {
+		t.Errorf("Expected Beanstalk secrets loot %q, got %q", expectedSecrets, secrets)
+	}
+}
@@ -0,0 +1,552 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/profiles/latest/keyvault/mgmt/keyvault"
+	"github.com/BishopFox/cloudfox/globals"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/fatih/color"
+	"github.com/kyokomi/emoji"
+)
+
+// Number of vaults whose access is evaluated and contents listed at the same time
+const keyVaultsConcurrency = 10
+
+const (
+	keyVaultSecrets      = "secrets"
+	keyVaultKeys         = "keys"
+	keyVaultCertificates = "certificates"
+)
+
+type AzKeyVault struct {
+	SubscriptionID   string
+	SubscriptionName string
+	Name             string
+	ResourceGroup    string
+	Location         string
+	URI              string
+	RBACEnabled      bool
+	Access           keyVaultAccess
+	// Names of the items in the vault, keyed by item type. Missing when the caller can't list that type.
+	Items map[string][]string
+	// Listing errors, keyed by item type
+	ItemErrors map[string]error
+}
+
+// keyVaultAccess is what the caller is allowed to do on a vault's data plane
+type keyVaultAccess struct {
+	ReadSecrets      bool
+	ListSecrets      bool
+	ListKeys         bool
+	ListCertificates bool
+	// The access policies or role assignments the access comes from
+	Via []string
+}
+
+func (a *keyVaultAccess) add(other keyVaultAccess, via string) {
+	a.ReadSecrets = a.ReadSecrets || other.ReadSecrets
+	a.ListSecrets = a.ListSecrets || other.ListSecrets
+	a.ListKeys = a.ListKeys || other.ListKeys
+	a.ListCertificates = a.ListCertificates || other.ListCertificates
+	a.Via = append(a.Via, via)
+}
+
+func (a keyVaultAccess) canList(itemType string) bool {
+	switch itemType {
+	case keyVaultSecrets:
+		return a.ListSecrets
+	case keyVaultKeys:
+		return a.ListKeys
+	case keyVaultCertificates:
+		return a.ListCertificates
+	}
+	return false
+}
+
+type keyVaultDataRole struct {
+	name   string
+	access keyVaultAccess
+}
+
+// Built-in roles that grant access to the data plane of vaults using the RBAC permission model, keyed by role
+// definition ID
+var keyVaultDataRoles = map[string]keyVaultDataRole{
+	"00482a5a-887f-4fb3-b363-3b7fe8e74483": {"Key Vault Administrator", keyVaultAccess{ReadSecrets: true, ListSecrets: true, ListKeys: true, ListCertificates: true}},
+	"b86a8fe4-44ce-4948-aee5-eccb2c155cd7": {"Key Vault Secrets Officer", keyVaultAccess{ReadSecrets: true, ListSecrets: true}},
+	"4633458b-17de-408a-b874-0445c86b69e6": {"Key Vault Secrets User", keyVaultAccess{ReadSecrets: true, ListSecrets: true}},
+	"21090545-7ca7-4776-b22c-e363652d74d2": {"Key Vault Reader", keyVaultAccess{ListSecrets: true, ListKeys: true, ListCertificates: true}},
+	"14b46e9e-c2b7-41b4-b07b-48a6ebf60603": {"Key Vault Crypto Officer", keyVaultAccess{ListKeys: true}},
+	"a4417e6f-fecd-4de8-b567-7b0420556985": {"Key Vault Certificates Officer", keyVaultAccess{ListCertificates: true}},
+}
+
+var getAzureIdentity = internal.GetAzureIdentity
+
+func AzKeyVaultsCommand(AzTenantID, AzSubscription, AzOutputFormat, AzOutputDirectory, Version string, AzVerbosity int, AzWrapTable bool, AzMergedTable bool) error {
+	identity, err := getAzureIdentity()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("[%s][%s] Authenticated as %s (%s %s) using %s\n",
+		color.CyanString(emoji.Sprintf(":fox:cloudfox %s :fox:", Version)), color.CyanString(globals.AZ_KEYVAULTS_MODULE_NAME),
+		identity.Name, identity.Type, identity.ObjectID, identity.Source)
+
+	if AzTenantID != "" && AzSubscription == "" {
+		// cloudfox azure keyvaults --tenant [TENANT_ID | PRIMARY_DOMAIN]
+		tenantInfo := populateTenant(AzTenantID)
+
+		if AzMergedTable {
+			o := internal.OutputClient{
+				Verbosity:     AzVerbosity,
+				CallingModule: globals.AZ_KEYVAULTS_MODULE_NAME,
+				Table: internal.TableClient{
+					Wrap: AzWrapTable,
+				},
+			}
+			fmt.Printf("[%s][%s] Enumerating key vaults for tenant %s\n",
+				color.CyanString(emoji.Sprintf(":fox:cloudfox %s :fox:", Version)), color.CyanString(globals.AZ_KEYVAULTS_MODULE_NAME),
+				fmt.Sprintf("%s (%s)", ptr.ToString(tenantInfo.DefaultDomain), ptr.ToString(tenantInfo.ID)))
+
+			o.PrefixIdentifier = ptr.ToString(tenantInfo.DefaultDomain)
+			o.Table.DirectoryName = filepath.Join(AzOutputDirectory, globals.CLOUDFOX_BASE_DIRECTORY, globals.AZ_DIR_BASE, ptr.ToString(tenantInfo.DefaultDomain), "1-tenant-level")
+			o.Loot.DirectoryName = o.Table.DirectoryName
+
+			var vaults []AzKeyVault
+			for _, s := range GetSubscriptionsPerTenantID(ptr.ToString(tenantInfo.ID)) {
+				vaults = append(vaults, getKeyVaultsPerSubscription(identity, ptr.ToString(s.SubscriptionID))...)
+			}
+			writeKeyVaultsOutput(o, vaults)
+		} else {
+			for _, s := range GetSubscriptionsPerTenantID(ptr.ToString(tenantInfo.ID)) {
+				runKeyVaultsCommandForSingleSubscription(identity, ptr.ToString(s.SubscriptionID), AzOutputDirectory, AzVerbosity, AzWrapTable, Version)
+			}
+		}
+
+	} else if AzTenantID == "" && AzSubscription != "" {
+		// cloudfox azure keyvaults --subscription [SUBSCRIPTION_ID | SUBSCRIPTION_NAME]
+		runKeyVaultsCommandForSingleSubscription(identity, AzSubscription, AzOutputDirectory, AzVerbosity, AzWrapTable, Version)
+
+	} else {
+		// cloudfox azure keyvaults
+		// Without a tenant or subscription, every subscription the credentials can see is enumerated
+		for _, s := range GetSubscriptions() {
+			runKeyVaultsCommandForSingleSubscription(identity, ptr.ToString(s.SubscriptionID), AzOutputDirectory, AzVerbosity, AzWrapTable, Version)
+		}
+	}
+
+	return nil
+}
+
+func runKeyVaultsCommandForSingleSubscription(identity internal.AzureIdentity, AzSubscription string, AzOutputDirectory string, AzVerbosity int, AzWrapTable bool, Version string) {
+	// setup logging client
+	o := internal.OutputClient{
+		Verbosity:     AzVerbosity,
+		CallingModule: globals.AZ_KEYVAULTS_MODULE_NAME,
+		Table: internal.TableClient{
+			Wrap: AzWrapTable,
+		},
+	}
+
+	tenantID := ptr.ToString(GetTenantIDPerSubscription(AzSubscription))
+	tenantInfo := populateTenant(tenantID)
+	AzSubscriptionInfo := PopulateSubsriptionType(AzSubscription)
+	o.PrefixIdentifier = AzSubscriptionInfo.Name
+	o.Table.DirectoryName = filepath.Join(AzOutputDirectory, globals.CLOUDFOX_BASE_DIRECTORY, globals.AZ_DIR_BASE, ptr.ToString(tenantInfo.DefaultDomain), AzSubscriptionInfo.Name)
+	o.Loot.DirectoryName = o.Table.DirectoryName
+
+	fmt.Printf(
+		"[%s][%s] Enumerating key vaults for subscription %s\n",
+		color.CyanString(emoji.Sprintf(":fox:cloudfox %s :fox:", Version)),
+		color.CyanString(globals.AZ_KEYVAULTS_MODULE_NAME),
+		fmt.Sprintf("%s (%s)", AzSubscriptionInfo.Name, AzSubscriptionInfo.ID))
+
+	writeKeyVaultsOutput(o, getKeyVaultsPerSubscription(identity, AzSubscriptionInfo.ID))
+}
+
+func writeKeyVaultsOutput(o internal.OutputClient, vaults []AzKeyVault) {
+	header, body, itemsHeader, itemsBody := getKeyVaultsTables(vaults)
+	if body == nil {
+		fmt.Printf("[%s][%s] No key vaults found, skipping the creation of an output file.\n", cyan(o.CallingModule), cyan(o.PrefixIdentifier))
+		return
+	}
+
+	o.Table.TableFiles = append(o.Table.TableFiles,
+		internal.TableFile{
+			Header: header,
+			Body:   body,
+			Name:   globals.AZ_KEYVAULTS_MODULE_NAME})
+	if itemsBody != nil {
+		o.Table.TableFiles = append(o.Table.TableFiles,
+			internal.TableFile{
+				Header: itemsHeader,
+				Body:   itemsBody,
+				Name:   globals.AZ_KEYVAULTS_MODULE_NAME + "-items"})
+	}
+
+	var lootFiles []internal.LootFile
+	if loot := getKeyVaultsLoot(vaults); loot != "" {
+		lootFiles = append(lootFiles, internal.LootFile{
+			Name:     "keyvault-secret-commands",
+			Contents: loot,
+		})
+	}
+	o.WriteFullOutput(o.Table.TableFiles, lootFiles)
+}
+
+// getKeyVaultsPerSubscription lists the vaults of a subscription, works out what the caller can do on each of them
+// and lists their contents where it can
+func getKeyVaultsPerSubscription(identity internal.AzureIdentity, subscriptionID string) []AzKeyVault {
+	var results []AzKeyVault
+	vaults, err := getKeyVaults(subscriptionID)
+	if err != nil {
+		fmt.Printf("[%s] failed to get key vaults for subscription %s: %s. Skipping it.\n", color.New(color.FgCyan).Sprint(globals.AZ_KEYVAULTS_MODULE_NAME), subscriptionID, err)
+		return results
+	}
+
+	var roleAssignments []keyVaultRoleAssignment
+	for _, v := range vaults {
+		if v.Properties != nil && ptr.ToBool(v.Properties.EnableRbacAuthorization) {
+			roleAssignments, err = getKeyVaultRoleAssignments(identity, subscriptionID)
+			if err != nil {
+				fmt.Printf("[%s] failed to get role assignments for subscription %s: %s. Access to RBAC vaults is unknown.\n", color.New(color.FgCyan).Sprint(globals.AZ_KEYVAULTS_MODULE_NAME), subscriptionID, err)
+			}
+			break
+		}
+	}
+
+	subscriptionName := ptr.ToString(GetSubscriptionNameFromID(subscriptionID))
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, keyVaultsConcurrency)
+	dataReceiver := make(chan AzKeyVault)
+	receiverDone := make(chan bool)
+	go func() {
+		defer close(receiverDone)
+		for vault := range dataReceiver {
+			results = append(results, vault)
+		}
+	}()
+
+	for _, v := range vaults {
+		vault := AzKeyVault{
+			SubscriptionID:   subscriptionID,
+			SubscriptionName: subscriptionName,
+			Name:             ptr.ToString(v.Name),
+			ResourceGroup:    resourceGroupFromID(ptr.ToString(v.ID)),
+			Location:         ptr.ToString(v.Location),
+			Items:            make(map[string][]string),
+			ItemErrors:       make(map[string]error),
+		}
+		if v.Properties != nil {
+			vault.URI = ptr.ToString(v.Properties.VaultURI)
+			vault.RBACEnabled = ptr.ToBool(v.Properties.EnableRbacAuthorization)
+		}
+		if vault.RBACEnabled {
+			vault.Access = keyVaultAccessFromRoleAssignments(ptr.ToString(v.ID), roleAssignments)
+		} else {
+			vault.Access = keyVaultAccessFromPolicies(identity, v.Properties)
+		}
+
+		wg.Add(1)
+		go func(vault AzKeyVault) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() {
+				<-semaphore
+			}()
+			for _, itemType := range []string{keyVaultSecrets, keyVaultKeys, keyVaultCertificates} {
+				if !vault.Access.canList(itemType) || vault.URI == "" {
+					continue
+				}
+				names, err := listKeyVaultItems(vault.URI, itemType)
+				if err != nil {
+					vault.ItemErrors[itemType] = err
+					continue
+				}
+				vault.Items[itemType] = names
+			}
+			dataReceiver <- vault
+		}(vault)
+	}
+
+	wg.Wait()
+	close(dataReceiver)
+	<-receiverDone
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
+
+// keyVaultAccessFromPolicies evaluates the access policies of a vault using the access policy permission model. Only
+// policies for the caller's own object ID are considered, group memberships aren't resolved.
+func keyVaultAccessFromPolicies(identity internal.AzureIdentity, properties *keyvault.VaultProperties) keyVaultAccess {
+	var access keyVaultAccess
+	if properties == nil || properties.AccessPolicies == nil {
+		return access
+	}
+	for _, policy := range *properties.AccessPolicies {
+		if !strings.EqualFold(ptr.ToString(policy.ObjectID), identity.ObjectID) || policy.Permissions == nil {
+			continue
+		}
+		access.add(keyVaultAccess{
+			ReadSecrets:      keyVaultPermissionsInclude(policy.Permissions.Secrets, keyvault.SecretPermissionsGet),
+			ListSecrets:      keyVaultPermissionsInclude(policy.Permissions.Secrets, keyvault.SecretPermissionsList),
+			ListKeys:         keyVaultPermissionsInclude(policy.Permissions.Keys, keyvault.KeyPermissionsList),
+			ListCertificates: keyVaultPermissionsInclude(policy.Permissions.Certificates, keyvault.CertificatePermissionsList),
+		}, "Access policy")
+	}
+	return access
+}
+
+func keyVaultPermissionsInclude[T ~string](permissions *[]T, wanted T) bool {
+	if permissions == nil {
+		return false
+	}
+	for _, permission := range *permissions {
+		if strings.EqualFold(string(permission), string(wanted)) || strings.EqualFold(string(permission), "all") {
+			return true
+		}
+	}
+	return false
+}
+
+type keyVaultRoleAssignment struct {
+	scope            string
+	roleDefinitionID string
+}
+
+// getKeyVaultRoleAssignments returns the role assignments of the caller in a subscription
+func getKeyVaultRoleAssignments(identity internal.AzureIdentity, subscriptionID string) ([]keyVaultRoleAssignment, error) {
+	var results []keyVaultRoleAssignment
+	roleAssignments, err := getRoleAssignments(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ra := range roleAssignments {
+		if ra.Properties == nil || !strings.EqualFold(ptr.ToString(ra.Properties.PrincipalID), identity.ObjectID) {
+			continue
+		}
+		roleDefinitionID := ptr.ToString(ra.Properties.RoleDefinitionID)
+		results = append(results, keyVaultRoleAssignment{
+			scope:            ptr.ToString(ra.Properties.Scope),
+			roleDefinitionID: roleDefinitionID[strings.LastIndex(roleDefinitionID, "/")+1:],
+		})
+	}
+	return results, nil
+}
+
+// keyVaultAccessFromRoleAssignments evaluates the caller's role assignments for a vault using the RBAC permission
+// model. Assignments at the vault, its resource group or its subscription all apply.
+func keyVaultAccessFromRoleAssignments(vaultID string, roleAssignments []keyVaultRoleAssignment) keyVaultAccess {
+	var access keyVaultAccess
+	for _, ra := range roleAssignments {
+		role, ok := keyVaultDataRoles[strings.ToLower(ra.roleDefinitionID)]
+		if !ok {
+			continue
+		}
+		scope := strings.ToLower(strings.TrimSuffix(ra.scope, "/"))
+		id := strings.ToLower(vaultID)
+		if scope == "" || id == scope || strings.HasPrefix(id, scope+"/") {
+			access.add(role.access, role.name)
+		}
+	}
+	return access
+}
+
+func getKeyVaultsTables(vaults []AzKeyVault) ([]string, [][]string, []string, [][]string) {
+	header := []string{"Subscription Name", "Vault Name", "Resource Group", "Location", "Permission Model", "Caller Can Read Secrets", "Access Via", "Secrets", "Keys", "Certificates"}
+	itemsHeader := []string{"Subscription Name", "Vault Name", "Type", "Name"}
+	var body, itemsBody [][]string
+
+	for _, vault := range vaults {
+		permissionModel := "Access policies"
+		if vault.RBACEnabled {
+			permissionModel = "RBAC"
+		}
+		canRead := "No"
+		if vault.Access.ReadSecrets {
+			canRead = "Yes"
+		}
+		body = append(body,
+			[]string{
+				vault.SubscriptionName,
+				vault.Name,
+				vault.ResourceGroup,
+				vault.Location,
+				permissionModel,
+				canRead,
+				strings.Join(vault.Access.Via, ", "),
+				vault.itemCount(keyVaultSecrets),
+				vault.itemCount(keyVaultKeys),
+				vault.itemCount(keyVaultCertificates),
+			})
+
+		for _, itemType := range []string{keyVaultSecrets, keyVaultKeys, keyVaultCertificates} {
+			for _, name := range vault.Items[itemType] {
+				itemsBody = append(itemsBody,
+					[]string{
+						vault.SubscriptionName,
+						vault.Name,
+						strings.TrimSuffix(itemType, "s"),
+						name,
+					})
+			}
+		}
+	}
+	return header, body, itemsHeader, itemsBody
+}
+
+func (v AzKeyVault) itemCount(itemType string) string {
+	if _, ok := v.ItemErrors[itemType]; ok {
+		return "Error"
+	}
+	names, ok := v.Items[itemType]
+	if !ok {
+		return "No access"
+	}
+	return strconv.Itoa(len(names))
+}
+
+func getKeyVaultsLoot(vaults []AzKeyVault) string {
+	var loot strings.Builder
+	for _, vault := range vaults {
+		for _, name := range vault.Items[keyVaultSecrets] {
+			loot.WriteString(fmt.Sprintf("az keyvault secret show --subscription %s --vault-name %s --name %s --query value -o tsv\n", vault.SubscriptionID, vault.Name, name))
+		}
+	}
+	return loot.String()
+}
+
+func resourceGroupFromID(id string) string {
+	parts := strings.Split(id, "/")
+	for i, part := range parts {
+		if strings.EqualFold(part, "resourceGroups") && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+var getKeyVaults = getKeyVaultsOriginal
+
+func getKeyVaultsOriginal(subscriptionID string) ([]keyvault.Vault, error) {
+	client := internal.GetKeyVaultsClient(subscriptionID)
+	var vaults []keyvault.Vault
+	page, err := client.ListBySubscription(context.TODO(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not get key vaults for subscription: %s", err)
+	}
+	for page.NotDone() {
+		vaults = append(vaults, page.Values()...)
+		err = page.NextWithContext(context.TODO())
+		if err != nil {
+			return nil, fmt.Errorf("could not get key vaults for subscription: %s", err)
+		}
+	}
+	return vaults, nil
+}
+
+func mockedGetKeyVaults(subscriptionID string) ([]keyvault.Vault, error) {
+	testFile, err := os.ReadFile(globals.KEY_VAULTS_TEST_FILE)
+	if err != nil {
+		return nil, fmt.Errorf("could not open key vaults test file %s", globals.KEY_VAULTS_TEST_FILE)
+	}
+	var vaultsAll, vaultsResults []keyvault.Vault
+	err = json.Unmarshal(testFile, &vaultsAll)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshall key vaults test file %s", globals.KEY_VAULTS_TEST_FILE)
+	}
+	for _, v := range vaultsAll {
+		vaultSubID := strings.Split(ptr.ToString(v.ID), "/")[2]
+		if vaultSubID == subscriptionID {
+			vaultsResults = append(vaultsResults, v)
+		}
+	}
+	return vaultsResults, nil
+}
+
+var listKeyVaultItems = listKeyVaultItemsOriginal
+
+// listKeyVaultItemsOriginal returns the names of the secrets, keys or certificates stored in a vault
+func listKeyVaultItemsOriginal(vaultURI string, itemType string) ([]string, error) {
+	client, err := internal.GetKeyVaultDataClient()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	switch itemType {
+	case keyVaultSecrets:
+		page, err := client.GetSecrets(context.TODO(), vaultURI, nil)
+		for ; err == nil && page.NotDone(); err = page.NextWithContext(context.TODO()) {
+			for _, item := range page.Values() {
+				names = append(names, keyVaultItemName(ptr.ToString(item.ID)))
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	case keyVaultKeys:
+		page, err := client.GetKeys(context.TODO(), vaultURI, nil)
+		for ; err == nil && page.NotDone(); err = page.NextWithContext(context.TODO()) {
+			for _, item := range page.Values() {
+				names = append(names, keyVaultItemName(ptr.ToString(item.Kid)))
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	case keyVaultCertificates:
+		page, err := client.GetCertificates(context.TODO(), vaultURI, nil, nil)
+		for ; err == nil && page.NotDone(); err = page.NextWithContext(context.TODO()) {
+			for _, item := range page.Values() {
+				names = append(names, keyVaultItemName(ptr.ToString(item.ID)))
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+// keyVaultItemName returns the name out of an item identifier such as https://vault.vault.azure.net/secrets/name
+func keyVaultItemName(id string) string {
+	return id[strings.LastIndex(strings.TrimSuffix(id, "/"), "/")+1:]
+}
+
+func mockedListKeyVaultItems(vaultURI string, itemType string) ([]string, error) {
+	testFile, err := os.ReadFile(globals.KEY_VAULT_ITEMS_TEST_FILE)
+	if err != nil {
+		return nil, fmt.Errorf("could not open key vault items test file %s", globals.KEY_VAULT_ITEMS_TEST_FILE)
+	}
+	var items map[string]map[string][]string
+	err = json.Unmarshal(testFile, &items)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshall key vault items test file %s", globals.KEY_VAULT_ITEMS_TEST_FILE)
+	}
+	vaultItems, ok := items[vaultURI]
+	if !ok {
+		return nil, fmt.Errorf("Forbidden: the caller is not authorized to list %s in %s", itemType, vaultURI)
+	}
+	return vaultItems[itemType], nil
+}
+
+func mockedGetAzureIdentity() (internal.AzureIdentity, error) {
+	return internal.AzureIdentity{
+		Name:     "test_username1@REDACTED.onmicrosoft.com",
+		ObjectID: "8da340e3-5e2e-4f55-a3f7-4ea20d6755be",
+		TenantID: "11111111-1111-1111-1111-11111111",
+		Type:     "User",
+		Source:   "az cli",
+	}, nil
+}
@@ -0,0 +1,145 @@
+package azure
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/BishopFox/cloudfox/globals"
+	"github.com/BishopFox/cloudfox/internal"
+)
+
+func TestAzKeyVaultsCommand(t *testing.T) {
+	fmt.Println()
+	fmt.Println("[test case] Azure Key Vaults")
+
+	// Test case parameters
+	subtests := []struct {
+		name              string
+		AzTenantID        string
+		AzSubscriptionID  string
+		AzOutputFormat    string
+		azOutputDirectory string
+		AzVerbosity       int
+		version           string
+		wrapTableOutput   bool
+		azMergedTable     bool
+	}{
+		{
+			name:              "./cloudfox az keyvaults --tenant 11111111-1111-1111-1111-11111111",
+			AzTenantID:        "11111111-1111-1111-1111-11111111",
+			AzOutputFormat:    "all",
+			azOutputDirectory: "~/.cloudfox",
+			AzVerbosity:       2,
+			version:           "DEV",
+		},
+		{
+			name:              "./cloudfox az keyvaults --tenant 11111111-1111-1111-1111-11111111 --merged-table",
+			AzTenantID:        "11111111-1111-1111-1111-11111111",
+			AzOutputFormat:    "all",
+			azOutputDirectory: "~/.cloudfox",
+			AzVerbosity:       2,
+			version:           "DEV",
+			azMergedTable:     true,
+		},
+		{
+			name:              "./cloudfox az keyvaults --subscription AAAAAAAA-AAAA-AAAA-AAAA-AAAAAAAA",
+			AzSubscriptionID:  "AAAAAAAA-AAAA-AAAA-AAAA-AAAAAAAA",
+			AzOutputFormat:    "all",
+			azOutputDirectory: "~/.cloudfox",
+			AzVerbosity:       3,
+			version:           "DEV",
+		},
+		{
+			name:              "./cloudfox az keyvaults",
+			AzOutputFormat:    "all",
+			azOutputDirectory: "~/.cloudfox",
+			AzVerbosity:       2,
+			version:           "DEV",
+		},
+	}
+	internal.MockFileSystem(true)
+	// Mocked functions to simulate Azure calls and responses
+	getTenants = mockedGetTenants
+	GetSubscriptions = mockedGetSubscriptions
+	getAzureIdentity = mockedGetAzureIdentity
+	getKeyVaults = mockedGetKeyVaults
+	listKeyVaultItems = mockedListKeyVaultItems
+	getRoleAssignments = mockedGetRoleAssignments
+	globals.RESOURCES_TEST_FILE = "./test-data/resources.json"
+	globals.KEY_VAULTS_TEST_FILE = "./test-data/keyvaults.json"
+	globals.KEY_VAULT_ITEMS_TEST_FILE = "./test-data/keyvault-items.json"
+	globals.ROLE_ASSIGNMENTS_TEST_FILE = "./test-data/keyvault-role-assignments.json"
+
+	for _, s := range subtests {
+		fmt.Println()
+		fmt.Printf("[subtest] %s\n", s.name)
+
+		err := AzKeyVaultsCommand(s.AzTenantID, s.AzSubscriptionID, s.AzOutputFormat, s.azOutputDirectory, s.version, s.AzVerbosity, s.wrapTableOutput, s.azMergedTable)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func TestGetKeyVaultsPerSubscription(t *testing.T) {
+	getTenants = mockedGetTenants
+	GetSubscriptions = mockedGetSubscriptions
+	getKeyVaults = mockedGetKeyVaults
+	listKeyVaultItems = mockedListKeyVaultItems
+	getRoleAssignments = mockedGetRoleAssignments
+	globals.RESOURCES_TEST_FILE = "./test-data/resources.json"
+	globals.KEY_VAULTS_TEST_FILE = "./test-data/keyvaults.json"
+	globals.KEY_VAULT_ITEMS_TEST_FILE = "./test-data/keyvault-items.json"
+	globals.ROLE_ASSIGNMENTS_TEST_FILE = "./test-data/keyvault-role-assignments.json"
+
+	identity, _ := mockedGetAzureIdentity()
+	vaults := getKeyVaultsPerSubscription(identity, "AAAAAAAA-AAAA-AAAA-AAAA-AAAAAAAA")
+	vaults = append(vaults, getKeyVaultsPerSubscription(identity, "BBBBBBBB-BBBB-BBBB-BBBB-BBBBBBBB")...)
+
+	expected := map[string]struct {
+		canRead      bool
+		via          string
+		secrets      string
+		keys         string
+		certificates string
+	}{
+		// Access policy granting get and list on secrets and list on keys
+		"kv-app-prod": {canRead: true, via: "Access policy", secrets: "2", keys: "1", certificates: "No access"},
+		// RBAC vault, the caller is a Key Vault Secrets User on the vault
+		"kv-shared": {canRead: true, via: "Key Vault Secrets User", secrets: "1", keys: "No access", certificates: "No access"},
+		// Only someone else has an access policy
+		"kv-locked": {canRead: false, via: "", secrets: "No access", keys: "No access", certificates: "No access"},
+		// The access policy allows everything on secrets but listing them fails
+		"kv-payments": {canRead: true, via: "Access policy", secrets: "Error", keys: "No access", certificates: "No access"},
+	}
+	if len(vaults) != len(expected) {
+		t.Fatalf("Expected %d vaults, got %d", len(expected), len(vaults))
+	}
+	for _, vault := range vaults {
+		want, ok := expected[vault.Name]
+		if !ok {
+			t.Errorf("Unexpected vault %s", vault.Name)
+			continue
+		}
+		if vault.Access.ReadSecrets != want.canRead {
+			t.Errorf("Expected read access to secrets in %s to be %t", vault.Name, want.canRead)
+		}
+		if strings.Join(vault.Access.Via, ", ") != want.via {
+			t.Errorf("Expected access to %s via %q, got %q", vault.Name, want.via, strings.Join(vault.Access.Via, ", "))
+		}
+		if vault.itemCount(keyVaultSecrets) != want.secrets || vault.itemCount(keyVaultKeys) != want.keys || vault.itemCount(keyVaultCertificates) != want.certificates {
+			t.Errorf("Unexpected item counts for %s: %s secrets, %s keys, %s certificates", vault.Name, vault.itemCount(keyVaultSecrets), vault.itemCount(keyVaultKeys), vault.itemCount(keyVaultCertificates))
+		}
+	}
+
+	loot := getKeyVaultsLoot(vaults)
+	expectedCommand := "az keyvault secret show --subscription AAAAAAAA-AAAA-AAAA-AAAA-AAAAAAAA --vault-name kv-app-prod --name db-password --query value -o tsv\n"
+	if !strings.Contains(loot, expectedCommand) {
+		t.Errorf("Expected the loot to contain %q", expectedCommand)
+	}
+	if strings.Contains(loot, "break-glass-password") {
+		t.Errorf("Did not expect secrets of vaults the caller can't list to be in the loot")
+	}
+}
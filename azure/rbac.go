@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -19,7 +20,7 @@ import (
 	"github.com/kyokomi/emoji"
 )
 
-func AzRBACCommand(AzTenantID, AzSubscription, AzOutputFormat, AzOutputDirectory, Version string, AzVerbosity int, AzWrapTable bool, AzMergedTable bool) error {
+func AzRBACCommand(AzTenantID, AzSubscription, AzOutputFormat, AzOutputDirectory, Version string, AzVerbosity int, AzWrapTable bool, AzMergedTable bool, AzPrincipal string, AzManagementGroups []string) error {
 	// setup logging client
 	o := internal.OutputClient{
 		Verbosity:     AzVerbosity,
@@ -29,7 +30,10 @@ func AzRBACCommand(AzTenantID, AzSubscription, AzOutputFormat, AzOutputDirectory
 		},
 	}
 	// initiate command specific client
-	var c CloudFoxRBACclient
+	c := CloudFoxRBACclient{
+		principalFilter:  AzPrincipal,
+		managementGroups: AzManagementGroups,
+	}
 	// set up table vars
 	var header []string
 	var body [][]string
@@ -51,7 +55,7 @@ func AzRBACCommand(AzTenantID, AzSubscription, AzOutputFormat, AzOutputDirectory
 			color.CyanString(emoji.Sprintf(":fox:cloudfox %s :fox:", Version)), color.CyanString(globals.AZ_RBAC_MODULE_NAME),
 			fmt.Sprintf("%s (%s)", ptr.ToString(tenantInfo.DefaultDomain), ptr.ToString(tenantInfo.ID)))
 
-		header, body, err = getRBACperTenant(ptr.ToString(tenantInfo.ID), c)
+		header, body, err = getRBACperTenant(ptr.ToString(tenantInfo.ID), &c)
 		if err != nil {
 			return err
 		}
@@ -71,7 +75,7 @@ func AzRBACCommand(AzTenantID, AzSubscription, AzOutputFormat, AzOutputDirectory
 
 		fmt.Printf("[%s][%s] Enumerating RBAC permissions for subscription %s\n", color.CyanString(emoji.Sprintf(":fox:cloudfox %s :fox:", Version)), color.CyanString(globals.AZ_RBAC_MODULE_NAME),
 			fmt.Sprintf("%s (%s)", AzSubscriptionInfo.Name, AzSubscriptionInfo.ID))
-		header, body = getRBACperSubscription(ptr.ToString(tenantInfo.ID), AzSubscriptionInfo.ID, c)
+		header, body = getRBACperSubscription(ptr.ToString(tenantInfo.ID), AzSubscriptionInfo.ID, &c)
 		o.Table.TableFiles = append(o.Table.TableFiles,
 			internal.TableFile{
 				Header: header,
@@ -86,14 +90,17 @@ func AzRBACCommand(AzTenantID, AzSubscription, AzOutputFormat, AzOutputDirectory
 	if body != nil {
 		//internal.OutputSelector(AzVerbosity, AzOutputFormat, header, body, outputDirectory, fileNameWithoutExtension, globals.AZ_RBAC_MODULE_NAME, AzWrapTable, controlMessagePrefix)
 		o.WriteFullOutput(o.Table.TableFiles, nil)
-
+		if dangerous := c.dangerousAssignmentCount(); dangerous > 0 {
+			fmt.Printf("[%s][%s] %d role assignments use custom roles with dangerous actions.\n", color.CyanString(emoji.Sprintf(":fox:cloudfox %s :fox:", Version)), color.CyanString(globals.AZ_RBAC_MODULE_NAME), dangerous)
+		}
+	} else if AzPrincipal != "" {
+		fmt.Printf("[%s][%s] No role assignments found for principal %s.\n", color.CyanString(emoji.Sprintf(":fox:cloudfox %s :fox:", Version)), color.CyanString(globals.AZ_RBAC_MODULE_NAME), AzPrincipal)
 	}
 	return nil
 }
 
-func getRBACperTenant(AzTenantID string, c CloudFoxRBACclient) ([]string, [][]string, error) {
-	var selectedSubs, resultsHeader []string
-	var resultsBody, b [][]string
+func getRBACperTenant(AzTenantID string, c *CloudFoxRBACclient) ([]string, [][]string, error) {
+	var selectedSubs []string
 	for _, s := range GetSubscriptions() {
 		if ptr.ToString(s.TenantID) == AzTenantID {
 			selectedSubs = append(selectedSubs, ptr.ToString(s.SubscriptionID))
@@ -103,20 +110,17 @@ func getRBACperTenant(AzTenantID string, c CloudFoxRBACclient) ([]string, [][]st
 	if err != nil {
 		return nil, nil, err
 	}
-	for _, s := range selectedSubs {
-		resultsHeader, b = c.GetRelevantRBACData(AzTenantID, s)
-		resultsBody = append(resultsBody, b...)
-	}
-	return resultsHeader, resultsBody, nil
+	header, body := c.GetRelevantRBACData()
+	return header, body, nil
 }
 
-func getRBACperSubscription(AzTenantID, AzSubscriptionID string, c CloudFoxRBACclient) ([]string, [][]string) {
+func getRBACperSubscription(AzTenantID, AzSubscriptionID string, c *CloudFoxRBACclient) ([]string, [][]string) {
 	var resultsHeader []string
 	var resultsBody [][]string
 	for _, s := range GetSubscriptions() {
 		if ptr.ToString(s.SubscriptionID) == AzSubscriptionID {
 			c.initialize(AzTenantID, []string{ptr.ToString(s.SubscriptionID)})
-			resultsHeader, resultsBody = c.GetRelevantRBACData(AzTenantID, ptr.ToString(s.SubscriptionID))
+			resultsHeader, resultsBody = c.GetRelevantRBACData()
 		}
 	}
 	return resultsHeader, resultsBody
@@ -125,19 +129,50 @@ func getRBACperSubscription(AzTenantID, AzSubscriptionID string, c CloudFoxRBACc
 type CloudFoxRBACclient struct {
 	roleAssignments []authorization.RoleAssignment
 	roleDefinitions []authorization.RoleDefinition
-	AADUsers        []graphrbac.User
+	// principals maps object IDs to what Graph knows about them. It stays empty when Graph can't be queried.
+	principals map[string]AzPrincipal
+
+	// principalFilter limits the output to one principal, given by object ID, display name or UPN
+	principalFilter  string
+	managementGroups []string
+	results          []AzRoleAssignment
+}
+
+// AzPrincipal is a user, group or service principal a role is assigned to
+type AzPrincipal struct {
+	ObjectID          string `json:"objectId"`
+	DisplayName       string `json:"displayName"`
+	UserPrincipalName string `json:"userPrincipalName,omitempty"`
+	Type              string `json:"objectType"`
+}
+
+type AzRoleAssignment struct {
+	PrincipalID   string
+	PrincipalName string
+	PrincipalUPN  string
+	PrincipalType string
+	RoleName      string
+	RoleType      string
+	Scope         string
+	// DangerousActions are the actions of a custom role that allow granting access or reading keys
+	DangerousActions []string
+}
+
+// Actions that let a custom role hand out access or read account keys. The concrete operations catch grants like
+// Microsoft.Authorization/roleAssignments/* that overlap the patterns without matching them.
+var rbacDangerousActions = []string{
+	"Microsoft.Authorization/*/write",
+	"*/listKeys/action",
+	"Microsoft.Authorization/roleAssignments/write",
+	"Microsoft.Authorization/roleDefinitions/write",
+	"Microsoft.Storage/storageAccounts/listKeys/action",
 }
 
 func (c *CloudFoxRBACclient) initialize(tenantID string, subscriptionIDs []string) error {
-	var err error
-	c.AADUsers = nil
+	c.principals = make(map[string]AzPrincipal)
 	c.roleAssignments = nil
 	c.roleDefinitions = nil
-
-	c.AADUsers, err = getAzureADUsers(tenantID)
-	if err != nil {
-		return fmt.Errorf("[%s] failed to get users for tenant %s: %s", color.New(color.FgCyan).Sprint(globals.AZ_RBAC_MODULE_NAME), tenantID, err)
-	}
+	c.results = nil
 
 	for _, subID := range subscriptionIDs {
 		rd, err := getRoleDefinitions(subID)
@@ -152,68 +187,180 @@ func (c *CloudFoxRBACclient) initialize(tenantID string, subscriptionIDs []strin
 		}
 		c.roleAssignments = append(c.roleAssignments, ra...)
 	}
+	for _, managementGroup := range c.managementGroups {
+		scope := fmt.Sprintf("/providers/Microsoft.Management/managementGroups/%s", managementGroup)
+		ra, err := getRoleAssignmentsForScope(scope)
+		if err != nil {
+			fmt.Printf("[%s] failed to get role assignments for management group %s: %s. Skipping it.\n", color.New(color.FgCyan).Sprint(globals.AZ_RBAC_MODULE_NAME), managementGroup, err)
+		}
+		c.roleAssignments = append(c.roleAssignments, ra...)
+	}
+
+	var principalIDs []string
+	for _, ra := range c.roleAssignments {
+		principalID := ptr.ToString(ra.Properties.PrincipalID)
+		if !internal.Contains(principalID, principalIDs) {
+			principalIDs = append(principalIDs, principalID)
+		}
+	}
+	principals, err := resolvePrincipals(tenantID, principalIDs)
+	if err != nil {
+		// Reading the directory needs its own permissions, the role assignments are still worth showing without names
+		fmt.Printf("[%s] failed to resolve principal names for tenant %s: %s. Showing object IDs instead.\n", color.New(color.FgCyan).Sprint(globals.AZ_RBAC_MODULE_NAME), tenantID, err)
+	}
+	for id, principal := range principals {
+		c.principals[id] = principal
+	}
 	return nil
 }
 
-func (c *CloudFoxRBACclient) GetRelevantRBACData(tenantID, subscriptionID string) ([]string, [][]string) {
-	header := []string{"User Name", "Role Name", "Role Scope"}
+func (c *CloudFoxRBACclient) GetRelevantRBACData() ([]string, [][]string) {
+	header := []string{"Principal", "Principal Type", "Principal ID", "Role Name", "Role Type", "Role Scope", "Dangerous Actions"}
 	var body [][]string
-	var roleAssignmentRelevantData RoleAssignmentRelevantData
-	var results []RoleAssignmentRelevantData
+	// Assignments inherited from a management group show up once for every subscription below it
+	var seen []string
 
 	for _, rb := range c.roleAssignments {
-		roleAssignmentRelevantData.tenantID = tenantID
-		roleAssignmentRelevantData.subscriptionID = subscriptionID
-		roleAssignmentRelevantData.roleScope = ptr.ToString(rb.Properties.Scope)
-		findUser(c.AADUsers, rb, &roleAssignmentRelevantData)
-		findRole(c.roleDefinitions, rb, &roleAssignmentRelevantData)
-		results = append(results, roleAssignmentRelevantData)
-	}
-	// Sort the results by userDisplayName using slice.Sort
-	sortedResults := results
-	sort.Slice(sortedResults, func(i, j int) bool {
-		return sortedResults[i].userDisplayName < sortedResults[j].userDisplayName
+		assignment := AzRoleAssignment{
+			PrincipalID:   ptr.ToString(rb.Properties.PrincipalID),
+			PrincipalName: ptr.ToString(rb.Properties.PrincipalID),
+			PrincipalType: "Unknown",
+			Scope:         ptr.ToString(rb.Properties.Scope),
+		}
+		findPrincipal(c.principals, &assignment)
+		findRole(c.roleDefinitions, rb, &assignment)
+
+		key := strings.ToLower(strings.Join([]string{assignment.PrincipalID, lastIDSegment(ptr.ToString(rb.Properties.RoleDefinitionID)), assignment.Scope}, "|"))
+		if internal.Contains(key, seen) || !assignment.matchesPrincipal(c.principalFilter) {
+			continue
+		}
+		seen = append(seen, key)
+		c.results = append(c.results, assignment)
+	}
+	sort.Slice(c.results, func(i, j int) bool {
+		if c.results[i].PrincipalName != c.results[j].PrincipalName {
+			return c.results[i].PrincipalName < c.results[j].PrincipalName
+		}
+		return c.results[i].Scope < c.results[j].Scope
 	})
 
-	for _, r := range sortedResults {
+	for _, r := range c.results {
 		body = append(body,
 			[]string{
-				r.userDisplayName,
-				r.roleName,
-				r.roleScope,
+				r.principalLabel(),
+				r.PrincipalType,
+				r.PrincipalID,
+				r.RoleName,
+				r.RoleType,
+				r.Scope,
+				strings.Join(r.DangerousActions, ", "),
 			})
 	}
 	return header, body
 }
 
-func findUser(users []graphrbac.User, roleAssignment authorization.RoleAssignment, roleAssignmentRelevantData *RoleAssignmentRelevantData) {
-	for _, u := range users {
-		principalID := ptr.ToString(roleAssignment.Properties.PrincipalID)
-		if ptr.ToString(u.ObjectID) == principalID {
-			// roleBindingRelevantData user data here
-			roleAssignmentRelevantData.userDisplayName = ptr.ToString(u.DisplayName)
+func (c *CloudFoxRBACclient) dangerousAssignmentCount() int {
+	var count int
+	for _, r := range c.results {
+		if len(r.DangerousActions) > 0 {
+			count++
 		}
 	}
+	return count
+}
+
+// matchesPrincipal tells if the assignment belongs to the principal given with --principal. Everything matches an
+// empty filter.
+func (a AzRoleAssignment) matchesPrincipal(filter string) bool {
+	if filter == "" {
+		return true
+	}
+	return strings.EqualFold(filter, a.PrincipalID) || strings.EqualFold(filter, a.PrincipalName) || strings.EqualFold(filter, a.PrincipalUPN)
+}
+
+// principalLabel shows users as "display name (UPN)" and everything else by its name or object ID
+func (a AzRoleAssignment) principalLabel() string {
+	if a.PrincipalUPN != "" {
+		return fmt.Sprintf("%s (%s)", a.PrincipalName, a.PrincipalUPN)
+	}
+	return a.PrincipalName
+}
+
+func findPrincipal(principals map[string]AzPrincipal, assignment *AzRoleAssignment) {
+	principal, ok := principals[assignment.PrincipalID]
+	if !ok {
+		return
+	}
+	assignment.PrincipalType = principal.Type
+	assignment.PrincipalName = principal.DisplayName
+	assignment.PrincipalUPN = principal.UserPrincipalName
 }
 
-func findRole(roleDefinitions []authorization.RoleDefinition, roleAssignment authorization.RoleAssignment, roleAssignmentRelevantData *RoleAssignmentRelevantData) {
-	// Find the role
+func findRole(roleDefinitions []authorization.RoleDefinition, roleAssignment authorization.RoleAssignment, assignment *AzRoleAssignment) {
+	roleDefinitionID := lastIDSegment(ptr.ToString(roleAssignment.Properties.RoleDefinitionID))
+	// Roles we can't see, e.g. custom roles defined on a management group, are shown by their ID
+	assignment.RoleName = roleDefinitionID
 	for _, rd := range roleDefinitions {
-		roleDefinitionID := strings.Split(ptr.ToString(roleAssignment.Properties.RoleDefinitionID), "/")[len(strings.Split(ptr.ToString(roleAssignment.Properties.RoleDefinitionID), "/"))-1]
-		rdID := strings.Split(ptr.ToString(rd.ID), "/")[len(strings.Split(ptr.ToString(rd.ID), "/"))-1]
-		// roleBindingRelevantData role data here
-		if rdID == roleDefinitionID {
-			roleAssignmentRelevantData.roleName = ptr.ToString(rd.RoleName)
+		if lastIDSegment(ptr.ToString(rd.ID)) != roleDefinitionID || rd.Properties == nil {
+			continue
+		}
+		assignment.RoleName = ptr.ToString(rd.Properties.RoleName)
+		assignment.RoleType = ptr.ToString(rd.Properties.RoleType)
+		if assignment.RoleType == "CustomRole" {
+			assignment.DangerousActions = dangerousRoleActions(rd)
+		}
+	}
+}
+
+// dangerousRoleActions returns the actions of a role definition that cover one of rbacDangerousActions without being
+// taken away again by the role's NotActions
+func dangerousRoleActions(rd authorization.RoleDefinition) []string {
+	var dangerous []string
+	if rd.Properties == nil || rd.Properties.Permissions == nil {
+		return nil
+	}
+	for _, permission := range *rd.Properties.Permissions {
+		var actions, notActions []string
+		if permission.Actions != nil {
+			actions = *permission.Actions
+		}
+		if permission.NotActions != nil {
+			notActions = *permission.NotActions
+		}
+		for _, action := range actions {
+			for _, target := range rbacDangerousActions {
+				if !actionsOverlap(action, target) || actionExcluded(target, notActions) {
+					continue
+				}
+				if !internal.Contains(action, dangerous) {
+					dangerous = append(dangerous, action)
+				}
+			}
+		}
+	}
+	return dangerous
+}
+
+// actionsOverlap tells if two actions, either of which may contain wildcards, can describe the same operation
+func actionsOverlap(a, b string) bool {
+	return actionMatches(a, b) || actionMatches(b, a)
+}
+
+func actionExcluded(action string, notActions []string) bool {
+	for _, notAction := range notActions {
+		if actionMatches(notAction, action) {
+			return true
 		}
 	}
+	return false
 }
 
-type RoleAssignmentRelevantData struct {
-	tenantID        string
-	subscriptionID  string
-	roleScope       string
-	userDisplayName string
-	roleName        string
+// actionMatches tells if the action pattern, where * matches anything, matches the action. Azure compares actions
+// case-insensitively.
+func actionMatches(pattern, action string) bool {
+	expression := "(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	matched, err := regexp.MatchString(expression, action)
+	return err == nil && matched
 }
 
 var getAzureADUsers = getAzureADUsersOriginal
@@ -400,3 +547,99 @@ func generateRoleAssignmentsTestFile(subscriptionID string) {
 type RoleAssignmentsTestFile struct {
 	RoleAssignments []authorization.RoleAssignment `json:"RoleAssignments"`
 }
+
+var resolvePrincipals = resolvePrincipalsOriginal
+
+// resolvePrincipalsOriginal looks up the users, groups and service principals behind role assignments. Whatever was
+// resolved before an error is returned along with it.
+func resolvePrincipalsOriginal(tenantID string, objectIDs []string) (map[string]AzPrincipal, error) {
+	principals := make(map[string]AzPrincipal)
+	client := internal.GetAADObjectsClient(tenantID)
+	// GetObjectsByObjectIds accepts at most 1000 IDs per call
+	for start := 0; start < len(objectIDs); start += 1000 {
+		ids := objectIDs[start:min(start+1000, len(objectIDs))]
+		page, err := client.GetObjectsByObjectIds(context.TODO(), graphrbac.GetObjectsParameters{
+			ObjectIds:                        &ids,
+			IncludeDirectoryObjectReferences: ptr.Bool(true),
+		})
+		if err != nil {
+			return principals, fmt.Errorf("could not look up directory objects: %s", err)
+		}
+		for page.NotDone() {
+			for _, object := range page.Values() {
+				if user, ok := object.AsUser(); ok {
+					principals[ptr.ToString(user.ObjectID)] = AzPrincipal{ObjectID: ptr.ToString(user.ObjectID), DisplayName: ptr.ToString(user.DisplayName), UserPrincipalName: ptr.ToString(user.UserPrincipalName), Type: "User"}
+				} else if servicePrincipal, ok := object.AsServicePrincipal(); ok {
+					principals[ptr.ToString(servicePrincipal.ObjectID)] = AzPrincipal{ObjectID: ptr.ToString(servicePrincipal.ObjectID), DisplayName: ptr.ToString(servicePrincipal.DisplayName), Type: "ServicePrincipal"}
+				} else if group, ok := object.AsADGroup(); ok {
+					principals[ptr.ToString(group.ObjectID)] = AzPrincipal{ObjectID: ptr.ToString(group.ObjectID), DisplayName: ptr.ToString(group.DisplayName), Type: "Group"}
+				}
+			}
+			if err := page.NextWithContext(context.TODO()); err != nil {
+				return principals, fmt.Errorf("could not look up directory objects: %s", err)
+			}
+		}
+	}
+	return principals, nil
+}
+
+func mockedResolvePrincipals(tenantID string, objectIDs []string) (map[string]AzPrincipal, error) {
+	var principals AzurePrincipalsTestFile
+	file, err := os.ReadFile(globals.AAD_PRINCIPALS_TEST_FILE)
+	if err != nil {
+		log.Fatalf("could not read file %s", globals.AAD_PRINCIPALS_TEST_FILE)
+	}
+	err = json.Unmarshal(file, &principals)
+	if err != nil {
+		log.Fatalf("could not unmarshall file %s", globals.AAD_PRINCIPALS_TEST_FILE)
+	}
+	results := make(map[string]AzPrincipal)
+	for _, principal := range principals.Principals {
+		if internal.Contains(principal.ObjectID, objectIDs) {
+			results[principal.ObjectID] = principal
+		}
+	}
+	return results, nil
+}
+
+type AzurePrincipalsTestFile struct {
+	Principals []AzPrincipal `json:"principals"`
+}
+
+var getRoleAssignmentsForScope = getRoleAssignmentsForScopeOriginal
+
+// getRoleAssignmentsForScopeOriginal lists the role assignments at and below a scope that doesn't belong to a
+// subscription, e.g. a management group
+func getRoleAssignmentsForScopeOriginal(scope string) ([]authorization.RoleAssignment, error) {
+	var roleAssignments []authorization.RoleAssignment
+	client := internal.GetRoleAssignmentsClient("")
+	page, err := client.ListForScope(context.TODO(), scope, "")
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch role assignments for scope %s: %s", scope, err)
+	}
+	for page.NotDone() {
+		roleAssignments = append(roleAssignments, page.Values()...)
+		if err := page.NextWithContext(context.TODO()); err != nil {
+			return roleAssignments, fmt.Errorf("could not fetch role assignments for scope %s: %s", scope, err)
+		}
+	}
+	return roleAssignments, nil
+}
+
+func mockedGetRoleAssignmentsForScope(scope string) ([]authorization.RoleAssignment, error) {
+	var allRoleAssignments, roleAssignmentsResults []authorization.RoleAssignment
+	file, err := os.ReadFile(globals.ROLE_ASSIGNMENTS_TEST_FILE)
+	if err != nil {
+		log.Fatalf("could not read file %s", globals.ROLE_ASSIGNMENTS_TEST_FILE)
+	}
+	err = json.Unmarshal(file, &allRoleAssignments)
+	if err != nil {
+		log.Fatalf("could not unmarshall file %s", globals.ROLE_ASSIGNMENTS_TEST_FILE)
+	}
+	for _, ra := range allRoleAssignments {
+		if strings.HasPrefix(strings.ToLower(ptr.ToString(ra.Properties.Scope)), strings.ToLower(scope)) {
+			roleAssignmentsResults = append(roleAssignmentsResults, ra)
+		}
+	}
+	return roleAssignmentsResults, nil
+}
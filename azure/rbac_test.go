@@ -1,7 +1,9 @@
 package azure
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/BishopFox/cloudfox/globals"
@@ -28,6 +30,8 @@ func TestAzRBACCommand(t *testing.T) {
 		roleAssignmentsTestFile string
 		wrapTableOutput         bool
 		azMergedTable           bool
+		azPrincipal             string
+		azManagementGroups      []string
 	}{
 		{
 			name:                    "./cloudfox azure rbac --tenant 11111111-1111-1111-1111-11111111",
@@ -59,6 +63,20 @@ func TestAzRBACCommand(t *testing.T) {
 			wrapTableOutput:         false,
 			azMergedTable:           false,
 		},
+		{
+			name:                    "./cloudfox azure rbac --tenant 11111111-1111-1111-1111-11111111 --management-group mg-prod --principal test_username3@REDACTED.onmicrosoft.com",
+			azTenantID:              "11111111-1111-1111-1111-11111111",
+			azOutputFormat:          "all",
+			azOutputDirectory:       "~/.cloudfox",
+			azVerbosity:             2,
+			resourcesTestFile:       "./test-data/resources.json",
+			usersTestFile:           "./test-data/users.json",
+			roleDefinitionsTestFile: "./test-data/role-definitions.json",
+			roleAssignmentsTestFile: "./test-data/role-assignments.json",
+			version:                 "DEV",
+			azPrincipal:             "test_username3@REDACTED.onmicrosoft.com",
+			azManagementGroups:      []string{"mg-prod"},
+		},
 		{
 			name:                    "./cloudfox azure rbac",
 			azOutputFormat:          "all",
@@ -79,6 +97,9 @@ func TestAzRBACCommand(t *testing.T) {
 	getAzureADUsers = mockedGetAzureADUsers
 	getRoleDefinitions = mockedGetRoleDefinitions
 	getRoleAssignments = mockedGetRoleAssignments
+	getRoleAssignmentsForScope = mockedGetRoleAssignmentsForScope
+	resolvePrincipals = mockedResolvePrincipals
+	globals.AAD_PRINCIPALS_TEST_FILE = "./test-data/principals.json"
 
 	for _, s := range subtests {
 		fmt.Println()
@@ -90,9 +111,86 @@ func TestAzRBACCommand(t *testing.T) {
 		globals.ROLE_DEFINITIONS_TEST_FILE = s.roleDefinitionsTestFile
 		globals.ROLE_ASSIGNMENTS_TEST_FILE = s.roleAssignmentsTestFile
 
-		if err := AzRBACCommand(s.azTenantID, s.azSubscriptionID, s.azOutputFormat, s.azOutputDirectory, s.version, 2, s.wrapTableOutput, s.azMergedTable); err != nil {
+		if err := AzRBACCommand(s.azTenantID, s.azSubscriptionID, s.azOutputFormat, s.azOutputDirectory, s.version, 2, s.wrapTableOutput, s.azMergedTable, s.azPrincipal, s.azManagementGroups); err != nil {
 			fmt.Println(err)
 		}
 	}
 	fmt.Println()
 }
+
+func TestGetRelevantRBACData(t *testing.T) {
+	GetSubscriptions = mockedGetSubscriptions
+	getRoleDefinitions = mockedGetRoleDefinitions
+	getRoleAssignments = mockedGetRoleAssignments
+	getRoleAssignmentsForScope = mockedGetRoleAssignmentsForScope
+	resolvePrincipals = mockedResolvePrincipals
+	globals.RESOURCES_TEST_FILE = "./test-data/resources.json"
+	globals.ROLE_DEFINITIONS_TEST_FILE = "./test-data/role-definitions.json"
+	globals.ROLE_ASSIGNMENTS_TEST_FILE = "./test-data/role-assignments.json"
+	globals.AAD_PRINCIPALS_TEST_FILE = "./test-data/principals.json"
+	defer func() { resolvePrincipals = mockedResolvePrincipals }()
+
+	subscriptionIDs := []string{"AAAAAAAA-AAAA-AAAA-AAAA-AAAAAAAA", "BBBBBBBB-BBBB-BBBB-BBBB-BBBBBBBB"}
+
+	c := CloudFoxRBACclient{managementGroups: []string{"mg-prod"}}
+	c.initialize("11111111-1111-1111-1111-11111111", subscriptionIDs)
+	_, body := c.GetRelevantRBACData()
+	if len(body) != 8 {
+		t.Fatalf("Expected 8 role assignments, got %d", len(body))
+	}
+
+	expected := map[string]struct {
+		principalType string
+		dangerous     string
+	}{
+		// Custom role that can read storage account keys
+		"deploy-pipeline|Storage Key Rotator": {principalType: "ServicePrincipal", dangerous: "Microsoft.Storage/storageAccounts/listKeys/action"},
+		// Custom role that can create role assignments
+		"Platform Admins|Access Reviewer": {principalType: "Group", dangerous: "Microsoft.Authorization/roleAssignments/*"},
+		// Allows everything, but NotActions take away role assignments and keys
+		"User 3|Deployment Operator": {principalType: "User"},
+		// Built-in roles are never flagged
+		"User 3|Owner": {principalType: "User"},
+	}
+	for _, result := range c.results {
+		want, ok := expected[result.PrincipalName+"|"+result.RoleName]
+		if !ok {
+			continue
+		}
+		if result.PrincipalType != want.principalType {
+			t.Errorf("Expected %s to be a %s, got %s", result.PrincipalName, want.principalType, result.PrincipalType)
+		}
+		if strings.Join(result.DangerousActions, ", ") != want.dangerous {
+			t.Errorf("Expected dangerous actions %q for %s, got %q", want.dangerous, result.RoleName, strings.Join(result.DangerousActions, ", "))
+		}
+	}
+
+	// --principal shows everything one identity can touch, including management group assignments
+	c = CloudFoxRBACclient{managementGroups: []string{"mg-prod"}, principalFilter: "test_username3@REDACTED.onmicrosoft.com"}
+	c.initialize("11111111-1111-1111-1111-11111111", subscriptionIDs)
+	_, body = c.GetRelevantRBACData()
+	var scopes []string
+	for _, row := range body {
+		scopes = append(scopes, row[5])
+	}
+	expectedScopes := "/providers/Microsoft.Management/managementGroups/mg-prod, /subscriptions/BBBBBBBB-BBBB-BBBB-BBBB-BBBBBBBB, /subscriptions/BBBBBBBB-BBBB-BBBB-BBBB-BBBBBBBB"
+	if strings.Join(scopes, ", ") != expectedScopes {
+		t.Errorf("Expected scopes %q for User 3, got %q", expectedScopes, strings.Join(scopes, ", "))
+	}
+
+	// Without Graph access the object IDs are shown instead
+	resolvePrincipals = func(tenantID string, objectIDs []string) (map[string]AzPrincipal, error) {
+		return nil, errors.New("Authorization_RequestDenied")
+	}
+	c = CloudFoxRBACclient{}
+	c.initialize("11111111-1111-1111-1111-11111111", subscriptionIDs)
+	_, body = c.GetRelevantRBACData()
+	if len(body) != 7 {
+		t.Fatalf("Expected 7 role assignments without Graph access, got %d", len(body))
+	}
+	for _, row := range body {
+		if row[0] != row[2] || row[1] != "Unknown" {
+			t.Errorf("Expected the object ID and an unknown type without Graph access, got %s (%s)", row[0], row[1])
+		}
+	}
+}
@@ -3,6 +3,7 @@ package azure
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/profiles/latest/storage/mgmt/storage"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
 	"github.com/BishopFox/cloudfox/globals"
@@ -24,17 +26,12 @@ var cyan = color.New(color.FgCyan).SprintFunc()
 
 func AzStorageCommand(AzTenantID, AzSubscription, AzOutputFormat, AzOutputDirectory, Version string, AzVerbosity int, AzWrapTable bool, AzMergedTable bool) error {
 
-	var publicBlobURLs []string
-
 	if AzTenantID != "" && AzSubscription == "" {
 		// cloudfox azure storage --tenant [TENANT_ID | PRIMARY_DOMAIN]
 		tenantInfo := populateTenant(AzTenantID)
 
 		if AzMergedTable {
 
-			// set up table vars
-			var header []string
-			var body [][]string
 			// setup logging client
 			o := internal.OutputClient{
 				Verbosity:     AzVerbosity,
@@ -44,35 +41,23 @@ func AzStorageCommand(AzTenantID, AzSubscription, AzOutputFormat, AzOutputDirect
 				},
 			}
 
-			var err error
-
 			fmt.Printf("[%s][%s] Enumerating storage accounts for tenant %s\n",
-				color.CyanString(emoji.Sprintf(":fox:cloudfox %s :fox:", Version)), color.CyanString(globals.AZ_RBAC_MODULE_NAME),
+				color.CyanString(emoji.Sprintf(":fox:cloudfox %s :fox:", Version)), color.CyanString(globals.AZ_STORAGE_MODULE_NAME),
 				fmt.Sprintf("%s (%s)", ptr.ToString(tenantInfo.DefaultDomain), ptr.ToString(tenantInfo.ID)))
 
 			o.PrefixIdentifier = ptr.ToString(tenantInfo.DefaultDomain)
 			o.Table.DirectoryName = filepath.Join(AzOutputDirectory, globals.CLOUDFOX_BASE_DIRECTORY, globals.AZ_DIR_BASE, ptr.ToString(tenantInfo.DefaultDomain), "1-tenant-level")
+			o.Loot.DirectoryName = o.Table.DirectoryName
 
-			header, body, publicBlobURLs, err = getStorageInfoPerTenant(ptr.ToString(tenantInfo.ID))
-
-			if err != nil {
-				return err
-			}
-			o.Table.TableFiles = append(o.Table.TableFiles,
-				internal.TableFile{
-					Header: header,
-					Body:   body,
-					Name:   fmt.Sprintf(globals.AZ_STORAGE_MODULE_NAME)})
-
-			if body != nil {
-				o.WriteFullOutput(o.Table.TableFiles, nil)
-			}
-			if publicBlobURLs != nil {
-				err := writeBlobURLslootFile(globals.AZ_STORAGE_MODULE_NAME, o.PrefixIdentifier, o.Table.DirectoryName, publicBlobURLs)
+			var accounts []AzStorageAccount
+			for _, s := range GetSubscriptionsPerTenantID(ptr.ToString(tenantInfo.ID)) {
+				subscriptionAccounts, err := getRelevantStorageAccountData(ptr.ToString(tenantInfo.ID), ptr.ToString(s.SubscriptionID))
 				if err != nil {
 					return err
 				}
+				accounts = append(accounts, subscriptionAccounts...)
 			}
+			writeStorageOutput(o, accounts)
 
 		} else {
 
@@ -94,7 +79,6 @@ func AzStorageCommand(AzTenantID, AzSubscription, AzOutputFormat, AzOutputDirect
 }
 
 func runStorageCommandForSingleSubcription(AzSubscription string, AzOutputDirectory string, AzVerbosity int, AzWrapTable bool, Version string) error {
-	var err error
 	// setup logging client
 	o := internal.OutputClient{
 		Verbosity:     AzVerbosity,
@@ -104,151 +88,207 @@ func runStorageCommandForSingleSubcription(AzSubscription string, AzOutputDirect
 		},
 	}
 
-	// set up table vars
-	var header []string
-	var body [][]string
-	var publicBlobURLs []string
-
 	tenantID := ptr.ToString(GetTenantIDPerSubscription(AzSubscription))
 	tenantInfo := populateTenant(tenantID)
 	AzSubscriptionInfo := PopulateSubsriptionType(AzSubscription)
 	o.PrefixIdentifier = AzSubscriptionInfo.Name
 	o.Table.DirectoryName = filepath.Join(AzOutputDirectory, globals.CLOUDFOX_BASE_DIRECTORY, globals.AZ_DIR_BASE, ptr.ToString(tenantInfo.DefaultDomain), AzSubscriptionInfo.Name)
+	o.Loot.DirectoryName = o.Table.DirectoryName
 
 	fmt.Printf(
 		"[%s][%s] Enumerating storage accounts for subscription %s\n",
 		color.CyanString(emoji.Sprintf(":fox:cloudfox %s :fox:", Version)),
 		color.CyanString(globals.AZ_STORAGE_MODULE_NAME),
 		fmt.Sprintf("%s (%s)", AzSubscriptionInfo.Name, AzSubscriptionInfo.ID))
-	//AzTenantID := ptr.ToString(GetTenantIDPerSubscription(AzSubscription))
-	header, body, publicBlobURLs, err = getStorageInfoPerSubscription(ptr.ToString(tenantInfo.ID), AzSubscriptionInfo.ID)
+
+	accounts, err := getRelevantStorageAccountData(ptr.ToString(tenantInfo.ID), AzSubscriptionInfo.ID)
 	if err != nil {
 		return err
 	}
+	writeStorageOutput(o, accounts)
+	return nil
+}
+
+func writeStorageOutput(o internal.OutputClient, accounts []AzStorageAccount) {
+	header, body := getStorageTable(accounts)
+	if body == nil {
+		fmt.Printf("[%s][%s] No storage accounts found, skipping the creation of an output file.\n", cyan(o.CallingModule), cyan(o.PrefixIdentifier))
+		return
+	}
 
 	o.Table.TableFiles = append(o.Table.TableFiles,
 		internal.TableFile{
 			Header: header,
 			Body:   body,
-			Name:   fmt.Sprintf(globals.AZ_STORAGE_MODULE_NAME)})
-	if body != nil {
-		o.WriteFullOutput(o.Table.TableFiles, nil)
-
+			Name:   globals.AZ_STORAGE_MODULE_NAME})
+
+	var lootFiles []internal.LootFile
+	commands, containerURLs, blobURLs := getStorageLoot(accounts)
+	if commands != "" {
+		lootFiles = append(lootFiles, internal.LootFile{
+			Name:     "storage-blob-commands",
+			Contents: commands,
+		})
 	}
-	if publicBlobURLs != nil {
-		err := writeBlobURLslootFile(globals.AZ_STORAGE_MODULE_NAME, o.PrefixIdentifier, o.Table.DirectoryName, publicBlobURLs)
-		if err != nil {
-			return err
-		}
+	if containerURLs != "" {
+		lootFiles = append(lootFiles, internal.LootFile{
+			Name:     "public-container-urls",
+			Contents: containerURLs,
+		})
 	}
-	return nil
+	if blobURLs != "" {
+		lootFiles = append(lootFiles, internal.LootFile{
+			Name:     "public-blob-urls",
+			Contents: blobURLs,
+		})
+	}
+	o.WriteFullOutput(o.Table.TableFiles, lootFiles)
+}
 
+// Container public access levels as shown in the portal
+const (
+	storageContainerAccessNone      = "None"
+	storageContainerAccessBlob      = "Blob"
+	storageContainerAccessContainer = "Container"
+)
+
+type AzStorageAccount struct {
+	SubscriptionID   string
+	SubscriptionName string
+	Name             string
+	ResourceGroup    string
+	SharedKeyAccess  bool
+	PublicBlobAccess bool
+	AllNetworks      bool
+	Containers       []AzStorageContainer
+	// ContainersError is set when the account was listed but its containers couldn't be
+	ContainersError string
 }
 
-func writeBlobURLslootFile(callingModule, controlMessagePrefix, outputDirectory string, publicBlobURLs []string) error {
-	lootDirectory := filepath.Join(outputDirectory, "loot")
-	lootFilePath := filepath.Join(lootDirectory, "public-blob-urls.txt")
+type AzStorageContainer struct {
+	Name         string
+	PublicAccess string
+	// PublicBlobURLs are the blobs of the container that could be read without credentials
+	PublicBlobURLs []string
+}
 
-	err := os.MkdirAll(lootDirectory, os.ModePerm)
-	if err != nil {
-		return err
-	}
+// IsPublic reports whether anonymous requests are actually allowed on the container. The container level setting
+// is ignored by Azure when public blob access is disabled on the account.
+func (c AzStorageContainer) IsPublic(account AzStorageAccount) bool {
+	return account.PublicBlobAccess && c.PublicAccess != storageContainerAccessNone
+}
 
-	file, err := os.Create(lootFilePath)
+func getRelevantStorageAccountData(tenantID, subscriptionID string) ([]AzStorageAccount, error) {
+	var accounts []AzStorageAccount
+	storageAccounts, err := getStorageAccounts(subscriptionID)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer file.Close()
+	for _, sa := range storageAccounts {
+		account := AzStorageAccount{
+			SubscriptionID:   subscriptionID,
+			SubscriptionName: ptr.ToString(GetSubscriptionNameFromID(subscriptionID)),
+			Name:             ptr.ToString(sa.Name),
+			ResourceGroup:    resourceGroupFromID(ptr.ToString(sa.ID)),
+			// Shared key access is allowed unless it has been explicitly disabled
+			SharedKeyAccess: true,
+			AllNetworks:     true,
+		}
+		if sa.AccountProperties != nil {
+			if sa.AccountProperties.AllowSharedKeyAccess != nil {
+				account.SharedKeyAccess = *sa.AccountProperties.AllowSharedKeyAccess
+			}
+			account.PublicBlobAccess = ptr.ToBool(sa.AccountProperties.AllowBlobPublicAccess)
+			if sa.AccountProperties.NetworkRuleSet != nil {
+				account.AllNetworks = sa.AccountProperties.NetworkRuleSet.DefaultAction == storage.DefaultActionAllow
+			}
+		}
 
-	for _, url := range publicBlobURLs {
-		_, err := file.WriteString(url + "\n")
+		containers, err := listStorageContainers(tenantID, account.Name)
 		if err != nil {
-			return err
+			// keep the account in the output rather than dropping it because the data plane call failed
+			var responseErr *azcore.ResponseError
+			if errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusForbidden {
+				account.ContainersError = "access denied"
+			} else {
+				account.ContainersError = "error"
+			}
+			accounts = append(accounts, account)
+			continue
 		}
-	}
-
-	fmt.Printf("[%s][%s] Loot file written to [%s]\n", cyan(callingModule), cyan(controlMessagePrefix), file.Name())
-	return nil
-}
-
-func getStorageInfoPerTenant(AzTenantID string) ([]string, [][]string, []string, error) {
-	var err error
-	var header []string
-	var body, b [][]string
-	var publicBlobURLs []string
 
-	for _, s := range GetSubscriptionsPerTenantID(AzTenantID) {
-		header, b, publicBlobURLs, err = getRelevantStorageAccountData(AzTenantID, ptr.ToString(s.SubscriptionID))
-		if err != nil {
-			return nil, nil, nil, err
-		} else {
-			body = append(body, b...)
+		for i, c := range containers {
+			if c.IsPublic(account) {
+				containers[i].PublicBlobURLs = getPublicBlobURLs(tenantID, account.Name, c.Name)
+			}
 		}
+		account.Containers = containers
+		accounts = append(accounts, account)
 	}
-	return header, body, publicBlobURLs, nil
+	return accounts, nil
 }
 
-func getStorageInfoPerSubscription(AzTenantID, AzSubscriptionID string) ([]string, [][]string, []string, error) {
-	var err error
-	var header []string
+func getStorageTable(accounts []AzStorageAccount) ([]string, [][]string) {
+	header := []string{"Subscription Name", "Storage Account Name", "Resource Group", "Shared Key Access", "Public Blob Access", "All Networks", "Container Name", "Public Access Level"}
 	var body [][]string
-	var publicBlobURLs []string
+	for _, account := range accounts {
+		row := []string{
+			account.SubscriptionName,
+			account.Name,
+			account.ResourceGroup,
+			enabledOrDisabled(account.SharedKeyAccess),
+			enabledOrDisabled(account.PublicBlobAccess),
+			yesOrNo(account.AllNetworks),
+		}
+		if account.ContainersError != "" {
+			body = append(body, append(row, "containers: "+account.ContainersError, "Unknown"))
+			continue
+		}
+		if len(account.Containers) == 0 {
+			body = append(body, append(row, "No containers", ""))
+			continue
+		}
+		for _, c := range account.Containers {
+			body = append(body, append(append([]string{}, row...), c.Name, c.PublicAccess))
+		}
+	}
+	return header, body
+}
 
-	for _, s := range GetSubscriptions() {
-		if ptr.ToString(s.SubscriptionID) == AzSubscriptionID {
-			header, body, publicBlobURLs, err = getRelevantStorageAccountData(AzTenantID, ptr.ToString(s.SubscriptionID))
-			if err != nil {
-				return nil, nil, nil, err
+func getStorageLoot(accounts []AzStorageAccount) (string, string, string) {
+	var commands, containerURLs, blobURLs strings.Builder
+	for _, account := range accounts {
+		for _, c := range account.Containers {
+			commands.WriteString(fmt.Sprintf("az storage blob list --subscription %s --account-name %s --container-name %s --auth-mode login -o table\n", account.SubscriptionID, account.Name, c.Name))
+			if !c.IsPublic(account) {
+				continue
+			}
+			if c.PublicAccess == storageContainerAccessContainer {
+				// anonymous listing only works on containers with container level access
+				containerURLs.WriteString(fmt.Sprintf("https://%s.blob.core.windows.net/%s?restype=container&comp=list\n", account.Name, c.Name))
+			} else {
+				containerURLs.WriteString(fmt.Sprintf("https://%s.blob.core.windows.net/%s/\n", account.Name, c.Name))
+			}
+			for _, url := range c.PublicBlobURLs {
+				blobURLs.WriteString(url + "\n")
 			}
 		}
 	}
-	return header, body, publicBlobURLs, nil
+	return commands.String(), containerURLs.String(), blobURLs.String()
 }
 
-func getRelevantStorageAccountData(tenantID, subscriptionID string) ([]string, [][]string, []string, error) {
-	tableHeader := []string{"Subscription Name", "Storage Account Name", "Container Name", "Access Status"}
-	var tableBody [][]string
-	var publicBlobURLs []string
-	storageAccounts, err := getStorageAccounts(subscriptionID)
-	if err != nil {
-		return nil, nil, nil, err
+func enabledOrDisabled(b bool) string {
+	if b {
+		return "Enabled"
 	}
-	for _, sa := range storageAccounts {
-		blobClient, err := internal.GetStorageAccountBlobClient(tenantID, ptr.ToString(sa.Name))
-		if err != nil {
-			return nil, nil, nil, err
-		}
-		containers, err := getStorageAccountContainers(blobClient)
-		if err != nil {
-			// rather than return an error, we'll just add a row to the table highlighting the storage account name and that we couldn't get the containers
-
-			tableBody = append(tableBody,
-				[]string{
-					subscriptionID,
-					ptr.ToString(sa.Name),
-					"Unknown",
-					"Authorization Failure"})
-
-			//return nil, nil, nil, nil
-		}
-
-		for containerName, accessType := range containers {
-			tableBody = append(tableBody,
-				[]string{
-					ptr.ToString(GetSubscriptionNameFromID(subscriptionID)),
-					ptr.ToString(sa.Name),
-					containerName,
-					accessType})
-		}
-		urls, err := getPublicBlobURLs(blobClient, ptr.ToString(sa.Name), containers)
-		if err == nil {
-			continue
-			//return nil, nil, nil, err
-		}
-		publicBlobURLs = append(publicBlobURLs, urls...)
+	return "Disabled"
+}
 
+func yesOrNo(b bool) string {
+	if b {
+		return "Yes"
 	}
-	return tableHeader, tableBody, publicBlobURLs, nil
+	return "No"
 }
 
 var getStorageAccounts = getStorageAccountsOriginal
@@ -284,52 +324,104 @@ func mockedGetStorageAccounts(subscriptionID string) ([]storage.Account, error)
 	return storageAccountsResults, nil
 }
 
-func getStorageAccountContainers(client *azblob.Client) (map[string]string, error) {
-	containers := make(map[string]string)
+var listStorageContainers = listStorageContainersOriginal
+
+func listStorageContainersOriginal(tenantID, storageAccountName string) ([]AzStorageContainer, error) {
+	client, err := internal.GetStorageAccountBlobClient(tenantID, storageAccountName)
+	if err != nil {
+		return nil, err
+	}
+	var containers []AzStorageContainer
 	pager := client.NewListContainersPager(&azblob.ListContainersOptions{
-		Include: azblob.ListContainersInclude{Metadata: true, Deleted: true},
+		Include: azblob.ListContainersInclude{Metadata: true},
 	})
 	for pager.More() {
 		resp, err := pager.NextPage(context.TODO())
 		if err != nil {
 			return nil, err
 		}
-		for _, container := range resp.ContainerItems {
-			if container.Properties.PublicAccess != nil {
-				containers[ptr.ToString(container.Name)] = "public"
-			} else {
-				containers[ptr.ToString(container.Name)] = "private"
+		for _, c := range resp.ContainerItems {
+			publicAccess := storageContainerAccessNone
+			if c.Properties != nil && c.Properties.PublicAccess != nil {
+				switch *c.Properties.PublicAccess {
+				case container.PublicAccessTypeBlob:
+					publicAccess = storageContainerAccessBlob
+				case container.PublicAccessTypeContainer:
+					publicAccess = storageContainerAccessContainer
+				}
 			}
+			containers = append(containers, AzStorageContainer{
+				Name:         ptr.ToString(c.Name),
+				PublicAccess: publicAccess,
+			})
 		}
 	}
 	return containers, nil
 }
 
-func getPublicBlobURLs(client *azblob.Client, storageAccountName string, containers map[string]string) ([]string, error) {
-	var publicBlobURLs []string
-	for containerName, accessType := range containers {
-		if accessType == "public" {
-			url, err := getPublicBlobURLsForContainer(client, storageAccountName, containerName)
-			if err != nil {
-				//return nil, err
-				continue
-			}
-			publicBlobURLs = append(publicBlobURLs, url...)
-		}
+func mockedListStorageContainers(tenantID, storageAccountName string) ([]AzStorageContainer, error) {
+	testFile, err := os.ReadFile(globals.STORAGE_CONTAINERS_TEST_FILE)
+	if err != nil {
+		return nil, fmt.Errorf("could not open storage containers test file %s", globals.STORAGE_CONTAINERS_TEST_FILE)
 	}
-	return publicBlobURLs, nil
+	var containersPerAccount map[string][]AzStorageContainer
+	err = json.Unmarshal(testFile, &containersPerAccount)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshall storage containers test file %s", globals.STORAGE_CONTAINERS_TEST_FILE)
+	}
+	containers, ok := containersPerAccount[storageAccountName]
+	if !ok {
+		// accounts missing from the test file behave like accounts whose data plane the caller can't read
+		return nil, &azcore.ResponseError{ErrorCode: "AuthorizationPermissionMismatch", StatusCode: http.StatusForbidden}
+	}
+	return containers, nil
 }
 
-func getPublicBlobURLsForContainer(client *azblob.Client, storageAccountName, containerName string) ([]string, error) {
+var getPublicBlobURLs = getPublicBlobURLsOriginal
+
+// getPublicBlobURLsOriginal lists the blobs of a container and keeps the ones that can be fetched anonymously
+func getPublicBlobURLsOriginal(tenantID, storageAccountName, containerName string) []string {
+	client, err := internal.GetStorageAccountBlobClient(tenantID, storageAccountName)
+	if err != nil {
+		return nil
+	}
 	blobNames, err := getAllBlobsForContainer(client, containerName)
 	if err != nil {
-		return nil, err
+		return nil
 	}
 	publicBlobURLs, err := validatePublicBlobURLs(storageAccountName, containerName, blobNames)
 	if err != nil {
-		return nil, err
+		return nil
 	}
-	return publicBlobURLs, nil
+	return publicBlobURLs
+}
+
+func mockedGetPublicBlobURLs(tenantID, storageAccountName, containerName string) []string {
+	testFile, err := os.ReadFile(globals.STORAGE_BLOBS_TEST_FILE)
+	if err != nil {
+		return nil
+	}
+	var blobs []struct {
+		StorageAccountName string   `json:"storage_account_name"`
+		BlobURLs           []string `json:"blob_urls"`
+	}
+	err = json.Unmarshal(testFile, &blobs)
+	if err != nil {
+		return nil
+	}
+	prefix := fmt.Sprintf("https://%s.blob.core.windows.net/%s/", storageAccountName, containerName)
+	var publicBlobURLs []string
+	for _, b := range blobs {
+		if b.StorageAccountName != storageAccountName {
+			continue
+		}
+		for _, url := range b.BlobURLs {
+			if strings.HasPrefix(url, prefix) {
+				publicBlobURLs = append(publicBlobURLs, url)
+			}
+		}
+	}
+	return publicBlobURLs
 }
 
 func getAllBlobsForContainer(blobClient *azblob.Client, containerName string) ([]string, error) {
@@ -3,14 +3,13 @@ package azure
 import (
 	"fmt"
 	"log"
+	"strings"
 	"testing"
 
 	"github.com/BishopFox/cloudfox/globals"
 	"github.com/BishopFox/cloudfox/internal"
 )
 
-// TO-DO: add blob URL enumeration to this table test.
-// This test won't work anymore until blob URL enumeration is added.
 func TestAzStorageCommand(t *testing.T) {
 	fmt.Println()
 	fmt.Println("[test case] Azure Storage Accounts")
@@ -73,6 +72,10 @@ func TestAzStorageCommand(t *testing.T) {
 	GetSubscriptions = mockedGetSubscriptions
 	getResourceGroups = mockedGetResourceGroups
 	getStorageAccounts = mockedGetStorageAccounts
+	listStorageContainers = mockedListStorageContainers
+	getPublicBlobURLs = mockedGetPublicBlobURLs
+	globals.STORAGE_CONTAINERS_TEST_FILE = "./test-data/storage-containers.json"
+	globals.STORAGE_BLOBS_TEST_FILE = "./test-data/storage-blobs.json"
 
 	for _, s := range subtests {
 		fmt.Println()
@@ -86,3 +89,61 @@ func TestAzStorageCommand(t *testing.T) {
 		}
 	}
 }
+
+func TestGetRelevantStorageAccountData(t *testing.T) {
+	GetSubscriptions = mockedGetSubscriptions
+	getStorageAccounts = mockedGetStorageAccounts
+	listStorageContainers = mockedListStorageContainers
+	getPublicBlobURLs = mockedGetPublicBlobURLs
+	globals.RESOURCES_TEST_FILE = "./test-data/resources.json"
+	globals.STORAGE_ACCOUNTS_TEST_FILE = "./test-data/storage-accounts.json"
+	globals.STORAGE_CONTAINERS_TEST_FILE = "./test-data/storage-containers.json"
+	globals.STORAGE_BLOBS_TEST_FILE = "./test-data/storage-blobs.json"
+
+	var accounts []AzStorageAccount
+	for _, subscriptionID := range []string{"AAAAAAAA-AAAA-AAAA-AAAA-AAAAAAAA", "BBBBBBBB-BBBB-BBBB-BBBB-BBBBBBBB"} {
+		subscriptionAccounts, err := getRelevantStorageAccountData("11111111-1111-1111-1111-11111111", subscriptionID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		accounts = append(accounts, subscriptionAccounts...)
+	}
+
+	_, body := getStorageTable(accounts)
+	expected := map[string][]string{
+		"Storage-12345/backups": {"Enabled", "Enabled", "Yes", "backups", "Container"},
+		"Storage-12345/images":  {"Enabled", "Enabled", "Yes", "images", "Blob"},
+		"Storage-12345/logs":    {"Enabled", "Enabled", "Yes", "logs", "None"},
+		// Shared keys disabled and the firewall denies by default
+		"Storage-678910/website":           {"Disabled", "Disabled", "No", "website", "Container"},
+		"Storage-1112131415/No containers": {"Enabled", "Enabled", "Yes", "No containers", ""},
+		// The test file has no containers for this account, which is mocked as a 403 from the data plane
+		"Storage-16171819/containers: access denied": {"Enabled", "Disabled", "Yes", "containers: access denied", "Unknown"},
+	}
+	if len(body) != len(expected) {
+		t.Fatalf("Expected %d rows, got %d", len(expected), len(body))
+	}
+	for _, row := range body {
+		want, ok := expected[row[1]+"/"+row[6]]
+		if !ok {
+			t.Errorf("Unexpected row %v", row)
+			continue
+		}
+		if strings.Join(row[3:], ",") != strings.Join(want, ",") {
+			t.Errorf("Expected %v for %s, got %v", want, row[1], row[3:])
+		}
+	}
+
+	commands, containerURLs, blobURLs := getStorageLoot(accounts)
+	expectedCommand := "az storage blob list --subscription AAAAAAAA-AAAA-AAAA-AAAA-AAAAAAAA --account-name Storage-12345 --container-name logs --auth-mode login -o table\n"
+	if !strings.Contains(commands, expectedCommand) {
+		t.Errorf("Expected the loot to contain %q", expectedCommand)
+	}
+	expectedURLs := "https://Storage-12345.blob.core.windows.net/backups?restype=container&comp=list\nhttps://Storage-12345.blob.core.windows.net/images/\n"
+	if containerURLs != expectedURLs {
+		t.Errorf("Expected public container URLs %q, got %q", expectedURLs, containerURLs)
+	}
+	if strings.Count(blobURLs, "\n") != 3 {
+		t.Errorf("Expected 3 public blob URLs, got %q", blobURLs)
+	}
+}
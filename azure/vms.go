@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/Azure/azure-sdk-for-go/profiles/latest/authorization/mgmt/authorization"
 	"github.com/Azure/azure-sdk-for-go/profiles/latest/compute/mgmt/compute"
 	"github.com/Azure/azure-sdk-for-go/profiles/latest/network/mgmt/network"
 	"github.com/Azure/azure-sdk-for-go/profiles/latest/resources/mgmt/resources"
@@ -20,6 +21,58 @@ import (
 	"github.com/kyokomi/emoji"
 )
 
+// Built-in roles that give a VM's managed identity control over everything in their scope
+var vmPrivilegedRoles = map[string]string{
+	"8e3af657-a8ff-443c-a75c-2fe8c4bcb635": "Owner",
+	"b24988ac-6180-42a0-ab88-20f7382dd24c": "Contributor",
+}
+
+type AzVM struct {
+	SubscriptionID   string
+	SubscriptionName string
+	ResourceGroup    string
+	Name             string
+	// Kind is either VM or VMSS
+	Kind          string
+	Location      string
+	OSType        string
+	PowerState    string
+	PrivateIPs    []string
+	PublicIPs     []string
+	AdminUsername string
+	Identities    []AzManagedIdentity
+	UserData      string
+}
+
+type AzManagedIdentity struct {
+	// Type is SystemAssigned or UserAssigned
+	Type string
+	// ResourceID is only set for user-assigned identities
+	ResourceID  string
+	PrincipalID string
+	ClientID    string
+	Roles       []AzIdentityRole
+}
+
+type AzIdentityRole struct {
+	RoleName   string
+	Scope      string
+	Privileged bool
+}
+
+// IsPrivilegeEscalationTarget reports whether code running on the VM can get a token for an identity that is Owner or
+// Contributor on the whole subscription
+func (vm AzVM) IsPrivilegeEscalationTarget() bool {
+	for _, identity := range vm.Identities {
+		for _, role := range identity.Roles {
+			if role.Privileged {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func AzVMsCommand(AzTenantID, AzSubscription, AzOutputFormat, AzOutputDirectory, Version string, AzVerbosity int, AzWrapTable bool, AzMergedTable bool) error {
 
 	if AzTenantID != "" && AzSubscription == "" {
@@ -27,11 +80,6 @@ func AzVMsCommand(AzTenantID, AzSubscription, AzOutputFormat, AzOutputDirectory,
 		tenantInfo := populateTenant(AzTenantID)
 
 		if AzMergedTable {
-			// set up table vars
-			var header []string
-			var body [][]string
-			var userData string
-
 			o := internal.OutputClient{
 				Verbosity:     AzVerbosity,
 				CallingModule: globals.AZ_VMS_MODULE_NAME,
@@ -45,32 +93,13 @@ func AzVMsCommand(AzTenantID, AzSubscription, AzOutputFormat, AzOutputDirectory,
 
 			o.PrefixIdentifier = ptr.ToString(tenantInfo.DefaultDomain)
 			o.Table.DirectoryName = filepath.Join(AzOutputDirectory, globals.CLOUDFOX_BASE_DIRECTORY, globals.AZ_DIR_BASE, ptr.ToString(tenantInfo.DefaultDomain), "1-tenant-level")
+			o.Loot.DirectoryName = o.Table.DirectoryName
 
-			// populate the table data
-			header, body, userData = getVMsPerTenantID(ptr.ToString(tenantInfo.ID))
-
-			o.Table.TableFiles = append(o.Table.TableFiles,
-				internal.TableFile{
-					Header: header,
-					Body:   body,
-					Name:   fmt.Sprintf(globals.AZ_VMS_MODULE_NAME)})
-
-			if body != nil {
-				if userData != "" {
-					o.Loot.DirectoryName = filepath.Join(AzOutputDirectory, globals.CLOUDFOX_BASE_DIRECTORY, globals.AZ_DIR_BASE, ptr.ToString(tenantInfo.DefaultDomain), "loot")
-					o.Loot.LootFiles = append(o.Loot.LootFiles,
-						internal.LootFile{
-							Contents: userData,
-							Name:     "virtualmachines-user-data"})
-					o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
-					fmt.Println()
-				} else {
-
-					o.WriteFullOutput(o.Table.TableFiles, nil)
-					fmt.Println()
-				}
-
+			var vms []AzVM
+			for _, s := range GetSubscriptionsPerTenantID(ptr.ToString(tenantInfo.ID)) {
+				vms = append(vms, getVMsPerSubscription(s)...)
 			}
+			writeVMsOutput(o, vms)
 		} else {
 
 			for _, s := range GetSubscriptionsPerTenantID(ptr.ToString(tenantInfo.ID)) {
@@ -91,11 +120,6 @@ func AzVMsCommand(AzTenantID, AzSubscription, AzOutputFormat, AzOutputDirectory,
 }
 
 func runVMsCommandForSingleSubscription(AzSubscription string, AzOutputDirectory string, AzVerbosity int, AzWrapTable bool, Version string) error {
-	// set up table vars
-	var header []string
-	var body [][]string
-	var userData string
-
 	o := internal.OutputClient{
 		Verbosity:     AzVerbosity,
 		CallingModule: globals.AZ_VMS_MODULE_NAME,
@@ -109,87 +133,144 @@ func runVMsCommandForSingleSubscription(AzSubscription string, AzOutputDirectory
 	AzSubscriptionInfo = PopulateSubsriptionType(AzSubscription)
 	o.PrefixIdentifier = AzSubscriptionInfo.Name
 	o.Table.DirectoryName = filepath.Join(AzOutputDirectory, globals.CLOUDFOX_BASE_DIRECTORY, globals.AZ_DIR_BASE, ptr.ToString(tenantInfo.DefaultDomain), AzSubscriptionInfo.Name)
+	o.Loot.DirectoryName = o.Table.DirectoryName
 
 	fmt.Printf("[%s][%s] Enumerating VMs for subscription %s\n",
 		color.CyanString(emoji.Sprintf(":fox:cloudfox %s :fox:", Version)), color.CyanString(globals.AZ_VMS_MODULE_NAME),
 		fmt.Sprintf("%s (%s)", AzSubscriptionInfo.Name, AzSubscriptionInfo.ID))
 
-	// populate the table data
-	header, body, userData = getVMsPerSubscriptionID(AzSubscriptionInfo.ID)
+	for _, s := range GetSubscriptions() {
+		if ptr.ToString(s.SubscriptionID) == AzSubscriptionInfo.ID {
+			writeVMsOutput(o, getVMsPerSubscription(s))
+		}
+	}
+	return nil
+}
 
+func writeVMsOutput(o internal.OutputClient, vms []AzVM) {
+	header, body := getVMsTable(vms)
+	if body == nil {
+		fmt.Printf("[%s][%s] No VMs found, skipping the creation of an output file.\n", cyan(o.CallingModule), cyan(o.PrefixIdentifier))
+		return
+	}
 	o.Table.TableFiles = append(o.Table.TableFiles,
 		internal.TableFile{
 			Header: header,
 			Body:   body,
-			Name:   fmt.Sprintf(globals.AZ_VMS_MODULE_NAME)})
-
-	if body != nil {
-		if userData != "" {
-			o.Loot.DirectoryName = filepath.Join(AzOutputDirectory, globals.CLOUDFOX_BASE_DIRECTORY, globals.AZ_DIR_BASE, ptr.ToString(tenantInfo.DefaultDomain), AzSubscriptionInfo.Name, "loot")
-			o.Loot.LootFiles = append(o.Loot.LootFiles,
-				internal.LootFile{
-					Contents: userData,
-					Name:     "virtualmachines-user-data"})
-			o.WriteFullOutput(o.Table.TableFiles, o.Loot.LootFiles)
-			fmt.Println()
-		} else {
+			Name:   globals.AZ_VMS_MODULE_NAME})
+
+	var lootFiles []internal.LootFile
+	userData, runCommands, imdsCommands := getVMsLoot(vms)
+	if userData != "" {
+		lootFiles = append(lootFiles, internal.LootFile{
+			Contents: userData,
+			Name:     "virtualmachines-user-data"})
+	}
+	if runCommands != "" {
+		lootFiles = append(lootFiles, internal.LootFile{
+			Contents: runCommands,
+			Name:     "virtualmachines-run-command"})
+	}
+	if imdsCommands != "" {
+		lootFiles = append(lootFiles, internal.LootFile{
+			Contents: imdsCommands,
+			Name:     "virtualmachines-imds-tokens"})
+	}
+	o.WriteFullOutput(o.Table.TableFiles, lootFiles)
 
-			o.WriteFullOutput(o.Table.TableFiles, nil)
-			fmt.Println()
+	var targets int
+	for _, vm := range vms {
+		if vm.IsPrivilegeEscalationTarget() {
+			targets++
 		}
-
 	}
-
-	return nil
+	if targets > 0 {
+		fmt.Printf("[%s][%s] %d VMs have a managed identity with Owner or Contributor on the subscription.\n", cyan(o.CallingModule), cyan(o.PrefixIdentifier), targets)
+	}
+	fmt.Println()
 }
 
-func getVMsPerTenantID(AzTenantID string) ([]string, [][]string, string) {
-	var resultsHeader []string
-	var resultsBody, b [][]string
-	var userDataCombined, userData string
-	var err error
+// getVMsPerSubscription lists the VMs and scale sets of every resource group in the subscription and maps their
+// managed identities to the roles they hold
+func getVMsPerSubscription(sub subscriptions.Subscription) []AzVM {
+	var vms []AzVM
+	subscriptionID := ptr.ToString(sub.SubscriptionID)
+	for _, rg := range getResourceGroups(subscriptionID) {
+		rgVMs, err := getComputeRelevantData(sub, rg)
+		if err != nil {
+			fmt.Printf("[%s] Could not enumerate VMs for resource group %s in subscription %s\n", color.CyanString(globals.AZ_VMS_MODULE_NAME), ptr.ToString(rg.Name), subscriptionID)
+			continue
+		}
+		vms = append(vms, rgVMs...)
+	}
+	if len(vms) == 0 {
+		return vms
+	}
 
-	for _, s := range GetSubscriptionsPerTenantID(AzTenantID) {
-		for _, rg := range getResourceGroups(ptr.ToString(s.SubscriptionID)) {
-			resultsHeader, b, userData, err = getComputeRelevantData(s, rg)
-			if err != nil {
-				fmt.Printf("[%s] Could not enumerate VMs for resource group %s in subscription %s\n", color.CyanString(globals.AZ_VMS_MODULE_NAME), ptr.ToString(rg.Name), ptr.ToString(s.SubscriptionID))
-			} else {
-				resultsBody = append(resultsBody, b...)
-				userDataCombined += userData
-			}
+	roleAssignments, err := getRoleAssignments(subscriptionID)
+	if err != nil {
+		fmt.Printf("[%s] failed to get role assignments for subscription %s: %s. Skipping identity roles.\n", color.CyanString(globals.AZ_VMS_MODULE_NAME), subscriptionID, err)
+		return vms
+	}
+	roleNames := make(map[string]string)
+	roleDefinitions, err := getRoleDefinitions(subscriptionID)
+	if err != nil {
+		fmt.Printf("[%s] failed to get role definitions for subscription %s: %s. Showing role IDs instead.\n", color.CyanString(globals.AZ_VMS_MODULE_NAME), subscriptionID, err)
+	}
+	for _, rd := range roleDefinitions {
+		roleNames[strings.ToLower(lastIDSegment(ptr.ToString(rd.ID)))] = ptr.ToString(rd.RoleName)
+	}
 
+	for i := range vms {
+		for j := range vms[i].Identities {
+			vms[i].Identities[j].Roles = getIdentityRoles(vms[i].Identities[j].PrincipalID, subscriptionID, roleAssignments, roleNames)
 		}
 	}
-	return resultsHeader, resultsBody, userDataCombined
+	return vms
 }
 
-func getVMsPerSubscriptionID(AzSubscriptionID string) ([]string, [][]string, string) {
-	var resultsHeader []string
-	var resultsBody, b [][]string
-	var userDataCombined, userData string
-	var err error
-
-	for _, s := range GetSubscriptions() {
-		if ptr.ToString(s.SubscriptionID) == AzSubscriptionID {
-			for _, rg := range getResourceGroups(ptr.ToString(s.SubscriptionID)) {
-				resultsHeader, b, userData, err = getComputeRelevantData(s, rg)
-				if err != nil {
-					fmt.Printf("[%s] Could not enumerate VMs for resource group %s in subscription %s\n", color.CyanString(globals.AZ_VMS_MODULE_NAME), ptr.ToString(rg.Name), ptr.ToString(s.SubscriptionID))
-				} else {
-					resultsBody = append(resultsBody, b...)
-					userDataCombined += userData
-				}
-			}
+func getIdentityRoles(principalID string, subscriptionID string, roleAssignments []authorization.RoleAssignment, roleNames map[string]string) []AzIdentityRole {
+	var roles []AzIdentityRole
+	if principalID == "" {
+		return roles
+	}
+	for _, ra := range roleAssignments {
+		if ra.Properties == nil || !strings.EqualFold(ptr.ToString(ra.Properties.PrincipalID), principalID) {
+			continue
 		}
+		roleID := strings.ToLower(lastIDSegment(ptr.ToString(ra.Properties.RoleDefinitionID)))
+		role := AzIdentityRole{
+			RoleName: roleNames[roleID],
+			Scope:    ptr.ToString(ra.Properties.Scope),
+		}
+		if role.RoleName == "" {
+			role.RoleName = vmPrivilegedRoles[roleID]
+		}
+		if role.RoleName == "" {
+			role.RoleName = roleID
+		}
+		_, privileged := vmPrivilegedRoles[roleID]
+		role.Privileged = privileged && isSubscriptionScopeOrAbove(role.Scope, subscriptionID)
+		roles = append(roles, role)
 	}
-	return resultsHeader, resultsBody, userDataCombined
+	return roles
 }
 
-func getComputeRelevantData(sub subscriptions.Subscription, rg resources.Group) ([]string, [][]string, string, error) {
-	header := []string{"Subscription Name", "VM Name", "VM Location", "Private IPs", "Public IPs", "Admin Username", "Resource Group Name"}
-	var body [][]string
-	var userDataString string
+// isSubscriptionScopeOrAbove reports whether a role assignment scope covers the whole subscription, which is the case
+// for the subscription itself, management groups and the root scope
+func isSubscriptionScopeOrAbove(scope string, subscriptionID string) bool {
+	scope = strings.ToLower(strings.TrimSuffix(scope, "/"))
+	return scope == "" ||
+		scope == strings.ToLower("/subscriptions/"+subscriptionID) ||
+		strings.HasPrefix(scope, "/providers/microsoft.management/managementgroups/")
+}
+
+func lastIDSegment(id string) string {
+	return id[strings.LastIndex(id, "/")+1:]
+}
+
+func getComputeRelevantData(sub subscriptions.Subscription, rg resources.Group) ([]AzVM, error) {
+	var results []AzVM
 
 	subscriptionID := ptr.ToString(sub.SubscriptionID)
 	subscriptionName := ptr.ToString(sub.DisplayName)
@@ -197,57 +278,210 @@ func getComputeRelevantData(sub subscriptions.Subscription, rg resources.Group)
 
 	vms, err := getComputeVMsPerResourceGroup(subscriptionID, resourceGroupName)
 	if err != nil {
-		return nil, nil, "", fmt.Errorf("error fetching vms for resource group %s: %s", resourceGroupName, err)
+		return nil, fmt.Errorf("error fetching vms for resource group %s: %s", resourceGroupName, err)
 	}
 
 	for _, vm := range vms {
-		var adminUsername string
-		if vm.VirtualMachineProperties != nil && vm.OsProfile != nil {
-			adminUsername = ptr.ToString(vm.OsProfile.AdminUsername)
+		result := AzVM{
+			SubscriptionID:   subscriptionID,
+			SubscriptionName: subscriptionName,
+			ResourceGroup:    resourceGroupName,
+			Name:             ptr.ToString(vm.Name),
+			Kind:             "VM",
+			Location:         ptr.ToString(vm.Location),
 		}
-		privateIPs, publicIPs := getIPs(ptr.ToString(sub.SubscriptionID), ptr.ToString(rg.Name), vm)
-		// get userdata
-		vmDetails, err := getComputeVmInfo(subscriptionID, resourceGroupName, ptr.ToString(vm.Name))
+		if vm.VirtualMachineProperties != nil {
+			if vm.OsProfile != nil {
+				result.AdminUsername = ptr.ToString(vm.OsProfile.AdminUsername)
+			}
+			if vm.StorageProfile != nil && vm.StorageProfile.OsDisk != nil {
+				result.OSType = string(vm.StorageProfile.OsDisk.OsType)
+			}
+		}
+		result.PrivateIPs, result.PublicIPs = getIPs(subscriptionID, resourceGroupName, vm)
+
+		powerState, err := getVMPowerState(subscriptionID, resourceGroupName, result.Name)
 		if err != nil {
-			fmt.Println("error fetching vm details for vm: ", ptr.ToString(vm.Name))
+			powerState = "Unknown"
+		}
+		result.PowerState = powerState
+
+		if vm.Identity != nil {
+			if vm.Identity.PrincipalID != nil {
+				result.Identities = append(result.Identities, AzManagedIdentity{
+					Type:        "SystemAssigned",
+					PrincipalID: ptr.ToString(vm.Identity.PrincipalID),
+				})
+			}
+			for id, userAssigned := range vm.Identity.UserAssignedIdentities {
+				if userAssigned == nil {
+					continue
+				}
+				result.Identities = append(result.Identities, AzManagedIdentity{
+					Type:        "UserAssigned",
+					ResourceID:  id,
+					PrincipalID: ptr.ToString(userAssigned.PrincipalID),
+					ClientID:    ptr.ToString(userAssigned.ClientID),
+				})
+			}
 		}
 
+		// get userdata
+		vmDetails, err := getComputeVmInfo(subscriptionID, resourceGroupName, result.Name)
+		if err != nil {
+			fmt.Println("error fetching vm details for vm: ", result.Name)
+		}
 		if vmDetails.VirtualMachineProperties != nil && vmDetails.VirtualMachineProperties.UserData != nil {
 			userData, err := base64.StdEncoding.DecodeString(ptr.ToString(vmDetails.VirtualMachineProperties.UserData))
 			if err != nil {
-				fmt.Println("error decoding userdata for vm: ", ptr.ToString(vm.Name))
+				fmt.Println("error decoding userdata for vm: ", result.Name)
+			}
+			result.UserData = string(userData)
+		}
+
+		results = append(results, result)
+	}
+
+	scaleSets, err := getComputeVMScaleSetsPerResourceGroup(subscriptionID, resourceGroupName)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching vm scale sets for resource group %s: %s", resourceGroupName, err)
+	}
+	for _, vmss := range scaleSets {
+		result := AzVM{
+			SubscriptionID:   subscriptionID,
+			SubscriptionName: subscriptionName,
+			ResourceGroup:    resourceGroupName,
+			Name:             ptr.ToString(vmss.Name),
+			Kind:             "VMSS",
+			Location:         ptr.ToString(vmss.Location),
+		}
+		if vmss.Sku != nil && vmss.Sku.Capacity != nil {
+			result.PowerState = fmt.Sprintf("%d instances", *vmss.Sku.Capacity)
+		}
+		if vmss.VirtualMachineScaleSetProperties != nil && vmss.VirtualMachineProfile != nil {
+			if vmss.VirtualMachineProfile.OsProfile != nil {
+				result.AdminUsername = ptr.ToString(vmss.VirtualMachineProfile.OsProfile.AdminUsername)
+			}
+			if vmss.VirtualMachineProfile.StorageProfile != nil && vmss.VirtualMachineProfile.StorageProfile.OsDisk != nil {
+				result.OSType = string(vmss.VirtualMachineProfile.StorageProfile.OsDisk.OsType)
 			}
+		}
+		if vmss.Identity != nil {
+			if vmss.Identity.PrincipalID != nil {
+				result.Identities = append(result.Identities, AzManagedIdentity{
+					Type:        "SystemAssigned",
+					PrincipalID: ptr.ToString(vmss.Identity.PrincipalID),
+				})
+			}
+			for id, userAssigned := range vmss.Identity.UserAssignedIdentities {
+				if userAssigned == nil {
+					continue
+				}
+				result.Identities = append(result.Identities, AzManagedIdentity{
+					Type:        "UserAssigned",
+					ResourceID:  id,
+					PrincipalID: ptr.ToString(userAssigned.PrincipalID),
+					ClientID:    ptr.ToString(userAssigned.ClientID),
+				})
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func getVMsTable(vms []AzVM) ([]string, [][]string) {
+	header := []string{"Subscription Name", "Type", "VM Name", "VM Location", "OS", "Power State", "Private IPs", "Public IPs", "Admin Username", "Resource Group Name", "Managed Identities", "Identity Roles", "Finding"}
+	var body [][]string
+	for _, vm := range vms {
+		var identities, roles []string
+		for _, identity := range vm.Identities {
+			if identity.Type == "UserAssigned" {
+				identities = append(identities, fmt.Sprintf("UserAssigned: %s (%s)", lastIDSegment(identity.ResourceID), identity.PrincipalID))
+			} else {
+				identities = append(identities, fmt.Sprintf("SystemAssigned (%s)", identity.PrincipalID))
+			}
+			for _, role := range identity.Roles {
+				roles = append(roles, fmt.Sprintf("%s on %s", role.RoleName, role.Scope))
+			}
+		}
+		var finding string
+		if vm.IsPrivilegeEscalationTarget() {
+			finding = "Privilege escalation target"
+		}
+		body = append(
+			body,
+			[]string{
+				vm.SubscriptionName,
+				vm.Kind,
+				vm.Name,
+				vm.Location,
+				vm.OSType,
+				vm.PowerState,
+				strings.Join(vm.PrivateIPs, "\n"),
+				strings.Join(vm.PublicIPs, "\n"),
+				vm.AdminUsername,
+				vm.ResourceGroup,
+				strings.Join(identities, "\n"),
+				strings.Join(roles, "\n"),
+				finding,
+			},
+		)
+	}
+	return header, body
+}
+
+// getVMsLoot returns the user data of every VM, run-command templates to execute code on them and the IMDS requests
+// that return a token for their managed identities from inside the box
+func getVMsLoot(vms []AzVM) (string, string, string) {
+	var userData, runCommands, imdsCommands strings.Builder
+	for _, vm := range vms {
+		if vm.UserData != "" {
 			//append userdata from this vm to the string with headers and newlines for VM name, location, and resource group name
-			userDataString += fmt.Sprintf(
+			userData.WriteString(fmt.Sprintf(
 				"===============================================================\n"+
 					"VM Name: %s\n"+
 					"Subscription Name: %s\n"+
 					"VM Location: %s\n"+
 					"Resource Group Name: %s\n\n"+
 					"UserData:\n%s\n\n",
-				ptr.ToString(vm.Name),
-				ptr.ToString(sub.DisplayName),
-				ptr.ToString(vmDetails.Location),
-				ptr.ToString(rg.Name),
-				string(userData),
-			)
+				vm.Name,
+				vm.SubscriptionName,
+				vm.Location,
+				vm.ResourceGroup,
+				vm.UserData,
+			))
+		}
 
+		windows := strings.EqualFold(vm.OSType, "Windows")
+		commandID, script := "RunShellScript", "id; hostname"
+		if windows {
+			commandID, script = "RunPowerShellScript", "whoami; hostname"
+		}
+		if vm.Kind == "VMSS" {
+			runCommands.WriteString(fmt.Sprintf("az vmss run-command invoke --subscription %s --resource-group %s --name %s --instance-id 0 --command-id %s --scripts \"%s\"\n", vm.SubscriptionID, vm.ResourceGroup, vm.Name, commandID, script))
+		} else {
+			runCommands.WriteString(fmt.Sprintf("az vm run-command invoke --subscription %s --resource-group %s --name %s --command-id %s --scripts \"%s\"\n", vm.SubscriptionID, vm.ResourceGroup, vm.Name, commandID, script))
 		}
 
-		body = append(
-			body,
-			[]string{
-				subscriptionName,
-				ptr.ToString(vm.Name),
-				ptr.ToString(vm.Location),
-				strings.Join(privateIPs, "\n"),
-				strings.Join(publicIPs, "\n"),
-				adminUsername,
-				ptr.ToString(rg.Name),
-			},
-		)
+		if len(vm.Identities) == 0 {
+			continue
+		}
+		imdsCommands.WriteString(fmt.Sprintf("# %s %s (%s/%s)\n", vm.Kind, vm.Name, vm.SubscriptionName, vm.ResourceGroup))
+		for _, identity := range vm.Identities {
+			tokenURL := "http://169.254.169.254/metadata/identity/oauth2/token?api-version=2018-02-01&resource=https://management.azure.com/"
+			if identity.Type == "UserAssigned" {
+				tokenURL += "&client_id=" + identity.ClientID
+			}
+			if windows {
+				imdsCommands.WriteString(fmt.Sprintf("Invoke-RestMethod -Headers @{Metadata=\"true\"} -Uri \"%s\"\n", tokenURL))
+			} else {
+				imdsCommands.WriteString(fmt.Sprintf("curl -s -H Metadata:true \"%s\"\n", tokenURL))
+			}
+		}
+		imdsCommands.WriteString("\n")
 	}
-	return header, body, userDataString, nil
+	return userData.String(), runCommands.String(), imdsCommands.String()
 }
 
 var getComputeVMsPerResourceGroup = getComputeVMsPerResourceGroupOriginal
@@ -268,8 +502,10 @@ func getComputeVMsPerResourceGroupOriginal(subscriptionID string, resourceGroup
 	return vms, nil
 }
 
+var getComputeVmInfo = getComputeVmInfoOriginal
+
 // get vms with user-data view
-func getComputeVmInfo(subscriptionID string, resourceGroup string, vmName string) (compute.VirtualMachine, error) {
+func getComputeVmInfoOriginal(subscriptionID string, resourceGroup string, vmName string) (compute.VirtualMachine, error) {
 	computeClient := internal.GetVirtualMachinesClient(subscriptionID)
 	vm, err := computeClient.Get(context.Background(), resourceGroup, vmName, compute.InstanceViewTypesUserData)
 	if err != nil {
@@ -278,6 +514,95 @@ func getComputeVmInfo(subscriptionID string, resourceGroup string, vmName string
 	return vm, nil
 }
 
+func mockedGetComputeVmInfo(subscriptionID string, resourceGroup string, vmName string) (compute.VirtualMachine, error) {
+	vms, err := mockedGetComputeVMsPerResourceGroup(subscriptionID, resourceGroup)
+	if err != nil {
+		return compute.VirtualMachine{}, err
+	}
+	for _, vm := range vms {
+		if ptr.ToString(vm.Name) == vmName {
+			return vm, nil
+		}
+	}
+	return compute.VirtualMachine{}, fmt.Errorf("could not get vm %s", vmName)
+}
+
+var getVMPowerState = getVMPowerStateOriginal
+
+func getVMPowerStateOriginal(subscriptionID string, resourceGroup string, vmName string) (string, error) {
+	computeClient := internal.GetVirtualMachinesClient(subscriptionID)
+	instanceView, err := computeClient.InstanceView(context.TODO(), resourceGroup, vmName)
+	if err != nil {
+		return "", fmt.Errorf("could not get instance view of vm %s. %s", vmName, err)
+	}
+	return powerStateFromStatuses(instanceView.Statuses), nil
+}
+
+func mockedGetVMPowerState(subscriptionID string, resourceGroup string, vmName string) (string, error) {
+	vm, err := mockedGetComputeVmInfo(subscriptionID, resourceGroup, vmName)
+	if err != nil {
+		return "", err
+	}
+	if vm.VirtualMachineProperties == nil || vm.InstanceView == nil {
+		return "", nil
+	}
+	return powerStateFromStatuses(vm.InstanceView.Statuses), nil
+}
+
+// powerStateFromStatuses picks the PowerState/... status out of an instance view, e.g. "VM running"
+func powerStateFromStatuses(statuses *[]compute.InstanceViewStatus) string {
+	if statuses == nil {
+		return ""
+	}
+	for _, status := range *statuses {
+		if strings.HasPrefix(ptr.ToString(status.Code), "PowerState/") {
+			if status.DisplayStatus != nil {
+				return ptr.ToString(status.DisplayStatus)
+			}
+			return strings.TrimPrefix(ptr.ToString(status.Code), "PowerState/")
+		}
+	}
+	return ""
+}
+
+var getComputeVMScaleSetsPerResourceGroup = getComputeVMScaleSetsPerResourceGroupOriginal
+
+func getComputeVMScaleSetsPerResourceGroupOriginal(subscriptionID string, resourceGroup string) ([]compute.VirtualMachineScaleSet, error) {
+	client := internal.GetVirtualMachineScaleSetsClient(subscriptionID)
+	var scaleSets []compute.VirtualMachineScaleSet
+
+	for page, err := client.List(context.TODO(), resourceGroup); page.NotDone(); page.Next() {
+		if err != nil {
+			return nil, fmt.Errorf("could not enumerate scale sets in resource group %s. %s", resourceGroup, err)
+		}
+		scaleSets = append(scaleSets, page.Values()...)
+	}
+	return scaleSets, nil
+}
+
+func mockedGetComputeVMScaleSetsPerResourceGroup(subscriptionID, resourceGroup string) ([]compute.VirtualMachineScaleSet, error) {
+	testFile, err := os.ReadFile(globals.VM_SCALE_SETS_TEST_FILE)
+	if err != nil {
+		return nil, fmt.Errorf("could not read file %s", globals.VM_SCALE_SETS_TEST_FILE)
+	}
+
+	var scaleSets []compute.VirtualMachineScaleSet
+	err = json.Unmarshal(testFile, &scaleSets)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshall file %s", globals.VM_SCALE_SETS_TEST_FILE)
+	}
+
+	var results []compute.VirtualMachineScaleSet
+	for _, vmss := range scaleSets {
+		vmssSub := strings.Split(ptr.ToString(vmss.ID), "/")[2]
+		vmssRG := strings.Split(ptr.ToString(vmss.ID), "/")[4]
+		if vmssSub == subscriptionID && vmssRG == resourceGroup {
+			results = append(results, vmss)
+		}
+	}
+	return results, nil
+}
+
 func mockedGetComputeVMsPerResourceGroup(subscriptionID, resourceGroup string) ([]compute.VirtualMachine, error) {
 	testFile, err := os.ReadFile(globals.VMS_TEST_FILE)
 	if err != nil {
@@ -304,7 +629,7 @@ func mockedGetComputeVMsPerResourceGroup(subscriptionID, resourceGroup string) (
 func getIPs(subscriptionID string, resourceGroup string, vm compute.VirtualMachine) ([]string, []string) {
 	var privateIPs, publicIPs []string
 
-	if vm.VirtualMachineProperties.NetworkProfile.NetworkInterfaces != nil {
+	if vm.VirtualMachineProperties != nil && vm.NetworkProfile != nil && vm.NetworkProfile.NetworkInterfaces != nil {
 		for _, nicReference := range *vm.VirtualMachineProperties.NetworkProfile.NetworkInterfaces {
 			nic, err := getNICdetails(subscriptionID, resourceGroup, nicReference)
 			if err != nil {
@@ -3,8 +3,11 @@ package azure
 import (
 	"fmt"
 	"log"
+	"strings"
 	"testing"
 
+	"github.com/aws/smithy-go/ptr"
+
 	"github.com/BishopFox/cloudfox/globals"
 	"github.com/BishopFox/cloudfox/internal"
 )
@@ -81,6 +84,14 @@ func TestAzVMsCommand(t *testing.T) {
 	getComputeVMsPerResourceGroup = mockedGetComputeVMsPerResourceGroup
 	getNICdetails = mockedGetNICdetails
 	getPublicIP = mockedGetPublicIP
+	getComputeVmInfo = mockedGetComputeVmInfo
+	getVMPowerState = mockedGetVMPowerState
+	getComputeVMScaleSetsPerResourceGroup = mockedGetComputeVMScaleSetsPerResourceGroup
+	getRoleAssignments = mockedGetRoleAssignments
+	getRoleDefinitions = mockedGetRoleDefinitions
+	globals.VM_SCALE_SETS_TEST_FILE = "./test-data/vm-scale-sets.json"
+	globals.ROLE_ASSIGNMENTS_TEST_FILE = "./test-data/role-assignments.json"
+	globals.ROLE_DEFINITIONS_TEST_FILE = "./test-data/role-definitions.json"
 
 	for _, s := range subtests {
 		fmt.Println()
@@ -96,3 +107,86 @@ func TestAzVMsCommand(t *testing.T) {
 		}
 	}
 }
+
+func TestGetVMsPerSubscription(t *testing.T) {
+	GetSubscriptions = mockedGetSubscriptions
+	getResourceGroups = mockedGetResourceGroups
+	getComputeVMsPerResourceGroup = mockedGetComputeVMsPerResourceGroup
+	getNICdetails = mockedGetNICdetails
+	getPublicIP = mockedGetPublicIP
+	getComputeVmInfo = mockedGetComputeVmInfo
+	getVMPowerState = mockedGetVMPowerState
+	getComputeVMScaleSetsPerResourceGroup = mockedGetComputeVMScaleSetsPerResourceGroup
+	getRoleAssignments = mockedGetRoleAssignments
+	getRoleDefinitions = mockedGetRoleDefinitions
+	globals.RESOURCES_TEST_FILE = "./test-data/resources.json"
+	globals.VMS_TEST_FILE = "./test-data/vms.json"
+	globals.NICS_TEST_FILE = "./test-data/nics.json"
+	globals.PUBLIC_IPS_TEST_FILE = "./test-data/public-ips.json"
+	globals.VM_SCALE_SETS_TEST_FILE = "./test-data/vm-scale-sets.json"
+	globals.ROLE_ASSIGNMENTS_TEST_FILE = "./test-data/role-assignments.json"
+	globals.ROLE_DEFINITIONS_TEST_FILE = "./test-data/role-definitions.json"
+
+	var vms []AzVM
+	for _, s := range GetSubscriptions() {
+		if ptr.ToString(s.SubscriptionID) == "AAAAAAAA-AAAA-AAAA-AAAA-AAAAAAAA" {
+			vms = getVMsPerSubscription(s)
+		}
+	}
+
+	expected := map[string]struct {
+		kind       string
+		osType     string
+		powerState string
+		roles      string
+		target     bool
+	}{
+		// The system-assigned identity is Contributor on the subscription
+		"TestVM-1": {kind: "VM", osType: "Linux", powerState: "VM running", roles: "Contributor on /subscriptions/AAAAAAAA-AAAA-AAAA-AAAA-AAAAAAAA", target: true},
+		"TestVM-2": {kind: "VM", osType: "Windows", powerState: "VM deallocated", roles: "Reader on /subscriptions/AAAAAAAA-AAAA-AAAA-AAAA-AAAAAAAA"},
+		"TestVM-3": {kind: "VM", osType: "Linux"},
+		// Shares the user-assigned identity of TestVM-2
+		"TestVMSS-1": {kind: "VMSS", osType: "Linux", powerState: "3 instances", roles: "Reader on /subscriptions/AAAAAAAA-AAAA-AAAA-AAAA-AAAAAAAA"},
+	}
+	if len(vms) != len(expected) {
+		t.Fatalf("Expected %d VMs and scale sets, got %d", len(expected), len(vms))
+	}
+	for _, vm := range vms {
+		want, ok := expected[vm.Name]
+		if !ok {
+			t.Errorf("Unexpected VM %s", vm.Name)
+			continue
+		}
+		var roles []string
+		for _, identity := range vm.Identities {
+			for _, role := range identity.Roles {
+				roles = append(roles, fmt.Sprintf("%s on %s", role.RoleName, role.Scope))
+			}
+		}
+		if vm.Kind != want.kind || vm.OSType != want.osType || vm.PowerState != want.powerState {
+			t.Errorf("Unexpected details for %s: %s, %s, %s", vm.Name, vm.Kind, vm.OSType, vm.PowerState)
+		}
+		if strings.Join(roles, ", ") != want.roles {
+			t.Errorf("Expected roles %q for %s, got %q", want.roles, vm.Name, strings.Join(roles, ", "))
+		}
+		if vm.IsPrivilegeEscalationTarget() != want.target {
+			t.Errorf("Expected %s to be a privilege escalation target: %t", vm.Name, want.target)
+		}
+	}
+
+	_, runCommands, imdsCommands := getVMsLoot(vms)
+	expectedLoot := []string{
+		"az vm run-command invoke --subscription AAAAAAAA-AAAA-AAAA-AAAA-AAAAAAAA --resource-group ResourceGroupA1 --name TestVM-1 --command-id RunShellScript",
+		"az vmss run-command invoke --subscription AAAAAAAA-AAAA-AAAA-AAAA-AAAAAAAA --resource-group ResourceGroupA1 --name TestVMSS-1 --instance-id 0",
+		"curl -s -H Metadata:true \"http://169.254.169.254/metadata/identity/oauth2/token?api-version=2018-02-01&resource=https://management.azure.com/\"",
+		"Invoke-RestMethod -Headers @{Metadata=\"true\"} -Uri \"http://169.254.169.254/metadata/identity/oauth2/token?api-version=2018-02-01&resource=https://management.azure.com/&client_id=0f5e1a6c-2b3d-4e5f-8a9b-1c2d3e4f5a6b\"",
+	}
+	for _, want := range expectedLoot {
+		if !strings.Contains(runCommands+imdsCommands, want) {
+			t.Errorf("Expected %s to be in the loot", want)
+		}
+	}
+	if strings.Contains(imdsCommands, "TestVM-3") {
+		t.Errorf("Did not expect IMDS commands for VMs without a managed identity")
+	}
+}
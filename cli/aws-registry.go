@@ -0,0 +1,2353 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/BishopFox/cloudfox/aws"
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/onepassword"
+	"github.com/BishopFox/cloudfox/internal/secretvalidation"
+	"github.com/BishopFox/cloudfox/internal/slack"
+	"github.com/BishopFox/cloudfox/internal/utils"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/accessanalyzer"
+	"github.com/aws/aws-sdk-go-v2/service/acm"
+	"github.com/aws/aws-sdk-go-v2/service/amp"
+	"github.com/aws/aws-sdk-go-v2/service/apigateway"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go-v2/service/apprunner"
+	"github.com/aws/aws-sdk-go-v2/service/athena"
+	"github.com/aws/aws-sdk-go-v2/service/backup"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+	"github.com/aws/aws-sdk-go-v2/service/cloud9"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudsearch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/codeartifact"
+	"github.com/aws/aws-sdk-go-v2/service/codebuild"
+	"github.com/aws/aws-sdk-go-v2/service/codecommit"
+	"github.com/aws/aws-sdk-go-v2/service/codedeploy"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	"github.com/aws/aws-sdk-go-v2/service/datapipeline"
+	"github.com/aws/aws-sdk-go-v2/service/directoryservice"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/efs"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+	"github.com/aws/aws-sdk-go-v2/service/elasticbeanstalk"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/emr"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	"github.com/aws/aws-sdk-go-v2/service/fsx"
+	"github.com/aws/aws-sdk-go-v2/service/glue"
+	"github.com/aws/aws-sdk-go-v2/service/grafana"
+	"github.com/aws/aws-sdk-go-v2/service/guardduty"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iot"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lightsail"
+	"github.com/aws/aws-sdk-go-v2/service/mediastore"
+	"github.com/aws/aws-sdk-go-v2/service/mq"
+	"github.com/aws/aws-sdk-go-v2/service/opensearch"
+	"github.com/aws/aws-sdk-go-v2/service/opensearchserverless"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/ram"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/redshift"
+	"github.com/aws/aws-sdk-go-v2/service/rekognition"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/route53resolver"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub"
+	"github.com/aws/aws-sdk-go-v2/service/sfn"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/transfer"
+	"github.com/aws/aws-sdk-go-v2/service/verifiedpermissions"
+	"github.com/aws/aws-sdk-go-v2/service/wafv2"
+	"github.com/aws/aws-sdk-go-v2/service/workmail"
+	"github.com/kyokomi/emoji"
+	"github.com/spf13/cobra"
+)
+
+// The registry holds every module that all-checks runs. Each registration knows how to build its module from the
+// clients of a profile and the command line flags, so the module's own subcommand and all-checks build it the same way.
+// Registering a module is all it takes for all-checks to pick it up.
+
+// Sections all-checks announces before the first module in them
+const (
+	awsSectionLayOfTheLand = "Getting a lay of the land, aka \"What regions is this account using?\""
+	awsSectionServices     = "Gathering the info you'll want for your application & service enumeration needs."
+	awsSectionSecrets      = "Looking for secrets hidden between the seat cushions."
+	awsSectionPrivesc      = "Arming you with the data you'll need for privesc quests."
+	awsSectionIAM          = "IAM is complicated. Complicated usually means misconfigurations. You'll want to pay attention here."
+)
+
+// awsClients is the client set of one profile, created once and shared by all modules
+type awsClients struct {
+	AccessAnalyzer        *accessanalyzer.Client
+	ACM                   *acm.Client
+	AMP                   *amp.Client
+	APIGateway            *apigateway.Client
+	APIGatewayv2          *apigatewayv2.Client
+	AppRunner             *apprunner.Client
+	Athena                *athena.Client
+	Backup                *backup.Client
+	Batch                 *batch.Client
+	Cloud9                *cloud9.Client
+	CloudFormation        *cloudformation.Client
+	Cloudfront            *cloudfront.Client
+	CloudSearch           *cloudsearch.Client
+	CloudTrail            *cloudtrail.Client
+	CloudWatch            *cloudwatch.Client
+	CloudWatchLogs        *cloudwatchlogs.Client
+	CodeArtifact          *codeartifact.Client
+	CodeBuild             *codebuild.Client
+	CodeCommit            *codecommit.Client
+	CodeDeploy            *codedeploy.Client
+	Config                *configservice.Client
+	DataPipeline          *datapipeline.Client
+	DirectoryService      *directoryservice.Client
+	DynamoDB              *dynamodb.Client
+	EC2                   *ec2.Client
+	ECR                   *ecr.Client
+	ECS                   *ecs.Client
+	EFS                   *efs.Client
+	EKS                   *eks.Client
+	Elasticache           *elasticache.Client
+	ElasticBeanstalk      *elasticbeanstalk.Client
+	ELB                   *elasticloadbalancing.Client
+	ELBv2                 *elasticloadbalancingv2.Client
+	EMR                   *emr.Client
+	EventBridge           *eventbridge.Client
+	Firehose              *firehose.Client
+	FSx                   *fsx.Client
+	Glue                  *glue.Client
+	Grafana               *grafana.Client
+	GuardDuty             *guardduty.Client
+	IAM                   *iam.Client
+	IoT                   *iot.Client
+	Kafka                 *kafka.Client
+	Kinesis               *kinesis.Client
+	KMS                   *kms.Client
+	Lambda                *lambda.Client
+	Lightsail             *lightsail.Client
+	MediaStore            *mediastore.Client
+	MQ                    *mq.Client
+	OpenSearch            *opensearch.Client
+	OpenSearchServerless  *opensearchserverless.Client
+	Organizations         *organizations.Client
+	PrometheusRules       *sdk.PrometheusRulesClient
+	RAM                   *ram.Client
+	RDS                   *rds.Client
+	Redshift              *redshift.Client
+	Rekognition           *rekognition.Client
+	ResourceGroupsTagging *resourcegroupstaggingapi.Client
+	Route53               *route53.Client
+	Route53Resolver       *route53resolver.Client
+	S3                    *s3.Client
+	SageMaker             *sagemaker.Client
+	SecretsManager        *secretsmanager.Client
+	SecurityHub           *securityhub.Client
+	SNS                   *sns.Client
+	SQS                   *sqs.Client
+	SSM                   *ssm.Client
+	StepFunctions         *sfn.Client
+	Transfer              *transfer.Client
+	VerifiedPermissions   *verifiedpermissions.Client
+	WAFv2                 *wafv2.Client
+	WorkMail              *workmail.Client
+}
+
+func newAWSClients(cfg awssdk.Config) *awsClients {
+	return &awsClients{
+		AccessAnalyzer:        accessanalyzer.NewFromConfig(cfg),
+		ACM:                   acm.NewFromConfig(cfg),
+		AMP:                   amp.NewFromConfig(cfg),
+		APIGateway:            apigateway.NewFromConfig(cfg),
+		APIGatewayv2:          apigatewayv2.NewFromConfig(cfg),
+		AppRunner:             apprunner.NewFromConfig(cfg),
+		Athena:                athena.NewFromConfig(cfg),
+		Backup:                backup.NewFromConfig(cfg),
+		Batch:                 batch.NewFromConfig(cfg),
+		Cloud9:                cloud9.NewFromConfig(cfg),
+		CloudFormation:        cloudformation.NewFromConfig(cfg),
+		Cloudfront:            cloudfront.NewFromConfig(cfg),
+		CloudSearch:           cloudsearch.NewFromConfig(cfg),
+		CloudTrail:            cloudtrail.NewFromConfig(cfg),
+		CloudWatch:            cloudwatch.NewFromConfig(cfg),
+		CloudWatchLogs:        cloudwatchlogs.NewFromConfig(cfg),
+		CodeArtifact:          codeartifact.NewFromConfig(cfg),
+		CodeBuild:             codebuild.NewFromConfig(cfg),
+		CodeCommit:            codecommit.NewFromConfig(cfg),
+		CodeDeploy:            codedeploy.NewFromConfig(cfg),
+		Config:                configservice.NewFromConfig(cfg),
+		DataPipeline:          datapipeline.NewFromConfig(cfg),
+		DirectoryService:      directoryservice.NewFromConfig(cfg),
+		DynamoDB:              dynamodb.NewFromConfig(cfg),
+		EC2:                   ec2.NewFromConfig(cfg),
+		ECR:                   ecr.NewFromConfig(cfg),
+		ECS:                   ecs.NewFromConfig(cfg),
+		EFS:                   efs.NewFromConfig(cfg),
+		EKS:                   eks.NewFromConfig(cfg),
+		Elasticache:           elasticache.NewFromConfig(cfg),
+		ElasticBeanstalk:      elasticbeanstalk.NewFromConfig(cfg),
+		ELB:                   elasticloadbalancing.NewFromConfig(cfg),
+		ELBv2:                 elasticloadbalancingv2.NewFromConfig(cfg),
+		EMR:                   emr.NewFromConfig(cfg),
+		EventBridge:           eventbridge.NewFromConfig(cfg),
+		Firehose:              firehose.NewFromConfig(cfg),
+		FSx:                   fsx.NewFromConfig(cfg),
+		Glue:                  glue.NewFromConfig(cfg),
+		Grafana:               grafana.NewFromConfig(cfg),
+		GuardDuty:             guardduty.NewFromConfig(cfg),
+		IAM:                   iam.NewFromConfig(cfg),
+		IoT:                   iot.NewFromConfig(cfg),
+		Kafka:                 kafka.NewFromConfig(cfg),
+		Kinesis:               kinesis.NewFromConfig(cfg),
+		KMS:                   kms.NewFromConfig(cfg),
+		Lambda:                lambda.NewFromConfig(cfg),
+		Lightsail:             lightsail.NewFromConfig(cfg),
+		MediaStore:            mediastore.NewFromConfig(cfg),
+		MQ:                    mq.NewFromConfig(cfg),
+		OpenSearch:            opensearch.NewFromConfig(cfg),
+		OpenSearchServerless:  opensearchserverless.NewFromConfig(cfg),
+		Organizations:         organizations.NewFromConfig(cfg),
+		PrometheusRules:       sdk.NewPrometheusRulesClient(cfg),
+		RAM:                   ram.NewFromConfig(cfg),
+		RDS:                   rds.NewFromConfig(cfg),
+		Redshift:              redshift.NewFromConfig(cfg),
+		Rekognition:           rekognition.NewFromConfig(cfg),
+		ResourceGroupsTagging: resourcegroupstaggingapi.NewFromConfig(cfg),
+		Route53:               route53.NewFromConfig(cfg),
+		Route53Resolver:       route53resolver.NewFromConfig(cfg),
+		S3:                    s3.NewFromConfig(cfg),
+		SageMaker:             sagemaker.NewFromConfig(cfg),
+		SecretsManager:        secretsmanager.NewFromConfig(cfg),
+		SecurityHub:           securityhub.NewFromConfig(cfg),
+		SNS:                   sns.NewFromConfig(cfg),
+		SQS:                   sqs.NewFromConfig(cfg),
+		SSM:                   ssm.NewFromConfig(cfg),
+		StepFunctions:         sfn.NewFromConfig(cfg),
+		Transfer:              transfer.NewFromConfig(cfg),
+		VerifiedPermissions:   verifiedpermissions.NewFromConfig(cfg),
+		WAFv2:                 wafv2.NewFromConfig(cfg),
+		WorkMail:              workmail.NewFromConfig(cfg),
+	}
+}
+
+// awsModuleEnv is what the modules of one profile share
+type awsModuleEnv struct {
+	Profile string
+	Version string
+	Config  awssdk.Config
+	Caller  sts.GetCallerIdentityOutput
+	Clients *awsClients
+	// AllChecks is set when the module runs as part of all-checks, for the few modules that scale down there
+	AllChecks bool
+	// Manifest records every module that runs, it is written to run-manifest.json when the profile is done
+	Manifest *internal.RunManifest
+
+	regions []string
+	// regionsUsed is set when the running module asked for the regions
+	regionsUsed bool
+}
+
+func newAWSModuleEnv(cmd *cobra.Command, profile string) (*awsModuleEnv, error) {
+	version := cmd.Root().Version
+	cfg := internal.AWSConfigFileLoader(profile, version, AWSMFAToken)
+	caller, err := internal.AWSWhoami(profile, version, AWSMFAToken)
+	if err != nil {
+		return nil, err
+	}
+	return &awsModuleEnv{
+		Profile: profile,
+		Version: version,
+		Config:  cfg,
+		Caller:  *caller,
+		Clients: newAWSClients(cfg),
+		Manifest: &internal.RunManifest{
+			Version:     version,
+			Command:     cmd.CommandPath(),
+			Profile:     profile,
+			CallerArn:   awssdk.ToString(caller.Arn),
+			AccountID:   awssdk.ToString(caller.Account),
+			StartTime:   time.Now(),
+			MaxAPICalls: AWSMaxAPICalls,
+		},
+	}, nil
+}
+
+// Regions looks up the enabled regions the first time a module asks for them, so IAM-only modules don't have to
+func (e *awsModuleEnv) Regions() []string {
+	if e.regions == nil {
+		e.regions = internal.GetEnabledRegions(e.Profile, e.Version, AWSMFAToken)
+	}
+	e.regionsUsed = true
+	return e.regions
+}
+
+// OutputDirectory is where the modules write the files of this profile
+func (e *awsModuleEnv) OutputDirectory(outputDirectory string) string {
+	return filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", e.Profile, awssdk.ToString(e.Caller.Account)))
+}
+
+// runModule runs a registered module and records its runtime, regions, API calls and errors in the manifest
+func (e *awsModuleEnv) runModule(registration awsModuleRegistration, outputDirectory string, verbosity int) awsModuleStats {
+	e.regionsUsed = false
+	before := internal.AWSAPICalls.Snapshot()
+	start := time.Now()
+	stats := registration.Run(e, outputDirectory, verbosity)
+	module := internal.ModuleRun{
+		Name:      registration.Name,
+		StartTime: start,
+		EndTime:   time.Now(),
+		APICalls:  internal.DiffAPICalls(before, internal.AWSAPICalls.Snapshot()),
+		Rows:      stats.Rows,
+		Errors:    stats.Errors,
+	}
+	if e.regionsUsed {
+		module.Regions = e.regions
+	}
+	e.Manifest.AddModule(module)
+	return stats
+}
+
+// Checkpoint opens the checkpoint of module in the profile's output directory. Modules that can't checkpoint are
+// simply run from the start, so failing to open one is not fatal.
+func (e *awsModuleEnv) Checkpoint(module string) *internal.Checkpoint {
+	checkpoint, err := internal.OpenCheckpoint(e.OutputDirectory(AWSOutputDirectory), module, AWSResume, AWSCheckpointAge)
+	if err != nil {
+		internal.TxtLog.Errorf("Could not open the %s checkpoint: %s", module, err)
+		fmt.Printf("[%s][%s] Could not open the %s checkpoint, running it from the start: %s\n", cyan(emoji.Sprintf(":fox:cloudfox v%s :fox:", e.Version)), cyan(e.Profile), module, err)
+		return nil
+	}
+	return checkpoint
+}
+
+// writeManifest appends the manifest of this run to run-manifest.json in the profile's output directory
+func (e *awsModuleEnv) writeManifest(outputDirectory string) {
+	e.Manifest.EndTime = time.Now()
+	manifestPath, err := internal.AppendRunManifest(e.OutputDirectory(outputDirectory), *e.Manifest)
+	if err != nil {
+		internal.TxtLog.Errorf("Could not write the run manifest: %s", err)
+		fmt.Printf("[%s][%s] Could not write the run manifest: %s\n", cyan(emoji.Sprintf(":fox:cloudfox v%s :fox:", e.Version)), cyan(e.Profile), err)
+		return
+	}
+	fmt.Printf("[%s][%s] %d API calls made, run manifest written to %s\n", cyan(emoji.Sprintf(":fox:cloudfox v%s :fox:", e.Version)), cyan(e.Profile), e.Manifest.TotalAPICalls, manifestPath)
+}
+
+// awsModuleStats is what a module reports back to all-checks
+type awsModuleStats struct {
+	Rows   int
+	Errors int
+	// Counts are what an IAM module found per category, for the summary of the iam command
+	Counts map[string]int
+}
+
+type awsModuleRegistration struct {
+	Name    string
+	Section string
+	// OutputFile is the main output file relative to the profile's output directory, table/<Name>.txt if empty
+	OutputFile string
+	Run        func(env *awsModuleEnv, outputDirectory string, verbosity int) awsModuleStats
+}
+
+var awsModuleRegistry []awsModuleRegistration
+
+// awsStandaloneModules are the modules all-checks leaves out, like the ones that take too long. They only run from
+// their own subcommand.
+var awsStandaloneModules []awsModuleRegistration
+
+// registerAWSModule adds a module to the registry. newModule builds it from the shared clients and the flags, run
+// prints it and counts what it found.
+func registerAWSModule[T any](name string, section string, newModule func(env *awsModuleEnv) *T, run func(m *T, outputDirectory string, verbosity int) awsModuleStats) {
+	registerAWSModuleWithOutput(name, section, "", newModule, run)
+}
+
+func registerAWSModuleWithOutput[T any](name string, section string, outputFile string, newModule func(env *awsModuleEnv) *T, run func(m *T, outputDirectory string, verbosity int) awsModuleStats) {
+	awsModuleRegistry = append(awsModuleRegistry, newAWSModuleRegistration(name, section, outputFile, newModule, run))
+}
+
+// registerStandaloneAWSModule adds a module that only runs from its own subcommand
+func registerStandaloneAWSModule[T any](name string, newModule func(env *awsModuleEnv) *T, run func(m *T, outputDirectory string, verbosity int) awsModuleStats) {
+	awsStandaloneModules = append(awsStandaloneModules, newAWSModuleRegistration(name, "", "", newModule, run))
+}
+
+func newAWSModuleRegistration[T any](name string, section string, outputFile string, newModule func(env *awsModuleEnv) *T, run func(m *T, outputDirectory string, verbosity int) awsModuleStats) awsModuleRegistration {
+	if _, ok := findAWSModule(name); ok {
+		log.Fatalf("[-] Module %s is registered twice", name)
+	}
+	return awsModuleRegistration{
+		Name:       name,
+		Section:    section,
+		OutputFile: outputFile,
+		Run: func(env *awsModuleEnv, outputDirectory string, verbosity int) awsModuleStats {
+			return run(newModule(env), outputDirectory, verbosity)
+		},
+	}
+}
+
+func findAWSModule(name string) (awsModuleRegistration, bool) {
+	for _, registrations := range [][]awsModuleRegistration{awsModuleRegistry, awsStandaloneModules} {
+		for _, registration := range registrations {
+			if registration.Name == name {
+				return registration, true
+			}
+		}
+	}
+	return awsModuleRegistration{}, false
+}
+
+func lookupAWSModule(name string) awsModuleRegistration {
+	registration, ok := findAWSModule(name)
+	if !ok {
+		log.Fatalf("[-] Module %s is not registered", name)
+	}
+	return registration
+}
+
+// runRegisteredAWSModule is the Run of the subcommand of a registered module
+func runRegisteredAWSModule(cmd *cobra.Command, name string) {
+	registration := lookupAWSModule(name)
+	for _, profile := range AWSProfiles {
+		env, err := newAWSModuleEnv(cmd, profile)
+		if err != nil {
+			continue
+		}
+		env.runModule(registration, AWSOutputDirectory, Verbosity)
+		env.writeManifest(AWSOutputDirectory)
+	}
+}
+
+// awsIAMCommandModules are the registered modules the iam command runs
+var awsIAMCommandModules = []string{"principals", "groups", "permissions", "access-keys", "iam-account"}
+
+// runIAMCommand runs all IAM modules with one caller identity lookup per profile and writes iam-summary with what each
+// of them counted
+func runIAMCommand(cmd *cobra.Command, args []string) {
+	for _, profile := range AWSProfiles {
+		env, err := newAWSModuleEnv(cmd, profile)
+		if err != nil {
+			continue
+		}
+		counts := make(map[string]int)
+		for _, name := range awsIAMCommandModules {
+			stats := env.runModule(lookupAWSModule(name), AWSOutputDirectory, Verbosity)
+			for category, count := range stats.Counts {
+				counts[category] += count
+			}
+		}
+		summary := aws.IAMSummaryModule{
+			Caller:     env.Caller,
+			AWSProfile: env.Profile,
+			WrapTable:  AWSWrapTable,
+			Counts:     counts,
+		}
+		summary.PrintIAMSummary(AWSOutputDirectory, Verbosity)
+		env.writeManifest(AWSOutputDirectory)
+	}
+}
+
+func runAllChecksCommand(cmd *cobra.Command, args []string) {
+	Verbosity = 1
+	for _, profile := range AWSProfiles {
+		env, err := newAWSModuleEnv(cmd, profile)
+		if err != nil {
+			continue
+		}
+		env.AllChecks = true
+		// all-checks checkpoints whole modules, a module that was cut short resumes from its own checkpoint if it has one
+		checkpoint := env.Checkpoint("all-checks")
+
+		var summary [][]string
+		var section string
+		for i, registration := range awsModuleRegistry {
+			if registration.Section != section {
+				section = registration.Section
+				fmt.Printf("[%s] %s\n", cyan(emoji.Sprintf(":fox:cloudfox :fox:")), green(section))
+			}
+			if internal.AWSAPICalls.LimitReached() {
+				fmt.Printf("[%s][%s] Reached --max-api-calls, skipping the remaining %d modules\n", cyan("all-checks"), cyan(profile), len(awsModuleRegistry)-i)
+				break
+			}
+			if internal.AWSAPICalls.CredentialsExpired() {
+				fmt.Printf("[%s][%s] The credentials expired, skipping the remaining %d modules. Refresh them and re-run with --resume.\n", cyan("all-checks"), cyan(profile), len(awsModuleRegistry)-i)
+				break
+			}
+			if checkpoint.Completed("", registration.Name) {
+				fmt.Printf("[%s][%s] Skipping %s (%d/%d), it completed in the run being resumed\n", cyan("all-checks"), cyan(profile), registration.Name, i+1, len(awsModuleRegistry))
+				summary = append(summary, []string{registration.Name, "-", "-", "resumed", "-"})
+				continue
+			}
+			fmt.Printf("[%s][%s] Running %s (%d/%d)\n", cyan("all-checks"), cyan(profile), registration.Name, i+1, len(awsModuleRegistry))
+
+			start := time.Now()
+			stats := env.runModule(registration, AWSOutputDirectory, Verbosity)
+			runtime := time.Since(start).Round(time.Second)
+			fmt.Printf("[%s][%s] Finished %s in %s: %d rows, %d errors\n", cyan("all-checks"), cyan(profile), registration.Name, runtime, stats.Rows, stats.Errors)
+			// A module cut short by the API call limit or expired credentials has to run again
+			if !internal.AWSAPICalls.LimitReached() && !internal.AWSAPICalls.CredentialsExpired() {
+				if err := checkpoint.Complete("", registration.Name, nil); err != nil {
+					internal.TxtLog.Errorf("Could not checkpoint %s: %s", registration.Name, err)
+				}
+			}
+
+			outputFile := registration.OutputFile
+			if outputFile == "" {
+				outputFile = filepath.Join("table", registration.Name+".txt")
+			}
+			outputPath := filepath.Join(env.OutputDirectory(AWSOutputDirectory), outputFile)
+			if info, err := os.Stat(outputPath); err != nil || info.ModTime().Before(start) {
+				outputPath = "-"
+			}
+			summary = append(summary, []string{
+				registration.Name,
+				strconv.Itoa(stats.Rows),
+				strconv.Itoa(stats.Errors),
+				runtime.String(),
+				outputPath,
+			})
+		}
+
+		fmt.Printf("[%s][%s] Summary of all %d modules:\n", cyan("all-checks"), cyan(profile), len(awsModuleRegistry))
+		internal.PrintTableToScreen([]string{"Module", "Rows", "Errors", "Runtime", "Output"}, summary, AWSWrapTable)
+		env.writeManifest(AWSOutputDirectory)
+		fmt.Printf("[%s] %s\n", cyan(emoji.Sprintf(":fox:cloudfox :fox:")), green("That's it! Check your output files for situational awareness and check your loot files for next steps."))
+		fmt.Printf("[%s] %s\n\n", cyan(emoji.Sprintf(":fox:cloudfox :fox:")), green("FYI, we skipped the outbound-assumed-roles module in all-checks (really long run time). Make sure to try it out manually."))
+	}
+}
+
+func init() {
+	registerAWSModule("inventory", awsSectionLayOfTheLand,
+		func(env *awsModuleEnv) *aws.Inventory2Module {
+			return &aws.Inventory2Module{
+				APIGatewayClient:       env.Clients.APIGateway,
+				APIGatewayv2Client:     env.Clients.APIGatewayv2,
+				AppRunnerClient:        env.Clients.AppRunner,
+				AthenaClient:           env.Clients.Athena,
+				Cloud9Client:           env.Clients.Cloud9,
+				CloudFormationClient:   env.Clients.CloudFormation,
+				CloudfrontClient:       env.Clients.Cloudfront,
+				CodeArtifactClient:     env.Clients.CodeArtifact,
+				CodeBuildClient:        env.Clients.CodeBuild,
+				CodeCommitClient:       env.Clients.CodeCommit,
+				CodeDeployClient:       env.Clients.CodeDeploy,
+				DataPipelineClient:     env.Clients.DataPipeline,
+				DynamoDBClient:         env.Clients.DynamoDB,
+				EC2Client:              env.Clients.EC2,
+				ECSClient:              env.Clients.ECS,
+				ECRClient:              env.Clients.ECR,
+				EKSClient:              env.Clients.EKS,
+				ELBClient:              env.Clients.ELB,
+				ELBv2Client:            env.Clients.ELBv2,
+				ElasticacheClient:      env.Clients.Elasticache,
+				ElasticBeanstalkClient: env.Clients.ElasticBeanstalk,
+				EMRClient:              env.Clients.EMR,
+				GlueClient:             env.Clients.Glue,
+				GrafanaClient:          env.Clients.Grafana,
+				IAMClient:              env.Clients.IAM,
+				KinesisClient:          env.Clients.Kinesis,
+				LambdaClient:           env.Clients.Lambda,
+				LightsailClient:        env.Clients.Lightsail,
+				MQClient:               env.Clients.MQ,
+				OpenSearchClient:       env.Clients.OpenSearch,
+				RDSClient:              env.Clients.RDS,
+				RedshiftClient:         env.Clients.Redshift,
+				Route53Client:          env.Clients.Route53,
+				S3Client:               env.Clients.S3,
+				SecretsManagerClient:   env.Clients.SecretsManager,
+				SNSClient:              env.Clients.SNS,
+				SQSClient:              env.Clients.SQS,
+				SSMClient:              env.Clients.SSM,
+				StepFunctionClient:     env.Clients.StepFunctions,
+
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.Inventory2Module, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintInventoryPerRegion(outputDirectory, verbosity)
+			return awsModuleStats{Rows: m.RegionResourceCount, Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("tags", awsSectionLayOfTheLand,
+		func(env *awsModuleEnv) *aws.TagsModule {
+			m := &aws.TagsModule{
+				ResourceGroupsTaggingApiInterface: env.Clients.ResourceGroupsTagging,
+				Caller:                            env.Caller,
+				AWSRegions:                        env.Regions(),
+				AWSProfile:                        env.Profile,
+				Goroutines:                        Goroutines,
+				WrapTable:                         AWSWrapTable,
+				MaxResourcesPerRegion:             MaxResourcesPerRegion,
+				TagKey:                            TagsTagKey,
+				TagValues:                         TagsTagValues,
+				IncludeUntagged:                   TagsIncludeUntagged,
+				AWSOutputType:                     AWSOutputType,
+				AWSTableCols:                      AWSTableCols,
+			}
+			// Tagging every resource of a big account takes ages, all-checks only samples them
+			if env.AllChecks && m.MaxResourcesPerRegion == 0 {
+				m.MaxResourcesPerRegion = 1000
+			}
+			return m
+		},
+		func(m *aws.TagsModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintTags(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Tags), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("org", awsSectionLayOfTheLand,
+		func(env *awsModuleEnv) *aws.OrgModule {
+			return &aws.OrgModule{
+				OrganizationsClient: env.Clients.Organizations,
+				Caller:              env.Caller,
+				AWSProfile:          env.Profile,
+				WrapTable:           AWSWrapTable,
+				AWSOutputType:       AWSOutputType,
+				AWSTableCols:        AWSTableCols,
+			}
+		},
+		func(m *aws.OrgModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintOrgAccounts(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Accounts), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("instances", awsSectionServices,
+		func(env *awsModuleEnv) *aws.InstancesModule {
+			return &aws.InstancesModule{
+				EC2Client: env.Clients.EC2,
+				IAMClient: env.Clients.IAM,
+
+				Caller:                 env.Caller,
+				AWSRegions:             env.Regions(),
+				UserDataAttributesOnly: InstanceMapUserDataAttributesOnly,
+				AWSProfile:             env.Profile,
+				SkipAdminCheck:         AWSSkipAdminCheck,
+				WrapTable:              AWSWrapTable,
+				AWSOutputType:          AWSOutputType,
+				AWSTableCols:           AWSTableCols,
+				PmapperDataBasePath:    PmapperDataBasePath,
+			}
+		},
+		func(m *aws.InstancesModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.Instances(InstancesFilter, outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.MappedInstances), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("iot", awsSectionServices,
+		func(env *awsModuleEnv) *aws.IoTModule {
+			return &aws.IoTModule{
+				IoTClient: env.Clients.IoT,
+
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.IoTModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintIoT(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Policies), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("lambda", awsSectionServices,
+		func(env *awsModuleEnv) *aws.LambdasModule {
+			return &aws.LambdasModule{
+				LambdaClient:        env.Clients.Lambda,
+				IAMClient:           env.Clients.IAM,
+				Caller:              env.Caller,
+				AWSRegions:          env.Regions(),
+				AWSProfile:          env.Profile,
+				Goroutines:          Goroutines,
+				SkipAdminCheck:      AWSSkipAdminCheck,
+				WrapTable:           AWSWrapTable,
+				AWSOutputType:       AWSOutputType,
+				AWSTableCols:        AWSTableCols,
+				PmapperDataBasePath: PmapperDataBasePath,
+			}
+		},
+		func(m *aws.LambdasModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintLambdas(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Lambdas), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("route53", awsSectionServices,
+		func(env *awsModuleEnv) *aws.Route53Module {
+			return &aws.Route53Module{
+				Route53Client: env.Clients.Route53,
+
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.Route53Module, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintRoute53(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Records), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("filesystems", awsSectionServices,
+		func(env *awsModuleEnv) *aws.FilesystemsModule {
+			return &aws.FilesystemsModule{
+				EFSClient: env.Clients.EFS,
+				FSxClient: env.Clients.FSx,
+
+				Caller:        env.Caller,
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				AWSRegions:    env.Regions(),
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.FilesystemsModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintFilesystems(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Filesystems), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("efs", awsSectionServices,
+		func(env *awsModuleEnv) *aws.EFSModule {
+			return &aws.EFSModule{
+				EFSClient:       env.Clients.EFS,
+				EFSConfigClient: env.Clients.EFS,
+				EC2Client:       env.Clients.EC2,
+				LambdaClient:    env.Clients.Lambda,
+
+				Caller:        env.Caller,
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				AWSRegions:    env.Regions(),
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.EFSModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintEFS(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.FileSystems) + len(m.LambdaMounts), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("transfer", awsSectionServices,
+		func(env *awsModuleEnv) *aws.TransferModule {
+			return &aws.TransferModule{
+				TransferClient: env.Clients.Transfer,
+
+				Caller:        env.Caller,
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				AWSRegions:    env.Regions(),
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.TransferModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintTransfer(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Servers), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("alb-bypass", awsSectionServices,
+		func(env *awsModuleEnv) *aws.ALBBypassModule {
+			return &aws.ALBBypassModule{
+				ELBv2Client: env.Clients.ELBv2,
+
+				Caller:        env.Caller,
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				AWSRegions:    env.Regions(),
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.ALBBypassModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintALBBypass(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Rules), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("cloudsearch", awsSectionServices,
+		func(env *awsModuleEnv) *aws.CloudSearchModule {
+			return &aws.CloudSearchModule{
+				CloudSearchClient: env.Clients.CloudSearch,
+
+				Caller:        env.Caller,
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				AWSRegions:    env.Regions(),
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.CloudSearchModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintCloudSearch(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Domains), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("glue-catalog", awsSectionServices,
+		func(env *awsModuleEnv) *aws.GlueDataCatalogModule {
+			return &aws.GlueDataCatalogModule{
+				GlueClient: env.Clients.Glue,
+				S3Client:   env.Clients.S3,
+
+				Caller:        env.Caller,
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				AWSRegions:    env.Regions(),
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.GlueDataCatalogModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintGlueDataCatalog(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Tables), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("rekognition-streaming", awsSectionServices,
+		func(env *awsModuleEnv) *aws.RekognitionStreamingModule {
+			return &aws.RekognitionStreamingModule{
+				RekognitionClient: env.Clients.Rekognition,
+				S3Client:          env.Clients.S3,
+				IAMClient:         env.Clients.IAM,
+
+				Caller:        env.Caller,
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				AWSRegions:    env.Regions(),
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.RekognitionStreamingModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintRekognitionStreaming(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.StreamProcessors), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("endpoints", awsSectionServices,
+		func(env *awsModuleEnv) *aws.EndpointsModule {
+			return &aws.EndpointsModule{
+				APIGatewayClient:       env.Clients.APIGateway,
+				APIGatewayv2Client:     env.Clients.APIGatewayv2,
+				AppRunnerClient:        env.Clients.AppRunner,
+				CloudfrontClient:       env.Clients.Cloudfront,
+				EKSClient:              env.Clients.EKS,
+				ElasticBeanstalkClient: env.Clients.ElasticBeanstalk,
+				ELBClient:              env.Clients.ELB,
+				ELBv2Client:            env.Clients.ELBv2,
+				GrafanaClient:          env.Clients.Grafana,
+				LambdaClient:           env.Clients.Lambda,
+				LightsailClient:        env.Clients.Lightsail,
+				MQClient:               env.Clients.MQ,
+				OpenSearchClient:       env.Clients.OpenSearch,
+				RDSClient:              env.Clients.RDS,
+				RedshiftClient:         env.Clients.Redshift,
+				S3Client:               env.Clients.S3,
+
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.EndpointsModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintEndpoints(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Endpoints), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("api-gw", awsSectionServices,
+		func(env *awsModuleEnv) *aws.ApiGwModule {
+			return &aws.ApiGwModule{
+				APIGatewayClient:   env.Clients.APIGateway,
+				APIGatewayv2Client: env.Clients.APIGatewayv2,
+
+				Caller:     env.Caller,
+				AWSRegions: env.Regions(),
+				AWSProfile: env.Profile,
+				Goroutines: Goroutines,
+				WrapTable:  AWSWrapTable,
+			}
+		},
+		func(m *aws.ApiGwModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintApiGws(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Gateways), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("databases", awsSectionServices,
+		func(env *awsModuleEnv) *aws.DatabasesModule {
+			return &aws.DatabasesModule{
+				RDSClient:      env.Clients.RDS,
+				RedshiftClient: env.Clients.Redshift,
+				DynamoDBClient: env.Clients.DynamoDB,
+				Caller:         env.Caller,
+				AWSRegions:     env.Regions(),
+				AWSProfile:     env.Profile,
+				Goroutines:     Goroutines,
+				WrapTable:      AWSWrapTable,
+				AWSOutputType:  AWSOutputType,
+				AWSTableCols:   AWSTableCols,
+			}
+		},
+		func(m *aws.DatabasesModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintDatabases(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Databases), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("ecs-tasks", awsSectionServices,
+		func(env *awsModuleEnv) *aws.ECSTasksModule {
+			return &aws.ECSTasksModule{
+				EC2Client: env.Clients.EC2,
+				ECSClient: env.Clients.ECS,
+				IAMClient: env.Clients.IAM,
+
+				Caller:              env.Caller,
+				AWSRegions:          env.Regions(),
+				AWSProfile:          env.Profile,
+				Goroutines:          Goroutines,
+				SkipAdminCheck:      AWSSkipAdminCheck,
+				WrapTable:           AWSWrapTable,
+				AWSOutputType:       AWSOutputType,
+				AWSTableCols:        AWSTableCols,
+				PmapperDataBasePath: PmapperDataBasePath,
+			}
+		},
+		func(m *aws.ECSTasksModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.ECSTasks(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.MappedECSTasks), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("eks", awsSectionServices,
+		func(env *awsModuleEnv) *aws.EKSModule {
+			return &aws.EKSModule{
+				IAMClient: env.Clients.IAM,
+				EKSClient: env.Clients.EKS,
+
+				Caller:              env.Caller,
+				AWSRegions:          env.Regions(),
+				AWSProfile:          env.Profile,
+				Goroutines:          Goroutines,
+				SkipAdminCheck:      AWSSkipAdminCheck,
+				WrapTable:           AWSWrapTable,
+				AWSOutputType:       AWSOutputType,
+				AWSTableCols:        AWSTableCols,
+				PmapperDataBasePath: PmapperDataBasePath,
+			}
+		},
+		func(m *aws.EKSModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.EKS(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Clusters), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("elastic-network-interfaces", awsSectionServices,
+		func(env *awsModuleEnv) *aws.ElasticNetworkInterfacesModule {
+			return &aws.ElasticNetworkInterfacesModule{
+				EC2Client: env.Clients.EC2,
+
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.ElasticNetworkInterfacesModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.ElasticNetworkInterfaces(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.MappedENIs), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("eventbridge", awsSectionServices,
+		func(env *awsModuleEnv) *aws.EventBridgeModule {
+			return &aws.EventBridgeModule{
+				EventBridgeClient: env.Clients.EventBridge,
+				IAMClient:         env.Clients.IAM,
+
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.EventBridgeModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintEventBridge(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Targets), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("lightsail", awsSectionServices,
+		func(env *awsModuleEnv) *aws.LightsailModule {
+			return &aws.LightsailModule{
+				LightsailClient: env.Clients.Lightsail,
+				Caller:          env.Caller,
+				AWSRegions:      env.Regions(),
+				AWSProfile:      env.Profile,
+				Goroutines:      Goroutines,
+				WrapTable:       AWSWrapTable,
+				AWSOutputType:   AWSOutputType,
+				AWSTableCols:    AWSTableCols,
+			}
+		},
+		func(m *aws.LightsailModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintLightsail(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Resources), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("mediastore", awsSectionServices,
+		func(env *awsModuleEnv) *aws.MediaStoreModule {
+			return &aws.MediaStoreModule{
+				MediaStoreClient: env.Clients.MediaStore,
+
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.MediaStoreModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintMediaStore(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Containers), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("msk", awsSectionServices,
+		func(env *awsModuleEnv) *aws.MSKModule {
+			return &aws.MSKModule{
+				KafkaClient:   env.Clients.Kafka,
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.MSKModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintMSK(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Clusters), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("sagemaker", awsSectionServices,
+		func(env *awsModuleEnv) *aws.SageMakerModule {
+			return &aws.SageMakerModule{
+				SageMakerClient:     env.Clients.SageMaker,
+				IAMClient:           env.Clients.IAM,
+				Caller:              env.Caller,
+				AWSRegions:          env.Regions(),
+				AWSProfile:          env.Profile,
+				Goroutines:          Goroutines,
+				SkipAdminCheck:      AWSSkipAdminCheck,
+				WrapTable:           AWSWrapTable,
+				AWSOutputType:       AWSOutputType,
+				AWSTableCols:        AWSTableCols,
+				PmapperDataBasePath: PmapperDataBasePath,
+			}
+		},
+		func(m *aws.SageMakerModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintSageMaker(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Resources), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("sagemaker-pipelines", awsSectionServices,
+		func(env *awsModuleEnv) *aws.SageMakerPipelinesModule {
+			return &aws.SageMakerPipelinesModule{
+				SageMakerClient: env.Clients.SageMaker,
+				IAMClient:       env.Clients.IAM,
+
+				Caller:        env.Caller,
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				AWSRegions:    env.Regions(),
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.SageMakerPipelinesModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintSageMakerPipelines(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Pipelines), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("verified-access-logs", awsSectionServices,
+		func(env *awsModuleEnv) *aws.VerifiedAccessLogsModule {
+			return &aws.VerifiedAccessLogsModule{
+				EC2Client:            env.Clients.EC2,
+				CloudWatchLogsClient: env.Clients.CloudWatchLogs,
+				S3Client:             env.Clients.S3,
+				Caller:               env.Caller,
+				AWSRegions:           env.Regions(),
+				AWSProfile:           env.Profile,
+				Goroutines:           Goroutines,
+				WrapTable:            AWSWrapTable,
+				AWSOutputType:        AWSOutputType,
+				AWSTableCols:         AWSTableCols,
+				Days:                 VerifiedAccessLogsDays,
+			}
+		},
+		func(m *aws.VerifiedAccessLogsModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintVerifiedAccessLogs(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.AccessPatterns) + len(m.Endpoints), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("waf-logging", awsSectionServices,
+		func(env *awsModuleEnv) *aws.WAFLoggingModule {
+			return &aws.WAFLoggingModule{
+				WAFv2Client:          env.Clients.WAFv2,
+				S3Client:             env.Clients.S3,
+				CloudWatchLogsClient: env.Clients.CloudWatchLogs,
+				FirehoseClient:       env.Clients.Firehose,
+				Caller:               env.Caller,
+				AWSRegions:           env.Regions(),
+				AWSProfile:           env.Profile,
+				Goroutines:           Goroutines,
+				WrapTable:            AWSWrapTable,
+				AWSOutputType:        AWSOutputType,
+				AWSTableCols:         AWSTableCols,
+			}
+		},
+		func(m *aws.WAFLoggingModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintWAFLogging(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.WebACLs), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("acm", awsSectionServices,
+		func(env *awsModuleEnv) *aws.ACMModule {
+			return &aws.ACMModule{
+				ACMClient:     env.Clients.ACM,
+				Caller:        env.Caller,
+				AWSProfile:    env.Profile,
+				AWSRegions:    env.Regions(),
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+				ExpiryDays:    ACMCertExpiryDays,
+			}
+		},
+		func(m *aws.ACMModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintCertificates(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Certificates), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("backup", awsSectionServices,
+		func(env *awsModuleEnv) *aws.BackupModule {
+			return &aws.BackupModule{
+				BackupClient:   env.Clients.Backup,
+				EC2Client:      env.Clients.EC2,
+				RDSClient:      env.Clients.RDS,
+				EFSClient:      env.Clients.EFS,
+				DynamoDBClient: env.Clients.DynamoDB,
+
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.BackupModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintBackup(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.BackupPlans) + len(m.UncoveredResources), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("data-pipeline", awsSectionServices,
+		func(env *awsModuleEnv) *aws.DataPipelineModule {
+			return &aws.DataPipelineModule{
+				DataPipelineClient: env.Clients.DataPipeline,
+				Caller:             env.Caller,
+				AWSRegions:         env.Regions(),
+				AWSProfile:         env.Profile,
+				Goroutines:         Goroutines,
+				WrapTable:          AWSWrapTable,
+				AWSOutputType:      AWSOutputType,
+				AWSTableCols:       AWSTableCols,
+			}
+		},
+		func(m *aws.DataPipelineModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintDataPipelines(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.PipelineObjects), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("defenses", awsSectionServices,
+		func(env *awsModuleEnv) *aws.DefensesModule {
+			return &aws.DefensesModule{
+				GuardDutyClient:      env.Clients.GuardDuty,
+				CloudTrailClient:     env.Clients.CloudTrail,
+				ConfigClient:         env.Clients.Config,
+				SecurityHubClient:    env.Clients.SecurityHub,
+				AccessAnalyzerClient: env.Clients.AccessAnalyzer,
+				Caller:               env.Caller,
+				AWSRegions:           env.Regions(),
+				AWSProfile:           env.Profile,
+				Goroutines:           Goroutines,
+				WrapTable:            AWSWrapTable,
+				AWSOutputType:        AWSOutputType,
+				AWSTableCols:         AWSTableCols,
+			}
+		},
+		func(m *aws.DefensesModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintDefenses(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Controls), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("cloudtrail-gaps", awsSectionServices,
+		func(env *awsModuleEnv) *aws.CloudTrailGapsModule {
+			return &aws.CloudTrailGapsModule{
+				CloudTrailClient:  env.Clients.CloudTrail,
+				Caller:            env.Caller,
+				AWSProfile:        env.Profile,
+				AWSRegions:        env.Regions(),
+				Goroutines:        Goroutines,
+				WrapTable:         AWSWrapTable,
+				AWSOutputType:     AWSOutputType,
+				AWSTableCols:      AWSTableCols,
+				Days:              CloudTrailGapsDays,
+				SecurityToolRoles: CloudTrailGapsSecurityToolRoles,
+			}
+		},
+		func(m *aws.CloudTrailGapsModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintCloudTrailGaps(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Trails) + len(m.StopLoggingEvents), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("legacy-services", awsSectionServices,
+		func(env *awsModuleEnv) *aws.LegacyServicesModule {
+			return &aws.LegacyServicesModule{
+				S3Client:         env.Clients.S3,
+				IAMClient:        env.Clients.IAM,
+				WorkMailClient:   env.Clients.WorkMail,
+				OpenSearchClient: env.Clients.OpenSearch,
+				Caller:           env.Caller,
+				AWSRegions:       env.Regions(),
+				AWSProfile:       env.Profile,
+				Goroutines:       Goroutines,
+				WrapTable:        AWSWrapTable,
+				AWSOutputType:    AWSOutputType,
+				AWSTableCols:     AWSTableCols,
+			}
+		},
+		func(m *aws.LegacyServicesModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintLegacyServices(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.LegacyResources), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("outposts-routing", awsSectionServices,
+		func(env *awsModuleEnv) *aws.OutpostsRoutingModule {
+			return &aws.OutpostsRoutingModule{
+				EC2Client:     env.Clients.EC2,
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.OutpostsRoutingModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintOutpostsRouting(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.OutpostsRoutes), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("opensearch-serverless", awsSectionServices,
+		func(env *awsModuleEnv) *aws.OpenSearchServerlessModule {
+			return &aws.OpenSearchServerlessModule{
+				OpenSearchServerlessClient: env.Clients.OpenSearchServerless,
+
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.OpenSearchServerlessModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintOpenSearchServerless(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Collections), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("rds-proxy", awsSectionServices,
+		func(env *awsModuleEnv) *aws.RDSProxyModule {
+			return &aws.RDSProxyModule{
+				RDSClient:     env.Clients.RDS,
+				Caller:        env.Caller,
+				AWSProfile:    env.Profile,
+				AWSRegions:    env.Regions(),
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.RDSProxyModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintRDSProxies(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Proxies), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("ssm-automation", awsSectionServices,
+		func(env *awsModuleEnv) *aws.SSMAutomationHistoryModule {
+			return &aws.SSMAutomationHistoryModule{
+				SSMClient:     env.Clients.SSM,
+				Caller:        env.Caller,
+				AWSProfile:    env.Profile,
+				AWSRegions:    env.Regions(),
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+				Days:          SSMAutomationDays,
+			}
+		},
+		func(m *aws.SSMAutomationHistoryModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintSSMAutomationHistory(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.AutomationExecutions), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("waf", awsSectionServices,
+		func(env *awsModuleEnv) *aws.WAFModule {
+			return &aws.WAFModule{
+				WAFv2Client:      env.Clients.WAFv2,
+				CloudFrontClient: env.Clients.Cloudfront,
+				Caller:           env.Caller,
+				AWSRegions:       env.Regions(),
+				AWSProfile:       env.Profile,
+				Goroutines:       Goroutines,
+				WrapTable:        AWSWrapTable,
+				AWSOutputType:    AWSOutputType,
+				AWSTableCols:     AWSTableCols,
+			}
+		},
+		func(m *aws.WAFModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintWebACLs(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.WebACLs), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("dns-firewall", awsSectionServices,
+		func(env *awsModuleEnv) *aws.DNSFirewallModule {
+			return &aws.DNSFirewallModule{
+				Route53ResolverClient: env.Clients.Route53Resolver,
+				EC2Client:             env.Clients.EC2,
+				Caller:                env.Caller,
+				AWSRegions:            env.Regions(),
+				AWSProfile:            env.Profile,
+				Goroutines:            Goroutines,
+				WrapTable:             AWSWrapTable,
+				AWSOutputType:         AWSOutputType,
+				AWSTableCols:          AWSTableCols,
+			}
+		},
+		func(m *aws.DNSFirewallModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintDNSFirewall(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.VPCProtections), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("ecs", awsSectionServices,
+		func(env *awsModuleEnv) *aws.ECSModule {
+			return &aws.ECSModule{
+				ECSClient: env.Clients.ECS,
+				IAMClient: env.Clients.IAM,
+
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.ECSModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintECS(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.ECSServices), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("endpoint-services", awsSectionServices,
+		func(env *awsModuleEnv) *aws.EndpointServicesModule {
+			return &aws.EndpointServicesModule{
+				EC2Client: env.Clients.EC2,
+
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.EndpointServicesModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintEndpointServices(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.EndpointServices), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("amp-rules", awsSectionServices,
+		func(env *awsModuleEnv) *aws.AMPRulesModule {
+			return &aws.AMPRulesModule{
+				AMPClient:             env.Clients.AMP,
+				PrometheusRulesClient: env.Clients.PrometheusRules,
+
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.AMPRulesModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintAMPRules(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Workspaces), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("aurora-global", awsSectionServices,
+		func(env *awsModuleEnv) *aws.AuroraGlobalModule {
+			return &aws.AuroraGlobalModule{
+				RDSClient:        env.Clients.RDS,
+				CloudWatchClient: env.Clients.CloudWatch,
+				IAMClient:        env.Clients.IAM,
+
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.AuroraGlobalModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintAuroraGlobalClusters(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.GlobalClusters), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("msk-replicator", awsSectionServices,
+		func(env *awsModuleEnv) *aws.MSKReplicatorModule {
+			return &aws.MSKReplicatorModule{
+				KafkaClient:         env.Clients.Kafka,
+				IAMClient:           env.Clients.IAM,
+				OrganizationsClient: env.Clients.Organizations,
+
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.MSKReplicatorModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintMSKReplicators(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Replications), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("log-groups", awsSectionServices,
+		func(env *awsModuleEnv) *aws.LogGroupsModule {
+			return &aws.LogGroupsModule{
+				CloudWatchLogsClient: env.Clients.CloudWatchLogs,
+				Caller:               env.Caller,
+				AWSRegions:           env.Regions(),
+				AWSProfile:           env.Profile,
+				Goroutines:           Goroutines,
+				WrapTable:            AWSWrapTable,
+				AWSOutputType:        AWSOutputType,
+				AWSTableCols:         AWSTableCols,
+				SearchLogs:           LogGroupsSearchLogs,
+				SearchTerms:          LogGroupsSearchTerms,
+				MinSize:              LogGroupsMinSize,
+			}
+		},
+		func(m *aws.LogGroupsModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintLogGroups(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.LogGroups), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("mq", awsSectionServices,
+		func(env *awsModuleEnv) *aws.AmazonMQModule {
+			return &aws.AmazonMQModule{
+				MQClient:      env.Clients.MQ,
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.AmazonMQModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintMQBrokers(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Brokers), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	// instance-userdata has no subcommand of its own, it is the instances module with --userdata
+	registerAWSModuleWithOutput("instance-userdata", awsSectionSecrets, filepath.Join("loot", "instance-userdata.txt"),
+		func(env *awsModuleEnv) *aws.InstancesModule {
+			return &aws.InstancesModule{
+				EC2Client:  env.Clients.EC2,
+				IAMClient:  env.Clients.IAM,
+				Caller:     env.Caller,
+				AWSRegions: env.Regions(),
+
+				UserDataAttributesOnly: true,
+				AWSProfile:             env.Profile,
+				Goroutines:             Goroutines,
+				WrapTable:              AWSWrapTable,
+				AWSOutputType:          AWSOutputType,
+				AWSTableCols:           AWSTableCols,
+			}
+		},
+		func(m *aws.InstancesModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.Instances(InstancesFilter, outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.MappedInstances), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("launch-template-secrets", awsSectionSecrets,
+		func(env *awsModuleEnv) *aws.LaunchTemplateSecretsModule {
+			return &aws.LaunchTemplateSecretsModule{
+				EC2Client: env.Clients.EC2,
+
+				Caller:        env.Caller,
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				AWSRegions:    env.Regions(),
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.LaunchTemplateSecretsModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintLaunchTemplateSecrets(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Secrets), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("env-vars", awsSectionSecrets,
+		func(env *awsModuleEnv) *aws.EnvsModule {
+			return &aws.EnvsModule{
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+
+				ECSClient:       env.Clients.ECS,
+				AppRunnerClient: env.Clients.AppRunner,
+				LambdaClient:    env.Clients.Lambda,
+				LightsailClient: env.Clients.Lightsail,
+				SagemakerClient: env.Clients.SageMaker,
+			}
+		},
+		func(m *aws.EnvsModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintEnvs(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.EnvironmentVariables), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("cloudformation", awsSectionSecrets,
+		func(env *awsModuleEnv) *aws.CloudformationModule {
+			return &aws.CloudformationModule{
+				CloudFormationClient: env.Clients.CloudFormation,
+				Caller:               env.Caller,
+				AWSRegions:           env.Regions(),
+				AWSProfile:           env.Profile,
+				Goroutines:           Goroutines,
+				WrapTable:            AWSWrapTable,
+				AWSOutputType:        AWSOutputType,
+				AWSTableCols:         AWSTableCols,
+			}
+		},
+		func(m *aws.CloudformationModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintCloudformationStacks(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.CFStacks), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("workflow-secrets", awsSectionSecrets,
+		func(env *awsModuleEnv) *aws.WorkflowSecretsModule {
+			return &aws.WorkflowSecretsModule{
+				StepFunctionsClient: env.Clients.StepFunctions,
+				SSMClient:           env.Clients.SSM,
+
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.WorkflowSecretsModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintWorkflowSecrets(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Findings), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("secret-access-anomalies", awsSectionSecrets,
+		func(env *awsModuleEnv) *aws.SecretAccessAnomalyModule {
+			return &aws.SecretAccessAnomalyModule{
+				CloudTrailClient: env.Clients.CloudTrail,
+				Caller:           env.Caller,
+				AWSProfile:       env.Profile,
+				AWSRegions:       env.Regions(),
+				Goroutines:       Goroutines,
+				WrapTable:        AWSWrapTable,
+				AWSOutputType:    AWSOutputType,
+				AWSTableCols:     AWSTableCols,
+				Days:             SecretAccessAnomaliesDays,
+			}
+		},
+		func(m *aws.SecretAccessAnomalyModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintSecretAccessAnomalies(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.SecretAccesses), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("buckets", awsSectionPrivesc,
+		func(env *awsModuleEnv) *aws.BucketsModule {
+			return &aws.BucketsModule{
+				S3Client:            env.Clients.S3,
+				Caller:              env.Caller,
+				AWSRegions:          env.Regions(),
+				AWSProfile:          env.Profile,
+				Goroutines:          Goroutines,
+				WrapTable:           AWSWrapTable,
+				CheckBucketPolicies: CheckBucketPolicies,
+				AWSOutputType:       AWSOutputType,
+				AWSTableCols:        AWSTableCols,
+			}
+		},
+		func(m *aws.BucketsModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintBuckets(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Buckets), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("ecr", awsSectionPrivesc,
+		func(env *awsModuleEnv) *aws.ECRModule {
+			return &aws.ECRModule{
+				ECRClient:     env.Clients.ECR,
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.ECRModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintECR(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Repositories), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("secrets", awsSectionPrivesc,
+		func(env *awsModuleEnv) *aws.SecretsModule {
+			m := &aws.SecretsModule{
+				SecretsManagerClient: env.Clients.SecretsManager,
+				SSMClient:            env.Clients.SSM,
+				ConfigClient:         env.Clients.Config,
+				AppRunnerClient:      env.Clients.AppRunner,
+
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+				AnsibleLoot:   SecretsAnsibleLoot,
+				TerraformLoot: SecretsTerraformLoot,
+
+				NucleiTemplates:   SecretsNucleiTemplates,
+				Stream:            SecretsStream,
+				PreferProd:        SecretsPreferProd,
+				ResolveValues:     SecretsResolveSSMValues,
+				ConfirmShowValues: SecretsConfirmShowValues,
+				Since:             parseSecretsSince(),
+				IAMClient:         env.Clients.IAM,
+				AnalyzeAccess:     SecretsAnalyzeAccess,
+				LootTemplateDir:   AWSLootTemplateDir,
+				SummarizePaths:    SecretsSummarizePaths,
+				Checkpoint:        env.Checkpoint("secrets"),
+			}
+			if SecretsNamesFile != "" {
+				m.SecretNames = internal.GetSecretNames(SecretsNamesFile)
+			}
+			if SecretsDescribeName != "" {
+				m.DescribeName = SecretsDescribeName
+				if SecretsDescribeRegion != "" {
+					m.AWSRegions = []string{SecretsDescribeRegion}
+				}
+			} else if SecretsDescribeRegion != "" {
+				log.Fatalf("[-] --region needs --name")
+			}
+			if SecretsCompare1Password {
+				client, err := onepassword.NewConnectClientFromEnv()
+				if err != nil {
+					log.Fatalf("[-] %s", err)
+				}
+				m.OnePasswordClient = client
+			}
+			if SecretsValidate {
+				m.Validator = secretvalidation.NewValidator()
+			}
+			if SecretsSlackWebhook != "" {
+				client, err := slack.NewWebhookClient(SecretsSlackWebhook)
+				if err != nil {
+					log.Fatalf("[-] %s", err)
+				}
+				m.Slack = client
+				m.SlackRealtime = SecretsSlackRealtime
+			} else if SecretsSlackRealtime {
+				log.Fatalf("[-] --slack-realtime needs --slack-webhook")
+			}
+			if SecretsProdPattern != "" {
+				if !SecretsPreferProd {
+					log.Fatalf("[-] --prod-pattern needs --prefer-prod")
+				}
+				pattern, err := regexp.Compile(SecretsProdPattern)
+				if err != nil {
+					log.Fatalf("[-] Invalid --prod-pattern: %s", err)
+				}
+				m.ProdPattern = pattern
+			}
+			if SecretsDynamoSecrets {
+				m.DynamoDBClient = env.Clients.DynamoDB
+				m.DynamoTables = SecretsDynamoTables
+				if SecretsDynamoRegex != "" {
+					pattern, err := regexp.Compile(SecretsDynamoRegex)
+					if err != nil {
+						log.Fatalf("[-] Invalid --dynamo-regex: %s", err)
+					}
+					m.DynamoRegex = pattern
+				}
+			}
+			return m
+		},
+		func(m *aws.SecretsModule, outputDirectory string, verbosity int) awsModuleStats {
+			if SecretsOutputPath != "" {
+				outputDirectory = utils.ExpandOutputPathTemplate(SecretsOutputPath, utils.OutputPathValues{
+					Account: awssdk.ToString(m.Caller.Account),
+					Profile: m.AWSProfile,
+					Regions: m.AWSRegions,
+				})
+			}
+			m.PrintSecrets(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Secrets), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("ram", awsSectionPrivesc,
+		func(env *awsModuleEnv) *aws.RAMModule {
+			return &aws.RAMModule{
+				RAMClient:           env.Clients.RAM,
+				OrganizationsClient: env.Clients.Organizations,
+				Caller:              env.Caller,
+				AWSProfile:          env.Profile,
+				Goroutines:          Goroutines,
+				AWSRegions:          env.Regions(),
+				WrapTable:           AWSWrapTable,
+				AWSOutputType:       AWSOutputType,
+				AWSTableCols:        AWSTableCols,
+			}
+		},
+		func(m *aws.RAMModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintRAM(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Resources), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("stacksets", awsSectionPrivesc,
+		func(env *awsModuleEnv) *aws.StackSetsModule {
+			return &aws.StackSetsModule{
+				CloudFormationClient: env.Clients.CloudFormation,
+				Caller:               env.Caller,
+				AWSRegions:           env.Regions(),
+				AWSProfile:           env.Profile,
+				Goroutines:           Goroutines,
+				WrapTable:            AWSWrapTable,
+				AWSOutputType:        AWSOutputType,
+				AWSTableCols:         AWSTableCols,
+			}
+		},
+		func(m *aws.StackSetsModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintStackSets(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.StackSets), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("network-ports", awsSectionPrivesc,
+		func(env *awsModuleEnv) *aws.NetworkPortsModule {
+			return &aws.NetworkPortsModule{
+				EC2Client:         env.Clients.EC2,
+				ECSClient:         env.Clients.ECS,
+				EFSClient:         env.Clients.EFS,
+				ElastiCacheClient: env.Clients.Elasticache,
+				ELBv2Client:       env.Clients.ELBv2,
+				LightsailClient:   env.Clients.Lightsail,
+				RDSClient:         env.Clients.RDS,
+				Caller:            env.Caller,
+				AWSRegions:        env.Regions(),
+				AWSProfile:        env.Profile,
+				Goroutines:        Goroutines,
+				WrapTable:         AWSWrapTable,
+				AWSOutputType:     AWSOutputType,
+				AWSTableCols:      AWSTableCols,
+			}
+		},
+		func(m *aws.NetworkPortsModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.Verbosity = verbosity
+			m.PrintNetworkPorts(outputDirectory)
+			return awsModuleStats{Rows: len(m.IPv4_Private) + len(m.IPv4_Public) + len(m.IPv6), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("sqs", awsSectionPrivesc,
+		func(env *awsModuleEnv) *aws.SQSModule {
+			return &aws.SQSModule{
+				SQSClient: env.Clients.SQS,
+
+				StorePolicies: StoreSQSAccessPolicies,
+
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.SQSModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintSQS(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Queues), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("sns", awsSectionPrivesc,
+		func(env *awsModuleEnv) *aws.SNSModule {
+			return &aws.SNSModule{
+				SNSClient:  env.Clients.SNS,
+				Caller:     env.Caller,
+				AWSProfile: env.Profile,
+				AWSRegions: env.Regions(),
+				Goroutines: Goroutines,
+				WrapTable:  AWSWrapTable,
+			}
+		},
+		func(m *aws.SNSModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintSNS(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Topics), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("resource-trusts", awsSectionPrivesc,
+		func(env *awsModuleEnv) *aws.ResourceTrustsModule {
+			return &aws.ResourceTrustsModule{
+				Caller:             env.Caller,
+				AWSProfileProvided: env.Profile,
+				Goroutines:         Goroutines,
+				AWSRegions:         env.Regions(),
+				WrapTable:          AWSWrapTable,
+				CloudFoxVersion:    env.Version,
+				AWSOutputType:      AWSOutputType,
+				AWSTableCols:       AWSTableCols,
+				AWSMFAToken:        AWSMFAToken,
+				AWSConfig:          env.Config,
+			}
+		},
+		func(m *aws.ResourceTrustsModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintResources(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Resources2), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("codebuild", awsSectionPrivesc,
+		func(env *awsModuleEnv) *aws.CodeBuildModule {
+			return &aws.CodeBuildModule{
+				CodeBuildClient:     env.Clients.CodeBuild,
+				Caller:              env.Caller,
+				AWSRegions:          env.Regions(),
+				AWSProfile:          env.Profile,
+				Goroutines:          Goroutines,
+				SkipAdminCheck:      AWSSkipAdminCheck,
+				WrapTable:           AWSWrapTable,
+				AWSOutputType:       AWSOutputType,
+				AWSTableCols:        AWSTableCols,
+				PmapperDataBasePath: PmapperDataBasePath,
+			}
+		},
+		func(m *aws.CodeBuildModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintCodeBuildProjects(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Projects), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("batch-scheduling", awsSectionPrivesc,
+		func(env *awsModuleEnv) *aws.BatchSchedulingModule {
+			return &aws.BatchSchedulingModule{
+				BatchClient:   env.Clients.Batch,
+				IAMClient:     env.Clients.IAM,
+				Caller:        env.Caller,
+				AWSProfile:    env.Profile,
+				AWSRegions:    env.Regions(),
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.BatchSchedulingModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintBatchScheduling(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Entries), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("imds", awsSectionPrivesc,
+		func(env *awsModuleEnv) *aws.ImdsModule {
+			return &aws.ImdsModule{
+				EC2Client: env.Clients.EC2,
+				IAMClient: env.Clients.IAM,
+
+				Caller:              env.Caller,
+				AWSRegions:          env.Regions(),
+				AWSProfile:          env.Profile,
+				Goroutines:          Goroutines,
+				SkipAdminCheck:      AWSSkipAdminCheck,
+				WrapTable:           AWSWrapTable,
+				AWSOutputType:       AWSOutputType,
+				AWSTableCols:        AWSTableCols,
+				PmapperDataBasePath: PmapperDataBasePath,
+			}
+		},
+		func(m *aws.ImdsModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintImds(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.ImdsInstances), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("resource-policies", awsSectionPrivesc,
+		func(env *awsModuleEnv) *aws.ResourcePolicyModule {
+			return &aws.ResourcePolicyModule{
+				S3Client:             env.Clients.S3,
+				SQSClient:            env.Clients.SQS,
+				SNSClient:            env.Clients.SNS,
+				KMSClient:            env.Clients.KMS,
+				LambdaClient:         env.Clients.Lambda,
+				SecretsManagerClient: env.Clients.SecretsManager,
+				ECRClient:            env.Clients.ECR,
+				EFSClient:            env.Clients.EFS,
+				Caller:               env.Caller,
+				AWSRegions:           env.Regions(),
+				AWSProfile:           env.Profile,
+				Goroutines:           Goroutines,
+				WrapTable:            AWSWrapTable,
+				AWSOutputType:        AWSOutputType,
+				AWSTableCols:         AWSTableCols,
+			}
+		},
+		func(m *aws.ResourcePolicyModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintResourcePolicies(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.ResourcePolicyFindings), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("verified-permissions-templates", awsSectionPrivesc,
+		func(env *awsModuleEnv) *aws.VerifiedPermissionsTemplatesModule {
+			return &aws.VerifiedPermissionsTemplatesModule{
+				VerifiedPermissionsClient: env.Clients.VerifiedPermissions,
+
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.VerifiedPermissionsTemplatesModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintVerifiedPermissionsTemplates(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Templates), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("principals", awsSectionIAM,
+		func(env *awsModuleEnv) *aws.IamPrincipalsModule {
+			return &aws.IamPrincipalsModule{
+				IAMClient:           env.Clients.IAM,
+				Caller:              env.Caller,
+				AWSProfile:          env.Profile,
+				Goroutines:          Goroutines,
+				SkipAdminCheck:      AWSSkipAdminCheck,
+				WrapTable:           AWSWrapTable,
+				AWSOutputType:       AWSOutputType,
+				AWSTableCols:        AWSTableCols,
+				PmapperDataBasePath: PmapperDataBasePath,
+			}
+		},
+		func(m *aws.IamPrincipalsModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintIamPrincipals(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Users) + len(m.Roles), Errors: m.CommandCounter.Error, Counts: map[string]int{
+				aws.IAMCategoryUsers: len(m.Users),
+				aws.IAMCategoryRoles: len(m.Roles),
+			}}
+		},
+	)
+
+	registerAWSModule("permissions", awsSectionIAM,
+		func(env *awsModuleEnv) *aws.IamPermissionsModule {
+			return &aws.IamPermissionsModule{
+				IAMClient:     env.Clients.IAM,
+				Caller:        env.Caller,
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSTableCols:  AWSTableCols,
+				AWSOutputType: AWSOutputType,
+			}
+		},
+		func(m *aws.IamPermissionsModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintIamPermissions(outputDirectory, verbosity, PermissionsPrincipal)
+			return awsModuleStats{Rows: len(m.Rows), Errors: m.CommandCounter.Error, Counts: map[string]int{aws.IAMCategoryPolicies: len(m.Policies)}}
+		},
+	)
+
+	registerAWSModule("access-keys", awsSectionIAM,
+		func(env *awsModuleEnv) *aws.AccessKeysModule {
+			return &aws.AccessKeysModule{
+				IAMClient:     env.Clients.IAM,
+				Caller:        env.Caller,
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.AccessKeysModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintAccessKeys(AccessKeysFilter, outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.AnalyzedUsers), Errors: m.CommandCounter.Error, Counts: map[string]int{aws.IAMCategoryAccessKeys: len(m.AnalyzedUsers)}}
+		},
+	)
+
+	registerAWSModule("groups", awsSectionIAM,
+		func(env *awsModuleEnv) *aws.GroupsModule {
+			return &aws.GroupsModule{
+				IAMClient:     env.Clients.IAM,
+				Caller:        env.Caller,
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.GroupsModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintGroups(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Groups), Errors: m.CommandCounter.Error, Counts: map[string]int{aws.IAMCategoryGroups: len(m.Groups)}}
+		},
+	)
+
+	registerAWSModule("iam-account", awsSectionIAM,
+		func(env *awsModuleEnv) *aws.IAMAccountModule {
+			return &aws.IAMAccountModule{
+				IAMClient:        env.Clients.IAM,
+				IAMAccountClient: env.Clients.IAM,
+				Caller:           env.Caller,
+				AWSProfile:       env.Profile,
+				Goroutines:       Goroutines,
+				WrapTable:        AWSWrapTable,
+				AWSOutputType:    AWSOutputType,
+				AWSTableCols:     AWSTableCols,
+			}
+		},
+		func(m *aws.IAMAccountModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintIAMAccount(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Items), Errors: m.CommandCounter.Error, Counts: m.Counts()}
+		},
+	)
+
+	registerAWSModuleWithOutput("role-trusts", awsSectionIAM, filepath.Join("table", "role-trusts-principals.txt"),
+		func(env *awsModuleEnv) *aws.RoleTrustsModule {
+			return &aws.RoleTrustsModule{
+				IAMClient:           env.Clients.IAM,
+				Caller:              env.Caller,
+				AWSProfile:          env.Profile,
+				Goroutines:          Goroutines,
+				SkipAdminCheck:      AWSSkipAdminCheck,
+				WrapTable:           AWSWrapTable,
+				AWSOutputType:       AWSOutputType,
+				AWSTableCols:        AWSTableCols,
+				PmapperDataBasePath: PmapperDataBasePath,
+			}
+		},
+		func(m *aws.RoleTrustsModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintRoleTrusts(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.AnalyzedRoles), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("pmapper", awsSectionIAM,
+		func(env *awsModuleEnv) *aws.PmapperModule {
+			return &aws.PmapperModule{
+				Caller:              env.Caller,
+				AWSProfile:          env.Profile,
+				Goroutines:          Goroutines,
+				WrapTable:           AWSWrapTable,
+				AWSOutputType:       AWSOutputType,
+				AWSTableCols:        AWSTableCols,
+				PmapperDataBasePath: PmapperDataBasePath,
+			}
+		},
+		func(m *aws.PmapperModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintPmapperData(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Nodes), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("iam-simulator", awsSectionIAM,
+		func(env *awsModuleEnv) *aws.IamSimulatorModule {
+			return &aws.IamSimulatorModule{
+				IAMClient:                  env.Clients.IAM,
+				Caller:                     env.Caller,
+				AWSProfileProvided:         env.Profile,
+				Goroutines:                 Goroutines,
+				WrapTable:                  AWSWrapTable,
+				AWSOutputType:              AWSOutputType,
+				AWSTableCols:               AWSTableCols,
+				IamSimulatorAdminCheckOnly: IamSimulatorAdminCheckOnly,
+			}
+		},
+		func(m *aws.IamSimulatorModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintIamSimulator(SimulatorPrincipal, SimulatorAction, SimulatorResource, outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.SimulatorResults), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("workloads", awsSectionIAM,
+		func(env *awsModuleEnv) *aws.WorkloadsModule {
+			return &aws.WorkloadsModule{
+				ECSClient:              env.Clients.ECS,
+				EC2Client:              env.Clients.EC2,
+				LambdaClient:           env.Clients.Lambda,
+				AppRunnerClient:        env.Clients.AppRunner,
+				ElasticBeanstalkClient: env.Clients.ElasticBeanstalk,
+				LightsailClient:        env.Clients.Lightsail,
+				IAMClient:              env.Clients.IAM,
+				Caller:                 env.Caller,
+				AWSRegions:             env.Regions(),
+				SkipAdminCheck:         AWSSkipAdminCheck,
+				AWSProfile:             env.Profile,
+				Goroutines:             Goroutines,
+				WrapTable:              AWSWrapTable,
+				AWSOutputType:          AWSOutputType,
+				AWSTableCols:           AWSTableCols,
+				PmapperDataBasePath:    PmapperDataBasePath,
+			}
+		},
+		func(m *aws.WorkloadsModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintWorkloads(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Workloads), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("role-chaining", awsSectionIAM,
+		func(env *awsModuleEnv) *aws.RoleChainingModule {
+			return &aws.RoleChainingModule{
+				IAMClient:     env.Clients.IAM,
+				Caller:        env.Caller,
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.RoleChainingModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintRoleChaining(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.RoleChains), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("inline-policies", awsSectionIAM,
+		func(env *awsModuleEnv) *aws.InlinePolicyModule {
+			return &aws.InlinePolicyModule{
+				IAMClient:     env.Clients.IAM,
+				Caller:        env.Caller,
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.InlinePolicyModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintInlinePolicies(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.InlinePolicies), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerAWSModule("recent-iam-changes", awsSectionIAM,
+		func(env *awsModuleEnv) *aws.RecentIAMChangesModule {
+			return &aws.RecentIAMChangesModule{
+				CloudTrailClient:   env.Clients.CloudTrail,
+				Caller:             env.Caller,
+				AWSProfile:         env.Profile,
+				WrapTable:          AWSWrapTable,
+				AWSOutputType:      AWSOutputType,
+				AWSTableCols:       AWSTableCols,
+				Days:               RecentIAMChangesDays,
+				ExpectedPrincipals: RecentIAMChangesExpectedPrincipals,
+				CorporateCIDRs:     RecentIAMChangesCorporateCIDRs,
+			}
+		},
+		func(m *aws.RecentIAMChangesModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintRecentIAMChanges(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Changes), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerStandaloneAWSModule("outbound-assumed-roles",
+		func(env *awsModuleEnv) *aws.OutboundAssumedRolesModule {
+			return &aws.OutboundAssumedRolesModule{
+				CloudTrailClient: env.Clients.CloudTrail,
+
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.OutboundAssumedRolesModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintOutboundRoleTrusts(OutboundAssumedRolesDays, outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.OutboundAssumeRoleEntries), Errors: m.CommandCounter.Error}
+		},
+	)
+
+	registerStandaloneAWSModule("directory-services",
+		func(env *awsModuleEnv) *aws.DirectoryModule {
+			return &aws.DirectoryModule{
+				DSClient:      env.Clients.DirectoryService,
+				Caller:        env.Caller,
+				AWSRegions:    env.Regions(),
+				AWSProfile:    env.Profile,
+				Goroutines:    Goroutines,
+				WrapTable:     AWSWrapTable,
+				AWSOutputType: AWSOutputType,
+				AWSTableCols:  AWSTableCols,
+			}
+		},
+		func(m *aws.DirectoryModule, outputDirectory string, verbosity int) awsModuleStats {
+			m.PrintDirectories(outputDirectory, verbosity)
+			return awsModuleStats{Rows: len(m.Directories), Errors: m.CommandCounter.Error}
+		},
+	)
+}
@@ -0,0 +1,28 @@
+package cli
+
+import "testing"
+
+// notAWSModules are the aws subcommands that run other modules or no module at all
+var notAWSModules = map[string]bool{
+	"all-checks": true,
+	"cape":       true,
+	"iam":        true,
+}
+
+func TestAWSSubcommandsAreRegistered(t *testing.T) {
+	for _, cmd := range AWSCommands.Commands() {
+		if notAWSModules[cmd.Name()] {
+			continue
+		}
+		registered := false
+		for _, name := range append([]string{cmd.Name()}, cmd.Aliases...) {
+			if _, ok := findAWSModule(name); ok {
+				registered = true
+				break
+			}
+		}
+		if !registered {
+			t.Errorf("aws %s is not in awsModuleRegistry or awsStandaloneModules, register it so all-checks and the run manifest know about it", cmd.Name())
+		}
+	}
+}
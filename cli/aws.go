@@ -6,59 +6,17 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/BishopFox/cloudfox/aws"
 	"github.com/BishopFox/cloudfox/aws/sdk"
 	"github.com/BishopFox/cloudfox/internal"
 	"github.com/BishopFox/cloudfox/internal/common"
-	"github.com/aws/aws-sdk-go-v2/service/apigateway"
-	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
-	"github.com/aws/aws-sdk-go-v2/service/apprunner"
-	"github.com/aws/aws-sdk-go-v2/service/athena"
-	"github.com/aws/aws-sdk-go-v2/service/cloud9"
-	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
-	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
-	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
-	"github.com/aws/aws-sdk-go-v2/service/codeartifact"
-	"github.com/aws/aws-sdk-go-v2/service/codebuild"
-	"github.com/aws/aws-sdk-go-v2/service/codecommit"
-	"github.com/aws/aws-sdk-go-v2/service/codedeploy"
-	"github.com/aws/aws-sdk-go-v2/service/datapipeline"
-	"github.com/aws/aws-sdk-go-v2/service/directoryservice"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
-	"github.com/aws/aws-sdk-go-v2/service/ec2"
-	"github.com/aws/aws-sdk-go-v2/service/ecr"
-	"github.com/aws/aws-sdk-go-v2/service/ecs"
-	"github.com/aws/aws-sdk-go-v2/service/efs"
-	"github.com/aws/aws-sdk-go-v2/service/eks"
-	"github.com/aws/aws-sdk-go-v2/service/elasticache"
-	"github.com/aws/aws-sdk-go-v2/service/elasticbeanstalk"
-	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
-	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
-	"github.com/aws/aws-sdk-go-v2/service/emr"
-	"github.com/aws/aws-sdk-go-v2/service/fsx"
-	"github.com/aws/aws-sdk-go-v2/service/glue"
-	"github.com/aws/aws-sdk-go-v2/service/grafana"
-	"github.com/aws/aws-sdk-go-v2/service/iam"
-	"github.com/aws/aws-sdk-go-v2/service/kinesis"
-	"github.com/aws/aws-sdk-go-v2/service/lambda"
-	"github.com/aws/aws-sdk-go-v2/service/lightsail"
-	"github.com/aws/aws-sdk-go-v2/service/mq"
-	"github.com/aws/aws-sdk-go-v2/service/opensearch"
-	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/BishopFox/cloudfox/internal/utils"
 	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
-	"github.com/aws/aws-sdk-go-v2/service/ram"
-	"github.com/aws/aws-sdk-go-v2/service/rds"
-	"github.com/aws/aws-sdk-go-v2/service/redshift"
-	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
-	"github.com/aws/aws-sdk-go-v2/service/route53"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
-	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
-	"github.com/aws/aws-sdk-go-v2/service/sfn"
-	"github.com/aws/aws-sdk-go-v2/service/sns"
-	"github.com/aws/aws-sdk-go-v2/service/sqs"
-	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/aws/smithy-go/ptr"
 	"github.com/bishopfox/knownawsaccountslookup"
 	"github.com/dominikbraun/graph"
@@ -73,6 +31,8 @@ var (
 	red              = color.New(color.FgRed).SprintFunc()
 	magenta          = color.New(color.FgMagenta).SprintFunc()
 	defaultOutputDir = ptr.ToString(internal.GetLogDirPath())
+	// verbosityUsage is the --verbosity help of every cloud, see internal.VerbositySummary for the levels
+	verbosityUsage = "0 = Print summary lines only\n1 = Also print tables\n2 = Also print error details\n3 = Also print loot file contents\n"
 
 	AWSProfile          string
 	AWSProfilesList     string
@@ -88,6 +48,13 @@ var (
 	AWSWrapTable       bool
 	AWSUseCache        bool
 	AWSMFAToken        string
+	AWSMaxAPICalls     int
+	AWSRequestsPerSec  float64
+	AWSLootTemplateDir string
+	AWSCombinedCSV     bool
+	AWSResume          bool
+	AWSCheckpointAge   time.Duration
+	AWSExcludeRegions  string
 
 	Goroutines int
 	Verbosity  int
@@ -114,6 +81,30 @@ var (
 		PostRun: awsPostRun,
 	}
 
+	ACMCertExpiryDays int
+	ACMCommand        = &cobra.Command{
+		Use:     "acm",
+		Aliases: []string{"certificates", "certs"},
+		Short:   "Enumerate ACM certificates and flag the ones that are expired or about to expire",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws acm --profile readonly_profile\n" +
+			os.Args[0] + " aws acm --cert-expiry-days 60 --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runACMCommand,
+		PostRun: awsPostRun,
+	}
+
+	AMPRulesCommand = &cobra.Command{
+		Use:     "amp-rules",
+		Aliases: []string{"prometheus-rules", "amp"},
+		Short:   "Enumerate Managed Prometheus alerting rules and alert managers, and flag missing security alerting as a detection gap",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws amp-rules --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runAMPRulesCommand,
+		PostRun: awsPostRun,
+	}
+
 	ApiGwCommand = &cobra.Command{
 		Use:     "api-gw",
 		Aliases: []string{"gw", "gateways", "api-gws"},
@@ -125,6 +116,39 @@ var (
 		PostRun: awsPostRun,
 	}
 
+	AuroraGlobalCommand = &cobra.Command{
+		Use:     "aurora-global",
+		Aliases: []string{"aurora-global-databases", "global-databases"},
+		Short:   "Enumerate Aurora global databases, their secondary regions and replication lag, cross-account secondaries and the roles that can promote a secondary",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws aurora-global --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runAuroraGlobalCommand,
+		PostRun: awsPostRun,
+	}
+
+	BackupCommand = &cobra.Command{
+		Use:     "backup",
+		Aliases: []string{"backups"},
+		Short:   "Enumerate AWS Backup plans and the EC2, RDS, EFS and DynamoDB resources that no backup plan covers",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws backup --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runBackupCommand,
+		PostRun: awsPostRun,
+	}
+
+	BatchSchedulingCommand = &cobra.Command{
+		Use:     "batch-scheduling",
+		Aliases: []string{"batch"},
+		Short:   "Enumerate Batch scheduling policies and job queues, and flag compute environment service roles with more permissions than Batch needs",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws batch-scheduling --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runBatchSchedulingCommand,
+		PostRun: awsPostRun,
+	}
+
 	CheckBucketPolicies bool
 	BucketsCommand      = &cobra.Command{
 		Use:     "buckets",
@@ -164,6 +188,20 @@ var (
 		PostRun: awsPostRun,
 	}
 
+	CloudTrailGapsDays              int
+	CloudTrailGapsSecurityToolRoles []string
+	CloudTrailGapsCommand           = &cobra.Command{
+		Use:     "cloudtrail-gaps",
+		Aliases: []string{"cloudtrailgaps", "trail-gaps"},
+		Short:   "Find trails that stopped logging or fail to deliver logs, and who called StopLogging",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws cloudtrail-gaps --profile readonly_profile\n" +
+			os.Args[0] + " aws cloudtrail-gaps --profile readonly_profile --days 14 --security-tool-roles security-automation,arn:aws:iam::123456789012:role/remediation",
+		PreRun:  awsPreRun,
+		Run:     runCloudTrailGapsCommand,
+		PostRun: awsPostRun,
+	}
+
 	CodeBuildCommand = &cobra.Command{
 		Use:   "codebuild",
 		Short: "Enumerate CodeBuild projects.",
@@ -174,6 +212,17 @@ var (
 		PostRun: awsPostRun,
 	}
 
+	DataPipelineCommand = &cobra.Command{
+		Use:     "data-pipeline",
+		Aliases: []string{"datapipeline"},
+		Short:   "Enumerate Data Pipeline database and S3 objects, including plaintext credentials in pipeline definitions",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws data-pipeline --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runDataPipelineCommand,
+		PostRun: awsPostRun,
+	}
+
 	DatabasesCommand = &cobra.Command{
 		Use:     "databases",
 		Aliases: []string{"db", "rds", "redshift", "dbs"},
@@ -185,6 +234,17 @@ var (
 		PostRun: awsPostRun,
 	}
 
+	DefensesCommand = &cobra.Command{
+		Use:     "defenses",
+		Aliases: []string{"defences", "detections"},
+		Short:   "Check which regions have GuardDuty, CloudTrail, Config, Security Hub and IAM Access Analyzer enabled",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws defenses --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runDefensesCommand,
+		PostRun: awsPostRun,
+	}
+
 	ECRCommand = &cobra.Command{
 		Use:     "ecr",
 		Aliases: []string{"repos", "repo", "repositories"},
@@ -264,6 +324,58 @@ var (
 		PostRun: awsPostRun,
 	}
 
+	EFSCommand = &cobra.Command{
+		Use:   "efs",
+		Short: "Check EFS file systems for mount targets open to the internet, cross-account mounts, encryption and lifecycle, and list the Lambda functions that mount them",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws efs --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runEFSCommand,
+		PostRun: awsPostRun,
+	}
+
+	GroupsCommand = &cobra.Command{
+		Use:     "groups",
+		Aliases: []string{"group", "iam-groups"},
+		Short:   "Enumerate IAM groups, their members and attached policies, and flag powerful groups with several members",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws groups --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runGroupsCommand,
+		PostRun: awsPostRun,
+	}
+
+	IAMCommand = &cobra.Command{
+		Use:   "iam",
+		Short: "Run all IAM modules in one go: principals, groups, permissions, access-keys and iam-account. Writes iam-summary with a count per category",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws iam --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runIAMCommand,
+		PostRun: awsPostRun,
+	}
+
+	IAMAccountCommand = &cobra.Command{
+		Use:   "iam-account",
+		Short: "Review the password policy, permission boundaries, service-linked roles and OIDC providers of the account",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws iam-account --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runIAMAccountCommand,
+		PostRun: awsPostRun,
+	}
+
+	InlinePoliciesCommand = &cobra.Command{
+		Use:     "inline-policies",
+		Aliases: []string{"inline", "inline-policy"},
+		Short:   "Review the inline policies of IAM users, roles and groups and flag the ones that allow * or iam:*",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws inline-policies --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runInlinePoliciesCommand,
+		PostRun: awsPostRun,
+	}
+
 	SimulatorResource          string
 	SimulatorAction            string
 	SimulatorPrincipal         string
@@ -279,6 +391,16 @@ var (
 		PostRun: awsPostRun,
 	}
 
+	ImdsCommand = &cobra.Command{
+		Use:   "imds",
+		Short: "Enumerate EC2 instance metadata service settings and flag instances that still allow IMDSv1",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws imds --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runImdsCommand,
+		PostRun: awsPostRun,
+	}
+
 	// This filter could be an instance ID or a TXT file with instance IDs separated by a new line.
 	InstancesFilter                   string
 	InstanceMapUserDataAttributesOnly bool
@@ -293,10 +415,30 @@ var (
 		PostRun: awsPostRun,
 	}
 
+	LaunchTemplateSecretsCommand = &cobra.Command{
+		Use:     "launch-template-secrets",
+		Aliases: []string{"launch-templates"},
+		Short:   "Search the user data, tags and parameters of every EC2 launch template version for secrets",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws launch-template-secrets --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runLaunchTemplateSecretsCommand,
+		PostRun: awsPostRun,
+	}
+
+	ECSCommand = &cobra.Command{
+		Use:   "ecs",
+		Short: "Enumerate ECS clusters and services, their task roles and tasks with ECS Exec enabled",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws ecs --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runECSCommand,
+		PostRun: awsPostRun,
+	}
+
 	ECSTasksCommand = &cobra.Command{
-		Use:     "ecs-tasks",
-		Aliases: []string{"ecs"},
-		Short:   "Enumerate all ECS tasks along with assigned IPs and profiles",
+		Use:   "ecs-tasks",
+		Short: "Enumerate all ECS tasks along with assigned IPs and profiles",
 		Long: "\nUse case examples:\n" +
 			os.Args[0] + " aws ecs-tasks --profile readonly_profile",
 		PreRun:  awsPreRun,
@@ -315,6 +457,28 @@ var (
 		PostRun: awsPostRun,
 	}
 
+	EventBridgeCommand = &cobra.Command{
+		Use:     "eventbridge",
+		Aliases: []string{"events", "event-buses"},
+		Short:   "Enumerate EventBridge rules and their targets, and flag event buses that accept events from other accounts",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws eventbridge --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runEventBridgeCommand,
+		PostRun: awsPostRun,
+	}
+
+	EndpointServicesCommand = &cobra.Command{
+		Use:     "endpoint-services",
+		Aliases: []string{"privatelink"},
+		Short:   "Enumerate PrivateLink endpoint services, who is allowed to connect to them and which accounts are connected",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws endpoint-services --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runEndpointServicesCommand,
+		PostRun: awsPostRun,
+	}
+
 	InventoryCommand = &cobra.Command{
 		Use:   "inventory",
 		Short: "Gain a rough understanding of size of the account and preferred regions",
@@ -325,6 +489,17 @@ var (
 		PostRun: awsPostRun,
 	}
 
+	IoTCommand = &cobra.Command{
+		Use:     "iot",
+		Aliases: []string{"iot-core"},
+		Short:   "Enumerate IoT Core things and policies, flag policies that allow iot:* or any resource, and write mosquitto commands for the MQTT endpoints",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws iot --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runIoTCommand,
+		PostRun: awsPostRun,
+	}
+
 	LambdasCommand = &cobra.Command{
 		Use:     "lambda",
 		Aliases: []string{"lambdas", "functions"},
@@ -336,6 +511,143 @@ var (
 		PostRun: awsPostRun,
 	}
 
+	LightsailCommand = &cobra.Command{
+		Use:     "lightsail",
+		Aliases: []string{},
+		Short:   "Enumerate Lightsail instances and databases, their open ports and master usernames, and flag SSH or RDP open to the internet",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws lightsail --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runLightsailCommand,
+		PostRun: awsPostRun,
+	}
+
+	LegacyServicesCommand = &cobra.Command{
+		Use:     "legacy-services",
+		Aliases: []string{"legacy"},
+		Short:   "Find leftovers of deprecated services: Sumerian buckets, GameSparks roles, empty WorkMail organizations and old Elasticsearch domains",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws legacy-services --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runLegacyServicesCommand,
+		PostRun: awsPostRun,
+	}
+
+	LogGroupsSearchLogs  bool
+	LogGroupsSearchTerms []string
+	LogGroupsMinSize     int64
+	LogGroupsCommand     = &cobra.Command{
+		Use:     "log-groups",
+		Aliases: []string{"loggroups", "cloudwatch-log-groups", "cloudwatch-logs"},
+		Short:   "Enumerate CloudWatch log groups and flag the ones that keep recent events forever",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws log-groups --profile readonly_profile\n" +
+			os.Args[0] + " aws log-groups --search-logs --search-terms password,secret,token,apikey --profile readonly_profile\n" +
+			os.Args[0] + " aws log-groups --min-size 1048576 --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runLogGroupsCommand,
+		PostRun: awsPostRun,
+	}
+
+	ALBBypassCommand = &cobra.Command{
+		Use:     "alb-bypass",
+		Aliases: []string{"alb-rules", "listener-rules"},
+		Short:   "Find ALB listener rules that forward requests without authentication before an authenticated rule gets them",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws alb-bypass --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runALBBypassCommand,
+		PostRun: awsPostRun,
+	}
+
+	CloudSearchCommand = &cobra.Command{
+		Use:     "cloudsearch",
+		Aliases: []string{"cloudsearch-domains"},
+		Short:   "Enumerate CloudSearch domains, flag anonymous search or document uploads and document endpoints that accept HTTP",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws cloudsearch --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runCloudSearchCommand,
+		PostRun: awsPostRun,
+	}
+
+	GlueDataCatalogCommand = &cobra.Command{
+		Use:     "glue-catalog",
+		Aliases: []string{"data-catalog", "glue-tables"},
+		Short:   "Enumerate Glue Data Catalog tables with their S3 locations, columns and classification, and flag the ones in public buckets",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws glue-catalog --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runGlueDataCatalogCommand,
+		PostRun: awsPostRun,
+	}
+
+	RekognitionStreamingCommand = &cobra.Command{
+		Use:     "rekognition-streaming",
+		Aliases: []string{"stream-processors", "rekognition"},
+		Short:   "Enumerate Rekognition Video stream processors, flag face search, public output buckets and roles that can read every video stream",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws rekognition-streaming --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runRekognitionStreamingCommand,
+		PostRun: awsPostRun,
+	}
+
+	TransferCommand = &cobra.Command{
+		Use:     "transfer",
+		Aliases: []string{"sftp", "transfer-family"},
+		Short:   "Enumerate Transfer Family servers, flag the ones reachable from the internet and get sftp commands for their users",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws transfer --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runTransferCommand,
+		PostRun: awsPostRun,
+	}
+
+	MediaStoreCommand = &cobra.Command{
+		Use:     "mediastore",
+		Aliases: []string{"media-store"},
+		Short:   "Enumerate MediaStore containers and flag the ones whose container policy lets anyone read them",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws mediastore --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runMediaStoreCommand,
+		PostRun: awsPostRun,
+	}
+
+	MQCommand = &cobra.Command{
+		Use:     "mq",
+		Aliases: []string{"amazonmq", "brokers"},
+		Short:   "Enumerate Amazon MQ brokers, their web consoles, and whether they are public",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws mq --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runMQCommand,
+		PostRun: awsPostRun,
+	}
+
+	MSKCommand = &cobra.Command{
+		Use:     "msk",
+		Aliases: []string{"kafka"},
+		Short:   "Enumerate MSK clusters, their brokers and client authentication, and flag publicly accessible clusters",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws msk --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runMSKCommand,
+		PostRun: awsPostRun,
+	}
+
+	MSKReplicatorCommand = &cobra.Command{
+		Use:     "msk-replicator",
+		Aliases: []string{"msk-replicators", "kafka-replicators"},
+		Short:   "Enumerate MSK replicators and flag replication to clusters in other accounts or outside the organization",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws msk-replicator --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runMSKReplicatorCommand,
+		PostRun: awsPostRun,
+	}
+
 	NetworkPortsCommand = &cobra.Command{
 		Use:     "network-ports",
 		Aliases: []string{"ports", "networkports"},
@@ -347,6 +659,17 @@ var (
 		PostRun: awsPostRun,
 	}
 
+	OpenSearchServerlessCommand = &cobra.Command{
+		Use:     "opensearch-serverless",
+		Aliases: []string{"aoss"},
+		Short:   "Enumerate OpenSearch Serverless collections and flag the ones that are public or open to any principal",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws opensearch-serverless --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runOpenSearchServerlessCommand,
+		PostRun: awsPostRun,
+	}
+
 	OutboundAssumedRolesDays    int
 	OutboundAssumedRolesCommand = &cobra.Command{
 		Use:     "outbound-assumed-roles",
@@ -359,6 +682,17 @@ var (
 		PostRun: awsPostRun,
 	}
 
+	OutpostsRoutingCommand = &cobra.Command{
+		Use:     "outposts-routing",
+		Aliases: []string{"outposts", "lgw"},
+		Short:   "Enumerate Outposts local gateway route tables and the routes between the on-premises network and VPCs",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws outposts-routing --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runOutpostsRoutingCommand,
+		PostRun: awsPostRun,
+	}
+
 	OrgsCommand = &cobra.Command{
 		Use:     "orgs",
 		Aliases: []string{"org", "organizations", "accounts", "account"},
@@ -397,6 +731,17 @@ var (
 		PostRun: awsPostRun,
 	}
 
+	StackSetsCommand = &cobra.Command{
+		Use:     "stacksets",
+		Aliases: []string{"stackset", "stack-sets"},
+		Short:   "Enumerate CloudFormation stack sets, the accounts and regions they deploy to, and flag the ones that deploy IAM roles",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws stacksets --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runStackSetsCommand,
+		PostRun: awsPostRun,
+	}
+
 	RAMCommand = &cobra.Command{
 		Use:   "ram",
 		Short: "Enumerate cross-account shared resources",
@@ -407,6 +752,17 @@ var (
 		PostRun: awsPostRun,
 	}
 
+	ResourcePoliciesCommand = &cobra.Command{
+		Use:     "resource-policies",
+		Aliases: []string{"resourcepolicies", "resource-policy"},
+		Short:   "Find resource policies across services that grant public or cross-account access, sorted by severity",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws resource-policies --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runResourcePoliciesCommand,
+		PostRun: awsPostRun,
+	}
+
 	ResourceTrustsCommand = &cobra.Command{
 		Use:     "resource-trusts",
 		Aliases: []string{"resourcetrusts", "resourcetrust"},
@@ -418,6 +774,17 @@ var (
 		PostRun: awsPostRun,
 	}
 
+	RoleChainingCommand = &cobra.Command{
+		Use:     "role-chaining",
+		Aliases: []string{"role-chains"},
+		Short:   "Find roles in the same account that can be chained through sts:AssumeRole, including circular chains",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws role-chaining --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runRoleChainingCommand,
+		PostRun: awsPostRun,
+	}
+
 	// The filter is set to "all" when the flag "--filter" is not used
 	RoleTrustFilter  string
 	RoleTrustCommand = &cobra.Command{
@@ -443,24 +810,130 @@ var (
 		PostRun: awsPostRun,
 	}
 
-	SecretsCommand = &cobra.Command{
+	RDSProxyCommand = &cobra.Command{
+		Use:     "rds-proxy",
+		Aliases: []string{"rds-proxies", "rdsproxy", "db-proxy"},
+		Short:   "Enumerate RDS proxies, their IAM authentication and TLS settings, and the secrets they use",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws rds-proxy --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runRDSProxyCommand,
+		PostRun: awsPostRun,
+	}
+
+	RecentIAMChangesDays               int
+	RecentIAMChangesExpectedPrincipals []string
+	RecentIAMChangesCorporateCIDRs     []string
+	RecentIAMChangesCommand            = &cobra.Command{
+		Use:     "recent-iam-changes",
+		Aliases: []string{"recentiamchanges", "iam-changes"},
+		Short:   "List recent high-risk IAM changes from CloudTrail and flag unexpected principals and source IPs",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws recent-iam-changes --profile readonly_profile\n" +
+			os.Args[0] + " aws recent-iam-changes --profile readonly_profile --days 30 --expected-principals arn:aws:iam::123456789012:role/deployer --corporate-cidrs 203.0.113.0/24,10.0.0.0/8",
+		PreRun:  awsPreRun,
+		Run:     runRecentIAMChangesCommand,
+		PostRun: awsPostRun,
+	}
+
+	SageMakerCommand = &cobra.Command{
+		Use:     "sagemaker",
+		Aliases: []string{"notebooks", "sagemaker-notebooks"},
+		Short:   "Enumerate SageMaker notebook instances and domains, their execution roles, and notebooks you can open with a presigned URL",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws sagemaker --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runSageMakerCommand,
+		PostRun: awsPostRun,
+	}
+
+	SageMakerPipelinesCommand = &cobra.Command{
+		Use:     "sagemaker-pipelines",
+		Aliases: []string{"ml-pipelines"},
+		Short:   "Enumerate SageMaker pipelines, the roles of their steps and secrets in their definitions, and flag roles that can access buckets the pipeline doesn't use",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws sagemaker-pipelines --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runSageMakerPipelinesCommand,
+		PostRun: awsPostRun,
+	}
+
+	SecretAccessAnomaliesDays    int
+	SecretAccessAnomaliesCommand = &cobra.Command{
+		Use:     "secret-access-anomalies",
+		Aliases: []string{"secretaccessanomalies", "secret-anomalies"},
+		Short:   "Flag unusual GetSecretValue activity by secret and principal in CloudTrail",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws secret-access-anomalies --profile readonly_profile\n" +
+			os.Args[0] + " aws secret-access-anomalies --profile readonly_profile --days 14",
+		PreRun:  awsPreRun,
+		Run:     runSecretAccessAnomaliesCommand,
+		PostRun: awsPostRun,
+	}
+
+	SecretsAnsibleLoot       bool
+	SecretsTerraformLoot     bool
+	SecretsNucleiTemplates   bool
+	SecretsOutputPath        string
+	SecretsNamesFile         string
+	SecretsResolveSSMValues  bool
+	SecretsConfirmShowValues bool
+	SecretsSince             string
+	SecretsAnalyzeAccess     bool
+	SecretsCompare1Password  bool
+	SecretsValidate          bool
+	SecretsDynamoSecrets     bool
+	SecretsDynamoTables      []string
+	SecretsDynamoRegex       string
+	SecretsSummarizePaths    bool
+	SecretsSlackWebhook      string
+	SecretsSlackRealtime     bool
+	SecretsStream            bool
+	SecretsPreferProd        bool
+	SecretsProdPattern       string
+	SecretsDescribeName      string
+	SecretsDescribeRegion    string
+	SecretsCommand           = &cobra.Command{
 		Use:     "secrets",
 		Aliases: []string{"secret"},
 		Short:   "Enumerate secrets from secrets manager and SSM",
 		Long: "\nUse case examples:\n" +
-			os.Args[0] + " aws secrets --profile readonly_profile",
+			os.Args[0] + " aws secrets --profile readonly_profile\n" +
+			os.Args[0] + " aws secrets --profile readonly_profile --output-path /tmp/scan-{account}-{date}\n" +
+			os.Args[0] + " aws secrets --profile readonly_profile --loot-terraform-data\n" +
+			os.Args[0] + " aws secrets --profile readonly_profile --resolve-ssm-values --confirm-show-values\n" +
+			os.Args[0] + " aws secrets --profile readonly_profile --secret-names-file known-secrets.txt\n" +
+			os.Args[0] + " aws secrets --profile readonly_profile --since 2024-01-01\n" +
+			os.Args[0] + " aws secrets --profile readonly_profile --name prod/db/password --region us-east-1\n" +
+			os.Args[0] + " aws secrets --profile readonly_profile --compare-1password",
 		PreRun:  awsPreRun,
 		Run:     runSecretsCommand,
 		PostRun: awsPostRun,
 	}
 
+	SSMAutomationDays    int
+	SSMAutomationCommand = &cobra.Command{
+		Use:     "ssm-automation",
+		Aliases: []string{"ssmautomation", "automation"},
+		Short:   "Enumerate recent SSM automation executions and flag high-risk documents and unexpected executors",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws ssm-automation --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runSSMAutomationCommand,
+		PostRun: awsPostRun,
+	}
+
 	MaxResourcesPerRegion int
+	TagsTagKey            string
+	TagsTagValues         []string
+	TagsIncludeUntagged   bool
 	TagsCommand           = &cobra.Command{
 		Use:     "tags",
 		Aliases: []string{"tag"},
 		Short:   "Enumerate resources with tags.",
 		Long: "\nUse case examples:\n" +
-			os.Args[0] + " aws tags --profile readonly_profile",
+			os.Args[0] + " aws tags --profile readonly_profile\n" +
+			os.Args[0] + " aws tags --profile readonly_profile --tag-key environment --tag-value prod",
 		PreRun:  awsPreRun,
 		Run:     runTagsCommand,
 		PostRun: awsPostRun,
@@ -487,17 +960,86 @@ var (
 		PostRun: awsPostRun,
 	}
 
+	VerifiedAccessLogsDays    int
+	VerifiedAccessLogsCommand = &cobra.Command{
+		Use:     "verified-access-logs",
+		Aliases: []string{"verifiedaccess", "va-logs"},
+		Short:   "Check Verified Access logging and flag new resources, unusual locations and denied requests in the access logs",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws verified-access-logs --profile readonly_profile\n" +
+			os.Args[0] + " aws verified-access-logs --profile readonly_profile --days 14",
+		PreRun:  awsPreRun,
+		Run:     runVerifiedAccessLogsCommand,
+		PostRun: awsPostRun,
+	}
+
+	VerifiedPermissionsTemplatesCommand = &cobra.Command{
+		Use:     "verified-permissions-templates",
+		Aliases: []string{"avp-templates", "policy-templates"},
+		Short:   "Enumerate Verified Permissions policy templates and flag templates any principal can be linked to",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws verified-permissions-templates --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runVerifiedPermissionsTemplatesCommand,
+		PostRun: awsPostRun,
+	}
+
 	WorkloadsCommand = &cobra.Command{
 		Use:     "workloads",
-		Short:   "Finds workloads with admin permissions or a path to admin permissions",
+		Short:   "Finds workloads with admin permissions or a path to admin permissions, and their public endpoints",
 		Long:    "\nUse case examples:\n" + os.Args[0] + " aws workloads --profile readonly_profile",
 		PreRun:  awsPreRun,
 		Run:     runWorkloadsCommand,
 		PostRun: awsPostRun,
 	}
 
+	WorkflowSecretsCommand = &cobra.Command{
+		Use:     "workflow-secrets",
+		Aliases: []string{"stepfunctions", "ssm-documents"},
+		Short:   "Search Step Functions state machine definitions and account-owned SSM documents for secrets, and flag shared documents",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws workflow-secrets --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runWorkflowSecretsCommand,
+		PostRun: awsPostRun,
+	}
+
+	WAFCommand = &cobra.Command{
+		Use:     "waf",
+		Aliases: []string{"wafv2", "webacls"},
+		Short:   "Enumerate WAF web ACLs and the resources they protect",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws waf --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runWAFCommand,
+		PostRun: awsPostRun,
+	}
+
+	WAFLoggingCommand = &cobra.Command{
+		Use:     "waf-logging",
+		Aliases: []string{"waflogging", "webacl-logging"},
+		Short:   "Find WAF web ACLs without logging and check the encryption, public access and redaction of their log destinations",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws waf-logging --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runWAFLoggingCommand,
+		PostRun: awsPostRun,
+	}
+
+	DNSFirewallCommand = &cobra.Command{
+		Use:     "dns-firewall",
+		Aliases: []string{"dnsfirewall", "resolver-firewall"},
+		Short:   "Enumerate Route53 Resolver DNS firewall rule groups and find VPCs that can resolve malware C2 domains",
+		Long: "\nUse case examples:\n" +
+			os.Args[0] + " aws dns-firewall --profile readonly_profile",
+		PreRun:  awsPreRun,
+		Run:     runDNSFirewallCommand,
+		PostRun: awsPostRun,
+	}
+
 	DirectoryServicesCommand = &cobra.Command{
 		Use:     "ds",
+		Aliases: []string{"directory-services"},
 		Short:   "Enumerate AWS-managed Active Directory instances and trusts",
 		Long:    "\nUse case examples:\n" + os.Args[0] + " aws clouddirectory --profile readonly_profile",
 		PreRun:  awsPreRun,
@@ -539,8 +1081,31 @@ type OrgAccounts struct {
 	Accounts     []types.Account
 }
 
+// setExcludedRegions parses --exclude-regions and warns about names that aren't AWS regions, which would exclude nothing
+func setExcludedRegions(version string) {
+	internal.ExcludedRegions = nil
+	for _, region := range strings.Split(AWSExcludeRegions, ",") {
+		region = strings.TrimSpace(region)
+		if region != "" && !internal.Contains(region, internal.ExcludedRegions) {
+			internal.ExcludedRegions = append(internal.ExcludedRegions, region)
+		}
+	}
+	for _, region := range internal.UnknownRegions(internal.ExcludedRegions) {
+		fmt.Printf("[%s] Warning: %s passed to --exclude-regions is not an AWS region\n", cyan(emoji.Sprintf(":fox:cloudfox v%s :fox:", version)), region)
+	}
+	if len(internal.ExcludedRegions) > 0 {
+		fmt.Printf("[%s] Skipping regions: %s\n", cyan(emoji.Sprintf(":fox:cloudfox v%s :fox:", version)), strings.Join(internal.ExcludedRegions, ", "))
+	}
+}
+
 func awsPreRun(cmd *cobra.Command, args []string) {
 	gob.Register(&types.Organization{})
+	internal.AWSAPICalls.SetMax(AWSMaxAPICalls)
+	internal.AWSRateLimiter.SetRate(AWSRequestsPerSec)
+	internal.HTMLOutput = AWSOutputType == "html"
+	internal.CombinedCSVOutput = AWSCombinedCSV
+	internal.SetTerminalVerbosity(Verbosity)
+	setExcludedRegions(cmd.Root().Version)
 
 	// if multiple profiles were used, ensure the management account is first
 	// if AWSProfilesList != "" || AWSAllProfiles {
@@ -617,6 +1182,43 @@ func awsPostRun(cmd *cobra.Command, args []string) {
 
 		fmt.Printf("[%s][%s] Cached AWS data written to %s\n", cyan(emoji.Sprintf(":fox:cloudfox v%s :fox:", cmd.Root().Version)), cyan(profile), outputDirectory)
 
+		printDeniedAPIs(profile, caller, cmd.Root().Version)
+	}
+}
+
+// printDeniedAPIs shows which API actions the profile was denied during the run, the output of the modules that
+// needed them is incomplete. The list goes to denied-permissions.csv along with a policy that allows the denied read
+// actions for a follow-up scan.
+func printDeniedAPIs(profile string, caller *sts.GetCallerIdentityOutput, version string) {
+	denied := internal.AWSDeniedAPIs.Denied(profile)
+	if len(denied) == 0 {
+		return
+	}
+	var body [][]string
+	for _, api := range denied {
+		body = append(body, []string{api.Service, api.Action, api.Region, strconv.Itoa(api.Count)})
+	}
+	if internal.ShowErrors(Verbosity) {
+		fmt.Printf("[%s][%s] Denied APIs, the results that depend on them are incomplete:\n", cyan(emoji.Sprintf(":fox:cloudfox v%s :fox:", version)), cyan(profile))
+		internal.PrintTableToScreen([]string{"Service", "Action", "Region", "Count"}, body, AWSWrapTable)
+	} else {
+		fmt.Printf("[%s][%s] %d API actions were denied, the results that depend on them are incomplete.\n", cyan(emoji.Sprintf(":fox:cloudfox v%s :fox:", version)), cyan(profile), len(denied))
+	}
+
+	outputDirectory := filepath.Join(AWSOutputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", profile, ptr.ToString(caller.Account)))
+	csvPath, err := internal.WriteDeniedAPIsCSV(outputDirectory, denied)
+	if err != nil {
+		internal.TxtLog.Errorf("Could not write %s: %s", internal.DeniedAPIsCSVFileName, err)
+		return
+	}
+	fmt.Printf("[%s][%s] Denied APIs written to %s\n", cyan(emoji.Sprintf(":fox:cloudfox v%s :fox:", version)), cyan(profile), csvPath)
+	if policy, ok := internal.DeniedAPIsPolicy(denied); ok {
+		policyPath, err := internal.WriteJSONFile(outputDirectory, internal.DeniedAPIsPolicyFileName, policy)
+		if err != nil {
+			internal.TxtLog.Errorf("Could not write %s: %s", internal.DeniedAPIsPolicyFileName, err)
+			return
+		}
+		fmt.Printf("[%s][%s] Policy that allows the denied read actions written to %s\n", cyan(emoji.Sprintf(":fox:cloudfox v%s :fox:", version)), cyan(profile), policyPath)
 	}
 }
 
@@ -664,300 +1266,83 @@ func FindOrgMgmtAccountAndReorderAccounts(AWSProfiles []string, version string)
 }
 
 func runAccessKeysCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.AccessKeysModule{
-			IAMClient:     iam.NewFromConfig(internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)),
-			Caller:        *caller,
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		m.PrintAccessKeys(AccessKeysFilter, AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "access-keys")
+}
+
+func runACMCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "acm")
 }
 
 func runApiGwCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.ApiGwModule{
-			APIGatewayClient:   apigateway.NewFromConfig(internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)),
-			APIGatewayv2Client: apigatewayv2.NewFromConfig(internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)),
-
-			Caller:     *caller,
-			AWSRegions: internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile: profile,
-			Goroutines: Goroutines,
-			WrapTable:  AWSWrapTable,
-		}
-		m.PrintApiGws(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "api-gw")
 }
 
-func runBucketsCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
+func runBackupCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "backup")
+}
 
-		m := aws.BucketsModule{
-			S3Client:            s3.NewFromConfig(internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)),
-			Caller:              *caller,
-			AWSRegions:          internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:          profile,
-			Goroutines:          Goroutines,
-			WrapTable:           AWSWrapTable,
-			CheckBucketPolicies: CheckBucketPolicies,
-			AWSOutputType:       AWSOutputType,
-			AWSTableCols:        AWSTableCols,
-		}
-		m.PrintBuckets(AWSOutputDirectory, Verbosity)
-	}
+func runBatchSchedulingCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "batch-scheduling")
+}
 
+func runBucketsCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "buckets")
 }
 
 func runCloudformationCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.CloudformationModule{
-			CloudFormationClient: cloudformation.NewFromConfig(AWSConfig),
-			Caller:               *caller,
-			AWSRegions:           internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:           profile,
-			Goroutines:           Goroutines,
-			WrapTable:            AWSWrapTable,
-			AWSOutputType:        AWSOutputType,
-			AWSTableCols:         AWSTableCols,
-		}
-		m.PrintCloudformationStacks(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "cloudformation")
+}
+
+func runCloudTrailGapsCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "cloudtrail-gaps")
 }
 
 func runCodeBuildCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.CodeBuildModule{
-			CodeBuildClient:     codebuild.NewFromConfig(AWSConfig),
-			Caller:              *caller,
-			AWSRegions:          internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:          profile,
-			Goroutines:          Goroutines,
-			SkipAdminCheck:      AWSSkipAdminCheck,
-			WrapTable:           AWSWrapTable,
-			AWSOutputType:       AWSOutputType,
-			AWSTableCols:        AWSTableCols,
-			PmapperDataBasePath: PmapperDataBasePath,
-		}
-		m.PrintCodeBuildProjects(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "codebuild")
+}
+
+func runDataPipelineCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "data-pipeline")
 }
 
 func runDatabasesCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
+	runRegisteredAWSModule(cmd, "databases")
+}
 
-		m := aws.DatabasesModule{
-			RDSClient:      rds.NewFromConfig(AWSConfig),
-			RedshiftClient: redshift.NewFromConfig(AWSConfig),
-			DynamoDBClient: dynamodb.NewFromConfig(AWSConfig),
-			Caller:         *caller,
-			AWSRegions:     internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:     profile,
-			Goroutines:     Goroutines,
-			WrapTable:      AWSWrapTable,
-			AWSOutputType:  AWSOutputType,
-			AWSTableCols:   AWSTableCols,
-		}
-		m.PrintDatabases(AWSOutputDirectory, Verbosity)
-	}
+func runDefensesCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "defenses")
 }
 
 func runECRCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.ECRModule{
-			ECRClient:     ecr.NewFromConfig(AWSConfig),
-			Caller:        *caller,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		m.PrintECR(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "ecr")
 }
 
 func runSQSCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.SQSModule{
-			SQSClient: sqs.NewFromConfig(AWSConfig),
-
-			StorePolicies: StoreSQSAccessPolicies,
-
-			Caller:        *caller,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		m.PrintSQS(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "sqs")
 }
 
 func runSNSCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		cloudFoxSNSClient := aws.InitCloudFoxSNSClient(*caller, profile, cmd.Root().Version, Goroutines, AWSWrapTable, AWSMFAToken)
-		cloudFoxSNSClient.PrintSNS(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "sns")
 }
 
 func runEKSCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.EKSModule{
-			IAMClient: iam.NewFromConfig(AWSConfig),
-			EKSClient: eks.NewFromConfig(AWSConfig),
-
-			Caller:              *caller,
-			AWSRegions:          internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:          profile,
-			Goroutines:          Goroutines,
-			SkipAdminCheck:      AWSSkipAdminCheck,
-			WrapTable:           AWSWrapTable,
-			AWSOutputType:       AWSOutputType,
-			AWSTableCols:        AWSTableCols,
-			PmapperDataBasePath: PmapperDataBasePath,
-		}
-		m.EKS(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "eks")
 }
 
 func runEndpointsCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.EndpointsModule{
-			APIGatewayClient:   apigateway.NewFromConfig(AWSConfig),
-			APIGatewayv2Client: apigatewayv2.NewFromConfig(AWSConfig),
-			AppRunnerClient:    apprunner.NewFromConfig(AWSConfig),
-			CloudfrontClient:   cloudfront.NewFromConfig(AWSConfig),
-			EKSClient:          eks.NewFromConfig(AWSConfig),
-			ELBClient:          elasticloadbalancing.NewFromConfig(AWSConfig),
-			ELBv2Client:        elasticloadbalancingv2.NewFromConfig(AWSConfig),
-			GrafanaClient:      grafana.NewFromConfig(AWSConfig),
-			LambdaClient:       lambda.NewFromConfig(AWSConfig),
-			LightsailClient:    lightsail.NewFromConfig(AWSConfig),
-			MQClient:           mq.NewFromConfig(AWSConfig),
-			OpenSearchClient:   opensearch.NewFromConfig(AWSConfig),
-			RDSClient:          rds.NewFromConfig(AWSConfig),
-			RedshiftClient:     redshift.NewFromConfig(AWSConfig),
-			S3Client:           s3.NewFromConfig(AWSConfig),
-
-			Caller:        *caller,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		m.PrintEndpoints(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "endpoints")
 }
 
 func runEnvsCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.EnvsModule{
-
-			Caller:        *caller,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-
-			ECSClient:       ecs.NewFromConfig(AWSConfig),
-			AppRunnerClient: apprunner.NewFromConfig(AWSConfig),
-			LambdaClient:    lambda.NewFromConfig(AWSConfig),
-			LightsailClient: lightsail.NewFromConfig(AWSConfig),
-			SagemakerClient: sagemaker.NewFromConfig(AWSConfig),
-		}
-		m.PrintEnvs(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "env-vars")
 }
 
 func runFilesystemsCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		filesystems := aws.FilesystemsModule{
-			EFSClient: efs.NewFromConfig(AWSConfig),
-			FSxClient: fsx.NewFromConfig(AWSConfig),
-
-			Caller:        *caller,
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		filesystems.PrintFilesystems(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "filesystems")
+}
+
+func runEFSCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "efs")
 }
 
 func runGraphCommand(cmd *cobra.Command, args []string) {
@@ -1357,1031 +1742,255 @@ func runCapeTUICommand(cmd *cobra.Command, args []string) {
 }
 
 func runIamSimulatorCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.IamSimulatorModule{
-			IAMClient:                  iam.NewFromConfig(AWSConfig),
-			Caller:                     *caller,
-			AWSProfileProvided:         profile,
-			Goroutines:                 Goroutines,
-			WrapTable:                  AWSWrapTable,
-			AWSOutputType:              AWSOutputType,
-			AWSTableCols:               AWSTableCols,
-			IamSimulatorAdminCheckOnly: IamSimulatorAdminCheckOnly,
-		}
-		m.PrintIamSimulator(SimulatorPrincipal, SimulatorAction, SimulatorResource, AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "iam-simulator")
+}
+
+func runImdsCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "imds")
 }
 
 func runInstancesCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.InstancesModule{
-			EC2Client: ec2.NewFromConfig(AWSConfig),
-			IAMClient: iam.NewFromConfig(AWSConfig),
-
-			Caller:                 *caller,
-			AWSRegions:             internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			UserDataAttributesOnly: InstanceMapUserDataAttributesOnly,
-			AWSProfile:             profile,
-			SkipAdminCheck:         AWSSkipAdminCheck,
-			WrapTable:              AWSWrapTable,
-			AWSOutputType:          AWSOutputType,
-			AWSTableCols:           AWSTableCols,
-			PmapperDataBasePath:    PmapperDataBasePath,
-		}
-		m.Instances(InstancesFilter, AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "instances")
+}
+
+func runLaunchTemplateSecretsCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "launch-template-secrets")
 }
 
 func runInventoryCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.Inventory2Module{
-			APIGatewayClient:       apigateway.NewFromConfig(AWSConfig),
-			APIGatewayv2Client:     apigatewayv2.NewFromConfig(AWSConfig),
-			AppRunnerClient:        apprunner.NewFromConfig(AWSConfig),
-			AthenaClient:           athena.NewFromConfig(AWSConfig),
-			Cloud9Client:           cloud9.NewFromConfig(AWSConfig),
-			CloudFormationClient:   cloudformation.NewFromConfig(AWSConfig),
-			CloudfrontClient:       cloudfront.NewFromConfig(AWSConfig),
-			CodeArtifactClient:     codeartifact.NewFromConfig(AWSConfig),
-			CodeBuildClient:        codebuild.NewFromConfig(AWSConfig),
-			CodeCommitClient:       codecommit.NewFromConfig(AWSConfig),
-			CodeDeployClient:       codedeploy.NewFromConfig(AWSConfig),
-			DataPipelineClient:     datapipeline.NewFromConfig(AWSConfig),
-			DynamoDBClient:         dynamodb.NewFromConfig(AWSConfig),
-			EC2Client:              ec2.NewFromConfig(AWSConfig),
-			ECSClient:              ecs.NewFromConfig(AWSConfig),
-			ECRClient:              ecr.NewFromConfig(AWSConfig),
-			EKSClient:              eks.NewFromConfig(AWSConfig),
-			ELBClient:              elasticloadbalancing.NewFromConfig(AWSConfig),
-			ELBv2Client:            elasticloadbalancingv2.NewFromConfig(AWSConfig),
-			ElasticacheClient:      elasticache.NewFromConfig(AWSConfig),
-			ElasticBeanstalkClient: elasticbeanstalk.NewFromConfig(AWSConfig),
-			EMRClient:              emr.NewFromConfig(AWSConfig),
-			GlueClient:             glue.NewFromConfig(AWSConfig),
-			GrafanaClient:          grafana.NewFromConfig(AWSConfig),
-			IAMClient:              iam.NewFromConfig(AWSConfig),
-			KinesisClient:          kinesis.NewFromConfig(AWSConfig),
-			LambdaClient:           lambda.NewFromConfig(AWSConfig),
-			LightsailClient:        lightsail.NewFromConfig(AWSConfig),
-			MQClient:               mq.NewFromConfig(AWSConfig),
-			OpenSearchClient:       opensearch.NewFromConfig(AWSConfig),
-			RDSClient:              rds.NewFromConfig(AWSConfig),
-			RedshiftClient:         redshift.NewFromConfig(AWSConfig),
-			Route53Client:          route53.NewFromConfig(AWSConfig),
-			S3Client:               s3.NewFromConfig(AWSConfig),
-			SecretsManagerClient:   secretsmanager.NewFromConfig(AWSConfig),
-			SNSClient:              sns.NewFromConfig(AWSConfig),
-			SQSClient:              sqs.NewFromConfig(AWSConfig),
-			SSMClient:              ssm.NewFromConfig(AWSConfig),
-			StepFunctionClient:     sfn.NewFromConfig(AWSConfig),
-
-			Caller:        *caller,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		m.PrintInventoryPerRegion(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "inventory")
+}
+
+func runIoTCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "iot")
 }
 
 func runLambdasCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.LambdasModule{
-			LambdaClient:        lambda.NewFromConfig(AWSConfig),
-			IAMClient:           iam.NewFromConfig(AWSConfig),
-			Caller:              *caller,
-			AWSRegions:          internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:          profile,
-			Goroutines:          Goroutines,
-			SkipAdminCheck:      AWSSkipAdminCheck,
-			WrapTable:           AWSWrapTable,
-			AWSOutputType:       AWSOutputType,
-			AWSTableCols:        AWSTableCols,
-			PmapperDataBasePath: PmapperDataBasePath,
-		}
-		m.PrintLambdas(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "lambda")
+}
+
+func runLightsailCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "lightsail")
+}
+
+func runLegacyServicesCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "legacy-services")
 }
 
 func runOutboundAssumedRolesCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.OutboundAssumedRolesModule{
-			CloudTrailClient: cloudtrail.NewFromConfig(AWSConfig),
-
-			Caller:        *caller,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		m.PrintOutboundRoleTrusts(OutboundAssumedRolesDays, AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "outbound-assumed-roles")
+}
+
+func runOutpostsRoutingCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "outposts-routing")
+}
+
+func runOpenSearchServerlessCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "opensearch-serverless")
 }
 
 func runOrgsCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.OrgModule{
-			OrganizationsClient: organizations.NewFromConfig(AWSConfig),
-			Caller:              *caller,
-			AWSProfile:          profile,
-			WrapTable:           AWSWrapTable,
-			AWSOutputType:       AWSOutputType,
-			AWSTableCols:        AWSTableCols,
-		}
-		m.PrintOrgAccounts(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "org")
 }
 
 func runPermissionsCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.IamPermissionsModule{
-			IAMClient:     iam.NewFromConfig(AWSConfig),
-			Caller:        *caller,
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSTableCols:  AWSTableCols,
-			AWSOutputType: AWSOutputType,
-		}
-		m.PrintIamPermissions(AWSOutputDirectory, Verbosity, PermissionsPrincipal)
-	}
+	runRegisteredAWSModule(cmd, "permissions")
 }
 
 func runPmapperCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.PmapperModule{
-			Caller:              *caller,
-			AWSProfile:          profile,
-			Goroutines:          Goroutines,
-			WrapTable:           AWSWrapTable,
-			AWSOutputType:       AWSOutputType,
-			AWSTableCols:        AWSTableCols,
-			PmapperDataBasePath: PmapperDataBasePath,
-		}
-		m.PrintPmapperData(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "pmapper")
 }
 
 func runPrincipalsCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.IamPrincipalsModule{
-			IAMClient:           iam.NewFromConfig(AWSConfig),
-			Caller:              *caller,
-			AWSProfile:          profile,
-			Goroutines:          Goroutines,
-			SkipAdminCheck:      AWSSkipAdminCheck,
-			WrapTable:           AWSWrapTable,
-			AWSOutputType:       AWSOutputType,
-			AWSTableCols:        AWSTableCols,
-			PmapperDataBasePath: PmapperDataBasePath,
-		}
-		m.PrintIamPrincipals(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "principals")
 }
 
 func runRAMCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		ram := aws.RAMModule{
-			RAMClient:     ram.NewFromConfig(AWSConfig),
-			Caller:        *caller,
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		ram.PrintRAM(AWSOutputDirectory, Verbosity)
+	runRegisteredAWSModule(cmd, "ram")
+}
 
-	}
+func runStackSetsCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "stacksets")
+}
+
+func runResourcePoliciesCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "resource-policies")
 }
 
 func runResourceTrustsCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.ResourceTrustsModule{
-			Caller:             *caller,
-			AWSProfileProvided: profile,
-			Goroutines:         Goroutines,
-			AWSRegions:         internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			WrapTable:          AWSWrapTable,
-			CloudFoxVersion:    cmd.Root().Version,
-			AWSOutputType:      AWSOutputType,
-			AWSTableCols:       AWSTableCols,
-			AWSConfig:          AWSConfig,
-		}
-		m.PrintResources(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "resource-trusts")
+}
+
+func runRoleChainingCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "role-chaining")
 }
 
 func runRoleTrustCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.RoleTrustsModule{
-			IAMClient:           iam.NewFromConfig(AWSConfig),
-			Caller:              *caller,
-			AWSProfile:          profile,
-			Goroutines:          Goroutines,
-			SkipAdminCheck:      AWSSkipAdminCheck,
-			WrapTable:           AWSWrapTable,
-			AWSOutputType:       AWSOutputType,
-			AWSTableCols:        AWSTableCols,
-			PmapperDataBasePath: PmapperDataBasePath,
-		}
-		m.PrintRoleTrusts(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "role-trusts")
 }
 
 func runRoute53Command(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.Route53Module{
-			Route53Client: route53.NewFromConfig(AWSConfig),
-
-			Caller:        *caller,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		m.PrintRoute53(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "route53")
+}
+
+func runRDSProxyCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "rds-proxy")
+}
+
+func runRecentIAMChangesCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "recent-iam-changes")
+}
+
+func runSageMakerCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "sagemaker")
+}
+
+func runSageMakerPipelinesCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "sagemaker-pipelines")
+}
+
+func runSecretAccessAnomaliesCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "secret-access-anomalies")
 }
 
 func runSecretsCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.SecretsModule{
-			SecretsManagerClient: secretsmanager.NewFromConfig(AWSConfig),
-			SSMClient:            ssm.NewFromConfig(AWSConfig),
-
-			Caller:        *caller,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		m.PrintSecrets(AWSOutputDirectory, Verbosity)
+	runRegisteredAWSModule(cmd, "secrets")
+}
+
+// parseSecretsSince turns --since into a date, the zero time keeps every secret
+func parseSecretsSince() time.Time {
+	if SecretsSince == "" {
+		return time.Time{}
+	}
+	since, err := utils.ParseDate(SecretsSince)
+	if err != nil {
+		log.Fatalf("[-] Invalid --since value: %s", err)
 	}
+	return since
+}
+
+func runSSMAutomationCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "ssm-automation")
 }
 
 func runTagsCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.TagsModule{
-			ResourceGroupsTaggingApiInterface: resourcegroupstaggingapi.NewFromConfig(AWSConfig),
-			Caller:                            *caller,
-			AWSRegions:                        internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:                        profile,
-			Goroutines:                        Goroutines,
-			WrapTable:                         AWSWrapTable,
-			MaxResourcesPerRegion:             MaxResourcesPerRegion,
-			AWSOutputType:                     AWSOutputType,
-			AWSTableCols:                      AWSTableCols,
-		}
-		m.PrintTags(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "tags")
 }
 
 func runWorkloadsCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.WorkloadsModule{
-			ECSClient:           ecs.NewFromConfig(AWSConfig),
-			EC2Client:           ec2.NewFromConfig(AWSConfig),
-			LambdaClient:        lambda.NewFromConfig(AWSConfig),
-			AppRunnerClient:     apprunner.NewFromConfig(AWSConfig),
-			IAMClient:           iam.NewFromConfig(AWSConfig),
-			Caller:              *caller,
-			AWSRegions:          internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			SkipAdminCheck:      AWSSkipAdminCheck,
-			AWSProfile:          profile,
-			Goroutines:          Goroutines,
-			WrapTable:           AWSWrapTable,
-			AWSOutputType:       AWSOutputType,
-			AWSTableCols:        AWSTableCols,
-			PmapperDataBasePath: PmapperDataBasePath,
-		}
-		m.PrintWorkloads(AWSOutputDirectory, Verbosity)
-	}
+	runRegisteredAWSModule(cmd, "workloads")
 }
 
-func runDirectoryServicesCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.DirectoryModule{
-			DSClient:      directoryservice.NewFromConfig(AWSConfig),
-			Caller:        *caller,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		m.PrintDirectories(AWSOutputDirectory, Verbosity)
-	}
+func runVerifiedPermissionsTemplatesCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "verified-permissions-templates")
 }
 
-func runECSTasksCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.ECSTasksModule{
-			EC2Client: ec2.NewFromConfig(internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)),
-			ECSClient: ecs.NewFromConfig(internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)),
-			IAMClient: iam.NewFromConfig(internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)),
+func runWorkflowSecretsCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "workflow-secrets")
+}
 
-			Caller:              *caller,
-			AWSRegions:          internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:          profile,
-			Goroutines:          Goroutines,
-			SkipAdminCheck:      AWSSkipAdminCheck,
-			WrapTable:           AWSWrapTable,
-			AWSOutputType:       AWSOutputType,
-			AWSTableCols:        AWSTableCols,
-			PmapperDataBasePath: PmapperDataBasePath,
-		}
-		m.ECSTasks(AWSOutputDirectory, Verbosity)
-	}
+func runWAFCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "waf")
 }
 
-func runENICommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.ElasticNetworkInterfacesModule{
-			//EC2Client:                       ec2.NewFromConfig(internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)),
-			EC2Client: ec2.NewFromConfig(internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)),
-
-			Caller:        *caller,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:    profile,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		m.ElasticNetworkInterfaces(AWSOutputDirectory, Verbosity)
-	}
+func runVerifiedAccessLogsCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "verified-access-logs")
 }
 
-func runNetworkPortsCommand(cmd *cobra.Command, args []string) {
-	for _, profile := range AWSProfiles {
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
-		m := aws.NetworkPortsModule{
-			EC2Client:         ec2.NewFromConfig(internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)),
-			ECSClient:         ecs.NewFromConfig(internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)),
-			EFSClient:         efs.NewFromConfig(internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)),
-			ElastiCacheClient: elasticache.NewFromConfig(internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)),
-			ELBv2Client:       elasticloadbalancingv2.NewFromConfig(internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)),
-			LightsailClient:   lightsail.NewFromConfig(internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)),
-			RDSClient:         rds.NewFromConfig(internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)),
-			Caller:            *caller,
-			AWSRegions:        internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:        profile,
-			Goroutines:        Goroutines,
-			WrapTable:         AWSWrapTable,
-			Verbosity:         Verbosity,
-			AWSOutputType:     AWSOutputType,
-			AWSTableCols:      AWSTableCols,
-		}
-		m.PrintNetworkPorts(AWSOutputDirectory)
-	}
+func runWAFLoggingCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "waf-logging")
 }
 
-func runAllChecksCommand(cmd *cobra.Command, args []string) {
-	Verbosity = 1
-	for _, profile := range AWSProfiles {
-		var AWSConfig = internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
-		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
-		if err != nil {
-			continue
-		}
+func runDNSFirewallCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "dns-firewall")
+}
 
-		apiGatewayClient := apigateway.NewFromConfig(AWSConfig)
-		apiGatewayv2Client := apigatewayv2.NewFromConfig(AWSConfig)
-		appRunnerClient := apprunner.NewFromConfig(AWSConfig)
-		athenaClient := athena.NewFromConfig(AWSConfig)
-		cloud9Client := cloud9.NewFromConfig(AWSConfig)
-		cloudFormationClient := cloudformation.NewFromConfig(AWSConfig)
-		cloudfrontClient := cloudfront.NewFromConfig(AWSConfig)
-		codeArtifactClient := codeartifact.NewFromConfig(AWSConfig)
-		codeBuildClient := codebuild.NewFromConfig(AWSConfig)
-		codeCommitClient := codecommit.NewFromConfig(AWSConfig)
-		codeDeployClient := codedeploy.NewFromConfig(AWSConfig)
-		dataPipelineClient := datapipeline.NewFromConfig(AWSConfig)
-		dynamodbClient := dynamodb.NewFromConfig(AWSConfig)
-		ec2Client := ec2.NewFromConfig(AWSConfig)
-		ecrClient := ecr.NewFromConfig(AWSConfig)
-		ecsClient := ecs.NewFromConfig(AWSConfig)
-		efsClient := efs.NewFromConfig(AWSConfig)
-		eksClient := eks.NewFromConfig(AWSConfig)
-		elasticacheClient := elasticache.NewFromConfig(AWSConfig)
-		elasticBeanstalkClient := elasticbeanstalk.NewFromConfig(AWSConfig)
-		elbClient := elasticloadbalancing.NewFromConfig(AWSConfig)
-		elbv2Client := elasticloadbalancingv2.NewFromConfig(AWSConfig)
-		emrClient := emr.NewFromConfig(AWSConfig)
-		fsxClient := fsx.NewFromConfig(AWSConfig)
-		glueClient := glue.NewFromConfig(AWSConfig)
-		grafanaClient := grafana.NewFromConfig(AWSConfig)
-		iamClient := iam.NewFromConfig(AWSConfig)
-		kinesisClient := kinesis.NewFromConfig(AWSConfig)
-		lambdaClient := lambda.NewFromConfig(AWSConfig)
-		lightsailClient := lightsail.NewFromConfig(AWSConfig)
-		mqClient := mq.NewFromConfig(AWSConfig)
-		openSearchClient := opensearch.NewFromConfig(AWSConfig)
-		orgClient := organizations.NewFromConfig(AWSConfig)
-		ramClient := ram.NewFromConfig(AWSConfig)
-		rdsClient := rds.NewFromConfig(AWSConfig)
-		redshiftClient := redshift.NewFromConfig(AWSConfig)
-		resourceClient := resourcegroupstaggingapi.NewFromConfig(AWSConfig)
-		route53Client := route53.NewFromConfig(AWSConfig)
-		s3Client := s3.NewFromConfig(AWSConfig)
-		sagemakerClient := sagemaker.NewFromConfig(AWSConfig)
-		secretsManagerClient := secretsmanager.NewFromConfig(AWSConfig)
-		snsClient := sns.NewFromConfig(AWSConfig)
-		sqsClient := sqs.NewFromConfig(AWSConfig)
-		ssmClient := ssm.NewFromConfig(AWSConfig)
-		stepFunctionClient := sfn.NewFromConfig(AWSConfig)
-
-		fmt.Printf("[%s] %s\n", cyan(emoji.Sprintf(":fox:cloudfox :fox:")), green("Getting a lay of the land, aka \"What regions is this account using?\""))
-		inventory2 := aws.Inventory2Module{
-			APIGatewayClient:       apiGatewayClient,
-			APIGatewayv2Client:     apiGatewayv2Client,
-			AppRunnerClient:        appRunnerClient,
-			AthenaClient:           athenaClient,
-			Cloud9Client:           cloud9Client,
-			CloudFormationClient:   cloudFormationClient,
-			CloudfrontClient:       cloudfrontClient,
-			CodeArtifactClient:     codeArtifactClient,
-			CodeBuildClient:        codeBuildClient,
-			CodeCommitClient:       codeCommitClient,
-			CodeDeployClient:       codeDeployClient,
-			DataPipelineClient:     dataPipelineClient,
-			DynamoDBClient:         dynamodbClient,
-			EC2Client:              ec2Client,
-			ECSClient:              ecsClient,
-			ECRClient:              ecrClient,
-			EKSClient:              eksClient,
-			ELBClient:              elbClient,
-			ELBv2Client:            elbv2Client,
-			ElasticacheClient:      elasticacheClient,
-			ElasticBeanstalkClient: elasticBeanstalkClient,
-			EMRClient:              emrClient,
-			GlueClient:             glueClient,
-			GrafanaClient:          grafanaClient,
-			IAMClient:              iamClient,
-			KinesisClient:          kinesisClient,
-			LambdaClient:           lambdaClient,
-			LightsailClient:        lightsailClient,
-			MQClient:               mqClient,
-			OpenSearchClient:       openSearchClient,
-			RDSClient:              rdsClient,
-			RedshiftClient:         redshiftClient,
-			Route53Client:          route53Client,
-			S3Client:               s3Client,
-			SecretsManagerClient:   secretsManagerClient,
-			SNSClient:              snsClient,
-			SQSClient:              sqsClient,
-			SSMClient:              ssmClient,
-			StepFunctionClient:     stepFunctionClient,
-
-			Caller:        *caller,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		inventory2.PrintInventoryPerRegion(AWSOutputDirectory, Verbosity)
-
-		tagsMod := aws.TagsModule{
-			ResourceGroupsTaggingApiInterface: resourceClient,
-			Caller:                            *caller,
-			AWSRegions:                        internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:                        profile,
-			Goroutines:                        Goroutines,
-			MaxResourcesPerRegion:             1000,
-		}
-		var verbosityOverride int = 1
-		tagsMod.PrintTags(AWSOutputDirectory, verbosityOverride)
+func runDirectoryServicesCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "directory-services")
+}
 
-		orgMod := aws.OrgModule{
-			OrganizationsClient: orgClient,
-			Caller:              *caller,
-			AWSRegions:          internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:          profile,
-			Goroutines:          Goroutines,
-			WrapTable:           AWSWrapTable,
-			AWSOutputType:       AWSOutputType,
-			AWSTableCols:        AWSTableCols,
-		}
-		orgMod.PrintOrgAccounts(AWSOutputDirectory, Verbosity)
-
-		// Service and endpoint enum section
-		fmt.Printf("[%s] %s\n", cyan(emoji.Sprintf(":fox:cloudfox :fox:")), green("Gathering the info you'll want for your application & service enumeration needs."))
-		instances := aws.InstancesModule{
-			EC2Client:              ec2Client,
-			IAMClient:              iamClient,
-			Caller:                 *caller,
-			AWSRegions:             internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			SkipAdminCheck:         AWSSkipAdminCheck,
-			UserDataAttributesOnly: false,
-			AWSProfile:             profile,
-			WrapTable:              AWSWrapTable,
-			AWSOutputType:          AWSOutputType,
-			AWSTableCols:           AWSTableCols,
-			PmapperDataBasePath:    PmapperDataBasePath,
-		}
-		instances.Instances(InstancesFilter, AWSOutputDirectory, Verbosity)
-		route53 := aws.Route53Module{
-			Route53Client: route53Client,
-
-			Caller:     *caller,
-			AWSRegions: internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile: profile,
-			Goroutines: Goroutines,
-		}
+func runECSCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "ecs")
+}
 
-		lambdasMod := aws.LambdasModule{
-			LambdaClient:        lambdaClient,
-			IAMClient:           iamClient,
-			Caller:              *caller,
-			AWSRegions:          internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:          profile,
-			Goroutines:          Goroutines,
-			SkipAdminCheck:      AWSSkipAdminCheck,
-			WrapTable:           AWSWrapTable,
-			AWSOutputType:       AWSOutputType,
-			AWSTableCols:        AWSTableCols,
-			PmapperDataBasePath: PmapperDataBasePath,
-		}
-		lambdasMod.PrintLambdas(AWSOutputDirectory, Verbosity)
-
-		route53.PrintRoute53(AWSOutputDirectory, Verbosity)
-
-		filesystems := aws.FilesystemsModule{
-			EFSClient:     efsClient,
-			FSxClient:     fsxClient,
-			Caller:        *caller,
-			AWSProfile:    profile,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		filesystems.PrintFilesystems(AWSOutputDirectory, Verbosity)
-
-		endpoints := aws.EndpointsModule{
-
-			EKSClient:          eksClient,
-			S3Client:           s3Client,
-			LambdaClient:       lambdaClient,
-			RDSClient:          rdsClient,
-			APIGatewayv2Client: apiGatewayv2Client,
-			APIGatewayClient:   apiGatewayClient,
-			ELBClient:          elbClient,
-			ELBv2Client:        elbv2Client,
-			MQClient:           mqClient,
-			OpenSearchClient:   openSearchClient,
-			GrafanaClient:      grafanaClient,
-			RedshiftClient:     redshiftClient,
-			CloudfrontClient:   cloudfrontClient,
-			AppRunnerClient:    appRunnerClient,
-			LightsailClient:    lightsailClient,
-
-			Caller:        *caller,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
+func runECSTasksCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "ecs-tasks")
+}
 
-		endpoints.PrintEndpoints(AWSOutputDirectory, Verbosity)
+func runENICommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "elastic-network-interfaces")
+}
 
-		gateways := aws.ApiGwModule{
-			APIGatewayv2Client: apiGatewayv2Client,
-			APIGatewayClient:   apiGatewayClient,
+func runEndpointServicesCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "endpoint-services")
+}
 
-			Caller:     *caller,
-			AWSRegions: internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile: profile,
-			Goroutines: Goroutines,
-			WrapTable:  AWSWrapTable,
-		}
+func runAMPRulesCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "amp-rules")
+}
 
-		gateways.PrintApiGws(AWSOutputDirectory, Verbosity)
-
-		databases := aws.DatabasesModule{
-			RDSClient:      rdsClient,
-			RedshiftClient: redshiftClient,
-			DynamoDBClient: dynamodbClient,
-			Caller:         *caller,
-			AWSProfile:     profile,
-			AWSRegions:     internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			Goroutines:     Goroutines,
-			WrapTable:      AWSWrapTable,
-			AWSOutputType:  AWSOutputType,
-			AWSTableCols:   AWSTableCols,
-		}
+func runAuroraGlobalCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "aurora-global")
+}
 
-		databases.PrintDatabases(AWSOutputDirectory, Verbosity)
+func runGroupsCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "groups")
+}
 
-		ecstasks := aws.ECSTasksModule{
-			EC2Client: ec2Client,
-			ECSClient: ecsClient,
-			IAMClient: iamClient,
+func runIAMAccountCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "iam-account")
+}
 
-			Caller:              *caller,
-			AWSRegions:          internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:          profile,
-			Goroutines:          Goroutines,
-			SkipAdminCheck:      AWSSkipAdminCheck,
-			WrapTable:           AWSWrapTable,
-			AWSOutputType:       AWSOutputType,
-			AWSTableCols:        AWSTableCols,
-			PmapperDataBasePath: PmapperDataBasePath,
-		}
-		ecstasks.ECSTasks(AWSOutputDirectory, Verbosity)
+func runInlinePoliciesCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "inline-policies")
+}
 
-		eksCommand := aws.EKSModule{
-			EKSClient: eksClient,
-			IAMClient: iamClient,
+func runMSKCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "msk")
+}
 
-			Caller:              *caller,
-			AWSRegions:          internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:          profile,
-			Goroutines:          Goroutines,
-			SkipAdminCheck:      AWSSkipAdminCheck,
-			WrapTable:           AWSWrapTable,
-			AWSOutputType:       AWSOutputType,
-			AWSTableCols:        AWSTableCols,
-			PmapperDataBasePath: PmapperDataBasePath,
-		}
-		eksCommand.EKS(AWSOutputDirectory, Verbosity)
-
-		elasticnetworkinterfaces := aws.ElasticNetworkInterfacesModule{
-			EC2Client:     ec2Client,
-			Caller:        *caller,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:    profile,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		elasticnetworkinterfaces.ElasticNetworkInterfaces(AWSOutputDirectory, Verbosity)
-
-		// Secrets section
-		fmt.Printf("[%s] %s\n", cyan(emoji.Sprintf(":fox:cloudfox :fox:")), green("Looking for secrets hidden between the seat cushions."))
-
-		ec2UserData := aws.InstancesModule{
-			EC2Client:  ec2Client,
-			IAMClient:  iamClient,
-			Caller:     *caller,
-			AWSRegions: internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-
-			UserDataAttributesOnly: true,
-			AWSProfile:             profile,
-			Goroutines:             Goroutines,
-			WrapTable:              AWSWrapTable,
-			AWSOutputType:          AWSOutputType,
-			AWSTableCols:           AWSTableCols,
-		}
-		ec2UserData.Instances(InstancesFilter, AWSOutputDirectory, Verbosity)
-		envsMod := aws.EnvsModule{
-
-			Caller:          *caller,
-			AWSRegions:      internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:      profile,
-			ECSClient:       ecsClient,
-			AppRunnerClient: appRunnerClient,
-			LambdaClient:    lambdaClient,
-			LightsailClient: lightsailClient,
-			SagemakerClient: sagemakerClient,
-			Goroutines:      Goroutines,
-			WrapTable:       AWSWrapTable,
-			AWSOutputType:   AWSOutputType,
-			AWSTableCols:    AWSTableCols,
-		}
-		envsMod.PrintEnvs(AWSOutputDirectory, Verbosity)
-
-		cfMod := aws.CloudformationModule{
-			CloudFormationClient: cloudFormationClient,
-			Caller:               *caller,
-			AWSRegions:           internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:           profile,
-			Goroutines:           Goroutines,
-			WrapTable:            AWSWrapTable,
-			AWSOutputType:        AWSOutputType,
-			AWSTableCols:         AWSTableCols,
-		}
-		cfMod.PrintCloudformationStacks(AWSOutputDirectory, Verbosity)
+func runMSKReplicatorCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "msk-replicator")
+}
 
-		// CPT Enum
-		//fmt.Printf("[%s] %s\n", cyan(emoji.Sprintf(":fox:cloudfox :fox:")), green("Gathering some other info that is often useful."))
-		fmt.Printf("[%s] %s\n", cyan(emoji.Sprintf(":fox:cloudfox :fox:")), green("Arming you with the data you'll need for privesc quests."))
+func runLogGroupsCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "log-groups")
+}
 
-		// cloudFoxS3Client := aws.CloudFoxS3Client{
-		// 	S3Client:   s3.NewFromConfig(internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)),
-		// 	Caller:     *caller,
-		// 	AWSRegions: internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-		// 	AWSProfile: profile,
-		// }
+func runALBBypassCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "alb-bypass")
+}
 
-		buckets := aws.BucketsModule{
-			S3Client:      s3.NewFromConfig(internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)),
-			Caller:        *caller,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		buckets.PrintBuckets(AWSOutputDirectory, Verbosity)
-
-		ecr := aws.ECRModule{
-			ECRClient:     ecrClient,
-			Caller:        *caller,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		ecr.PrintECR(AWSOutputDirectory, Verbosity)
-
-		secrets := aws.SecretsModule{
-			SecretsManagerClient: secretsManagerClient,
-			SSMClient:            ssmClient,
-
-			Caller:        *caller,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		secrets.PrintSecrets(AWSOutputDirectory, Verbosity)
-
-		ram := aws.RAMModule{
-			RAMClient:     ramClient,
-			Caller:        *caller,
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		ram.PrintRAM(AWSOutputDirectory, Verbosity)
-
-		networkPorts := aws.NetworkPortsModule{
-			EC2Client:         ec2Client,
-			ECSClient:         ecsClient,
-			EFSClient:         efsClient,
-			ElastiCacheClient: elasticacheClient,
-			ELBv2Client:       elbv2Client,
-			LightsailClient:   lightsailClient,
-			RDSClient:         rdsClient,
-			Caller:            *caller,
-			AWSProfile:        profile,
-			Goroutines:        Goroutines,
-			AWSRegions:        internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSOutputType:     AWSOutputType,
-			AWSTableCols:      AWSTableCols,
-		}
-		networkPorts.PrintNetworkPorts(AWSOutputDirectory)
+func runCloudSearchCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "cloudsearch")
+}
 
-		sqsMod := aws.SQSModule{
-			SQSClient: sqsClient,
+func runGlueDataCatalogCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "glue-catalog")
+}
 
-			StorePolicies: StoreSQSAccessPolicies,
+func runRekognitionStreamingCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "rekognition-streaming")
+}
 
-			Caller:        *caller,
-			AWSRegions:    internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		sqsMod.PrintSQS(AWSOutputDirectory, Verbosity)
-
-		cloudFoxSNSClient := aws.InitCloudFoxSNSClient(*caller, profile, cmd.Root().Version, Goroutines, AWSWrapTable, AWSMFAToken)
-		cloudFoxSNSClient.PrintSNS(AWSOutputDirectory, Verbosity)
-
-		resourceTrustsCommand := aws.ResourceTrustsModule{
-			Caller:             *caller,
-			AWSProfileProvided: profile,
-			Goroutines:         Goroutines,
-			AWSRegions:         internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			WrapTable:          AWSWrapTable,
-			CloudFoxVersion:    cmd.Root().Version,
-			AWSOutputType:      AWSOutputType,
-			AWSTableCols:       AWSTableCols,
-			AWSMFAToken:        AWSMFAToken,
-			AWSConfig:          AWSConfig,
-		}
-		resourceTrustsCommand.PrintResources(AWSOutputDirectory, Verbosity)
+func runTransferCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "transfer")
+}
 
-		codeBuildCommand := aws.CodeBuildModule{
-			CodeBuildClient:     codeBuildClient,
-			Caller:              *caller,
-			AWSProfile:          profile,
-			Goroutines:          Goroutines,
-			AWSRegions:          internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			WrapTable:           AWSWrapTable,
-			AWSOutputType:       AWSOutputType,
-			AWSTableCols:        AWSTableCols,
-			PmapperDataBasePath: PmapperDataBasePath,
-		}
-		codeBuildCommand.PrintCodeBuildProjects(AWSOutputDirectory, Verbosity)
-
-		// IAM privesc section
-		fmt.Printf("[%s] %s\n", cyan(emoji.Sprintf(":fox:cloudfox :fox:")), green("IAM is complicated. Complicated usually means misconfigurations. You'll want to pay attention here."))
-		principals := aws.IamPrincipalsModule{
-			IAMClient:     iamClient,
-			Caller:        *caller,
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
+func runEventBridgeCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "eventbridge")
+}
 
-		principals.PrintIamPrincipals(AWSOutputDirectory, Verbosity)
-		permissions := aws.IamPermissionsModule{
-			IAMClient:  iamClient,
-			Caller:     *caller,
-			AWSProfile: profile,
-			Goroutines: Goroutines,
-			WrapTable:  AWSWrapTable,
-		}
-		permissions.PrintIamPermissions(AWSOutputDirectory, Verbosity, PermissionsPrincipal)
-		accessKeys := aws.AccessKeysModule{
-			IAMClient:     iam.NewFromConfig(AWSConfig),
-			Caller:        *caller,
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		accessKeys.PrintAccessKeys(AccessKeysFilter, AWSOutputDirectory, Verbosity)
-		roleTrusts := aws.RoleTrustsModule{
-			IAMClient:      iamClient,
-			Caller:         *caller,
-			AWSProfile:     profile,
-			Goroutines:     Goroutines,
-			SkipAdminCheck: AWSSkipAdminCheck,
-			WrapTable:      AWSWrapTable,
-			AWSOutputType:  AWSOutputType,
-			AWSTableCols:   AWSTableCols,
-		}
-		roleTrusts.PrintRoleTrusts(AWSOutputDirectory, Verbosity)
-
-		pmapperCommand := aws.PmapperModule{
-			Caller:        *caller,
-			AWSProfile:    profile,
-			Goroutines:    Goroutines,
-			WrapTable:     AWSWrapTable,
-			AWSOutputType: AWSOutputType,
-			AWSTableCols:  AWSTableCols,
-		}
-		pmapperCommand.PrintPmapperData(AWSOutputDirectory, Verbosity)
-
-		iamSimulator := aws.IamSimulatorModule{
-			IAMClient:          iamClient,
-			Caller:             *caller,
-			AWSProfileProvided: profile,
-			Goroutines:         Goroutines,
-			WrapTable:          AWSWrapTable,
-			AWSOutputType:      AWSOutputType,
-			AWSTableCols:       AWSTableCols,
-		}
-		iamSimulator.PrintIamSimulator(SimulatorPrincipal, SimulatorAction, SimulatorResource, AWSOutputDirectory, Verbosity)
-
-		workloads := aws.WorkloadsModule{
-			ECSClient:           ecsClient,
-			EC2Client:           ec2Client,
-			LambdaClient:        lambdaClient,
-			AppRunnerClient:     appRunnerClient,
-			IAMClient:           iamClient,
-			Caller:              *caller,
-			AWSRegions:          internal.GetEnabledRegions(profile, cmd.Root().Version, AWSMFAToken),
-			SkipAdminCheck:      AWSSkipAdminCheck,
-			AWSProfile:          profile,
-			Goroutines:          Goroutines,
-			WrapTable:           AWSWrapTable,
-			AWSOutputType:       AWSOutputType,
-			AWSTableCols:        AWSTableCols,
-			PmapperDataBasePath: PmapperDataBasePath,
-		}
-		workloads.PrintWorkloads(AWSOutputDirectory, Verbosity)
+func runMediaStoreCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "mediastore")
+}
 
-		fmt.Printf("[%s] %s\n", cyan(emoji.Sprintf(":fox:cloudfox :fox:")), green("That's it! Check your output files for situational awareness and check your loot files for next steps."))
-		fmt.Printf("[%s] %s\n\n", cyan(emoji.Sprintf(":fox:cloudfox :fox:")), green("FYI, we skipped the outbound-assumed-roles module in all-checks (really long run time). Make sure to try it out manually."))
-	}
+func runMQCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "mq")
+}
+
+func runNetworkPortsCommand(cmd *cobra.Command, args []string) {
+	runRegisteredAWSModule(cmd, "network-ports")
 }
 
 var CapeTuiCmd = &cobra.Command{
@@ -2415,7 +2024,57 @@ func init() {
 	SNSCommand.Flags().BoolVarP(&StoreSNSAccessPolicies, "policies", "", false, "Store all flagged access policies along with the output")
 
 	//  outbound-assumed-roles module flags
-	OutboundAssumedRolesCommand.Flags().IntVarP(&OutboundAssumedRolesDays, "days", "d", -7, "How many days of CloudTrail events should we go back and look at.")
+	OutboundAssumedRolesCommand.Flags().IntVarP(&OutboundAssumedRolesDays, "days", "d", 7, "How many days of CloudTrail events should we go back and look at.")
+
+	// acm module flags
+	ACMCommand.Flags().IntVar(&ACMCertExpiryDays, "cert-expiry-days", 30, "Flag certificates that expire within this many days")
+
+	// log-groups module flags
+	LogGroupsCommand.Flags().BoolVar(&LogGroupsSearchLogs, "search-logs", false, "Search the last 7 days of every log group for the search terms with logs:FilterLogEvents")
+	LogGroupsCommand.Flags().StringSliceVar(&LogGroupsSearchTerms, "search-terms", []string{"password", "secret", "token"}, "Terms to search recent log events for when --search-logs is set")
+	LogGroupsCommand.Flags().Int64Var(&LogGroupsMinSize, "min-size", 0, "Skip log groups that store fewer than this many bytes")
+
+	// recent-iam-changes module flags
+	RecentIAMChangesCommand.Flags().IntVarP(&RecentIAMChangesDays, "days", "d", 7, "How many days of IAM events in CloudTrail to look at")
+	RecentIAMChangesCommand.Flags().StringSliceVar(&RecentIAMChangesExpectedPrincipals, "expected-principals", []string{}, "Role and user ARNs that are supposed to change IAM. Changes by other principals are flagged")
+	RecentIAMChangesCommand.Flags().StringSliceVar(&RecentIAMChangesCorporateCIDRs, "corporate-cidrs", []string{}, "CIDRs IAM changes are supposed to come from. Changes from other source IPs are flagged")
+
+	// cloudtrail-gaps module flags
+	CloudTrailGapsCommand.Flags().IntVarP(&CloudTrailGapsDays, "days", "d", 30, "How many days of StopLogging events in CloudTrail to look at")
+	CloudTrailGapsCommand.Flags().StringSliceVar(&CloudTrailGapsSecurityToolRoles, "security-tool-roles", []string{}, "Role ARNs or names of security tooling that is expected to stop trails. StopLogging calls by other principals are flagged")
+
+	// secret-access-anomalies module flags
+	SecretAccessAnomaliesCommand.Flags().IntVarP(&SecretAccessAnomaliesDays, "days", "d", 30, "How many days of GetSecretValue events to analyze")
+
+	// verified-access-logs module flags
+	VerifiedAccessLogsCommand.Flags().IntVarP(&VerifiedAccessLogsDays, "days", "d", 7, "How many days of Verified Access logs to read. The last day is compared with the days before it")
+
+	// secrets module flags
+	SecretsCommand.Flags().BoolVar(&SecretsAnsibleLoot, "ansible-loot", false, "Also write a retrieve-secrets.yml Ansible playbook that pulls every secret into Ansible variables")
+	SecretsCommand.Flags().BoolVar(&SecretsTerraformLoot, "loot-terraform-data", false, "Also write a secrets-data.tf file with Terraform data sources that read every secret and parameter")
+	SecretsCommand.Flags().BoolVar(&SecretsNucleiTemplates, "nuclei-templates", false, "Also write a Nuclei template per service and secret type that checks which of the secrets can be read with signed GetSecretValue and GetParameter calls")
+	SecretsCommand.Flags().BoolVar(&SecretsResolveSSMValues, "resolve-ssm-values", false, "Decrypt SecureString parameters with ssm:GetParameter and show the first 80 characters of each value. Requires --confirm-show-values")
+	SecretsCommand.Flags().BoolVar(&SecretsConfirmShowValues, "confirm-show-values", false, "Confirm that secret values may be printed to the screen and written to the output files")
+	SecretsCommand.Flags().StringVar(&SecretsNamesFile, "secret-names-file", "", "File with one secret name per line. Looks up only these names with DescribeSecret and GetParameter in every region instead of listing secrets, so ListSecrets and DescribeParameters permissions are not needed")
+	SecretsCommand.Flags().StringVar(&SecretsSince, "since", "", "Only show secrets created or changed after this date, as RFC3339 timestamp or YYYY-MM-DD. Adds a LastModified column")
+	SecretsCommand.Flags().BoolVar(&SecretsAnalyzeAccess, "analyze-access", false, "Simulate the policies of all IAM users and roles to find who can read each secret. Adds a Readable By column. Slow in accounts with many principals")
+	SecretsCommand.Flags().BoolVar(&SecretsCompare1Password, "compare-1password", false, "Compare Secrets Manager secret names with the items of a 1Password Connect server to find secrets that are only in one store or were rotated in one only. Reads the server from OP_CONNECT_HOST and the token from OP_CONNECT_TOKEN")
+	SecretsCommand.Flags().BoolVar(&SecretsValidate, "validate", false, "Check if secrets named after GitHub, Slack, Stripe or Twilio still hold working credentials with one read-only call to the service. Reads the secret values with secretsmanager:GetSecretValue and ssm:GetParameter, but never shows them")
+	SecretsCommand.Flags().BoolVar(&SecretsDynamoSecrets, "dynamo-secrets", false, "Also scan up to 100 items of every DynamoDB table for credentials in their string attributes. Reads item values with dynamodb:Scan, only masked previews are shown")
+	SecretsCommand.Flags().StringSliceVar(&SecretsDynamoTables, "dynamo-tables", []string{}, "DynamoDB tables to scan with --dynamo-secrets instead of listing all tables, comma separated")
+	SecretsCommand.Flags().StringVar(&SecretsDynamoRegex, "dynamo-regex", "", "Regular expression that marks a DynamoDB attribute value as a secret, replaces the built-in credential patterns of --dynamo-secrets")
+	SecretsCommand.Flags().BoolVar(&SecretsSummarizePaths, "summarize-paths", false, "Group SSM parameters by the first two components of their path into a summary table and write one get-parameters-by-path command per group to the loot file. The full list is still written to the output files")
+	SecretsCommand.Flags().StringVar(&SecretsSlackWebhook, "slack-webhook", "", "Slack incoming webhook URL. When the scan is done, posts the number of secrets per service and the top 10 secrets by estimated severity. Secret values are never sent")
+	SecretsCommand.Flags().BoolVar(&SecretsSlackRealtime, "slack-realtime", false, "Also post every HIGH or CRITICAL secret to --slack-webhook as soon as it is found")
+	SecretsCommand.Flags().BoolVar(&SecretsPreferProd, "prefer-prod", false, "List the secrets that look like production secrets first, by their env, environment or stage tag or a prod/ in the name. SSM parameter tags take one extra call per parameter")
+	SecretsCommand.Flags().StringVar(&SecretsProdPattern, "prod-pattern", "", "Regular expression matched against the name and every key=value tag of a secret to mark it as production, replaces the built-in heuristics of --prefer-prod")
+	SecretsCommand.Flags().BoolVar(&SecretsStream, "stream", false, "Print every secret as a tab-separated row as soon as it is found. The progress goes to stderr, and the table and output files are written at the end as usual")
+	SecretsCommand.Flags().StringVar(&SecretsDescribeName, "name", "", "Describe only this secret or parameter, by name or ARN, instead of scanning. Shows its tags, rotation, version stages and resource policy, or its parameter metadata and version history. A name that exists in both Secrets Manager and SSM shows both")
+	SecretsCommand.Flags().StringVar(&SecretsDescribeRegion, "region", "", "Region to look up --name in. Without it, every region is searched for that name only")
+	SecretsCommand.Flags().StringVar(&SecretsOutputPath, "output-path", "", "Output directory for this run, overrides --outdir. Supports {account}, {profile}, {region} and {date} placeholders")
+
+	// ssm-automation module flags
+	SSMAutomationCommand.Flags().IntVarP(&SSMAutomationDays, "days", "d", 7, "How many days of automation executions should we go back and look at.")
 
 	//  iam-simulator module flags
 	IamSimulatorCommand.Flags().StringVar(&SimulatorPrincipal, "principal", "", "Principal Arn")
@@ -2428,6 +2087,9 @@ func init() {
 
 	// tags module flags
 	TagsCommand.Flags().IntVarP(&MaxResourcesPerRegion, "max-resources-per-region", "m", 0, "Maximum number of resources to enumerate per region. Set to 0 to enumerate all resources.")
+	TagsCommand.Flags().StringVar(&TagsTagKey, "tag-key", "", "Only enumerate resources with this tag key")
+	TagsCommand.Flags().StringSliceVar(&TagsTagValues, "tag-value", []string{}, "Only enumerate resources where the --tag-key tag has one of these values")
+	TagsCommand.Flags().BoolVar(&TagsIncludeUntagged, "include-untagged", false, "Include resources that are returned with no tags. These are often unmanaged resources worth a closer look. AWS does not return them when --tag-key is set")
 
 	// buckets command flags (for bucket policies)
 	BucketsCommand.Flags().BoolVarP(&CheckBucketPolicies, "with-policies", "", false, "Analyze bucket policies (this is already done in the resource-trusts command)")
@@ -2446,8 +2108,9 @@ func init() {
 	AWSCommands.PersistentFlags().StringVarP(&AWSProfilesList, "profiles-list", "l", "", "File containing a AWS CLI profile names separated by newlines")
 	AWSCommands.PersistentFlags().BoolVarP(&AWSAllProfiles, "all-profiles", "a", false, "Use all AWS CLI profiles in AWS credentials file")
 	AWSCommands.PersistentFlags().BoolVarP(&AWSConfirm, "yes", "y", false, "Non-interactive mode (like apt/yum)")
-	AWSCommands.PersistentFlags().StringVarP(&AWSOutputType, "output", "o", "brief", "[\"brief\" | \"wide\" ]")
-	AWSCommands.PersistentFlags().IntVarP(&Verbosity, "verbosity", "v", 2, "1 = Print control messages only\n2 = Print control messages, module output\n3 = Print control messages, module output, and loot file output\n")
+	AWSCommands.PersistentFlags().StringVarP(&AWSOutputType, "output", "o", "brief", "[\"brief\" | \"wide\" | \"sarif\" | \"html\" ]. sarif also writes a SARIF 2.1.0 report (secrets only), html also writes a self-contained HTML report of every table")
+	AWSCommands.PersistentFlags().IntVarP(&Verbosity, "verbosity", "v", internal.VerbosityTables, verbosityUsage)
+	AWSCommands.PersistentFlags().BoolVar(&internal.PrintLoot, "print-loot", false, "Print the loot file contents at every verbosity")
 	AWSCommands.PersistentFlags().StringVar(&AWSOutputDirectory, "outdir", defaultOutputDir, "Output Directory ")
 	AWSCommands.PersistentFlags().IntVarP(&Goroutines, "max-goroutines", "g", 30, "Maximum number of concurrent goroutines")
 	AWSCommands.PersistentFlags().BoolVar(&AWSSkipAdminCheck, "skip-admin-check", false, "Skip check to determine if role is an Admin")
@@ -2455,45 +2118,100 @@ func init() {
 	AWSCommands.PersistentFlags().BoolVarP(&AWSUseCache, "cached", "c", false, "Load cached data from disk. Faster, but if changes have been recently made you'll miss them")
 	AWSCommands.PersistentFlags().StringVarP(&AWSTableCols, "cols", "t", "", "Comma separated list of columns to display in table output")
 	AWSCommands.PersistentFlags().StringVar(&AWSMFAToken, "mfa-token", "", "MFA Token")
+	AWSCommands.PersistentFlags().BoolVar(&AWSCombinedCSV, "combined-csv", false, "Also merge the rows of every table into cloudfox-output/aws/combined/<table>.csv with AWSProfile and AccountID columns. Re-running a profile replaces its rows")
+	AWSCommands.PersistentFlags().StringVar(&AWSLootTemplateDir, "loot-template-dir", "", "Directory with Go text/template files that replace the default loot commands, one <module>.tmpl per module (supported: secrets)")
+	AWSCommands.PersistentFlags().IntVar(&AWSMaxAPICalls, "max-api-calls", 0, "Stop making AWS API calls after this many. Set to 0 for no limit")
+	AWSCommands.PersistentFlags().Float64Var(&AWSRequestsPerSec, "requests-per-second", 0, "Send at most this many AWS API requests per second across all modules and regions, retries included. Use it to stay under anomaly detection thresholds. Set to 0 for no limit")
+	AWSCommands.PersistentFlags().BoolVar(&AWSResume, "resume", false, "Resume an interrupted run from the checkpoints in the output directory: skip the modules (all-checks) and region checks (secrets) that already completed and merge their results with the new ones")
+	AWSCommands.PersistentFlags().DurationVar(&AWSCheckpointAge, "checkpoint-max-age", 24*time.Hour, "Ignore checkpoints older than this with --resume. Set to 0 to use checkpoints of any age")
+	AWSCommands.PersistentFlags().StringVar(&AWSExcludeRegions, "exclude-regions", "", "Comma separated list of regions to skip in every module, e.g. ap-east-1,me-south-1")
 	AWSCommands.PersistentFlags().StringVar(&PmapperDataBasePath, "pmapper-data-basepath", "", "Supply the base path for the pmapper data files (useful if you have copied them from another machine)\nPoint to the parent directory that contains all of the pmapper data by account numbers. \n\tExample: /path/to/com.nccgroup.principalmapper/\n\tExample: ./pmapperdata/")
 
 	AWSCommands.AddCommand(
 		AccessKeysCommand,
+		ACMCommand,
+		ALBBypassCommand,
 		AllChecksCommand,
+		AMPRulesCommand,
 		ApiGwCommand,
+		AuroraGlobalCommand,
+		BackupCommand,
+		BatchSchedulingCommand,
 		BucketsCommand,
 		CapeCommand,
 		CloudformationCommand,
+		CloudSearchCommand,
+		CloudTrailGapsCommand,
 		CodeBuildCommand,
+		DataPipelineCommand,
 		DatabasesCommand,
+		DefensesCommand,
+		ECSCommand,
 		ECSTasksCommand,
 		ECRCommand,
 		EKSCommand,
 		ElasticNetworkInterfacesCommand,
+		EndpointServicesCommand,
 		EndpointsCommand,
 		EnvsCommand,
+		EventBridgeCommand,
 		FilesystemsCommand,
+		EFSCommand,
+		GlueDataCatalogCommand,
 		//GraphCommand,
+		GroupsCommand,
+		IAMCommand,
+		IAMAccountCommand,
 		IamSimulatorCommand,
+		InlinePoliciesCommand,
+		ImdsCommand,
 		InstancesCommand,
+		LaunchTemplateSecretsCommand,
 		InventoryCommand,
+		IoTCommand,
 		LambdasCommand,
+		LightsailCommand,
+		LegacyServicesCommand,
+		LogGroupsCommand,
+		MediaStoreCommand,
+		MQCommand,
+		TransferCommand,
+		MSKCommand,
+		MSKReplicatorCommand,
 		NetworkPortsCommand,
+		OpenSearchServerlessCommand,
 		OrgsCommand,
 		OutboundAssumedRolesCommand,
+		OutpostsRoutingCommand,
 		PermissionsCommand,
 		PrincipalsCommand,
 		PmapperCommand,
 		RAMCommand,
+		RDSProxyCommand,
+		RecentIAMChangesCommand,
+		RekognitionStreamingCommand,
+		ResourcePoliciesCommand,
 		ResourceTrustsCommand,
+		RoleChainingCommand,
 		RoleTrustCommand,
 		Route53Command,
 		SQSCommand,
 		SNSCommand,
+		SageMakerCommand,
+		SageMakerPipelinesCommand,
+		SecretAccessAnomaliesCommand,
 		SecretsCommand,
+		SSMAutomationCommand,
+		StackSetsCommand,
 		TagsCommand,
+		VerifiedAccessLogsCommand,
+		VerifiedPermissionsTemplatesCommand,
+		WAFCommand,
+		WAFLoggingCommand,
+		WorkflowSecretsCommand,
 		WorkloadsCommand,
 		DirectoryServicesCommand,
+		DNSFirewallCommand,
 	)
 
 	CapeCommand.AddCommand(
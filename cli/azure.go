@@ -4,6 +4,7 @@ import (
 	"log"
 
 	"github.com/BishopFox/cloudfox/azure"
+	"github.com/BishopFox/cloudfox/internal"
 	"github.com/spf13/cobra"
 )
 
@@ -22,6 +23,9 @@ var (
 		Aliases: []string{"az"},
 		Long:    `See "Available Commands" for Azure Modules below`,
 		Short:   "See \"Available Commands\" for Azure Modules below",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			internal.SetTerminalVerbosity(AzVerbosity)
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			cmd.Help()
 		},
@@ -59,20 +63,28 @@ Enumerate inventory for a specific subscription:
 			}
 		},
 	}
-	AzRBACCommand = &cobra.Command{
+	AzRBACPrincipal        string
+	AzRBACManagementGroups []string
+	AzRBACCommand          = &cobra.Command{
 		Use:     "rbac",
 		Aliases: []string{},
-		Short:   "Display role assignemts for Azure principals",
+		Short:   "Display role assignemts for Azure principals and flag dangerous custom roles",
 		Long: `
 Enumerate role assignments for a specific tenant:
 ./cloudfox az rbac --tenant TENANT_ID
 
 Enumerate role assignments for a specific subscription:
 ./cloudfox az rbac --subscription SUBSCRIPTION_ID
+
+Include role assignments made on management groups:
+./cloudfox az rbac --tenant TENANT_ID --management-group MANAGEMENT_GROUP_ID
+
+Show everything one principal can touch:
+./cloudfox az rbac --tenant TENANT_ID --principal OBJECT_ID_OR_NAME
 `,
 		Run: func(cmd *cobra.Command, args []string) {
 
-			err := azure.AzRBACCommand(AzTenantID, AzSubscription, AzOutputFormat, AzOutputDirectory, cmd.Root().Version, AzVerbosity, AzWrapTable, AzMergedTable)
+			err := azure.AzRBACCommand(AzTenantID, AzSubscription, AzOutputFormat, AzOutputDirectory, cmd.Root().Version, AzVerbosity, AzWrapTable, AzMergedTable, AzRBACPrincipal, AzRBACManagementGroups)
 			if err != nil {
 				log.Fatal(err)
 			}
@@ -81,7 +93,7 @@ Enumerate role assignments for a specific subscription:
 	AzVMsCommand = &cobra.Command{
 		Use:     "vms",
 		Aliases: []string{"vms", "virtualmachines"},
-		Short:   "Enumerates Azure Compute virtual machines",
+		Short:   "Enumerates Azure Compute virtual machines and scale sets and the roles of their managed identities",
 		Long: `
 Enumerate VMs for a specific tenant:
 ./cloudfox az vms --tenant TENANT_ID
@@ -95,10 +107,34 @@ Enumerate VMs for a specific subscription:
 			}
 		},
 	}
+	AzKeyVaultsCommand = &cobra.Command{
+		Use:     "keyvaults",
+		Aliases: []string{"kv"},
+		Short:   "Enumerates key vaults and the secrets, keys and certificates the caller can list",
+		Long: `
+Enumerate key vaults in every subscription visible to the credentials:
+./cloudfox az keyvaults
+
+Enumerate key vaults for a specific tenant:
+./cloudfox az keyvaults --tenant TENANT_ID
+
+Enumerate key vaults for a specific subscription:
+./cloudfox az keyvaults --subscription SUBSCRIPTION_ID
+
+Credentials come from the az cli session, unless AZURE_CLIENT_ID, AZURE_CLIENT_SECRET and AZURE_TENANT_ID
+are set, in which case the service principal they describe is used.
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := azure.AzKeyVaultsCommand(AzTenantID, AzSubscription, AzOutputFormat, AzOutputDirectory, cmd.Root().Version, AzVerbosity, AzWrapTable, AzMergedTable)
+			if err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
 	AzStorageCommand = &cobra.Command{
 		Use:     "storage",
 		Aliases: []string{},
-		Short:   "Enumerates azure storage accounts",
+		Short:   "Enumerates azure storage accounts and their containers",
 		Long: `
 Enumerate storage accounts for a specific tenant:
 ./cloudfox az storage --tenant TENANT_ID
@@ -118,11 +154,14 @@ Enumerate storage accounts for a specific subscription:
 func init() {
 
 	AzWhoamiCommand.Flags().BoolVarP(&AzWhoamiListRGsAlso, "list-rgs", "l", false, "Drill down to the resource group level")
+	AzRBACCommand.Flags().StringVar(&AzRBACPrincipal, "principal", "", "Only show role assignments of this principal (object ID, display name or UPN)")
+	AzRBACCommand.Flags().StringSliceVar(&AzRBACManagementGroups, "management-group", []string{}, "Also list role assignments of these management groups")
 
 	// Global flags
 	AzCommands.PersistentFlags().StringVarP(&AzOutputFormat, "output", "o", "all", "[\"table\" | \"csv\" | \"all\" ]")
 	AzCommands.PersistentFlags().StringVar(&AzOutputDirectory, "outdir", defaultOutputDir, "Output Directory ")
-	AzCommands.PersistentFlags().IntVarP(&AzVerbosity, "verbosity", "v", 2, "1 = Print control messages only\n2 = Print control messages, module output\n3 = Print control messages, module output, and loot file output\n")
+	AzCommands.PersistentFlags().IntVarP(&AzVerbosity, "verbosity", "v", internal.VerbosityTables, verbosityUsage)
+	AzCommands.PersistentFlags().BoolVar(&internal.PrintLoot, "print-loot", false, "Print the loot file contents at every verbosity")
 	AzCommands.PersistentFlags().StringVarP(&AzTenantID, "tenant", "t", "", "Tenant name")
 	AzCommands.PersistentFlags().StringVarP(&AzSubscription, "subscription", "s", "", "Subscription ID or Name")
 	AzCommands.PersistentFlags().StringVarP(&AzRGName, "resource-group", "g", "", "Resource Group name")
@@ -134,6 +173,7 @@ func init() {
 		AzRBACCommand,
 		AzVMsCommand,
 		AzStorageCommand,
+		AzKeyVaultsCommand,
 		AzInventoryCommand)
 
 }
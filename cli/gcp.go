@@ -6,6 +6,7 @@ import (
 
 	"github.com/BishopFox/cloudfox/gcp/commands"
 	oauthservice "github.com/BishopFox/cloudfox/gcp/services/oauthService"
+	resourcemanagerservice "github.com/BishopFox/cloudfox/gcp/services/resourceManagerService"
 	"github.com/BishopFox/cloudfox/internal"
 	"github.com/spf13/cobra"
 )
@@ -15,6 +16,7 @@ var (
 	GCPOrganization       string
 	GCPProjectID          string
 	GCPProjectIDsFilePath string
+	GCPProjectIDsList     []string
 	GCPProjectIDs         []string
 
 	// Output formatting options
@@ -36,12 +38,22 @@ var (
 		Long:    `See "Available Commands" for GCP Modules below`,
 		Short:   "See \"Available Commands\" for GCP Modules below",
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			internal.SetTerminalVerbosity(Verbosity)
 			if GCPProjectID != "" {
 				GCPProjectIDs = append(GCPProjectIDs, GCPProjectID)
+			} else if len(GCPProjectIDsList) > 0 {
+				GCPProjectIDs = GCPProjectIDsList
 			} else if GCPProjectIDsFilePath != "" {
 				GCPProjectIDs = internal.LoadFileLinesIntoArray(GCPProjectIDsFilePath)
 			} else {
-				GCPLogger.InfoM("project or project-list flags not given, commands requiring a project ID will fail", "gcp")
+				// Fall back to every project the credentials can see, like the AWS commands fall back to every region
+				projectIDs, err := resourcemanagerservice.New().ProjectIDs()
+				if err != nil || len(projectIDs) == 0 {
+					GCPLogger.InfoM("project, projects or project-list flags not given and no projects visible through Resource Manager, commands requiring a project ID will fail", "gcp")
+				} else {
+					GCPLogger.InfoM(fmt.Sprintf("project, projects or project-list flags not given, using the %d projects visible through Resource Manager", len(projectIDs)), "gcp")
+					GCPProjectIDs = projectIDs
+				}
 			}
 			// Create a context with this value to share it with subcommands at runtime
 			ctx := context.WithValue(context.Background(), "projectIDs", GCPProjectIDs)
@@ -85,11 +97,13 @@ func init() {
 	// Resource filtering options
 	// GCPCommands.PersistentFlags().StringVarP(&GCPOrganization, "organization", "o", "", "Organization name or number, repetable")
 	GCPCommands.PersistentFlags().StringVarP(&GCPProjectID, "project", "p", "", "GCP project ID")
+	GCPCommands.PersistentFlags().StringSliceVar(&GCPProjectIDsList, "projects", []string{}, "Comma separated list of GCP project IDs")
 	GCPCommands.PersistentFlags().StringVarP(&GCPProjectIDsFilePath, "project-list", "l", "", "Path to a file containing a list of project IDs separated by newlines")
 	// GCPCommands.PersistentFlags().BoolVarP(&GCPAllProjects, "all-projects", "a", false, "Use all project IDs available to activated gloud account or given gcloud account")
 	// GCPCommands.PersistentFlags().BoolVarP(&GCPConfirm, "yes", "y", false, "Non-interactive mode (like apt/yum)")
 	// GCPCommands.PersistentFlags().StringVarP(&GCPOutputFormat, "output", "", "brief", "[\"brief\" | \"wide\" ]")
-	GCPCommands.PersistentFlags().IntVarP(&Verbosity, "verbosity", "v", 2, "1 = Print control messages only\n2 = Print control messages, module output\n3 = Print control messages, module output, and loot file output\n")
+	GCPCommands.PersistentFlags().IntVarP(&Verbosity, "verbosity", "v", internal.VerbosityTables, verbosityUsage)
+	GCPCommands.PersistentFlags().BoolVar(&internal.PrintLoot, "print-loot", false, "Print the loot file contents at every verbosity")
 	// defaultOutputDir is defined in cli.aws
 	GCPCommands.PersistentFlags().StringVar(&GCPOutputDirectory, "outdir", defaultOutputDir, "Output Directory ")
 	// GCPCommands.PersistentFlags().IntVarP(&Goroutines, "max-goroutines", "g", 30, "Maximum number of concurrent goroutines")
@@ -101,6 +115,7 @@ func init() {
 		commands.GCPArtifactRegistryCommand,
 		commands.GCPBigQueryCommand,
 		commands.GCPSecretsCommand,
+		commands.GCPServiceAccountsCommand,
 		commands.GCPIAMCommand,
 		commands.GCPInstancesCommand,
 		commands.GCPWhoAmICommand,
@@ -2,6 +2,9 @@ package commands
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
 	CloudStorageService "github.com/BishopFox/cloudfox/gcp/services/cloudStorageService"
 	"github.com/BishopFox/cloudfox/globals"
@@ -12,7 +15,7 @@ import (
 var GCPBucketsCommand = &cobra.Command{
 	Use:     globals.GCP_BUCKETS_MODULE_NAME,
 	Aliases: []string{},
-	Short:   "Display GCP buckets information",
+	Short:   "Display GCP buckets information and flag public ones",
 	Args:    cobra.MinimumNArgs(0),
 	Long: `
 Display available bucket information:
@@ -20,6 +23,9 @@ cloudfox gcp buckets`,
 	Run: runGCPBucketsCommand,
 }
 
+// How many projects are enumerated at the same time
+const gcpBucketsGoroutines = 10
+
 // Code needed to output fields from buckets results using generic HandleOutput function
 
 // Results struct that implements the internal.OutputInterface
@@ -34,6 +40,10 @@ func (g GCPBucketsResults) TableFiles() []internal.TableFile {
 	header := []string{
 		"Name",
 		"Location",
+		"UniformAccess",
+		"PublicAccessPrevention",
+		"Public",
+		"PublicBindings",
 		"ProjectID",
 	}
 
@@ -45,6 +55,10 @@ func (g GCPBucketsResults) TableFiles() []internal.TableFile {
 			[]string{
 				value.Name,
 				value.Location,
+				fmt.Sprintf("%t", value.UniformBucketLevelAccess),
+				value.PublicAccessPrevention,
+				bucketPublicAccess(value),
+				strings.Join(value.PublicBindings, ", "),
 				value.ProjectID,
 			},
 		)
@@ -62,7 +76,206 @@ func (g GCPBucketsResults) TableFiles() []internal.TableFile {
 
 // Decide what is loot based on resource information
 func (g GCPBucketsResults) LootFiles() []internal.LootFile {
-	return []internal.LootFile{}
+	if len(g.Data) == 0 {
+		return []internal.LootFile{}
+	}
+
+	var commands, publicURLs string
+	for _, value := range g.Data {
+		commands += fmt.Sprintf("gsutil ls gs://%s\n", value.Name)
+		if value.PublicRead || value.PublicWrite {
+			publicURLs += fmt.Sprintf("# %s: %s\n", value.Name, strings.Join(value.PublicBindings, ", "))
+			publicURLs += fmt.Sprintf("https://storage.googleapis.com/%s/\n", value.Name)
+		}
+	}
+
+	lootFiles := []internal.LootFile{
+		{
+			Name:     globals.GCP_BUCKETS_MODULE_NAME + "-commands",
+			Contents: commands,
+		},
+	}
+	if publicURLs != "" {
+		lootFiles = append(lootFiles, internal.LootFile{
+			Name:     globals.GCP_BUCKETS_MODULE_NAME + "-public-urls",
+			Contents: publicURLs,
+		})
+	}
+	return lootFiles
+}
+
+// bucketPublicAccess summarizes what allUsers and allAuthenticatedUsers can do with a bucket
+func bucketPublicAccess(bucket CloudStorageService.BucketInfo) string {
+	switch {
+	case bucket.IAMPolicyError != "":
+		return "Unknown"
+	case bucket.PublicRead && bucket.PublicWrite:
+		return "Read, Write"
+	case bucket.PublicWrite:
+		return "Write"
+	case bucket.PublicRead:
+		return "Read"
+	case len(bucket.PublicBindings) > 0:
+		return "Custom role"
+	}
+	return "No"
+}
+
+// GCPBucketsModule lists the buckets of several projects at once, with a goroutine per project instead of the
+// goroutine per region the AWS modules use
+type GCPBucketsModule struct {
+	ProjectIDs []string
+	Account    string
+	Goroutines int
+	Wrap       bool
+	Format     string
+
+	// Main module data
+	Buckets []CloudStorageService.BucketInfo
+	// Errors holds why the buckets of a project couldn't be listed, e.g. because storage.buckets.list is denied
+	Errors         map[string]string
+	CommandCounter internal.CommandCounter
+	// Used to store output data for pretty printing
+	output internal.OutputData2
+	logger internal.Logger
+}
+
+// gcpBucketsProjectResult is what a project goroutine sends to the receiver
+type gcpBucketsProjectResult struct {
+	ProjectID string
+	Buckets   []CloudStorageService.BucketInfo
+	Err       error
+}
+
+func (m *GCPBucketsModule) PrintBuckets(outputDirectory string, verbosity int) {
+	// These struct values are used by the output module
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = globals.GCP_BUCKETS_MODULE_NAME
+	m.logger = internal.NewLogger()
+	m.Errors = make(map[string]string)
+	if m.Goroutines <= 0 {
+		m.Goroutines = gcpBucketsGoroutines
+	}
+
+	wg := new(sync.WaitGroup)
+	semaphore := make(chan struct{}, m.Goroutines)
+
+	// Create a channel to signal the spinner aka task status goroutine to finish
+	spinnerDone := make(chan bool)
+	//fire up the the task status spinner/updated
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "projects")
+
+	//create a channel to receive the objects
+	dataReceiver := make(chan gcpBucketsProjectResult)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	cs := CloudStorageService.New()
+	for _, projectID := range m.ProjectIDs {
+		wg.Add(1)
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending++
+		go m.getBucketsPerProject(cs, projectID, wg, semaphore, dataReceiver)
+	}
+
+	wg.Wait()
+
+	// Send a message to the spinner goroutine to close the channel and stop
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	sort.Slice(m.Buckets, func(i, j int) bool {
+		if m.Buckets[i].ProjectID != m.Buckets[j].ProjectID {
+			return m.Buckets[i].ProjectID < m.Buckets[j].ProjectID
+		}
+		return m.Buckets[i].Name < m.Buckets[j].Name
+	})
+
+	// Output lands in a directory per project
+	bucketsByProject := make(map[string][]CloudStorageService.BucketInfo)
+	for _, bucket := range m.Buckets {
+		bucketsByProject[bucket.ProjectID] = append(bucketsByProject[bucket.ProjectID], bucket)
+	}
+	var public int
+	for _, projectID := range m.ProjectIDs {
+		buckets, ok := bucketsByProject[projectID]
+		if !ok {
+			continue
+		}
+		for _, bucket := range buckets {
+			if bucket.PublicRead || bucket.PublicWrite {
+				public++
+			}
+		}
+		err := internal.HandleOutput("gcp", m.Format, outputDirectory, verbosity, m.Wrap, m.output.CallingModule, m.Account, projectID, GCPBucketsResults{Data: buckets})
+		if err != nil {
+			m.logger.ErrorM(err.Error(), m.output.CallingModule)
+			continue
+		}
+		m.logger.InfoM(fmt.Sprintf("Done writing output for project %s", projectID), m.output.CallingModule)
+	}
+
+	m.logger.InfoM(fmt.Sprintf("%d buckets found in %d projects, %d of them public.", len(m.Buckets), len(bucketsByProject), public), m.output.CallingModule)
+	m.printErrorsSummary()
+}
+
+// printErrorsSummary lists the projects that were skipped, so a denied project doesn't go unnoticed among the others
+func (m *GCPBucketsModule) printErrorsSummary() {
+	if len(m.Errors) == 0 {
+		return
+	}
+	var projectIDs []string
+	for projectID := range m.Errors {
+		projectIDs = append(projectIDs, projectID)
+	}
+	sort.Strings(projectIDs)
+	m.logger.ErrorM(fmt.Sprintf("Could not list the buckets of %d projects:", len(projectIDs)), m.output.CallingModule)
+	for _, projectID := range projectIDs {
+		m.logger.ErrorM(fmt.Sprintf("  %s: %s", projectID, m.Errors[projectID]), m.output.CallingModule)
+	}
+}
+
+func (m *GCPBucketsModule) Receiver(receiver chan gcpBucketsProjectResult, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			if data.Err != nil {
+				m.Errors[data.ProjectID] = data.Err.Error()
+				continue
+			}
+			m.Buckets = append(m.Buckets, data.Buckets...)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *GCPBucketsModule) getBucketsPerProject(cs *CloudStorageService.CloudStorageService, projectID string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan gcpBucketsProjectResult) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+
+	buckets, err := cs.Buckets(projectID)
+	if err != nil {
+		m.CommandCounter.Error++
+	}
+	dataReceiver <- gcpBucketsProjectResult{ProjectID: projectID, Buckets: buckets, Err: err}
 }
 
 // Houses high-level logic that retrieves resources and writes to output
@@ -85,31 +298,17 @@ func runGCPBucketsCommand(cmd *cobra.Command, args []string) {
 		logger.ErrorM("Could not retrieve account email from command", globals.GCP_BUCKETS_MODULE_NAME)
 	}
 
-	// Get the bucket info using the projectIDs and CloudStorageService
-	cs := CloudStorageService.New()
-	var results []CloudStorageService.BucketInfo
-
 	// Set output params leveraging parent (gcp) pflag values
 	verbosity, _ := parentCmd.PersistentFlags().GetInt("verbosity")
 	wrap, _ := parentCmd.PersistentFlags().GetBool("wrap")
 	outputDirectory, _ := parentCmd.PersistentFlags().GetString("outdir")
 	format, _ := parentCmd.PersistentFlags().GetString("output")
 
-	for _, projectID := range projectIDs {
-		logger.InfoM(fmt.Sprintf("Retrieving all buckets from project: %s", projectID), globals.GCP_BUCKETS_MODULE_NAME)
-		result, err := cs.Buckets(projectID)
-		if err != nil {
-			logger.ErrorM(err.Error(), globals.GCP_BUCKETS_MODULE_NAME)
-			return
-		}
-		results = append(results, result...)
-		logger.InfoM(fmt.Sprintf("Done retrieving all buckets from project: %s", projectID), globals.GCP_BUCKETS_MODULE_NAME)
-		cloudfoxOutput := GCPBucketsResults{Data: results}
-		err = internal.HandleOutput("gcp", format, outputDirectory, verbosity, wrap, globals.GCP_BUCKETS_MODULE_NAME, account, projectID, cloudfoxOutput)
-		if err != nil {
-			logger.ErrorM(err.Error(), globals.GCP_BUCKETS_MODULE_NAME)
-			return
-		}
-		logger.InfoM(fmt.Sprintf("Done writing output for project %s", projectID), globals.GCP_BUCKETS_MODULE_NAME)
+	m := GCPBucketsModule{
+		ProjectIDs: projectIDs,
+		Account:    account,
+		Wrap:       wrap,
+		Format:     format,
 	}
+	m.PrintBuckets(outputDirectory, verbosity)
 }
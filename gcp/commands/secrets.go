@@ -18,7 +18,8 @@ var GCPSecretsCommand = &cobra.Command{
 	Args:    cobra.MinimumNArgs(0),
 	Long: `
 Display available secrets information:
-cloudfox gcp secrets`,
+cloudfox gcp secrets
+cloudfox gcp secrets --projects project-a,project-b`,
 	Run: runGCPSecretsCommand,
 }
 
@@ -35,17 +36,27 @@ func (g GCPSecretsResults) TableFiles() []internal.TableFile {
 		"CreationTime",
 		"Labels",
 		"Rotation",
+		"Replication",
+		"LatestVersion",
+		"CanAccess",
 		"ProjectID",
 		// Add more fields as necessary
 	}
 
 	var body [][]string
 	for _, value := range g.Data {
+		latestVersion := value.LatestVersionTime
+		if latestVersion == "" {
+			latestVersion = "Unknown"
+		}
 		body = append(body, []string{
-			value.Name,
+			value.ShortName(),
 			value.CreationTime,
 			fmt.Sprintf("%v", value.Labels),
 			value.Rotation,
+			value.Replication,
+			latestVersion,
+			fmt.Sprintf("%t", value.CanAccessVersions),
 			value.ProjectID,
 		})
 	}
@@ -61,8 +72,27 @@ func (g GCPSecretsResults) TableFiles() []internal.TableFile {
 }
 
 func (g GCPSecretsResults) LootFiles() []internal.LootFile {
-	// Define any specific data considered as loot
-	return []internal.LootFile{}
+	if len(g.Data) == 0 {
+		return []internal.LootFile{}
+	}
+
+	var contents string
+	contents += fmt.Sprintln("# Secrets the caller can access according to testIamPermissions come first.")
+	for _, canAccess := range []bool{true, false} {
+		for _, value := range g.Data {
+			if value.CanAccessVersions != canAccess {
+				continue
+			}
+			contents += fmt.Sprintf("gcloud secrets versions access latest --secret %s --project %s\n", value.ShortName(), value.ProjectID)
+		}
+	}
+
+	return []internal.LootFile{
+		{
+			Name:     globals.GCP_SECRETS_MODULE_NAME + "-commands",
+			Contents: contents,
+		},
+	}
 }
 
 func runGCPSecretsCommand(cmd *cobra.Command, args []string) {
@@ -92,7 +122,6 @@ func runGCPSecretsCommand(cmd *cobra.Command, args []string) {
 	defer client.Close()
 
 	ss := SecretsService.New(client)
-	var results []SecretsService.SecretInfo
 
 	// Set output params from parentCmd
 	verbosity, _ := parentCmd.PersistentFlags().GetInt("verbosity")
@@ -102,12 +131,11 @@ func runGCPSecretsCommand(cmd *cobra.Command, args []string) {
 
 	for _, projectID := range projectIDs {
 		logger.InfoM(fmt.Sprintf("Retrieving all secrets from project: %s", projectID), globals.GCP_SECRETS_MODULE_NAME)
-		result, err := ss.Secrets(projectID)
+		results, err := ss.Secrets(projectID)
 		if err != nil {
 			logger.ErrorM(err.Error(), globals.GCP_SECRETS_MODULE_NAME)
-			return
+			continue
 		}
-		results = append(results, result...)
 		logger.InfoM(fmt.Sprintf("Done retrieving all secrets from project: %s", projectID), globals.GCP_SECRETS_MODULE_NAME)
 		cloudfoxOutput := GCPSecretsResults{Data: results}
 		err = internal.HandleOutput("gcp", format, outputDirectory, verbosity, wrap, globals.GCP_SECRETS_MODULE_NAME, account, projectID, cloudfoxOutput)
@@ -0,0 +1,254 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	admin "cloud.google.com/go/iam/admin/apiv1"
+	IAMService "github.com/BishopFox/cloudfox/gcp/services/iamService"
+	ServiceAccountsService "github.com/BishopFox/cloudfox/gcp/services/serviceAccountsService"
+	"github.com/BishopFox/cloudfox/globals"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/spf13/cobra"
+)
+
+var GCPServiceAccountsCommand = &cobra.Command{
+	Use:     globals.GCP_SERVICE_ACCOUNTS_MODULE_NAME,
+	Aliases: []string{"sa"},
+	Short:   "Display GCP service accounts, their user-managed keys and who can impersonate them",
+	Args:    cobra.MinimumNArgs(0),
+	Long: `
+Display service accounts, their user-managed keys and the bindings that let other principals act as them:
+cloudfox gcp service-accounts
+cloudfox gcp service-accounts --projects project-a,project-b`,
+	Run: runGCPServiceAccountsCommand,
+}
+
+// User-managed keys older than this are flagged, they are long lived credentials that may have been copied around
+const gcpOldServiceAccountKeyAge = 90 * 24 * time.Hour
+
+// GCPServiceAccountsResults struct that implements the internal.OutputInterface
+type GCPServiceAccountsResults struct {
+	Data []ServiceAccountsService.ServiceAccountInfo
+	// ProjectBindings apply to every service account in the project
+	ProjectBindings []ServiceAccountsService.ImpersonationBinding
+	ProjectID       string
+	Now             time.Time
+}
+
+func (g GCPServiceAccountsResults) TableFiles() []internal.TableFile {
+	var tableFiles []internal.TableFile
+
+	header := []string{
+		"Email",
+		"DisplayName",
+		"Disabled",
+		"UserManagedKeys",
+		"OldKeys",
+		"CallerCan",
+		"ProjectID",
+	}
+
+	var body [][]string
+	var keysBody [][]string
+	var bindingsBody [][]string
+	for _, value := range g.Data {
+		keys := fmt.Sprintf("%d", len(value.Keys))
+		if value.KeysError != "" {
+			keys = "Unknown"
+		}
+		var oldKeys int
+		for _, key := range value.Keys {
+			old := key.Age(g.Now) > gcpOldServiceAccountKeyAge
+			if old {
+				oldKeys++
+			}
+			expires := "Never"
+			if !key.Expires.IsZero() {
+				expires = key.Expires.String()
+			}
+			keysBody = append(keysBody, []string{
+				value.Email,
+				key.ID,
+				key.Created.String(),
+				fmt.Sprintf("%d", int(key.Age(g.Now).Hours()/24)),
+				expires,
+				fmt.Sprintf("%t", key.Disabled),
+				fmt.Sprintf("%t", old),
+				value.ProjectID,
+			})
+		}
+		body = append(body, []string{
+			value.Email,
+			value.DisplayName,
+			fmt.Sprintf("%t", value.Disabled),
+			keys,
+			fmt.Sprintf("%d", oldKeys),
+			callerServiceAccountAccess(value),
+			value.ProjectID,
+		})
+
+		for _, binding := range value.Bindings {
+			bindingsBody = append(bindingsBody, []string{value.Email, binding.Member, binding.Role, binding.Level, value.ProjectID})
+		}
+	}
+	for _, binding := range g.ProjectBindings {
+		bindingsBody = append(bindingsBody, []string{"All service accounts", binding.Member, binding.Role, binding.Level, g.ProjectID})
+	}
+
+	tableFiles = append(tableFiles, internal.TableFile{
+		Header: header,
+		Body:   body,
+		Name:   globals.GCP_SERVICE_ACCOUNTS_MODULE_NAME,
+	})
+	if len(keysBody) > 0 {
+		tableFiles = append(tableFiles, internal.TableFile{
+			Header: []string{"Email", "KeyID", "Created", "AgeDays", "Expires", "Disabled", "Old", "ProjectID"},
+			Body:   keysBody,
+			Name:   globals.GCP_SERVICE_ACCOUNTS_MODULE_NAME + "-keys",
+		})
+	}
+	if len(bindingsBody) > 0 {
+		tableFiles = append(tableFiles, internal.TableFile{
+			Header: []string{"ServiceAccount", "Member", "Role", "Level", "ProjectID"},
+			Body:   bindingsBody,
+			Name:   globals.GCP_SERVICE_ACCOUNTS_MODULE_NAME + "-bindings",
+		})
+	}
+
+	return tableFiles
+}
+
+func (g GCPServiceAccountsResults) LootFiles() []internal.LootFile {
+	if len(g.Data) == 0 {
+		return []internal.LootFile{}
+	}
+
+	var keyCommands string
+	keyCommands += fmt.Sprintln("# Service accounts the caller can create keys for according to testIamPermissions come first.")
+	for _, canCreateKeys := range []bool{true, false} {
+		for _, value := range g.Data {
+			if value.CanCreateKeys != canCreateKeys {
+				continue
+			}
+			keyCommands += fmt.Sprintf("gcloud iam service-accounts keys create %s.json --iam-account %s --project %s\n", value.Email, value.Email, value.ProjectID)
+			keyCommands += fmt.Sprintf("gcloud auth activate-service-account %s --key-file %s.json\n", value.Email, value.Email)
+		}
+	}
+	lootFiles := []internal.LootFile{
+		{
+			Name:     globals.GCP_SERVICE_ACCOUNTS_MODULE_NAME + "-keys-commands",
+			Contents: keyCommands,
+		},
+	}
+
+	var impersonationCommands string
+	for _, value := range g.Data {
+		if value.CanGetAccessToken {
+			impersonationCommands += fmt.Sprintf("gcloud auth print-access-token --impersonate-service-account %s\n", value.Email)
+			impersonationCommands += fmt.Sprintf("gcloud projects list --impersonate-service-account %s\n", value.Email)
+		}
+		if value.CanActAs {
+			impersonationCommands += fmt.Sprintf("# actAs on %s lets you run a VM as it and read its token from the metadata server\n", value.Email)
+			impersonationCommands += fmt.Sprintf("gcloud compute instances create cloudfox-%s --service-account %s --scopes cloud-platform --project %s\n", strings.Split(value.Email, "@")[0], value.Email, value.ProjectID)
+		}
+	}
+	if impersonationCommands != "" {
+		lootFiles = append(lootFiles, internal.LootFile{
+			Name:     globals.GCP_SERVICE_ACCOUNTS_MODULE_NAME + "-impersonation-commands",
+			Contents: impersonationCommands,
+		})
+	}
+	return lootFiles
+}
+
+// callerServiceAccountAccess lists the ways the caller can get credentials for the service account
+func callerServiceAccountAccess(serviceAccount ServiceAccountsService.ServiceAccountInfo) string {
+	var access []string
+	if serviceAccount.CanGetAccessToken {
+		access = append(access, "getAccessToken")
+	}
+	if serviceAccount.CanActAs {
+		access = append(access, "actAs")
+	}
+	if serviceAccount.CanCreateKeys {
+		access = append(access, "createKeys")
+	}
+	if len(access) == 0 {
+		return "-"
+	}
+	return strings.Join(access, ", ")
+}
+
+func runGCPServiceAccountsCommand(cmd *cobra.Command, args []string) {
+	var projectIDs []string
+	var account string
+	parentCmd := cmd.Parent()
+	ctx := cmd.Context()
+	logger := internal.NewLogger()
+	if value, ok := ctx.Value("projectIDs").([]string); ok && len(value) > 0 {
+		projectIDs = value
+	} else {
+		logger.ErrorM("Could not retrieve projectIDs from flag value or value is empty", globals.GCP_SERVICE_ACCOUNTS_MODULE_NAME)
+		return
+	}
+
+	if value, ok := ctx.Value("account").(string); ok {
+		account = value
+	} else {
+		logger.ErrorM("Could not retrieve account email from command", globals.GCP_SERVICE_ACCOUNTS_MODULE_NAME)
+	}
+
+	client, err := admin.NewIamClient(ctx)
+	if err != nil {
+		logger.ErrorM(fmt.Sprintf("failed to create IAM admin client: %v", err), globals.GCP_SERVICE_ACCOUNTS_MODULE_NAME)
+		return
+	}
+	defer client.Close()
+
+	sas := ServiceAccountsService.New(client)
+	iamService := IAMService.New()
+
+	// Set output params from parentCmd
+	verbosity, _ := parentCmd.PersistentFlags().GetInt("verbosity")
+	wrap, _ := parentCmd.PersistentFlags().GetBool("wrap")
+	outputDirectory, _ := parentCmd.PersistentFlags().GetString("outdir")
+	format, _ := parentCmd.PersistentFlags().GetString("output")
+
+	for _, projectID := range projectIDs {
+		logger.InfoM(fmt.Sprintf("Retrieving service accounts from project: %s", projectID), globals.GCP_SERVICE_ACCOUNTS_MODULE_NAME)
+		results, err := sas.ServiceAccounts(projectID)
+		if err != nil {
+			logger.ErrorM(err.Error(), globals.GCP_SERVICE_ACCOUNTS_MODULE_NAME)
+			continue
+		}
+
+		// Token creator and service account user granted on the project apply to all of its service accounts
+		var projectBindings []ServiceAccountsService.ImpersonationBinding
+		policyBindings, err := iamService.Policies(projectID, "project")
+		if err != nil {
+			logger.ErrorM(fmt.Sprintf("Could not read the IAM policy of project %s, project level bindings are missing: %v", projectID, err), globals.GCP_SERVICE_ACCOUNTS_MODULE_NAME)
+		} else {
+			bindings := make(map[string][]string)
+			for _, binding := range policyBindings {
+				bindings[binding.Role] = append(bindings[binding.Role], binding.Members...)
+			}
+			projectBindings = ServiceAccountsService.ImpersonationBindings(bindings, ServiceAccountsService.ProjectLevel)
+		}
+		logger.InfoM(fmt.Sprintf("Done retrieving %d service accounts from project: %s", len(results), projectID), globals.GCP_SERVICE_ACCOUNTS_MODULE_NAME)
+
+		cloudfoxOutput := GCPServiceAccountsResults{
+			Data:            results,
+			ProjectBindings: projectBindings,
+			ProjectID:       projectID,
+			Now:             time.Now(),
+		}
+		err = internal.HandleOutput("gcp", format, outputDirectory, verbosity, wrap, globals.GCP_SERVICE_ACCOUNTS_MODULE_NAME, account, projectID, cloudfoxOutput)
+		if err != nil {
+			logger.ErrorM(err.Error(), globals.GCP_SERVICE_ACCOUNTS_MODULE_NAME)
+			return
+		}
+		logger.InfoM(fmt.Sprintf("Done writing output for project %s", projectID), globals.GCP_SERVICE_ACCOUNTS_MODULE_NAME)
+	}
+}
@@ -3,7 +3,9 @@ package cloudstorageservice
 import (
 	"context"
 	"fmt"
+	"sort"
 
+	"cloud.google.com/go/iam"
 	"cloud.google.com/go/storage"
 	"google.golang.org/api/iterator"
 )
@@ -23,9 +25,64 @@ func New() *CloudStorageService {
 // }
 
 type BucketInfo struct {
-	Name      string `json:"name"`
-	Location  string `json:"location"`
-	ProjectID string `json:"projectID"`
+	Name                     string `json:"name"`
+	Location                 string `json:"location"`
+	ProjectID                string `json:"projectID"`
+	UniformBucketLevelAccess bool   `json:"uniformBucketLevelAccess"`
+	PublicAccessPrevention   string `json:"publicAccessPrevention"`
+	// PublicBindings lists the roles granted to allUsers or allAuthenticatedUsers, e.g. allUsers:roles/storage.objectViewer
+	PublicBindings []string `json:"publicBindings,omitempty"`
+	PublicRead     bool     `json:"publicRead"`
+	PublicWrite    bool     `json:"publicWrite"`
+	// IAMPolicyError is set when the bucket's IAM policy couldn't be read, so nothing is known about public access
+	IAMPolicyError string `json:"iamPolicyError,omitempty"`
+}
+
+// Roles that let their members read objects or write them. Basic roles only apply to buckets through the legacy
+// convenience values, but are listed for completeness.
+var (
+	publicReadRoles = map[string]bool{
+		"roles/storage.objectViewer":       true,
+		"roles/storage.objectUser":         true,
+		"roles/storage.objectAdmin":        true,
+		"roles/storage.admin":              true,
+		"roles/storage.legacyObjectReader": true,
+		"roles/storage.legacyObjectOwner":  true,
+		"roles/storage.legacyBucketReader": true,
+		"roles/storage.legacyBucketWriter": true,
+		"roles/storage.legacyBucketOwner":  true,
+		"roles/viewer":                     true,
+		"roles/editor":                     true,
+		"roles/owner":                      true,
+	}
+	publicWriteRoles = map[string]bool{
+		"roles/storage.objectCreator":      true,
+		"roles/storage.objectUser":         true,
+		"roles/storage.objectAdmin":        true,
+		"roles/storage.admin":              true,
+		"roles/storage.legacyObjectOwner":  true,
+		"roles/storage.legacyBucketWriter": true,
+		"roles/storage.legacyBucketOwner":  true,
+		"roles/editor":                     true,
+		"roles/owner":                      true,
+	}
+)
+
+// PublicAccess returns the bindings of a bucket IAM policy, given as role to members, that grant something to
+// allUsers or allAuthenticatedUsers, and whether they make the bucket publicly readable or writable
+func PublicAccess(bindings map[string][]string) (publicBindings []string, read bool, write bool) {
+	for role, members := range bindings {
+		for _, member := range members {
+			if member != "allUsers" && member != "allAuthenticatedUsers" {
+				continue
+			}
+			publicBindings = append(publicBindings, fmt.Sprintf("%s:%s", member, role))
+			read = read || publicReadRoles[role]
+			write = write || publicWriteRoles[role]
+		}
+	}
+	sort.Strings(publicBindings)
+	return publicBindings, read, write
 }
 
 func (cs *CloudStorageService) Buckets(projectID string) ([]BucketInfo, error) {
@@ -46,12 +103,32 @@ func (cs *CloudStorageService) Buckets(projectID string) ([]BucketInfo, error) {
 		if err != nil {
 			return nil, err
 		}
-		bucket := BucketInfo{Name: battrs.Name, Location: battrs.Location, ProjectID: projectID}
+		bucket := BucketInfo{
+			Name:                     battrs.Name,
+			Location:                 battrs.Location,
+			ProjectID:                projectID,
+			UniformBucketLevelAccess: battrs.UniformBucketLevelAccess.Enabled,
+			PublicAccessPrevention:   battrs.PublicAccessPrevention.String(),
+		}
+		policy, err := client.Bucket(battrs.Name).IAM().Policy(ctx)
+		if err != nil {
+			bucket.IAMPolicyError = err.Error()
+		} else {
+			bucket.PublicBindings, bucket.PublicRead, bucket.PublicWrite = PublicAccess(policyBindings(policy))
+		}
 		buckets = append(buckets, bucket)
 	}
 	return buckets, nil
 }
 
+func policyBindings(policy *iam.Policy) map[string][]string {
+	bindings := make(map[string][]string)
+	for _, role := range policy.Roles() {
+		bindings[string(role)] = policy.Members(role)
+	}
+	return bindings
+}
+
 // func (cs *CloudStorageService) BucketsWithMetaData(projectID string) (map[string][]BucketInfo, error) {
 // 	buckets, _ := cs.Buckets(projectID)
 // 	bucketInfos := make(map[string][]BucketInfo)
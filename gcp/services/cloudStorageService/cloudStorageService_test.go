@@ -1 +1,63 @@
 package cloudstorageservice_test
+
+import (
+	"reflect"
+	"testing"
+
+	cloudstorageservice "github.com/BishopFox/cloudfox/gcp/services/cloudStorageService"
+)
+
+func TestPublicAccess(t *testing.T) {
+	tests := []struct {
+		name         string
+		bindings     map[string][]string
+		wantBindings []string
+		wantRead     bool
+		wantWrite    bool
+	}{
+		{
+			name: "Private bucket",
+			bindings: map[string][]string{
+				"roles/storage.objectViewer": {"user:alice@example.com"},
+				"roles/storage.admin":        {"projectOwner:my-project"},
+			},
+		},
+		{
+			name: "Publicly readable bucket",
+			bindings: map[string][]string{
+				"roles/storage.objectViewer": {"allUsers", "user:alice@example.com"},
+			},
+			wantBindings: []string{"allUsers:roles/storage.objectViewer"},
+			wantRead:     true,
+		},
+		{
+			name: "Writable by any Google account",
+			bindings: map[string][]string{
+				"roles/storage.objectCreator":      {"allAuthenticatedUsers"},
+				"roles/storage.legacyBucketReader": {"allAuthenticatedUsers"},
+			},
+			wantBindings: []string{"allAuthenticatedUsers:roles/storage.legacyBucketReader", "allAuthenticatedUsers:roles/storage.objectCreator"},
+			wantRead:     true,
+			wantWrite:    true,
+		},
+		{
+			name: "Custom role granted to allUsers",
+			bindings: map[string][]string{
+				"projects/my-project/roles/bucketLister": {"allUsers"},
+			},
+			wantBindings: []string{"allUsers:projects/my-project/roles/bucketLister"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bindings, read, write := cloudstorageservice.PublicAccess(tt.bindings)
+			if !reflect.DeepEqual(bindings, tt.wantBindings) {
+				t.Errorf("PublicAccess() bindings = %v, want %v", bindings, tt.wantBindings)
+			}
+			if read != tt.wantRead || write != tt.wantWrite {
+				t.Errorf("PublicAccess() read = %t, write = %t, want %t and %t", read, write, tt.wantRead, tt.wantWrite)
+			}
+		})
+	}
+}
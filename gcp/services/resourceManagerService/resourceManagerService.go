@@ -0,0 +1,44 @@
+package resourcemanagerservice
+
+import (
+	"context"
+	"fmt"
+
+	resourcemanager "cloud.google.com/go/resourcemanager/apiv3"
+	resourcemanagerpb "cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	"google.golang.org/api/iterator"
+)
+
+type ResourceManagerService struct {
+}
+
+func New() *ResourceManagerService {
+	return &ResourceManagerService{}
+}
+
+// ProjectIDs returns the IDs of all active projects the application default credentials can see
+func (s *ResourceManagerService) ProjectIDs() ([]string, error) {
+	ctx := context.Background()
+	client, err := resourcemanager.NewProjectsClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("resourcemanager.NewProjectsClient: %v", err)
+	}
+	defer client.Close()
+
+	var projectIDs []string
+	it := client.SearchProjects(ctx, &resourcemanagerpb.SearchProjectsRequest{})
+	for {
+		project, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to search projects: %v", err)
+		}
+		if project.State != resourcemanagerpb.Project_ACTIVE {
+			continue
+		}
+		projectIDs = append(projectIDs, project.ProjectId)
+	}
+	return projectIDs, nil
+}
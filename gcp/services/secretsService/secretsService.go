@@ -3,7 +3,10 @@ package secretservice
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
+	iampb "cloud.google.com/go/iam/apiv1/iampb"
 	secretmanager "cloud.google.com/go/secretmanager/apiv1"
 	secretmanagerpb "cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
 	"github.com/googleapis/gax-go/v2"
@@ -15,9 +18,15 @@ type Iterator interface {
 	Next() (*secretmanagerpb.Secret, error)
 }
 
+type VersionIterator interface {
+	Next() (*secretmanagerpb.SecretVersion, error)
+}
+
 type SecretsManagerClientWrapper struct {
-	Closer       func() error
-	SecretLister func(ctx context.Context, req *secretmanagerpb.ListSecretsRequest, opts ...gax.CallOption) Iterator
+	Closer           func() error
+	SecretLister     func(ctx context.Context, req *secretmanagerpb.ListSecretsRequest, opts ...gax.CallOption) Iterator
+	VersionLister    func(ctx context.Context, req *secretmanagerpb.ListSecretVersionsRequest, opts ...gax.CallOption) VersionIterator
+	PermissionTester func(ctx context.Context, req *iampb.TestIamPermissionsRequest, opts ...gax.CallOption) (*iampb.TestIamPermissionsResponse, error)
 }
 
 func (w *SecretsManagerClientWrapper) Close() error {
@@ -29,6 +38,14 @@ func (w *SecretsManagerClientWrapper) ListSecrets(ctx context.Context, req *secr
 
 }
 
+func (w *SecretsManagerClientWrapper) ListSecretVersions(ctx context.Context, req *secretmanagerpb.ListSecretVersionsRequest, opts ...gax.CallOption) VersionIterator {
+	return w.VersionLister(ctx, req, opts...)
+}
+
+func (w *SecretsManagerClientWrapper) TestIamPermissions(ctx context.Context, req *iampb.TestIamPermissionsRequest, opts ...gax.CallOption) (*iampb.TestIamPermissionsResponse, error) {
+	return w.PermissionTester(ctx, req, opts...)
+}
+
 type SecretsService struct {
 	Client *SecretsManagerClientWrapper
 }
@@ -41,17 +58,32 @@ func New(client *secretmanager.Client) SecretsService {
 			SecretLister: func(ctx context.Context, req *secretmanagerpb.ListSecretsRequest, opts ...gax.CallOption) Iterator {
 				return client.ListSecrets(ctx, req, opts...)
 			},
+			VersionLister: func(ctx context.Context, req *secretmanagerpb.ListSecretVersionsRequest, opts ...gax.CallOption) VersionIterator {
+				return client.ListSecretVersions(ctx, req, opts...)
+			},
+			PermissionTester: client.TestIamPermissions,
 		},
 	}
 	return ss
 }
 
+// accessVersionsPermission is what `gcloud secrets versions access` needs
+const accessVersionsPermission = "secretmanager.versions.access"
+
 type SecretInfo struct {
-	Name         string            `json:"name"`
-	ProjectID    string            `json:"projectID"`
-	CreationTime string            `json:"creationTime"`
-	Labels       map[string]string `json:"labels"`
-	Rotation     string            `json:"rotation,omitempty"`
+	Name              string            `json:"name"`
+	ProjectID         string            `json:"projectID"`
+	CreationTime      string            `json:"creationTime"`
+	Labels            map[string]string `json:"labels"`
+	Rotation          string            `json:"rotation,omitempty"`
+	Replication       string            `json:"replication"`
+	LatestVersionTime string            `json:"latestVersionTime,omitempty"`
+	CanAccessVersions bool              `json:"canAccessVersions"`
+}
+
+// ShortName returns the secret name without the projects/<project>/secrets/ prefix, which is what gcloud expects
+func (s SecretInfo) ShortName() string {
+	return s.Name[strings.LastIndex(s.Name, "/")+1:]
 }
 
 func (ss *SecretsService) Secrets(projectID string) ([]SecretInfo, error) {
@@ -71,13 +103,76 @@ func (ss *SecretsService) Secrets(projectID string) ([]SecretInfo, error) {
 			return nil, fmt.Errorf("failed to list secrets: %v", err)
 		}
 
-		secrets = append(secrets, SecretInfo{
+		secret := SecretInfo{
 			Name:         resp.Name,
 			ProjectID:    projectID,
 			CreationTime: resp.CreateTime.AsTime().String(),
 			Labels:       resp.Labels,
 			Rotation:     resp.Rotation.String(),
-		})
+			Replication:  replicationPolicy(resp.Replication),
+		}
+		// Failing to look at the versions of one secret shouldn't hide all the others, so these errors only leave
+		// the corresponding fields empty
+		if latest, err := ss.latestVersionTime(ctx, resp.Name); err == nil && !latest.IsZero() {
+			secret.LatestVersionTime = latest.String()
+		}
+		if canAccess, err := ss.canAccessVersions(ctx, resp.Name); err == nil {
+			secret.CanAccessVersions = canAccess
+		}
+		secrets = append(secrets, secret)
 	}
 	return secrets, nil
 }
+
+// replicationPolicy returns "automatic" or the locations of user managed replicas
+func replicationPolicy(replication *secretmanagerpb.Replication) string {
+	if replication.GetAutomatic() != nil {
+		return "automatic"
+	}
+	if userManaged := replication.GetUserManaged(); userManaged != nil {
+		var locations []string
+		for _, replica := range userManaged.GetReplicas() {
+			locations = append(locations, replica.GetLocation())
+		}
+		return fmt.Sprintf("user-managed (%s)", strings.Join(locations, ", "))
+	}
+	return ""
+}
+
+// latestVersionTime returns when the newest version of a secret was created
+func (ss *SecretsService) latestVersionTime(ctx context.Context, secretName string) (time.Time, error) {
+	var latest time.Time
+	it := ss.Client.ListSecretVersions(ctx, &secretmanagerpb.ListSecretVersionsRequest{
+		Parent: secretName,
+	})
+	for {
+		version, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return latest, fmt.Errorf("failed to list versions of %s: %v", secretName, err)
+		}
+		if created := version.CreateTime.AsTime(); created.After(latest) {
+			latest = created
+		}
+	}
+	return latest, nil
+}
+
+// canAccessVersions asks Secret Manager whether the caller is allowed to read the secret's versions
+func (ss *SecretsService) canAccessVersions(ctx context.Context, secretName string) (bool, error) {
+	resp, err := ss.Client.TestIamPermissions(ctx, &iampb.TestIamPermissionsRequest{
+		Resource:    secretName,
+		Permissions: []string{accessVersionsPermission},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to test permissions on %s: %v", secretName, err)
+	}
+	for _, permission := range resp.GetPermissions() {
+		if permission == accessVersionsPermission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
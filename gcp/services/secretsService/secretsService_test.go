@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	iampb "cloud.google.com/go/iam/apiv1/iampb"
 	secretmanagerpb "cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
 	secretservice "github.com/BishopFox/cloudfox/gcp/services/secretsService"
 	"github.com/googleapis/gax-go/v2"
@@ -15,6 +16,9 @@ import (
 
 type mockSecretManagerClient struct {
 	secrets []*secretmanagerpb.Secret
+	// versions and accessible are keyed by secret name
+	versions   map[string][]*secretmanagerpb.SecretVersion
+	accessible map[string]bool
 }
 
 func (m *mockSecretManagerClient) Close() error {
@@ -83,6 +87,33 @@ func (m *mockSecretManagerClient) ListSecrets(ctx context.Context, req *secretma
 	return it
 }
 
+// VersionIterator returns all versions of a secret in a single page
+type VersionIterator struct {
+	versions  []*secretmanagerpb.SecretVersion
+	nextIndex int
+}
+
+func (it *VersionIterator) Next() (*secretmanagerpb.SecretVersion, error) {
+	if it.nextIndex >= len(it.versions) {
+		return nil, iterator.Done
+	}
+	version := it.versions[it.nextIndex]
+	it.nextIndex++
+	return version, nil
+}
+
+func (m *mockSecretManagerClient) ListSecretVersions(ctx context.Context, req *secretmanagerpb.ListSecretVersionsRequest, opts ...gax.CallOption) secretservice.VersionIterator {
+	return &VersionIterator{versions: m.versions[req.Parent]}
+}
+
+// TestIamPermissions grants the requested permissions on secrets marked as accessible
+func (m *mockSecretManagerClient) TestIamPermissions(ctx context.Context, req *iampb.TestIamPermissionsRequest, opts ...gax.CallOption) (*iampb.TestIamPermissionsResponse, error) {
+	if !m.accessible[req.Resource] {
+		return &iampb.TestIamPermissionsResponse{}, nil
+	}
+	return &iampb.TestIamPermissionsResponse{Permissions: req.Permissions}, nil
+}
+
 func TestSecrets(t *testing.T) {
 	mockClient := &mockSecretManagerClient{}
 	ss := secretservice.SecretsService{
@@ -91,15 +122,21 @@ func TestSecrets(t *testing.T) {
 			SecretLister: func(ctx context.Context, req *secretmanagerpb.ListSecretsRequest, opts ...gax.CallOption) secretservice.Iterator {
 				return mockClient.ListSecrets(ctx, req, opts...)
 			},
+			VersionLister: func(ctx context.Context, req *secretmanagerpb.ListSecretVersionsRequest, opts ...gax.CallOption) secretservice.VersionIterator {
+				return mockClient.ListSecretVersions(ctx, req, opts...)
+			},
+			PermissionTester: mockClient.TestIamPermissions,
 		},
 	}
 
 	tests := []struct {
-		name      string
-		projectID string
-		secrets   []*secretmanagerpb.Secret
-		want      []secretservice.SecretInfo
-		wantErr   bool
+		name       string
+		projectID  string
+		secrets    []*secretmanagerpb.Secret
+		versions   map[string][]*secretmanagerpb.SecretVersion
+		accessible map[string]bool
+		want       []secretservice.SecretInfo
+		wantErr    bool
 	}{
 		{
 			name:      "Retrieve secrets successfully",
@@ -121,6 +158,55 @@ func TestSecrets(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:      "Replication, latest version and access",
+			projectID: "my-project",
+			secrets: []*secretmanagerpb.Secret{
+				{
+					Name:       "projects/my-project/secrets/db-password",
+					CreateTime: timestamppb.New(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)),
+					Replication: &secretmanagerpb.Replication{
+						Replication: &secretmanagerpb.Replication_Automatic_{Automatic: &secretmanagerpb.Replication_Automatic{}},
+					},
+				},
+				{
+					Name:       "projects/my-project/secrets/api-key",
+					CreateTime: timestamppb.New(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)),
+					Replication: &secretmanagerpb.Replication{
+						Replication: &secretmanagerpb.Replication_UserManaged_{UserManaged: &secretmanagerpb.Replication_UserManaged{
+							Replicas: []*secretmanagerpb.Replication_UserManaged_Replica{
+								{Location: "europe-west1"},
+								{Location: "us-east1"},
+							},
+						}},
+					},
+				},
+			},
+			versions: map[string][]*secretmanagerpb.SecretVersion{
+				"projects/my-project/secrets/db-password": {
+					{Name: "projects/my-project/secrets/db-password/versions/2", CreateTime: timestamppb.New(time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC))},
+					{Name: "projects/my-project/secrets/db-password/versions/1", CreateTime: timestamppb.New(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))},
+				},
+			},
+			accessible: map[string]bool{"projects/my-project/secrets/db-password": true},
+			want: []secretservice.SecretInfo{
+				{
+					Name:              "projects/my-project/secrets/db-password",
+					ProjectID:         "my-project",
+					CreationTime:      time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).String(),
+					Replication:       "automatic",
+					LatestVersionTime: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC).String(),
+					CanAccessVersions: true,
+				},
+				{
+					Name:         "projects/my-project/secrets/api-key",
+					ProjectID:    "my-project",
+					CreationTime: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).String(),
+					Replication:  "user-managed (europe-west1, us-east1)",
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name:      "No secrets found",
 			projectID: "empty-project",
@@ -133,6 +219,8 @@ func TestSecrets(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient.secrets = tt.secrets
+			mockClient.versions = tt.versions
+			mockClient.accessible = tt.accessible
 			got, err := ss.Secrets(tt.projectID) // Adapt this call to match the actual method signature
 			if (err != nil) != tt.wantErr {
 				t.Errorf("[%s] Secrets() error = %v, wantErr %v", tt.name, err, tt.wantErr)
@@ -151,6 +239,9 @@ func TestSecrets(t *testing.T) {
 				if g.Name != w.Name || !reflect.DeepEqual(g.Labels, w.Labels) || g.CreationTime != w.CreationTime {
 					t.Errorf("Secrets() got = %v, want %v", g, w)
 				}
+				if g.Replication != w.Replication || g.LatestVersionTime != w.LatestVersionTime || g.CanAccessVersions != w.CanAccessVersions {
+					t.Errorf("Secrets() got replication %q, latest version %q and access %t, want %q, %q and %t", g.Replication, g.LatestVersionTime, g.CanAccessVersions, w.Replication, w.LatestVersionTime, w.CanAccessVersions)
+				}
 			}
 		})
 	}
@@ -0,0 +1,251 @@
+package serviceaccountsservice
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/iam"
+	admin "cloud.google.com/go/iam/admin/apiv1"
+	"cloud.google.com/go/iam/admin/apiv1/adminpb"
+	iampb "cloud.google.com/go/iam/apiv1/iampb"
+	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/iterator"
+)
+
+// Wrappers and abstracting types to facilitate mocking the client responses
+type Iterator interface {
+	Next() (*adminpb.ServiceAccount, error)
+}
+
+type IAMAdminClientWrapper struct {
+	Closer               func() error
+	ServiceAccountLister func(ctx context.Context, req *adminpb.ListServiceAccountsRequest, opts ...gax.CallOption) Iterator
+	KeyLister            func(ctx context.Context, req *adminpb.ListServiceAccountKeysRequest, opts ...gax.CallOption) (*adminpb.ListServiceAccountKeysResponse, error)
+	PolicyGetter         func(ctx context.Context, req *iampb.GetIamPolicyRequest) (*iam.Policy, error)
+	PermissionTester     func(ctx context.Context, req *iampb.TestIamPermissionsRequest, opts ...gax.CallOption) (*iampb.TestIamPermissionsResponse, error)
+}
+
+func (w *IAMAdminClientWrapper) Close() error {
+	return w.Closer()
+}
+
+func (w *IAMAdminClientWrapper) ListServiceAccounts(ctx context.Context, req *adminpb.ListServiceAccountsRequest, opts ...gax.CallOption) Iterator {
+	return w.ServiceAccountLister(ctx, req, opts...)
+}
+
+func (w *IAMAdminClientWrapper) ListServiceAccountKeys(ctx context.Context, req *adminpb.ListServiceAccountKeysRequest, opts ...gax.CallOption) (*adminpb.ListServiceAccountKeysResponse, error) {
+	return w.KeyLister(ctx, req, opts...)
+}
+
+func (w *IAMAdminClientWrapper) GetIamPolicy(ctx context.Context, req *iampb.GetIamPolicyRequest) (*iam.Policy, error) {
+	return w.PolicyGetter(ctx, req)
+}
+
+func (w *IAMAdminClientWrapper) TestIamPermissions(ctx context.Context, req *iampb.TestIamPermissionsRequest, opts ...gax.CallOption) (*iampb.TestIamPermissionsResponse, error) {
+	return w.PermissionTester(ctx, req, opts...)
+}
+
+type ServiceAccountsService struct {
+	Client *IAMAdminClientWrapper
+}
+
+// New function to facilitate using the sas client
+func New(client *admin.IamClient) ServiceAccountsService {
+	sas := ServiceAccountsService{
+		Client: &IAMAdminClientWrapper{
+			Closer: client.Close,
+			ServiceAccountLister: func(ctx context.Context, req *adminpb.ListServiceAccountsRequest, opts ...gax.CallOption) Iterator {
+				return client.ListServiceAccounts(ctx, req, opts...)
+			},
+			KeyLister:        client.ListServiceAccountKeys,
+			PolicyGetter:     client.GetIamPolicy,
+			PermissionTester: client.TestIamPermissions,
+		},
+	}
+	return sas
+}
+
+// Roles that let their members act as a service account. The token creator can mint access and ID tokens for it,
+// the service account user can attach it to resources like VMs and Cloud Functions and run code as it.
+const (
+	TokenCreatorRole         = "roles/iam.serviceAccountTokenCreator"
+	ServiceAccountUserRole   = "roles/iam.serviceAccountUser"
+	getAccessTokenPermission = "iam.serviceAccounts.getAccessToken"
+	actAsPermission          = "iam.serviceAccounts.actAs"
+	createKeyPermission      = "iam.serviceAccountKeys.create"
+)
+
+// Where a binding was found. Project level bindings apply to every service account in the project.
+const (
+	ProjectLevel        = "project"
+	ServiceAccountLevel = "service account"
+)
+
+// User-managed keys without an expiry are valid until the end of year 9999
+var neverExpires = time.Date(9999, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+type KeyInfo struct {
+	ID       string    `json:"id"`
+	Created  time.Time `json:"created"`
+	Expires  time.Time `json:"expires,omitempty"`
+	Disabled bool      `json:"disabled"`
+}
+
+// Age returns how long ago the key was created
+func (k KeyInfo) Age(now time.Time) time.Duration {
+	return now.Sub(k.Created)
+}
+
+// ImpersonationBinding grants Member a role that lets it act as the service account
+type ImpersonationBinding struct {
+	Member string `json:"member"`
+	Role   string `json:"role"`
+	Level  string `json:"level"`
+}
+
+type ServiceAccountInfo struct {
+	Email       string                 `json:"email"`
+	DisplayName string                 `json:"displayName"`
+	ProjectID   string                 `json:"projectID"`
+	Disabled    bool                   `json:"disabled"`
+	Keys        []KeyInfo              `json:"keys,omitempty"`
+	Bindings    []ImpersonationBinding `json:"bindings,omitempty"`
+	// What the caller can do with the service account according to testIamPermissions
+	CanGetAccessToken bool `json:"canGetAccessToken"`
+	CanActAs          bool `json:"canActAs"`
+	CanCreateKeys     bool `json:"canCreateKeys"`
+	// Errors reading keys or the IAM policy only leave the corresponding fields empty
+	KeysError   string `json:"keysError,omitempty"`
+	PolicyError string `json:"policyError,omitempty"`
+}
+
+// CanImpersonate tells if the caller can get credentials for the service account in any way
+func (s ServiceAccountInfo) CanImpersonate() bool {
+	return s.CanGetAccessToken || s.CanActAs || s.CanCreateKeys
+}
+
+func (sas *ServiceAccountsService) ServiceAccounts(projectID string) ([]ServiceAccountInfo, error) {
+	var serviceAccounts []ServiceAccountInfo
+	req := &adminpb.ListServiceAccountsRequest{
+		Name: fmt.Sprintf("projects/%s", projectID),
+	}
+
+	ctx := context.Background()
+	it := sas.Client.ListServiceAccounts(ctx, req)
+	for {
+		resp, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list service accounts: %v", err)
+		}
+
+		serviceAccount := ServiceAccountInfo{
+			Email:       resp.Email,
+			DisplayName: resp.DisplayName,
+			ProjectID:   projectID,
+			Disabled:    resp.Disabled,
+		}
+		keys, err := sas.userManagedKeys(ctx, resp.Name)
+		if err != nil {
+			serviceAccount.KeysError = err.Error()
+		}
+		serviceAccount.Keys = keys
+
+		policy, err := sas.Client.GetIamPolicy(ctx, &iampb.GetIamPolicyRequest{Resource: resp.Name})
+		if err != nil {
+			serviceAccount.PolicyError = err.Error()
+		} else {
+			serviceAccount.Bindings = ImpersonationBindings(policyBindings(policy), ServiceAccountLevel)
+		}
+
+		if permissions, err := sas.callerPermissions(ctx, resp.Name); err == nil {
+			serviceAccount.CanGetAccessToken = permissions[getAccessTokenPermission]
+			serviceAccount.CanActAs = permissions[actAsPermission]
+			serviceAccount.CanCreateKeys = permissions[createKeyPermission]
+		}
+		serviceAccounts = append(serviceAccounts, serviceAccount)
+	}
+	return serviceAccounts, nil
+}
+
+// userManagedKeys returns the keys someone created and downloaded. Google managed keys never leave Google and are
+// rotated automatically, so they are left out.
+func (sas *ServiceAccountsService) userManagedKeys(ctx context.Context, serviceAccountName string) ([]KeyInfo, error) {
+	resp, err := sas.Client.ListServiceAccountKeys(ctx, &adminpb.ListServiceAccountKeysRequest{
+		Name:     serviceAccountName,
+		KeyTypes: []adminpb.ListServiceAccountKeysRequest_KeyType{adminpb.ListServiceAccountKeysRequest_USER_MANAGED},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list keys: %v", err)
+	}
+
+	var keys []KeyInfo
+	for _, key := range resp.Keys {
+		keyInfo := KeyInfo{
+			ID:       key.Name[strings.LastIndex(key.Name, "/")+1:],
+			Created:  key.ValidAfterTime.AsTime(),
+			Disabled: key.Disabled,
+		}
+		if expires := key.ValidBeforeTime.AsTime(); key.ValidBeforeTime != nil && expires.Before(neverExpires) {
+			keyInfo.Expires = expires
+		}
+		keys = append(keys, keyInfo)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Created.Before(keys[j].Created)
+	})
+	return keys, nil
+}
+
+func (sas *ServiceAccountsService) callerPermissions(ctx context.Context, serviceAccountName string) (map[string]bool, error) {
+	resp, err := sas.Client.TestIamPermissions(ctx, &iampb.TestIamPermissionsRequest{
+		Resource:    serviceAccountName,
+		Permissions: []string{getAccessTokenPermission, actAsPermission, createKeyPermission},
+	})
+	if err != nil {
+		return nil, err
+	}
+	permissions := make(map[string]bool)
+	for _, permission := range resp.Permissions {
+		permissions[permission] = true
+	}
+	return permissions, nil
+}
+
+// ImpersonationBindings returns the members of an IAM policy, given as role to members, that were granted the token
+// creator or service account user role, sorted by role and member
+func ImpersonationBindings(bindings map[string][]string, level string) []ImpersonationBinding {
+	var impersonationBindings []ImpersonationBinding
+	for role, members := range bindings {
+		if role != TokenCreatorRole && role != ServiceAccountUserRole {
+			continue
+		}
+		for _, member := range members {
+			impersonationBindings = append(impersonationBindings, ImpersonationBinding{
+				Member: member,
+				Role:   role,
+				Level:  level,
+			})
+		}
+	}
+	sort.Slice(impersonationBindings, func(i, j int) bool {
+		if impersonationBindings[i].Role != impersonationBindings[j].Role {
+			return impersonationBindings[i].Role < impersonationBindings[j].Role
+		}
+		return impersonationBindings[i].Member < impersonationBindings[j].Member
+	})
+	return impersonationBindings
+}
+
+func policyBindings(policy *iam.Policy) map[string][]string {
+	bindings := make(map[string][]string)
+	for _, role := range policy.Roles() {
+		bindings[string(role)] = policy.Members(role)
+	}
+	return bindings
+}
@@ -0,0 +1,153 @@
+package serviceaccountsservice_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/iam"
+	"cloud.google.com/go/iam/admin/apiv1/adminpb"
+	iampb "cloud.google.com/go/iam/apiv1/iampb"
+	serviceaccountsservice "github.com/BishopFox/cloudfox/gcp/services/serviceAccountsService"
+	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/iterator"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type mockIAMAdminClient struct {
+	serviceAccounts []*adminpb.ServiceAccount
+	// keys, bindings and permissions are keyed by service account name
+	keys        map[string][]*adminpb.ServiceAccountKey
+	bindings    map[string][]*iampb.Binding
+	permissions map[string][]string
+}
+
+// ServiceAccountIterator returns all service accounts in a single page
+type ServiceAccountIterator struct {
+	serviceAccounts []*adminpb.ServiceAccount
+	nextIndex       int
+}
+
+func (it *ServiceAccountIterator) Next() (*adminpb.ServiceAccount, error) {
+	if it.nextIndex >= len(it.serviceAccounts) {
+		return nil, iterator.Done
+	}
+	serviceAccount := it.serviceAccounts[it.nextIndex]
+	it.nextIndex++
+	return serviceAccount, nil
+}
+
+func (m *mockIAMAdminClient) ListServiceAccounts(ctx context.Context, req *adminpb.ListServiceAccountsRequest, opts ...gax.CallOption) serviceaccountsservice.Iterator {
+	return &ServiceAccountIterator{serviceAccounts: m.serviceAccounts}
+}
+
+func (m *mockIAMAdminClient) ListServiceAccountKeys(ctx context.Context, req *adminpb.ListServiceAccountKeysRequest, opts ...gax.CallOption) (*adminpb.ListServiceAccountKeysResponse, error) {
+	keys, ok := m.keys[req.Name]
+	if !ok {
+		return nil, fmt.Errorf("permission denied on %s", req.Name)
+	}
+	return &adminpb.ListServiceAccountKeysResponse{Keys: keys}, nil
+}
+
+func (m *mockIAMAdminClient) GetIamPolicy(ctx context.Context, req *iampb.GetIamPolicyRequest) (*iam.Policy, error) {
+	return &iam.Policy{InternalProto: &iampb.Policy{Bindings: m.bindings[req.Resource]}}, nil
+}
+
+func (m *mockIAMAdminClient) TestIamPermissions(ctx context.Context, req *iampb.TestIamPermissionsRequest, opts ...gax.CallOption) (*iampb.TestIamPermissionsResponse, error) {
+	return &iampb.TestIamPermissionsResponse{Permissions: m.permissions[req.Resource]}, nil
+}
+
+func TestServiceAccounts(t *testing.T) {
+	deployerName := "projects/my-project/serviceAccounts/deployer@my-project.iam.gserviceaccount.com"
+	ciName := "projects/my-project/serviceAccounts/ci@my-project.iam.gserviceaccount.com"
+	mockClient := &mockIAMAdminClient{
+		serviceAccounts: []*adminpb.ServiceAccount{
+			{Name: deployerName, Email: "deployer@my-project.iam.gserviceaccount.com", DisplayName: "Deployer"},
+			{Name: ciName, Email: "ci@my-project.iam.gserviceaccount.com", Disabled: true},
+		},
+		keys: map[string][]*adminpb.ServiceAccountKey{
+			deployerName: {
+				{
+					Name:            deployerName + "/keys/new",
+					ValidAfterTime:  timestamppb.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+					ValidBeforeTime: timestamppb.New(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)),
+				},
+				{
+					Name:            deployerName + "/keys/old",
+					ValidAfterTime:  timestamppb.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+					ValidBeforeTime: timestamppb.New(time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)),
+				},
+			},
+		},
+		bindings: map[string][]*iampb.Binding{
+			deployerName: {
+				{Role: serviceaccountsservice.TokenCreatorRole, Members: []string{"user:bob@example.com"}},
+				{Role: "roles/iam.serviceAccountViewer", Members: []string{"user:carol@example.com"}},
+			},
+		},
+		permissions: map[string][]string{
+			deployerName: {"iam.serviceAccounts.getAccessToken"},
+		},
+	}
+	sas := serviceaccountsservice.ServiceAccountsService{
+		Client: &serviceaccountsservice.IAMAdminClientWrapper{
+			Closer: func() error { return nil },
+			ServiceAccountLister: func(ctx context.Context, req *adminpb.ListServiceAccountsRequest, opts ...gax.CallOption) serviceaccountsservice.Iterator {
+				return mockClient.ListServiceAccounts(ctx, req, opts...)
+			},
+			KeyLister:        mockClient.ListServiceAccountKeys,
+			PolicyGetter:     mockClient.GetIamPolicy,
+			PermissionTester: mockClient.TestIamPermissions,
+		},
+	}
+
+	got, err := sas.ServiceAccounts("my-project")
+	if err != nil {
+		t.Fatalf("ServiceAccounts() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("ServiceAccounts() got length = %v, want length 2", len(got))
+	}
+
+	deployer := got[0]
+	wantKeys := []serviceaccountsservice.KeyInfo{
+		{ID: "old", Created: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "new", Created: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Expires: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	if !reflect.DeepEqual(deployer.Keys, wantKeys) {
+		t.Errorf("ServiceAccounts() got keys %v, want %v", deployer.Keys, wantKeys)
+	}
+	wantBindings := []serviceaccountsservice.ImpersonationBinding{
+		{Member: "user:bob@example.com", Role: serviceaccountsservice.TokenCreatorRole, Level: serviceaccountsservice.ServiceAccountLevel},
+	}
+	if !reflect.DeepEqual(deployer.Bindings, wantBindings) {
+		t.Errorf("ServiceAccounts() got bindings %v, want %v", deployer.Bindings, wantBindings)
+	}
+	if !deployer.CanGetAccessToken || deployer.CanActAs || deployer.CanCreateKeys {
+		t.Errorf("ServiceAccounts() expected the caller to only get access tokens for %s", deployer.Email)
+	}
+
+	ci := got[1]
+	if !ci.Disabled || ci.KeysError == "" || ci.CanImpersonate() {
+		t.Errorf("ServiceAccounts() expected %s to be disabled, with a keys error and not impersonable, got %v", ci.Email, ci)
+	}
+}
+
+func TestImpersonationBindings(t *testing.T) {
+	bindings := map[string][]string{
+		"roles/owner": {"user:alice@example.com"},
+		serviceaccountsservice.ServiceAccountUserRole: {"group:devs@example.com", "serviceAccount:ci@my-project.iam.gserviceaccount.com"},
+		serviceaccountsservice.TokenCreatorRole:       {"user:bob@example.com"},
+	}
+	want := []serviceaccountsservice.ImpersonationBinding{
+		{Member: "user:bob@example.com", Role: serviceaccountsservice.TokenCreatorRole, Level: serviceaccountsservice.ProjectLevel},
+		{Member: "group:devs@example.com", Role: serviceaccountsservice.ServiceAccountUserRole, Level: serviceaccountsservice.ProjectLevel},
+		{Member: "serviceAccount:ci@my-project.iam.gserviceaccount.com", Role: serviceaccountsservice.ServiceAccountUserRole, Level: serviceaccountsservice.ProjectLevel},
+	}
+	got := serviceaccountsservice.ImpersonationBindings(bindings, serviceaccountsservice.ProjectLevel)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ImpersonationBindings() got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/kyokomi/emoji"
+)
+
+// ErrMaxAPICallsReached is returned instead of sending a request once the --max-api-calls limit is used up
+var ErrMaxAPICallsReached = errors.New("maximum number of API calls reached")
+
+// AWSAPICalls counts the API calls of every client created from a config of AWSConfigFileLoader
+var AWSAPICalls = NewAPICallCounter()
+
+// APICallCounter counts AWS API calls by service and operation. It hooks into the SDK as middleware, so every call
+// is counted once no matter how many times the retryer sends it.
+type APICallCounter struct {
+	mu     sync.Mutex
+	max    int
+	total  int
+	calls  map[string]map[string]int
+	warned bool
+	// expired is set by the first call that failed because the credentials expired
+	expired bool
+}
+
+func NewAPICallCounter() *APICallCounter {
+	return &APICallCounter{calls: map[string]map[string]int{}}
+}
+
+// SetMax makes every call after the first max calls fail with ErrMaxAPICallsReached. 0 means no limit.
+func (c *APICallCounter) SetMax(max int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.max = max
+}
+
+// AddToStack adds the counter to the middleware stack of an operation, use it as an aws.Config APIOption
+func (c *APICallCounter) AddToStack(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("CloudfoxAPICallCounter", c.handleInitialize), middleware.After)
+}
+
+func (c *APICallCounter) handleInitialize(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+	service := awsmiddleware.GetServiceID(ctx)
+	operation := awsmiddleware.GetOperationName(ctx)
+	if !c.count(service, operation) {
+		return middleware.InitializeOutput{}, middleware.Metadata{}, fmt.Errorf("%s:%s: %w", service, operation, ErrMaxAPICallsReached)
+	}
+	out, metadata, err := next.HandleInitialize(ctx, in)
+	if IsExpiredCredentialsError(err) {
+		c.credentialsExpired(service, operation)
+	}
+	return out, metadata, err
+}
+
+// credentialsExpired tells the user once how to continue when the credentials expire in the middle of a run
+func (c *APICallCounter) credentialsExpired(service string, operation string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.expired {
+		return
+	}
+	c.expired = true
+	fmt.Printf("[%s] The credentials expired during %s:%s. Refresh them (for example with aws sso login) and re-run the same command with --resume to pick up where this run stopped.\n", cyan(emoji.Sprintf(":fox:cloudfox :fox:")), service, operation)
+	TxtLog.Warnf("Credentials expired during %s:%s", service, operation)
+}
+
+// count records a call and reports whether it may be sent
+func (c *APICallCounter) count(service string, operation string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.max > 0 && c.total >= c.max {
+		if !c.warned {
+			c.warned = true
+			fmt.Printf("[%s] Reached the limit of %d API calls, all further calls are skipped.\n", cyan(emoji.Sprintf(":fox:cloudfox :fox:")), c.max)
+			TxtLog.Warnf("Reached the limit of %d API calls, skipping %s:%s and all further calls", c.max, service, operation)
+		}
+		return false
+	}
+	c.total++
+	if c.calls[service] == nil {
+		c.calls[service] = map[string]int{}
+	}
+	c.calls[service][operation]++
+	return true
+}
+
+// Total is the number of calls sent so far
+func (c *APICallCounter) Total() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.total
+}
+
+// LimitReached reports whether the --max-api-calls limit is used up
+func (c *APICallCounter) LimitReached() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.max > 0 && c.total >= c.max
+}
+
+// CredentialsExpired reports whether a call failed because the credentials expired
+func (c *APICallCounter) CredentialsExpired() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.expired
+}
+
+// Snapshot returns a copy of the calls by service and operation
+func (c *APICallCounter) Snapshot() map[string]map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snapshot := map[string]map[string]int{}
+	for service, operations := range c.calls {
+		snapshot[service] = map[string]int{}
+		for operation, count := range operations {
+			snapshot[service][operation] = count
+		}
+	}
+	return snapshot
+}
+
+// DiffAPICalls returns the calls made between two snapshots
+func DiffAPICalls(before map[string]map[string]int, after map[string]map[string]int) map[string]map[string]int {
+	diff := map[string]map[string]int{}
+	for service, operations := range after {
+		for operation, count := range operations {
+			if delta := count - before[service][operation]; delta > 0 {
+				if diff[service] == nil {
+					diff[service] = map[string]int{}
+				}
+				diff[service][operation] = delta
+			}
+		}
+	}
+	return diff
+}
+
+// SumAPICalls is the total number of calls in a snapshot
+func SumAPICalls(calls map[string]map[string]int) int {
+	var total int
+	for _, operations := range calls {
+		for _, count := range operations {
+			total += count
+		}
+	}
+	return total
+}
@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"testing"
+)
+
+func TestAPICallCounter(t *testing.T) {
+	counter := NewAPICallCounter()
+	counter.SetMax(3)
+
+	for _, call := range [][2]string{{"S3", "ListBuckets"}, {"S3", "GetBucketPolicy"}, {"S3", "GetBucketPolicy"}, {"IAM", "ListRoles"}} {
+		counter.count(call[0], call[1])
+	}
+
+	if counter.Total() != 3 {
+		t.Errorf("Expected 3 calls, got %d", counter.Total())
+	}
+	if !counter.LimitReached() {
+		t.Error("Expected the limit to be reached")
+	}
+	snapshot := counter.Snapshot()
+	if snapshot["S3"]["GetBucketPolicy"] != 2 {
+		t.Errorf("Expected 2 GetBucketPolicy calls, got %d", snapshot["S3"]["GetBucketPolicy"])
+	}
+	if _, ok := snapshot["IAM"]; ok {
+		t.Error("Expected the IAM call over the limit to be skipped")
+	}
+
+	before := map[string]map[string]int{"S3": {"ListBuckets": 1}}
+	diff := DiffAPICalls(before, snapshot)
+	if _, ok := diff["S3"]["ListBuckets"]; ok || diff["S3"]["GetBucketPolicy"] != 2 || SumAPICalls(diff) != 2 {
+		t.Errorf("Unexpected diff %v", diff)
+	}
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"errors"
+
+	"github.com/aws/smithy-go"
+)
+
+// AWSErrorClass is what kind of failure an AWS API error is, independent of the service that returned it
+type AWSErrorClass int
+
+const (
+	AWSErrorNone AWSErrorClass = iota
+	// AWSErrorAccessDenied means the caller lacks the permission for the call, the results it would have returned are
+	// unknown rather than empty
+	AWSErrorAccessDenied
+	AWSErrorExpiredCredentials
+	AWSErrorThrottled
+	AWSErrorOther
+)
+
+// accessDeniedErrorCodes are the codes the services use for calls the caller has no permission for
+var accessDeniedErrorCodes = []string{
+	"AccessDenied",
+	"AccessDeniedException",
+	"AuthorizationError",
+	"AuthorizationErrorException",
+	"Forbidden",
+	"ForbiddenException",
+	"InsufficientPermissionsException",
+	"UnauthorizedAccess",
+	"UnauthorizedException",
+	"UnauthorizedOperation",
+}
+
+var throttlingErrorCodes = []string{
+	"RequestLimitExceeded",
+	"SlowDown",
+	"Throttling",
+	"ThrottlingException",
+	"TooManyRequestsException",
+}
+
+// ClassifyAWSError tells apart the errors a run has to handle differently: missing permissions, expired credentials
+// and throttling. Everything else is AWSErrorOther.
+func ClassifyAWSError(err error) AWSErrorClass {
+	if err == nil {
+		return AWSErrorNone
+	}
+	if IsExpiredCredentialsError(err) {
+		return AWSErrorExpiredCredentials
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case Contains(apiErr.ErrorCode(), accessDeniedErrorCodes):
+			return AWSErrorAccessDenied
+		case Contains(apiErr.ErrorCode(), throttlingErrorCodes):
+			return AWSErrorThrottled
+		}
+	}
+	return AWSErrorOther
+}
+
+// IsAccessDeniedError tells if err means the caller lacks the permission for the call
+func IsAccessDeniedError(err error) bool {
+	return ClassifyAWSError(err) == AWSErrorAccessDenied
+}
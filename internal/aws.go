@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -160,6 +161,21 @@ func AWSConfigFileLoader(AWSProfile string, version string, AwsMfaToken string)
 			//os.Exit(1)
 		}
 
+		// Inside an EKS pod with IRSA the service account token beats every other credential source, including a profile
+		if provider, ok := irsaCredentialsProvider(cfg); ok {
+			fmt.Printf("[%s][%s] Found IRSA environment variables, assuming %s with the pod's service account token.\n", cyan(emoji.Sprintf(":fox:cloudfox v%s :fox:", version)), cyan(AWSProfile), os.Getenv(irsaRoleARNEnvVar))
+			cfg.Credentials = provider
+		}
+
+		// Count every API call of the clients built from this config for the run manifest and --max-api-calls
+		cfg.APIOptions = append(cfg.APIOptions, AWSAPICalls.AddToStack)
+		// record the calls the profile was denied for the Denied APIs report at the end of the run
+		cfg.APIOptions = append(cfg.APIOptions, AWSDeniedAPIs.APIOption(AWSProfile))
+		// renew the credentials when they expire mid-run, inside the counter so a call sent again is counted once
+		cfg.APIOptions = append(cfg.APIOptions, NewCredentialRefresher(AWSProfile, cfg.Credentials).AddToStack)
+		// and limit them for --requests-per-second
+		cfg.APIOptions = append(cfg.APIOptions, AWSRateLimiter.AddToStack)
+
 		_, err := cfg.Credentials.Retrieve(context.TODO())
 
 		if err != nil {
@@ -182,6 +198,34 @@ func AWSConfigFileLoader(AWSProfile string, version string, AwsMfaToken string)
 	return cfg
 }
 
+// Environment variables the EKS pod identity webhook injects into pods whose service account is annotated with an IAM
+// role (IRSA, IAM Roles for Service Accounts)
+const (
+	irsaTokenFileEnvVar       = "AWS_WEB_IDENTITY_TOKEN_FILE"
+	irsaRoleARNEnvVar         = "AWS_ROLE_ARN"
+	irsaRoleSessionNameEnvVar = "AWS_ROLE_SESSION_NAME"
+	irsaDefaultSessionName    = "cloudfox"
+)
+
+// irsaCredentialsProvider returns a provider that calls AssumeRoleWithWebIdentity with the projected service account
+// token, or false when cloudfox isn't running in a pod with IRSA. The token file is read again on every refresh
+// because the kubelet rotates it.
+func irsaCredentialsProvider(cfg aws.Config) (aws.CredentialsProvider, bool) {
+	tokenFile := os.Getenv(irsaTokenFileEnvVar)
+	roleARN := os.Getenv(irsaRoleARNEnvVar)
+	if tokenFile == "" || roleARN == "" {
+		return nil, false
+	}
+	sessionName := os.Getenv(irsaRoleSessionNameEnvVar)
+	if sessionName == "" {
+		sessionName = irsaDefaultSessionName
+	}
+	provider := stscreds.NewWebIdentityRoleProvider(sts.NewFromConfig(cfg), roleARN, stscreds.IdentityTokenFile(tokenFile), func(o *stscreds.WebIdentityRoleOptions) {
+		o.RoleSessionName = sessionName
+	})
+	return aws.NewCredentialsCache(provider), true
+}
+
 func AWSWhoami(awsProfile string, version string, AwsMfaToken string) (*sts.GetCallerIdentityOutput, error) {
 
 	cacheKey := fmt.Sprintf("sts-getCallerIdentity-%s", awsProfile)
@@ -208,11 +252,14 @@ func AWSWhoami(awsProfile string, version string, AwsMfaToken string) (*sts.GetC
 	return CallerIdentity, err
 }
 
+// ExcludedRegions are left out of the regions GetEnabledRegions returns, for --exclude-regions
+var ExcludedRegions []string
+
 func GetEnabledRegions(awsProfile string, version string, AwsMfaToken string) []string {
 	cacheKey := fmt.Sprintf("GetEnabledRegions-%s", awsProfile)
 	cached, found := Cache.Get(cacheKey)
 	if found {
-		return cached.([]string)
+		return withoutExcludedRegions(cached.([]string))
 	}
 
 	var enabledRegions []string
@@ -232,15 +279,48 @@ func GetEnabledRegions(awsProfile string, version string, AwsMfaToken string) []
 		if err != nil {
 			TxtLog.Println(err)
 		}
-		return AWSRegions
+		return withoutExcludedRegions(AWSRegions)
 	}
 
 	for _, region := range regions.Regions {
 		enabledRegions = append(enabledRegions, *region.RegionName)
 	}
 	Cache.Set(cacheKey, enabledRegions, cache.DefaultExpiration)
-	return enabledRegions
+	return withoutExcludedRegions(enabledRegions)
+
+}
+
+// withoutExcludedRegions filters a copy, the cached list keeps every enabled region
+func withoutExcludedRegions(regions []string) []string {
+	if len(ExcludedRegions) == 0 {
+		return regions
+	}
+	var kept []string
+	for _, region := range regions {
+		if !Contains(region, ExcludedRegions) {
+			kept = append(kept, region)
+		}
+	}
+	return kept
+}
 
+// UnknownRegions returns the names that aren't AWS regions. It returns nothing if the list of regions can't be loaded.
+func UnknownRegions(names []string) []string {
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	allRegions, err := servicemap.GetAllRegions()
+	if err != nil {
+		TxtLog.Println(err)
+		return nil
+	}
+	var unknown []string
+	for _, name := range names {
+		if !Contains(name, allRegions) {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
 }
 
 // txtLogger - Returns the txt logger
@@ -354,6 +434,27 @@ func GetSelectedAWSProfiles(AWSProfilesListPath string) []string {
 	return AWSProfiles
 }
 
+// GetSecretNames reads the newline separated secret names for the secrets module's --secret-names-file flag
+func GetSecretNames(secretNamesPath string) []string {
+	secretNamesFile, err := UtilsFs.Open(secretNamesPath)
+	CheckErr(err, fmt.Sprintf("could not open given file %s", secretNamesPath))
+	if err != nil {
+		fmt.Printf("\nError loading secret names. Could not open file at location[%s]\n", secretNamesPath)
+		os.Exit(1)
+	}
+	defer secretNamesFile.Close()
+	var secretNames []string
+	scanner := bufio.NewScanner(secretNamesFile)
+	scanner.Split(bufio.ScanLines)
+	for scanner.Scan() {
+		name := strings.TrimSpace(scanner.Text())
+		if len(name) != 0 {
+			secretNames = append(secretNames, name)
+		}
+	}
+	return secretNames
+}
+
 func removeBadPathChars(receivedPath *string) string {
 	var path string
 	var bannedPathChars *regexp.Regexp = regexp.MustCompile(`[<>:"'|?*]`)
@@ -379,22 +480,110 @@ type CommandCounter struct {
 	Complete  int
 	Error     int
 	Executing int
+	// Duration tracks how long each unit of work (usually a region) took to complete
+	Duration map[string]time.Duration
+}
+
+// durationMutex protects the Duration map since regions are checked concurrently
+var durationMutex sync.Mutex
+
+// RecordDuration stores the elapsed time for a unit of work, like a region's executeChecks call
+func (c *CommandCounter) RecordDuration(key string, elapsed time.Duration) {
+	durationMutex.Lock()
+	defer durationMutex.Unlock()
+	if c.Duration == nil {
+		c.Duration = make(map[string]time.Duration)
+	}
+	c.Duration[key] = elapsed
+}
+
+// SlowestAndFastest returns the keys with the longest and shortest recorded durations
+func (c *CommandCounter) SlowestAndFastest() (string, string) {
+	durationMutex.Lock()
+	defer durationMutex.Unlock()
+	var slowest, fastest string
+	for key, elapsed := range c.Duration {
+		if slowest == "" || elapsed > c.Duration[slowest] || (elapsed == c.Duration[slowest] && key < slowest) {
+			slowest = key
+		}
+		if fastest == "" || elapsed < c.Duration[fastest] || (elapsed == c.Duration[fastest] && key < fastest) {
+			fastest = key
+		}
+	}
+	return slowest, fastest
+}
+
+// spinnerMutex keeps anything printed while the spinner is running from interleaving with the status line
+var spinnerMutex sync.Mutex
+
+// PrintWhileSpinning clears the spinner's status line and prints the message in its place. The spinner redraws
+// itself on its next tick, so this is safe to call from receivers that want to stream results as they arrive.
+func PrintWhileSpinning(format string, a ...interface{}) {
+	spinnerMutex.Lock()
+	defer spinnerMutex.Unlock()
+	fmt.Printf(clearln+format, a...)
+}
+
+// PrintStreamRow prints a result row for modules that stream their rows to stdout as they arrive. It shares the lock
+// with the status line, so a row never ends up in the middle of one.
+func PrintStreamRow(format string, a ...interface{}) {
+	spinnerMutex.Lock()
+	defer spinnerMutex.Unlock()
+	fmt.Printf(format, a...)
+}
+
+// PrintStatusToStderr prints a status line on a line of its own on stderr. Modules that stream rows to stdout use it
+// instead of redrawing the status line in place, which would mangle the rows with carriage returns.
+func PrintStatusToStderr(format string, a ...interface{}) {
+	spinnerMutex.Lock()
+	defer spinnerMutex.Unlock()
+	fmt.Fprintf(os.Stderr, format+"\n", a...)
 }
 
 func SpinUntil(callingModuleName string, counter *CommandCounter, done chan bool, spinType string) {
 	defer close(done)
+	sentAtStart := AWSRateLimiter.Sent()
 	for {
 		select {
 		case <-time.After(1 * time.Second):
-			fmt.Printf(clearln+"[%s] Status: %d/%d %s complete (%d errors -- For details check %s)", cyan(callingModuleName), counter.Complete, counter.Total, spinType, counter.Error, fmt.Sprintf("%s/cloudfox-error.log", ptr.ToString(GetLogDirPath())))
+			spinnerMutex.Lock()
+			fmt.Printf(clearln+"[%s] Status: %s%d/%d %s complete (%d errors -- For details check %s)%s", cyan(callingModuleName), spinnerProgress(spinType, counter.Complete, counter.Total), counter.Complete, counter.Total, spinType, counter.Error, fmt.Sprintf("%s/cloudfox-error.log", ptr.ToString(GetLogDirPath())), spinnerETA(counter, sentAtStart))
+			spinnerMutex.Unlock()
 		case <-done:
-			fmt.Printf(clearln+"[%s] Status: %d/%d %s complete (%d errors -- For details check %s)\n", cyan(callingModuleName), counter.Complete, counter.Complete, spinType, counter.Error, fmt.Sprintf("%s/cloudfox-error.log", ptr.ToString(GetLogDirPath())))
+			spinnerMutex.Lock()
+			fmt.Printf(clearln+"[%s] Status: %s%d/%d %s complete (%d errors -- For details check %s)\n", cyan(callingModuleName), spinnerProgress(spinType, counter.Complete, counter.Complete), counter.Complete, counter.Complete, spinType, counter.Error, fmt.Sprintf("%s/cloudfox-error.log", ptr.ToString(GetLogDirPath())))
+			spinnerMutex.Unlock()
 			done <- true
 			return
 		}
 	}
 }
 
+const progressBarWidth = 20
+
+// spinnerProgress returns the progress bar shown in front of the status. Modules that spin on regions know how many
+// regions they check up front, so their percentage is meaningful. Task counts keep growing while the module runs,
+// so they don't get a bar.
+func spinnerProgress(spinType string, complete int, total int) string {
+	if spinType != "regions" {
+		return ""
+	}
+	return ProgressBar(complete, total, progressBarWidth) + " "
+}
+
+// ProgressBar renders complete out of total as an ASCII bar followed by the percentage, e.g. [#####---------------]  25%
+func ProgressBar(complete int, total int, width int) string {
+	var percent int
+	if total > 0 {
+		percent = complete * 100 / total
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	filled := percent * width / 100
+	return fmt.Sprintf("[%s%s] %3d%%", strings.Repeat("#", filled), strings.Repeat("-", width-filled), percent)
+}
+
 func ReorganizeAWSProfiles(allProfiles []string, mgmtProfile string) []string {
 	// take the mgmt profile, move it from its current position to the front of the list
 	var newProfiles []string
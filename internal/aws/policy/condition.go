@@ -30,6 +30,7 @@ func (psc *PolicyStatementCondition) IsScopedOnAccountOrOrganization() bool {
 				"aws:principalaccount", // https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_policies_condition-keys.html#condition-keys-principalaccount
 				"aws:principalorgid",   // https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_policies_condition-keys.html#condition-keys-principalorgid
 				"sns:endpoint",         // https://docs.aws.amazon.com/sns/latest/dg/sns-using-identity-based-policies.html#sns-policy-keys
+				"kms:calleraccount",    // https://docs.aws.amazon.com/kms/latest/developerguide/conditions-kms.html#conditions-kms-caller-account
 			}, strings.ToLower(k)) {
 				return len(v) > 0
 			}
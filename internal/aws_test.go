@@ -2,8 +2,11 @@ package internal
 
 import (
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/spf13/afero"
 )
@@ -75,3 +78,96 @@ func TestGetSelectedAWSProfiles(t *testing.T) {
 		}
 	}
 }
+
+func TestCommandCounterDuration(t *testing.T) {
+	var counter CommandCounter
+	wg := new(sync.WaitGroup)
+	durations := map[string]time.Duration{
+		"us-east-1":    3 * time.Second,
+		"us-west-2":    1 * time.Second,
+		"eu-central-1": 2 * time.Second,
+	}
+	for region, elapsed := range durations {
+		wg.Add(1)
+		go func(region string, elapsed time.Duration) {
+			defer wg.Done()
+			counter.RecordDuration(region, elapsed)
+		}(region, elapsed)
+	}
+	wg.Wait()
+
+	slowest, fastest := counter.SlowestAndFastest()
+	if slowest != "us-east-1" {
+		t.Errorf("Expected us-east-1 to be the slowest region, got %s", slowest)
+	}
+	if fastest != "us-west-2" {
+		t.Errorf("Expected us-west-2 to be the fastest region, got %s", fastest)
+	}
+}
+
+func TestGetEnabledRegionsExcludedRegions(t *testing.T) {
+	Cache.Set("GetEnabledRegions-exclude-regions", []string{"us-east-1", "ap-east-1", "eu-west-1"}, 0)
+	ExcludedRegions = []string{"ap-east-1", "eu-west-1"}
+	defer func() { ExcludedRegions = nil }()
+
+	regions := GetEnabledRegions("exclude-regions", "test", "")
+	if !compareSlice(regions, []string{"us-east-1"}) {
+		t.Errorf("Expected only us-east-1, got %v", regions)
+	}
+
+	// The cached list still has every enabled region
+	ExcludedRegions = nil
+	regions = GetEnabledRegions("exclude-regions", "test", "")
+	if !compareSlice(regions, []string{"us-east-1", "ap-east-1", "eu-west-1"}) {
+		t.Errorf("Expected all regions without exclusions, got %v", regions)
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	tests := []struct {
+		complete int
+		total    int
+		expected string
+	}{
+		{0, 0, "[----------]   0%"},
+		{0, 17, "[----------]   0%"},
+		{4, 16, "[##--------]  25%"},
+		{13, 17, "[#######---]  76%"},
+		{17, 17, "[##########] 100%"},
+		{18, 17, "[##########] 100%"},
+	}
+	for _, test := range tests {
+		if bar := ProgressBar(test.complete, test.total, 10); bar != test.expected {
+			t.Errorf("Expected %q for %d/%d, got %q", test.expected, test.complete, test.total, bar)
+		}
+	}
+	if progress := spinnerProgress("tasks", 3, 10); progress != "" {
+		t.Errorf("Did not expect a progress bar for tasks, got %q", progress)
+	}
+}
+
+func TestIRSACredentialsProvider(t *testing.T) {
+	var tests = []struct {
+		tokenFile string
+		roleARN   string
+		expected  bool
+		caseName  string
+	}{
+		{"", "", false, "no IRSA environment variables"},
+		{"/var/run/secrets/eks.amazonaws.com/serviceaccount/token", "", false, "token file without a role"},
+		{"/var/run/secrets/eks.amazonaws.com/serviceaccount/token", "arn:aws:iam::123456789012:role/cloudfox-scanner", true, "IRSA pod"},
+	}
+
+	for _, test := range tests {
+		fmt.Printf("[*] Testing %s\n", test.caseName)
+		t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", test.tokenFile)
+		t.Setenv("AWS_ROLE_ARN", test.roleARN)
+		provider, ok := irsaCredentialsProvider(aws.Config{Region: "us-east-1"})
+		if ok != test.expected {
+			t.Errorf("Test Failed: expected IRSA to be detected to be %t, got %t", test.expected, ok)
+		}
+		if ok && provider == nil {
+			t.Errorf("Test Failed: expected a credentials provider for %s", test.roleARN)
+		}
+	}
+}
@@ -1,25 +1,51 @@
 package internal
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"log"
+	"os"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/profiles/latest/authorization/mgmt/authorization"
 	"github.com/Azure/azure-sdk-for-go/profiles/latest/compute/mgmt/compute"
+	"github.com/Azure/azure-sdk-for-go/profiles/latest/keyvault/keyvault"
+	keyvaultmgmt "github.com/Azure/azure-sdk-for-go/profiles/latest/keyvault/mgmt/keyvault"
 	"github.com/Azure/azure-sdk-for-go/profiles/latest/network/mgmt/network"
 	"github.com/Azure/azure-sdk-for-go/profiles/latest/resources/mgmt/resources"
 	"github.com/Azure/azure-sdk-for-go/profiles/latest/resources/mgmt/subscriptions"
 	"github.com/Azure/azure-sdk-for-go/profiles/latest/storage/mgmt/storage"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-sdk-for-go/services/graphrbac/1.6/graphrbac"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
+	"github.com/Azure/go-autorest/autorest/azure/cli"
 	"github.com/BishopFox/cloudfox/globals"
 )
 
+// AzureServicePrincipalFromEnvironment reports whether service principal credentials are set in the environment. When
+// they are, they take precedence over the az cli session.
+func AzureServicePrincipalFromEnvironment() bool {
+	return os.Getenv(auth.ClientID) != "" && os.Getenv(auth.ClientSecret) != "" && os.Getenv(auth.TenantID) != ""
+}
+
 func getAuthorizer(endpoint string) (autorest.Authorizer, error) {
+	if AzureServicePrincipalFromEnvironment() {
+		settings, err := auth.GetSettingsFromEnvironment()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get client authorizer: %s", err)
+		}
+		settings.Values[auth.Resource] = endpoint
+		authorizer, err := settings.GetAuthorizer()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get client authorizer: %s", err)
+		}
+		return authorizer, nil
+	}
 	auth, err := auth.NewAuthorizerFromCLIWithResource(endpoint)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client authorizer: %s", err)
@@ -27,6 +53,91 @@ func getAuthorizer(endpoint string) (autorest.Authorizer, error) {
 	return auth, nil
 }
 
+// AzureIdentity is the principal cloudfox is authenticated as
+type AzureIdentity struct {
+	Name     string
+	ObjectID string
+	TenantID string
+	Type     string
+	// Where the credentials came from: the az cli or service principal environment variables
+	Source string
+}
+
+type azureAccessTokenClaims struct {
+	ObjectID   string `json:"oid"`
+	TenantID   string `json:"tid"`
+	UPN        string `json:"upn"`
+	UniqueName string `json:"unique_name"`
+	AppID      string `json:"appid"`
+}
+
+// GetAzureIdentity works out who the caller is from the claims of a resource manager access token, the same way the
+// AWS modules use sts:GetCallerIdentity
+func GetAzureIdentity() (AzureIdentity, error) {
+	var identity AzureIdentity
+	var accessToken string
+	if AzureServicePrincipalFromEnvironment() {
+		identity.Source = "service principal environment variables"
+		settings, err := auth.GetSettingsFromEnvironment()
+		if err != nil {
+			return identity, fmt.Errorf("failed to read service principal credentials: %s", err)
+		}
+		credentials, err := settings.GetClientCredentials()
+		if err != nil {
+			return identity, fmt.Errorf("failed to read service principal credentials: %s", err)
+		}
+		token, err := credentials.ServicePrincipalToken()
+		if err != nil {
+			return identity, fmt.Errorf("failed to get service principal token: %s", err)
+		}
+		err = token.Refresh()
+		if err != nil {
+			return identity, fmt.Errorf("failed to get service principal token: %s", err)
+		}
+		accessToken = token.OAuthToken()
+	} else {
+		identity.Source = "az cli"
+		token, err := cli.GetTokenFromCLI(globals.AZ_RESOURCE_MANAGER_ENDPOINT)
+		if err != nil {
+			return identity, fmt.Errorf("failed to get token from az cli: %s", err)
+		}
+		accessToken = token.AccessToken
+	}
+
+	claims, err := parseAzureAccessTokenClaims(accessToken)
+	if err != nil {
+		return identity, err
+	}
+	identity.ObjectID = claims.ObjectID
+	identity.TenantID = claims.TenantID
+	switch {
+	case claims.UPN != "":
+		identity.Name, identity.Type = claims.UPN, "User"
+	case claims.UniqueName != "":
+		identity.Name, identity.Type = claims.UniqueName, "User"
+	default:
+		identity.Name, identity.Type = claims.AppID, "ServicePrincipal"
+	}
+	return identity, nil
+}
+
+func parseAzureAccessTokenClaims(accessToken string) (azureAccessTokenClaims, error) {
+	var claims azureAccessTokenClaims
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return claims, fmt.Errorf("access token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return claims, fmt.Errorf("could not decode access token: %s", err)
+	}
+	err = json.Unmarshal(payload, &claims)
+	if err != nil {
+		return claims, fmt.Errorf("could not parse access token claims: %s", err)
+	}
+	return claims, nil
+}
+
 func GetTenantsClient() subscriptions.TenantsClient {
 	client := subscriptions.NewTenantsClient()
 	a, err := getAuthorizer(globals.AZ_RESOURCE_MANAGER_ENDPOINT)
@@ -82,6 +193,17 @@ func GetAADUsersClient(tenantID string) graphrbac.UsersClient {
 	return client
 }
 
+func GetAADObjectsClient(tenantID string) graphrbac.ObjectsClient {
+	client := graphrbac.NewObjectsClient(tenantID)
+	a, err := getAuthorizer(globals.AZ_GRAPH_ENDPOINT)
+	if err != nil {
+		log.Fatalf("failed to get azure active directory objects client: %s", err)
+	}
+	client.Authorizer = a
+	client.AddToUserAgent(globals.CLOUDFOX_USER_AGENT)
+	return client
+}
+
 func GetRoleAssignmentsClient(subscriptionID string) authorization.RoleAssignmentsClient {
 	client := authorization.NewRoleAssignmentsClient(subscriptionID)
 	a, err := getAuthorizer(globals.AZ_RESOURCE_MANAGER_ENDPOINT)
@@ -115,6 +237,17 @@ func GetVirtualMachinesClient(subscriptionID string) compute.VirtualMachinesClie
 	return client
 }
 
+func GetVirtualMachineScaleSetsClient(subscriptionID string) compute.VirtualMachineScaleSetsClient {
+	client := compute.NewVirtualMachineScaleSetsClient(subscriptionID)
+	authorizer, err := getAuthorizer(globals.AZ_RESOURCE_MANAGER_ENDPOINT)
+	if err != nil {
+		log.Fatalf("failed to get compute client: %s", err)
+	}
+	client.Authorizer = authorizer
+	client.AddToUserAgent(globals.CLOUDFOX_USER_AGENT)
+	return client
+}
+
 func GetNICClient(subscriptionID string) network.InterfacesClient {
 	client := network.NewInterfacesClient(subscriptionID)
 	authorizer, err := getAuthorizer(globals.AZ_RESOURCE_MANAGER_ENDPOINT)
@@ -136,6 +269,29 @@ func GetPublicIPClient(subscriptionID string) network.PublicIPAddressesClient {
 	return client
 }
 
+func GetKeyVaultsClient(subscriptionID string) keyvaultmgmt.VaultsClient {
+	client := keyvaultmgmt.NewVaultsClient(subscriptionID)
+	a, err := getAuthorizer(globals.AZ_RESOURCE_MANAGER_ENDPOINT)
+	if err != nil {
+		log.Fatalf("failed to get key vaults client: %s", err)
+	}
+	client.Authorizer = a
+	client.AddToUserAgent(globals.CLOUDFOX_USER_AGENT)
+	return client
+}
+
+// GetKeyVaultDataClient returns a client for the data plane of key vaults, which lists secrets, keys and certificates
+func GetKeyVaultDataClient() (keyvault.BaseClient, error) {
+	client := keyvault.New()
+	a, err := getAuthorizer(globals.AZ_KEY_VAULT_ENDPOINT)
+	if err != nil {
+		return client, err
+	}
+	client.Authorizer = a
+	client.AddToUserAgent(globals.CLOUDFOX_USER_AGENT)
+	return client, nil
+}
+
 func GetStorageClient(subscriptionID string) storage.AccountsClient {
 	client := storage.NewAccountsClient(subscriptionID)
 	a, err := getAuthorizer(globals.AZ_RESOURCE_MANAGER_ENDPOINT)
@@ -149,9 +305,15 @@ func GetStorageClient(subscriptionID string) storage.AccountsClient {
 
 func GetStorageAccountBlobClient(tenantID, storageAccountName string) (*azblob.Client, error) {
 	serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", storageAccountName)
-	cred, err := azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{TenantID: tenantID})
+	var cred azcore.TokenCredential
+	var err error
+	if AzureServicePrincipalFromEnvironment() {
+		cred, err = azidentity.NewEnvironmentCredential(nil)
+	} else {
+		cred, err = azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{TenantID: tenantID})
+	}
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
 	client, err := azblob.NewClient(serviceURL, cred, nil)
 	if err != nil {
@@ -0,0 +1,215 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/smithy-go"
+)
+
+const CheckpointDirectoryName = "checkpoints"
+
+// Checkpoint records the checks of a module that completed, one JSON line per region and check with the rows the
+// check found. A run that dies halfway (laptop sleep, expired SSO token) can then be resumed with --resume: the
+// completed checks are skipped and their rows are merged with the new ones before the output is written.
+//
+// All methods can be called on a nil Checkpoint, which never has anything completed and records nothing.
+type Checkpoint struct {
+	path      string
+	mu        sync.Mutex
+	completed map[string]CheckpointEntry
+	// order keeps the rows in the order their checks completed
+	order []string
+	// flags is recorded with every check, see MatchFlags
+	flags string
+}
+
+type CheckpointEntry struct {
+	Region string          `json:"region"`
+	Check  string          `json:"check"`
+	Time   time.Time       `json:"time"`
+	Flags  string          `json:"flags,omitempty"`
+	Rows   json.RawMessage `json:"rows,omitempty"`
+}
+
+// OpenCheckpoint opens the checkpoint of module in directory. With resume, the checks that completed less than maxAge
+// ago are loaded and older ones are ignored, 0 never ignores any. Without resume, the previous checkpoint is
+// discarded and the run starts over.
+func OpenCheckpoint(directory string, module string, resume bool, maxAge time.Duration) (*Checkpoint, error) {
+	c := &Checkpoint{
+		path:      filepath.Join(directory, CheckpointDirectoryName, module+".jsonl"),
+		completed: make(map[string]CheckpointEntry),
+	}
+	if err := fileSystem.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return nil, err
+	}
+	if !resume {
+		if err := fileSystem.Remove(c.path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		return c, nil
+	}
+
+	file, err := fileSystem.Open(c.path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	// Lines hold all rows of a check, which can be far more than the default 64KB
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var entry CheckpointEntry
+		// The last line is cut short if the run died while writing it
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if maxAge > 0 && time.Since(entry.Time) > maxAge {
+			continue
+		}
+		key := checkpointKey(entry.Region, entry.Check)
+		if _, ok := c.completed[key]; !ok {
+			c.order = append(c.order, key)
+		}
+		c.completed[key] = entry
+	}
+	return c, scanner.Err()
+}
+
+func checkpointKey(region string, check string) string {
+	return region + "|" + check
+}
+
+// Completed reports whether the run being resumed already completed check in region
+func (c *Checkpoint) Completed(region string, check string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.completed[checkpointKey(region, check)]
+	return ok
+}
+
+// MatchFlags drops the loaded checks that ran with other flags than flags, a fingerprint of the flags that change what
+// the checks find, and records flags with the checks completed from now on. It returns how many checks were dropped.
+func (c *Checkpoint) MatchFlags(flags string) int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flags = flags
+	var order []string
+	for _, key := range c.order {
+		if c.completed[key].Flags != flags {
+			delete(c.completed, key)
+			continue
+		}
+		order = append(order, key)
+	}
+	dropped := len(c.order) - len(order)
+	c.order = order
+	return dropped
+}
+
+// Resumed is the number of checks loaded from the run being resumed
+func (c *Checkpoint) Resumed() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.completed)
+}
+
+// Complete records that check completed in region and found rows. The line is appended in one write, so an interrupted
+// run leaves either the whole check or nothing of it.
+func (c *Checkpoint) Complete(region string, check string, rows interface{}) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := CheckpointEntry{Region: region, Check: check, Time: time.Now(), Flags: c.flags}
+	if rows != nil {
+		encoded, err := json.Marshal(rows)
+		if err != nil {
+			return err
+		}
+		entry.Rows = encoded
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := fileSystem.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	key := checkpointKey(region, check)
+	if _, ok := c.completed[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.completed[key] = entry
+	return nil
+}
+
+// CheckpointRows returns the rows of all completed checks, decoded as T
+func CheckpointRows[T any](c *Checkpoint) ([]T, error) {
+	if c == nil {
+		return nil, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var rows []T
+	for _, key := range c.order {
+		entry := c.completed[key]
+		if len(entry.Rows) == 0 {
+			continue
+		}
+		var entryRows []T
+		if err := json.Unmarshal(entry.Rows, &entryRows); err != nil {
+			return nil, fmt.Errorf("checkpoint %s of %s in %s: %w", c.path, entry.Check, entry.Region, err)
+		}
+		rows = append(rows, entryRows...)
+	}
+	return rows, nil
+}
+
+// expiredCredentialsErrorCodes are the error codes of calls signed with credentials that expired
+var expiredCredentialsErrorCodes = []string{
+	"ExpiredToken",
+	"ExpiredTokenException",
+	"TokenRefreshRequired",
+}
+
+// IsExpiredCredentialsError tells if err means the credentials expired, as opposed to them lacking permissions. SSO
+// token expiry fails before the call is sent, so it has no error code and is recognized by its message.
+func IsExpiredCredentialsError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && Contains(apiErr.ErrorCode(), expiredCredentialsErrorCodes) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "sso session has expired") ||
+		strings.Contains(msg, "cached sso token") ||
+		strings.Contains(msg, "token has expired")
+}
@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/spf13/afero"
+)
+
+type checkpointRow struct {
+	Name string
+}
+
+func TestCheckpoint(t *testing.T) {
+	fs := MockFileSystem(true)
+	defer MockFileSystem(false)
+
+	c, err := OpenCheckpoint("cloudfox-output", "secrets", false, time.Hour)
+	if err != nil {
+		t.Fatalf("Cannot open checkpoint: %s", err)
+	}
+	if err := c.Complete("us-east-1", "secretsmanager", []checkpointRow{{Name: "secret1"}, {Name: "secret2"}}); err != nil {
+		t.Fatalf("Cannot complete check: %s", err)
+	}
+	if err := c.Complete("us-east-1", "ssm", nil); err != nil {
+		t.Fatalf("Cannot complete check: %s", err)
+	}
+
+	// A check from an old run, and a check the run died while recording
+	path := "cloudfox-output/checkpoints/secrets.jsonl"
+	file, err := fs.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatalf("Cannot open checkpoint file: %s", err)
+	}
+	fmt.Fprintf(file, `{"region":"us-west-2","check":"ssm","time":"%s","rows":[{"Name":"stale"}]}`+"\n", time.Now().Add(-2*time.Hour).Format(time.RFC3339))
+	fmt.Fprint(file, `{"region":"us-west-2","check":"secretsmanager","rows":[{"Na`)
+	file.Close()
+
+	resumed, err := OpenCheckpoint("cloudfox-output", "secrets", true, time.Hour)
+	if err != nil {
+		t.Fatalf("Cannot resume checkpoint: %s", err)
+	}
+	if resumed.Resumed() != 2 || !resumed.Completed("us-east-1", "secretsmanager") || !resumed.Completed("us-east-1", "ssm") {
+		t.Errorf("Expected both us-east-1 checks to be completed")
+	}
+	if resumed.Completed("us-west-2", "ssm") || resumed.Completed("us-west-2", "secretsmanager") {
+		t.Errorf("Expected stale and cut short checks to be run again")
+	}
+	rows, err := CheckpointRows[checkpointRow](resumed)
+	if err != nil || len(rows) != 2 || rows[0].Name != "secret1" || rows[1].Name != "secret2" {
+		t.Errorf("Expected the rows of the completed checks, got %v, error %v", rows, err)
+	}
+
+	if _, err := OpenCheckpoint("cloudfox-output", "secrets", false, time.Hour); err != nil {
+		t.Fatalf("Cannot open checkpoint: %s", err)
+	}
+	if exists, _ := afero.Exists(fs, path); exists {
+		t.Errorf("Expected a run without --resume to start over")
+	}
+
+	var nilCheckpoint *Checkpoint
+	if nilCheckpoint.Completed("us-east-1", "ssm") || nilCheckpoint.Complete("us-east-1", "ssm", nil) != nil {
+		t.Errorf("Expected a nil checkpoint to record nothing")
+	}
+}
+
+func TestCheckpointMatchFlags(t *testing.T) {
+	MockFileSystem(true)
+	defer MockFileSystem(false)
+
+	c, err := OpenCheckpoint("cloudfox-output", "secrets", false, time.Hour)
+	if err != nil {
+		t.Fatalf("Cannot open checkpoint: %s", err)
+	}
+	c.MatchFlags("since=2024-01-01")
+	if err := c.Complete("us-east-1", "secretsmanager", []checkpointRow{{Name: "secret1"}}); err != nil {
+		t.Fatalf("Cannot complete check: %s", err)
+	}
+
+	resumed, err := OpenCheckpoint("cloudfox-output", "secrets", true, time.Hour)
+	if err != nil {
+		t.Fatalf("Cannot resume checkpoint: %s", err)
+	}
+	if dropped := resumed.MatchFlags("since=2024-01-01"); dropped != 0 || !resumed.Completed("us-east-1", "secretsmanager") {
+		t.Errorf("Expected the check of a run with the same flags to be kept, %d dropped", dropped)
+	}
+	if dropped := resumed.MatchFlags("since=2025-01-01"); dropped != 1 || resumed.Resumed() != 0 {
+		t.Errorf("Expected the check of a run with other flags to be dropped, %d dropped", dropped)
+	}
+	if rows, _ := CheckpointRows[checkpointRow](resumed); len(rows) != 0 {
+		t.Errorf("Expected no rows from a run with other flags, got %v", rows)
+	}
+}
+
+func TestIsExpiredCredentialsError(t *testing.T) {
+	subtests := map[error]bool{
+		&smithy.GenericAPIError{Code: "ExpiredToken", Message: "The security token included in the request is expired"}: true,
+		&smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized"}:                               false,
+		errors.New("failed to refresh cached credentials, the SSO session has expired or is invalid"):                   true,
+		errors.New("dial tcp: i/o timeout"): false,
+		nil:                                 false,
+	}
+	for err, expected := range subtests {
+		if IsExpiredCredentialsError(err) != expected {
+			t.Errorf("Expected %v to be an expired credentials error: %t", err, expected)
+		}
+	}
+}
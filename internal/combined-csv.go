@@ -0,0 +1,168 @@
+package internal
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CombinedCSVOutput makes OutputClient.WriteFullOutput also merge every table into cloudfox-output/aws/combined/<name>.csv,
+// one file per table across all profiles, for --combined-csv
+var CombinedCSVOutput bool
+
+const (
+	CombinedCSVDirectoryName = "combined"
+	combinedCSVProfileColumn = "AWSProfile"
+	combinedCSVAccountColumn = "AccountID"
+	// Runs wait this long for another run to finish its merge before giving up on the combined file
+	combinedCSVLockTimeout = 30 * time.Second
+	// A lock file older than this was left behind by a run that died in the middle of a merge
+	combinedCSVStaleLock = 2 * time.Minute
+)
+
+// combinedCSVMutex keeps the modules of one run from waiting on each other's lock files
+var combinedCSVMutex sync.Mutex
+
+// writeCombinedCSVFiles merges the tables into the combined directory under baseDirectory, the --outdir of the run
+func (b *TableClient) writeCombinedCSVFiles(profile string, accountID string, baseDirectory string) []string {
+	var fullFilePaths []string
+	directory := filepath.Join(baseDirectory, "cloudfox-output", "aws", CombinedCSVDirectoryName)
+	for _, file := range b.TableFiles {
+		var body [][]string
+		for _, row := range file.Body {
+			body = append(body, removeColorCodesFromSlice(row))
+		}
+		fullPath, err := WriteCombinedCSV(directory, file.Name, profile, accountID, removeColorCodesFromSlice(file.Header), body)
+		if err != nil {
+			TxtLog.Errorf("Cannot update the combined CSV file of %s: %s", file.Name, err)
+			continue
+		}
+		fullFilePaths = append(fullFilePaths, fullPath)
+	}
+	return fullFilePaths
+}
+
+// WriteCombinedCSV replaces the rows of profile and accountID in directory/<name>.csv with body. Other runs may do the
+// same at the same time, so the file is only changed under a lock file, and the merged rows are written to a temporary
+// file that replaces the combined file in one rename.
+func WriteCombinedCSV(directory string, name string, profile string, accountID string, header []string, body [][]string) (string, error) {
+	combinedCSVMutex.Lock()
+	defer combinedCSVMutex.Unlock()
+
+	if err := fileSystem.MkdirAll(directory, 0700); err != nil {
+		return "", err
+	}
+	csvPath := filepath.Join(directory, fmt.Sprintf("%s.csv", name))
+	unlock, err := lockCombinedCSV(csvPath + ".lock")
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	var existing [][]string
+	if file, err := fileSystem.Open(csvPath); err == nil {
+		reader := csv.NewReader(file)
+		// Rows written before a column was added are shorter than the header
+		reader.FieldsPerRecord = -1
+		existing, err = reader.ReadAll()
+		file.Close()
+		if err != nil {
+			return "", fmt.Errorf("cannot read %s: %s", csvPath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	merged := mergeCombinedCSV(existing, profile, accountID, header, body)
+
+	tmpPath := csvPath + ".tmp"
+	tmpFile, err := fileSystem.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", err
+	}
+	csvWriter := csv.NewWriter(tmpFile)
+	csvWriter.WriteAll(merged)
+	tmpFile.Close()
+	if err := csvWriter.Error(); err != nil {
+		fileSystem.Remove(tmpPath)
+		return "", err
+	}
+	if err := fileSystem.Rename(tmpPath, csvPath); err != nil {
+		return "", err
+	}
+	return csvPath, nil
+}
+
+// mergeCombinedCSV drops the rows of profile and accountID from existing, the combined file's records with its header
+// first, and adds body in their place. The header is the module's full column list behind AWSProfile and AccountID, so
+// it doesn't depend on which profile ran first. Rows already in the file are moved to the columns of that header, and
+// columns the module no longer has are dropped.
+func mergeCombinedCSV(existing [][]string, profile string, accountID string, header []string, body [][]string) [][]string {
+	combinedHeader := append([]string{combinedCSVProfileColumn, combinedCSVAccountColumn}, header...)
+	var existingHeader []string
+	if len(existing) > 0 {
+		existingHeader = existing[0]
+	}
+
+	columnIndex := make(map[string]int)
+	for i, column := range combinedHeader {
+		columnIndex[column] = i
+	}
+	reorder := func(columns []string, row []string) []string {
+		out := make([]string, len(combinedHeader))
+		for i, value := range row {
+			if i >= len(columns) {
+				break
+			}
+			if index, ok := columnIndex[columns[i]]; ok {
+				out[index] = value
+			}
+		}
+		return out
+	}
+
+	merged := [][]string{combinedHeader}
+	if len(existing) > 0 {
+		for _, row := range existing[1:] {
+			row = reorder(existingHeader, row)
+			if row[columnIndex[combinedCSVProfileColumn]] == profile && row[columnIndex[combinedCSVAccountColumn]] == accountID {
+				continue
+			}
+			merged = append(merged, row)
+		}
+	}
+	for _, row := range body {
+		merged = append(merged, reorder(combinedHeader, append([]string{profile, accountID}, row...)))
+	}
+	return merged
+}
+
+// lockCombinedCSV creates the lock file, waiting for other runs that hold it. The returned function removes it again.
+func lockCombinedCSV(lockPath string) (func(), error) {
+	deadline := time.Now().Add(combinedCSVLockTimeout)
+	for {
+		file, err := fileSystem.OpenFile(lockPath, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			fmt.Fprintf(file, "%d\n", os.Getpid())
+			file.Close()
+			return func() {
+				fileSystem.Remove(lockPath)
+			}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := fileSystem.Stat(lockPath); err == nil && time.Since(info.ModTime()) > combinedCSVStaleLock {
+			fileSystem.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for the lock %s", lockPath)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
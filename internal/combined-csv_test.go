@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestMergeCombinedCSV(t *testing.T) {
+	existing := [][]string{
+		{"AWSProfile", "AccountID", "Name", "Region"},
+		{"dev", "111111111111", "old-secret", "us-east-1"},
+		{"prod", "222222222222", "prod-secret", "us-east-1"},
+	}
+	// The new run of dev has an extra column and its columns in another order, the header follows the new run
+	merged := mergeCombinedCSV(existing, "dev", "111111111111", []string{"Region", "Name", "LastModified"}, [][]string{
+		{"eu-west-1", "new-secret", "2024-01-01"},
+	})
+	expected := [][]string{
+		{"AWSProfile", "AccountID", "Region", "Name", "LastModified"},
+		{"prod", "222222222222", "us-east-1", "prod-secret", ""},
+		{"dev", "111111111111", "eu-west-1", "new-secret", "2024-01-01"},
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("Expected %v, got %v", expected, merged)
+	}
+}
+
+func TestWriteCombinedCSVFiles(t *testing.T) {
+	fs := MockFileSystem(true)
+	defer MockFileSystem(false)
+	CombinedCSVOutput = true
+	defer func() { CombinedCSVOutput = false }()
+
+	// Every profile writes twice, the second run has to replace the rows of the first
+	profiles := []string{"dev", "prod", "staging", "sandbox"}
+	wg := new(sync.WaitGroup)
+	for i, profile := range profiles {
+		wg.Add(1)
+		go func(i int, profile string) {
+			defer wg.Done()
+			for run := 1; run <= 2; run++ {
+				o := OutputClient{
+					Verbosity:        1,
+					CallingModule:    "secrets",
+					PrefixIdentifier: profile,
+					AccountID:        fmt.Sprint(100000000000 + i),
+					BaseDirectory:    "out",
+				}
+				o.Table.DirectoryName = filepath.Join("out", "cloudfox-output", "aws", fmt.Sprintf("%s-%s", profile, o.AccountID))
+				o.Table.TableFiles = []TableFile{{
+					Name:   "secrets",
+					Header: []string{"Name", "Run"},
+					Body:   [][]string{{profile + "-secret", fmt.Sprint(run)}},
+				}}
+				o.WriteFullOutput(o.Table.TableFiles, nil)
+			}
+		}(i, profile)
+	}
+	wg.Wait()
+
+	file, err := fs.Open(filepath.Join("out", "cloudfox-output", "aws", CombinedCSVDirectoryName, "secrets.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(file).ReadAll()
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(records[0], []string{"AWSProfile", "AccountID", "Name", "Run"}) {
+		t.Errorf("Unexpected header %v", records[0])
+	}
+	if len(records) != len(profiles)+1 {
+		t.Fatalf("Expected one row per profile, got %v", records[1:])
+	}
+	for _, record := range records[1:] {
+		if record[2] != record[0]+"-secret" || record[3] != "2" || !strings.HasPrefix(record[1], "10000000000") {
+			t.Errorf("Unexpected row %v", record)
+		}
+	}
+	if exists, _ := afero.Exists(fs, filepath.Join("out", "cloudfox-output", "aws", CombinedCSVDirectoryName, "secrets.csv.lock")); exists {
+		t.Errorf("Expected the lock file to be removed")
+	}
+}
@@ -0,0 +1,146 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/kyokomi/emoji"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// CredentialRefresher renews the credentials of a profile when they expire in the middle of a run, so long runs with
+// SSO or credential_process profiles don't fail every call after the expiry. It sits in the middleware stack of every
+// client built from the profile's config, so the modules don't know about it.
+//
+// A call that fails with expired credentials holds back all other calls of the profile while the credentials are
+// refreshed, then it is sent again. If the credential source can't refresh on its own, for example because the SSO
+// session itself expired, the user is asked to log in again and the run waits for them.
+type CredentialRefresher struct {
+	profile     string
+	credentials aws.CredentialsProvider
+	// prompt asks the user to renew the credentials and reports whether they did. It is nil when nobody can answer.
+	prompt func(profile string, err error) bool
+
+	// mu is held during a refresh, calls wait for it before they are sent
+	mu sync.Mutex
+	// generation counts the refreshes, a call that failed with credentials of an older generation just retries
+	generation int
+	gaveUp     bool
+}
+
+func NewCredentialRefresher(profile string, credentials aws.CredentialsProvider) *CredentialRefresher {
+	r := &CredentialRefresher{
+		profile:     profile,
+		credentials: credentials,
+	}
+	if terminal.IsTerminal(int(os.Stdin.Fd())) {
+		r.prompt = promptCredentialRefresh
+	}
+	return r
+}
+
+// AddToStack adds the refresher to the middleware stack of an operation, use it as an aws.Config APIOption. It wraps
+// the whole request, so a retried call resolves its credentials and is signed again.
+func (r *CredentialRefresher) AddToStack(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("CloudfoxCredentialRefresher", r.handleInitialize), middleware.After)
+}
+
+func (r *CredentialRefresher) handleInitialize(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+	generation := r.wait()
+	out, metadata, err := next.HandleInitialize(ctx, in)
+	retried := false
+	for isRefreshableCredentialsError(err) {
+		var ok bool
+		if generation, ok = r.refresh(ctx, generation, retried); !ok {
+			break
+		}
+		retried = true
+		out, metadata, err = next.HandleInitialize(ctx, in)
+	}
+	return out, metadata, err
+}
+
+// wait blocks while a refresh is going on and returns the generation of the credentials the call is sent with
+func (r *CredentialRefresher) wait() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.generation
+}
+
+// refresh renews the credentials unless a call that failed at the same time already did. It returns the generation
+// to send the failed call again with, or false if it should give up. retried is set when the call already failed with
+// refreshed credentials, then only the user can help.
+func (r *CredentialRefresher) refresh(ctx context.Context, generation int, retried bool) (int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.gaveUp {
+		return r.generation, false
+	}
+	if r.generation != generation {
+		return r.generation, true
+	}
+
+	err := errors.New("the refreshed credentials were rejected as well")
+	if !retried {
+		if err = r.renew(ctx); err == nil {
+			return r.generation, true
+		}
+	}
+	for {
+		TxtLog.Warnf("Could not refresh the credentials of profile %s: %s", r.profile, err)
+		if r.prompt == nil || !r.prompt(r.profile, err) {
+			r.gaveUp = true
+			return r.generation, false
+		}
+		if err = r.renew(ctx); err == nil {
+			return r.generation, true
+		}
+	}
+}
+
+// renew drops the cached credentials and has the credential source, such as the SSO token cache or the
+// credential_process, hand out new ones
+func (r *CredentialRefresher) renew(ctx context.Context) error {
+	if r.credentials == nil {
+		return errors.New("no credential source to refresh")
+	}
+	if cache, ok := r.credentials.(*aws.CredentialsCache); ok {
+		cache.Invalidate()
+	}
+	if _, err := r.credentials.Retrieve(ctx); err != nil {
+		return err
+	}
+	r.generation++
+	PrintWhileSpinning("[%s][%s] Refreshed the expired credentials, continuing.\n", cyan(emoji.Sprintf(":fox:cloudfox :fox:")), cyan(r.profile))
+	TxtLog.Infof("Refreshed the expired credentials of profile %s", r.profile)
+	return nil
+}
+
+// promptCredentialRefresh asks the user to renew the credentials of profile and waits until they press Enter. It
+// returns false when stdin is closed. The spinner is paused until then, so its status line doesn't overwrite the
+// prompt.
+func promptCredentialRefresh(profile string, err error) bool {
+	spinnerMutex.Lock()
+	defer spinnerMutex.Unlock()
+	fmt.Printf(clearln+"[%s][%s] The credentials expired and could not be refreshed automatically: %s\n", cyan(emoji.Sprintf(":fox:cloudfox :fox:")), cyan(profile), err)
+	fmt.Printf("[%s][%s] Renew them in another terminal, for example with aws sso login --profile %s, then press Enter to continue.\n", cyan(emoji.Sprintf(":fox:cloudfox :fox:")), cyan(profile), profile)
+	_, readErr := bufio.NewReader(os.Stdin).ReadString('\n')
+	return readErr == nil
+}
+
+// isRefreshableCredentialsError tells if a new set of credentials could make a failed call succeed. Once a run is
+// going, InvalidClientTokenId means the session token is no longer accepted, some services report expiry that way.
+func isRefreshableCredentialsError(err error) bool {
+	if IsExpiredCredentialsError(err) {
+		return true
+	}
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "InvalidClientTokenId"
+}
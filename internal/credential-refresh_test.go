@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// refreshingProvider hands out a new access key on every call, like an SSO profile or a credential_process
+type refreshingProvider struct {
+	mu        sync.Mutex
+	retrieved int
+}
+
+func (p *refreshingProvider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.retrieved++
+	return aws.Credentials{AccessKeyID: fmt.Sprintf("ASIA%d", p.retrieved), SecretAccessKey: "secret", SessionToken: "token"}, nil
+}
+
+func (p *refreshingProvider) Retrieved() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.retrieved
+}
+
+// expiringAPI fails calls with ExpiredToken until the credentials were retrieved acceptFrom times
+func expiringAPI(provider *refreshingProvider, acceptFrom int) middleware.InitializeHandler {
+	return middleware.InitializeHandlerFunc(func(ctx context.Context, in middleware.InitializeInput) (middleware.InitializeOutput, middleware.Metadata, error) {
+		if provider.Retrieved() < acceptFrom {
+			return middleware.InitializeOutput{}, middleware.Metadata{}, &smithy.GenericAPIError{Code: "ExpiredToken", Message: "The security token included in the request is expired"}
+		}
+		return middleware.InitializeOutput{Result: "ok"}, middleware.Metadata{}, nil
+	})
+}
+
+func TestCredentialRefresherRefreshesOnce(t *testing.T) {
+	provider := &refreshingProvider{}
+	r := &CredentialRefresher{profile: "unittesting", credentials: aws.NewCredentialsCache(provider)}
+	next := expiringAPI(provider, 1)
+
+	// Calls that fail at the same time share one refresh
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := r.handleInitialize(context.Background(), middleware.InitializeInput{}, next)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Expected the call to succeed after the refresh, got %s", err)
+		}
+	}
+	if provider.Retrieved() != 1 {
+		t.Errorf("Expected the credentials to be refreshed once, got %d", provider.Retrieved())
+	}
+}
+
+func TestCredentialRefresherPrompt(t *testing.T) {
+	// The first refresh is not enough, the user has to log in again
+	provider := &refreshingProvider{}
+	var prompts int
+	r := &CredentialRefresher{
+		profile:     "unittesting",
+		credentials: aws.NewCredentialsCache(provider),
+		prompt: func(profile string, err error) bool {
+			prompts++
+			return true
+		},
+	}
+	_, _, err := r.handleInitialize(context.Background(), middleware.InitializeInput{}, expiringAPI(provider, 2))
+	if err != nil {
+		t.Errorf("Expected the call to succeed after the prompt, got %s", err)
+	}
+	if prompts != 1 {
+		t.Errorf("Expected one prompt, got %d", prompts)
+	}
+
+	// Without anybody to answer the prompt, the refresher gives up and stops trying
+	provider = &refreshingProvider{}
+	r = &CredentialRefresher{profile: "unittesting", credentials: aws.NewCredentialsCache(provider)}
+	next := expiringAPI(provider, 100)
+	for i := 0; i < 2; i++ {
+		_, _, err = r.handleInitialize(context.Background(), middleware.InitializeInput{}, next)
+		if !IsExpiredCredentialsError(err) {
+			t.Errorf("Expected the expired credentials error, got %v", err)
+		}
+	}
+	if provider.Retrieved() != 1 {
+		t.Errorf("Expected one refresh before giving up, got %d", provider.Retrieved())
+	}
+}
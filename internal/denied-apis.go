@@ -0,0 +1,199 @@
+package internal
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+)
+
+const (
+	DeniedAPIsCSVFileName    = "denied-permissions.csv"
+	DeniedAPIsPolicyFileName = "denied-permissions-policy.json"
+)
+
+// AWSDeniedAPIs records the calls of every client created from a config of AWSConfigFileLoader that were denied
+var AWSDeniedAPIs = NewDeniedAPITracker()
+
+// DeniedAPI is an API action the caller was denied in a region, and how often
+type DeniedAPI struct {
+	Service string `json:"service"`
+	Action  string `json:"action"`
+	Region  string `json:"region"`
+	Count   int    `json:"count"`
+}
+
+// DeniedAPITracker collects the calls that failed with missing permissions by profile. It hooks into the SDK as
+// middleware, so the modules don't have to report their AccessDenied errors themselves.
+type DeniedAPITracker struct {
+	mu     sync.Mutex
+	denied map[string]map[DeniedAPI]int
+}
+
+func NewDeniedAPITracker() *DeniedAPITracker {
+	return &DeniedAPITracker{denied: map[string]map[DeniedAPI]int{}}
+}
+
+// APIOption returns the middleware that records the denied calls of profile, use it as an aws.Config APIOption
+func (t *DeniedAPITracker) APIOption(profile string) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("CloudfoxDeniedAPITracker", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			out, metadata, err := next.HandleInitialize(ctx, in)
+			if IsAccessDeniedError(err) {
+				t.record(profile, awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx), awsmiddleware.GetRegion(ctx))
+			}
+			return out, metadata, err
+		}), middleware.After)
+	}
+}
+
+func (t *DeniedAPITracker) record(profile string, service string, operation string, region string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.denied[profile] == nil {
+		t.denied[profile] = map[DeniedAPI]int{}
+	}
+	key := DeniedAPI{
+		Service: service,
+		Action:  IAMActionName(service, operation),
+		Region:  region,
+	}
+	t.denied[profile][key]++
+}
+
+// Denied returns the denied calls of profile sorted by action and region
+func (t *DeniedAPITracker) Denied(profile string) []DeniedAPI {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var denied []DeniedAPI
+	for key, count := range t.denied[profile] {
+		key.Count = count
+		denied = append(denied, key)
+	}
+	sort.Slice(denied, func(i, j int) bool {
+		if denied[i].Action != denied[j].Action {
+			return denied[i].Action < denied[j].Action
+		}
+		return denied[i].Region < denied[j].Region
+	})
+	return denied
+}
+
+// iamServicePrefixes are the IAM action prefixes of the SDK service IDs that aren't just the ID in lower case
+// without spaces
+var iamServicePrefixes = map[string]string{
+	"AccessAnalyzer":              "access-analyzer",
+	"amp":                         "aps",
+	"API Gateway":                 "apigateway",
+	"ApiGatewayV2":                "apigateway",
+	"Auto Scaling":                "autoscaling",
+	"CloudWatch Logs":             "logs",
+	"Cognito Identity":            "cognito-identity",
+	"Cognito Identity Provider":   "cognito-idp",
+	"Config Service":              "config",
+	"Directory Service":           "ds",
+	"DocDB":                       "rds",
+	"EFS":                         "elasticfilesystem",
+	"Elastic Load Balancing":      "elasticloadbalancing",
+	"Elastic Load Balancing v2":   "elasticloadbalancing",
+	"Elasticsearch Service":       "es",
+	"EMR":                         "elasticmapreduce",
+	"EventBridge":                 "events",
+	"Neptune":                     "rds",
+	"OpenSearch":                  "es",
+	"OpenSearchServerless":        "aoss",
+	"Resource Groups Tagging API": "tag",
+	"SFN":                         "states",
+}
+
+// IAMActionName turns the SDK service ID and operation name of a call into the IAM action that allows it, for example
+// Secrets Manager and ListSecrets into secretsmanager:ListSecrets
+func IAMActionName(service string, operation string) string {
+	prefix, ok := iamServicePrefixes[service]
+	if !ok {
+		prefix = strings.ToLower(strings.ReplaceAll(service, " ", ""))
+	}
+	return prefix + ":" + operation
+}
+
+// readActionPrefixes are the operations a read-only scan needs, the ones that go into the policy for a follow-up scan
+var readActionPrefixes = []string{"BatchGet", "Describe", "Get", "List", "Lookup", "Query", "Scan", "Search"}
+
+// IAMPolicyDocument is an identity policy with Allow statements only
+type IAMPolicyDocument struct {
+	Version   string               `json:"Version"`
+	Statement []IAMPolicyStatement `json:"Statement"`
+}
+
+type IAMPolicyStatement struct {
+	Sid      string   `json:"Sid"`
+	Effect   string   `json:"Effect"`
+	Action   []string `json:"Action"`
+	Resource string   `json:"Resource"`
+}
+
+// DeniedAPIsPolicy is the minimal policy that would have allowed the denied read actions, for the client to grant
+// before a follow-up scan. Denied actions that change something are left out. It returns false when no read action
+// was denied.
+func DeniedAPIsPolicy(denied []DeniedAPI) (IAMPolicyDocument, bool) {
+	var actions []string
+	for _, api := range denied {
+		operation := api.Action[strings.Index(api.Action, ":")+1:]
+		if Contains(api.Action, actions) || !hasAnyPrefix(operation, readActionPrefixes) {
+			continue
+		}
+		actions = append(actions, api.Action)
+	}
+	if len(actions) == 0 {
+		return IAMPolicyDocument{}, false
+	}
+	sort.Strings(actions)
+	return IAMPolicyDocument{
+		Version: "2012-10-17",
+		Statement: []IAMPolicyStatement{
+			{
+				Sid:      "CloudfoxDeniedReadActions",
+				Effect:   "Allow",
+				Action:   actions,
+				Resource: "*",
+			},
+		},
+	}, true
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteDeniedAPIsCSV writes the denied calls to denied-permissions.csv in directory
+func WriteDeniedAPIsCSV(directory string, denied []DeniedAPI) (string, error) {
+	if err := fileSystem.MkdirAll(directory, 0700); err != nil {
+		return "", err
+	}
+	csvPath := filepath.Join(directory, DeniedAPIsCSVFileName)
+	file, err := fileSystem.OpenFile(csvPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	csvWriter := csv.NewWriter(file)
+	csvWriter.Write([]string{"Service", "Action", "Region", "Count"})
+	for _, api := range denied {
+		csvWriter.Write([]string{api.Service, api.Action, api.Region, strconv.Itoa(api.Count)})
+	}
+	csvWriter.Flush()
+	return csvPath, csvWriter.Error()
+}
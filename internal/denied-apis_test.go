@@ -0,0 +1,242 @@
+package internal
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/accessanalyzer"
+	"github.com/aws/aws-sdk-go-v2/service/acm"
+	"github.com/aws/aws-sdk-go-v2/service/amp"
+	"github.com/aws/aws-sdk-go-v2/service/apigateway"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go-v2/service/apprunner"
+	"github.com/aws/aws-sdk-go-v2/service/athena"
+	"github.com/aws/aws-sdk-go-v2/service/backup"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+	"github.com/aws/aws-sdk-go-v2/service/cloud9"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudsearch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/codeartifact"
+	"github.com/aws/aws-sdk-go-v2/service/codebuild"
+	"github.com/aws/aws-sdk-go-v2/service/codecommit"
+	"github.com/aws/aws-sdk-go-v2/service/codedeploy"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	"github.com/aws/aws-sdk-go-v2/service/datapipeline"
+	"github.com/aws/aws-sdk-go-v2/service/directoryservice"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/efs"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+	"github.com/aws/aws-sdk-go-v2/service/elasticbeanstalk"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/emr"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	"github.com/aws/aws-sdk-go-v2/service/glue"
+	"github.com/aws/aws-sdk-go-v2/service/grafana"
+	"github.com/aws/aws-sdk-go-v2/service/guardduty"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iot"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lightsail"
+	"github.com/aws/aws-sdk-go-v2/service/mediastore"
+	"github.com/aws/aws-sdk-go-v2/service/mq"
+	"github.com/aws/aws-sdk-go-v2/service/opensearch"
+	"github.com/aws/aws-sdk-go-v2/service/opensearchserverless"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/ram"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/redshift"
+	"github.com/aws/aws-sdk-go-v2/service/rekognition"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/route53resolver"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub"
+	"github.com/aws/aws-sdk-go-v2/service/sfn"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/transfer"
+	"github.com/aws/aws-sdk-go-v2/service/verifiedpermissions"
+	"github.com/aws/aws-sdk-go-v2/service/wafv2"
+	"github.com/aws/aws-sdk-go-v2/service/workmail"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/spf13/afero"
+)
+
+// callDenied sends a call through a stack with the tracker of profile that fails with err
+func callDenied(t *testing.T, tracker *DeniedAPITracker, profile string, service string, operation string, region string, err error) {
+	stack := middleware.NewStack(operation, smithyhttp.NewStackRequest)
+	stack.Initialize.Add(&awsmiddleware.RegisterServiceMetadata{ServiceID: service, OperationName: operation, Region: region}, middleware.Before)
+	if err := tracker.APIOption(profile)(stack); err != nil {
+		t.Fatal(err)
+	}
+	handler := middleware.DecorateHandler(middleware.HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, middleware.Metadata, error) {
+		return nil, middleware.Metadata{}, err
+	}), stack)
+	handler.Handle(context.Background(), nil)
+}
+
+func TestDeniedAPITracker(t *testing.T) {
+	tracker := NewDeniedAPITracker()
+	accessDenied := &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized to perform: secretsmanager:ListSecrets"}
+	callDenied(t, tracker, "dev", "Secrets Manager", "ListSecrets", "us-east-1", accessDenied)
+	callDenied(t, tracker, "dev", "Secrets Manager", "ListSecrets", "us-east-1", accessDenied)
+	callDenied(t, tracker, "dev", "Secrets Manager", "ListSecrets", "eu-west-1", accessDenied)
+	callDenied(t, tracker, "dev", "EC2", "DescribeInstances", "us-east-1", &smithy.GenericAPIError{Code: "UnauthorizedOperation"})
+	callDenied(t, tracker, "dev", "CloudWatch Logs", "DescribeLogGroups", "us-east-1", &smithy.GenericAPIError{Code: "AccessDeniedException"})
+	callDenied(t, tracker, "dev", "Lambda", "InvokeFunction", "us-east-1", &smithy.GenericAPIError{Code: "AccessDeniedException"})
+	// Errors that are not about permissions and other profiles don't count
+	callDenied(t, tracker, "dev", "EC2", "DescribeVolumes", "us-east-1", &smithy.GenericAPIError{Code: "RequestLimitExceeded"})
+	callDenied(t, tracker, "dev", "S3", "ListBuckets", "us-east-1", nil)
+	callDenied(t, tracker, "prod", "S3", "ListBuckets", "us-east-1", &smithy.GenericAPIError{Code: "AccessDenied"})
+
+	expected := []DeniedAPI{
+		{Service: "EC2", Action: "ec2:DescribeInstances", Region: "us-east-1", Count: 1},
+		{Service: "Lambda", Action: "lambda:InvokeFunction", Region: "us-east-1", Count: 1},
+		{Service: "CloudWatch Logs", Action: "logs:DescribeLogGroups", Region: "us-east-1", Count: 1},
+		{Service: "Secrets Manager", Action: "secretsmanager:ListSecrets", Region: "eu-west-1", Count: 1},
+		{Service: "Secrets Manager", Action: "secretsmanager:ListSecrets", Region: "us-east-1", Count: 2},
+	}
+	denied := tracker.Denied("dev")
+	if !reflect.DeepEqual(denied, expected) {
+		t.Fatalf("Expected denied APIs %v, got %v", expected, denied)
+	}
+
+	// The policy only allows the read actions, once each
+	policy, ok := DeniedAPIsPolicy(denied)
+	if !ok {
+		t.Fatal("Expected a policy for the denied read actions")
+	}
+	expectedActions := []string{"ec2:DescribeInstances", "logs:DescribeLogGroups", "secretsmanager:ListSecrets"}
+	if !reflect.DeepEqual(policy.Statement[0].Action, expectedActions) {
+		t.Errorf("Expected policy actions %v, got %v", expectedActions, policy.Statement[0].Action)
+	}
+	if _, ok := DeniedAPIsPolicy(tracker.Denied("dev")[1:2]); ok {
+		t.Errorf("Expected no policy when only lambda:InvokeFunction was denied")
+	}
+
+	fs := MockFileSystem(true)
+	defer MockFileSystem(false)
+	csvPath, err := WriteDeniedAPIsCSV("out", denied)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if csvPath != filepath.Join("out", DeniedAPIsCSVFileName) {
+		t.Errorf("Unexpected CSV path %s", csvPath)
+	}
+	content, err := afero.ReadFile(fs, csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "Secrets Manager,secretsmanager:ListSecrets,us-east-1,2\n") {
+		t.Errorf("Expected the ListSecrets row in the CSV, got %s", content)
+	}
+}
+
+// TestIAMActionName covers the service of every client in aws/sdk, plus the tagging API the tags module calls
+func TestIAMActionName(t *testing.T) {
+	subtests := []struct {
+		service string
+		action  string
+	}{
+		{accessanalyzer.ServiceID, "access-analyzer:ListAnalyzers"},
+		{acm.ServiceID, "acm:ListCertificates"},
+		{amp.ServiceID, "aps:ListWorkspaces"},
+		{apigateway.ServiceID, "apigateway:GetRestApis"},
+		{apigatewayv2.ServiceID, "apigateway:GetApis"},
+		{apprunner.ServiceID, "apprunner:ListServices"},
+		{athena.ServiceID, "athena:ListDataCatalogs"},
+		{backup.ServiceID, "backup:ListBackupVaults"},
+		{batch.ServiceID, "batch:DescribeJobQueues"},
+		{cloud9.ServiceID, "cloud9:ListEnvironments"},
+		{cloudformation.ServiceID, "cloudformation:ListStacks"},
+		{cloudfront.ServiceID, "cloudfront:ListDistributions"},
+		{cloudsearch.ServiceID, "cloudsearch:DescribeDomains"},
+		{cloudtrail.ServiceID, "cloudtrail:DescribeTrails"},
+		{cloudwatch.ServiceID, "cloudwatch:DescribeAlarms"},
+		{cloudwatchlogs.ServiceID, "logs:DescribeLogGroups"},
+		{codeartifact.ServiceID, "codeartifact:ListDomains"},
+		{codebuild.ServiceID, "codebuild:ListProjects"},
+		{codecommit.ServiceID, "codecommit:ListRepositories"},
+		{codedeploy.ServiceID, "codedeploy:ListApplications"},
+		{configservice.ServiceID, "config:DescribeConfigRules"},
+		{datapipeline.ServiceID, "datapipeline:ListPipelines"},
+		{directoryservice.ServiceID, "ds:DescribeDirectories"},
+		{docdb.ServiceID, "rds:DescribeDBClusters"},
+		{dynamodb.ServiceID, "dynamodb:ListTables"},
+		{ec2.ServiceID, "ec2:DescribeInstances"},
+		{ecr.ServiceID, "ecr:DescribeRepositories"},
+		{ecs.ServiceID, "ecs:ListClusters"},
+		{efs.ServiceID, "elasticfilesystem:DescribeFileSystems"},
+		{eks.ServiceID, "eks:ListClusters"},
+		{elasticache.ServiceID, "elasticache:DescribeCacheClusters"},
+		{elasticbeanstalk.ServiceID, "elasticbeanstalk:DescribeApplications"},
+		{elasticloadbalancing.ServiceID, "elasticloadbalancing:DescribeLoadBalancers"},
+		{elasticloadbalancingv2.ServiceID, "elasticloadbalancing:DescribeLoadBalancers"},
+		{emr.ServiceID, "elasticmapreduce:ListClusters"},
+		{eventbridge.ServiceID, "events:ListRules"},
+		{firehose.ServiceID, "firehose:ListDeliveryStreams"},
+		{glue.ServiceID, "glue:GetDatabases"},
+		{grafana.ServiceID, "grafana:ListWorkspaces"},
+		{guardduty.ServiceID, "guardduty:ListDetectors"},
+		{iam.ServiceID, "iam:ListRoles"},
+		{iot.ServiceID, "iot:ListThings"},
+		{kafka.ServiceID, "kafka:ListClustersV2"},
+		{kinesis.ServiceID, "kinesis:ListStreams"},
+		{kms.ServiceID, "kms:ListKeys"},
+		{lambda.ServiceID, "lambda:ListFunctions"},
+		{lightsail.ServiceID, "lightsail:GetInstances"},
+		{mediastore.ServiceID, "mediastore:ListContainers"},
+		{mq.ServiceID, "mq:ListBrokers"},
+		{opensearch.ServiceID, "es:ListDomainNames"},
+		{opensearchserverless.ServiceID, "aoss:ListCollections"},
+		{organizations.ServiceID, "organizations:ListAccounts"},
+		{ram.ServiceID, "ram:GetResourceShares"},
+		{rds.ServiceID, "rds:DescribeDBInstances"},
+		{redshift.ServiceID, "redshift:DescribeClusters"},
+		{rekognition.ServiceID, "rekognition:ListCollections"},
+		{resourcegroupstaggingapi.ServiceID, "tag:GetResources"},
+		{route53.ServiceID, "route53:ListHostedZones"},
+		{route53resolver.ServiceID, "route53resolver:ListResolverEndpoints"},
+		{s3.ServiceID, "s3:ListBuckets"},
+		{sagemaker.ServiceID, "sagemaker:ListNotebookInstances"},
+		{secretsmanager.ServiceID, "secretsmanager:ListSecrets"},
+		{securityhub.ServiceID, "securityhub:GetFindings"},
+		{sfn.ServiceID, "states:ListStateMachines"},
+		{sns.ServiceID, "sns:ListTopics"},
+		{sqs.ServiceID, "sqs:ListQueues"},
+		{ssm.ServiceID, "ssm:DescribeParameters"},
+		{transfer.ServiceID, "transfer:ListServers"},
+		{verifiedpermissions.ServiceID, "verifiedpermissions:ListPolicyStores"},
+		{wafv2.ServiceID, "wafv2:ListWebACLs"},
+		{workmail.ServiceID, "workmail:ListOrganizations"},
+	}
+	for _, subtest := range subtests {
+		operation := subtest.action[strings.Index(subtest.action, ":")+1:]
+		if action := IAMActionName(subtest.service, operation); action != subtest.action {
+			t.Errorf("Expected %s for service %q, got %s", subtest.action, subtest.service, action)
+		}
+	}
+}
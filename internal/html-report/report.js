@@ -0,0 +1,92 @@
+// Sorts the report table by the clicked column and filters it with the search box and the per-column inputs. Numbers
+// sort as numbers, everything else as text. No libraries, so the report works offline.
+(function () {
+  "use strict";
+  var table = document.getElementById("report-table");
+  if (!table) {
+    return;
+  }
+  var tbody = table.tBodies[0];
+  var rows = Array.prototype.slice.call(tbody.rows);
+  var headers = table.querySelectorAll("th.sortable");
+  var columnFilters = table.querySelectorAll("input.column-filter");
+  var search = document.getElementById("report-search");
+  var count = document.getElementById("report-count");
+  var sortColumn = -1;
+  var sortAscending = true;
+
+  function cellText(row, column) {
+    var cell = row.cells[column];
+    return cell ? cell.textContent : "";
+  }
+
+  function compare(a, b) {
+    var x = cellText(a, sortColumn);
+    var y = cellText(b, sortColumn);
+    var nx = Number(x);
+    var ny = Number(y);
+    var result;
+    if (x !== "" && y !== "" && !isNaN(nx) && !isNaN(ny)) {
+      result = nx - ny;
+    } else {
+      result = x.localeCompare(y, undefined, { numeric: true, sensitivity: "base" });
+    }
+    return sortAscending ? result : -result;
+  }
+
+  function matches(row) {
+    var term = search ? search.value.trim().toLowerCase() : "";
+    if (term !== "" && row.textContent.toLowerCase().indexOf(term) === -1) {
+      return false;
+    }
+    for (var i = 0; i < columnFilters.length; i++) {
+      var filter = columnFilters[i].value.trim().toLowerCase();
+      var column = Number(columnFilters[i].getAttribute("data-column"));
+      if (filter !== "" && cellText(row, column).toLowerCase().indexOf(filter) === -1) {
+        return false;
+      }
+    }
+    return true;
+  }
+
+  function render() {
+    var sorted = rows.slice();
+    if (sortColumn >= 0) {
+      sorted.sort(compare);
+    }
+    var shown = 0;
+    var fragment = document.createDocumentFragment();
+    sorted.forEach(function (row) {
+      var visible = matches(row);
+      row.style.display = visible ? "" : "none";
+      if (visible) {
+        shown++;
+      }
+      fragment.appendChild(row);
+    });
+    tbody.appendChild(fragment);
+    if (count) {
+      count.textContent = "Showing " + shown + " of " + rows.length + " rows";
+    }
+  }
+
+  Array.prototype.forEach.call(headers, function (header) {
+    header.addEventListener("click", function () {
+      var column = Number(header.getAttribute("data-column"));
+      sortAscending = column === sortColumn ? !sortAscending : true;
+      sortColumn = column;
+      Array.prototype.forEach.call(headers, function (h) {
+        h.classList.remove("sort-asc", "sort-desc");
+      });
+      header.classList.add(sortAscending ? "sort-asc" : "sort-desc");
+      render();
+    });
+  });
+  Array.prototype.forEach.call(columnFilters, function (input) {
+    input.addEventListener("input", render);
+  });
+  if (search) {
+    search.addEventListener("input", render);
+  }
+  render();
+})();
@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// HTMLOutput makes OutputClient.WriteFullOutput also write an HTML report of every table, for -o html
+var HTMLOutput bool
+
+// The report embeds its stylesheet and script so the file can be emailed or committed without anything else
+//
+//go:embed html-report/report.html.tmpl html-report/report.css html-report/report.js
+var htmlReportFiles embed.FS
+
+var htmlReportTemplate = template.Must(template.ParseFS(htmlReportFiles, "html-report/report.html.tmpl"))
+
+// HTMLReportMetadata is the scan information in the header of an HTML report
+type HTMLReportMetadata struct {
+	Module    string
+	AccountID string
+	Profile   string
+	ScanDate  time.Time
+}
+
+// WriteHTMLReport writes a self-contained HTML page with a sortable and filterable table of body
+func WriteHTMLReport(w io.Writer, metadata HTMLReportMetadata, header []string, body [][]string) error {
+	css, err := htmlReportFiles.ReadFile("html-report/report.css")
+	if err != nil {
+		return err
+	}
+	js, err := htmlReportFiles.ReadFile("html-report/report.js")
+	if err != nil {
+		return err
+	}
+	if metadata.ScanDate.IsZero() {
+		metadata.ScanDate = time.Now()
+	}
+	// Render into a buffer first, so a failing template doesn't leave half a report behind
+	var out bytes.Buffer
+	err = htmlReportTemplate.Execute(&out, struct {
+		Metadata HTMLReportMetadata
+		ScanDate string
+		Header   []string
+		Body     [][]string
+		CSS      template.CSS
+		JS       template.JS
+	}{
+		Metadata: metadata,
+		ScanDate: metadata.ScanDate.UTC().Format(time.RFC3339),
+		Header:   header,
+		Body:     removeColorCodesFromNestedSlice(body),
+		CSS:      template.CSS(css),
+		JS:       template.JS(js),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out.Bytes())
+	return err
+}
+
+func printHTMLToFile(header []string, body [][]string, metadata HTMLReportMetadata, outputFile afero.File) {
+	if err := WriteHTMLReport(outputFile, metadata, header, body); err != nil {
+		fmt.Println("error writing html report:", err)
+	}
+}
+
+// htmlReportAccountID finds the account in an output directory named <profile>-<account>, like the AWS modules use
+func htmlReportAccountID(directory string, profile string) string {
+	if profile == "" {
+		return ""
+	}
+	for dir := filepath.Clean(directory); dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		if account := strings.TrimPrefix(filepath.Base(dir), profile+"-"); account != filepath.Base(dir) {
+			return account
+		}
+	}
+	return ""
+}
+
+func (b *TableClient) writeHTMLFiles(metadata HTMLReportMetadata) []string {
+	var fullFilePaths []string
+
+	if b.DirectoryName == "" {
+		b.DirectoryName = "."
+	}
+	htmlDirectory := path.Join(b.DirectoryName, "html")
+	if _, err := fileSystem.Stat(htmlDirectory); os.IsNotExist(err) {
+		err = fileSystem.MkdirAll(htmlDirectory, 0700)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	for _, file := range b.TableFiles {
+		fullPath := path.Join(htmlDirectory, fmt.Sprintf("%s.html", file.Name))
+		filePointer, err := fileSystem.OpenFile(fullPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			log.Fatalf("error creating html file: %s", err)
+		}
+		// The report has every column, they can be filtered in the browser
+		printHTMLToFile(file.Header, file.Body, metadata, filePointer)
+		filePointer.Close()
+		fullFilePaths = append(fullFilePaths, fullPath)
+	}
+
+	return fullFilePaths
+}
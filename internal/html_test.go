@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+func TestWriteHTMLReport(t *testing.T) {
+	var out bytes.Buffer
+	metadata := HTMLReportMetadata{
+		Module:    "secrets",
+		AccountID: "123456789012",
+		Profile:   "unittesting",
+		ScanDate:  time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	header := []string{"Name", "Description"}
+	body := [][]string{
+		{"db-password", "<script>alert(1)</script>"},
+		{"\x1b[31mapi-key\x1b[0m", "colored"},
+	}
+	if err := WriteHTMLReport(&out, metadata, header, body); err != nil {
+		t.Fatal(err)
+	}
+	report := out.String()
+
+	for _, expected := range []string{"123456789012", "unittesting", "2024-05-01T12:00:00Z", "<code>secrets</code>", "db-password", "<td>api-key</td>", "&lt;script&gt;alert(1)&lt;/script&gt;", `id="report-search"`, "sortColumn"} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("Expected %q to be in the report", expected)
+		}
+	}
+	if strings.Contains(report, "<script>alert(1)</script>") {
+		t.Errorf("Table cells are not escaped")
+	}
+	// The report must open without network access
+	for _, external := range []string{`src="http`, `href="http`, "<link"} {
+		if strings.Contains(report, external) {
+			t.Errorf("Report references an external resource: %s", external)
+		}
+	}
+}
+
+func TestHTMLReportAccountID(t *testing.T) {
+	subTests := []struct {
+		directory string
+		profile   string
+		expected  string
+	}{
+		{directory: "cloudfox-output/aws/dev-123456789012", profile: "dev", expected: "123456789012"},
+		{directory: "cloudfox-output/aws/dev-123456789012/inventory", profile: "dev", expected: "123456789012"},
+		{directory: "cloudfox-output/azure/tenant", profile: "dev", expected: ""},
+		{directory: "cloudfox-output/aws/dev-123456789012", profile: "", expected: ""},
+	}
+	for _, s := range subTests {
+		if got := htmlReportAccountID(s.directory, s.profile); got != s.expected {
+			t.Errorf("%s: expected %q, got %q", s.directory, s.expected, got)
+		}
+	}
+}
+
+func TestWriteFullOutputHTML(t *testing.T) {
+	fs := MockFileSystem(true)
+	defer MockFileSystem(false)
+	HTMLOutput = true
+	defer func() { HTMLOutput = false }()
+
+	o := OutputClient{
+		Verbosity:        1,
+		CallingModule:    "buckets",
+		PrefixIdentifier: "unittesting",
+		Table: TableClient{
+			DirectoryName: "cloudfox-output/aws/unittesting-123456789012",
+		},
+	}
+	tables := []TableFile{{Name: "buckets", Header: []string{"Name"}, Body: [][]string{{"bucket1"}}}}
+	o.WriteFullOutput(tables, nil)
+
+	report, err := afero.ReadFile(fs, "cloudfox-output/aws/unittesting-123456789012/html/buckets.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(report), "bucket1") || !strings.Contains(string(report), "123456789012") {
+		t.Errorf("Report is missing the table or the account ID")
+	}
+}
@@ -0,0 +1,102 @@
+// Package onepassword is a small client for the 1Password Connect REST API. It only lists vaults and item metadata,
+// item fields and their values are never requested.
+package onepassword
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Environment variables of the official 1Password Connect SDKs and CLI
+const (
+	ConnectHostEnvVar  = "OP_CONNECT_HOST"
+	ConnectTokenEnvVar = "OP_CONNECT_TOKEN"
+)
+
+type Vault struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type Item struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Category  string    `json:"category"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Vault     struct {
+		ID string `json:"id"`
+	} `json:"vault"`
+}
+
+type ConnectClientInterface interface {
+	ListVaults(ctx context.Context) ([]Vault, error)
+	ListItems(ctx context.Context, vaultID string) ([]Item, error)
+}
+
+type ConnectClient struct {
+	Host       string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// NewConnectClientFromEnv builds a client from OP_CONNECT_HOST and OP_CONNECT_TOKEN
+func NewConnectClientFromEnv() (*ConnectClient, error) {
+	host := os.Getenv(ConnectHostEnvVar)
+	token := os.Getenv(ConnectTokenEnvVar)
+	if host == "" || token == "" {
+		return nil, fmt.Errorf("%s and %s must be set to query 1Password Connect", ConnectHostEnvVar, ConnectTokenEnvVar)
+	}
+	return &ConnectClient{
+		Host:       strings.TrimSuffix(host, "/"),
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (c *ConnectClient) ListVaults(ctx context.Context) ([]Vault, error) {
+	var vaults []Vault
+	err := c.get(ctx, "/v1/vaults", &vaults)
+	return vaults, err
+}
+
+func (c *ConnectClient) ListItems(ctx context.Context, vaultID string) ([]Item, error) {
+	var items []Item
+	err := c.get(ctx, fmt.Sprintf("/v1/vaults/%s/items", url.PathEscape(vaultID)), &items)
+	return items, err
+}
+
+// connectError is the body Connect sends with every non-2xx response
+type connectError struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+func (c *ConnectClient) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.Host+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var connectErr connectError
+		if json.NewDecoder(resp.Body).Decode(&connectErr) == nil && connectErr.Message != "" {
+			return fmt.Errorf("1Password Connect GET %s: %d %s", path, resp.StatusCode, connectErr.Message)
+		}
+		return fmt.Errorf("1Password Connect GET %s: %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,41 @@
+package onepassword
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type MockedConnectClient struct {
+}
+
+func (m *MockedConnectClient) ListVaults(ctx context.Context) ([]Vault, error) {
+	return []Vault{
+		{ID: "vault1", Name: "Infrastructure"},
+		{ID: "vault2", Name: "Databases"},
+	}, nil
+}
+
+func (m *MockedConnectClient) ListItems(ctx context.Context, vaultID string) ([]Item, error) {
+	var items []Item
+	switch vaultID {
+	case "vault1":
+		items = append(items,
+			mockedItem("item1", "deploy-key", vaultID, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)),
+			mockedItem("item2", "Secret2", vaultID, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+		)
+	case "vault2":
+		items = append(items,
+			mockedItem("item3", "stripe-api-key", vaultID, time.Date(2023, 11, 5, 0, 0, 0, 0, time.UTC)),
+		)
+	default:
+		return nil, fmt.Errorf("vault %s not found", vaultID)
+	}
+	return items, nil
+}
+
+func mockedItem(id string, title string, vaultID string, updatedAt time.Time) Item {
+	item := Item{ID: id, Title: title, Category: "PASSWORD", UpdatedAt: updatedAt}
+	item.Vault.ID = vaultID
+	return item
+}
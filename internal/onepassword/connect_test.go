@@ -0,0 +1,46 @@
+package onepassword
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConnectClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer connect-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":401,"message":"Invalid token signature"}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/vaults":
+			w.Write([]byte(`[{"id":"vault1","name":"Infrastructure"}]`))
+		case "/v1/vaults/vault1/items":
+			w.Write([]byte(`[{"id":"item1","title":"deploy-key","category":"PASSWORD","updatedAt":"2024-01-10T00:00:00Z","vault":{"id":"vault1"}}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status":404,"message":"vault not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := &ConnectClient{Host: server.URL, Token: "connect-token", HTTPClient: server.Client()}
+	vaults, err := client.ListVaults(context.TODO())
+	if err != nil || len(vaults) != 1 || vaults[0].Name != "Infrastructure" {
+		t.Fatalf("Unexpected vaults %+v, error %v", vaults, err)
+	}
+	items, err := client.ListItems(context.TODO(), "vault1")
+	if err != nil || len(items) != 1 || items[0].Title != "deploy-key" || items[0].Vault.ID != "vault1" || items[0].UpdatedAt.IsZero() {
+		t.Fatalf("Unexpected items %+v, error %v", items, err)
+	}
+	if _, err := client.ListItems(context.TODO(), "vault2"); err == nil {
+		t.Error("Expected an error for an unknown vault")
+	}
+
+	client.Token = "wrong-token"
+	if _, err := client.ListVaults(context.TODO()); err == nil || err.Error() != "1Password Connect GET /v1/vaults: 401 Invalid token signature" {
+		t.Errorf("Expected the Connect error message, got %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"time"
 
 	"github.com/aquasecurity/table"
 	"github.com/aws/smithy-go/ptr"
@@ -26,19 +27,14 @@ type OutputData2 struct {
 	Directory     string
 }
 
-// verbosity = 1 (Output and loot printed to file).
-// verbosity = 2 (Output and loot printed to file, output printed screen).
-// verbosity = 3 (Output and loot printed to file and screen).
-// outputType = "table", "csv"
+// verbosity = VerbositySummary (Output and loot printed to file).
+// verbosity = VerbosityTables or higher (Output and loot printed to file, output printed screen).
+// outputType = "table", "csv", "html"
 // prefixIdentifier = this string gets printed with control message calling module (e.g. aws profile, azure resource group, gcp project, etc)
 func OutputSelector(verbosity int, outputType string, header []string, body [][]string, outputDirectory string, fileName string, callingModule string, wrapTable bool, prefixIdentifier string) {
 
-	switch verbosity {
-	case 2:
+	if ShowTables(verbosity) {
 		PrintTableToScreen(header, body, wrapTable)
-	case 3:
-		PrintTableToScreen(header, body, wrapTable)
-		// Add writeLootToScreen function here
 	}
 	switch outputType {
 
@@ -65,6 +61,20 @@ func OutputSelector(verbosity int, outputType string, header []string, body [][]
 		fmt.Printf("[%s][%s] Output written to [%s]\n", cyan(callingModule), cyan(prefixIdentifier), outputFileCSV.Name())
 		// Add writeLootToFile function here
 
+	case "html":
+		outputFileHTML := createOutputFile(
+			ptr.String(filepath.Join(outputDirectory, "html")),
+			ptr.String(fmt.Sprintf("%s.html", fileName)),
+			outputType,
+			callingModule)
+		printHTMLToFile(header, body, HTMLReportMetadata{
+			Module:    callingModule,
+			AccountID: htmlReportAccountID(outputDirectory, prefixIdentifier),
+			Profile:   prefixIdentifier,
+			ScanDate:  time.Now(),
+		}, outputFileHTML)
+		fmt.Printf("[%s][%s] Output written to [%s]\n", cyan(callingModule), cyan(prefixIdentifier), outputFileHTML.Name())
+
 	default:
 		outputFileTable := createOutputFile(
 			ptr.String(filepath.Join(outputDirectory, "table")),
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/aquasecurity/table"
 	"github.com/fatih/color"
@@ -28,8 +29,12 @@ type OutputClient struct {
 	Verbosity        int
 	CallingModule    string
 	PrefixIdentifier string
-	Table            TableClient
-	Loot             LootClient
+	// AccountID and BaseDirectory are the AWS account of the output and the --outdir it goes to, for the files that
+	// cover every profile like the combined CSV
+	AccountID     string
+	BaseDirectory string
+	Table         TableClient
+	Loot          LootClient
 }
 
 type TableClient struct {
@@ -47,6 +52,9 @@ type TableFile struct {
 	Header            []string
 	Body              [][]string
 	SkipPrintToScreen bool
+	// SeparatorAfter adds a divider row after this many rows of the screen and txt tables, 0 means none. The csv and
+	// json files don't get it.
+	SeparatorAfter int
 }
 
 type LootClient struct {
@@ -58,6 +66,8 @@ type LootFile struct {
 	Name        string
 	FilePointer afero.File
 	Contents    string
+	// Extension defaults to txt when it is not set
+	Extension string
 }
 
 // TODO support datastructures that enable brief or wide format
@@ -142,15 +152,12 @@ func removeColorCodesFromNestedSlice(input [][]string) [][]string {
 
 func (o *OutputClient) WriteFullOutput(tables []TableFile, lootFiles []LootFile) {
 	logger := NewLogger()
-	switch o.Verbosity {
-	case 2:
-		o.Table.printTablesToScreen(tables)
-	case 3:
+	if ShowTables(o.Verbosity) {
 		o.Table.printTablesToScreen(tables)
+	}
+	if ShowLoot(o.Verbosity) && lootFiles != nil {
 		fmt.Println()
-		if lootFiles != nil {
-			o.Loot.printLoottoScreen(lootFiles)
-		}
+		o.Loot.printLoottoScreen(lootFiles)
 	}
 
 	o.Table.createTableFiles(tables)
@@ -163,6 +170,22 @@ func (o *OutputClient) WriteFullOutput(tables []TableFile, lootFiles []LootFile)
 	outputPaths = append(outputPaths, tableOutputPaths...)
 	outputPaths = append(outputPaths, csvOutputPaths...)
 	outputPaths = append(outputPaths, jsonOutputPaths...)
+	if HTMLOutput {
+		outputPaths = append(outputPaths, o.Table.writeHTMLFiles(HTMLReportMetadata{
+			Module:    o.CallingModule,
+			AccountID: htmlReportAccountID(o.Table.DirectoryName, o.PrefixIdentifier),
+			Profile:   o.PrefixIdentifier,
+			ScanDate:  time.Now(),
+		})...)
+	}
+
+	if CombinedCSVOutput {
+		if o.PrefixIdentifier == "" || o.AccountID == "" {
+			logger.ErrorM("Cannot update the combined CSV files, the output has no profile or account", o.CallingModule)
+		} else {
+			outputPaths = append(outputPaths, o.Table.writeCombinedCSVFiles(o.PrefixIdentifier, o.AccountID, o.BaseDirectory)...)
+		}
+	}
 
 	if lootFiles != nil {
 		o.Loot.createLootFiles(lootFiles)
@@ -202,7 +225,11 @@ func (l *LootClient) createLootFiles(lootFiles []LootFile) {
 			log.Fatalf("error creating loot file: no file name was specified")
 		}
 
-		l.LootFiles[i].Name = fmt.Sprintf("%s.txt", file.Name)
+		extension := file.Extension
+		if extension == "" {
+			extension = "txt"
+		}
+		l.LootFiles[i].Name = fmt.Sprintf("%s.%s", file.Name, extension)
 
 		filePointer, err := fileSystem.OpenFile(path.Join(lootDirectory, l.LootFiles[i].Name), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
 		if err != nil {
@@ -243,7 +270,7 @@ func (b *TableClient) printTablesToScreen(tableFiles []TableFile) {
 
 		//t.SetColumnMaxWidth(standardColumnWidth)
 		t.SetHeaders(tf.Header...)
-		t.AddRows(tf.Body...)
+		t.AddRows(withSeparatorRow(tf.Body, tf.SeparatorAfter)...)
 		t.SetHeaderStyle(table.StyleBold)
 		t.SetRowLines(false)
 		t.SetLineStyle(table.StyleCyan)
@@ -253,6 +280,20 @@ func (b *TableClient) printTablesToScreen(tableFiles []TableFile) {
 	}
 }
 
+// withSeparatorRow returns the body with a row of dashes after the first after rows
+func withSeparatorRow(body [][]string, after int) [][]string {
+	if after <= 0 || after >= len(body) {
+		return body
+	}
+	separator := make([]string, len(body[0]))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	rows := append([][]string{}, body[:after]...)
+	rows = append(rows, separator)
+	return append(rows, body[after:]...)
+}
+
 func (b *TableClient) createTableFiles(files []TableFile) {
 	b.TableFiles = files
 
@@ -299,7 +340,7 @@ func (b *TableClient) writeTableFiles(files []TableFile) []string {
 
 		t.SetHeaders(file.Header...)
 		file.Body = removeColorCodesFromNestedSlice(file.Body)
-		t.AddRows(file.Body...)
+		t.AddRows(withSeparatorRow(file.Body, file.SeparatorAfter)...)
 		t.SetRowLines(false)
 		t.SetDividers(table.UnicodeRoundedDividers)
 		t.SetAlignment(table.AlignLeft)
@@ -519,3 +560,24 @@ func WriteJsonlFile(file *os.File, data interface{}) error {
 	}
 	return nil
 }
+
+// WriteJSONFile writes data as indented JSON to directory/name, creating the directory if needed. It goes through the
+// same file system as the table and loot files so it can be mocked in unit tests.
+func WriteJSONFile(directory string, name string, data interface{}) (string, error) {
+	if _, err := fileSystem.Stat(directory); os.IsNotExist(err) {
+		err = fileSystem.MkdirAll(directory, 0700)
+		if err != nil {
+			return "", err
+		}
+	}
+	jsonBytes, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	fullPath := path.Join(directory, name)
+	err = afero.WriteFile(fileSystem, fullPath, jsonBytes, 0644)
+	if err != nil {
+		return "", err
+	}
+	return fullPath, nil
+}
@@ -0,0 +1,129 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// AWSRateLimiter limits the requests of every client created from a config of AWSConfigFileLoader, for
+// --requests-per-second. It is shared by all modules and region goroutines, so the limit holds for the whole run.
+var AWSRateLimiter = NewRateLimiter()
+
+// RateLimiter is a token bucket that holds a single token, so requests are spread evenly instead of being sent in
+// bursts that stand out in CloudTrail. A rate of 0 turns it off.
+type RateLimiter struct {
+	mu   sync.Mutex
+	rate float64
+	// next is when the next token is available, it moves ahead by one interval with every request
+	next time.Time
+	// sent is the number of requests let through, for the spinner ETA
+	sent int
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{}
+}
+
+// SetRate allows requestsPerSecond requests per second, 0 for no limit
+func (l *RateLimiter) SetRate(requestsPerSecond float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = requestsPerSecond
+	l.next = time.Time{}
+}
+
+// Rate is the limit in requests per second, 0 if there is none
+func (l *RateLimiter) Rate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+// Sent is the number of requests let through so far
+func (l *RateLimiter) Sent() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sent
+}
+
+// reserve takes the next token and returns how long to wait for it
+func (l *RateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sent++
+	if l.rate <= 0 {
+		return 0
+	}
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(float64(time.Second) / l.rate))
+	return wait
+}
+
+// Wait blocks until the request may be sent or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	wait := l.reserve(time.Now())
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// AddToStack adds the limiter to the middleware stack of an operation, use it as an aws.Config APIOption. It runs
+// after the retry middleware, so every attempt of a retried call takes a token of its own. Without a limit nothing is
+// added, so the default costs nothing.
+func (l *RateLimiter) AddToStack(stack *middleware.Stack) error {
+	if l.Rate() <= 0 {
+		return nil
+	}
+	limiter := middleware.FinalizeMiddlewareFunc("CloudfoxRateLimiter", l.handleFinalize)
+	if err := stack.Finalize.Insert(limiter, "Retry", middleware.After); err != nil {
+		// Clients without the standard retryer
+		return stack.Finalize.Add(limiter, middleware.After)
+	}
+	return nil
+}
+
+func (l *RateLimiter) handleFinalize(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+	if err := l.Wait(ctx); err != nil {
+		return middleware.FinalizeOutput{}, middleware.Metadata{}, err
+	}
+	return next.HandleFinalize(ctx, in)
+}
+
+// ETA estimates how long the remaining units of work of a module take at the current limit. Calls per unit are
+// averaged over the completed units, which sent sent requests, and assumed to be one before any unit completed.
+func (l *RateLimiter) ETA(complete int, total int, sent int) (time.Duration, bool) {
+	rate := l.Rate()
+	if rate <= 0 || total <= complete {
+		return 0, false
+	}
+	callsPerUnit := 1.0
+	if complete > 0 && sent > 0 {
+		callsPerUnit = float64(sent) / float64(complete)
+	}
+	seconds := float64(total-complete) * callsPerUnit / rate
+	return time.Duration(seconds * float64(time.Second)).Round(time.Second), true
+}
+
+// spinnerETA is the ETA shown after the spinner status when --requests-per-second is set
+func spinnerETA(counter *CommandCounter, sentAtStart int) string {
+	eta, ok := AWSRateLimiter.ETA(counter.Complete, counter.Total, AWSRateLimiter.Sent()-sentAtStart)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(" (ETA %s at %g requests/s)", eta, AWSRateLimiter.Rate())
+}
@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	limiter := NewRateLimiter()
+	now := time.Now()
+	if wait := limiter.reserve(now); wait != 0 {
+		t.Errorf("Expected no wait without a limit, got %s", wait)
+	}
+
+	limiter.SetRate(2)
+	for i, expected := range []time.Duration{0, 500 * time.Millisecond, time.Second} {
+		if wait := limiter.reserve(now); wait != expected {
+			t.Errorf("Request %d: expected to wait %s, got %s", i, expected, wait)
+		}
+	}
+	// Idle time doesn't build up tokens for a burst later
+	later := now.Add(time.Minute)
+	for i, expected := range []time.Duration{0, 500 * time.Millisecond} {
+		if wait := limiter.reserve(later); wait != expected {
+			t.Errorf("Request %d after a pause: expected to wait %s, got %s", i, expected, wait)
+		}
+	}
+	if limiter.Sent() != 6 {
+		t.Errorf("Expected 6 requests, got %d", limiter.Sent())
+	}
+}
+
+func TestRateLimiterETA(t *testing.T) {
+	limiter := NewRateLimiter()
+	if _, ok := limiter.ETA(1, 10, 5); ok {
+		t.Error("Expected no ETA without a limit")
+	}
+	limiter.SetRate(0.5)
+	// Nothing completed yet, one call per region
+	if eta, _ := limiter.ETA(0, 4, 0); eta != 8*time.Second {
+		t.Errorf("Expected 8s, got %s", eta)
+	}
+	// Two regions took 10 calls, so the other two take 10 more
+	if eta, _ := limiter.ETA(2, 4, 10); eta != 20*time.Second {
+		t.Errorf("Expected 20s, got %s", eta)
+	}
+	if _, ok := limiter.ETA(4, 4, 10); ok {
+		t.Error("Expected no ETA once everything completed")
+	}
+}
+
+func TestRateLimiterAddToStack(t *testing.T) {
+	limiter := NewRateLimiter()
+	stack := middleware.NewStack("test", nil)
+	stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("Retry", nil), middleware.After)
+	stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("Signing", nil), middleware.After)
+
+	if err := limiter.AddToStack(stack); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := stack.Finalize.Get("CloudfoxRateLimiter"); ok {
+		t.Error("Expected no middleware without a limit")
+	}
+
+	limiter.SetRate(10)
+	if err := limiter.AddToStack(stack); err != nil {
+		t.Fatal(err)
+	}
+	// Retried attempts go through the limiter again
+	ids := stack.Finalize.List()
+	if len(ids) != 3 || ids[0] != "Retry" || ids[1] != "CloudfoxRateLimiter" {
+		t.Errorf("Expected the limiter right after Retry, got %v", ids)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	limiter.reserve(time.Now())
+	if err := limiter.Wait(ctx); err == nil {
+		t.Error("Expected Wait to return when the context is done")
+	}
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+const RunManifestFileName = "run-manifest.json"
+
+// RunManifest documents one cloudfox run against one profile for engagement evidence: who ran it, which modules ran
+// against which regions, and which API calls they made
+type RunManifest struct {
+	Version       string                    `json:"version"`
+	Command       string                    `json:"command"`
+	Profile       string                    `json:"profile"`
+	CallerArn     string                    `json:"caller_arn"`
+	AccountID     string                    `json:"account_id"`
+	StartTime     time.Time                 `json:"start_time"`
+	EndTime       time.Time                 `json:"end_time"`
+	MaxAPICalls   int                       `json:"max_api_calls,omitempty"`
+	TotalAPICalls int                       `json:"total_api_calls"`
+	APICalls      map[string]map[string]int `json:"api_calls"`
+	Errors        int                       `json:"errors"`
+	Modules       []ModuleRun               `json:"modules"`
+}
+
+// ModuleRun is what one module of a run did
+type ModuleRun struct {
+	Name          string                    `json:"name"`
+	StartTime     time.Time                 `json:"start_time"`
+	EndTime       time.Time                 `json:"end_time"`
+	Regions       []string                  `json:"regions,omitempty"`
+	TotalAPICalls int                       `json:"total_api_calls"`
+	APICalls      map[string]map[string]int `json:"api_calls"`
+	Rows          int                       `json:"rows"`
+	Errors        int                       `json:"errors"`
+}
+
+// AddModule adds a module to the run and to the run's totals
+func (r *RunManifest) AddModule(module ModuleRun) {
+	module.TotalAPICalls = SumAPICalls(module.APICalls)
+	r.Modules = append(r.Modules, module)
+	r.Errors += module.Errors
+	r.TotalAPICalls += module.TotalAPICalls
+	if r.APICalls == nil {
+		r.APICalls = map[string]map[string]int{}
+	}
+	for service, operations := range module.APICalls {
+		if r.APICalls[service] == nil {
+			r.APICalls[service] = map[string]int{}
+		}
+		for operation, count := range operations {
+			r.APICalls[service][operation] += count
+		}
+	}
+}
+
+// runManifestFile is the content of run-manifest.json, every run that wrote to the directory, oldest first
+type runManifestFile struct {
+	Runs []RunManifest `json:"runs"`
+}
+
+// AppendRunManifest adds run to the run-manifest.json in outputDirectory. Runs already in the file are kept, so an
+// output directory that is reused, for example one per day, documents all of its runs.
+func AppendRunManifest(outputDirectory string, run RunManifest) (string, error) {
+	manifestPath := filepath.Join(outputDirectory, RunManifestFileName)
+
+	var manifest runManifestFile
+	existing, err := afero.ReadFile(fileSystem, manifestPath)
+	if err == nil {
+		if err := json.Unmarshal(existing, &manifest); err != nil {
+			return "", err
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+	manifest.Runs = append(manifest.Runs, run)
+
+	if err := fileSystem.MkdirAll(outputDirectory, 0700); err != nil {
+		return "", err
+	}
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return manifestPath, afero.WriteFile(fileSystem, manifestPath, content, 0644)
+}
@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestAppendRunManifest(t *testing.T) {
+	fs := MockFileSystem(true)
+	defer MockFileSystem(false)
+
+	for _, profile := range []string{"first", "second"} {
+		run := RunManifest{Profile: profile, AccountID: "123456789012"}
+		run.AddModule(ModuleRun{Name: "buckets", Regions: []string{"us-east-1"}, APICalls: map[string]map[string]int{"S3": {"ListBuckets": 1, "GetBucketPolicy": 4}}, Errors: 1})
+		run.AddModule(ModuleRun{Name: "principals", APICalls: map[string]map[string]int{"IAM": {"ListRoles": 2}}})
+		if _, err := AppendRunManifest("cloudfox-output", run); err != nil {
+			t.Fatalf("Cannot write run manifest: %s", err)
+		}
+	}
+
+	content, err := afero.ReadFile(fs, "cloudfox-output/run-manifest.json")
+	if err != nil {
+		t.Fatalf("Cannot read run manifest: %s", err)
+	}
+	var manifest runManifestFile
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		t.Fatalf("Cannot parse run manifest: %s", err)
+	}
+	if len(manifest.Runs) != 2 || manifest.Runs[0].Profile != "first" || manifest.Runs[1].Profile != "second" {
+		t.Fatalf("Expected both runs in order, got %+v", manifest.Runs)
+	}
+	run := manifest.Runs[1]
+	if run.TotalAPICalls != 7 || run.Errors != 1 || run.APICalls["S3"]["GetBucketPolicy"] != 4 || run.Modules[0].TotalAPICalls != 5 {
+		t.Errorf("Unexpected totals in %+v", run)
+	}
+}
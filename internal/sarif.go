@@ -0,0 +1,121 @@
+package internal
+
+// SARIF 2.1.0 is the format GitHub code scanning, Azure DevOps and most CI security gates read findings from. Only the
+// parts of the spec cloudfox needs are modelled here.
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+const (
+	SarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	SarifVersion = "2.1.0"
+)
+
+// SARIF result levels
+const (
+	SarifLevelError   = "error"
+	SarifLevelWarning = "warning"
+	SarifLevelNote    = "note"
+)
+
+type SarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SarifRun `json:"runs"`
+}
+
+type SarifRun struct {
+	Tool    SarifTool     `json:"tool"`
+	Results []SarifResult `json:"results"`
+}
+
+type SarifTool struct {
+	Driver SarifDriver `json:"driver"`
+}
+
+type SarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []SarifRule `json:"rules"`
+}
+
+type SarifRule struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name,omitempty"`
+	ShortDescription     SarifMessage           `json:"shortDescription"`
+	HelpURI              string                 `json:"helpUri,omitempty"`
+	DefaultConfiguration SarifRuleConfiguration `json:"defaultConfiguration"`
+	Properties           SarifPropertyBag       `json:"properties,omitempty"`
+}
+
+type SarifRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+// SarifPropertyBag holds the extra properties GitHub uses to rank alerts, e.g. "security-severity" and "tags"
+type SarifPropertyBag map[string]interface{}
+
+type SarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   SarifMessage    `json:"message"`
+	Locations []SarifLocation `json:"locations"`
+}
+
+type SarifMessage struct {
+	Text string `json:"text"`
+}
+
+// SarifLocation points at a cloud resource rather than a file. The ARN goes in the artifact URI, because GitHub rejects
+// results without a physical location, and in a logical location for tools that understand those.
+type SarifLocation struct {
+	PhysicalLocation SarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []SarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type SarifPhysicalLocation struct {
+	ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+}
+
+type SarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type SarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+// NewSarifLog returns a log with a single cloudfox run
+func NewSarifLog(rules []SarifRule, results []SarifResult) SarifLog {
+	if results == nil {
+		results = []SarifResult{}
+	}
+	return SarifLog{
+		Schema:  SarifSchema,
+		Version: SarifVersion,
+		Runs: []SarifRun{
+			{
+				Tool: SarifTool{
+					Driver: SarifDriver{
+						Name:           "cloudfox",
+						InformationURI: "https://github.com/BishopFox/cloudfox",
+						Rules:          rules,
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+}
+
+// NewSarifResourceLocation builds a location for a cloud resource identified by its ARN or resource ID
+func NewSarifResourceLocation(resourceID string) SarifLocation {
+	return SarifLocation{
+		PhysicalLocation: SarifPhysicalLocation{
+			ArtifactLocation: SarifArtifactLocation{URI: resourceID},
+		},
+		LogicalLocations: []SarifLogicalLocation{
+			{FullyQualifiedName: resourceID, Kind: "resource"},
+		},
+	}
+}
@@ -0,0 +1,276 @@
+// Package secretvalidation checks whether third-party credentials found in a cloud account still work. Every check is
+// a single read-only call that identifies the caller (GitHub GET /user, Slack auth.test, Stripe GET /v1/balance,
+// Twilio GET Account) and nothing is changed on the other side.
+package secretvalidation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Services with a validation call
+const (
+	GitHub = "GitHub"
+	Slack  = "Slack"
+	Stripe = "Stripe"
+	Twilio = "Twilio"
+)
+
+// Validation outcomes. A credential is only ACTIVE when the service accepted it, anything else (rejected, rate
+// limited, network errors, values we can't parse) is UNVERIFIED with the reason in Result.Reason.
+const (
+	StatusActive     = "ACTIVE"
+	StatusUnverified = "UNVERIFIED"
+)
+
+// maxRetryAfter is the longest Retry-After we wait for before retrying a rate limited call once
+const maxRetryAfter = 5 * time.Second
+
+var defaultBaseURLs = map[string]string{
+	GitHub: "https://api.github.com",
+	Slack:  "https://slack.com",
+	Stripe: "https://api.stripe.com",
+	Twilio: "https://api.twilio.com",
+}
+
+// namePatterns map the upper-cased words in a secret name to the service its value belongs to
+var namePatterns = []struct {
+	pattern string
+	service string
+}{
+	{"GITHUB", GitHub},
+	{"SLACK", Slack},
+	{"STRIPE", Stripe},
+	{"TWILIO", Twilio},
+}
+
+type Result struct {
+	Status string
+	Reason string
+}
+
+type ValidatorInterface interface {
+	Validate(ctx context.Context, service string, value string) Result
+}
+
+type Validator struct {
+	HTTPClient *http.Client
+	// BaseURLs replace the API endpoint of a service, tests point them at an httptest server
+	BaseURLs map[string]string
+}
+
+func NewValidator() *Validator {
+	return &Validator{
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ServiceForSecretName returns the service a secret name points to, or "" if it doesn't match any of them
+func ServiceForSecretName(name string) string {
+	upper := strings.ToUpper(name)
+	for _, p := range namePatterns {
+		if strings.Contains(upper, p.pattern) {
+			return p.service
+		}
+	}
+	return ""
+}
+
+// Validate makes the validation call of service with the credential in value. Values can be the bare credential or
+// a JSON object with the credential in a field named like token, key or secret.
+func (v *Validator) Validate(ctx context.Context, service string, value string) Result {
+	var req *http.Request
+	var err error
+	switch service {
+	case GitHub:
+		token := credentialFromValue(value, "token", "key", "pat", "secret", "password")
+		if token == "" {
+			return unverified("no token in the secret value")
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, v.baseURL(service)+"/user", nil)
+		if err == nil {
+			req.Header.Set("Authorization", "token "+token)
+			req.Header.Set("Accept", "application/vnd.github+json")
+		}
+	case Slack:
+		token := credentialFromValue(value, "token", "key", "secret")
+		if token == "" {
+			return unverified("no token in the secret value")
+		}
+		if strings.HasPrefix(token, "https://hooks.slack.com/") {
+			return unverified("incoming webhook URLs can't be checked without posting a message")
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, v.baseURL(service)+"/api/auth.test", nil)
+		if err == nil {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	case Stripe:
+		key := credentialFromValue(value, "key", "secret", "token")
+		if key == "" {
+			return unverified("no API key in the secret value")
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, v.baseURL(service)+"/v1/balance", nil)
+		if err == nil {
+			req.SetBasicAuth(key, "")
+		}
+	case Twilio:
+		sid, token := twilioCredentialsFromValue(value)
+		if sid == "" || token == "" {
+			return unverified("no account SID and auth token in the secret value")
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/2010-04-01/Accounts/%s.json", v.baseURL(service), url.PathEscape(sid)), nil)
+		if err == nil {
+			req.SetBasicAuth(sid, token)
+		}
+	default:
+		return unverified(fmt.Sprintf("no validation for %q", service))
+	}
+	if err != nil {
+		return unverified(err.Error())
+	}
+
+	resp, err := v.do(req)
+	if err != nil {
+		return unverified("network error: " + err.Error())
+	}
+	defer resp.Body.Close()
+
+	if isRateLimited(resp) {
+		return unverified("rate limited by " + service)
+	}
+	switch {
+	case resp.StatusCode == http.StatusOK && service == Slack:
+		// Slack answers 200 to everything and puts the outcome in the body
+		var body struct {
+			OK    bool   `json:"ok"`
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return unverified("unexpected response: " + err.Error())
+		}
+		if !body.OK {
+			return unverified("rejected: " + body.Error)
+		}
+		return Result{Status: StatusActive}
+	case resp.StatusCode == http.StatusOK:
+		return Result{Status: StatusActive}
+	case resp.StatusCode == http.StatusForbidden && service == Stripe:
+		// Restricted keys without balance read access authenticate fine and are then denied
+		return Result{Status: StatusActive, Reason: "restricted key"}
+	case resp.StatusCode == http.StatusUnauthorized:
+		return unverified("rejected")
+	}
+	return unverified("unexpected response: " + resp.Status)
+}
+
+func (v *Validator) baseURL(service string) string {
+	if baseURL, ok := v.BaseURLs[service]; ok {
+		return baseURL
+	}
+	return defaultBaseURLs[service]
+}
+
+// do sends req and retries it once when the service asks us to wait for only a short while
+func (v *Validator) do(req *http.Request) (*http.Response, error) {
+	resp, err := v.HTTPClient.Do(req)
+	if err != nil || !isRateLimited(resp) {
+		return resp, err
+	}
+	wait, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || time.Duration(wait)*time.Second > maxRetryAfter {
+		return resp, nil
+	}
+	resp.Body.Close()
+	select {
+	case <-time.After(time.Duration(wait) * time.Second):
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	return v.HTTPClient.Do(req)
+}
+
+// isRateLimited tells 429s and GitHub's 403 with an exhausted rate limit apart from rejected credentials
+func isRateLimited(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0"
+}
+
+func unverified(reason string) Result {
+	return Result{Status: StatusUnverified, Reason: reason}
+}
+
+// credentialFromValue returns the value itself, or for JSON objects the first string field whose name contains one of
+// keys. A JSON object with a single string field returns that field whatever its name.
+func credentialFromValue(value string, keys ...string) string {
+	value = strings.TrimSpace(value)
+	fields, ok := jsonStringFields(value)
+	if !ok {
+		return value
+	}
+	if len(fields) == 1 {
+		for _, field := range fields {
+			return field
+		}
+	}
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, key := range keys {
+		for _, name := range names {
+			if strings.Contains(strings.ToLower(name), key) {
+				return fields[name]
+			}
+		}
+	}
+	return ""
+}
+
+// twilioCredentialsFromValue reads an account SID and auth token from a JSON object or from "SID:token"
+func twilioCredentialsFromValue(value string) (string, string) {
+	value = strings.TrimSpace(value)
+	fields, ok := jsonStringFields(value)
+	if !ok {
+		sid, token, found := strings.Cut(value, ":")
+		if !found {
+			return "", ""
+		}
+		return sid, token
+	}
+	var sid, token string
+	for name, field := range fields {
+		name = strings.ToLower(name)
+		switch {
+		case strings.Contains(name, "sid"):
+			sid = field
+		case strings.Contains(name, "token"), strings.Contains(name, "secret"):
+			token = field
+		}
+	}
+	return sid, token
+}
+
+func jsonStringFields(value string) (map[string]string, bool) {
+	if !strings.HasPrefix(value, "{") {
+		return nil, false
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return nil, false
+	}
+	fields := make(map[string]string)
+	for name, field := range raw {
+		if s, ok := field.(string); ok && s != "" {
+			fields[name] = s
+		}
+	}
+	return fields, true
+}
@@ -0,0 +1,16 @@
+package secretvalidation
+
+import (
+	"context"
+)
+
+// MockedValidator accepts the value "active-credential" for every service and rejects everything else
+type MockedValidator struct {
+}
+
+func (m *MockedValidator) Validate(ctx context.Context, service string, value string) Result {
+	if credentialFromValue(value, "token", "key", "secret") == "active-credential" {
+		return Result{Status: StatusActive}
+	}
+	return unverified("rejected")
+}
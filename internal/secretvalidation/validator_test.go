@@ -0,0 +1,111 @@
+package secretvalidation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServiceForSecretName(t *testing.T) {
+	subtests := map[string]string{
+		"prod/github-token":          GitHub,
+		"ci/GITHUB_PAT":              GitHub,
+		"payments/StripeSecretKey":   Stripe,
+		"alerts/slack_bot_token":     Slack,
+		"sms/twilio":                 Twilio,
+		"prod/db-password":           "",
+		"arn:aws:secretsmanager:x:y": "",
+	}
+	for name, expected := range subtests {
+		if service := ServiceForSecretName(name); service != expected {
+			t.Errorf("Expected %s to belong to %q, got %q", name, expected, service)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user":
+			switch r.Header.Get("Authorization") {
+			case "token ghp_active":
+				w.Write([]byte(`{"login":"octocat"}`))
+			case "token ghp_limited":
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.WriteHeader(http.StatusForbidden)
+			default:
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		case "/api/auth.test":
+			if r.Header.Get("Authorization") == "Bearer xoxb-active" {
+				w.Write([]byte(`{"ok":true,"team":"cloudfox"}`))
+				return
+			}
+			w.Write([]byte(`{"ok":false,"error":"invalid_auth"}`))
+		case "/v1/balance":
+			key, _, _ := r.BasicAuth()
+			switch key {
+			case "sk_live_active":
+				w.Write([]byte(`{"object":"balance"}`))
+			case "rk_live_restricted":
+				w.WriteHeader(http.StatusForbidden)
+			default:
+				w.Header().Set("Retry-After", "60")
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		case "/2010-04-01/Accounts/AC123.json":
+			sid, token, _ := r.BasicAuth()
+			if sid == "AC123" && token == "twilio-active" {
+				w.Write([]byte(`{"sid":"AC123"}`))
+				return
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	v := &Validator{
+		HTTPClient: server.Client(),
+		BaseURLs: map[string]string{
+			GitHub: server.URL,
+			Slack:  server.URL,
+			Stripe: server.URL,
+			Twilio: server.URL,
+		},
+	}
+	subtests := []struct {
+		service string
+		value   string
+		status  string
+		reason  string
+	}{
+		{service: GitHub, value: "ghp_active", status: StatusActive},
+		{service: GitHub, value: `{"username":"ci","token":"ghp_active"}`, status: StatusActive},
+		{service: GitHub, value: "ghp_revoked", status: StatusUnverified, reason: "rejected"},
+		{service: GitHub, value: "ghp_limited", status: StatusUnverified, reason: "rate limited by GitHub"},
+		{service: Slack, value: "xoxb-active", status: StatusActive},
+		{service: Slack, value: "xoxb-revoked", status: StatusUnverified, reason: "rejected: invalid_auth"},
+		{service: Slack, value: "https://hooks.slack.com/services/T000/B000/XXXX", status: StatusUnverified, reason: "incoming webhook URLs can't be checked without posting a message"},
+		{service: Stripe, value: "sk_live_active", status: StatusActive},
+		{service: Stripe, value: "rk_live_restricted", status: StatusActive, reason: "restricted key"},
+		{service: Stripe, value: "sk_live_busy", status: StatusUnverified, reason: "rate limited by Stripe"},
+		{service: Twilio, value: `{"account_sid":"AC123","auth_token":"twilio-active"}`, status: StatusActive},
+		{service: Twilio, value: "AC123:twilio-revoked", status: StatusUnverified, reason: "rejected"},
+		{service: Twilio, value: "twilio-active", status: StatusUnverified, reason: "no account SID and auth token in the secret value"},
+	}
+	for _, subtest := range subtests {
+		result := v.Validate(context.TODO(), subtest.service, subtest.value)
+		if result.Status != subtest.status || result.Reason != subtest.reason {
+			t.Errorf("Expected %s value %s to be %s (%q), got %s (%q)", subtest.service, subtest.value, subtest.status, subtest.reason, result.Status, result.Reason)
+		}
+	}
+
+	server.Close()
+	if result := v.Validate(context.TODO(), GitHub, "ghp_active"); result.Status != StatusUnverified || !strings.HasPrefix(result.Reason, "network error") {
+		t.Errorf("Expected a network error to leave the secret unverified, got %+v", result)
+	}
+}
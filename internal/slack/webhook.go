@@ -0,0 +1,95 @@
+// Package slack posts messages to Slack incoming webhooks. Messages use the legacy attachment format, which webhooks
+// still render with a colored bar and needs no app configuration beyond the webhook itself.
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Attachment colors by severity
+const (
+	ColorDanger  = "danger"
+	ColorWarning = "warning"
+	ColorGood    = "good"
+)
+
+type Message struct {
+	Text        string       `json:"text"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+type Attachment struct {
+	Color  string  `json:"color,omitempty"`
+	Title  string  `json:"title,omitempty"`
+	Text   string  `json:"text,omitempty"`
+	Fields []Field `json:"fields,omitempty"`
+	Footer string  `json:"footer,omitempty"`
+}
+
+type Field struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+type WebhookClientInterface interface {
+	Post(ctx context.Context, message Message) error
+}
+
+type WebhookClient struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// NewWebhookClient checks that webhookURL is an https URL, the webhook URL is a credential and must not go out in
+// plaintext
+func NewWebhookClient(webhookURL string) (*WebhookClient, error) {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Slack webhook URL: %s", err)
+	}
+	if parsed.Scheme != "https" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid Slack webhook URL: expected https://hooks.slack.com/services/...")
+	}
+	return &WebhookClient{
+		URL:        webhookURL,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (c *WebhookClient) Post(ctx context.Context, message Message) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		// The error has the URL in it, which holds the webhook's secret path
+		return fmt.Errorf("Slack webhook POST failed: %s", strings.ReplaceAll(err.Error(), c.URL, "<webhook>"))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// Webhooks answer errors with a short plaintext reason like invalid_payload or no_service
+		reason, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if len(reason) > 0 {
+			return fmt.Errorf("Slack webhook POST: %d %s", resp.StatusCode, strings.TrimSpace(string(reason)))
+		}
+		return fmt.Errorf("Slack webhook POST: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package slack
+
+import (
+	"context"
+	"sync"
+)
+
+// MockedWebhookClient keeps the posted messages instead of sending them
+type MockedWebhookClient struct {
+	Messages []Message
+	mu       sync.Mutex
+}
+
+func (m *MockedWebhookClient) Post(ctx context.Context, message Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Messages = append(m.Messages, message)
+	return nil
+}
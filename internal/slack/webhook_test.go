@@ -0,0 +1,60 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookClient(t *testing.T) {
+	var received Message
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("invalid_payload"))
+			return
+		}
+		if r.URL.Path != "/services/T000/B000/XXXX" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("no_service"))
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client, err := NewWebhookClient(server.URL + "/services/T000/B000/XXXX")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.HTTPClient = server.Client()
+	message := Message{
+		Text:        "cloudfox found 2 secrets",
+		Attachments: []Attachment{{Color: ColorDanger, Title: "Top secrets", Text: "prod/db"}},
+	}
+	if err := client.Post(context.TODO(), message); err != nil {
+		t.Fatal(err)
+	}
+	if received.Text != message.Text || len(received.Attachments) != 1 || received.Attachments[0].Color != ColorDanger {
+		t.Errorf("Unexpected message %+v", received)
+	}
+
+	client.URL = server.URL + "/services/T000/B000/YYYY"
+	if err := client.Post(context.TODO(), message); err == nil || err.Error() != "Slack webhook POST: 404 no_service" {
+		t.Errorf("Expected the webhook's reason, got %v", err)
+	}
+
+	// The webhook path is a credential and stays out of the errors
+	server.Close()
+	if err := client.Post(context.TODO(), message); err == nil || strings.Contains(err.Error(), "YYYY") {
+		t.Errorf("Expected an error without the webhook URL, got %v", err)
+	}
+
+	if _, err := NewWebhookClient("http://hooks.slack.com/services/T000/B000/XXXX"); err == nil {
+		t.Error("Expected plaintext webhook URLs to be refused")
+	}
+}
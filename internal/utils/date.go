@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// ParseDate reads a date given on the command line, either as RFC3339 or as YYYY-MM-DD. A plain date means midnight
+// UTC at the start of that day.
+func ParseDate(value string) (time.Time, error) {
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is neither an RFC3339 timestamp nor a YYYY-MM-DD date", value)
+}
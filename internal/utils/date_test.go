@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	subtests := []struct {
+		value    string
+		expected time.Time
+		wantErr  bool
+	}{
+		{
+			value:    "2024-03-01",
+			expected: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			value:    "2024-03-01T12:30:00Z",
+			expected: time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC),
+		},
+		{
+			value:    "2024-03-01T12:30:00+02:00",
+			expected: time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			value:   "01/03/2024",
+			wantErr: true,
+		},
+	}
+	for _, subtest := range subtests {
+		date, err := ParseDate(subtest.value)
+		if (err != nil) != subtest.wantErr {
+			t.Errorf("ParseDate(%q) error = %v, wantErr %t", subtest.value, err, subtest.wantErr)
+			continue
+		}
+		if !date.Equal(subtest.expected) {
+			t.Errorf("ParseDate(%q) = %s, want %s", subtest.value, date, subtest.expected)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// LootWriter renders loot files from Go text/template files. A module's loot comes from <name>.tmpl in
+// OverrideDir when there is one, so users can swap in their own commands (aws-vault wrappers, awscurl, other profile
+// flags), and from the embedded default otherwise. A broken override falls back to the default with a warning, so a
+// typo never leaves an empty loot file behind.
+type LootWriter struct {
+	// Defaults holds the <name>.tmpl files that reproduce the built-in loot
+	Defaults fs.FS
+	// OverrideDir is the --loot-template-dir, empty to always use the defaults
+	OverrideDir string
+	// Warn reports overrides that could not be used, it prints to stderr if nil
+	Warn func(format string, args ...interface{})
+}
+
+const lootTemplateExtension = ".tmpl"
+
+// Render executes the template name with data and returns the loot file contents
+func (w LootWriter) Render(name string, data interface{}) string {
+	if w.OverrideDir != "" {
+		overridePath := filepath.Join(w.OverrideDir, name+lootTemplateExtension)
+		text, err := os.ReadFile(overridePath)
+		if err == nil {
+			out, err := executeLootTemplate(name, string(text), data)
+			if err == nil {
+				return out
+			}
+			w.warn("Loot template %s failed, using the default: %s", overridePath, err)
+		} else if !os.IsNotExist(err) {
+			w.warn("Cannot read loot template %s, using the default: %s", overridePath, err)
+		}
+	}
+
+	text, err := fs.ReadFile(w.Defaults, name+lootTemplateExtension)
+	if err != nil {
+		w.warn("No default loot template for %s: %s", name, err)
+		return ""
+	}
+	out, err := executeLootTemplate(name, string(text), data)
+	if err != nil {
+		w.warn("Default loot template for %s failed: %s", name, err)
+		return ""
+	}
+	return out
+}
+
+func (w LootWriter) warn(format string, args ...interface{}) {
+	if w.Warn != nil {
+		w.Warn(format, args...)
+		return
+	}
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+// executeLootTemplate renders into a buffer first, so a template that fails halfway doesn't leave partial output
+func executeLootTemplate(name string, text string, data interface{}) (string, error) {
+	// once is true the first time it sees a key, for resources that should only get one command
+	seen := make(map[string]bool)
+	funcs := template.FuncMap{
+		"once": func(key string) bool {
+			if seen[key] {
+				return false
+			}
+			seen[key] = true
+			return true
+		},
+		"join":  strings.Join,
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+	}
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLootWriter(t *testing.T) {
+	type bucket struct {
+		Name   string
+		Region string
+	}
+	buckets := []bucket{{Name: "logs", Region: "us-east-1"}, {Name: "backups", Region: "us-east-1"}, {Name: "logs", Region: "eu-west-1"}}
+	defaults := fstest.MapFS{
+		"buckets.tmpl": {Data: []byte("{{ range . }}{{ if once .Name }}aws s3 ls s3://{{ .Name }}\n{{ end }}{{ end }}")},
+	}
+
+	overrideDir := t.TempDir()
+	writeTemplate := func(name string, text string) {
+		if err := os.WriteFile(filepath.Join(overrideDir, name+".tmpl"), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	subtests := []struct {
+		name     string
+		override string
+		expected string
+		warns    bool
+	}{
+		{
+			name:     "default",
+			expected: "aws s3 ls s3://logs\naws s3 ls s3://backups\n",
+		},
+		{
+			name:     "override",
+			override: "{{ range . }}aws-vault exec $profile -- aws --region {{ .Region }} s3 ls s3://{{ .Name }}\n{{ end }}",
+			expected: "aws-vault exec $profile -- aws --region us-east-1 s3 ls s3://logs\naws-vault exec $profile -- aws --region us-east-1 s3 ls s3://backups\naws-vault exec $profile -- aws --region eu-west-1 s3 ls s3://logs\n",
+		},
+		{
+			name:     "override that does not parse",
+			override: "{{ range . }}{{ .Name }",
+			expected: "aws s3 ls s3://logs\naws s3 ls s3://backups\n",
+			warns:    true,
+		},
+		{
+			name:     "override that fails to execute",
+			override: "{{ range . }}{{ .Bucket }}\n{{ end }}",
+			expected: "aws s3 ls s3://logs\naws s3 ls s3://backups\n",
+			warns:    true,
+		},
+	}
+	for _, subtest := range subtests {
+		t.Run(subtest.name, func(t *testing.T) {
+			os.Remove(filepath.Join(overrideDir, "buckets.tmpl"))
+			if subtest.override != "" {
+				writeTemplate("buckets", subtest.override)
+			}
+			var warnings []string
+			w := LootWriter{
+				Defaults:    defaults,
+				OverrideDir: overrideDir,
+				Warn: func(format string, args ...interface{}) {
+					warnings = append(warnings, fmt.Sprintf(format, args...))
+				},
+			}
+			if got := w.Render("buckets", buckets); got != subtest.expected {
+				t.Errorf("Expected %q, got %q", subtest.expected, got)
+			}
+			if subtest.warns != (len(warnings) > 0) {
+				t.Errorf("Expected warnings: %t, got %v", subtest.warns, warnings)
+			}
+		})
+	}
+}
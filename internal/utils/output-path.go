@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OutputPathValues holds what the placeholders in an output path template are replaced with
+type OutputPathValues struct {
+	Account string
+	Profile string
+	Regions []string
+	Date    time.Time
+}
+
+// ExpandOutputPathTemplate replaces {account}, {profile}, {region} and {date} in an output path, so scheduled scans
+// can write each run to its own directory, e.g. /tmp/scan-{account}-{date}. {region} becomes the region name when a
+// single region is scanned and "<n>-regions" otherwise. Unknown placeholders are left alone.
+func ExpandOutputPathTemplate(template string, values OutputPathValues) string {
+	if !strings.Contains(template, "{") {
+		return template
+	}
+
+	date := values.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	replacer := strings.NewReplacer(
+		"{account}", sanitizePathElement(values.Account),
+		"{profile}", sanitizePathElement(values.Profile),
+		"{region}", sanitizePathElement(regionSetName(values.Regions)),
+		"{date}", date.Format("2006-01-02"),
+	)
+	return replacer.Replace(template)
+}
+
+func regionSetName(regions []string) string {
+	switch len(regions) {
+	case 0:
+		return "no-regions"
+	case 1:
+		return regions[0]
+	default:
+		return fmt.Sprintf("%d-regions", len(regions))
+	}
+}
+
+// sanitizePathElement keeps a substituted value from adding directories to the path, profile names like
+// "org/dev" would otherwise do that
+func sanitizePathElement(value string) string {
+	value = strings.ReplaceAll(value, "/", "-")
+	value = strings.ReplaceAll(value, "\\", "-")
+	return value
+}
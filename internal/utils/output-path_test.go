@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpandOutputPathTemplate(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 2, 0, 0, 0, time.UTC)
+	subtests := []struct {
+		name     string
+		template string
+		values   OutputPathValues
+		expected string
+	}{
+		{
+			name:     "no placeholders",
+			template: "/tmp/cloudfox",
+			values:   OutputPathValues{Account: "123456789012"},
+			expected: "/tmp/cloudfox",
+		},
+		{
+			name:     "account and date",
+			template: "/tmp/scan-{account}-{date}",
+			values:   OutputPathValues{Account: "123456789012", Date: date},
+			expected: "/tmp/scan-123456789012-2024-03-05",
+		},
+		{
+			name:     "profile with a slash and a single region",
+			template: "/scans/{profile}/{region}",
+			values:   OutputPathValues{Profile: "org/dev", Regions: []string{"us-east-1"}, Date: date},
+			expected: "/scans/org-dev/us-east-1",
+		},
+		{
+			name:     "several regions and an unknown placeholder",
+			template: "/scans/{region}-{unknown}",
+			values:   OutputPathValues{Regions: []string{"us-east-1", "eu-west-1"}, Date: date},
+			expected: "/scans/2-regions-{unknown}",
+		},
+	}
+
+	for _, subtest := range subtests {
+		t.Run(subtest.name, func(t *testing.T) {
+			got := ExpandOutputPathTemplate(subtest.template, subtest.values)
+			if got != subtest.expected {
+				t.Errorf("ExpandOutputPathTemplate(%s) = %s, expected %s", subtest.template, got, subtest.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package utils
+
+import "math"
+
+// Mean returns the arithmetic mean of values, or 0 when there are none
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// StandardDeviation returns the population standard deviation of values. We always look at the complete set of events
+// in a time window rather than a sample of them, so there is no Bessel correction.
+func StandardDeviation(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	mean := Mean(values)
+	var squares float64
+	for _, value := range values {
+		squares += (value - mean) * (value - mean)
+	}
+	return math.Sqrt(squares / float64(len(values)))
+}
+
+// ZScore returns how many standard deviations value is away from mean. It is 0 when there is no deviation at all,
+// because then nothing stands out.
+func ZScore(value float64, mean float64, standardDeviation float64) float64 {
+	if standardDeviation == 0 {
+		return 0
+	}
+	return (value - mean) / standardDeviation
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	subtests := []struct {
+		name              string
+		values            []float64
+		mean              float64
+		standardDeviation float64
+	}{
+		{
+			name: "no values",
+		},
+		{
+			name:              "identical values",
+			values:            []float64{4, 4, 4},
+			mean:              4,
+			standardDeviation: 0,
+		},
+		{
+			name:              "textbook example",
+			values:            []float64{2, 4, 4, 4, 5, 5, 7, 9},
+			mean:              5,
+			standardDeviation: 2,
+		},
+	}
+
+	for _, subtest := range subtests {
+		t.Run(subtest.name, func(t *testing.T) {
+			if mean := Mean(subtest.values); math.Abs(mean-subtest.mean) > 1e-9 {
+				t.Errorf("Expected a mean of %f, got %f", subtest.mean, mean)
+			}
+			if standardDeviation := StandardDeviation(subtest.values); math.Abs(standardDeviation-subtest.standardDeviation) > 1e-9 {
+				t.Errorf("Expected a standard deviation of %f, got %f", subtest.standardDeviation, standardDeviation)
+			}
+		})
+	}
+}
+
+func TestZScore(t *testing.T) {
+	if z := ZScore(9, 5, 2); z != 2 {
+		t.Errorf("Expected a z-score of 2, got %f", z)
+	}
+	if z := ZScore(1, 5, 2); z != -2 {
+		t.Errorf("Expected a z-score of -2, got %f", z)
+	}
+	if z := ZScore(9, 5, 0); z != 0 {
+		t.Errorf("Expected a z-score of 0 without any deviation, got %f", z)
+	}
+}
@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/sirupsen/logrus"
+)
+
+// Levels of --verbosity, the same for every module and cloud. Each level prints everything the levels below it print.
+// Output files are written the same way at every level.
+const (
+	// VerbositySummary prints the summary lines only: what is enumerated, how much was found and where it was written
+	VerbositySummary = 0
+	// VerbosityTables also prints the tables
+	VerbosityTables = 1
+	// VerbosityErrors also prints the details of errors, which otherwise only go to cloudfox-error.log
+	VerbosityErrors = 2
+	// VerbosityAll also prints the loot file contents and the findings of modules that stream them as they come in
+	VerbosityAll = 3
+)
+
+// PrintLoot prints the loot file contents at every verbosity, for --print-loot
+var PrintLoot bool
+
+var (
+	terminalVerbosity   = VerbositySummary
+	terminalVerbosityMu sync.Mutex
+	errorHookOnce       sync.Once
+)
+
+func ShowTables(verbosity int) bool {
+	return verbosity >= VerbosityTables
+}
+
+func ShowErrors(verbosity int) bool {
+	return verbosity >= VerbosityErrors
+}
+
+func ShowLoot(verbosity int) bool {
+	return PrintLoot || verbosity >= VerbosityAll
+}
+
+// SetTerminalVerbosity makes the errors that modules log to TxtLog show up on the terminal as well from
+// VerbosityErrors on
+func SetTerminalVerbosity(verbosity int) {
+	terminalVerbosityMu.Lock()
+	terminalVerbosity = verbosity
+	terminalVerbosityMu.Unlock()
+	errorHookOnce.Do(func() {
+		TxtLog.AddHook(terminalErrorHook{})
+	})
+}
+
+// terminalErrorHook echoes the errors logged to TxtLog to the terminal, without breaking the spinner's status line
+type terminalErrorHook struct{}
+
+func (terminalErrorHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel}
+}
+
+func (terminalErrorHook) Fire(entry *logrus.Entry) error {
+	terminalVerbosityMu.Lock()
+	verbosity := terminalVerbosity
+	terminalVerbosityMu.Unlock()
+	if !ShowErrors(verbosity) {
+		return nil
+	}
+	var red = color.New(color.FgRed).SprintFunc()
+	if module, ok := entry.Data["module"]; ok {
+		PrintWhileSpinning("[%s] %s\n", red(module), entry.Message)
+	} else {
+		PrintWhileSpinning("[%s] %s\n", red("error"), entry.Message)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// captureStdout returns what run printed to the terminal
+func captureStdout(t *testing.T, run func()) string {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	output := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(reader)
+		output <- string(out)
+	}()
+	run()
+	writer.Close()
+	os.Stdout = stdout
+	return <-output
+}
+
+func TestVerbosityMatrix(t *testing.T) {
+	MockFileSystem(true)
+	defer MockFileSystem(false)
+	defer func() {
+		PrintLoot = false
+		SetTerminalVerbosity(VerbositySummary)
+	}()
+
+	const (
+		summary = "Output written to"
+		table   = "table-cell"
+		errors  = "error-detail"
+		loot    = "loot-line"
+	)
+	tests := []struct {
+		verbosity int
+		printLoot bool
+		expected  []string
+	}{
+		{VerbositySummary, false, []string{summary}},
+		{VerbosityTables, false, []string{summary, table}},
+		{VerbosityErrors, false, []string{summary, table, errors}},
+		{VerbosityAll, false, []string{summary, table, errors, loot}},
+		{VerbositySummary, true, []string{summary, loot}},
+		{VerbosityTables, true, []string{summary, table, loot}},
+		{VerbosityErrors, true, []string{summary, table, errors, loot}},
+		{VerbosityAll, true, []string{summary, table, errors, loot}},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("verbosity %d print-loot %v", test.verbosity, test.printLoot), func(t *testing.T) {
+			PrintLoot = test.printLoot
+			SetTerminalVerbosity(test.verbosity)
+			output := captureStdout(t, func() {
+				TxtLog.WithFields(logrus.Fields{"module": "test"}).Error(errors)
+				o := OutputClient{
+					Verbosity:        test.verbosity,
+					CallingModule:    "test",
+					PrefixIdentifier: "unittesting",
+					Table:            TableClient{DirectoryName: "verbosity"},
+					Loot:             LootClient{DirectoryName: "verbosity"},
+				}
+				o.WriteFullOutput(
+					[]TableFile{{Name: "test", Header: []string{"Column"}, Body: [][]string{{table}}}},
+					[]LootFile{{Name: "test", Contents: loot}},
+				)
+			})
+			for _, level := range []string{summary, table, errors, loot} {
+				shown := strings.Contains(output, level)
+				if expected := Contains(level, test.expected); shown != expected {
+					t.Errorf("Expected %s on the terminal: %v, got %v", level, expected, shown)
+				}
+			}
+		})
+	}
+}